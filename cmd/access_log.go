@@ -0,0 +1,339 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// sensitiveTag is the struct tag of the domain entity marking a field
+// declared with the sensitive modifier, e.g. sensitive:"true".
+const sensitiveTag = "sensitive"
+
+// defaultRedactedFields are the JSON keys the access-log middleware redacts
+// in every project, before the sensitive fields of its entities.
+var defaultRedactedFields = []string{
+	"password", "token", "access_token", "refresh_token", "secret", "api_key", "authorization",
+}
+
+// addSensitiveTags marks the fields declared with the sensitive modifier in
+// their struct tag, so the access-log middleware finds them in the domain.
+func addSensitiveTags(fieldsList []Field) {
+	for i, f := range fieldsList {
+		if !f.Sensitive {
+			continue
+		}
+		inner := strings.Trim(f.Tag, "`")
+		fieldsList[i].Tag = fmt.Sprintf("`%s %s:\"true\"`", inner, sensitiveTag)
+	}
+}
+
+// fieldSensitive reports whether f was declared with the sensitive modifier,
+// or carries the sensitive tag in the domain entity.
+func fieldSensitive(f Field) bool {
+	return f.Sensitive || reflect.StructTag(strings.Trim(f.Tag, "`")).Get(sensitiveTag) == "true"
+}
+
+// domainSensitiveFields returns the JSON names of the fields tagged sensitive
+// in the structs of the domain package dir, sorted and without duplicates.
+func domainSensitiveFields(dir string) []string {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			st, ok := n.(*ast.StructType)
+			if !ok {
+				return true
+			}
+			for _, field := range structFields(f, st) {
+				if !fieldSensitive(field) {
+					continue
+				}
+				name := jsonTagName(field.Tag)
+				if name == "" || name == "-" {
+					name = field.Name
+				}
+				if !seen[name] {
+					seen[name] = true
+					names = append(names, name)
+				}
+			}
+			return true
+		})
+	}
+	sort.Strings(names)
+	return names
+}
+
+// accessLogExists reports whether the access-log middleware has been
+// generated in internal/middleware.
+func accessLogExists() bool {
+	_, err := os.Stat(filepath.Join(DirInternal, dirMiddleware, "access_log.go"))
+	return err == nil
+}
+
+// syncSensitiveFields rewrites internal/middleware/sensitive_fields.go for
+// the sensitive fields of the domain, after an entity or goca field changed
+// them. Projects without the access-log middleware are left alone.
+func syncSensitiveFields(sm ...*SafetyManager) error {
+	if !accessLogExists() {
+		return nil
+	}
+	path := filepath.Join(DirInternal, dirMiddleware, "sensitive_fields.go")
+	return writeGoFileMerged(path, generateSensitiveFields(), sm...)
+}
+
+// generateSensitiveFields returns sensitive_fields.go content: the JSON keys
+// of the fields tagged sensitive in internal/domain.
+func generateSensitiveFields() string {
+	var b strings.Builder
+	b.WriteString("package middleware\n\n")
+	b.WriteString("// sensitiveFields are the JSON names of the domain fields declared with\n")
+	b.WriteString("// the sensitive modifier of --fields. goca rewrites this file when\n")
+	b.WriteString("// entities or their fields change.\n")
+	b.WriteString("var sensitiveFields = []string{\n")
+	for _, name := range domainSensitiveFields(filepath.Join(DirInternal, DirDomain)) {
+		fmt.Fprintf(&b, "\t%q,\n", name)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// generateAccessLogMiddleware returns access_log.go content.
+func generateAccessLogMiddleware() string {
+	var b strings.Builder
+	b.WriteString(accessLogConfigTemplate)
+	b.WriteString("// DefaultRedactedFields returns the JSON keys redacted in every project\n")
+	b.WriteString("// followed by the sensitive fields of the domain.\n")
+	b.WriteString("func DefaultRedactedFields() []string {\n")
+	items := make([]string, len(defaultRedactedFields))
+	for i, name := range defaultRedactedFields {
+		items[i] = fmt.Sprintf("%q", name)
+	}
+	fmt.Fprintf(&b, "\treturn append([]string{%s}, sensitiveFields...)\n", strings.Join(items, ", "))
+	b.WriteString("}\n\n")
+	b.WriteString(accessLogMiddlewareTemplate)
+	return b.String()
+}
+
+const accessLogConfigTemplate = `package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// redacted replaces the values of redacted fields in logged bodies.
+const redacted = "[REDACTED]"
+
+// AccessLogConfig holds the settings of the access-log middleware.
+type AccessLogConfig struct {
+	// Logger receives one record per request. Nil logs to slog.Default().
+	Logger *slog.Logger
+	// LogBodies adds the JSON request and response bodies to the records,
+	// with the values of RedactFields replaced.
+	LogBodies bool
+	// MaxBodySize is the number of bytes of a body logged. Larger bodies
+	// are logged as truncated.
+	MaxBodySize int
+	// RedactFields are the JSON keys whose values are never logged, at any
+	// depth. Keys match regardless of case, '_' and '-', so "access_token"
+	// also redacts "accessToken".
+	RedactFields []string
+	// SkipPaths are the paths not logged, e.g. health checks.
+	SkipPaths []string
+}
+
+// DefaultAccessLogConfig returns sensible defaults: bodies are not logged,
+// and at most 4 KiB of them when they are.
+func DefaultAccessLogConfig() AccessLogConfig {
+	return AccessLogConfig{
+		Logger:       slog.Default(),
+		MaxBodySize:  4 << 10,
+		RedactFields: DefaultRedactedFields(),
+		SkipPaths:    []string{"/health", "/health/ready", "/health/live"},
+	}
+}
+
+`
+
+const accessLogMiddlewareTemplate = `// AccessLog returns middleware that logs the method, path, status, latency
+// and size of every request, with its X-Request-ID. Register it after
+// RequestID so the records carry the ID the response is sent with.
+// Responses with a 5xx status are logged at error level, 4xx at warn level.
+func AccessLog(cfg AccessLogConfig) Middleware {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	redact := make(map[string]bool, len(cfg.RedactFields))
+	for _, field := range cfg.RedactFields {
+		redact[normalizeFieldKey(field)] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, path := range cfg.SkipPaths {
+				if r.URL.Path == path {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			start := time.Now()
+			var requestBody []byte
+			if cfg.LogBodies && r.Body != nil {
+				requestBody = peekBody(r, cfg.MaxBodySize)
+			}
+			aw := &accessLogWriter{ResponseWriter: w, status: http.StatusOK, capture: cfg.LogBodies, max: cfg.MaxBodySize}
+			next.ServeHTTP(aw, r)
+
+			attrs := []slog.Attr{
+				slog.String("request_id", requestID(w, r)),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", aw.status),
+				slog.Duration("latency", time.Since(start)),
+				slog.Int("bytes", aw.written),
+				slog.String("remote_addr", r.RemoteAddr),
+			}
+			if cfg.LogBodies {
+				if body := redactBody(requestBody, cfg.MaxBodySize, redact); body != "" {
+					attrs = append(attrs, slog.String("request_body", body))
+				}
+				if body := redactBody(aw.body.Bytes(), cfg.MaxBodySize, redact); body != "" {
+					attrs = append(attrs, slog.String("response_body", body))
+				}
+			}
+
+			level := slog.LevelInfo
+			switch {
+			case aw.status >= http.StatusInternalServerError:
+				level = slog.LevelError
+			case aw.status >= http.StatusBadRequest:
+				level = slog.LevelWarn
+			}
+			logger.LogAttrs(r.Context(), level, "http request", attrs...)
+		})
+	}
+}
+
+// requestID returns the X-Request-ID set on the response by RequestID, or
+// the one the client sent.
+func requestID(w http.ResponseWriter, r *http.Request) string {
+	if id := w.Header().Get("X-Request-ID"); id != "" {
+		return id
+	}
+	return r.Header.Get("X-Request-ID")
+}
+
+// peekBody reads up to max+1 bytes of the request body and puts them back in
+// front of the rest, so the handler still reads the whole body.
+func peekBody(r *http.Request, max int) []byte {
+	head, _ := io.ReadAll(io.LimitReader(r.Body, int64(max)+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+	return head
+}
+
+// redactBody returns body as compact JSON with the values of the redacted
+// keys replaced. Bodies that are empty are not logged, and bodies past max
+// bytes or that are not JSON are only described: they may hold anything.
+func redactBody(body []byte, max int, redact map[string]bool) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return ""
+	}
+	if len(body) > max {
+		return "[truncated]"
+	}
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return "[non-JSON body]"
+	}
+	out, err := json.Marshal(redactValue(value, redact))
+	if err != nil {
+		return "[non-JSON body]"
+	}
+	return string(out)
+}
+
+// redactValue replaces the values of the redacted keys of value, at any
+// depth.
+func redactValue(value any, redact map[string]bool) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if redact[normalizeFieldKey(key)] {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(field, redact)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item, redact)
+		}
+	}
+	return value
+}
+
+// normalizeFieldKey lowercases key and drops '_' and '-', so snake_case,
+// kebab-case and camelCase names of a field match.
+func normalizeFieldKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+// accessLogWriter wraps http.ResponseWriter to capture the status code, the
+// size of the response and, when capture is set, its first max+1 bytes.
+type accessLogWriter struct {
+	http.ResponseWriter
+	status  int
+	written int
+	capture bool
+	max     int
+	body    bytes.Buffer
+}
+
+func (w *accessLogWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *accessLogWriter) Write(p []byte) (int, error) {
+	if w.capture && w.body.Len() <= w.max {
+		w.body.Write(p[:min(len(p), w.max+1-w.body.Len())])
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.written += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *accessLogWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+`
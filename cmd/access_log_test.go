@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensitiveModifier(t *testing.T) {
+	fields := parseFieldsWithValidation("name:string,password:string:sensitive,api_key:string:sensitive:json=apiKey", true)[1:]
+	assert.False(t, fieldSensitive(fields[0]))
+	assert.True(t, fields[1].Sensitive)
+	assert.Equal(t, "`json:\"password\" gorm:\"type:varchar(255)\" sensitive:\"true\" validate:\"required\"`", fields[1].Tag)
+	assert.True(t, fieldSensitive(Field{Tag: "`json:\"apiKey\" sensitive:\"true\"`"}))
+}
+
+func TestGenerateAccessLogMiddleware(t *testing.T) {
+	out := generateAccessLogMiddleware()
+	assert.Contains(t, out, "func AccessLog(cfg AccessLogConfig) Middleware {")
+	assert.Contains(t, out, `return append([]string{"password", "token", "access_token", "refresh_token", "secret", "api_key", "authorization"}, sensitiveFields...)`)
+	assert.Contains(t, out, `slog.String("request_id", requestID(w, r))`)
+	assert.Contains(t, out, "func (w *accessLogWriter) Unwrap() http.ResponseWriter {")
+}
+
+func TestSyncSensitiveFields(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+	sm := NewSafetyManager(false, true, false)
+
+	// Without the access log there is nothing to keep in sync.
+	require.NoError(t, generateEntity("User", "name:string,password:string:sensitive", false, false, false, false, false, false, false, false, "lowercase", sm))
+	_, err := os.Stat(filepath.Join(DirInternal, dirMiddleware, "sensitive_fields.go"))
+	assert.True(t, os.IsNotExist(err))
+
+	require.NoError(t, generateMiddlewarePackage("api", []string{"request-id", "access-log"}, RouterMux, sm))
+	readParsedGo(t, filepath.Join(DirInternal, dirMiddleware, "access_log.go"))
+	fields := readParsedGo(t, filepath.Join(DirInternal, dirMiddleware, "sensitive_fields.go"))
+	assert.Contains(t, fields, "var sensitiveFields = []string{\n\t\"password\",\n}")
+
+	require.NoError(t, generateEntity("Card", "number:string:sensitive:json=cardNumber,holder:string", false, false, false, false, false, false, false, false, "lowercase", sm))
+	fields = readParsedGo(t, filepath.Join(DirInternal, dirMiddleware, "sensitive_fields.go"))
+	assert.Contains(t, fields, "var sensitiveFields = []string{\n\t\"cardNumber\",\n\t\"password\",\n}")
+}
@@ -0,0 +1,536 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// ExperimentAdminUI gates goca admin.
+const ExperimentAdminUI = "admin-ui"
+
+// adminDir is the package of the admin panel.
+var adminDir = filepath.Join(DirInternal, DirHandler, "admin")
+
+// adminConfigDecl declares the admin panel settings of pkg/config.
+const adminConfigDecl = `// AdminConfig protects the admin panel of internal/handler/admin.
+type AdminConfig struct {
+	// Username and Password are the HTTP basic credentials of the panel. It
+	// is not served without a password.
+	Username string
+	Password string
+	// PageSize is the number of rows of a table page.
+	PageSize int
+}
+`
+
+// adminConfigLoad is the Admin element of the Config literal Load returns.
+const adminConfigLoad = `		Admin: AdminConfig{
+			Username: getEnv("ADMIN_USER", "admin"),
+			Password: getEnv("ADMIN_PASSWORD", ""),
+			PageSize: getEnvAsInt("ADMIN_PAGE_SIZE", 20),
+		},
+`
+
+// adminMountPattern matches the admin.Mount call goca admin writes into
+// main.go, with its comment.
+var adminMountPattern = regexp.MustCompile(`(?s)\t// Admin panel[^\n]*\n\tadmin\.Mount\(router, .*?\n\t\)\n`)
+
+// adminResourcePattern finds the resource constructors of the admin package.
+var adminResourcePattern = regexp.MustCompile(`(?m)^func New(\w+)Resource\(uc usecase\.\w+UseCase\) Resource \{`)
+
+var adminCmd = &cobra.Command{
+	Use:   "admin [Entity...]",
+	Short: "Generate a server-rendered admin panel over the entities",
+	Long: `Generates a back-office for internal use: an html/template admin panel
+served under /admin, with a paginated table and create and edit forms per
+entity.
+
+  - internal/handler/admin/admin.go        Routes, basic authentication,
+                                           pagination and form parsing
+  - internal/handler/admin/templates/      The embedded HTML templates
+  - internal/handler/admin/<entity>_admin.go
+                                           The fields of the entity and the
+                                           use case calls behind its pages
+
+Without arguments, every integrated feature gets a page. The form of an
+entity is derived from its fields and their validate tags: numbers, dates,
+checkboxes and selects for enums, with required, min, max and length
+constraints. Buttons are only shown for the operations its use case declares.
+Fields of other types, such as nested structs, are left out.
+
+The panel is mounted in main.go behind HTTP basic authentication with
+ADMIN_USER (admin by default) and ADMIN_PASSWORD; it is not served until
+ADMIN_PASSWORD is set. Running goca admin again adds the new entities.
+
+The admin panel is experimental: enable it with GOCA_EXPERIMENTAL=admin-ui or
+by adding admin-ui to experimental in .goca.yaml.
+
+Examples:
+  goca admin
+  goca admin Product Order`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireExperiment(ExperimentAdminUI); err != nil {
+			return err
+		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		force, _ := cmd.Flags().GetBool("force")
+
+		entities := args
+		if len(entities) == 0 {
+			entities = detectExistingFeatures()
+		}
+		var resources []adminResource
+		for _, entity := range entities {
+			res, err := loadAdminResource(entity)
+			if err != nil {
+				if len(args) > 0 {
+					return err
+				}
+				ui.Warning(err.Error())
+				continue
+			}
+			resources = append(resources, res)
+		}
+		if len(resources) == 0 {
+			return fmt.Errorf("no entity to manage: generate a feature first, e.g. goca feature Product --fields \"name:string,price:float64\"")
+		}
+
+		ui.Header("Goca Admin")
+		ui.Blank()
+		for _, res := range resources {
+			ui.KeyValue(res.Plural, fmt.Sprintf("/admin/%s (%s)", res.Path, strings.Join(res.Operations, ", ")))
+		}
+		ui.Blank()
+
+		sm := NewSafetyManager(dryRun, force, false)
+		if err := generateAdmin(resources, sm); err != nil {
+			return err
+		}
+		if dryRun {
+			sm.PrintSummary()
+			return nil
+		}
+
+		ui.Blank()
+		ui.Success("Admin panel generated")
+		ui.NextSteps([]string{
+			"Set the credentials: ADMIN_USER=admin ADMIN_PASSWORD=<secret>",
+			"Start the server: go run ./cmd/server",
+			"Open http://localhost:8080/admin/",
+		})
+		return nil
+	},
+}
+
+func init() {
+	adminCmd.Flags().Bool("dry-run", false, "Show the files that would be generated without writing them")
+	adminCmd.Flags().Bool("force", false, "Overwrite the existing admin files")
+}
+
+// adminResource is an entity of the admin panel.
+type adminResource struct {
+	Entity     string
+	Plural     string
+	Path       string
+	Fields     []adminField
+	Operations []string
+}
+
+// adminField is a field of an entity in the admin tables and forms.
+type adminField struct {
+	Name      string // JSON name
+	Label     string
+	Kind      string // a Kind constant of the admin package
+	Required  bool
+	Min       string
+	Max       string
+	MinLength int
+	MaxLength int
+	Options   []string
+}
+
+// loadAdminResource reads the fields of entity and the operations of its use
+// case. Entities whose use case cannot list them have no table.
+func loadAdminResource(entity string) (adminResource, error) {
+	entityFile, err := findEntityFile(entity)
+	if err != nil {
+		return adminResource{}, err
+	}
+	src, err := parseGoSource(entityFile)
+	if err != nil {
+		return adminResource{}, err
+	}
+	if !useCaseDeclared(entity) {
+		return adminResource{}, fmt.Errorf("%s has no use case: generate it with goca usecase %s", entity, entity)
+	}
+	operations := useCaseOperations(entity)
+	if !slices.Contains(operations, "list") {
+		return adminResource{}, fmt.Errorf("%sUseCase does not declare List%s, so %s has no admin table", entity, toPlural(entity), entity)
+	}
+
+	plural := toPlural(entity)
+	res := adminResource{Entity: entity, Plural: plural, Path: toKebabCase(plural)}
+	for _, op := range operations {
+		switch op {
+		case "create", "read", "update", "delete", "list":
+			res.Operations = append(res.Operations, op)
+		}
+	}
+	for _, field := range readEntityFields(src.file, entity) {
+		if isSystemField(field.Name) {
+			continue
+		}
+		if f, ok := adminFieldFor(field); ok {
+			res.Fields = append(res.Fields, f)
+		}
+	}
+	return res, nil
+}
+
+// useCaseDeclared reports whether internal/usecase declares the use case
+// interface of entity.
+func useCaseDeclared(entity string) bool {
+	return findTypeFile(filepath.Join(DirInternal, DirUseCase), entity+"UseCase") != ""
+}
+
+// adminFieldFor returns the admin field of an entity field, or false for the
+// types the forms do not edit, such as nested structs and slices.
+func adminFieldFor(field Field) (adminField, bool) {
+	tag := reflect.StructTag(strings.Trim(field.Tag, "`"))
+	name := strings.Split(tag.Get("json"), ",")[0]
+	if name == "-" {
+		return adminField{}, false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	f := adminField{Name: name, Label: adminLabel(field.Name)}
+
+	typ := strings.TrimPrefix(field.Type, "*")
+	switch {
+	case len(field.Enum) > 0:
+		f.Kind, f.Options = "KindSelect", field.Enum
+	case typ == "string":
+		f.Kind = "KindText"
+	case typ == "bool":
+		f.Kind = "KindCheckbox"
+	case typ == "time.Time":
+		f.Kind = "KindDateTime"
+	case typ == "float32" || typ == "float64":
+		f.Kind = "KindDecimal"
+	case strings.HasPrefix(typ, "int") || strings.HasPrefix(typ, "uint"):
+		f.Kind = "KindNumber"
+		if strings.HasPrefix(typ, "uint") {
+			f.Min = "0"
+		}
+	default:
+		return adminField{}, false
+	}
+
+	text := f.Kind == "KindText"
+	for _, rule := range strings.Split(tag.Get("validate"), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch key {
+		case "required":
+			f.Required = f.Kind != "KindCheckbox"
+		case "email":
+			f.Kind = "KindEmail"
+		case "url":
+			f.Kind = "KindURL"
+		case "oneof":
+			if text {
+				f.Kind, f.Options = "KindSelect", strings.Fields(value)
+			}
+		case "min", "max", "len", "gte", "lte", "gt", "lt":
+			applyAdminBound(&f, key, value, text)
+		}
+	}
+	// varchar(N) columns bound the length of texts without a max rule.
+	if text && f.MaxLength == 0 {
+		if m := regexp.MustCompile(`varchar\((\d+)\)`).FindStringSubmatch(tag.Get("gorm")); m != nil {
+			f.MaxLength, _ = strconv.Atoi(m[1])
+		}
+	}
+	return f, true
+}
+
+// applyAdminBound applies a min, max, len, gte, lte, gt or lt rule: lengths
+// for texts, values for numbers.
+func applyAdminBound(f *adminField, key, value string, text bool) {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return
+	}
+	if text || f.Kind == "KindEmail" || f.Kind == "KindURL" {
+		switch key {
+		case "min", "gte":
+			f.MinLength = int(n)
+		case "max", "lte":
+			f.MaxLength = int(n)
+		case "len":
+			f.MinLength, f.MaxLength = int(n), int(n)
+		}
+		return
+	}
+	if f.Kind != "KindNumber" && f.Kind != "KindDecimal" {
+		return
+	}
+	// gt and lt are exclusive; whole numbers step to the next value.
+	if f.Kind == "KindNumber" {
+		switch key {
+		case "gt":
+			key, n = "min", n+1
+		case "lt":
+			key, n = "max", n-1
+		}
+	}
+	bound := strconv.FormatFloat(n, 'f', -1, 64)
+	switch key {
+	case "min", "gte", "gt":
+		f.Min = bound
+	case "max", "lte", "lt":
+		f.Max = bound
+	}
+}
+
+// adminLabel returns the label of a field: CustomerID is "Customer ID".
+func adminLabel(name string) string {
+	words := nameWords(name)
+	for i, word := range words {
+		switch word {
+		case "id", "url", "api", "ip", "sku":
+			words[i] = strings.ToUpper(word)
+		default:
+			if i == 0 {
+				words[i] = strings.ToUpper(word[:1]) + word[1:]
+			}
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// generateAdmin writes the admin package, its templates and the resources,
+// adds AdminConfig to pkg/config and mounts the panel in main.go.
+func generateAdmin(resources []adminResource, sm *SafetyManager) error {
+	moduleName := getModuleName()
+	shared := []struct {
+		path, content string
+	}{
+		{filepath.Join(adminDir, "admin.go"), fmt.Sprintf(adminPackageTemplate, moduleName)},
+		{filepath.Join(adminDir, "templates", "layout.html"), adminLayoutTemplate},
+		{filepath.Join(adminDir, "templates", "index.html"), adminIndexTemplate},
+		{filepath.Join(adminDir, "templates", "list.html"), adminListTemplate},
+		{filepath.Join(adminDir, "templates", "form.html"), adminFormTemplate},
+	}
+	// Like response.go, the shared files are only rewritten with --force, so
+	// adding entities keeps their customizations.
+	for _, file := range shared {
+		if _, err := os.Stat(file.path); err == nil && !sm.Force {
+			continue
+		}
+		write := writeFile
+		if strings.HasSuffix(file.path, ".go") {
+			write = writeGoFile
+		}
+		if err := write(file.path, file.content, sm); err != nil {
+			return err
+		}
+	}
+
+	for _, res := range resources {
+		path := filepath.Join(adminDir, toSnakeCase(res.Entity)+"_admin.go")
+		if _, err := os.Stat(path); err == nil && !sm.Force {
+			ui.Dim(fmt.Sprintf("   Keeping %s (use --force to regenerate it)", path))
+			continue
+		}
+		if err := writeGoFile(path, buildAdminResource(res, moduleName), sm); err != nil {
+			return err
+		}
+	}
+
+	if sm.DryRun {
+		return nil
+	}
+	if err := ensureConfigSection("Admin", "AdminConfig", adminConfigDecl, adminConfigLoad, sm); err != nil {
+		ui.Warning(fmt.Sprintf("Could not add the admin settings to pkg/config: %v", err))
+	}
+	mountAdminPanel()
+	return nil
+}
+
+// buildAdminResource renders internal/handler/admin/<entity>_admin.go.
+func buildAdminResource(res adminResource, moduleName string) string {
+	var b strings.Builder
+	b.WriteString("package admin\n\n")
+	fmt.Fprintf(&b, "import \"%s/internal/usecase\"\n\n", moduleName)
+	fmt.Fprintf(&b, "// New%sResource manages %s in the admin panel with uc.\n", res.Entity, strings.ToLower(res.Plural))
+	fmt.Fprintf(&b, "func New%sResource(uc usecase.%sUseCase) Resource {\n", res.Entity, res.Entity)
+	b.WriteString("\treturn Resource{\n")
+	fmt.Fprintf(&b, "\t\tName:   %q,\n", res.Entity)
+	fmt.Fprintf(&b, "\t\tPlural: %q,\n", res.Plural)
+	fmt.Fprintf(&b, "\t\tPath:   %q,\n", res.Path)
+	b.WriteString("\t\tFields: []Field{\n")
+	for _, f := range res.Fields {
+		b.WriteString("\t\t\t" + adminFieldLiteral(f) + ",\n")
+	}
+	b.WriteString("\t\t},\n")
+
+	for _, op := range res.Operations {
+		switch op {
+		case "list":
+			b.WriteString("\t\tList: func() ([]any, error) {\n")
+			fmt.Fprintf(&b, "\t\t\tout, err := uc.List%s()\n", toPlural(res.Entity))
+			b.WriteString("\t\t\tif err != nil {\n\t\t\t\treturn nil, err\n\t\t\t}\n")
+			fmt.Fprintf(&b, "\t\t\treturn records(out.%s), nil\n", toPlural(res.Entity))
+			b.WriteString("\t\t},\n")
+		case "read":
+			b.WriteString("\t\tGet: func(id int) (any, error) {\n")
+			fmt.Fprintf(&b, "\t\t\treturn uc.Get%s(id)\n", res.Entity)
+			b.WriteString("\t\t},\n")
+		case "create":
+			b.WriteString("\t\tCreate: func(values map[string]any) error {\n")
+			fmt.Fprintf(&b, "\t\t\tvar input usecase.Create%sInput\n", res.Entity)
+			b.WriteString("\t\t\tif err := decodeInput(values, &input); err != nil {\n\t\t\t\treturn err\n\t\t\t}\n")
+			fmt.Fprintf(&b, "\t\t\t_, err := uc.Create%s(input)\n", res.Entity)
+			b.WriteString("\t\t\treturn err\n")
+			b.WriteString("\t\t},\n")
+		case "update":
+			b.WriteString("\t\tUpdate: func(id int, values map[string]any) error {\n")
+			fmt.Fprintf(&b, "\t\t\tvar input usecase.Update%sInput\n", res.Entity)
+			b.WriteString("\t\t\tif err := decodeInput(values, &input); err != nil {\n\t\t\t\treturn err\n\t\t\t}\n")
+			fmt.Fprintf(&b, "\t\t\treturn uc.Update%s(id, input)\n", res.Entity)
+			b.WriteString("\t\t},\n")
+		case "delete":
+			fmt.Fprintf(&b, "\t\tDelete: uc.Delete%s,\n", res.Entity)
+		}
+	}
+	b.WriteString("\t}\n}\n")
+	return b.String()
+}
+
+// adminFieldLiteral renders f as a Field literal of the admin package.
+func adminFieldLiteral(f adminField) string {
+	parts := []string{
+		fmt.Sprintf("Name: %q", f.Name),
+		fmt.Sprintf("Label: %q", f.Label),
+		"Kind: " + f.Kind,
+	}
+	if f.Required {
+		parts = append(parts, "Required: true")
+	}
+	if f.Min != "" {
+		parts = append(parts, fmt.Sprintf("Min: %q", f.Min))
+	}
+	if f.Max != "" {
+		parts = append(parts, fmt.Sprintf("Max: %q", f.Max))
+	}
+	if f.MinLength > 0 {
+		parts = append(parts, fmt.Sprintf("MinLength: %d", f.MinLength))
+	}
+	if f.MaxLength > 0 {
+		parts = append(parts, fmt.Sprintf("MaxLength: %d", f.MaxLength))
+	}
+	if len(f.Options) > 0 {
+		quoted := make([]string, len(f.Options))
+		for i, option := range f.Options {
+			quoted[i] = strconv.Quote(option)
+		}
+		parts = append(parts, "Options: []string{"+strings.Join(quoted, ", ")+"}")
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// adminResourceEntities returns the entities with a resource in the admin
+// package, sorted.
+func adminResourceEntities() []string {
+	files, _ := filepath.Glob(filepath.Join(adminDir, "*.go"))
+	var entities []string
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, m := range adminResourcePattern.FindAllStringSubmatch(string(content), -1) {
+			entities = append(entities, m[1])
+		}
+	}
+	sort.Strings(entities)
+	return entities
+}
+
+// mountAdminPanel mounts the panel with every resource of the admin package
+// in main.go, printing the manual step when main.go does not have the
+// expected layout.
+func mountAdminPanel() {
+	entities := adminResourceEntities()
+	mainPath, found := findMainGoPath()
+	if !found {
+		printAdminInstructions(entities)
+		return
+	}
+	defer lockSharedFile(mainPath)()
+	content, err := os.ReadFile(mainPath)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not read main.go: %v", err))
+		printAdminInstructions(entities)
+		return
+	}
+	updated, ok := wireAdminIntoMainGo(string(content), getImportPath(getModuleName()), entities)
+	if !ok {
+		ui.Warning("main.go has no DI container and routes marker to mount the admin panel with")
+		printAdminInstructions(entities)
+		return
+	}
+	if updated == string(content) {
+		return
+	}
+	if err := writeMainGoInPlace(mainPath, updated); err != nil {
+		ui.Warning(fmt.Sprintf("Could not mount the admin panel in main.go: %v", err))
+		printAdminInstructions(entities)
+		return
+	}
+	ui.Dim(fmt.Sprintf("   Admin panel mounted at /admin in %s", mainPath))
+}
+
+// wireAdminIntoMainGo mounts the admin panel with the resources of entities
+// before the routes marker of main.go, replacing the call of an earlier
+// goca admin. It reports false when main.go has no DI container or marker.
+func wireAdminIntoMainGo(content, importPath string, entities []string) (string, bool) {
+	if !strings.Contains(content, wiringRoutesMarker) || !strings.Contains(content, "container := ") {
+		return content, false
+	}
+	var mount strings.Builder
+	mount.WriteString("\t// Admin panel (/admin), served once ADMIN_PASSWORD is set\n")
+	mount.WriteString("\tadmin.Mount(router, cfg.Admin,\n")
+	for _, entity := range entities {
+		fmt.Fprintf(&mount, "\t\tadmin.New%sResource(container.%sUseCase()),\n", entity, entity)
+	}
+	mount.WriteString("\t)\n")
+
+	if loc := adminMountPattern.FindStringIndex(content); loc != nil {
+		return content[:loc[0]] + mount.String() + content[loc[1]:], true
+	}
+	updated := strings.Replace(content, wiringRoutesMarker, mount.String()+wiringRoutesMarker, 1)
+	return ensureMainGoImport(updated, importPath+"/internal/handler/admin"), true
+}
+
+// printAdminInstructions prints how to mount the admin panel by hand.
+func printAdminInstructions(entities []string) {
+	ui.Blank()
+	ui.Section("Manual admin integration")
+	ui.Println("Mount the panel on the router in main():")
+	ui.Dim("      admin.Mount(router, cfg.Admin,")
+	for _, entity := range entities {
+		ui.Dim(fmt.Sprintf("          admin.New%sResource(container.%sUseCase()),", entity, entity))
+	}
+	ui.Dim("      )")
+}
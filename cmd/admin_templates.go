@@ -0,0 +1,608 @@
+package cmd
+
+// adminPackageTemplate is internal/handler/admin/admin.go. Its only verb is
+// the module path of the project.
+const adminPackageTemplate = `// Package admin serves a server-rendered back-office over the use cases of
+// the project: a paginated table and create and edit forms per entity.
+package admin
+
+import (
+	"crypto/subtle"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"%s/pkg/config"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// The kinds of a Field select its form control and how its value is parsed.
+const (
+	KindText     = "text"
+	KindEmail    = "email"
+	KindURL      = "url"
+	KindNumber   = "number"
+	KindDecimal  = "decimal"
+	KindCheckbox = "checkbox"
+	KindDateTime = "datetime"
+	KindSelect   = "select"
+)
+
+// dateTimeLayout is the value format of datetime-local inputs.
+const dateTimeLayout = "2006-01-02T15:04"
+
+// Field is a field of a Resource shown in its table and forms.
+type Field struct {
+	// Name is the JSON name of the field in the entity and its DTOs.
+	Name     string
+	Label    string
+	Kind     string
+	Required bool
+	// Min and Max bound numbers, MinLength and MaxLength texts.
+	Min       string
+	Max       string
+	MinLength int
+	MaxLength int
+	// Options are the values of a KindSelect field.
+	Options []string
+}
+
+// Resource is an entity managed in the admin panel. Create, Update and
+// Delete are nil when its use case does not declare them.
+type Resource struct {
+	Name   string
+	Plural string
+	// Path is the URL segment of the resource under /admin.
+	Path   string
+	Fields []Field
+
+	List   func() ([]any, error)
+	Get    func(id int) (any, error)
+	Create func(values map[string]any) error
+	Update func(id int, values map[string]any) error
+	Delete func(id int) error
+}
+
+// CanCreate reports whether records can be created.
+func (r Resource) CanCreate() bool { return r.Create != nil }
+
+// CanUpdate reports whether records can be edited.
+func (r Resource) CanUpdate() bool { return r.Update != nil && r.Get != nil }
+
+// CanDelete reports whether records can be deleted.
+func (r Resource) CanDelete() bool { return r.Delete != nil }
+
+// Mount serves the admin panel under /admin on router, behind HTTP basic
+// authentication with the credentials of cfg. The panel is not served until
+// a password is set.
+func Mount(router *mux.Router, cfg config.AdminConfig, resources ...Resource) {
+	if cfg.Password == "" {
+		log.Printf("Admin panel disabled: set ADMIN_PASSWORD to serve /admin")
+		return
+	}
+	if cfg.PageSize < 1 {
+		cfg.PageSize = 20
+	}
+	h := &handler{cfg: cfg, resources: resources, pages: make(map[string]*template.Template)}
+	for _, name := range []string{"index.html", "list.html", "form.html"} {
+		h.pages[name] = template.Must(template.ParseFS(templateFS, "templates/layout.html", "templates/"+name))
+	}
+
+	router.Handle("/admin", http.RedirectHandler("/admin/", http.StatusMovedPermanently))
+	sub := router.PathPrefix("/admin").Subrouter()
+	sub.Use(h.authenticate, sameOrigin)
+	sub.HandleFunc("/", h.index).Methods(http.MethodGet)
+	sub.HandleFunc("/{resource}", h.list).Methods(http.MethodGet)
+	sub.HandleFunc("/{resource}", h.create).Methods(http.MethodPost)
+	sub.HandleFunc("/{resource}/new", h.newForm).Methods(http.MethodGet)
+	sub.HandleFunc("/{resource}/{id:[0-9]+}", h.editForm).Methods(http.MethodGet)
+	sub.HandleFunc("/{resource}/{id:[0-9]+}", h.update).Methods(http.MethodPost)
+	sub.HandleFunc("/{resource}/{id:[0-9]+}/delete", h.delete).Methods(http.MethodPost)
+}
+
+type handler struct {
+	cfg       config.AdminConfig
+	resources []Resource
+	pages     map[string]*template.Template
+}
+
+// page is the data of every template.
+type page struct {
+	Title     string
+	Resources []Resource
+	Resource  Resource
+	Error     string
+
+	// Tables
+	Rows     []row
+	Columns  int
+	Total    int
+	Page     int
+	Pages    int
+	PrevPage int
+	NextPage int
+
+	// Forms
+	Action string
+	Inputs []input
+}
+
+// row is a record of a table.
+type row struct {
+	ID    string
+	Cells []string
+}
+
+// input is a form control.
+type input struct {
+	Field
+	Type    string
+	Step    string
+	Value   string
+	Checked bool
+	Choices []choice
+}
+
+// choice is an option of a select.
+type choice struct {
+	Value    string
+	Selected bool
+}
+
+func (h *handler) index(w http.ResponseWriter, r *http.Request) {
+	h.render(w, http.StatusOK, "index.html", page{Title: "Admin"})
+}
+
+func (h *handler) list(w http.ResponseWriter, r *http.Request) {
+	res, ok := h.resource(w, r)
+	if !ok {
+		return
+	}
+	items, err := res.List()
+	if err != nil {
+		h.fail(w, err)
+		return
+	}
+
+	pages := (len(items) + h.cfg.PageSize - 1) / h.cfg.PageSize
+	if pages < 1 {
+		pages = 1
+	}
+	current, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	current = min(max(current, 1), pages)
+	start := (current - 1) * h.cfg.PageSize
+	end := min(start+h.cfg.PageSize, len(items))
+
+	p := page{Title: res.Plural, Resource: res, Columns: len(res.Fields) + 2, Total: len(items), Page: current, Pages: pages}
+	if current > 1 {
+		p.PrevPage = current - 1
+	}
+	if current < pages {
+		p.NextPage = current + 1
+	}
+	for _, item := range items[start:end] {
+		record, err := toRecord(item)
+		if err != nil {
+			h.fail(w, err)
+			return
+		}
+		cells := make([]string, len(res.Fields))
+		for i, field := range res.Fields {
+			cells[i] = display(field, record[field.Name])
+		}
+		p.Rows = append(p.Rows, row{ID: display(Field{}, record["id"]), Cells: cells})
+	}
+	h.render(w, http.StatusOK, "list.html", p)
+}
+
+func (h *handler) newForm(w http.ResponseWriter, r *http.Request) {
+	res, ok := h.resource(w, r)
+	if !ok {
+		return
+	}
+	if !res.CanCreate() {
+		http.NotFound(w, r)
+		return
+	}
+	h.renderForm(w, http.StatusOK, res, "New "+res.Name, "/admin/"+res.Path, map[string]string{}, "")
+}
+
+func (h *handler) create(w http.ResponseWriter, r *http.Request) {
+	res, ok := h.resource(w, r)
+	if !ok {
+		return
+	}
+	if !res.CanCreate() {
+		http.NotFound(w, r)
+		return
+	}
+	values, form, err := parseForm(res, r)
+	if err == nil {
+		err = res.Create(values)
+	}
+	if err != nil {
+		h.renderForm(w, http.StatusUnprocessableEntity, res, "New "+res.Name, "/admin/"+res.Path, form, err.Error())
+		return
+	}
+	http.Redirect(w, r, "/admin/"+res.Path, http.StatusSeeOther)
+}
+
+func (h *handler) editForm(w http.ResponseWriter, r *http.Request) {
+	res, ok := h.resource(w, r)
+	if !ok {
+		return
+	}
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	if !res.CanUpdate() {
+		http.NotFound(w, r)
+		return
+	}
+	item, err := res.Get(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	record, err := toRecord(item)
+	if err != nil {
+		h.fail(w, err)
+		return
+	}
+	form := make(map[string]string, len(res.Fields))
+	for _, field := range res.Fields {
+		form[field.Name] = formValue(field, record[field.Name])
+	}
+	h.renderForm(w, http.StatusOK, res, fmt.Sprintf("Edit %%s %%d", res.Name, id), fmt.Sprintf("/admin/%%s/%%d", res.Path, id), form, "")
+}
+
+func (h *handler) update(w http.ResponseWriter, r *http.Request) {
+	res, ok := h.resource(w, r)
+	if !ok {
+		return
+	}
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	if !res.CanUpdate() {
+		http.NotFound(w, r)
+		return
+	}
+	values, form, err := parseForm(res, r)
+	if err == nil {
+		err = res.Update(id, values)
+	}
+	if err != nil {
+		h.renderForm(w, http.StatusUnprocessableEntity, res, fmt.Sprintf("Edit %%s %%d", res.Name, id), fmt.Sprintf("/admin/%%s/%%d", res.Path, id), form, err.Error())
+		return
+	}
+	http.Redirect(w, r, "/admin/"+res.Path, http.StatusSeeOther)
+}
+
+func (h *handler) delete(w http.ResponseWriter, r *http.Request) {
+	res, ok := h.resource(w, r)
+	if !ok {
+		return
+	}
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	if !res.CanDelete() {
+		http.NotFound(w, r)
+		return
+	}
+	if err := res.Delete(id); err != nil {
+		h.fail(w, err)
+		return
+	}
+	http.Redirect(w, r, "/admin/"+res.Path, http.StatusSeeOther)
+}
+
+// resource returns the resource of the request, or answers 404.
+func (h *handler) resource(w http.ResponseWriter, r *http.Request) (Resource, bool) {
+	path := mux.Vars(r)["resource"]
+	for _, res := range h.resources {
+		if res.Path == path {
+			return res, true
+		}
+	}
+	http.NotFound(w, r)
+	return Resource{}, false
+}
+
+func (h *handler) renderForm(w http.ResponseWriter, status int, res Resource, title, action string, form map[string]string, message string) {
+	p := page{Title: title, Resource: res, Action: action, Error: message}
+	for _, field := range res.Fields {
+		in := input{Field: field, Type: field.Kind, Value: form[field.Name]}
+		switch field.Kind {
+		case KindNumber:
+			in.Step = "1"
+		case KindDecimal:
+			in.Type, in.Step = "number", "any"
+		case KindDateTime:
+			in.Type = "datetime-local"
+		case KindCheckbox:
+			in.Checked = in.Value == "true"
+		case KindSelect:
+			for _, option := range field.Options {
+				in.Choices = append(in.Choices, choice{Value: option, Selected: option == in.Value})
+			}
+		}
+		p.Inputs = append(p.Inputs, in)
+	}
+	h.render(w, status, "form.html", p)
+}
+
+func (h *handler) render(w http.ResponseWriter, status int, name string, p page) {
+	p.Resources = h.resources
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := h.pages[name].ExecuteTemplate(w, "layout.html", p); err != nil {
+		log.Printf("admin: rendering %%s: %%v", name, err)
+	}
+}
+
+func (h *handler) fail(w http.ResponseWriter, err error) {
+	log.Printf("admin: %%v", err)
+	http.Error(w, "Internal server error", http.StatusInternalServerError)
+}
+
+// authenticate requires the basic credentials of the configuration.
+func (h *handler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok ||
+			subtle.ConstantTimeCompare([]byte(user), []byte(h.cfg.Username)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(password), []byte(h.cfg.Password)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Basic realm=\"admin\", charset=\"UTF-8\"")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// sameOrigin rejects forms posted from other sites: browsers send the basic
+// credentials with them too.
+func sameOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			cross := r.Header.Get("Sec-Fetch-Site") == "cross-site"
+			if origin := r.Header.Get("Origin"); origin != "" {
+				u, err := url.Parse(origin)
+				cross = cross || err != nil || u.Host != r.Host
+			}
+			if cross {
+				http.Error(w, "Cross-origin request rejected", http.StatusForbidden)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// parseForm returns the values of the posted form by field name, typed for
+// decodeInput, and the raw values to render the form again with. Empty
+// numbers and dates are left out, so updates keep their current value.
+func parseForm(res Resource, r *http.Request) (map[string]any, map[string]string, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, nil, err
+	}
+	values := make(map[string]any, len(res.Fields))
+	form := make(map[string]string, len(res.Fields))
+	var errs []error
+	for _, field := range res.Fields {
+		raw := strings.TrimSpace(r.PostFormValue(field.Name))
+		if field.Kind == KindCheckbox {
+			values[field.Name] = raw != ""
+			form[field.Name] = strconv.FormatBool(raw != "")
+			continue
+		}
+		form[field.Name] = raw
+
+		switch {
+		case raw == "" && (field.Kind == KindNumber || field.Kind == KindDecimal || field.Kind == KindDateTime):
+		case field.Kind == KindNumber:
+			n, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%%s: %%q is not a whole number", field.Label, raw))
+			}
+			values[field.Name] = n
+		case field.Kind == KindDecimal:
+			n, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%%s: %%q is not a number", field.Label, raw))
+			}
+			values[field.Name] = n
+		case field.Kind == KindDateTime:
+			t, err := time.ParseInLocation(dateTimeLayout, raw, time.Local)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%%s: %%q is not a date and time", field.Label, raw))
+			}
+			values[field.Name] = t.Format(time.RFC3339)
+		default:
+			values[field.Name] = raw
+		}
+	}
+	return values, form, errors.Join(errs...)
+}
+
+// decodeInput fills the DTO input points to with values, by JSON name.
+func decodeInput(values map[string]any, input any) error {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, input)
+}
+
+// records converts the items of a list output.
+func records[T any](items []T) []any {
+	out := make([]any, len(items))
+	for i := range items {
+		out[i] = items[i]
+	}
+	return out
+}
+
+// toRecord returns the fields of an entity by JSON name.
+func toRecord(item any) (map[string]any, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	var record map[string]any
+	return record, json.Unmarshal(data, &record)
+}
+
+// display formats a value of a record for a table.
+func display(field Field, value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case bool:
+		if v {
+			return "Yes"
+		}
+		return "No"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		if field.Kind == KindDateTime {
+			if t, err := time.Parse(time.RFC3339, v); err == nil {
+				return t.Local().Format("2006-01-02 15:04")
+			}
+		}
+		return v
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// formValue formats a value of a record for a form control.
+func formValue(field Field, value any) string {
+	switch v := value.(type) {
+	case bool:
+		return strconv.FormatBool(v)
+	case string:
+		if field.Kind == KindDateTime {
+			if t, err := time.Parse(time.RFC3339, v); err == nil {
+				return t.Local().Format(dateTimeLayout)
+			}
+		}
+		return v
+	default:
+		return display(field, v)
+	}
+}
+`
+
+// adminLayoutTemplate is internal/handler/admin/templates/layout.html.
+const adminLayoutTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} · Admin</title>
+<style>
+  body { margin: 0; font: 15px/1.5 system-ui, sans-serif; color: #1f2933; display: flex; min-height: 100vh; }
+  nav { width: 220px; background: #1f2933; padding: 1rem; }
+  nav a { display: block; color: #cbd2d9; text-decoration: none; padding: .3rem .5rem; border-radius: 4px; }
+  nav a:hover, nav a.active { background: #323f4b; color: #fff; }
+  nav .brand { color: #fff; font-weight: 600; margin-bottom: 1rem; }
+  main { flex: 1; padding: 1.5rem 2rem; }
+  .bar { display: flex; align-items: center; justify-content: space-between; }
+  table { border-collapse: collapse; width: 100%; margin: 1rem 0; }
+  th, td { text-align: left; padding: .5rem; border-bottom: 1px solid #e4e7eb; }
+  th { background: #f5f7fa; }
+  td.actions { white-space: nowrap; text-align: right; }
+  td.actions form { display: inline; }
+  a.button, button { background: #2563eb; color: #fff; border: 0; border-radius: 4px; padding: .45rem .9rem; font: inherit; cursor: pointer; text-decoration: none; }
+  button.link { background: none; color: #b91c1c; padding: 0 0 0 .75rem; }
+  label { display: block; margin: .75rem 0; font-weight: 500; }
+  input:not([type=checkbox]), select { display: block; width: 100%; max-width: 420px; padding: .4rem; margin-top: .25rem; font: inherit; }
+  .error { background: #fde8e8; color: #9b1c1c; padding: .5rem .75rem; border-radius: 4px; white-space: pre-line; }
+  .pager { display: flex; gap: 1rem; align-items: center; }
+</style>
+</head>
+<body>
+<nav>
+  <a class="brand" href="/admin/">Admin</a>
+  {{range .Resources}}<a href="/admin/{{.Path}}"{{if eq .Path $.Resource.Path}} class="active"{{end}}>{{.Plural}}</a>
+  {{end}}
+</nav>
+<main>
+{{template "content" .}}
+</main>
+</body>
+</html>
+`
+
+// adminIndexTemplate is internal/handler/admin/templates/index.html.
+const adminIndexTemplate = `{{define "content"}}
+<h1>Admin</h1>
+<table>
+  <thead><tr><th>Entity</th><th></th></tr></thead>
+  <tbody>
+  {{range .Resources}}<tr><td><a href="/admin/{{.Path}}">{{.Plural}}</a></td><td class="actions">{{if .CanCreate}}<a href="/admin/{{.Path}}/new">New {{.Name}}</a>{{end}}</td></tr>
+  {{end}}
+  </tbody>
+</table>
+{{end}}
+`
+
+// adminListTemplate is internal/handler/admin/templates/list.html.
+const adminListTemplate = `{{define "content"}}
+<div class="bar">
+  <h1>{{.Resource.Plural}}</h1>
+  {{if .Resource.CanCreate}}<a class="button" href="/admin/{{.Resource.Path}}/new">New {{.Resource.Name}}</a>{{end}}
+</div>
+<table>
+  <thead><tr><th>ID</th>{{range .Resource.Fields}}<th>{{.Label}}</th>{{end}}<th></th></tr></thead>
+  <tbody>
+  {{range .Rows}}<tr>
+    <td>{{.ID}}</td>{{range .Cells}}<td>{{.}}</td>{{end}}
+    <td class="actions">
+      {{if $.Resource.CanUpdate}}<a href="/admin/{{$.Resource.Path}}/{{.ID}}">Edit</a>{{end}}
+      {{if $.Resource.CanDelete}}<form method="post" action="/admin/{{$.Resource.Path}}/{{.ID}}/delete" onsubmit="return confirm('Delete {{$.Resource.Name}} {{.ID}}?')"><button class="link">Delete</button></form>{{end}}
+    </td>
+  </tr>
+  {{else}}<tr><td colspan="{{.Columns}}">No {{.Resource.Plural}} yet.</td></tr>
+  {{end}}
+  </tbody>
+</table>
+<div class="pager">
+  {{if .PrevPage}}<a href="?page={{.PrevPage}}">&larr; Previous</a>{{end}}
+  <span>Page {{.Page}} of {{.Pages}} · {{.Total}} total</span>
+  {{if .NextPage}}<a href="?page={{.NextPage}}">Next &rarr;</a>{{end}}
+</div>
+{{end}}
+`
+
+// adminFormTemplate is internal/handler/admin/templates/form.html.
+const adminFormTemplate = `{{define "content"}}
+<h1>{{.Title}}</h1>
+{{with .Error}}<p class="error">{{.}}</p>{{end}}
+<form method="post" action="{{.Action}}">
+  {{range .Inputs}}<label>{{.Label}}{{if .Required}} *{{end}}
+    {{if eq .Kind "select"}}<select name="{{.Name}}"{{if .Required}} required{{end}}>
+      {{if not .Required}}<option value=""></option>{{end}}
+      {{range .Choices}}<option value="{{.Value}}"{{if .Selected}} selected{{end}}>{{.Value}}</option>{{end}}
+    </select>
+    {{else if eq .Kind "checkbox"}}<input type="checkbox" name="{{.Name}}"{{if .Checked}} checked{{end}}>
+    {{else}}<input type="{{.Type}}" name="{{.Name}}" value="{{.Value}}"{{if .Step}} step="{{.Step}}"{{end}}{{if .Min}} min="{{.Min}}"{{end}}{{if .Max}} max="{{.Max}}"{{end}}{{if .MinLength}} minlength="{{.MinLength}}"{{end}}{{if .MaxLength}} maxlength="{{.MaxLength}}"{{end}}{{if .Required}} required{{end}}>
+    {{end}}
+  </label>
+  {{end}}
+  <button type="submit">Save</button>
+  <a href="/admin/{{.Resource.Path}}">Cancel</a>
+</form>
+{{end}}
+`
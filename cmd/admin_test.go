@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminFieldFor(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		field Field
+		want  adminField
+		ok    bool
+	}{
+		{
+			field: Field{Name: "Title", Type: "string", Tag: "`json:\"title\" gorm:\"type:varchar(120);not null\" validate:\"required\"`"},
+			want:  adminField{Name: "title", Label: "Title", Kind: "KindText", Required: true, MaxLength: 120},
+			ok:    true,
+		},
+		{
+			field: Field{Name: "CustomerID", Type: "uint", Tag: "`json:\"customer_id\" validate:\"required,gt=0\"`"},
+			want:  adminField{Name: "customer_id", Label: "Customer ID", Kind: "KindNumber", Required: true, Min: "1"},
+			ok:    true,
+		},
+		{
+			field: Field{Name: "Price", Type: "float64", Tag: "`json:\"price\" validate:\"gte=0,lte=999.99\"`"},
+			want:  adminField{Name: "price", Label: "Price", Kind: "KindDecimal", Min: "0", Max: "999.99"},
+			ok:    true,
+		},
+		{
+			field: Field{Name: "Email", Type: "string", Tag: "`json:\"email\" validate:\"required,email,max=80\"`"},
+			want:  adminField{Name: "email", Label: "Email", Kind: "KindEmail", Required: true, MaxLength: 80},
+			ok:    true,
+		},
+		{
+			field: Field{Name: "Status", Type: "string", Tag: "`json:\"status\"`", Enum: []string{"draft", "published"}},
+			want:  adminField{Name: "status", Label: "Status", Kind: "KindSelect", Options: []string{"draft", "published"}},
+			ok:    true,
+		},
+		{
+			field: Field{Name: "Size", Type: "string", Tag: "`json:\"size\" validate:\"oneof=s m l\"`"},
+			want:  adminField{Name: "size", Label: "Size", Kind: "KindSelect", Options: []string{"s", "m", "l"}},
+			ok:    true,
+		},
+		{
+			field: Field{Name: "PublishedAt", Type: "*time.Time", Tag: "`json:\"published_at\"`"},
+			want:  adminField{Name: "published_at", Label: "Published at", Kind: "KindDateTime"},
+			ok:    true,
+		},
+		{field: Field{Name: "Address", Type: "Address", Tag: "`json:\"address\" gorm:\"embedded\"`"}},
+		{field: Field{Name: "Tags", Type: "[]string", Tag: "`json:\"tags\"`"}},
+		{field: Field{Name: "Secret", Type: "string", Tag: "`json:\"-\"`"}},
+	}
+	for _, tt := range tests {
+		got, ok := adminFieldFor(tt.field)
+		assert.Equal(t, tt.ok, ok, tt.field.Name)
+		if tt.ok {
+			assert.Equal(t, tt.want, got, tt.field.Name)
+		}
+	}
+}
+
+func TestAdminCommand_RequiresExperiment(t *testing.T) {
+	defer setupDiscardUI(t)()
+	chdirTemp(t)
+	t.Setenv(experimentalEnv, "")
+
+	err := adminCmd.RunE(adminCmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), experimentalEnv+"="+ExperimentAdminUI)
+	assert.NoDirExists(t, adminDir)
+
+	e, ok := lookupExperiment(ExperimentAdminUI)
+	require.True(t, ok)
+	assert.Equal(t, StageAlpha, e.Stage)
+}
+
+func TestGenerateAdmin(t *testing.T) {
+	defer setupDiscardUI(t)()
+	chdirTemp(t)
+	sm := NewSafetyManager(false, false, false)
+	generateCompleteFeature("Product", "name:string,price:float64,active:bool", "postgres", "http", "", false, false, false, false, false, false, false, false, "lowercase", DTOStyleNested, allFeatureLayers(), sm)
+	writeTestFile(t, ".", filepath.Join("pkg", "config", "config.go"), legacyConfig)
+
+	res, err := loadAdminResource("Product")
+	require.NoError(t, err)
+	assert.Equal(t, "products", res.Path)
+	assert.Equal(t, []string{"create", "read", "update", "delete", "list"}, res.Operations)
+	require.NoError(t, generateAdmin([]adminResource{res}, sm))
+
+	resource := readParsedGo(t, filepath.Join(adminDir, "product_admin.go"))
+	assert.Contains(t, resource, "func NewProductResource(uc usecase.ProductUseCase) Resource {")
+	assert.Contains(t, resource, `{Name: "price", Label: "Price", Kind: KindDecimal`)
+	assert.Contains(t, resource, `{Name: "active", Label: "Active", Kind: KindCheckbox}`)
+	assert.Contains(t, resource, "return records(out.Products), nil")
+	assert.Contains(t, resource, "var input usecase.UpdateProductInput")
+	assert.Contains(t, resource, "Delete: uc.DeleteProduct,")
+
+	admin := readParsedGo(t, filepath.Join(adminDir, "admin.go"))
+	assert.Contains(t, admin, `"testproject/pkg/config"`)
+	assert.Contains(t, admin, "//go:embed templates/*.html")
+	assert.Contains(t, admin, "sub.Use(h.authenticate, sameOrigin)")
+	assert.FileExists(t, filepath.Join(adminDir, "templates", "form.html"))
+
+	config := readParsedGo(t, filepath.Join("pkg", "config", "config.go"))
+	assert.Contains(t, config, `Password: getEnv("ADMIN_PASSWORD", ""),`)
+	assert.Equal(t, []string{"Product"}, adminResourceEntities())
+
+	_, err = loadAdminResource("Invoice")
+	assert.Error(t, err)
+}
+
+func TestWireAdminIntoMainGo(t *testing.T) {
+	t.Parallel()
+	main := `package main
+
+import (
+	"net/http"
+)
+
+func main() {
+	container := di.NewContainer(db)
+	apphttp.SetupProductRoutes(apiRouter, container.ProductUseCase()) // product routes
+` + wiringRoutesMarker + `
+}
+`
+	updated, ok := wireAdminIntoMainGo(main, "testproject", []string{"Product"})
+	require.True(t, ok)
+	assert.Contains(t, updated, `"testproject/internal/handler/admin"`)
+	assert.Contains(t, updated, "\tadmin.Mount(router, cfg.Admin,\n\t\tadmin.NewProductResource(container.ProductUseCase()),\n\t)\n"+wiringRoutesMarker)
+
+	// Running it again replaces the call with every resource.
+	again, ok := wireAdminIntoMainGo(updated, "testproject", []string{"Order", "Product"})
+	require.True(t, ok)
+	assert.Equal(t, 1, strings.Count(again, "admin.Mount("))
+	assert.Contains(t, again, "admin.NewOrderResource(container.OrderUseCase()),\n\t\tadmin.NewProductResource(")
+
+	_, ok = wireAdminIntoMainGo("package main\n\nfunc main() {}\n", "testproject", nil)
+	assert.False(t, ok)
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
@@ -24,6 +25,7 @@ func runArchitectureChecks() []analyzeResult {
 		checkRepositoryImplsExist(),
 		checkDIContainerExists(),
 		checkHandlerDoesNotImportRepository(),
+		checkSingleRepositoryContract(),
 	}
 }
 
@@ -293,6 +295,39 @@ func checkHandlerDoesNotImportRepository() analyzeResult {
 	}
 }
 
+// repositoryInterfaceDecl matches a declaration of an <Entity>Repository
+// interface.
+var repositoryInterfaceDecl = regexp.MustCompile(`(?m)^type\s+(\w+Repository)\s+interface\b`)
+
+// checkSingleRepositoryContract ensures repository interfaces are declared
+// only in internal/repository, which use cases import, so a second copy
+// elsewhere cannot drift from the one the implementations satisfy.
+func checkSingleRepositoryContract() analyzeResult {
+	files, _ := analyzeGoFiles("internal", true)
+	repoDir := filepath.Join("internal", "repository") + string(filepath.Separator)
+	for _, f := range files {
+		if strings.HasPrefix(f, repoDir) {
+			continue
+		}
+		if m := repositoryInterfaceDecl.FindStringSubmatch(analyzeReadFile(f)); m != nil {
+			return analyzeResult{
+				category:   "Architecture",
+				rule:       "repo-contract-single",
+				status:     "⚠",
+				file:       f,
+				message:    fmt.Sprintf("%s is declared outside internal/repository", m[1]),
+				suggestion: "Delete this copy and depend on repository." + m[1] + "; goca interfaces --repository writes it to internal/repository/interfaces.go",
+			}
+		}
+	}
+	return analyzeResult{
+		category: "Architecture",
+		rule:     "repo-contract-single",
+		status:   "✓",
+		message:  "Repository interfaces are declared once, in internal/repository",
+	}
+}
+
 // ═══════════════════════════════════════════════════════════════════════════════
 // QUALITY CHECKS
 // ═══════════════════════════════════════════════════════════════════════════════
@@ -741,8 +776,7 @@ func checkGoModHasCorrectModule() analyzeResult {
 	for _, line := range strings.Split(content, "\n") {
 		line = strings.TrimSpace(line)
 		if strings.HasPrefix(line, "module ") {
-			mod := strings.TrimPrefix(line, "module ")
-			mod = strings.TrimSpace(mod)
+			mod := parseModulePath([]byte(content))
 			if mod == "" {
 				return analyzeResult{
 					category:   "Standards",
@@ -1137,7 +1171,7 @@ func findForbiddenImports(filePath, moduleName string, forbiddenPrefixes []strin
 	var found []string
 	for _, imp := range node.Imports {
 		path := strings.Trim(imp.Path.Value, `"`)
-		if strings.HasPrefix(path, moduleName) {
+		if isOwnImport(path, moduleName) {
 			continue // own module is allowed
 		}
 		for _, prefix := range forbiddenPrefixes {
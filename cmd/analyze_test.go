@@ -249,6 +249,18 @@ func TestCheckHandlerDoesNotImportRepository_Pass(t *testing.T) {
 	assert.Equal(t, "✓", r.status, r.message)
 }
 
+func TestCheckSingleRepositoryContract(t *testing.T) {
+	dir := setupAnalyzeProject(t)
+	r := checkSingleRepositoryContract()
+	assert.Equal(t, "✓", r.status, r.message)
+
+	writeTestFile(t, dir, "internal/usecase/interfaces.go", "package usecase\n\ntype UserRepository interface {\n\tFindAll() error\n}\n")
+	r = checkSingleRepositoryContract()
+	assert.Equal(t, "⚠", r.status)
+	assert.Equal(t, filepath.Join("internal", "usecase", "interfaces.go"), r.file)
+	assert.Contains(t, r.message, "UserRepository")
+}
+
 func TestCheckDIContainerExists_Pass(t *testing.T) {
 	setupAnalyzeProject(t)
 	r := checkDIContainerExists()
@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// defaultAPIVersion is the version features are served under unless
+// api.versioning says otherwise. Its routes hang off the apiRouter of main.go.
+const defaultAPIVersion = "v1"
+
+// apiVersionPattern matches the API versions goca generates routes for.
+var apiVersionPattern = regexp.MustCompile(`^v[1-9][0-9]*$`)
+
+// deprecationDateLayout is the layout of the since and sunset dates of
+// api.versioning.deprecated.
+const deprecationDateLayout = "2006-01-02"
+
+// validateAPIVersion reports whether version names an API version, e.g. v2.
+func validateAPIVersion(version string) error {
+	if !apiVersionPattern.MatchString(version) {
+		return fmt.Errorf("invalid API version %q: expected v1, v2, ...", version) //nolint:err113
+	}
+	return nil
+}
+
+// projectVersioning returns the api.versioning section of the project.
+func projectVersioning() VersioningConfig {
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	return ci.GetAPIConfig().Versioning
+}
+
+// projectAPIVersion returns the API version entity's HTTP routes are
+// generated for.
+func projectAPIVersion(entity string) string {
+	return resolveAPIVersion(projectVersioning(), entity)
+}
+
+// featureAPIVersion returns the version chosen with --api-version, else the
+// one of the project configuration.
+func featureAPIVersion(entity, flag string) string {
+	if flag != "" {
+		return flag
+	}
+	return projectAPIVersion(entity)
+}
+
+// resolveAPIVersion returns the version api.versioning.features gives
+// entity, else the default version.
+func resolveAPIVersion(cfg VersioningConfig, entity string) string {
+	for _, key := range []string{entity, strings.ToLower(entity), toSnakeCase(entity)} {
+		if version, ok := cfg.Features[key]; ok && apiVersionPattern.MatchString(version) {
+			return version
+		}
+	}
+	if apiVersionPattern.MatchString(cfg.Default) {
+		return cfg.Default
+	}
+	return defaultAPIVersion
+}
+
+// apiVersionSuffix returns what the names of a version's handler and route
+// setup carry after the entity, e.g. V2 in ProductV2Handler. v1 names carry
+// none, so the handlers generated before versioning keep theirs.
+func apiVersionSuffix(version string) string {
+	if version == "" || version == defaultAPIVersion {
+		return ""
+	}
+	return "V" + strings.TrimPrefix(version, "v")
+}
+
+// apiVersionPrefix returns the path the routes of a version are mounted
+// under, e.g. /api/v2.
+func apiVersionPrefix(version string) string {
+	if version == "" {
+		version = defaultAPIVersion
+	}
+	return "/api/" + version
+}
+
+// apiVersionRouter returns the main.go variable of a version's subrouter.
+func apiVersionRouter(version string) string {
+	return "api" + apiVersionSuffix(version) + "Router"
+}
+
+// apiRouterDecl returns the main.go line declaring a version's subrouter.
+func apiRouterDecl(version string) string {
+	return fmt.Sprintf("\t%s := router.PathPrefix(%q).Subrouter()\n", apiVersionRouter(version), apiVersionPrefix(version))
+}
+
+// ensureVersionRouter declares the subrouter of version in main.go after
+// the one of the closest earlier version, so the routers stay in order.
+// The v1 router comes from ensureContainerScaffold.
+func ensureVersionRouter(content, version string) string {
+	decl := apiRouterDecl(version)
+	if apiVersionSuffix(version) == "" || strings.Contains(content, decl) {
+		return content
+	}
+	n, _ := strconv.Atoi(strings.TrimPrefix(version, "v"))
+	for earlier := n - 1; earlier >= 1; earlier-- {
+		anchor := apiRouterDecl("v" + strconv.Itoa(earlier))
+		if idx := strings.Index(content, anchor); idx != -1 {
+			at := idx + len(anchor)
+			// Keep the deprecation of the earlier router next to it.
+			if next := content[at:]; strings.HasPrefix(next, "\t"+apiVersionRouter("v"+strconv.Itoa(earlier))+".Use(apphttp.Deprecated(") {
+				at += strings.Index(next, "\n") + 1
+			}
+			return content[:at] + fmt.Sprintf("\t// API %s routes\n", version) + decl + content[at:]
+		}
+	}
+	return content
+}
+
+// applyDeprecations registers the Deprecated middleware of
+// internal/handler/http/deprecation.go on the subrouter of every deprecated
+// version main.go declares, and updates the registrations whose deprecation
+// changed in the configuration.
+func applyDeprecations(content string, cfg VersioningConfig) string {
+	for _, version := range slices.Sorted(maps.Keys(cfg.Deprecated)) {
+		decl := apiRouterDecl(version)
+		idx := strings.Index(content, decl)
+		if idx == -1 || !apiVersionPattern.MatchString(version) {
+			continue
+		}
+		use := deprecationRegistration(version, cfg.Deprecated[version])
+		at := idx + len(decl)
+		prefix := "\t" + apiVersionRouter(version) + ".Use(apphttp.Deprecated("
+		if next := content[at:]; strings.HasPrefix(next, prefix) {
+			end := at + strings.Index(next, "\n") + 1
+			content = content[:at] + use + content[end:]
+			continue
+		}
+		content = content[:at] + use + content[at:]
+	}
+	return content
+}
+
+// deprecationRegistration returns the main.go line deprecating the
+// subrouter of version.
+func deprecationRegistration(version string, d DeprecationConfig) string {
+	var fields []string
+	if d.Since != "" {
+		fields = append(fields, fmt.Sprintf("Since: %q", d.Since))
+	}
+	if d.Sunset != "" {
+		fields = append(fields, fmt.Sprintf("Sunset: %q", d.Sunset))
+	}
+	if d.Link != "" {
+		fields = append(fields, fmt.Sprintf("Link: %q", d.Link))
+	}
+	return fmt.Sprintf("\t%s.Use(apphttp.Deprecated(apphttp.Deprecation{%s}))\n", apiVersionRouter(version), strings.Join(fields, ", "))
+}
+
+// registerVersionedRoutes wires the routes of a handler generated for a
+// version after v1 into main.go, next to the routes of earlier versions.
+func registerVersionedRoutes(entity, version string, sm ...*SafetyManager) {
+	if len(sm) > 0 && sm[0] != nil && sm[0].DryRun {
+		return
+	}
+	routeCall := fmt.Sprintf("apphttp.Setup%s%sRoutes(%s, container.%sUseCase())", entity, apiVersionSuffix(version), apiVersionRouter(version), entity)
+	mainPath, found := findMainGoPath()
+	if !found {
+		ui.Warning("main.go not found, register the routes manually:")
+		ui.Dim("      " + strings.TrimSpace(apiRouterDecl(version)))
+		ui.Dim("      " + routeCall)
+		return
+	}
+	defer lockSharedFile(mainPath)()
+	content, err := os.ReadFile(mainPath)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not read main.go: %v", err))
+		return
+	}
+	if strings.Contains(string(content), routeCall) {
+		ui.Dim(fmt.Sprintf("   %s routes already registered", version))
+		return
+	}
+	if err := wireFeatureIntoMainGo(mainPath, entity, getModuleName(), version, string(content)); err != nil {
+		ui.Warning(fmt.Sprintf("Could not register the %s routes in main.go: %v", version, err))
+		return
+	}
+	ui.Dim(fmt.Sprintf("   %s%s registered in %s", apiVersionPrefix(version), defaultHTTPRoutes(entity).Path, mainPath))
+}
+
+// generateHTTPDeprecationFile writes internal/handler/http/deprecation.go
+// with the middleware main.go registers on deprecated versions. Like etag.go,
+// it is only rewritten with --force.
+func generateHTTPDeprecationFile(dir string, sm ...*SafetyManager) {
+	writeOnce(filepath.Join(dir, "deprecation.go"), httpDeprecationSource, "deprecation middleware", sm...)
+}
+
+const httpDeprecationSource = `package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Deprecation describes the retirement of an API version. Since and Sunset
+// are dates such as 2027-06-30; Link is the URL of the migration guide.
+type Deprecation struct {
+	Since  string
+	Sunset string
+	Link   string
+}
+
+// Deprecated returns a middleware announcing the retirement of the routes of
+// a router with the Deprecation (RFC 9745), Sunset (RFC 8594) and Link
+// headers. From the sunset date on, the routes answer 410 Gone. It panics on
+// malformed dates, which goca config validate reports before they reach
+// main.go.
+func Deprecated(d Deprecation) mux.MiddlewareFunc {
+	deprecation := "true"
+	if since := mustParseDeprecationDate(d.Since); !since.IsZero() {
+		deprecation = fmt.Sprintf("@%d", since.Unix())
+	}
+	sunset := mustParseDeprecationDate(d.Sunset)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", deprecation)
+			if d.Link != "" {
+				w.Header().Add("Link", fmt.Sprintf("<%s>; rel=\"deprecation\"; type=\"text/html\"", d.Link))
+			}
+			if !sunset.IsZero() {
+				w.Header().Set("Sunset", sunset.Format(http.TimeFormat))
+				if !time.Now().Before(sunset) {
+					http.Error(w, "this API version has been retired", http.StatusGone)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// mustParseDeprecationDate parses a date of a Deprecation; empty dates are
+// the zero time.
+func mustParseDeprecationDate(date string) time.Time {
+	if date == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		panic(fmt.Sprintf("deprecation: invalid date %q: %v", date, err))
+	}
+	return t
+}
+`
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveAPIVersion(t *testing.T) {
+	cfg := VersioningConfig{Features: map[string]string{"order_item": "v3", "product": "v2", "user": "two"}}
+	assert.Equal(t, "v2", resolveAPIVersion(cfg, "Product"))
+	assert.Equal(t, "v3", resolveAPIVersion(cfg, "OrderItem"))
+	assert.Equal(t, "v1", resolveAPIVersion(cfg, "User"), "invalid versions fall back to the default")
+	cfg.Default = "v2"
+	assert.Equal(t, "v2", resolveAPIVersion(cfg, "Customer"))
+
+	assert.Equal(t, "", apiVersionSuffix("v1"))
+	assert.Equal(t, "V12", apiVersionSuffix("v12"))
+	assert.Equal(t, "apiRouter", apiVersionRouter("v1"))
+	assert.Equal(t, "apiV2Router", apiVersionRouter("v2"))
+	assert.Equal(t, "/api/v2", apiVersionPrefix("v2"))
+
+	assert.NoError(t, validateAPIVersion("v10"))
+	for _, version := range []string{"", "2", "v0", "V2", "v2.1"} {
+		assert.Error(t, validateAPIVersion(version), version)
+	}
+}
+
+func TestValidateAPI_Versioning(t *testing.T) {
+	cm := NewConfigManager()
+	cm.validateAPI(&APIConfig{Versioning: VersioningConfig{
+		Default:  "2",
+		Features: map[string]string{"product": "v2", "order": "latest"},
+		Deprecated: map[string]DeprecationConfig{
+			"v1":   {Since: "2026-10-01", Sunset: "2026-01-31", Link: "docs/migrate"},
+			"beta": {Sunset: "2027-01-01"},
+		},
+	}})
+	var fields []string
+	for _, err := range cm.GetErrors() {
+		fields = append(fields, err.Field)
+	}
+	assert.Equal(t, []string{
+		"api.versioning.default",
+		"api.versioning.features.order",
+		"api.versioning.deprecated.beta",
+		"api.versioning.deprecated.v1.sunset",
+		"api.versioning.deprecated.v1.link",
+	}, fields)
+
+	cm = NewConfigManager()
+	cm.validateAPI(&APIConfig{Versioning: VersioningConfig{Deprecated: map[string]DeprecationConfig{"v1": {Sunset: "2027-06-30"}}}})
+	assert.Empty(t, cm.GetErrors())
+	require.Len(t, cm.GetWarnings(), 1, "new features still default to v1")
+	assert.Equal(t, "api.versioning.deprecated.v1", cm.GetWarnings()[0].Field)
+}
+
+func TestGenerateVersionedHTTPHandler(t *testing.T) {
+	defer ensureTestUI(t)()
+	setupCLIProject(t, fullProductUseCase)
+
+	sm := NewSafetyManager(false, false, false)
+	generateHandler("Product", HandlerHTTP, false, true, true, "lowercase", sm)
+	generateVersionedHTTPHandler("Product", "v2", false, true, true, "lowercase", sm)
+
+	dir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	v1 := readParsedGo(t, filepath.Join(dir, "product_handler.go"))
+	assert.Contains(t, v1, "type ProductHandler struct {")
+	v2 := readParsedGo(t, filepath.Join(dir, "product_v2_handler.go"))
+	assert.Contains(t, v2, "type ProductV2Handler struct {")
+	assert.Contains(t, v2, "func NewProductV2Handler(uc usecase.ProductUseCase) *ProductV2Handler {")
+	assert.Contains(t, v2, "func (p *ProductV2Handler) CreateProduct(w http.ResponseWriter, r *http.Request) {")
+	assert.Contains(t, v2, "// @Router /api/v2/products [post]")
+
+	routes := readParsedGo(t, filepath.Join(dir, "routes.go"))
+	assert.Equal(t, 1, strings.Count(routes, "func SetupProductRoutes("))
+	assert.Contains(t, routes, "func SetupProductV2Routes(router *mux.Router, uc usecase.ProductUseCase) {\n\thandler := NewProductV2Handler(uc)")
+
+	generateVersionedHTTPHandlerFile(dir, "Product", "v3", false, false, "kebab-case", sm)
+	assert.FileExists(t, filepath.Join(dir, "product-v3-handler.go"))
+}
+
+func TestWireFeatureIntoMainGo_Versions(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0o644))
+	require.NoError(t, os.WriteFile(".goca.yaml", []byte("project:\n  name: shop\n  module: testproject\napi:\n  versioning:\n    deprecated:\n      v1:\n        sunset: \"2027-06-30\"\n        link: https://example.com/migrate\n"), 0o644))
+	dir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	generateHTTPDeprecationFile(dir)
+	assert.Contains(t, readParsedGo(t, filepath.Join(dir, "deprecation.go")), "func Deprecated(d Deprecation) mux.MiddlewareFunc {")
+
+	main := "package main\n\nimport (\n\t\"github.com/gorilla/mux\"\n)\n\nfunc main() {\n\trouter := mux.NewRouter()\n\t_ = router\n}\n"
+	require.NoError(t, os.WriteFile("main.go", []byte(main), 0o644))
+	require.NoError(t, wireFeatureIntoMainGo("main.go", "Product", "testproject", "v1", main))
+	registerVersionedRoutes("Product", "v2")
+	registerVersionedRoutes("Product", "v2")
+	registerVersionedRoutes("Order", "v3")
+
+	got := readParsedGo(t, "main.go")
+	assert.Contains(t, got, "apphttp.SetupProductRoutes(apiRouter, container.ProductUseCase()) // product routes")
+	assert.Equal(t, 1, strings.Count(got, "apphttp.SetupProductV2Routes(apiV2Router, container.ProductUseCase()) // product v2 routes"))
+	assert.Contains(t, got, "apphttp.SetupOrderV3Routes(apiV3Router, container.OrderUseCase())")
+	assert.Equal(t, 1, strings.Count(got, `apiRouter.Use(apphttp.Deprecated(apphttp.Deprecation{Sunset: "2027-06-30", Link: "https://example.com/migrate"}))`))
+
+	// The routers are declared in version order, each before its routes.
+	v1 := strings.Index(got, apiRouterDecl("v1"))
+	v2 := strings.Index(got, apiRouterDecl("v2"))
+	v3 := strings.Index(got, apiRouterDecl("v3"))
+	assert.Less(t, v1, strings.Index(got, "apiRouter.Use("))
+	assert.Less(t, strings.Index(got, "apiRouter.Use("), v2)
+	assert.Less(t, v2, v3)
+	assert.Less(t, v3, strings.Index(got, "SetupProductRoutes("))
+
+	// A changed deprecation replaces the registration.
+	updated := applyDeprecations(got, VersioningConfig{Deprecated: map[string]DeprecationConfig{"v1": {Since: "2026-10-01", Sunset: "2027-12-31"}}})
+	assert.Contains(t, updated, `apiRouter.Use(apphttp.Deprecated(apphttp.Deprecation{Since: "2026-10-01", Sunset: "2027-12-31"}))`)
+	assert.Equal(t, 1, strings.Count(updated, "apiRouter.Use("))
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// dirAppErrors is the package, under pkg, of the errors use cases wrap.
+const dirAppErrors = "apperrors"
+
+// errorWrappingEnabled reports whether the current project sets
+// generation.error_wrapping: use cases then wrap the errors of repositories
+// with the operation that failed, and handlers log server errors with the
+// stack recorded where they were wrapped.
+func errorWrappingEnabled() bool {
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	return ci.GetGenerationConfig().ErrorWrapping
+}
+
+// appErrorsImport returns the import path of pkg/apperrors.
+func appErrorsImport() string {
+	return getImportPath(getModuleName()) + "/" + DirPkg + "/" + dirAppErrors
+}
+
+// generateAppErrors writes pkg/apperrors/apperrors.go, shared by every use
+// case and handler and only rewritten with --force.
+func generateAppErrors(sm ...*SafetyManager) {
+	writeOnce(filepath.Join(DirPkg, dirAppErrors, "apperrors.go"), appErrorsTemplate, "error wrapping package", sm...)
+}
+
+// useCaseOp returns the operation a use case wraps the repository errors of
+// with, e.g. "create order" or "list orders".
+func useCaseOp(op, entity string) string {
+	name := strings.ToLower(entity)
+	if op == OpList || op == OpCount || op == OpSearch {
+		name += "s"
+	}
+	return op + " " + name
+}
+
+// useCaseError returns the expression a use case returns for the err of its
+// repository: err, or err wrapped with the operation when wrap is set.
+func useCaseError(wrap bool, op, entity string) string {
+	if !wrap {
+		return "err"
+	}
+	return fmt.Sprintf("apperrors.Wrap(err, %q)", useCaseOp(op, entity))
+}
+
+// writeWrappedReturn writes the statements returning the call of a
+// repository method whose results are value and an error. Without wrap the
+// call is returned as is; with it the error is wrapped and zero returned.
+func writeWrappedReturn(content *strings.Builder, wrap bool, op, entity, call, value, zero string) {
+	if !wrap {
+		fmt.Fprintf(content, "\treturn %s\n", call)
+		return
+	}
+	fmt.Fprintf(content, "\t%s, err := %s\n", value, call)
+	content.WriteString("\tif err != nil {\n")
+	fmt.Fprintf(content, "\t\treturn %s, %s\n", zero, useCaseError(wrap, op, entity))
+	content.WriteString("\t}\n")
+	fmt.Fprintf(content, "\treturn %s, nil\n", value)
+}
+
+// writeWrappedErrorReturn writes the statement returning the error of a
+// repository call, wrapped with the operation when wrap is set.
+func writeWrappedErrorReturn(content *strings.Builder, wrap bool, op, entity, call string) {
+	if !wrap {
+		fmt.Fprintf(content, "\treturn %s\n", call)
+		return
+	}
+	fmt.Fprintf(content, "\treturn apperrors.Wrap(%s, %q)\n", call, useCaseOp(op, entity))
+}
+
+// wrappedServerErrorLog returns the log statement of respondUseCaseError
+// with the stack of the error appended.
+func wrappedServerErrorLog(template string) string {
+	return strings.Replace(template, serverErrorLog, `log.Printf("%s %s: %v%s", r.Method, r.URL.Path, err, apperrors.Stack(err))`, 1)
+}
+
+const appErrorsTemplate = `// Package apperrors wraps the errors crossing the layers of the service with
+// the operation that failed and, when enabled, the call stack where they were
+// first wrapped. The sentinel error kinds stay in internal/messages and
+// internal/domain: wrapped errors still match them with errors.Is and
+// errors.As, so handlers map them to statuses unchanged.
+package apperrors
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// CaptureStacks makes Wrap record the call stack of the errors it wraps
+// first. It is read from the ERROR_STACKS environment variable; recording a
+// stack costs an allocation per failed operation.
+var CaptureStacks, _ = strconv.ParseBool(os.Getenv("ERROR_STACKS"))
+
+// maxDepth is the number of frames a stack records.
+const maxDepth = 32
+
+// Error is an error annotated with the operation that failed, e.g.
+// "create order: not found".
+type Error struct {
+	Op    string
+	Err   error
+	stack []uintptr
+}
+
+func (e *Error) Error() string {
+	return e.Op + ": " + e.Err.Error()
+}
+
+// Unwrap returns the wrapped error, so errors.Is and errors.As see through e.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// StackTrace returns the stack recorded where e was created, one function
+// and file:line per frame, or "" when none was recorded.
+func (e *Error) StackTrace() string {
+	if len(e.stack) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		b.WriteString(frame.Function)
+		b.WriteString("\n\t")
+		b.WriteString(frame.File)
+		b.WriteString(":")
+		b.WriteString(strconv.Itoa(frame.Line))
+		b.WriteString("\n")
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// Wrap annotates err with op. It returns nil for a nil err, so a result can
+// be wrapped unconditionally. With CaptureStacks, the first Wrap of an error
+// records the stack; outer ones only add their operation.
+func Wrap(err error, op string) error {
+	if err == nil {
+		return nil
+	}
+	e := &Error{Op: op, Err: err}
+	var inner *Error
+	if CaptureStacks && !errors.As(err, &inner) {
+		pcs := make([]uintptr, maxDepth)
+		e.stack = pcs[:runtime.Callers(2, pcs)]
+	}
+	return e
+}
+
+// Stack returns "\n" and the stack recorded by the Error in err's chain that
+// has one, or "" when there is none. It is meant to follow the error in logs:
+//
+//	log.Printf("%v%s", err, apperrors.Stack(err))
+func Stack(err error) string {
+	var e *Error
+	for errors.As(err, &e) {
+		if trace := e.StackTrace(); trace != "" {
+			return "\n" + trace
+		}
+		err = e.Err
+	}
+	return ""
+}
+`
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseCaseErrorWrapping(t *testing.T) {
+	assert.Equal(t, "err", useCaseError(false, OpCreate, "Order"))
+	assert.Equal(t, `apperrors.Wrap(err, "create order")`, useCaseError(true, OpCreate, "Order"))
+	assert.Equal(t, "list orders", useCaseOp(OpList, "Order"))
+
+	var sb strings.Builder
+	generateGetMethod(&sb, "orderService", "Order", true)
+	generateDeleteMethod(&sb, "orderService", "Order", true)
+	generateCountMethod(&sb, "orderService", "Order", true)
+	out := sb.String()
+	assert.Contains(t, out, "\torder, err := o.repo.FindByID(id)\n\tif err != nil {\n\t\treturn nil, apperrors.Wrap(err, \"get order\")\n\t}\n\treturn order, nil\n")
+	assert.Contains(t, out, "\treturn apperrors.Wrap(o.repo.Delete(id), \"delete order\")\n")
+	assert.Contains(t, out, "\t\treturn 0, apperrors.Wrap(err, \"count orders\")\n")
+
+	sb.Reset()
+	generateGetMethod(&sb, "orderService", "Order", false)
+	assert.Contains(t, sb.String(), "\treturn o.repo.FindByID(id)\n")
+}
+
+func TestGenerateErrorWrapping(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	sm := NewSafetyManager(false, true, false)
+	dir := filepath.Join(DirInternal, DirUseCase)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+
+	generateUseCaseServiceWithFields(dir, "OrderUseCase", "Order", []string{OpCreate, OpRead}, false, false, "Name:string", DTOStyleNested, sm)
+	service := readParsedGo(t, filepath.Join(dir, "order_service.go"))
+	assert.NotContains(t, service, "apperrors")
+	assert.NoFileExists(t, filepath.Join(DirPkg, dirAppErrors, "apperrors.go"))
+
+	require.NoError(t, os.WriteFile(".goca.yaml", []byte("project:\n  name: shop\n  module: testproject\ngeneration:\n  error_wrapping: true\n"), 0o644))
+	generateUseCaseServiceWithFields(dir, "OrderUseCase", "Order", []string{OpCreate, OpRead}, false, false, "Name:string", DTOStyleNested, sm)
+	service = readParsedGo(t, filepath.Join(dir, "order_service.go"))
+	assert.Contains(t, service, `"testproject/pkg/apperrors"`)
+	assert.Contains(t, service, `return CreateOrderOutput{}, apperrors.Wrap(err, "create order")`)
+	assert.Contains(t, service, `return nil, apperrors.Wrap(err, "get order")`)
+
+	apperrors := readParsedGo(t, filepath.Join(DirPkg, dirAppErrors, "apperrors.go"))
+	assert.Contains(t, apperrors, "func Wrap(err error, op string) error {")
+	assert.Contains(t, apperrors, "func Stack(err error) string {")
+	assert.Contains(t, apperrors, `os.Getenv("ERROR_STACKS")`)
+
+	httpDir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	require.NoError(t, os.MkdirAll(httpDir, 0o755))
+	generateHTTPStatusFile(httpDir, sm)
+	status := readParsedGo(t, filepath.Join(httpDir, "status.go"))
+	assert.Contains(t, status, "\tif fallback >= http.StatusInternalServerError {\n\t\tlog.Printf(\"%v%s\", err, apperrors.Stack(err))\n\t}\n")
+
+	generateHTTPResponseFile(httpDir, ResponseFormatProblem, sm)
+	response := readParsedGo(t, filepath.Join(httpDir, "response.go"))
+	assert.Contains(t, response, `log.Printf("%s %s: %v%s", r.Method, r.URL.Path, err, apperrors.Stack(err))`)
+	assert.NotContains(t, response, serverErrorLog)
+}
@@ -0,0 +1,435 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// archiveDir is the package holding the generated archive jobs.
+var archiveDir = filepath.Join(DirInternal, "archive")
+
+// Stores and object formats of goca archive.
+const (
+	ArchiveStoreTable = "table"
+	ArchiveStoreS3    = "s3"
+
+	ArchiveFormatParquet = "parquet"
+	ArchiveFormatCSV     = "csv"
+)
+
+// ValidArchiveStores are the values of --store.
+var ValidArchiveStores = []string{ArchiveStoreTable, ArchiveStoreS3}
+
+// ValidArchiveFormats are the values of --format.
+var ValidArchiveFormats = []string{ArchiveFormatParquet, ArchiveFormatCSV}
+
+// archiveConfigDecl declares the archive settings of pkg/config.
+const archiveConfigDecl = `// ArchiveConfig configures the archive jobs of cmd/archive.
+type ArchiveConfig struct {
+	// DryRun logs the rows the jobs would archive without moving them.
+	DryRun bool
+	// Bucket, Prefix, Region and Endpoint locate the objects of jobs with an
+	// S3 store; Endpoint selects an S3-compatible service such as MinIO.
+	Bucket   string
+	Prefix   string
+	Region   string
+	Endpoint string
+}
+`
+
+// archiveConfigLoad is the Archive element of the Config literal Load
+// returns.
+const archiveConfigLoad = `		Archive: ArchiveConfig{
+			DryRun:   getEnv("ARCHIVE_DRY_RUN", "false") == "true",
+			Bucket:   getEnv("ARCHIVE_BUCKET", ""),
+			Prefix:   getEnv("ARCHIVE_PREFIX", "archive"),
+			Region:   getEnv("ARCHIVE_REGION", ""),
+			Endpoint: getEnv("ARCHIVE_ENDPOINT", ""),
+		},
+`
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive <Entity>",
+	Short: "Generate a worker moving old rows to an archive table or S3",
+	Long: `Generates an archive job that moves the rows of an entity older than
+--older-than out of its table, into an archive table of the same database
+(--store table) or into Parquet or gzipped CSV objects of an S3 bucket
+(--store s3), and restores them on demand.
+
+  - internal/archive/<entity>.go  The job: schedule, age, column and store
+  - internal/archive/archive.go   Batching, the archive_checkpoints record,
+                                  restores and the table store
+  - internal/archive/s3.go        The S3 store and its Parquet and CSV
+                                  encodings, with the first S3 job
+  - cmd/archive/main.go           Opens the database, runs the jobs and
+                                  restores rows
+
+Rows are moved in batches of --batch-size ordered by primary key. Each batch
+is stored and deleted from the source table in one transaction that also
+advances the job's checkpoint, so a worker that is stopped or fails resumes
+with the cutoff and batch it was on. Soft-deleted rows are archived too.
+
+Run the workers with:
+
+  go run ./cmd/archive                 # run the jobs on their schedules
+  go run ./cmd/archive -once           # run every job once and exit
+  go run ./cmd/archive restore -job invoice -from 2024-01-01 -to 2024-02-01
+
+Examples:
+  goca archive Invoice --older-than 365d
+  goca archive Order --store s3 --format parquet --schedule "@weekly"
+  goca archive AuditLog --column OccurredAt --older-than 90d --store s3 --format csv`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		job := archiveJob{Entity: args[0]}
+		olderThan, _ := cmd.Flags().GetString("older-than")
+		column, _ := cmd.Flags().GetString("column")
+		job.Store, _ = cmd.Flags().GetString("store")
+		job.Format, _ = cmd.Flags().GetString("format")
+		job.Schedule, _ = cmd.Flags().GetString("schedule")
+		job.BatchSize, _ = cmd.Flags().GetInt("batch-size")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if !dataMigrationNamePattern.MatchString(job.Entity) {
+			return fmt.Errorf("invalid entity name %q: use PascalCase letters and digits, e.g. Invoice", job.Entity)
+		}
+		window, err := parseRetentionWindow(olderThan)
+		if err != nil {
+			return fmt.Errorf("--older-than: %w", err)
+		}
+		job.OlderThan = window
+		if job.Store == ArchiveStoreTable && cmd.Flags().Changed("format") {
+			return fmt.Errorf("--format only applies to --store %s", ArchiveStoreS3)
+		}
+		if err := job.validate(); err != nil {
+			return err
+		}
+		if _, err := os.Stat(job.path()); err == nil && !force {
+			return fmt.Errorf("%s already exists; use --force to regenerate it", job.path())
+		}
+
+		ci := NewConfigIntegration()
+		_ = ci.LoadConfigForProject()
+		database := ci.GetDatabaseType("")
+		if sqlDialect(database) < 0 {
+			return fmt.Errorf("goca archive only supports SQL databases, not %s", database)
+		}
+		field, err := resolveArchiveColumn(job.Entity, column)
+		if err != nil {
+			return err
+		}
+		job.Column = fieldColumns([]Field{field}, database)[0].Name
+
+		ui.Header("Goca Archive")
+		ui.Blank()
+		ui.KeyValue("Job", toSnakeCase(job.Entity))
+		ui.KeyValue("Rows", fmt.Sprintf("%s older than %s", field.Name, olderThan))
+		ui.KeyValue("Store", job.describeStore())
+		ui.KeyValue("Schedule", job.Schedule)
+		ui.KeyValue("Batch size", fmt.Sprintf("%d rows", job.BatchSize))
+		ui.Blank()
+
+		sm := NewSafetyManager(dryRun, force, false)
+		if err := generateArchive(job, database, sm); err != nil {
+			return err
+		}
+		if dryRun {
+			sm.PrintSummary()
+			return nil
+		}
+
+		projectRoot, _ := os.Getwd()
+		depMgr := NewDependencyManager(projectRoot, false)
+		options := map[string]bool{"archive": true, "archive-s3": job.Store == ArchiveStoreS3}
+		for _, dep := range depMgr.GetRequiredDependenciesForFeature("", options) {
+			if err := depMgr.AddDependency(dep); err != nil {
+				ui.Warning(fmt.Sprintf("Could not add dependency %s: %v", dep.Module, err))
+			}
+		}
+
+		steps := []string{
+			"Check what a run would archive with 'ARCHIVE_DRY_RUN=true go run ./cmd/archive -once -job " + toSnakeCase(job.Entity) + "'",
+			"Deploy 'go run ./cmd/archive' as a long-running worker",
+		}
+		if job.Store == ArchiveStoreS3 {
+			steps = append([]string{"Set ARCHIVE_BUCKET (and ARCHIVE_REGION or ARCHIVE_ENDPOINT) in the environment"}, steps...)
+		}
+		ui.Blank()
+		ui.NextSteps(steps)
+		return nil
+	},
+}
+
+// archiveJob is an archive job being generated.
+type archiveJob struct {
+	Entity string
+	// Column is the time column compared with the cutoff.
+	Column    string
+	OlderThan time.Duration
+	Store     string
+	Format    string
+	Schedule  string
+	BatchSize int
+}
+
+// path returns the file of the job.
+func (j archiveJob) path() string {
+	return filepath.Join(archiveDir, toSnakeCase(j.Entity)+".go")
+}
+
+// validate checks the flags of the job.
+func (j archiveJob) validate() error {
+	if !contains(ValidArchiveStores, j.Store) {
+		return fmt.Errorf("unsupported --store %q: use one of %s", j.Store, strings.Join(ValidArchiveStores, ", "))
+	}
+	if !contains(ValidArchiveFormats, j.Format) {
+		return fmt.Errorf("unsupported --format %q: use one of %s", j.Format, strings.Join(ValidArchiveFormats, ", "))
+	}
+	if err := validateCronSchedule(j.Schedule); err != nil {
+		return err
+	}
+	if j.BatchSize < 1 {
+		return fmt.Errorf("--batch-size must be at least 1")
+	}
+	return nil
+}
+
+// describeStore returns where the job keeps the rows, for the summary.
+func (j archiveJob) describeStore() string {
+	if j.Store == ArchiveStoreS3 {
+		return fmt.Sprintf("S3 (%s objects)", j.Format)
+	}
+	return entityTableName(j.Entity) + "_archive table"
+}
+
+// resolveArchiveColumn returns the time field of entity named column,
+// ignoring case.
+func resolveArchiveColumn(entity, column string) (Field, error) {
+	file, err := findEntityFile(entity)
+	if err != nil {
+		return Field{}, fmt.Errorf("%w; generate it first, e.g. goca entity %s --fields \"total:float64\" --timestamps", err, entity)
+	}
+	src, err := parseGoSource(file)
+	if err != nil {
+		return Field{}, err
+	}
+	for _, field := range readEntityFields(src.file, entity) {
+		if !strings.EqualFold(field.Name, column) || field.Nested != nil {
+			continue
+		}
+		if field.Type != "time.Time" && field.Type != "*time.Time" && field.Type != "gorm.DeletedAt" {
+			return Field{}, fmt.Errorf("%s.%s is a %s; --column needs a time.Time field", entity, field.Name, field.Type)
+		}
+		return field, nil
+	}
+	return Field{}, fmt.Errorf("%s has no %s field to compare with the cutoff; add it with goca field add %s \"%s:time.Time\" or pick one with --column", entity, column, entity, toSnakeCase(column))
+}
+
+// generateArchive writes the job, the runtime, regenerated on every run, and
+// the worker entrypoint, written once so it can be customized.
+func generateArchive(j archiveJob, database string, sm *SafetyManager) error {
+	importPath := getImportPath(getModuleName())
+	if err := writeGoFile(j.path(), buildArchiveJob(j, importPath), sm); err != nil {
+		return fmt.Errorf("writing archive job: %w", err)
+	}
+	if err := writeGoFileMerged(filepath.Join(archiveDir, "archive.go"), fmt.Sprintf(archiveRuntimeSource, importPath), sm); err != nil {
+		return fmt.Errorf("writing archive runtime: %w", err)
+	}
+	s3Path := filepath.Join(archiveDir, "s3.go")
+	if _, err := os.Stat(s3Path); err == nil || j.Store == ArchiveStoreS3 {
+		if err := writeGoFileMerged(s3Path, fmt.Sprintf(archiveS3Source, importPath), sm); err != nil {
+			return fmt.Errorf("writing archive S3 store: %w", err)
+		}
+	}
+	if err := ensureConfigSection("Archive", "ArchiveConfig", archiveConfigDecl, archiveConfigLoad, sm); err != nil {
+		ui.Warning(fmt.Sprintf("Could not add the archive settings to pkg/config: %v", err))
+	}
+
+	mainPath := filepath.Join("cmd", "archive", "main.go")
+	if _, err := os.Stat(mainPath); err == nil {
+		return nil
+	}
+	main, ok := buildArchiveMain(importPath, database, projectSQLiteDriver())
+	if !ok {
+		return fmt.Errorf("goca archive does not support %s", database)
+	}
+	if err := writeGoFile(mainPath, main, sm); err != nil {
+		return fmt.Errorf("writing archive entrypoint: %w", err)
+	}
+	return nil
+}
+
+// buildArchiveJob returns the file of a new archive job.
+func buildArchiveJob(j archiveJob, importPath string) string {
+	name := toSnakeCase(j.Entity)
+	pluralLower := strings.ToLower(toPlural(j.Entity))
+
+	var b strings.Builder
+	b.WriteString("package archive\n\n")
+	b.WriteString("import (\n\t\"time\"\n\n")
+	fmt.Fprintf(&b, "\t\"%s/internal/domain\"\n", importPath)
+	fmt.Fprintf(&b, "\t\"%s/pkg/config\"\n", importPath)
+	b.WriteString(")\n\n")
+
+	b.WriteString("func init() {\n")
+	fmt.Fprintf(&b, "\t// The %s whose %s is older than OlderThan are moved to\n", pluralLower, j.Column)
+	if j.Store == ArchiveStoreS3 {
+		fmt.Fprintf(&b, "\t// %s objects of cfg.Bucket.\n", j.Format)
+	} else {
+		fmt.Fprintf(&b, "\t// %s_archive.\n", entityTableName(j.Entity))
+	}
+	b.WriteString("\tregister(func(cfg config.ArchiveConfig) Job {\n")
+	b.WriteString("\t\treturn Job{\n")
+	fmt.Fprintf(&b, "\t\t\tName:      %q,\n", name)
+	fmt.Fprintf(&b, "\t\t\tSchedule:  %q,\n", j.Schedule)
+	fmt.Fprintf(&b, "\t\t\tModel:     &domain.%s{},\n", j.Entity)
+	fmt.Fprintf(&b, "\t\t\tColumn:    %q,\n", j.Column)
+	fmt.Fprintf(&b, "\t\t\tOlderThan: %s,\n", retentionWindowLiteral(j.OlderThan))
+	fmt.Fprintf(&b, "\t\t\tBatchSize: %d,\n", j.BatchSize)
+	if j.Store == ArchiveStoreS3 {
+		format := map[string]string{ArchiveFormatParquet: "FormatParquet", ArchiveFormatCSV: "FormatCSV"}[j.Format]
+		fmt.Fprintf(&b, "\t\t\tStore:     NewS3Store(cfg, %s),\n", format)
+	} else {
+		b.WriteString("\t\t\tStore:     TableStore{},\n")
+	}
+	b.WriteString("\t\t}\n")
+	b.WriteString("\t})\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// buildArchiveMain returns cmd/archive/main.go, or false when the database
+// has no generated GORM opener.
+func buildArchiveMain(importPath, database, sqliteDriver string) (string, bool) {
+	if sqlDialect(database) < 0 {
+		return "", false
+	}
+	std, external, openDatabase, ok := databaseOpener(database, sqliteDriver)
+	if !ok {
+		return "", false
+	}
+	imports := importLines(append([]string{"context", "flag", "fmt", "log", "os", "os/signal", "syscall", "time"}, std...), external)
+
+	return fmt.Sprintf(`package main
+
+import (
+%s
+	"%s/internal/archive"
+	"%s/pkg/config"
+)
+
+// main runs the archive jobs of internal/archive on their schedules, or once
+// with -once. An interrupted job resumes at its checkpoint on the next run.
+//
+// The restore subcommand puts archived rows back:
+//
+//	go run ./cmd/archive restore -job invoice -from 2024-01-01 -to 2024-02-01
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "restore" {
+		restore(os.Args[2:])
+		return
+	}
+	once := flag.Bool("once", false, "run the jobs once and exit instead of on their schedules")
+	only := flag.String("job", "", "run only the named job")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg := config.Load()
+	db, err := openDatabase(cfg)
+	if err != nil {
+		fail("connecting to database: %%v", err)
+	}
+
+	jobs := archive.Jobs(cfg.Archive)
+	if *only != "" {
+		job, ok := archive.Lookup(cfg.Archive, *only)
+		if !ok {
+			fail("unknown job %%q", *only)
+		}
+		jobs = []archive.Job{job}
+	}
+
+	if *once {
+		for _, job := range jobs {
+			if err := archive.Run(ctx, db, job); err != nil {
+				fail("%%v", err)
+			}
+		}
+		return
+	}
+	log.Printf("scheduling %%d archive jobs", len(jobs))
+	if err := archive.Serve(ctx, db, jobs); err != nil {
+		fail("%%v", err)
+	}
+}
+
+// restore inserts the archived rows of a job whose column is in [-from, -to)
+// back into its table.
+func restore(args []string) {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	only := flags.String("job", "", "job whose rows are restored")
+	from := flags.String("from", "", "restore the rows from this date on, e.g. 2024-01-01")
+	to := flags.String("to", "", "restore the rows before this date, e.g. 2024-02-01")
+	_ = flags.Parse(args)
+	if *only == "" {
+		fail("restore needs -job")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg := config.Load()
+	job, ok := archive.Lookup(cfg.Archive, *only)
+	if !ok {
+		fail("unknown job %%q", *only)
+	}
+	start, end := parseDate("from", *from), parseDate("to", *to)
+	db, err := openDatabase(cfg)
+	if err != nil {
+		fail("connecting to database: %%v", err)
+	}
+	n, err := archive.Restore(ctx, db, job, start, end)
+	if err != nil {
+		fail("%%v", err)
+	}
+	log.Printf("%%s: restored %%d rows", job.Name, n)
+}
+
+// parseDate parses the value of a date flag, "" being the zero time.
+func parseDate(name, value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		fail("invalid -%%s %%q: use a date such as 2024-01-31", name, value)
+	}
+	return t
+}
+
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "Error: "+format+"\n", args...)
+	os.Exit(1)
+}
+
+%s`, imports, importPath, importPath, openDatabase), true
+}
+
+func init() {
+	archiveCmd.Flags().String("older-than", "365d", "Age past which rows are archived, in days (365d), weeks (52w) or a duration (36h)")
+	archiveCmd.Flags().String("column", "CreatedAt", "Time field of the entity compared with the cutoff")
+	archiveCmd.Flags().String("store", ArchiveStoreTable, "Where archived rows are kept ("+strings.Join(ValidArchiveStores, ", ")+")")
+	archiveCmd.Flags().String("format", ArchiveFormatParquet, "Object format of --store s3 ("+strings.Join(ValidArchiveFormats, ", ")+")")
+	archiveCmd.Flags().String("schedule", "0 3 * * *", "Cron schedule of the job, @daily-style descriptor or @every <duration>")
+	archiveCmd.Flags().Int("batch-size", 1000, "Rows moved per transaction")
+	archiveCmd.Flags().Bool("dry-run", false, "Show the files that would be generated without writing them")
+	archiveCmd.Flags().Bool("force", false, "Overwrite an existing job")
+}
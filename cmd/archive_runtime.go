@@ -0,0 +1,779 @@
+package cmd
+
+// archiveRuntimeSource is internal/archive/archive.go, the runtime of the jobs
+// goca archive generates. Its %s is the import path of the project.
+const archiveRuntimeSource = `// Code generated by goca archive. DO NOT EDIT.
+
+// Package archive moves the rows of entities past their age to cheaper
+// storage, an archive table or S3 objects, in resumable batches, and
+// restores them on demand.
+package archive
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"%s/pkg/config"
+)
+
+// Job archives the rows of one table.
+type Job struct {
+	// Name identifies the job's checkpoint and selects it in cmd/archive.
+	Name string
+	// Schedule is a cron expression, a descriptor such as @daily or
+	// @every <duration>.
+	Schedule string
+	// Model points to the entity whose table is archived, e.g. &domain.Order{}.
+	Model interface{}
+	// Column is the time column compared with the cutoff of a run.
+	Column string
+	// OlderThan is the age past which rows are archived.
+	OlderThan time.Duration
+	// BatchSize is the number of rows moved per transaction.
+	BatchSize int
+	// Store keeps the archived rows.
+	Store Store
+	// DryRun logs the rows a run would archive without moving them.
+	DryRun bool
+}
+
+// Batch is a batch of rows being archived.
+type Batch struct {
+	// Rows points to a slice of the job's model.
+	Rows interface{}
+	// Cutoff is the cutoff of the run the batch belongs to.
+	Cutoff time.Time
+	// Seq numbers the batches of a run from 1. A batch retried after a
+	// failure keeps its number.
+	Seq int
+}
+
+// Store keeps archived rows.
+type Store interface {
+	// Prepare readies the store for the runs and restores of job.
+	Prepare(ctx context.Context, db *gorm.DB, job Job) error
+	// Put keeps a batch. It is called in tx, the transaction deleting the
+	// rows from the source table, so a store outside the database must accept
+	// the same batch again after a failed commit.
+	Put(ctx context.Context, tx *gorm.DB, job Job, batch Batch) error
+	// Restore inserts the archived rows of job whose Column is in [from, to)
+	// back into the source table and returns how many it inserted. A zero
+	// from or to leaves that end open.
+	Restore(ctx context.Context, db *gorm.DB, job Job, from, to time.Time) (int64, error)
+}
+
+// Checkpoint records the progress of a job.
+type Checkpoint struct {
+	Job string ` + "`gorm:\"primaryKey;size:100\"`" + `
+	// Cutoff is the cutoff of the current or last run: the rows whose Column
+	// is before it are archived.
+	Cutoff time.Time
+	// Done reports whether the run completed. An interrupted run resumes
+	// with its cutoff, so the objects of its batches keep their names.
+	Done bool
+	// Batches and Rows count what the current or last run archived.
+	Batches   int
+	Rows      int64
+	UpdatedAt time.Time
+}
+
+// TableName names the checkpoint table.
+func (Checkpoint) TableName() string { return "archive_checkpoints" }
+
+// Factory returns a job configured with the settings of cmd/archive.
+type Factory func(cfg config.ArchiveConfig) Job
+
+var factories []Factory
+
+// register adds a job; every job file calls it from init.
+func register(factory Factory) {
+	factories = append(factories, factory)
+}
+
+// Jobs returns the registered jobs ordered by name.
+func Jobs(cfg config.ArchiveConfig) []Job {
+	jobs := make([]Job, 0, len(factories))
+	for _, factory := range factories {
+		job := factory(cfg)
+		job.DryRun = job.DryRun || cfg.DryRun
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Name < jobs[j].Name })
+	return jobs
+}
+
+// Lookup returns the registered job named name.
+func Lookup(cfg config.ArchiveConfig, name string) (Job, bool) {
+	for _, job := range Jobs(cfg) {
+		if job.Name == name {
+			return job, true
+		}
+	}
+	return Job{}, false
+}
+
+// Serve runs jobs on their schedules until ctx is done. A run that is due
+// while the previous one of the job is still going is skipped.
+func Serve(ctx context.Context, db *gorm.DB, jobs []Job) error {
+	c := cron.New(cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
+	for _, job := range jobs {
+		job := job
+		_, err := c.AddFunc(job.Schedule, func() {
+			if err := Run(ctx, db, job); err != nil && ctx.Err() == nil {
+				log.Printf("%%s: %%v", job.Name, err)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("%%s: schedule %%q: %%w", job.Name, job.Schedule, err)
+		}
+	}
+	c.Start()
+	<-ctx.Done()
+	<-c.Stop().Done()
+	return nil
+}
+
+// Run archives the rows of job older than the cutoff of the run, a batch per
+// transaction that stores them, deletes them from the source table and
+// advances the checkpoint. A run that was interrupted resumes with the cutoff
+// and batch number it stopped at.
+func Run(ctx context.Context, db *gorm.DB, job Job) error {
+	job = withDefaults(job)
+	db = db.WithContext(ctx)
+	s, err := parse(db, job)
+	if err != nil {
+		return fmt.Errorf("%%s: %%w", job.Name, err)
+	}
+	if err := db.AutoMigrate(&Checkpoint{}); err != nil {
+		return fmt.Errorf("%%s: creating archive_checkpoints: %%w", job.Name, err)
+	}
+	checkpoint := Checkpoint{Job: job.Name}
+	if err := db.FirstOrInit(&checkpoint, Checkpoint{Job: job.Name}).Error; err != nil {
+		return fmt.Errorf("%%s: reading checkpoint: %%w", job.Name, err)
+	}
+	if checkpoint.Done || checkpoint.Cutoff.IsZero() {
+		checkpoint = Checkpoint{Job: job.Name, Cutoff: time.Now().UTC().Add(-job.OlderThan)}
+	} else {
+		log.Printf("%%s: resuming the run archiving rows before %%s at batch %%d", job.Name, checkpoint.Cutoff.Format(time.RFC3339), checkpoint.Batches+1)
+	}
+	expired := clause.Lt{Column: clause.Column{Name: job.Column}, Value: checkpoint.Cutoff}
+
+	if job.DryRun {
+		var n int64
+		if err := db.Unscoped().Model(job.Model).Where(expired).Count(&n).Error; err != nil {
+			return fmt.Errorf("%%s: counting rows: %%w", job.Name, err)
+		}
+		log.Printf("%%s: dry run, would archive %%d rows before %%s", job.Name, n, checkpoint.Cutoff.Format(time.RFC3339))
+		return nil
+	}
+	if err := job.Store.Prepare(ctx, db, job); err != nil {
+		return fmt.Errorf("%%s: preparing store: %%w", job.Name, err)
+	}
+
+	pk := s.PrioritizedPrimaryField
+	start := time.Now()
+	for {
+		rows := newRows(job.Model)
+		err := db.Unscoped().Model(job.Model).Where(expired).Order(clause.OrderByColumn{Column: clause.Column{Name: pk.DBName}}).Limit(job.BatchSize).Find(rows).Error
+		if err != nil {
+			return fmt.Errorf("%%s: reading batch %%d: %%w", job.Name, checkpoint.Batches+1, err)
+		}
+		n := reflect.ValueOf(rows).Elem().Len()
+		if n == 0 {
+			break
+		}
+
+		next := checkpoint
+		next.Batches++
+		next.Rows += int64(n)
+		batch := Batch{Rows: rows, Cutoff: checkpoint.Cutoff, Seq: next.Batches}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := job.Store.Put(ctx, tx, job, batch); err != nil {
+				return fmt.Errorf("storing %%d rows: %%w", n, err)
+			}
+			keys := primaryKeys(ctx, pk, rows)
+			if err := withoutHooks(tx).Unscoped().Where(clause.IN{Column: clause.Column{Name: pk.DBName}, Values: keys}).Delete(job.Model).Error; err != nil {
+				return fmt.Errorf("deleting %%d rows: %%w", n, err)
+			}
+			return tx.Save(&next).Error
+		})
+		if err != nil {
+			return fmt.Errorf("%%s: batch %%d: %%w", job.Name, batch.Seq, err)
+		}
+		checkpoint = next
+	}
+
+	checkpoint.Done = true
+	if err := db.Save(&checkpoint).Error; err != nil {
+		return fmt.Errorf("%%s: saving checkpoint: %%w", job.Name, err)
+	}
+	log.Printf("%%s: done, archived %%d rows before %%s in %%s", job.Name, checkpoint.Rows, checkpoint.Cutoff.Format(time.RFC3339), time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// Restore inserts the archived rows of job whose Column is in [from, to)
+// back into the source table. Rows whose primary key is already there are
+// skipped, so a restore can be repeated.
+func Restore(ctx context.Context, db *gorm.DB, job Job, from, to time.Time) (int64, error) {
+	job = withDefaults(job)
+	db = db.WithContext(ctx)
+	if _, err := parse(db, job); err != nil {
+		return 0, fmt.Errorf("%%s: %%w", job.Name, err)
+	}
+	if err := job.Store.Prepare(ctx, db, job); err != nil {
+		return 0, fmt.Errorf("%%s: preparing store: %%w", job.Name, err)
+	}
+	n, err := job.Store.Restore(ctx, db, job, from, to)
+	if err != nil {
+		return n, fmt.Errorf("%%s: %%w", job.Name, err)
+	}
+	return n, nil
+}
+
+// withDefaults fills in the optional fields of job.
+func withDefaults(job Job) Job {
+	if job.Column == "" {
+		job.Column = "created_at"
+	}
+	if job.BatchSize < 1 {
+		job.BatchSize = 1000
+	}
+	if job.Store == nil {
+		job.Store = TableStore{}
+	}
+	return job
+}
+
+// parse returns the schema of the job's model, checking it has a primary key
+// and a time Column.
+func parse(db *gorm.DB, job Job) (*schema.Schema, error) {
+	if job.Model == nil {
+		return nil, fmt.Errorf("job has no Model")
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(job.Model); err != nil {
+		return nil, fmt.Errorf("parsing %%T: %%w", job.Model, err)
+	}
+	if stmt.Schema.PrioritizedPrimaryField == nil {
+		return nil, fmt.Errorf("%%s has no primary key to archive by", stmt.Schema.Table)
+	}
+	field := stmt.Schema.LookUpField(job.Column)
+	if field == nil || field.DataType != schema.Time {
+		return nil, fmt.Errorf("%%s has no time column %%s", stmt.Schema.Table, job.Column)
+	}
+	return stmt.Schema, nil
+}
+
+// newRows returns a pointer to an empty slice of the model.
+func newRows(model interface{}) interface{} {
+	return reflect.New(reflect.SliceOf(reflect.TypeOf(model).Elem())).Interface()
+}
+
+// primaryKeys returns the primary keys of rows, a pointer to a slice.
+func primaryKeys(ctx context.Context, pk *schema.Field, rows interface{}) []interface{} {
+	slice := reflect.ValueOf(rows).Elem()
+	keys := make([]interface{}, slice.Len())
+	for i := range keys {
+		keys[i], _ = pk.ValueOf(ctx, slice.Index(i))
+	}
+	return keys
+}
+
+// withoutHooks skips the hooks of the model: archiving moves rows as they
+// are, and restoring puts them back the same way.
+func withoutHooks(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{SkipHooks: true})
+}
+
+// insert inserts rows, a pointer to a slice of the model, into table, or the
+// model's table when it is "", skipping the rows whose primary key is taken.
+func insert(db *gorm.DB, table string, rows interface{}, batchSize int) (int64, error) {
+	if reflect.ValueOf(rows).Elem().Len() == 0 {
+		return 0, nil
+	}
+	tx := withoutHooks(db).Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true})
+	if table != "" {
+		tx = tx.Table(table)
+	}
+	result := tx.CreateInBatches(rows, batchSize)
+	return result.RowsAffected, result.Error
+}
+
+// timeOf returns the time of a Column value, false when it is NULL.
+func timeOf(value interface{}) (time.Time, bool) {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return time.Time{}, false
+		}
+		value = v
+	}
+	switch t := value.(type) {
+	case time.Time:
+		return t, true
+	case *time.Time:
+		if t != nil {
+			return *t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// inRange reports whether t is in [from, to), zero ends being open.
+func inRange(t, from, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || t.Before(to))
+}
+
+// TableStore moves archived rows to a table of the same database named after
+// the source table with Suffix, "_archive" by default. A batch is inserted in
+// the transaction deleting it from the source table, so it is never lost or
+// kept twice.
+type TableStore struct {
+	Suffix string
+}
+
+// table returns the archive table of the source table.
+func (t TableStore) table(source string) string {
+	if t.Suffix == "" {
+		return source + "_archive"
+	}
+	return source + t.Suffix
+}
+
+// Prepare creates the archive table, or adds the columns the model gained
+// since, without foreign keys so the source rows they pointed to can be
+// archived or deleted too.
+func (t TableStore) Prepare(ctx context.Context, db *gorm.DB, job Job) error {
+	s, err := parse(db, job)
+	if err != nil {
+		return err
+	}
+	migrator := db.Session(&gorm.Session{NewDB: true})
+	cfg := *migrator.Config
+	cfg.DisableForeignKeyConstraintWhenMigrating = true
+	cfg.IgnoreRelationshipsWhenMigrating = true
+	migrator.Config = &cfg
+	return migrator.Table(t.table(s.Table)).AutoMigrate(job.Model)
+}
+
+// Put inserts the batch into the archive table.
+func (t TableStore) Put(ctx context.Context, tx *gorm.DB, job Job, batch Batch) error {
+	s, err := parse(tx, job)
+	if err != nil {
+		return err
+	}
+	_, err = insert(tx, t.table(s.Table), batch.Rows, job.BatchSize)
+	return err
+}
+
+// Restore moves the rows back from the archive table, a batch per
+// transaction.
+func (t TableStore) Restore(ctx context.Context, db *gorm.DB, job Job, from, to time.Time) (int64, error) {
+	s, err := parse(db, job)
+	if err != nil {
+		return 0, err
+	}
+	archive := t.table(s.Table)
+	pk := s.PrioritizedPrimaryField
+	var restored int64
+	for {
+		query := db.Unscoped().Table(archive)
+		if !from.IsZero() {
+			query = query.Where(clause.Gte{Column: clause.Column{Name: job.Column}, Value: from})
+		}
+		if !to.IsZero() {
+			query = query.Where(clause.Lt{Column: clause.Column{Name: job.Column}, Value: to})
+		}
+		rows := newRows(job.Model)
+		if err := query.Order(clause.OrderByColumn{Column: clause.Column{Name: pk.DBName}}).Limit(job.BatchSize).Find(rows).Error; err != nil {
+			return restored, fmt.Errorf("reading %%s: %%w", archive, err)
+		}
+		if reflect.ValueOf(rows).Elem().Len() == 0 {
+			return restored, nil
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			n, err := insert(tx, "", rows, job.BatchSize)
+			if err != nil {
+				return fmt.Errorf("inserting into %%s: %%w", s.Table, err)
+			}
+			restored += n
+			keys := primaryKeys(ctx, pk, rows)
+			return withoutHooks(tx).Unscoped().Table(archive).Where(clause.IN{Column: clause.Column{Name: pk.DBName}, Values: keys}).Delete(job.Model).Error
+		})
+		if err != nil {
+			return restored, err
+		}
+	}
+}
+`
+
+// archiveS3Source is internal/archive/s3.go, the S3 store of the jobs, written
+// with the first job using it. Its %s is the import path of the project.
+const archiveS3Source = `// Code generated by goca archive. DO NOT EDIT.
+
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/parquet-go/parquet-go"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"%s/pkg/config"
+)
+
+// Formats of the objects of S3Store.
+const (
+	// FormatParquet writes a Parquet file per batch, with the model's Go
+	// field names as columns.
+	FormatParquet = "parquet"
+	// FormatCSV writes a gzipped CSV file per batch, with the table's
+	// columns as header. NULL and empty strings are both written as "".
+	FormatCSV = "csv"
+)
+
+// runLayout names the directory of the objects of a run after its cutoff.
+const runLayout = "20060102T150405Z"
+
+// S3Store writes each batch to an object of cfg.Bucket, or of a bucket of a
+// compatible service such as MinIO when cfg.Endpoint is set:
+//
+//	<prefix>/<job>/<cutoff>/<batch>.parquet
+//
+// A batch retried after a failed commit overwrites its object. Credentials are
+// read from the environment, the shared AWS configuration or the instance
+// role. Restores keep the objects.
+type S3Store struct {
+	cfg    config.ArchiveConfig
+	format string
+	client *s3.Client
+}
+
+// NewS3Store returns an S3 store writing objects in format.
+func NewS3Store(cfg config.ArchiveConfig, format string) *S3Store {
+	return &S3Store{cfg: cfg, format: format}
+}
+
+// Prepare connects to the bucket.
+func (s *S3Store) Prepare(ctx context.Context, db *gorm.DB, job Job) error {
+	if s.cfg.Bucket == "" {
+		return errors.New("ARCHIVE_BUCKET is required by the S3 archive store")
+	}
+	if s.format != FormatParquet && s.format != FormatCSV {
+		return fmt.Errorf("unknown archive format %%q", s.format)
+	}
+	if s.client != nil {
+		return nil
+	}
+	var opts []func(*awsconfig.LoadOptions) error
+	if s.cfg.Region != "" {
+		opts = append(opts, awsconfig.WithRegion(s.cfg.Region))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("load AWS configuration: %%w", err)
+	}
+	s.client = s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if s.cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(s.cfg.Endpoint)
+			o.UsePathStyle = true
+		}
+	})
+	return nil
+}
+
+// Put uploads the batch.
+func (s *S3Store) Put(ctx context.Context, tx *gorm.DB, job Job, batch Batch) error {
+	sch, err := parse(tx, job)
+	if err != nil {
+		return err
+	}
+	var body []byte
+	if s.format == FormatCSV {
+		body, err = encodeCSV(ctx, sch, batch.Rows)
+	} else {
+		body, err = encodeParquet(job.Model, batch.Rows)
+	}
+	if err != nil {
+		return fmt.Errorf("encoding batch %%d: %%w", batch.Seq, err)
+	}
+	key := path.Join(s.jobPrefix(job), batch.Cutoff.UTC().Format(runLayout), fmt.Sprintf("%%06d.%%s", batch.Seq, s.extension()))
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.cfg.Bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String(s.contentType()),
+	})
+	if err != nil {
+		return fmt.Errorf("uploading %%s: %%w", key, err)
+	}
+	return nil
+}
+
+// Restore inserts the rows of the job's objects whose Column is in
+// [from, to). The objects of runs whose cutoff is not after from are
+// skipped, as all their rows are older.
+func (s *S3Store) Restore(ctx context.Context, db *gorm.DB, job Job, from, to time.Time) (int64, error) {
+	sch, err := parse(db, job)
+	if err != nil {
+		return 0, err
+	}
+	column := sch.LookUpField(job.Column)
+	var restored int64
+	pages := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.cfg.Bucket),
+		Prefix: aws.String(s.jobPrefix(job) + "/"),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return restored, fmt.Errorf("listing objects: %%w", err)
+		}
+		for _, object := range page.Contents {
+			key := aws.ToString(object.Key)
+			if cutoff, err := time.Parse(runLayout, path.Base(path.Dir(key))); err == nil && !from.IsZero() && !cutoff.After(from) {
+				continue
+			}
+			rows, err := s.read(ctx, sch, job, key)
+			if err != nil {
+				return restored, err
+			}
+
+			kept := reflect.ValueOf(newRows(job.Model))
+			slice := reflect.ValueOf(rows).Elem()
+			for i := 0; i < slice.Len(); i++ {
+				value, _ := column.ValueOf(ctx, slice.Index(i))
+				if t, ok := timeOf(value); ok && inRange(t, from, to) {
+					kept.Elem().Set(reflect.Append(kept.Elem(), slice.Index(i)))
+				}
+			}
+			n, err := insert(db, "", kept.Interface(), job.BatchSize)
+			if err != nil {
+				return restored, fmt.Errorf("restoring %%s: %%w", key, err)
+			}
+			restored += n
+		}
+	}
+	return restored, nil
+}
+
+// read downloads and decodes an object.
+func (s *S3Store) read(ctx context.Context, sch *schema.Schema, job Job, key string) (interface{}, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(s.cfg.Bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, fmt.Errorf("downloading %%s: %%w", key, err)
+	}
+	defer out.Body.Close()
+	body, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, fmt.Errorf("downloading %%s: %%w", key, err)
+	}
+	var rows interface{}
+	if strings.HasSuffix(key, ".csv.gz") {
+		rows, err = decodeCSV(ctx, sch, job.Model, body)
+	} else {
+		rows, err = decodeParquet(job.Model, body)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decoding %%s: %%w", key, err)
+	}
+	return rows, nil
+}
+
+// jobPrefix returns the prefix of the objects of job.
+func (s *S3Store) jobPrefix(job Job) string {
+	return path.Join(s.cfg.Prefix, job.Name)
+}
+
+func (s *S3Store) extension() string {
+	if s.format == FormatCSV {
+		return "csv.gz"
+	}
+	return "parquet"
+}
+
+func (s *S3Store) contentType() string {
+	if s.format == FormatCSV {
+		return "application/gzip"
+	}
+	return "application/vnd.apache.parquet"
+}
+
+// encodeParquet writes rows, a pointer to a slice of model, as a Parquet file.
+func encodeParquet(model, rows interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	w := parquet.NewWriter(&buf, parquet.SchemaOf(model))
+	slice := reflect.ValueOf(rows).Elem()
+	for i := 0; i < slice.Len(); i++ {
+		if err := w.Write(slice.Index(i).Interface()); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeParquet reads the rows of a Parquet file into a slice of model.
+func decodeParquet(model interface{}, body []byte) (interface{}, error) {
+	r := parquet.NewReader(bytes.NewReader(body), parquet.SchemaOf(model))
+	defer r.Close()
+	rows := reflect.ValueOf(newRows(model))
+	for {
+		row := reflect.New(rows.Type().Elem().Elem())
+		if err := r.Read(row.Interface()); errors.Is(err, io.EOF) {
+			return rows.Interface(), nil
+		} else if err != nil {
+			return nil, err
+		}
+		rows.Elem().Set(reflect.Append(rows.Elem(), row.Elem()))
+	}
+}
+
+// encodeCSV writes rows, a pointer to a slice of the model of sch, as a
+// gzipped CSV file with a header of column names.
+func encodeCSV(ctx context.Context, sch *schema.Schema, rows interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	w := csv.NewWriter(zw)
+	if err := w.Write(sch.DBNames); err != nil {
+		return nil, err
+	}
+	slice := reflect.ValueOf(rows).Elem()
+	record := make([]string, len(sch.DBNames))
+	for i := 0; i < slice.Len(); i++ {
+		for j, name := range sch.DBNames {
+			field := sch.FieldsByDBName[name]
+			value, _ := field.ValueOf(ctx, slice.Index(i))
+			cell, err := csvCell(field, value)
+			if err != nil {
+				return nil, fmt.Errorf("%%s: %%w", name, err)
+			}
+			record[j] = cell
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// csvCell formats a column value: times in RFC 3339, binary columns in
+// base64, NULL as "".
+func csvCell(field *schema.Field, value interface{}) (string, error) {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return "", err
+		}
+		value = v
+	}
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return "", nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return "", nil
+	}
+	switch v := rv.Interface().(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano), nil
+	case []byte:
+		if field.DataType == schema.Bytes {
+			return base64.StdEncoding.EncodeToString(v), nil
+		}
+		return string(v), nil
+	}
+	return fmt.Sprint(rv.Interface()), nil
+}
+
+// decodeCSV reads a gzipped CSV file into a slice of model. Columns the
+// model no longer has are ignored.
+func decodeCSV(ctx context.Context, sch *schema.Schema, model interface{}, body []byte) (interface{}, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	r := csv.NewReader(zr)
+	header, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	rows := reflect.ValueOf(newRows(model))
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return rows.Interface(), nil
+		} else if err != nil {
+			return nil, err
+		}
+		row := reflect.New(rows.Type().Elem().Elem()).Elem()
+		for i, name := range header {
+			field := sch.LookUpField(name)
+			if field == nil || i >= len(record) || record[i] == "" {
+				continue
+			}
+			if err := setCSVCell(ctx, field, row, record[i]); err != nil {
+				return nil, fmt.Errorf("%%s: %%w", name, err)
+			}
+		}
+		rows.Elem().Set(reflect.Append(rows.Elem(), row))
+	}
+}
+
+// setCSVCell sets a field of row to a cell written by csvCell.
+func setCSVCell(ctx context.Context, field *schema.Field, row reflect.Value, cell string) error {
+	switch field.DataType {
+	case schema.Time:
+		t, err := time.Parse(time.RFC3339Nano, cell)
+		if err != nil {
+			return err
+		}
+		return field.Set(ctx, row, t)
+	case schema.Bytes:
+		b, err := base64.StdEncoding.DecodeString(cell)
+		if err != nil {
+			return err
+		}
+		return field.Set(ctx, row, b)
+	}
+	return field.Set(ctx, row, cell)
+}
+`
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveJobValidate(t *testing.T) {
+	job := archiveJob{Entity: "Invoice", Store: ArchiveStoreS3, Format: ArchiveFormatCSV, Schedule: "@weekly", BatchSize: 500}
+	assert.NoError(t, job.validate())
+
+	bad := job
+	bad.Store = "glacier"
+	assert.ErrorContains(t, bad.validate(), `unsupported --store "glacier"`)
+	bad = job
+	bad.Format = "avro"
+	assert.ErrorContains(t, bad.validate(), `unsupported --format "avro"`)
+	bad = job
+	bad.Schedule = "nightly"
+	assert.Error(t, bad.validate())
+	bad = job
+	bad.BatchSize = 0
+	assert.ErrorContains(t, bad.validate(), "--batch-size")
+}
+
+func TestGenerateArchive(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	writeTestFile(t, ".", "go.mod", "module example.com/billing\n\ngo 1.22\n")
+	writeTestFile(t, ".", filepath.Join("pkg", "config", "config.go"), legacyConfig)
+	sm := NewSafetyManager(false, false, false)
+	require.NoError(t, generateEntity("Invoice", "number:string,total:float64", true, false, true, true, false, false, false, false, "lowercase", sm))
+
+	field, err := resolveArchiveColumn("Invoice", "createdat")
+	require.NoError(t, err)
+	assert.Equal(t, "CreatedAt", field.Name)
+	_, err = resolveArchiveColumn("Invoice", "Total")
+	assert.ErrorContains(t, err, "needs a time.Time field")
+	_, err = resolveArchiveColumn("Invoice", "PaidAt")
+	assert.ErrorContains(t, err, "goca field add Invoice")
+
+	job := archiveJob{Entity: "Invoice", Column: "created_at", OlderThan: 365 * 24 * time.Hour,
+		Store: ArchiveStoreTable, Format: ArchiveFormatParquet, Schedule: "0 3 * * *", BatchSize: 1000}
+	require.NoError(t, generateArchive(job, DBPostgres, sm))
+
+	source := readParsedGo(t, filepath.Join(archiveDir, "invoice.go"))
+	assert.Contains(t, source, "register(func(cfg config.ArchiveConfig) Job {")
+	assert.Contains(t, source, "Model:     &domain.Invoice{},")
+	assert.Contains(t, source, "OlderThan: 365 * 24 * time.Hour,")
+	assert.Contains(t, source, "Store:     TableStore{},")
+	assert.Contains(t, source, "// invoices_archive.")
+
+	runtime := readParsedGo(t, filepath.Join(archiveDir, "archive.go"))
+	assert.Contains(t, runtime, `"example.com/billing/pkg/config"`)
+	assert.Contains(t, runtime, `log.Printf("%s: resuming the run archiving rows before %s at batch %d"`)
+	assert.NoFileExists(t, filepath.Join(archiveDir, "s3.go"), "table jobs need no AWS SDK")
+
+	config := readParsedGo(t, filepath.Join(DirPkg, DirConfig, "config.go"))
+	assert.Contains(t, config, "Archive ArchiveConfig")
+	assert.Contains(t, config, `Prefix:   getEnv("ARCHIVE_PREFIX", "archive"),`)
+
+	main := readParsedGo(t, filepath.Join("cmd", "archive", "main.go"))
+	assert.Contains(t, main, `"example.com/billing/internal/archive"`)
+	assert.Contains(t, main, "archive.Serve(ctx, db, jobs)")
+	assert.Contains(t, main, "n, err := archive.Restore(ctx, db, job, start, end)")
+	assert.Contains(t, main, `"gorm.io/driver/postgres"`)
+
+	// The job is the developer's once written.
+	assert.Error(t, generateArchive(job, DBPostgres, sm))
+
+	job.Store, job.Format = ArchiveStoreS3, ArchiveFormatCSV
+	require.NoError(t, generateArchive(job, DBPostgres, NewSafetyManager(false, true, false)))
+	source = readParsedGo(t, filepath.Join(archiveDir, "invoice.go"))
+	assert.Contains(t, source, "Store:     NewS3Store(cfg, FormatCSV),")
+	s3 := readParsedGo(t, filepath.Join(archiveDir, "s3.go"))
+	assert.Contains(t, s3, "func NewS3Store(cfg config.ArchiveConfig, format string) *S3Store {")
+	assert.Contains(t, s3, `fmt.Sprintf("%06d.%s", batch.Seq, s.extension())`)
+}
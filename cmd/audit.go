@@ -0,0 +1,380 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// auditFields are the columns --audit adds to an entity: the users who created
+// it and changed it last. They are filled by the audited use case, never by
+// request bodies.
+var auditFields = []Field{
+	{Name: "CreatedBy", Type: "string", Tag: "`json:\"created_by\" gorm:\"size:255\"`"},
+	{Name: "UpdatedBy", Type: "string", Tag: "`json:\"updated_by\" gorm:\"size:255\"`"},
+}
+
+// isAuditedEntity reports whether the entity in internal/domain declares the
+// columns of --audit. Like isVersionedEntity, it lets the outer layers follow
+// the entity.
+func isAuditedEntity(entity string) bool {
+	for _, f := range auditFields {
+		if !entityDeclaresField(entity, f) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasAuditedUseCase reports whether an audit decorator
+// (audited_<entity>_usecase.go) was generated for the entity.
+func hasAuditedUseCase(feature string) bool {
+	path := filepath.Join(DirInternal, DirUseCase, "audited_"+strings.ToLower(feature)+"_usecase.go")
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return !info.IsDir()
+}
+
+// writeOnce writes a file shared by every audited entity unless it exists;
+// --force rewrites it.
+func writeOnce(filename, content, what string, sm ...*SafetyManager) {
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+	if _, err := os.Stat(filename); err == nil && !force {
+		return
+	}
+	if err := writeGoFile(filename, content, sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing %s: %v", what, err))
+	}
+}
+
+// generateAuditLogFile writes internal/domain/audit_log.go with the AuditLog
+// entity and the context helpers carrying the acting user.
+func generateAuditLogFile(dir string, sm ...*SafetyManager) {
+	writeOnce(filepath.Join(dir, "audit_log.go"), auditLogSource, "audit log entity", sm...)
+}
+
+const auditLogSource = `package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// AuditLog records one change of an audited entity: who made it and the
+// entity as JSON before and after the change.
+type AuditLog struct {
+	ID         uint            ` + "`json:\"id\" gorm:\"primaryKey;autoIncrement\"`" + `
+	EntityType string          ` + "`json:\"entity_type\" gorm:\"size:100;not null;index:idx_audit_logs_entity\"`" + `
+	EntityID   uint            ` + "`json:\"entity_id\" gorm:\"not null;index:idx_audit_logs_entity\"`" + `
+	Action     string          ` + "`json:\"action\" gorm:\"size:20;not null\"`" + `
+	Actor      string          ` + "`json:\"actor\" gorm:\"size:255\"`" + `
+	Before     json.RawMessage ` + "`json:\"before,omitempty\"`" + `
+	After      json.RawMessage ` + "`json:\"after,omitempty\"`" + `
+	CreatedAt  time.Time       ` + "`json:\"created_at\" gorm:\"autoCreateTime\"`" + `
+}
+
+// Actions recorded in the audit log.
+const (
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx carrying the user a request acts for.
+// Authentication middleware calls it once the user is known, e.g. with the
+// subject of a JWT.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the user stored by WithActor, or "" when none was.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+`
+
+// generateAuditLogRepository writes internal/repository/audit_log_repository.go
+// with the AuditLogRepository interface and its GORM implementation.
+func generateAuditLogRepository(dir string, sm ...*SafetyManager) {
+	importPath := getImportPath(getModuleName())
+	writeOnce(filepath.Join(dir, "audit_log_repository.go"), fmt.Sprintf(auditLogRepositorySource, importPath), "audit log repository", sm...)
+}
+
+const auditLogRepositorySource = `package repository
+
+import (
+	"context"
+
+	"%s/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// AuditLogRepository stores the changes of audited entities.
+type AuditLogRepository interface {
+	Record(ctx context.Context, log *domain.AuditLog) error
+	FindByEntity(ctx context.Context, entityType string, entityID int) ([]domain.AuditLog, error)
+}
+
+type gormAuditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository returns an AuditLogRepository on the audit_logs table.
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &gormAuditLogRepository{db: db}
+}
+
+func (r *gormAuditLogRepository) Record(ctx context.Context, log *domain.AuditLog) error {
+	return r.db.WithContext(ctx).Create(log).Error
+}
+
+// FindByEntity returns the changes of one entity, oldest first.
+func (r *gormAuditLogRepository) FindByEntity(ctx context.Context, entityType string, entityID int) ([]domain.AuditLog, error) {
+	var logs []domain.AuditLog
+	err := r.db.WithContext(ctx).
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("id").
+		Find(&logs).Error
+	return logs, err
+}
+`
+
+// generateAuditedUseCase writes internal/usecase/audited_<entity>_usecase.go:
+// a decorator of <Entity>UseCase that stamps CreatedBy and UpdatedBy with the
+// acting user and records JSON snapshots of every update and delete. Only the
+// operations the use case declares are decorated.
+func generateAuditedUseCase(dir, entity string, operations []string, sm ...*SafetyManager) {
+	importPath := getImportPath(getModuleName())
+	entityVar := strings.ToLower(entity)
+	iface := entity + "UseCase"
+	typeName := "audited" + iface
+	filename := filepath.Join(dir, "audited_"+entityVar+"_usecase.go")
+
+	updates := contains(operations, OpUpdate)
+	deletes := contains(operations, OpDelete)
+	snapshots := updates || deletes
+
+	var b strings.Builder
+	b.WriteString("package usecase\n\n")
+	b.WriteString("import (\n")
+	b.WriteString("\t\"context\"\n")
+	if snapshots {
+		b.WriteString("\t\"encoding/json\"\n")
+		b.WriteString("\t\"fmt\"\n")
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "\t\"%s/internal/domain\"\n", importPath)
+	fmt.Fprintf(&b, "\t\"%s/internal/repository\"\n", importPath)
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(&b, "// Audited%s is a %s that records who changes %s and keeps\n", iface, iface, toPlural(entityVar))
+	b.WriteString("// their history in the audit log.\n")
+	fmt.Fprintf(&b, "type Audited%s interface {\n", iface)
+	b.WriteString("\t" + iface + "\n")
+	b.WriteString("\t// As returns the use case acting for actor.\n")
+	fmt.Fprintf(&b, "\tAs(actor string) %s\n", iface)
+	fmt.Fprintf(&b, "\t// Get%sHistory returns the recorded changes of a %s, oldest first.\n", entity, entityVar)
+	fmt.Fprintf(&b, "\tGet%sHistory(id int) ([]domain.AuditLog, error)\n", entity)
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "type %s struct {\n", typeName)
+	b.WriteString("\t" + iface + "\n")
+	fmt.Fprintf(&b, "\trepo  repository.%sRepository\n", entity)
+	b.WriteString("\taudit repository.AuditLogRepository\n")
+	b.WriteString("\tactor string\n")
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// NewAudited%s wraps inner so its changes are recorded in audit.\n", iface)
+	fmt.Fprintf(&b, "func NewAudited%s(inner %s, repo repository.%sRepository, audit repository.AuditLogRepository) Audited%s {\n", iface, iface, entity, iface)
+	fmt.Fprintf(&b, "\treturn &%s{%s: inner, repo: repo, audit: audit}\n", typeName, iface)
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "func (a *%s) As(actor string) %s {\n", typeName, iface)
+	b.WriteString("\tacting := *a\n")
+	b.WriteString("\tacting.actor = actor\n")
+	b.WriteString("\treturn &acting\n")
+	b.WriteString("}\n\n")
+
+	if contains(operations, OpCreate) {
+		fmt.Fprintf(&b, "func (a *%s) Create%s(input Create%sInput) (Create%sOutput, error) {\n", typeName, entity, entity, entity)
+		b.WriteString("\tinput.CreatedBy = a.actor\n")
+		fmt.Fprintf(&b, "\treturn a.%s.Create%s(input)\n", iface, entity)
+		b.WriteString("}\n\n")
+	}
+
+	if updates {
+		fmt.Fprintf(&b, "func (a *%s) Update%s(id int, input Update%sInput) error {\n", typeName, entity, entity)
+		b.WriteString("\tbefore, err := a.snapshot(id)\n")
+		b.WriteString("\tif err != nil {\n\t\treturn err\n\t}\n")
+		b.WriteString("\tinput.UpdatedBy = a.actor\n")
+		fmt.Fprintf(&b, "\tif err := a.%s.Update%s(id, input); err != nil {\n", iface, entity)
+		b.WriteString("\t\treturn err\n\t}\n")
+		b.WriteString("\tafter, err := a.snapshot(id)\n")
+		b.WriteString("\tif err != nil {\n\t\treturn err\n\t}\n")
+		b.WriteString("\treturn a.record(id, domain.AuditActionUpdate, before, after)\n")
+		b.WriteString("}\n\n")
+	}
+
+	if deletes {
+		fmt.Fprintf(&b, "func (a *%s) Delete%s(id int) error {\n", typeName, entity)
+		b.WriteString("\tbefore, err := a.snapshot(id)\n")
+		b.WriteString("\tif err != nil {\n\t\treturn err\n\t}\n")
+		fmt.Fprintf(&b, "\tif err := a.%s.Delete%s(id); err != nil {\n", iface, entity)
+		b.WriteString("\t\treturn err\n\t}\n")
+		b.WriteString("\treturn a.record(id, domain.AuditActionDelete, before, nil)\n")
+		b.WriteString("}\n\n")
+	}
+
+	fmt.Fprintf(&b, "func (a *%s) Get%sHistory(id int) ([]domain.AuditLog, error) {\n", typeName, entity)
+	fmt.Fprintf(&b, "\treturn a.audit.FindByEntity(context.Background(), %q, id)\n", entity)
+	b.WriteString("}\n")
+
+	if snapshots {
+		fmt.Fprintf(&b, "\n// snapshot returns the stored %s as JSON.\n", entityVar)
+		fmt.Fprintf(&b, "func (a *%s) snapshot(id int) (json.RawMessage, error) {\n", typeName)
+		fmt.Fprintf(&b, "\t%s, err := a.repo.FindByID(id)\n", entityVar)
+		b.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+		fmt.Fprintf(&b, "\treturn json.Marshal(%s)\n", entityVar)
+		b.WriteString("}\n\n")
+
+		fmt.Fprintf(&b, "func (a *%s) record(id int, action string, before, after json.RawMessage) error {\n", typeName)
+		b.WriteString("\terr := a.audit.Record(context.Background(), &domain.AuditLog{\n")
+		fmt.Fprintf(&b, "\t\tEntityType: %q,\n", entity)
+		b.WriteString("\t\tEntityID:   uint(id),\n")
+		b.WriteString("\t\tAction:     action,\n")
+		b.WriteString("\t\tActor:      a.actor,\n")
+		b.WriteString("\t\tBefore:     before,\n")
+		b.WriteString("\t\tAfter:      after,\n")
+		b.WriteString("\t})\n")
+		b.WriteString("\tif err != nil {\n")
+		fmt.Fprintf(&b, "\t\treturn fmt.Errorf(\"recording %s %%s in the audit log: %%w\", action, err)\n", entityVar)
+		b.WriteString("\t}\n")
+		b.WriteString("\treturn nil\n")
+		b.WriteString("}\n")
+	}
+
+	if err := writeGoFile(filename, b.String(), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing audit decorator: %v", err))
+	}
+}
+
+// writeAuditInputField writes the field of a create or update DTO that carries
+// the acting user. It is never read from a request body.
+func writeAuditInputField(content *strings.Builder, name string) {
+	fmt.Fprintf(content, "\t%s string `json:\"-\"`\n", name)
+}
+
+// writeAuditCreateMapping writes the composite literal elements stamping a
+// new entity with the user who creates it.
+func writeAuditCreateMapping(content *strings.Builder) {
+	fmt.Fprintf(content, "\t\t%[1]s: input.%[1]s,\n", auditFields[0].Name)
+	fmt.Fprintf(content, "\t\t%s: input.%s,\n", auditFields[1].Name, auditFields[0].Name)
+}
+
+// writeAuditCreateAssignments writes the statements stamping the entityVar
+// entity built by its constructor, like writeAuditCreateMapping.
+func writeAuditCreateAssignments(content *strings.Builder, entityVar string) {
+	fmt.Fprintf(content, "\t%s.%[2]s = input.%[2]s\n", entityVar, auditFields[0].Name)
+	fmt.Fprintf(content, "\t%s.%s = input.%s\n", entityVar, auditFields[1].Name, auditFields[0].Name)
+}
+
+// writeAuditUpdateMapping writes the statement stamping an updated entity
+// with the user who changes it. Callers that do not act for a user, such as
+// an unaudited use case, leave UpdatedBy as it was.
+func writeAuditUpdateMapping(content *strings.Builder, entityVar string) {
+	fmt.Fprintf(content, "\tif input.%s != \"\" {\n", auditFields[1].Name)
+	fmt.Fprintf(content, "\t\t%s.%[2]s = input.%[2]s\n", entityVar, auditFields[1].Name)
+	content.WriteString("\t}\n")
+}
+
+// generateHTTPActorFile writes internal/handler/http/actor.go with the helper
+// audited handlers use to find who a request acts for.
+func generateHTTPActorFile(dir string, sm ...*SafetyManager) {
+	importPath := getImportPath(getModuleName())
+	writeOnce(filepath.Join(dir, "actor.go"), fmt.Sprintf(httpActorSource, DirHTTP, importPath), "actor helper", sm...)
+}
+
+const httpActorSource = `package %s
+
+import (
+	"net/http"
+
+	"%s/internal/domain"
+)
+
+// actorFromRequest returns who a request acts for: the user an authentication
+// middleware stored with domain.WithActor, or "anonymous".
+func actorFromRequest(r *http.Request) string {
+	if actor := domain.ActorFromContext(r.Context()); actor != "" {
+		return actor
+	}
+	return "anonymous"
+}
+`
+
+// writeActingUseCaseMethod writes the handler method returning the use case
+// acting for the user of a request. Handlers built on a use case that is not
+// audited keep using it as is.
+func writeActingUseCaseMethod(content *strings.Builder, entity, handlerName string) {
+	handlerVar := strings.ToLower(string(handlerName[0]))
+	fmt.Fprintf(content, "// acting returns the use case acting for the user of r, so audited changes\n")
+	content.WriteString("// record who made them.\n")
+	fmt.Fprintf(content, "func (%s *%s) acting(r *http.Request) usecase.%sUseCase {\n", handlerVar, handlerName, entity)
+	fmt.Fprintf(content, "\tif audited, ok := %s.usecase.(usecase.Audited%sUseCase); ok {\n", handlerVar, entity)
+	content.WriteString("\t\treturn audited.As(actorFromRequest(r))\n")
+	content.WriteString("\t}\n")
+	fmt.Fprintf(content, "\treturn %s.usecase\n", handlerVar)
+	content.WriteString("}\n\n")
+}
+
+// handlerUseCase returns the expression a handler method calls the use case
+// through: the use case acting for the request's user or tenant when acting.
+func handlerUseCase(handlerVar string, acting bool) string {
+	if acting {
+		return handlerVar + ".acting(r)"
+	}
+	return handlerVar + ".usecase"
+}
+
+// generateHistoryHandlerMethod writes GET /<entities>/{id}/history, which
+// answers the audit log of one entity.
+func generateHistoryHandlerMethod(content *strings.Builder, entity, handlerName string, swagger bool, format string) {
+	handlerVar := strings.ToLower(string(handlerName[0]))
+	entityLower := strings.ToLower(entity)
+
+	if swagger {
+		writeSwaggerAnnotations(content, entity, fmt.Sprintf("Get %s history", entityLower), "get", "/"+toPlural(entityLower)+"/{id}/history", "200", "[]domain.AuditLog", "", format)
+	}
+
+	fmt.Fprintf(content, "func (%s *%s) Get%sHistory(w http.ResponseWriter, r *http.Request) {\n", handlerVar, handlerName, entity)
+	fmt.Fprintf(content, "\taudited, ok := %s.usecase.(usecase.Audited%sUseCase)\n", handlerVar, entity)
+	content.WriteString("\tif !ok {\n")
+	writeHandlerError(content, format, "http.StatusNotFound", fmt.Sprintf("\"%s history is not recorded\"", entityLower))
+	content.WriteString("\t\treturn\n")
+	content.WriteString("\t}\n\n")
+	content.WriteString("\tvars := mux.Vars(r)\n")
+	content.WriteString("\tid, err := strconv.Atoi(vars[\"id\"])\n")
+	content.WriteString("\tif err != nil {\n")
+	writeHandlerError(content, format, "http.StatusBadRequest", fmt.Sprintf("\"Invalid %s ID\"", entityLower))
+	content.WriteString("\t\treturn\n")
+	content.WriteString("\t}\n\n")
+	fmt.Fprintf(content, "\thistory, err := audited.Get%sHistory(id)\n", entity)
+	content.WriteString("\tif err != nil {\n")
+	writeUseCaseError(content, format, "http.StatusInternalServerError")
+	content.WriteString("\t\treturn\n")
+	content.WriteString("\t}\n\n")
+	if format == ResponseFormatRaw {
+		content.WriteString("\tw.Header().Set(\"Content-Type\", \"application/json\")\n")
+		content.WriteString("\tjson.NewEncoder(w).Encode(history)\n")
+	} else {
+		content.WriteString("\trespond(w, http.StatusOK, history)\n")
+	}
+	content.WriteString("}\n\n")
+}
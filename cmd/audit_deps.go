@@ -0,0 +1,498 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Kinds of dependency advice, in the order the report lists them.
+const (
+	depAdviceSecurity = "security"
+	depAdviceReplace  = "replace"
+	depAdviceUpgrade  = "upgrade"
+)
+
+// govulncheckInstall is the command that installs govulncheck.
+const govulncheckInstall = "go install golang.org/x/vuln/cmd/govulncheck@latest"
+
+// matrixEntry is one module of the curated dependency matrix.
+type matrixEntry struct {
+	// Recommended is the version goca generates and tests against.
+	Recommended string
+	// Minimum is the oldest version free of the advisories in Advisory.
+	Minimum  string
+	Advisory string
+	// Successor replaces a deprecated or unmaintained module.
+	Successor string
+}
+
+// goModRequire is a require directive of a go.mod file.
+type goModRequire struct {
+	Path     string
+	Version  string
+	Indirect bool
+}
+
+// vulnFinding is a govulncheck finding merged per module.
+type vulnFinding struct {
+	Module  string
+	Version string
+	Fixed   string
+	IDs     []string
+	Called  bool
+}
+
+// depAdvice is one line of the audit report.
+type depAdvice struct {
+	Module  string
+	Current string
+	Target  string
+	Kind    string
+	Reason  string
+	// Safe bumps stay within the current major version; --apply runs them.
+	Safe bool
+}
+
+var auditDepsCmd = &cobra.Command{
+	Use:   "audit-deps",
+	Short: "Check go.mod against the curated dependency matrix and known vulnerabilities",
+	Long: `Compares the requirements of the project's go.mod with the dependency matrix
+goca generates and tests against, and with the vulnerabilities govulncheck
+reports for the code, then prints upgrade guidance per module:
+
+  security  the version has a known vulnerability; upgrade to the fixed version
+  replace   the module is deprecated or unmaintained; move to its successor
+  upgrade   a direct dependency is older than the version goca recommends
+
+govulncheck runs when it is on the PATH (install it with
+'` + govulncheckInstall + `'); otherwise only the matrix is
+checked. --apply runs 'go get' for the bumps that stay within the current
+major version, then 'go mod tidy'. Replacements and Go toolchain upgrades are
+left to you. The command fails while security advice remains.
+
+Examples:
+  goca audit-deps
+  goca audit-deps --security-only
+  goca audit-deps --apply
+  goca audit-deps --no-vulncheck`,
+	Args: cobra.NoArgs,
+	RunE: runAuditDeps,
+}
+
+func init() {
+	auditDepsCmd.Flags().Bool("apply", false, "Run go get for the safe bumps, then go mod tidy")
+	auditDepsCmd.Flags().Bool("security-only", false, "Only report and apply security advice")
+	auditDepsCmd.Flags().Bool("no-vulncheck", false, "Skip govulncheck and only check the curated matrix")
+}
+
+func runAuditDeps(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	apply, _ := cmd.Flags().GetBool("apply")
+	securityOnly, _ := cmd.Flags().GetBool("security-only")
+	noVulncheck, _ := cmd.Flags().GetBool("no-vulncheck")
+
+	root, ok := findModuleRoot(".")
+	if !ok {
+		return errors.New("go.mod not found; run goca audit-deps inside a generated project")
+	}
+	data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return err
+	}
+	requires := parseGoModRequires(data)
+
+	ui.Header("Goca Dependency Audit")
+	ui.KeyValue("Module", parseModulePath(data))
+	ui.KeyValue("Requirements", fmt.Sprintf("%d", len(requires)))
+
+	var findings []vulnFinding
+	if noVulncheck {
+		ui.Dim("govulncheck skipped (--no-vulncheck)")
+	} else if _, err := exec.LookPath("govulncheck"); err != nil {
+		ui.Warning("govulncheck not found; only the curated matrix was checked")
+		ui.Dim("   Install it with: " + govulncheckInstall)
+	} else {
+		stop := ui.Spinner("Running govulncheck")
+		findings, err = runGovulncheck(root)
+		stop()
+		if err != nil {
+			ui.Warning(fmt.Sprintf("govulncheck failed, only the curated matrix was checked: %v", err))
+		}
+	}
+
+	advice := auditDependencies(requires, findings, dependencyMatrix())
+	if securityOnly {
+		advice = filterAdvice(advice, depAdviceSecurity)
+	}
+
+	ui.Blank()
+	if len(advice) == 0 {
+		ui.Success("All dependencies match the matrix and have no known vulnerabilities")
+		return nil
+	}
+	printDependencyAdvice(advice)
+
+	if apply {
+		if err := applyDependencyBumps(root, advice); err != nil {
+			return err
+		}
+		advice = unappliedAdvice(advice)
+	}
+
+	if remaining := len(filterAdvice(advice, depAdviceSecurity)); remaining > 0 {
+		return fmt.Errorf("%d module(s) with known vulnerabilities need an upgrade", remaining)
+	}
+	return nil
+}
+
+// dependencyMatrix returns the curated matrix: the versions goca writes to
+// generated go.mod files and feature dependencies, the security floors of
+// modules generated projects commonly pull in, and deprecated modules.
+func dependencyMatrix() map[string]matrixEntry {
+	matrix := map[string]matrixEntry{
+		// Modules createGoMod writes on init.
+		"github.com/gorilla/mux":                                       {Recommended: "v1.8.0"},
+		"gorm.io/gorm":                                                 {Recommended: "v1.25.5"},
+		"gorm.io/driver/postgres":                                      {Recommended: "v1.5.4"},
+		"gorm.io/driver/mysql":                                         {Recommended: "v1.5.2"},
+		"gorm.io/driver/sqlite":                                        {Recommended: "v1.5.4"},
+		"gorm.io/driver/sqlserver":                                     {Recommended: "v1.5.2"},
+		oracleDriverModule:                                             {Recommended: oracleDriverVersion},
+		"github.com/glebarez/sqlite":                                   {Recommended: "v1.11.0"},
+		"go.mongodb.org/mongo-driver":                                  {Recommended: "v1.12.1"},
+		"github.com/elastic/go-elasticsearch/v8":                       {Recommended: "v8.10.1"},
+		"github.com/aws/aws-sdk-go-v2/credentials":                     {Recommended: "v1.17.67"},
+		"github.com/aws/aws-sdk-go-v2/service/dynamodb":                {Recommended: "v1.43.1"},
+		"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue": {Recommended: "v1.18.12"},
+		"github.com/golang-jwt/jwt/v4":                                 {Recommended: "v4.5.2"},
+		"github.com/coreos/go-oidc/v3":                                 {Recommended: "v3.12.0"},
+
+		// Security floors.
+		"golang.org/x/net":             {Minimum: "v0.33.0", Advisory: "CVE-2024-45338: quadratic parsing of malformed HTML"},
+		"github.com/jackc/pgx/v5":      {Minimum: "v5.5.4", Advisory: "CVE-2024-27304: SQL injection through protocol message size overflow"},
+		"github.com/gorilla/websocket": {Minimum: "v1.4.1", Advisory: "CVE-2020-27813: integer overflow on crafted frames"},
+
+		// Deprecated and unmaintained modules.
+		"github.com/golang/mock":      {Successor: "go.uber.org/mock"},
+		"github.com/dgrijalva/jwt-go": {Successor: "github.com/golang-jwt/jwt/v5"},
+		"github.com/go-redis/redis":   {Successor: "github.com/redis/go-redis/v9"},
+		"gopkg.in/mgo.v2":             {Successor: "go.mongodb.org/mongo-driver"},
+		"github.com/astaxie/beego":    {Successor: "github.com/beego/beego/v2"},
+	}
+
+	// Feature dependencies installed through the dependency manager.
+	for _, dep := range NewDependencyManager(".", true).CommonDependencies() {
+		entry := matrix[dep.Module]
+		entry.Recommended = dep.Version
+		matrix[dep.Module] = entry
+	}
+
+	floors := []struct{ module, minimum, advisory string }{
+		{"github.com/golang-jwt/jwt/v4", "v4.5.2", "CVE-2025-30204: excessive memory allocation while parsing headers"},
+		{"github.com/golang-jwt/jwt/v5", "v5.2.2", "CVE-2025-30204: excessive memory allocation while parsing headers"},
+		{"golang.org/x/crypto", "v0.31.0", "CVE-2024-45337: authorization bypass through misused ssh PublicKeyCallback"},
+		{"google.golang.org/protobuf", "v1.33.0", "CVE-2024-24786: protojson.Unmarshal loops forever on invalid JSON"},
+		{"google.golang.org/grpc", "v1.58.3", "CVE-2023-44487: HTTP/2 rapid reset denial of service"},
+	}
+	for _, floor := range floors {
+		entry := matrix[floor.module]
+		entry.Minimum, entry.Advisory = floor.minimum, floor.advisory
+		matrix[floor.module] = entry
+	}
+	return matrix
+}
+
+// parseGoModRequires returns the require directives of a go.mod file, in
+// both the single-line and the parenthesised form.
+func parseGoModRequires(data []byte) []goModRequire {
+	var requires []goModRequire
+	inBlock := false
+	for _, line := range strings.Split(string(data), "\n") {
+		code, comment, _ := strings.Cut(line, "//")
+		code = strings.TrimSpace(code)
+
+		switch {
+		case inBlock && code == ")":
+			inBlock = false
+			continue
+		case code == "require (" || code == "require(":
+			inBlock = true
+			continue
+		case !inBlock && strings.HasPrefix(code, "require "):
+			code = strings.TrimSpace(strings.TrimPrefix(code, "require "))
+		case !inBlock:
+			continue
+		}
+
+		fields := strings.Fields(code)
+		if len(fields) != 2 {
+			continue
+		}
+		requires = append(requires, goModRequire{
+			Path:     unquoteModulePath(fields[0]),
+			Version:  fields[1],
+			Indirect: strings.TrimSpace(comment) == "indirect",
+		})
+	}
+	return requires
+}
+
+// runGovulncheck runs govulncheck on every package of the module at root.
+func runGovulncheck(root string) ([]vulnFinding, error) {
+	cmd := exec.Command("govulncheck", "-json", "./...")
+	cmd.Dir = root
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w\n%s", err, stderr.String())
+	}
+	return parseGovulncheck(output)
+}
+
+// parseGovulncheck merges the findings of a govulncheck -json stream per
+// module. A finding whose trace reaches a function is reported as called.
+func parseGovulncheck(data []byte) ([]vulnFinding, error) {
+	type frame struct {
+		Module   string `json:"module"`
+		Version  string `json:"version"`
+		Function string `json:"function"`
+	}
+	type message struct {
+		Finding *struct {
+			OSV          string  `json:"osv"`
+			FixedVersion string  `json:"fixed_version"`
+			Trace        []frame `json:"trace"`
+		} `json:"finding"`
+	}
+
+	byModule := map[string]*vulnFinding{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var msg message
+		if err := decoder.Decode(&msg); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid govulncheck output: %w", err)
+		}
+		if msg.Finding == nil || len(msg.Finding.Trace) == 0 {
+			continue
+		}
+
+		top := msg.Finding.Trace[0]
+		finding := byModule[top.Module]
+		if finding == nil {
+			finding = &vulnFinding{Module: top.Module, Version: top.Version}
+			byModule[top.Module] = finding
+		}
+		if !contains(finding.IDs, msg.Finding.OSV) {
+			finding.IDs = append(finding.IDs, msg.Finding.OSV)
+		}
+		if compareVersions(msg.Finding.FixedVersion, finding.Fixed) > 0 {
+			finding.Fixed = msg.Finding.FixedVersion
+		}
+		if top.Function != "" {
+			finding.Called = true
+		}
+	}
+
+	findings := make([]vulnFinding, 0, len(byModule))
+	for _, finding := range byModule {
+		sort.Strings(finding.IDs)
+		findings = append(findings, *finding)
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Module < findings[j].Module })
+	return findings, nil
+}
+
+// auditDependencies turns the requirements, the govulncheck findings and
+// the matrix into advice, security first. Indirect requirements only get
+// security advice; their versions follow the direct ones.
+func auditDependencies(requires []goModRequire, findings []vulnFinding, matrix map[string]matrixEntry) []depAdvice {
+	vulns := map[string]vulnFinding{}
+	for _, finding := range findings {
+		vulns[finding.Module] = finding
+	}
+
+	var advice []depAdvice
+	for _, req := range requires {
+		entry := matrix[req.Path]
+		finding, vulnerable := vulns[req.Path]
+		delete(vulns, req.Path)
+
+		switch {
+		case vulnerable || (entry.Minimum != "" && compareVersions(req.Version, entry.Minimum) < 0):
+			target, reasons := entry.Minimum, []string{}
+			if entry.Minimum != "" && compareVersions(req.Version, entry.Minimum) < 0 {
+				reasons = append(reasons, entry.Advisory)
+			}
+			if vulnerable {
+				reasons = append(reasons, vulnReason(finding))
+				if compareVersions(finding.Fixed, target) > 0 {
+					target = finding.Fixed
+				}
+			}
+			if compareVersions(entry.Recommended, target) > 0 {
+				target = entry.Recommended
+			}
+			advice = append(advice, newDepAdvice(req, target, depAdviceSecurity, strings.Join(reasons, "; ")))
+		case entry.Successor != "":
+			advice = append(advice, depAdvice{
+				Module:  req.Path,
+				Current: req.Version,
+				Target:  entry.Successor,
+				Kind:    depAdviceReplace,
+				Reason:  "deprecated; migrate to " + entry.Successor,
+			})
+		case !req.Indirect && entry.Recommended != "" && compareVersions(req.Version, entry.Recommended) < 0:
+			advice = append(advice, newDepAdvice(req, entry.Recommended, depAdviceUpgrade, "older than the version goca generates"))
+		}
+	}
+
+	// Findings outside go.mod, such as the standard library, need a newer Go.
+	for _, finding := range findings {
+		if _, ok := vulns[finding.Module]; !ok {
+			continue
+		}
+		target := finding.Fixed
+		if finding.Module == "stdlib" {
+			target = "go " + strings.TrimPrefix(finding.Fixed, "v")
+		}
+		advice = append(advice, depAdvice{
+			Module:  finding.Module,
+			Current: finding.Version,
+			Target:  target,
+			Kind:    depAdviceSecurity,
+			Reason:  vulnReason(finding),
+		})
+	}
+
+	order := map[string]int{depAdviceSecurity: 0, depAdviceReplace: 1, depAdviceUpgrade: 2}
+	sort.SliceStable(advice, func(i, j int) bool { return order[advice[i].Kind] < order[advice[j].Kind] })
+	return advice
+}
+
+// newDepAdvice builds a version bump of req to target.
+func newDepAdvice(req goModRequire, target, kind, reason string) depAdvice {
+	return depAdvice{
+		Module:  req.Path,
+		Current: req.Version,
+		Target:  target,
+		Kind:    kind,
+		Reason:  reason,
+		Safe:    target != "" && semverMajor(req.Version) == semverMajor(target),
+	}
+}
+
+// vulnReason describes a govulncheck finding.
+func vulnReason(finding vulnFinding) string {
+	reach := "required"
+	if finding.Called {
+		reach = "called"
+	}
+	return fmt.Sprintf("%s (%s)", strings.Join(finding.IDs, ", "), reach)
+}
+
+// semverMajor returns the major version of v, e.g. "v1" for v1.8.0.
+func semverMajor(v string) string {
+	major, _, _ := strings.Cut(strings.TrimSuffix(v, "+incompatible"), ".")
+	return major
+}
+
+// filterAdvice keeps the advice of one kind.
+func filterAdvice(advice []depAdvice, kind string) []depAdvice {
+	var kept []depAdvice
+	for _, a := range advice {
+		if a.Kind == kind {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
+
+// unappliedAdvice drops the advice --apply took care of.
+func unappliedAdvice(advice []depAdvice) []depAdvice {
+	var kept []depAdvice
+	for _, a := range advice {
+		if !a.Safe {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
+
+// printDependencyAdvice prints the report table followed by the commands
+// that carry it out.
+func printDependencyAdvice(advice []depAdvice) {
+	rows := make([][]string, len(advice))
+	for i, a := range advice {
+		target := a.Target
+		if target == "" {
+			target = "—"
+		}
+		rows[i] = []string{a.Kind, a.Module, a.Current, target, a.Reason}
+	}
+	ui.Table([]string{"Advice", "Module", "Current", "Target", "Reason"}, rows)
+
+	var gets, manual []string
+	for _, a := range advice {
+		switch {
+		case a.Safe:
+			gets = append(gets, a.Module+"@"+a.Target)
+		case a.Kind == depAdviceReplace:
+			manual = append(manual, fmt.Sprintf("Replace %s with %s and update its imports", a.Module, a.Target))
+		case a.Target == "":
+			manual = append(manual, fmt.Sprintf("No fixed version of %s yet; check whether the vulnerable code is reachable", a.Module))
+		case a.Module == "stdlib":
+			manual = append(manual, fmt.Sprintf("Build with %s or newer", a.Target))
+		default:
+			manual = append(manual, fmt.Sprintf("Upgrade %s to %s (new major version: review its changelog)", a.Module, a.Target))
+		}
+	}
+
+	ui.Blank()
+	if len(gets) > 0 {
+		ui.Info("Safe bumps (goca audit-deps --apply runs them):")
+		ui.Dim("   go get " + strings.Join(gets, " ") + " && go mod tidy")
+	}
+	for _, step := range manual {
+		ui.Info(step)
+	}
+}
+
+// applyDependencyBumps runs go get for every safe bump, then go mod tidy.
+func applyDependencyBumps(root string, advice []depAdvice) error {
+	args := []string{"get"}
+	for _, a := range advice {
+		if a.Safe {
+			args = append(args, a.Module+"@"+a.Target)
+		}
+	}
+	if len(args) == 1 {
+		ui.Info("No safe bumps to apply")
+		return nil
+	}
+
+	stop := ui.Spinner(fmt.Sprintf("Applying %d dependency bump(s)", len(args)-1))
+	cmd := exec.Command("go", args...)
+	cmd.Dir = root
+	output, err := cmd.CombinedOutput()
+	stop()
+	if err != nil {
+		return fmt.Errorf("go %s failed: %w\n%s", strings.Join(args, " "), err, output)
+	}
+	for _, bump := range args[1:] {
+		ui.Success("Upgraded " + bump)
+	}
+	return NewDependencyManager(root, false).UpdateGoMod()
+}
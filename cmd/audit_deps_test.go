@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGoModRequires(t *testing.T) {
+	t.Parallel()
+	requires := parseGoModRequires([]byte(`module example.com/shop
+
+go 1.21
+
+require github.com/gorilla/mux v1.8.0 // router
+
+require (
+	gorm.io/gorm v1.25.5
+	// golang.org/x/old v0.1.0
+	"github.com/jackc/pgx/v5" v5.4.3 // indirect
+)
+
+replace gorm.io/gorm => ../gorm
+`))
+	assert.Equal(t, []goModRequire{
+		{Path: "github.com/gorilla/mux", Version: "v1.8.0"},
+		{Path: "gorm.io/gorm", Version: "v1.25.5"},
+		{Path: "github.com/jackc/pgx/v5", Version: "v5.4.3", Indirect: true},
+	}, requires)
+}
+
+func TestParseGovulncheck(t *testing.T) {
+	t.Parallel()
+	findings, err := parseGovulncheck([]byte(`{"config": {"scanner_name": "govulncheck"}}
+{"osv": {"id": "GO-2024-2611", "summary": "Infinite loop in JSON unmarshaling"}}
+{"finding": {"osv": "GO-2024-2611", "fixed_version": "v1.33.0", "trace": [{"module": "google.golang.org/protobuf", "version": "v1.31.0"}]}}
+{"finding": {"osv": "GO-2024-2611", "fixed_version": "v1.33.0", "trace": [{"module": "google.golang.org/protobuf", "version": "v1.31.0", "package": "google.golang.org/protobuf/encoding/protojson", "function": "Unmarshal"}]}}
+{
+  "finding": {"osv": "GO-2025-3487", "fixed_version": "v1.23.6", "trace": [{"module": "stdlib", "version": "v1.23.1"}]}
+}
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []vulnFinding{
+		{Module: "google.golang.org/protobuf", Version: "v1.31.0", Fixed: "v1.33.0", IDs: []string{"GO-2024-2611"}, Called: true},
+		{Module: "stdlib", Version: "v1.23.1", Fixed: "v1.23.6", IDs: []string{"GO-2025-3487"}},
+	}, findings)
+
+	_, err = parseGovulncheck([]byte(`{"finding": `))
+	assert.Error(t, err)
+}
+
+func TestAuditDependencies(t *testing.T) {
+	t.Parallel()
+	requires := []goModRequire{
+		{Path: "github.com/golang-jwt/jwt/v5", Version: "v5.2.0"},
+		{Path: "github.com/golang/mock", Version: "v1.6.0"},
+		{Path: "gorm.io/gorm", Version: "v1.24.0"},
+		{Path: "gorm.io/driver/postgres", Version: "v1.5.4"},
+		{Path: "github.com/jackc/pgx/v5", Version: "v5.4.3", Indirect: true},
+		{Path: "github.com/jinzhu/now", Version: "v1.1.5", Indirect: true},
+		{Path: "github.com/rs/cors", Version: "v1.10.0", Indirect: true},
+		{Path: "example.com/legacy", Version: "v1.2.0"},
+	}
+	findings := []vulnFinding{
+		{Module: "example.com/legacy", Version: "v1.2.0", Fixed: "v2.0.0", IDs: []string{"GO-2025-0001"}},
+		{Module: "stdlib", Version: "v1.23.1", Fixed: "v1.23.6", IDs: []string{"GO-2025-3487"}, Called: true},
+	}
+
+	advice := auditDependencies(requires, findings, dependencyMatrix())
+	assert.Equal(t, []depAdvice{
+		{Module: "github.com/golang-jwt/jwt/v5", Current: "v5.2.0", Target: "v5.2.2", Kind: depAdviceSecurity, Reason: "CVE-2025-30204: excessive memory allocation while parsing headers", Safe: true},
+		{Module: "github.com/jackc/pgx/v5", Current: "v5.4.3", Target: "v5.5.4", Kind: depAdviceSecurity, Reason: "CVE-2024-27304: SQL injection through protocol message size overflow", Safe: true},
+		{Module: "example.com/legacy", Current: "v1.2.0", Target: "v2.0.0", Kind: depAdviceSecurity, Reason: "GO-2025-0001 (required)"},
+		{Module: "stdlib", Current: "v1.23.1", Target: "go 1.23.6", Kind: depAdviceSecurity, Reason: "GO-2025-3487 (called)"},
+		{Module: "github.com/golang/mock", Current: "v1.6.0", Target: "go.uber.org/mock", Kind: depAdviceReplace, Reason: "deprecated; migrate to go.uber.org/mock"},
+		{Module: "gorm.io/gorm", Current: "v1.24.0", Target: "v1.25.5", Kind: depAdviceUpgrade, Reason: "older than the version goca generates", Safe: true},
+	}, advice)
+
+	assert.Len(t, filterAdvice(advice, depAdviceSecurity), 4)
+	assert.Len(t, unappliedAdvice(advice), 3)
+}
+
+// TestDependencyMatrixCoversGeneratedGoMod keeps the matrix in step with the
+// go.mod files init writes, and goca's own versions above its security floors.
+func TestDependencyMatrixCoversGeneratedGoMod(t *testing.T) {
+	defer setupDiscardUI(t)()
+	chdirTemp(t)
+	matrix := dependencyMatrix()
+
+	for _, database := range ValidDatabases {
+		for _, authMode := range append([]string{""}, ValidAuthModes...) {
+			dir := fmt.Sprintf("%s-%s", database, authMode)
+			createGoMod(dir, "example.com/"+database, database, "", authMode, false, NewSafetyManager(false, true, false))
+			data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+			require.NoError(t, err)
+
+			for _, req := range parseGoModRequires(data) {
+				entry, ok := matrix[req.Path]
+				if assert.True(t, ok, "%s: %s missing from the matrix", database, req.Path) {
+					assert.Equal(t, req.Version, entry.Recommended, "%s: %s", database, req.Path)
+				}
+			}
+		}
+	}
+
+	for module, entry := range matrix {
+		if entry.Recommended != "" && entry.Minimum != "" {
+			assert.GreaterOrEqual(t, compareVersions(entry.Recommended, entry.Minimum), 0, "%s recommends a vulnerable version", module)
+		}
+	}
+}
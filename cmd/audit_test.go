@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditedEntity(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+
+	require.NoError(t, generateEntity("Product", "name:string,price:float64", true, false, false, false, false, true, false, false, "lowercase"))
+	entity := readParsedGo(t, filepath.Join(DirInternal, DirDomain, "product.go"))
+	assert.Regexp(t, "CreatedBy +string +`json:\"created_by\" gorm:\"size:255\"`", entity)
+	assert.Regexp(t, "UpdatedBy +string +`json:\"updated_by\" gorm:\"size:255\"`", entity)
+	assert.NotContains(t, entity, "p.CreatedBy", "the audit columns are not validated")
+
+	auditLog := readParsedGo(t, filepath.Join(DirInternal, DirDomain, "audit_log.go"))
+	assert.Contains(t, auditLog, "type AuditLog struct")
+	assert.Contains(t, auditLog, "func WithActor(ctx context.Context, actor string) context.Context")
+
+	assert.True(t, isAuditedEntity("Product"))
+	assert.Equal(t, "name:string,price:float64", readEntityFieldsString("Product"))
+
+	require.NoError(t, generateEntity("Order", "total:float64", true, false, false, false, false, false, false, false, "lowercase"))
+	assert.False(t, isAuditedEntity("Order"))
+}
+
+func TestAuditedLayers(t *testing.T) {
+	defer ensureTestUI(t)()
+	setupCLIProject(t, fullProductUseCase)
+	require.NoError(t, generateEntity("Product", "name:string,price:float64", true, false, false, false, false, true, false, false, "lowercase", NewSafetyManager(false, true, false)))
+	fields := "name:string,price:float64"
+
+	usecaseDir := filepath.Join(DirInternal, DirUseCase)
+	generateDTOFileWithFields(usecaseDir, "Product", []string{OpCreate, OpUpdate}, false, fields, DTOStyleNested)
+	dto := readParsedGo(t, filepath.Join(usecaseDir, "dto.go"))
+	assert.Regexp(t, "CreatedBy +string +`json:\"-\"`", dto)
+	assert.Regexp(t, "UpdatedBy +string +`json:\"-\"`", dto)
+
+	generateUseCaseServiceWithFields(usecaseDir, "ProductUseCase", "Product", []string{OpCreate, OpUpdate}, false, false, fields, DTOStyleNested)
+	service := readParsedGo(t, filepath.Join(usecaseDir, "product_service.go"))
+	assert.Regexp(t, `CreatedBy: +input\.CreatedBy,`, service)
+	assert.Contains(t, service, "if input.UpdatedBy != \"\" {\n\t\tproduct.UpdatedBy = input.UpdatedBy")
+
+	generateAuditedUseCase(usecaseDir, "Product", []string{OpCreate, OpRead, OpUpdate, OpDelete}, NewSafetyManager(false, true, false))
+	audited := readParsedGo(t, filepath.Join(usecaseDir, "audited_product_usecase.go"))
+	assert.Contains(t, audited, "func NewAuditedProductUseCase(inner ProductUseCase, repo repository.ProductRepository, audit repository.AuditLogRepository) AuditedProductUseCase")
+	assert.Contains(t, audited, "return a.record(id, domain.AuditActionUpdate, before, after)")
+	assert.Contains(t, audited, "return a.record(id, domain.AuditActionDelete, before, nil)")
+
+	repoDir := filepath.Join(DirInternal, DirRepository)
+	require.NoError(t, os.MkdirAll(repoDir, 0o755))
+	generateAuditLogRepository(repoDir)
+	assert.Contains(t, readParsedGo(t, filepath.Join(repoDir, "audit_log_repository.go")), "func NewAuditLogRepository(db *gorm.DB) AuditLogRepository")
+
+	httpDir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	require.NoError(t, os.MkdirAll(httpDir, 0o755))
+	generateHTTPHandlerFile(httpDir, "Product", false, false, "lowercase")
+	handler := readParsedGo(t, filepath.Join(httpDir, "product_handler.go"))
+	assert.Contains(t, handler, "output, err := p.acting(r).CreateProduct(input)")
+	assert.Contains(t, handler, "if err := p.acting(r).DeleteProduct(id); err != nil")
+	assert.Contains(t, handler, "func (p *ProductHandler) GetProductHistory(w http.ResponseWriter, r *http.Request)")
+	assert.Contains(t, readParsedGo(t, filepath.Join(httpDir, "actor.go")), "func actorFromRequest(r *http.Request) string")
+
+	var routes strings.Builder
+	writeRouteSetupFunc(&routes, "Product", defaultHTTPRoutes("Product"), false, false)
+	assert.Contains(t, routes.String(), `HandleFunc("/products/{id}/history", handler.GetProductHistory).Methods("GET")`)
+
+	assert.Equal(t, "usecase.NewAuditedProductUseCase(usecase.NewProductService(c.productRepo), c.productRepo, repository.NewAuditLogRepository(c.db))", useCaseConstructor("Product"))
+}
+
+func TestUnauditedHandler(t *testing.T) {
+	defer ensureTestUI(t)()
+	setupCLIProject(t, fullProductUseCase)
+
+	httpDir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	require.NoError(t, os.MkdirAll(httpDir, 0o755))
+	generateHTTPHandlerFile(httpDir, "Product", false, false, "lowercase")
+	handler := readParsedGo(t, filepath.Join(httpDir, "product_handler.go"))
+	assert.Contains(t, handler, "p.usecase.CreateProduct(input)")
+	assert.NotContains(t, handler, "History")
+	assert.NotContains(t, defaultHTTPRoutes("Product").Operations, OpHistory)
+	assert.Equal(t, "usecase.NewProductService(c.productRepo)", useCaseConstructor("Product"))
+}
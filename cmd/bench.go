@@ -0,0 +1,857 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// benchDir holds the load test scripts, their payloads and the results of
+// the runs.
+const benchDir = "bench"
+
+const (
+	benchToolK6     = "k6"
+	benchToolVegeta = "vegeta"
+)
+
+// benchBaseURLToken stands for the API's base URL in vegeta targets, which
+// make bench replaces with BENCH_URL.
+const benchBaseURLToken = "{{BASE_URL}}"
+
+// benchFormatRules are the validate rules seed values are not checked
+// against; fields with them get the value of the contract examples instead.
+var benchFormatRules = []string{"email", "url", "uri", "http_url", "uuid", "uuid4", "oneof"}
+
+var benchCmd = &cobra.Command{
+	Use:   "bench [Entity...]",
+	Short: "Generate HTTP load tests and a report comparing runs",
+	Long: `Generates load tests of the HTTP API, on top of the Go micro-benchmarks:
+
+  - bench/payloads/<entity>.json   Create and update bodies, built from the
+                                   entity's seed data
+  - bench/k6/<entity>.js           k6 scenario creating, reading, updating,
+                                   listing and deleting entities (--tool k6)
+  - bench/vegeta/<entity>.txt      vegeta targets (--tool vegeta)
+  - cmd/benchreport/main.go        Compares the latency percentiles of the
+                                   last two runs in bench/results
+
+and adds bench targets to the Makefile: 'make bench' starts the
+docker-compose stack, runs the load tests against it, saves the results in
+bench/results/<timestamp> and prints the report.
+
+Without arguments, every integrated feature is load tested. The routes come
+from the handlers' route setup, so versioned and custom paths are exercised.
+Values of unique fields get a per-iteration suffix in k6, so creates do not
+conflict. vegeta replays fixed requests: it reads the seeded rows (run
+'goca seed run' first) and only creates entities without unique fields.
+
+Examples:
+  goca bench
+  goca bench Product --vus 50 --duration 1m
+  goca bench --tool vegeta --rate 200`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tool, _ := cmd.Flags().GetString("tool")
+		duration, _ := cmd.Flags().GetString("duration")
+		vus, _ := cmd.Flags().GetInt("vus")
+		rate, _ := cmd.Flags().GetInt("rate")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if tool != benchToolK6 && tool != benchToolVegeta {
+			return fmt.Errorf("invalid --tool %q: use %s or %s", tool, benchToolK6, benchToolVegeta)
+		}
+
+		entities := args
+		if len(entities) == 0 {
+			entities = detectExistingFeatures()
+		}
+		sources, err := loadExplainSources()
+		if err != nil {
+			return err
+		}
+		routes := explainHTTPRoutes(sources)
+		var suites []benchSuite
+		for _, entity := range entities {
+			suite, err := loadBenchSuite(entity, routes)
+			if err != nil {
+				if len(args) > 0 {
+					return err
+				}
+				ui.Warning(err.Error())
+				continue
+			}
+			suites = append(suites, suite)
+		}
+		if len(suites) == 0 {
+			return fmt.Errorf("no HTTP API to load test: generate a feature first, e.g. goca feature Product --fields \"name:string,price:float64\"")
+		}
+
+		ui.Header("Goca Bench")
+		ui.Blank()
+		ui.KeyValue("Tool", tool)
+		for _, s := range suites {
+			var names []string
+			for _, e := range s.Endpoints {
+				names = append(names, e.Method+" "+e.Path)
+			}
+			ui.KeyValue(s.Entity, strings.Join(names, ", "))
+		}
+		ui.Blank()
+
+		data, _ := buildK8sTemplateData("", "")
+		settings := benchSettings{Tool: tool, Duration: duration, VUs: vus, Rate: rate, URL: "http://localhost:" + data.Port}
+		sm := NewSafetyManager(dryRun, force, false)
+		if err := generateBench(suites, settings, sm); err != nil {
+			return err
+		}
+		if dryRun {
+			sm.PrintSummary()
+			return nil
+		}
+
+		ui.Blank()
+		ui.Success("Load tests generated")
+		install := "Install k6: https://grafana.com/docs/k6/latest/set-up/install-k6/"
+		if tool == benchToolVegeta {
+			install = "Install vegeta: go install github.com/tsenart/vegeta/v12@latest"
+		}
+		ui.NextSteps([]string{
+			install,
+			"Run them against the compose stack: make bench",
+			"Change the API and run make bench again to compare the latencies",
+		})
+		return nil
+	},
+}
+
+func init() {
+	benchCmd.Flags().String("tool", benchToolK6, "Load testing tool: k6 or vegeta")
+	benchCmd.Flags().String("duration", "30s", "Duration of each load test (BENCH_DURATION)")
+	benchCmd.Flags().Int("vus", 10, "Virtual users of the k6 scenarios (BENCH_VUS)")
+	benchCmd.Flags().Int("rate", 50, "Requests per second of vegeta (BENCH_RATE)")
+	benchCmd.Flags().Bool("dry-run", false, "Show the files that would be generated without writing them")
+	benchCmd.Flags().Bool("force", false, "Overwrite the existing load tests")
+}
+
+// benchSettings are the defaults of the Makefile's bench variables.
+type benchSettings struct {
+	Tool     string
+	Duration string
+	VUs      int
+	Rate     int
+	URL      string
+}
+
+// benchSuite is the load test of an entity's HTTP API.
+type benchSuite struct {
+	Entity    string
+	Endpoints []benchEndpoint
+	// Create and Update are the request bodies, one per seed.
+	Create []jsonObject
+	Update []jsonObject
+	// Unique are the JSON keys of the fields under a unique constraint.
+	Unique   []string
+	IDKey    string
+	TenantID string
+}
+
+// benchEndpoint is a route of the API. Item routes have an {id} segment.
+type benchEndpoint struct {
+	Op     string
+	Method string
+	Path   string
+}
+
+// endpoint returns the endpoint of op, or false when the API has no route
+// for it.
+func (s benchSuite) endpoint(op string) (benchEndpoint, bool) {
+	for _, e := range s.Endpoints {
+		if e.Op == op {
+			return e, true
+		}
+	}
+	return benchEndpoint{}, false
+}
+
+// loadBenchSuite reads the routes of entity, and builds its request bodies
+// from its seed data and use case inputs.
+func loadBenchSuite(entity string, routes map[string]string) (benchSuite, error) {
+	entityFile, err := findEntityFile(entity)
+	if err != nil {
+		return benchSuite{}, err
+	}
+	src, err := parseGoSource(entityFile)
+	if err != nil {
+		return benchSuite{}, err
+	}
+	endpoints := benchEndpoints(entity, routes)
+	if len(endpoints) == 0 {
+		return benchSuite{}, fmt.Errorf("%s has no HTTP routes to load test: generate its handler with goca handler %s", entity, entity)
+	}
+
+	entityFields := readEntityFields(src.file, entity)
+	suite := benchSuite{Entity: entity, Endpoints: endpoints, IDKey: fieldJSONName("ID", projectNaming().JSON)}
+	if isMultiTenantEntity(entity) {
+		suite.TenantID = contractTenantID
+	}
+
+	view := loadProjection(entity, HandlerHTTP)
+	usecaseDir := filepath.Join(DirInternal, DirUseCase)
+	createFields := view.visible(contractStructFields(usecaseDir, "Create"+entity+"Input"))
+	updateFields := view.visible(contractStructFields(usecaseDir, "Update"+entity+"Input"))
+	seeds := readSeedRecords(filepath.Join(DirInternal, DirDomain), entity)
+	if len(seeds) == 0 {
+		seeds = []map[string]any{nil}
+	}
+	for _, seed := range seeds {
+		suite.Create = append(suite.Create, benchBody(createFields, entityFields, entity, seed, false))
+	}
+	if len(updateFields) > 0 {
+		suite.Update = []jsonObject{benchBody(updateFields, entityFields, entity, nil, true)}
+	}
+
+	for _, f := range entityFields {
+		tag := reflect.StructTag(strings.Trim(f.Tag, "`"))
+		if !strings.Contains(tag.Get("gorm"), "unique") {
+			continue
+		}
+		if key, _, ok := contractJSONKey(f); ok {
+			suite.Unique = append(suite.Unique, key)
+		}
+	}
+	return suite, nil
+}
+
+// benchEndpoints returns the CRUD routes of entity: the ones its route setup
+// registers, else the default routes of its use case under /api/v1.
+func benchEndpoints(entity string, routes map[string]string) []benchEndpoint {
+	handlers := map[string]string{
+		OpCreate: "Create" + entity,
+		OpRead:   "Get" + entity,
+		OpUpdate: "Update" + entity,
+		OpDelete: "Delete" + entity,
+		OpList:   "List" + toPlural(entity),
+	}
+	defaults := defaultHTTPRoutes(entity)
+	collection := "/api/v1" + defaults.Path
+
+	var endpoints []benchEndpoint
+	for _, op := range []string{OpCreate, OpRead, OpUpdate, OpList, OpDelete} {
+		if route, ok := routes[entity+"Handler."+handlers[op]]; ok {
+			// Handlers routed several times are load tested on their first route.
+			route, _, _ = strings.Cut(route, ", ")
+			verbs, path, _ := strings.Cut(route, " ")
+			method, _, _ := strings.Cut(verbs, "|")
+			endpoints = append(endpoints, benchEndpoint{Op: op, Method: method, Path: path})
+			continue
+		}
+		if len(routes) > 0 || !slices.Contains(defaults.Operations, op) {
+			continue
+		}
+		switch op {
+		case OpCreate:
+			endpoints = append(endpoints, benchEndpoint{Op: op, Method: "POST", Path: collection})
+		case OpRead:
+			endpoints = append(endpoints, benchEndpoint{Op: op, Method: "GET", Path: collection + "/{id}"})
+		case OpUpdate:
+			endpoints = append(endpoints, benchEndpoint{Op: op, Method: defaults.UpdateMethod, Path: collection + "/{id}"})
+		case OpList:
+			endpoints = append(endpoints, benchEndpoint{Op: op, Method: "GET", Path: collection})
+		case OpDelete:
+			endpoints = append(endpoints, benchEndpoint{Op: op, Method: "DELETE", Path: collection + "/{id}"})
+		}
+	}
+	return endpoints
+}
+
+// benchBody returns the request body of the input fields with the values of
+// seed. Fields the seed leaves out, or whose validate rules the seed values
+// are not checked against, get the values of the contract examples.
+func benchBody(fields, entityFields []Field, entity string, seed map[string]any, updated bool) jsonObject {
+	rules := make(map[string]string)
+	for _, f := range entityFields {
+		rules[f.Name] = reflect.StructTag(strings.Trim(f.Tag, "`")).Get("validate")
+	}
+	body := jsonObject{}
+	for _, f := range fields {
+		key, tag, ok := contractJSONKey(f)
+		if !ok {
+			continue
+		}
+		value, seeded := seed[f.Name]
+		if !seeded || hasFormatRule(rules[f.Name]) {
+			value = contractValue(f, tag, entity, updated, true)
+		}
+		body = append(body, jsonMember{key, value})
+	}
+	return body
+}
+
+// hasFormatRule reports whether the validate rules include one of
+// benchFormatRules.
+func hasFormatRule(rules string) bool {
+	for _, rule := range strings.Split(rules, ",") {
+		name, _, _ := strings.Cut(rule, "=")
+		if slices.Contains(benchFormatRules, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// readSeedRecords returns the literal field values of the records
+// Get<Entity>Seeds declares in dir, keyed by field name. Values that are not
+// literals, such as nested structs, are left out; time.Now() becomes the
+// contract example timestamp.
+func readSeedRecords(dir, entity string) []map[string]any {
+	filename := filepath.Join(dir, strings.ToLower(entity)+"_seeds.go")
+	file, err := parser.ParseFile(token.NewFileSet(), filename, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	var records []map[string]any
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != "Get"+entity+"Seeds" || fn.Body == nil {
+			continue
+		}
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			ret, ok := n.(*ast.ReturnStmt)
+			if !ok || len(ret.Results) != 1 {
+				return true
+			}
+			list, ok := ret.Results[0].(*ast.CompositeLit)
+			if !ok {
+				return false
+			}
+			for _, elt := range list.Elts {
+				lit, ok := elt.(*ast.CompositeLit)
+				if !ok {
+					continue
+				}
+				record := make(map[string]any)
+				for _, e := range lit.Elts {
+					kv, ok := e.(*ast.KeyValueExpr)
+					key, isIdent := kv.Key.(*ast.Ident)
+					if !ok || !isIdent {
+						continue
+					}
+					if value, ok := seedLiteralValue(kv.Value); ok {
+						record[key.Name] = value
+					}
+				}
+				records = append(records, record)
+			}
+			return false
+		})
+	}
+	return records
+}
+
+// seedLiteralValue returns the JSON value of a literal of a seed record.
+func seedLiteralValue(expr ast.Expr) (any, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.STRING:
+			s, err := strconv.Unquote(e.Value)
+			return s, err == nil
+		case token.INT, token.FLOAT:
+			var n json.Number
+			return n, json.Unmarshal([]byte(e.Value), &n) == nil
+		}
+	case *ast.Ident:
+		if e.Name == "true" || e.Name == "false" {
+			return e.Name == "true", true
+		}
+	case *ast.UnaryExpr:
+		if e.Op == token.SUB {
+			if v, ok := seedLiteralValue(e.X); ok {
+				if n, isNumber := v.(json.Number); isNumber {
+					return json.Number("-" + n), true
+				}
+			}
+		}
+	case *ast.CallExpr:
+		if sel, ok := e.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Now" {
+			if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "time" {
+				return contractTimestamp, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// benchFile is a file of the load tests.
+type benchFile struct {
+	path    string
+	content string
+}
+
+// generateBench writes the payloads and scripts of suites for the tool of
+// settings, the report command and the Makefile targets.
+func generateBench(suites []benchSuite, settings benchSettings, sm ...*SafetyManager) error {
+	for _, s := range suites {
+		name := strings.ToLower(s.Entity)
+		payloads, err := json.MarshalIndent(map[string][]jsonObject{"create": s.Create, "update": s.Update}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding the payloads of %s: %w", s.Entity, err)
+		}
+		files := []benchFile{{filepath.Join(benchDir, "payloads", name+".json"), string(payloads) + "\n"}}
+		if settings.Tool == benchToolK6 {
+			files = append(files, benchFile{filepath.Join(benchDir, benchToolK6, name+".js"), k6Script(s)})
+		} else {
+			files = append(files, benchFile{filepath.Join(benchDir, benchToolVegeta, name+".txt"), vegetaTargets(s)})
+			ops := []struct {
+				name   string
+				bodies []jsonObject
+			}{{"create", s.Create}, {"update", s.Update}}
+			if len(s.Unique) > 0 {
+				// The targets of entities with unique fields only read.
+				ops = nil
+			}
+			for _, op := range ops {
+				for i, body := range op.bodies {
+					content, err := json.Marshal(body)
+					if err != nil {
+						return fmt.Errorf("encoding the payloads of %s: %w", s.Entity, err)
+					}
+					files = append(files, benchFile{vegetaBodyPath(name, op.name, i), string(content) + "\n"})
+				}
+			}
+		}
+		for _, f := range files {
+			if err := writeFile(f.path, f.content, sm...); err != nil {
+				return fmt.Errorf("writing %s: %w", f.path, err)
+			}
+		}
+	}
+
+	writeOnce(filepath.Join("cmd", "benchreport", "main.go"), benchReportSource, "bench report", sm...)
+	ensureMakefileBenchTargets(settings, sm...)
+	return nil
+}
+
+// k6Script returns the k6 scenario of s. Each iteration creates an entity,
+// reads, updates and lists, then deletes it; APIs without create read the
+// seeded rows 1 to 3. Requests are tagged with their operation, and the empty
+// thresholds make the summary export report each operation on its own.
+func k6Script(s benchSuite) string {
+	name := strings.ToLower(s.Entity)
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Load test of the %s API, generated by goca bench. The request bodies\n", s.Entity)
+	fmt.Fprintf(&b, "// are in bench/payloads/%s.json.\n", name)
+	b.WriteString("import http from 'k6/http';\nimport { check } from 'k6';\nimport { SharedArray } from 'k6/data';\n\n")
+	b.WriteString("const BASE_URL = __ENV.BASE_URL || 'http://localhost:8080';\n")
+	fmt.Fprintf(&b, "const payloads = JSON.parse(open('../payloads/%s.json'));\n", name)
+	fmt.Fprintf(&b, "const creates = new SharedArray('%s creates', () => payloads.create || []);\n", name)
+	fmt.Fprintf(&b, "const updates = new SharedArray('%s updates', () => payloads.update || []);\n", name)
+	unique, _ := json.Marshal(s.Unique)
+	if s.Unique == nil {
+		unique = []byte("[]")
+	}
+	fmt.Fprintf(&b, "// Values of these fields must be unique, so every iteration changes them.\nconst unique = %s;\n\n", unique)
+
+	b.WriteString("const headers = { 'Content-Type': 'application/json'")
+	if s.TenantID != "" {
+		fmt.Fprintf(&b, ", 'X-Tenant-ID': '%s'", s.TenantID)
+	}
+	b.WriteString(" };\n\n")
+
+	b.WriteString("export const options = {\n")
+	b.WriteString("  vus: Number(__ENV.VUS || 10),\n")
+	b.WriteString("  duration: __ENV.DURATION || '30s',\n")
+	b.WriteString("  summaryTrendStats: ['avg', 'min', 'med', 'max', 'p(90)', 'p(95)', 'p(99)'],\n")
+	b.WriteString("  thresholds: {\n")
+	b.WriteString("    http_req_failed: ['rate<0.01'],\n")
+	for _, e := range s.Endpoints {
+		for _, metric := range []string{"http_reqs", "http_req_duration", "http_req_failed"} {
+			fmt.Fprintf(&b, "    '%s{name:%s}': [],\n", metric, e.Op)
+		}
+	}
+	b.WriteString("  },\n};\n\n")
+
+	b.WriteString(`// body returns the i-th payload of list as JSON, with the unique fields
+// suffixed by the virtual user and iteration.
+function body(list, i) {
+  const payload = Object.assign({}, list[i % list.length]);
+  const suffix = ` + "`${__VU}-${__ITER}`" + `;
+  for (const key of unique) {
+    const value = payload[key];
+    if (typeof value !== 'string') {
+      continue;
+    }
+    const at = value.indexOf('@');
+    payload[key] = at < 0 ? ` + "`${value}-${suffix}`" + ` : ` + "`${value.slice(0, at)}+${suffix}${value.slice(at)}`" + `;
+  }
+  return JSON.stringify(payload);
+}
+
+`)
+	fmt.Fprintf(&b, "// idOf returns the %s of the entity a create answered, enveloped or not.\n", s.IDKey)
+	b.WriteString("function idOf(res) {\n  const payload = res.json();\n")
+	fmt.Fprintf(&b, "  return (payload.data || payload).%s;\n}\n\n", s.IDKey)
+
+	url := func(e benchEndpoint) string {
+		return "`${BASE_URL}" + strings.Replace(e.Path, "{id}", "${id}", 1) + "`"
+	}
+	request := func(e benchEndpoint, payload string) string {
+		params := fmt.Sprintf("{ headers, tags: { name: '%s' } }", e.Op)
+		switch e.Method {
+		case "GET":
+			return fmt.Sprintf("http.get(%s, %s)", url(e), params)
+		case "DELETE":
+			return fmt.Sprintf("http.del(%s, null, %s)", url(e), params)
+		}
+		return fmt.Sprintf("http.%s(%s, %s, %s)", strings.ToLower(e.Method), url(e), payload, params)
+	}
+
+	b.WriteString("export default function () {\n")
+	create, creates := s.endpoint(OpCreate)
+	if creates {
+		fmt.Fprintf(&b, "  const created = %s;\n", request(create, "body(creates, __ITER)"))
+		b.WriteString("  check(created, { 'created': (r) => r.status === 201 });\n")
+		b.WriteString("  if (created.status !== 201) {\n    return;\n  }\n")
+		b.WriteString("  const id = idOf(created);\n\n")
+	} else {
+		b.WriteString("  // The API does not create entities: use the seeded rows (goca seed run).\n")
+		b.WriteString("  const id = (__ITER % 3) + 1;\n\n")
+	}
+	if e, ok := s.endpoint(OpRead); ok {
+		fmt.Fprintf(&b, "  check(%s, { 'read': (r) => r.status === 200 });\n", request(e, ""))
+	}
+	if e, ok := s.endpoint(OpUpdate); ok && len(s.Update) > 0 {
+		fmt.Fprintf(&b, "  check(%s, { 'updated': (r) => r.status < 300 });\n", request(e, "body(updates, __ITER)"))
+	}
+	if e, ok := s.endpoint(OpList); ok {
+		fmt.Fprintf(&b, "  check(%s, { 'listed': (r) => r.status === 200 });\n", request(e, ""))
+	}
+	if e, ok := s.endpoint(OpDelete); ok && creates {
+		fmt.Fprintf(&b, "  check(%s, { 'deleted': (r) => r.status < 300 });\n", request(e, ""))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// vegetaTargets returns the vegeta targets of s: reads of the seeded rows 1
+// to 3, and, when no field is unique, creates and updates with the seed
+// payloads. Deletes would remove the rows the reads need, so they are left
+// out.
+func vegetaTargets(s benchSuite) string {
+	name := strings.ToLower(s.Entity)
+	var b strings.Builder
+	fmt.Fprintf(&b, "# vegeta targets of the %s API, generated by goca bench.\n", s.Entity)
+	fmt.Fprintf(&b, "# make bench replaces %s with BENCH_URL; the reads need the seeded rows\n# (goca seed run).\n", benchBaseURLToken)
+	target := func(e benchEndpoint, id int, bodyPath string) {
+		path := strings.Replace(e.Path, "{id}", strconv.Itoa(id), 1)
+		fmt.Fprintf(&b, "\n%s %s%s\n", e.Method, benchBaseURLToken, path)
+		if bodyPath != "" {
+			b.WriteString("Content-Type: application/json\n")
+		}
+		if s.TenantID != "" {
+			fmt.Fprintf(&b, "X-Tenant-ID: %s\n", s.TenantID)
+		}
+		if bodyPath != "" {
+			fmt.Fprintf(&b, "@%s\n", filepath.ToSlash(bodyPath))
+		}
+	}
+	writes := len(s.Unique) == 0
+	for _, e := range s.Endpoints {
+		switch {
+		case e.Op == OpList:
+			target(e, 0, "")
+		case e.Op == OpRead:
+			for id := 1; id <= 3; id++ {
+				target(e, id, "")
+			}
+		case e.Op == OpCreate && writes:
+			for i := range s.Create {
+				target(e, 0, vegetaBodyPath(name, "create", i))
+			}
+		case e.Op == OpUpdate && writes:
+			for i := range s.Update {
+				target(e, 1, vegetaBodyPath(name, "update", i))
+			}
+		}
+	}
+	return b.String()
+}
+
+// vegetaBodyPath returns the file of the i-th body of an operation, which
+// vegeta targets reference relative to the project root.
+func vegetaBodyPath(name, op string, i int) string {
+	return filepath.Join(benchDir, benchToolVegeta, "bodies", fmt.Sprintf("%s-%s-%d.json", name, op, i+1))
+}
+
+// ensureMakefileBenchTargets adds the bench targets to the project's
+// Makefile, with the variables defaulting to settings. Makefiles that
+// already have them are left alone.
+func ensureMakefileBenchTargets(settings benchSettings, sm ...*SafetyManager) {
+	const makefile = "Makefile"
+	raw, err := os.ReadFile(makefile)
+	if err != nil {
+		ui.Dim("   No Makefile: run the load tests with k6 run or vegeta attack, then go run ./cmd/benchreport")
+		return
+	}
+	content := string(raw)
+	if strings.Contains(content, "\nbench:") {
+		return
+	}
+
+	targets := fmt.Sprintf(`# Load tests (goca bench)
+BENCH_TOOL ?= %s
+BENCH_URL ?= %s
+BENCH_DURATION ?= %s
+BENCH_VUS ?= %d
+BENCH_RATE ?= %d
+BENCH_RESULTS := bench/results
+
+bench: bench-$(BENCH_TOOL) ## Load test the compose stack and compare with the previous run
+	go run ./cmd/benchreport -dir $(BENCH_RESULTS)
+
+bench-up: ## Start the compose stack and wait until it is healthy
+	docker compose up -d --build --wait
+
+bench-k6: bench-up ## Run the k6 load tests of bench/k6
+	@run=$(BENCH_RESULTS)/$$(date +%%Y%%m%%d-%%H%%M%%S); mkdir -p $$run; \
+	for script in bench/k6/*.js; do \
+		k6 run -e BASE_URL=$(BENCH_URL) -e VUS=$(BENCH_VUS) -e DURATION=$(BENCH_DURATION) \
+			--summary-export $$run/$$(basename $$script .js).json $$script || exit 1; \
+	done
+
+bench-vegeta: bench-up ## Run the vegeta load tests of bench/vegeta
+	@run=$(BENCH_RESULTS)/$$(date +%%Y%%m%%d-%%H%%M%%S); mkdir -p $$run; \
+	for targets in bench/vegeta/*.txt; do \
+		sed 's#%s#$(BENCH_URL)#' $$targets | \
+			vegeta attack -rate=$(BENCH_RATE) -duration=$(BENCH_DURATION) | \
+			vegeta report -type=json > $$run/$$(basename $$targets .txt).json || exit 1; \
+	done
+
+bench-report: ## Compare the latencies of the last two load test runs
+	go run ./cmd/benchreport -dir $(BENCH_RESULTS)
+
+`, settings.Tool, settings.URL, settings.Duration, settings.VUs, settings.Rate, benchBaseURLToken)
+
+	if strings.Contains(content, "\nlint:") {
+		content = strings.Replace(content, "\nlint:", "\n"+targets+"lint:", 1)
+	} else {
+		content = strings.TrimRight(content, "\n") + "\n\n" + targets
+	}
+	content = strings.Replace(content, ".PHONY: help build run test ", ".PHONY: help build run test bench bench-up bench-k6 bench-vegeta bench-report ", 1)
+
+	if err := writeMergedFileSafe(makefile, content, sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Could not add bench targets to Makefile: %v", err))
+	}
+}
+
+// benchReportSource is cmd/benchreport/main.go, which compares the
+// percentiles of two runs of make bench.
+const benchReportSource = `// Command benchreport compares the latency percentiles of two load test
+// runs written by make bench: the k6 summary exports or vegeta JSON reports
+// in bench/results/<run>. By default it compares the last run with the one
+// before.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// stats are the latencies of a scenario, in milliseconds.
+type stats struct {
+	Requests float64
+	Errors   float64 // share of the requests that failed
+	P50      float64
+	P90      float64
+	P95      float64
+	P99      float64
+}
+
+func main() {
+	dir := flag.String("dir", "bench/results", "directory of the runs")
+	base := flag.String("base", "", "run to compare against (default: the run before -head)")
+	head := flag.String("head", "", "run to report (default: the last run)")
+	out := flag.String("out", "", "also write the report to this Markdown file")
+	flag.Parse()
+
+	runs, err := listRuns(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(runs) == 0 {
+		log.Fatalf("no runs in %s: run make bench first", *dir)
+	}
+	if *head == "" {
+		*head = runs[len(runs)-1]
+	}
+	if *base == "" {
+		for i, run := range runs {
+			if run == *head && i > 0 {
+				*base = runs[i-1]
+			}
+		}
+	}
+
+	current, err := loadRun(filepath.Join(*dir, *head))
+	if err != nil {
+		log.Fatal(err)
+	}
+	var previous map[string]stats
+	if *base != "" {
+		if previous, err = loadRun(filepath.Join(*dir, *base)); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = io.MultiWriter(os.Stdout, f)
+	}
+	writeReport(w, *head, *base, current, previous)
+}
+
+// listRuns returns the runs in dir, oldest first. Runs are named after the
+// time they started, so they sort by name.
+func listRuns(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var runs []string
+	for _, e := range entries {
+		if e.IsDir() {
+			runs = append(runs, e.Name())
+		}
+	}
+	sort.Strings(runs)
+	return runs, nil
+}
+
+// loadRun returns the stats of every scenario of a run: one per result file,
+// and one per operation of the k6 scenarios.
+func loadRun(dir string) (map[string]stats, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	run := make(map[string]stats)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+		var result struct {
+			Metrics   map[string]map[string]float64 ` + "`json:\"metrics\"`" + `
+			Requests  float64                       ` + "`json:\"requests\"`" + `
+			Success   float64                       ` + "`json:\"success\"`" + `
+			Latencies map[string]float64            ` + "`json:\"latencies\"`" + `
+		}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if result.Metrics != nil {
+			addK6Stats(run, name, result.Metrics)
+			continue
+		}
+		// vegeta reports latencies in nanoseconds.
+		ms := func(key string) float64 { return result.Latencies[key] / 1e6 }
+		run[name] = stats{
+			Requests: result.Requests,
+			Errors:   1 - result.Success,
+			P50:      ms("50th"),
+			P90:      ms("90th"),
+			P95:      ms("95th"),
+			P99:      ms("99th"),
+		}
+	}
+	return run, nil
+}
+
+// addK6Stats adds the stats of a k6 summary export: the whole scenario and
+// each operation its requests are tagged with.
+func addK6Stats(run map[string]stats, name string, metrics map[string]map[string]float64) {
+	add := func(scenario, suffix string) {
+		duration, ok := metrics["http_req_duration"+suffix]
+		if !ok {
+			return
+		}
+		run[scenario] = stats{
+			Requests: metrics["http_reqs"+suffix]["count"],
+			Errors:   metrics["http_req_failed"+suffix]["value"],
+			P50:      duration["med"],
+			P90:      duration["p(90)"],
+			P95:      duration["p(95)"],
+			P99:      duration["p(99)"],
+		}
+	}
+	add(name, "")
+	for metric := range metrics {
+		if tag, ok := strings.CutPrefix(metric, "http_req_duration{name:"); ok {
+			add(name+" "+strings.TrimSuffix(tag, "}"), strings.TrimPrefix(metric, "http_req_duration"))
+		}
+	}
+}
+
+// writeReport writes a Markdown table of the scenarios of current, with the
+// change of p95 and p99 since previous.
+func writeReport(w io.Writer, head, base string, current, previous map[string]stats) {
+	if base == "" {
+		fmt.Fprintf(w, "## Load test %s\n\n", head)
+	} else {
+		fmt.Fprintf(w, "## Load test %s compared with %s\n\n", head, base)
+	}
+	fmt.Fprintln(w, "| Scenario | Requests | Errors | p50 | p90 | p95 | p99 | p95 change | p99 change |")
+	fmt.Fprintln(w, "|---|---:|---:|---:|---:|---:|---:|---:|---:|")
+
+	names := make([]string, 0, len(current))
+	for name := range current {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s := current[name]
+		p95, p99 := "new", "new"
+		if before, ok := previous[name]; ok {
+			p95, p99 = change(before.P95, s.P95), change(before.P99, s.P99)
+		}
+		fmt.Fprintf(w, "| %s | %.0f | %.2f%% | %s | %s | %s | %s | %s | %s |\n",
+			name, s.Requests, s.Errors*100, millis(s.P50), millis(s.P90), millis(s.P95), millis(s.P99), p95, p99)
+	}
+}
+
+func millis(v float64) string {
+	return fmt.Sprintf("%.1fms", v)
+}
+
+// change returns the relative change from before to after.
+func change(before, after float64) string {
+	if before == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.1f%%", (after-before)/before*100)
+}
+`
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateBench(t *testing.T) {
+	defer setupDiscardUI(t)()
+	chdirTemp(t)
+	sm := NewSafetyManager(false, false, false)
+	generateCompleteFeature("Product", "name:string,email:string,price:float64", "postgres", "http", "", true, false, false, false, false, false, false, false, "lowercase", DTOStyleNested, allFeatureLayers(), sm)
+	writeTestFile(t, ".", "Makefile", ".PHONY: help build run test lint\n\ntest: ## Run tests\n\tgo test ./...\n\nlint: ## Run linter\n\tgolangci-lint run\n")
+
+	suite, err := loadBenchSuite("Product", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []benchEndpoint{
+		{OpCreate, "POST", "/api/v1/products"},
+		{OpRead, "GET", "/api/v1/products/{id}"},
+		{OpUpdate, "PUT", "/api/v1/products/{id}"},
+		{OpList, "GET", "/api/v1/products"},
+		{OpDelete, "DELETE", "/api/v1/products/{id}"},
+	}, suite.Endpoints)
+	assert.Equal(t, []string{"email"}, suite.Unique)
+	require.Len(t, suite.Create, 3)
+	require.Len(t, suite.Update, 1)
+
+	require.NoError(t, generateBench([]benchSuite{suite}, benchSettings{Tool: benchToolK6, Duration: "30s", VUs: 10, Rate: 50, URL: "http://localhost:8080"}, sm))
+
+	// Bodies carry the seed values, except the email the seeds do not validate.
+	raw, err := os.ReadFile(filepath.Join(benchDir, "payloads", "product.json"))
+	require.NoError(t, err)
+	var payloads map[string][]map[string]any
+	require.NoError(t, json.Unmarshal(raw, &payloads))
+	assert.Equal(t, "John Smith", payloads["create"][0]["name"])
+	assert.Equal(t, "Jane Doe", payloads["create"][1]["name"])
+	assert.Equal(t, "test@product.com", payloads["create"][1]["email"])
+	assert.Equal(t, 149.5, payloads["create"][1]["price"])
+
+	raw, err = os.ReadFile(filepath.Join(benchDir, benchToolK6, "product.js"))
+	require.NoError(t, err)
+	script := string(raw)
+	assert.Contains(t, script, `const unique = ["email"];`)
+	assert.Contains(t, script, "const created = http.post(`${BASE_URL}/api/v1/products`, body(creates, __ITER), { headers, tags: { name: 'create' } });")
+	assert.Contains(t, script, "http.del(`${BASE_URL}/api/v1/products/${id}`, null, { headers, tags: { name: 'delete' } })")
+	assert.Contains(t, script, "'http_req_duration{name:list}': [],")
+
+	report := readParsedGo(t, filepath.Join("cmd", "benchreport", "main.go"))
+	assert.Contains(t, report, `strings.CutPrefix(metric, "http_req_duration{name:")`)
+
+	raw, err = os.ReadFile("Makefile")
+	require.NoError(t, err)
+	makefile := string(raw)
+	assert.Contains(t, makefile, ".PHONY: help build run test bench bench-up bench-k6 bench-vegeta bench-report lint")
+	assert.Contains(t, makefile, "BENCH_TOOL ?= k6\n")
+	assert.Contains(t, makefile, "\tdocker compose up -d --build --wait\n")
+	assert.Contains(t, makefile, "--summary-export $$run/$$(basename $$script .js).json $$script || exit 1")
+	assert.Less(t, strings.Index(makefile, "\nbench:"), strings.Index(makefile, "\nlint:"))
+}
+
+func TestVegetaTargets(t *testing.T) {
+	t.Parallel()
+	suite := benchSuite{
+		Entity: "Order",
+		Endpoints: []benchEndpoint{
+			{OpCreate, "POST", "/api/v2/orders"},
+			{OpRead, "GET", "/api/v2/orders/{id}"},
+			{OpList, "GET", "/api/v2/orders"},
+			{OpDelete, "DELETE", "/api/v2/orders/{id}"},
+		},
+		Create:   []jsonObject{{{"total", 10}}},
+		TenantID: contractTenantID,
+	}
+
+	targets := vegetaTargets(suite)
+	assert.Contains(t, targets, "\nPOST {{BASE_URL}}/api/v2/orders\nContent-Type: application/json\nX-Tenant-ID: tenant-a\n@bench/vegeta/bodies/order-create-1.json\n")
+	assert.Contains(t, targets, "\nGET {{BASE_URL}}/api/v2/orders/3\nX-Tenant-ID: tenant-a\n")
+	assert.NotContains(t, targets, "DELETE")
+
+	// Creates of entities with unique fields would conflict after the first.
+	suite.Unique = []string{"number"}
+	assert.NotContains(t, vegetaTargets(suite), "POST")
+}
+
+func TestReadSeedRecords(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "order_seeds.go", `package domain
+
+import "time"
+
+func GetOrderSeeds() []Order {
+	return []Order{
+		{Number: "A-1", Total: -5.5, Paid: true, Items: 2, PlacedAt: time.Now(), Address: Address{City: "Lima"}},
+	}
+}
+`)
+	records := readSeedRecords(dir, "Order")
+	require.Len(t, records, 1)
+	assert.Equal(t, map[string]any{
+		"Number":   "A-1",
+		"Total":    json.Number("-5.5"),
+		"Paid":     true,
+		"Items":    json.Number("2"),
+		"PlacedAt": contractTimestamp,
+	}, records[0])
+	assert.Nil(t, readSeedRecords(dir, "Invoice"))
+}
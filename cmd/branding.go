@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadBranding reads project.branding from the .goca.yaml found in projectDir.
+// Only the branding block is decoded, so a config that fails full validation
+// still brands the generated output. A missing or unreadable file yields the
+// zero value, which keeps the default Goca wording.
+func loadBranding(projectDir string) BrandingConfig {
+	configPath := NewConfigManager().findConfigFile(projectDir)
+	if configPath == "" {
+		return BrandingConfig{}
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return BrandingConfig{}
+	}
+	data = bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF})
+
+	var partial struct {
+		Project struct {
+			Branding BrandingConfig `yaml:"branding"`
+		} `yaml:"project"`
+	}
+	if err := yaml.Unmarshal(data, &partial); err != nil {
+		return BrandingConfig{}
+	}
+
+	return partial.Project.Branding
+}
+
+// hasSupportContact reports whether an email or URL is available for error responses.
+func (b BrandingConfig) hasSupportContact() bool {
+	return b.SupportEmail != "" || b.SupportURL != ""
+}
+
+// readmeBadges renders the configured badges as a markdown block placed under the title.
+func readmeBadges(b BrandingConfig) string {
+	var badges []string
+	for _, badge := range b.Badges {
+		if badge.Image == "" {
+			continue
+		}
+		img := fmt.Sprintf("![%s](%s)", badge.Label, badge.Image)
+		if badge.Link != "" {
+			img = fmt.Sprintf("[%s](%s)", img, badge.Link)
+		}
+		badges = append(badges, img)
+	}
+	if len(badges) == 0 {
+		return ""
+	}
+	return strings.Join(badges, " ") + "\n\n"
+}
+
+// readmeOrgLine returns the line shown under the README title.
+func readmeOrgLine(b BrandingConfig) string {
+	if b.OrgName == "" {
+		return "Generated with Goca - Go Clean Architecture Code Generator"
+	}
+	return "Maintained by " + markdownLink(b.OrgName, b.OrgURL)
+}
+
+// readmeResources returns the "Additional Resources" list.
+func readmeResources(b BrandingConfig) string {
+	var lines []string
+	if b.DocsURL != "" {
+		name := "Internal"
+		if b.OrgName != "" {
+			name = b.OrgName
+		}
+		lines = append(lines, fmt.Sprintf("- [%s Documentation](%s)", name, b.DocsURL))
+	}
+	if b.OrgName == "" {
+		lines = append(lines, "- [Goca Documentation](https://github.com/sazardev/goca)")
+	}
+	lines = append(lines, "- [Clean Architecture Principles](https://blog.cleancoder.com/uncle-bob/2012/08/13/the-clean-architecture.html)")
+	if b.OrgName == "" {
+		lines = append(lines, "- [Complete Tutorial](https://github.com/sazardev/goca/wiki/Complete-Tutorial)")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// readmeSupportSection returns a "## Support" section, or "" when no contact is configured.
+func readmeSupportSection(b BrandingConfig) string {
+	if !b.hasSupportContact() {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## Support\n\n")
+	if b.SupportEmail != "" {
+		fmt.Fprintf(&sb, "- Email: [%s](mailto:%s)\n", b.SupportEmail, b.SupportEmail)
+	}
+	if b.SupportURL != "" {
+		fmt.Fprintf(&sb, "- Help desk: %s\n", b.SupportURL)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// readmeFooter returns the closing line of the README.
+func readmeFooter(b BrandingConfig) string {
+	if b.OrgName == "" {
+		return "Generated with [Goca](https://github.com/sazardev/goca)"
+	}
+	return markdownLink(b.OrgName, b.OrgURL)
+}
+
+func markdownLink(text, url string) string {
+	if url == "" {
+		return text
+	}
+	return fmt.Sprintf("[%s](%s)", text, url)
+}
+
+// openAPIContact renders the contact entry of an OpenAPI info block
+// (indented for placement under "info:"), or "" when branding is empty.
+func openAPIContact(b BrandingConfig) string {
+	if b.OrgName == "" && !b.hasSupportContact() {
+		return ""
+	}
+
+	url := b.SupportURL
+	if url == "" {
+		url = b.OrgURL
+	}
+
+	var sb strings.Builder
+	sb.WriteString("  contact:\n")
+	if b.OrgName != "" {
+		fmt.Fprintf(&sb, "    name: %q\n", b.OrgName)
+	}
+	if url != "" {
+		fmt.Fprintf(&sb, "    url: %q\n", url)
+	}
+	if b.SupportEmail != "" {
+		fmt.Fprintf(&sb, "    email: %q\n", b.SupportEmail)
+	}
+	return sb.String()
+}
+
+// openAPIExternalDocs renders a top-level externalDocs entry pointing at the
+// organization's documentation, or "" when no docs URL is configured.
+func openAPIExternalDocs(b BrandingConfig) string {
+	if b.DocsURL == "" {
+		return ""
+	}
+	description := "Documentation"
+	if b.OrgName != "" {
+		description = b.OrgName + " documentation"
+	}
+	return fmt.Sprintf("externalDocs:\n  description: %q\n  url: %q\n\n", description, b.DocsURL)
+}
+
+// applyBrandingToMainGo installs JSON 404/405 handlers that tell API consumers
+// whom to contact. main.go is left untouched when no support contact is set.
+func applyBrandingToMainGo(content string, b BrandingConfig) string {
+	if !b.hasSupportContact() {
+		return content
+	}
+
+	routerPattern := "router := mux.NewRouter()"
+	content = strings.Replace(content, routerPattern, routerPattern+`
+
+	// Branded error responses for unknown routes
+	router.NotFoundHandler = http.HandlerFunc(notFoundHandler)
+	router.MethodNotAllowedHandler = http.HandlerFunc(methodNotAllowedHandler)`, 1)
+
+	content += fmt.Sprintf(`
+// ErrorContact tells API consumers where to get help.
+type ErrorContact struct {
+	Name  string `+"`"+`json:"name,omitempty"`+"`"+`
+	Email string `+"`"+`json:"email,omitempty"`+"`"+`
+	URL   string `+"`"+`json:"url,omitempty"`+"`"+`
+	Docs  string `+"`"+`json:"docs,omitempty"`+"`"+`
+}
+
+// ErrorResponse is the body returned for routing errors.
+type ErrorResponse struct {
+	Error   string       `+"`"+`json:"error"`+"`"+`
+	Status  int          `+"`"+`json:"status"`+"`"+`
+	Contact ErrorContact `+"`"+`json:"contact"`+"`"+`
+}
+
+var supportContact = ErrorContact{
+	Name:  %q,
+	Email: %q,
+	URL:   %q,
+	Docs:  %q,
+}
+
+func writeErrorResponse(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: message, Status: status, Contact: supportContact})
+}
+
+func notFoundHandler(w http.ResponseWriter, r *http.Request) {
+	writeErrorResponse(w, http.StatusNotFound, "resource not found")
+}
+
+func methodNotAllowedHandler(w http.ResponseWriter, r *http.Request) {
+	writeErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+`, b.OrgName, b.SupportEmail, b.SupportURL, b.DocsURL)
+
+	return content
+}
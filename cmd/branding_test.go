@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const brandingYAML = `project:
+  name: brand
+  module: github.com/acme/brand
+  branding:
+    org_name: Acme Corp
+    org_url: https://acme.example
+    support_email: api@acme.example
+    support_url: https://help.acme.example
+    docs_url: https://docs.acme.example
+    badges:
+      - label: build
+        image: https://ci.acme.example/badge.svg
+        link: https://ci.acme.example
+database:
+  port: 0
+`
+
+func TestLoadBranding(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, BrandingConfig{}, loadBranding(dir))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".goca.yaml"), []byte(brandingYAML), 0o600))
+
+	// database.port 0 fails full validation; branding must still load.
+	b := loadBranding(dir)
+	assert.Equal(t, "Acme Corp", b.OrgName)
+	assert.Equal(t, "api@acme.example", b.SupportEmail)
+	require.Len(t, b.Badges, 1)
+	assert.Equal(t, "https://ci.acme.example", b.Badges[0].Link)
+}
+
+func TestCreateReadme_Branding(t *testing.T) {
+	cleanup := setupDiscardUI(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	sm := NewSafetyManager(false, true, false)
+
+	createReadme(dir, "github.com/acme/brand", DBPostgres, sm)
+	plain, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(plain), "Generated with [Goca](https://github.com/sazardev/goca)")
+	assert.NotContains(t, string(plain), "## Support")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".goca.yaml"), []byte(brandingYAML), 0o600))
+	createReadme(dir, "github.com/acme/brand", DBPostgres, sm)
+	branded, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	require.NoError(t, err)
+	src := string(branded)
+
+	assert.Contains(t, src, "[![build](https://ci.acme.example/badge.svg)](https://ci.acme.example)")
+	assert.Contains(t, src, "Maintained by [Acme Corp](https://acme.example)")
+	assert.Contains(t, src, "- [Acme Corp Documentation](https://docs.acme.example)")
+	assert.Contains(t, src, "## Support\n\n- Email: [api@acme.example](mailto:api@acme.example)")
+	assert.NotContains(t, src, "Generated with [Goca]")
+	assert.NotContains(t, src, "Complete Tutorial")
+}
+
+func TestGenerateSwaggerFile_Branding(t *testing.T) {
+	cleanup := ensureTestUI(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(dir)
+
+	require.NoError(t, os.WriteFile(".goca.yaml", []byte(brandingYAML), 0o600))
+
+	generateSwaggerFile(".", "Product", NewSafetyManager(false, true, false))
+
+	content, err := os.ReadFile("swagger.yaml")
+	require.NoError(t, err)
+	src := string(content)
+
+	assert.Contains(t, src, "  description: API for managing product entities\n  contact:\n    name: \"Acme Corp\"\n")
+	assert.Contains(t, src, "    url: \"https://help.acme.example\"\n    email: \"api@acme.example\"\n")
+	assert.Contains(t, src, "externalDocs:\n  description: \"Acme Corp documentation\"\n  url: \"https://docs.acme.example\"\n\npaths:")
+}
+
+func TestApplyBrandingToMainGo(t *testing.T) {
+	cleanup := setupDiscardUI(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".goca.yaml"), []byte(brandingYAML), 0o600))
+
+	createMainGo(dir, "github.com/acme/brand", DBPostgres, "", true, NewSafetyManager(false, true, false))
+
+	content, err := os.ReadFile(filepath.Join(dir, "cmd", "server", "main.go"))
+	require.NoError(t, err)
+	src := string(content)
+
+	_, err = parser.ParseFile(token.NewFileSet(), "main.go", src, 0)
+	require.NoError(t, err)
+
+	assert.Contains(t, src, "router.NotFoundHandler = http.HandlerFunc(notFoundHandler)")
+	assert.Contains(t, src, `Email: "api@acme.example"`)
+	assert.Contains(t, src, "router.Use(tracing.Middleware)")
+
+	// Without a support contact main.go keeps mux's default handlers.
+	assert.Equal(t, "main", applyBrandingToMainGo("main", BrandingConfig{OrgName: "Acme Corp"}))
+}
+
+func TestValidateBranding(t *testing.T) {
+	cm := NewConfigManager()
+	cm.validateBranding(&BrandingConfig{
+		DocsURL:      "docs.acme.example",
+		SupportEmail: "support",
+		Badges:       []BadgeConfig{{Label: "build"}},
+	})
+
+	fields := make([]string, 0, len(cm.GetWarnings()))
+	for _, w := range cm.GetWarnings() {
+		fields = append(fields, w.Field)
+	}
+	assert.ElementsMatch(t, []string{
+		"project.branding.docs_url",
+		"project.branding.support_email",
+		"project.branding.badges[0].image",
+	}, fields)
+	assert.Empty(t, cm.GetErrors())
+}
@@ -0,0 +1,559 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// errBreakingChanges is returned by goca breaking when an API definition
+// changed incompatibly.
+var errBreakingChanges = errors.New("breaking API changes")
+
+// defaultAPISnapshot is where goca breaking stores the API definitions
+// compared when no --base revision is given.
+const defaultAPISnapshot = ".goca/api-snapshot.json"
+
+var breakingCmd = &cobra.Command{
+	Use:   "breaking",
+	Short: "Report breaking changes of the OpenAPI and proto definitions",
+	Long: `Compares the OpenAPI documents (swagger.yaml, openapi.yaml and their .yml and
+.json variants) and .proto files of the project with a previous version and
+reports the changes that break existing clients:
+
+  operation-removed       an HTTP operation was removed
+  new-required-parameter  an operation requires a new query or header parameter
+  response-removed        an operation no longer returns a body
+  field-removed           a field of a request, response or message was removed
+  type-changed            a field, parameter or repeated field changed type
+  new-required-field      a request field, or a proto2 required field, is new
+                          or became required
+  field-optional          a response field is no longer always returned
+  enum-value-removed      a request or proto enum value was removed
+  service-removed         a gRPC service was removed
+  rpc-removed             a gRPC method was removed
+  rpc-signature-changed   a gRPC method changed request, response or streaming
+  message-removed         a proto message or enum was removed
+  field-number-changed    a proto field was renumbered
+  package-changed         a proto package was renamed
+
+Proto fields removed with their number reserved are not reported.
+
+The previous version is a git revision given with --base, or else the snapshot
+recorded by --update-snapshot in ` + defaultAPISnapshot + `. goca breaking exits
+with status 1 when a change is breaking, so it can gate CI; after a deliberate
+break, record a new snapshot.`,
+	Example: `  goca breaking --update-snapshot
+  goca breaking
+  goca breaking --base origin/main --output github`,
+	RunE: runBreaking,
+}
+
+func init() {
+	breakingCmd.Flags().String("base", "", "Compare with the definitions of this git revision instead of the snapshot")
+	breakingCmd.Flags().String("snapshot", defaultAPISnapshot, "Snapshot of the definitions to compare with")
+	breakingCmd.Flags().Bool("update-snapshot", false, "Record the current definitions in the snapshot instead of comparing")
+	breakingCmd.Flags().String("output", "text", "Output format: text, json or github (workflow annotations)")
+}
+
+// Breaking change kinds.
+const (
+	breakingOperationRemoved    = "operation-removed"
+	breakingParameterRequired   = "new-required-parameter"
+	breakingResponseRemoved     = "response-removed"
+	breakingFieldRemoved        = "field-removed"
+	breakingTypeChanged         = "type-changed"
+	breakingFieldRequired       = "new-required-field"
+	breakingFieldOptional       = "field-optional"
+	breakingEnumValueRemoved    = "enum-value-removed"
+	breakingServiceRemoved      = "service-removed"
+	breakingRPCRemoved          = "rpc-removed"
+	breakingRPCSignatureChanged = "rpc-signature-changed"
+	breakingMessageRemoved      = "message-removed"
+	breakingFieldNumberChanged  = "field-number-changed"
+	breakingProtoPackageChanged = "package-changed"
+)
+
+// apiSnapshotVersion is the format version of the snapshot file.
+const apiSnapshotVersion = 1
+
+// breakingChange is an incompatible change of an API definition.
+type breakingChange struct {
+	Kind     string `json:"kind"`
+	File     string `json:"file"`
+	Location string `json:"location"`
+	Message  string `json:"message"`
+}
+
+func (c breakingChange) String() string {
+	return fmt.Sprintf("%s: %s: %s %s", c.File, c.Kind, c.Location, c.Message)
+}
+
+// apiSnapshot is the content of the API definitions of a project, by path.
+type apiSnapshot struct {
+	Version int               `json:"version"`
+	Files   map[string]string `json:"files"`
+}
+
+func runBreaking(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	base, _ := cmd.Flags().GetString("base")
+	snapshot, _ := cmd.Flags().GetString("snapshot")
+	update, _ := cmd.Flags().GetBool("update-snapshot")
+	output, _ := cmd.Flags().GetString("output")
+	if output != "text" && output != "json" && output != "github" {
+		return fmt.Errorf("invalid --output %q: must be text, json or github", output)
+	}
+	if update && base != "" {
+		return fmt.Errorf("--update-snapshot records the current definitions; it cannot be combined with --base")
+	}
+	// Keep stdout machine-readable: the summary and errors go to stderr.
+	if output != "text" && ui != nil {
+		prevWriter := ui.writer
+		ui.writer = os.Stderr
+		defer func() { ui.writer = prevWriter }()
+	}
+
+	current, err := currentAPIDefinitions()
+	if err != nil {
+		return err
+	}
+	if update {
+		if err := saveAPISnapshot(snapshot, current); err != nil {
+			return err
+		}
+		ui.Success(fmt.Sprintf("Recorded %d API definition(s) in %s", len(current), snapshot))
+		return nil
+	}
+
+	var previous map[string]string
+	against := base
+	if base != "" {
+		previous, err = gitAPIDefinitions(base)
+	} else {
+		previous, err = loadAPISnapshot(snapshot)
+		against = snapshot
+	}
+	if err != nil {
+		return err
+	}
+	ui.Info(fmt.Sprintf("Comparing %d API definition(s) with %s", len(previous), against))
+
+	changes, err := compareAPIDefinitions(previous, current)
+	if err != nil {
+		return err
+	}
+
+	switch output {
+	case "json":
+		if changes == nil {
+			changes = []breakingChange{}
+		}
+		out, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	case "github":
+		for _, c := range changes {
+			fmt.Printf("::error file=%s,title=%s::%s %s\n", c.File, c.Kind, c.Location, c.Message)
+		}
+	default:
+		printBreakingReport(changes)
+	}
+
+	if len(changes) > 0 {
+		err := fmt.Errorf("%w: %d change(s)", errBreakingChanges, len(changes))
+		if output != "text" {
+			// Execute() would print the error on stdout after the report, so
+			// report it on stderr and exit with the same code here.
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+		return err
+	}
+	return nil
+}
+
+// isAPIDefinition reports whether path is an OpenAPI document or a proto
+// file goca breaking compares.
+func isAPIDefinition(path string) bool {
+	base := strings.ToLower(filepath.Base(path))
+	if strings.HasSuffix(base, ".proto") {
+		return true
+	}
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	switch filepath.Ext(base) {
+	case ".yaml", ".yml", ".json":
+		return name == "swagger" || name == "openapi"
+	}
+	return false
+}
+
+// currentAPIDefinitions reads the API definitions of the project, skipping
+// hidden directories, vendor and node_modules.
+func currentAPIDefinitions() (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != "." && skipAPIDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isAPIDefinition(path) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(path)] = string(content)
+		return nil
+	})
+	return files, err
+}
+
+// skipAPIDir reports whether the definitions of a directory are not the
+// project's own.
+func skipAPIDir(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules"
+}
+
+// gitAPIDefinitions reads the API definitions of the project at revision
+// rev. Paths are relative to the current directory, like those of
+// currentAPIDefinitions.
+func gitAPIDefinitions(rev string) (map[string]string, error) {
+	if err := exec.Command("git", "rev-parse", "--verify", "--quiet", rev+"^{commit}").Run(); err != nil {
+		return nil, fmt.Errorf("unknown git revision %q", rev)
+	}
+	out, err := exec.Command("git", "ls-tree", "-r", "--name-only", rev).Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing the files of %s: %w", rev, err)
+	}
+	files := make(map[string]string)
+	for _, path := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if path == "" || !isAPIDefinition(path) || slices.ContainsFunc(strings.Split(filepath.Dir(path), "/"), func(dir string) bool { return dir != "." && skipAPIDir(dir) }) {
+			continue
+		}
+		content, err := exec.Command("git", "show", rev+":./"+path).Output()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s at %s: %w", path, rev, err)
+		}
+		files[path] = string(content)
+	}
+	return files, nil
+}
+
+func loadAPISnapshot(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no API snapshot at %s: record one with goca breaking --update-snapshot, or compare with a git revision with --base", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var snapshot apiSnapshot
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if snapshot.Version != apiSnapshotVersion {
+		return nil, fmt.Errorf("%s has unsupported version %d; record it again with goca breaking --update-snapshot", path, snapshot.Version)
+	}
+	return snapshot.Files, nil
+}
+
+func saveAPISnapshot(path string, files map[string]string) error {
+	content, err := json.MarshalIndent(apiSnapshot{Version: apiSnapshotVersion, Files: files}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0o644)
+}
+
+// compareAPIDefinitions returns the breaking changes from the previous to
+// the current definitions, by file. A removed file breaks everything it
+// declared; a new file breaks nothing.
+func compareAPIDefinitions(previous, current map[string]string) ([]breakingChange, error) {
+	paths := make([]string, 0, len(previous))
+	for path := range previous {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var changes []breakingChange
+	for _, path := range paths {
+		var found []breakingChange
+		var err error
+		if strings.HasSuffix(strings.ToLower(path), ".proto") {
+			found, err = compareProtoFiles(path, previous[path], current[path])
+		} else {
+			found, err = compareOpenAPIFiles(path, previous[path], current[path])
+		}
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, found...)
+	}
+	return changes, nil
+}
+
+// printBreakingReport prints the changes one per line, followed by a count
+// per kind.
+func printBreakingReport(changes []breakingChange) {
+	if len(changes) == 0 {
+		ui.Success("No breaking API change")
+		return
+	}
+	perKind := make(map[string]int)
+	for _, c := range changes {
+		fmt.Println(c.String())
+		perKind[c.Kind]++
+	}
+	ui.Blank()
+	kinds := make([]string, 0, len(perKind))
+	for kind := range perKind {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		ui.KeyValue(kind, strconv.Itoa(perKind[kind]))
+	}
+}
+
+// openAPIComparison collects the breaking changes between two versions of
+// an OpenAPI document.
+type openAPIComparison struct {
+	file     string
+	old, new *openAPIDocument
+	seen     map[string]bool // component schemas compared, per direction
+	changes  []breakingChange
+}
+
+func (c *openAPIComparison) report(kind, location, message string) {
+	c.changes = append(c.changes, breakingChange{Kind: kind, File: c.file, Location: location, Message: message})
+}
+
+// compareOpenAPIFiles compares two versions of an OpenAPI document. The
+// current content is empty when the file was removed.
+func compareOpenAPIFiles(file, previous, current string) ([]breakingChange, error) {
+	old, err := parseOpenAPIDocument([]byte(previous))
+	if err != nil {
+		return nil, fmt.Errorf("%s (previous version): %w", file, err)
+	}
+	doc := &openAPIDocument{}
+	if current != "" {
+		if doc, err = parseOpenAPIDocument([]byte(current)); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	c := &openAPIComparison{file: file, old: old, new: doc, seen: make(map[string]bool)}
+	// Paths match whatever their parameters are named: /pets/{id} is
+	// /pets/{petId}.
+	newPaths := make(map[string]string, len(doc.Paths.Keys))
+	for _, path := range doc.Paths.Keys {
+		newPaths[openAPIPathPattern(path)] = path
+	}
+	for _, path := range old.Paths.Keys {
+		oldItem := old.Paths.Values[path]
+		newPath, ok := newPaths[openAPIPathPattern(path)]
+		newOps := make(map[string]*openAPIOperation)
+		var newItem openAPIPathItem
+		if ok {
+			newItem = doc.Paths.Values[newPath]
+			for _, op := range newItem.operations() {
+				newOps[op.Method] = op.Operation
+			}
+		}
+		for _, op := range oldItem.operations() {
+			location := op.Method + " " + path
+			current := newOps[op.Method]
+			if current == nil {
+				c.report(breakingOperationRemoved, location, "was removed")
+				continue
+			}
+			c.compareParameters(location, openAPIParameters(oldItem, op.Operation), openAPIParameters(newItem, current))
+			c.compareRequestBody(location, op.Operation.RequestBody, current.RequestBody)
+
+			before, after := op.Operation.successSchema(), current.successSchema()
+			switch {
+			case before != nil && after == nil:
+				c.report(breakingResponseRemoved, location, "no longer returns a response body")
+			case before != nil:
+				c.compareSchemas(location+" response", before, after, false, 0)
+			}
+		}
+	}
+	return c.changes, nil
+}
+
+// openAPIPathPattern replaces the parameter names of path with {}.
+func openAPIPathPattern(path string) string {
+	var b strings.Builder
+	inParam := false
+	for _, r := range path {
+		switch {
+		case r == '{':
+			inParam = true
+			b.WriteString("{}")
+		case r == '}':
+			inParam = false
+		case !inParam:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// openAPIParameters returns the parameters of an operation, those of the
+// path included, by location and name.
+func openAPIParameters(item openAPIPathItem, op *openAPIOperation) map[string]openAPIParameter {
+	params := make(map[string]openAPIParameter)
+	for _, p := range append(slices.Clone(item.Parameters), op.Parameters...) {
+		if p.Name != "" {
+			params[p.In+" "+p.Name] = p
+		}
+	}
+	return params
+}
+
+func (c *openAPIComparison) compareParameters(location string, before, after map[string]openAPIParameter) {
+	keys := make([]string, 0, len(after))
+	for key := range after {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		p := after[key]
+		old, existed := before[key]
+		switch {
+		case p.In == "path":
+			// Path parameters are part of the path, which matched.
+		case p.Required && !existed:
+			c.report(breakingParameterRequired, location, fmt.Sprintf("requires the new %s parameter %s", p.In, p.Name))
+		case p.Required && !old.Required:
+			c.report(breakingParameterRequired, location, fmt.Sprintf("now requires the %s parameter %s", p.In, p.Name))
+		}
+		if existed {
+			if from, to := c.schemaType(c.old, old.Schema), c.schemaType(c.new, p.Schema); from != "" && to != "" && from != to {
+				c.report(breakingTypeChanged, location, fmt.Sprintf("%s parameter %s changed type from %s to %s", p.In, p.Name, from, to))
+			}
+		}
+	}
+}
+
+func (c *openAPIComparison) compareRequestBody(location string, before, after *openAPIBody) {
+	switch {
+	case after == nil:
+		// Clients sending a body the operation ignores keep working.
+	case before == nil:
+		if after.Required {
+			c.report(breakingFieldRequired, location, "now requires a request body")
+		}
+	default:
+		if s := before.schema(); s != nil {
+			c.compareSchemas(location+" request", s, after.schema(), true, 0)
+		}
+	}
+}
+
+// schemaType returns the type and format of a schema, e.g. string/date-time,
+// or "" when it has none.
+func (c *openAPIComparison) schemaType(doc *openAPIDocument, s *openAPISchema) string {
+	s, _ = doc.resolve(s)
+	if s == nil || s.Type.Name == "" {
+		return ""
+	}
+	if s.Format != "" {
+		return s.Type.Name + "/" + s.Format
+	}
+	return s.Type.Name
+}
+
+// compareSchemas compares the previous and current schemas of a request
+// (clients write it) or a response (clients read it). Component schemas are
+// reported under their name, once per direction.
+func (c *openAPIComparison) compareSchemas(location string, before, after *openAPISchema, request bool, depth int) {
+	if depth > 16 || before == nil || after == nil {
+		return
+	}
+	from, to := c.schemaType(c.old, before), c.schemaType(c.new, after)
+	before, name := c.old.resolve(before)
+	after, _ = c.new.resolve(after)
+	if before == nil || after == nil {
+		return
+	}
+	if name != "" {
+		key := fmt.Sprintf("%s %t", name, request)
+		if c.seen[key] {
+			return
+		}
+		c.seen[key] = true
+		location = name
+	}
+	if from != "" && to != "" && from != to {
+		c.report(breakingTypeChanged, location, fmt.Sprintf("changed type from %s to %s", from, to))
+		return
+	}
+
+	if request && len(after.Enum) > 0 {
+		for _, v := range before.Enum {
+			if !slices.ContainsFunc(after.Enum, func(a any) bool { return fmt.Sprint(a) == fmt.Sprint(v) }) {
+				c.report(breakingEnumValueRemoved, location, fmt.Sprintf("no longer accepts %v", v))
+			}
+		}
+	}
+	if before.Items != nil {
+		c.compareSchemas(location+"[]", before.Items, after.Items, request, depth+1)
+	}
+
+	for _, prop := range before.Properties.Keys {
+		field := location + "." + prop
+		next, ok := after.Properties.Values[prop]
+		if !ok {
+			if request {
+				c.report(breakingFieldRemoved, field, "is no longer accepted")
+			} else {
+				c.report(breakingFieldRemoved, field, "is no longer returned")
+			}
+			continue
+		}
+		c.compareSchemas(field, before.Properties.Values[prop], next, request, depth+1)
+	}
+
+	if request {
+		for _, prop := range after.Required {
+			if slices.Contains(before.Required, prop) {
+				continue
+			}
+			if _, existed := before.Properties.Values[prop]; existed {
+				c.report(breakingFieldRequired, location+"."+prop, "is now required")
+			} else {
+				c.report(breakingFieldRequired, location+"."+prop, "is a new required field")
+			}
+		}
+		return
+	}
+	for _, prop := range before.Required {
+		if _, ok := after.Properties.Values[prop]; ok && !slices.Contains(after.Required, prop) {
+			c.report(breakingFieldOptional, location+"."+prop, "is no longer required in responses")
+		}
+	}
+}
@@ -0,0 +1,443 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// protoDefinition is what goca breaking compares of a .proto file.
+type protoDefinition struct {
+	pkg      string
+	services map[string]map[string]protoRPC
+	messages map[string]*protoMessage
+	enums    map[string]*protoEnum
+}
+
+// protoRPC is the signature of a gRPC method, e.g. "stream Event".
+type protoRPC struct {
+	request, response string
+}
+
+// protoMessage holds the fields of a message by name, and the field numbers
+// and names it reserves.
+type protoMessage struct {
+	fields        map[string]protoField
+	reserved      [][2]int // inclusive ranges
+	reservedNames map[string]bool
+}
+
+// reserves reports whether the message reserves a field number.
+func (m *protoMessage) reserves(number int) bool {
+	for _, r := range m.reserved {
+		if number >= r[0] && number <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// protoField is a message field. The label is repeated, optional, required
+// or empty.
+type protoField struct {
+	label  string
+	typ    string
+	number int
+}
+
+// protoEnum holds the values of an enum by name.
+type protoEnum struct {
+	values map[string]int
+}
+
+// protoToken matches comments, strings, identifiers, numbers and symbols.
+var protoToken = regexp.MustCompile(`//[^\n]*|/\*(?s:.*?)\*/|"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|[A-Za-z_.][\w.]*|-?\d[\w.]*|[{}();=<>,\[\]]`)
+
+// protoParser reads a .proto file token by token. It keeps what
+// protoDefinition needs and skips options, imports and extensions.
+type protoParser struct {
+	tokens []string
+	pos    int
+	def    *protoDefinition
+}
+
+// parseProto parses the services, messages and enums of a .proto file.
+// Nested messages and enums are named Outer.Inner.
+func parseProto(source string) (*protoDefinition, error) {
+	p := &protoParser{def: &protoDefinition{
+		services: make(map[string]map[string]protoRPC),
+		messages: make(map[string]*protoMessage),
+		enums:    make(map[string]*protoEnum),
+	}}
+	for _, tok := range protoToken.FindAllString(source, -1) {
+		if !strings.HasPrefix(tok, "//") && !strings.HasPrefix(tok, "/*") {
+			p.tokens = append(p.tokens, tok)
+		}
+	}
+	for p.pos < len(p.tokens) {
+		var err error
+		switch tok := p.next(); tok {
+		case "package":
+			p.def.pkg = p.next()
+			err = p.expect(";")
+		case "message":
+			err = p.message("")
+		case "enum":
+			err = p.enum("")
+		case "service":
+			err = p.service()
+		case ";":
+		default:
+			err = p.skipStatement()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p.def, nil
+}
+
+func (p *protoParser) next() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	p.pos++
+	return p.tokens[p.pos-1]
+}
+
+func (p *protoParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *protoParser) expect(tok string) error {
+	if got := p.next(); got != tok {
+		if got == "" {
+			return fmt.Errorf("expected %q, found the end of the file", tok)
+		}
+		return fmt.Errorf("expected %q, found %q", tok, got)
+	}
+	return nil
+}
+
+// skipStatement skips to the end of the current statement: a ";" or a
+// block, whichever comes first.
+func (p *protoParser) skipStatement() error {
+	depth := 0
+	for p.pos < len(p.tokens) {
+		switch p.next() {
+		case "{":
+			depth++
+		case "}":
+			depth--
+			if depth == 0 {
+				return nil
+			}
+			if depth < 0 {
+				return fmt.Errorf("unbalanced %q", "}")
+			}
+		case ";":
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+	if depth > 0 {
+		return fmt.Errorf("unterminated block")
+	}
+	return nil
+}
+
+func (p *protoParser) message(prefix string) error {
+	name := prefix + p.next()
+	msg := &protoMessage{fields: make(map[string]protoField), reservedNames: make(map[string]bool)}
+	p.def.messages[name] = msg
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	return p.messageBody(name, msg)
+}
+
+// messageBody reads fields up to the "}" closing a message or oneof.
+func (p *protoParser) messageBody(name string, msg *protoMessage) error {
+	for {
+		var err error
+		switch tok := p.next(); tok {
+		case "}":
+			return nil
+		case "":
+			return fmt.Errorf("message %s is not closed", name)
+		case ";":
+		case "message":
+			err = p.message(name + ".")
+		case "enum":
+			err = p.enum(name + ".")
+		case "oneof":
+			p.next()
+			if err = p.expect("{"); err == nil {
+				err = p.messageBody(name, msg)
+			}
+		case "reserved":
+			p.reservedRanges(msg)
+		case "option", "extensions", "extend":
+			err = p.skipStatement()
+		default:
+			err = p.field(tok, msg)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// field reads a field declaration whose first token is first:
+// [label] type name = number [options];
+func (p *protoParser) field(first string, msg *protoMessage) error {
+	f := protoField{}
+	if first == "repeated" || first == "optional" || first == "required" {
+		f.label, first = first, p.next()
+	}
+	f.typ = first
+	if first == "map" {
+		// map<key, value>
+		var b strings.Builder
+		b.WriteString("map")
+		for tok := p.next(); tok != ">" && tok != ""; tok = p.next() {
+			b.WriteString(tok)
+			if tok == "," {
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString(">")
+		f.typ = b.String()
+	}
+	fieldName := p.next()
+	if err := p.expect("="); err != nil {
+		return fmt.Errorf("field %s: %w", fieldName, err)
+	}
+	number, err := strconv.Atoi(p.next())
+	if err != nil {
+		return fmt.Errorf("field %s: invalid number", fieldName)
+	}
+	f.number = number
+	msg.fields[fieldName] = f
+	return p.skipStatement()
+}
+
+// reservedRanges reads reserved 2, 15, 9 to 11; or reserved "foo", "bar";
+func (p *protoParser) reservedRanges(msg *protoMessage) {
+	for tok := p.next(); tok != ";" && tok != ""; tok = p.next() {
+		if tok == "," {
+			continue
+		}
+		if name, err := strconv.Unquote(tok); err == nil {
+			msg.reservedNames[name] = true
+			continue
+		}
+		from, err := strconv.Atoi(tok)
+		if err != nil {
+			continue
+		}
+		to := from
+		if p.peek() == "to" {
+			p.next()
+			if end := p.next(); end == "max" {
+				to = 1<<29 - 1
+			} else if n, err := strconv.Atoi(end); err == nil {
+				to = n
+			}
+		}
+		msg.reserved = append(msg.reserved, [2]int{from, to})
+	}
+}
+
+func (p *protoParser) enum(prefix string) error {
+	name := prefix + p.next()
+	e := &protoEnum{values: make(map[string]int)}
+	p.def.enums[name] = e
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		switch tok := p.next(); tok {
+		case "}":
+			return nil
+		case "":
+			return fmt.Errorf("enum %s is not closed", name)
+		case ";":
+		case "option", "reserved":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		default:
+			if err := p.expect("="); err != nil {
+				return fmt.Errorf("enum %s: %w", name, err)
+			}
+			n, err := strconv.Atoi(p.next())
+			if err != nil {
+				return fmt.Errorf("enum %s: invalid value of %s", name, tok)
+			}
+			e.values[tok] = n
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *protoParser) service() error {
+	name := p.next()
+	rpcs := make(map[string]protoRPC)
+	p.def.services[name] = rpcs
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		switch tok := p.next(); tok {
+		case "}":
+			return nil
+		case "":
+			return fmt.Errorf("service %s is not closed", name)
+		case ";":
+		case "rpc":
+			method := p.next()
+			request, err := p.rpcType()
+			if err != nil {
+				return fmt.Errorf("rpc %s.%s: %w", name, method, err)
+			}
+			if err := p.expect("returns"); err != nil {
+				return fmt.Errorf("rpc %s.%s: %w", name, method, err)
+			}
+			response, err := p.rpcType()
+			if err != nil {
+				return fmt.Errorf("rpc %s.%s: %w", name, method, err)
+			}
+			rpcs[method] = protoRPC{request: request, response: response}
+			if p.peek() == ";" {
+				p.next()
+			} else if err := p.skipStatement(); err != nil {
+				return err
+			}
+		default:
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// rpcType reads ([stream] Type).
+func (p *protoParser) rpcType() (string, error) {
+	if err := p.expect("("); err != nil {
+		return "", err
+	}
+	typ := p.next()
+	if typ == "stream" && p.peek() != ")" {
+		typ = "stream " + p.next()
+	}
+	return typ, p.expect(")")
+}
+
+// compareProtoFiles compares two versions of a .proto file. The current
+// content is empty when the file was removed.
+func compareProtoFiles(file, previous, current string) ([]breakingChange, error) {
+	old, err := parseProto(previous)
+	if err != nil {
+		return nil, fmt.Errorf("%s (previous version): %w", file, err)
+	}
+	def, err := parseProto(current)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	var changes []breakingChange
+	report := func(kind, location, message string) {
+		changes = append(changes, breakingChange{Kind: kind, File: file, Location: location, Message: message})
+	}
+
+	if current != "" && old.pkg != def.pkg {
+		report(breakingProtoPackageChanged, "package "+old.pkg, fmt.Sprintf("was renamed to %s, changing every method path", def.pkg))
+	}
+
+	for _, service := range slices.Sorted(maps.Keys(old.services)) {
+		rpcs, ok := def.services[service]
+		if !ok {
+			report(breakingServiceRemoved, "service "+service, "was removed")
+			continue
+		}
+		for _, method := range slices.Sorted(maps.Keys(old.services[service])) {
+			before := old.services[service][method]
+			after, ok := rpcs[method]
+			location := "rpc " + service + "." + method
+			switch {
+			case !ok:
+				report(breakingRPCRemoved, location, "was removed")
+			case before != after:
+				report(breakingRPCSignatureChanged, location, fmt.Sprintf("changed from (%s) returns (%s) to (%s) returns (%s)", before.request, before.response, after.request, after.response))
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(old.messages)) {
+		after, ok := def.messages[name]
+		if !ok {
+			report(breakingMessageRemoved, "message "+name, "was removed")
+			continue
+		}
+		before := old.messages[name]
+		for _, fieldName := range slices.Sorted(maps.Keys(before.fields)) {
+			f := before.fields[fieldName]
+			location := name + "." + fieldName
+			next, ok := after.fields[fieldName]
+			switch {
+			case !ok:
+				if !after.reserves(f.number) && !after.reservedNames[fieldName] {
+					report(breakingFieldRemoved, location, fmt.Sprintf("was removed without reserving number %d", f.number))
+				}
+			case next.number != f.number:
+				report(breakingFieldNumberChanged, location, fmt.Sprintf("changed number from %d to %d", f.number, next.number))
+			case protoWireType(f) != protoWireType(next):
+				report(breakingTypeChanged, location, fmt.Sprintf("changed type from %s to %s", protoWireType(f), protoWireType(next)))
+			case next.label == "required" && f.label != "required":
+				report(breakingFieldRequired, location, "is now required")
+			}
+		}
+		for _, fieldName := range slices.Sorted(maps.Keys(after.fields)) {
+			if _, existed := before.fields[fieldName]; !existed && after.fields[fieldName].label == "required" {
+				report(breakingFieldRequired, name+"."+fieldName, "is a new required field")
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(old.enums)) {
+		after, ok := def.enums[name]
+		if !ok {
+			report(breakingMessageRemoved, "enum "+name, "was removed")
+			continue
+		}
+		for _, value := range slices.Sorted(maps.Keys(old.enums[name].values)) {
+			n, ok := after.values[value]
+			switch {
+			case !ok:
+				report(breakingEnumValueRemoved, name+"."+value, "was removed")
+			case n != old.enums[name].values[value]:
+				report(breakingFieldNumberChanged, name+"."+value, fmt.Sprintf("changed number from %d to %d", old.enums[name].values[value], n))
+			}
+		}
+	}
+	return changes, nil
+}
+
+// protoWireType is the type of a field as it is encoded: optional does not
+// change the encoding, repeated does.
+func protoWireType(f protoField) string {
+	if f.label == "repeated" {
+		return "repeated " + f.typ
+	}
+	return f.typ
+}
@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const breakingSpec = `openapi: 3.0.0
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/CreatePet'
+      responses:
+        '201':
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+  /pets/{id}:
+    delete:
+      responses:
+        '204':
+          description: Deleted
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id]
+      properties:
+        id:
+          type: integer
+        name:
+          type: string
+        tag:
+          type: string
+    CreatePet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        kind:
+          type: string
+          enum: [cat, dog]
+`
+
+func breakingKinds(changes []breakingChange) map[string]string {
+	kinds := make(map[string]string, len(changes))
+	for _, c := range changes {
+		kinds[c.Location] = c.Kind
+	}
+	return kinds
+}
+
+func TestCompareOpenAPIFiles(t *testing.T) {
+	changes, err := compareOpenAPIFiles("openapi.yaml", breakingSpec, breakingSpec)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// Additive changes and renamed path parameters are compatible.
+	current := replaceAll(breakingSpec,
+		"  /pets/{id}:", "  /pets/{petId}:",
+		"        tag:\n          type: string\n", "        tag:\n          type: string\n        age:\n          type: integer\n")
+	changes, err = compareOpenAPIFiles("openapi.yaml", breakingSpec, current)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	current = replaceAll(breakingSpec,
+		"    delete:\n      responses:\n        '204':\n          description: Deleted\n", "    get:\n      responses:\n        '200':\n          description: Found\n",
+		"          schema:\n            type: integer\n", "          required: true\n          schema:\n            type: string\n",
+		"        tag:\n          type: string\n", "",
+		"      required: [id]\n", "",
+		"      required: [name]\n", "      required: [name, owner]\n",
+		"          enum: [cat, dog]\n", "          enum: [cat]\n")
+	changes, err = compareOpenAPIFiles("openapi.yaml", breakingSpec, current)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"GET /pets":         breakingTypeChanged,
+		"DELETE /pets/{id}": breakingOperationRemoved,
+		"Pet.tag":           breakingFieldRemoved,
+		"Pet.id":            breakingFieldOptional,
+		"CreatePet.owner":   breakingFieldRequired,
+		"CreatePet.kind":    breakingEnumValueRemoved,
+	}, withoutParameterRequired(t, changes))
+
+	// A removed document removes every operation.
+	changes, err = compareOpenAPIFiles("openapi.yaml", breakingSpec, "")
+	require.NoError(t, err)
+	assert.Len(t, changes, 3)
+	for _, c := range changes {
+		assert.Equal(t, breakingOperationRemoved, c.Kind)
+	}
+}
+
+// withoutParameterRequired checks the limit parameter became required and
+// returns the other changes by location.
+func withoutParameterRequired(t *testing.T, changes []breakingChange) map[string]string {
+	t.Helper()
+	kinds := map[string]string{}
+	required := false
+	for _, c := range changes {
+		if c.Kind == breakingParameterRequired {
+			assert.Equal(t, "GET /pets", c.Location)
+			assert.Equal(t, "now requires the query parameter limit", c.Message)
+			required = true
+			continue
+		}
+		if c.Kind == breakingEnumValueRemoved {
+			assert.Equal(t, "no longer accepts dog", c.Message)
+		}
+		kinds[c.Location] = c.Kind
+	}
+	assert.True(t, required)
+	return kinds
+}
+
+// replaceAll applies old, new replacement pairs to s, each once.
+func replaceAll(s string, pairs ...string) string {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if !strings.Contains(s, pairs[i]) {
+			panic("replaceAll: " + pairs[i] + " not found")
+		}
+		s = strings.Replace(s, pairs[i], pairs[i+1], 1)
+	}
+	return s
+}
+
+const breakingProto = `syntax = "proto3";
+
+package shop.v1;
+
+// OrderService manages orders.
+service OrderService {
+  rpc CreateOrder(CreateOrderRequest) returns (Order);
+  rpc WatchOrders(WatchOrdersRequest) returns (stream Order) {
+    option deprecated = false;
+  }
+  rpc CancelOrder(CancelOrderRequest) returns (Order);
+}
+
+message Order {
+  int64 id = 1;
+  string code = 2; /* customer facing */
+  repeated Line lines = 3;
+  map<string, string> labels = 4;
+  Status status = 5;
+  oneof payment {
+    string card = 6;
+    string iban = 7;
+  }
+
+  message Line {
+    string sku = 1;
+    int32 quantity = 2 [json_name = "qty"];
+  }
+
+  enum Status {
+    STATUS_UNSPECIFIED = 0;
+    STATUS_OPEN = 1;
+    STATUS_PAID = 2;
+  }
+}
+
+message CreateOrderRequest {
+  string code = 1;
+}
+
+message WatchOrdersRequest {}
+
+message CancelOrderRequest {
+  int64 id = 1;
+}
+`
+
+func TestParseProto(t *testing.T) {
+	def, err := parseProto(breakingProto)
+	require.NoError(t, err)
+	assert.Equal(t, "shop.v1", def.pkg)
+	assert.Equal(t, protoRPC{request: "WatchOrdersRequest", response: "stream Order"}, def.services["OrderService"]["WatchOrders"])
+	assert.Len(t, def.services["OrderService"], 3)
+	assert.Equal(t, protoField{label: "repeated", typ: "Line", number: 3}, def.messages["Order"].fields["lines"])
+	assert.Equal(t, "map<string, string>", def.messages["Order"].fields["labels"].typ)
+	assert.Equal(t, 7, def.messages["Order"].fields["iban"].number)
+	assert.Equal(t, 2, def.messages["Order.Line"].fields["quantity"].number)
+	assert.Equal(t, 2, def.enums["Order.Status"].values["STATUS_PAID"])
+
+	_, err = parseProto("message Order {\n  string code = 1;\n")
+	assert.ErrorContains(t, err, "message Order is not closed")
+}
+
+func TestCompareProtoFiles(t *testing.T) {
+	changes, err := compareProtoFiles("order.proto", breakingProto, breakingProto)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// Removing a field whose number is reserved and adding fields is safe.
+	current := replaceAll(breakingProto,
+		"message CancelOrderRequest {\n  int64 id = 1;\n}", "message CancelOrderRequest {\n  reserved 1;\n  string code = 2;\n}",
+		"  string code = 2; /* customer facing */\n", "  string code = 2;\n  string note = 8;\n")
+	changes, err = compareProtoFiles("order.proto", breakingProto, current)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	current = replaceAll(breakingProto,
+		"  rpc CancelOrder(CancelOrderRequest) returns (Order);\n", "",
+		"returns (stream Order)", "returns (Order)",
+		"  repeated Line lines = 3;\n", "  Line lines = 3;\n",
+		"  map<string, string> labels = 4;\n", "  map<string, string> labels = 9;\n",
+		"    string iban = 7;\n", "",
+		"    STATUS_PAID = 2;\n", "",
+		"message CreateOrderRequest {\n  string code = 1;\n}\n", "message CreateOrderRequest {\n  string code = 1;\n  required string currency = 2;\n}\n",
+		"message WatchOrdersRequest {}\n", "")
+	changes, err = compareProtoFiles("order.proto", breakingProto, current)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"rpc OrderService.CancelOrder": breakingRPCRemoved,
+		"rpc OrderService.WatchOrders": breakingRPCSignatureChanged,
+		"Order.lines":                  breakingTypeChanged,
+		"Order.labels":                 breakingFieldNumberChanged,
+		"Order.iban":                   breakingFieldRemoved,
+		"Order.Status.STATUS_PAID":     breakingEnumValueRemoved,
+		"CreateOrderRequest.currency":  breakingFieldRequired,
+		"message WatchOrdersRequest":   breakingMessageRemoved,
+	}, breakingKinds(changes))
+
+	changes, err = compareProtoFiles("order.proto", breakingProto, replaceAll(breakingProto, "package shop.v1;", "package shop.v2;"))
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, breakingProtoPackageChanged, changes[0].Kind)
+}
+
+func TestRunBreaking_Snapshot(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	writeTestFile(t, ".", filepath.Join("api", "openapi.yaml"), breakingSpec)
+	writeTestFile(t, ".", filepath.Join("internal", "handler", "grpc", "order.proto"), breakingProto)
+	writeTestFile(t, ".", filepath.Join("vendor", "example.com", "lib", "lib.proto"), "syntax = \"proto3\";\n")
+	writeTestFile(t, ".", filepath.Join("docs", "swagger.md"), "# not a spec\n")
+
+	defer func() {
+		_ = breakingCmd.Flags().Set("update-snapshot", "false")
+	}()
+	assert.ErrorContains(t, runBreaking(breakingCmd, nil), "goca breaking --update-snapshot")
+
+	require.NoError(t, breakingCmd.Flags().Set("update-snapshot", "true"))
+	require.NoError(t, runBreaking(breakingCmd, nil))
+	previous, err := loadAPISnapshot(defaultAPISnapshot)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api/openapi.yaml", "internal/handler/grpc/order.proto"}, slices.Sorted(maps.Keys(previous)))
+
+	require.NoError(t, breakingCmd.Flags().Set("update-snapshot", "false"))
+	require.NoError(t, runBreaking(breakingCmd, nil))
+
+	writeTestFile(t, ".", filepath.Join("internal", "handler", "grpc", "order.proto"), replaceAll(breakingProto, "  int64 id = 1;\n  string code", "  string id = 1;\n  string code"))
+	err = runBreaking(breakingCmd, nil)
+	assert.ErrorIs(t, err, errBreakingChanges)
+	assert.ErrorContains(t, err, "1 change(s)")
+}
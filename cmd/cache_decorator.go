@@ -103,12 +103,24 @@ func generateCacheDecorator(entity string, fields []Field, sm ...*SafetyManager)
 	// parsed fields); when no fields are known, emit none so we never reference
 	// an undefined inner method like FindByEmail on an entity without that field.
 	if len(fields) > 0 {
-		searchMethods := generateSearchMethods(fields, entity)
+		searchMethods := generateRepositoryMethods(fields, entity)
 		for _, m := range searchMethods {
 			generateCacheSearchMethodDelegate(&b, entity, m)
 		}
 	}
 
+	// Count, ExistsByID and FindAllPaged — delegate only, they change with
+	// every write.
+	fmt.Fprintf(&b, "func (r *Cached%sRepository) Count(ctx context.Context, spec %sSpec) (int64, error) {\n", entity, entity)
+	b.WriteString("\treturn r.inner.Count(ctx, spec)\n")
+	b.WriteString("}\n\n")
+	fmt.Fprintf(&b, "func (r *Cached%sRepository) ExistsByID(ctx context.Context, id int) (bool, error) {\n", entity)
+	b.WriteString("\treturn r.inner.ExistsByID(ctx, id)\n")
+	b.WriteString("}\n\n")
+	fmt.Fprintf(&b, "func (r *Cached%sRepository) FindAllPaged(ctx context.Context, limit, offset int) ([]domain.%s, int64, error) {\n", entity, entity)
+	b.WriteString("\treturn r.inner.FindAllPaged(ctx, limit, offset)\n")
+	b.WriteString("}\n\n")
+
 	// Update — delegate + invalidate
 	b.WriteString(fmt.Sprintf("func (r *Cached%sRepository) Update(%s *domain.%s) error {\n", entity, entityLower, entity))
 	b.WriteString(fmt.Sprintf("\tif err := r.inner.Update(%s); err != nil {\n", entityLower))
@@ -171,7 +183,9 @@ func generateCacheDecorator(entity string, fields []Field, sm ...*SafetyManager)
 // the entity declares transactional methods (SaveWithTx). It reads the already
 // generated interfaces.go; if it cannot be read, it returns false.
 func interfaceHasTransactions(interfacesPath, entity string) bool {
+	unlock := lockSharedFile(interfacesPath)
 	data, err := os.ReadFile(interfacesPath)
+	unlock()
 	if err != nil {
 		return false
 	}
@@ -180,10 +194,8 @@ func interfaceHasTransactions(interfacesPath, entity string) bool {
 
 // generateCacheSearchMethodDelegate generates a delegate-only method for a search method.
 func generateCacheSearchMethodDelegate(b *strings.Builder, entity string, m SearchMethod) {
-	paramName := strings.ToLower(m.FieldName)
-
-	fmt.Fprintf(b, "func (r *Cached%sRepository) %s(%s %s) %s {\n",
-		entity, m.MethodName, paramName, m.FieldType, m.ReturnType)
-	fmt.Fprintf(b, "\treturn r.inner.%s(%s)\n", m.MethodName, paramName)
+	fmt.Fprintf(b, "func (r *Cached%sRepository) %s(%s) %s {\n",
+		entity, m.MethodName, m.params(), m.ReturnType)
+	fmt.Fprintf(b, "\treturn r.inner.%s(%s)\n", m.MethodName, m.args())
 	b.WriteString("}\n\n")
 }
@@ -0,0 +1,459 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// cdcDir is the package holding the generated change data capture handlers.
+var cdcDir = filepath.Join(DirInternal, "cdc")
+
+// Targets of goca cdc: a read model kept in the entity's table, or the
+// entity's use case.
+const (
+	CDCTargetReadModel = "readmodel"
+	CDCTargetUseCase   = "usecase"
+)
+
+// ValidCDCTargets are the values of --target.
+var ValidCDCTargets = []string{CDCTargetReadModel, CDCTargetUseCase}
+
+// cdcHandlersMarker marks where goca cdc registers topic handlers in
+// cmd/cdc/main.go.
+const cdcHandlersMarker = "// goca:cdc-handlers -- topic handlers are registered above this line"
+
+var cdcCmd = &cobra.Command{
+	Use:   "cdc <Entity>",
+	Short: "Generate a consumer of Debezium change events",
+	Long: `Generates a consumer of Debezium change data capture (CDC) events from Kafka
+that applies the changes of a source table to an entity.
+
+  - internal/cdc/<entity>.go  The handler mapping change records to the
+                              entity, to adjust to the source table
+  - internal/cdc/debezium.go  Event parsing, tombstones, column conversions
+                              and the Kafka consumer group loop
+  - cmd/cdc/main.go           Opens the database and consumes the registered
+                              topics
+
+--target readmodel upserts the rows into the entity's table by primary key
+and deletes them on deletes, keeping a local read model of the source table.
+--target usecase calls the entity's use case instead, so its validation and
+business rules run on every change.
+
+Events with and without schemas and events flattened by the
+ExtractNewRecordState transform are read. Columns added to or dropped from
+the source table read as zero values instead of failing, and timestamps,
+decimals and booleans are converted from their Debezium representations.
+The tombstone Debezium sends after a delete is applied as a delete.
+
+Run the consumer with:
+
+  go run ./cmd/cdc -brokers localhost:9092 -group shop-cdc
+
+Examples:
+  goca cdc Order --topic dbserver1.public.orders
+  goca cdc Customer --topic erp.inventory.customers --target usecase`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entity := args[0]
+		topic, _ := cmd.Flags().GetString("topic")
+		target, _ := cmd.Flags().GetString("target")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if !contains(ValidCDCTargets, target) {
+			return fmt.Errorf("unsupported --target %q: use one of %s", target, strings.Join(ValidCDCTargets, ", "))
+		}
+		ci := NewConfigIntegration()
+		_ = ci.LoadConfigForProject()
+		database := ci.GetDatabaseType("")
+		if sqlDialect(database) < 0 {
+			return fmt.Errorf("goca cdc only supports SQL databases, not %s", database)
+		}
+		if _, err := findEntityFile(entity); err != nil {
+			return fmt.Errorf("%w; generate it first with goca feature %s", err, entity)
+		}
+		if target == CDCTargetUseCase {
+			if findTypeFile(filepath.Join(DirInternal, DirUseCase), entity+"UseCase") == "" {
+				return fmt.Errorf("%sUseCase not found in %s; generate it with goca feature %s or use --target readmodel", entity, filepath.Join(DirInternal, DirUseCase), entity)
+			}
+			if ops := useCaseOperations(entity); !contains(ops, "create") || !contains(ops, "read") || !contains(ops, "update") || !contains(ops, "delete") {
+				return fmt.Errorf("%sUseCase must declare create, read, update and delete for --target usecase; it declares %s", entity, strings.Join(ops, ", "))
+			}
+		}
+		if topic == "" {
+			topic = "dbserver1.public." + entityTableName(entity)
+		}
+		path := filepath.Join(cdcDir, toSnakeCase(entity)+".go")
+		if _, err := os.Stat(path); err == nil && !force {
+			return fmt.Errorf("%s already exists; use --force to regenerate it", path)
+		}
+
+		ui.Header("Goca CDC")
+		ui.Blank()
+		ui.KeyValue("Entity", entity)
+		ui.KeyValue("Topic", topic)
+		ui.KeyValue("Target", target)
+		ui.Blank()
+
+		sm := NewSafetyManager(dryRun, force, false)
+		if err := generateCDCConsumer(entity, topic, target, database, sm); err != nil {
+			return err
+		}
+		if dryRun {
+			sm.PrintSummary()
+			return nil
+		}
+
+		projectRoot, _ := os.Getwd()
+		depMgr := NewDependencyManager(projectRoot, false)
+		for _, dep := range depMgr.GetRequiredDependenciesForFeature("", map[string]bool{"cdc": true}) {
+			if err := depMgr.AddDependency(dep); err != nil {
+				ui.Warning(fmt.Sprintf("Could not add dependency %s: %v", dep.Module, err))
+			}
+		}
+
+		ui.Blank()
+		ui.NextSteps([]string{
+			"Match the source table's columns in " + path,
+			"Point a Debezium connector at the source table, publishing to " + topic,
+			"Run 'go run ./cmd/cdc -brokers <host:port>'",
+		})
+		return nil
+	},
+}
+
+// generateCDCConsumer writes the entity's handler, the runtime, regenerated
+// on every run, and registers the handler in cmd/cdc/main.go.
+func generateCDCConsumer(entity, topic, target, database string, sm *SafetyManager) error {
+	importPath := getImportPath(getModuleName())
+	file, err := findEntityFile(entity)
+	if err != nil {
+		return err
+	}
+	src, err := parseGoSource(file)
+	if err != nil {
+		return err
+	}
+	fields := readEntityFields(src.file, entity)
+
+	var content string
+	registration := fmt.Sprintf("\t\t%q: cdc.New%sProjection(db),\n", topic, entity)
+	if target == CDCTargetUseCase {
+		content = buildCDCConsumer(entity, importPath, fields, database)
+		registration = fmt.Sprintf("\t\t%q: cdc.New%sConsumer(container.%sUseCase()),\n", topic, entity, entity)
+	} else {
+		content = buildCDCProjection(entity, importPath, fields, database)
+	}
+	formatted, err := syncImports([]byte(content), "time", "gorm.io/gorm", "gorm.io/datatypes")
+	if err != nil {
+		return fmt.Errorf("generated change data capture handler does not parse: %w", err)
+	}
+	if err := writeGoFile(filepath.Join(cdcDir, toSnakeCase(entity)+".go"), string(formatted), sm); err != nil {
+		return fmt.Errorf("writing change data capture handler: %w", err)
+	}
+	if err := writeGoFileMerged(filepath.Join(cdcDir, "debezium.go"), cdcRuntimeSource, sm); err != nil {
+		return fmt.Errorf("writing change data capture runtime: %w", err)
+	}
+	return registerCDCHandler(entity, registration, importPath, database, sm)
+}
+
+// registerCDCHandler creates cmd/cdc/main.go on first use and registers the
+// handler of a topic above cdcHandlersMarker, replacing an earlier
+// registration of the entity.
+func registerCDCHandler(entity, registration, importPath, database string, sm *SafetyManager) error {
+	mainPath := filepath.Join("cmd", "cdc", "main.go")
+	raw, err := os.ReadFile(mainPath)
+	if err != nil {
+		raw = []byte(buildCDCMain(importPath, database, projectSQLiteDriver()))
+	}
+	// A handler regenerated with another --target replaces its registration.
+	var lines []string
+	for _, line := range strings.SplitAfter(string(raw), "\n") {
+		if !strings.Contains(line, "cdc.New"+entity+"Projection(") && !strings.Contains(line, "cdc.New"+entity+"Consumer(") {
+			lines = append(lines, line)
+		}
+	}
+	content := strings.Join(lines, "")
+	if !strings.Contains(content, cdcHandlersMarker) {
+		ui.Dim(fmt.Sprintf("   Register the handler in %s: %s", mainPath, strings.TrimSpace(registration)))
+		return nil
+	}
+	content = strings.Replace(content, "\t\t"+cdcHandlersMarker, registration+"\t\t"+cdcHandlersMarker, 1)
+	if strings.Contains(registration, "container.") && !strings.Contains(content, "container := di.NewContainer(db)") {
+		content = strings.Replace(content, "\treturn map[string]cdc.Handler{", "\tcontainer := di.NewContainer(db)\n\treturn map[string]cdc.Handler{", 1)
+	}
+	if strings.Contains(content, "container := di.NewContainer(db)") && !strings.Contains(content, importPath+"/internal/di\"") {
+		content = strings.Replace(content, fmt.Sprintf("\t%q\n", importPath+"/internal/cdc"), fmt.Sprintf("\t%q\n\t%q\n", importPath+"/internal/cdc", importPath+"/internal/di"), 1)
+	}
+	if err := writeGoFileMerged(mainPath, content, sm); err != nil {
+		return fmt.Errorf("writing change data capture entrypoint: %w", err)
+	}
+	return nil
+}
+
+// buildCDCProjection returns the handler keeping the entity's table a read
+// model of the source table.
+func buildCDCProjection(entity, importPath string, fields []Field, database string) string {
+	v := strings.ToLower(entity[:1]) + entity[1:]
+	var b strings.Builder
+	writeCDCHeader(&b, importPath, "\t\"gorm.io/gorm\"\n\t\"gorm.io/gorm/clause\"\n")
+
+	fmt.Fprintf(&b, "// %sProjection keeps the table of %s a read model of the source table,\n", v, entity)
+	b.WriteString("// upserting its rows by primary key.\n")
+	fmt.Fprintf(&b, "type %sProjection struct {\n\tdb *gorm.DB\n}\n\n", v)
+	fmt.Fprintf(&b, "// New%sProjection returns the handler of the %s change events.\n", entity, entity)
+	fmt.Fprintf(&b, "func New%sProjection(db *gorm.DB) Handler {\n\treturn &%sProjection{db: db}\n}\n\n", entity, v)
+
+	b.WriteString("// Apply upserts created, read and updated rows and deletes deleted ones.\n")
+	b.WriteString("// Truncates are ignored.\n")
+	fmt.Fprintf(&b, "func (p *%sProjection) Apply(ctx context.Context, change Change) error {\n", v)
+	b.WriteString("\tdb := p.db.WithContext(ctx)\n")
+	b.WriteString("\tswitch change.Op {\n")
+	b.WriteString("\tcase OpCreate, OpRead, OpUpdate:\n")
+	fmt.Fprintf(&b, "\t\te, err := %sFromRow(change.After)\n", v)
+	b.WriteString("\t\tif err != nil {\n\t\t\treturn err\n\t\t}\n")
+	b.WriteString("\t\tupsert := clause.OnConflict{Columns: []clause.Column{{Name: \"id\"}}, UpdateAll: true}\n")
+	b.WriteString("\t\treturn db.Clauses(upsert).Create(&e).Error\n")
+	b.WriteString("\tcase OpDelete:\n")
+	fmt.Fprintf(&b, "\t\treturn db.Delete(&domain.%s{}, change.Key.Int64(\"id\")).Error\n", entity)
+	b.WriteString("\t}\n")
+	b.WriteString("\treturn nil\n")
+	b.WriteString("}\n\n")
+
+	writeCDCFromRow(&b, entity, fields, database, true)
+	return b.String()
+}
+
+// buildCDCConsumer returns the handler applying the changes of the source
+// table with the entity's use case.
+func buildCDCConsumer(entity, importPath string, fields []Field, database string) string {
+	v := strings.ToLower(entity[:1]) + entity[1:]
+	var b strings.Builder
+	writeCDCHeader(&b, importPath, "")
+
+	fmt.Fprintf(&b, "// %sConsumer applies the changes of the source table with the %s use\n", v, entity)
+	b.WriteString("// case. The use case is called with the primary keys of the source rows;\n")
+	b.WriteString("// when it assigns IDs of its own, look records up by a column holding the\n")
+	b.WriteString("// source ID instead.\n")
+	fmt.Fprintf(&b, "type %sConsumer struct {\n\tuc usecase.%sUseCase\n}\n\n", v, entity)
+	fmt.Fprintf(&b, "// New%sConsumer returns the handler of the %s change events.\n", entity, entity)
+	fmt.Fprintf(&b, "func New%sConsumer(uc usecase.%sUseCase) Handler {\n\treturn &%sConsumer{uc: uc}\n}\n\n", entity, entity, v)
+
+	b.WriteString("// Apply creates or updates the record of created, read and updated rows, so\n")
+	b.WriteString("// redelivered events are applied once, and deletes deleted ones. Truncates\n")
+	b.WriteString("// are ignored.\n")
+	fmt.Fprintf(&b, "func (c *%sConsumer) Apply(ctx context.Context, change Change) error {\n", v)
+	b.WriteString("\tid := int(change.Key.Int64(\"id\"))\n")
+	b.WriteString("\tswitch change.Op {\n")
+	b.WriteString("\tcase OpCreate, OpRead, OpUpdate:\n")
+	fmt.Fprintf(&b, "\t\te, err := %sFromRow(change.After)\n", v)
+	b.WriteString("\t\tif err != nil {\n\t\t\treturn err\n\t\t}\n")
+	fmt.Fprintf(&b, "\t\tif _, err := c.uc.Get%s(id); err == nil {\n", entity)
+	fmt.Fprintf(&b, "\t\t\treturn c.uc.Update%s(id, usecase.Update%sInput{\n", entity, entity)
+	writeCDCInputFields(&b, entity, "Update"+entity+"Input", fields, "\t\t\t\t")
+	b.WriteString("\t\t\t})\n")
+	b.WriteString("\t\t}\n")
+	fmt.Fprintf(&b, "\t\t_, err = c.uc.Create%s(usecase.Create%sInput{\n", entity, entity)
+	writeCDCInputFields(&b, entity, "Create"+entity+"Input", fields, "\t\t\t")
+	b.WriteString("\t\t})\n")
+	b.WriteString("\t\treturn err\n")
+	b.WriteString("\tcase OpDelete:\n")
+	fmt.Fprintf(&b, "\t\treturn c.uc.Delete%s(id)\n", entity)
+	b.WriteString("\t}\n")
+	b.WriteString("\treturn nil\n")
+	b.WriteString("}\n\n")
+
+	writeCDCFromRow(&b, entity, fields, database, false)
+	return b.String()
+}
+
+// writeCDCHeader writes the package clause and imports of a handler; the
+// unused ones are dropped by syncImports.
+func writeCDCHeader(b *strings.Builder, importPath, external string) {
+	b.WriteString("package cdc\n\n")
+	b.WriteString("import (\n\t\"context\"\n\t\"time\"\n\n")
+	fmt.Fprintf(b, "\t\"%s/internal/domain\"\n", importPath)
+	if external == "" {
+		fmt.Fprintf(b, "\t\"%s/internal/usecase\"\n", importPath)
+	}
+	b.WriteString("\n\t\"gorm.io/datatypes\"\n")
+	if external != "" {
+		b.WriteString(external)
+	} else {
+		b.WriteString("\t\"gorm.io/gorm\"\n")
+	}
+	b.WriteString(")\n\n")
+}
+
+// writeCDCFromRow writes <entity>FromRow, mapping a row of the source table
+// to the entity. system selects whether the ID, timestamps and other system
+// fields are mapped too.
+func writeCDCFromRow(b *strings.Builder, entity string, fields []Field, database string, system bool) {
+	v := strings.ToLower(entity[:1]) + entity[1:]
+	var literal, statements strings.Builder
+	for _, field := range fields {
+		if !system && isSystemField(field.Name) {
+			continue
+		}
+		writeCDCField(&literal, &statements, field, "e."+field.Name, "", database, "\t\t")
+	}
+
+	fmt.Fprintf(b, "// %sFromRow maps a row of the source table to a domain.%s. TODO: match\n", v, entity)
+	b.WriteString("// the column names of the source table.\n")
+	fmt.Fprintf(b, "func %sFromRow(row Row) (domain.%s, error) {\n", v, entity)
+	fmt.Fprintf(b, "\te := domain.%s{\n%s\t}\n", entity, literal.String())
+	b.WriteString(statements.String())
+	b.WriteString("\treturn e, nil\n")
+	b.WriteString("}\n")
+}
+
+// writeCDCField writes the element of field in the entity literal, or, for
+// types the Row accessors do not cover, a statement decoding it into target.
+// prefix is the column prefix of embedded structs.
+func writeCDCField(literal, statements *strings.Builder, field Field, target, prefix, database, indent string) {
+	column := prefix + fieldColumns([]Field{{Name: field.Name, Type: field.Type, Tag: field.Tag}}, database)[0].Name
+	if field.Nested != nil {
+		fmt.Fprintf(literal, "%s%s: domain.%s{\n", indent, field.Name, field.Type)
+		nestedPrefix := prefix + gormTagOptions(field.Tag)["embeddedprefix"]
+		for _, sub := range field.Nested {
+			writeCDCField(literal, statements, sub, target+"."+sub.Name, nestedPrefix, database, indent+"\t")
+		}
+		fmt.Fprintf(literal, "%s},\n", indent)
+		return
+	}
+	if expr, ok := cdcRowExpr(field.Type, column); ok {
+		fmt.Fprintf(literal, "%s%s: %s,\n", indent, field.Name, expr)
+		return
+	}
+	if elem, ok := strings.CutPrefix(field.Type, "*"); ok {
+		if expr, ok := cdcRowExpr(elem, column); ok {
+			fmt.Fprintf(statements, "\tif !row.IsNull(%q) {\n\t\tv := %s\n\t\t%s = &v\n\t}\n", column, expr, target)
+			return
+		}
+	}
+	fmt.Fprintf(statements, "\tif err := row.Decode(%q, &%s); err != nil {\n\t\treturn e, err\n\t}\n", column, target)
+}
+
+// cdcRowExpr returns the Row accessor reading a column of a Go type, or false
+// for types Row.Decode reads.
+func cdcRowExpr(goType, column string) (string, bool) {
+	switch goType {
+	case "string":
+		return fmt.Sprintf("row.String(%q)", column), true
+	case "int64":
+		return fmt.Sprintf("row.Int64(%q)", column), true
+	case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32", "uint64":
+		return fmt.Sprintf("%s(row.Int64(%q))", goType, column), true
+	case "float64":
+		return fmt.Sprintf("row.Float64(%q)", column), true
+	case "float32":
+		return fmt.Sprintf("float32(row.Float64(%q))", column), true
+	case "bool":
+		return fmt.Sprintf("row.Bool(%q)", column), true
+	case "time.Time":
+		return fmt.Sprintf("row.Time(%q)", column), true
+	case "gorm.DeletedAt":
+		return fmt.Sprintf("gorm.DeletedAt{Time: row.Time(%q), Valid: !row.IsNull(%q)}", column, column), true
+	}
+	return "", false
+}
+
+// writeCDCInputFields writes the fields of a use case input DTO set from the
+// entity e. DTO fields are matched to entity fields by name, and to the
+// fields of nested structs by <Field><Sub> for flat DTOs; pointer fields take
+// the address of the entity's value.
+func writeCDCInputFields(b *strings.Builder, entity, input string, fields []Field, indent string) {
+	values := make(map[string]Field)
+	exprs := make(map[string]string)
+	for _, field := range fields {
+		values[field.Name], exprs[field.Name] = field, "e."+field.Name
+		for _, sub := range field.Nested {
+			values[field.Name+sub.Name], exprs[field.Name+sub.Name] = sub, "e."+field.Name+"."+sub.Name
+		}
+	}
+
+	var dtoFields []Field
+	if file := findTypeFile(filepath.Join(DirInternal, DirUseCase), input); file != "" {
+		if src, err := parseGoSource(file); err == nil {
+			dtoFields = readEntityFields(src.file, input)
+		}
+	}
+	for _, dto := range dtoFields {
+		expr, ok := exprs[dto.Name]
+		if !ok {
+			fmt.Fprintf(b, "%s// TODO: %s: no %s field of the same name\n", indent, dto.Name, entity)
+			continue
+		}
+		if strings.HasPrefix(dto.Type, "*") && !strings.HasPrefix(values[dto.Name].Type, "*") {
+			expr = "&" + expr
+		}
+		fmt.Fprintf(b, "%s%s: %s,\n", indent, dto.Name, expr)
+	}
+}
+
+// buildCDCMain returns cmd/cdc/main.go without handlers.
+func buildCDCMain(importPath, database, sqliteDriver string) string {
+	std, external, openDatabase, _ := databaseOpener(database, sqliteDriver)
+	imports := importLines(append([]string{"context", "flag", "fmt", "os", "os/signal", "strings", "syscall"}, std...), external)
+	name := getCurrentProjectName()
+
+	return fmt.Sprintf(`package main
+
+import (
+%s
+	"%s/internal/cdc"
+	"%s/pkg/config"
+)
+
+// main consumes the Debezium change events of the topics in handlers and
+// applies them until it is interrupted.
+func main() {
+	brokers := flag.String("brokers", envOr("KAFKA_BROKERS", "localhost:9092"), "comma-separated Kafka brokers")
+	group := flag.String("group", envOr("CDC_GROUP_ID", %q), "Kafka consumer group")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg := config.Load()
+	db, err := openDatabase(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: connecting to database: %%v\n", err)
+		os.Exit(1)
+	}
+
+	consumer := cdc.Config{Brokers: strings.Split(*brokers, ","), GroupID: *group}
+	if err := cdc.Consume(ctx, consumer, handlers(db)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %%v\n", err)
+		os.Exit(1)
+	}
+}
+
+// handlers returns the handler of each topic.
+func handlers(db *gorm.DB) map[string]cdc.Handler {
+	return map[string]cdc.Handler{
+		%s
+	}
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+%s`, imports, importPath, importPath, name+"-cdc", cdcHandlersMarker, openDatabase)
+}
+
+func init() {
+	cdcCmd.Flags().String("topic", "", "Kafka topic of the source table's change events (default: dbserver1.public.<table>)")
+	cdcCmd.Flags().String("target", CDCTargetReadModel, "Where changes are applied ("+strings.Join(ValidCDCTargets, ", ")+")")
+	cdcCmd.Flags().Bool("dry-run", false, "Show the files that would be generated without writing them")
+	cdcCmd.Flags().Bool("force", false, "Overwrite an existing handler")
+}
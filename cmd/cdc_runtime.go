@@ -0,0 +1,462 @@
+package cmd
+
+// cdcRuntimeSource is internal/cdc/debezium.go, the runtime of the change
+// data capture consumers goca cdc generates.
+const cdcRuntimeSource = `// Code generated by goca cdc. DO NOT EDIT.
+
+// Package cdc consumes Debezium change events from Kafka and applies them
+// with a handler per topic.
+package cdc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Op is the operation of a change event.
+type Op string
+
+// Debezium operations.
+const (
+	OpCreate   Op = "c"
+	OpRead     Op = "r" // a row read by a snapshot
+	OpUpdate   Op = "u"
+	OpDelete   Op = "d"
+	OpTruncate Op = "t"
+)
+
+// Change is a Debezium change event.
+type Change struct {
+	Op Op
+	// Key holds the primary key columns of the row.
+	Key Row
+	// Before is the row before an update or delete, After the row after a
+	// create, read or update. Either is empty when the event has none.
+	Before Row
+	After  Row
+	// Tombstone is set for the empty message Debezium sends after a delete so
+	// Kafka compaction drops the key. Its Op is OpDelete; compaction may have
+	// removed the delete before it, so it must be applied like one.
+	Tombstone bool
+	Source    Source
+	// Timestamp is when the connector processed the event.
+	Timestamp time.Time
+}
+
+// Source describes where a change event comes from.
+type Source struct {
+	Connector string ` + "`json:\"connector\"`" + `
+	Database  string ` + "`json:\"db\"`" + `
+	Schema    string ` + "`json:\"schema\"`" + `
+	Table     string ` + "`json:\"table\"`" + `
+	// Snapshot is "true", "last" or "incremental" for rows read by a
+	// snapshot.
+	Snapshot string ` + "`json:\"snapshot\"`" + `
+}
+
+// Handler applies the change events of a topic. Events are delivered at
+// least once, so Apply must be idempotent.
+type Handler interface {
+	Apply(ctx context.Context, change Change) error
+}
+
+// HandlerFunc adapts a function to Handler.
+type HandlerFunc func(ctx context.Context, change Change) error
+
+// Apply calls f.
+func (f HandlerFunc) Apply(ctx context.Context, change Change) error { return f(ctx, change) }
+
+// Config configures Consume.
+type Config struct {
+	Brokers []string
+	GroupID string
+}
+
+// Consume reads the topics of handlers as the consumer group cfg.GroupID
+// and applies each change with the handler of its topic. The offset of a
+// message is committed once its change is applied; a failing handler is
+// retried with backoff until it succeeds or ctx is done. Messages that are
+// not Debezium events are logged and skipped.
+func Consume(ctx context.Context, cfg Config, handlers map[string]Handler) error {
+	topics := make([]string, 0, len(handlers))
+	for topic := range handlers {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	if len(topics) == 0 {
+		return fmt.Errorf("no change data capture handlers are registered")
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     cfg.Brokers,
+		GroupID:     cfg.GroupID,
+		GroupTopics: topics,
+		StartOffset: kafka.FirstOffset,
+		MaxBytes:    10e6,
+	})
+	defer reader.Close()
+	log.Printf("consuming %s as %s", strings.Join(topics, ", "), cfg.GroupID)
+
+	for {
+		msg, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("reading messages: %w", err)
+		}
+		change, err := Parse(msg.Key, msg.Value)
+		if err != nil {
+			log.Printf("%s[%d]@%d: skipping message: %v", msg.Topic, msg.Partition, msg.Offset, err)
+		} else if err := apply(ctx, handlers[msg.Topic], change, msg); err != nil {
+			return nil // ctx is done
+		}
+		if err := reader.CommitMessages(ctx, msg); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("committing %s[%d]@%d: %w", msg.Topic, msg.Partition, msg.Offset, err)
+		}
+	}
+}
+
+// apply runs handler until it succeeds. It only fails when ctx is done.
+func apply(ctx context.Context, handler Handler, change Change, msg kafka.Message) error {
+	for attempt := 1; ; attempt++ {
+		err := handler.Apply(ctx, change)
+		if err == nil {
+			return nil
+		}
+		wait := min(time.Duration(1<<min(attempt-1, 6))*time.Second, time.Minute)
+		log.Printf("%s[%d]@%d: %s change failed (attempt %d), retrying in %s: %v", msg.Topic, msg.Partition, msg.Offset, change.Op, attempt, wait, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// envelope is a message of the JSON converter: the payload, with its schema
+// when schemas.enable is on.
+type envelope struct {
+	Schema  *schema         ` + "`json:\"schema\"`" + `
+	Payload json.RawMessage ` + "`json:\"payload\"`" + `
+}
+
+// schema is a Kafka Connect schema.
+type schema struct {
+	Type       string            ` + "`json:\"type\"`" + `
+	Name       string            ` + "`json:\"name\"`" + `
+	Field      string            ` + "`json:\"field\"`" + `
+	Fields     []schema          ` + "`json:\"fields\"`" + `
+	Parameters map[string]string ` + "`json:\"parameters\"`" + `
+}
+
+// field returns the schema of the named field, or nil.
+func (s *schema) field(name string) *schema {
+	if s == nil {
+		return nil
+	}
+	for i := range s.Fields {
+		if s.Fields[i].Field == name {
+			return &s.Fields[i]
+		}
+	}
+	return nil
+}
+
+// payload is the value of a change event.
+type payload struct {
+	Op     Op                         ` + "`json:\"op\"`" + `
+	Before map[string]json.RawMessage ` + "`json:\"before\"`" + `
+	After  map[string]json.RawMessage ` + "`json:\"after\"`" + `
+	Source Source                     ` + "`json:\"source\"`" + `
+	TsMs   int64                      ` + "`json:\"ts_ms\"`" + `
+}
+
+// Parse decodes the key and value of a Debezium message, with or without
+// schemas. It also reads events flattened by the ExtractNewRecordState
+// transform, whose value is the row itself with optional __op and __deleted
+// fields. An empty value is a tombstone.
+func Parse(key, value []byte) (Change, error) {
+	var change Change
+	if len(bytes.TrimSpace(key)) > 0 {
+		columns, s, err := unwrap(key)
+		if err != nil {
+			return Change{}, fmt.Errorf("decoding key: %w", err)
+		}
+		change.Key = newRow(columns, s)
+	}
+	if len(bytes.TrimSpace(value)) == 0 || string(bytes.TrimSpace(value)) == "null" {
+		change.Op = OpDelete
+		change.Tombstone = true
+		return change, nil
+	}
+
+	raw, s, err := unwrap(value)
+	if err != nil {
+		return Change{}, fmt.Errorf("decoding value: %w", err)
+	}
+	var p payload
+	if _, ok := raw["op"]; ok {
+		encoded, _ := json.Marshal(raw)
+		if err := json.Unmarshal(encoded, &p); err != nil {
+			return Change{}, fmt.Errorf("decoding value: %w", err)
+		}
+		change.Op = p.Op
+		change.Before = newRow(p.Before, s.field("before"))
+		change.After = newRow(p.After, s.field("after"))
+		change.Source = p.Source
+		if p.TsMs > 0 {
+			change.Timestamp = time.UnixMilli(p.TsMs)
+		}
+	} else {
+		// A flattened event.
+		row := newRow(raw, s)
+		change.Op = Op(row.String("__op"))
+		if change.Op == "" {
+			change.Op = OpUpdate
+		}
+		if row.Bool("__deleted") {
+			change.Op = OpDelete
+			change.Before = row
+		} else {
+			change.After = row
+		}
+	}
+	if change.Key.Len() == 0 {
+		// Tables without a primary key have no message key.
+		if change.After.Len() > 0 {
+			change.Key = change.After
+		} else {
+			change.Key = change.Before
+		}
+	}
+	return change, nil
+}
+
+// unwrap returns the columns of a JSON converter message and their schema,
+// nil without one.
+func unwrap(data []byte) (map[string]json.RawMessage, *schema, error) {
+	var columns map[string]json.RawMessage
+	if err := json.Unmarshal(data, &columns); err != nil {
+		return nil, nil, err
+	}
+	body, hasPayload := columns["payload"]
+	if _, hasSchema := columns["schema"]; !hasSchema || !hasPayload || len(columns) != 2 {
+		return columns, nil, nil
+	}
+	var e envelope
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, nil, err
+	}
+	var inner map[string]json.RawMessage
+	if err := json.Unmarshal(body, &inner); err != nil {
+		return nil, nil, err
+	}
+	return inner, e.Schema, nil
+}
+
+// Row is the state of a table row in a change event. Columns missing from
+// the event read as zero values and unknown columns are ignored, so
+// consumers keep working while columns are added to or dropped from the
+// source table. The accessors convert between the representations Debezium
+// and its converters use, e.g. timestamps as epoch numbers or strings and
+// decimals as bytes or strings.
+type Row struct {
+	columns map[string]json.RawMessage
+	schemas map[string]*schema
+}
+
+func newRow(columns map[string]json.RawMessage, s *schema) Row {
+	row := Row{columns: columns, schemas: map[string]*schema{}}
+	if s != nil {
+		for i := range s.Fields {
+			row.schemas[s.Fields[i].Field] = &s.Fields[i]
+		}
+	}
+	return row
+}
+
+// Len returns the number of columns of the row.
+func (r Row) Len() int { return len(r.columns) }
+
+// Has reports whether the row has column.
+func (r Row) Has(column string) bool {
+	_, ok := r.columns[column]
+	return ok
+}
+
+// IsNull reports whether column is missing or null.
+func (r Row) IsNull(column string) bool {
+	raw, ok := r.columns[column]
+	return !ok || string(raw) == "null"
+}
+
+// String returns a text column; other values are returned as their JSON
+// text.
+func (r Row) String(column string) string {
+	raw := r.columns[column]
+	if r.IsNull(column) {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// Int64 returns an integer column, also read from numeric strings, decimals
+// and booleans.
+func (r Row) Int64(column string) int64 {
+	if r.IsNull(column) {
+		return 0
+	}
+	raw := r.columns[column]
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		if i, err := n.Int64(); err == nil {
+			return i
+		}
+	}
+	if r.Bool(column) {
+		return 1
+	}
+	return int64(r.Float64(column))
+}
+
+// Float64 returns a numeric column, also read from numeric strings and from
+// the bytes or struct of Kafka Connect and Debezium decimals.
+func (r Row) Float64(column string) float64 {
+	if r.IsNull(column) {
+		return 0
+	}
+	raw := r.columns[column]
+	var f float64
+	if err := json.Unmarshal(raw, &f); err == nil {
+		return f
+	}
+	if s := r.schemas[column]; s != nil && s.Name == "org.apache.kafka.connect.data.Decimal" {
+		scale, _ := strconv.Atoi(s.Parameters["scale"])
+		return decodeDecimal(r.String(column), scale)
+	}
+	var variable struct {
+		Scale int    ` + "`json:\"scale\"`" + `
+		Value string ` + "`json:\"value\"`" + `
+	}
+	if err := json.Unmarshal(raw, &variable); err == nil && variable.Value != "" {
+		return decodeDecimal(variable.Value, variable.Scale)
+	}
+	f, _ = strconv.ParseFloat(strings.TrimSpace(r.String(column)), 64)
+	return f
+}
+
+// decodeDecimal decodes the base64 big-endian unscaled value of a decimal.
+func decodeDecimal(value string, scale int) float64 {
+	b, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(b) == 0 {
+		return 0
+	}
+	unscaled := new(big.Int).SetBytes(b)
+	if b[0]&0x80 != 0 {
+		unscaled.Sub(unscaled, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+	}
+	f, _ := new(big.Float).SetInt(unscaled).Float64()
+	return f / math.Pow10(scale)
+}
+
+// Bool returns a boolean column, also read from numbers (MySQL TINYINT(1))
+// and strings.
+func (r Row) Bool(column string) bool {
+	switch strings.ToLower(r.String(column)) {
+	case "true", "1", "t", "yes", "y":
+		return true
+	}
+	return false
+}
+
+// Time returns a temporal column: RFC 3339 and SQL strings, or numbers in
+// the unit their Debezium schema names. Without a schema, numbers are read
+// as days, milliseconds, microseconds or nanoseconds since the epoch by
+// their magnitude.
+func (r Row) Time(column string) time.Time {
+	if r.IsNull(column) {
+		return time.Time{}
+	}
+	var n int64
+	if err := json.Unmarshal(r.columns[column], &n); err != nil {
+		s := r.String(column)
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999", "2006-01-02"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t.UTC()
+			}
+		}
+		return time.Time{}
+	}
+
+	name := ""
+	if s := r.schemas[column]; s != nil {
+		name = s.Name
+	}
+	switch {
+	case name == "io.debezium.time.Date" || name == "org.apache.kafka.connect.data.Date":
+		return time.Unix(n*86400, 0).UTC()
+	case name == "io.debezium.time.Timestamp" || name == "org.apache.kafka.connect.data.Timestamp":
+		return time.UnixMilli(n).UTC()
+	case name == "io.debezium.time.MicroTimestamp":
+		return time.UnixMicro(n).UTC()
+	case name == "io.debezium.time.NanoTimestamp":
+		return time.Unix(0, n).UTC()
+	}
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs < 1e7:
+		return time.Unix(n*86400, 0).UTC()
+	case abs < 1e14:
+		return time.UnixMilli(n).UTC()
+	case abs < 1e17:
+		return time.UnixMicro(n).UTC()
+	}
+	return time.Unix(0, n).UTC()
+}
+
+// Decode decodes a column into v with encoding/json. JSON columns, which
+// Debezium sends as strings holding the document, are decoded from the
+// document.
+func (r Row) Decode(column string, v interface{}) error {
+	if r.IsNull(column) {
+		return nil
+	}
+	raw := r.columns[column]
+	var document string
+	if err := json.Unmarshal(raw, &document); err == nil {
+		trimmed := strings.TrimSpace(document)
+		if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			if err := json.Unmarshal([]byte(trimmed), v); err == nil {
+				return nil
+			}
+		}
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("column %s: %w", column, err)
+	}
+	return nil
+}
+`
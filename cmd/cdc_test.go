@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCDCRowExpr(t *testing.T) {
+	for goType, expected := range map[string]string{
+		"string":         `row.String("c")`,
+		"int64":          `row.Int64("c")`,
+		"uint":           `uint(row.Int64("c"))`,
+		"float32":        `float32(row.Float64("c"))`,
+		"bool":           `row.Bool("c")`,
+		"time.Time":      `row.Time("c")`,
+		"gorm.DeletedAt": `gorm.DeletedAt{Time: row.Time("c"), Valid: !row.IsNull("c")}`,
+	} {
+		expr, ok := cdcRowExpr(goType, "c")
+		assert.True(t, ok, goType)
+		assert.Equal(t, expected, expr)
+	}
+	for _, goType := range []string{"[]string", "datatypes.JSON", "Status", "*string"} {
+		_, ok := cdcRowExpr(goType, "c")
+		assert.False(t, ok, goType)
+	}
+}
+
+func TestGenerateCDCConsumer(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	writeTestFile(t, ".", "go.mod", "module example.com/shop\n\ngo 1.22\n")
+	writeTestFile(t, ".", "internal/domain/order.go", `package domain
+
+import "time"
+
+type Order struct {
+	ID       uint      `+"`json:\"id\" gorm:\"primaryKey\"`"+`
+	Customer string    `+"`json:\"customer\"`"+`
+	PlacedAt time.Time `+"`json:\"placed_at\" gorm:\"column:ordered_at\"`"+`
+	Notes    *string   `+"`json:\"notes\"`"+`
+	Tags     []string  `+"`json:\"tags\" gorm:\"serializer:json\"`"+`
+}
+`)
+	writeTestFile(t, ".", "internal/usecase/order_usecase.go", `package usecase
+
+import "example.com/shop/internal/domain"
+
+type OrderUseCase interface {
+	CreateOrder(input CreateOrderInput) (CreateOrderOutput, error)
+	GetOrder(id int) (*domain.Order, error)
+	UpdateOrder(id int, input UpdateOrderInput) error
+	DeleteOrder(id int) error
+}
+
+type CreateOrderInput struct {
+	Customer string
+	Notes    *string
+	Coupon   string
+}
+
+type CreateOrderOutput struct{}
+
+type UpdateOrderInput struct {
+	Customer *string
+	Notes    *string
+}
+`)
+	sm := NewSafetyManager(false, false, false)
+	require.NoError(t, generateCDCConsumer("Order", "dbserver1.public.orders", CDCTargetReadModel, DBPostgres, sm))
+
+	projection := readParsedGo(t, filepath.Join(cdcDir, "order.go"))
+	assert.Contains(t, projection, "func NewOrderProjection(db *gorm.DB) Handler {")
+	assert.Contains(t, projection, `ID:       uint(row.Int64("id")),`)
+	assert.Contains(t, projection, `PlacedAt: row.Time("ordered_at"),`)
+	assert.Contains(t, projection, "if !row.IsNull(\"notes\") {\n\t\tv := row.String(\"notes\")\n\t\te.Notes = &v\n\t}")
+	assert.Contains(t, projection, `if err := row.Decode("tags", &e.Tags); err != nil {`)
+	assert.Contains(t, projection, `return db.Delete(&domain.Order{}, change.Key.Int64("id")).Error`)
+	assert.Contains(t, projection, `"example.com/shop/internal/domain"`)
+	assert.NotContains(t, projection, `"time"`)
+
+	runtime := readParsedGo(t, filepath.Join(cdcDir, "debezium.go"))
+	assert.Contains(t, runtime, "func Parse(key, value []byte) (Change, error) {")
+	main := readParsedGo(t, filepath.Join("cmd", "cdc", "main.go"))
+	assert.Contains(t, main, `"dbserver1.public.orders": cdc.NewOrderProjection(db),`)
+	assert.NotContains(t, main, "di.NewContainer")
+
+	// The handler is the developer's once written.
+	assert.Error(t, generateCDCConsumer("Order", "dbserver1.public.orders", CDCTargetReadModel, DBPostgres, sm))
+
+	force := NewSafetyManager(false, true, false)
+	require.NoError(t, generateCDCConsumer("Order", "erp.public.orders", CDCTargetUseCase, DBPostgres, force))
+	consumer := readParsedGo(t, filepath.Join(cdcDir, "order.go"))
+	assert.Contains(t, consumer, "func NewOrderConsumer(uc usecase.OrderUseCase) Handler {")
+	assert.NotContains(t, consumer, `row.Int64("id")),`)
+	assert.Contains(t, consumer, "return c.uc.UpdateOrder(id, usecase.UpdateOrderInput{\n\t\t\t\tCustomer: &e.Customer,\n\t\t\t\tNotes:    e.Notes,")
+	assert.Contains(t, consumer, "Customer: e.Customer,\n\t\t\tNotes:    e.Notes,\n\t\t\t// TODO: Coupon: no Order field of the same name")
+
+	main = readParsedGo(t, filepath.Join("cmd", "cdc", "main.go"))
+	assert.Contains(t, main, "container := di.NewContainer(db)")
+	assert.Contains(t, main, `"erp.public.orders": cdc.NewOrderConsumer(container.OrderUseCase()),`)
+	assert.Contains(t, main, "\"example.com/shop/internal/cdc\"\n\t\"example.com/shop/internal/di\"")
+	assert.NotContains(t, main, "NewOrderProjection")
+}
@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// projectCodeStyle returns generation.style from the current project's
+// configuration.
+func projectCodeStyle() StyleConfig {
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	return ci.GetGenerationConfig().Style
+}
+
+// styleEnabled reports whether any option that rewrites generated code is set.
+func styleEnabled(style StyleConfig) bool {
+	return style.ReceiverNames != "" ||
+		style.CommentDensity == CommentDensityMinimal ||
+		style.ErrorStrings != ""
+}
+
+// applyProjectCodeStyle rewrites generated Go source to follow the project's
+// generation.style options. Files that do not parse are returned unchanged so
+// the caller's formatting step can report them.
+func applyProjectCodeStyle(path, content string) string {
+	if !strings.HasSuffix(path, ExtGo) {
+		return content
+	}
+	defer profileStep("code style")()
+	style := projectCodeStyle()
+	if !styleEnabled(style) {
+		return content
+	}
+	styled, err := applyCodeStyle([]byte(content), style)
+	if err != nil {
+		return content
+	}
+	return string(styled)
+}
+
+// applyCodeStyle renames receivers, drops comments inside function bodies and
+// recases error strings in src according to style.
+func applyCodeStyle(src []byte, style StyleConfig) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	if style.ReceiverNames != "" {
+		renameReceivers(file, style.ReceiverNames)
+	}
+	if style.CommentDensity == CommentDensityMinimal {
+		stripBodyComments(file)
+	}
+	if style.ErrorStrings != "" {
+		recaseErrorStrings(file, style.ErrorStrings)
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// renameReceivers gives every method receiver the name receiverName derives
+// from its type. Methods of a type keep their names when the new name would
+// collide with another identifier in any of them, so receivers stay
+// consistent per type.
+func renameReceivers(file *ast.File, style string) {
+	methods := make(map[string][]*ast.FuncDecl)
+	var order []string
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || len(fn.Recv.List) != 1 || len(fn.Recv.List[0].Names) != 1 {
+			continue
+		}
+		typeName := receiverTypeName(fn.Recv.List[0].Type)
+		if typeName == "" {
+			continue
+		}
+		if _, seen := methods[typeName]; !seen {
+			order = append(order, typeName)
+		}
+		methods[typeName] = append(methods[typeName], fn)
+	}
+
+	for _, typeName := range order {
+		name := receiverName(typeName, style)
+		if name == "" || token.IsKeyword(name) || types.Universe.Lookup(name) != nil {
+			continue
+		}
+		fns := methods[typeName]
+		if !receiverNameFree(fns, name) {
+			continue
+		}
+		for _, fn := range fns {
+			recv := fn.Recv.List[0].Names[0]
+			if recv.Name == "_" || recv.Name == name {
+				continue
+			}
+			obj := recv.Obj
+			ast.Inspect(fn, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok && id.Obj != nil && id.Obj == obj {
+					id.Name = name
+				}
+				return true
+			})
+			recv.Name = name
+		}
+	}
+}
+
+// receiverNameFree reports whether name is unused in fns apart from their
+// receivers.
+func receiverNameFree(fns []*ast.FuncDecl, name string) bool {
+	for _, fn := range fns {
+		obj := fn.Recv.List[0].Names[0].Obj
+		free := true
+		ast.Inspect(fn, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && id.Name == name && (id.Obj == nil || id.Obj != obj) {
+				free = false
+			}
+			return free
+		})
+		if !free {
+			return false
+		}
+	}
+	return true
+}
+
+// receiverTypeName returns the type name of a receiver expression such as
+// *userService or Box[T].
+func receiverTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// receiverName derives a receiver name from a type name: its first letter for
+// single, or the initial of each camel-case word for abbreviated, so
+// postgresUserRepository becomes p or pur.
+func receiverName(typeName, style string) string {
+	words := camelCaseWords(typeName)
+	if len(words) == 0 {
+		return ""
+	}
+	if style != ReceiverNamesAbbreviated {
+		words = words[:1]
+	}
+	var name strings.Builder
+	for _, word := range words {
+		r, _ := utf8.DecodeRuneInString(word)
+		name.WriteRune(unicode.ToLower(r))
+	}
+	return name.String()
+}
+
+// camelCaseWords splits an identifier into words, keeping acronyms such as
+// HTTP in HTTPHandler together.
+func camelCaseWords(name string) []string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		switch {
+		case cur == '_':
+			words = append(words, string(runes[start:i]))
+			start = i + 1
+		case unicode.IsUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower)):
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	words = append(words, string(runes[start:]))
+
+	nonEmpty := words[:0]
+	for _, word := range words {
+		if word != "" && word != "_" {
+			nonEmpty = append(nonEmpty, word)
+		}
+	}
+	return nonEmpty
+}
+
+// stripBodyComments removes comments inside function bodies, keeping
+// directives such as //nolint and //#nosec.
+func stripBodyComments(file *ast.File) {
+	var bodies []*ast.BlockStmt
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch fn := n.(type) {
+		case *ast.FuncDecl:
+			if fn.Body != nil {
+				bodies = append(bodies, fn.Body)
+			}
+			return false
+		case *ast.FuncLit:
+			bodies = append(bodies, fn.Body)
+			return false
+		}
+		return true
+	})
+
+	kept := file.Comments[:0]
+	for _, group := range file.Comments {
+		inBody := false
+		for _, body := range bodies {
+			if group.Pos() > body.Lbrace && group.End() < body.Rbrace {
+				inBody = true
+				break
+			}
+		}
+		if !inBody || isDirectiveComment(group) {
+			kept = append(kept, group)
+		}
+	}
+	file.Comments = kept
+}
+
+// isDirectiveComment reports whether a comment group is a tool directive
+// rather than prose.
+func isDirectiveComment(group *ast.CommentGroup) bool {
+	for _, c := range group.List {
+		text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+		for _, prefix := range []string{"go:", "nolint", "#nosec", "lint:"} {
+			if strings.HasPrefix(text, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// recaseErrorStrings recases the message passed to errors.New and fmt.Errorf.
+func recaseErrorStrings(file *ast.File, style string) {
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		pkg, ok := sel.X.(*ast.Ident)
+		if !ok || !(pkg.Name == "errors" && sel.Sel.Name == "New" || pkg.Name == "fmt" && sel.Sel.Name == "Errorf") {
+			return true
+		}
+		if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING && len(lit.Value) > 1 {
+			lit.Value = lit.Value[:1] + styleErrorString(lit.Value[1:], style)
+		}
+		return true
+	})
+}
+
+// styleErrorString recases the first letter of an error message. Lowercasing
+// leaves acronyms such as ID alone.
+func styleErrorString(msg, style string) string {
+	first, size := utf8.DecodeRuneInString(msg)
+	if size == 0 || !unicode.IsLetter(first) {
+		return msg
+	}
+	switch style {
+	case ErrorStringsCapitalized:
+		return string(unicode.ToUpper(first)) + msg[size:]
+	case ErrorStringsLowercase:
+		next, _ := utf8.DecodeRuneInString(msg[size:])
+		if unicode.IsUpper(next) {
+			return msg
+		}
+		return string(unicode.ToLower(first)) + msg[size:]
+	}
+	return msg
+}
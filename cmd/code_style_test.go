@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const styleSource = `package usecase
+
+import "errors"
+
+var errMissing = errors.New("Name is required")
+
+type userService struct{ repo Repository }
+
+// GetUser loads a user.
+func (s *userService) GetUser(id int) error {
+	// Look the user up.
+	if _, err := s.repo.Find(id); err != nil { //nolint:wrapcheck
+		return fmt.Errorf("ID %d: %w", id, err)
+	}
+	return errMissing
+}
+
+func (u *userService) Delete(us int) error {
+	return u.repo.Delete(us)
+}
+`
+
+func TestReceiverName(t *testing.T) {
+	assert.Equal(t, "p", receiverName("postgresUserRepository", ReceiverNamesSingle))
+	assert.Equal(t, "pur", receiverName("postgresUserRepository", ReceiverNamesAbbreviated))
+	assert.Equal(t, "hh", receiverName("HTTPHandler", ReceiverNamesAbbreviated))
+	assert.Equal(t, "ui", receiverName("user_info", ReceiverNamesAbbreviated))
+}
+
+func TestStyleErrorString(t *testing.T) {
+	assert.Equal(t, "Name is required", styleErrorString("name is required", ErrorStringsCapitalized))
+	assert.Equal(t, "name is required", styleErrorString("Name is required", ErrorStringsLowercase))
+	assert.Equal(t, "ID is required", styleErrorString("ID is required", ErrorStringsLowercase))
+	assert.Equal(t, "%s not found", styleErrorString("%s not found", ErrorStringsCapitalized))
+}
+
+func TestApplyCodeStyle(t *testing.T) {
+	t.Run("receivers are consistent per type", func(t *testing.T) {
+		out, err := applyCodeStyle([]byte(styleSource), StyleConfig{ReceiverNames: ReceiverNamesSingle})
+		require.NoError(t, err)
+		assert.Contains(t, string(out), "func (u *userService) GetUser(id int) error {")
+		assert.Contains(t, string(out), "if _, err := u.repo.Find(id)")
+	})
+
+	t.Run("colliding names are left alone", func(t *testing.T) {
+		out, err := applyCodeStyle([]byte(styleSource), StyleConfig{ReceiverNames: ReceiverNamesAbbreviated})
+		require.NoError(t, err)
+		assert.Contains(t, string(out), "func (s *userService) GetUser(")
+		assert.Contains(t, string(out), "func (u *userService) Delete(us int)")
+	})
+
+	t.Run("minimal comments keep docs and directives", func(t *testing.T) {
+		out, err := applyCodeStyle([]byte(styleSource), StyleConfig{CommentDensity: CommentDensityMinimal})
+		require.NoError(t, err)
+		assert.Contains(t, string(out), "// GetUser loads a user.")
+		assert.Contains(t, string(out), "//nolint:wrapcheck")
+		assert.NotContains(t, string(out), "Look the user up")
+	})
+
+	t.Run("error strings", func(t *testing.T) {
+		out, err := applyCodeStyle([]byte(styleSource), StyleConfig{ErrorStrings: ErrorStringsLowercase})
+		require.NoError(t, err)
+		assert.Contains(t, string(out), `errors.New("name is required")`)
+		assert.Contains(t, string(out), `fmt.Errorf("ID %d: %w", id, err)`)
+	})
+}
+
+func TestApplyProjectCodeStyle(t *testing.T) {
+	chdirTemp(t)
+	src := "package domain\n\nfunc (p *Product) Name() string { return \"\" }\n"
+	assert.Equal(t, src, applyProjectCodeStyle("product.go", src), "unchanged without style options")
+
+	require.NoError(t, os.WriteFile(".goca.yaml", []byte("project:\n  name: shop\n  module: example.com/shop\ngeneration:\n  style:\n    receiver_names: abbreviated\n"), 0o644))
+	assert.Contains(t, applyProjectCodeStyle("product.go", src), "func (p *Product) Name()")
+	src = "package domain\n\nfunc (x *OrderItem) Name() string { return \"\" }\n"
+	assert.Contains(t, applyProjectCodeStyle("order_item.go", src), "func (oi *OrderItem) Name()")
+	assert.Equal(t, src, applyProjectCodeStyle("README.md", src))
+}
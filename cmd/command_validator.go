@@ -130,7 +130,7 @@ func (v *CommandValidator) ValidateHandlerCommand(entity, handlerType string) er
 
 	// Validate handler type
 	if handlerType != "" {
-		validHandlers := []string{HandlerHTTP, HandlerGRPC, HandlerCLI, HandlerWorker}
+		validHandlers := ValidHandlers
 		found := false
 		for _, valid := range validHandlers {
 			if handlerType == valid {
@@ -67,7 +67,7 @@ func TestValidateRepositoryCommand(t *testing.T) {
 		{name: "valid mysql", entity: "Product", database: "mysql", wantErr: false},
 		{name: "valid sqlite", entity: "Order", database: "sqlite", wantErr: false},
 		{name: "empty database ok", entity: "User", database: "", wantErr: false},
-		{name: "invalid database", entity: "User", database: "oracle", wantErr: true},
+		{name: "invalid database", entity: "User", database: "cassandra", wantErr: true},
 		{name: "empty entity", entity: "", database: "postgres", wantErr: true},
 	}
 
@@ -162,7 +162,7 @@ func TestValidateFeatureCommand(t *testing.T) {
 		{name: "valid full", feature: "User", fields: "Name:string,Email:string", database: "postgres", handlers: "http", wantErr: false},
 		{name: "valid no db no handlers", feature: "Product", fields: "Name:string", database: "", handlers: "", wantErr: false},
 		{name: "empty feature name", feature: "", fields: "Name:string", database: "", handlers: "", wantErr: true},
-		{name: "invalid database", feature: "User", fields: "Name:string", database: "oracle", handlers: "", wantErr: true},
+		{name: "invalid database", feature: "User", fields: "Name:string", database: "cassandra", handlers: "", wantErr: true},
 		{name: "invalid handlers", feature: "User", fields: "Name:string", database: "", handlers: "soap", wantErr: true},
 	}
 
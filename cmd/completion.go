@@ -0,0 +1,324 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion <bash|zsh|fish|powershell>",
+	Short: "Generate the shell completion script",
+	Long: `Writes the completion script of a shell to standard output. Besides
+commands and flags, it completes the values of --database, --handlers, --type,
+--di, --dto-style, --router, --preset and the layer flags, and the entities
+and fields of the project in internal/domain.
+
+Bash (needs the bash-completion package):
+  Linux:  goca completion bash > /etc/bash_completion.d/goca
+  macOS:  goca completion bash > $(brew --prefix)/etc/bash_completion.d/goca
+
+Zsh (with compinit enabled):
+  goca completion zsh > "${fpath[1]}/_goca"
+
+Fish:
+  goca completion fish > ~/.config/fish/completions/goca.fish
+
+PowerShell (Windows, macOS and Linux):
+  goca completion powershell | Out-String | Invoke-Expression
+  Add the line to $PROFILE to load it in every session.
+
+Start a new shell for the completions to take effect.`,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		noDescriptions, _ := cmd.Flags().GetBool("no-descriptions")
+		return writeCompletion(cmd.Root(), args[0], !noDescriptions)
+	},
+}
+
+func init() {
+	completionCmd.Flags().Bool("no-descriptions", false, "Leave the descriptions out of the completions")
+}
+
+// writeCompletion writes the completion script of shell for root to
+// standard output.
+func writeCompletion(root *cobra.Command, shell string, descriptions bool) error {
+	out := root.OutOrStdout()
+	switch shell {
+	case "bash":
+		return root.GenBashCompletionV2(out, descriptions)
+	case "zsh":
+		if descriptions {
+			return root.GenZshCompletion(out)
+		}
+		return root.GenZshCompletionNoDesc(out)
+	case "fish":
+		return root.GenFishCompletion(out, descriptions)
+	case "powershell":
+		if descriptions {
+			return root.GenPowerShellCompletionWithDesc(out)
+		}
+		return root.GenPowerShellCompletion(out)
+	}
+	return fmt.Errorf("unsupported shell %q", shell)
+}
+
+// flagValueCompletions are the values completed for the flags of these names,
+// on every command declaring one. Flags taking a comma-separated list complete
+// each element of the list.
+var flagValueCompletions = map[string]struct {
+	values func() []string
+	list   bool
+}{
+	"database":         {func() []string { return ValidDatabases }, false},
+	"databases":        {func() []string { return ValidDatabases }, true},
+	"handlers":         {func() []string { return ValidHandlers }, true},
+	"di":               {func() []string { return ValidDIStyles }, false},
+	"lifetime":         {func() []string { return ValidDILifetimes }, false},
+	"dto-style":        {func() []string { return ValidDTOStyles }, false},
+	"router":           {func() []string { return ValidRouters }, false},
+	"sqlite-driver":    {func() []string { return ValidSQLiteDrivers }, false},
+	"auth-mode":        {func() []string { return ValidAuthModes }, false},
+	"skip":             {func() []string { return ValidFeatureLayers }, true},
+	"layers":           {func() []string { return ValidFeatureLayers }, true},
+	"only":             {func() []string { return ValidFeatureLayers }, true},
+	"preset":           {completedPresets, false},
+	"entity":           {completedEntities, false},
+	"middleware-types": {func() []string { return validMiddlewareTypes }, true},
+	"types":            {func() []string { return validMiddlewareTypes }, true},
+	"app":              {exampleAppNames, false},
+	"partitioned-by":   {partitionSpecCompletions, false},
+}
+
+// registerCompletions adds the dynamic completions to the commands under
+// root. It runs once every command is registered, right before root
+// executes.
+func registerCompletions(root *cobra.Command) {
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		cmd.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+			completion, ok := flagValueCompletions[f.Name]
+			if !ok {
+				return
+			}
+			if _, exists := cmd.GetFlagCompletionFunc(f.Name); exists {
+				return
+			}
+			_ = cmd.RegisterFlagCompletionFunc(f.Name, completeValues(completion.values, completion.list))
+		})
+		for _, sub := range cmd.Commands() {
+			walk(sub)
+		}
+	}
+	walk(root)
+
+	if _, exists := handlerCmd.GetFlagCompletionFunc("type"); !exists {
+		_ = handlerCmd.RegisterFlagCompletionFunc("type", completeValues(func() []string {
+			return append(append([]string{}, ValidHandlers...), DirSOAP)
+		}, false))
+	}
+
+	for _, cmd := range []*cobra.Command{
+		handlerCmd, repositoryCmd, interfacesCmd, messagesCmd, mocksCmd, presenterCmd,
+		archiveCmd, cdcCmd, testIntegrationCmd, fieldAddCmd, renameFeatureCmd,
+	} {
+		setArgsCompletion(cmd, completeEntityArg)
+	}
+	setArgsCompletion(regenCmd, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		_, lock, err := loadProjectLock()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return lock.lockedFeatures(), cobra.ShellCompDirectiveNoFileComp
+	})
+	for _, cmd := range []*cobra.Command{adminCmd, benchCmd, diffCmd} {
+		setArgsCompletion(cmd, completeEntityArgs)
+	}
+	setArgsCompletion(fieldRemoveCmd, completeEntityFields(true))
+	setArgsCompletion(fieldRenameCmd, completeEntityFields(false))
+	setArgsCompletion(explainCmd, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completedEntities(), cobra.ShellCompDirectiveDefault
+	})
+}
+
+// setArgsCompletion completes the arguments of cmd with complete unless it
+// already completes them.
+func setArgsCompletion(cmd *cobra.Command, complete cobra.CompletionFunc) {
+	if cmd.ValidArgsFunction == nil && len(cmd.ValidArgs) == 0 {
+		cmd.ValidArgsFunction = complete
+	}
+}
+
+// completeValues completes a flag with values. A list flag completes the last
+// element of its comma-separated value, leaving out the elements already
+// given.
+func completeValues(values func() []string, list bool) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if !list {
+			return values(), cobra.ShellCompDirectiveNoFileComp
+		}
+		prefix := ""
+		if i := strings.LastIndex(toComplete, ","); i >= 0 {
+			prefix = toComplete[:i+1]
+		}
+		given := make(map[string]bool)
+		for _, v := range strings.Split(prefix, ",") {
+			given[strings.TrimSpace(v)] = true
+		}
+		var completions []string
+		for _, v := range values() {
+			if !given[v] {
+				completions = append(completions, prefix+v)
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+}
+
+// completeEntityArg completes the single entity argument of a command.
+func completeEntityArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completedEntities(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeEntityArgs completes the entity arguments of a command taking
+// several, leaving out those already given.
+func completeEntityArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	given := make(map[string]bool, len(args))
+	for _, arg := range args {
+		given[arg] = true
+	}
+	var completions []string
+	for _, entity := range completedEntities() {
+		if !given[entity] {
+			completions = append(completions, entity)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeEntityFields completes an entity, then its fields. With list, the
+// fields are a comma-separated list, as goca field remove takes them;
+// otherwise a single field is completed and the arguments after it are not.
+func completeEntityFields(list bool) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return completedEntities(), cobra.ShellCompDirectiveNoFileComp
+		}
+		if len(args) > 1 && !list {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		fields := func() []string { return completedFields(args[0]) }
+		return completeValues(fields, list)(cmd, args, toComplete)
+	}
+}
+
+// completedEntities returns the entities of the project goca runs in,
+// honoring -C.
+func completedEntities() []string {
+	return domainEntities(filepath.Join(chdir, DirInternal, DirDomain))
+}
+
+// completedFields returns the fields of entity that goca field remove and
+// rename accept.
+func completedFields(entity string) []string {
+	for _, e := range completedEntities() {
+		if !strings.EqualFold(e, entity) {
+			continue
+		}
+		file := findTypeFile(filepath.Join(chdir, DirInternal, DirDomain), e)
+		if file == "" {
+			return nil
+		}
+		src, err := parseGoSource(file)
+		if err != nil {
+			return nil
+		}
+		var names []string
+		for _, f := range readEntityFields(src.file, e) {
+			if !isSystemField(f.Name) {
+				names = append(names, f.Name)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// completedPresets returns the built-in presets and those of .goca.yaml.
+func completedPresets() []string {
+	dir := chdir
+	if dir == "" {
+		dir = "."
+	}
+	_, defined := loadConfigPresets(dir)
+	return presetNames(defined)
+}
+
+// domainEntities returns the entities declared in the domain package dir:
+// the exported structs with an ID field, sorted by name.
+func domainEntities(dir string) []string {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil
+	}
+	var entities []string
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), file, data, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				st, ok := ts.Type.(*ast.StructType)
+				if ok && ts.Name.IsExported() && hasIDField(st) {
+					entities = append(entities, ts.Name.Name)
+				}
+			}
+		}
+	}
+	sort.Strings(entities)
+	return entities
+}
+
+// hasIDField reports whether the struct st declares an ID field.
+func hasIDField(st *ast.StructType) bool {
+	for _, field := range st.Fields.List {
+		for _, name := range field.Names {
+			if name.Name == "ID" {
+				return true
+			}
+		}
+	}
+	return false
+}
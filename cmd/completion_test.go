@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompleteValues(t *testing.T) {
+	values := func() []string { return []string{"http", "grpc", "cli"} }
+
+	got, directive := completeValues(values, false)(nil, nil, "")
+	assert.Equal(t, []string{"http", "grpc", "cli"}, got)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	got, directive = completeValues(values, true)(nil, nil, "http,g")
+	assert.Equal(t, []string{"http,grpc", "http,cli"}, got)
+	assert.NotZero(t, directive&cobra.ShellCompDirectiveNoSpace)
+}
+
+func TestCompleteEntities(t *testing.T) {
+	chdirTemp(t)
+	writeTestFile(t, filepath.Join(DirInternal, DirDomain), "product.go", `package domain
+
+type Product struct {
+	ID    uint
+	Name  string
+	Stock int
+}
+
+type ProductFilter struct {
+	Name string
+}
+`)
+	writeTestFile(t, filepath.Join(DirInternal, DirDomain), "order.go", "package domain\n\ntype Order struct {\n\tID    uint\n\tTotal float64\n}\n")
+
+	assert.Equal(t, []string{"Order", "Product"}, completedEntities())
+
+	got, _ := completeEntityArgs(nil, []string{"Order"}, "")
+	assert.Equal(t, []string{"Product"}, got)
+	got, _ = completeEntityArg(nil, []string{"Order"}, "")
+	assert.Empty(t, got)
+
+	got, _ = completeEntityFields(true)(nil, []string{"Product"}, "Name,")
+	assert.Equal(t, []string{"Name,Stock"}, got)
+	got, _ = completeEntityFields(false)(nil, []string{"Product", "Name"}, "")
+	assert.Empty(t, got)
+}
+
+func TestWriteCompletion(t *testing.T) {
+	root := &cobra.Command{Use: "goca"}
+	root.AddCommand(&cobra.Command{Use: "feature", Run: func(*cobra.Command, []string) {}})
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		var out bytes.Buffer
+		root.SetOut(&out)
+		require.NoError(t, writeCompletion(root, shell, true), shell)
+		assert.Contains(t, out.String(), "goca", shell)
+	}
+	assert.Error(t, writeCompletion(root, "tcsh", true))
+}
+
+func TestGenerateManPages(t *testing.T) {
+	root := &cobra.Command{Use: "goca", Short: "Go Clean Architecture Code Generator"}
+	field := &cobra.Command{Use: "field", Short: "Evolve an entity"}
+	field.AddCommand(&cobra.Command{
+		Use:   "add <entity> <fields>",
+		Short: "Add fields",
+		Long:  "Adds fields to <entity>.",
+		Run:   func(*cobra.Command, []string) {},
+	})
+	root.AddCommand(field)
+
+	dir := t.TempDir()
+	count, err := generateManPages(root, dir)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	page, err := os.ReadFile(filepath.Join(dir, "goca-field-add.1"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "goca field add <entity> <fields> [flags]")
+	assert.Contains(t, string(page), "Adds fields to <entity>")
+	assert.Equal(t, "add <entity> <fields>", field.Commands()[0].Use)
+}
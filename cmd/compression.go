@@ -0,0 +1,509 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// klauspostCompressVersion pins github.com/klauspost/compress, the zstd
+// encoder of the compression middleware.
+const klauspostCompressVersion = "v1.17.9"
+
+// compressionAlgorithms are the encodings the compression middleware
+// supports, in the default order of preference.
+var compressionAlgorithms = []string{"zstd", "gzip"}
+
+// defaultCompressionContentTypes are the media types compressed when
+// api.compression.content_types is not set.
+var defaultCompressionContentTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/xml",
+	"application/javascript",
+	"image/svg+xml",
+	"text/*",
+}
+
+// projectCompression returns api.compression of the current project with the
+// defaults filled in.
+func projectCompression() CompressionConfig {
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	return resolveCompression(ci.GetAPIConfig().Compression)
+}
+
+// resolveCompression fills in the defaults of cfg and drops the algorithms
+// the middleware does not support; goca config validate reports them.
+func resolveCompression(cfg CompressionConfig) CompressionConfig {
+	var algorithms []string
+	for _, algorithm := range cfg.Algorithms {
+		algorithm = strings.ToLower(strings.TrimSpace(algorithm))
+		if contains(compressionAlgorithms, algorithm) && !contains(algorithms, algorithm) {
+			algorithms = append(algorithms, algorithm)
+		}
+	}
+	if len(algorithms) == 0 {
+		algorithms = compressionAlgorithms
+	}
+	cfg.Algorithms = algorithms
+	if cfg.MinSize <= 0 {
+		cfg.MinSize = 1024
+	}
+	if len(cfg.ContentTypes) == 0 {
+		cfg.ContentTypes = defaultCompressionContentTypes
+	}
+	if cfg.MaxDecompressedSize <= 0 {
+		cfg.MaxDecompressedSize = 10 << 20
+	}
+	return cfg
+}
+
+// compressionUsesZstd reports whether the compression middleware of the
+// current project needs github.com/klauspost/compress.
+func compressionUsesZstd() bool {
+	cfg := projectCompression()
+	return cfg.Enabled && contains(cfg.Algorithms, "zstd")
+}
+
+// generateCompressionMiddleware returns compression.go content, with the
+// settings of api.compression as DefaultCompressionConfig. zstd support is
+// only compiled in when it is one of the algorithms.
+func generateCompressionMiddleware() string {
+	cfg := projectCompression()
+	zstd := contains(cfg.Algorithms, "zstd")
+
+	var b strings.Builder
+	b.WriteString("package middleware\n\n")
+	b.WriteString("import (\n")
+	b.WriteString("\t\"compress/gzip\"\n")
+	b.WriteString("\t\"io\"\n")
+	b.WriteString("\t\"mime\"\n")
+	b.WriteString("\t\"net/http\"\n")
+	b.WriteString("\t\"strconv\"\n")
+	b.WriteString("\t\"strings\"\n")
+	b.WriteString("\t\"sync\"\n")
+	if zstd {
+		b.WriteString("\n\t\"github.com/klauspost/compress/zstd\"\n")
+	}
+	b.WriteString(")\n\n")
+	b.WriteString(compressionConfigTemplate)
+
+	quoted := func(values []string) string {
+		items := make([]string, len(values))
+		for i, v := range values {
+			items[i] = fmt.Sprintf("%q", v)
+		}
+		return strings.Join(items, ", ")
+	}
+	b.WriteString("// DefaultCompressionConfig returns the settings of api.compression in\n")
+	b.WriteString("// .goca.yaml when the middleware was generated.\n")
+	b.WriteString("func DefaultCompressionConfig() CompressionConfig {\n")
+	b.WriteString("\treturn CompressionConfig{\n")
+	fmt.Fprintf(&b, "\t\tEncodings:           []string{%s},\n", quoted(cfg.Algorithms))
+	fmt.Fprintf(&b, "\t\tMinSize:             %d,\n", cfg.MinSize)
+	fmt.Fprintf(&b, "\t\tContentTypes:        []string{%s},\n", quoted(cfg.ContentTypes))
+	fmt.Fprintf(&b, "\t\tMaxDecompressedSize: %d,\n", cfg.MaxDecompressedSize)
+	b.WriteString("\t}\n")
+	b.WriteString("}\n\n")
+
+	b.WriteString(compressionMiddlewareTemplate)
+	if zstd {
+		b.WriteString(compressionDecodeZstdCase)
+	}
+	b.WriteString(compressionDecodeTail)
+	b.WriteString(compressionWriterTemplate)
+	if zstd {
+		b.WriteString(zstdEncoderTemplate)
+	} else {
+		b.WriteString(gzipEncoderTemplate)
+	}
+	return b.String()
+}
+
+// ensureCompressionMiddleware writes internal/middleware/compression.go, with
+// middleware.go when the package does not exist yet, and registers the
+// middleware on the router of main.go. Like the other shared files, an
+// existing compression.go is only rewritten with --force.
+func ensureCompressionMiddleware(sm ...*SafetyManager) {
+	dir := filepath.Join(DirInternal, dirMiddleware)
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+
+	chainPath := filepath.Join(dir, "middleware.go")
+	unlockChain := lockSharedFile(chainPath)
+	if _, err := os.Stat(chainPath); err != nil {
+		if err := writeGoFile(chainPath, generateChainMiddleware(getModuleName()), sm...); err != nil {
+			ui.Error(fmt.Sprintf("Error writing middleware.go: %v", err))
+		}
+	}
+	unlockChain()
+
+	filename := filepath.Join(dir, "compression.go")
+	unlock := lockSharedFile(filename)
+	if _, err := os.Stat(filename); err != nil || force {
+		if err := writeGoFile(filename, generateCompressionMiddleware(), sm...); err != nil {
+			ui.Error(fmt.Sprintf("Error writing compression middleware: %v", err))
+		}
+	}
+	unlock()
+
+	wireCompressionIntoMainGo(sm...)
+}
+
+// wireCompressionIntoMainGo registers middleware.Compression on the root
+// router of main.go. It is idempotent and leaves main.go alone when the
+// router anchor is missing.
+func wireCompressionIntoMainGo(sm ...*SafetyManager) {
+	call := "router.Use(mux.MiddlewareFunc(middleware.Compression(middleware.DefaultCompressionConfig())))"
+	mainPath, found := findMainGoPath()
+	if !found {
+		ui.Dim("   Register the compression middleware in main.go: " + call)
+		return
+	}
+	defer lockSharedFile(mainPath)()
+
+	raw, err := os.ReadFile(mainPath)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not read main.go: %v", err))
+		return
+	}
+	content := string(raw)
+	if strings.Contains(content, "middleware.Compression(") {
+		return
+	}
+
+	anchor := "\trouter := mux.NewRouter()\n"
+	if !strings.Contains(content, anchor) {
+		ui.Dim("   Register the compression middleware in main.go: " + call)
+		return
+	}
+
+	content = ensureMainGoImport(content, getImportPath(getModuleName())+"/internal/middleware")
+	content = strings.Replace(content, anchor, anchor+
+		"\n\t// Compress responses and decompress request bodies (api.compression)\n"+
+		"\t"+call+"\n", 1)
+
+	if err := writeGoFileMerged(mainPath, content, sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Could not register compression middleware in main.go: %v", err))
+		return
+	}
+	ui.Dim("   Compression middleware registered in main.go")
+}
+
+const compressionConfigTemplate = `// CompressionConfig holds the response compression and request
+// decompression settings.
+type CompressionConfig struct {
+	// Encodings are the encodings responses are compressed with, in order
+	// of preference when the client accepts several equally.
+	Encodings []string
+	// MinSize is the smallest response body compressed, in bytes. Smaller
+	// bodies cost more to compress than they save.
+	MinSize int
+	// ContentTypes are the media types compressed. An entry ending in /*
+	// matches every subtype, e.g. text/*.
+	ContentTypes []string
+	// MaxDecompressedSize bounds request bodies after decompression, in
+	// bytes, so a small compressed body cannot expand without limit.
+	// Larger bodies fail to read with *http.MaxBytesError. Zero refuses
+	// compressed request bodies.
+	MaxDecompressedSize int64
+}
+
+`
+
+const compressionMiddlewareTemplate = `// Compression returns middleware that compresses responses with the
+// preferred encoding the client accepts, once they reach MinSize bytes of a
+// compressible content type, and decompresses request bodies.
+// Responses that are already encoded, ranges, HEAD requests and upgrades
+// are passed through.
+func Compression(cfg CompressionConfig) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !decompressRequest(w, r, cfg) {
+				return
+			}
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), cfg.Encodings)
+			if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Upgrade") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			cw := &compressWriter{ResponseWriter: w, cfg: cfg, encoding: encoding}
+			defer cw.Close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding returns the encoding of encodings the Accept-Encoding
+// header gives the highest quality, or "" when it accepts none of them.
+func negotiateEncoding(header string, encodings []string) string {
+	best, bestQuality := "", 0.0
+	for _, encoding := range encodings {
+		if quality := acceptQuality(header, encoding); quality > bestQuality {
+			best, bestQuality = encoding, quality
+		}
+	}
+	return best
+}
+
+// acceptQuality returns the q-value the Accept-Encoding header gives
+// encoding, directly or through *, and 0 when it is not accepted.
+func acceptQuality(header, encoding string) float64 {
+	wildcard := 0.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if q, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = q
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case encoding:
+			return quality
+		case "*":
+			wildcard = quality
+		}
+	}
+	return wildcard
+}
+
+// decompressRequest replaces a compressed request body with its content,
+// limited to MaxDecompressedSize bytes. It answers a body it cannot decode
+// with 415 or 400 itself, and then reports false.
+func decompressRequest(w http.ResponseWriter, r *http.Request, cfg CompressionConfig) bool {
+	encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+	if encoding == "" || encoding == "identity" || r.Body == nil || r.Body == http.NoBody {
+		return true
+	}
+	if cfg.MaxDecompressedSize <= 0 {
+		http.Error(w, "compressed request bodies are not accepted", http.StatusUnsupportedMediaType)
+		return false
+	}
+
+	var body io.ReadCloser
+	switch encoding {
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, "request body is not valid gzip", http.StatusBadRequest)
+			return false
+		}
+		body = reader
+`
+
+const compressionDecodeZstdCase = `	case "zstd":
+		reader, err := zstd.NewReader(r.Body, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(uint64(cfg.MaxDecompressedSize)))
+		if err != nil {
+			http.Error(w, "request body is not valid zstd", http.StatusBadRequest)
+			return false
+		}
+		body = reader.IOReadCloser()
+`
+
+const compressionDecodeTail = `	default:
+		http.Error(w, "unsupported Content-Encoding "+strconv.Quote(encoding), http.StatusUnsupportedMediaType)
+		return false
+	}
+
+	// The server still closes the original body once the handler returns.
+	r.Body = http.MaxBytesReader(w, body, cfg.MaxDecompressedSize)
+	r.Header.Del("Content-Encoding")
+	r.Header.Del("Content-Length")
+	r.ContentLength = -1
+	return true
+}
+
+`
+
+const compressionWriterTemplate = `// compressWriter holds back the start of a response until it knows whether
+// to compress it: the content type must be compressible and the body reach
+// MinSize bytes, or be flushed.
+type compressWriter struct {
+	http.ResponseWriter
+	cfg      CompressionConfig
+	encoding string
+	status   int
+	buf      []byte
+	started  bool
+	encoder  io.WriteCloser
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if status < http.StatusOK {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if cw.status == 0 {
+		cw.status = status
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	if cw.started {
+		if cw.encoder != nil {
+			return cw.encoder.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.cfg.MinSize {
+		if err := cw.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush sends what was written so far, compressed when the content type is
+// compressible whatever its size, since a flushed response is a stream.
+func (cw *compressWriter) Flush() {
+	if !cw.started {
+		if cw.status == 0 {
+			cw.status = http.StatusOK
+		}
+		if err := cw.start(true); err != nil {
+			return
+		}
+	}
+	if flusher, ok := cw.encoder.(interface{ Flush() error }); ok {
+		if err := flusher.Flush(); err != nil {
+			return
+		}
+	}
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// Close sends a response that stayed under MinSize as it is and ends the
+// compressed stream of one that did not.
+func (cw *compressWriter) Close() error {
+	if !cw.started {
+		if cw.status == 0 {
+			return nil
+		}
+		if err := cw.start(false); err != nil {
+			return err
+		}
+	}
+	if cw.encoder == nil {
+		return nil
+	}
+	err := cw.encoder.Close()
+	releaseEncoder(cw.encoder)
+	cw.encoder = nil
+	return err
+}
+
+// start writes the header, compressing the response when large is set and
+// it qualifies, followed by the held back start of the body.
+func (cw *compressWriter) start(large bool) error {
+	cw.started = true
+	header := cw.Header()
+	if _, ok := header["Content-Type"]; !ok && len(cw.buf) > 0 {
+		// Sniff before compressing, as net/http would sniff the encoded bytes.
+		header.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+	if large && cw.compressible() {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", cw.encoding)
+		cw.encoder = newEncoder(cw.encoding, cw.ResponseWriter)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if cw.encoder != nil {
+		_, err = cw.encoder.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// compressible reports whether the response may be compressed: it has a
+// body, is not encoded or a range yet, and its content type is listed.
+func (cw *compressWriter) compressible() bool {
+	header := cw.Header()
+	if cw.status == http.StatusNoContent || cw.status == http.StatusNotModified ||
+		header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	for _, pattern := range cw.cfg.ContentTypes {
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+		} else if mediaType == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+var gzipEncoders = sync.Pool{New: func() any { return gzip.NewWriter(nil) }}
+
+`
+
+const zstdEncoderTemplate = `var zstdEncoders = sync.Pool{New: func() any {
+	encoder, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+	return encoder
+}}
+
+// newEncoder returns a pooled encoder of encoding writing to w.
+func newEncoder(encoding string, w io.Writer) io.WriteCloser {
+	if encoding == "zstd" {
+		encoder := zstdEncoders.Get().(*zstd.Encoder)
+		encoder.Reset(w)
+		return encoder
+	}
+	encoder := gzipEncoders.Get().(*gzip.Writer)
+	encoder.Reset(w)
+	return encoder
+}
+
+// releaseEncoder returns a closed encoder to its pool.
+func releaseEncoder(encoder io.WriteCloser) {
+	switch e := encoder.(type) {
+	case *gzip.Writer:
+		gzipEncoders.Put(e)
+	case *zstd.Encoder:
+		zstdEncoders.Put(e)
+	}
+}
+`
+
+const gzipEncoderTemplate = `// newEncoder returns a pooled gzip encoder writing to w.
+func newEncoder(_ string, w io.Writer) io.WriteCloser {
+	encoder := gzipEncoders.Get().(*gzip.Writer)
+	encoder.Reset(w)
+	return encoder
+}
+
+// releaseEncoder returns a closed encoder to its pool.
+func releaseEncoder(encoder io.WriteCloser) {
+	if e, ok := encoder.(*gzip.Writer); ok {
+		gzipEncoders.Put(e)
+	}
+}
+`
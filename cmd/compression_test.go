@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCompressionConfig(t *testing.T, compression string) {
+	t.Helper()
+	config := "project:\n  name: shop\n  module: testproject\napi:\n  type: rest\n  compression:\n" + compression
+	require.NoError(t, os.WriteFile(".goca.yaml", []byte(config), 0o644))
+}
+
+func TestGenerateCompressionMiddleware(t *testing.T) {
+	chdirTemp(t)
+	writeCompressionConfig(t, "    enabled: true\n    min_size: 512\n    content_types: [application/json, text/*]\n")
+
+	path := filepath.Join(t.TempDir(), "compression.go")
+	require.NoError(t, os.WriteFile(path, []byte(generateCompressionMiddleware()), 0o644))
+	src := readParsedGo(t, path)
+	assert.Contains(t, src, `Encodings:           []string{"zstd", "gzip"},`)
+	assert.Contains(t, src, "MinSize:             512,")
+	assert.Contains(t, src, `ContentTypes:        []string{"application/json", "text/*"},`)
+	assert.Contains(t, src, "MaxDecompressedSize: 10485760,")
+	assert.Contains(t, src, `"github.com/klauspost/compress/zstd"`)
+	assert.Contains(t, src, "http.MaxBytesReader(w, body, cfg.MaxDecompressedSize)")
+	assert.Contains(t, src, "func Compression(cfg CompressionConfig) Middleware {")
+
+	writeCompressionConfig(t, "    enabled: true\n    algorithms: [gzip]\n")
+	require.NoError(t, os.WriteFile(path, []byte(generateCompressionMiddleware()), 0o644))
+	src = readParsedGo(t, path)
+	assert.Contains(t, src, `Encodings:           []string{"gzip"},`)
+	assert.NotContains(t, src, "zstd.")
+	assert.NotContains(t, src, "klauspost")
+}
+
+func TestGenerateHTTPHandler_Compression(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+	writeCompressionConfig(t, "    enabled: true\n")
+	mainPath := filepath.Join("cmd", "server", "main.go")
+	writeTestFile(t, ".", mainPath, "package main\n\nimport (\n\t\"github.com/gorilla/mux\"\n)\n\nfunc main() {\n\trouter := mux.NewRouter()\n\t_ = router\n}\n")
+
+	sm := NewSafetyManager(false, true, false)
+	generateUseCaseWithFields("ProductService", "Product", "create,read,update,delete,list", false, false, "name:string", "", sm)
+	generateHTTPHandler("Product", false, false, false, "lowercase", sm)
+	generateHTTPHandler("Product", false, false, false, "lowercase", sm)
+
+	readParsedGo(t, filepath.Join(DirInternal, dirMiddleware, "middleware.go"))
+	readParsedGo(t, filepath.Join(DirInternal, dirMiddleware, "compression.go"))
+	main := readParsedGo(t, mainPath)
+	assert.Contains(t, main, `"testproject/internal/middleware"`)
+	assert.Equal(t, 1, strings.Count(main, "router.Use(mux.MiddlewareFunc(middleware.Compression(middleware.DefaultCompressionConfig())))"))
+	assert.True(t, compressionUsesZstd())
+}
+
+func TestGenerateHTTPHandler_CompressionDisabled(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	sm := NewSafetyManager(false, true, false)
+	generateUseCaseWithFields("ProductService", "Product", "create,read,update,delete,list", false, false, "name:string", "", sm)
+	generateHTTPHandler("Product", false, false, false, "lowercase", sm)
+	assert.NoFileExists(t, filepath.Join(DirInternal, dirMiddleware, "compression.go"))
+	assert.False(t, compressionUsesZstd())
+}
+
+func TestResolveCompression(t *testing.T) {
+	cfg := resolveCompression(CompressionConfig{Algorithms: []string{"GZIP", "brotli", "gzip"}})
+	assert.Equal(t, []string{"gzip"}, cfg.Algorithms)
+	assert.Equal(t, 1024, cfg.MinSize)
+	assert.Equal(t, defaultCompressionContentTypes, cfg.ContentTypes)
+	assert.Equal(t, int64(10<<20), cfg.MaxDecompressedSize)
+
+	cm := NewConfigManager()
+	cm.validateAPI(&APIConfig{Compression: CompressionConfig{Algorithms: []string{"brotli"}, MinSize: -1}})
+	var fields []string
+	for _, e := range cm.GetErrors() {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{"api.compression.algorithms", "api.compression.min_size"}, fields)
+}
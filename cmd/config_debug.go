@@ -135,7 +135,7 @@ func showCurrentConfig() {
 // allowed values for `goca config init` flags.
 var (
 	validConfigTemplates = []string{"web", "api", "microservice", "full", "default"}
-	validConfigDatabases = []string{"postgres", "postgres-json", "mysql", "mongodb", "sqlite", "sqlserver", "dynamodb", "elasticsearch"}
+	validConfigDatabases = []string{"postgres", "postgres-json", "mysql", "mariadb", "tidb", "mongodb", "sqlite", "sqlserver", "oracle", "dynamodb", "elasticsearch"}
 )
 
 func isOneOf(value string, allowed []string) bool {
@@ -524,6 +524,9 @@ func getCurrentProjectName() string {
 }
 
 func getCurrentModuleName() string {
+	if importPath, ok := moduleImportPath(getCurrentDir()); ok {
+		return importPath
+	}
 	projectName := getCurrentProjectName()
 	return fmt.Sprintf("github.com/usuario/%s", projectName)
 }
@@ -112,6 +112,159 @@ func (ci *ConfigIntegration) GetBusinessRulesEnabled(cliFlag *bool) bool {
 	return false // default
 }
 
+// GetTracingEnabled reports whether OpenTelemetry tracing should be generated.
+// The CLI flag can only enable it; otherwise features.monitoring.tracing decides.
+func (ci *ConfigIntegration) GetTracingEnabled(cliFlag bool) bool {
+	if cliFlag {
+		return true
+	}
+
+	if ci.config != nil {
+		return ci.config.Features.Monitoring.Tracing
+	}
+
+	return false // default
+}
+
+// GetResilienceEnabled returns whether to wrap repositories with retry and
+// circuit breaker decorators, with fallback to features.resilience.enabled.
+func (ci *ConfigIntegration) GetResilienceEnabled(cliFlag bool) bool {
+	if cliFlag {
+		return true
+	}
+
+	if ci.config != nil {
+		return ci.config.Features.Resilience.Enabled
+	}
+
+	return false // default
+}
+
+// GetCQRSEnabled returns whether to split use cases into command and query
+// services, with fallback to features.cqrs.enabled.
+func (ci *ConfigIntegration) GetCQRSEnabled(cliFlag bool) bool {
+	if cliFlag {
+		return true
+	}
+
+	if ci.config != nil {
+		return ci.config.Features.CQRS.Enabled
+	}
+
+	return false // default
+}
+
+// GetContractsEnabled returns whether to generate Pact contract tests for
+// HTTP handlers, with fallback to testing.contracts.
+func (ci *ConfigIntegration) GetContractsEnabled(cliFlag bool) bool {
+	if cliFlag {
+		return true
+	}
+
+	if ci.config != nil {
+		return ci.config.Testing.Contracts
+	}
+
+	return false // default
+}
+
+// GetGoGenerateEnabled returns whether goca feature records features for go
+// generate, from the CLI flag or generation.go_generate.
+func (ci *ConfigIntegration) GetGoGenerateEnabled(cliFlag bool) bool {
+	if cliFlag {
+		return true
+	}
+
+	if ci.config != nil {
+		return ci.config.Generation.GoGenerate
+	}
+
+	return false // default
+}
+
+// GetPresenterEnabled returns whether goca feature generates the presenter
+// the handlers share, from the CLI flag or generation.presenter.
+func (ci *ConfigIntegration) GetPresenterEnabled(cliFlag bool) bool {
+	if cliFlag {
+		return true
+	}
+
+	if ci.config != nil {
+		return ci.config.Generation.Presenter
+	}
+
+	return false // default
+}
+
+// GetDIType returns the dependency injection style with fallback to
+// architecture.di.type and finally manual. Configured styles the generator
+// does not support (dig) fall back to manual.
+func (ci *ConfigIntegration) GetDIType(cliFlag string) string {
+	if cliFlag != "" {
+		return cliFlag
+	}
+
+	if ci.config != nil && isValidDIStyle(ci.config.Architecture.DI.Type) {
+		return ci.config.Architecture.DI.Type
+	}
+
+	return DIManual // default
+}
+
+// GetDILifetime returns when a modular DI container builds the components of
+// a feature, with fallback to architecture.di.lifetime and finally singleton.
+func (ci *ConfigIntegration) GetDILifetime(cliFlag string) string {
+	if cliFlag != "" {
+		return cliFlag
+	}
+
+	if ci.config != nil && ci.config.Architecture.DI.Lifetime != "" {
+		return ci.config.Architecture.DI.Lifetime
+	}
+
+	return DILifetimeSingleton // default
+}
+
+// GetDTOStyle returns how nested struct fields appear in DTOs, with fallback
+// to generation.dto_style and finally nested.
+func (ci *ConfigIntegration) GetDTOStyle(cliFlag string) string {
+	if cliFlag != "" {
+		return cliFlag
+	}
+
+	if ci.config != nil && ci.config.Generation.DTOStyle != "" {
+		return ci.config.Generation.DTOStyle
+	}
+
+	return DTOStyleNested // default
+}
+
+// GetResponseFormat returns how HTTP handlers write responses, with fallback
+// to raw.
+func (ci *ConfigIntegration) GetResponseFormat() string {
+	if ci.config != nil && ci.config.Generation.ResponseFormat != "" {
+		return ci.config.Generation.ResponseFormat
+	}
+
+	return ResponseFormatRaw // default
+}
+
+// repositoryInterfacesSegregated reports whether the current project enables
+// generation.interfaces.segregated.
+func repositoryInterfacesSegregated() bool {
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	return ci.GetGenerationConfig().Interfaces.Segregated
+}
+
+// projectSQLiteDriver returns the current project's database.driver, which
+// is empty (the cgo driver) unless SQLite was initialized with modernc.
+func projectSQLiteDriver() string {
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	return ci.GetDatabaseConfig().Driver
+}
+
 // GetProjectConfig returns project configuration for generation.
 func (ci *ConfigIntegration) GetProjectConfig() ProjectConfig {
 	if ci.config != nil {
@@ -188,6 +341,27 @@ func (ci *ConfigIntegration) GetDatabaseConfig() DatabaseConfig {
 	}
 }
 
+// GetDeployConfig returns deployment configuration.
+func (ci *ConfigIntegration) GetDeployConfig() DeployConfig {
+	if ci.config != nil {
+		return ci.config.Deploy
+	}
+
+	// Return default deploy config
+	projectName := filepath.Base(getCurrentDir())
+	return DeployConfig{
+		Docker: DockerConfig{
+			Enabled:    true,
+			Dockerfile: "Dockerfile",
+			Multistage: true,
+		},
+		Kubernetes: KubernetesConfig{
+			Namespace: projectName,
+			Manifests: "k8s",
+		},
+	}
+}
+
 // GetGenerationConfig returns generation configuration.
 func (ci *ConfigIntegration) GetGenerationConfig() GenerationConfig {
 	if ci.config != nil {
@@ -248,6 +422,72 @@ func (ci *ConfigIntegration) GetTemplateConfig() TemplateConfig {
 	}
 }
 
+// GetLintConfig returns the goca lint configuration.
+func (ci *ConfigIntegration) GetLintConfig() LintConfig {
+	if ci.config != nil {
+		return ci.config.Lint
+	}
+	return LintConfig{}
+}
+
+// GetResilienceConfig returns the settings of the repository resilience
+// decorators.
+func (ci *ConfigIntegration) GetResilienceConfig() ResilienceConfig {
+	if ci.config != nil {
+		return ci.config.Features.Resilience
+	}
+	return ResilienceConfig{}
+}
+
+// GetCQRSConfig returns the settings of the command and query use cases.
+func (ci *ConfigIntegration) GetCQRSConfig() CQRSConfig {
+	if ci.config != nil {
+		return ci.config.Features.CQRS
+	}
+	return CQRSConfig{}
+}
+
+// GetSchedulerConfig returns the schedules of cron handler jobs.
+func (ci *ConfigIntegration) GetSchedulerConfig() SchedulerConfig {
+	if ci.config != nil {
+		return ci.config.Features.Scheduler
+	}
+	return SchedulerConfig{}
+}
+
+// GetRetentionConfig returns the settings of the purge jobs of entities with a
+// retention window.
+func (ci *ConfigIntegration) GetRetentionConfig() RetentionConfig {
+	if ci.config != nil {
+		return ci.config.Features.Retention
+	}
+	return RetentionConfig{}
+}
+
+// GetNotificationConfig returns the defaults of goca notification.
+func (ci *ConfigIntegration) GetNotificationConfig() NotificationConfig {
+	if ci.config != nil {
+		return ci.config.Features.Notification
+	}
+	return NotificationConfig{}
+}
+
+// GetFeatureFlagsConfig returns the defaults of goca featureflags.
+func (ci *ConfigIntegration) GetFeatureFlagsConfig() FeatureFlagsConfig {
+	if ci.config != nil {
+		return ci.config.Features.FeatureFlags
+	}
+	return FeatureFlagsConfig{}
+}
+
+// GetAPIConfig returns how HTTP handlers decode request bodies.
+func (ci *ConfigIntegration) GetAPIConfig() APIConfig {
+	if ci.config != nil {
+		return ci.config.API
+	}
+	return APIConfig{}
+}
+
 // GetFeatureConfig returns feature configuration.
 func (ci *ConfigIntegration) GetFeatureConfig() FeatureConfig {
 	if ci.config != nil {
@@ -4,8 +4,13 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"maps"
+	"net/mail"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -329,6 +334,12 @@ func (cm *ConfigManager) validateConfig(config *GocaConfig) error {
 	// Validate features configuration
 	cm.validateFeatures(&config.Features)
 
+	// Validate API configuration
+	cm.validateAPI(&config.API)
+
+	// Validate experimental generators
+	cm.validateExperimental(config.Experimental)
+
 	// If we have critical errors, return them
 	if len(cm.errors) > 0 {
 		return fmt.Errorf("configuration validation failed with %d errors", len(cm.errors))
@@ -350,6 +361,32 @@ func (cm *ConfigManager) validateProject(project *ProjectConfig) {
 	if project.Version == "" {
 		cm.addWarning("project.version", "version not specified", "", "1.0.0")
 	}
+
+	cm.validateBranding(&project.Branding)
+}
+
+// validateBranding flags branding values that would render as broken links.
+func (cm *ConfigManager) validateBranding(b *BrandingConfig) {
+	urls := map[string]string{
+		"project.branding.org_url":     b.OrgURL,
+		"project.branding.support_url": b.SupportURL,
+		"project.branding.docs_url":    b.DocsURL,
+	}
+	for field, value := range urls {
+		if value != "" && !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "https://") {
+			cm.addWarning(field, "URL should start with http:// or https://", value, "https://"+value)
+		}
+	}
+
+	if b.SupportEmail != "" && !strings.Contains(b.SupportEmail, "@") {
+		cm.addWarning("project.branding.support_email", "support email looks invalid", b.SupportEmail, "support@example.com")
+	}
+
+	for i, badge := range b.Badges {
+		if badge.Image == "" {
+			cm.addWarning(fmt.Sprintf("project.branding.badges[%d].image", i), "badge has no image URL and will be skipped", badge.Label, "")
+		}
+	}
 }
 
 // validateArchitecture validates architecture configuration.
@@ -365,29 +402,62 @@ func (cm *ConfigManager) validateArchitecture(arch *ArchitectureConfig) {
 		cm.addError("architecture.naming.fields", "invalid naming convention", arch.Naming.Fields)
 	}
 
+	if arch.Naming.Tables != "" && !cm.contains([]string{TableNamingPlural, TableNamingSingular}, arch.Naming.Tables) {
+		cm.addError("architecture.naming.tables", "invalid table naming, use plural or singular", arch.Naming.Tables)
+	}
+
+	if arch.Naming.JSON != "" && !cm.contains([]string{JSONNamingSnake, JSONNamingCamel}, arch.Naming.JSON) {
+		cm.addError("architecture.naming.json", "invalid json naming, use snake_case or camelCase", arch.Naming.JSON)
+	}
+
+	for entity, table := range arch.Naming.TableNames {
+		if err := validateTableName(table); err != nil {
+			cm.addError("architecture.naming.table_names."+entity, err.Error(), table)
+		}
+	}
+
 	// Validate DI type
-	validDITypes := []string{"manual", "wire", "fx", "dig"}
+	validDITypes := []string{"manual", "wire", "fx", "modular", "dig"}
 	if !cm.contains(validDITypes, arch.DI.Type) {
 		cm.addError("architecture.di.type", "invalid DI type", arch.DI.Type)
 	}
+	if arch.DI.Lifetime != "" && !cm.contains(ValidDILifetimes, arch.DI.Lifetime) {
+		cm.addError("architecture.di.lifetime", "invalid DI lifetime, use singleton or lazy", arch.DI.Lifetime)
+	}
 }
 
 // validateDatabase validates database configuration.
 func (cm *ConfigManager) validateDatabase(db *DatabaseConfig) {
 	// Must stay in sync with the database types init/feature accept; otherwise a
 	// generated config fails to load and the project falls back to defaults.
-	validDBTypes := []string{"postgres", "postgres-json", "mysql", "mongodb", "sqlite", "sqlserver", "dynamodb", "elasticsearch"}
+	validDBTypes := []string{"postgres", "postgres-json", "mysql", "mariadb", "tidb", "mongodb", "sqlite", "sqlserver", "oracle", "dynamodb", "elasticsearch"}
 	if !cm.contains(validDBTypes, db.Type) {
 		cm.addError("database.type", "invalid database type", db.Type)
 	}
 
-	if db.Port <= 0 || db.Port > 65535 {
+	// SQLite and DynamoDB Local record no port (see defaultPortForDatabase).
+	portless := db.Type == "sqlite" || db.Type == "dynamodb"
+	if (db.Port <= 0 && !portless) || db.Port < 0 || db.Port > 65535 {
 		cm.addError("database.port", "invalid port number", strconv.Itoa(db.Port))
 	}
 
+	if db.Driver != "" && (db.Type != "sqlite" || !cm.contains(ValidSQLiteDrivers, db.Driver)) {
+		cm.addError("database.driver", "driver must be mattn or modernc and requires type sqlite", db.Driver)
+	}
+
+	if db.IDStrategy != "" && (db.Type != DBMongoDB || !cm.contains(ValidIDStrategies, db.IDStrategy)) {
+		cm.addError("database.id_strategy", "id_strategy must be sequence or objectid and requires type mongodb", db.IDStrategy)
+	}
+
 	if db.Connection.MaxOpen <= 0 {
 		cm.addWarning("database.connection.max_open", "max_open should be > 0", strconv.Itoa(db.Connection.MaxOpen), "25")
 	}
+
+	if timeout := db.Migrations.Startup.LockTimeout; timeout != "" {
+		if parsed, err := time.ParseDuration(timeout); err != nil || parsed <= 0 {
+			cm.addError("database.migrations.startup.lock_timeout", "invalid duration", timeout)
+		}
+	}
 }
 
 // validateGeneration validates generation configuration.
@@ -398,10 +468,33 @@ func (cm *ConfigManager) validateGeneration(gen *GenerationConfig) {
 		cm.addError("generation.validation.library", "invalid validation library", gen.Validation.Library)
 	}
 
+	if gen.DTOStyle != "" && !cm.contains(ValidDTOStyles, gen.DTOStyle) {
+		cm.addError("generation.dto_style", "invalid DTO style", gen.DTOStyle)
+	}
+	if gen.ResponseFormat != "" && !cm.contains(ValidResponseFormats, gen.ResponseFormat) {
+		cm.addError("generation.response_format", "invalid response format", gen.ResponseFormat)
+	}
+
 	// Validate style configuration
+	if gen.Style.ReceiverNames != "" && !cm.contains(ValidReceiverNames, gen.Style.ReceiverNames) {
+		cm.addError("generation.style.receiver_names", "invalid receiver naming style", gen.Style.ReceiverNames)
+	}
+	if gen.Style.CommentDensity != "" && !cm.contains(ValidCommentDensities, gen.Style.CommentDensity) {
+		cm.addError("generation.style.comment_density", "invalid comment density", gen.Style.CommentDensity)
+	}
+	if gen.Style.ErrorStrings != "" && !cm.contains(ValidErrorStrings, gen.Style.ErrorStrings) {
+		cm.addError("generation.style.error_strings", "invalid error string style", gen.Style.ErrorStrings)
+	}
 	if gen.Style.LineLength <= 0 {
 		cm.addWarning("generation.style.line_length", "line_length should be > 0", strconv.Itoa(gen.Style.LineLength), "120")
 	}
+	// A warning rather than an error: a config that fails to load would drop
+	// the protection of every other pattern.
+	for _, pattern := range gen.Protected {
+		if _, err := path.Match(pattern, ""); err != nil {
+			cm.addWarning("generation.protected", "invalid glob pattern; it matches no files", pattern, "")
+		}
+	}
 }
 
 // validateTesting validates testing configuration.
@@ -416,11 +509,62 @@ func (cm *ConfigManager) validateTesting(test *TestingConfig) {
 	}
 }
 
+// validateAPI validates API configuration.
+func (cm *ConfigManager) validateAPI(api *APIConfig) {
+	compression := api.Compression
+	for _, algorithm := range compression.Algorithms {
+		if !cm.contains(compressionAlgorithms, algorithm) {
+			cm.addError("api.compression.algorithms", "invalid algorithm", algorithm)
+		}
+	}
+	if compression.MinSize < 0 {
+		cm.addError("api.compression.min_size", "must not be negative", fmt.Sprint(compression.MinSize))
+	}
+	if compression.MaxDecompressedSize < 0 {
+		cm.addError("api.compression.max_decompressed_size", "must not be negative", fmt.Sprint(compression.MaxDecompressedSize))
+	}
+
+	versioning := api.Versioning
+	if versioning.Default != "" && validateAPIVersion(versioning.Default) != nil {
+		cm.addError("api.versioning.default", "invalid API version", versioning.Default)
+	}
+	for _, feature := range slices.Sorted(maps.Keys(versioning.Features)) {
+		if version := versioning.Features[feature]; validateAPIVersion(version) != nil {
+			cm.addError("api.versioning.features."+feature, "invalid API version", version)
+		}
+	}
+	for _, version := range slices.Sorted(maps.Keys(versioning.Deprecated)) {
+		field := "api.versioning.deprecated." + version
+		if validateAPIVersion(version) != nil {
+			cm.addError(field, "invalid API version", version)
+			continue
+		}
+		d := versioning.Deprecated[version]
+		since, sinceErr := time.Parse(deprecationDateLayout, d.Since)
+		if d.Since != "" && sinceErr != nil {
+			cm.addError(field+".since", "invalid date; expected YYYY-MM-DD", d.Since)
+		}
+		sunset, sunsetErr := time.Parse(deprecationDateLayout, d.Sunset)
+		if d.Sunset != "" && sunsetErr != nil {
+			cm.addError(field+".sunset", "invalid date; expected YYYY-MM-DD", d.Sunset)
+		}
+		if sinceErr == nil && sunsetErr == nil && sunset.Before(since) {
+			cm.addError(field+".sunset", "must not precede since", d.Sunset)
+		}
+		if u, err := url.Parse(d.Link); d.Link != "" && (err != nil || u.Scheme == "" || u.Host == "") {
+			cm.addError(field+".link", "must be an absolute URL", d.Link)
+		}
+		if version == resolveAPIVersion(VersioningConfig{Default: versioning.Default}, "") {
+			cm.addWarning(field, "new features are generated for a deprecated version", version, "set api.versioning.default to a later version")
+		}
+	}
+}
+
 // validateFeatures validates features configuration.
 func (cm *ConfigManager) validateFeatures(features *FeatureConfig) {
 	// Validate auth type
 	if features.Auth.Enabled {
-		validAuthTypes := []string{"jwt", "oauth2", "session", "basic"}
+		validAuthTypes := []string{AuthModeJWT, AuthModeAPIKey, AuthModeOIDC, "oauth2", "session", "basic"}
 		if !cm.contains(validAuthTypes, features.Auth.Type) {
 			cm.addError("features.auth.type", "invalid auth type", features.Auth.Type)
 		}
@@ -433,6 +577,69 @@ func (cm *ConfigManager) validateFeatures(features *FeatureConfig) {
 			cm.addError("features.cache.type", "invalid cache type", features.Cache.Type)
 		}
 	}
+
+	// Validate resilience durations
+	durations := []struct{ field, value string }{
+		{"features.resilience.retry.initial_backoff", features.Resilience.Retry.InitialBackoff},
+		{"features.resilience.retry.max_backoff", features.Resilience.Retry.MaxBackoff},
+		{"features.resilience.breaker.open_timeout", features.Resilience.Breaker.OpenTimeout},
+	}
+	for _, d := range durations {
+		if parsed, err := time.ParseDuration(d.value); d.value != "" && (err != nil || parsed <= 0) {
+			cm.addError(d.field, "invalid duration", d.value)
+		}
+	}
+	if features.Resilience.Retry.MaxAttempts < 0 {
+		cm.addError("features.resilience.retry.max_attempts", "must not be negative", fmt.Sprint(features.Resilience.Retry.MaxAttempts))
+	}
+
+	// Validate the replica environment variable name
+	if env := features.CQRS.ReplicaDSNEnv; env != "" && !envVarNamePattern.MatchString(env) {
+		cm.addError("features.cqrs.replica_dsn_env", "invalid environment variable name", env)
+	}
+
+	// Validate the cron schedules of scheduled jobs
+	for _, entity := range slices.Sorted(maps.Keys(features.Scheduler.Jobs)) {
+		jobs := features.Scheduler.Jobs[entity]
+		for _, op := range slices.Sorted(maps.Keys(jobs)) {
+			field := fmt.Sprintf("features.scheduler.jobs.%s.%s", entity, op)
+			if !contains(cronOperations, strings.ToLower(op)) {
+				cm.addError(field, "operation cannot be scheduled", op)
+			} else if err := validateCronSchedule(jobs[op]); err != nil {
+				cm.addError(field, "invalid cron schedule", jobs[op])
+			}
+		}
+	}
+
+	// Validate the purge jobs of entities with a retention window
+	if mode := features.Retention.Mode; mode != "" && !contains(ValidRetentionModes, mode) {
+		cm.addError("features.retention.mode", "invalid retention mode", mode)
+	}
+	if schedule := features.Retention.Schedule; schedule != "" {
+		if err := validateCronSchedule(schedule); err != nil {
+			cm.addError("features.retention.schedule", "invalid cron schedule", schedule)
+		}
+	}
+
+	// Validate the defaults of goca notification
+	if provider := features.Notification.Provider; provider != "" && !contains(ValidNotificationProviders, provider) {
+		cm.addError("features.notification.provider", "invalid notification provider", provider)
+	}
+	if from := features.Notification.From; from != "" {
+		if _, err := mail.ParseAddress(from); err != nil {
+			cm.addError("features.notification.from", "invalid email address", from)
+		}
+	}
+
+	// Validate the defaults of goca featureflags
+	if backend := features.FeatureFlags.Backend; backend != "" && !contains(ValidFlagsBackends, backend) {
+		cm.addError("features.feature_flags.backend", "invalid feature flags backend", backend)
+	}
+	if refresh := features.FeatureFlags.Refresh; refresh != "" {
+		if d, err := time.ParseDuration(refresh); err != nil || d <= 0 {
+			cm.addError("features.feature_flags.refresh", "invalid duration", refresh)
+		}
+	}
 }
 
 // applyDefaults applies default values for missing configuration.
@@ -446,14 +653,22 @@ func (cm *ConfigManager) applyDefaults(config *GocaConfig) {
 		config.Database.Port = 5432
 	}
 
-	if config.Database.Type == "mysql" && config.Database.Port == 0 {
+	if (config.Database.Type == "mysql" || config.Database.Type == "mariadb") && config.Database.Port == 0 {
 		config.Database.Port = 3306
 	}
 
+	if config.Database.Type == "tidb" && config.Database.Port == 0 {
+		config.Database.Port = 4000
+	}
+
 	if config.Database.Type == "mongodb" && config.Database.Port == 0 {
 		config.Database.Port = 27017
 	}
 
+	if config.Database.Type == "oracle" && config.Database.Port == 0 {
+		config.Database.Port = 1521
+	}
+
 	if config.Database.Type == "sqlite" && config.Database.Port == 0 {
 		config.Database.Port = 0 // SQLite doesn't need a port
 	}
@@ -532,6 +747,15 @@ func (cm *ConfigManager) GetErrors() []ConfigError {
 	return cm.errors
 }
 
+// validateExperimental warns about experiment names that are not registered.
+func (cm *ConfigManager) validateExperimental(names []string) {
+	for i, name := range parseExperimentList(strings.Join(names, ",")) {
+		if _, ok := lookupExperiment(name); !ok {
+			cm.addWarning(fmt.Sprintf("experimental[%d]", i), "unknown experiment has no effect", name, "")
+		}
+	}
+}
+
 // GetWarnings returns configuration warnings.
 func (cm *ConfigManager) GetWarnings() []ConfigWarning {
 	return cm.warnings
@@ -567,12 +791,16 @@ func (cm *ConfigManager) SaveConfig(filePath string) error {
 // database instead of always recording Postgres' 5432.
 func defaultPortForDatabase(database string) int {
 	switch database {
-	case "mysql":
+	case "mysql", "mariadb":
 		return 3306
+	case "tidb":
+		return 4000
 	case "mongodb":
 		return 27017
 	case "sqlserver":
 		return 1433
+	case "oracle":
+		return 1521
 	case "sqlite", "dynamodb":
 		return 0
 	case "elasticsearch":
@@ -596,6 +824,11 @@ func (cm *ConfigManager) GenerateDefaultConfig(projectPath, projectName, module,
 		config.Database.Type = database
 		config.Database.Port = defaultPortForDatabase(database)
 	}
+	// Record the generating version for goca version --check and goca upgrade.
+	if config.Project.Metadata == nil {
+		config.Project.Metadata = make(map[string]string)
+	}
+	config.Project.Metadata["goca_version"] = Version
 
 	cm.config = config
 
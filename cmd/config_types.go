@@ -29,6 +29,20 @@ type GocaConfig struct {
 
 	// Deployment and infrastructure
 	Deploy DeployConfig `json:"deploy" yaml:"deploy"`
+
+	// Architecture rules of goca lint
+	Lint LintConfig `json:"lint,omitempty" yaml:"lint,omitempty"`
+
+	// API type and how generated HTTP handlers read requests
+	API APIConfig `json:"api,omitempty" yaml:"api,omitempty"`
+
+	// Experimental generators enabled for this project (see goca experiments list)
+	Experimental []string `json:"experimental,omitempty" yaml:"experimental,omitempty"`
+
+	// Preset goca feature applies when --preset is not given, and presets
+	// defined or overridden for this project (see goca init --preset)
+	Preset  string            `json:"preset,omitempty"  yaml:"preset,omitempty"`
+	Presets map[string]Preset `json:"presets,omitempty" yaml:"presets,omitempty"`
 }
 
 // ProjectConfig contains basic project information.
@@ -42,6 +56,24 @@ type ProjectConfig struct {
 	Repository  string            `json:"repository"  yaml:"repository"`
 	Tags        []string          `json:"tags"        yaml:"tags"`
 	Metadata    map[string]string `json:"metadata"    yaml:"metadata"`
+	Branding    BrandingConfig    `json:"branding"    yaml:"branding"`
+}
+
+// BrandingConfig holds organization details injected into generated docs.
+type BrandingConfig struct {
+	OrgName      string        `json:"org_name"      yaml:"org_name"`
+	OrgURL       string        `json:"org_url"       yaml:"org_url"`
+	SupportEmail string        `json:"support_email" yaml:"support_email"`
+	SupportURL   string        `json:"support_url"   yaml:"support_url"`
+	DocsURL      string        `json:"docs_url"      yaml:"docs_url"`
+	Badges       []BadgeConfig `json:"badges"        yaml:"badges"`
+}
+
+// BadgeConfig defines a README badge.
+type BadgeConfig struct {
+	Label string `json:"label" yaml:"label"`
+	Image string `json:"image" yaml:"image"`
+	Link  string `json:"link"  yaml:"link"`
 }
 
 // ArchitectureConfig defines Clean Architecture preferences.
@@ -80,7 +112,8 @@ type LayerConfig struct {
 
 // DIConfig defines dependency injection preferences.
 type DIConfig struct {
-	Type       string            `json:"type"       yaml:"type"` // manual, wire, fx, dig
+	Type       string            `json:"type"       yaml:"type"`     // manual, wire, fx, modular, dig
+	Lifetime   string            `json:"lifetime"   yaml:"lifetime"` // singleton, lazy (modular)
 	AutoWire   bool              `json:"auto_wire"  yaml:"auto_wire"`
 	Providers  []string          `json:"providers"  yaml:"providers"`
 	Modules    []string          `json:"modules"    yaml:"modules"`
@@ -96,29 +129,63 @@ type NamingConfig struct {
 	Constants string `json:"constants" yaml:"constants"` // UPPER_CASE, PascalCase
 	Variables string `json:"variables" yaml:"variables"` // camelCase, snake_case
 	Functions string `json:"functions" yaml:"functions"` // camelCase, PascalCase
+	// Tables is plural (categories, the GORM default) or singular
+	// (category).
+	Tables string `json:"tables,omitempty" yaml:"tables,omitempty"`
+	// JSON names the json keys of generated fields: snake_case (first_name)
+	// or camelCase (firstName).
+	JSON string `json:"json,omitempty" yaml:"json,omitempty"`
+	// TableNames maps entities to existing tables, e.g. Person: people.
+	TableNames map[string]string `json:"table_names,omitempty" yaml:"table_names,omitempty"`
 }
 
 // DatabaseConfig contains database configuration.
 type DatabaseConfig struct {
-	Type        string                `json:"type"         yaml:"type"` // postgres, mysql, mongodb, sqlite
-	Host        string                `json:"host"         yaml:"host"`
-	Port        int                   `json:"port"         yaml:"port"`
-	Name        string                `json:"name"         yaml:"name"`
-	Migrations  MigrationConfig       `json:"migrations"   yaml:"migrations"`
-	Connection  ConnectionConfig      `json:"connection"   yaml:"connection"`
-	Features    DatabaseFeatureConfig `json:"features"     yaml:"features"`
-	Extensions  []string              `json:"extensions"   yaml:"extensions"`
-	CustomTypes map[string]string     `json:"custom_types" yaml:"custom_types"`
+	Type        string                `json:"type"             yaml:"type"` // postgres, mysql, mongodb, sqlite
+	Host        string                `json:"host"             yaml:"host"`
+	Port        int                   `json:"port"             yaml:"port"`
+	Name        string                `json:"name"             yaml:"name"`
+	Driver      string                `json:"driver,omitempty" yaml:"driver,omitempty"`           // SQLite only: mattn (default) or modernc
+	IDStrategy  string                `json:"id_strategy,omitempty" yaml:"id_strategy,omitempty"` // MongoDB only: sequence (default) or objectid
+	Migrations  MigrationConfig       `json:"migrations"       yaml:"migrations"`
+	Connection  ConnectionConfig      `json:"connection"       yaml:"connection"`
+	Features    DatabaseFeatureConfig `json:"features"         yaml:"features"`
+	Partitions  PartitionConfig       `json:"partitions"       yaml:"partitions,omitempty"`
+	Extensions  []string              `json:"extensions"       yaml:"extensions"`
+	CustomTypes map[string]string     `json:"custom_types"     yaml:"custom_types"`
+}
+
+// PartitionConfig defines the maintenance of the tables of features
+// generated with --partitioned-by.
+type PartitionConfig struct {
+	// Retention is the number of periods before the current one whose
+	// partitions are kept; older ones are dropped. 0 keeps every partition.
+	Retention int `json:"retention,omitempty" yaml:"retention,omitempty"`
+	// Premake is the number of periods after the current one partitions are
+	// created for ahead of time. Defaults to 3.
+	Premake int `json:"premake,omitempty" yaml:"premake,omitempty"`
 }
 
 // MigrationConfig defines migration preferences.
 type MigrationConfig struct {
-	Enabled      bool     `json:"enabled"       yaml:"enabled"`
-	AutoGenerate bool     `json:"auto_generate" yaml:"auto_generate"`
-	Directory    string   `json:"directory"     yaml:"directory"`
-	Naming       string   `json:"naming"        yaml:"naming"`
-	Versioning   string   `json:"versioning"    yaml:"versioning"`
-	Tools        []string `json:"tools"         yaml:"tools"`
+	Enabled      bool                   `json:"enabled"       yaml:"enabled"`
+	AutoGenerate bool                   `json:"auto_generate" yaml:"auto_generate"`
+	Directory    string                 `json:"directory"     yaml:"directory"`
+	Naming       string                 `json:"naming"        yaml:"naming"`
+	Versioning   string                 `json:"versioning"    yaml:"versioning"`
+	Tools        []string               `json:"tools"         yaml:"tools"`
+	Startup      StartupMigrationConfig `json:"startup"       yaml:"startup,omitempty"`
+}
+
+// StartupMigrationConfig defines how the server applies the migrations when
+// it starts (goca migrate startup).
+type StartupMigrationConfig struct {
+	// Strict stops the server when the migrations fail instead of serving
+	// the current schema.
+	Strict bool `json:"strict" yaml:"strict"`
+	// LockTimeout bounds the wait for another instance's migrations, e.g.
+	// "1m".
+	LockTimeout string `json:"lock_timeout" yaml:"lock_timeout"`
 }
 
 // ConnectionConfig defines database connection settings.
@@ -160,6 +227,41 @@ type GenerationConfig struct {
 
 	// Import management
 	Imports ImportConfig `json:"imports" yaml:"imports"`
+
+	// DTOStyle controls how struct{...} fields appear in use case DTOs:
+	// nested (default) or flat (one DTO field per nested field).
+	DTOStyle string `json:"dto_style,omitempty" yaml:"dto_style,omitempty"`
+
+	// ResponseFormat controls how HTTP handlers write responses: raw (default),
+	// envelope ({"data","error","meta"}) or problem (RFC 7807 errors).
+	ResponseFormat string `json:"response_format,omitempty" yaml:"response_format,omitempty"`
+
+	// Interfaces controls how layer contracts are declared.
+	Interfaces InterfacesConfig `json:"interfaces,omitempty" yaml:"interfaces,omitempty"`
+
+	// Protected lists files and globs, relative to the project root, that
+	// generators never overwrite, even with --force.
+	Protected []string `json:"protected,omitempty" yaml:"protected,omitempty"`
+
+	// GoGenerate records generated features in goca.features.yaml and adds a
+	// go:generate directive that regenerates them.
+	GoGenerate bool `json:"go_generate,omitempty" yaml:"go_generate,omitempty"`
+
+	// Presenter generates internal/presenter/<entity>_presenter.go with each
+	// feature, which the HTTP, gRPC and CLI handlers present entities through.
+	Presenter bool `json:"presenter,omitempty" yaml:"presenter,omitempty"`
+
+	// ErrorWrapping generates pkg/apperrors: use cases wrap the errors of
+	// repositories with the operation that failed, and handlers log server
+	// errors with the stack recorded where they were wrapped.
+	ErrorWrapping bool `json:"error_wrapping,omitempty" yaml:"error_wrapping,omitempty"`
+}
+
+// InterfacesConfig defines how repository interfaces are generated.
+type InterfacesConfig struct {
+	// Segregated splits <Entity>Repository into <Entity>Reader, <Entity>Writer
+	// and <Entity>Searcher, and use cases depend on the narrowest of them.
+	Segregated bool `json:"segregated,omitempty" yaml:"segregated,omitempty"`
 }
 
 // ValidationConfig defines validation generation preferences.
@@ -247,6 +349,15 @@ type StyleConfig struct {
 	Custom      []string `json:"custom"      yaml:"custom"`
 	LineLength  int      `json:"line_length" yaml:"line_length"`
 	TabWidth    int      `json:"tab_width"   yaml:"tab_width"`
+
+	// ReceiverNames renames method receivers in generated code: single
+	// (first letter of the type) or abbreviated (initials of the type name).
+	ReceiverNames string `json:"receiver_names,omitempty" yaml:"receiver_names,omitempty"`
+	// CommentDensity is standard or minimal (declaration doc comments only).
+	CommentDensity string `json:"comment_density,omitempty" yaml:"comment_density,omitempty"`
+	// ErrorStrings sets the first letter of errors.New and fmt.Errorf
+	// messages: lowercase or capitalized.
+	ErrorStrings string `json:"error_strings,omitempty" yaml:"error_strings,omitempty"`
 }
 
 // ImportConfig defines import management.
@@ -275,6 +386,7 @@ type TestingConfig struct {
 	Benchmarks  bool           `json:"benchmarks"  yaml:"benchmarks"`
 	Examples    bool           `json:"examples"    yaml:"examples"`
 	Fixtures    FixtureConfig  `json:"fixtures"    yaml:"fixtures"`
+	Contracts   bool           `json:"contracts"   yaml:"contracts"` // Pact contract tests for HTTP handlers
 }
 
 // CoverageConfig defines test coverage preferences.
@@ -337,6 +449,24 @@ type FeatureConfig struct {
 	// Security features
 	Security SecurityConfig `json:"security" yaml:"security"`
 
+	// Retry and circuit breaker decorators of repositories
+	Resilience ResilienceConfig `json:"resilience,omitempty" yaml:"resilience,omitempty"`
+
+	// Command and query services reading from a replica
+	CQRS CQRSConfig `json:"cqrs,omitempty" yaml:"cqrs,omitempty"`
+
+	// Schedules of the jobs of cron handlers
+	Scheduler SchedulerConfig `json:"scheduler,omitempty" yaml:"scheduler,omitempty"`
+
+	// Purge jobs of entities with a retention window
+	Retention RetentionConfig `json:"retention,omitempty" yaml:"retention,omitempty"`
+
+	// Email notifications of goca notification
+	Notification NotificationConfig `json:"notification,omitempty" yaml:"notification,omitempty"`
+
+	// Feature flags of goca featureflags
+	FeatureFlags FeatureFlagsConfig `json:"feature_flags,omitempty" yaml:"feature_flags,omitempty"`
+
 	// Plugins and extensions
 	Plugins []PluginConfig `json:"plugins" yaml:"plugins"`
 }
@@ -344,7 +474,7 @@ type FeatureConfig struct {
 // AuthConfig defines authentication configuration.
 type AuthConfig struct {
 	Enabled    bool     `json:"enabled"    yaml:"enabled"`
-	Type       string   `json:"type"       yaml:"type"` // jwt, oauth2, session, basic
+	Type       string   `json:"type"       yaml:"type"` // jwt, api-key, oidc, oauth2, session, basic
 	Providers  []string `json:"providers"  yaml:"providers"`
 	RBAC       bool     `json:"rbac"       yaml:"rbac"`
 	Middleware bool     `json:"middleware" yaml:"middleware"`
@@ -359,6 +489,62 @@ type CacheConfig struct {
 	Patterns []string `json:"patterns" yaml:"patterns"`
 }
 
+// ResilienceConfig defines the retry and circuit breaker decorators of
+// repositories (goca feature --resilience). Durations use Go syntax, e.g. 250ms.
+type ResilienceConfig struct {
+	Enabled bool                 `json:"enabled"           yaml:"enabled"`
+	Retry   RetryConfig          `json:"retry,omitempty"   yaml:"retry,omitempty"`
+	Breaker CircuitBreakerConfig `json:"breaker,omitempty" yaml:"breaker,omitempty"`
+}
+
+// RetryConfig tunes the retries of transient repository errors.
+type RetryConfig struct {
+	MaxAttempts    int    `json:"max_attempts,omitempty"    yaml:"max_attempts,omitempty"`    // calls including the first, default 3
+	InitialBackoff string `json:"initial_backoff,omitempty" yaml:"initial_backoff,omitempty"` // default 100ms, doubled per retry
+	MaxBackoff     string `json:"max_backoff,omitempty"     yaml:"max_backoff,omitempty"`     // default 2s
+	Writes         bool   `json:"writes,omitempty"          yaml:"writes,omitempty"`          // also retry writes, which are not idempotent
+}
+
+// CircuitBreakerConfig tunes the circuit breaker of each repository.
+type CircuitBreakerConfig struct {
+	FailureThreshold uint32 `json:"failure_threshold,omitempty"  yaml:"failure_threshold,omitempty"`  // consecutive failures opening it, default 5
+	OpenTimeout      string `json:"open_timeout,omitempty"       yaml:"open_timeout,omitempty"`       // default 30s
+	HalfOpenRequests uint32 `json:"half_open_requests,omitempty" yaml:"half_open_requests,omitempty"` // trial calls once open, default 1
+}
+
+// CQRSConfig defines the command and query use cases of goca feature --cqrs.
+type CQRSConfig struct {
+	Enabled       bool   `json:"enabled"                   yaml:"enabled"`
+	Projection    bool   `json:"projection,omitempty"      yaml:"projection,omitempty"`      // serve queries from an in-memory read model
+	ReplicaDSNEnv string `json:"replica_dsn_env,omitempty" yaml:"replica_dsn_env,omitempty"` // default DB_REPLICA_DSN
+}
+
+// SchedulerConfig defines the jobs of goca handler --type cron.
+type SchedulerConfig struct {
+	// Jobs maps an entity to the cron schedule of each of its jobs, keyed by
+	// use case operation (list, count, delete).
+	Jobs map[string]map[string]string `json:"jobs,omitempty" yaml:"jobs,omitempty"`
+}
+
+// RetentionConfig defines the purge jobs of goca entity --retention.
+type RetentionConfig struct {
+	Mode     string `json:"mode,omitempty"     yaml:"mode,omitempty"`     // purge (default) or archive
+	Schedule string `json:"schedule,omitempty" yaml:"schedule,omitempty"` // cron schedule of the jobs, default "0 4 * * *"
+}
+
+// NotificationConfig defines the defaults of goca notification.
+type NotificationConfig struct {
+	Provider string `json:"provider,omitempty" yaml:"provider,omitempty"` // smtp (default), sendgrid, ses or log
+	From     string `json:"from,omitempty"     yaml:"from,omitempty"`     // sender address
+	Sync     bool   `json:"sync,omitempty"     yaml:"sync,omitempty"`     // send in the request instead of on the worker pool
+}
+
+// FeatureFlagsConfig defines the defaults of goca featureflags.
+type FeatureFlagsConfig struct {
+	Backend string `json:"backend,omitempty" yaml:"backend,omitempty"` // file (default), consul or etcd
+	Refresh string `json:"refresh,omitempty" yaml:"refresh,omitempty"` // reload interval, default 30s
+}
+
 // LoggingConfig defines logging configuration.
 type LoggingConfig struct {
 	Enabled    bool     `json:"enabled"    yaml:"enabled"`
@@ -369,14 +555,116 @@ type LoggingConfig struct {
 	Tracing    bool     `json:"tracing"    yaml:"tracing"`
 }
 
+// LintConfig tunes the architecture rules goca lint enforces.
+type LintConfig struct {
+	Disable []string        `json:"disable,omitempty" yaml:"disable,omitempty"` // rule names not checked
+	Allow   []LintException `json:"allow,omitempty"   yaml:"allow,omitempty"`
+}
+
+// LintException allows the imports a rule forbids in some files. Path is a
+// glob of slash-separated paths, where ** matches any number of directories
+// and a directory covers the files below it; an empty Path or Import matches
+// every file or import.
+type LintException struct {
+	Rule   string `json:"rule"             yaml:"rule"`
+	Path   string `json:"path,omitempty"   yaml:"path,omitempty"`
+	Import string `json:"import,omitempty" yaml:"import,omitempty"` // import path or prefix
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// APIConfig defines the API of the project and how generated HTTP handlers
+// decode request bodies.
+type APIConfig struct {
+	// Type is the API type chosen by goca init (rest, graphql or grpc).
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// StrictDecoding rejects request bodies with unknown fields, values of
+	// the wrong type or nesting deeper than MaxDepth, listing the rejected
+	// fields, instead of silently dropping what does not fit the DTO.
+	StrictDecoding bool `json:"strict_decoding,omitempty" yaml:"strict_decoding,omitempty"`
+	MaxDepth       int  `json:"max_depth,omitempty"       yaml:"max_depth,omitempty"` // default 32
+	// Compression compresses responses and decompresses request bodies in a
+	// middleware registered on the router of main.go.
+	Compression CompressionConfig `json:"compression,omitempty" yaml:"compression,omitempty"`
+	// Versioning chooses the API version the HTTP routes of each feature are
+	// generated for and announces the retirement of deprecated versions.
+	Versioning VersioningConfig `json:"versioning,omitempty" yaml:"versioning,omitempty"`
+}
+
+// VersioningConfig defines the API versions of HTTP projects. Each version
+// is mounted under /api/<version> in main.go.
+type VersioningConfig struct {
+	// Default is the version of the features Features does not list.
+	// Default v1.
+	Default string `json:"default,omitempty" yaml:"default,omitempty"`
+	// Features maps a feature to its version, e.g. product: v2. Versions
+	// after v1 get their own <Entity>V<N>Handler, which coexists with the
+	// handlers of earlier versions.
+	Features map[string]string `json:"features,omitempty" yaml:"features,omitempty"`
+	// Deprecated maps a version to its retirement, announced on every
+	// response of its routes with the Deprecation, Sunset and Link headers.
+	Deprecated map[string]DeprecationConfig `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+}
+
+// DeprecationConfig defines the retirement of an API version.
+type DeprecationConfig struct {
+	// Since is the date the version was deprecated, e.g. 2026-10-01.
+	Since string `json:"since,omitempty" yaml:"since,omitempty"`
+	// Sunset is the date the version is removed; from then on its routes
+	// answer 410 Gone.
+	Sunset string `json:"sunset,omitempty" yaml:"sunset,omitempty"`
+	// Link is the URL of the migration guide.
+	Link string `json:"link,omitempty" yaml:"link,omitempty"`
+}
+
+// CompressionConfig defines the compression middleware of HTTP projects.
+type CompressionConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// Algorithms are the response encodings in order of preference: gzip,
+	// zstd. Default zstd, gzip.
+	Algorithms []string `json:"algorithms,omitempty" yaml:"algorithms,omitempty"`
+	// MinSize is the smallest response body compressed, in bytes. Default 1024.
+	MinSize int `json:"min_size,omitempty" yaml:"min_size,omitempty"`
+	// ContentTypes are the media types compressed; type/* matches every
+	// subtype. Default JSON, XML, JavaScript, SVG and text/*.
+	ContentTypes []string `json:"content_types,omitempty" yaml:"content_types,omitempty"`
+	// MaxDecompressedSize bounds decompressed request bodies, in bytes.
+	// Default 10 MiB.
+	MaxDecompressedSize int64 `json:"max_decompressed_size,omitempty" yaml:"max_decompressed_size,omitempty"`
+}
+
 // MonitoringConfig defines monitoring and observability.
 type MonitoringConfig struct {
-	Enabled     bool     `json:"enabled"      yaml:"enabled"`
-	Metrics     bool     `json:"metrics"      yaml:"metrics"`
-	Tracing     bool     `json:"tracing"      yaml:"tracing"`
-	HealthCheck bool     `json:"health_check" yaml:"health_check"`
-	Profiling   bool     `json:"profiling"    yaml:"profiling"`
-	Tools       []string `json:"tools"        yaml:"tools"` // prometheus, jaeger, datadog
+	Enabled     bool      `json:"enabled"      yaml:"enabled"`
+	Metrics     bool      `json:"metrics"      yaml:"metrics"`
+	Tracing     bool      `json:"tracing"      yaml:"tracing"`
+	HealthCheck bool      `json:"health_check" yaml:"health_check"`
+	Profiling   bool      `json:"profiling"    yaml:"profiling"`
+	Tools       []string  `json:"tools"        yaml:"tools"` // prometheus, jaeger, datadog
+	SLO         SLOConfig `json:"slo"          yaml:"slo"`
+}
+
+// SLOConfig declares the service level objectives goca deploy slo turns into
+// Prometheus rules and a Grafana dashboard. Objectives are percentages.
+type SLOConfig struct {
+	Window       string              `json:"window"       yaml:"window"`       // compliance window, e.g. 30d
+	Availability float64             `json:"availability" yaml:"availability"` // % of requests answered without a 5xx
+	Latency      LatencyObjective    `json:"latency"      yaml:"latency"`
+	Endpoints    []EndpointObjective `json:"endpoints"    yaml:"endpoints"`
+}
+
+// LatencyObjective is the percentage of requests served within Threshold.
+type LatencyObjective struct {
+	Threshold string  `json:"threshold" yaml:"threshold"` // e.g. 250ms
+	Objective float64 `json:"objective" yaml:"objective"`
+}
+
+// EndpointObjective holds the objectives of one route. Objectives left out
+// are the service's.
+type EndpointObjective struct {
+	Method       string           `json:"method"       yaml:"method"`
+	Route        string           `json:"route"        yaml:"route"` // mux path template, e.g. /api/v1/orders/{id}
+	Availability float64          `json:"availability" yaml:"availability"`
+	Latency      LatencyObjective `json:"latency"      yaml:"latency"`
 }
 
 // SecurityConfig defines security features.
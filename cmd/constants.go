@@ -15,7 +15,7 @@ const (
 
 // Flag usage messages - Flag usage messages.
 const (
-	DatabaseFlagUsage       = "Database type (postgres, postgres-json, mysql, mongodb, sqlite, sqlserver, elasticsearch, dynamodb)"
+	DatabaseFlagUsage       = "Database type (postgres, postgres-json, mysql, mariadb, tidb, mongodb, sqlite, sqlserver, oracle, elasticsearch, dynamodb)"
 	FieldsFlagUsage         = "Comma-separated list of fields (ex: name:string,age:int)"
 	InterfaceOnlyFlagUsage  = "Generate interfaces only"
 	ImplementationFlagUsage = "Generate implementation only"
@@ -31,29 +31,108 @@ const (
 	DBPostgres      = "postgres"
 	DBPostgresJSON  = "postgres-json"
 	DBMySQL         = "mysql"
+	DBMariaDB       = "mariadb"
+	DBTiDB          = "tidb"
 	DBMongoDB       = "mongodb"
 	DBSQLite        = "sqlite"
 	DBSQLServer     = "sqlserver"
+	DBOracle        = "oracle"
 	DBElasticsearch = "elasticsearch"
 	DBDynamoDB      = "dynamodb"
 )
 
+// SQLite driver constants. mattn is the cgo driver behind gorm.io/driver/sqlite;
+// modernc is a pure-Go port that builds with CGO_ENABLED=0.
+const (
+	SQLiteDriverMattn   = "mattn"
+	SQLiteDriverModernc = "modernc"
+)
+
+// ValidSQLiteDrivers contains the supported values of --sqlite-driver.
+var ValidSQLiteDrivers = []string{SQLiteDriverMattn, SQLiteDriverModernc}
+
+// ID strategies of database.id_strategy in MongoDB projects. sequence
+// allocates numeric IDs from the counters collection; objectid identifies
+// entities by the ObjectID of their documents, as a hex string.
+const (
+	IDStrategySequence = "sequence"
+	IDStrategyObjectID = "objectid"
+)
+
+// ValidIDStrategies contains the supported values of database.id_strategy.
+var ValidIDStrategies = []string{IDStrategySequence, IDStrategyObjectID}
+
+// Authentication modes of init --auth-mode. jwt signs its own tokens with
+// JWT_SECRET, api-key checks keys stored hashed in the database and oidc
+// verifies the tokens of an external OpenID Connect issuer.
+const (
+	AuthModeJWT    = "jwt"
+	AuthModeAPIKey = "api-key"
+	AuthModeOIDC   = "oidc"
+)
+
+// ValidAuthModes contains the supported values of --auth-mode.
+var ValidAuthModes = []string{AuthModeJWT, AuthModeAPIKey, AuthModeOIDC}
+
 // ValidDatabases contains the list of supported database types for the CLI.
 var ValidDatabases = []string{
-	DBPostgres, DBPostgresJSON, DBMySQL, DBMongoDB, DBSQLite,
-	DBSQLServer, DBElasticsearch, DBDynamoDB,
+	DBPostgres, DBPostgresJSON, DBMySQL, DBMariaDB, DBTiDB, DBMongoDB,
+	DBSQLite, DBSQLServer, DBOracle, DBElasticsearch, DBDynamoDB,
 }
 
 // Handler/Protocol constants.
 const (
-	HandlerHTTP   = "http"
-	HandlerGRPC   = "grpc"
-	HandlerCLI    = "cli"
-	HandlerWorker = "worker"
+	HandlerHTTP      = "http"
+	HandlerGRPC      = "grpc"
+	HandlerCLI       = "cli"
+	HandlerWorker    = "worker"
+	HandlerWebSocket = "websocket"
+	HandlerCron      = "cron"
 )
 
 // ValidHandlers contains the list of supported handler types for the CLI.
-var ValidHandlers = []string{HandlerHTTP, HandlerGRPC, HandlerCLI, HandlerWorker}
+var ValidHandlers = []string{HandlerHTTP, HandlerGRPC, HandlerCLI, HandlerWorker, HandlerWebSocket, HandlerCron}
+
+// HTTP routers the generated middleware integrates with.
+const (
+	RouterMux = "mux" // github.com/gorilla/mux, used by the generated server
+	RouterChi = "chi" // github.com/go-chi/chi/v5
+)
+
+// ValidRouters contains the supported HTTP routers.
+var ValidRouters = []string{RouterMux, RouterChi}
+
+// Dependency injection styles.
+const (
+	DIManual  = "manual"
+	DIWire    = "wire"
+	DIFx      = "fx"
+	DIModular = "modular"
+)
+
+// ValidDIStyles contains the dependency injection styles the generator supports.
+var ValidDIStyles = []string{DIManual, DIWire, DIFx, DIModular}
+
+// Lifetimes of the components of a feature in a modular DI container.
+const (
+	DILifetimeSingleton = "singleton" // built when the container is created
+	DILifetimeLazy      = "lazy"      // built on first use
+)
+
+// ValidDILifetimes contains the supported modular DI lifetimes.
+var ValidDILifetimes = []string{DILifetimeSingleton, DILifetimeLazy}
+
+// Feature layers selectable with --skip and --only.
+const (
+	LayerDomain     = "domain"
+	LayerUseCase    = "usecase"
+	LayerRepository = "repository"
+	LayerHandler    = "handler"
+	LayerMessages   = "messages"
+)
+
+// ValidFeatureLayers contains the layers goca feature generates, in order.
+var ValidFeatureLayers = []string{LayerDomain, LayerUseCase, LayerRepository, LayerHandler, LayerMessages}
 
 // Operation constants.
 const (
@@ -62,6 +141,13 @@ const (
 	OpUpdate = "update"
 	OpDelete = "delete"
 	OpList   = "list"
+	// OpCount and OpSearch are optional: they are not part of the default
+	// operations.
+	OpCount  = "count"
+	OpSearch = "search"
+	// OpHistory is the audit log route of --audit entities; it is not a use
+	// case operation.
+	OpHistory = "history"
 )
 
 // Default operation combinations.
@@ -72,7 +158,7 @@ const (
 )
 
 // ValidOperations contains the list of supported CRUD operations.
-var ValidOperations = []string{OpCreate, OpRead, OpUpdate, OpDelete, OpList}
+var ValidOperations = []string{OpCreate, OpRead, OpUpdate, OpDelete, OpList, OpCount, OpSearch}
 
 // API Types constants.
 const (
@@ -108,6 +194,109 @@ const (
 	FieldInterface = "interface{}"
 )
 
+// Field DSL keywords for composite columns. json and jsonb both map to
+// FieldJSONType, which GORM stores as jsonb on PostgreSQL and JSON elsewhere;
+// struct{...} declares a nested struct embedded into the entity's table.
+const (
+	FieldJSON       = "json"
+	FieldJSONB      = "jsonb"
+	FieldJSONType   = "datatypes.JSON"
+	FieldStructOpen = "struct{"
+)
+
+// Field DSL keywords for uploaded files. file and blob both map to
+// FieldFileType, the domain File holding the metadata of a file kept in
+// pkg/storage; its fields are embedded with the "<field>_" column prefix.
+const (
+	FieldFile     = "file"
+	FieldBlob     = "blob"
+	FieldFileType = "File"
+)
+
+// Storage backends of pkg/storage, selected with STORAGE_BACKEND.
+const (
+	StorageLocal = "local"
+	StorageS3    = "s3"
+	StorageGCS   = "gcs"
+)
+
+// DTO styles for nested struct fields.
+const (
+	DTOStyleNested = "nested"
+	DTOStyleFlat   = "flat"
+)
+
+// ValidDTOStyles contains the supported DTO styles for nested struct fields.
+var ValidDTOStyles = []string{DTOStyleNested, DTOStyleFlat}
+
+// Repository finder kinds, used as --fields modifiers (email:string:findby)
+// and --finders prefixes.
+const (
+	FinderOne    = "findby"
+	FinderAll    = "findallby"
+	FinderExists = "existsby"
+)
+
+// ValidFinderKinds contains the supported repository finder kinds.
+var ValidFinderKinds = []string{FinderOne, FinderAll, FinderExists}
+
+// EnumModifier declares the values a string field accepts as a --fields
+// modifier: status:string:enum=draft|published.
+const EnumModifier = "enum="
+
+// DefaultSeparator follows the type of a --fields definition with the value
+// the field takes when it is not given: status:string=active.
+const DefaultSeparator = "="
+
+// ComputedModifier declares a read-only field as its type or a --fields
+// modifier: full_name:computed, total:float64:computed.
+const ComputedModifier = "computed"
+
+// SensitiveModifier marks a --fields field whose value the access-log
+// middleware redacts from logged bodies: password:string:sensitive.
+const SensitiveModifier = "sensitive"
+
+// Repository aggregate kinds, generated for the numeric fields of an entity
+// as SumBy<Field> and MaxBy<Field>.
+const (
+	AggregateSum = "sumby"
+	AggregateMax = "maxby"
+)
+
+// SearchCriteria is the kind of the Search(ctx, <Entity>Criteria) repository
+// method generated for entities with filterable fields.
+const SearchCriteria = "criteria"
+
+// HTTP response formats (generation.response_format in .goca.yaml).
+const (
+	ResponseFormatRaw      = "raw"
+	ResponseFormatEnvelope = "envelope"
+	ResponseFormatProblem  = "problem"
+)
+
+// ValidResponseFormats contains the supported HTTP response formats.
+var ValidResponseFormats = []string{ResponseFormatRaw, ResponseFormatEnvelope, ResponseFormatProblem}
+
+// Generated code style options (generation.style in .goca.yaml). Unset options
+// keep the generators' own output.
+const (
+	ReceiverNamesSingle      = "single"
+	ReceiverNamesAbbreviated = "abbreviated"
+	CommentDensityStandard   = "standard"
+	CommentDensityMinimal    = "minimal"
+	ErrorStringsLowercase    = "lowercase"
+	ErrorStringsCapitalized  = "capitalized"
+)
+
+// ValidReceiverNames contains the supported receiver naming styles.
+var ValidReceiverNames = []string{ReceiverNamesSingle, ReceiverNamesAbbreviated}
+
+// ValidCommentDensities contains the supported comment densities.
+var ValidCommentDensities = []string{CommentDensityStandard, CommentDensityMinimal}
+
+// ValidErrorStrings contains the supported error string capitalizations.
+var ValidErrorStrings = []string{ErrorStringsLowercase, ErrorStringsCapitalized}
+
 // ValidFieldTypes contains the list of supported basic field types for entities.
 // Note: Complex types like slices, pointers, maps, channels, functions, and custom types
 // are validated dynamically by the field validator.
@@ -146,8 +335,11 @@ const (
 	DirGRPC       = "grpc"
 	DirCLI        = "cli"
 	DirWorker     = "worker"
+	DirScheduler  = "scheduler"
 	DirSOAP       = "soap"
+	DirWebSocket  = "ws"
 	DirMessages   = "messages"
+	DirPresenter  = "presenter"
 	DirInterfaces = "interfaces"
 	DirPkg        = "pkg"
 	DirConfig     = "config"
@@ -175,7 +367,7 @@ const (
 
 // Error messages.
 const (
-	ErrInvalidDatabase    = "invalid database. Options: postgres, mysql, mongodb, sqlite"
+	ErrInvalidDatabase    = "invalid database. Options: postgres, mysql, mariadb, tidb, mongodb, sqlite, sqlserver, oracle"
 	ErrInvalidHandler     = "invalid handler. Options: http, grpc, cli, worker"
 	ErrInvalidOperation   = "invalid operation. Options: create, read, update, delete, list"
 	ErrInvalidFieldType   = "invalid field type"
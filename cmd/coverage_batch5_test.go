@@ -215,7 +215,7 @@ func TestUpdateDIContainer_NoExistingFile(t *testing.T) {
 	os.Chdir(t.TempDir())
 
 	sm := NewSafetyManager(true, false, false)
-	updateDIContainer("Product", "postgres", false, sm)
+	updateDIContainer("Product", "postgres", DIManual, false, sm)
 	// Should handle gracefully when no di/container.go exists
 }
 
@@ -228,7 +228,7 @@ func TestAutoIntegrateFeature_NoMainGo(t *testing.T) {
 	os.Chdir(t.TempDir())
 
 	sm := NewSafetyManager(true, false, false)
-	autoIntegrateFeature("Product", "http", "postgres", false, sm)
+	autoIntegrateFeature("Product", "http", "", "postgres", DIManual, false, sm)
 	// Should handle gracefully when main.go doesnt exist
 }
 
@@ -247,7 +247,7 @@ func TestUpdateMainRoutes_NoMainGo(t *testing.T) {
 	defer os.Chdir(origDir)
 	os.Chdir(t.TempDir())
 
-	updateMainRoutes("Product") // Should handle missing main.go gracefully
+	updateMainRoutes("Product", "v1") // Should handle missing main.go gracefully
 }
 
 // --- Config integration tests ---
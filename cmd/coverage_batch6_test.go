@@ -294,7 +294,7 @@ func main() {
 `
 	os.WriteFile("main.go", []byte(mainContent), 0o644)
 
-	setupMainGoWithFeature("main.go", "Product", "github.com/test/proj", mainContent)
+	setupMainGoWithFeature("main.go", "Product", "github.com/test/proj", "v1", mainContent)
 }
 
 func TestWireFeatureIntoMainGo_Coverage(t *testing.T) {
@@ -326,7 +326,7 @@ func main() {
 	os.WriteFile("main.go", []byte(mainContent), 0o644)
 
 	// First wiring run adds the container scaffold + Product routes.
-	err := wireFeatureIntoMainGo("main.go", "Product", "github.com/test/proj", mainContent)
+	err := wireFeatureIntoMainGo("main.go", "Product", "github.com/test/proj", "v1", mainContent)
 	assert.NoError(t, err)
 
 	out, _ := os.ReadFile("main.go")
@@ -337,7 +337,7 @@ func main() {
 	assert.Contains(t, got, "\"github.com/test/proj/internal/di\"")
 
 	// Idempotency: re-running must not duplicate the container or routes.
-	err = wireFeatureIntoMainGo("main.go", "Product", "github.com/test/proj", got)
+	err = wireFeatureIntoMainGo("main.go", "Product", "github.com/test/proj", "v1", got)
 	assert.NoError(t, err)
 	out2, _ := os.ReadFile("main.go")
 	got2 := string(out2)
@@ -1262,7 +1262,7 @@ func TestPrintFeatureStructure_MultipleHandlers(t *testing.T) {
 	cleanup := ensureTestUI(t)
 	defer cleanup()
 
-	printFeatureStructure("order", "http,grpc,cli,worker,soap")
+	printFeatureStructure("order", "http,grpc,cli,worker,soap", allFeatureLayers())
 }
 
 // --- Misc: extractImportSection ---
@@ -0,0 +1,598 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// defaultReplicaDSNEnv is the environment variable ReadReplica reads the DSN
+// of the read replica from unless features.cqrs.replica_dsn_env names another.
+const defaultReplicaDSNEnv = "DB_REPLICA_DSN"
+
+// envVarNamePattern matches the names features.cqrs.replica_dsn_env accepts.
+var envVarNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// cqrsCommandOps and cqrsQueryOps split the operations of a feature between
+// its command and query services.
+var (
+	cqrsCommandOps = []string{OpCreate, OpUpdate, OpDelete}
+	cqrsQueryOps   = []string{OpRead, OpList}
+)
+
+// validateCQRS reports why goca feature --cqrs cannot split the use case of a
+// feature: the read replica is a second GORM connection, and the tenant scope
+// of multi-tenant use cases is not split between the services.
+func validateCQRS(database string, multiTenant bool) error {
+	if !isGormDatabase(database) {
+		return fmt.Errorf("--cqrs reads from a GORM replica and does not support %s", database)
+	}
+	if multiTenant {
+		return errors.New("--cqrs cannot be combined with --multi-tenant")
+	}
+	return nil
+}
+
+// createCQRSFiles writes the files the CQRS features of a project share:
+// internal/repository/replica.go, which opens the read replica, and
+// internal/domain/events.go, the kinds of the domain events the command
+// services publish. Like the other shared files, they are only rewritten with
+// --force.
+func createCQRSFiles(database string, cfg CQRSConfig, sm ...*SafetyManager) {
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+	env := cfg.ReplicaDSNEnv
+	if env == "" {
+		env = defaultReplicaDSNEnv
+	}
+
+	replica := filepath.Join(DirInternal, DirRepository, "replica.go")
+	if _, err := os.Stat(replica); err != nil || force {
+		driverImport, driverPackage := gormDriver(database, projectSQLiteDriver())
+		content := fmt.Sprintf(replicaTemplate, driverImport, env, driverPackage)
+		if err := writeGoFile(replica, content, sm...); err != nil {
+			ui.Warning(fmt.Sprintf("Error writing read replica: %v", err))
+		}
+	}
+
+	events := filepath.Join(DirInternal, DirDomain, "events.go")
+	if _, err := os.Stat(events); err != nil || force {
+		if err := writeGoFile(events, domainEventsTemplate, sm...); err != nil {
+			ui.Warning(fmt.Sprintf("Error writing domain events: %v", err))
+		}
+	}
+}
+
+// generateCQRS splits the use case of a generated feature into command and
+// query services (goca feature --cqrs). The repository interface is
+// segregated so the queries depend on <Entity>Reader alone, which
+// New<Entity>Reader implements on the read replica. The commands publish a
+// domain.<Entity>Event after each write; with projection the queries are
+// served by an in-memory read model those events keep current. The DI
+// container builds the feature with New<Entity>CQRSUseCase once
+// internal/usecase/<entity>_cqrs.go exists.
+func generateCQRS(entity, fields, database, dtoStyle string, validation, projection bool, sm ...*SafetyManager) {
+	defer profileStep("cqrs", entity)()
+	if err := segregateRepositoryInterface(entity, sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Skipping the CQRS use cases: %v", err))
+		return
+	}
+
+	importPath := getImportPath(getModuleName())
+	entityLower := strings.ToLower(entity)
+	wrapErrors := errorWrappingEnabled()
+	if wrapErrors {
+		generateAppErrors(sm...)
+	}
+	files := []struct{ path, content string }{
+		{filepath.Join(DirInternal, DirRepository, entityLower+"_reader.go"), buildEntityReader(entity, database)},
+		{filepath.Join(DirInternal, DirDomain, entityLower+"_events.go"), buildEntityEvent(entity)},
+		{filepath.Join(DirInternal, DirUseCase, entityLower+"_cqrs.go"), buildCQRSUseCase(entity, importPath, projection)},
+		{filepath.Join(DirInternal, DirUseCase, entityLower+"_commands.go"), buildCommandService(entity, importPath, fields, dtoStyle, validation, wrapErrors)},
+		{filepath.Join(DirInternal, DirUseCase, entityLower+"_queries.go"), buildQueryService(entity, importPath, wrapErrors)},
+	}
+	if projection {
+		files = append(files, struct{ path, content string }{
+			filepath.Join(DirInternal, DirUseCase, entityLower+"_projection.go"), buildProjection(entity, importPath),
+		})
+	}
+	for _, f := range files {
+		if err := writeGoFile(f.path, f.content, sm...); err != nil {
+			ui.Error(fmt.Sprintf("Error writing %s: %v", f.path, err))
+		}
+	}
+	generateMessageErrorKinds(sm...)
+}
+
+// segregateRepositoryInterface splits a combined <Entity>Repository into
+// <Entity>Reader, <Entity>Writer and, for the finders and aggregates,
+// <Entity>Searcher, the interfaces generation.interfaces.segregated
+// generates, and makes <Entity>Repository embed them. Interfaces that are
+// already segregated are left alone.
+func segregateRepositoryInterface(entity string, sm ...*SafetyManager) error {
+	path := findTypeFile(filepath.Join(DirInternal, DirRepository), entity+"Repository")
+	if path == "" {
+		return fmt.Errorf("no %sRepository interface found", entity)
+	}
+	defer lockSharedFile(path)()
+	s, err := parseGoSource(path)
+	if err != nil {
+		return err
+	}
+	if findInterfaceType(s.file, entity+"Reader") != nil {
+		return nil
+	}
+	gen, ts := findTypeSpec(s.file, entity+"Repository")
+	it, ok := ts.Type.(*ast.InterfaceType)
+	if !ok || gen.Lparen.IsValid() {
+		return fmt.Errorf("%sRepository in %s is not a standalone interface", entity, path)
+	}
+
+	var reader, writer, searcher, rest strings.Builder
+	for _, m := range it.Methods.List {
+		start := m.Pos()
+		if m.Doc != nil {
+			start = m.Doc.Pos()
+		}
+		text := string(s.src[s.lineStart(s.offset(start)) : s.lineEnd(s.offset(m.End()))+1])
+		name := ""
+		if len(m.Names) == 1 {
+			name = m.Names[0].Name
+		}
+		switch {
+		case name == "FindByID" || name == "ExistsByID" || name == "FindAll" || name == "FindAllPaged" || name == "Count":
+			reader.WriteString(text)
+		case name == "Save" || name == "Update" || name == "Delete" || strings.HasSuffix(name, "WithTx"):
+			writer.WriteString(text)
+		case name == "" || strings.Contains(text, entity+"Repository"):
+			// Embedded interfaces and the tenant scope stay in the combination.
+			rest.WriteString(text)
+		default:
+			searcher.WriteString(text)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %sReader loads and counts %s entities.\n", entity, entity)
+	fmt.Fprintf(&b, "type %sReader interface {\n%s}\n\n", entity, reader.String())
+	fmt.Fprintf(&b, "// %sWriter persists %s entities.\n", entity, entity)
+	fmt.Fprintf(&b, "type %sWriter interface {\n%s}\n\n", entity, writer.String())
+	if searcher.Len() > 0 {
+		writeSearcherInterface(&b, entity, searcher.String())
+	}
+	fmt.Fprintf(&b, "// %sRepository combines every %s repository contract.\n", entity, entity)
+	fmt.Fprintf(&b, "type %sRepository interface {\n\t%sReader\n\t%sWriter\n", entity, entity, entity)
+	if searcher.Len() > 0 {
+		fmt.Fprintf(&b, "\t%sSearcher\n", entity)
+	}
+	b.WriteString(rest.String() + "}")
+
+	start := gen.Pos()
+	if gen.Doc != nil {
+		start = gen.Doc.Pos()
+	}
+	s.edits = append(s.edits, sourceEdit{start: s.offset(start), end: s.offset(gen.End()), text: b.String()})
+	_, err = writeEditedGoFile(s, nil, sm...)
+	return err
+}
+
+// buildEntityReader returns internal/repository/<entity>_reader.go: the
+// implementation of <Entity>Reader on the read replica.
+func buildEntityReader(entity, database string) string {
+	var b strings.Builder
+	b.WriteString("package repository\n\n")
+	b.WriteString("import \"gorm.io/gorm\"\n\n")
+	fmt.Fprintf(&b, "// New%sReader returns the %s queries on the read replica of\n", entity, entity)
+	fmt.Fprintf(&b, "// primary (see ReadReplica). Writes stay on the %sRepository of primary.\n", entity)
+	fmt.Fprintf(&b, "func New%sReader(primary *gorm.DB) %sReader {\n", entity, entity)
+	fmt.Fprintf(&b, "\treturn New%s%sRepository(ReadReplica(primary))\n", repoConstructorPrefix(database), entity)
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// buildEntityEvent returns internal/domain/<entity>_events.go: the event the
+// command service of the entity publishes.
+func buildEntityEvent(entity string) string {
+	var b strings.Builder
+	b.WriteString("package domain\n\n")
+	fmt.Fprintf(&b, "// %sEvent reports a change a command made to a %s.\n", entity, entity)
+	fmt.Fprintf(&b, "type %sEvent struct {\n", entity)
+	b.WriteString("\tKind EventKind\n")
+	b.WriteString("\tID   int\n")
+	fmt.Fprintf(&b, "\t%s %s // the state after the change, zero for EventDeleted\n", entity, entity)
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// buildCQRSUseCase returns internal/usecase/<entity>_cqrs.go: the command and
+// query interfaces and the <Entity>UseCase composed of both.
+func buildCQRSUseCase(entity, importPath string, projection bool) string {
+	var b strings.Builder
+	b.WriteString("package usecase\n\n")
+	fmt.Fprintf(&b, "import (\n\t\"%s/internal/domain\"\n\t\"%s/internal/repository\"\n)\n\n", importPath, importPath)
+
+	fmt.Fprintf(&b, "// %sCommands are the %s use cases that change state. They run on\n", entity, entity)
+	fmt.Fprintf(&b, "// the primary database and publish a domain.%sEvent after each change.\n", entity)
+	fmt.Fprintf(&b, "type %sCommands interface {\n", entity)
+	fmt.Fprintf(&b, "\tCreate%s(input Create%sInput) (Create%sOutput, error)\n", entity, entity, entity)
+	fmt.Fprintf(&b, "\tUpdate%s(id int, input Update%sInput) error\n", entity, entity)
+	fmt.Fprintf(&b, "\tDelete%s(id int) error\n", entity)
+	b.WriteString("\t// Subscribe registers fn to be called with every event of the commands.\n")
+	fmt.Fprintf(&b, "\tSubscribe(fn func(domain.%sEvent))\n", entity)
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// %sQueries are the %s use cases that only read. Their results may\n", entity, entity)
+	b.WriteString("// lag behind the commands while the read replica catches up.\n")
+	fmt.Fprintf(&b, "type %sQueries interface {\n", entity)
+	fmt.Fprintf(&b, "\tGet%s(id int) (*domain.%s, error)\n", entity, entity)
+	fmt.Fprintf(&b, "\tList%s() (List%sOutput, error)\n", toPlural(entity), entity)
+	b.WriteString("}\n\n")
+
+	typeName := strings.ToLower(entity[:1]) + entity[1:] + "CQRSUseCase"
+	fmt.Fprintf(&b, "// %s implements %sUseCase with separate command and query\n", typeName, entity)
+	b.WriteString("// services.\n")
+	fmt.Fprintf(&b, "type %s struct {\n\t%sCommands\n\t%sQueries\n}\n\n", typeName, entity, entity)
+
+	fmt.Fprintf(&b, "// New%sCQRSUseCase serves the %s commands from repo, on the primary\n", entity, entity)
+	if projection {
+		b.WriteString("// database, and the queries from an in-memory projection loaded from reader,\n")
+		fmt.Fprintf(&b, "// usually repository.New%sReader, and kept current by the events of the\n", entity)
+		b.WriteString("// commands.\n")
+	} else {
+		fmt.Fprintf(&b, "// database, and the queries from reader, usually repository.New%sReader.\n", entity)
+	}
+	fmt.Fprintf(&b, "func New%sCQRSUseCase(repo repository.%sRepository, reader repository.%sReader) %sUseCase {\n", entity, entity, entity, entity)
+	fmt.Fprintf(&b, "\tcommands := New%sCommandService(repo)\n", entity)
+	if projection {
+		fmt.Fprintf(&b, "\tprojection := New%sProjection(reader)\n", entity)
+		b.WriteString("\tcommands.Subscribe(projection.Apply)\n")
+		fmt.Fprintf(&b, "\treturn &%s{%sCommands: commands, %sQueries: New%sQueryService(projection)}\n", typeName, entity, entity, entity)
+	} else {
+		fmt.Fprintf(&b, "\treturn &%s{%sCommands: commands, %sQueries: New%sQueryService(reader)}\n", typeName, entity, entity, entity)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// buildCommandService returns internal/usecase/<entity>_commands.go. The
+// methods are those of the combined service; the writes go through a
+// repository decorator publishing the events.
+func buildCommandService(entity, importPath, fields, dtoStyle string, validation, wrapErrors bool) string {
+	entityLower := strings.ToLower(entity)
+	serviceName := strings.ToLower(entity[:1]) + entity[1:] + "CommandService"
+	recv := string(serviceName[0])
+	eventRepo := strings.ToLower(entity[:1]) + entity[1:] + "EventRepository"
+
+	var b strings.Builder
+	b.WriteString("package usecase\n\n")
+	b.WriteString("import (\n\t\"fmt\"\n\t\"sync\"\n\n")
+	fmt.Fprintf(&b, "\t\"%s/internal/domain\"\n\t\"%s/internal/messages\"\n\t\"%s/internal/repository\"\n", importPath, importPath, importPath)
+	if wrapErrors {
+		fmt.Fprintf(&b, "\t\"%s/%s/%s\"\n", importPath, DirPkg, dirAppErrors)
+	}
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(&b, "// %s runs the %s commands on the primary database.\n", serviceName, entity)
+	fmt.Fprintf(&b, "type %s struct {\n", serviceName)
+	fmt.Fprintf(&b, "\trepo repository.%sRepository\n\n", entity)
+	b.WriteString("\tmu          sync.RWMutex\n")
+	fmt.Fprintf(&b, "\tsubscribers []func(domain.%sEvent)\n", entity)
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// New%sCommandService returns the %s commands persisting through\n", entity, entity)
+	fmt.Fprintf(&b, "// repo. Each successful write publishes a domain.%sEvent.\n", entity)
+	fmt.Fprintf(&b, "func New%sCommandService(repo repository.%sRepository) %sCommands {\n", entity, entity, entity)
+	fmt.Fprintf(&b, "\t%s := &%s{}\n", recv, serviceName)
+	fmt.Fprintf(&b, "\t%s.repo = %s{%sRepository: repo, publish: %s.publish}\n", recv, eventRepo, entity, recv)
+	fmt.Fprintf(&b, "\treturn %s\n", recv)
+	b.WriteString("}\n\n")
+
+	b.WriteString("// Subscribe registers fn to be called with every event of the commands. fn\n")
+	b.WriteString("// is called synchronously, after the write, and must not block.\n")
+	fmt.Fprintf(&b, "func (%s *%s) Subscribe(fn func(domain.%sEvent)) {\n", recv, serviceName, entity)
+	fmt.Fprintf(&b, "\t%s.mu.Lock()\n\tdefer %s.mu.Unlock()\n", recv, recv)
+	fmt.Fprintf(&b, "\t%s.subscribers = append(%s.subscribers, fn)\n", recv, recv)
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "func (%s *%s) publish(e domain.%sEvent) {\n", recv, serviceName, entity)
+	fmt.Fprintf(&b, "\t%s.mu.RLock()\n\tdefer %s.mu.RUnlock()\n", recv, recv)
+	fmt.Fprintf(&b, "\tfor _, fn := range %s.subscribers {\n\t\tfn(e)\n\t}\n", recv)
+	b.WriteString("}\n\n")
+
+	for _, op := range cqrsCommandOps {
+		switch op {
+		case OpCreate:
+			if fields != "" {
+				generateCreateMethodWithFields(&b, serviceName, entity, fields, dtoStyle, validation, true, wrapErrors)
+			} else {
+				generateCreateMethod(&b, serviceName, entity, true, wrapErrors)
+			}
+		case OpUpdate:
+			if fields != "" {
+				generateUpdateMethodWithFields(&b, serviceName, entity, fields, dtoStyle, wrapErrors)
+			} else {
+				generateUpdateMethod(&b, serviceName, entity, wrapErrors)
+			}
+		case OpDelete:
+			generateDeleteMethod(&b, serviceName, entity, wrapErrors)
+		}
+	}
+
+	fmt.Fprintf(&b, "// %s publishes a domain.%sEvent after each successful\n", eventRepo, entity)
+	b.WriteString("// write of the repository it wraps.\n")
+	fmt.Fprintf(&b, "type %s struct {\n", eventRepo)
+	fmt.Fprintf(&b, "\trepository.%sRepository\n", entity)
+	fmt.Fprintf(&b, "\tpublish func(domain.%sEvent)\n", entity)
+	b.WriteString("}\n\n")
+	for _, w := range []struct{ method, kind string }{{"Save", "EventCreated"}, {"Update", "EventUpdated"}} {
+		fmt.Fprintf(&b, "func (r %s) %s(%s *domain.%s) error {\n", eventRepo, w.method, entityLower, entity)
+		fmt.Fprintf(&b, "\tif err := r.%sRepository.%s(%s); err != nil {\n\t\treturn err\n\t}\n", entity, w.method, entityLower)
+		fmt.Fprintf(&b, "\tr.publish(domain.%sEvent{Kind: domain.%s, ID: int(%s.ID), %s: *%s})\n", entity, w.kind, entityLower, entity, entityLower)
+		b.WriteString("\treturn nil\n}\n\n")
+	}
+	fmt.Fprintf(&b, "func (r %s) Delete(id int) error {\n", eventRepo)
+	fmt.Fprintf(&b, "\tif err := r.%sRepository.Delete(id); err != nil {\n\t\treturn err\n\t}\n", entity)
+	fmt.Fprintf(&b, "\tr.publish(domain.%sEvent{Kind: domain.EventDeleted, ID: id})\n", entity)
+	b.WriteString("\treturn nil\n}\n")
+
+	content, err := syncImports([]byte(b.String()), "fmt", "sync", importPath+"/internal/messages")
+	if err != nil {
+		return b.String()
+	}
+	return string(content)
+}
+
+// buildQueryService returns internal/usecase/<entity>_queries.go.
+func buildQueryService(entity, importPath string, wrapErrors bool) string {
+	serviceName := strings.ToLower(entity[:1]) + entity[1:] + "QueryService"
+
+	var b strings.Builder
+	b.WriteString("package usecase\n\n")
+	fmt.Fprintf(&b, "import (\n\t\"%s/internal/domain\"\n\t\"%s/internal/messages\"\n\t\"%s/internal/repository\"\n", importPath, importPath, importPath)
+	if wrapErrors {
+		fmt.Fprintf(&b, "\t\"%s/%s/%s\"\n", importPath, DirPkg, dirAppErrors)
+	}
+	b.WriteString(")\n\n")
+	fmt.Fprintf(&b, "// %s runs the %s queries on a reader that cannot write.\n", serviceName, entity)
+	fmt.Fprintf(&b, "type %s struct {\n\trepo repository.%sReader\n}\n\n", serviceName, entity)
+	fmt.Fprintf(&b, "// New%sQueryService returns the %s queries reading from repo.\n", entity, entity)
+	fmt.Fprintf(&b, "func New%sQueryService(repo repository.%sReader) %sQueries {\n", entity, entity, entity)
+	fmt.Fprintf(&b, "\treturn &%s{repo: repo}\n", serviceName)
+	b.WriteString("}\n\n")
+	for _, op := range cqrsQueryOps {
+		switch op {
+		case OpRead:
+			generateGetMethod(&b, serviceName, entity, wrapErrors)
+		case OpList:
+			generateListMethod(&b, serviceName, entity, wrapErrors)
+		}
+	}
+	return b.String()
+}
+
+// buildProjection returns internal/usecase/<entity>_projection.go: the
+// in-memory read model of goca feature --cqrs-projection.
+func buildProjection(entity, importPath string) string {
+	return fmt.Sprintf(projectionTemplate, importPath, entity, toPlural(strings.ToLower(entity)))
+}
+
+// hasCQRSUseCase reports whether the use case of the feature was split into
+// command and query services (internal/usecase/<entity>_cqrs.go).
+func hasCQRSUseCase(feature string) bool {
+	path := filepath.Join(DirInternal, DirUseCase, strings.ToLower(feature)+"_cqrs.go")
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return !info.IsDir()
+}
+
+// replicaTemplate is internal/repository/replica.go; the verbs are the GORM
+// driver import, the environment variable and the driver package.
+const replicaTemplate = `package repository
+
+import (
+	"log"
+	"os"
+	"sync"
+
+	%[1]q
+	"gorm.io/gorm"
+)
+
+// ReplicaDSNEnv names the environment variable holding the DSN of the read
+// replica the queries of the CQRS use cases run on.
+const ReplicaDSNEnv = %[2]q
+
+var (
+	replicaOnce sync.Once
+	replica     *gorm.DB
+)
+
+// ReadReplica returns the read replica, opened from ReplicaDSNEnv on first
+// use, or primary when the variable is unset or the replica cannot be opened.
+// Reads from a replica may lag behind the writes made on primary.
+func ReadReplica(primary *gorm.DB) *gorm.DB {
+	replicaOnce.Do(func() {
+		dsn := os.Getenv(ReplicaDSNEnv)
+		if dsn == "" {
+			return
+		}
+		db, err := gorm.Open(%[3]s.Open(dsn), &gorm.Config{})
+		if err != nil {
+			log.Printf("Read replica unavailable, reading from the primary database: %%v", err)
+			return
+		}
+		replica = db
+	})
+	if replica == nil {
+		return primary
+	}
+	return replica
+}
+`
+
+// domainEventsTemplate is internal/domain/events.go.
+const domainEventsTemplate = `package domain
+
+// EventKind says how a command changed an entity.
+type EventKind string
+
+const (
+	EventCreated EventKind = "created"
+	EventUpdated EventKind = "updated"
+	EventDeleted EventKind = "deleted"
+)
+`
+
+// projectionTemplate is internal/usecase/<entity>_projection.go; the verbs
+// are the import path, the entity and its lowercase name.
+const projectionTemplate = `package usecase
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+
+	"%[1]s/internal/domain"
+	"%[1]s/internal/repository"
+)
+
+// %[2]sProjection is an in-memory read model of the %[2]s entities. It is
+// loaded from its source on first use and then kept current by Apply, which
+// the command service calls with each of its events, so the queries do not
+// wait for the read replica to catch up. Count with a non-empty spec is
+// passed to the source.
+type %[2]sProjection struct {
+	source repository.%[2]sReader
+
+	mu      sync.RWMutex
+	loaded  bool
+	items   map[int]domain.%[2]s
+	deleted map[int]bool // deleted before the projection was loaded
+}
+
+var _ repository.%[2]sReader = (*%[2]sProjection)(nil)
+
+// New%[2]sProjection returns an empty projection of source.
+func New%[2]sProjection(source repository.%[2]sReader) *%[2]sProjection {
+	return &%[2]sProjection{
+		source:  source,
+		items:   make(map[int]domain.%[2]s),
+		deleted: make(map[int]bool),
+	}
+}
+
+// Apply updates the projection with an event of the %[2]s commands.
+func (p *%[2]sProjection) Apply(e domain.%[2]sEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if e.Kind == domain.EventDeleted {
+		delete(p.items, e.ID)
+		if !p.loaded {
+			p.deleted[e.ID] = true
+		}
+		return
+	}
+	p.items[e.ID] = e.%[2]s
+}
+
+// load fills the projection from source once. The entities changed by events
+// applied in the meantime keep their newer state.
+func (p *%[2]sProjection) load() error {
+	p.mu.RLock()
+	loaded := p.loaded
+	p.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	%[3]s, err := p.source.FindAll()
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.loaded {
+		return nil
+	}
+	for _, item := range %[3]s {
+		id := int(item.ID)
+		if _, ok := p.items[id]; !ok && !p.deleted[id] {
+			p.items[id] = item
+		}
+	}
+	p.loaded, p.deleted = true, nil
+	return nil
+}
+
+func (p *%[2]sProjection) FindByID(id int) (*domain.%[2]s, error) {
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	item, ok := p.items[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &item, nil
+}
+
+func (p *%[2]sProjection) ExistsByID(ctx context.Context, id int) (bool, error) {
+	if err := p.load(); err != nil {
+		return false, err
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	_, ok := p.items[id]
+	return ok, nil
+}
+
+func (p *%[2]sProjection) FindAll() ([]domain.%[2]s, error) {
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+	p.mu.RLock()
+	%[3]s := make([]domain.%[2]s, 0, len(p.items))
+	for _, item := range p.items {
+		%[3]s = append(%[3]s, item)
+	}
+	p.mu.RUnlock()
+	sort.Slice(%[3]s, func(i, j int) bool { return %[3]s[i].ID < %[3]s[j].ID })
+	return %[3]s, nil
+}
+
+func (p *%[2]sProjection) FindAllPaged(ctx context.Context, limit, offset int) ([]domain.%[2]s, int64, error) {
+	%[3]s, err := p.FindAll()
+	if err != nil {
+		return nil, 0, err
+	}
+	total := int64(len(%[3]s))
+	if offset >= len(%[3]s) {
+		return []domain.%[2]s{}, total, nil
+	}
+	%[3]s = %[3]s[offset:]
+	if limit < len(%[3]s) {
+		%[3]s = %[3]s[:limit]
+	}
+	return %[3]s, total, nil
+}
+
+func (p *%[2]sProjection) Count(ctx context.Context, spec repository.%[2]sSpec) (int64, error) {
+	if len(spec.Where) > 0 {
+		return p.source.Count(ctx, spec)
+	}
+	if err := p.load(); err != nil {
+		return 0, err
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return int64(len(p.items)), nil
+}
+`
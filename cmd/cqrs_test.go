@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSegregateRepositoryInterface(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	sm := NewSafetyManager(false, true, false)
+	generateRepository("Product", "postgres", true, false, false, true, "Name:string:findby,Price:float64", sm)
+	require.NoError(t, segregateRepositoryInterface("Product", sm))
+	require.NoError(t, segregateRepositoryInterface("Product", sm))
+
+	src := readParsedGo(t, filepath.Join(DirInternal, DirRepository, "interfaces.go"))
+	assert.Contains(t, src, "type ProductReader interface {\n\tFindByID(id int) (*domain.Product, error)\n\tExistsByID(ctx context.Context, id int) (bool, error)\n\tFindAll() ([]domain.Product, error)\n\tFindAllPaged(ctx context.Context, limit, offset int) ([]domain.Product, int64, error)\n\tCount(ctx context.Context, spec ProductSpec) (int64, error)\n}")
+	assert.Contains(t, src, "type ProductWriter interface {\n\tSave(product *domain.Product) error\n\tUpdate(product *domain.Product) error\n\tDelete(id int) error\n\tSaveWithTx(")
+	assert.Contains(t, src, "type ProductSearcher interface {\n\tFindByName(name string) (*domain.Product, error)\n")
+	assert.Contains(t, src, "type ProductRepository interface {\n\tProductReader\n\tProductWriter\n\tProductSearcher\n}")
+	assert.Contains(t, src, "type ProductSpec struct")
+	assert.Error(t, segregateRepositoryInterface("Order", sm))
+}
+
+func TestGenerateCQRS(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	sm := NewSafetyManager(false, true, false)
+	fields := "Name:string,Price:float64"
+	generateRepository("Product", "postgres", false, false, false, false, fields, sm)
+	generateUseCaseWithFields("ProductUseCase", "Product", "create,read,update,delete,list", false, false, fields, "", sm)
+	createCQRSFiles(DBPostgres, CQRSConfig{ReplicaDSNEnv: "REPLICA_URL"}, sm)
+	generateCQRS("Product", fields, DBPostgres, "", false, true, sm)
+
+	replica := readParsedGo(t, filepath.Join(DirInternal, DirRepository, "replica.go"))
+	assert.Contains(t, replica, `const ReplicaDSNEnv = "REPLICA_URL"`)
+	assert.Contains(t, replica, "gorm.Open(postgres.Open(dsn), &gorm.Config{})")
+	reader := readParsedGo(t, filepath.Join(DirInternal, DirRepository, "product_reader.go"))
+	assert.Contains(t, reader, "return NewPostgresProductRepository(ReadReplica(primary))")
+	readParsedGo(t, filepath.Join(DirInternal, DirDomain, "events.go"))
+	assert.Contains(t, readParsedGo(t, filepath.Join(DirInternal, DirDomain, "product_events.go")), "Product Product // the state after the change")
+
+	cqrs := readParsedGo(t, filepath.Join(DirInternal, DirUseCase, "product_cqrs.go"))
+	assert.Contains(t, cqrs, "func NewProductCQRSUseCase(repo repository.ProductRepository, reader repository.ProductReader) ProductUseCase {")
+	assert.Contains(t, cqrs, "commands.Subscribe(projection.Apply)")
+	commands := readParsedGo(t, filepath.Join(DirInternal, DirUseCase, "product_commands.go"))
+	assert.Contains(t, commands, "func (p *productCommandService) CreateProduct(input CreateProductInput) (CreateProductOutput, error) {")
+	assert.Contains(t, commands, "r.publish(domain.ProductEvent{Kind: domain.EventUpdated, ID: int(product.ID), Product: *product})")
+	assert.Contains(t, commands, `return CreateProductOutput{}, fmt.Errorf("%w: %w", messages.ErrValidation, err)`)
+	queries := readParsedGo(t, filepath.Join(DirInternal, DirUseCase, "product_queries.go"))
+	assert.Contains(t, queries, "repo repository.ProductReader")
+	assert.NotContains(t, queries, "CreateProduct")
+	projection := readParsedGo(t, filepath.Join(DirInternal, DirUseCase, "product_projection.go"))
+	assert.Contains(t, projection, "var _ repository.ProductReader = (*ProductProjection)(nil)")
+
+	// The combined service is left for callers wanting a single database.
+	assert.FileExists(t, filepath.Join(DirInternal, DirUseCase, "product_service.go"))
+	assert.Equal(t, []string{
+		filepath.Join(DirInternal, DirUseCase, "product_commands.go"),
+		filepath.Join(DirInternal, DirUseCase, "product_service.go"),
+	}, findServiceFiles("Product"))
+
+	require.True(t, hasCQRSUseCase("Product"))
+	assert.False(t, hasCQRSUseCase("Order"))
+	assert.Equal(t, "usecase.NewProductCQRSUseCase(c.productRepo, repository.NewProductReader(c.db))", useCaseConstructor("Product"))
+
+	generateFeatureProviders(filepath.Join(DirInternal, "di"), providerFeature{Name: "Product"}, DBPostgres, DIWire, sm)
+	providers := readParsedGo(t, filepath.Join(DirInternal, "di", "product_providers.go"))
+	assert.Contains(t, providers, "func ProvideProductUseCase(db *gorm.DB, repo repository.ProductRepository) usecase.ProductUseCase {")
+}
+
+func TestGenerateCQRS_WithoutProjection(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	sm := NewSafetyManager(false, true, false)
+	generateRepository("Product", "mysql", false, false, false, false, "", sm)
+	generateUseCaseWithFields("ProductUseCase", "Product", "create,read,update,delete,list", false, false, "", "", sm)
+	generateCQRS("Product", "", DBMySQL, "", false, false, sm)
+
+	cqrs := readParsedGo(t, filepath.Join(DirInternal, DirUseCase, "product_cqrs.go"))
+	assert.Contains(t, cqrs, "ProductQueries: NewProductQueryService(reader)}")
+	assert.NoFileExists(t, filepath.Join(DirInternal, DirUseCase, "product_projection.go"))
+	readParsedGo(t, filepath.Join(DirInternal, DirUseCase, "product_commands.go"))
+}
+
+func TestValidateCQRS(t *testing.T) {
+	assert.NoError(t, validateCQRS(DBSQLite, false))
+	assert.ErrorContains(t, validateCQRS(DBMongoDB, false), "does not support mongodb")
+	assert.ErrorContains(t, validateCQRS(DBPostgres, true), "--multi-tenant")
+
+	cm := NewConfigManager()
+	cm.validateFeatures(&FeatureConfig{CQRS: CQRSConfig{ReplicaDSNEnv: "REPLICA-DSN"}})
+	require.Len(t, cm.GetErrors(), 1)
+	assert.Equal(t, "features.cqrs.replica_dsn_env", cm.GetErrors()[0].Field)
+}
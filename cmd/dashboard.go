@@ -0,0 +1,335 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// dirObservability holds the Prometheus and Grafana configuration of the
+// observability compose profile.
+const dirObservability = "observability"
+
+// grafanaPanel is a panel of a generated dashboard.
+type grafanaPanel struct {
+	ID          int             `json:"id"`
+	Type        string          `json:"type"`
+	Title       string          `json:"title"`
+	GridPos     grafanaGridPos  `json:"gridPos"`
+	Datasource  *grafanaRef     `json:"datasource,omitempty"`
+	Targets     []grafanaTarget `json:"targets,omitempty"`
+	FieldConfig *grafanaFields  `json:"fieldConfig,omitempty"`
+}
+
+type grafanaGridPos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type grafanaRef struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+type grafanaTarget struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat,omitempty"`
+}
+
+type grafanaFields struct {
+	Defaults grafanaFieldDefaults `json:"defaults"`
+}
+
+type grafanaFieldDefaults struct {
+	Unit string `json:"unit,omitempty"`
+	Min  *int   `json:"min,omitempty"`
+	Max  *int   `json:"max,omitempty"`
+}
+
+// grafanaDatasource is the Prometheus data source of every panel, picked by
+// the datasource variable of the dashboard.
+var grafanaDatasource = &grafanaRef{Type: "prometheus", UID: "${datasource}"}
+
+// grafanaDatasourceVariable lets the dashboard be imported into any Grafana,
+// whatever its Prometheus data source is called.
+var grafanaDatasourceVariable = map[string]any{"name": "datasource", "label": "Data source", "type": "datasource", "query": "prometheus"}
+
+// grafanaBoard collects the panels of a dashboard, numbering them and their
+// queries in the order they are added.
+type grafanaBoard struct {
+	panels []grafanaPanel
+}
+
+func (b *grafanaBoard) add(typ, title string, pos grafanaGridPos, fields *grafanaFields, targets ...grafanaTarget) {
+	p := grafanaPanel{ID: len(b.panels) + 1, Type: typ, Title: title, GridPos: pos, FieldConfig: fields}
+	if typ != "row" {
+		p.Datasource = grafanaDatasource
+	}
+	for i := range targets {
+		targets[i].RefID = string(rune('A' + i))
+	}
+	p.Targets = targets
+	b.panels = append(b.panels, p)
+}
+
+// generateMetricsDashboard returns the Grafana dashboard of the series the
+// metrics middleware exports: rate, errors and duration of each route, the
+// database connection pool and the depth of the job queues. The service
+// variable selects the Prometheus job and defaults to service.
+func generateMetricsDashboard(service string) (string, error) {
+	job := `job="$service"`
+	unit := func(u string) *grafanaFields { return &grafanaFields{Defaults: grafanaFieldDefaults{Unit: u}} }
+
+	var board grafanaBoard
+	add := board.add
+
+	add("row", "HTTP", grafanaGridPos{Y: 0, W: 24, H: 1}, nil)
+	add("timeseries", "Requests per second", grafanaGridPos{Y: 1, W: 12, H: 8}, unit("reqps"),
+		grafanaTarget{Expr: fmt.Sprintf("sum by (method, route) (rate(http_requests_total{%s}[$__rate_interval]))", job), LegendFormat: "{{method}} {{route}}"})
+	add("timeseries", "5xx ratio", grafanaGridPos{X: 12, Y: 1, W: 12, H: 8}, unit("percentunit"),
+		grafanaTarget{Expr: fmt.Sprintf("sum by (method, route) (rate(http_requests_total{%s, code=~\"5..\"}[$__rate_interval])) / sum by (method, route) (rate(http_requests_total{%s}[$__rate_interval]))", job, job), LegendFormat: "{{method}} {{route}}"})
+	add("timeseries", "p95 latency", grafanaGridPos{Y: 9, W: 12, H: 8}, unit("s"),
+		grafanaTarget{Expr: fmt.Sprintf("histogram_quantile(0.95, sum by (le, method, route) (rate(http_request_duration_seconds_bucket{%s}[$__rate_interval])))", job), LegendFormat: "{{method}} {{route}}"})
+	var quantiles []grafanaTarget
+	for _, q := range []struct{ quantile, legend string }{{"0.5", "p50"}, {"0.95", "p95"}, {"0.99", "p99"}} {
+		quantiles = append(quantiles, grafanaTarget{
+			Expr:         fmt.Sprintf("histogram_quantile(%s, sum by (le) (rate(http_request_duration_seconds_bucket{%s}[$__rate_interval])))", q.quantile, job),
+			LegendFormat: q.legend,
+		})
+	}
+	add("timeseries", "Latency", grafanaGridPos{X: 12, Y: 9, W: 12, H: 8}, unit("s"), quantiles...)
+
+	add("row", "Database pool", grafanaGridPos{Y: 17, W: 24, H: 1}, nil)
+	add("timeseries", "Connections", grafanaGridPos{Y: 18, W: 8, H: 8}, unit("short"),
+		grafanaTarget{Expr: fmt.Sprintf("sum by (db_name) (go_sql_in_use_connections{%s})", job), LegendFormat: "{{db_name}} in use"},
+		grafanaTarget{Expr: fmt.Sprintf("sum by (db_name) (go_sql_idle_connections{%s})", job), LegendFormat: "{{db_name}} idle"},
+		grafanaTarget{Expr: fmt.Sprintf("max by (db_name) (go_sql_max_open_connections{%s})", job), LegendFormat: "{{db_name}} max open"})
+	add("timeseries", "Waits for a connection", grafanaGridPos{X: 8, Y: 18, W: 8, H: 8}, unit("ops"),
+		grafanaTarget{Expr: fmt.Sprintf("sum by (db_name) (rate(go_sql_wait_count_total{%s}[$__rate_interval]))", job), LegendFormat: "{{db_name}}"})
+	add("timeseries", "Time waiting for a connection", grafanaGridPos{X: 16, Y: 18, W: 8, H: 8}, unit("s"),
+		grafanaTarget{Expr: fmt.Sprintf("sum by (db_name) (rate(go_sql_wait_duration_seconds_total{%s}[$__rate_interval]))", job), LegendFormat: "{{db_name}}"})
+
+	add("row", "Jobs", grafanaGridPos{Y: 26, W: 24, H: 1}, nil)
+	add("timeseries", "Queue depth", grafanaGridPos{Y: 27, W: 24, H: 8}, unit("short"),
+		grafanaTarget{Expr: fmt.Sprintf("sum by (queue) (worker_queue_depth{%s})", job), LegendFormat: "{{queue}}"})
+
+	uid := k8sName(service + "-metrics")
+	if len(uid) > 40 {
+		uid = strings.TrimRight(uid[:40], "-")
+	}
+	dashboard := map[string]any{
+		"uid":           uid,
+		"title":         service + " metrics",
+		"tags":          []string{"goca", service},
+		"schemaVersion": 39,
+		"refresh":       "30s",
+		"time":          map[string]string{"from": "now-1h", "to": "now"},
+		"templating": map[string]any{
+			"list": []map[string]any{
+				grafanaDatasourceVariable,
+				{
+					"name":       "service",
+					"label":      "Service",
+					"type":       "query",
+					"datasource": grafanaDatasource,
+					"query":      "label_values(http_requests_total, job)",
+					"refresh":    2,
+					"current":    map[string]string{"text": service, "value": service},
+				},
+			},
+		},
+		"panels": board.panels,
+	}
+	content, err := json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(content) + "\n", nil
+}
+
+// generateObservability writes the metrics dashboard and the Prometheus and
+// Grafana configuration that serve it, and adds them to docker-compose.yml as
+// the observability profile.
+func generateObservability(sm ...*SafetyManager) error {
+	data, _ := buildK8sTemplateData("", "")
+	service := data.Name
+	dashboard, err := generateMetricsDashboard(service)
+	if err != nil {
+		return fmt.Errorf("rendering the metrics dashboard: %w", err)
+	}
+
+	compose, _ := os.ReadFile("docker-compose.yml")
+	target := composeAppService(compose)
+	if target == "" {
+		target = service
+	}
+
+	grafana := filepath.Join(dirObservability, "grafana")
+	files := []struct {
+		path    string
+		content string
+	}{
+		{filepath.Join(grafana, "dashboards", service+".json"), dashboard},
+		{filepath.Join(grafana, "provisioning", "datasources", "prometheus.yaml"), grafanaDatasourceProvisioning},
+		{filepath.Join(grafana, "provisioning", "dashboards", "dashboards.yaml"), grafanaDashboardProvisioning},
+		{filepath.Join(dirObservability, "prometheus.yml"), prometheusScrapeConfig(service, target+":"+data.Port)},
+	}
+	for _, f := range files {
+		if err := writeFile(f.path, f.content, sm...); err != nil {
+			return fmt.Errorf("writing %s: %w", f.path, err)
+		}
+	}
+
+	if compose == nil {
+		return nil
+	}
+	updated := addObservabilityProfile(string(compose), target)
+	if updated == string(compose) {
+		return nil
+	}
+	if err := writeGoFileMerged("docker-compose.yml", updated, sm...); err != nil {
+		return fmt.Errorf("adding the observability profile to docker-compose.yml: %w", err)
+	}
+	return nil
+}
+
+// composeAppService returns the service of a docker-compose.yml built from
+// the project's Dockerfile, or "" when there is none.
+func composeAppService(compose []byte) string {
+	var file struct {
+		Services map[string]struct {
+			Build any `yaml:"build"`
+		} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(compose, &file); err != nil {
+		return ""
+	}
+	var names []string
+	for name, s := range file.Services {
+		if s.Build != nil {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	return names[0]
+}
+
+// addObservabilityProfile appends the prometheus and grafana services to the
+// services of a docker-compose.yml. They belong to the observability profile,
+// so a plain `docker compose up` leaves them out. Compose files that already
+// define a prometheus service are returned unchanged.
+func addObservabilityProfile(compose, app string) string {
+	lines := strings.Split(compose, "\n")
+	start := -1
+	for i, line := range lines {
+		if strings.TrimRight(line, " ") == "services:" {
+			start = i
+		}
+		if start >= 0 && strings.TrimSpace(line) == "prometheus:" && strings.HasPrefix(line, "  ") && !strings.HasPrefix(line, "   ") {
+			return compose
+		}
+	}
+	if start < 0 {
+		return compose
+	}
+
+	// The services section ends at the next top-level key.
+	next := len(lines)
+	for i := start + 1; i < len(lines); i++ {
+		if lines[i] != "" && lines[i][0] != ' ' && lines[i][0] != '#' {
+			next = i
+			break
+		}
+	}
+	end := next
+	for end > start+1 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+
+	services := strings.Split(fmt.Sprintf(observabilityServices, app), "\n")
+	merged := append(append(append([]string{}, lines[:end]...), ""), services...)
+	if next == len(lines) {
+		return strings.Join(merged, "\n")
+	}
+	return strings.Join(append(merged, lines[next:]...), "\n")
+}
+
+// observabilityServices are the services of the observability profile.
+const observabilityServices = `  prometheus:
+    image: prom/prometheus:v2.54.1
+    profiles: ["observability"]
+    volumes:
+      - ./observability/prometheus.yml:/etc/prometheus/prometheus.yml:ro
+    ports:
+      - "9090:9090"
+    depends_on:
+      - %s
+    restart: unless-stopped
+
+  grafana:
+    image: grafana/grafana:11.2.0
+    profiles: ["observability"]
+    environment:
+      - GF_AUTH_ANONYMOUS_ENABLED=true
+      - GF_AUTH_ANONYMOUS_ORG_ROLE=Viewer
+    volumes:
+      - ./observability/grafana/provisioning:/etc/grafana/provisioning:ro
+      - ./observability/grafana/dashboards:/var/lib/grafana/dashboards:ro
+    ports:
+      - "3000:3000"
+    depends_on:
+      - prometheus
+    restart: unless-stopped
+`
+
+// prometheusScrapeConfig returns the prometheus.yml of the observability
+// profile. The job is named after the service, which is the default of the
+// dashboard's service variable.
+func prometheusScrapeConfig(service, target string) string {
+	return fmt.Sprintf(`global:
+  scrape_interval: 15s
+
+scrape_configs:
+  - job_name: %s
+    metrics_path: /metrics
+    static_configs:
+      - targets: ["%s"]
+`, service, target)
+}
+
+// grafanaDatasourceProvisioning points Grafana at the Prometheus service of
+// the observability profile.
+const grafanaDatasourceProvisioning = `apiVersion: 1
+
+datasources:
+  - name: Prometheus
+    uid: prometheus
+    type: prometheus
+    access: proxy
+    url: http://prometheus:9090
+    isDefault: true
+`
+
+// grafanaDashboardProvisioning loads the dashboards mounted at
+// /var/lib/grafana/dashboards.
+const grafanaDashboardProvisioning = `apiVersion: 1
+
+providers:
+  - name: goca
+    type: file
+    disableDeletion: false
+    updateIntervalSeconds: 30
+    options:
+      path: /var/lib/grafana/dashboards
+`
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const observabilityCompose = `version: '3.8'
+
+services:
+  shop:
+    build: .
+    ports:
+      - "8080:8080"
+    restart: unless-stopped
+
+  database:
+    image: postgres:15
+
+volumes:
+  db_data:
+`
+
+func TestGenerateObservability(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+	writeTestFile(t, ".", ".goca.yaml", "project:\n  name: Shop API\n  module: example.com/shop\n")
+	writeTestFile(t, ".", "docker-compose.yml", observabilityCompose)
+
+	require.NoError(t, generateMiddlewarePackage("api", []string{"metrics"}, RouterMux, NewSafetyManager(false, false, false)))
+
+	content, err := os.ReadFile(filepath.Join(dirObservability, "grafana", "dashboards", "shop-api.json"))
+	require.NoError(t, err)
+	var dashboard struct {
+		UID        string `json:"uid"`
+		Templating struct {
+			List []map[string]any `json:"list"`
+		} `json:"templating"`
+		Panels []grafanaPanel `json:"panels"`
+	}
+	require.NoError(t, json.Unmarshal(content, &dashboard))
+	assert.Equal(t, "shop-api-metrics", dashboard.UID)
+	require.Len(t, dashboard.Templating.List, 2)
+	assert.Equal(t, map[string]any{"text": "shop-api", "value": "shop-api"}, dashboard.Templating.List[1]["current"])
+	var exprs []string
+	for _, p := range dashboard.Panels {
+		for _, target := range p.Targets {
+			exprs = append(exprs, target.Expr)
+		}
+	}
+	all := strings.Join(exprs, "\n")
+	assert.Contains(t, all, `sum by (method, route) (rate(http_requests_total{job="$service"}[$__rate_interval]))`)
+	assert.Contains(t, all, `sum by (db_name) (go_sql_in_use_connections{job="$service"})`)
+	assert.Contains(t, all, `sum by (queue) (worker_queue_depth{job="$service"})`)
+
+	prometheus, err := os.ReadFile(filepath.Join(dirObservability, "prometheus.yml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(prometheus), "  - job_name: shop-api\n")
+	assert.Contains(t, string(prometheus), `- targets: ["shop:8080"]`)
+	assert.FileExists(t, filepath.Join(dirObservability, "grafana", "provisioning", "datasources", "prometheus.yaml"))
+	assert.FileExists(t, filepath.Join(dirObservability, "grafana", "provisioning", "dashboards", "dashboards.yaml"))
+
+	compose, err := os.ReadFile("docker-compose.yml")
+	require.NoError(t, err)
+	var parsed struct {
+		Services map[string]struct {
+			Profiles  []string `yaml:"profiles"`
+			DependsOn []string `yaml:"depends_on"`
+		} `yaml:"services"`
+		Volumes map[string]any `yaml:"volumes"`
+	}
+	require.NoError(t, yaml.Unmarshal(compose, &parsed))
+	assert.Equal(t, []string{"observability"}, parsed.Services["prometheus"].Profiles)
+	assert.Equal(t, []string{"shop"}, parsed.Services["prometheus"].DependsOn)
+	assert.Equal(t, []string{"observability"}, parsed.Services["grafana"].Profiles)
+	assert.Contains(t, parsed.Volumes, "db_data")
+	assert.Contains(t, string(compose), "      - prometheus\n    restart: unless-stopped\n\nvolumes:\n")
+
+	// Generating again leaves the compose file alone.
+	assert.Equal(t, string(compose), addObservabilityProfile(string(compose), "shop"))
+}
+
+func TestAddObservabilityProfile_ServicesLast(t *testing.T) {
+	compose := "services:\n  app:\n    build: .\n"
+	updated := addObservabilityProfile(compose, "app")
+	assert.True(t, strings.HasPrefix(updated, compose+"\n  prometheus:\n"))
+	assert.Equal(t, "app", composeAppService([]byte(updated)))
+	assert.Equal(t, "", composeAppService([]byte("services:\n  db:\n    image: postgres\n")))
+}
@@ -228,13 +228,15 @@ func min(a, b int) int {
 // GenerateInsertSQL generates SQL INSERT statements with sample data.
 func (g *DataGenerator) GenerateInsertSQL(tableName string, fields []Field, count int) string {
 	var result strings.Builder
-	entity := strings.TrimSuffix(tableName, "s") // Simple pluralization removal
+	entity := toSingular(tableName)
 
-	// Generate field names (excluding ID)
-	var fieldNames []string
+	// Generate column names (excluding ID)
+	var columns []string
+	var dataFields []string
 	for _, field := range fields {
 		if field.Name != "ID" {
-			fieldNames = append(fieldNames, strings.ToLower(field.Name))
+			columns = append(columns, fieldColumn(field))
+			dataFields = append(dataFields, field.Name)
 		}
 	}
 
@@ -244,13 +246,11 @@ func (g *DataGenerator) GenerateInsertSQL(tableName string, fields []Field, coun
 		data := g.GenerateSampleData(fields, entity)
 
 		result.WriteString(fmt.Sprintf("INSERT INTO %s (%s) VALUES (",
-			tableName, strings.Join(fieldNames, ", ")))
+			tableName, strings.Join(columns, ", ")))
 
 		var values []string
-		caser := cases.Title(language.English)
-		for _, fieldName := range fieldNames {
-			value := data[caser.String(fieldName)]
-			values = append(values, g.formatSQLValue(value))
+		for _, name := range dataFields {
+			values = append(values, g.formatSQLValue(data[name]))
 		}
 
 		result.WriteString(strings.Join(values, ", "))
@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// mongoCountersCollection holds the sequences MongoDB repositories allocate
+// entity IDs from, one document per collection.
+const mongoCountersCollection = "counters"
+
+// objectIDField is the primary key of entities in MongoDB projects with the
+// objectid ID strategy: the hex string of the ObjectID of their document's
+// _id. omitempty leaves it out of inserts, so MongoDB assigns it.
+var objectIDField = Field{
+	Name: "ID",
+	Type: "string",
+	Tag:  "`json:\"id\" bson:\"_id,omitempty\"`",
+}
+
+// projectUsesObjectIDs reports whether the current project is a MongoDB
+// project with the objectid ID strategy.
+func projectUsesObjectIDs() bool {
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	db := ci.GetDatabaseConfig()
+	return db.Type == DBMongoDB && db.IDStrategy == IDStrategyObjectID
+}
+
+// validateObjectIDs reports why goca feature cannot generate a feature of a
+// project with the objectid ID strategy: only the HTTP handler takes string
+// IDs, and the cache decorator, the audit log and the contract tests key
+// entities by numeric IDs.
+func validateObjectIDs(handlers string, cache, audit, contracts bool) error {
+	for _, handler := range strings.Split(handlers, ",") {
+		if handler = strings.TrimSpace(handler); handler != "" && handler != HandlerHTTP {
+			return fmt.Errorf("the %s handler takes numeric IDs and does not support database.id_strategy objectid", handler)
+		}
+	}
+	switch {
+	case cache:
+		return errors.New("--cache keys entities by numeric IDs and does not support database.id_strategy objectid")
+	case audit:
+		return errors.New("--audit records numeric entity IDs and does not support database.id_strategy objectid")
+	case contracts:
+		return errors.New("contract tests key entities by numeric IDs and do not support database.id_strategy objectid")
+	}
+	return nil
+}
+
+// entityIDType returns the Go type of the ID field of entity, or the type the
+// project's ID strategy gives it when the entity cannot be read.
+func entityIDType(entity string) string {
+	if file, err := findEntityFile(entity); err == nil {
+		if src, err := parseGoSource(file); err == nil {
+			for _, field := range readEntityFields(src.file, entity) {
+				if field.Name == "ID" {
+					return field.Type
+				}
+			}
+		}
+	}
+	if projectUsesObjectIDs() {
+		return objectIDField.Type
+	}
+	return "uint"
+}
+
+// idParamType returns the type repositories, use cases and handlers take the
+// ID of entity as: string for ObjectIDs, int for numeric IDs.
+func idParamType(entity string) string {
+	if entityIDType(entity) == "string" {
+		return "string"
+	}
+	return "int"
+}
+
+// addBSONTags gives each field a bson tag naming its document key after its
+// column, so documents, filters and Spec keys share the snake case names.
+func addBSONTags(fields []Field) {
+	for i := range fields {
+		tag := strings.Trim(fields[i].Tag, "`")
+		if reflect.StructTag(tag).Get("bson") != "" {
+			continue
+		}
+		fields[i].Tag = "`" + setStructTagKey(tag, "bson", fieldColumn(fields[i])) + "`"
+	}
+}
+
+// hasBSONTags reports whether an entity maps its fields with bson tags, so
+// fields added to it get one too.
+func hasBSONTags(fields []Field) bool {
+	for _, field := range fields {
+		if reflect.StructTag(strings.Trim(field.Tag, "`")).Get("bson") != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// mongoFieldKey returns the document key the driver stores field under: the
+// name of its bson tag, or the lower case field name.
+func mongoFieldKey(field Field) string {
+	tag := reflect.StructTag(strings.Trim(field.Tag, "`")).Get("bson")
+	if name, _, _ := strings.Cut(tag, ","); name != "" && name != "-" {
+		return name
+	}
+	return strings.ToLower(field.Name)
+}
+
+// mongoDocument describes how a MongoDB repository stores an entity.
+type mongoDocument struct {
+	// keys maps the field names to their document keys.
+	keys   map[string]string
+	fields []Field
+	// idType is the Go type of the ID field.
+	idType string
+	// objectIDs is set when the ID is the ObjectID of the document's _id.
+	objectIDs bool
+}
+
+// readMongoDocument reads the document keys from the bson tags of the
+// entity, falling back to fields when the entity cannot be read.
+func readMongoDocument(entity string, fields []Field) mongoDocument {
+	if file, err := findEntityFile(entity); err == nil {
+		if src, err := parseGoSource(file); err == nil {
+			if declared := readEntityFields(src.file, entity); len(declared) > 0 {
+				fields = declared
+			}
+		}
+	}
+	doc := mongoDocument{keys: make(map[string]string), idType: "uint"}
+	if projectUsesObjectIDs() {
+		doc.idType = objectIDField.Type
+		doc.keys["ID"] = "_id"
+	}
+	for _, field := range fields {
+		doc.keys[field.Name] = mongoFieldKey(field)
+		doc.fields = append(doc.fields, field)
+		if field.Name == "ID" {
+			doc.idType = field.Type
+		}
+	}
+	doc.objectIDs = doc.idType == "string" && doc.key("ID") == "_id"
+	return doc
+}
+
+// key returns the document key of the field name.
+func (d mongoDocument) key(name string) string {
+	if key, ok := d.keys[name]; ok {
+		return key
+	}
+	return strings.ToLower(name)
+}
+
+// indexes returns the keys of the finder fields of methods in order, each
+// with whether its gorm tag makes it unique. The ID index is not included.
+func (d mongoDocument) indexes(methods []SearchMethod) ([]string, map[string]bool) {
+	tags := make(map[string]string, len(d.fields))
+	for _, field := range d.fields {
+		tags[field.Name] = field.Tag
+	}
+	var keys []string
+	unique := make(map[string]bool)
+	for _, method := range methods {
+		if method.isAggregate() || method.Kind == SearchCriteria {
+			continue
+		}
+		for _, column := range method.columns() {
+			key := d.key(column.Name)
+			if key == d.key("ID") || contains(keys, key) {
+				continue
+			}
+			keys = append(keys, key)
+			options := gormTagOptions(tags[column.Name])
+			_, uniqueIndex := options["uniqueindex"]
+			_, uniqueColumn := options["unique"]
+			unique[key] = uniqueIndex || uniqueColumn
+		}
+	}
+	return keys, unique
+}
+
+// writeMongoConstructor writes the constructor of a MongoDB repository,
+// which creates the indexes of its collection when it has any.
+func writeMongoConstructor(content *strings.Builder, entity, repoName string, doc mongoDocument, indexed bool) {
+	collection := entityTableName(entity)
+	fmt.Fprintf(content, "func NewMongo%sRepository(db *mongo.Database) %sRepository {\n", entity, entity)
+	if !indexed {
+		fmt.Fprintf(content, "\treturn &%s{collection: db.Collection(%q)}\n", repoName, collection)
+		content.WriteString("}\n\n")
+		return
+	}
+	fmt.Fprintf(content, "\tm := &%s{\n", repoName)
+	fmt.Fprintf(content, "\t\tcollection: db.Collection(%q),\n", collection)
+	if !doc.objectIDs {
+		fmt.Fprintf(content, "\t\tcounters:   db.Collection(%q),\n", mongoCountersCollection)
+	}
+	content.WriteString("\t}\n")
+	content.WriteString("\tctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)\n")
+	content.WriteString("\tdefer cancel()\n")
+	content.WriteString("\tif err := m.ensureIndexes(ctx); err != nil {\n")
+	fmt.Fprintf(content, "\t\tlog.Printf(\"Could not create the indexes of %s: %%v\", err)\n", collection)
+	content.WriteString("\t}\n")
+	content.WriteString("\treturn m\n")
+	content.WriteString("}\n\n")
+}
+
+// writeMongoIndexes writes ensureIndexes, creating the unique index of the
+// ID and the indexes of the finder fields. Creating an existing index is a
+// no-op, so it runs on every start. ObjectIDs live in _id, which MongoDB
+// always indexes.
+func writeMongoIndexes(content *strings.Builder, repoName string, doc mongoDocument, methods []SearchMethod) {
+	keys, unique := doc.indexes(methods)
+	if doc.objectIDs {
+		content.WriteString("// ensureIndexes creates the indexes of the finders. Creating an existing\n")
+		content.WriteString("// index is a no-op.\n")
+	} else {
+		content.WriteString("// ensureIndexes creates the unique index of the ID and the indexes of the\n")
+		content.WriteString("// finders. Creating an existing index is a no-op.\n")
+	}
+	fmt.Fprintf(content, "func (m *%s) ensureIndexes(ctx context.Context) error {\n", repoName)
+	content.WriteString("\t_, err := m.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{\n")
+	if !doc.objectIDs {
+		fmt.Fprintf(content, "\t\t{Keys: bson.D{{Key: %q, Value: 1}}, Options: options.Index().SetUnique(true)},\n", doc.key("ID"))
+	}
+	for _, key := range keys {
+		if unique[key] {
+			fmt.Fprintf(content, "\t\t{Keys: bson.D{{Key: %q, Value: 1}}, Options: options.Index().SetUnique(true)},\n", key)
+		} else {
+			fmt.Fprintf(content, "\t\t{Keys: bson.D{{Key: %q, Value: 1}}},\n", key)
+		}
+	}
+	content.WriteString("\t})\n")
+	content.WriteString("\treturn err\n")
+	content.WriteString("}\n\n")
+}
+
+// writeMongoNextID writes nextID, allocating entity IDs from a sequence in
+// the counters collection. Documents keep the ObjectID MongoDB gives their
+// _id; the sequential ID is the one the use cases and handlers see.
+func writeMongoNextID(content *strings.Builder, repoName string) {
+	content.WriteString("// nextID returns the next ID of the collection, incrementing its sequence in\n")
+	content.WriteString("// the counters collection atomically.\n")
+	fmt.Fprintf(content, "func (m *%s) nextID(ctx context.Context) (int64, error) {\n", repoName)
+	content.WriteString("\tvar counter struct {\n")
+	content.WriteString("\t\tSeq int64 `bson:\"seq\"`\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\terr := m.counters.FindOneAndUpdate(ctx,\n")
+	content.WriteString("\t\tbson.M{\"_id\": m.collection.Name()},\n")
+	content.WriteString("\t\tbson.M{\"$inc\": bson.M{\"seq\": 1}},\n")
+	content.WriteString("\t\toptions.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),\n")
+	content.WriteString("\t).Decode(&counter)\n")
+	content.WriteString("\treturn counter.Seq, err\n")
+	content.WriteString("}\n\n")
+}
+
+// writeMongoObjectID writes the parsing of the ObjectID in the hex string
+// expr into oid. Strings that are not ObjectIDs name no document, so the
+// method returns its zero values followed by domain.ErrNotFound.
+func writeMongoObjectID(content *strings.Builder, expr, zeros string) {
+	fmt.Fprintf(content, "\toid, err := primitive.ObjectIDFromHex(%s)\n", expr)
+	content.WriteString("\tif err != nil {\n")
+	fmt.Fprintf(content, "\t\treturn %stranslateMongoError(mongo.ErrNoDocuments)\n", zeros)
+	content.WriteString("\t}\n")
+}
+
+// writeMongoReplacement writes document, the copy of entityVar a MongoDB
+// update with ObjectIDs replaces the document with. Without its ID it keeps
+// the document's _id, which cannot change.
+func writeMongoReplacement(content *strings.Builder, entityVar string) {
+	fmt.Fprintf(content, "\tdocument := *%s\n", entityVar)
+	content.WriteString("\tdocument.ID = \"\"\n")
+}
+
+// writeMongoFindAllPaged writes FindAllPaged, which pages through the
+// entities in ID order.
+func writeMongoFindAllPaged(content *strings.Builder, entity, repoName string, doc mongoDocument) {
+	entityVar := strings.ToLower(entity)
+	fmt.Fprintf(content, "func (m *%s) FindAllPaged(ctx context.Context, limit, offset int) ([]domain.%s, int64, error) {\n", repoName, entity)
+	content.WriteString("\ttotal, err := m.collection.CountDocuments(ctx, bson.M{})\n")
+	content.WriteString("\tif err != nil {\n")
+	content.WriteString("\t\treturn nil, 0, err\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\topts := options.Find().\n")
+	fmt.Fprintf(content, "\t\tSetSort(bson.D{{Key: %q, Value: 1}}).\n", doc.key("ID"))
+	content.WriteString("\t\tSetSkip(int64(offset)).\n")
+	content.WriteString("\t\tSetLimit(int64(limit))\n")
+	content.WriteString("\tcursor, err := m.collection.Find(ctx, bson.M{}, opts)\n")
+	content.WriteString("\tif err != nil {\n")
+	content.WriteString("\t\treturn nil, 0, err\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tdefer cursor.Close(ctx)\n")
+	fmt.Fprintf(content, "\t%s := []domain.%s{}\n", toPlural(entityVar), entity)
+	fmt.Fprintf(content, "\tif err := cursor.All(ctx, &%s); err != nil {\n", toPlural(entityVar))
+	content.WriteString("\t\treturn nil, 0, err\n")
+	content.WriteString("\t}\n")
+	fmt.Fprintf(content, "\treturn %s, total, nil\n", toPlural(entityVar))
+	content.WriteString("}\n\n")
+}
+
+// writeMongoKeys writes the map from the fields of entity to their document
+// keys, which Search filters by.
+func writeMongoKeys(content *strings.Builder, entity string, doc mongoDocument) {
+	fmt.Fprintf(content, "// mongo%sKeys maps the fields of %s to their document keys.\n", entity, entity)
+	fmt.Fprintf(content, "var mongo%sKeys = map[string]string{\n", entity)
+	for _, field := range doc.fields {
+		fmt.Fprintf(content, "\t%q: %q,\n", field.Name, doc.key(field.Name))
+	}
+	content.WriteString("}\n\n")
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMongoDBEntityBSONTags(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	sm := NewSafetyManager(false, true, false)
+
+	writeTestFile(t, ".", ".goca.yaml", "project:\n  name: shop\n  module: example.com/shop\ndatabase:\n  type: mongodb\n")
+	require.NoError(t, generateEntity("Product", "name:string,unitPrice:float64", true, false, false, false, false, false, false, false, "lowercase", sm))
+	entity := readParsedGo(t, filepath.Join(DirInternal, DirDomain, "product.go"))
+	assert.Contains(t, entity, `bson:"id"`)
+	assert.Contains(t, entity, `bson:"unit_price"`)
+
+	fields := []Field{{Name: "ID", Type: "uint"}, {Name: "UserName", Type: "string", Tag: "`bson:\"user\"`"}}
+	assert.False(t, hasBSONTags(fields[:1]))
+	assert.True(t, hasBSONTags(fields))
+	addBSONTags(fields)
+	assert.Equal(t, "`bson:\"id\"`", fields[0].Tag)
+	assert.Equal(t, "`bson:\"user\"`", fields[1].Tag)
+}
+
+func TestMongoDBDocumentKeys(t *testing.T) {
+	chdirTemp(t)
+
+	doc := readMongoDocument("Product", []Field{
+		{Name: "ID", Type: "int64"},
+		{Name: "UnitPrice", Type: "float64"},
+		{Name: "Email", Type: "string", Tag: "`gorm:\"uniqueIndex\" bson:\"email_address\"`"},
+	})
+	assert.Equal(t, "int64", doc.idType)
+	assert.Equal(t, "unitprice", doc.key("UnitPrice"))
+	assert.Equal(t, "email_address", doc.key("Email"))
+	assert.Equal(t, "sku", doc.key("Sku"))
+
+	keys, unique := doc.indexes(generateRepositoryMethods(doc.fields, "Product"))
+	assert.Contains(t, keys, "email_address")
+	assert.True(t, unique["email_address"])
+	assert.NotContains(t, keys, "id")
+}
+
+func TestMongoDBRepositoryGeneration(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	sm := NewSafetyManager(false, true, false)
+
+	writeTestFile(t, ".", "go.mod", "module example.com/shop\n\ngo 1.21\n")
+	writeTestFile(t, ".", filepath.Join(DirInternal, DirDomain, "product.go"), `package domain
+
+type Product struct {
+	ID    uint   `+"`"+`json:"id" bson:"id"`+"`"+`
+	Email string `+"`"+`json:"email" gorm:"uniqueIndex" bson:"email"`+"`"+`
+	Name  string `+"`"+`json:"name" bson:"display_name"`+"`"+`
+}
+`)
+	findBy := []FieldFinder{{Kind: FinderOne}}
+	fields := []Field{{Name: "Email", Type: "string", Finders: findBy}, {Name: "Name", Type: "string", Finders: findBy}}
+	dir := filepath.Join(DirInternal, "repository")
+	generateMongoRepositoryWithFields(dir, "Product", fields, false, false, sm)
+	repo := readParsedGo(t, filepath.Join(dir, "mongo_product_repository.go"))
+
+	assert.Contains(t, repo, `counters:   db.Collection("counters")`)
+	assert.Contains(t, repo, `{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},`)
+	assert.Contains(t, repo, `{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},`)
+	assert.Contains(t, repo, `{Keys: bson.D{{Key: "display_name", Value: 1}}},`)
+	assert.Contains(t, repo, "product.ID = uint(id)")
+	assert.Contains(t, repo, "result.MatchedCount == 0")
+	assert.Contains(t, repo, "result.DeletedCount == 0")
+	assert.Contains(t, repo, `options.Find().SetSort(bson.D{{Key: "id", Value: 1}})`)
+	assert.Contains(t, repo, `filter := bson.M{"display_name": name}`)
+	assert.Contains(t, repo, "func (m *mongoProductRepository) FindAllPaged(ctx context.Context, limit, offset int) ([]domain.Product, int64, error) {")
+	assert.Contains(t, repo, "SetSkip(int64(offset))")
+	assert.Contains(t, repo, `"Name":  "display_name",`)
+	assert.Contains(t, repo, "key, ok := mongoProductKeys[f.Field]")
+	assert.NotContains(t, repo, "Timestamp()")
+}
+
+func TestMongoDBObjectIDs(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	sm := NewSafetyManager(false, true, false)
+
+	writeTestFile(t, ".", "go.mod", "module example.com/shop\n\ngo 1.21\n")
+	writeTestFile(t, ".", ".goca.yaml", "project:\n  name: shop\n  module: example.com/shop\ndatabase:\n  type: mongodb\n  id_strategy: objectid\n")
+	fields := "name:string,email:string"
+	require.NoError(t, generateEntity("Product", fields, true, false, false, false, false, false, false, false, "lowercase", sm))
+	entity := readParsedGo(t, filepath.Join(DirInternal, DirDomain, "product.go"))
+	assert.Contains(t, entity, "ID    string `json:\"id\" bson:\"_id,omitempty\"`")
+	assert.Equal(t, "string", idParamType("Product"))
+
+	generateRepository("Product", DBMongoDB, false, false, false, false, fields, sm)
+	interfaces := readParsedGo(t, filepath.Join(DirInternal, DirRepository, "interfaces.go"))
+	assert.Contains(t, interfaces, "FindByID(id string) (*domain.Product, error)")
+	assert.Contains(t, interfaces, "ExistsByID(ctx context.Context, id string) (bool, error)")
+	assert.Contains(t, interfaces, "FindAllPaged(ctx context.Context, limit, offset int) ([]domain.Product, int64, error)")
+
+	repo := readParsedGo(t, filepath.Join(DirInternal, DirRepository, "mongo_product_repository.go"))
+	assert.NotContains(t, repo, "counters")
+	assert.NotContains(t, repo, "nextID")
+	assert.Contains(t, repo, "product.ID = result.InsertedID.(primitive.ObjectID).Hex()")
+	assert.Contains(t, repo, "oid, err := primitive.ObjectIDFromHex(id)")
+	assert.Contains(t, repo, "document.ID = \"\"")
+	assert.Contains(t, repo, `SetSort(bson.D{{Key: "_id", Value: 1}})`)
+
+	generateUseCaseWithFields("ProductUseCase", "Product", "create,read,update,delete,list", false, false, fields, "", sm)
+	usecase := readParsedGo(t, filepath.Join(DirInternal, DirUseCase, "product_usecase.go"))
+	assert.Contains(t, usecase, "GetProduct(id string) (*domain.Product, error)")
+	generateHandler("Product", HandlerHTTP, true, false, true, "lowercase", sm)
+	handler := readParsedGo(t, filepath.Join(DirInternal, DirHandler, DirHTTP, "product_handler.go"))
+	assert.Contains(t, handler, "id := vars[\"id\"]")
+	assert.NotContains(t, handler, "strconv")
+	assert.Contains(t, handler, "// @Param id path string true")
+
+	assert.NoError(t, validateObjectIDs(HandlerHTTP, false, false, false))
+	assert.Error(t, validateObjectIDs("http,grpc", false, false, false))
+	assert.Error(t, validateObjectIDs(HandlerHTTP, true, false, false))
+}
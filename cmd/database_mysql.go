@@ -0,0 +1,83 @@
+package cmd
+
+import "fmt"
+
+// Images of the MySQL-compatible databases in the generated
+// docker-compose.yml. TiDB's unistore storage runs it as a single container.
+const (
+	mariadbImage = "mariadb:11.4"
+	tidbImage    = "pingcap/tidb:v8.5.1"
+)
+
+// isMySQLCompatible reports whether database speaks the MySQL protocol:
+// MariaDB and TiDB share MySQL's GORM driver, DSN, repositories and SQL
+// dialect.
+func isMySQLCompatible(database string) bool {
+	switch database {
+	case DBMySQL, DBMariaDB, DBTiDB:
+		return true
+	}
+	return false
+}
+
+// tidbIDField is the primary key of entities in TiDB projects. AUTO_RANDOM
+// spreads inserts across TiKV regions, where AUTO_INCREMENT sends them all to
+// the region holding the highest ID. default:(-) keeps the ID out of INSERTs
+// and reads it back from LAST_INSERT_ID().
+var tidbIDField = Field{
+	Name: "ID",
+	Type: "uint",
+	Tag:  "`json:\"id\" gorm:\"primaryKey;autoIncrement:false;default:(-);type:bigint unsigned AUTO_RANDOM\"`",
+}
+
+// projectDatabase returns the database type of .goca.yaml, postgres without
+// one.
+func projectDatabase() string {
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	return ci.GetDatabaseType("")
+}
+
+// tidbDockerCompose returns the docker-compose.yml of a TiDB project. TiDB
+// has no variable creating a database or a password, so a MySQL client
+// creates the database once TiDB accepts connections, and the application
+// connects as root without a password.
+func tidbDockerCompose(projectName string) string {
+	createDatabase := fmt.Sprintf("until mysql -h database -P 4000 -u root -e 'CREATE DATABASE IF NOT EXISTS `%s`'; do sleep 2; done", projectName)
+	return fmt.Sprintf(`version: '3.8'
+
+services:
+  %s:
+    build: .
+    ports:
+      - "8080:8080"
+    environment:
+      - DB_HOST=database
+      - DB_PORT=4000
+      - DB_USER=root
+      - DB_PASSWORD=
+      - DB_NAME=%s
+    depends_on:
+      database-init:
+        condition: service_completed_successfully
+    restart: unless-stopped
+
+  database:
+    image: %s
+    command: ["--store=unistore", "--path=/data"]
+    ports:
+      - "4000:4000"
+    volumes:
+      - db_data:/data
+    restart: unless-stopped
+
+  database-init:
+    image: mysql:8.0
+    depends_on:
+      - database
+    entrypoint: ["sh", "-c", %q]
+
+volumes:
+  db_data:
+`, projectName, projectName, tidbImage, createDatabase)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLCompatibleDatabases(t *testing.T) {
+	for _, database := range []string{DBMariaDB, DBTiDB} {
+		importPath, pkg := gormDriver(database, "")
+		assert.Equal(t, "gorm.io/driver/mysql", importPath)
+		assert.Equal(t, "mysql", pkg)
+		assert.Equal(t, sqlDialect(DBMySQL), sqlDialect(database))
+		assert.Equal(t, "Postgres", repoConstructorPrefix(database))
+		assert.True(t, isMySQLCompatible(database))
+	}
+	assert.False(t, isMySQLCompatible(DBPostgres))
+	assert.NoError(t, validateDatabaseFlag(DBTiDB))
+
+	assert.NotContains(t, databaseURLBody(DBMariaDB), "tls")
+	assert.Contains(t, databaseURLBody(DBTiDB), `dsn += "&tls=true"`)
+}
+
+func TestMySQLCompatibleDockerCompose(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	sm := NewSafetyManager(false, true, false)
+
+	createDockerfiles("shop", DBMariaDB, "", sm)
+	compose, err := os.ReadFile(filepath.Join("shop", "docker-compose.yml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(compose), "image: "+mariadbImage)
+	assert.Contains(t, string(compose), "- MARIADB_DATABASE=shop")
+	assert.Contains(t, string(compose), `test: ["CMD", "healthcheck.sh", "--connect", "--innodb_initialized"]`)
+
+	createDockerfiles("ledger", DBTiDB, "", sm)
+	compose, err = os.ReadFile(filepath.Join("ledger", "docker-compose.yml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(compose), "image: "+tidbImage)
+	assert.Contains(t, string(compose), "- DB_PORT=4000")
+	assert.Contains(t, string(compose), "CREATE DATABASE IF NOT EXISTS `ledger`")
+	assert.Contains(t, string(compose), "condition: service_completed_successfully")
+}
+
+func TestTiDBEntityAutoRandom(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	sm := NewSafetyManager(false, true, false)
+
+	writeTestFile(t, ".", ".goca.yaml", "project:\n  name: ledger\n  module: example.com/ledger\ndatabase:\n  type: tidb\n")
+	require.NoError(t, generateEntity("Entry", "amount:float64", true, false, false, false, false, false, false, false, "lowercase", sm))
+	entity := readParsedGo(t, filepath.Join(DirInternal, DirDomain, "entry.go"))
+	assert.Contains(t, entity, `gorm:"primaryKey;autoIncrement:false;default:(-);type:bigint unsigned AUTO_RANDOM"`)
+
+	writeTestFile(t, ".", ".goca.yaml", "project:\n  name: ledger\n  module: example.com/ledger\ndatabase:\n  type: mariadb\n")
+	require.NoError(t, generateEntity("Account", "name:string", true, false, false, false, false, false, false, false, "lowercase", sm))
+	assert.Contains(t, readParsedGo(t, filepath.Join(DirInternal, DirDomain, "account.go")), `gorm:"primaryKey;autoIncrement"`)
+}
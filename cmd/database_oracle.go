@@ -0,0 +1,76 @@
+package cmd
+
+import "fmt"
+
+// Oracle projects run on GORM through godoes/gorm-oracle, whose go-ora
+// driver is pure Go: no Instant Client is needed to build or run them. The
+// dialector renders the ? placeholders of GORM queries as :1, :2 bind
+// variables, migrates autoIncrement IDs to identity columns and reads the
+// generated ID back with RETURNING ... INTO.
+const (
+	oracleDriverModule  = "github.com/godoes/gorm-oracle"
+	oracleDriverVersion = "v1.6.12"
+)
+
+// The generated docker-compose.yml runs Oracle Database Free. The image
+// creates the application user in the FREEPDB1 pluggable database, the
+// service name the application connects to.
+const (
+	oracleImage       = "gvenzl/oracle-free:23-slim"
+	oracleServiceName = "FREEPDB1"
+	oracleAppUser     = "app"
+)
+
+// defaultDatabaseName returns the DB_NAME of a new project: the project name,
+// or the service name of the pluggable database for Oracle.
+func defaultDatabaseName(database, projectName string) string {
+	if database == DBOracle {
+		return oracleServiceName
+	}
+	return projectName
+}
+
+// oracleDockerCompose returns the docker-compose.yml of an Oracle project.
+// The database takes a minute or two to create on the first start, so its
+// health check allows for it before the application starts.
+func oracleDockerCompose(projectName string) string {
+	return fmt.Sprintf(`version: '3.8'
+
+services:
+  %s:
+    build: .
+    ports:
+      - "8080:8080"
+    environment:
+      - DB_HOST=database
+      - DB_PORT=1521
+      - DB_USER=%s
+      - DB_PASSWORD=password
+      - DB_NAME=%s
+    depends_on:
+      database:
+        condition: service_healthy
+    restart: unless-stopped
+
+  database:
+    image: %s
+    environment:
+      - ORACLE_PASSWORD=password
+      - APP_USER=%s
+      - APP_USER_PASSWORD=password
+    ports:
+      - "1521:1521"
+    volumes:
+      - db_data:/opt/oracle/oradata
+    healthcheck:
+      test: ["CMD", "healthcheck.sh"]
+      interval: 10s
+      timeout: 5s
+      retries: 10
+      start_period: 2m
+    restart: unless-stopped
+
+volumes:
+  db_data:
+`, projectName, oracleAppUser, oracleServiceName, oracleImage, oracleAppUser)
+}
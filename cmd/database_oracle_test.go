@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOracleDatabase(t *testing.T) {
+	importPath, pkg := gormDriver(DBOracle, "")
+	assert.Equal(t, oracleDriverModule, importPath)
+	assert.Equal(t, "oracle", pkg)
+	assert.Equal(t, "Postgres", repoConstructorPrefix(DBOracle))
+	assert.True(t, isGormDatabase(DBOracle))
+	assert.NoError(t, validateDatabaseFlag(DBOracle))
+	assert.Equal(t, "1521", getDatabasePort(DBOracle))
+	assert.Equal(t, oracleServiceName, defaultDatabaseName(DBOracle, "shop"))
+	assert.Equal(t, "shop", defaultDatabaseName(DBPostgres, "shop"))
+}
+
+func TestOracleProjectFiles(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	sm := NewSafetyManager(false, true, false)
+
+	createConfig("shop", "example.com/shop", DBOracle, sm)
+	config := readParsedGo(t, filepath.Join("shop", "pkg", "config", "config.go"))
+	assert.Contains(t, config, `"net/url"`)
+	assert.Contains(t, config, "url.UserPassword(c.Database.User, c.Database.Password)")
+	assert.Contains(t, config, `getEnv("DB_NAME", "FREEPDB1")`)
+
+	createGoMod("shop", "example.com/shop", DBOracle, "", "", false, sm)
+	goMod, err := os.ReadFile(filepath.Join("shop", "go.mod"))
+	require.NoError(t, err)
+	assert.Contains(t, string(goMod), oracleDriverModule+" "+oracleDriverVersion)
+
+	createDockerfiles("shop", DBOracle, "", sm)
+	compose, err := os.ReadFile(filepath.Join("shop", "docker-compose.yml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(compose), "image: "+oracleImage)
+	assert.Contains(t, string(compose), "- APP_USER="+oracleAppUser)
+	assert.Contains(t, string(compose), "- DB_NAME=FREEPDB1")
+	assert.Contains(t, string(compose), "start_period: 2m")
+}
+
+func TestOracleMigrations(t *testing.T) {
+	fields := parseFieldsWithValidation("active:bool,placed_at:time.Time", false)[1:]
+	fields[0].Tag = "`json:\"active\" gorm:\"not null\"`"
+	columns := fieldColumns(fields, DBOracle)
+
+	var up strings.Builder
+	writeAddColumns(&up, "orders", DBOracle, columns)
+	assert.Equal(t, "ALTER TABLE orders ADD active number(1) DEFAULT 0 NOT NULL;\n"+
+		"ALTER TABLE orders ADD placed_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL;\n", up.String())
+
+	from := Field{Name: "Total", Type: "float64", Tag: "`json:\"total\" gorm:\"not null\"`"}
+	to := Field{Name: "TotalCents", Type: "int64", Tag: "`json:\"total_cents\" gorm:\"not null\"`"}
+	change := renameColumnChange("Order", DBOracle, from, to)
+	assert.Equal(t, "UPDATE orders SET total_cents = COALESCE(total_cents, CAST(total AS integer)) WHERE (total_cents IS NULL AND total IS NOT NULL) AND ROWNUM <= ?",
+		change.batchStatement(DBOracle))
+	enforce, relax := change.enforceMigration(DBOracle)
+	assert.Contains(t, enforce, "ALTER TABLE orders MODIFY (total_cents NOT NULL);\n")
+	assert.Contains(t, relax, "ALTER TABLE orders MODIFY (total_cents NULL);\n")
+}
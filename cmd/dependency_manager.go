@@ -24,8 +24,12 @@ type Dependency struct {
 	Reason  string
 }
 
-// NewDependencyManager creates a new dependency manager.
+// NewDependencyManager creates a new dependency manager. When projectRoot is a
+// subdirectory of a module, the enclosing module's go.mod is managed.
 func NewDependencyManager(projectRoot string, dryRun bool) *DependencyManager {
+	if root, ok := findModuleRoot(projectRoot); ok {
+		projectRoot = root
+	}
 	return &DependencyManager{
 		projectRoot: projectRoot,
 		goModPath:   filepath.Join(projectRoot, "go.mod"),
@@ -44,13 +48,13 @@ func (dm *DependencyManager) CommonDependencies() map[string]Dependency {
 		},
 		"jwt": {
 			Module:  "github.com/golang-jwt/jwt/v5",
-			Version: "v5.2.0",
+			Version: "v5.2.2",
 			Type:    "optional",
 			Reason:  "JWT authentication",
 		},
 		"cors": {
 			Module:  "github.com/rs/cors",
-			Version: "v1.10.1",
+			Version: "v1.11.0",
 			Type:    "optional",
 			Reason:  "CORS middleware",
 		},
@@ -62,7 +66,7 @@ func (dm *DependencyManager) CommonDependencies() map[string]Dependency {
 		},
 		"bcrypt": {
 			Module:  "golang.org/x/crypto",
-			Version: "v0.17.0",
+			Version: "v0.31.0",
 			Type:    "optional",
 			Reason:  "password hashing",
 		},
@@ -84,9 +88,153 @@ func (dm *DependencyManager) CommonDependencies() map[string]Dependency {
 			Type:    "required",
 			Reason:  "gRPC protocol support",
 		},
+		"grpc-gateway": {
+			Module:  "github.com/grpc-ecosystem/grpc-gateway/v2",
+			Version: "v2.22.0",
+			Type:    "required",
+			Reason:  "REST gateway in front of gRPC services",
+		},
+		"cobra": {
+			Module:  "github.com/spf13/cobra",
+			Version: "v1.10.1",
+			Type:    "required",
+			Reason:  "CLI command handlers",
+		},
+		"websocket": {
+			Module:  "github.com/gorilla/websocket",
+			Version: "v1.5.3",
+			Type:    "required",
+			Reason:  "WebSocket handlers",
+		},
+		"aws-s3": {
+			Module:  "github.com/aws/aws-sdk-go-v2/service/s3",
+			Version: "v1.79.2",
+			Type:    "required",
+			Reason:  "S3 file storage",
+		},
+		"aws-s3-manager": {
+			Module:  "github.com/aws/aws-sdk-go-v2/feature/s3/manager",
+			Version: "v1.17.72",
+			Type:    "required",
+			Reason:  "streaming S3 uploads",
+		},
+		"aws-config": {
+			Module:  "github.com/aws/aws-sdk-go-v2/config",
+			Version: "v1.29.14",
+			Type:    "required",
+			Reason:  "AWS credentials and region",
+		},
+		"aws": {
+			Module:  "github.com/aws/aws-sdk-go-v2",
+			Version: "v1.36.3",
+			Type:    "required",
+			Reason:  "AWS SDK core",
+		},
+		"aws-sesv2": {
+			Module:  "github.com/aws/aws-sdk-go-v2/service/sesv2",
+			Version: "v1.44.0",
+			Type:    "required",
+			Reason:  "Amazon SES notifications",
+		},
+		"yaml": {
+			Module:  "gopkg.in/yaml.v3",
+			Version: "v3.0.1",
+			Type:    "required",
+			Reason:  "YAML feature flags file",
+		},
+		"gcs": {
+			Module:  "cloud.google.com/go/storage",
+			Version: "v1.51.0",
+			Type:    "required",
+			Reason:  "Google Cloud Storage file storage",
+		},
+		"datatypes": {
+			Module:  "gorm.io/datatypes",
+			Version: "v1.2.7",
+			Type:    "required",
+			Reason:  "JSON columns",
+		},
+		"otel": {
+			Module:  "go.opentelemetry.io/otel",
+			Version: otelVersion,
+			Type:    "required",
+			Reason:  "OpenTelemetry tracing API",
+		},
+		"otel-trace": {
+			Module:  "go.opentelemetry.io/otel/trace",
+			Version: otelVersion,
+			Type:    "required",
+			Reason:  "OpenTelemetry span types",
+		},
+		"wire": {
+			Module:  "github.com/google/wire",
+			Version: wireVersion,
+			Type:    "required",
+			Reason:  "compile-time dependency injection",
+		},
+		"pact": {
+			Module:  "github.com/pact-foundation/pact-go/v2",
+			Version: pactGoVersion,
+			Type:    "required",
+			Reason:  "consumer-driven contract tests",
+		},
+		"cron": {
+			Module:  "github.com/robfig/cron/v3",
+			Version: "v3.0.1",
+			Type:    "required",
+			Reason:  "scheduled jobs of goca ingest, goca archive, cron handlers and retention windows",
+		},
+		"rate": {
+			Module:  "golang.org/x/time",
+			Version: "v0.10.0",
+			Type:    "required",
+			Reason:  "rate limiting of ingestion requests",
+		},
+		"parquet": {
+			Module:  "github.com/parquet-go/parquet-go",
+			Version: "v0.25.0",
+			Type:    "required",
+			Reason:  "Parquet objects of archive jobs",
+		},
+		"kafka": {
+			Module:  "github.com/segmentio/kafka-go",
+			Version: "v0.4.47",
+			Type:    "required",
+			Reason:  "change data capture consumers",
+		},
+		"fx": {
+			Module:  "go.uber.org/fx",
+			Version: fxVersion,
+			Type:    "required",
+			Reason:  "dependency injection modules",
+		},
+		"gobreaker": {
+			Module:  "github.com/sony/gobreaker/v2",
+			Version: gobreakerVersion,
+			Type:    "required",
+			Reason:  "repository circuit breakers",
+		},
+		"prometheus": {
+			Module:  "github.com/prometheus/client_golang",
+			Version: prometheusVersion,
+			Type:    "required",
+			Reason:  "Prometheus metrics",
+		},
+		"zstd": {
+			Module:  "github.com/klauspost/compress",
+			Version: klauspostCompressVersion,
+			Type:    "required",
+			Reason:  "zstd compression middleware",
+		},
+		"migrate": {
+			Module:  "github.com/golang-migrate/migrate/v4",
+			Version: golangMigrateVersion,
+			Type:    "required",
+			Reason:  "migrations applied on startup",
+		},
 		"protobuf": {
 			Module:  "google.golang.org/protobuf",
-			Version: "v1.31.0",
+			Version: "v1.33.0",
 			Type:    "required",
 			Reason:  "Protocol Buffers",
 		},
@@ -147,9 +295,11 @@ func (dm *DependencyManager) AddDependency(dep Dependency) error {
 	if ui != nil {
 		stop = ui.Spinner(fmt.Sprintf("Installing %s", dep.Module))
 	}
+	stopProfile := profileStep("go get", dep.Module)
 	cmd := exec.Command("go", "get", dep.Module+"@"+dep.Version)
 	cmd.Dir = dm.projectRoot
 	output, err := cmd.CombinedOutput()
+	stopProfile()
 	if stop != nil {
 		stop()
 	}
@@ -232,9 +382,11 @@ func (dm *DependencyManager) UpdateGoMod() error {
 	if ui != nil {
 		stop = ui.Spinner("Running go mod tidy")
 	}
+	stopProfile := profileStep("go mod tidy")
 	cmd := exec.Command("go", "mod", "tidy")
 	cmd.Dir = dm.projectRoot
 	output, err := cmd.CombinedOutput()
+	stopProfile()
 	if stop != nil {
 		stop()
 	}
@@ -297,17 +449,80 @@ func (dm *DependencyManager) GetRequiredDependenciesForFeature(featureType strin
 	commonDeps := dm.CommonDependencies()
 
 	// Add dependencies based on feature type
-	switch strings.ToLower(featureType) {
-	case "grpc":
-		required = append(required, commonDeps["grpc"], commonDeps["protobuf"])
-	case "auth":
-		required = append(required, commonDeps["jwt"], commonDeps["bcrypt"])
+	// featureType may list several handler types, e.g. "http,grpc,cli".
+	for _, kind := range strings.Split(strings.ToLower(featureType), ",") {
+		switch strings.TrimSpace(kind) {
+		case "grpc":
+			required = append(required, commonDeps["grpc"], commonDeps["protobuf"])
+		case "cli":
+			required = append(required, commonDeps["cobra"])
+		case "websocket":
+			required = append(required, commonDeps["websocket"])
+		case "cron":
+			required = append(required, commonDeps["cron"])
+		case "worker":
+			// The worker pool exports its queue depth and lag.
+			required = append(required, commonDeps["prometheus"])
+		case "auth":
+			required = append(required, commonDeps["jwt"], commonDeps["bcrypt"])
+		}
 	}
 
 	// Add based on options
 	if options["validation"] {
 		required = append(required, commonDeps["validator"])
 	}
+	if options["tracing"] {
+		required = append(required, commonDeps["otel"], commonDeps["otel-trace"])
+	}
+	if options["wire"] {
+		required = append(required, commonDeps["wire"])
+	}
+	if options["fx"] {
+		required = append(required, commonDeps["fx"])
+	}
+	if options["json"] {
+		required = append(required, commonDeps["datatypes"])
+	}
+	if options["contracts"] {
+		required = append(required, commonDeps["pact"])
+	}
+	if options["gateway"] {
+		required = append(required, commonDeps["grpc-gateway"])
+	}
+	if options["storage"] {
+		required = append(required, commonDeps["aws"], commonDeps["aws-config"], commonDeps["aws-s3"], commonDeps["aws-s3-manager"], commonDeps["gcs"])
+	}
+	if options["ingest"] {
+		required = append(required, commonDeps["cron"], commonDeps["rate"])
+	}
+	if options["cdc"] {
+		required = append(required, commonDeps["kafka"])
+	}
+	if options["zstd"] {
+		required = append(required, commonDeps["zstd"])
+	}
+	if options["resilience"] {
+		required = append(required, commonDeps["gobreaker"], commonDeps["prometheus"])
+	}
+	if options["retention"] {
+		required = append(required, commonDeps["cron"], commonDeps["prometheus"])
+	}
+	if options["archive"] {
+		required = append(required, commonDeps["cron"])
+	}
+	if options["archive-s3"] {
+		required = append(required, commonDeps["aws"], commonDeps["aws-config"], commonDeps["aws-s3"], commonDeps["parquet"])
+	}
+	if options["migrate"] {
+		required = append(required, commonDeps["migrate"])
+	}
+	if options["notification"] {
+		required = append(required, commonDeps["aws"], commonDeps["aws-config"], commonDeps["aws-sesv2"])
+	}
+	if options["featureflags"] {
+		required = append(required, commonDeps["yaml"])
+	}
 
 	return required
 }
@@ -116,6 +116,27 @@ func TestGetRequiredDependenciesForFeature(t *testing.T) {
 		assert.Len(t, deps, 3) // jwt + bcrypt + validator
 	})
 
+	t.Run("handler list", func(t *testing.T) {
+		t.Parallel()
+		deps := dm.GetRequiredDependenciesForFeature("http, grpc,cli", nil)
+		require.Len(t, deps, 3) // grpc + protobuf + cobra
+		assert.Equal(t, "github.com/spf13/cobra", deps[2].Module)
+	})
+
+	t.Run("worker", func(t *testing.T) {
+		t.Parallel()
+		deps := dm.GetRequiredDependenciesForFeature("worker", nil)
+		require.Len(t, deps, 1)
+		assert.Equal(t, "github.com/prometheus/client_golang", deps[0].Module)
+	})
+
+	t.Run("json fields", func(t *testing.T) {
+		t.Parallel()
+		deps := dm.GetRequiredDependenciesForFeature("http", map[string]bool{"json": true})
+		require.Len(t, deps, 1)
+		assert.Equal(t, "gorm.io/datatypes", deps[0].Module)
+	})
+
 	t.Run("unknown type no options", func(t *testing.T) {
 		t.Parallel()
 		deps := dm.GetRequiredDependenciesForFeature("unknown", nil)
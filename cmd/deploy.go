@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var deployCmd = &cobra.Command{
+	Use:   "deploy",
+	Short: "Generate deployment configuration",
+	Long: `Generates deployment configuration for the project beyond the Dockerfile
+created by goca init.`,
+}
+
+var deployK8sCmd = &cobra.Command{
+	Use:   "k8s",
+	Short: "Generate Kubernetes manifests and an optional Helm chart",
+	Long: `Generates Kubernetes manifests for the project:
+
+  - namespace.yaml      Namespace the resources are created in
+  - configmap.yaml      Non-secret variables from .env
+  - secret.yaml         Secret variables from .env (passwords, tokens, keys)
+  - deployment.yaml     Deployment with liveness and readiness probes
+  - service.yaml        ClusterIP Service in front of the pods
+  - hpa.yaml            HorizontalPodAutoscaler on CPU usage
+  - kustomization.yaml  Lets 'kubectl apply -k' apply all of the above
+
+The probes point at the health endpoints of the generated server. Variables
+are read from .env (or .env.example), and defaults such as the namespace and
+image come from the deploy section of .goca.yaml. Secret values are left
+empty so they never end up in version control.
+
+With --helm a chart with the same resources is generated under charts/, its
+values.yaml derived from the same sources. When .goca.yaml declares SLOs, the
+monitoring of 'goca deploy slo' is regenerated under monitoring/ as well.
+
+Examples:
+  goca deploy k8s
+  goca deploy k8s --image ghcr.io/acme/shop:1.0.0 --replicas 3
+  goca deploy k8s --helm`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, _ := cmd.Flags().GetString("namespace")
+		image, _ := cmd.Flags().GetString("image")
+		replicas, _ := cmd.Flags().GetInt("replicas")
+		maxReplicas, _ := cmd.Flags().GetInt("max-replicas")
+		output, _ := cmd.Flags().GetString("output")
+		helm, _ := cmd.Flags().GetBool("helm")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		force, _ := cmd.Flags().GetBool("force")
+		backup, _ := cmd.Flags().GetBool("backup")
+
+		if err := validateReplicas(replicas, maxReplicas); err != nil {
+			return err
+		}
+
+		sm := NewSafetyManager(dryRun, force, backup)
+
+		data, k8sCfg := buildK8sTemplateData(namespace, image)
+		data.Replicas = replicas
+		data.MaxReplicas = maxReplicas
+		if output == "" {
+			output = k8sCfg.Manifests
+		}
+		helm = helm || k8sCfg.Helm
+
+		ui.Header("Goca Deploy — Kubernetes")
+		ui.Blank()
+		ui.KeyValue("Application", data.Name)
+		ui.KeyValue("Namespace", data.Namespace)
+		ui.KeyValue("Image", data.Image)
+		ui.KeyValue("Replicas", strconv.Itoa(data.Replicas)+"-"+strconv.Itoa(data.MaxReplicas))
+		ui.KeyValue("Probes", data.LivenessPath+", "+data.ReadinessPath)
+		ui.KeyValue("Helm chart", strconv.FormatBool(helm))
+		ui.Blank()
+
+		if err := generateK8sManifests(output, data, sm); err != nil {
+			return err
+		}
+		if helm {
+			if err := generateHelmChart(helmChartDir(data.Name), data, sm); err != nil {
+				return err
+			}
+		}
+		if cfg := loadSLOConfig(); sloDeclared(cfg) {
+			objectives, window, err := buildSLOObjectives(cfg, data.Name)
+			if err != nil {
+				return err
+			}
+			dir := filepath.Join(output, "monitoring")
+			ui.Step(3, "Generating SLO monitoring in "+dir)
+			if err := generateSLOMonitoring(dir, data, objectives, window, sm); err != nil {
+				return err
+			}
+		}
+
+		if dryRun {
+			sm.PrintSummary()
+			return nil
+		}
+
+		ui.Blank()
+		ui.Success("Kubernetes configuration generated successfully!")
+		ui.Blank()
+		ui.Info("Next steps:")
+		ui.Step(1, "Review the values in "+output+"/configmap.yaml (DB_HOST, ENVIRONMENT, ...)")
+		ui.Step(2, "Set the secret values before applying "+output+"/secret.yaml")
+		if helm {
+			ui.Step(3, "kubectl apply -k "+output+"  or  helm install "+data.Name+" "+helmChartDir(data.Name))
+		} else {
+			ui.Step(3, "kubectl apply -k "+output)
+		}
+		return nil
+	},
+}
+
+func init() {
+	deployK8sCmd.Flags().String("namespace", "", "Kubernetes namespace (default: deploy.kubernetes.namespace or the project name)")
+	deployK8sCmd.Flags().String("image", "", "Container image (default: deploy.docker image and registry, or <project>:latest)")
+	deployK8sCmd.Flags().Int("replicas", 2, "Minimum number of replicas")
+	deployK8sCmd.Flags().Int("max-replicas", 10, "Maximum number of replicas the autoscaler may scale to")
+	deployK8sCmd.Flags().String("output", "", "Directory for the manifests (default: deploy.kubernetes.manifests or k8s)")
+	deployK8sCmd.Flags().Bool("helm", false, "Also generate a Helm chart under charts/")
+	deployK8sCmd.Flags().Bool("dry-run", false, "Preview changes without creating files")
+	deployK8sCmd.Flags().Bool("force", false, "Overwrite existing files without asking")
+	deployK8sCmd.Flags().Bool("backup", false, "Backup existing files before overwriting")
+
+	deployCmd.AddCommand(deployK8sCmd)
+}
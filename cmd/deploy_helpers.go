@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// envVariable is one KEY=value line of a .env file.
+type envVariable struct {
+	Key   string
+	Value string
+}
+
+// dns1123Invalid matches runs of characters not allowed in Kubernetes names.
+var dns1123Invalid = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// validateReplicas checks the --replicas and --max-replicas flags.
+func validateReplicas(replicas, maxReplicas int) error {
+	if replicas < 1 {
+		return fmt.Errorf("--replicas must be at least 1, got %d", replicas)
+	}
+	if maxReplicas < replicas {
+		return fmt.Errorf("--max-replicas (%d) must not be lower than --replicas (%d)", maxReplicas, replicas)
+	}
+	return nil
+}
+
+// buildK8sTemplateData reads the project's .goca.yaml, .env and server entry
+// point and returns the data for the Kubernetes templates, along with the
+// kubernetes section of the config. Empty namespace and image fall back to
+// the config and then to the project name.
+func buildK8sTemplateData(namespace, image string) (K8sTemplateData, KubernetesConfig) {
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	deploy := ci.GetDeployConfig()
+	name := k8sName(ci.GetProjectConfig().Name)
+	if name == "" {
+		name = k8sName(getCurrentProjectName())
+	}
+
+	if namespace == "" {
+		namespace = deploy.Kubernetes.Namespace
+	}
+	if namespace == "" {
+		namespace = name
+	}
+	if image == "" {
+		image = dockerImage(deploy.Docker, name)
+	}
+	if deploy.Kubernetes.Manifests == "" {
+		deploy.Kubernetes.Manifests = "k8s"
+	}
+
+	data := K8sTemplateData{
+		Name:      name,
+		Namespace: k8sName(namespace),
+		Image:     image,
+		Port:      "8080",
+		Labels:    deploy.Kubernetes.Labels,
+	}
+	data.LivenessPath, data.ReadinessPath = detectHealthProbes()
+	for _, v := range readEnvFile() {
+		switch {
+		case v.Key == "PORT":
+			if _, err := strconv.Atoi(v.Value); err == nil {
+				data.Port = v.Value
+			}
+			data.Config = append(data.Config, v)
+		case isSecretEnvKey(v.Key):
+			data.Secrets = append(data.Secrets, envVariable{Key: v.Key})
+		default:
+			data.Config = append(data.Config, v)
+		}
+	}
+	return data, deploy.Kubernetes
+}
+
+// dockerImage returns the image configured under deploy.docker, or
+// <name>:latest.
+func dockerImage(docker DockerConfig, name string) string {
+	image := docker.Image
+	if image == "" {
+		image = name
+	}
+	if docker.Registry != "" {
+		image = strings.TrimSuffix(docker.Registry, "/") + "/" + image
+	}
+	if !strings.Contains(image[strings.LastIndex(image, "/")+1:], ":") {
+		image += ":latest"
+	}
+	return image
+}
+
+// splitImage splits an image reference into repository and tag.
+func splitImage(image string) (repository, tag string) {
+	slash := strings.LastIndex(image, "/")
+	if colon := strings.LastIndex(image, ":"); colon > slash {
+		return image[:colon], image[colon+1:]
+	}
+	return image, "latest"
+}
+
+// k8sName lowercases s and replaces characters Kubernetes does not allow in
+// resource names with dashes.
+func k8sName(s string) string {
+	s = dns1123Invalid.ReplaceAllString(strings.ToLower(s), "-")
+	s = strings.Trim(s, "-")
+	if len(s) > 63 {
+		s = strings.TrimRight(s[:63], "-")
+	}
+	return s
+}
+
+// readEnvFile returns the variables of .env, or of .env.example when the
+// project has no .env, in file order.
+func readEnvFile() []envVariable {
+	for _, path := range []string{".env", ".env.example"} {
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+
+		var vars []envVariable
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+			if !ok {
+				continue
+			}
+			value = strings.TrimSpace(value)
+			if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+				value = value[1 : len(value)-1]
+			}
+			vars = append(vars, envVariable{Key: strings.TrimSpace(key), Value: value})
+		}
+		return vars
+	}
+	return nil
+}
+
+// isSecretEnvKey reports whether a variable belongs in a Secret rather than a
+// ConfigMap.
+func isSecretEnvKey(key string) bool {
+	key = strings.ToUpper(key)
+	for _, marker := range []string{"PASSWORD", "SECRET", "TOKEN", "CREDENTIAL", "PRIVATE"} {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	return strings.HasSuffix(key, "_KEY") || strings.HasSuffix(key, "_DSN")
+}
+
+// detectHealthProbes returns the liveness and readiness paths registered by
+// cmd/server/main.go. Servers with only /health use it for both probes.
+func detectHealthProbes() (liveness, readiness string) {
+	content, err := os.ReadFile(filepath.Join("cmd", "server", "main.go"))
+	if err != nil {
+		return "/health/live", "/health/ready"
+	}
+	liveness, readiness = "/health", "/health"
+	if strings.Contains(string(content), `"/health/live"`) {
+		liveness = "/health/live"
+	}
+	if strings.Contains(string(content), `"/health/ready"`) {
+		readiness = "/health/ready"
+	}
+	return liveness, readiness
+}
+
+// helmChartDir returns the directory of the project's Helm chart.
+func helmChartDir(name string) string {
+	return filepath.Join("charts", name)
+}
+
+// generateK8sManifests writes the Kubernetes manifests to dir.
+func generateK8sManifests(dir string, data K8sTemplateData, sm ...*SafetyManager) error {
+	manifests := []struct {
+		file    string
+		content string
+	}{
+		{"namespace.yaml", generateK8sNamespace(data)},
+		{"configmap.yaml", generateK8sConfigMap(data)},
+		{"secret.yaml", generateK8sSecret(data)},
+		{"deployment.yaml", generateK8sDeployment(data)},
+		{"service.yaml", generateK8sService(data)},
+		{"hpa.yaml", generateK8sHPA(data)},
+		{"kustomization.yaml", generateK8sKustomization(data)},
+	}
+
+	ui.Step(1, "Generating Kubernetes manifests in "+dir)
+	for _, m := range manifests {
+		if err := writeFile(filepath.Join(dir, m.file), m.content, sm...); err != nil {
+			return fmt.Errorf("writing %s: %w", m.file, err)
+		}
+	}
+	return nil
+}
+
+// generateHelmChart writes a Helm chart for the project to dir.
+func generateHelmChart(dir string, data K8sTemplateData, sm ...*SafetyManager) error {
+	files := []struct {
+		file    string
+		content string
+	}{
+		{"Chart.yaml", generateHelmChartYAML(data)},
+		{"values.yaml", generateHelmValues(data)},
+		{".helmignore", helmIgnore},
+		{filepath.Join("templates", "_helpers.tpl"), generateHelmHelpers(data)},
+		{filepath.Join("templates", "configmap.yaml"), generateHelmConfigMap(data)},
+		{filepath.Join("templates", "secret.yaml"), generateHelmSecret(data)},
+		{filepath.Join("templates", "deployment.yaml"), generateHelmDeployment(data)},
+		{filepath.Join("templates", "service.yaml"), generateHelmService(data)},
+		{filepath.Join("templates", "hpa.yaml"), generateHelmHPA(data)},
+	}
+
+	ui.Step(2, "Generating Helm chart in "+dir)
+	for _, f := range files {
+		if err := writeFile(filepath.Join(dir, f.file), f.content, sm...); err != nil {
+			return fmt.Errorf("writing %s: %w", f.file, err)
+		}
+	}
+	return nil
+}
+
+// createK8sManifests writes the manifests of goca deploy k8s, with its default
+// replicas, for the project goca init creates in projectName.
+func createK8sManifests(projectName string, sm ...*SafetyManager) {
+	wd, err := os.Getwd()
+	if err == nil {
+		err = os.Chdir(projectName)
+	}
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Failed to generate Kubernetes manifests: %v", err))
+		return
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	data, k8sCfg := buildK8sTemplateData("", "")
+	data.Replicas, data.MaxReplicas = 2, 10
+	if err := generateK8sManifests(k8sCfg.Manifests, data, sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Failed to generate Kubernetes manifests: %v", err))
+		return
+	}
+	if k8sCfg.Helm {
+		if err := generateHelmChart(helmChartDir(data.Name), data, sm...); err != nil {
+			ui.Warning(fmt.Sprintf("Failed to generate Helm chart: %v", err))
+		}
+	}
+}
@@ -0,0 +1,496 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// K8sTemplateData holds the values fed into the Kubernetes and Helm templates.
+type K8sTemplateData struct {
+	Name          string
+	Namespace     string
+	Image         string
+	Port          string
+	Replicas      int
+	MaxReplicas   int
+	LivenessPath  string
+	ReadinessPath string
+	Config        []envVariable
+	Secrets       []envVariable
+	Labels        map[string]string
+}
+
+// k8sLabels returns the labels of every resource as YAML indented by indent
+// spaces.
+func k8sLabels(data K8sTemplateData, indent int) string {
+	pad := strings.Repeat(" ", indent)
+	return fmt.Sprintf("%sapp.kubernetes.io/name: %s\n", pad, data.Name) + k8sExtraLabels(data.Labels, indent)
+}
+
+// k8sExtraLabels returns the labels from deploy.kubernetes.labels, sorted by
+// key. app.kubernetes.io/name is skipped since the selectors rely on it.
+func k8sExtraLabels(labels map[string]string, indent int) string {
+	pad := strings.Repeat(" ", indent)
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		if k != "app.kubernetes.io/name" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s%s: %s\n", pad, k, strconv.Quote(labels[k]))
+	}
+	return b.String()
+}
+
+// k8sEnvMap returns vars as a YAML map indented by indent spaces, or {} when
+// vars is empty.
+func k8sEnvMap(vars []envVariable, indent int) string {
+	if len(vars) == 0 {
+		return " {}\n"
+	}
+	pad := strings.Repeat(" ", indent)
+	var b strings.Builder
+	b.WriteString("\n")
+	for _, v := range vars {
+		fmt.Fprintf(&b, "%s%s: %s\n", pad, v.Key, strconv.Quote(v.Value))
+	}
+	return b.String()
+}
+
+// generateK8sNamespace returns the Namespace manifest.
+func generateK8sNamespace(data K8sTemplateData) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: %s
+  labels:
+%s`, data.Namespace, k8sLabels(data, 4))
+}
+
+// generateK8sConfigMap returns the ConfigMap holding the non-secret variables
+// of .env.
+func generateK8sConfigMap(data K8sTemplateData) string {
+	return fmt.Sprintf(`# Non-secret variables from .env. Values such as DB_HOST and ENVIRONMENT
+# usually differ in the cluster; review them before applying.
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s-config
+  namespace: %s
+  labels:
+%sdata:%s`, data.Name, data.Namespace, k8sLabels(data, 4), k8sEnvMap(data.Config, 2))
+}
+
+// generateK8sSecret returns the Secret holding the secret variables of .env,
+// with empty values.
+func generateK8sSecret(data K8sTemplateData) string {
+	return fmt.Sprintf(`# Secret variables from .env. The values are left empty so they are never
+# committed; fill them in before applying or create the Secret directly:
+#   kubectl -n %[2]s create secret generic %[1]s-secrets --from-literal=KEY=value
+apiVersion: v1
+kind: Secret
+metadata:
+  name: %[1]s-secrets
+  namespace: %[2]s
+  labels:
+%[3]stype: Opaque
+stringData:%[4]s`, data.Name, data.Namespace, k8sLabels(data, 4), k8sEnvMap(data.Secrets, 2))
+}
+
+// generateK8sDeployment returns the Deployment manifest with liveness and
+// readiness probes on the generated health endpoints.
+func generateK8sDeployment(data K8sTemplateData) string {
+	return fmt.Sprintf(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: %[1]s
+  namespace: %[2]s
+  labels:
+%[3]sspec:
+  replicas: %[4]d
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: %[1]s
+  template:
+    metadata:
+      labels:
+%[5]s    spec:
+      containers:
+        - name: %[1]s
+          image: %[6]s
+          imagePullPolicy: IfNotPresent
+          ports:
+            - name: http
+              containerPort: %[7]s
+              protocol: TCP
+          envFrom:
+            - configMapRef:
+                name: %[1]s-config
+            - secretRef:
+                name: %[1]s-secrets
+          livenessProbe:
+            httpGet:
+              path: %[8]s
+              port: http
+            initialDelaySeconds: 10
+            periodSeconds: 10
+            timeoutSeconds: 3
+            failureThreshold: 3
+          readinessProbe:
+            httpGet:
+              path: %[9]s
+              port: http
+            initialDelaySeconds: 5
+            periodSeconds: 5
+            timeoutSeconds: 3
+            failureThreshold: 3
+          resources:
+            requests:
+              cpu: 100m
+              memory: 128Mi
+            limits:
+              cpu: 500m
+              memory: 512Mi
+`, data.Name, data.Namespace, k8sLabels(data, 4), data.Replicas, k8sLabels(data, 8),
+		data.Image, data.Port, data.LivenessPath, data.ReadinessPath)
+}
+
+// generateK8sService returns the ClusterIP Service manifest.
+func generateK8sService(data K8sTemplateData) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Service
+metadata:
+  name: %[1]s
+  namespace: %[2]s
+  labels:
+%[3]sspec:
+  type: ClusterIP
+  selector:
+    app.kubernetes.io/name: %[1]s
+  ports:
+    - name: http
+      port: 80
+      targetPort: http
+      protocol: TCP
+`, data.Name, data.Namespace, k8sLabels(data, 4))
+}
+
+// generateK8sHPA returns the HorizontalPodAutoscaler manifest.
+func generateK8sHPA(data K8sTemplateData) string {
+	return fmt.Sprintf(`apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: %[1]s
+  namespace: %[2]s
+  labels:
+%[3]sspec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: %[1]s
+  minReplicas: %[4]d
+  maxReplicas: %[5]d
+  metrics:
+    - type: Resource
+      resource:
+        name: cpu
+        target:
+          type: Utilization
+          averageUtilization: 80
+`, data.Name, data.Namespace, k8sLabels(data, 4), data.Replicas, data.MaxReplicas)
+}
+
+// generateK8sKustomization returns the kustomization listing every manifest.
+func generateK8sKustomization(data K8sTemplateData) string {
+	return fmt.Sprintf(`apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namespace: %s
+resources:
+  - namespace.yaml
+  - configmap.yaml
+  - secret.yaml
+  - deployment.yaml
+  - service.yaml
+  - hpa.yaml
+`, data.Namespace)
+}
+
+// generateHelmChartYAML returns the Chart.yaml of the Helm chart.
+func generateHelmChartYAML(data K8sTemplateData) string {
+	_, tag := splitImage(data.Image)
+	return fmt.Sprintf(`apiVersion: v2
+name: %s
+description: A Helm chart for %s
+type: application
+version: 0.1.0
+appVersion: %s
+`, data.Name, data.Name, strconv.Quote(tag))
+}
+
+// generateHelmValues returns the values.yaml of the Helm chart. PORT is
+// rendered from containerPort so the two cannot disagree.
+func generateHelmValues(data K8sTemplateData) string {
+	repository, tag := splitImage(data.Image)
+	var config []envVariable
+	for _, v := range data.Config {
+		if v.Key != "PORT" {
+			config = append(config, v)
+		}
+	}
+
+	labels := " {}\n"
+	if extra := k8sExtraLabels(data.Labels, 2); extra != "" {
+		labels = "\n" + extra
+	}
+
+	return fmt.Sprintf(`# Default values for %[1]s, generated by goca from .env and .goca.yaml.
+
+replicaCount: %[2]d
+
+image:
+  repository: %[3]s
+  tag: %[4]s
+  pullPolicy: IfNotPresent
+
+nameOverride: ""
+fullnameOverride: ""
+
+# Extra labels added to every resource.
+labels:%[5]s
+containerPort: %[6]s
+
+service:
+  type: ClusterIP
+  port: 80
+
+probes:
+  liveness:
+    path: %[7]s
+  readiness:
+    path: %[8]s
+
+# Non-secret environment variables, rendered into a ConfigMap. Values such as
+# DB_HOST and ENVIRONMENT usually differ in the cluster.
+config:%[9]s
+# Secret environment variables, rendered into a Secret. Set them at install
+# time, e.g. --set secrets.DB_PASSWORD=..., instead of committing them here.
+secrets:%[10]s
+resources:
+  requests:
+    cpu: 100m
+    memory: 128Mi
+  limits:
+    cpu: 500m
+    memory: 512Mi
+
+autoscaling:
+  enabled: true
+  minReplicas: %[2]d
+  maxReplicas: %[11]d
+  targetCPUUtilizationPercentage: 80
+`, data.Name, data.Replicas, repository, strconv.Quote(tag), labels, data.Port,
+		data.LivenessPath, data.ReadinessPath, k8sEnvMap(config, 2), k8sEnvMap(data.Secrets, 2), data.MaxReplicas)
+}
+
+// helmIgnore is the .helmignore of the Helm chart.
+const helmIgnore = `.DS_Store
+.git/
+.gitignore
+*.swp
+*.bak
+*.tmp
+.idea/
+.vscode/
+`
+
+// generateHelmHelpers returns templates/_helpers.tpl of the Helm chart.
+func generateHelmHelpers(data K8sTemplateData) string {
+	return fmt.Sprintf(`{{/*
+Name of the chart.
+*/}}
+{{- define "%[1]s.name" -}}
+{{- default .Chart.Name .Values.nameOverride | trunc 63 | trimSuffix "-" }}
+{{- end }}
+
+{{/*
+Fully qualified app name, truncated to the 63 characters Kubernetes allows.
+*/}}
+{{- define "%[1]s.fullname" -}}
+{{- if .Values.fullnameOverride }}
+{{- .Values.fullnameOverride | trunc 63 | trimSuffix "-" }}
+{{- else }}
+{{- $name := default .Chart.Name .Values.nameOverride }}
+{{- if contains $name .Release.Name }}
+{{- .Release.Name | trunc 63 | trimSuffix "-" }}
+{{- else }}
+{{- printf "%%s-%%s" .Release.Name $name | trunc 63 | trimSuffix "-" }}
+{{- end }}
+{{- end }}
+{{- end }}
+
+{{/*
+Common labels.
+*/}}
+{{- define "%[1]s.labels" -}}
+helm.sh/chart: {{ printf "%%s-%%s" .Chart.Name .Chart.Version | replace "+" "_" | trunc 63 | trimSuffix "-" }}
+{{ include "%[1]s.selectorLabels" . }}
+app.kubernetes.io/version: {{ .Chart.AppVersion | quote }}
+app.kubernetes.io/managed-by: {{ .Release.Service }}
+{{- with .Values.labels }}
+{{ toYaml . }}
+{{- end }}
+{{- end }}
+
+{{/*
+Selector labels.
+*/}}
+{{- define "%[1]s.selectorLabels" -}}
+app.kubernetes.io/name: {{ include "%[1]s.name" . }}
+app.kubernetes.io/instance: {{ .Release.Name }}
+{{- end }}
+`, data.Name)
+}
+
+// generateHelmConfigMap returns templates/configmap.yaml of the Helm chart.
+func generateHelmConfigMap(data K8sTemplateData) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ include "%[1]s.fullname" . }}
+  labels:
+    {{- include "%[1]s.labels" . | nindent 4 }}
+data:
+  {{- range $key, $value := .Values.config }}
+  {{ $key }}: {{ $value | quote }}
+  {{- end }}
+`, data.Name)
+}
+
+// generateHelmSecret returns templates/secret.yaml of the Helm chart.
+func generateHelmSecret(data K8sTemplateData) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Secret
+metadata:
+  name: {{ include "%[1]s.fullname" . }}
+  labels:
+    {{- include "%[1]s.labels" . | nindent 4 }}
+type: Opaque
+stringData:
+  {{- range $key, $value := .Values.secrets }}
+  {{ $key }}: {{ $value | quote }}
+  {{- end }}
+`, data.Name)
+}
+
+// generateHelmDeployment returns templates/deployment.yaml of the Helm chart.
+func generateHelmDeployment(data K8sTemplateData) string {
+	return fmt.Sprintf(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "%[1]s.fullname" . }}
+  labels:
+    {{- include "%[1]s.labels" . | nindent 4 }}
+spec:
+  {{- if not .Values.autoscaling.enabled }}
+  replicas: {{ .Values.replicaCount }}
+  {{- end }}
+  selector:
+    matchLabels:
+      {{- include "%[1]s.selectorLabels" . | nindent 6 }}
+  template:
+    metadata:
+      annotations:
+        # Roll the pods when the configuration changes.
+        checksum/config: {{ include (print $.Template.BasePath "/configmap.yaml") . | sha256sum }}
+        checksum/secret: {{ include (print $.Template.BasePath "/secret.yaml") . | sha256sum }}
+      labels:
+        {{- include "%[1]s.labels" . | nindent 8 }}
+    spec:
+      containers:
+        - name: {{ .Chart.Name }}
+          image: "{{ .Values.image.repository }}:{{ .Values.image.tag | default .Chart.AppVersion }}"
+          imagePullPolicy: {{ .Values.image.pullPolicy }}
+          ports:
+            - name: http
+              containerPort: {{ .Values.containerPort }}
+              protocol: TCP
+          env:
+            - name: PORT
+              value: {{ .Values.containerPort | quote }}
+          envFrom:
+            - configMapRef:
+                name: {{ include "%[1]s.fullname" . }}
+            - secretRef:
+                name: {{ include "%[1]s.fullname" . }}
+          livenessProbe:
+            httpGet:
+              path: {{ .Values.probes.liveness.path }}
+              port: http
+            initialDelaySeconds: 10
+            periodSeconds: 10
+            timeoutSeconds: 3
+            failureThreshold: 3
+          readinessProbe:
+            httpGet:
+              path: {{ .Values.probes.readiness.path }}
+              port: http
+            initialDelaySeconds: 5
+            periodSeconds: 5
+            timeoutSeconds: 3
+            failureThreshold: 3
+          resources:
+            {{- toYaml .Values.resources | nindent 12 }}
+`, data.Name)
+}
+
+// generateHelmService returns templates/service.yaml of the Helm chart.
+func generateHelmService(data K8sTemplateData) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Service
+metadata:
+  name: {{ include "%[1]s.fullname" . }}
+  labels:
+    {{- include "%[1]s.labels" . | nindent 4 }}
+spec:
+  type: {{ .Values.service.type }}
+  selector:
+    {{- include "%[1]s.selectorLabels" . | nindent 4 }}
+  ports:
+    - name: http
+      port: {{ .Values.service.port }}
+      targetPort: http
+      protocol: TCP
+`, data.Name)
+}
+
+// generateHelmHPA returns templates/hpa.yaml of the Helm chart.
+func generateHelmHPA(data K8sTemplateData) string {
+	return fmt.Sprintf(`{{- if .Values.autoscaling.enabled }}
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: {{ include "%[1]s.fullname" . }}
+  labels:
+    {{- include "%[1]s.labels" . | nindent 4 }}
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: {{ include "%[1]s.fullname" . }}
+  minReplicas: {{ .Values.autoscaling.minReplicas }}
+  maxReplicas: {{ .Values.autoscaling.maxReplicas }}
+  metrics:
+    - type: Resource
+      resource:
+        name: cpu
+        target:
+          type: Utilization
+          averageUtilization: {{ .Values.autoscaling.targetCPUUtilizationPercentage }}
+{{- end }}
+`, data.Name)
+}
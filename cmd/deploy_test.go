@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestBuildK8sTemplateData(t *testing.T) {
+	chdirTemp(t)
+	require.NoError(t, os.WriteFile(".goca.yaml", []byte(`project:
+  name: Shop_API
+  module: testproject
+database:
+  type: postgres
+  port: 5432
+deploy:
+  docker:
+    image: shop-api
+    registry: ghcr.io/acme
+  kubernetes:
+    namespace: store
+    labels:
+      team: payments
+`), 0o644))
+	require.NoError(t, os.WriteFile(".env", []byte(`# Server
+PORT=9090
+DB_HOST=localhost
+DB_PASSWORD=hunter2
+export JWT_SECRET="dev"
+STRIPE_API_KEY=sk_test
+`), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join("cmd", "server"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join("cmd", "server", "main.go"),
+		[]byte(`router.HandleFunc("/health", healthCheckHandler)`), 0o644))
+
+	data, k8sCfg := buildK8sTemplateData("", "")
+	assert.Equal(t, "shop-api", data.Name)
+	assert.Equal(t, "store", data.Namespace)
+	assert.Equal(t, "ghcr.io/acme/shop-api:latest", data.Image)
+	assert.Equal(t, "9090", data.Port)
+	assert.Equal(t, "/health", data.LivenessPath)
+	assert.Equal(t, "/health", data.ReadinessPath)
+	assert.Equal(t, []envVariable{{"PORT", "9090"}, {"DB_HOST", "localhost"}}, data.Config)
+	assert.Equal(t, []envVariable{{Key: "DB_PASSWORD"}, {Key: "JWT_SECRET"}, {Key: "STRIPE_API_KEY"}}, data.Secrets)
+	assert.Equal(t, "k8s", k8sCfg.Manifests)
+
+	data, _ = buildK8sTemplateData("Staging", "registry.local:5000/shop")
+	assert.Equal(t, "staging", data.Namespace)
+	assert.Equal(t, "registry.local:5000/shop", data.Image)
+}
+
+func TestGenerateK8sManifests(t *testing.T) {
+	defer ensureTestUI(t)()
+	dir := t.TempDir()
+	data := K8sTemplateData{
+		Name: "shop", Namespace: "shop", Image: "shop:1.2.0", Port: "8080",
+		Replicas: 2, MaxReplicas: 5,
+		LivenessPath: "/health/live", ReadinessPath: "/health/ready",
+		Config:  []envVariable{{"PORT", "8080"}, {"LOG_LEVEL", "info"}},
+		Secrets: []envVariable{{Key: "DB_PASSWORD"}},
+		Labels:  map[string]string{"team": "payments"},
+	}
+	require.NoError(t, generateK8sManifests(dir, data))
+
+	for _, name := range []string{"namespace", "configmap", "secret", "deployment", "service", "hpa", "kustomization"} {
+		content, err := os.ReadFile(filepath.Join(dir, name+".yaml"))
+		require.NoError(t, err, name)
+		var doc map[string]any
+		require.NoError(t, yaml.Unmarshal(content, &doc), name)
+	}
+
+	deployment, _ := os.ReadFile(filepath.Join(dir, "deployment.yaml"))
+	assert.Contains(t, string(deployment), "path: /health/live")
+	assert.Contains(t, string(deployment), "path: /health/ready")
+	assert.Contains(t, string(deployment), "containerPort: 8080")
+	assert.Contains(t, string(deployment), "team: \"payments\"")
+
+	secret, _ := os.ReadFile(filepath.Join(dir, "secret.yaml"))
+	assert.Contains(t, string(secret), "DB_PASSWORD: \"\"")
+
+	hpa, _ := os.ReadFile(filepath.Join(dir, "hpa.yaml"))
+	assert.Contains(t, string(hpa), "minReplicas: 2")
+	assert.Contains(t, string(hpa), "maxReplicas: 5")
+}
+
+func TestGenerateHelmChart(t *testing.T) {
+	defer ensureTestUI(t)()
+	dir := t.TempDir()
+	data := K8sTemplateData{
+		Name: "shop", Image: "ghcr.io/acme/shop:1.2.0", Port: "8080",
+		Replicas: 2, MaxReplicas: 5,
+		LivenessPath: "/health/live", ReadinessPath: "/health/ready",
+		Config:  []envVariable{{"PORT", "8080"}, {"LOG_LEVEL", "info"}},
+		Secrets: []envVariable{{Key: "DB_PASSWORD"}},
+	}
+	require.NoError(t, generateHelmChart(dir, data))
+
+	content, err := os.ReadFile(filepath.Join(dir, "values.yaml"))
+	require.NoError(t, err)
+	var values struct {
+		Image struct {
+			Repository string `yaml:"repository"`
+			Tag        string `yaml:"tag"`
+		} `yaml:"image"`
+		ContainerPort int               `yaml:"containerPort"`
+		Config        map[string]string `yaml:"config"`
+		Secrets       map[string]string `yaml:"secrets"`
+	}
+	require.NoError(t, yaml.Unmarshal(content, &values))
+	assert.Equal(t, "ghcr.io/acme/shop", values.Image.Repository)
+	assert.Equal(t, "1.2.0", values.Image.Tag)
+	assert.Equal(t, 8080, values.ContainerPort)
+	assert.Equal(t, map[string]string{"LOG_LEVEL": "info"}, values.Config)
+	assert.Equal(t, map[string]string{"DB_PASSWORD": ""}, values.Secrets)
+
+	chart, _ := os.ReadFile(filepath.Join(dir, "Chart.yaml"))
+	assert.Contains(t, string(chart), "appVersion: \"1.2.0\"")
+	helpers, _ := os.ReadFile(filepath.Join(dir, "templates", "_helpers.tpl"))
+	assert.Contains(t, string(helpers), `printf "%s-%s" .Release.Name $name`)
+	deployment, _ := os.ReadFile(filepath.Join(dir, "templates", "deployment.yaml"))
+	assert.Contains(t, string(deployment), `include "shop.fullname" .`)
+}
+
+func TestValidateReplicas(t *testing.T) {
+	assert.NoError(t, validateReplicas(2, 10))
+	assert.Error(t, validateReplicas(0, 10))
+	assert.Error(t, validateReplicas(3, 2))
+}
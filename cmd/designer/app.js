@@ -0,0 +1,169 @@
+// Goca project designer: composes a design and posts it to the serve-ui API.
+(function () {
+  'use strict';
+
+  var project = { entities: [], databases: [], handlers: [], field_types: [] };
+  var $ = function (id) { return document.getElementById(id); };
+
+  function option(select, value, selected) {
+    var o = document.createElement('option');
+    o.value = value;
+    o.textContent = value;
+    o.selected = !!selected;
+    select.appendChild(o);
+  }
+
+  function addField(entity, name, type) {
+    var row = $('field-template').content.firstElementChild.cloneNode(true);
+    var types = row.querySelector('.field-type');
+    project.field_types.forEach(function (t) { option(types, t, t === (type || 'string')); });
+    row.querySelector('.field-name').value = name || '';
+    row.querySelector('.remove-field').onclick = function () { row.remove(); };
+    entity.querySelector('.fields tbody').appendChild(row);
+  }
+
+  function addEntity() {
+    var entity = $('entity-template').content.firstElementChild.cloneNode(true);
+    entity.querySelector('.remove-entity').onclick = function () {
+      entity.remove();
+      refreshRelations();
+    };
+    entity.querySelector('.add-field').onclick = function () { addField(entity); };
+    entity.querySelector('.entity-name').onchange = refreshRelations;
+    addField(entity, 'name', 'string');
+    $('entities').appendChild(entity);
+    refreshRelations();
+  }
+
+  // refreshRelations offers every other entity of the design and of the
+  // project in the belongs-to lists, keeping the current selection.
+  function refreshRelations() {
+    var entities = Array.prototype.slice.call(document.querySelectorAll('.entity'));
+    var names = entities.map(function (e) { return e.querySelector('.entity-name').value.trim(); })
+      .filter(Boolean);
+    var all = names.concat(project.entities.filter(function (n) { return names.indexOf(n) < 0; }));
+
+    entities.forEach(function (e) {
+      var own = e.querySelector('.entity-name').value.trim();
+      var select = e.querySelector('.belongs-to');
+      var selected = Array.prototype.map.call(select.selectedOptions, function (o) { return o.value; });
+      select.innerHTML = '';
+      all.filter(function (n) { return n !== own; })
+        .forEach(function (n) { option(select, n, selected.indexOf(n) >= 0); });
+    });
+  }
+
+  function design() {
+    var handlers = Array.prototype.map.call(
+      document.querySelectorAll('#handlers input:checked'), function (i) { return i.value; });
+    var entities = Array.prototype.map.call(document.querySelectorAll('.entity'), function (e) {
+      return {
+        name: e.querySelector('.entity-name').value.trim(),
+        fields: Array.prototype.map.call(e.querySelectorAll('.fields tbody tr'), function (row) {
+          return { name: row.querySelector('.field-name').value.trim(), type: row.querySelector('.field-type').value };
+        }).filter(function (f) { return f.name; }),
+        belongs_to: Array.prototype.map.call(e.querySelector('.belongs-to').selectedOptions, function (o) { return o.value; }),
+        validation: e.querySelector('.validation').checked,
+        business_rules: e.querySelector('.business-rules').checked,
+        versioned: e.querySelector('.versioned').checked,
+        cache: e.querySelector('.cache').checked
+      };
+    });
+    return { database: $('database').value, handlers: handlers, entities: entities };
+  }
+
+  function show(message, kind) {
+    $('message').textContent = message;
+    $('message').className = kind || '';
+  }
+
+  function showCommands(commands) {
+    $('commands').textContent = (commands || []).map(function (args) {
+      return 'goca ' + args.map(function (a) { return /[\s,:]/.test(a) ? '"' + a + '"' : a; }).join(' ');
+    }).join('\n');
+  }
+
+  function showFiles(files) {
+    var list = $('files');
+    list.innerHTML = '';
+    $('file-content').textContent = '';
+    (files || []).forEach(function (f) {
+      var li = document.createElement('li');
+      li.textContent = f.path;
+      li.className = f.action;
+      li.onclick = function () {
+        Array.prototype.forEach.call(list.children, function (c) { c.classList.remove('selected'); });
+        li.classList.add('selected');
+        $('file-content').textContent = f.content;
+      };
+      list.appendChild(li);
+    });
+  }
+
+  function post(path, done) {
+    var buttons = document.querySelectorAll('.actions button');
+    Array.prototype.forEach.call(buttons, function (b) { b.disabled = true; });
+    show('Working...');
+    fetch(path, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify(design())
+    }).then(function (res) {
+      return res.json().then(function (body) { return { ok: res.ok, body: body }; });
+    }).then(function (r) {
+      $('output').textContent = r.body.output || '';
+      if (!r.ok) {
+        show(r.body.error, 'error');
+        return;
+      }
+      showCommands(r.body.commands);
+      done(r.body);
+    }).catch(function (err) {
+      show(String(err), 'error');
+    }).finally(function () {
+      Array.prototype.forEach.call(buttons, function (b) { b.disabled = false; });
+    });
+  }
+
+  $('add-entity').onclick = addEntity;
+  $('show-commands').onclick = function () {
+    post('/api/commands', function () { showFiles([]); show(''); });
+  };
+  $('preview').onclick = function () {
+    post('/api/preview', function (body) {
+      showFiles(body.files);
+      show((body.files || []).length + ' files would be created or modified', 'success');
+    });
+  };
+  $('generate').onclick = function () {
+    if (!window.confirm('Generate the design in ' + ($('project').textContent || 'the project') + '?')) {
+      return;
+    }
+    post('/api/generate', function () {
+      showFiles([]);
+      show('Generated', 'success');
+      load();
+    });
+  };
+
+  function load() {
+    return fetch('/api/project').then(function (res) { return res.json(); }).then(function (p) {
+      project = p;
+      $('project').textContent = p.module || '';
+      refreshRelations();
+    });
+  }
+
+  load().then(function () {
+    option($('database'), '', !project.database);
+    project.databases.forEach(function (d) { option($('database'), d, d === project.database); });
+    project.handlers.forEach(function (h) {
+      var label = document.createElement('label');
+      label.innerHTML = '<input type="checkbox" value="' + h + '"' + (h === 'http' ? ' checked' : '') + '> ' + h;
+      $('handlers').appendChild(label);
+    });
+    addEntity();
+  }).catch(function (err) {
+    show('Could not load the project: ' + err, 'error');
+  });
+})();
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -17,10 +18,16 @@ const (
 
 // dbHandleType returns the Go type and its import path for the database handle
 // passed to the container/wire injectors. MongoDB repositories take a
-// *mongo.Database; everything else uses GORM's *gorm.DB.
+// *mongo.Database, Elasticsearch and DynamoDB ones their SDK client;
+// everything else uses GORM's *gorm.DB.
 func dbHandleType(database string) (goType, importPath string) {
-	if database == dbMongoDB {
+	switch database {
+	case dbMongoDB:
 		return "*mongo.Database", "go.mongodb.org/mongo-driver/mongo"
+	case DBElasticsearch:
+		return "*elasticsearch.Client", "github.com/elastic/go-elasticsearch/v8"
+	case DBDynamoDB:
+		return "*dynamodb.Client", "github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	}
 	return "*gorm.DB", "gorm.io/gorm"
 }
@@ -45,7 +52,7 @@ func repoConstructorPrefix(database string) string {
 	case DBDynamoDB:
 		return "DynamoDB"
 	default:
-		// postgres, mysql, sqlite and any unknown SQL backend all use the
+		// postgres, mysql, sqlite, oracle and any unknown SQL backend use the
 		// shared GORM "Postgres" repository constructor.
 		return "Postgres"
 	}
@@ -54,11 +61,21 @@ func repoConstructorPrefix(database string) string {
 var diCmd = &cobra.Command{
 	Use:   "di",
 	Short: "Generate dependency injection container",
-	Long: `Creates a dependency injection container that automatically connects 
-all layers of the system using Google Wire.`,
+	Long: `Creates a dependency injection container that automatically connects
+all layers of the system.
+
+Styles (--di, or architecture.di.type in .goca.yaml):
+  manual  hand-written container with explicit constructors (default)
+  wire    google/wire provider sets per feature, built into wire_gen.go by "make wire"
+  fx      uber/fx modules per feature, resolved into the container at startup
+  modular plain Go providers per feature with singleton or lazy lifetimes
+          (--lifetime), repository implementations selected by environment
+          variable and a self-check that fails fast on missing dependencies`,
 	Run: func(cmd *cobra.Command, _ []string) {
 		features, _ := cmd.Flags().GetString("features")
 		database, _ := cmd.Flags().GetString("database")
+		style, _ := cmd.Flags().GetString("di")
+		lifetime, _ := cmd.Flags().GetString("lifetime")
 		wire, _ := cmd.Flags().GetBool("wire")
 		cache, _ := cmd.Flags().GetBool("cache")
 
@@ -67,16 +84,33 @@ all layers of the system using Google Wire.`,
 			os.Exit(1)
 		}
 
-		// Honor the database configured in .goca.yaml when -d is not provided.
+		// --wire predates --di and is kept as an alias.
+		if wire && style == "" {
+			style = DIWire
+		}
+
+		// Honor the database and DI style configured in .goca.yaml when the
+		// flags are not provided.
 		configIntegration := NewConfigIntegration()
 		_ = configIntegration.LoadConfigForProject()
 		database = configIntegration.GetDatabaseType(database)
+		style = configIntegration.GetDIType(style)
+
+		if !isValidDIStyle(style) {
+			ui.Error(fmt.Sprintf("Invalid DI style %q; use one of: %s", style, strings.Join(ValidDIStyles, ", ")))
+			os.Exit(1)
+		}
+		lifetime = configIntegration.GetDILifetime(lifetime)
+		if !slices.Contains(ValidDILifetimes, lifetime) {
+			ui.Error(fmt.Sprintf("Invalid DI lifetime %q; use one of: %s", lifetime, strings.Join(ValidDILifetimes, ", ")))
+			os.Exit(1)
+		}
 
 		ui.Header(fmt.Sprintf("Generating DI container for features: %s", features))
 		ui.KeyValue("Database", database)
-
-		if wire {
-			ui.Feature("Using Google Wire", false)
+		ui.KeyValue("Style", style)
+		if style == DIModular {
+			ui.KeyValue("Lifetime", lifetime)
 		}
 
 		if cache {
@@ -93,18 +127,35 @@ all layers of the system using Google Wire.`,
 			ui.DryRun("Previewing changes without creating files")
 		}
 
-		generateDI(features, database, wire, cache, sm)
+		generateDI(features, database, style, lifetime, cache, sm)
 
 		if dryRun {
 			sm.PrintSummary()
 			return
 		}
 
+		if style == DIWire || style == DIFx {
+			addDIDependency(style)
+		}
+
 		ui.Success("DI container generated successfully!")
 	},
 }
 
-func generateDI(features, database string, wire, cache bool, sm ...*SafetyManager) {
+// isValidDIStyle reports whether style is one of ValidDIStyles.
+func isValidDIStyle(style string) bool {
+	for _, s := range ValidDIStyles {
+		if s == style {
+			return true
+		}
+	}
+	return false
+}
+
+// generateDI writes the DI container of features in the given style. An empty
+// lifetime takes the one configured for the project.
+func generateDI(features, database, style, lifetime string, cache bool, sm ...*SafetyManager) {
+	defer profileStep("di")()
 	diDir := "internal/di"
 	// Create di directory if it doesn't exist
 	_ = os.MkdirAll(diDir, 0o755)
@@ -115,125 +166,222 @@ func generateDI(features, database string, wire, cache bool, sm ...*SafetyManage
 		featureList[i] = strings.TrimSpace(feature)
 	}
 
-	if wire {
-		generateWireDI(diDir, featureList, database, sm...)
-	} else {
+	switch style {
+	case DIWire, DIFx:
+		generateProviderDI(diDir, featureList, database, style, cache, sm...)
+	case DIModular:
+		if lifetime == "" {
+			configIntegration := NewConfigIntegration()
+			_ = configIntegration.LoadConfigForProject()
+			lifetime = configIntegration.GetDILifetime("")
+		}
+		generateModularDI(diDir, featureList, database, lifetime, cache, sm...)
+	default:
 		generateManualDI(diDir, featureList, database, cache, sm...)
 	}
 }
 
-func generateManualDI(dir string, features []string, database string, cache bool, sm ...*SafetyManager) {
-	// Get the module name from go.mod
-	moduleName := getModuleName()
+// diFeature is a feature wired by the container templates.
+type diFeature struct {
+	Name    string // Product
+	Lower   string // product: the repository and use case fields
+	Field   string // camel case: the handler field
+	HTTP    bool   // the feature has an HTTP handler
+	Cached  bool   // the repository is wrapped in the Redis cache decorator
+	Repo    string // the expression building the repository
+	UseCase string // the expression building the use case
+}
 
-	// Use relative imports for local development and testing
-	importPath := getImportPath(moduleName)
+// newDIFeatures returns the container data of features. Repositories are
+// built for database and wrapped in their cache decorator when cache is set
+// and one was generated.
+func newDIFeatures(features []string, database string, cache bool) []diFeature {
+	result := make([]diFeature, 0, len(features))
+	for _, feature := range features {
+		result = append(result, diFeature{
+			Name:  feature,
+			Lower: strings.ToLower(feature),
+			Field: strings.ToLower(feature[:1]) + feature[1:],
+			HTTP:  hasHTTPHandler(feature),
+			// Reference the constructor the repository generator actually
+			// emits for this database (New<prefix><Entity>Repository), so the
+			// container compiles for every backend, not just Postgres.
+			Repo:    resilientRepositoryExpr(feature, fmt.Sprintf("repository.New%s%sRepository(c.db)", repoConstructorPrefix(database), feature)),
+			Cached:  cache && hasCacheDecorator(feature),
+			UseCase: useCaseConstructor(feature),
+		})
+	}
+	return result
+}
 
-	filename := filepath.Join(dir, "container.go")
+// manualDIContainer is the data of manualDITemplates.
+type manualDIContainer struct {
+	Module   string
+	DBType   string
+	DBImport string
+	Cache    bool
+	Features []diFeature
+	Handlers []diFeature
+}
 
-	// Only wire the Redis cache layer when --cache was requested AND at least
-	// one of the features has a generated cache decorator. Otherwise the
-	// redisClient field / redis + time imports would be unused (or reference a
-	// non-existent NewCached%sRepository) and the container would not compile.
-	effectiveCache := cache && anyFeatureHasCacheDecorator(features)
-	dbType, dbImport := dbHandleType(database)
+// manualDITemplates renders internal/di/container.go of the manual style. Its
+// sections are reused by the other styles and rendered on their own in tests.
+var manualDITemplates = parseGoTemplates("container.go", `package di
 
-	var content strings.Builder
-	content.WriteString("package di\n\n")
-	content.WriteString("import (\n")
-	if effectiveCache {
-		content.WriteString("\t\"time\"\n\n")
-	}
-	fmt.Fprintf(&content, "\t%q\n", dbImport)
-	if effectiveCache {
-		content.WriteString("\t\"github.com/redis/go-redis/v9\"\n")
-	}
-	content.WriteString("\n")
-	content.WriteString(fmt.Sprintf("\t\"%s/internal/repository\"\n", importPath))
-	content.WriteString(fmt.Sprintf("\t\"%s/internal/usecase\"\n", importPath))
-	content.WriteString(fmt.Sprintf("\t\"%s/internal/handler/http\"\n", importPath))
-	content.WriteString(")\n\n") // Container struct
-	content.WriteString("type Container struct {\n")
-	fmt.Fprintf(&content, "\tdb %s\n", dbType)
-	if effectiveCache {
-		content.WriteString("\tredisClient *redis.Client\n")
-	}
-	content.WriteString("\n")
+import (
+{{- if .Cache}}
+	"time"
+{{end}}
+	"{{.DBImport}}"
+{{- if .Cache}}
+	"github.com/redis/go-redis/v9"
+{{- end}}
+
+	"{{.Module}}/internal/repository"
+	"{{.Module}}/internal/usecase"
+{{- if .Handlers}}
+	"{{.Module}}/internal/handler/http"
+{{- end}}
+)
+
+type Container struct {
+	db {{.DBType}}
+{{- if .Cache}}
+	redisClient *redis.Client
+{{- end}}
 
 	// Repositories
-	content.WriteString("\t// Repositories\n")
-	for _, feature := range features {
-		featureLower := strings.ToLower(feature)
-		content.WriteString(fmt.Sprintf("\t%sRepo    repository.%sRepository\n", featureLower, feature))
-	}
+{{- range .Features}}
+	{{.Lower}}Repo repository.{{.Name}}Repository
+{{- end}}
 
 	// Use Cases
-	content.WriteString("\n\t// Use Cases\n")
-	for _, feature := range features {
-		featureLower := strings.ToLower(feature)
-		content.WriteString(fmt.Sprintf("\t%sUC    usecase.%sUseCase\n", featureLower, feature))
-	}
+{{- range .Features}}
+	{{.Lower}}UC usecase.{{.Name}}UseCase
+{{- end}}
 
 	// Handlers
-	content.WriteString("\n\t// Handlers\n")
-	for _, feature := range features {
-		fieldName := strings.ToLower(feature[:1]) + feature[1:] // camelCase
-		content.WriteString(fmt.Sprintf("\t%sHandler    *http.%sHandler\n", fieldName, feature))
-	}
+{{- range .Handlers}}
+	{{.Field}}Handler *http.{{.Name}}Handler
+{{- end}}
+}
 
-	content.WriteString("}\n\n")
+{{if .Cache -}}
+func NewContainer(db {{.DBType}}, redisClient *redis.Client) *Container {
+	c := &Container{db: db, redisClient: redisClient}
+{{- else -}}
+func NewContainer(db {{.DBType}}) *Container {
+	c := &Container{db: db}
+{{- end}}
+	c.setupRepositories()
+	c.setupUseCases()
+	c.setupHandlers()
+	return c
+}
 
-	// Constructor
-	if effectiveCache {
-		fmt.Fprintf(&content, "func NewContainer(db %s, redisClient *redis.Client) *Container {\n", dbType)
-		content.WriteString("\tc := &Container{db: db, redisClient: redisClient}\n")
-	} else {
-		fmt.Fprintf(&content, "func NewContainer(db %s) *Container {\n", dbType)
-		content.WriteString("\tc := &Container{db: db}\n")
-	}
-	content.WriteString("\tc.setupRepositories()\n")
-	content.WriteString("\tc.setupUseCases()\n")
-	content.WriteString("\tc.setupHandlers()\n")
-	content.WriteString("\treturn c\n")
-	content.WriteString("}\n\n")
+{{template "repositories" .Features}}{{template "usecases" .Features}}{{template "handlers" .Handlers}}{{template "getters" .Features}}
+
+{{- define "repositories" -}}
+func (c *Container) setupRepositories() {
+{{- range .}}
+{{- if .Cached}}
+	base{{.Name}}Repo := {{.Repo}}
+	c.{{.Lower}}Repo = repository.NewCached{{.Name}}Repository(base{{.Name}}Repo, c.redisClient, 5*time.Minute)
+{{- else}}
+	c.{{.Lower}}Repo = {{.Repo}}
+{{- end}}
+{{- end}}
+}
 
-	// Setup methods
-	generateSetupRepositories(&content, features, database, effectiveCache)
-	generateSetupUseCases(&content, features)
-	generateSetupHandlers(&content, features)
+{{end}}
+
+{{- define "usecases" -}}
+func (c *Container) setupUseCases() {
+{{- range .}}
+	c.{{.Lower}}UC = {{.UseCase}}
+{{- end}}
+}
 
-	// Getters
-	generateGetters(&content, features)
+{{end}}
 
-	if err := writeGoFile(filename, content.String(), sm...); err != nil {
+{{- define "handlers" -}}
+func (c *Container) setupHandlers() {
+{{- range .}}
+	c.{{.Field}}Handler = http.New{{.Name}}Handler(c.{{.Lower}}UC)
+{{- end}}
+}
+
+{{end}}
+
+{{- define "getters" -}}
+// Getters
+{{range .}}
+{{- if .HTTP -}}
+func (c *Container) {{.Name}}Handler() *http.{{.Name}}Handler {
+	return c.{{.Field}}Handler
+}
+
+{{end -}}
+func (c *Container) {{.Name}}UseCase() usecase.{{.Name}}UseCase {
+	return c.{{.Lower}}UC
+}
+
+func (c *Container) {{.Name}}Repository() repository.{{.Name}}Repository {
+	return c.{{.Lower}}Repo
+}
+
+{{end}}
+{{- end}}
+`)
+
+func generateManualDI(dir string, features []string, database string, cache bool, sm ...*SafetyManager) {
+	// Only wire the Redis cache layer when --cache was requested AND at least
+	// one of the features has a generated cache decorator. Otherwise the
+	// redisClient field / redis + time imports would be unused (or reference a
+	// non-existent NewCached%sRepository) and the container would not compile.
+	effectiveCache := cache && anyFeatureHasCacheDecorator(features)
+	data := manualDIContainer{
+		Module:   getImportPath(getModuleName()),
+		Cache:    effectiveCache,
+		Features: newDIFeatures(features, database, effectiveCache),
+	}
+	data.DBType, data.DBImport = dbHandleType(database)
+	for _, feature := range data.Features {
+		if feature.HTTP {
+			data.Handlers = append(data.Handlers, feature)
+		}
+	}
+
+	var content strings.Builder
+	if err := manualDITemplates.Execute(&content, data); err != nil {
+		ui.Error(fmt.Sprintf("Error writing DI file: %v", err))
+		return
+	}
+	if err := writeGoFile(filepath.Join(dir, "container.go"), content.String(), sm...); err != nil {
 		ui.Error(fmt.Sprintf("Error writing DI file: %v", err))
 		return
 	}
 }
 
-func generateSetupRepositories(content *strings.Builder, features []string, database string, cache bool) {
-	content.WriteString("func (c *Container) setupRepositories() {\n")
-
-	for _, feature := range features {
-		featureLower := strings.ToLower(feature)
-		// Reference the constructor the repository generator actually emits
-		// for this database (New<prefix><Entity>Repository), so the container
-		// compiles for every backend, not just Postgres.
-		repoConstructor := fmt.Sprintf("repository.New%s%sRepository(c.db)", repoConstructorPrefix(database), feature)
-
-		// Only wrap with the Redis cache decorator when one was actually
-		// generated for this entity (goca repository --cache). Emitting
-		// NewCached%sRepository otherwise references an undefined constructor.
-		if cache && hasCacheDecorator(feature) {
-			fmt.Fprintf(content, "\tbase%sRepo := %s\n", feature, repoConstructor)
-			fmt.Fprintf(content, "\tc.%sRepo = repository.NewCached%sRepository(base%sRepo, c.redisClient, 5*time.Minute)\n",
-				featureLower, feature, feature)
-		} else {
-			fmt.Fprintf(content, "\tc.%sRepo = %s\n", featureLower, repoConstructor)
+// hasHTTPHandler reports whether the container wires the feature's HTTP
+// handler. A feature generated with other handler types only, such as
+// --handlers cli, has none; before any handler of the feature exists the HTTP
+// handler is assumed.
+func hasHTTPHandler(feature string) bool {
+	dir := filepath.Join(DirInternal, DirHandler)
+	if findTypeFile(filepath.Join(dir, HandlerHTTP), feature+"Handler") != "" {
+		return true
+	}
+	for _, prefix := range []string{strings.ToLower(feature), toSnakeCase(feature)} {
+		if others, _ := filepath.Glob(filepath.Join(dir, "*", prefix+"_*.go")); len(others) > 0 {
+			return false
 		}
 	}
+	return true
+}
 
-	content.WriteString("}\n\n")
+func generateSetupRepositories(content *strings.Builder, features []string, database string, cache bool) {
+	writeGoTemplate(content, manualDITemplates, "repositories", newDIFeatures(features, database, cache))
 }
 
 // hasCacheDecorator reports whether a Redis cache decorator
@@ -258,233 +406,50 @@ func anyFeatureHasCacheDecorator(features []string) bool {
 	return false
 }
 
-func generateSetupUseCases(content *strings.Builder, features []string) {
-	content.WriteString("func (c *Container) setupUseCases() {\n")
-
-	for _, feature := range features {
-		featureLower := strings.ToLower(feature)
-		fmt.Fprintf(content, "\tc.%sUC = usecase.New%sService(c.%sRepo)\n",
-			featureLower, feature, featureLower)
-	}
-
-	content.WriteString("}\n\n")
-}
-
-func generateSetupHandlers(content *strings.Builder, features []string) {
-	content.WriteString("func (c *Container) setupHandlers() {\n")
-
-	for _, feature := range features {
-		fieldName := strings.ToLower(feature[:1]) + feature[1:] // camelCase
-		featureLower := strings.ToLower(feature)
-		fmt.Fprintf(content, "\tc.%sHandler = http.New%sHandler(c.%sUC)\n",
-			fieldName, feature, featureLower)
-	}
-
-	content.WriteString("}\n\n")
-}
-
-func generateGetters(content *strings.Builder, features []string) {
-	content.WriteString("// Getters\n")
-
-	for _, feature := range features {
-		fieldName := strings.ToLower(feature[:1]) + feature[1:] // camelCase
-		featureLower := strings.ToLower(feature)
-
-		// Handler getter
-		fmt.Fprintf(content, "func (c *Container) %sHandler() *http.%sHandler {\n",
-			feature, feature)
-		fmt.Fprintf(content, "\treturn c.%sHandler\n", fieldName)
-		content.WriteString("}\n\n")
-
-		// UseCase getter
-		fmt.Fprintf(content, "func (c *Container) %sUseCase() usecase.%sUseCase {\n",
-			feature, feature)
-		fmt.Fprintf(content, "\treturn c.%sUC\n", featureLower)
-		content.WriteString("}\n\n")
-
-		// Repository getter
-		fmt.Fprintf(content, "func (c *Container) %sRepository() repository.%sRepository {\n",
-			feature, feature)
-		fmt.Fprintf(content, "\treturn c.%sRepo\n", featureLower)
-		content.WriteString("}\n\n")
-	}
-}
-
-func generateWireDI(dir string, features []string, database string, sm ...*SafetyManager) {
-	// Generate wire.go
-	generateWireFile(dir, features, database, sm...)
-
-	// Generate wire_gen.go template
-	generateWireGenTemplate(dir, features, sm...)
-}
-
-func generateWireFile(dir string, features []string, database string, sm ...*SafetyManager) {
-	moduleName := getModuleName()
-	importPath := getImportPath(moduleName)
-	filename := filepath.Join(dir, "wire.go")
-
-	var content strings.Builder
-	writeWireHeader(&content)
-	writeWireImports(&content, importPath, database)
-	writeWireSets(&content, features, database)
-	writeWireFunctions(&content, features, database)
-
-	if err := writeGoFile(filename, content.String(), sm...); err != nil {
-		ui.Error(fmt.Sprintf("Error writing Wire file: %v", err))
-		return
-	}
-}
-
-// writeWireHeader writes the Wire file header with build tags.
-func writeWireHeader(content *strings.Builder) {
-	content.WriteString("//go:build wireinject\n")
-	content.WriteString("// +build wireinject\n\n")
-	content.WriteString("package di\n\n")
-}
-
-// writeWireImports writes the import section for Wire file.
-func writeWireImports(content *strings.Builder, importPath, database string) {
-	_, dbImport := dbHandleType(database)
-	content.WriteString("import (\n")
-	content.WriteString("\t\"github.com/google/wire\"\n")
-	fmt.Fprintf(content, "\t%q\n\n", dbImport)
-	fmt.Fprintf(content, "\t\"%s/internal/repository\"\n", importPath)
-	fmt.Fprintf(content, "\t\"%s/internal/usecase\"\n", importPath)
-	fmt.Fprintf(content, "\t\"%s/internal/handler/http\"\n", importPath)
-	content.WriteString(")\n\n")
-}
-
-// writeWireSets writes all Wire sets (Repository, UseCase, Handler, All).
-func writeWireSets(content *strings.Builder, features []string, database string) {
-	content.WriteString("// Wire sets\n")
-	content.WriteString("var (\n")
-
-	writeRepositorySet(content, features, database)
-	writeUseCaseSet(content, features)
-	writeHandlerSet(content, features)
-	writeAllSet(content)
-
-	content.WriteString(")\n\n")
+// useCaseConstructor returns the expression that builds a feature's use case
+// in the container, wrapped in the tracing decorator when one was generated
+// (goca feature --tracing).
+func useCaseConstructor(feature string) string {
+	return useCaseExpr(feature, fmt.Sprintf("c.%sRepo", strings.ToLower(feature)), "c.db")
 }
 
-// writeRepositorySet writes the Repository Wire set.
-func writeRepositorySet(content *strings.Builder, features []string, database string) {
-	content.WriteString("\tRepositorySet = wire.NewSet(\n")
-	for _, feature := range features {
-		fmt.Fprintf(content, "\t\trepository.New%s%sRepository,\n", repoConstructorPrefix(database), feature)
+// useCaseExpr builds a feature's use case from the given repository and
+// database handle expressions. The queries of a CQRS use case read from the
+// replica of the database handle. The audit decorator goes outermost so
+// handlers find it by type assertion.
+func useCaseExpr(feature, repo, db string) string {
+	expr := fmt.Sprintf("usecase.New%sService(%s)", feature, repo)
+	if hasCQRSUseCase(feature) {
+		expr = fmt.Sprintf("usecase.New%sCQRSUseCase(%s, repository.New%sReader(%s))", feature, repo, feature, db)
 	}
-	content.WriteString("\t)\n\n")
-}
-
-// writeUseCaseSet writes the UseCase Wire set.
-func writeUseCaseSet(content *strings.Builder, features []string) {
-	content.WriteString("\tUseCaseSet = wire.NewSet(\n")
-	for _, feature := range features {
-		fmt.Fprintf(content, "\t\tusecase.New%sService,\n", feature)
+	if hasTracingDecorator(feature) {
+		expr = fmt.Sprintf("usecase.NewTraced%sUseCase(%s)", feature, expr)
 	}
-	content.WriteString("\t)\n\n")
-}
-
-// writeHandlerSet writes the Handler Wire set.
-func writeHandlerSet(content *strings.Builder, features []string) {
-	content.WriteString("\tHandlerSet = wire.NewSet(\n")
-	for _, feature := range features {
-		fmt.Fprintf(content, "\t\thttp.New%sHandler,\n", feature)
+	if hasAuditedUseCase(feature) {
+		expr = fmt.Sprintf("usecase.NewAudited%sUseCase(%s, %s, repository.NewAuditLogRepository(%s))", feature, expr, repo, db)
 	}
-	content.WriteString("\t)\n\n")
+	return expr
 }
 
-// writeAllSet writes the combined All Wire set.
-func writeAllSet(content *strings.Builder) {
-	content.WriteString("\tAllSet = wire.NewSet(\n")
-	content.WriteString("\t\tRepositorySet,\n")
-	content.WriteString("\t\tUseCaseSet,\n")
-	content.WriteString("\t\tHandlerSet,\n")
-	content.WriteString("\t)\n")
+func generateSetupUseCases(content *strings.Builder, features []string) {
+	writeGoTemplate(content, manualDITemplates, "usecases", newDIFeatures(features, "", false))
 }
 
-// writeWireFunctions writes Wire initialization functions.
-//
-// All injectors take the GORM *gorm.DB (the type repository constructors
-// expect) and return the WireContainer that NewWireContainer provides, keeping
-// the provider graph and the declared return types consistent so `wire` can
-// generate code without errors.
-func writeWireFunctions(content *strings.Builder, features []string, database string) {
-	dbType, _ := dbHandleType(database)
-	for _, feature := range features {
-		fmt.Fprintf(content, "func Initialize%sHandler(db %s) *http.%sHandler {\n",
-			feature, dbType, feature)
-		content.WriteString("\twire.Build(AllSet)\n")
-		fmt.Fprintf(content, "\treturn &http.%sHandler{}\n", feature)
-		content.WriteString("}\n\n")
-	}
-
-	fmt.Fprintf(content, "func InitializeContainer(db %s) *WireContainer {\n", dbType)
-	content.WriteString("\twire.Build(\n")
-	content.WriteString("\t\tAllSet,\n")
-	content.WriteString("\t\tNewWireContainer,\n")
-	content.WriteString("\t)\n")
-	content.WriteString("\treturn &WireContainer{}\n")
-	content.WriteString("}\n")
+func generateSetupHandlers(content *strings.Builder, features []string) {
+	writeGoTemplate(content, manualDITemplates, "handlers", newDIFeatures(features, "", false))
 }
 
-func generateWireGenTemplate(dir string, features []string, sm ...*SafetyManager) {
-	// Get the module name from go.mod
-	moduleName := getModuleName()
-
-	// Use relative imports for local development and testing
-	importPath := getImportPath(moduleName)
-
-	filename := filepath.Join(dir, "wire_container.go")
-
-	var content strings.Builder
-	content.WriteString("package di\n\n")
-	content.WriteString("import (\n")
-	content.WriteString(fmt.Sprintf("\t\"%s/internal/handler/http\"\n", importPath))
-	content.WriteString(")\n\n")
-	// Wire container struct. Named WireContainer to avoid colliding with the
-	// regular Container generated in container.go (same package).
-	content.WriteString("type WireContainer struct {\n")
-	for _, feature := range features {
-		featureLower := strings.ToLower(feature)
-		content.WriteString(fmt.Sprintf("\t%sHandler *http.%sHandler\n", featureLower, feature))
-	}
-	content.WriteString("}\n\n")
-
-	// Constructor
-	content.WriteString("func NewWireContainer(\n")
-	for _, feature := range features {
-		featureLower := strings.ToLower(feature)
-		content.WriteString(fmt.Sprintf("\t%sHandler *http.%sHandler,\n", featureLower, feature))
-	}
-	content.WriteString(") *WireContainer {\n")
-	content.WriteString("\treturn &WireContainer{\n")
-	for _, feature := range features {
-		featureLower := strings.ToLower(feature)
-		content.WriteString(fmt.Sprintf("\t\t%sHandler: %sHandler,\n", featureLower, featureLower))
-	}
-	content.WriteString("\t}\n")
-	content.WriteString("}\n\n")
-
-	// Getters
-	for _, feature := range features {
-		featureLower := strings.ToLower(feature)
-		content.WriteString(fmt.Sprintf("func (c *WireContainer) %sHandler() *http.%sHandler {\n",
-			feature, feature))
-		content.WriteString(fmt.Sprintf("\treturn c.%sHandler\n", featureLower))
-		content.WriteString("}\n\n")
-	}
-
-	if err := writeGoFile(filename, content.String(), sm...); err != nil {
-		ui.Error(fmt.Sprintf("Error writing Wire generator file: %v", err))
-		return
-	}
+func generateGetters(content *strings.Builder, features []string) {
+	writeGoTemplate(content, manualDITemplates, "getters", newDIFeatures(features, "", false))
 }
 
 func init() {
 	diCmd.Flags().StringP("features", "f", "", "Project features (crud,auth,validation,etc)")
 	diCmd.Flags().StringP("database", "d", "", "Database type (postgres, mysql, mongodb); defaults to the project configuration")
+	diCmd.Flags().String("di", "", "Dependency injection style (manual, wire, fx, modular); defaults to the project configuration")
+	diCmd.Flags().String("lifetime", "", "When the modular style builds the components of a feature (singleton, lazy); defaults to architecture.di.lifetime")
 	diCmd.Flags().BoolP("wire", "w", false, "Use Google Wire for dependency injection")
+	_ = diCmd.Flags().MarkDeprecated("wire", "use --di wire")
 	diCmd.Flags().BoolP("cache", "c", false, "Wire Redis cache decorators for repositories")
 	diCmd.Flags().Bool("dry-run", false, "Preview changes without creating files")
 	diCmd.Flags().Bool("force", false, "Overwrite existing files without asking")
@@ -81,90 +81,17 @@ func TestWriteWireHeader(t *testing.T) {
 func TestWriteWireImports(t *testing.T) {
 	t.Parallel()
 	var b strings.Builder
-	writeWireImports(&b, "mymodule", "postgres")
+	writeWireImports(&b, "postgres", false)
 	output := b.String()
 	assert.Contains(t, output, "import (")
 	// Repositories take *gorm.DB, so wire injectors must too (not database/sql).
 	assert.Contains(t, output, "gorm.io/gorm")
 	assert.NotContains(t, output, "database/sql")
 	assert.Contains(t, output, "github.com/google/wire")
-	assert.Contains(t, output, "mymodule/internal/repository")
-	assert.Contains(t, output, "mymodule/internal/usecase")
+	assert.NotContains(t, output, "go-redis")
 
 	var bMongo strings.Builder
-	writeWireImports(&bMongo, "mymodule", "mongodb")
+	writeWireImports(&bMongo, "mongodb", true)
 	assert.Contains(t, bMongo.String(), "go.mongodb.org/mongo-driver/mongo")
-}
-
-func TestWriteWireSets(t *testing.T) {
-	t.Parallel()
-	var b strings.Builder
-	writeWireSets(&b, []string{"Product"}, "postgres")
-	output := b.String()
-	assert.Contains(t, output, "Wire sets")
-	assert.Contains(t, output, "RepositorySet")
-	assert.Contains(t, output, "UseCaseSet")
-	assert.Contains(t, output, "HandlerSet")
-	assert.Contains(t, output, "AllSet")
-}
-
-func TestWriteRepositorySet(t *testing.T) {
-	t.Parallel()
-
-	cases := []struct {
-		name     string
-		database string
-		expected string
-	}{
-		{"postgres", "postgres", "NewPostgresProductRepository"},
-		{"mysql", "mysql", "NewPostgresProductRepository"},
-		{"mongodb", "mongodb", "NewMongoProductRepository"},
-		{"default", "other", "NewPostgresProductRepository"},
-	}
-
-	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			t.Parallel()
-			var b strings.Builder
-			writeRepositorySet(&b, []string{"Product"}, tc.database)
-			assert.Contains(t, b.String(), tc.expected)
-		})
-	}
-}
-
-func TestWriteUseCaseSet(t *testing.T) {
-	t.Parallel()
-	var b strings.Builder
-	writeUseCaseSet(&b, []string{"Product", "User"})
-	output := b.String()
-	assert.Contains(t, output, "usecase.NewProductService")
-	assert.Contains(t, output, "usecase.NewUserService")
-}
-
-func TestWriteHandlerSet(t *testing.T) {
-	t.Parallel()
-	var b strings.Builder
-	writeHandlerSet(&b, []string{"Product"})
-	assert.Contains(t, b.String(), "http.NewProductHandler")
-}
-
-func TestWriteAllSet(t *testing.T) {
-	t.Parallel()
-	var b strings.Builder
-	writeAllSet(&b)
-	output := b.String()
-	assert.Contains(t, output, "RepositorySet")
-	assert.Contains(t, output, "UseCaseSet")
-	assert.Contains(t, output, "HandlerSet")
-}
-
-func TestWriteWireFunctions(t *testing.T) {
-	t.Parallel()
-	var b strings.Builder
-	writeWireFunctions(&b, []string{"Product"}, "postgres")
-	output := b.String()
-	assert.Contains(t, output, "func InitializeProductHandler(db *gorm.DB)")
-	assert.Contains(t, output, "wire.Build(AllSet)")
-	// Return type must match the only available provider (NewWireContainer).
-	assert.Contains(t, output, "func InitializeContainer(db *gorm.DB) *WireContainer")
+	assert.Contains(t, bMongo.String(), "github.com/redis/go-redis/v9")
 }
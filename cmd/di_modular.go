@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// modularProvidersPattern finds the feature name in a modular providers file.
+var modularProvidersPattern = regexp.MustCompile(`func new(\w+)Providers\(`)
+
+// generateModularDI generates the modular DI style: one providers file per
+// feature that builds its components with the given lifetime and selects the
+// repository implementation from the environment, plus a container rebuilt
+// from every providers file in dir. Adding a feature never patches existing
+// code, so container.go only grows by a field and a check per feature.
+func generateModularDI(dir string, features []string, database, lifetime string, cache bool, sm ...*SafetyManager) {
+	all := detectModularFeatures(dir)
+	for _, feature := range features {
+		pf := providerFeature{Name: feature, Redis: cache && hasCacheDecorator(feature)}
+		if generateModularProviders(dir, pf, database, lifetime, sm...) {
+			all = mergeProviderFeature(all, pf)
+		}
+	}
+
+	if len(all) == 0 {
+		return
+	}
+	generateModularContainer(dir, all, database, sm...)
+}
+
+// isModularContainer reports whether container.go was generated for the
+// modular style.
+func isModularContainer(content string) bool {
+	return strings.Contains(content, "func (c *Container) Validate() error")
+}
+
+// detectModularFeatures lists the features that already have a modular
+// providers file.
+func detectModularFeatures(dir string) []providerFeature {
+	files, _ := filepath.Glob(filepath.Join(dir, "*_providers.go"))
+	var features []providerFeature
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		match := modularProvidersPattern.FindSubmatch(data)
+		if match == nil {
+			continue
+		}
+		features = append(features, providerFeature{
+			Name:  string(match[1]),
+			Redis: strings.Contains(string(data), "*redis.Client"),
+		})
+	}
+	return features
+}
+
+// generateModularProviders writes the modular providers file of one feature
+// and reports whether the feature is available to the container. An existing
+// file is kept unless the SafetyManager allows overwriting it, so the
+// lifetime and the implementations registered there survive regeneration.
+func generateModularProviders(dir string, pf providerFeature, database, lifetime string, sm ...*SafetyManager) bool {
+	filename := providerFileName(dir, pf.Name)
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+	if _, err := os.Stat(filename); err == nil && !force {
+		ui.Dim(fmt.Sprintf("   %s providers already exist", pf.Name))
+		return true
+	}
+
+	importPath := getImportPath(getModuleName())
+	dbType, dbImport := dbHandleType(database)
+	feature := pf.Name
+	field := strings.ToLower(feature[:1]) + feature[1:] // camelCase
+	env := strings.ToUpper(toSnakeCase(feature)) + "_REPOSITORY"
+	handler := hasHTTPHandler(feature)
+	lazy := lifetime == DILifetimeLazy
+	deps := containerParams(database, pf.Redis)
+
+	var content strings.Builder
+	content.WriteString("package di\n\n")
+	content.WriteString("import (\n")
+	if !lazy {
+		content.WriteString("\t\"errors\"\n")
+	}
+	content.WriteString("\t\"fmt\"\n")
+	content.WriteString("\t\"sync\"\n")
+	if pf.Redis {
+		content.WriteString("\t\"time\"\n\n")
+		content.WriteString("\t\"github.com/redis/go-redis/v9\"\n")
+	}
+	fmt.Fprintf(&content, "\n\t%q\n\n", dbImport)
+	if handler {
+		fmt.Fprintf(&content, "\t\"%s/internal/handler/http\"\n", importPath)
+	}
+	fmt.Fprintf(&content, "\t\"%s/internal/repository\"\n", importPath)
+	fmt.Fprintf(&content, "\t\"%s/internal/usecase\"\n", importPath)
+	content.WriteString(")\n\n")
+
+	fmt.Fprintf(&content, "// %sRepositoryEnv selects the %s repository implementation, %q when unset.\n", field, feature, database)
+	fmt.Fprintf(&content, "const %sRepositoryEnv = %q\n\n", field, env)
+
+	fmt.Fprintf(&content, "// %sRepositories are the %s repository implementations %s selects from.\n", field, feature, env)
+	fmt.Fprintf(&content, "var %sRepositories = map[string]func(db %s) repository.%sRepository{\n", field, dbType, feature)
+	fmt.Fprintf(&content, "\t%q: func(db %s) repository.%sRepository {\n", database, dbType, feature)
+	fmt.Fprintf(&content, "\t\treturn repository.New%s%sRepository(db)\n", repoConstructorPrefix(database), feature)
+	content.WriteString("\t},\n")
+	content.WriteString("}\n\n")
+
+	fmt.Fprintf(&content, "// Register%sRepository makes a %s repository implementation, such as an\n", feature, feature)
+	fmt.Fprintf(&content, "// in-memory one for tests, selectable with %s=<name>. Register it\n", env)
+	content.WriteString("// before NewContainer.\n")
+	fmt.Fprintf(&content, "func Register%sRepository(name string, build func(db %s) repository.%sRepository) {\n", feature, dbType, feature)
+	fmt.Fprintf(&content, "\t%sRepositories[name] = build\n", field)
+	content.WriteString("}\n\n")
+
+	if lazy {
+		fmt.Fprintf(&content, "// %sProviders builds the %s components on first use (lazy lifetime).\n", field, feature)
+	} else {
+		fmt.Fprintf(&content, "// %sProviders builds the %s components when the container is created\n", field, feature)
+		content.WriteString("// (singleton lifetime).\n")
+	}
+	fmt.Fprintf(&content, "type %sProviders struct {\n", field)
+	fmt.Fprintf(&content, "\tdb %s\n", dbType)
+	if pf.Redis {
+		content.WriteString("\tredisClient *redis.Client\n")
+	}
+	content.WriteString("\tonce sync.Once\n")
+	fmt.Fprintf(&content, "\trepo repository.%sRepository\n", feature)
+	fmt.Fprintf(&content, "\tuc usecase.%sUseCase\n", feature)
+	if handler {
+		fmt.Fprintf(&content, "\thandler *http.%sHandler\n", feature)
+	}
+	content.WriteString("}\n\n")
+
+	fmt.Fprintf(&content, "func new%sProviders(%s) *%sProviders {\n", feature, deps, field)
+	if pf.Redis {
+		fmt.Fprintf(&content, "\treturn &%sProviders{db: db, redisClient: redisClient}\n", field)
+	} else {
+		fmt.Fprintf(&content, "\treturn &%sProviders{db: db}\n", field)
+	}
+	content.WriteString("}\n\n")
+
+	fmt.Fprintf(&content, "// build creates the %s components once.\n", feature)
+	fmt.Fprintf(&content, "func (p *%sProviders) build() {\n", field)
+	content.WriteString("\tp.once.Do(func() {\n")
+	repo := resilientRepositoryExpr(feature, fmt.Sprintf("%sRepositories[implementation(%sRepositoryEnv, %q)](p.db)", field, field, database))
+	if pf.Redis {
+		fmt.Fprintf(&content, "\t\tp.repo = repository.NewCached%sRepository(%s, p.redisClient, 5*time.Minute)\n", feature, repo)
+	} else {
+		fmt.Fprintf(&content, "\t\tp.repo = %s\n", repo)
+	}
+	fmt.Fprintf(&content, "\t\tp.uc = %s\n", useCaseExpr(feature, "p.repo", "p.db"))
+	if handler {
+		fmt.Fprintf(&content, "\t\tp.handler = http.New%sHandler(p.uc)\n", feature)
+	}
+	content.WriteString("\t})\n")
+	content.WriteString("}\n\n")
+
+	if lazy {
+		fmt.Fprintf(&content, "// check reports the missing %s dependencies. The components are lazy, so\n", feature)
+		content.WriteString("// only the selected repository implementation is checked.\n")
+	} else {
+		fmt.Fprintf(&content, "// check builds the %s components and reports the missing dependencies.\n", feature)
+	}
+	fmt.Fprintf(&content, "func (p *%sProviders) check() []error {\n", field)
+	fmt.Fprintf(&content, "\tname := implementation(%sRepositoryEnv, %q)\n", field, database)
+	fmt.Fprintf(&content, "\tif %sRepositories[name] == nil {\n", field)
+	fmt.Fprintf(&content, "\t\treturn []error{fmt.Errorf(\"%%s=%%s is not a registered %s repository\", %sRepositoryEnv, name)}\n", feature, field)
+	content.WriteString("\t}\n")
+	if lazy {
+		content.WriteString("\treturn nil\n")
+	} else {
+		content.WriteString("\tp.build()\n\n")
+		content.WriteString("\tvar errs []error\n")
+		checks := [][2]string{{"repo", "repository"}, {"uc", "use case"}}
+		if handler {
+			checks = append(checks, [2]string{"handler", "handler"})
+		}
+		for _, c := range checks {
+			fmt.Fprintf(&content, "\tif isNil(p.%s) {\n", c[0])
+			fmt.Fprintf(&content, "\t\terrs = append(errs, errors.New(\"nil %s %s\"))\n", feature, c[1])
+			content.WriteString("\t}\n")
+		}
+		content.WriteString("\treturn errs\n")
+	}
+	content.WriteString("}\n\n")
+
+	getters := [][4]string{
+		{"Repository", "repository." + feature + "Repository", "repo", "repository"},
+		{"UseCase", "usecase." + feature + "UseCase", "uc", "use case"},
+	}
+	if handler {
+		getters = append(getters, [4]string{"Handler", "*http." + feature + "Handler", "handler", "HTTP handler"})
+	}
+	for _, g := range getters {
+		fmt.Fprintf(&content, "// %s%s returns the %s %s.\n", feature, g[0], feature, g[3])
+		fmt.Fprintf(&content, "func (c *Container) %s%s() %s {\n", feature, g[0], g[1])
+		fmt.Fprintf(&content, "\tc.%s.build()\n", field)
+		fmt.Fprintf(&content, "\treturn c.%s.%s\n", field, g[2])
+		content.WriteString("}\n\n")
+	}
+
+	if err := writeGoFile(filename, content.String(), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing %s providers: %v", feature, err))
+		return false
+	}
+	return true
+}
+
+// generateModularContainer writes container.go: a Container holding the
+// providers of every feature, whose NewContainer fails fast through Validate.
+func generateModularContainer(dir string, features []providerFeature, database string, sm ...*SafetyManager) {
+	redis := anyProviderNeedsRedis(features)
+	dbType, dbImport := dbHandleType(database)
+
+	var content strings.Builder
+	content.WriteString("package di\n\n")
+	content.WriteString("import (\n")
+	content.WriteString("\t\"errors\"\n")
+	content.WriteString("\t\"fmt\"\n")
+	content.WriteString("\t\"os\"\n")
+	content.WriteString("\t\"reflect\"\n\n")
+	if redis {
+		content.WriteString("\t\"github.com/redis/go-redis/v9\"\n")
+	}
+	fmt.Fprintf(&content, "\t%q\n", dbImport)
+	content.WriteString(")\n\n")
+
+	content.WriteString("// Container holds the providers of every feature.\n")
+	content.WriteString("// Regenerated from the *_providers.go files; do not edit.\n")
+	content.WriteString("type Container struct {\n")
+	fmt.Fprintf(&content, "\tdb %s\n", dbType)
+	if redis {
+		content.WriteString("\tredisClient *redis.Client\n")
+	}
+	content.WriteString("\n")
+	for _, f := range features {
+		field := strings.ToLower(f.Name[:1]) + f.Name[1:] // camelCase
+		fmt.Fprintf(&content, "\t%s *%sProviders\n", field, field)
+	}
+	content.WriteString("}\n\n")
+
+	content.WriteString("// NewContainer creates the providers of every feature and checks them with\n")
+	content.WriteString("// Validate. A missing dependency is a programming error and panics, so it\n")
+	content.WriteString("// surfaces at startup rather than on the first request.\n")
+	fmt.Fprintf(&content, "func NewContainer(%s) *Container {\n", containerParams(database, redis))
+	content.WriteString("\tc := &Container{\n")
+	content.WriteString("\t\tdb: db,\n")
+	if redis {
+		content.WriteString("\t\tredisClient: redisClient,\n")
+	}
+	for _, f := range features {
+		field := strings.ToLower(f.Name[:1]) + f.Name[1:] // camelCase
+		args := "db"
+		if f.Redis {
+			args += ", redisClient"
+		}
+		fmt.Fprintf(&content, "\t\t%s: new%sProviders(%s),\n", field, f.Name, args)
+	}
+	content.WriteString("\t}\n")
+	content.WriteString("\tif err := c.Validate(); err != nil {\n")
+	content.WriteString("\t\tpanic(fmt.Sprintf(\"di: %v\", err))\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\treturn c\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString("// Validate reports every missing dependency of the container: a nil database\n")
+	content.WriteString("// handle, a repository implementation the environment selects that is not\n")
+	content.WriteString("// registered and, for singleton features, a component built as nil.\n")
+	content.WriteString("func (c *Container) Validate() error {\n")
+	content.WriteString("\tvar errs []error\n")
+	content.WriteString("\tif isNil(c.db) {\n")
+	content.WriteString("\t\terrs = append(errs, errors.New(\"nil database handle\"))\n")
+	content.WriteString("\t}\n")
+	if redis {
+		content.WriteString("\tif isNil(c.redisClient) {\n")
+		content.WriteString("\t\terrs = append(errs, errors.New(\"nil Redis client\"))\n")
+		content.WriteString("\t}\n")
+	}
+	content.WriteString("\tif len(errs) > 0 {\n")
+	content.WriteString("\t\treturn errors.Join(errs...)\n")
+	content.WriteString("\t}\n\n")
+	for _, f := range features {
+		field := strings.ToLower(f.Name[:1]) + f.Name[1:] // camelCase
+		fmt.Fprintf(&content, "\terrs = append(errs, c.%s.check()...)\n", field)
+	}
+	content.WriteString("\treturn errors.Join(errs...)\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString(modularContainerHelpers)
+
+	if err := writeGoFileMerged(filepath.Join(dir, "container.go"), content.String(), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing DI file: %v", err))
+	}
+}
+
+// modularContainerHelpers are the helpers the modular providers share.
+const modularContainerHelpers = `// implementation returns the implementation the environment variable key
+// selects, or fallback when it is unset.
+func implementation(key, fallback string) string {
+	if name := os.Getenv(key); name != "" {
+		return name
+	}
+	return fallback
+}
+
+// isNil reports whether v is nil or holds a nil pointer, map, slice, func or
+// channel.
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+`
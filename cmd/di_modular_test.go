@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateModularDI(t *testing.T) {
+	defer ensureTestUI(t)()
+	diDir := setupDIProject(t)
+
+	sm := NewSafetyManager(false, true, false)
+	generateModularDI(diDir, []string{"Product"}, "postgres", DILifetimeSingleton, false, sm)
+	generateModularDI(diDir, []string{"OrderItem"}, "postgres", DILifetimeLazy, false, sm)
+
+	product := readParsedGo(t, filepath.Join(diDir, "product_providers.go"))
+	assert.Contains(t, product, `const productRepositoryEnv = "PRODUCT_REPOSITORY"`)
+	assert.Contains(t, product, "\t\"postgres\": func(db *gorm.DB) repository.ProductRepository {\n\t\treturn repository.NewPostgresProductRepository(db)")
+	assert.Contains(t, product, "func RegisterProductRepository(name string, build func(db *gorm.DB) repository.ProductRepository) {")
+	assert.Contains(t, product, `p.repo = productRepositories[implementation(productRepositoryEnv, "postgres")](p.db)`)
+	assert.Contains(t, product, "p.handler = http.NewProductHandler(p.uc)")
+	assert.Contains(t, product, "(singleton lifetime)")
+	assert.Contains(t, product, "\tp.build()\n\n\tvar errs []error\n\tif isNil(p.repo) {")
+	assert.Contains(t, product, "func (c *Container) ProductUseCase() usecase.ProductUseCase {\n\tc.product.build()\n\treturn c.product.uc\n}")
+
+	// Lazy components are built by the getters only.
+	item := readParsedGo(t, filepath.Join(diDir, "order_item_providers.go"))
+	assert.Contains(t, item, `const orderItemRepositoryEnv = "ORDER_ITEM_REPOSITORY"`)
+	assert.Contains(t, item, "(lazy lifetime)")
+	assert.NotContains(t, item, "p.build()\n\n\tvar errs")
+	assert.NotContains(t, item, `"errors"`)
+
+	// The container covers the features added in separate runs.
+	container := readParsedGo(t, filepath.Join(diDir, "container.go"))
+	assert.True(t, isModularContainer(container))
+	assert.True(t, isProviderContainer(container))
+	assert.Contains(t, container, "orderItem *orderItemProviders")
+	assert.Contains(t, container, "product:   newProductProviders(db),")
+	assert.Contains(t, container, "errs = append(errs, c.orderItem.check()...)")
+	assert.Contains(t, container, "panic(fmt.Sprintf(\"di: %v\", err))")
+	assert.ElementsMatch(t, []providerFeature{{Name: "OrderItem"}, {Name: "Product"}}, detectModularFeatures(diDir))
+	assert.Equal(t, map[string]bool{"OrderItem": true, "Product": true}, containerRepositoryGetters(diDir))
+}
+
+func TestUpdateDIContainer_Modular(t *testing.T) {
+	defer ensureTestUI(t)()
+	diDir := setupDIProject(t)
+	sm := NewSafetyManager(false, true, false)
+
+	updateDIContainer("Product", "mongodb", DIModular, false, sm)
+	updateDIContainer("Order", "mongodb", DIModular, false, sm)
+	container := readParsedGo(t, filepath.Join(diDir, "container.go"))
+	assert.Contains(t, container, "func NewContainer(db *mongo.Database) *Container {")
+	assert.Contains(t, container, "order:   newOrderProviders(db),")
+	assert.Contains(t, readParsedGo(t, providerFileName(diDir, "Order")), "return repository.NewMongoOrderRepository(db)")
+
+	// A modular container is not replaced by another provider style.
+	updateDIContainer("User", "mongodb", DIFx, false, sm)
+	_, err := os.Stat(providerFileName(diDir, "User"))
+	assert.True(t, os.IsNotExist(err))
+	assert.True(t, isModularContainer(readParsedGo(t, filepath.Join(diDir, "container.go"))))
+}
+
+func TestGetDILifetime(t *testing.T) {
+	ci := &ConfigIntegration{}
+	assert.Equal(t, DILifetimeSingleton, ci.GetDILifetime(""))
+	assert.Equal(t, DILifetimeLazy, ci.GetDILifetime("lazy"))
+
+	ci.config = &GocaConfig{Architecture: ArchitectureConfig{DI: DIConfig{Type: DIModular, Lifetime: DILifetimeLazy}}}
+	assert.Equal(t, DILifetimeLazy, ci.GetDILifetime(""))
+	assert.Equal(t, DILifetimeSingleton, ci.GetDILifetime("singleton"))
+	require.Equal(t, DIModular, ci.GetDIType(""))
+}
@@ -0,0 +1,414 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// wireVersion and fxVersion pin the DI libraries added to go.mod.
+const (
+	wireVersion = "v0.6.0"
+	fxVersion   = "v1.22.1"
+)
+
+// providerFeature describes a feature that has an internal/di/<feature>_providers.go file.
+type providerFeature struct {
+	Name  string
+	Redis bool // repository provider takes a *redis.Client
+}
+
+// providerHandlerPattern finds the feature name in a providers file.
+var providerHandlerPattern = regexp.MustCompile(`func Provide(\w+)Handler\(`)
+
+// generateProviderDI generates wire or fx dependency injection: one providers
+// file per feature with a constructor per layer, plus the container and the
+// injector (wire.go) or module (fx.go). The latter two are rebuilt from every
+// providers file in dir, so adding a feature never patches existing code.
+func generateProviderDI(dir string, features []string, database, style string, cache bool, sm ...*SafetyManager) {
+	all := detectProviderFeatures(dir)
+	for _, feature := range features {
+		pf := providerFeature{Name: feature, Redis: cache && hasCacheDecorator(feature)}
+		if generateFeatureProviders(dir, pf, database, style, sm...) {
+			all = mergeProviderFeature(all, pf)
+		}
+	}
+
+	if len(all) == 0 {
+		return
+	}
+
+	generateProviderContainer(dir, all, style, sm...)
+
+	if style == DIWire {
+		generateWireInjector(dir, all, database, sm...)
+		ensureMakefileWireTarget(sm...)
+	} else {
+		generateFxModule(dir, all, database, sm...)
+	}
+}
+
+// isProviderContainer reports whether container.go was generated for the
+// wire, fx or modular style rather than the manual one.
+func isProviderContainer(content string) bool {
+	return strings.Contains(content, "func newContainer(") || isModularContainer(content)
+}
+
+// detectProviderFeatures lists the features that already have a providers file.
+func detectProviderFeatures(dir string) []providerFeature {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var features []providerFeature
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), "_providers.go") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		match := providerHandlerPattern.FindSubmatch(data)
+		if match == nil {
+			continue
+		}
+		features = append(features, providerFeature{
+			Name:  string(match[1]),
+			Redis: strings.Contains(string(data), "*redis.Client"),
+		})
+	}
+	return features
+}
+
+// mergeProviderFeature adds pf to features, replacing an entry with the same name.
+func mergeProviderFeature(features []providerFeature, pf providerFeature) []providerFeature {
+	for i, existing := range features {
+		if existing.Name == pf.Name {
+			features[i] = pf
+			return features
+		}
+	}
+	return append(features, pf)
+}
+
+func anyProviderNeedsRedis(features []providerFeature) bool {
+	for _, f := range features {
+		if f.Redis {
+			return true
+		}
+	}
+	return false
+}
+
+// providerFileName returns internal/di/<feature>_providers.go for a feature.
+func providerFileName(dir, feature string) string {
+	return filepath.Join(dir, toSnakeCase(feature)+"_providers.go")
+}
+
+// generateFeatureProviders writes the providers file for one feature and
+// reports whether the feature is available to the container. An existing file
+// is kept unless the SafetyManager allows overwriting it.
+func generateFeatureProviders(dir string, pf providerFeature, database, style string, sm ...*SafetyManager) bool {
+	filename := providerFileName(dir, pf.Name)
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+	if _, err := os.Stat(filename); err == nil && !force {
+		ui.Dim(fmt.Sprintf("   %s providers already exist", pf.Name))
+		return true
+	}
+
+	importPath := getImportPath(getModuleName())
+	dbType, dbImport := dbHandleType(database)
+	feature := pf.Name
+
+	var content strings.Builder
+	content.WriteString("package di\n\n")
+	content.WriteString("import (\n")
+	if pf.Redis {
+		content.WriteString("\t\"time\"\n\n")
+		content.WriteString("\t\"github.com/redis/go-redis/v9\"\n")
+	}
+	if style == DIWire {
+		content.WriteString("\t\"github.com/google/wire\"\n")
+	} else {
+		content.WriteString("\t\"go.uber.org/fx\"\n")
+	}
+	fmt.Fprintf(&content, "\t%q\n\n", dbImport)
+	fmt.Fprintf(&content, "\t\"%s/internal/handler/http\"\n", importPath)
+	fmt.Fprintf(&content, "\t\"%s/internal/repository\"\n", importPath)
+	fmt.Fprintf(&content, "\t\"%s/internal/usecase\"\n", importPath)
+	content.WriteString(")\n\n")
+
+	providers := fmt.Sprintf("Provide%sRepository, Provide%sUseCase, Provide%sHandler", feature, feature, feature)
+	if style == DIWire {
+		fmt.Fprintf(&content, "// %sSet provides the %s repository, use case and handler.\n", feature, feature)
+		fmt.Fprintf(&content, "var %sSet = wire.NewSet(%s)\n\n", feature, providers)
+	} else {
+		fmt.Fprintf(&content, "// %sModule provides the %s repository, use case and handler.\n", feature, feature)
+		fmt.Fprintf(&content, "var %sModule = fx.Module(%q, fx.Provide(%s))\n\n", feature, strings.ToLower(feature), providers)
+	}
+
+	repoConstructor := resilientRepositoryExpr(feature, fmt.Sprintf("repository.New%s%sRepository(db)", repoConstructorPrefix(database), feature))
+	fmt.Fprintf(&content, "// Provide%sRepository builds the %s repository.\n", feature, feature)
+	if pf.Redis {
+		fmt.Fprintf(&content, "func Provide%sRepository(db %s, redisClient *redis.Client) repository.%sRepository {\n", feature, dbType, feature)
+		fmt.Fprintf(&content, "\treturn repository.NewCached%sRepository(%s, redisClient, 5*time.Minute)\n", feature, repoConstructor)
+	} else {
+		fmt.Fprintf(&content, "func Provide%sRepository(db %s) repository.%sRepository {\n", feature, dbType, feature)
+		fmt.Fprintf(&content, "\treturn %s\n", repoConstructor)
+	}
+	content.WriteString("}\n\n")
+
+	fmt.Fprintf(&content, "// Provide%sUseCase builds the %s use case.\n", feature, feature)
+	if hasAuditedUseCase(feature) || hasCQRSUseCase(feature) {
+		fmt.Fprintf(&content, "func Provide%sUseCase(db %s, repo repository.%sRepository) usecase.%sUseCase {\n", feature, dbType, feature, feature)
+	} else {
+		fmt.Fprintf(&content, "func Provide%sUseCase(repo repository.%sRepository) usecase.%sUseCase {\n", feature, feature, feature)
+	}
+	fmt.Fprintf(&content, "\treturn %s\n", useCaseExpr(feature, "repo", "db"))
+	content.WriteString("}\n\n")
+
+	fmt.Fprintf(&content, "// Provide%sHandler builds the %s HTTP handler.\n", feature, feature)
+	fmt.Fprintf(&content, "func Provide%sHandler(uc usecase.%sUseCase) *http.%sHandler {\n", feature, feature, feature)
+	fmt.Fprintf(&content, "\treturn http.New%sHandler(uc)\n", feature)
+	content.WriteString("}\n")
+
+	if err := writeGoFile(filename, content.String(), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing %s providers: %v", feature, err))
+		return false
+	}
+	return true
+}
+
+// generateProviderContainer writes container.go: a Container holding every
+// feature's components, built by newContainer from the provider graph. The
+// getters match the manual container so main.go wiring is unchanged.
+func generateProviderContainer(dir string, features []providerFeature, style string, sm ...*SafetyManager) {
+	importPath := getImportPath(getModuleName())
+	names := make([]string, 0, len(features))
+	for _, f := range features {
+		names = append(names, f.Name)
+	}
+
+	var content strings.Builder
+	content.WriteString("package di\n\n")
+	content.WriteString("import (\n")
+	fmt.Fprintf(&content, "\t\"%s/internal/handler/http\"\n", importPath)
+	fmt.Fprintf(&content, "\t\"%s/internal/repository\"\n", importPath)
+	fmt.Fprintf(&content, "\t\"%s/internal/usecase\"\n", importPath)
+	content.WriteString(")\n\n")
+
+	library := "Wire"
+	if style == DIFx {
+		library = "fx"
+	}
+	fmt.Fprintf(&content, "// Container exposes the components assembled by the %s providers.\n", library)
+	content.WriteString("// Regenerated from the *_providers.go files; do not edit.\n")
+	content.WriteString("type Container struct {\n")
+	for _, feature := range names {
+		writeProviderContainerFields(&content, feature, "\t", " ")
+	}
+	content.WriteString("}\n\n")
+
+	content.WriteString("func newContainer(\n")
+	for _, feature := range names {
+		writeProviderContainerFields(&content, feature, "\t", ",")
+	}
+	content.WriteString(") *Container {\n")
+	content.WriteString("\treturn &Container{\n")
+	for _, feature := range names {
+		featureLower := strings.ToLower(feature)
+		fieldName := strings.ToLower(feature[:1]) + feature[1:] // camelCase
+		fmt.Fprintf(&content, "\t\t%sRepo: %sRepo,\n", featureLower, featureLower)
+		fmt.Fprintf(&content, "\t\t%sUC: %sUC,\n", featureLower, featureLower)
+		fmt.Fprintf(&content, "\t\t%sHandler: %sHandler,\n", fieldName, fieldName)
+	}
+	content.WriteString("\t}\n")
+	content.WriteString("}\n\n")
+
+	generateGetters(&content, names)
+
+	if err := writeGoFileMerged(filepath.Join(dir, "container.go"), content.String(), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing DI file: %v", err))
+	}
+}
+
+// writeProviderContainerFields writes the repo/use case/handler entries of a
+// feature, either as struct fields (sep " ") or as parameters (sep ",").
+func writeProviderContainerFields(content *strings.Builder, feature, indent, sep string) {
+	featureLower := strings.ToLower(feature)
+	fieldName := strings.ToLower(feature[:1]) + feature[1:] // camelCase
+	if sep == "," {
+		fmt.Fprintf(content, "%s%sRepo repository.%sRepository,\n", indent, featureLower, feature)
+		fmt.Fprintf(content, "%s%sUC usecase.%sUseCase,\n", indent, featureLower, feature)
+		fmt.Fprintf(content, "%s%sHandler *http.%sHandler,\n", indent, fieldName, feature)
+		return
+	}
+	fmt.Fprintf(content, "%s%sRepo repository.%sRepository\n", indent, featureLower, feature)
+	fmt.Fprintf(content, "%s%sUC usecase.%sUseCase\n", indent, featureLower, feature)
+	fmt.Fprintf(content, "%s%sHandler *http.%sHandler\n", indent, fieldName, feature)
+}
+
+// containerParams returns the NewContainer parameter list for the database
+// handle plus the Redis client when a feature needs it.
+func containerParams(database string, redis bool) string {
+	dbType, _ := dbHandleType(database)
+	params := "db " + dbType
+	if redis {
+		params += ", redisClient *redis.Client"
+	}
+	return params
+}
+
+// generateWireInjector writes wire.go, the injector that `wire` turns into
+// wire_gen.go.
+func generateWireInjector(dir string, features []providerFeature, database string, sm ...*SafetyManager) {
+	redis := anyProviderNeedsRedis(features)
+
+	var content strings.Builder
+	writeWireHeader(&content)
+	writeWireImports(&content, database, redis)
+
+	content.WriteString("// NewContainer is the Wire injector; run `make wire` to generate its\n")
+	content.WriteString("// implementation in wire_gen.go.\n")
+	fmt.Fprintf(&content, "func NewContainer(%s) *Container {\n", containerParams(database, redis))
+	content.WriteString("\twire.Build(\n")
+	for _, f := range features {
+		fmt.Fprintf(&content, "\t\t%sSet,\n", f.Name)
+	}
+	content.WriteString("\t\tnewContainer,\n")
+	content.WriteString("\t)\n")
+	content.WriteString("\treturn nil\n")
+	content.WriteString("}\n")
+
+	if err := writeGoFileMerged(filepath.Join(dir, "wire.go"), content.String(), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing Wire file: %v", err))
+	}
+}
+
+// writeWireHeader writes the Wire file header with build tags.
+func writeWireHeader(content *strings.Builder) {
+	content.WriteString("//go:build wireinject\n")
+	content.WriteString("// +build wireinject\n\n")
+	content.WriteString("package di\n\n")
+}
+
+// writeWireImports writes the import section for the Wire injector.
+func writeWireImports(content *strings.Builder, database string, redis bool) {
+	_, dbImport := dbHandleType(database)
+	content.WriteString("import (\n")
+	content.WriteString("\t\"github.com/google/wire\"\n")
+	if redis {
+		content.WriteString("\t\"github.com/redis/go-redis/v9\"\n")
+	}
+	fmt.Fprintf(content, "\t%q\n", dbImport)
+	content.WriteString(")\n\n")
+}
+
+// generateFxModule writes fx.go with the aggregate Module and a NewContainer
+// that resolves it, keeping main.go's di.NewContainer(db) call working.
+func generateFxModule(dir string, features []providerFeature, database string, sm ...*SafetyManager) {
+	redis := anyProviderNeedsRedis(features)
+	_, dbImport := dbHandleType(database)
+
+	var content strings.Builder
+	content.WriteString("package di\n\n")
+	content.WriteString("import (\n")
+	content.WriteString("\t\"fmt\"\n\n")
+	if redis {
+		content.WriteString("\t\"github.com/redis/go-redis/v9\"\n")
+	}
+	content.WriteString("\t\"go.uber.org/fx\"\n")
+	fmt.Fprintf(&content, "\t%q\n", dbImport)
+	content.WriteString(")\n\n")
+
+	content.WriteString("// Module bundles every feature module with the container. Include it in an\n")
+	content.WriteString("// fx.App to take over the application lifecycle.\n")
+	content.WriteString("var Module = fx.Options(\n")
+	for _, f := range features {
+		fmt.Fprintf(&content, "\t%sModule,\n", f.Name)
+	}
+	content.WriteString("\tfx.Provide(newContainer),\n")
+	content.WriteString(")\n\n")
+
+	supplied := "db"
+	if redis {
+		supplied += ", redisClient"
+	}
+	content.WriteString("// NewContainer resolves Module without starting an fx lifecycle. An\n")
+	content.WriteString("// incomplete provider graph is a programming error and panics.\n")
+	fmt.Fprintf(&content, "func NewContainer(%s) *Container {\n", containerParams(database, redis))
+	content.WriteString("\tvar c *Container\n")
+	content.WriteString("\tapp := fx.New(\n")
+	fmt.Fprintf(&content, "\t\tfx.Supply(%s),\n", supplied)
+	content.WriteString("\t\tModule,\n")
+	content.WriteString("\t\tfx.Populate(&c),\n")
+	content.WriteString("\t\tfx.NopLogger,\n")
+	content.WriteString("\t)\n")
+	content.WriteString("\tif err := app.Err(); err != nil {\n")
+	content.WriteString("\t\tpanic(fmt.Sprintf(\"di: %v\", err))\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\treturn c\n")
+	content.WriteString("}\n")
+
+	if err := writeGoFileMerged(filepath.Join(dir, "fx.go"), content.String(), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing fx module: %v", err))
+	}
+}
+
+// serverNextSteps returns the next steps that start the server of a project
+// whose container uses di. With Wire, main.go needs the injector wire
+// generates into wire_gen.go, so generating it comes first.
+func serverNextSteps(di string) []string {
+	var steps []string
+	if di == DIWire {
+		steps = append(steps, fmt.Sprintf("Generate wire_gen.go: wire ./... (install: go install github.com/google/wire/cmd/wire@%s)", wireVersion))
+	}
+	return append(steps, "Start server: go run cmd/server/main.go")
+}
+
+// ensureMakefileWireTarget adds a `wire` target to the project Makefile and
+// makes build/run depend on it, so wire_gen.go is regenerated before compiling.
+func ensureMakefileWireTarget(sm ...*SafetyManager) {
+	const makefile = "Makefile"
+	raw, err := os.ReadFile(makefile)
+	if err != nil {
+		ui.Dim(fmt.Sprintf("   Run `go run github.com/google/wire/cmd/wire@%s ./internal/di` to generate wire_gen.go", wireVersion))
+		return
+	}
+	content := string(raw)
+	if strings.Contains(content, "\nwire:") {
+		return
+	}
+
+	target := fmt.Sprintf("wire: ## Generate dependency injection code (internal/di/wire_gen.go)\n"+
+		"\tgo run github.com/google/wire/cmd/wire@%s ./internal/di\n\n", wireVersion)
+
+	if strings.Contains(content, "\nbuild:") {
+		content = strings.Replace(content, "\nbuild:", "\n"+target+"build:", 1)
+	} else {
+		content = strings.TrimRight(content, "\n") + "\n\n" + target
+	}
+	content = strings.Replace(content, "build: ## ", "build: wire ## ", 1)
+	content = strings.Replace(content, "run: ## ", "run: wire ## ", 1)
+	content = strings.Replace(content, ".PHONY: help build", ".PHONY: help wire build", 1)
+
+	if err := writeMergedFileSafe(makefile, content, sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Could not add wire target to Makefile: %v", err))
+	}
+}
+
+// addDIDependency adds the wire or fx module to go.mod.
+func addDIDependency(style string) {
+	projectRoot, _ := os.Getwd()
+	depMgr := NewDependencyManager(projectRoot, false)
+	deps := depMgr.GetRequiredDependenciesForFeature("", map[string]bool{style: true})
+	for _, dep := range deps {
+		if err := depMgr.AddDependency(dep); err != nil {
+			ui.Warning(fmt.Sprintf("Could not add dependency %s: %v", dep.Module, err))
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupDIProject chdirs into an empty module and returns the DI directory.
+func setupDIProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	origDir, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(origDir) })
+	require.NoError(t, os.Chdir(dir))
+	require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0o644))
+	diDir := filepath.Join("internal", "di")
+	require.NoError(t, os.MkdirAll(diDir, 0o755))
+	return diDir
+}
+
+func readParsedGo(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), path, content, parser.ParseComments)
+	require.NoError(t, err, string(content))
+	return string(content)
+}
+
+func TestGenerateProviderDI_Wire(t *testing.T) {
+	cleanup := ensureTestUI(t)
+	defer cleanup()
+	diDir := setupDIProject(t)
+	require.NoError(t, os.WriteFile("Makefile", []byte(".PHONY: help build run test\n\nbuild: ## Build the application\n\tgo build -o bin/app cmd/server/main.go\n\nrun: ## Run the application\n\tgo run cmd/server/main.go\n"), 0o644))
+
+	sm := NewSafetyManager(false, true, false)
+	generateProviderDI(diDir, []string{"Product"}, "postgres", DIWire, false, sm)
+	generateProviderDI(diDir, []string{"Order"}, "postgres", DIWire, false, sm)
+
+	providers := readParsedGo(t, filepath.Join(diDir, "product_providers.go"))
+	assert.Contains(t, providers, "var ProductSet = wire.NewSet(ProvideProductRepository, ProvideProductUseCase, ProvideProductHandler)")
+	assert.Contains(t, providers, "func ProvideProductRepository(db *gorm.DB) repository.ProductRepository")
+	assert.Contains(t, providers, "return usecase.NewProductService(repo)")
+	assert.Contains(t, providers, "return http.NewProductHandler(uc)")
+
+	// The container and injector cover features added in separate runs.
+	container := readParsedGo(t, filepath.Join(diDir, "container.go"))
+	assert.Contains(t, container, "productRepo repository.ProductRepository")
+	assert.Contains(t, container, "orderHandler *http.OrderHandler")
+	assert.Contains(t, container, "func (c *Container) OrderHandler() *http.OrderHandler")
+
+	injector := readParsedGo(t, filepath.Join(diDir, "wire.go"))
+	assert.True(t, strings.HasPrefix(injector, "//go:build wireinject"))
+	assert.Contains(t, injector, "func NewContainer(db *gorm.DB) *Container")
+	assert.Contains(t, injector, "ProductSet,")
+	assert.Contains(t, injector, "OrderSet,")
+	assert.Contains(t, injector, "newContainer,")
+
+	makefile, err := os.ReadFile("Makefile")
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(makefile), "\nwire:"))
+	assert.Contains(t, string(makefile), ".PHONY: help wire build")
+	assert.Contains(t, string(makefile), "build: wire ## ")
+	assert.Contains(t, string(makefile), "run: wire ## ")
+	assert.Contains(t, string(makefile), "github.com/google/wire/cmd/wire@"+wireVersion)
+}
+
+func TestGenerateProviderDI_Fx(t *testing.T) {
+	cleanup := ensureTestUI(t)
+	defer cleanup()
+	diDir := setupDIProject(t)
+
+	generateProviderDI(diDir, []string{"Product", "User"}, "mongodb", DIFx, false, NewSafetyManager(false, true, false))
+
+	providers := readParsedGo(t, filepath.Join(diDir, "user_providers.go"))
+	assert.Contains(t, providers, `var UserModule = fx.Module("user", fx.Provide(ProvideUserRepository, ProvideUserUseCase, ProvideUserHandler))`)
+	assert.Contains(t, providers, "func ProvideUserRepository(db *mongo.Database) repository.UserRepository")
+	assert.Contains(t, providers, "repository.NewMongoUserRepository(db)")
+
+	module := readParsedGo(t, filepath.Join(diDir, "fx.go"))
+	assert.Contains(t, module, "ProductModule,")
+	assert.Contains(t, module, "UserModule,")
+	assert.Contains(t, module, "fx.Provide(newContainer)")
+	assert.Contains(t, module, "func NewContainer(db *mongo.Database) *Container")
+	assert.Contains(t, module, "fx.Supply(db)")
+
+	_, err := os.Stat(filepath.Join(diDir, "wire.go"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestGenerateFeatureProviders_KeepsExisting(t *testing.T) {
+	cleanup := ensureTestUI(t)
+	defer cleanup()
+	diDir := setupDIProject(t)
+
+	path := providerFileName(diDir, "Product")
+	custom := "package di\n\n// customised\nfunc ProvideProductHandler() {}\n"
+	require.NoError(t, os.WriteFile(path, []byte(custom), 0o644))
+
+	assert.True(t, generateFeatureProviders(diDir, providerFeature{Name: "Product"}, "postgres", DIWire, NewSafetyManager(false, false, false)))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, custom, string(content))
+
+	features := detectProviderFeatures(diDir)
+	require.Len(t, features, 1)
+	assert.Equal(t, "Product", features[0].Name)
+}
+
+func TestUpdateDIContainer_ProviderStyles(t *testing.T) {
+	cleanup := ensureTestUI(t)
+	defer cleanup()
+	diDir := setupDIProject(t)
+
+	// An existing manual container is extended manually rather than replaced.
+	generateDI("Product", "postgres", DIManual, "", false, NewSafetyManager(false, true, false))
+	updateDIContainer("Order", "postgres", DIFx, false, NewSafetyManager(false, true, false))
+	container := readParsedGo(t, filepath.Join(diDir, "container.go"))
+	assert.Contains(t, container, "orderRepo")
+	assert.False(t, isProviderContainer(container))
+	_, err := os.Stat(providerFileName(diDir, "Order"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Switching styles with di regenerates the container from providers.
+	generateDI("Product,Order", "postgres", DIFx, "", false, NewSafetyManager(false, true, false))
+	updateDIContainer("User", "postgres", DIFx, false, NewSafetyManager(false, true, false))
+	container = readParsedGo(t, filepath.Join(diDir, "container.go"))
+	assert.True(t, isProviderContainer(container))
+	assert.Contains(t, container, "userHandler *http.UserHandler")
+	assert.Contains(t, container, "productHandler *http.ProductHandler")
+}
+
+func TestGetDIType(t *testing.T) {
+	ci := &ConfigIntegration{}
+	assert.Equal(t, DIManual, ci.GetDIType(""))
+	assert.Equal(t, DIFx, ci.GetDIType("fx"))
+
+	ci.config = &GocaConfig{Architecture: ArchitectureConfig{DI: DIConfig{Type: "wire"}}}
+	assert.Equal(t, DIWire, ci.GetDIType(""))
+	assert.Equal(t, DIFx, ci.GetDIType("fx"))
+
+	// dig is a valid config value the generator does not support yet.
+	ci.config.Architecture.DI.Type = "dig"
+	assert.Equal(t, DIManual, ci.GetDIType(""))
+}
+
+func TestServerNextSteps(t *testing.T) {
+	assert.Equal(t, []string{"Start server: go run cmd/server/main.go"}, serverNextSteps(DIManual))
+	assert.Equal(t, []string{"Start server: go run cmd/server/main.go"}, serverNextSteps(DIFx))
+
+	steps := serverNextSteps(DIWire)
+	require.Len(t, steps, 2)
+	assert.Contains(t, steps[0], "wire ./...")
+	assert.Equal(t, "Start server: go run cmd/server/main.go", steps[1])
+}
@@ -0,0 +1,492 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+)
+
+// errDrift is returned by goca diff --exit-code when a file drifted.
+var errDrift = errors.New("project files differ from the generated code")
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [feature...]",
+	Short: "Show where project files drifted from the code goca generates",
+	Long: `Renders what goca would generate today for each feature of the project,
+without writing anything, and prints a diff against the files on disk, grouped
+by feature and layer. Removed lines are what goca generates; added lines are
+what the project has instead.
+
+Features are the entities in internal/domain, all of them by default. Each is
+rendered the way goca feature --force would regenerate it: with its current
+fields, the --validation, --business-rules, --versioned, --audit,
+--multi-tenant and --cache options its code shows it was generated with, and
+the settings of .goca.yaml. Handlers are compared for the handler types of
+.goca.yaml that the feature has a file for. Files goca rebuilds from one
+feature alone, such as constants.go, are only compared for the features they
+describe.
+
+Run it before upgrading goca or regenerating a feature to see which
+customizations regenerating would overwrite.`,
+	Example: `  goca diff
+  goca diff Order --layer handler
+  goca diff --stat --exit-code`,
+	RunE: runDiff,
+}
+
+func init() {
+	diffCmd.Flags().String("layer", "", "Only compare these layers (comma-separated: "+strings.Join(ValidFeatureLayers, ", ")+")")
+	diffCmd.Flags().Bool("stat", false, "Only print the number of drifted files per feature and layer")
+	diffCmd.Flags().Bool("exit-code", false, "Exit with an error when a file drifted, for CI")
+}
+
+// diffSettings are the generation settings features are rendered with.
+type diffSettings struct {
+	database string
+	handlers []string
+	naming   string
+	dtoStyle string
+	features []string
+}
+
+// driftFile is a file goca generates for a feature, compared with the one on
+// disk.
+type driftFile struct {
+	feature   string
+	layer     string
+	path      string
+	generated string
+	current   string
+	missing   bool
+}
+
+func (f driftFile) drifted() bool {
+	return f.missing || f.generated != f.current
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	only, _ := cmd.Flags().GetString("layer")
+	stat, _ := cmd.Flags().GetBool("stat")
+	exitCode, _ := cmd.Flags().GetBool("exit-code")
+
+	layers := allFeatureLayers()
+	if strings.TrimSpace(only) != "" {
+		var err error
+		if layers, err = resolveFeatureLayers("", only); err != nil {
+			return errors.New(strings.Replace(err.Error(), "--only", "--layer", 1))
+		}
+	}
+
+	features, err := selectDiffFeatures(args)
+	if err != nil {
+		return err
+	}
+	if len(features) == 0 {
+		ui.Warning("No entities found in internal/domain")
+		return nil
+	}
+
+	ui.Header("Goca Diff")
+	ui.Blank()
+	settings := loadDiffSettings()
+	var files []driftFile
+	for _, feature := range features {
+		files = append(files, compareFeature(feature, layers, settings)...)
+	}
+
+	if !stat {
+		printDrift(files)
+	}
+	drifted := printDriftSummary(files)
+	if drifted > 0 && exitCode {
+		return errDrift
+	}
+	return nil
+}
+
+// selectDiffFeatures returns the features named in args, or every feature of
+// the project.
+func selectDiffFeatures(args []string) ([]string, error) {
+	features := projectFeatures()
+	if len(args) == 0 {
+		return features, nil
+	}
+
+	var selected []string
+	for _, arg := range args {
+		found := ""
+		for _, feature := range features {
+			if strings.EqualFold(feature, arg) {
+				found = feature
+				break
+			}
+		}
+		if found == "" {
+			return nil, fmt.Errorf("feature %q not found in %s", arg, filepath.Join(DirInternal, DirDomain))
+		}
+		selected = append(selected, found)
+	}
+	return selected, nil
+}
+
+// projectFeatures returns the entities declared in internal/domain, each in
+// the file named after it.
+func projectFeatures() []string {
+	files, _ := filepath.Glob(filepath.Join(DirInternal, DirDomain, "*.go"))
+	var features []string
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		s, err := parseGoSource(file)
+		if err != nil {
+			continue
+		}
+		base := strings.NewReplacer("_", "", "-", "").Replace(strings.TrimSuffix(filepath.Base(file), ".go"))
+		for _, name := range structTypeNames(s.file) {
+			if strings.EqualFold(name, base) {
+				features = append(features, name)
+			}
+		}
+	}
+	sort.Strings(features)
+	return features
+}
+
+// loadDiffSettings reads the settings features are generated with from
+// .goca.yaml.
+func loadDiffSettings() diffSettings {
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+
+	settings := diffSettings{
+		database: ci.GetDatabaseType(""),
+		naming:   "lowercase",
+		dtoStyle: ci.GetDTOStyle(""),
+		features: projectFeatures(),
+	}
+	if ci.config != nil {
+		settings.naming = ci.GetNamingConvention("file")
+	}
+	for _, handler := range ci.GetHandlerTypes("") {
+		if _, err := os.Stat(filepath.Join(DirInternal, DirHandler, handlerDir(handler))); err == nil {
+			settings.handlers = append(settings.handlers, handler)
+		}
+	}
+	return settings
+}
+
+// handlerDir returns the directory under internal/handler of a handler type.
+func handlerDir(handlerType string) string {
+	if handlerType == HandlerWebSocket {
+		return DirWebSocket
+	}
+	return handlerType
+}
+
+// compareFeature renders each layer of feature and compares the files with
+// the ones on disk.
+func compareFeature(feature string, layers map[string]bool, settings diffSettings) []driftFile {
+	fields := readEntityFieldsString(feature)
+	validation := featureValidated(feature)
+	businessRules := featureHasBusinessRules(feature)
+	versioned := isVersionedEntity(feature)
+	audit := entityDeclaresField(feature, auditFields[0])
+	multiTenant := entityDeclaresField(feature, tenantField)
+	_, err := os.Stat(filepath.Join(DirInternal, DirRepository, "cached_"+strings.ToLower(feature)+"_repository.go"))
+	cache := err == nil
+
+	renderers := map[string]func(sm *SafetyManager){
+		LayerDomain: func(sm *SafetyManager) {
+			_ = generateEntity(feature, fields, true, businessRules, false, false, versioned, audit, multiTenant, true, settings.naming, sm)
+		},
+		LayerUseCase: func(sm *SafetyManager) {
+			generateUseCaseWithFields(feature+"UseCase", feature, "create,read,update,delete,list", validation, false, fields, settings.dtoStyle, sm)
+		},
+		LayerRepository: func(sm *SafetyManager) {
+			generateRepository(feature, settings.database, false, false, cache, false, fields, sm)
+		},
+		LayerMessages: func(sm *SafetyManager) {
+			generateMessages(feature, true, true, true, sm)
+		},
+	}
+
+	var files []driftFile
+	for _, layer := range selectedLayerNames(layers) {
+		if layer != LayerHandler {
+			rendered := renderQuietly(renderers[layer])
+			dropOtherFeatureFiles(rendered, feature, settings.features)
+			files = append(files, compareRendered(feature, layer, rendered)...)
+			continue
+		}
+		// A handler type is only compared for the features that have one.
+		for _, handler := range settings.handlers {
+			rendered := renderQuietly(func(sm *SafetyManager) {
+				generateHandler(feature, handler, true, validation, handler == HandlerHTTP, settings.naming, sm)
+			})
+			dropOtherFeatureFiles(rendered, feature, settings.features)
+			if hasHandlerFile(rendered, feature, handler) {
+				files = append(files, compareRendered(feature, layer, rendered)...)
+			}
+		}
+	}
+	return files
+}
+
+// featureValidated reports whether the feature was generated with
+// --validation, which tags the fields of its create input.
+func featureValidated(feature string) bool {
+	input := "Create" + feature + "Input"
+	file := findTypeFile(filepath.Join(DirInternal, DirUseCase), input)
+	if file == "" {
+		return false
+	}
+	s, err := parseGoSource(file)
+	if err != nil {
+		return false
+	}
+	st := findStructType(s.file, input)
+	if st == nil {
+		return false
+	}
+	for _, field := range st.Fields.List {
+		if field.Tag != nil && strings.Contains(field.Tag.Value, `validate:"`) {
+			return true
+		}
+	}
+	return false
+}
+
+// featureHasBusinessRules reports whether the entity was generated with
+// --business-rules, which declares one of the methods of
+// generateBusinessRules.
+func featureHasBusinessRules(feature string) bool {
+	path, err := findEntityFile(feature)
+	if err != nil {
+		return false
+	}
+	s, err := parseGoSource(path)
+	if err != nil {
+		return false
+	}
+	for _, method := range []string{"IsAdult", "IsExpensive", "HasValidEmail", "IsActive"} {
+		if findMethod(s.file, feature, method) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// hasHandlerFile reports whether a file named after the feature that was
+// rendered in the directory of the handler type exists.
+func hasHandlerFile(rendered map[string]string, feature, handlerType string) bool {
+	dir := filepath.Join(DirInternal, DirHandler, handlerDir(handlerType))
+	name := strings.ToLower(feature)
+	for path := range rendered {
+		base := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(filepath.Base(path)))
+		if filepath.Dir(path) != dir || !strings.Contains(base, name) {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// dropOtherFeatureFiles removes from rendered the shared files that
+// regenerating feature rebuilds from its part alone, such as constants.go:
+// those whose current content names another feature of the
+// project that the rendered content does not. They hold another feature's
+// part, so comparing them would report that part as drift. Files named after
+// the feature are always compared.
+func dropOtherFeatureFiles(rendered map[string]string, feature string, features []string) {
+	name := strings.ToLower(feature)
+	for path, generated := range rendered {
+		base := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(filepath.Base(path)))
+		if strings.Contains(base, name) {
+			continue
+		}
+		current, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for _, other := range features {
+			word := regexp.MustCompile(`\b` + regexp.QuoteMeta(other) + `\b`)
+			if other != feature && word.Match(current) && !word.MatchString(generated) {
+				delete(rendered, path)
+				break
+			}
+		}
+	}
+}
+
+// compareRendered compares the rendered files of a layer with the ones on
+// disk.
+func compareRendered(feature, layer string, rendered map[string]string) []driftFile {
+	paths := make([]string, 0, len(rendered))
+	for path := range rendered {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	files := make([]driftFile, 0, len(paths))
+	for _, path := range paths {
+		current, err := os.ReadFile(path)
+		files = append(files, driftFile{
+			feature:   feature,
+			layer:     layer,
+			path:      path,
+			generated: rendered[path],
+			current:   string(current),
+			missing:   err != nil,
+		})
+	}
+	return files
+}
+
+// renderQuietly runs render with a dry-run SafetyManager and the UI silenced,
+// and returns the content of each file it would write. A file written twice
+// keeps its last content.
+func renderQuietly(render func(sm *SafetyManager)) map[string]string {
+	saved := ui
+	ui = &UIRenderer{writer: io.Discard, noColor: true}
+	defer func() { ui = saved }()
+
+	sm := NewSafetyManager(true, true, false)
+	render(sm)
+	rendered := make(map[string]string)
+	for _, entry := range sm.GetPendingFiles() {
+		rendered[filepath.Clean(entry.Path)] = entry.content
+	}
+	return rendered
+}
+
+// printDrift prints the diff of each drifted file, grouped by feature and
+// layer. A shared file, such as dto.go, is rendered per feature with that
+// feature's part regenerated; the same drift is only printed once.
+func printDrift(files []driftFile) {
+	printed := make(map[string]bool)
+	group := ""
+	for _, f := range files {
+		key := f.path + "\x00" + f.generated
+		if !f.drifted() || printed[key] {
+			continue
+		}
+		printed[key] = true
+
+		if g := f.feature + " / " + f.layer; g != group {
+			if group != "" {
+				ui.Blank()
+			}
+			group = g
+			ui.Section(g)
+		}
+		path := filepath.ToSlash(f.path)
+		if f.missing {
+			ui.Warning(path + ": missing, goca generates it")
+			continue
+		}
+		ui.Diff(unifiedDiff(path, f.generated, f.current))
+	}
+	if group != "" {
+		ui.Blank()
+	}
+}
+
+// unifiedDiff returns the diff from the generated content of path to its
+// current content.
+func unifiedDiff(path, generated, current string) string {
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(generated),
+		B:        difflib.SplitLines(current),
+		FromFile: "generated/" + path,
+		ToFile:   "project/" + path,
+		Context:  3,
+	})
+	return diff
+}
+
+// printDriftSummary prints the drifted, missing and unchanged files per
+// feature and layer, and returns the number of drifted files.
+func printDriftSummary(files []driftFile) int {
+	type counts struct{ drifted, missing, unchanged int }
+	var order []string
+	groups := make(map[string]*counts)
+	total := 0
+	for _, f := range files {
+		key := f.feature + "\x00" + f.layer
+		c, ok := groups[key]
+		if !ok {
+			c = &counts{}
+			groups[key] = c
+			order = append(order, key)
+		}
+		switch {
+		case f.missing:
+			c.missing++
+		case f.drifted():
+			c.drifted++
+		default:
+			c.unchanged++
+		}
+		if f.drifted() {
+			total++
+		}
+	}
+
+	rows := make([][]string, 0, len(order))
+	for _, key := range order {
+		feature, layer, _ := strings.Cut(key, "\x00")
+		c := groups[key]
+		rows = append(rows, []string{feature, layer, strconv.Itoa(c.drifted), strconv.Itoa(c.missing), strconv.Itoa(c.unchanged)})
+	}
+	ui.Table([]string{"Feature", "Layer", "Drifted", "Missing", "Unchanged"}, rows)
+	ui.Blank()
+
+	if total == 0 {
+		ui.Success("Every generated file matches what goca generates today")
+	} else {
+		noun := "files differ"
+		if total == 1 {
+			noun = "file differs"
+		}
+		ui.Warning(fmt.Sprintf("%d %s from what goca generates today", total, noun))
+		ui.Dim("  Regenerating them with --force would overwrite these changes")
+	}
+	return total
+}
+
+// structTypeNames returns the names of the struct types declared in file.
+func structTypeNames(file *ast.File) []string {
+	var names []string
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if ts := spec.(*ast.TypeSpec); isStructType(ts) {
+				names = append(names, ts.Name.Name)
+			}
+		}
+	}
+	return names
+}
+
+func isStructType(ts *ast.TypeSpec) bool {
+	_, ok := ts.Type.(*ast.StructType)
+	return ok
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareFeature_Drift(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	settings := diffSettings{database: DBPostgres, handlers: []string{HandlerHTTP}, naming: "lowercase", dtoStyle: "standard"}
+	sm := NewSafetyManager(false, true, false)
+	require.NoError(t, generateEntity("Order", "Total:float64,Status:string", true, false, false, false, false, false, false, true, settings.naming, sm))
+	generateUseCaseWithFields("OrderUseCase", "Order", "create,read,update,delete,list", false, false, "Total:float64,Status:string", settings.dtoStyle, sm)
+	generateRepository("Order", settings.database, false, false, false, false, "Total:float64,Status:string", sm)
+
+	features, err := selectDiffFeatures([]string{"order"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Order"}, features)
+
+	layers := map[string]bool{LayerDomain: true, LayerUseCase: true, LayerRepository: true, LayerHandler: true}
+	for _, f := range compareFeature("Order", layers, settings) {
+		assert.False(t, f.drifted(), f.path)
+	}
+
+	entity := filepath.Join(DirInternal, DirDomain, "order.go")
+	content, err := os.ReadFile(entity)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(entity, append(content, []byte("\n// Paid reports whether the order was paid.\nfunc (o *Order) Paid() bool { return o.Status == \"paid\" }\n")...), 0o644))
+
+	byPath := map[string]driftFile{}
+	for _, f := range compareFeature("Order", layers, settings) {
+		byPath[f.path] = f
+	}
+	assert.True(t, byPath[entity].drifted())
+	assert.False(t, byPath[entity].missing)
+	assert.Contains(t, unifiedDiff(entity, byPath[entity].generated, byPath[entity].current), "+func (o *Order) Paid() bool")
+
+	repository := filepath.Join(DirInternal, DirRepository, "postgres_order_repository.go")
+	require.Contains(t, byPath, repository)
+	assert.False(t, byPath[repository].drifted())
+
+	// Features without an HTTP handler are not compared with one.
+	for path, f := range byPath {
+		assert.NotEqual(t, LayerHandler, f.layer, path)
+	}
+
+	require.NoError(t, os.Remove(repository))
+	byPath = map[string]driftFile{}
+	for _, f := range compareFeature("Order", map[string]bool{LayerRepository: true}, settings) {
+		byPath[f.path] = f
+	}
+	assert.True(t, byPath[repository].missing)
+	assert.False(t, byPath[filepath.Join(DirInternal, DirRepository, "gorm_errors.go")].drifted())
+}
+
+func TestCompareFeature_SeveralFeatures(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	settings := diffSettings{database: DBPostgres, handlers: []string{HandlerHTTP}, naming: "lowercase", dtoStyle: "standard", features: []string{"Order", "Product"}}
+	sm := NewSafetyManager(false, true, false)
+	fields := map[string]string{"Product": "Name:string,Price:float64", "Order": "Total:float64,Status:string"}
+	// Generated in the opposite order of the comparison, like goca feature
+	// Product then goca feature Order.
+	for _, feature := range []string{"Product", "Order"} {
+		require.NoError(t, generateEntity(feature, fields[feature], true, false, false, false, false, false, false, true, settings.naming, sm))
+		generateUseCaseWithFields(feature+"UseCase", feature, "create,read,update,delete,list", false, false, fields[feature], settings.dtoStyle, sm)
+		generateRepository(feature, settings.database, false, false, false, false, fields[feature], sm)
+		generateHandler(feature, HandlerHTTP, true, false, true, settings.naming, sm)
+		generateMessages(feature, true, true, true, sm)
+	}
+
+	compared := map[string]bool{}
+	for _, feature := range settings.features {
+		for _, f := range compareFeature(feature, allFeatureLayers(), settings) {
+			assert.False(t, f.drifted(), "%s: %s", feature, f.path)
+			compared[f.path] = true
+		}
+	}
+	for _, path := range []string{
+		filepath.Join(DirInternal, DirDomain, "errors.go"),
+		filepath.Join(DirInternal, DirHandler, DirHTTP, "swagger.yaml"),
+		filepath.Join(DirInternal, "constants", "constants.go"),
+	} {
+		assert.True(t, compared[path], "%s is compared for one feature at least", path)
+	}
+}
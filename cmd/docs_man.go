@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+var docsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Generate the documentation of the goca CLI",
+	Long:  `Generates the reference documentation of goca itself from its commands and flags.`,
+}
+
+var docsManCmd = &cobra.Command{
+	Use:   "man",
+	Short: "Generate man pages for goca and its commands",
+	Long: `Writes a man page per command, goca.1 for goca itself and goca-<command>.1
+for each command, e.g. goca-feature.1 and goca-field-add.1.
+
+Install them where man looks for section 1 pages:
+  Linux:  goca docs man --dir /usr/local/share/man/man1
+  macOS:  goca docs man --dir "$(brew --prefix)/share/man/man1"
+
+Then read them with: man goca-feature
+
+Examples:
+  goca docs man
+  goca docs man --dir ./man/man1`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		dir, _ := cmd.Flags().GetString("dir")
+
+		count, err := generateManPages(cmd.Root(), dir)
+		if err != nil {
+			return err
+		}
+		ui.Success(fmt.Sprintf("Wrote %d man pages to %s", count, dir))
+		return nil
+	},
+}
+
+func init() {
+	docsManCmd.Flags().String("dir", "man", "Directory the man pages are written to")
+	docsCmd.AddCommand(docsManCmd)
+}
+
+// generateManPages writes the man pages of root and its commands to dir and
+// returns how many it wrote. The pages carry no generation date unless
+// SOURCE_DATE_EPOCH sets one, so they can be committed and packaged.
+func generateManPages(root *cobra.Command, dir string) (int, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, fmt.Errorf("cannot create %s: %w", dir, err)
+	}
+	root.DisableAutoGenTag = true
+	defer escapeManPlaceholders(root)()
+	header := &doc.GenManHeader{
+		Title:   "GOCA",
+		Section: "1",
+		Source:  "Goca " + Version,
+		Manual:  "Goca Manual",
+	}
+	if err := doc.GenManTree(root, header, dir); err != nil {
+		return 0, fmt.Errorf("cannot generate the man pages: %w", err)
+	}
+	pages, err := filepath.Glob(filepath.Join(dir, "*.1"))
+	if err != nil {
+		return 0, err
+	}
+	return len(pages), nil
+}
+
+// manPlaceholders escapes the angle brackets of placeholders such as <entity>,
+// which the Markdown renderer of the man pages would drop as HTML tags.
+var manPlaceholders = strings.NewReplacer("<", `\<`, ">", `\>`)
+
+// escapeManPlaceholders escapes the placeholders in the usage and help of root
+// and its commands, and returns the function restoring them. Examples are
+// rendered verbatim and need no escaping.
+func escapeManPlaceholders(root *cobra.Command) func() {
+	type text struct{ use, long string }
+	saved := make(map[*cobra.Command]text)
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		saved[cmd] = text{cmd.Use, cmd.Long}
+		cmd.Use = manPlaceholders.Replace(cmd.Use)
+		cmd.Long = manPlaceholders.Replace(cmd.Long)
+		for _, sub := range cmd.Commands() {
+			walk(sub)
+		}
+	}
+	walk(root)
+	return func() {
+		for cmd, t := range saved {
+			cmd.Use, cmd.Long = t.use, t.long
+		}
+	}
+}
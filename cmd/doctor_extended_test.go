@@ -304,7 +304,7 @@ func TestPrintFeatureStructure(t *testing.T) {
 	ui = testUI
 	defer func() { ui = oldUI }()
 
-	printFeatureStructure("Product", "http")
+	printFeatureStructure("Product", "http", allFeatureLayers())
 	out := buf.String()
 	assert.Contains(t, out, "product")
 }
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// renderGoTemplate executes the template text, the source of a Go file, with
+// data. A field missing from data fails the rendering instead of leaving
+// "<no value>" in the source.
+func renderGoTemplate(name, text string, data any) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("template %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("template %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// parseGoTemplates parses text, the templates of a Go file and of the
+// sections other generators reuse, once at startup.
+func parseGoTemplates(name, text string) *template.Template {
+	return template.Must(template.New(name).Option("missingkey=error").Parse(text))
+}
+
+// writeGoTemplate executes the template name of set with data into b. The
+// templates are built into goca and their data typed, so a failure is a bug
+// and panics, like template.Must.
+func writeGoTemplate(b *strings.Builder, set *template.Template, name string, data any) {
+	if err := set.ExecuteTemplate(b, name, data); err != nil {
+		panic(err)
+	}
+}
+
+// emitGoSource applies the code style of the project to the generated Go
+// source of filename, gofmts it and checks it with checkGeneratedGo.
+func emitGoSource(filename, content string) (string, error) {
+	content = applyProjectCodeStyle(filename, content)
+	formatted, err := formatGoSource(content)
+	if err != nil {
+		return "", invalidGeneratedGo(filename, err)
+	}
+	if err := checkGeneratedGo(filename, string(formatted)); err != nil {
+		return "", err
+	}
+	return string(formatted), nil
+}
+
+// badFormatVerb matches what fmt prints for a verb without its operand or of
+// the wrong type, such as %!s(MISSING) or %!d(string=x).
+var badFormatVerb = regexp.MustCompile(`%!(?:[a-zA-Z]?\(|\(EXTRA)`)
+
+// checkGeneratedGo parses content, the generated Go source of filename,
+// before it is written. Syntax errors, verbs fmt could not format, standard library
+// imports the file never uses and common ones it uses without importing are
+// reported, and recorded for generatedGoError. Other files are not checked.
+func checkGeneratedGo(filename, content string) error {
+	if !strings.HasSuffix(filename, ".go") {
+		return nil
+	}
+	defer profileStep("check generated Go")()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, content, 0)
+	if err != nil {
+		return invalidGeneratedGo(filename, err)
+	}
+	if loc := badFormatVerb.FindStringIndex(content); loc != nil {
+		line := strings.Count(content[:loc[0]], "\n") + 1
+		return invalidGeneratedGo(filename, fmt.Errorf("line %d: unformatted verb %s", line, content[loc[0]:loc[1]]))
+	}
+	if unused := unusedStdImports(file); len(unused) > 0 {
+		return invalidGeneratedGo(filename, fmt.Errorf("unused imports %s", strings.Join(unused, ", ")))
+	}
+	if missing := missingStdImports(file); len(missing) > 0 {
+		return invalidGeneratedGo(filename, fmt.Errorf("missing imports %s", strings.Join(missing, ", ")))
+	}
+	return nil
+}
+
+// commonStdPackages are the standard library packages generated code uses
+// most, by package name.
+var commonStdPackages = map[string]string{
+	"bytes": "bytes", "context": "context", "errors": "errors", "fmt": "fmt",
+	"http": "net/http", "io": "io", "json": "encoding/json", "os": "os",
+	"sort": "sort", "sql": "database/sql", "strconv": "strconv",
+	"strings": "strings", "sync": "sync", "time": "time",
+}
+
+// missingStdImports returns the commonStdPackages file refers to, as in
+// time.Now(), without importing a package of that name. Only identifiers the
+// file does not declare count, so a local variable named like a package does
+// not.
+func missingStdImports(file *ast.File) []string {
+	imported := make(map[string]bool)
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imported[name] = true
+	}
+	unresolved := make(map[*ast.Ident]bool, len(file.Unresolved))
+	for _, id := range file.Unresolved {
+		unresolved[id] = true
+	}
+	seen := make(map[string]bool)
+	var missing []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		id, ok := sel.X.(*ast.Ident)
+		if !ok || !unresolved[id] || imported[id.Name] || seen[id.Name] {
+			return true
+		}
+		if importPath, ok := commonStdPackages[id.Name]; ok {
+			seen[id.Name] = true
+			missing = append(missing, strconv.Quote(importPath))
+		}
+		return true
+	})
+	return missing
+}
+
+// unusedStdImports returns the standard library imports of file none of its
+// selectors refer to. Other imports are left out, because their package name
+// cannot be told from their path.
+func unusedStdImports(file *ast.File) []string {
+	used := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+	var unused []string
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || !isStdPackage(importPath) {
+			continue
+		}
+		name := path.Base(importPath)
+		if strings.HasPrefix(name, "v") && strings.Trim(name[1:], "0123456789") == "" && len(name) > 1 {
+			// math/rand/v2 is package rand.
+			name = path.Base(path.Dir(importPath))
+		}
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name != "_" && name != "." && !used[name] {
+			unused = append(unused, strconv.Quote(importPath))
+		}
+	}
+	return unused
+}
+
+// isStdPackage reports whether importPath is a package of the standard
+// library. Paths without a dot can also be packages of a project whose module
+// has no dot, such as "myproject/internal/domain", so the list of standard
+// packages decides.
+func isStdPackage(importPath string) bool {
+	return stdPackages[importPath]
+}
+
+var (
+	invalidGoMu    sync.Mutex
+	invalidGoFiles []error
+)
+
+// invalidGeneratedGo records that the generated Go source of filename is invalid
+// and returns the error saying why.
+func invalidGeneratedGo(filename string, err error) error {
+	err = fmt.Errorf("generated %s is not valid Go: %w", filename, err)
+	invalidGoMu.Lock()
+	defer invalidGoMu.Unlock()
+	invalidGoFiles = append(invalidGoFiles, err)
+	return err
+}
+
+// resetGeneratedGoErrors forgets the invalid files of a previous command.
+func resetGeneratedGoErrors() {
+	invalidGoMu.Lock()
+	defer invalidGoMu.Unlock()
+	invalidGoFiles = nil
+}
+
+// generatedGoError returns the error failing a command that generated invalid
+// Go files, which were not written, or nil.
+func generatedGoError() error {
+	invalidGoMu.Lock()
+	defer invalidGoMu.Unlock()
+	if len(invalidGoFiles) == 0 {
+		return nil
+	}
+	return errors.Join(append([]error{fmt.Errorf("%d generated Go files were invalid and not written", len(invalidGoFiles))}, invalidGoFiles...)...)
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderGoTemplate(t *testing.T) {
+	src, err := renderGoTemplate("main.go", "package {{.Name}}\n", struct{ Name string }{"main"})
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", src)
+
+	_, err = renderGoTemplate("main.go", "package {{.Name}}\n", map[string]string{})
+	assert.ErrorContains(t, err, "template main.go")
+	_, err = renderGoTemplate("main.go", "package {{.Name\n", nil)
+	assert.Error(t, err)
+}
+
+func TestWriteGoTemplate(t *testing.T) {
+	set := parseGoTemplates("file.go", `package {{.Name}}
+{{define "section"}}func {{.Name}}() {}
+{{end}}`)
+	var b strings.Builder
+	writeGoTemplate(&b, set, "file.go", struct{ Name string }{"main"})
+	writeGoTemplate(&b, set, "section", struct{ Name string }{"run"})
+	assert.Equal(t, "package main\nfunc run() {}\n", b.String())
+
+	assert.Panics(t, func() { writeGoTemplate(&b, set, "section", struct{}{}) })
+	assert.Panics(t, func() { parseGoTemplates("file.go", "package {{.Name") })
+}
+
+func TestCheckGeneratedGo(t *testing.T) {
+	defer resetGeneratedGoErrors()
+	resetGeneratedGoErrors()
+
+	valid := "package main\n\nimport (\n\t\"fmt\"\n\n\t\"myproject/internal/domain\"\n)\n\nfunc main() {\n\ttime := 1\n\tfmt.Println(time.String(), \"100%\")\n}\n"
+	assert.NoError(t, checkGeneratedGo("main.go", valid))
+	assert.NoError(t, checkGeneratedGo("README.md", "package {"))
+	require.NoError(t, generatedGoError())
+
+	for src, msg := range map[string]string{
+		"package main\n\nfunc main() {\n": "expected '}', found 'EOF'",
+		"package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println(\"%!s(MISSING)\") }\n":              "line 5: unformatted verb %!s(",
+		"package main\n\nimport (\n\t\"encoding/json\"\n\t\"fmt\"\n)\n\nfunc main() { fmt.Println() }\n": `unused imports "encoding/json"`,
+		"package main\n\nfunc main() { _ = time.Now() }\n":                                               `missing imports "time"`,
+	} {
+		assert.ErrorContains(t, checkGeneratedGo("main.go", src), msg, src)
+	}
+	err := generatedGoError()
+	assert.ErrorContains(t, err, "4 generated Go files were invalid and not written")
+	assert.ErrorContains(t, err, "generated main.go is not valid Go: ")
+}
+
+func TestIsStdPackage(t *testing.T) {
+	// The list is static: it does not need the GOROOT goca was built with.
+	t.Setenv("GOROOT", t.TempDir())
+	for _, importPath := range []string{"fmt", "encoding/json", "net/http/httptest", "math/rand/v2", "log/slog", "encoding"} {
+		assert.True(t, isStdPackage(importPath), importPath)
+	}
+	for _, importPath := range []string{"myproject/internal/domain", "github.com/gorilla/mux", "C", "internal/abi"} {
+		assert.False(t, isStdPackage(importPath), importPath)
+	}
+}
+
+func TestWriteGoFileInvalid(t *testing.T) {
+	defer ensureTestUI(t)()
+	defer resetGeneratedGoErrors()
+	chdirTemp(t)
+	resetGeneratedGoErrors()
+
+	err := writeGoFile("broken.go", "package main\n\nfunc main() {\n", NewSafetyManager(false, true, false))
+	assert.ErrorContains(t, err, "generated broken.go is not valid Go")
+	assert.NoFileExists(t, "broken.go")
+	assert.Error(t, generatedGoError())
+
+	resetGeneratedGoErrors()
+	require.NoError(t, writeGoFile("ok.go", "package main\nfunc main() {}\n"))
+	assert.NoError(t, generatedGoError())
+}
+
+func TestMainGoTemplates(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	sm := NewSafetyManager(false, true, false)
+
+	for _, db := range []string{DBPostgres, DBSQLite, DBTiDB, DBMongoDB, DBDynamoDB, DBElasticsearch} {
+		createMainGo(db, "example.com/shop", db, "", false, sm)
+	}
+	postgres := readParsedGo(t, filepath.Join(DBPostgres, "cmd", "server", "main.go"))
+	assert.Contains(t, postgres, "\t\"example.com/shop/pkg/config\"\n")
+	assert.Contains(t, postgres, "\t\"gorm.io/driver/postgres\"\n")
+	assert.Contains(t, postgres, "gorm.Open(postgres.Open(dsn), &gorm.Config{})")
+	assert.Contains(t, postgres, `log.Printf("Connecting to database at %s:%s/%s", cfg.Database.Host, cfg.Database.Port, cfg.Database.Name)`)
+	assert.Contains(t, postgres, "No database password set")
+
+	sqlite := readParsedGo(t, filepath.Join(DBSQLite, "cmd", "server", "main.go"))
+	assert.Contains(t, sqlite, "\tdsn := cfg.GetDatabaseURL()\n\n\tlog.Printf(\"Opening SQLite database %s\", dsn)\n\n\t// Retry connection")
+	assert.NotContains(t, sqlite, "No database password set")
+	tidb := readParsedGo(t, filepath.Join(DBTiDB, "cmd", "server", "main.go"))
+	assert.Contains(t, tidb, "gorm.Open(mysql.Open(dsn), &gorm.Config{})")
+	assert.NotContains(t, tidb, "No database password set")
+
+	for _, db := range []string{DBMongoDB, DBDynamoDB, DBElasticsearch} {
+		src := readParsedGo(t, filepath.Join(db, "cmd", "server", "main.go"))
+		assert.Contains(t, src, "\t\"example.com/shop/pkg/logger\"\n", db)
+		assert.NotContains(t, src, "{{", db)
+	}
+}
+
+func TestRoutesFileImports(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	require.NoError(t, os.WriteFile("go.mod", []byte("module example.com/shop\n\ngo 1.21\n"), 0o644))
+	sm := NewSafetyManager(false, true, false)
+	dir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+
+	generateHTTPRoutesFile(dir, "Product", defaultHTTPRoutes("Product"), false, sm)
+	require.NoError(t, os.MkdirAll(filepath.Join(DirInternal, dirMiddleware), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(DirInternal, dirMiddleware, "middleware.go"), []byte("package middleware\n"), 0o644))
+	generateHTTPRoutesFile(dir, "Order", defaultHTTPRoutes("Order"), true, sm)
+
+	routes := readParsedGo(t, filepath.Join(dir, "routes.go"))
+	assert.Contains(t, routes, "\t\"example.com/shop/internal/middleware\"\n")
+	assert.Contains(t, routes, "orderRouter.Use(mux.MiddlewareFunc(middleware.Logging()))")
+}
@@ -2,10 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -24,7 +28,13 @@ without external dependencies and with complete business validations.`,
 		businessRules, _ := cmd.Flags().GetBool("business-rules")
 		timestamps, _ := cmd.Flags().GetBool("timestamps")
 		softDelete, _ := cmd.Flags().GetBool("soft-delete")
+		versioned, _ := cmd.Flags().GetBool("versioned")
+		audit, _ := cmd.Flags().GetBool("audit")
+		multiTenant, _ := cmd.Flags().GetBool("multi-tenant")
 		tests, _ := cmd.Flags().GetBool("tests")
+		retentionFlag, _ := cmd.Flags().GetString("retention")
+		retentionMode, _ := cmd.Flags().GetString("retention-mode")
+		table, _ := cmd.Flags().GetString("table")
 
 		// Initialize configuration integration
 		configIntegration := NewConfigIntegration()
@@ -80,6 +90,31 @@ without external dependencies and with complete business validations.`,
 		}
 
 		validator.errorHandler.ValidateRequiredFlag(fields, "fields")
+		if err := validateTableName(table); err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+
+		// A retention window adds a job purging the soft-deleted rows.
+		var retentionWindow time.Duration
+		retentionConfig := configIntegration.GetRetentionConfig()
+		if retentionFlag != "" {
+			var err error
+			if retentionWindow, err = parseRetentionWindow(retentionFlag); err != nil {
+				ui.Error(err.Error())
+				os.Exit(1)
+			}
+			if retentionMode == "" {
+				retentionMode = retentionConfig.Mode
+			}
+			if retentionMode == "" {
+				retentionMode = RetentionPurge
+			}
+			if err := validateRetention(projectDatabase(), retentionMode, effectiveSoftDelete); err != nil {
+				ui.Error(err.Error())
+				os.Exit(1)
+			}
+		}
 
 		ui.Header(fmt.Sprintf("Generating entity '%s'", entityName))
 		ui.KeyValue("Fields", fields)
@@ -96,6 +131,18 @@ without external dependencies and with complete business validations.`,
 		if effectiveSoftDelete {
 			ui.Feature("Including soft delete", configIntegration.HasConfigFile() && !cmd.Flags().Changed("soft-delete"))
 		}
+		if versioned {
+			ui.Feature("Including optimistic locking", false)
+		}
+		if audit {
+			ui.Feature("Including audit trail", false)
+		}
+		if multiTenant {
+			ui.Feature("Including tenant column", false)
+		}
+		if retentionWindow > 0 {
+			ui.Feature(fmt.Sprintf("Including a %s job after %s", retentionMode, retentionFlag), false)
+		}
 
 		if configIntegration.HasConfigFile() {
 			configIntegration.PrintConfigSummary()
@@ -117,9 +164,20 @@ without external dependencies and with complete business validations.`,
 			ui.DryRun("Previewing changes without creating files")
 		}
 
-		if err := generateEntity(entityName, fields, effectiveValidation, effectiveBusinessRules, effectiveTimestamps, effectiveSoftDelete, tests, fileNamingConvention, sm); err != nil {
+		if table != "" {
+			generateEntityTableName(entityName, table, sm)
+		}
+		if err := generateEntity(entityName, fields, effectiveValidation, effectiveBusinessRules, effectiveTimestamps, effectiveSoftDelete, versioned, audit, multiTenant, tests, fileNamingConvention, sm); err != nil {
 			os.Exit(1)
 		}
+		if retentionWindow > 0 {
+			schedule := retentionConfig.Schedule
+			if schedule == "" {
+				schedule = defaultRetentionSchedule
+			}
+			generateRetention(entityName, retentionWindow, retentionMode, schedule, fileNamingConvention, sm)
+		}
+		runPluginHooks(entityName, sm, pluginHookEntity)
 
 		if dryRun {
 			sm.PrintSummary()
@@ -138,13 +196,30 @@ without external dependencies and with complete business validations.`,
 		if tests {
 			rows = append(rows, []string{fmt.Sprintf("internal/domain/%s_test.go", strings.ToLower(entityName)), "Unit tests"})
 		}
+		if retentionWindow > 0 {
+			rows = append(rows,
+				[]string{fmt.Sprintf("internal/domain/%s_retention.go", strings.ToLower(entityName)), "Retention window"},
+				[]string{fmt.Sprintf("internal/handler/scheduler/%s_retention.go", strings.ToLower(entityName)), "Purge job"},
+				[]string{"pkg/retention/retention.go", "Purge runtime and metrics"},
+				[]string{"cmd/scheduler/main.go", "Scheduler entrypoint"},
+			)
+
+			projectRoot, _ := os.Getwd()
+			depMgr := NewDependencyManager(projectRoot, false)
+			for _, dep := range depMgr.GetRequiredDependenciesForFeature("", map[string]bool{"retention": true}) {
+				if err := depMgr.AddDependency(dep); err != nil {
+					ui.Warning(fmt.Sprintf("Could not add dependency %s: %v", dep.Module, err))
+				}
+			}
+		}
 		ui.Table([]string{"File", "Description"}, rows)
 		ui.Blank()
 		ui.Success("All set! Your entity is ready to use.")
 	},
 }
 
-func generateEntity(entityName, fields string, validation, businessRules, timestamps, softDelete, tests bool, fileNamingConvention string, sm ...*SafetyManager) error {
+func generateEntity(entityName, fields string, validation, businessRules, timestamps, softDelete, versioned, audit, multiTenant, tests bool, fileNamingConvention string, sm ...*SafetyManager) error {
+	defer profileStep("entity", entityName)()
 	// Create domain directory if it doesn't exist
 	domainDir := "internal/domain"
 	_ = os.MkdirAll(domainDir, 0o755)
@@ -153,6 +228,31 @@ func generateEntity(entityName, fields string, validation, businessRules, timest
 	// Note: ParseFieldsWithValidation already adds the ID field
 	fieldsList := parseFieldsWithValidation(fields, validation)
 
+	// TiDB spreads new IDs across regions with AUTO_RANDOM
+	if projectDatabase() == DBTiDB {
+		fieldsList[0] = tidbIDField
+	}
+
+	// MongoDB entities may be identified by the ObjectID of their documents
+	if projectUsesObjectIDs() {
+		fieldsList[0] = objectIDField
+	}
+
+	// Add the optimistic-locking version if requested
+	if versioned {
+		fieldsList = append(fieldsList, versionField)
+	}
+
+	// Add the users who created and last changed the entity if audited
+	if audit {
+		fieldsList = append(fieldsList, auditFields...)
+	}
+
+	// Add the owning tenant if multi-tenant
+	if multiTenant {
+		fieldsList = append(fieldsList, tenantField)
+	}
+
 	// Add timestamps if requested
 	if timestamps {
 		fieldsList = append(fieldsList, Field{Name: "CreatedAt", Type: "time.Time", Tag: "`json:\"created_at\" gorm:\"autoCreateTime\"`"})
@@ -164,20 +264,42 @@ func generateEntity(entityName, fields string, validation, businessRules, timest
 		fieldsList = append(fieldsList, Field{Name: "DeletedAt", Type: "gorm.DeletedAt", Tag: "`json:\"deleted_at,omitempty\" gorm:\"index\"`"})
 	}
 
+	// MongoDB documents key the fields by their column names
+	if projectDatabase() == DBMongoDB {
+		addBSONTags(fieldsList)
+	}
+
 	// Generate entity file with real field-based content. This is the primary
 	// artifact: if it cannot be written, abort without performing partial side
 	// effects (errors/seeds/tests) so the caller can fail cleanly.
 	if err := generateEntityFile(domainDir, entityName, fieldsList, validation, businessRules, timestamps, softDelete, fileNamingConvention, sm...); err != nil {
 		return err
 	}
+	generateEntityTableName(entityName, "", sm...)
+	if err := syncSensitiveFields(sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Could not update the sensitive fields of the access log: %v", err))
+	}
+
+	if versioned {
+		generateDomainErrorKinds(domainDir, sm...)
+	}
+	if audit {
+		generateAuditLogFile(domainDir, sm...)
+	}
+	if multiTenant {
+		generateTenantFile(domainDir, sm...)
+	}
+	if fieldsUseType(fieldsList, FieldFileType) {
+		generateDomainFileType(domainDir, sm...)
+	}
 
 	// Generate errors file if validation is enabled - now with real field validations
 	if validation {
 		generateErrorsFile(domainDir, entityName, fieldsList, sm...)
 	}
 
-	// Generate seed data automatically
-	generateSeedData(domainDir, entityName, fieldsList, sm...)
+	// Generate seed data automatically; the database fills computed fields.
+	generateSeedData(domainDir, entityName, withoutComputedFields(fieldsList), sm...)
 
 	// Generate unit tests if requested
 	if tests {
@@ -192,6 +314,43 @@ type Field struct {
 	Name string
 	Type string
 	Tag  string
+	// Nested holds the fields of a struct{...} field. Type is then the name of
+	// the struct generated next to the entity.
+	Nested []Field
+	// Finders are the repository finders declared on the field with --fields
+	// modifiers such as email:string:findby.
+	Finders []FieldFinder
+	// Enum holds the values of a string field declared with the
+	// enum=draft|published modifier.
+	Enum []string
+	// Hidden lists the handler protocols a field declared with the hide= or
+	// only= modifier is hidden from.
+	Hidden []string
+	// JSONName overrides the json name of a field declared with the json=
+	// modifier.
+	JSONName string
+	// Default is the value a field declared with type=value takes when it is
+	// not given, as written in --fields.
+	Default string
+	// Computed marks a read-only field declared with the computed type or
+	// modifier: the database fills it and inputs never set it.
+	Computed bool
+	// Sensitive marks a field declared with the sensitive modifier, whose
+	// value the access-log middleware redacts.
+	Sensitive bool
+	// Deprecated marks a field declared with the deprecated or deprecated=
+	// modifier, which input DTOs still accept from older clients.
+	Deprecated bool
+	// ReplacedBy names the field a deprecated= field was renamed to. Such a
+	// field is not stored: input DTOs map it onto its replacement.
+	ReplacedBy string
+}
+
+// FieldFinder is a repository finder matching a field and, for multi-column
+// finders, the fields in With.
+type FieldFinder struct {
+	Kind string
+	With []string
 }
 
 func parseFields(fields string) []Field {
@@ -208,37 +367,83 @@ func parseFieldsWithValidation(fields string, withValidation bool) []Field {
 
 	// Normalize field names to idiomatic Go PascalCase (handling snake_case,
 	// kebab-case and common initialisms like ID/URL/API). The struct tag keeps
-	// the snake_case form for json/gorm. The ID field is left untouched.
-	for i := range fieldsList {
-		if fieldsList[i].Name == "ID" {
-			continue
-		}
-		snake := strings.ToLower(strings.ReplaceAll(fieldsList[i].Name, "-", "_"))
-		fieldsList[i].Name = toGoFieldName(fieldsList[i].Name)
-		fieldsList[i].Tag = rebuildFieldTag(fieldsList[i].Tag, snake)
-	}
+	// the json name of the project's naming convention. The ID field is left
+	// untouched.
+	normalizeFieldNames(fieldsList, projectNaming().JSON)
+	addVisibilityTags(fieldsList)
+	addSensitiveTags(fieldsList)
 
 	// If validation is enabled, add validate tags to the field tags
 	if withValidation {
-		for i := range fieldsList {
-			if fieldsList[i].Name != "ID" {
-				// Parse existing tag and add validation tag
-				existingTag := fieldsList[i].Tag
-				// Remove backticks
-				existingTag = strings.Trim(existingTag, "`")
-
-				// Add validation tag based on field type
-				validateTag := getValidateTag(fieldsList[i].Name, fieldsList[i].Type)
-				if validateTag != "" {
-					existingTag += fmt.Sprintf(" validate:\"%s\"", validateTag)
-				}
+		addValidateTags(fieldsList)
+	}
+
+	return fieldsList
+}
 
-				fieldsList[i].Tag = "`" + existingTag + "`"
+// normalizeFieldNames rewrites field names to idiomatic Go PascalCase, keeping
+// the json name of the architecture.naming.json convention, or of the json=
+// modifier, in the struct tag. Nested struct fields are normalized too, and
+// the nested struct type is named after its field.
+func normalizeFieldNames(fieldsList []Field, convention string) {
+	for i := range fieldsList {
+		field := &fieldsList[i]
+		if field.Name == "ID" {
+			continue
+		}
+		jsonName := field.JSONName
+		if jsonName == "" {
+			jsonName = fieldJSONName(field.Name, convention)
+		}
+		column := fieldJSONName(field.Name, JSONNamingSnake)
+		field.Name = toGoFieldName(field.Name)
+		field.Tag = rebuildFieldTag(field.Tag, jsonName)
+		for j, finder := range field.Finders {
+			for k, name := range finder.With {
+				field.Finders[j].With[k] = toGoFieldName(name)
+			}
+		}
+		if field.Nested != nil {
+			if !isFileField(*field) {
+				field.Type = field.Name
 			}
+			field.Tag = fmt.Sprintf("`json:\"%s\" gorm:\"embedded;embeddedPrefix:%s_\"`", jsonName, column)
+			normalizeFieldNames(field.Nested, convention)
 		}
 	}
+}
 
-	return fieldsList
+// addValidateTags appends a validate tag to every field except ID. Nested
+// struct fields are validated through their own sub-fields.
+func addValidateTags(fieldsList []Field) {
+	for i := range fieldsList {
+		if fieldsList[i].Name == "ID" {
+			continue
+		}
+		if fieldsList[i].Nested != nil {
+			addValidateTags(fieldsList[i].Nested)
+			continue
+		}
+
+		// Parse existing tag and add validation tag
+		existingTag := fieldsList[i].Tag
+		// Remove backticks
+		existingTag = strings.Trim(existingTag, "`")
+
+		// Add validation tag based on field type
+		if fieldsList[i].Computed {
+			continue // filled by the database, never by a request
+		}
+		validateTag := getValidateTag(fieldsList[i].Name, fieldsList[i].Type)
+		if len(fieldsList[i].Hidden) > 0 || fieldsList[i].Default != "" || fieldsList[i].Deprecated {
+			validateTag = optionalValidateTag(validateTag)
+		}
+		if validateTag != "" {
+			existingTag += fmt.Sprintf(" validate:\"%s\"", validateTag)
+		}
+
+		fieldsList[i].Tag = "`" + existingTag + "`"
+	}
 }
 
 // commonInitialisms maps lowercase word fragments to their idiomatic Go
@@ -339,6 +544,8 @@ func getValidateTag(fieldName, fieldType string) string {
 		return "required"
 	case fieldType == "bool":
 		return "" // Booleans don't usually need validation
+	case fieldType == FieldJSONType:
+		return "" // any JSON document is accepted
 	case isSliceType(fieldType) || isPointerType(fieldType):
 		// required on a slice/pointer is dubious and the runtime Validate() body
 		// has no coherent check for these, so emit no validate tag (see ENTITY-9).
@@ -432,9 +639,12 @@ func generateEntityFile(dir, entityName string, fields []Field, validation, busi
 
 	writeEntityHeader(&content, fields, businessRules, timestamps, softDelete)
 	writeEntityStruct(&content, entityName, fields)
+	writeNestedStructs(&content, dir, filename, entityName, fields)
 	// Emit stub definitions for unknown custom/named types referenced by fields
 	// (e.g. status:UserStatus) so the generated package compiles (ENTITY-1).
 	writeCustomTypeStubs(&content, entityName, fields)
+	writeEnumValues(&content, entityName, fields)
+	writeEntityConstructor(&content, entityName, fields)
 
 	if validation {
 		writeValidationMethod(&content, entityName, fields)
@@ -448,7 +658,12 @@ func generateEntityFile(dir, entityName string, fields []Field, validation, busi
 		writeSoftDeleteMethods(&content, entityName)
 	}
 
-	if err := writeGoFile(filename, content.String(), sm...); err != nil {
+	// Validate checks enum fields with slices.Contains.
+	src, err := syncImports([]byte(content.String()), "slices")
+	if err != nil {
+		src = []byte(content.String())
+	}
+	if err := writeGoFile(filename, string(src), sm...); err != nil {
 		ui.Error(fmt.Sprintf("Error writing entity file: %v", err))
 		return err
 	}
@@ -459,20 +674,12 @@ func generateEntityFile(dir, entityName string, fields []Field, validation, busi
 func writeEntityHeader(content *strings.Builder, fields []Field, businessRules, timestamps, softDelete bool) {
 	content.WriteString("package domain\n\n")
 
-	// Check if any field is time.Time
-	hasTimeField := false
-	for _, field := range fields {
-		if field.Type == "time.Time" {
-			hasTimeField = true
-			break
-		}
-	}
-
-	needsTime := timestamps || softDelete || hasTimeField
+	needsTime := timestamps || softDelete || fieldsUseType(fields, "time.Time")
 	needsStrings := (businessRules && hasStringBusinessRules(fields)) || hasEmailField(fields)
+	needsDatatypes := fieldsUseType(fields, FieldJSONType)
 	needsGorm := softDelete // Need gorm.io/gorm for gorm.DeletedAt
 
-	if needsTime || needsStrings || needsGorm {
+	if needsTime || needsStrings || needsDatatypes || needsGorm {
 		content.WriteString("import (\n")
 		if needsStrings {
 			content.WriteString("\t\"strings\"\n")
@@ -480,13 +687,30 @@ func writeEntityHeader(content *strings.Builder, fields []Field, businessRules,
 		if needsTime {
 			content.WriteString("\t\"time\"\n")
 		}
+		if needsDatatypes || needsGorm {
+			content.WriteString("\n")
+		}
+		if needsDatatypes {
+			content.WriteString("\t\"gorm.io/datatypes\"\n")
+		}
 		if needsGorm {
-			content.WriteString("\n\t\"gorm.io/gorm\"\n")
+			content.WriteString("\t\"gorm.io/gorm\"\n")
 		}
 		content.WriteString(")\n\n")
 	}
 }
 
+// fieldsUseType reports whether any field, including the fields of nested
+// structs, has the given type.
+func fieldsUseType(fields []Field, fieldType string) bool {
+	for _, field := range fields {
+		if field.Type == fieldType || fieldsUseType(field.Nested, fieldType) {
+			return true
+		}
+	}
+	return false
+}
+
 // writeEntityStruct writes the entity struct definition.
 func writeEntityStruct(content *strings.Builder, entityName string, fields []Field) {
 	fmt.Fprintf(content, "type %s struct {\n", entityName)
@@ -496,15 +720,103 @@ func writeEntityStruct(content *strings.Builder, entityName string, fields []Fie
 	content.WriteString("}\n\n")
 }
 
+// writeEnumValues declares <Entity><Field>Values with the values of each enum
+// field. Validation and the generated layers refer to them, and goca reads them
+// back when it regenerates from the entity.
+func writeEnumValues(content *strings.Builder, entityName string, fields []Field) {
+	for _, field := range fields {
+		if len(field.Enum) == 0 {
+			continue
+		}
+		quoted := make([]string, len(field.Enum))
+		for i, value := range field.Enum {
+			quoted[i] = strconv.Quote(value)
+		}
+		fmt.Fprintf(content, "// %s%sValues are the values %s.%s accepts.\n", entityName, field.Name, entityName, field.Name)
+		fmt.Fprintf(content, "var %s%sValues = []string{%s}\n\n", entityName, field.Name, strings.Join(quoted, ", "))
+	}
+}
+
+// writeNestedStructs writes the struct type of each struct{...} field. GORM
+// stores its fields in the entity table with the "<field>_" column prefix. A
+// type already declared in another domain file is reused, not redeclared, and
+// File is declared in file.go.
+func writeNestedStructs(content *strings.Builder, dir, filename, entityName string, fields []Field) {
+	for _, field := range fields {
+		if field.Nested == nil || isFileField(field) {
+			continue
+		}
+		if domainTypeDeclared(dir, filename, field.Type) {
+			ui.Dim(fmt.Sprintf("Reusing existing domain type %s for %s.%s", field.Type, entityName, field.Name))
+			continue
+		}
+
+		fmt.Fprintf(content, "// %s is embedded in %s; its fields are stored in the %s_ prefixed columns.\n",
+			field.Type, entityName, jsonTagName(field.Tag))
+		writeEntityStruct(content, field.Type, field.Nested)
+	}
+}
+
+// jsonTagName returns the name in the json key of a struct tag.
+func jsonTagName(tag string) string {
+	const key = `json:"`
+	idx := strings.Index(tag, key)
+	if idx < 0 {
+		return ""
+	}
+	name := tag[idx+len(key):]
+	if end := strings.IndexAny(name, `",`); end >= 0 {
+		name = name[:end]
+	}
+	return name
+}
+
+// domainTypeDeclared reports whether a Go file in dir other than exclude
+// declares the named type.
+func domainTypeDeclared(dir, exclude, typeName string) bool {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, path := range matches {
+		if filepath.Clean(path) == filepath.Clean(exclude) || strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				if spec.(*ast.TypeSpec).Name.Name == typeName {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 // writeCustomTypeStubs emits a stub "type X string" definition for each unknown
 // custom/named type referenced by a field, so the generated domain package
 // compiles even when the user passes a type that does not yet exist.
 func writeCustomTypeStubs(content *strings.Builder, entityName string, fields []Field) {
 	seen := map[string]bool{}
 	var stubs []string
+	var all []Field
 	for _, field := range fields {
+		if field.Nested != nil {
+			// The nested struct type itself is written by writeNestedStructs.
+			seen[field.Type] = true
+			all = append(all, field.Nested...)
+			continue
+		}
+		all = append(all, field)
+	}
+	for _, field := range all {
 		base := customTypeBase(field.Type)
-		if base == "" || base == entityName || seen[base] {
+		if base == "" || base == entityName || seen[base] || isValueObjectType(base) {
 			continue
 		}
 		seen[base] = true
@@ -561,28 +873,60 @@ func writeValidationMethod(content *strings.Builder, entityName string, fields [
 		if isSystemField(field.Name) {
 			continue
 		}
-
-		writeFieldValidation(content, entityVar, entityName, field)
+		writeFieldChecks(content, entityVar, entityName, field)
 	}
 
 	content.WriteString("\treturn nil\n")
 	content.WriteString("}\n\n")
 }
 
+// writeFieldChecks writes the Validate checks of a field. Nested fields are
+// checked in place and get ErrInvalid<Entity><Field><Sub> errors; files are
+// optional and computed fields filled by the database, so neither is checked.
+func writeFieldChecks(content *strings.Builder, entityVar, entityName string, field Field) {
+	if isFileField(field) || field.Computed {
+		return
+	}
+	for _, sub := range field.Nested {
+		writeFieldValidation(content, entityVar+"."+field.Name, entityName+field.Name, sub)
+	}
+	writeFieldValidation(content, entityVar, entityName, field)
+}
+
 // writeFieldValidation writes validation logic for a specific field.
 func writeFieldValidation(content *strings.Builder, entityVar, entityName string, field Field) {
+	if isValueObjectType(field.Type) {
+		// The zero value object of an entity without one is invalid too.
+		fmt.Fprintf(content, "\tif err := %s.%s.Validate(); err != nil {\n", entityVar, field.Name)
+		content.WriteString("\t\treturn err\n")
+		content.WriteString("\t}\n")
+		return
+	}
 	switch field.Type {
 	case FieldString:
-		fmt.Fprintf(content, "\tif %s.%s == \"\" {\n", entityVar, field.Name)
-		fmt.Fprintf(content, "\t\treturn ErrInvalid%s%s\n", entityName, field.Name)
-		content.WriteString("\t}\n")
+		// Hidden fields may be left empty by the protocols they are hidden
+		// from, and deprecated ones by new clients, so only their non-empty
+		// values are checked.
+		set := ""
+		if stringFieldMayBeEmpty(field) {
+			set = fmt.Sprintf("%s.%s != \"\" && ", entityVar, field.Name)
+		} else {
+			fmt.Fprintf(content, "\tif %s.%s == \"\" {\n", entityVar, field.Name)
+			fmt.Fprintf(content, "\t\treturn ErrInvalid%s%s\n", entityName, field.Name)
+			content.WriteString("\t}\n")
+		}
 		if isEmailFieldName(field.Name) {
 			// Minimal email-format validation (no external dependency).
-			fmt.Fprintf(content, "\tif !strings.Contains(%s.%s, \"@\") || !strings.Contains(%s.%s, \".\") {\n",
-				entityVar, field.Name, entityVar, field.Name)
+			fmt.Fprintf(content, "\tif %s(!strings.Contains(%s.%s, \"@\") || !strings.Contains(%s.%s, \".\")) {\n",
+				set, entityVar, field.Name, entityVar, field.Name)
 			fmt.Fprintf(content, "\t\treturn ErrInvalid%s%s\n", entityName, field.Name)
 			content.WriteString("\t}\n")
 		}
+		if len(field.Enum) > 0 {
+			fmt.Fprintf(content, "\tif %s!slices.Contains(%s%sValues, %s.%s) {\n", set, entityName, field.Name, entityVar, field.Name)
+			fmt.Fprintf(content, "\t\treturn ErrInvalid%s%sValue\n", entityName, field.Name)
+			content.WriteString("\t}\n")
+		}
 	default:
 		// "< 0" is only meaningful for signed integers and floats; unsigned
 		// integers are always non-negative so no runtime check is emitted.
@@ -594,6 +938,12 @@ func writeFieldValidation(content *strings.Builder, entityVar, entityName string
 	}
 }
 
+// stringFieldMayBeEmpty reports whether Validate() accepts an empty value
+// for the string field: hidden and deprecated fields are optional.
+func stringFieldMayBeEmpty(field Field) bool {
+	return len(field.Hidden) > 0 || field.Deprecated
+}
+
 // isEmailFieldName reports whether a field name denotes an email field.
 func isEmailFieldName(name string) bool {
 	return strings.Contains(strings.ToLower(name), "email")
@@ -605,6 +955,9 @@ func hasEmailField(fields []Field) bool {
 		if f.Type == FieldString && !isSystemField(f.Name) && isEmailFieldName(f.Name) {
 			return true
 		}
+		if hasEmailField(f.Nested) {
+			return true
+		}
 	}
 	return false
 }
@@ -624,7 +977,7 @@ func writeSoftDeleteMethods(content *strings.Builder, entityName string) {
 
 // isSystemField checks if a field is a system-managed field.
 func isSystemField(fieldName string) bool {
-	systemFields := []string{"ID", StringCreatedAt, "UpdatedAt", "DeletedAt"}
+	systemFields := []string{"ID", versionField.Name, auditFields[0].Name, auditFields[1].Name, tenantField.Name, StringCreatedAt, "UpdatedAt", "DeletedAt"}
 	for _, sf := range systemFields {
 		if fieldName == sf {
 			return true
@@ -664,6 +1017,7 @@ func generateBusinessRules(content *strings.Builder, entityName string, fields [
 
 func generateErrorsFile(dir, entityName string, fields []Field, sm ...*SafetyManager) {
 	filename := filepath.Join(dir, "errors.go")
+	defer lockSharedFile(filename)()
 	existingErrors := readExistingErrors(filename, entityName)
 
 	var content strings.Builder
@@ -687,7 +1041,10 @@ func readExistingErrors(filename, entityName string) []string {
 			for _, line := range lines {
 				line = strings.TrimSpace(line)
 				if strings.HasPrefix(line, "ErrInvalid") && strings.Contains(line, "errors.New") {
-					existingErrors = append(existingErrors, "\t"+line)
+					// Undo gofmt's alignment so the declarations compare equal
+					// to the ones written below and keep their place.
+					name, value, _ := strings.Cut(line, "=")
+					existingErrors = append(existingErrors, "\t"+strings.TrimSpace(name)+" = "+strings.TrimSpace(value))
 				}
 			}
 		}
@@ -759,14 +1116,18 @@ func writeExistingErrors(content *strings.Builder, existingErrors []string) {
 // writeFieldErrors writes validation errors for all fields.
 func writeFieldErrors(content *strings.Builder, entityName string, fields []Field, existingErrors []string) {
 	for _, field := range fields {
-		if isSystemField(field.Name) {
+		if isSystemField(field.Name) || isFileField(field) {
+			continue
+		}
+		if field.Nested != nil {
+			writeFieldErrors(content, entityName+field.Name, field.Nested, existingErrors)
 			continue
 		}
 
 		// Only declare ErrInvalid<Entity><Field> when Validate() actually emits a
 		// check for it (string emptiness or signed-numeric "< 0"); otherwise the
 		// constant would be declared but never used (ENTITY-9).
-		if fieldHasBaseValidation(field.Type) {
+		if fieldHasBaseValidation(field) {
 			writeRequiredFieldError(content, entityName, field, existingErrors)
 		}
 		writeTypeSpecificErrors(content, entityName, field, existingErrors)
@@ -774,9 +1135,12 @@ func writeFieldErrors(content *strings.Builder, entityName string, fields []Fiel
 }
 
 // fieldHasBaseValidation reports whether writeFieldValidation emits a check that
-// references the ErrInvalid<Entity><Field> "required" constant for this type.
-func fieldHasBaseValidation(fieldType string) bool {
-	return fieldType == FieldString || isSignedNumericType(fieldType)
+// references the ErrInvalid<Entity><Field> "required" constant for this field.
+func fieldHasBaseValidation(field Field) bool {
+	if field.Type == FieldString {
+		return len(field.Hidden) == 0 || isEmailFieldName(field.Name)
+	}
+	return isSignedNumericType(field.Type)
 }
 
 // writeRequiredFieldError writes the required field error.
@@ -814,6 +1178,14 @@ func writeStringFieldErrors(content *strings.Builder, entityName string, field F
 		}
 	}
 
+	if len(field.Enum) > 0 {
+		valueError := fmt.Sprintf("\tErrInvalid%s%sValue = errors.New(\"%s must be one of %s\")",
+			entityName, field.Name, fieldLower, strings.Join(field.Enum, ", "))
+		if !contains(existingErrors, valueError) {
+			content.WriteString(valueError + "\n")
+		}
+	}
+
 	if strings.Contains(fieldLower, "name") {
 		lengthError := fmt.Sprintf("\tErrInvalid%s%sLength = errors.New(\"%s must be between 2 and 100 characters\")",
 			entityName, field.Name, fieldLower)
@@ -907,16 +1279,25 @@ func generateSeedData(dir, entityName string, fields []Field, sm ...*SafetyManag
 	if err := writeGoFile(filename, content.String(), sm...); err != nil {
 		ui.Error(fmt.Sprintf("Error writing seed file: %v", err))
 	}
+
+	generateYAMLFixtures(entityName, fields, sm...)
 }
 
 // writeSeedFileHeader writes the package declaration and imports for the seed
-// file. The "time" import is only added when the generated body actually
-// references the time package, to avoid an unused-import compile error.
+// file. The "time" and datatypes imports are only added when the generated body
+// actually references them, to avoid an unused-import compile error.
 func writeSeedFileHeader(content *strings.Builder, body string) {
 	content.WriteString("package domain\n\n")
 
-	if strings.Contains(body, "time.") {
+	needsTime := strings.Contains(body, "time.")
+	needsDatatypes := strings.Contains(body, "datatypes.")
+	switch {
+	case needsTime && needsDatatypes:
+		content.WriteString("import (\n\t\"time\"\n\n\t\"gorm.io/datatypes\"\n)\n\n")
+	case needsTime:
 		content.WriteString("import \"time\"\n\n")
+	case needsDatatypes:
+		content.WriteString("import \"gorm.io/datatypes\"\n\n")
 	}
 }
 
@@ -957,7 +1338,7 @@ func writeGoSeedRecord(content *strings.Builder, fields []Field, recordNum int)
 func writeSQLSeeds(content *strings.Builder, entityName string, fields []Field) {
 	fmt.Fprintf(content, "// GetSQL%sSeeds returns SQL INSERT statements for %s\n", entityName, strings.ToLower(entityName))
 	fmt.Fprintf(content, "func GetSQL%sSeeds() string {\n", entityName)
-	fmt.Fprintf(content, "\treturn `-- Sample data for table %s\n", strings.ToLower(entityName))
+	fmt.Fprintf(content, "\treturn `-- Sample data for table %s\n", entityTableName(entityName))
 
 	// Generate SQL INSERT statements
 	for i := 1; i <= 3; i++ {
@@ -970,7 +1351,7 @@ func writeSQLSeeds(content *strings.Builder, entityName string, fields []Field)
 
 // writeSQLInsertStatement writes a single SQL INSERT statement.
 func writeSQLInsertStatement(content *strings.Builder, entityName string, fields []Field, recordNum int) {
-	fmt.Fprintf(content, "INSERT INTO %s (", strings.ToLower(entityName)+"s")
+	fmt.Fprintf(content, "INSERT INTO %s (", entityTableName(entityName))
 
 	// Field names
 	fieldNames := getNonSystemFieldNames(fields)
@@ -987,9 +1368,18 @@ func writeSQLInsertStatement(content *strings.Builder, entityName string, fields
 func getNonSystemFieldNames(fields []Field) []string {
 	var fieldNames []string
 	for _, field := range fields {
-		if !isSystemField(field.Name) {
-			fieldNames = append(fieldNames, strings.ToLower(field.Name))
+		if isSystemField(field.Name) {
+			continue
+		}
+		if field.Nested != nil {
+			// Embedded struct fields live in "<field>_<sub>" columns.
+			prefix := gormTagOptions(field.Tag)["embeddedprefix"]
+			for _, sub := range field.Nested {
+				fieldNames = append(fieldNames, prefix+fieldColumn(sub))
+			}
+			continue
 		}
+		fieldNames = append(fieldNames, fieldColumn(field))
 	}
 	return fieldNames
 }
@@ -998,10 +1388,14 @@ func getNonSystemFieldNames(fields []Field) []string {
 func getSQLFieldValues(fields []Field, recordNum int) []string {
 	var values []string
 	for _, field := range fields {
-		if !isSystemField(field.Name) {
-			sqlValue := generateSQLSampleValue(field, recordNum)
-			values = append(values, sqlValue)
+		if isSystemField(field.Name) {
+			continue
 		}
+		if field.Nested != nil {
+			values = append(values, getSQLFieldValues(field.Nested, recordNum)...)
+			continue
+		}
+		values = append(values, generateSQLSampleValue(field, recordNum))
 	}
 	return values
 }
@@ -1010,6 +1404,14 @@ func getSQLFieldValues(fields []Field, recordNum int) []string {
 // The second return value reports whether a type-correct sample could be produced;
 // when false, callers should omit the field and rely on the Go zero value.
 func generateSampleValue(field Field, index int) (string, bool) {
+	if field.Nested != nil {
+		return nestedSampleLiteral(field, index), true
+	}
+
+	if len(field.Enum) > 0 {
+		return strconv.Quote(enumSampleValue(field, index)), true
+	}
+
 	switch field.Type {
 	case FieldString:
 		return generateStringSampleValue(field.Name, index), true
@@ -1023,10 +1425,33 @@ func generateSampleValue(field Field, index int) (string, bool) {
 		return "time.Now()", true
 	case "[]byte":
 		return fmt.Sprintf("[]byte(\"sample%d\")", index), true
+	case FieldJSONType:
+		return fmt.Sprintf("datatypes.JSON(%q)", sampleJSONDocument(index)), true
 	}
 	return generateDefaultSampleValue(field.Type, index)
 }
 
+// enumSampleValue returns one of the values of an enum field.
+func enumSampleValue(field Field, index int) string {
+	return field.Enum[(index-1)%len(field.Enum)]
+}
+
+// nestedSampleLiteral returns a composite literal of a nested struct field.
+func nestedSampleLiteral(field Field, index int) string {
+	var parts []string
+	for _, sub := range field.Nested {
+		if sample, ok := generateSampleValue(sub, index); ok {
+			parts = append(parts, sub.Name+": "+sample)
+		}
+	}
+	return fmt.Sprintf("%s{%s}", field.Type, strings.Join(parts, ", "))
+}
+
+// sampleJSONDocument returns the sample document stored in JSON columns.
+func sampleJSONDocument(index int) string {
+	return fmt.Sprintf(`{"sample": %d, "tags": ["seed"]}`, index)
+}
+
 // generateStringSampleValue generates string sample values based on field name.
 func generateStringSampleValue(fieldName string, index int) string {
 	fieldLower := strings.ToLower(fieldName)
@@ -1060,6 +1485,10 @@ func generateIntSampleValue(fieldName string, index int) string {
 	fieldLower := strings.ToLower(fieldName)
 
 	switch {
+	case len(fieldName) > 2 && strings.HasSuffix(fieldName, "ID"):
+		// References point at the seed of the same position in the related
+		// entity's fixtures; goca seed run maps them to the inserted IDs.
+		return strconv.Itoa(index)
 	case strings.Contains(fieldLower, "age"):
 		ages := []int{25, 30, 35}
 		return strconv.Itoa(ages[(index-1)%len(ages)])
@@ -1145,6 +1574,8 @@ func generateSQLSampleValue(field Field, index int) string {
 	switch field.Type {
 	case "string":
 		switch {
+		case len(field.Enum) > 0:
+			return fmt.Sprintf("'%s'", enumSampleValue(field, index))
 		case strings.Contains(fieldLower, "name"):
 			names := []string{"John Smith", "Jane Doe", "Bob Johnson"}
 			return fmt.Sprintf("'%s'", names[(index-1)%len(names)])
@@ -1163,6 +1594,9 @@ func generateSQLSampleValue(field Field, index int) string {
 
 	case "int", "int64", "uint", "uint64":
 		switch {
+		case len(field.Name) > 2 && strings.HasSuffix(field.Name, "ID"):
+			// Like the Go seeds, references point at the row of the same position.
+			return strconv.Itoa(index)
 		case strings.Contains(fieldLower, "age"):
 			ages := []int{25, 30, 35}
 			return strconv.Itoa(ages[(index-1)%len(ages)])
@@ -1188,6 +1622,9 @@ func generateSQLSampleValue(field Field, index int) string {
 	case "time.Time":
 		return "NOW()"
 
+	case FieldJSONType:
+		return "'" + sampleJSONDocument(index) + "'"
+
 	default:
 		return generateSQLCompositeSampleValue(field, index)
 	}
@@ -1230,7 +1667,13 @@ func init() {
 	entityCmd.Flags().BoolP("business-rules", "b", false, "Include advanced business rules")
 	entityCmd.Flags().BoolP("timestamps", "t", false, "Include CreatedAt and UpdatedAt fields")
 	entityCmd.Flags().BoolP("soft-delete", "s", false, "Include soft delete (DeletedAt)")
+	entityCmd.Flags().Bool("versioned", false, "Include a Version column for optimistic locking")
+	entityCmd.Flags().Bool("audit", false, "Include CreatedBy/UpdatedBy columns and the AuditLog entity")
+	entityCmd.Flags().Bool("multi-tenant", false, "Include a TenantID column and the tenant context helpers")
 	entityCmd.Flags().Bool("tests", true, "Generate unit tests for the entity")
+	entityCmd.Flags().String("table", "", "Table the entity is stored in, e.g. people (default: the plural snake_case entity name, or architecture.naming in .goca.yaml)")
+	entityCmd.Flags().String("retention", "", "Purge soft-deleted rows older than this window on a schedule, e.g. 90d, 12w or 36h (requires --soft-delete)")
+	entityCmd.Flags().String("retention-mode", "", "What the retention job does with expired rows: purge or archive (default from features.retention.mode, else purge)")
 	entityCmd.Flags().Bool("dry-run", false, "Preview changes without creating files")
 	entityCmd.Flags().Bool("force", false, "Overwrite existing files without asking")
 	entityCmd.Flags().Bool("backup", false, "Backup existing files before overwriting")
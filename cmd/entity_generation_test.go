@@ -331,6 +331,7 @@ func TestGenerateIntSampleValue(t *testing.T) {
 	assert.Equal(t, "100", generateIntSampleValue("Stock", 1))
 	assert.Equal(t, "10", generateIntSampleValue("Quantity", 1))
 	assert.Equal(t, "10", generateIntSampleValue("Other", 1))
+	assert.Equal(t, "2", generateIntSampleValue("CustomerID", 2))
 }
 
 func TestGenerateFloatSampleValue(t *testing.T) {
@@ -33,6 +33,9 @@ func generateEntityTests(domainDir, entityName string, fields []Field, validatio
 		content.WriteString("\t\"time\"\n")
 	}
 	content.WriteString("\n\t\"github.com/stretchr/testify/assert\"\n")
+	if fieldsUseType(fields, FieldJSONType) {
+		content.WriteString("\t\"gorm.io/datatypes\"\n")
+	}
 	content.WriteString(")\n\n") // Generate validation tests if validation is enabled
 	if validation {
 		generateValidationTests(&content, entityName, fields)
@@ -45,7 +48,7 @@ func generateEntityTests(domainDir, entityName string, fields []Field, validatio
 	generateFieldTests(&content, entityName, fields)
 
 	// Write file — SafetyManager/writeFile already emits ui.FileCreated
-	if err := writeFile(testFile, content.String(), sm...); err != nil {
+	if err := writeGoFile(testFile, content.String(), sm...); err != nil {
 		if ui != nil {
 			ui.Error(fmt.Sprintf("Error writing test file: %v", err))
 		} else {
@@ -98,6 +101,10 @@ func generateValidationTests(content *strings.Builder, entityName string, fields
 		if !fieldHasValidationRule(field) {
 			continue
 		}
+		if field.Type == FieldString && stringFieldMayBeEmpty(field) {
+			generateOptionalFieldTestCase(content, entityName, entityLower, field, fields)
+			continue
+		}
 
 		generateInvalidTestCase(content, entityName, entityLower, field, fields)
 	}
@@ -124,6 +131,9 @@ func generateValidationTests(content *strings.Builder, entityName string, fields
 // fieldHasValidationRule reports whether the generated Validate() emits a rule
 // for this field type (non-empty for strings, non-negative for numbers).
 func fieldHasValidationRule(field Field) bool {
+	if field.Computed {
+		return false
+	}
 	switch field.Type {
 	case "string", "int", "int64", "float64":
 		return true
@@ -159,7 +169,26 @@ func generateInvalidTestCase(content *strings.Builder, entityName, entityLower s
 
 	content.WriteString("\t\t\t},\n")
 	content.WriteString("\t\t\twantErr: true,\n")
-	fmt.Fprintf(content, "\t\t\terrMsg: \"%s\",\n", fieldNameLower)
+	// Validation errors start with the field name, so errMsg follows the
+	// project's error string capitalization.
+	fmt.Fprintf(content, "\t\t\terrMsg: \"%s\",\n", styleErrorString(fieldNameLower, projectCodeStyle().ErrorStrings))
+	content.WriteString("\t\t},\n")
+}
+
+// generateOptionalFieldTestCase creates the test case of a valid entity
+// leaving the optional string field empty.
+func generateOptionalFieldTestCase(content *strings.Builder, entityName, entityLower string, optionalField Field, allFields []Field) {
+	content.WriteString("\t\t{\n")
+	fmt.Fprintf(content, "\t\t\tname: \"%s without optional %s\",\n", entityLower, strings.ToLower(optionalField.Name))
+	fmt.Fprintf(content, "\t\t\t%s: %s{\n", entityLower, entityName)
+	for _, field := range allFields {
+		if isTestSkippedField(field.Name) || field.Name == optionalField.Name {
+			continue
+		}
+		fmt.Fprintf(content, "\t\t\t\t%s: %s,\n", field.Name, getValidFieldValue(field))
+	}
+	content.WriteString("\t\t\t},\n")
+	content.WriteString("\t\t\twantErr: false,\n")
 	content.WriteString("\t\t},\n")
 }
 
@@ -197,10 +226,27 @@ func generateConstructorTests(content *strings.Builder, entityName string, field
 				entityLower, field.Name, field.Name)
 			continue
 		}
+		if fieldsUseType(field.Nested, "time.Time") {
+			fmt.Fprintf(content, "\tassert.NotZero(t, %s.%s, \"%s should be set correctly\")\n",
+				entityLower, field.Name, field.Name)
+			continue
+		}
 
 		expectedValue := getValidFieldValue(field)
-		fmt.Fprintf(content, "\tassert.Equal(t, %s, %s.%s, \"%s should be set correctly\")\n",
-			expectedValue, entityLower, field.Name, field.Name)
+		switch {
+		case expectedValue == "nil":
+			// A typed nil pointer never equals an untyped nil.
+			fmt.Fprintf(content, "\tassert.Nil(t, %s.%s, \"%s should be set correctly\")\n",
+				entityLower, field.Name, field.Name)
+		case isNumericType(field.Type) && field.Type != "int" && field.Type != "float64":
+			// Untyped constants default to int and float64, so sized
+			// numbers are compared by value.
+			fmt.Fprintf(content, "\tassert.EqualValues(t, %s, %s.%s, \"%s should be set correctly\")\n",
+				expectedValue, entityLower, field.Name, field.Name)
+		default:
+			fmt.Fprintf(content, "\tassert.Equal(t, %s, %s.%s, \"%s should be set correctly\")\n",
+				expectedValue, entityLower, field.Name, field.Name)
+		}
 	}
 
 	content.WriteString("}\n\n")
@@ -208,10 +254,16 @@ func generateConstructorTests(content *strings.Builder, entityName string, field
 
 // generateFieldTests creates specific tests for field constraints.
 func generateFieldTests(content *strings.Builder, entityName string, fields []Field) {
+	generateFieldTestsFor(content, entityName, fields, fields)
+}
+
+// generateFieldTestsFor creates the constraint tests of the tested fields,
+// building each entity with valid values for all fields.
+func generateFieldTestsFor(content *strings.Builder, entityName string, tested, fields []Field) {
 	entityLower := strings.ToLower(string(entityName[0])) + entityName[1:]
 
-	for _, field := range fields {
-		if field.Name == "ID" || field.Name == "CreatedAt" || field.Name == "UpdatedAt" || field.Name == "DeletedAt" {
+	for _, field := range tested {
+		if field.Name == "ID" || field.Name == "CreatedAt" || field.Name == "UpdatedAt" || field.Name == "DeletedAt" || field.Computed {
 			continue
 		}
 
@@ -228,12 +280,17 @@ func generateFieldTests(content *strings.Builder, entityName string, fields []Fi
 
 			// Valid/invalid cases. Email fields are validated for format, so the
 			// generic "Valid Name" value would not be a valid email — use email
-			// specific cases instead.
-			content.WriteString("\t\t{name: \"empty string\", value: \"\", wantErr: true},\n")
-			if strings.Contains(strings.ToLower(field.Name), "email") {
+			// specific cases instead. Enum fields only accept their values.
+			// Optional fields may be empty.
+			fmt.Fprintf(content, "\t\t{name: \"empty string\", value: \"\", wantErr: %t},\n", !stringFieldMayBeEmpty(field))
+			switch {
+			case len(field.Enum) > 0:
+				fmt.Fprintf(content, "\t\t{name: \"enum value\", value: %q, wantErr: false},\n", field.Enum[0])
+				content.WriteString("\t\t{name: \"value outside the enum\", value: \"not-a-value\", wantErr: true},\n")
+			case strings.Contains(strings.ToLower(field.Name), "email"):
 				content.WriteString("\t\t{name: \"valid email\", value: \"test@example.com\", wantErr: false},\n")
 				content.WriteString("\t\t{name: \"invalid email format\", value: \"notanemail\", wantErr: true},\n")
-			} else {
+			default:
 				content.WriteString("\t\t{name: \"valid value\", value: \"Valid Name\", wantErr: false},\n")
 			}
 
@@ -332,7 +389,10 @@ func isTestSkippedField(name string) bool {
 // tests is a time.Time, which requires importing the "time" package.
 func fieldsNeedTimeImport(fields []Field) bool {
 	for _, f := range fields {
-		if f.Type == "time.Time" && !isTestSkippedField(f.Name) {
+		if isTestSkippedField(f.Name) {
+			continue
+		}
+		if f.Type == "time.Time" || fieldsUseType(f.Nested, "time.Time") {
 			return true
 		}
 	}
@@ -342,8 +402,22 @@ func fieldsNeedTimeImport(fields []Field) bool {
 // Helper functions to generate test values
 
 func getValidFieldValue(field Field) string {
+	if field.Nested != nil {
+		parts := make([]string, 0, len(field.Nested))
+		for _, sub := range field.Nested {
+			parts = append(parts, sub.Name+": "+getValidFieldValue(sub))
+		}
+		return fmt.Sprintf("%s{%s}", field.Type, strings.Join(parts, ", "))
+	}
+	if isValueObjectType(field.Type) {
+		return valueObjectLiteral(field.Type)
+	}
+
 	switch field.Type {
 	case "string":
+		if len(field.Enum) > 0 {
+			return fmt.Sprintf("%q", field.Enum[0])
+		}
 		if strings.Contains(strings.ToLower(field.Name), "email") {
 			return "\"test@example.com\""
 		}
@@ -377,6 +451,8 @@ func getValidFieldValue(field Field) string {
 		return "true"
 	case "time.Time":
 		return "time.Now()"
+	case FieldJSONType:
+		return fmt.Sprintf("datatypes.JSON(%q)", sampleJSONDocument(1))
 	default:
 		return compositeOrZeroLiteral(field.Type)
 	}
@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// exampleBatchFile is the goca feature --batch file an example project is
+// generated from. It is kept in the project as a record of its features.
+const exampleBatchFile = "features.yaml"
+
+// exampleApp is an example project of goca example: the features of its
+// domain and the requests of the walkthrough in its README.
+type exampleApp struct {
+	name        string
+	title       string
+	description string
+	features    []exampleFeature
+	requests    []exampleRequest
+}
+
+// exampleFeature is a feature of an example app. Fields named <entity>_id
+// reference an earlier feature.
+type exampleFeature struct {
+	name   string
+	fields string
+	about  string
+}
+
+// exampleRequest is a request of the README walkthrough.
+type exampleRequest struct {
+	about  string
+	method string
+	path   string
+	body   string
+}
+
+// exampleApps are the apps goca example generates, the default first.
+var exampleApps = []exampleApp{
+	{
+		name:        "todo",
+		title:       "Todo",
+		description: "projects with tasks and the comments left on them",
+		features: []exampleFeature{
+			{name: "Project", fields: "name:string,description:string", about: "A list of tasks"},
+			{name: "Task", fields: "title:string,notes:string,done:bool,due_at:time.Time,project_id:uint", about: "Something to do in a project"},
+			{name: "Comment", fields: "body:string,author:string,task_id:uint", about: "A note left on a task"},
+		},
+		requests: []exampleRequest{
+			{about: "Create a project", method: "POST", path: "/projects", body: `{"name": "Launch", "description": "Ship the first release"}`},
+			{about: "Add a task to it", method: "POST", path: "/tasks", body: `{"title": "Write the docs", "notes": "Start with the README", "due_at": "2030-01-15T09:00:00Z", "project_id": 1}`},
+			{about: "Mark the task done", method: "PUT", path: "/tasks/1", body: `{"done": true}`},
+			{about: "Comment on it", method: "POST", path: "/comments", body: `{"body": "Looks good to me", "author": "alice", "task_id": 1}`},
+			{about: "List the tasks", method: "GET", path: "/tasks"},
+			{about: "Delete the comment", method: "DELETE", path: "/comments/1"},
+		},
+	},
+	{
+		name:        "shop",
+		title:       "Shop",
+		description: "a product catalog with customers and their orders",
+		features: []exampleFeature{
+			{name: "Brand", fields: "name:string,slug:string:findby", about: "The maker of products"},
+			{name: "Product", fields: "name:string,description:string,price:float64,stock:int,brand_id:uint", about: "An item for sale"},
+			{name: "Customer", fields: "name:string,email:string:findby", about: "Someone who places orders"},
+			{name: "Order", fields: "customer_id:uint,product_id:uint,quantity:int,total:float64,status:string=pending:enum=pending|paid|shipped", about: "A product bought by a customer"},
+		},
+		requests: []exampleRequest{
+			{about: "Create a brand", method: "POST", path: "/brands", body: `{"name": "Gopher Gear", "slug": "gopher-gear"}`},
+			{about: "Add a product of it", method: "POST", path: "/products", body: `{"name": "Gopher mug", "description": "Holds 350 ml", "price": 12.5, "stock": 40, "brand_id": 1}`},
+			{about: "Register a customer", method: "POST", path: "/customers", body: `{"name": "Alice", "email": "alice@example.com"}`},
+			{about: "Place an order", method: "POST", path: "/orders", body: `{"customer_id": 1, "product_id": 1, "quantity": 2, "total": 25}`},
+			{about: "Mark it paid", method: "PUT", path: "/orders/1", body: `{"status": "paid"}`},
+			{about: "List the orders", method: "GET", path: "/orders"},
+		},
+	},
+}
+
+// exampleAppNames returns the names of exampleApps.
+func exampleAppNames() []string {
+	names := make([]string, len(exampleApps))
+	for i, app := range exampleApps {
+		names[i] = app.name
+	}
+	return names
+}
+
+// lookupExampleApp returns the example app with the given name.
+func lookupExampleApp(name string) (exampleApp, error) {
+	for _, app := range exampleApps {
+		if app.name == name {
+			return app, nil
+		}
+	}
+	return exampleApp{}, fmt.Errorf("unknown example app %q; use one of: %s", name, strings.Join(exampleAppNames(), ", "))
+}
+
+var exampleCmd = &cobra.Command{
+	Use:   "example [directory]",
+	Short: "Generate a working example project with related features, auth and seeds",
+	Long: `Generates a complete example project with this goca binary: goca init with
+JWT authentication and docker-compose, then three or four related features
+with validation from a features.yaml batch, each with seed fixtures. The
+README.md of the project walks through starting it, seeding the database and
+calling every feature with curl.
+
+The apps are:
+  todo  projects with tasks and the comments left on them
+  shop  a product catalog with customers and their orders
+
+The example is also a smoke test of the generators combined: --verify runs
+go mod tidy, go build ./... and go vet ./... on the project and fails when
+one of them does. go mod tidy needs the module proxy unless the module cache
+already has the dependencies.`,
+	Example: `  goca example
+  goca example my-shop --app shop --database mysql
+  goca example --verify`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runExample,
+}
+
+func init() {
+	exampleCmd.Flags().String("app", exampleApps[0].name, "Example app to generate: "+strings.Join(exampleAppNames(), ", "))
+	exampleCmd.Flags().StringP("module", "m", "", "Go module of the project (default example.com/<directory>)")
+	exampleCmd.Flags().StringP("database", "d", DBPostgres, fmt.Sprintf("Database type (%s)", strings.Join(ValidDatabases, ", ")))
+	exampleCmd.Flags().Bool("verify", false, "Run go mod tidy, go build and go vet on the generated project")
+}
+
+func runExample(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	appName, _ := cmd.Flags().GetString("app")
+	module, _ := cmd.Flags().GetString("module")
+	database, _ := cmd.Flags().GetString("database")
+	verify, _ := cmd.Flags().GetBool("verify")
+
+	app, err := lookupExampleApp(appName)
+	if err != nil {
+		return err
+	}
+	if err := validateDatabaseFlag(database); err != nil {
+		return err
+	}
+	dir := app.name
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	if module == "" {
+		module = "example.com/" + filepath.Base(dir)
+	}
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("%s already exists and is not empty", dir)
+	}
+	goca, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("could not locate the goca binary: %w", err)
+	}
+	if verify {
+		if _, err := exec.LookPath("go"); err != nil {
+			return errors.New("goca example --verify needs the go toolchain on PATH")
+		}
+	}
+
+	ui.Header("Goca Example — " + app.title)
+	ui.KeyValue("Directory", dir)
+	ui.KeyValue("Module", module)
+	ui.KeyValue("Database", database)
+	ui.Blank()
+
+	if err := generateExample(goca, app, dir, module, database, verify, runSelfTestCommand); err != nil {
+		return err
+	}
+
+	ui.Blank()
+	ui.Success(fmt.Sprintf("%s example generated in %s", app.title, dir))
+	ui.Blank()
+	ui.Info("Next steps:")
+	ui.Step(1, "cd "+dir)
+	ui.Step(2, "Follow the walkthrough in README.md")
+	return nil
+}
+
+// generateExample generates app into dir with the goca binary, writes its
+// README walkthrough and, with verify, compiles and vets it. Each step is run
+// with run and the first that fails stops the generation.
+func generateExample(goca string, app exampleApp, dir, module, database string, verify bool, run selfTestRunner) error {
+	parent, name := filepath.Dir(dir), filepath.Base(dir)
+	step := func(label, dir, command string, args ...string) error {
+		ui.Dim("   " + label)
+		if out, err := run(dir, command, args...); err != nil {
+			return fmt.Errorf("%s failed: %w\n%s", label, err, strings.TrimSpace(out))
+		}
+		return nil
+	}
+
+	if err := step("goca init", parent, goca, "init", name, "--module", module, "--database", database, "--auth", "--no-interactive"); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, exampleBatchFile), []byte(exampleBatch(app)), 0o644); err != nil {
+		return err
+	}
+	if err := step("goca feature --batch "+exampleBatchFile, dir, goca, "feature", "--batch", exampleBatchFile, "--no-interactive"); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte(exampleReadme(app, name, database)), 0o644); err != nil {
+		return err
+	}
+	if !verify {
+		return nil
+	}
+	for _, stage := range [][]string{{"go", "mod", "tidy"}, {"go", "build", "./..."}, {"go", "vet", "./..."}} {
+		if err := step(strings.Join(stage, " "), dir, stage[0], stage[1:]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exampleBatch returns the features.yaml of app.
+func exampleBatch(app exampleApp) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Features of the %s example, generated with:\n", app.name)
+	fmt.Fprintf(&b, "#   goca feature --batch %s\n", exampleBatchFile)
+	b.WriteString("handlers: http\n")
+	b.WriteString("validation: true\n")
+	b.WriteString("features:\n")
+	for _, f := range app.features {
+		fmt.Fprintf(&b, "  # %s\n", f.about)
+		fmt.Fprintf(&b, "  - name: %s\n", f.name)
+		fmt.Fprintf(&b, "    fields: %q\n", f.fields)
+	}
+	return b.String()
+}
+
+// exampleReadme returns the README.md walkthrough of app in project.
+func exampleReadme(app exampleApp, project, database string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", app.title)
+	fmt.Fprintf(&b, "An example Clean Architecture API generated by `goca example --app %s`: %s.\n\n", app.name, app.description)
+
+	b.WriteString("## Features\n\n")
+	b.WriteString("| Entity | Fields | Endpoints |\n")
+	b.WriteString("| ------ | ------ | --------- |\n")
+	for _, f := range app.features {
+		fmt.Fprintf(&b, "| `%s` | `%s` | `/api/v1/%s` |\n", f.name, f.fields, toPlural(strings.ToLower(f.name)))
+	}
+	b.WriteString("\nFields named `<entity>_id` reference the entity of that name.\n\n")
+
+	b.WriteString("## How it was generated\n\n")
+	b.WriteString("```bash\n")
+	fmt.Fprintf(&b, "goca init %s --database %s --auth\n", project, database)
+	fmt.Fprintf(&b, "goca feature --batch %s\n", exampleBatchFile)
+	b.WriteString("```\n\n")
+	fmt.Fprintf(&b, "`%s` lists the features and their fields. Each layer of a feature is in its own package:\n\n", exampleBatchFile)
+	b.WriteString("```\n")
+	b.WriteString("internal/domain        entities, validation and seed fixtures\n")
+	b.WriteString("internal/usecase       services and DTOs\n")
+	b.WriteString("internal/repository    GORM repositories\n")
+	b.WriteString("internal/handler/http  HTTP handlers and routes\n")
+	b.WriteString("internal/di            dependency injection container\n")
+	b.WriteString("pkg/auth               JWT issuing and validation\n")
+	b.WriteString("```\n\n")
+	b.WriteString("`goca explain <Entity>` describes how the layers of a feature fit together.\n\n")
+
+	b.WriteString("## Walkthrough\n\n")
+	b.WriteString("### 1. Start the database\n\n")
+	if database == DBSQLite {
+		fmt.Fprintf(&b, "SQLite needs no server: the database is the `%s.db` file, created on the first start.\n\n", project)
+	} else {
+		b.WriteString("```bash\ndocker compose up -d database\n```\n\n")
+		b.WriteString("`docker compose up -d` starts the API in a container next to it instead of step 4.\n\n")
+	}
+	b.WriteString("### 2. Install the dependencies\n\n")
+	b.WriteString("```bash\ngo mod tidy\n```\n\n")
+	b.WriteString("### 3. Seed the database\n\n")
+	b.WriteString("```bash\ngoca seed run\n```\n\n")
+	b.WriteString("Every entity has three fixtures in `internal/domain/<entity>_seeds.go`, inserted after the entities they reference. `goca seed run --undo` removes them.\n\n")
+	b.WriteString("### 4. Run the API\n\n")
+	b.WriteString("```bash\ngo run ./cmd/server\n```\n\n")
+	b.WriteString("`GET http://localhost:8080/health` reports the state of the database.\n\n")
+	b.WriteString("### 5. Call the API\n\n")
+	for _, r := range app.requests {
+		fmt.Fprintf(&b, "%s:\n\n```bash\n", r.about)
+		fmt.Fprintf(&b, "curl -X %s http://localhost:8080/api/v1%s", r.method, r.path)
+		if r.body != "" {
+			fmt.Fprintf(&b, " \\\n  -H 'Content-Type: application/json' \\\n  -d '%s'", r.body)
+		}
+		b.WriteString("\n```\n\n")
+	}
+	b.WriteString("Invalid inputs, e.g. an empty required field, are answered with `400` and the failing fields.\n\n")
+	b.WriteString("### 6. Authenticate\n\n")
+	b.WriteString("`pkg/auth` issues and validates JWTs signed with `JWT_SECRET` from `.env`. Print tokens of the development users:\n\n")
+	b.WriteString("```bash\nmake dev-token\n```\n\n")
+	b.WriteString("The routes are public until a middleware checks the tokens. `goca middleware api --types auth` generates one that validates the `Authorization: Bearer <token>` header.\n\n")
+
+	b.WriteString("## Next steps\n\n")
+	f := app.features[0]
+	fmt.Fprintf(&b, "- Add a field: `goca field add %s \"archived:bool\"`\n", f.name)
+	b.WriteString("- Generate mocks for unit tests: `goca mocks`\n")
+	b.WriteString("- Check the architecture rules: `goca lint`\n")
+	b.WriteString("- See the generated files you changed: `goca status`\n")
+	return b.String()
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateExample(t *testing.T) {
+	defer setupDiscardUI(t)()
+	root := t.TempDir()
+
+	var commands []string
+	run := func(dir, name string, args ...string) (string, error) {
+		commands = append(commands, filepath.Base(dir)+": "+strings.Join(append([]string{filepath.Base(name)}, args...), " "))
+		if args[0] == "init" {
+			return "", os.MkdirAll(filepath.Join(dir, args[1]), 0o755)
+		}
+		return "", nil
+	}
+
+	app, err := lookupExampleApp("shop")
+	require.NoError(t, err)
+	dir := filepath.Join(root, "my-shop")
+	require.NoError(t, generateExample("/usr/bin/goca", app, dir, "example.com/my-shop", DBMySQL, true, run))
+
+	assert.Equal(t, []string{
+		filepath.Base(root) + ": goca init my-shop --module example.com/my-shop --database mysql --auth --no-interactive",
+		"my-shop: goca feature --batch features.yaml --no-interactive",
+		"my-shop: go mod tidy",
+		"my-shop: go build ./...",
+		"my-shop: go vet ./...",
+	}, commands)
+
+	batch, err := loadFeatureBatch(filepath.Join(dir, exampleBatchFile))
+	require.NoError(t, err)
+	require.Len(t, batch.Features, 4)
+	assert.Equal(t, "Order", batch.Features[3].Name)
+	assert.Equal(t, "customer_id:uint,product_id:uint,quantity:int,total:float64,status:string=pending:enum=pending|paid|shipped", string(batch.Features[3].Fields))
+	assert.True(t, *batch.Validation)
+
+	readme, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(readme), "goca init my-shop --database mysql --auth")
+	assert.Contains(t, string(readme), "| `Brand` | `name:string,slug:string:findby` | `/api/v1/brands` |")
+	assert.Contains(t, string(readme), "docker compose up -d database")
+	assert.Contains(t, string(readme), "curl -X PUT http://localhost:8080/api/v1/orders/1 \\\n  -H 'Content-Type: application/json' \\\n  -d '{\"status\": \"paid\"}'")
+}
+
+func TestGenerateExample_FeatureFailure(t *testing.T) {
+	defer setupDiscardUI(t)()
+	run := func(dir, name string, args ...string) (string, error) {
+		switch args[0] {
+		case "init":
+			return "", os.MkdirAll(filepath.Join(dir, args[1]), 0o755)
+		case "feature":
+			return "✗ Task: fields: invalid field", errors.New("exit status 1")
+		}
+		t.Fatalf("unexpected command %s %v", name, args)
+		return "", nil
+	}
+
+	app, err := lookupExampleApp("todo")
+	require.NoError(t, err)
+	err = generateExample("goca", app, filepath.Join(t.TempDir(), "todo"), "example.com/todo", DBSQLite, true, run)
+	assert.ErrorContains(t, err, "goca feature --batch features.yaml failed")
+	assert.ErrorContains(t, err, "invalid field")
+
+	_, err = lookupExampleApp("blog")
+	assert.ErrorContains(t, err, `unknown example app "blog"; use one of: todo, shop`)
+}
+
+func TestExampleReadme_SQLite(t *testing.T) {
+	app, err := lookupExampleApp("todo")
+	require.NoError(t, err)
+	readme := exampleReadme(app, "todo", DBSQLite)
+	assert.Contains(t, readme, "the database is the `todo.db` file")
+	assert.NotContains(t, readme, "docker compose up -d database")
+	assert.Contains(t, readme, "goca field add Project \"archived:bool\"")
+}
@@ -0,0 +1,597 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// expandContractDir is the directory in the migrations directory that stages
+// the later phases of expand/contract changes. golang-migrate does not read
+// subdirectories, so a staged phase only runs once goca migrate advance moves
+// it next to the other migrations.
+const expandContractDir = "expand_contract"
+
+// columnChange is a column written by an expand/contract change: a new column
+// filled with its default, or copied from the column it replaces.
+type columnChange struct {
+	Column migrationColumn
+	// From is the replaced column of a rename; its Name is "" for an added
+	// column.
+	From migrationColumn
+}
+
+// expandContract is a column change split into phases that each keep both the
+// running and the next version of the application working:
+//
+//  1. expand: add the new columns as nullable columns
+//  2. backfill: fill the existing rows in batches (a data migration)
+//  3. enforce: fill the rows written since, then add NOT NULL and unique
+//     constraints (staged)
+//  4. contract: drop the replaced column of a rename (staged)
+type expandContract struct {
+	Entity  string
+	Table   string
+	Name    string
+	Changes []columnChange
+}
+
+// addColumnsChange returns the expand/contract change adding the columns of
+// fields.
+func addColumnsChange(entity, database string, fields []Field) expandContract {
+	table := entityTableName(entity)
+	var names []string
+	var changes []columnChange
+	for _, column := range fieldColumns(fields, database) {
+		names = append(names, column.Name)
+		changes = append(changes, columnChange{Column: column})
+	}
+	return expandContract{
+		Entity:  entity,
+		Table:   table,
+		Name:    fmt.Sprintf("add_%s_to_%s", strings.Join(names, "_"), table),
+		Changes: changes,
+	}
+}
+
+// renameColumnChange returns the expand/contract change moving the column of
+// from into the column of to.
+func renameColumnChange(entity, database string, from, to Field) expandContract {
+	table := entityTableName(entity)
+	source := fieldColumns([]Field{from}, database)[0]
+	target := fieldColumns([]Field{to}, database)[0]
+	return expandContract{
+		Entity:  entity,
+		Table:   table,
+		Name:    fmt.Sprintf("rename_%s_to_%s_in_%s", source.Name, target.Name, table),
+		Changes: []columnChange{{Column: target, From: source}},
+	}
+}
+
+// renames reports whether the change replaces a column.
+func (p expandContract) renames() bool {
+	return len(p.Changes) > 0 && p.Changes[0].From.Name != ""
+}
+
+// columns returns the new columns.
+func (p expandContract) columns() []migrationColumn {
+	columns := make([]migrationColumn, 0, len(p.Changes))
+	for _, c := range p.Changes {
+		columns = append(columns, c.Column)
+	}
+	return columns
+}
+
+// columnNames returns the names of the new columns, joined for comments.
+func (p expandContract) columnNames() string {
+	names := make([]string, 0, len(p.Changes))
+	for _, c := range p.Changes {
+		names = append(names, c.Column.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// backfilled returns the changes whose column existing rows need a value for:
+// renamed columns and columns with a default.
+func (p expandContract) backfilled() []columnChange {
+	var changes []columnChange
+	for _, c := range p.Changes {
+		if c.From.Name != "" || c.Column.Default != "" {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// enforces reports whether the change has constraints to add once the rows
+// are filled.
+func (p expandContract) enforces() bool {
+	for _, c := range p.Changes {
+		if c.Column.NotNull || c.Column.Unique {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceExpr returns the value the backfill writes to the column of c: the
+// replaced column, converted when its type changes, or the column's default.
+func (c columnChange) sourceExpr(database string) string {
+	if c.From.Name == "" {
+		if database == DBSQLServer && c.Column.Default == "false" {
+			return "0"
+		}
+		return columnLiteral(c.Column.Default, database)
+	}
+	return convertColumn(c.From, c.Column, database)
+}
+
+// convertColumn returns from converted to the type of to. MySQL and SQLite
+// convert on assignment; PostgreSQL, SQL Server and Oracle need a CAST.
+func convertColumn(from, to migrationColumn, database string) string {
+	if from.Type == to.Type || isMySQLCompatible(database) || database == DBSQLite {
+		return from.Name
+	}
+	return fmt.Sprintf("CAST(%s AS %s)", from.Name, to.Type)
+}
+
+// backfillCondition returns the condition selecting the rows the backfill
+// still has to fill.
+func (p expandContract) backfillCondition() string {
+	var conditions []string
+	for _, c := range p.backfilled() {
+		if c.From.Name != "" {
+			conditions = append(conditions, fmt.Sprintf("%s IS NULL AND %s IS NOT NULL", c.Column.Name, c.From.Name))
+		} else {
+			conditions = append(conditions, c.Column.Name+" IS NULL")
+		}
+	}
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+	return "(" + strings.Join(conditions, ") OR (") + ")"
+}
+
+// backfillAssignments returns the SET list of the backfill. COALESCE keeps the
+// values rows already have when several columns are filled together.
+func (p expandContract) backfillAssignments(database string) string {
+	var assignments []string
+	for _, c := range p.backfilled() {
+		assignments = append(assignments, fmt.Sprintf("%s = COALESCE(%s, %s)", c.Column.Name, c.Column.Name, c.sourceExpr(database)))
+	}
+	return strings.Join(assignments, ", ")
+}
+
+// backfillStatement returns the UPDATE filling the rows that need it.
+func (p expandContract) backfillStatement(database string) string {
+	return fmt.Sprintf("UPDATE %s SET %s WHERE %s;\n", p.Table, p.backfillAssignments(database), p.backfillCondition())
+}
+
+// batchStatement returns the UPDATE filling at most ? rows, for the backfill
+// data migration.
+func (p expandContract) batchStatement(database string) string {
+	set, where := p.backfillAssignments(database), p.backfillCondition()
+	switch database {
+	case DBMySQL, DBMariaDB, DBTiDB:
+		return fmt.Sprintf("UPDATE %s SET %s WHERE %s ORDER BY id LIMIT ?", p.Table, set, where)
+	case DBSQLServer:
+		return fmt.Sprintf("UPDATE TOP (?) %s SET %s WHERE %s", p.Table, set, where)
+	case DBOracle:
+		return fmt.Sprintf("UPDATE %s SET %s WHERE (%s) AND ROWNUM <= ?", p.Table, set, where)
+	}
+	return fmt.Sprintf("UPDATE %s SET %s WHERE id IN (SELECT id FROM %s WHERE %s ORDER BY id LIMIT ?)", p.Table, set, p.Table, where)
+}
+
+// expandMigration returns the migration adding the new columns without
+// constraints, so the running version can keep inserting rows.
+func (p expandContract) expandMigration(database string) (up, down string) {
+	var nullable []migrationColumn
+	for _, column := range p.columns() {
+		nullable = append(nullable, migrationColumn{Name: column.Name, Type: column.Type})
+	}
+
+	var u, d strings.Builder
+	fmt.Fprintf(&u, "-- Expand: add %s to %s as nullable columns.\n", p.columnNames(), p.Table)
+	u.WriteString("-- Apply before deploying the version that uses them; the running version\n")
+	u.WriteString("-- does not read them.\n")
+	writeAddColumns(&u, p.Table, database, nullable)
+	fmt.Fprintf(&d, "-- Remove %s from %s\n", p.columnNames(), p.Table)
+	writeDropColumns(&d, p.Table, database, nullable)
+	return u.String(), d.String()
+}
+
+// enforceMigration returns the migration filling the rows the previous
+// version wrote after the backfill and adding the constraints of the new
+// columns.
+func (p expandContract) enforceMigration(database string) (up, down string) {
+	var u, d strings.Builder
+	fmt.Fprintf(&u, "-- Enforce: add the constraints of %s in %s.\n", p.columnNames(), p.Table)
+	u.WriteString("-- Apply once the backfill is done and no instance of the previous version\n")
+	u.WriteString("-- is running; the UPDATE fills the rows it wrote since the backfill.\n")
+	if len(p.backfilled()) > 0 {
+		u.WriteString(p.backfillStatement(database))
+	}
+	writeEnforceConstraints(&u, p.Table, database, p.columns())
+
+	fmt.Fprintf(&d, "-- Drop the constraints of %s in %s\n", p.columnNames(), p.Table)
+	writeRelaxConstraints(&d, p.Table, database, p.columns())
+	return u.String(), d.String()
+}
+
+// contractMigration returns the migration dropping the column a rename
+// replaced. Its down migration restores the column from the new one.
+func (p expandContract) contractMigration(database string) (up, down string) {
+	c := p.Changes[0]
+	var u, d strings.Builder
+	fmt.Fprintf(&u, "-- Contract: drop %s from %s, replaced by %s.\n", c.From.Name, p.Table, c.Column.Name)
+	u.WriteString("-- Apply once the new version is verified and nothing reads the old column.\n")
+	writeDropColumns(&u, p.Table, database, []migrationColumn{c.From})
+
+	fmt.Fprintf(&d, "-- Restore %s in %s from %s\n", c.From.Name, p.Table, c.Column.Name)
+	writeAddColumns(&d, p.Table, database, []migrationColumn{c.From})
+	fmt.Fprintf(&d, "UPDATE %s SET %s = %s;\n", p.Table, c.From.Name, convertColumn(c.Column, c.From, database))
+	return u.String(), d.String()
+}
+
+// renameMigration returns the single migration of a rename without
+// expand/contract: a column rename, or a copy into a column of the new type.
+func (p expandContract) renameMigration(database string) (up, down string) {
+	c := p.Changes[0]
+	if c.From.Type == c.Column.Type {
+		var u, d strings.Builder
+		fmt.Fprintf(&u, "-- Rename %s to %s in %s\n", c.From.Name, c.Column.Name, p.Table)
+		writeRenameColumn(&u, p.Table, database, c.From.Name, c.Column.Name)
+		fmt.Fprintf(&d, "-- Rename %s back to %s in %s\n", c.Column.Name, c.From.Name, p.Table)
+		writeRenameColumn(&d, p.Table, database, c.Column.Name, c.From.Name)
+		return u.String(), d.String()
+	}
+
+	expandUp, expandDown := p.expandMigration(database)
+	enforceUp, enforceDown := p.enforceMigration(database)
+	contractUp, contractDown := p.contractMigration(database)
+	return stripSQLComments(expandUp + enforceUp + contractUp), stripSQLComments(contractDown + enforceDown + expandDown)
+}
+
+// stripSQLComments drops the comment lines of a migration assembled from
+// phases, keeping the first as its title.
+func stripSQLComments(sql string) string {
+	var b strings.Builder
+	for i, line := range strings.SplitAfter(sql, "\n") {
+		if i > 0 && strings.HasPrefix(line, "--") {
+			continue
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// writeRenameColumn writes the statement renaming a column.
+func writeRenameColumn(content *strings.Builder, table, database, from, to string) {
+	if database == DBSQLServer {
+		fmt.Fprintf(content, "EXEC sp_rename '%s.%s', '%s', 'COLUMN';\n", table, from, to)
+		return
+	}
+	fmt.Fprintf(content, "ALTER TABLE %s RENAME COLUMN %s TO %s;\n", table, from, to)
+}
+
+// writeEnforceConstraints writes the statements making columns NOT NULL and
+// creating their unique indexes.
+func writeEnforceConstraints(content *strings.Builder, table, database string, columns []migrationColumn) {
+	for _, column := range columns {
+		if column.NotNull {
+			writeColumnNullability(content, table, database, column, true)
+		}
+		if column.Unique {
+			writeCreateUniqueIndex(content, table, column)
+		}
+	}
+}
+
+// writeRelaxConstraints reverts writeEnforceConstraints.
+func writeRelaxConstraints(content *strings.Builder, table, database string, columns []migrationColumn) {
+	for _, column := range columns {
+		if column.Unique {
+			writeDropUniqueIndex(content, table, database, column)
+		}
+		if column.NotNull {
+			writeColumnNullability(content, table, database, column, false)
+		}
+	}
+}
+
+// writeColumnNullability writes the statement making an existing column NOT
+// NULL, or nullable again. SQLite cannot change it without rebuilding the
+// table, so the column stays nullable there.
+func writeColumnNullability(content *strings.Builder, table, database string, column migrationColumn, notNull bool) {
+	null := "NULL"
+	if notNull {
+		null = "NOT NULL"
+	}
+	switch database {
+	case DBMySQL, DBMariaDB, DBTiDB:
+		fmt.Fprintf(content, "ALTER TABLE %s MODIFY COLUMN %s %s %s;\n", table, column.Name, column.Type, null)
+	case DBSQLServer:
+		fmt.Fprintf(content, "ALTER TABLE %s ALTER COLUMN %s %s %s;\n", table, column.Name, column.Type, null)
+	case DBOracle:
+		fmt.Fprintf(content, "ALTER TABLE %s MODIFY (%s %s);\n", table, column.Name, null)
+	case DBSQLite:
+		if notNull {
+			fmt.Fprintf(content, "-- SQLite cannot make the existing column %s NOT NULL; it stays nullable.\n", column.Name)
+		}
+	default:
+		action := "DROP NOT NULL"
+		if notNull {
+			action = "SET NOT NULL"
+		}
+		fmt.Fprintf(content, "ALTER TABLE %s ALTER COLUMN %s %s;\n", table, column.Name, action)
+	}
+}
+
+// writeExpandContract writes the phases of p: the expand migration and the
+// backfill data migration now, and the enforce and contract migrations staged
+// for goca migrate advance. It returns the next steps to print.
+func writeExpandContract(ci *ConfigIntegration, p expandContract, database string, sm ...*SafetyManager) ([]string, error) {
+	if sqlDialect(database) < 0 {
+		ui.Warning(fmt.Sprintf("Skipping --expand-contract: %s has no table schema to alter", database))
+		return nil, nil
+	}
+
+	up, down := p.expandMigration(database)
+	path, err := writeFieldMigration(ci, "expand_"+p.Name, up, down, sm...)
+	if err != nil {
+		return nil, err
+	}
+	version := strings.SplitN(filepath.Base(path), "_", 2)[0]
+	steps := []string{"Apply the expand migration: make migrate-up"}
+
+	if len(p.backfilled()) > 0 {
+		migration := dataMigration{
+			Version:       nextMigrationVersion(dataMigrationDir, migrationVersioning(ci)),
+			Name:          "Backfill" + toPascalCase(p.Name),
+			SchemaVersion: version,
+			BatchSize:     500,
+			Table:         p.Table,
+			Pending:       p.backfillCondition(),
+			Statement:     p.batchStatement(database),
+		}
+		// The runner is regenerated on every run, like goca migrate data does.
+		manager := NewSafetyManager(false, true, false)
+		if len(sm) > 0 && sm[0] != nil {
+			manager = sm[0]
+			defer func(force bool) { manager.Force = force }(manager.Force)
+			manager.Force = true
+		}
+		if err := generateDataMigration(migration, database, manager); err != nil {
+			return nil, err
+		}
+		steps = append(steps, "Backfill the existing rows: go run ./cmd/datamigrate")
+	}
+	steps = append(steps, "Deploy the new version")
+
+	var phases [][3]string
+	if p.enforces() {
+		up, down := p.enforceMigration(database)
+		phases = append(phases, [3]string{"enforce_" + p.Name, up, down})
+	}
+	if p.renames() {
+		up, down := p.contractMigration(database)
+		phases = append(phases, [3]string{"contract_" + p.Name, up, down})
+	}
+	if len(phases) == 0 {
+		return steps, nil
+	}
+
+	plan := version + "_" + p.Name
+	stage := filepath.Join(projectMigrationsDir(ci), expandContractDir, plan)
+	for i, phase := range phases {
+		base := filepath.Join(stage, fmt.Sprintf("%d_%s", i+2, phase[0]))
+		if err := writeFile(base+".up.sql", phase[1], sm...); err != nil {
+			return nil, err
+		}
+		if err := writeFile(base+".down.sql", phase[2], sm...); err != nil {
+			return nil, err
+		}
+	}
+	if err := writeFile(filepath.Join(stage, "README.md"), buildExpandContractReadme(p, plan), sm...); err != nil {
+		return nil, err
+	}
+
+	if p.enforces() {
+		steps = append(steps, fmt.Sprintf("Once the previous version is retired: goca migrate advance %s && make migrate-up", plan))
+	}
+	if p.renames() {
+		steps = append(steps, fmt.Sprintf("Once the new version is verified: goca migrate advance %s && make migrate-up", plan))
+	}
+	return steps, nil
+}
+
+// buildExpandContractReadme returns the README of a staged expand/contract
+// change, listing its phases in the order they run.
+func buildExpandContractReadme(p expandContract, plan string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", plan)
+	if p.renames() {
+		c := p.Changes[0]
+		fmt.Fprintf(&b, "Zero-downtime rename of `%s.%s` to `%s` (%s).\n\n", p.Table, c.From.Name, c.Column.Name, c.Column.Type)
+	} else {
+		fmt.Fprintf(&b, "Zero-downtime addition of %s to `%s`.\n\n", p.columnNames(), p.Table)
+	}
+	b.WriteString("| Phase | Run | When |\n")
+	b.WriteString("| ----- | --- | ---- |\n")
+	b.WriteString("| expand | `make migrate-up` | before deploying the new version |\n")
+	if len(p.backfilled()) > 0 {
+		b.WriteString("| backfill | `go run ./cmd/datamigrate` | after the expand migration, before deploying the new version |\n")
+	}
+	if p.enforces() {
+		b.WriteString("| enforce | `goca migrate advance`, `make migrate-up` | once no instance of the previous version is running |\n")
+	}
+	if p.renames() {
+		b.WriteString("| contract | `goca migrate advance`, `make migrate-up` | once the new version is verified |\n")
+	}
+	b.WriteString("\nEach `goca migrate advance " + plan + "` moves the next phase staged in this\n")
+	b.WriteString("directory into the migrations directory. The directory is removed with the\n")
+	b.WriteString("last phase.\n")
+	if p.renames() {
+		b.WriteString("\nUntil the enforce phase, instances of the previous version write only the old\n")
+		b.WriteString("column. The enforce migration copies the rows they insert, but not their\n")
+		b.WriteString("updates of rows already copied: stop writes through the previous version\n")
+		b.WriteString("before the backfill if those updates matter.\n")
+	}
+	return b.String()
+}
+
+var migrateAdvanceCmd = &cobra.Command{
+	Use:   "advance [plan]",
+	Short: "Move the next staged phase of an expand/contract change into the migrations",
+	Long: `Moves the next staged phase of an expand/contract change, written by
+goca field add or goca field rename with --expand-contract, from
+<migrations>/expand_contract/<plan>/ into the migrations directory with a new
+version. Apply it with 'make migrate-up'.
+
+Phases are advanced one deploy at a time: the enforce phase once no instance
+of the previous version is running, the contract phase once the new version
+is verified. The plan can be omitted when only one change is staged.
+
+Examples:
+  goca migrate advance
+  goca migrate advance 007_rename_name_to_full_name_in_users --dry-run`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		ci := NewConfigIntegration()
+		_ = ci.LoadConfigForProject()
+		dir := projectMigrationsDir(ci)
+		plans, err := stagedExpandContractPlans(dir)
+		if err != nil {
+			return err
+		}
+		plan, err := selectExpandContractPlan(plans, args, dir)
+		if err != nil {
+			return err
+		}
+
+		stage := filepath.Join(dir, expandContractDir, plan)
+		phases, err := readMigrations(stage)
+		if err != nil {
+			return err
+		}
+		next := phases[0]
+		if next.Up == "" || next.Down == "" {
+			return fmt.Errorf("staged phase %s_%s needs both an up and a down file", next.Version, next.Name)
+		}
+		up, err := os.ReadFile(next.Up)
+		if err != nil {
+			return err
+		}
+		down, err := os.ReadFile(next.Down)
+		if err != nil {
+			return err
+		}
+
+		base := filepath.Join(dir, nextMigrationVersion(dir, migrationVersioning(ci))+"_"+next.Name)
+		ui.Header("Goca Migrate Advance")
+		ui.Blank()
+		ui.KeyValue("Plan", plan)
+		ui.KeyValue("Phase", next.Name)
+		ui.KeyValue("Migration", base+".up.sql")
+		ui.Blank()
+		for _, line := range strings.Split(string(up), "\n") {
+			if strings.HasPrefix(line, "--") {
+				ui.Dim(line)
+			}
+		}
+
+		sm := NewSafetyManager(dryRun, false, false)
+		if err := sm.WriteFile(base+".up.sql", string(up)); err != nil {
+			return err
+		}
+		if err := sm.WriteFile(base+".down.sql", string(down)); err != nil {
+			return err
+		}
+		if dryRun {
+			sm.PrintSummary()
+			return nil
+		}
+
+		if len(phases) == 1 {
+			err = os.RemoveAll(stage)
+		} else {
+			err = removeFiles(next.Up, next.Down)
+		}
+		if err != nil {
+			return fmt.Errorf("removing staged phase: %w", err)
+		}
+
+		ui.Blank()
+		ui.Success(fmt.Sprintf("Moved %s into %s", next.Name, dir))
+		steps := []string{"Apply it with 'make migrate-up'"}
+		if len(phases) > 1 {
+			steps = append(steps, fmt.Sprintf("Advance %s as its comment describes: goca migrate advance %s", phases[1].Name, plan))
+		}
+		ui.NextSteps(steps)
+		return nil
+	},
+}
+
+// stagedExpandContractPlans returns the expand/contract changes staged in the
+// migrations directory dir, in version order.
+func stagedExpandContractPlans(dir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, expandContractDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading staged phases: %w", err)
+	}
+	var plans []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if phases, err := readMigrations(filepath.Join(dir, expandContractDir, entry.Name())); err == nil && len(phases) > 0 {
+			plans = append(plans, entry.Name())
+		}
+	}
+	sort.Slice(plans, func(i, j int) bool {
+		return migrationVersionNumber(strings.SplitN(plans[i], "_", 2)[0]) < migrationVersionNumber(strings.SplitN(plans[j], "_", 2)[0])
+	})
+	return plans, nil
+}
+
+// selectExpandContractPlan returns the plan named in args, matched by its full
+// name or its name without the version, or the only staged plan.
+func selectExpandContractPlan(plans, args []string, dir string) (string, error) {
+	if len(plans) == 0 {
+		return "", fmt.Errorf("no expand/contract phases are staged in %s", filepath.Join(dir, expandContractDir))
+	}
+	if len(args) == 0 {
+		if len(plans) > 1 {
+			return "", fmt.Errorf("%d expand/contract changes are staged, name one of: %s", len(plans), strings.Join(plans, ", "))
+		}
+		return plans[0], nil
+	}
+	for _, plan := range plans {
+		if plan == args[0] || strings.SplitN(plan, "_", 2)[1] == args[0] {
+			return plan, nil
+		}
+	}
+	return "", fmt.Errorf("no staged expand/contract change %q, staged: %s", args[0], strings.Join(plans, ", "))
+}
+
+// removeFiles removes paths, stopping at the first error.
+func removeFiles(paths ...string) error {
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	migrateAdvanceCmd.Flags().Bool("dry-run", false, "Show the migration that would be written without moving the phase")
+
+	migrateCmd.AddCommand(migrateAdvanceCmd)
+}
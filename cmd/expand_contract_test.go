@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandContract_RenamePhases(t *testing.T) {
+	chdirTemp(t)
+	from := Field{Name: "Price", Type: "float64", Tag: "`json:\"price\" gorm:\"not null\"`"}
+	to := Field{Name: "PriceCents", Type: "int64", Tag: "`json:\"price_cents\" gorm:\"not null;uniqueIndex\"`"}
+	change := renameColumnChange("Product", DBPostgres, from, to)
+	assert.Equal(t, "rename_price_to_price_cents_in_products", change.Name)
+
+	up, down := change.expandMigration(DBPostgres)
+	assert.Contains(t, up, "ALTER TABLE products ADD COLUMN price_cents bigint;\n")
+	assert.Equal(t, "-- Remove price_cents from products\nALTER TABLE products DROP COLUMN price_cents;\n", down)
+
+	assert.Equal(t, "UPDATE products SET price_cents = COALESCE(price_cents, CAST(price AS bigint)) WHERE id IN "+
+		"(SELECT id FROM products WHERE price_cents IS NULL AND price IS NOT NULL ORDER BY id LIMIT ?)", change.batchStatement(DBPostgres))
+	assert.Equal(t, "UPDATE products SET price_cents = COALESCE(price_cents, price) WHERE price_cents IS NULL AND price IS NOT NULL ORDER BY id LIMIT ?",
+		renameColumnChange("Product", DBMySQL, from, to).batchStatement(DBMySQL))
+	assert.Contains(t, change.batchStatement(DBSQLServer), "UPDATE TOP (?) products SET")
+
+	up, down = change.enforceMigration(DBPostgres)
+	assert.Contains(t, up, "UPDATE products SET price_cents = COALESCE(price_cents, CAST(price AS bigint)) WHERE price_cents IS NULL AND price IS NOT NULL;\n"+
+		"ALTER TABLE products ALTER COLUMN price_cents SET NOT NULL;\n"+
+		"CREATE UNIQUE INDEX idx_products_price_cents ON products (price_cents);\n")
+	assert.Contains(t, down, "DROP INDEX idx_products_price_cents;\nALTER TABLE products ALTER COLUMN price_cents DROP NOT NULL;\n")
+	up, _ = change.enforceMigration(DBMySQL)
+	assert.Contains(t, up, "ALTER TABLE products MODIFY COLUMN price_cents bigint NOT NULL;\n")
+	up, _ = change.enforceMigration(DBSQLite)
+	assert.Contains(t, up, "-- SQLite cannot make the existing column price_cents NOT NULL; it stays nullable.\n")
+
+	up, down = change.contractMigration(DBPostgres)
+	assert.Contains(t, up, "ALTER TABLE products DROP COLUMN price;\n")
+	assert.Contains(t, down, "ALTER TABLE products ADD COLUMN price double precision NOT NULL DEFAULT 0;\n"+
+		"UPDATE products SET price = CAST(price_cents AS double precision);\n")
+
+	renamed := Field{Name: "Amount", Type: "float64", Tag: "`json:\"amount\" gorm:\"not null\"`"}
+	up, down = renameColumnChange("Product", DBSQLServer, from, renamed).renameMigration(DBSQLServer)
+	assert.Equal(t, "-- Rename price to amount in products\nEXEC sp_rename 'products.price', 'amount', 'COLUMN';\n", up)
+	assert.Contains(t, down, "EXEC sp_rename 'products.amount', 'price', 'COLUMN';\n")
+	up, _ = change.renameMigration(DBPostgres)
+	assert.NotContains(t, up, "-- Apply")
+	assert.Contains(t, up, "ADD COLUMN price_cents bigint;\nUPDATE products")
+	assert.Contains(t, up, "ALTER TABLE products DROP COLUMN price;\n")
+}
+
+func TestExpandContract_AddColumns(t *testing.T) {
+	chdirTemp(t)
+	fields := []Field{
+		{Name: "Tier", Type: "string", Tag: "`json:\"tier\" gorm:\"not null\"`"},
+		{Name: "Notes", Type: "*string", Tag: "`json:\"notes\"`"},
+	}
+	change := addColumnsChange("User", DBPostgres, fields)
+	assert.Equal(t, "add_tier_notes_to_users", change.Name)
+	assert.False(t, change.renames())
+	assert.True(t, change.enforces())
+	assert.Equal(t, "tier IS NULL", change.backfillCondition())
+
+	nullable := addColumnsChange("User", DBPostgres, fields[1:])
+	assert.Empty(t, nullable.backfilled())
+	assert.False(t, nullable.enforces())
+}
+
+func TestFieldRename_ExpandContract(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	const fields = "name:string,price:float64"
+	sm := NewSafetyManager(false, true, false)
+	require.NoError(t, generateEntity("Product", fields, true, false, false, false, false, false, false, true, "lowercase", sm))
+	generateUseCaseWithFields("ProductService", "Product", "create,read,update,delete,list", false, false, fields, "", sm)
+	generateRepository("Product", DBPostgres, false, false, false, false, fields, sm)
+	entityFile := filepath.Join("internal", "domain", "product.go")
+	f, err := os.OpenFile(entityFile, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString("\n// Label returns the display label.\nfunc (p *Product) Label() string {\n\treturn p.Name\n}\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	require.NoError(t, fieldRenameCmd.Flags().Set("expand-contract", "true"))
+	t.Cleanup(func() { _ = fieldRenameCmd.Flags().Set("expand-contract", "false") })
+	require.NoError(t, runFieldRename(fieldRenameCmd, []string{"Product", "name", "title"}))
+
+	entity := readParsedGo(t, entityFile)
+	assert.Regexp(t, "Title +string", entity)
+	assert.NotContains(t, entity, "p.Name")
+	assert.Contains(t, entity, "return p.Title\n", "business rules follow the rename")
+	assert.Contains(t, entity, "if p.Title == \"\" {")
+	assert.Contains(t, readParsedGo(t, filepath.Join("internal", "usecase", "dto.go")), "Title string")
+	assert.Contains(t, readParsedGo(t, filepath.Join("internal", "usecase", "product_service.go")), "Title: input.Title,")
+
+	assert.FileExists(t, filepath.Join("migrations", "001_expand_rename_name_to_title_in_products.up.sql"))
+	backfill := readParsedGo(t, filepath.Join(dataMigrationDir, "001_backfill_rename_name_to_title_in_products.go"))
+	assert.Contains(t, backfill, "SchemaVersion: 1,")
+	assert.Contains(t, backfill, "WHERE title IS NULL AND name IS NOT NULL")
+	stage := filepath.Join("migrations", expandContractDir, "001_rename_name_to_title_in_products")
+	assert.FileExists(t, filepath.Join(stage, "2_enforce_rename_name_to_title_in_products.up.sql"))
+	assert.FileExists(t, filepath.Join(stage, "3_contract_rename_name_to_title_in_products.down.sql"))
+	assert.FileExists(t, filepath.Join(stage, "README.md"))
+
+	err = runFieldRename(fieldRenameCmd, []string{"Product", "price", "title"})
+	assert.ErrorContains(t, err, "already has a field Title")
+
+	require.NoError(t, migrateAdvanceCmd.RunE(migrateAdvanceCmd, nil))
+	assert.FileExists(t, filepath.Join("migrations", "002_enforce_rename_name_to_title_in_products.up.sql"))
+	assert.NoFileExists(t, filepath.Join(stage, "2_enforce_rename_name_to_title_in_products.up.sql"))
+	require.NoError(t, migrateAdvanceCmd.RunE(migrateAdvanceCmd, []string{"rename_name_to_title_in_products"}))
+	contract, err := os.ReadFile(filepath.Join("migrations", "003_contract_rename_name_to_title_in_products.up.sql"))
+	require.NoError(t, err)
+	assert.Contains(t, string(contract), "ALTER TABLE products DROP COLUMN name;\n")
+	assert.NoDirExists(t, stage)
+
+	assert.ErrorContains(t, migrateAdvanceCmd.RunE(migrateAdvanceCmd, nil), "no expand/contract phases are staged")
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// experimentalEnv lists enabled experiments, comma separated, or "all".
+const experimentalEnv = "GOCA_EXPERIMENTAL"
+
+// Experiment stages. A planned experiment is reserved for a generator that is
+// not part of this release yet; enabling it has no effect.
+const (
+	StagePlanned = "planned"
+	StageAlpha   = "alpha"
+	StageBeta    = "beta"
+)
+
+// experiment describes a generator that ships behind an opt-in flag until its
+// output is stable.
+type experiment struct {
+	Name        string
+	Stage       string
+	Description string
+}
+
+// experiments is the registry of gated generators. New subsystems start here
+// and are removed once they graduate to the default path.
+var experiments = []experiment{
+	{Name: ExperimentAdminUI, Stage: StageAlpha, Description: "Admin CRUD web UI scaffold (goca admin)"},
+	{Name: "event-sourcing", Stage: StagePlanned, Description: "Event-sourced aggregates, event store and projections"},
+	{Name: "layouts", Stage: StagePlanned, Description: "Alternative project layouts beyond the default Clean Architecture tree"},
+	{Name: ExperimentServeUI, Stage: StageAlpha, Description: "Web project designer (goca serve-ui)"},
+}
+
+// warnedExperiments records which experiments already printed their
+// instability warning in this process.
+var warnedExperiments sync.Map
+
+// lookupExperiment returns the registered experiment with the given name.
+func lookupExperiment(name string) (experiment, bool) {
+	for _, e := range experiments {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return experiment{}, false
+}
+
+// parseExperimentList splits a comma-separated list of experiment names. The
+// value "all" expands to every registered experiment.
+func parseExperimentList(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if name == "all" {
+			for _, e := range experiments {
+				names = append(names, e.Name)
+			}
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// loadConfigExperiments reads the experimental list from the .goca.yaml found
+// in projectDir. Only that key is decoded, so a config that fails full
+// validation still enables its experiments.
+func loadConfigExperiments(projectDir string) []string {
+	configPath := NewConfigManager().findConfigFile(projectDir)
+	if configPath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil
+	}
+	data = bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF})
+
+	var partial struct {
+		Experimental []string `yaml:"experimental"`
+	}
+	if err := yaml.Unmarshal(data, &partial); err != nil {
+		return nil
+	}
+
+	return parseExperimentList(strings.Join(partial.Experimental, ","))
+}
+
+// enabledExperiments maps each enabled experiment name to where it was enabled
+// ("env" or "config"). The environment takes precedence when both list it.
+func enabledExperiments(projectDir string) map[string]string {
+	enabled := make(map[string]string)
+	for _, name := range loadConfigExperiments(projectDir) {
+		enabled[name] = "config"
+	}
+	for _, name := range parseExperimentList(os.Getenv(experimentalEnv)) {
+		enabled[name] = "env"
+	}
+	return enabled
+}
+
+// experimentEnabled reports whether the named experiment is enabled for the
+// project in the current directory.
+func experimentEnabled(name string) bool {
+	wd, err := os.Getwd()
+	if err != nil {
+		wd = "."
+	}
+	_, ok := enabledExperiments(wd)[name]
+	return ok
+}
+
+// requireExperiment gates an experimental generator. It returns an error
+// explaining how to opt in when the experiment is disabled, and prints a
+// one-time warning when it is enabled.
+func requireExperiment(name string) error {
+	e, ok := lookupExperiment(name)
+	if !ok {
+		return fmt.Errorf("unknown experiment %q", name)
+	}
+	if !experimentEnabled(name) {
+		return fmt.Errorf("%s is experimental; enable it with %s=%s or by adding %q to experimental in .goca.yaml",
+			e.Description, experimentalEnv, name, name)
+	}
+	if _, warned := warnedExperiments.LoadOrStore(name, true); !warned {
+		ui.Warning(fmt.Sprintf("Experimental (%s): %s may change or be removed in a future release", e.Stage, e.Description))
+	}
+	return nil
+}
+
+var experimentsCmd = &cobra.Command{
+	Use:   "experiments",
+	Short: "Manage experimental generators",
+	Long: `Experimental generators ship behind opt-in flags until their output is
+stable. Enable them per shell with GOCA_EXPERIMENTAL (comma separated, or "all")
+or per project with the experimental list in .goca.yaml:
+
+  experimental:
+    - admin-ui`,
+}
+
+var experimentsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List experimental generators and whether they are enabled",
+	Run: func(cmd *cobra.Command, args []string) {
+		wd, err := os.Getwd()
+		if err != nil {
+			wd = "."
+		}
+		enabled := enabledExperiments(wd)
+
+		ui.Header("Experimental Generators")
+		rows := make([][]string, 0, len(experiments))
+		for _, e := range experiments {
+			status := "no"
+			if source, ok := enabled[e.Name]; ok {
+				status = "yes (" + source + ")"
+			}
+			rows = append(rows, []string{e.Name, e.Stage, status, e.Description})
+		}
+		ui.Table([]string{"Name", "Stage", "Enabled", "Description"}, rows)
+
+		var unknown []string
+		for name := range enabled {
+			if _, ok := lookupExperiment(name); !ok {
+				unknown = append(unknown, name)
+			}
+		}
+		sort.Strings(unknown)
+		for _, name := range unknown {
+			ui.Warning(fmt.Sprintf("Unknown experiment %q is enabled (%s) and has no effect", name, enabled[name]))
+		}
+
+		ui.Blank()
+		ui.Dim(fmt.Sprintf("Tip: enable with %s=<name>[,<name>] or the experimental list in .goca.yaml", experimentalEnv))
+		ui.Dim("Planned experiments are not available in this release yet.")
+	},
+}
+
+func init() {
+	experimentsCmd.AddCommand(experimentsListCmd)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExperimentList(t *testing.T) {
+	assert.Empty(t, parseExperimentList(""))
+	assert.Equal(t, []string{"admin-ui", "layouts"}, parseExperimentList(" Admin-UI, ,layouts "))
+
+	all := parseExperimentList("all")
+	require.Len(t, all, len(experiments))
+	for _, e := range experiments {
+		assert.Contains(t, all, e.Name)
+	}
+}
+
+func TestEnabledExperiments(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(experimentalEnv, "")
+	assert.Empty(t, enabledExperiments(dir))
+
+	// database.port 0 fails full validation; experiments must still load.
+	yaml := "experimental:\n  - admin-ui\n  - layouts\ndatabase:\n  port: 0\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".goca.yaml"), []byte(yaml), 0o600))
+	assert.Equal(t, map[string]string{"admin-ui": "config", "layouts": "config"}, enabledExperiments(dir))
+
+	t.Setenv(experimentalEnv, "layouts,event-sourcing")
+	assert.Equal(t, map[string]string{"admin-ui": "config", "layouts": "env", "event-sourcing": "env"}, enabledExperiments(dir))
+}
+
+func TestRequireExperiment(t *testing.T) {
+	var buf bytes.Buffer
+	origUI := ui
+	ui = NewUIRenderer(&buf, true, 1)
+	t.Cleanup(func() { ui = origUI })
+
+	origDir, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(origDir) })
+	require.NoError(t, os.Chdir(t.TempDir()))
+	t.Setenv(experimentalEnv, "")
+	warnedExperiments.Delete("admin-ui")
+
+	err := requireExperiment("admin-ui")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), experimentalEnv+"=admin-ui")
+
+	assert.Error(t, requireExperiment("does-not-exist"))
+
+	t.Setenv(experimentalEnv, "all")
+	require.NoError(t, requireExperiment("admin-ui"))
+	require.NoError(t, requireExperiment("admin-ui"))
+	assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("Experimental (")), "the warning is printed once")
+}
+
+func TestValidateExperimental(t *testing.T) {
+	cm := NewConfigManager()
+	cm.warnings = nil
+	cm.validateExperimental([]string{"admin-ui", "hologram"})
+	require.Len(t, cm.GetWarnings(), 1)
+	assert.Equal(t, "experimental[1]", cm.GetWarnings()[0].Field)
+}
@@ -0,0 +1,1039 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <feature|file>",
+	Short: "Describe how the layers of a feature fit together",
+	Long: `Reads the code of a feature and describes its architecture, for developers
+new to the project:
+
+  Layers        the files of each layer and what they declare for the feature
+  Dependencies  which layer imports which
+  Interfaces    the use case and repository interfaces, and the types and
+                constructors implementing them
+  Entry points  the HTTP routes, gRPC methods, CLI commands and other handlers,
+                with the use case methods each one calls
+  Wiring        the constructors the DI container chains
+  Custom logic  where business rules, queries and transport code belong
+
+The argument is a feature, i.e. an entity of internal/domain, or one of its
+files, whose role is then described first. The explanation is read from the
+code on disk, so it covers hand-written changes too.
+
+--format markdown renders it as a Markdown document with a Mermaid diagram of
+the layers, for onboarding docs; --format mermaid only prints the diagram.`,
+	Example: `  goca explain Product
+  goca explain internal/usecase/product_service.go
+  goca explain Order --format markdown -o docs/order.md`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExplain,
+}
+
+func init() {
+	explainCmd.Flags().String("format", "text", "Output format: text, markdown or mermaid")
+	explainCmd.Flags().StringP("output", "o", "", "Write the markdown or mermaid output to this file")
+}
+
+// explainFile is a file of a feature and the symbols it declares for it.
+type explainFile struct {
+	layer   string
+	path    string
+	symbols []string
+}
+
+// explainInterface is an interface of a feature and the types implementing it.
+type explainInterface struct {
+	name    string
+	layer   string
+	path    string
+	methods []string
+	impls   []explainImpl
+}
+
+// explainImpl is a struct type implementing an explainInterface.
+type explainImpl struct {
+	typeName    string
+	path        string
+	constructor string
+}
+
+// explainCall is a function or method calling the methods of a use case or
+// repository of the feature. The route is set for HTTP handlers.
+type explainCall struct {
+	layer  string
+	caller string
+	route  string
+	calls  []string
+	path   string
+}
+
+// featureExplanation is what goca explain reports about a feature.
+type featureExplanation struct {
+	feature    string
+	focus      string
+	files      []explainFile
+	edges      [][2]string
+	interfaces []explainInterface
+	entries    []explainCall
+	useCases   []explainCall
+	wiring     []string
+	hints      [][2]string
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+	if format != "text" && format != "markdown" && format != "mermaid" {
+		return fmt.Errorf("invalid --format %q: must be text, markdown or mermaid", format)
+	}
+	if output != "" && format == "text" {
+		return fmt.Errorf("--output needs --format markdown or mermaid")
+	}
+
+	sources, err := loadExplainSources()
+	if err != nil {
+		return err
+	}
+	feature, focus, err := resolveExplainTarget(args[0], sources)
+	if err != nil {
+		return err
+	}
+	e := explainFeature(feature, focus, sources)
+
+	var rendered string
+	switch format {
+	case "text":
+		printExplanation(e)
+		return nil
+	case "markdown":
+		rendered = explanationMarkdown(e)
+	default:
+		rendered = explanationMermaid(e)
+	}
+	if output == "" {
+		_, err := fmt.Fprint(cmd.OutOrStdout(), rendered)
+		return err
+	}
+	if dir := filepath.Dir(output); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(output, []byte(rendered), 0o644); err != nil {
+		return err
+	}
+	ui.Success(fmt.Sprintf("Explanation of %s written to %s", feature, output))
+	return nil
+}
+
+// explainSource is a parsed file of the project.
+type explainSource struct {
+	path  string // slash-separated, relative to the project root
+	dir   string
+	layer string
+	file  *ast.File
+}
+
+// loadExplainSources parses the non-test Go files under internal and cmd,
+// leaving out generated code such as protoc output.
+func loadExplainSources() ([]explainSource, error) {
+	fset := token.NewFileSet()
+	var sources []explainSource
+	for _, root := range []string{DirInternal, "cmd"} {
+		if !dirExists(root) {
+			continue
+		}
+		files, err := analyzeGoFiles(root, true)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", root, err)
+		}
+		for _, path := range files {
+			file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", path, err)
+			}
+			if ast.IsGenerated(file) || strings.HasSuffix(path, ".pb.go") {
+				continue
+			}
+			path = filepath.ToSlash(path)
+			sources = append(sources, explainSource{path: path, dir: filepath.ToSlash(filepath.Dir(path)), layer: explainLayer(path), file: file})
+		}
+	}
+	return sources, nil
+}
+
+// explainLayer returns the layer of a file: its directory under internal,
+// e.g. usecase, handler/http for handlers, or cmd/<binary>.
+func explainLayer(path string) string {
+	dirs := strings.Split(filepath.ToSlash(filepath.Dir(path)), "/")
+	switch {
+	case dirs[0] == "cmd" && len(dirs) > 1:
+		return "cmd/" + dirs[1]
+	case dirs[0] == DirInternal && len(dirs) > 2 && dirs[1] == DirHandler:
+		return DirHandler + "/" + dirs[2]
+	case dirs[0] == DirInternal && len(dirs) > 1:
+		return dirs[1]
+	}
+	return dirs[0]
+}
+
+// resolveExplainTarget returns the feature an argument names, and the file
+// to describe first when the argument is one of the feature's files.
+func resolveExplainTarget(arg string, sources []explainSource) (feature, focus string, err error) {
+	features := projectFeatures()
+	if !strings.HasSuffix(arg, ".go") {
+		selected, err := selectDiffFeatures([]string{arg})
+		if err != nil {
+			return "", "", err
+		}
+		return selected[0], "", nil
+	}
+
+	focus = filepath.ToSlash(filepath.Clean(arg))
+	for _, s := range sources {
+		if s.path != focus {
+			continue
+		}
+		for _, f := range features {
+			if len(featureSymbols(s.file, newEntityMatcher(f, features))) > 0 {
+				return f, focus, nil
+			}
+		}
+		return "", "", fmt.Errorf("%s declares nothing for a feature of %s", arg, filepath.Join(DirInternal, DirDomain))
+	}
+	return "", "", fmt.Errorf("%s is not a Go file under %s or cmd", arg, DirInternal)
+}
+
+// entityMatcher finds an entity in identifiers at camel case boundaries:
+// Product in NewPostgresProductRepository, ListProducts and productService,
+// Category in ListCategories, but not Order in OrderItemRepository when
+// OrderItem is a feature too.
+type entityMatcher struct {
+	entity string
+	longer []string
+}
+
+func newEntityMatcher(entity string, features []string) entityMatcher {
+	m := entityMatcher{entity: entity}
+	for _, f := range features {
+		if f != entity && strings.HasPrefix(f, entity) {
+			m.longer = append(m.longer, f)
+		}
+	}
+	return m
+}
+
+func (m entityMatcher) matches(name string) bool {
+	for _, word := range []string{toPlural(m.entity), m.entity} {
+		lower := strings.ToLower(word[:1]) + word[1:]
+		if strings.HasPrefix(name, lower) && m.boundary(name, 0, word) {
+			return true
+		}
+		for i := 0; i < len(name); i++ {
+			if strings.HasPrefix(name[i:], word) && m.boundary(name, i, word) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// boundary reports whether word, the entity or its plural found at i of
+// name, ends a word there and is not the start of a longer feature.
+func (m entityMatcher) boundary(name string, i int, word string) bool {
+	for _, f := range m.longer {
+		if strings.HasPrefix(strings.ToLower(name[i:]), strings.ToLower(f)) {
+			return false
+		}
+	}
+	return wordStart(name[i+len(word):])
+}
+
+// wordStart reports whether s is empty or starts a new word of an
+// identifier.
+func wordStart(s string) bool {
+	if s == "" {
+		return true
+	}
+	r := rune(s[0])
+	return unicode.IsUpper(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// featureSymbols returns the top-level types and functions of file that
+// belong to the feature m matches, and the methods whose name, but not
+// receiver, mentions it, e.g. Container.ProductHandler.
+func featureSymbols(file *ast.File, m entityMatcher) []string {
+	var symbols []string
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok && m.matches(ts.Name.Name) {
+					symbols = append(symbols, ts.Name.Name)
+				}
+			}
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				if m.matches(d.Name.Name) {
+					symbols = append(symbols, d.Name.Name+"()")
+				}
+				continue
+			}
+			recv := explainTypeName(d.Recv.List[0].Type)
+			if !m.matches(recv) && m.matches(d.Name.Name) {
+				symbols = append(symbols, recv+"."+d.Name.Name+"()")
+			}
+		}
+	}
+	return symbols
+}
+
+// explainTypeName returns the name of a type expression without its package
+// and pointer, or "".
+func explainTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return explainTypeName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return ""
+}
+
+// explainIndex holds the declarations of the whole project by package
+// directory and type name.
+type explainIndex struct {
+	interfaces map[string]*ast.InterfaceType
+	structs    map[string]*ast.StructType
+	structFile map[string]string
+	methods    map[string][]string
+}
+
+func indexExplainSources(sources []explainSource) explainIndex {
+	idx := explainIndex{
+		interfaces: make(map[string]*ast.InterfaceType),
+		structs:    make(map[string]*ast.StructType),
+		structFile: make(map[string]string),
+		methods:    make(map[string][]string),
+	}
+	for _, s := range sources {
+		for _, decl := range s.file.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					key := s.dir + "." + ts.Name.Name
+					switch t := ts.Type.(type) {
+					case *ast.InterfaceType:
+						idx.interfaces[key] = t
+					case *ast.StructType:
+						idx.structs[key] = t
+						idx.structFile[key] = s.path
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Recv != nil {
+					key := s.dir + "." + explainTypeName(d.Recv.List[0].Type)
+					idx.methods[key] = append(idx.methods[key], d.Name.Name)
+				}
+			}
+		}
+	}
+	return idx
+}
+
+// interfaceMethods returns the methods of an interface of dir, with those of
+// the interfaces it embeds.
+func (idx explainIndex) interfaceMethods(dir, name string) []string {
+	iface := idx.interfaces[dir+"."+name]
+	if iface == nil {
+		return nil
+	}
+	var methods []string
+	for _, field := range iface.Methods.List {
+		if len(field.Names) == 0 {
+			methods = append(methods, idx.interfaceMethods(dir, explainTypeName(field.Type))...)
+			continue
+		}
+		for _, n := range field.Names {
+			methods = append(methods, n.Name)
+		}
+	}
+	return methods
+}
+
+// explainFeature analyzes the sources of the project for feature.
+func explainFeature(feature, focus string, sources []explainSource) featureExplanation {
+	m := newEntityMatcher(feature, projectFeatures())
+	idx := indexExplainSources(sources)
+	module := getModuleName()
+	e := featureExplanation{feature: feature, focus: focus}
+
+	edges := make(map[[2]string]bool)
+	for _, s := range sources {
+		symbols := featureSymbols(s.file, m)
+		if len(symbols) == 0 {
+			continue
+		}
+		e.files = append(e.files, explainFile{layer: s.layer, path: s.path, symbols: symbols})
+		for _, imp := range s.file.Imports {
+			path, _ := strconv.Unquote(imp.Path.Value)
+			rel, ok := strings.CutPrefix(path, module+"/")
+			if !ok {
+				continue
+			}
+			if to := explainLayer(rel + "/x.go"); to != s.layer {
+				edges[[2]string{s.layer, to}] = true
+			}
+		}
+	}
+	for edge := range edges {
+		e.edges = append(e.edges, edge)
+	}
+	sort.Slice(e.edges, func(i, j int) bool {
+		if e.edges[i][0] != e.edges[j][0] {
+			return e.edges[i][0] < e.edges[j][0]
+		}
+		return e.edges[i][1] < e.edges[j][1]
+	})
+
+	e.interfaces = explainInterfaces(sources, idx, m)
+	routes := explainHTTPRoutes(sources)
+	for _, s := range sources {
+		switch {
+		case strings.HasPrefix(s.layer, DirHandler+"/"), strings.HasPrefix(s.layer, "cmd/"):
+			for _, c := range explainCalls(s, idx, m, "UseCase") {
+				c.route = routes[c.caller]
+				e.entries = append(e.entries, c)
+			}
+		case s.layer == DirUseCase:
+			e.useCases = append(e.useCases, explainCalls(s, idx, m, "Repository")...)
+		case s.layer == "di":
+			e.wiring = appendWiring(e.wiring, s.file, m)
+		}
+	}
+	e.hints = explainHints(e, idx)
+	return e
+}
+
+// explainInterfaces returns the interfaces of the feature with the struct
+// types of the feature whose methods implement them.
+func explainInterfaces(sources []explainSource, idx explainIndex, m entityMatcher) []explainInterface {
+	var ifaces []explainInterface
+	for _, s := range sources {
+		for _, decl := range s.file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok || !m.matches(ts.Name.Name) {
+					continue
+				}
+				if _, ok := ts.Type.(*ast.InterfaceType); !ok {
+					continue
+				}
+				iface := explainInterface{name: ts.Name.Name, layer: s.layer, path: s.path, methods: idx.interfaceMethods(s.dir, ts.Name.Name)}
+				if len(iface.methods) == 0 {
+					continue
+				}
+				iface.impls = explainImpls(sources, idx, m, iface)
+				ifaces = append(ifaces, iface)
+			}
+		}
+	}
+	return ifaces
+}
+
+// explainImpls returns the struct types of the feature having every method
+// of iface, with their constructors. Handlers are left out: they mirror the
+// use case methods without implementing the interface.
+func explainImpls(sources []explainSource, idx explainIndex, m entityMatcher, iface explainInterface) []explainImpl {
+	var impls []explainImpl
+	for _, key := range slices.Sorted(maps.Keys(idx.structs)) {
+		dir, typeName, _ := strings.Cut(key, ".")
+		if layer := explainLayer(dir + "/x.go"); !m.matches(typeName) || strings.HasPrefix(layer, DirHandler+"/") || strings.HasPrefix(layer, "cmd/") {
+			continue
+		}
+		methods := idx.methods[key]
+		if !containsAll(methods, iface.methods) {
+			continue
+		}
+		impls = append(impls, explainImpl{typeName: typeName, path: idx.structFile[key], constructor: explainConstructor(sources, dir, typeName, iface.name)})
+	}
+	return impls
+}
+
+func containsAll(set, items []string) bool {
+	for _, item := range items {
+		if !slices.Contains(set, item) {
+			return false
+		}
+	}
+	return true
+}
+
+// explainConstructor returns the New function of dir returning typeName, or
+// returning the interface and building a typeName.
+func explainConstructor(sources []explainSource, dir, typeName, iface string) string {
+	for _, s := range sources {
+		if s.dir != dir {
+			continue
+		}
+		for _, decl := range s.file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || !strings.HasPrefix(fn.Name.Name, "New") || fn.Type.Results == nil {
+				continue
+			}
+			for _, result := range fn.Type.Results.List {
+				switch explainTypeName(result.Type) {
+				case typeName:
+					return fn.Name.Name
+				case iface:
+					if buildsType(fn.Body, typeName) {
+						return fn.Name.Name
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// buildsType reports whether body contains a composite literal of typeName.
+func buildsType(body *ast.BlockStmt, typeName string) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.CompositeLit); ok && explainTypeName(lit.Type) == typeName {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// explainCalls returns the functions and methods of s calling the methods of
+// a dependency of the feature whose type ends with suffix (UseCase or
+// Repository), held in a parameter or a field of the receiver.
+func explainCalls(s explainSource, idx explainIndex, m entityMatcher, suffix string) []explainCall {
+	isDependency := func(expr ast.Expr) bool {
+		name := explainTypeName(expr)
+		return strings.HasSuffix(name, suffix) && m.matches(name)
+	}
+
+	var calls []explainCall
+	for _, decl := range s.file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		params := make(map[string]bool)
+		for _, field := range fn.Type.Params.List {
+			if isDependency(field.Type) {
+				for _, n := range field.Names {
+					params[n.Name] = true
+				}
+			}
+		}
+		caller, recv := fn.Name.Name, ""
+		fields := make(map[string]bool)
+		if fn.Recv != nil {
+			typeName := explainTypeName(fn.Recv.List[0].Type)
+			caller = typeName + "." + fn.Name.Name
+			if names := fn.Recv.List[0].Names; len(names) > 0 {
+				recv = names[0].Name
+			}
+			if st := idx.structs[s.dir+"."+typeName]; st != nil {
+				for _, field := range st.Fields.List {
+					if isDependency(field.Type) {
+						for _, n := range field.Names {
+							fields[n.Name] = true
+						}
+					}
+				}
+			}
+		}
+		if len(params) == 0 && len(fields) == 0 {
+			continue
+		}
+
+		var called []string
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			switch x := sel.X.(type) {
+			case *ast.Ident:
+				if !params[x.Name] {
+					return true
+				}
+			case *ast.SelectorExpr:
+				if id, ok := x.X.(*ast.Ident); !ok || id.Name != recv || !fields[x.Sel.Name] {
+					return true
+				}
+			default:
+				return true
+			}
+			if !slices.Contains(called, sel.Sel.Name) {
+				called = append(called, sel.Sel.Name)
+			}
+			return true
+		})
+		if len(called) > 0 {
+			calls = append(calls, explainCall{layer: s.layer, caller: caller, calls: called, path: s.path})
+		}
+	}
+	return calls
+}
+
+var (
+	explainRouterDecl = regexp.MustCompile(`(\w+) := router\.PathPrefix\("([^"]*)"\)\.Subrouter\(\)`)
+	explainRouteSetup = regexp.MustCompile(`\.(Setup\w+Routes)\((\w+),`)
+)
+
+// explainHTTPRoutes returns the routes of the HTTP handler methods, keyed by
+// Type.Method, e.g. "POST /api/v1/products" for ProductHandler.CreateProduct.
+// The prefixes of the route setup functions come from the routers main.go
+// passes them.
+func explainHTTPRoutes(sources []explainSource) map[string]string {
+	prefixes := make(map[string]string)
+	if mainPath, found := findMainGoPath(); found {
+		content := analyzeReadFile(mainPath)
+		routers := make(map[string]string)
+		for _, match := range explainRouterDecl.FindAllStringSubmatch(content, -1) {
+			routers[match[1]] = match[2]
+		}
+		for _, match := range explainRouteSetup.FindAllStringSubmatch(content, -1) {
+			prefixes[match[1]] = routers[match[2]]
+		}
+	}
+
+	routes := make(map[string]string)
+	for _, s := range sources {
+		if s.layer != DirHandler+"/"+DirHTTP {
+			continue
+		}
+		for _, decl := range s.file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil || fn.Recv != nil {
+				continue
+			}
+			vars := make(map[string]string)
+			if params := fn.Type.Params.List; len(params) > 0 && len(params[0].Names) > 0 {
+				vars[params[0].Names[0].Name] = prefixes[fn.Name.Name]
+			}
+			handlers := make(map[string]string)
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				switch node := n.(type) {
+				case *ast.AssignStmt:
+					recordRouteVar(node, vars, handlers)
+				case *ast.CallExpr:
+					if key, route := routeOf(node, vars, handlers); key != "" {
+						if routes[key] != "" {
+							route = routes[key] + ", " + route
+						}
+						routes[key] = route
+					}
+				}
+				return true
+			})
+		}
+	}
+	return routes
+}
+
+// recordRouteVar records the prefix of a subrouter assigned with
+// x := base.PathPrefix("/p").Subrouter(), and the handler type of
+// h := NewTHandler(...).
+func recordRouteVar(assign *ast.AssignStmt, vars, handlers map[string]string) {
+	if len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+		return
+	}
+	lhs, ok := assign.Lhs[0].(*ast.Ident)
+	call, isCall := assign.Rhs[0].(*ast.CallExpr)
+	if !ok || !isCall {
+		return
+	}
+	if fn, ok := call.Fun.(*ast.Ident); ok && strings.HasPrefix(fn.Name, "New") {
+		handlers[lhs.Name] = strings.TrimPrefix(fn.Name, "New")
+		return
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Subrouter" {
+		return
+	}
+	inner, ok := sel.X.(*ast.CallExpr)
+	if !ok || len(inner.Args) != 1 {
+		return
+	}
+	innerSel, ok := inner.Fun.(*ast.SelectorExpr)
+	base, isIdent := innerSel.X.(*ast.Ident)
+	if !ok || !isIdent || innerSel.Sel.Name != "PathPrefix" {
+		return
+	}
+	if lit, ok := inner.Args[0].(*ast.BasicLit); ok {
+		prefix, _ := strconv.Unquote(lit.Value)
+		vars[lhs.Name] = vars[base.Name] + prefix
+	}
+}
+
+// routeOf returns the handler method and the route of a
+// r.HandleFunc("/p", h.Method).Methods("GET") call.
+func routeOf(call *ast.CallExpr, vars, handlers map[string]string) (key, route string) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Methods" {
+		return "", ""
+	}
+	handle, ok := sel.X.(*ast.CallExpr)
+	if !ok || len(handle.Args) != 2 {
+		return "", ""
+	}
+	handleSel, ok := handle.Fun.(*ast.SelectorExpr)
+	if !ok || handleSel.Sel.Name != "HandleFunc" {
+		return "", ""
+	}
+	router, ok := handleSel.X.(*ast.Ident)
+	lit, isLit := handle.Args[0].(*ast.BasicLit)
+	target, isSel := handle.Args[1].(*ast.SelectorExpr)
+	if !ok || !isLit || !isSel {
+		return "", ""
+	}
+	h, ok := target.X.(*ast.Ident)
+	if !ok || handlers[h.Name] == "" {
+		return "", ""
+	}
+	path, _ := strconv.Unquote(lit.Value)
+	var verbs []string
+	for _, arg := range call.Args {
+		if v, ok := arg.(*ast.BasicLit); ok {
+			verb, _ := strconv.Unquote(v.Value)
+			verbs = append(verbs, verb)
+		}
+	}
+	return handlers[h.Name] + "." + target.Sel.Name, strings.Join(verbs, "|") + " " + vars[router.Name] + path
+}
+
+// appendWiring appends the constructors of the feature a DI file refers to,
+// called (manual container, Wire) or provided (fx), in order.
+func appendWiring(wiring []string, file *ast.File, m entityMatcher) []string {
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		pkg, ok := sel.X.(*ast.Ident)
+		name := sel.Sel.Name
+		if ok && strings.HasPrefix(name, "New") && m.matches(name) && !slices.Contains(wiring, pkg.Name+"."+name) {
+			wiring = append(wiring, pkg.Name+"."+name)
+		}
+		return true
+	})
+	return wiring
+}
+
+// explainHints says where the custom code of the feature belongs, naming the
+// files found.
+func explainHints(e featureExplanation, idx explainIndex) [][2]string {
+	var hints [][2]string
+	for _, f := range e.files {
+		if f.layer == DirDomain && slices.Contains(f.symbols, e.feature) {
+			what := fmt.Sprintf("Rules about a single %s (invariants, derived values): new methods on %s", e.feature, e.feature)
+			if slices.Contains(idx.methods[filepath.ToSlash(filepath.Dir(f.path))+"."+e.feature], "Validate") {
+				what += " and checks in Validate"
+			}
+			hints = append(hints, [2]string{f.path, what})
+		}
+	}
+	for _, iface := range e.interfaces {
+		var paths []string
+		for _, impl := range iface.impls {
+			if !slices.Contains(paths, impl.path) {
+				paths = append(paths, impl.path)
+			}
+		}
+		if len(paths) == 0 {
+			continue
+		}
+		switch iface.layer {
+		case DirUseCase:
+			hints = append(hints, [2]string{strings.Join(paths, ", "),
+				fmt.Sprintf("Business workflows and rules spanning entities: new operations declared on %s in %s, implemented here", iface.name, iface.path)})
+		case DirRepository:
+			hints = append(hints, [2]string{strings.Join(paths, ", "),
+				fmt.Sprintf("Queries and persistence: new methods declared on %s in %s, implemented here", iface.name, iface.path)})
+		}
+	}
+	var handlerPaths []string
+	for _, c := range e.entries {
+		if !slices.Contains(handlerPaths, c.path) {
+			handlerPaths = append(handlerPaths, c.path)
+		}
+	}
+	if len(handlerPaths) > 0 {
+		hints = append(hints, [2]string{strings.Join(handlerPaths, ", "),
+			"Transport only: decoding requests, status codes and encoding responses; no business rules"})
+	}
+	hints = append(hints, [2]string{"goca diff " + e.feature,
+		"goca feature --force overwrites the feature's files: run it first to see which customizations regenerating would lose"})
+	return hints
+}
+
+// printExplanation prints the explanation with the UI renderer.
+func printExplanation(e featureExplanation) {
+	ui.Header("Goca Explain — " + e.feature)
+	ui.Blank()
+
+	if e.focus != "" {
+		printExplainFocus(e)
+	}
+
+	ui.Section("Layers")
+	rows := make([][]string, 0, len(e.files))
+	for _, f := range e.files {
+		rows = append(rows, []string{f.layer, f.path, summarizeSymbols(f.symbols)})
+	}
+	ui.Table([]string{"Layer", "File", "Declares"}, rows)
+	ui.Blank()
+
+	ui.Section("Dependencies")
+	for _, edge := range e.edges {
+		ui.Println(fmt.Sprintf("  %s → %s", edge[0], edge[1]))
+	}
+	ui.Blank()
+
+	ui.Section("Interfaces")
+	for _, iface := range e.interfaces {
+		ui.Println(fmt.Sprintf("  %s (%s)", iface.name, iface.path))
+		ui.Dim("    methods: " + strings.Join(iface.methods, ", "))
+		for _, impl := range iface.impls {
+			ui.Dim("    implemented by " + describeImpl(impl))
+		}
+		if len(iface.impls) == 0 {
+			ui.Dim("    no implementation found")
+		}
+	}
+	ui.Blank()
+
+	if len(e.entries) > 0 {
+		ui.Section("Entry points")
+		rows = rows[:0]
+		for _, c := range e.entries {
+			rows = append(rows, []string{c.layer, c.caller, c.route, strings.Join(c.calls, ", ")})
+		}
+		ui.Table([]string{"Layer", "Handler", "Route", "Use case calls"}, rows)
+		ui.Blank()
+	}
+
+	if len(e.useCases) > 0 {
+		ui.Section("Use case → repository")
+		rows = rows[:0]
+		for _, c := range e.useCases {
+			rows = append(rows, []string{c.caller, strings.Join(c.calls, ", ")})
+		}
+		ui.Table([]string{"Use case method", "Repository calls"}, rows)
+		ui.Blank()
+	}
+
+	if len(e.wiring) > 0 {
+		ui.Section("Wiring")
+		ui.Println("  " + strings.Join(e.wiring, " → "))
+		ui.Blank()
+	}
+
+	ui.Section("Where to put custom logic")
+	for _, hint := range e.hints {
+		ui.Println("  " + hint[1])
+		ui.Dim("    " + hint[0])
+	}
+}
+
+// printExplainFocus describes the file goca explain was given.
+func printExplainFocus(e featureExplanation) {
+	ui.Section("This file")
+	for _, f := range e.files {
+		if f.path == e.focus {
+			ui.KeyValue("Layer", f.layer)
+			ui.KeyValue("Declares", strings.Join(f.symbols, ", "))
+		}
+	}
+	for _, iface := range e.interfaces {
+		for _, impl := range iface.impls {
+			if impl.path == e.focus {
+				ui.KeyValue("Implements", fmt.Sprintf("%s with %s", iface.name, impl.typeName))
+			}
+		}
+	}
+	for _, c := range append(slices.Clone(e.entries), e.useCases...) {
+		if c.path == e.focus {
+			ui.KeyValue(c.caller, "calls "+strings.Join(c.calls, ", "))
+		}
+	}
+	ui.Blank()
+}
+
+// summarizeSymbols lists the first symbols of a file, keeping the table
+// readable for files such as dto.go.
+func summarizeSymbols(symbols []string) string {
+	const shown = 4
+	if len(symbols) <= shown {
+		return strings.Join(symbols, ", ")
+	}
+	return fmt.Sprintf("%s, +%d more", strings.Join(symbols[:shown], ", "), len(symbols)-shown)
+}
+
+func describeImpl(impl explainImpl) string {
+	s := fmt.Sprintf("%s in %s", impl.typeName, impl.path)
+	if impl.constructor != "" {
+		s += fmt.Sprintf(" (%s)", impl.constructor)
+	}
+	return s
+}
+
+// explanationMarkdown renders the explanation as a Markdown document.
+func explanationMarkdown(e featureExplanation) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", e.feature)
+	if e.focus != "" {
+		fmt.Fprintf(&b, "Explained from `%s`.\n\n", e.focus)
+	}
+
+	b.WriteString("## Layers\n\n```mermaid\n")
+	b.WriteString(explanationMermaid(e))
+	b.WriteString("```\n\n| Layer | File | Declares |\n| --- | --- | --- |\n")
+	for _, f := range e.files {
+		fmt.Fprintf(&b, "| %s | `%s` | %s |\n", f.layer, f.path, markdownCode(f.symbols))
+	}
+
+	b.WriteString("\n## Interfaces\n\n")
+	for _, iface := range e.interfaces {
+		fmt.Fprintf(&b, "### %s\n\nDeclared in `%s` with %s.\n\n", iface.name, iface.path, markdownCode(iface.methods))
+		for _, impl := range iface.impls {
+			fmt.Fprintf(&b, "- Implemented by `%s` in `%s`", impl.typeName, impl.path)
+			if impl.constructor != "" {
+				fmt.Fprintf(&b, ", built by `%s`", impl.constructor)
+			}
+			b.WriteString("\n")
+		}
+		if len(iface.impls) > 0 {
+			b.WriteString("\n")
+		}
+	}
+
+	if len(e.entries) > 0 {
+		b.WriteString("## Entry points\n\n| Layer | Handler | Route | Use case calls |\n| --- | --- | --- | --- |\n")
+		for _, c := range e.entries {
+			route := ""
+			if c.route != "" {
+				route = "`" + c.route + "`"
+			}
+			fmt.Fprintf(&b, "| %s | `%s` | %s | %s |\n", c.layer, c.caller, route, markdownCode(c.calls))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(e.useCases) > 0 {
+		b.WriteString("## Use case → repository\n\n| Use case method | Repository calls |\n| --- | --- |\n")
+		for _, c := range e.useCases {
+			fmt.Fprintf(&b, "| `%s` | %s |\n", c.caller, markdownCode(c.calls))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(e.wiring) > 0 {
+		fmt.Fprintf(&b, "## Wiring\n\nThe DI container builds the feature with %s.\n\n", markdownCode(e.wiring))
+	}
+
+	b.WriteString("## Where to put custom logic\n\n")
+	for _, hint := range e.hints {
+		fmt.Fprintf(&b, "- %s: `%s`\n", hint[1], strings.ReplaceAll(hint[0], ", ", "`, `"))
+	}
+	return b.String()
+}
+
+func markdownCode(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = "`" + item + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// explanationMermaid renders the layers of the feature and their imports as
+// a Mermaid flowchart. Each node lists the interfaces, implementations and
+// handlers of its layer.
+func explanationMermaid(e featureExplanation) string {
+	types := make(map[string][]string)
+	add := func(layer, name string) {
+		if !slices.Contains(types[layer], name) {
+			types[layer] = append(types[layer], name)
+		}
+	}
+	var layers []string
+	for _, f := range e.files {
+		if !slices.Contains(layers, f.layer) {
+			layers = append(layers, f.layer)
+		}
+		if f.layer == DirDomain && slices.Contains(f.symbols, e.feature) {
+			add(f.layer, e.feature)
+		}
+	}
+	for _, iface := range e.interfaces {
+		add(iface.layer, iface.name)
+		for _, impl := range iface.impls {
+			add(explainLayer(impl.path), impl.typeName)
+		}
+	}
+	for _, c := range e.entries {
+		if typeName, _, ok := strings.Cut(c.caller, "."); ok {
+			add(c.layer, typeName)
+		}
+	}
+
+	id := func(layer string) string {
+		return strings.NewReplacer("/", "_", "-", "_").Replace(layer)
+	}
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, layer := range layers {
+		label := layer
+		if len(types[layer]) > 0 {
+			label += "<br/>" + strings.Join(types[layer], ", ")
+		}
+		fmt.Fprintf(&b, "    %s[\"%s\"]\n", id(layer), label)
+	}
+	for _, edge := range e.edges {
+		if slices.Contains(layers, edge[1]) {
+			fmt.Fprintf(&b, "    %s --> %s\n", id(edge[0]), id(edge[1]))
+		}
+	}
+	return b.String()
+}
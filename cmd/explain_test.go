@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntityMatcher(t *testing.T) {
+	m := newEntityMatcher("Order", []string{"Order", "OrderItem", "Product"})
+	for _, name := range []string{"Order", "NewPostgresOrderRepository", "ListOrders", "orderService", "OrderUseCase", "Order2"} {
+		assert.True(t, m.matches(name), name)
+	}
+	for _, name := range []string{"OrderItem", "NewOrderItemHandler", "Ordering", "Reorder", "borderWidth"} {
+		assert.False(t, m.matches(name), name)
+	}
+}
+
+func TestExplainFeature(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	writeTestFile(t, ".", "go.mod", "module example.com/shop\n\ngo 1.22\n")
+	sm := NewSafetyManager(false, true, false)
+	for _, entity := range []string{"Order", "OrderItem"} {
+		require.NoError(t, generateEntity(entity, "Total:float64,Status:string", true, false, false, false, false, false, false, true, "lowercase", sm))
+		generateUseCaseWithFields(entity+"UseCase", entity, "create,read,update,delete,list", false, false, "Total:float64,Status:string", "standard", sm)
+		generateRepository(entity, DBPostgres, false, false, false, false, "Total:float64,Status:string", sm)
+		generateHandler(entity, HandlerHTTP, false, false, false, "lowercase", sm)
+	}
+
+	sources, err := loadExplainSources()
+	require.NoError(t, err)
+	feature, focus, err := resolveExplainTarget("order", sources)
+	require.NoError(t, err)
+	assert.Equal(t, "Order", feature)
+	assert.Empty(t, focus)
+
+	service := filepath.ToSlash(filepath.Join(DirInternal, DirUseCase, "order_service.go"))
+	feature, focus, err = resolveExplainTarget(service, sources)
+	require.NoError(t, err)
+	assert.Equal(t, "Order", feature)
+	assert.Equal(t, service, focus)
+	_, _, err = resolveExplainTarget("internal/usecase/missing.go", sources)
+	assert.Error(t, err)
+
+	e := explainFeature("Order", "", sources)
+	for _, f := range e.files {
+		assert.NotContains(t, f.path, "order_item", "OrderItem is a feature of its own")
+	}
+	assert.Contains(t, e.edges, [2]string{DirUseCase, DirRepository})
+	assert.Contains(t, e.edges, [2]string{DirHandler + "/" + DirHTTP, DirUseCase})
+
+	byName := map[string]explainInterface{}
+	for _, iface := range e.interfaces {
+		byName[iface.name] = iface
+	}
+	require.Contains(t, byName, "OrderUseCase")
+	require.Len(t, byName["OrderUseCase"].impls, 1, "handlers mirror the use case without implementing it")
+	assert.Equal(t, "orderService", byName["OrderUseCase"].impls[0].typeName)
+	assert.Equal(t, "NewOrderService", byName["OrderUseCase"].impls[0].constructor)
+	require.Contains(t, byName, "OrderRepository")
+	require.Len(t, byName["OrderRepository"].impls, 1)
+	assert.Equal(t, "NewPostgresOrderRepository", byName["OrderRepository"].impls[0].constructor)
+
+	entries := map[string]explainCall{}
+	for _, c := range e.entries {
+		entries[c.caller] = c
+	}
+	require.Contains(t, entries, "OrderHandler.CreateOrder")
+	assert.Equal(t, []string{"CreateOrder"}, entries["OrderHandler.CreateOrder"].calls)
+	assert.Contains(t, entries["OrderHandler.CreateOrder"].route, "POST /orders")
+
+	useCases := map[string][]string{}
+	for _, c := range e.useCases {
+		useCases[c.caller] = c.calls
+	}
+	assert.Equal(t, []string{"FindByID", "Update"}, useCases["orderService.UpdateOrder"])
+
+	markdown := explanationMarkdown(e)
+	assert.Contains(t, markdown, "```mermaid\nflowchart LR\n")
+	assert.Contains(t, markdown, "    usecase --> repository\n")
+	assert.Contains(t, markdown, "goca diff Order")
+}
+
+func TestRunExplain_Output(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	writeTestFile(t, ".", "go.mod", "module example.com/shop\n\ngo 1.22\n")
+	sm := NewSafetyManager(false, true, false)
+	require.NoError(t, generateEntity("Order", "Total:float64", true, false, false, false, false, false, false, true, "lowercase", sm))
+
+	require.NoError(t, explainCmd.Flags().Set("format", "mermaid"))
+	require.NoError(t, explainCmd.Flags().Set("output", filepath.Join("docs", "order.mmd")))
+	defer func() {
+		_ = explainCmd.Flags().Set("format", "text")
+		_ = explainCmd.Flags().Set("output", "")
+	}()
+	require.NoError(t, runExplain(explainCmd, []string{"Order"}))
+	content, err := os.ReadFile(filepath.Join("docs", "order.mmd"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `domain["domain<br/>Order"]`)
+
+	require.NoError(t, explainCmd.Flags().Set("format", "text"))
+	assert.ErrorContains(t, runExplain(explainCmd, []string{"Order"}), "--output needs --format markdown or mermaid")
+	require.NoError(t, explainCmd.Flags().Set("output", ""))
+	assert.ErrorContains(t, runExplain(explainCmd, []string{"Invoice"}), `feature "Invoice" not found`)
+}
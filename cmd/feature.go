@@ -10,14 +10,66 @@ import (
 )
 
 var featureCmd = &cobra.Command{
-	Use:   "feature <name>",
+	Use:   "feature <name> | --batch <file> | --from-manifest <file>",
 	Short: "Generate complete feature with Clean Architecture",
 	Long: `Generates all necessary layers for a complete feature, 
-including domain, use cases, repository and handlers in a single operation.`,
-	Args: cobra.ExactArgs(1),
+including domain, use cases, repository and handlers in a single operation.
+
+--preset applies the feature flags of a preset (api, microservice, monolith,
+cli-app, or one defined in .goca.yaml); without it the preset recorded by
+goca init --preset applies. Flags given on the command line take precedence.
+
+--batch generates every feature listed in a YAML file in one run. The layers
+of the features are generated in parallel (--parallel workers); the files they
+share, such as dto.go, interfaces.go and main.go, are updated one feature at a
+time, and the DI container and routes are wired in the order of the file:
+
+  handlers: http
+  validation: true
+  features:
+    - name: Product
+      fields: "name:string,price:float64"
+    - name: Order
+      fields: [customer_id:int, total:float64]
+      cache: true
+
+--go-generate records the feature in goca.features.yaml, a file in the format
+of --batch, and adds a go:generate directive to internal/domain/generate.go.
+'go generate ./...' then runs --from-manifest, which regenerates the features
+of the manifest whose entry changed since they were last generated, as
+recorded in .goca/features.lock.json, and leaves the others untouched.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		batch, _ := cmd.Flags().GetString("batch")
+		manifest, _ := cmd.Flags().GetString("from-manifest")
+		if batch != "" && manifest != "" {
+			return fmt.Errorf("--batch and --from-manifest cannot be used together")
+		}
+		if batch != "" || manifest != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
+		batch, _ := cmd.Flags().GetString("batch")
+		manifest, _ := cmd.Flags().GetString("from-manifest")
+		if batch != "" || manifest != "" {
+			path, fromManifest := batch, false
+			if manifest != "" {
+				path, fromManifest = manifest, true
+			}
+			if err := runFeatureBatch(cmd, path, fromManifest); err != nil {
+				ui.Error(err.Error())
+				os.Exit(1)
+			}
+			return
+		}
 		featureName := args[0]
 
+		preset, err := applyPreset(cmd, presetFeature)
+		if err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
 		fields, _ := cmd.Flags().GetString("fields")
 		database, _ := cmd.Flags().GetString("database")
 		handlers, _ := cmd.Flags().GetString("handlers")
@@ -32,6 +84,53 @@ including domain, use cases, repository and handlers in a single operation.`,
 		generateMocksFlag, _ := cmd.Flags().GetBool("mocks")
 		middlewareTypesStr, _ := cmd.Flags().GetString("middleware-types")
 		cacheFlag, _ := cmd.Flags().GetBool("cache")
+		versioned, _ := cmd.Flags().GetBool("versioned")
+		audit, _ := cmd.Flags().GetBool("audit")
+		multiTenant, _ := cmd.Flags().GetBool("multi-tenant")
+		tenantIsolation, _ := cmd.Flags().GetString("tenant-isolation")
+		tracingFlag, _ := cmd.Flags().GetBool("tracing")
+		resilienceFlag, _ := cmd.Flags().GetBool("resilience")
+		cqrsFlag, _ := cmd.Flags().GetBool("cqrs")
+		projectionFlag, _ := cmd.Flags().GetBool("cqrs-projection")
+		contractsFlag, _ := cmd.Flags().GetBool("contracts")
+		gateway, _ := cmd.Flags().GetBool("gateway")
+		diFlag, _ := cmd.Flags().GetString("di")
+		dtoStyleFlag, _ := cmd.Flags().GetString("dto-style")
+		skipLayers, _ := cmd.Flags().GetString("skip")
+		onlyLayers, _ := cmd.Flags().GetString("only")
+		if selected, _ := cmd.Flags().GetString("layers"); selected != "" {
+			onlyLayers = selected
+		}
+		apiVersionFlag, _ := cmd.Flags().GetString("api-version")
+		table, _ := cmd.Flags().GetString("table")
+		goGenerateFlag, _ := cmd.Flags().GetBool("go-generate")
+		presenterFlag, _ := cmd.Flags().GetBool("presenter")
+		importExport, _ := cmd.Flags().GetBool("import-export")
+		partitionedBy, _ := cmd.Flags().GetString("partitioned-by")
+
+		layers, err := resolveFeatureLayers(skipLayers, onlyLayers)
+		if err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+		if err := checkFeatureLayerDependencies(featureName, layers); err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+		if err := validateTableName(table); err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+		// Regenerating outer layers reuses the fields of the existing entity.
+		if fields == "" && !layers[LayerDomain] {
+			fields = readEntityFieldsString(featureName)
+		}
+		if finders, _ := cmd.Flags().GetString("finders"); finders != "" {
+			if fields, err = applyFinderSpecs(fields, finders); err != nil {
+				ui.Error(err.Error())
+				os.Exit(1)
+			}
+		}
 
 		// Initialize configuration integration
 		configIntegration := NewConfigIntegration()
@@ -52,6 +151,16 @@ including domain, use cases, repository and handlers in a single operation.`,
 		effectiveHandlers := strings.Join(configIntegration.GetHandlerTypes(handlers), ",")
 		effectiveValidation := configIntegration.GetValidationEnabled(&validation)
 		effectiveBusinessRules := configIntegration.GetBusinessRulesEnabled(&businessRules)
+		effectiveDI := configIntegration.GetDIType(diFlag)
+		if !isValidDIStyle(effectiveDI) {
+			ui.Error(fmt.Sprintf("Invalid DI style %q; use one of: %s", effectiveDI, strings.Join(ValidDIStyles, ", ")))
+			os.Exit(1)
+		}
+		effectiveDTOStyle := configIntegration.GetDTOStyle(dtoStyleFlag)
+		if !isValidDTOStyle(effectiveDTOStyle) {
+			ui.Error(fmt.Sprintf("Invalid DTO style %q; use one of: %s", effectiveDTOStyle, strings.Join(ValidDTOStyles, ", ")))
+			os.Exit(1)
+		}
 
 		// Initialize safety manager
 		safetyMgr := NewSafetyManager(dryRun, force, backup)
@@ -63,8 +172,9 @@ including domain, use cases, repository and handlers in a single operation.`,
 			ui.Warning(fmt.Sprintf("Could not scan for conflicts: %v", err))
 		}
 
-		// Check for name conflicts
-		if err := conflictDetector.CheckNameConflict(featureName); err != nil && !force {
+		// Check for name conflicts. Regenerating outer layers of an existing
+		// entity is expected to find it.
+		if err := conflictDetector.CheckNameConflict(featureName); err != nil && !force && layers[LayerDomain] {
 			ui.Error(fmt.Sprintf("%v", err))
 			ui.Dim("Tip: Use --force to generate anyway")
 			os.Exit(1)
@@ -92,6 +202,25 @@ including domain, use cases, repository and handlers in a single operation.`,
 		if configIntegration.HasConfigFile() {
 			ui.Dim("  (from config)")
 		}
+		if preset.Name != "" {
+			ui.KeyValue("Preset", preset.Name)
+		}
+		if !isFullFeature(layers) {
+			ui.KeyValue("Layers", strings.Join(selectedLayerNames(layers), ", "))
+		}
+		if apiVersionFlag != "" {
+			if err := validateAPIVersion(apiVersionFlag); err != nil {
+				ui.Error(err.Error())
+				os.Exit(1)
+			}
+		}
+		apiVersion := featureAPIVersion(featureName, apiVersionFlag)
+		if apiVersion != defaultAPIVersion && strings.Contains(effectiveHandlers, HandlerHTTP) {
+			ui.KeyValue("API version", apiVersion)
+			if apiVersionFlag == "" {
+				ui.Dim("  (from config)")
+			}
+		}
 
 		if effectiveValidation {
 			ui.Feature("Including validations", configIntegration.HasConfigFile())
@@ -99,6 +228,92 @@ including domain, use cases, repository and handlers in a single operation.`,
 		if effectiveBusinessRules {
 			ui.Feature("Including business rules", configIntegration.HasConfigFile())
 		}
+		if versioned {
+			ui.Feature("Including optimistic locking", false)
+		}
+		if audit {
+			if !isGormDatabase(effectiveDatabase) {
+				ui.Error(fmt.Sprintf("--audit stores the audit log with GORM and does not support %s", effectiveDatabase))
+				os.Exit(1)
+			}
+			ui.Feature("Including audit trail", false)
+		}
+		if multiTenant {
+			if err := validateMultiTenant(effectiveDatabase, tenantIsolation, cacheFlag, audit); err != nil {
+				ui.Error(err.Error())
+				os.Exit(1)
+			}
+			ui.Feature(fmt.Sprintf("Including multi-tenancy (%s isolation)", tenantIsolation), false)
+		}
+		effectiveTracing := configIntegration.GetTracingEnabled(tracingFlag)
+		if effectiveTracing {
+			ui.Feature("Including OpenTelemetry tracing", !tracingFlag)
+		}
+		effectiveResilience := configIntegration.GetResilienceEnabled(resilienceFlag) && layers[LayerRepository]
+		if effectiveResilience {
+			ui.Feature("Including repository retries and circuit breaker", !resilienceFlag)
+		}
+		effectiveCQRS := (configIntegration.GetCQRSEnabled(cqrsFlag) || projectionFlag) && layers[LayerUseCase]
+		effectiveProjection := effectiveCQRS && (projectionFlag || configIntegration.GetCQRSConfig().Projection)
+		if effectiveCQRS {
+			if err := validateCQRS(effectiveDatabase, multiTenant); err != nil {
+				ui.Error(err.Error())
+				os.Exit(1)
+			}
+			ui.Feature("Splitting use cases into commands and queries", !cqrsFlag && !projectionFlag)
+			if effectiveProjection {
+				ui.Feature("Serving queries from an in-memory projection", !projectionFlag)
+			}
+		}
+		// With a gateway the REST routes come from the .proto, not an HTTP handler.
+		if gateway {
+			if err := validateGateway(effectiveHandlers); err != nil {
+				ui.Error(err.Error())
+				os.Exit(1)
+			}
+			effectiveHandlers = withoutHTTPHandler(effectiveHandlers)
+			ui.Feature("Serving REST through a gRPC gateway", false)
+		}
+		// Contracts describe the HTTP API, so they need its handler.
+		effectiveContracts := configIntegration.GetContractsEnabled(contractsFlag) &&
+			layers[LayerHandler] && strings.Contains(effectiveHandlers, HandlerHTTP)
+		if effectiveContracts {
+			ui.Feature("Including Pact contract tests", !contractsFlag)
+		}
+		if effectiveDI != DIManual {
+			ui.Feature(fmt.Sprintf("Dependency injection with %s", effectiveDI), diFlag == "")
+		}
+		// Bulk endpoints build on the repository, the service and the HTTP
+		// handler.
+		importExport = importExport && layers[LayerRepository] && layers[LayerUseCase] && layers[LayerHandler]
+		if importExport {
+			if err := validateImportExport(effectiveDatabase, effectiveHandlers, audit, multiTenant, effectiveCQRS); err != nil {
+				ui.Error(err.Error())
+				os.Exit(1)
+			}
+			ui.Feature("Including bulk CSV/JSON import and export", false)
+		}
+		if projectUsesObjectIDs() {
+			if err := validateObjectIDs(effectiveHandlers, cacheFlag, audit, effectiveContracts); err != nil {
+				ui.Error(err.Error())
+				os.Exit(1)
+			}
+		}
+		var partition partitionSpec
+		if partitionedBy != "" {
+			if partition, err = parsePartitionSpec(partitionedBy); err == nil {
+				err = validatePartitioning(effectiveDatabase, partition, fields, layers, multiTenant)
+			}
+			if err != nil {
+				ui.Error(err.Error())
+				os.Exit(1)
+			}
+			ui.Feature(fmt.Sprintf("Partitioning the table by %s of %s", partition.Interval, partition.Column), false)
+		}
+		effectivePresenter := configIntegration.GetPresenterEnabled(presenterFlag) && layers[LayerHandler]
+		if effectivePresenter {
+			ui.Feature("Presenting entities through a shared presenter", !presenterFlag)
+		}
 
 		if configIntegration.HasConfigFile() {
 			configIntegration.PrintConfigSummary()
@@ -114,19 +329,87 @@ including domain, use cases, repository and handlers in a single operation.`,
 		// routes generated below detect it and wire middleware.CORS/Logging from
 		// the package instead of emitting inline duplicates that leave the
 		// package orphaned.
-		if middlewareTypesStr != "" {
+		if middlewareTypesStr != "" && layers[LayerHandler] {
 			mwTypes := parseMiddlewareTypes(middlewareTypesStr)
 			if err := validateMiddlewareTypes(mwTypes); err != nil {
 				ui.Error(fmt.Sprintf("middleware-types: %v", err))
 				os.Exit(1)
 			}
 			ui.Step(0, "Generating middleware package...")
-			if err := generateMiddlewarePackage(featureName, mwTypes, safetyMgr); err != nil {
+			if err := generateMiddlewarePackage(featureName, mwTypes, RouterMux, safetyMgr); err != nil {
 				ui.Warning(fmt.Sprintf("Could not generate middleware: %v", err))
 			}
 		}
 
-		generateCompleteFeature(featureName, fields, effectiveDatabase, effectiveHandlers, effectiveValidation, effectiveBusinessRules, cacheFlag, fileNamingConvention, safetyMgr)
+		// The TableName method comes first so every layer uses the table.
+		if table != "" {
+			generateEntityTableName(featureName, table, safetyMgr)
+		}
+		// Likewise the partitions file, whose callback the repository
+		// constructor registers.
+		if partitionedBy != "" {
+			if err := generatePartitions(featureName, partition, fields, safetyMgr); err != nil {
+				ui.Error(fmt.Sprintf("Error generating partitions: %v", err))
+				os.Exit(1)
+			}
+		}
+		generateCompleteFeature(featureName, fields, effectiveDatabase, effectiveHandlers, apiVersion, effectiveValidation, effectiveBusinessRules, cacheFlag, versioned, audit, multiTenant, gateway, effectivePresenter, fileNamingConvention, effectiveDTOStyle, layers, safetyMgr)
+
+		// Generate the tracing decorator before integration so the DI container
+		// detects it and wraps the use case.
+		if effectiveTracing && layers[LayerUseCase] {
+			ui.Dim("   Generating use case tracing decorator...")
+			generateUseCaseTracingDecorator(featureName, parseOperations("create,read,update,delete,list"), safetyMgr)
+		}
+
+		// Likewise the resilience decorator, which the container puts around
+		// the repository.
+		if effectiveResilience {
+			ui.Dim("   Generating repository resilience decorator...")
+			createResiliencePackage(configIntegration.GetResilienceConfig(), safetyMgr)
+			generateResilienceDecorator(featureName, safetyMgr)
+		}
+
+		// And the command and query services, which the container builds
+		// instead of the combined service.
+		if effectiveCQRS {
+			ui.Dim("   Generating command and query services...")
+			createCQRSFiles(effectiveDatabase, configIntegration.GetCQRSConfig(), safetyMgr)
+			generateCQRS(featureName, fields, effectiveDatabase, effectiveDTOStyle, effectiveValidation, effectiveProjection, safetyMgr)
+		}
+
+		// Dry runs write no entity to add CreatedAt to or read the columns of.
+		if partitionedBy != "" && !dryRun {
+			ui.Dim("   Generating partitioned table migration...")
+			migration, err := completePartitioning(featureName, partition, safetyMgr)
+			if err != nil {
+				ui.Error(fmt.Sprintf("Error generating the partitioned table migration: %v", err))
+				os.Exit(1)
+			}
+			ui.Dim(fmt.Sprintf("   Migration: %s", migration))
+		}
+
+		if multiTenant && tenantIsolation == TenantIsolationSchema {
+			ui.Dim("   Generating tenant registry and cmd/tenant...")
+			generateTenantOnboarding(featureName, effectiveDatabase, safetyMgr)
+		}
+
+		if effectiveContracts {
+			ui.Dim("   Generating Pact contract tests...")
+			generateContractTests(featureName, safetyMgr)
+		}
+
+		if importExport {
+			ui.Dim("   Generating bulk import and export endpoints...")
+			if err := generateImportExport(featureName, safetyMgr); err != nil {
+				ui.Error(fmt.Sprintf("Error generating import and export: %v", err))
+				os.Exit(1)
+			}
+		}
+
+		// Plugins run after the built-in generators, once per generated layer
+		// and once for the whole feature.
+		runPluginHooks(featureName, safetyMgr, featurePluginHooks(layers)...)
 
 		// Show dry-run summary
 		if dryRun {
@@ -146,8 +429,19 @@ including domain, use cases, repository and handlers in a single operation.`,
 		}
 
 		// 7. Auto-integrate with DI and main.go
-		ui.Step(7, "Integrating automatically...")
-		autoIntegrateFeature(featureName, handlers, effectiveDatabase, cacheFlag, safetyMgr)
+		integrated := canIntegrateFeature(presentFeatureLayers(featureName, layers))
+		if integrated {
+			ui.Step(7, "Integrating automatically...")
+			autoIntegrateFeature(featureName, effectiveHandlers, apiVersion, effectiveDatabase, effectiveDI, cacheFlag, safetyMgr)
+			if multiTenant {
+				registerTenancyPlugin(tenantIsolation, safetyMgr)
+			}
+			if importExport {
+				registerImportExportRoutes(featureName, safetyMgr)
+			}
+		} else {
+			ui.Dim("   Skipping DI and route integration: it needs the usecase, repository and handler layers, generated now or earlier")
+		}
 
 		// 8. Handle dependencies
 		ui.Step(8, "Managing dependencies...")
@@ -155,7 +449,18 @@ including domain, use cases, repository and handlers in a single operation.`,
 		// Add required dependencies
 		requiredDeps := depMgr.GetRequiredDependenciesForFeature(
 			effectiveHandlers,
-			map[string]bool{"validation": effectiveValidation},
+			map[string]bool{
+				"validation": effectiveValidation,
+				"tracing":    effectiveTracing,
+				"resilience": effectiveResilience,
+				DIWire:       effectiveDI == DIWire,
+				DIFx:         effectiveDI == DIFx,
+				"json":       layers[LayerDomain] && fieldsUseType(parseFields(fields), FieldJSONType),
+				"contracts":  effectiveContracts,
+				"storage":    layers[LayerHandler] && strings.Contains(effectiveHandlers, HandlerHTTP) && fieldsUseType(parseFields(fields), FieldFileType),
+				"zstd":       layers[LayerHandler] && strings.Contains(effectiveHandlers, HandlerHTTP) && compressionUsesZstd(),
+				"gateway":    layers[LayerHandler] && gateway,
+			},
 		)
 
 		for _, dep := range requiredDeps {
@@ -183,6 +488,10 @@ including domain, use cases, repository and handlers in a single operation.`,
 		}
 
 		// 10. Generate integration tests if requested
+		if integrationTests && !isFullFeature(layers) {
+			ui.Warning("Skipping integration tests: they need every layer")
+			integrationTests = false
+		}
 		if integrationTests {
 			ui.Step(10, "Generating integration tests...")
 			parsedFields := parseFields(fields)
@@ -203,28 +512,66 @@ including domain, use cases, repository and handlers in a single operation.`,
 			}
 		}
 
-		ui.Success(fmt.Sprintf("Feature '%s' generated and integrated successfully!", featureName))
+		// Record the feature for go generate, which regenerates it when its
+		// entry in the manifest changes.
+		goGenerate := configIntegration.GetGoGenerateEnabled(goGenerateFlag)
+		if goGenerate {
+			if unrecorded := unrecordedManifestFlags(cmd); len(unrecorded) > 0 {
+				ui.Warning(fmt.Sprintf("%s cannot record %s; go generate regenerates %s without them", featureManifestFile, strings.Join(unrecorded, ", "), featureName))
+			}
+			recorded := batchFeature{
+				name:          featureName,
+				fields:        fields,
+				handlers:      effectiveHandlers,
+				validation:    effectiveValidation,
+				businessRules: effectiveBusinessRules,
+				cache:         cacheFlag,
+				versioned:     versioned,
+				audit:         audit,
+				resilience:    effectiveResilience,
+				cqrs:          effectiveCQRS,
+			}
+			if err := recordManifestFeature(recorded, effectiveDatabase, safetyMgr); err != nil {
+				ui.Warning(fmt.Sprintf("Could not record %s in %s: %v", featureName, featureManifestFile, err))
+			}
+		}
+
+		if integrated {
+			ui.Success(fmt.Sprintf("Feature '%s' generated and integrated successfully!", featureName))
+		} else {
+			ui.Success(fmt.Sprintf("Feature '%s' generated (%s)", featureName, strings.Join(selectedLayerNames(layers), ", ")))
+		}
 		ui.Blank()
 		ui.Section("Generated structure")
-		printFeatureStructure(featureName, handlers)
+		printFeatureStructure(featureName, handlers, layers)
 
 		ui.Blank()
 		ui.Println("The feature is now:")
-		ui.Dim("   - Connected in the DI container")
-		ui.Dim("   - Routes registered in the server")
-		ui.Dim("   - Ready to use immediately")
-		ui.Dim("   - With seed data included")
+		if integrated {
+			ui.Dim("   - Connected in the DI container")
+			ui.Dim("   - Routes registered in the server")
+			ui.Dim("   - Ready to use immediately")
+		}
+		if layers[LayerDomain] {
+			ui.Dim("   - With seed data included")
+		}
 		if integrationTests {
 			ui.Dim("   - Integration tests generated")
 		}
 		if generateMocksFlag {
 			ui.Dim("   - Mock implementations generated")
 		}
+		if goGenerate {
+			ui.Dim("   - Recorded in " + featureManifestFile + " for go generate")
+		}
 
-		nextSteps := []string{
-			"Run: go mod tidy",
-			"Start server: go run cmd/server/main.go",
-			fmt.Sprintf("Test endpoints: curl http://localhost:8080/api/v1/%ss", strings.ToLower(featureName)),
+		nextSteps := []string{"Run: go mod tidy"}
+		if multiTenant && tenantIsolation == TenantIsolationSchema {
+			nextSteps = append(nextSteps, "Onboard a tenant: go run ./cmd/tenant create acme -name \"Acme Inc.\"")
+		}
+		if integrated {
+			nextSteps = append(nextSteps, serverNextSteps(effectiveDI)...)
+			nextSteps = append(nextSteps, fmt.Sprintf("Test endpoints: curl http://localhost:8080/api/v1/%s", toPlural(strings.ToLower(featureName))))
 		}
 		if integrationTests {
 			nextSteps = append(nextSteps, "Run integration tests: go test ./internal/testing/integration -v")
@@ -244,69 +591,270 @@ including domain, use cases, repository and handlers in a single operation.`,
 	},
 }
 
-func generateCompleteFeature(featureName, fields, database, handlers string, validation, businessRules, cache bool, fileNamingConvention string, safetyMgr *SafetyManager) {
+func generateCompleteFeature(featureName, fields, database, handlers, apiVersion string, validation, businessRules, cache, versioned, audit, multiTenant, gateway, presenter bool, fileNamingConvention, dtoStyle string, layers map[string]bool, safetyMgr *SafetyManager) {
+	defer profileStep("feature", featureName)()
 	ui.Blank()
 	ui.Info("Generating layers...")
 
 	// 1. Generate Entity (Domain layer)
-	ui.Step(1, "Generating domain entity...")
-	if err := generateEntity(featureName, fields, true, businessRules, false, false, true, fileNamingConvention, safetyMgr); err != nil {
-		os.Exit(1)
+	if layers[LayerDomain] {
+		ui.Step(1, "Generating domain entity...")
+		if err := generateEntity(featureName, fields, true, businessRules, false, false, versioned, audit, multiTenant, true, fileNamingConvention, safetyMgr); err != nil {
+			os.Exit(1)
+		}
 	}
 
 	// 2. Generate Use Case
-	ui.Step(2, "Generating use cases...")
-	generateUseCaseWithFields(featureName+"UseCase", featureName, "create,read,update,delete,list", validation, false, fields, safetyMgr)
+	if layers[LayerUseCase] {
+		ui.Step(2, "Generating use cases...")
+		generateUseCaseWithFields(featureName+"UseCase", featureName, "create,read,update,delete,list", validation, false, fields, dtoStyle, safetyMgr)
+	}
 
 	// 3. Generate Repository
-	ui.Step(3, "Generating repository...")
-	// implementation=false so BOTH the interface and the implementation are
-	// generated (the DI container and use case depend on the interface type).
-	generateRepository(featureName, database, false, false, cache, false, fields, safetyMgr)
+	if layers[LayerRepository] {
+		ui.Step(3, "Generating repository...")
+		// implementation=false so BOTH the interface and the implementation are
+		// generated (the DI container and use case depend on the interface type).
+		generateRepository(featureName, database, false, false, cache, false, fields, safetyMgr)
+	} else if layers[LayerUseCase] && !featureLayerDeclared(featureName, LayerRepository) {
+		// The use case calls the repository through its interface: generate
+		// that port alone, for the project to implement.
+		ui.Step(3, "Generating repository interface...")
+		generateRepository(featureName, "", true, false, false, false, fields, safetyMgr)
+	}
 
-	// 4. Generate Handlers
-	ui.Step(4, "Generating handlers...")
-	handlerTypes := strings.Split(handlers, ",")
-	for _, handlerType := range handlerTypes {
-		handlerType = strings.TrimSpace(handlerType)
-		ui.Dim(fmt.Sprintf("   Generating %s handler...", handlerType))
-		generateHandler(featureName, handlerType, true, validation, handlerType == "http", fileNamingConvention, safetyMgr)
+	// 4. Generate Handlers, which present entities through the presenter
+	// when there is one
+	if layers[LayerHandler] {
+		ui.Step(4, "Generating handlers...")
+		if presenter && (loadPresenter(featureName) == nil || safetyMgr.Force) {
+			ui.Dim("   Generating presenter...")
+			if err := generatePresenterFile(featureName, fileNamingConvention, safetyMgr); err != nil {
+				ui.Warning(err.Error())
+			}
+		}
+		handlerTypes := strings.Split(handlers, ",")
+		for _, handlerType := range handlerTypes {
+			handlerType = strings.TrimSpace(handlerType)
+			ui.Dim(fmt.Sprintf("   Generating %s handler...", handlerType))
+			if gateway && handlerType == HandlerGRPC {
+				generateGRPCHandler(featureName, fileNamingConvention, true, safetyMgr)
+				continue
+			}
+			if handlerType == HandlerHTTP {
+				generateVersionedHTTPHandler(featureName, featureAPIVersion(featureName, apiVersion), true, validation, true, fileNamingConvention, safetyMgr)
+				continue
+			}
+			generateHandler(featureName, handlerType, true, validation, false, fileNamingConvention, safetyMgr)
+		}
 	}
 
 	// 5. Generate Messages
-	ui.Step(5, "Generating messages...")
-	generateMessages(featureName, true, true, true, safetyMgr)
-
-	// 6. Register entity for auto-migration
-	ui.Step(6, "Registering entity for auto-migration...")
-	if registered, err := registerEntityForAutoMigration(featureName); err != nil {
-		ui.Warning(fmt.Sprintf("Could not register entity for auto-migration: %v", err))
-		ui.Dim("   Tip: Entity was created correctly, but you'll need to configure migration manually")
-	} else if registered {
-		ui.Success(fmt.Sprintf("Entity %s registered for GORM auto-migration", featureName))
+	if layers[LayerMessages] {
+		ui.Step(5, "Generating messages...")
+		generateMessages(featureName, true, true, true, safetyMgr)
+	} else if layers[LayerUseCase] {
+		// The use case returns the entity's response messages.
+		ui.Step(5, "Generating the use case messages...")
+		generateMessages(featureName, true, true, true, safetyMgr)
+	}
+
+	// 6. Register entity for auto-migration, which persists it through the
+	// repository; entities of library features stay out of main.go.
+	if layers[LayerRepository] && isPartitionedEntity(featureName) {
+		ui.Dim(fmt.Sprintf("   Entity %s is partitioned: a migration creates its table instead of GORM auto-migration", featureName))
+	} else if layers[LayerRepository] {
+		ui.Step(6, "Registering entity for auto-migration...")
+		if registered, err := registerEntityForAutoMigration(featureName); err != nil {
+			ui.Warning(fmt.Sprintf("Could not register entity for auto-migration: %v", err))
+			ui.Dim("   Tip: Entity was created correctly, but you'll need to configure migration manually")
+		} else if registered {
+			ui.Success(fmt.Sprintf("Entity %s registered for GORM auto-migration", featureName))
+		} else {
+			ui.Dim(fmt.Sprintf("   Entity %s already registered for auto-migration", featureName))
+		}
+		if audit {
+			if _, err := registerEntityForAutoMigration("AuditLog"); err != nil {
+				ui.Warning(fmt.Sprintf("Could not register AuditLog for auto-migration: %v", err))
+			}
+		}
+	}
+
+	if isFullFeature(layers) {
+		ui.Success("All layers generated successfully!")
 	} else {
-		ui.Dim(fmt.Sprintf("   Entity %s already registered for auto-migration", featureName))
+		ui.Success(fmt.Sprintf("Generated layers: %s", strings.Join(selectedLayerNames(layers), ", ")))
 	}
+}
+
+// resolveFeatureLayers turns the --skip and --layers (--only) flags into the
+// set of layers to generate. Both take comma-separated names from
+// ValidFeatureLayers and cannot be combined.
+func resolveFeatureLayers(skip, only string) (map[string]bool, error) {
+	if strings.TrimSpace(skip) != "" && strings.TrimSpace(only) != "" {
+		return nil, fmt.Errorf("--skip and --layers cannot be used together")
+	}
+
+	parse := func(flag, value string) ([]string, error) {
+		var names []string
+		for _, name := range strings.Split(value, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if !contains(ValidFeatureLayers, name) {
+				return nil, fmt.Errorf("--%s: unknown layer %q; use one of: %s", flag, name, strings.Join(ValidFeatureLayers, ", "))
+			}
+			names = append(names, name)
+		}
+		return names, nil
+	}
+
+	layers := allFeatureLayers()
+	if strings.TrimSpace(only) != "" {
+		names, err := parse("layers", only)
+		if err != nil {
+			return nil, err
+		}
+		layers = make(map[string]bool, len(names))
+		for _, name := range names {
+			layers[name] = true
+		}
+	} else {
+		names, err := parse("skip", skip)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			delete(layers, name)
+		}
+	}
+
+	if len(layers) == 0 {
+		return nil, fmt.Errorf("no layers left to generate")
+	}
+	return layers, nil
+}
+
+// allFeatureLayers returns the default layer set: every layer.
+func allFeatureLayers() map[string]bool {
+	layers := make(map[string]bool, len(ValidFeatureLayers))
+	for _, layer := range ValidFeatureLayers {
+		layers[layer] = true
+	}
+	return layers
+}
+
+// isFullFeature reports whether every layer is selected.
+func isFullFeature(layers map[string]bool) bool {
+	return len(selectedLayerNames(layers)) == len(ValidFeatureLayers)
+}
+
+// selectedLayerNames lists the selected layers in generation order.
+func selectedLayerNames(layers map[string]bool) []string {
+	var names []string
+	for _, layer := range ValidFeatureLayers {
+		if layers[layer] {
+			names = append(names, layer)
+		}
+	}
+	return names
+}
+
+// canIntegrateFeature reports whether the DI container and routes can be
+// wired: they reference the use case, the repository and the handler.
+func canIntegrateFeature(layers map[string]bool) bool {
+	return layers[LayerUseCase] && layers[LayerRepository] && layers[LayerHandler]
+}
 
-	ui.Success("All layers generated successfully!")
+// featureLayerDependencies lists the layers the code of each layer refers to.
+var featureLayerDependencies = map[string][]string{
+	LayerUseCase:    {LayerDomain, LayerRepository},
+	LayerRepository: {LayerDomain},
+	LayerHandler:    {LayerUseCase},
 }
 
-func printFeatureStructure(featureName, handlers string) {
+// featureLayerDeclared reports whether the project already has a layer of
+// entity: the entity, its use case or its repository interface. Handlers
+// and messages are not depended on.
+func featureLayerDeclared(entity, layer string) bool {
+	switch layer {
+	case LayerDomain:
+		return findTypeFile(filepath.Join(DirInternal, DirDomain), entity) != ""
+	case LayerUseCase:
+		return useCaseDeclared(entity)
+	case LayerRepository:
+		return findTypeFile(filepath.Join(DirInternal, DirRepository), entity+"Repository") != ""
+	case LayerHandler:
+		return findTypeFile(filepath.Join(DirInternal, DirHandler, DirHTTP), entity+"Handler") != ""
+	}
+	return false
+}
+
+// presentFeatureLayers returns the selected layers plus those the project
+// already has.
+func presentFeatureLayers(entity string, layers map[string]bool) map[string]bool {
+	present := make(map[string]bool, len(ValidFeatureLayers))
+	for _, layer := range ValidFeatureLayers {
+		present[layer] = layers[layer] || featureLayerDeclared(entity, layer)
+	}
+	return present
+}
+
+// checkFeatureLayerDependencies rejects a selection leaving out a layer that
+// a selected one refers to, unless the project already has it. The
+// repository of a use case is the exception: without it, its interface is
+// generated alone, as are the messages the use case returns.
+func checkFeatureLayerDependencies(entity string, layers map[string]bool) error {
+	var missing []string
+	for _, layer := range selectedLayerNames(layers) {
+		for _, dep := range featureLayerDependencies[layer] {
+			if layers[dep] || featureLayerDeclared(entity, dep) || (layer == LayerUseCase && dep == LayerRepository) {
+				continue
+			}
+			missing = append(missing, fmt.Sprintf("%s needs %s", layer, dep))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s is missing layers: %s; add them to --layers or generate them first",
+			entity, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func printFeatureStructure(featureName, handlers string, layers map[string]bool) {
 	featureLower := strings.ToLower(featureName)
 
-	rows := [][]string{
-		{"Domain", fmt.Sprintf("%s.go", featureLower), "Pure entity"},
-		{"Domain", "errors.go", "Domain errors"},
-		{"Domain", "validations.go", "Business validations"},
-		{"UseCase", "dto.go", "Input/Output DTOs"},
-		{"UseCase", fmt.Sprintf("%s_usecase.go", featureLower), "Use case interface"},
-		{"UseCase", fmt.Sprintf("%s_service.go", featureLower), "Implementation"},
-		{"UseCase", "interfaces.go", "Layer contracts"},
-		{"Repository", "interfaces.go", "Persistence contracts"},
-		{"Repository", fmt.Sprintf("postgres_%s_repo.go", featureLower), "DB implementation"},
+	var rows [][]string
+	if layers[LayerDomain] {
+		rows = append(
+			rows,
+			[]string{"Domain", fmt.Sprintf("%s.go", featureLower), "Pure entity"},
+			[]string{"Domain", "errors.go", "Domain errors"},
+			[]string{"Domain", "validations.go", "Business validations"},
+		)
+	}
+	if layers[LayerUseCase] {
+		rows = append(
+			rows,
+			[]string{"UseCase", "dto.go", "Input/Output DTOs"},
+			[]string{"UseCase", fmt.Sprintf("%s_usecase.go", featureLower), "Use case interface"},
+			[]string{"UseCase", fmt.Sprintf("%s_service.go", featureLower), "Implementation"},
+			[]string{"UseCase", "interfaces.go", "Layer contracts"},
+		)
+	}
+	if layers[LayerRepository] {
+		rows = append(
+			rows,
+			[]string{"Repository", "interfaces.go", "Persistence contracts"},
+			[]string{"Repository", fmt.Sprintf("postgres_%s_repo.go", featureLower), "DB implementation"},
+		)
 	}
 
 	handlerTypes := strings.Split(handlers, ",")
+	if !layers[LayerHandler] {
+		handlerTypes = nil
+	}
 	for _, handlerType := range handlerTypes {
 		handlerType = strings.TrimSpace(handlerType)
 		switch handlerType {
@@ -321,47 +869,100 @@ func printFeatureStructure(featureName, handlers string) {
 				rows,
 				[]string{"Handler", fmt.Sprintf("grpc/%s.proto", featureLower), "gRPC definition"},
 				[]string{"Handler", fmt.Sprintf("grpc/%s_server.go", featureLower), "gRPC server"},
+				[]string{"Handler", fmt.Sprintf("grpc/%s_mapping.go", featureLower), "Message/DTO mapping"},
 			)
 		case "cli":
-			rows = append(rows, []string{"Handler", fmt.Sprintf("cli/%s_commands.go", featureLower), "CLI commands"})
+			rows = append(
+				rows,
+				[]string{"Handler", fmt.Sprintf("cli/%s_commands.go", featureLower), "CLI commands"},
+				[]string{"Entrypoint", "cmd/cli/main.go", "CLI command groups"},
+			)
 		case "worker":
 			rows = append(rows, []string{"Handler", fmt.Sprintf("worker/%s_worker.go", featureLower), "Workers/Jobs"})
+		case HandlerWebSocket:
+			rows = append(
+				rows,
+				[]string{"Handler", fmt.Sprintf("ws/%s_ws.go", featureLower), "WebSocket broadcaster and route"},
+				[]string{"Handler", "ws/hub.go", "WebSocket hub"},
+			)
+		case HandlerCron:
+			rows = append(
+				rows,
+				[]string{"Handler", fmt.Sprintf("scheduler/%s_jobs.go", featureLower), "Scheduled jobs"},
+				[]string{"Entrypoint", "cmd/scheduler/main.go", "Job scheduler"},
+			)
 		case "soap":
 			rows = append(rows, []string{"Handler", fmt.Sprintf("soap/%s_client.go", featureLower), "SOAP client"})
 		}
 	}
 
-	rows = append(
-		rows,
-		[]string{"Messages", "errors.go", "Error messages"},
-		[]string{"Messages", "responses.go", "Response messages"},
-	)
+	if layers[LayerMessages] {
+		rows = append(
+			rows,
+			[]string{"Messages", "errors.go", "Error messages"},
+			[]string{"Messages", "responses.go", "Response messages"},
+		)
+	}
 
 	ui.Table([]string{"Layer", "File", "Description"}, rows)
 }
 
 // autoIntegrateFeature automatically integrates the feature with DI and main.go.
-func autoIntegrateFeature(featureName, handlers, database string, cache bool, sm ...*SafetyManager) {
+func autoIntegrateFeature(featureName, handlers, apiVersion, database, diStyle string, cache bool, sm ...*SafetyManager) {
+	defer profileStep("integrate", featureName)()
 	ui.Dim("   Updating DI container...")
-	updateDIContainer(featureName, database, cache, sm...)
+	updateDIContainer(featureName, database, diStyle, cache, sm...)
 
 	ui.Dim("   Registering HTTP routes...")
 	if strings.Contains(handlers, "http") {
-		updateMainRoutes(featureName)
+		updateMainRoutes(featureName, featureAPIVersion(featureName, apiVersion))
+	}
+	if strings.Contains(handlers, HandlerWebSocket) {
+		ui.Dim("   Registering WebSocket routes...")
+		registerWebSocketRoutes(featureName, sm...)
+	}
+	if strings.Contains(handlers, HandlerGRPC) && isGatewayEntity(featureName) {
+		ui.Dim("   Mounting gRPC gateway...")
+		registerGateway(featureName, sm...)
 	}
 
 	ui.Info("Integration completed")
 }
 
-// updateDIContainer updates or creates DI container with new feature.
-func updateDIContainer(featureName, database string, cache bool, sm ...*SafetyManager) {
+// updateDIContainer updates or creates DI container with new feature. With the
+// wire, fx and modular styles the feature gets its own providers file and the
+// container is regenerated from all of them.
+func updateDIContainer(featureName, database, diStyle string, cache bool, sm ...*SafetyManager) {
 	// Check if DI container exists
 	diPath := filepath.Join("internal", "di", "container.go")
+	existing, readErr := os.ReadFile(diPath)
 
-	if _, err := os.Stat(diPath); os.IsNotExist(err) {
+	if diStyle == DIWire || diStyle == DIFx || diStyle == DIModular {
+		if readErr == nil && !isProviderContainer(string(existing)) {
+			ui.Warning(fmt.Sprintf("%s is a manual container; adding %s manually", diPath, featureName))
+			ui.Dim(fmt.Sprintf("   Tip: goca di --di %s --features <all features> --force switches the project to %s", diStyle, diStyle))
+			addFeatureToDI(featureName, database, cache, sm...)
+			return
+		}
+		if readErr == nil && isModularContainer(string(existing)) != (diStyle == DIModular) {
+			ui.Warning(fmt.Sprintf("%s was generated for another DI style; not adding %s with %s", diPath, featureName, diStyle))
+			ui.Dim(fmt.Sprintf("   Tip: goca di --di %s --features <all features> --force switches the project to %s", diStyle, diStyle))
+			return
+		}
+		ui.Dim(fmt.Sprintf("   Generating %s providers for %s...", diStyle, featureName))
+		generateDI(featureName, database, diStyle, "", cache, sm...)
+		return
+	}
+
+	if readErr == nil && isProviderContainer(string(existing)) {
+		ui.Warning(fmt.Sprintf("%s is generated from providers; rerun with --di wire, fx or modular", diPath))
+		return
+	}
+
+	if os.IsNotExist(readErr) {
 		// DI doesn't exist, create it with this feature
 		ui.Dim(fmt.Sprintf("   Creating DI container for %s...", featureName))
-		generateDI(featureName, database, false, false, sm...)
+		generateDI(featureName, database, DIManual, "", false, sm...)
 	} else {
 		// DI exists, update it to include new feature
 		ui.Dim("   Updating existing DI container...")
@@ -393,6 +994,13 @@ func addFeatureToDI(featureName, database string, cache bool, sm ...*SafetyManag
 	updatedContent := addFieldsToDIContainer(contentStr, featureName, featureLower)
 	updatedContent = addSetupMethodsToDI(updatedContent, featureName, featureLower, database, cache)
 	updatedContent = addGetterMethodsToDI(updatedContent, featureName, featureLower)
+	if !hasHTTPHandler(featureName) {
+		updatedContent = removeHTTPHandlerFromDI(updatedContent, featureName)
+	}
+	// The handler/http import follows whether any feature has an HTTP handler.
+	if synced, err := syncImports([]byte(updatedContent), getImportPath(getModuleName())+"/internal/handler/http"); err == nil {
+		updatedContent = string(synced)
+	}
 
 	// This is an in-place merge of an existing container.go that we just read,
 	// so honor dry-run/backup but bypass the "file already exists" guard.
@@ -435,18 +1043,19 @@ func addSetupMethodsToDI(content, featureName, featureLower, database string, ca
 	// redisClient field. Otherwise emitting NewCached…/c.redisClient would
 	// reference symbols the container does not provide and break compilation.
 	wireCache := cache && hasCacheDecorator(featureName) && strings.Contains(content, "redisClient")
+	repoConstructor := resilientRepositoryExpr(featureName, fmt.Sprintf("repository.New%s%sRepository(c.db)", repoPrefix, featureName))
 	var repoSetup string
 	if wireCache {
-		repoSetup = fmt.Sprintf("\tbase%sRepo := repository.New%s%sRepository(c.db)\n", featureName, repoPrefix, featureName)
+		repoSetup = fmt.Sprintf("\tbase%sRepo := %s\n", featureName, repoConstructor)
 		repoSetup += fmt.Sprintf("\tc.%sRepo = repository.NewCached%sRepository(base%sRepo, c.redisClient, 5*time.Minute)\n", featureLower, featureName, featureName)
 	} else {
-		repoSetup = fmt.Sprintf("\tc.%sRepo = repository.New%s%sRepository(c.db)\n", featureLower, repoPrefix, featureName)
+		repoSetup = fmt.Sprintf("\tc.%sRepo = %s\n", featureLower, repoConstructor)
 	}
 	setupRepoEnd := "}\n\nfunc (c *Container) setupUseCases() {"
 	content = strings.Replace(content, setupRepoEnd, repoSetup+setupRepoEnd, 1)
 
 	// Add use case setup
-	ucSetup := fmt.Sprintf("\tc.%sUC = usecase.New%sService(c.%sRepo)\n", featureLower, featureName, featureLower)
+	ucSetup := fmt.Sprintf("\tc.%sUC = %s\n", featureLower, useCaseConstructor(featureName))
 	setupUCEnd := "}\n\nfunc (c *Container) setupHandlers() {"
 	content = strings.Replace(content, setupUCEnd, ucSetup+setupUCEnd, 1)
 
@@ -479,8 +1088,30 @@ func (c *Container) %sRepository() repository.%sRepository {
 	return content + getters
 }
 
-// updateMainRoutes updates main.go to include new feature routes.
-func updateMainRoutes(featureName string) {
+// removeHTTPHandlerFromDI drops the HTTP handler of a feature without one from
+// the container.
+func removeHTTPHandlerFromDI(content, featureName string) string {
+	var lines []string
+	skip := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		switch {
+		case skip > 0:
+			skip--
+			continue
+		case strings.HasPrefix(line, fmt.Sprintf("func (c *Container) %sHandler() *http.", featureName)):
+			skip = 3 // body, closing brace and blank line
+			continue
+		case strings.Contains(line, "*http."+featureName+"Handler") || strings.Contains(line, "http.New"+featureName+"Handler("):
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "")
+}
+
+// updateMainRoutes updates main.go to include new feature routes under the
+// subrouter of an API version.
+func updateMainRoutes(featureName, version string) {
 	mainPath, found := findMainGoPath()
 	if !found {
 		handleMainGoNotFound(featureName)
@@ -488,6 +1119,7 @@ func updateMainRoutes(featureName string) {
 	}
 
 	ui.Dim(fmt.Sprintf("   Found main.go at: %s", mainPath))
+	defer lockSharedFile(mainPath)()
 
 	content, err := os.ReadFile(mainPath)
 	if err != nil {
@@ -508,7 +1140,7 @@ func updateMainRoutes(featureName string) {
 		return
 	}
 
-	setupMainGoWithFeature(mainPath, featureName, moduleName, string(content))
+	setupMainGoWithFeature(mainPath, featureName, moduleName, version, string(content))
 }
 
 // findMainGoPath locates the main.go file in possible locations.
@@ -537,14 +1169,14 @@ func handleMainGoNotFound(featureName string) {
 // isFeatureAlreadyRegistered checks if feature routes are already present.
 func isFeatureAlreadyRegistered(content, featureName string) bool {
 	featureLower := strings.ToLower(featureName)
-	return strings.Contains(content, fmt.Sprintf("/%ss", featureLower))
+	return strings.Contains(content, fmt.Sprintf("/%s", toPlural(featureLower)))
 }
 
 // setupMainGoWithFeature sets up the main.go file with the new feature.
-func setupMainGoWithFeature(mainPath, featureName, moduleName, content string) {
-	// Wire the feature into main.go: DI container + /api/v1 routes.
+func setupMainGoWithFeature(mainPath, featureName, moduleName, version, content string) {
+	// Wire the feature into main.go: DI container + /api/<version> routes.
 	ui.Dim("   Wiring feature into main.go (DI container + routes)...")
-	if err := wireFeatureIntoMainGo(mainPath, featureName, moduleName, content); err != nil {
+	if err := wireFeatureIntoMainGo(mainPath, featureName, moduleName, version, content); err != nil {
 		ui.Warning(fmt.Sprintf("Could not wire routes into main.go: %v", err))
 		printManualIntegrationInstructions(featureName)
 		return
@@ -554,6 +1186,8 @@ func setupMainGoWithFeature(mainPath, featureName, moduleName, content string) {
 
 func init() {
 	featureCmd.Flags().StringP("fields", "f", "", "Entity fields \"field:type,field2:type\" (required)")
+	featureCmd.Flags().String("table", "", "Table the entity is stored in, e.g. people (default: the plural snake_case entity name, or architecture.naming in .goca.yaml)")
+	featureCmd.Flags().String("finders", "", "Repository finders \"email,findallby:status+tenant_id,existsby:email\" (default: derived from field names)")
 	// Default is empty so the database configured in .goca.yaml is honored when
 	// the flag is not provided; an explicit -d still takes precedence.
 	featureCmd.Flags().StringP("database", "d", "", fmt.Sprintf("Database type (%s)", strings.Join(ValidDatabases, ", ")))
@@ -562,6 +1196,7 @@ func init() {
 	featureCmd.Flags().BoolP("business-rules", "b", false, "Include business rule methods")
 
 	// Safety flags
+	featureCmd.Flags().String("preset", "", "Apply the feature flags of a preset (api, microservice, monolith, cli-app, or one defined in .goca.yaml; default: the preset in .goca.yaml)")
 	featureCmd.Flags().Bool("dry-run", false, "Preview changes without creating files")
 	featureCmd.Flags().Bool("force", false, "Overwrite existing files without asking")
 	featureCmd.Flags().Bool("backup", false, "Backup existing files before overwriting")
@@ -572,13 +1207,64 @@ func init() {
 	featureCmd.Flags().Bool("test-container", false, "Use test containers for database (used with --integration-tests)")
 	featureCmd.Flags().Bool("mocks", false, "Generate mock implementations for unit testing")
 
+	featureCmd.Flags().String("api-version", "", "API version the HTTP routes are generated for, e.g. v2, mounted under /api/<version> next to earlier versions (default from api.versioning)")
+
+	// gRPC gateway flag
+	featureCmd.Flags().Bool("gateway", false, "Serve the REST routes through a grpc-gateway derived from the gRPC .proto instead of an HTTP handler (needs the grpc handler)")
+
 	// Middleware flag
 	featureCmd.Flags().String("middleware-types", "", "Generate middleware package with given types (e.g. cors,logging,recovery)")
 
 	// Cache flag
 	featureCmd.Flags().BoolP("cache", "c", false, "Generate Redis cache decorator for the repository")
 
-	_ = featureCmd.MarkFlagRequired("fields")
+	// Optimistic locking flag
+	featureCmd.Flags().Bool("versioned", false, "Add a Version column checked on update (409 on conflict, ETag/If-Match in HTTP handlers)")
+
+	// Audit trail flag
+	featureCmd.Flags().Bool("audit", false, "Record who creates and changes the entity and keep an audit log with a history endpoint (GORM databases)")
+
+	// Multi-tenancy flags
+	featureCmd.Flags().Bool("multi-tenant", false, "Add a TenantID column and scope every repository query to the tenant of the request (GORM databases)")
+	featureCmd.Flags().String("tenant-isolation", TenantIsolationColumn, "How --multi-tenant keeps tenants apart: column (tenant_id filter) or schema (one PostgreSQL schema per tenant)")
+
+	// DI flag
+	featureCmd.Flags().String("di", "", "Dependency injection style (manual, wire, fx, modular); defaults to architecture.di.type")
+
+	// Presenter flag
+	featureCmd.Flags().Bool("presenter", false, "Generate internal/presenter, which the HTTP, gRPC and CLI handlers present the entity through (default from generation.presenter)")
+
+	// DTO style flag
+	featureCmd.Flags().String("dto-style", "", "How struct{...} fields appear in DTOs (nested, flat); defaults to generation.dto_style")
+
+	// Layer selection flags
+	featureCmd.Flags().String("skip", "", fmt.Sprintf("Layers to leave out (%s)", strings.Join(ValidFeatureLayers, ", ")))
+	featureCmd.Flags().String("layers", "", fmt.Sprintf("Only generate these layers (%s); layers they depend on must exist", strings.Join(ValidFeatureLayers, ", ")))
+	featureCmd.Flags().String("only", "", "Same as --layers")
+	featureCmd.MarkFlagsMutuallyExclusive("layers", "only")
+
+	// Tracing flag
+	featureCmd.Flags().Bool("tracing", false, "Generate OpenTelemetry tracing decorator for the use case (default from features.monitoring.tracing)")
+
+	// Resilience flag
+	featureCmd.Flags().Bool("resilience", false, "Wrap the repository with retries and a circuit breaker (default from features.resilience.enabled)")
+	featureCmd.Flags().Bool("cqrs", false, "Split the use case into command and query services reading from a replica (default from features.cqrs.enabled)")
+	featureCmd.Flags().Bool("cqrs-projection", false, "With --cqrs, serve the queries from an in-memory projection updated by domain events (default from features.cqrs.projection)")
+
+	// Contract testing flag
+	featureCmd.Flags().Bool("contracts", false, "Generate Pact provider verification tests and an example consumer pact for the HTTP handler (default from testing.contracts)")
+
+	// Import and export flag
+	featureCmd.Flags().Bool("import-export", false, "Generate bulk CSV/JSON import and filtered export endpoints with SaveBatch and FindPage batch methods (GORM databases)")
+
+	// Partitioning flag
+	featureCmd.Flags().String("partitioned-by", "", "Range partition the PostgreSQL table by a time column, as column:day|month|year (e.g. created_at:month)")
+
+	// Batch flags
+	featureCmd.Flags().String("batch", "", "Generate every feature listed in a YAML file instead of a single one")
+	featureCmd.Flags().Int("parallel", 0, "Features generated at once with --batch (default: the number of CPUs)")
+	featureCmd.Flags().String("from-manifest", "", "Regenerate the features of a manifest, such as "+featureManifestFile+", that changed since they were last generated")
+	featureCmd.Flags().Bool("go-generate", false, "Record the feature in "+featureManifestFile+" for go generate (default from generation.go_generate)")
 }
 
 // writeMergedFileSafe writes content that the caller has rebuilt from an
@@ -586,13 +1272,20 @@ func init() {
 // supplied it routes through WriteMergedFile (honoring dry-run/backup while
 // allowing overwrite of an existing file); otherwise it writes directly.
 func writeMergedFileSafe(path, content string, sm ...*SafetyManager) error {
+	if err := checkGeneratedGo(path, content); err != nil {
+		return err
+	}
 	if len(sm) > 0 && sm[0] != nil {
 		return sm[0].WriteMergedFile(path, content)
 	}
+	if err := checkProtectedPath(path); err != nil {
+		return err
+	}
 	//#nosec G306 // generated Go source, standard 0644 perms
 	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
 		return fmt.Errorf("failed to write %s: %w", path, err)
 	}
+	recordGenerated(path, content)
 	return nil
 }
 
@@ -601,10 +1294,17 @@ func writeMergedFileSafe(path, content string, sm ...*SafetyManager) error {
 // content. It deliberately does NOT go through the SafetyManager "file already
 // exists" guard, because these are in-place edits of files we just read.
 func writeMainGoInPlace(path, content string) error {
+	if err := checkGeneratedGo(path, content); err != nil {
+		return err
+	}
+	if err := checkProtectedPath(path); err != nil {
+		return err
+	}
 	//#nosec G306 // generated Go source, standard 0644 perms
 	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
 		return fmt.Errorf("failed to write %s: %w", path, err)
 	}
+	recordGenerated(path, content)
 	return nil
 }
 
@@ -629,10 +1329,12 @@ func isEntityAlreadyMigrated(content, entityReference string) bool {
 // adds the domain import if missing. Returns (true, nil) when it actually added
 // the entity, (false, nil) when it was already present.
 func registerEntityForAutoMigration(featureName string) (bool, error) {
+	defer profileStep("auto-migration", featureName)()
 	mainPath, err := findMainGoFile()
 	if err != nil {
 		return false, err
 	}
+	defer lockSharedFile(mainPath)()
 
 	raw, err := os.ReadFile(mainPath)
 	if err != nil {
@@ -666,10 +1368,24 @@ func registerEntityForAutoMigration(featureName string) (bool, error) {
 
 // wireFeatureIntoMainGo edits cmd/server/main.go in-place so the generated app
 // genuinely serves the feature: it instantiates the DI container (once) and
-// registers the feature's routes under an /api/v1 subrouter. It is idempotent.
-func wireFeatureIntoMainGo(mainPath, featureName, moduleName, content string) error {
+// registers the feature's routes under the subrouter of their API version,
+// e.g. /api/v1, deprecating the versions api.versioning retires. When main.go
+// has no wiringRoutesMarker the routes go to a RegisterRoutes function of the
+// main package instead, and without either it fails rather than leaving the
+// feature unrouted. It is idempotent.
+func wireFeatureIntoMainGo(mainPath, featureName, moduleName, version, content string) error {
 	featureLower := strings.ToLower(featureName)
 
+	if !strings.Contains(content, wiringRoutesMarker) {
+		src, fn, err := findRegisterRoutes(mainPath, content)
+		if err != nil {
+			return err
+		}
+		if fn != nil {
+			return wireFeatureIntoRegisterRoutes(src, fn, featureName, moduleName, version)
+		}
+	}
+
 	updated := content
 
 	// 1. Ensure required imports (di + handler http packages).
@@ -679,14 +1395,29 @@ func wireFeatureIntoMainGo(mainPath, featureName, moduleName, content string) er
 	// 2. Ensure the DI container + /api/v1 subrouter scaffold exist (once).
 	updated = ensureContainerScaffold(updated)
 
-	// 3. Register this feature's routes (idempotent).
-	routeCall := fmt.Sprintf("apphttp.Setup%sRoutes(apiRouter, container.%sUseCase())", featureName, featureName)
+	// 3. Register this feature's routes (idempotent), declaring the subrouter
+	// of a later version first.
+	updated = ensureVersionRouter(updated, version)
+	suffix := apiVersionSuffix(version)
+	routeCall := fmt.Sprintf("apphttp.Setup%s%sRoutes(%s, container.%sUseCase())", featureName, suffix, apiVersionRouter(version), featureName)
 	if !strings.Contains(updated, routeCall) {
 		marker := wiringRoutesMarker
-		insertion := fmt.Sprintf("\t%s // %s routes\n%s", routeCall, featureLower, marker)
+		if !strings.Contains(updated, marker) {
+			return fmt.Errorf("%s has neither the %q marker nor a RegisterRoutes function to add the routes to", mainPath, marker) //nolint:err113
+		}
+		comment := featureLower
+		if suffix != "" {
+			comment += " " + version
+		}
+		insertion := fmt.Sprintf("\t%s // %s routes\n%s", routeCall, comment, marker)
 		updated = strings.Replace(updated, marker, insertion, 1)
 	}
 
+	// 4. Deprecate the versions api.versioning retires.
+	if _, err := os.Stat(filepath.Join(DirInternal, DirHandler, DirHTTP, "deprecation.go")); err == nil {
+		updated = applyDeprecations(updated, projectVersioning())
+	}
+
 	if err := writeMainGoInPlace(mainPath, updated); err != nil {
 		return err
 	}
@@ -731,17 +1462,31 @@ func ensureMainGoImport(content, importLine string) string {
 	return content[:closeIdx] + "\n\t" + spec + content[closeIdx:]
 }
 
+// containerDBArg returns the main.go expression passed to di.NewContainer.
+// MongoDB projects expose a *mongo.Client named mongoClient (and no `db`
+// variable); the container's NewContainer takes a *mongo.Database, so the
+// handle must be derived from the client. Elasticsearch and DynamoDB projects
+// pass their esClient and dynamoClient as is, and every other backend exposes
+// a *gorm.DB named db that the container accepts directly.
+func containerDBArg(content string) string {
+	if strings.Contains(content, "\tdb ") {
+		return "db"
+	}
+	switch {
+	case strings.Contains(content, "mongoClient"):
+		return "mongoClient.Database(cfg.Database.Name)"
+	case strings.Contains(content, "esClient"):
+		return "esClient"
+	case strings.Contains(content, "dynamoClient"):
+		return "dynamoClient"
+	}
+	return "db"
+}
+
 // ensureContainerScaffold injects (once) the DI container instantiation and an
 // /api/v1 subrouter together with the route marker into main.go.
 func ensureContainerScaffold(content string) string {
-	// MongoDB projects expose a *mongo.Client named mongoClient (and no `db`
-	// variable); the container's NewContainer takes a *mongo.Database, so the
-	// handle must be derived from the client. Every other backend exposes a
-	// *gorm.DB named db that the container accepts directly.
-	dbArg := "db"
-	if strings.Contains(content, "mongoClient") && !strings.Contains(content, "\tdb ") {
-		dbArg = "mongoClient.Database(cfg.Database.Name)"
-	}
+	dbArg := containerDBArg(content)
 
 	if strings.Contains(content, fmt.Sprintf("container := di.NewContainer(%s)", dbArg)) {
 		return content
@@ -778,9 +1523,9 @@ func printManualIntegrationInstructions(featureName string) {
 	ui.Blank()
 	ui.Println("3. Add the feature routes:")
 	ui.Dim(fmt.Sprintf("      %sHandler := container.%sHandler()", featureLower, featureName))
-	ui.Dim(fmt.Sprintf("      router.HandleFunc(\"/api/v1/%ss\", %sHandler.Create%s).Methods(\"POST\")", featureLower, featureLower, featureName))
-	ui.Dim(fmt.Sprintf("      router.HandleFunc(\"/api/v1/%ss/{id}\", %sHandler.Get%s).Methods(\"GET\")", featureLower, featureLower, featureName))
-	ui.Dim(fmt.Sprintf("      router.HandleFunc(\"/api/v1/%ss/{id}\", %sHandler.Update%s).Methods(\"PUT\")", featureLower, featureLower, featureName))
-	ui.Dim(fmt.Sprintf("      router.HandleFunc(\"/api/v1/%ss/{id}\", %sHandler.Delete%s).Methods(\"DELETE\")", featureLower, featureLower, featureName))
-	ui.Dim(fmt.Sprintf("      router.HandleFunc(\"/api/v1/%ss\", %sHandler.List%ss).Methods(\"GET\")", featureLower, featureLower, featureName))
+	ui.Dim(fmt.Sprintf("      router.HandleFunc(\"/api/v1/%s\", %sHandler.Create%s).Methods(\"POST\")", toPlural(featureLower), featureLower, featureName))
+	ui.Dim(fmt.Sprintf("      router.HandleFunc(\"/api/v1/%s/{id}\", %sHandler.Get%s).Methods(\"GET\")", toPlural(featureLower), featureLower, featureName))
+	ui.Dim(fmt.Sprintf("      router.HandleFunc(\"/api/v1/%s/{id}\", %sHandler.Update%s).Methods(\"PUT\")", toPlural(featureLower), featureLower, featureName))
+	ui.Dim(fmt.Sprintf("      router.HandleFunc(\"/api/v1/%s/{id}\", %sHandler.Delete%s).Methods(\"DELETE\")", toPlural(featureLower), featureLower, featureName))
+	ui.Dim(fmt.Sprintf("      router.HandleFunc(\"/api/v1/%s\", %sHandler.List%s).Methods(\"GET\")", toPlural(featureLower), featureLower, toPlural(featureName)))
 }
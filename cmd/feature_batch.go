@@ -0,0 +1,463 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// featureBatchFile is the file read by goca feature --batch. The options at
+// the top apply to every feature that does not set them itself.
+type featureBatchFile struct {
+	Database            string `yaml:"database,omitempty"`
+	featureBatchOptions `yaml:",inline"`
+	Features            []featureBatchSpec `yaml:"features"`
+}
+
+// featureBatchSpec is a feature of the batch.
+type featureBatchSpec struct {
+	Name                string             `yaml:"name"`
+	Fields              featureBatchFields `yaml:"fields"`
+	featureBatchOptions `yaml:",inline"`
+}
+
+// featureBatchOptions are the feature flags a batch can set, globally or per
+// feature. Unset options fall back to the command line and .goca.yaml.
+type featureBatchOptions struct {
+	Handlers      string `yaml:"handlers,omitempty"`
+	Validation    *bool  `yaml:"validation,omitempty"`
+	BusinessRules *bool  `yaml:"business_rules,omitempty"`
+	Cache         *bool  `yaml:"cache,omitempty"`
+	Versioned     *bool  `yaml:"versioned,omitempty"`
+	Audit         *bool  `yaml:"audit,omitempty"`
+	Resilience    *bool  `yaml:"resilience,omitempty"`
+	CQRS          *bool  `yaml:"cqrs,omitempty"`
+}
+
+// featureBatchFields are the fields of a feature: a --fields string or a list
+// of name:type entries.
+type featureBatchFields string
+
+// UnmarshalYAML accepts a string or a list of strings.
+func (f *featureBatchFields) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*f = featureBatchFields(node.Value)
+		return nil
+	}
+	var fields []string
+	if err := node.Decode(&fields); err != nil {
+		return err
+	}
+	*f = featureBatchFields(strings.Join(fields, ","))
+	return nil
+}
+
+// batchFeature is a feature of the batch with its options resolved.
+type batchFeature struct {
+	name, fields, handlers                                               string
+	validation, businessRules, cache, versioned, audit, resilience, cqrs bool
+}
+
+// featureBatchExclusiveFlags are the feature flags that describe a single
+// feature or the whole project and have no meaning for a batch.
+var featureBatchExclusiveFlags = []string{
+	"fields", "finders", "skip", "only", "layers", "gateway", "multi-tenant", "tenant-isolation",
+	"middleware-types", "integration-tests", "mocks", "go-generate",
+}
+
+// loadFeatureBatch reads a batch file. Unknown keys are errors, so a typo in
+// an option does not silently generate the feature without it.
+func loadFeatureBatch(path string) (*featureBatchFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var batch featureBatchFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&batch); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if len(batch.Features) == 0 {
+		return nil, fmt.Errorf("%s lists no features", path)
+	}
+	return &batch, nil
+}
+
+// resolveFeatureBatch applies the batch defaults and then defaults, the
+// values of the command line and .goca.yaml, to every feature.
+func resolveFeatureBatch(batch *featureBatchFile, defaults featureBatchOptions) ([]batchFeature, error) {
+	pick := func(values ...*bool) bool {
+		for _, v := range values {
+			if v != nil {
+				return *v
+			}
+		}
+		return false
+	}
+
+	seen := make(map[string]bool, len(batch.Features))
+	features := make([]batchFeature, 0, len(batch.Features))
+	for i, spec := range batch.Features {
+		name := strings.TrimSpace(spec.Name)
+		if name == "" {
+			return nil, fmt.Errorf("feature %d has no name", i+1)
+		}
+		if seen[strings.ToLower(name)] {
+			return nil, fmt.Errorf("feature %s is listed twice", name)
+		}
+		seen[strings.ToLower(name)] = true
+
+		handlers := defaults.Handlers
+		for _, h := range []string{batch.Handlers, spec.Handlers} {
+			if h != "" {
+				handlers = h
+			}
+		}
+		features = append(features, batchFeature{
+			name:          name,
+			fields:        string(spec.Fields),
+			handlers:      handlers,
+			validation:    pick(spec.Validation, batch.Validation, defaults.Validation),
+			businessRules: pick(spec.BusinessRules, batch.BusinessRules, defaults.BusinessRules),
+			cache:         pick(spec.Cache, batch.Cache, defaults.Cache),
+			versioned:     pick(spec.Versioned, batch.Versioned, defaults.Versioned),
+			audit:         pick(spec.Audit, batch.Audit, defaults.Audit),
+			resilience:    pick(spec.Resilience, batch.Resilience, defaults.Resilience),
+			cqrs:          pick(spec.CQRS, batch.CQRS, defaults.CQRS),
+		})
+	}
+	return features, nil
+}
+
+// lockedWriter serializes writes, so the lines printed by features generated
+// in parallel do not interleave mid-line.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// generateFeatureBatch calls generate for every feature on up to workers
+// goroutines and returns how long each feature took. Generators that share a
+// file serialize on it with lockSharedFile.
+func generateFeatureBatch(features []batchFeature, workers int, generate func(batchFeature)) []time.Duration {
+	if workers < 1 {
+		workers = 1
+	}
+	durations := make([]time.Duration, len(features))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				start := time.Now()
+				generate(features[i])
+				durations[i] = time.Since(start)
+			}
+		}()
+	}
+	for i := range features {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return durations
+}
+
+// runFeatureBatch is goca feature --batch: it generates the layers of every
+// feature of the batch file in parallel, then integrates them one by one in
+// the order of the file and manages the dependencies once. With fromManifest
+// it is goca feature --from-manifest, which only generates the features that
+// changed since they were last generated.
+func runFeatureBatch(cmd *cobra.Command, path string, fromManifest bool) error {
+	mode := "--batch"
+	if fromManifest {
+		mode = "--from-manifest"
+	}
+	for _, name := range featureBatchExclusiveFlags {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s cannot be used with %s", name, mode)
+		}
+	}
+	if _, err := applyPreset(cmd, presetFeature); err != nil {
+		return err
+	}
+	database, _ := cmd.Flags().GetString("database")
+	handlers, _ := cmd.Flags().GetString("handlers")
+	validation, _ := cmd.Flags().GetBool("validation")
+	businessRules, _ := cmd.Flags().GetBool("business-rules")
+	cacheFlag, _ := cmd.Flags().GetBool("cache")
+	versioned, _ := cmd.Flags().GetBool("versioned")
+	audit, _ := cmd.Flags().GetBool("audit")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+	backup, _ := cmd.Flags().GetBool("backup")
+	parallel, _ := cmd.Flags().GetInt("parallel")
+	tracingFlag, _ := cmd.Flags().GetBool("tracing")
+	resilienceFlag, _ := cmd.Flags().GetBool("resilience")
+	cqrsFlag, _ := cmd.Flags().GetBool("cqrs")
+	projectionFlag, _ := cmd.Flags().GetBool("cqrs-projection")
+	contractsFlag, _ := cmd.Flags().GetBool("contracts")
+	diFlag, _ := cmd.Flags().GetString("di")
+	dtoStyleFlag, _ := cmd.Flags().GetString("dto-style")
+
+	batch, err := loadFeatureBatch(path)
+	if err != nil {
+		return err
+	}
+
+	configIntegration := NewConfigIntegration()
+	if err := configIntegration.LoadConfigForProject(); err != nil {
+		ui.Warning(fmt.Sprintf("Could not load configuration: %v", err))
+	}
+	configIntegration.MergeWithCLIFlags(map[string]interface{}{
+		"database":       database,
+		"handlers":       handlers,
+		"validation":     validation,
+		"business-rules": businessRules,
+	})
+	if batch.Database != "" {
+		database = batch.Database
+	}
+	effectiveDatabase := configIntegration.GetDatabaseType(database)
+	effectiveValidation := configIntegration.GetValidationEnabled(&validation)
+	effectiveBusinessRules := configIntegration.GetBusinessRulesEnabled(&businessRules)
+	effectiveTracing := configIntegration.GetTracingEnabled(tracingFlag)
+	effectiveResilience := configIntegration.GetResilienceEnabled(resilienceFlag)
+	effectiveCQRS := configIntegration.GetCQRSEnabled(cqrsFlag) || projectionFlag
+	effectiveProjection := projectionFlag || configIntegration.GetCQRSConfig().Projection
+	effectiveContracts := configIntegration.GetContractsEnabled(contractsFlag)
+	effectiveDI := configIntegration.GetDIType(diFlag)
+	if !isValidDIStyle(effectiveDI) {
+		return fmt.Errorf("invalid DI style %q; use one of: %s", effectiveDI, strings.Join(ValidDIStyles, ", "))
+	}
+	effectiveDTOStyle := configIntegration.GetDTOStyle(dtoStyleFlag)
+	if !isValidDTOStyle(effectiveDTOStyle) {
+		return fmt.Errorf("invalid DTO style %q; use one of: %s", effectiveDTOStyle, strings.Join(ValidDTOStyles, ", "))
+	}
+	fileNamingConvention := "lowercase"
+	if configIntegration.config != nil {
+		fileNamingConvention = configIntegration.GetNamingConvention("file")
+	}
+
+	features, err := resolveFeatureBatch(batch, featureBatchOptions{
+		Handlers:      strings.Join(configIntegration.GetHandlerTypes(handlers), ","),
+		Validation:    &effectiveValidation,
+		BusinessRules: &effectiveBusinessRules,
+		Cache:         &cacheFlag,
+		Versioned:     &versioned,
+		Audit:         &audit,
+		Resilience:    &effectiveResilience,
+		CQRS:          &effectiveCQRS,
+	})
+	if err != nil {
+		return err
+	}
+
+	// go generate runs --from-manifest whenever the generated code is
+	// refreshed, so the features generated with the same options are left
+	// untouched and the others overwrite their files.
+	var lock map[string]string
+	var listed []string
+	if fromManifest {
+		for _, f := range features {
+			listed = append(listed, f.name)
+		}
+		lock = loadManifestLock()
+		features = changedManifestFeatures(features, effectiveDatabase, lock, force)
+		force = true
+		if len(features) == 0 {
+			if !dryRun {
+				if err := updateManifestLock(lock, listed, nil, effectiveDatabase); err != nil {
+					return err
+				}
+			}
+			ui.Success(fmt.Sprintf("The features of %s are up to date", path))
+			return nil
+		}
+	}
+
+	// Check the whole batch before generating anything.
+	projectRoot, _ := os.Getwd()
+	conflictDetector := NewNameConflictDetector(projectRoot)
+	if err := conflictDetector.ScanExistingEntities(); err != nil {
+		ui.Warning(fmt.Sprintf("Could not scan for conflicts: %v", err))
+	}
+	validator := NewCommandValidator()
+	for _, f := range features {
+		if err := validator.ValidateFeatureCommand(f.name, f.fields, effectiveDatabase, f.handlers); err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+		if f.audit && !isGormDatabase(effectiveDatabase) {
+			return fmt.Errorf("%s: audit stores the audit log with GORM and does not support %s", f.name, effectiveDatabase)
+		}
+		if f.cqrs {
+			if err := validateCQRS(effectiveDatabase, false); err != nil {
+				return fmt.Errorf("%s: %w", f.name, err)
+			}
+		}
+		if err := conflictDetector.CheckNameConflict(f.name); err != nil && !force {
+			return fmt.Errorf("%w (use --force to generate anyway)", err)
+		}
+	}
+
+	workers := parallel
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, len(features))
+	// The profile attributes time to nested steps, which needs one feature
+	// at a time.
+	if profiler != nil {
+		workers = 1
+	}
+
+	if dryRun {
+		ui.DryRun("Previewing changes without creating files")
+	}
+	ui.Header(fmt.Sprintf("Generating %d features from %s", len(features), path))
+	ui.KeyValue("Database", effectiveDatabase)
+	ui.KeyValue("Workers", strconv.Itoa(workers))
+	if effectiveTracing {
+		ui.Feature("Including OpenTelemetry tracing", !tracingFlag)
+	}
+	if effectiveDI != DIManual {
+		ui.Feature(fmt.Sprintf("Dependency injection with %s", effectiveDI), diFlag == "")
+	}
+
+	safetyMgr := NewSafetyManager(dryRun, force, backup)
+
+	// Fields added to or removed from existing features of the manifest are
+	// applied first, so the shared files follow them.
+	if fromManifest {
+		for _, f := range features {
+			if err := syncManifestFields(f, effectiveDTOStyle, safetyMgr); err != nil {
+				return fmt.Errorf("%s: %w", f.name, err)
+			}
+		}
+	}
+
+	// 1-6. The layers of every feature, in parallel. Only the successes and
+	// errors of the generators are printed unless --verbose is given.
+	ui.Blank()
+	ui.Info("Generating layers...")
+	prevWriter, prevVerbosity := ui.writer, ui.verbosity
+	ui.writer = &lockedWriter{w: prevWriter}
+	if ui.verbosity < 2 {
+		ui.verbosity = 0
+	}
+	// The resilience package and the CQRS files are shared, so they are
+	// written before the workers.
+	for _, f := range features {
+		if f.resilience {
+			createResiliencePackage(configIntegration.GetResilienceConfig(), safetyMgr)
+			break
+		}
+	}
+	for _, f := range features {
+		if f.cqrs {
+			createCQRSFiles(effectiveDatabase, configIntegration.GetCQRSConfig(), safetyMgr)
+			break
+		}
+	}
+	durations := generateFeatureBatch(features, workers, func(f batchFeature) {
+		generateCompleteFeature(f.name, f.fields, effectiveDatabase, f.handlers, "", f.validation, f.businessRules, f.cache, f.versioned, f.audit, false, false, configIntegration.GetPresenterEnabled(false), fileNamingConvention, effectiveDTOStyle, allFeatureLayers(), safetyMgr)
+		if effectiveTracing {
+			generateUseCaseTracingDecorator(f.name, parseOperations("create,read,update,delete,list"), safetyMgr)
+		}
+		if f.resilience {
+			generateResilienceDecorator(f.name, safetyMgr)
+		}
+		if f.cqrs {
+			generateCQRS(f.name, f.fields, effectiveDatabase, effectiveDTOStyle, f.validation, effectiveProjection, safetyMgr)
+		}
+	})
+	ui.writer, ui.verbosity = prevWriter, prevVerbosity
+
+	// Contract tests and plugins edit project files such as the Makefile, so
+	// they run one feature at a time.
+	for _, f := range features {
+		if effectiveContracts && strings.Contains(f.handlers, HandlerHTTP) {
+			generateContractTests(f.name, safetyMgr)
+		}
+		runPluginHooks(f.name, safetyMgr, featurePluginHooks(allFeatureLayers())...)
+	}
+
+	rows := make([][]string, len(features))
+	for i, f := range features {
+		rows[i] = []string{f.name, strconv.Itoa(len(parseFields(f.fields))), f.handlers, durations[i].Round(time.Millisecond).String()}
+	}
+	ui.Blank()
+	ui.Table([]string{"Feature", "Fields", "Handlers", "Time"}, rows)
+
+	depMgr := NewDependencyManager(projectRoot, dryRun)
+	if dryRun {
+		safetyMgr.PrintSummary()
+		return nil
+	}
+
+	// 7. The DI container and main.go, in the order of the batch file.
+	ui.Step(7, "Integrating automatically...")
+	for _, f := range features {
+		autoIntegrateFeature(f.name, f.handlers, "", effectiveDatabase, effectiveDI, f.cache, safetyMgr)
+	}
+
+	// 8. The dependencies of all the features, once.
+	ui.Step(8, "Managing dependencies...")
+	added := make(map[string]bool)
+	for _, f := range features {
+		deps := depMgr.GetRequiredDependenciesForFeature(f.handlers, map[string]bool{
+			"validation": f.validation,
+			"tracing":    effectiveTracing,
+			"resilience": f.resilience,
+			DIWire:       effectiveDI == DIWire,
+			DIFx:         effectiveDI == DIFx,
+			"json":       fieldsUseType(parseFields(f.fields), FieldJSONType),
+			"contracts":  effectiveContracts && strings.Contains(f.handlers, HandlerHTTP),
+			"storage":    strings.Contains(f.handlers, HandlerHTTP) && fieldsUseType(parseFields(f.fields), FieldFileType),
+			"zstd":       strings.Contains(f.handlers, HandlerHTTP) && compressionUsesZstd(),
+		})
+		for _, dep := range deps {
+			if added[dep.Module] {
+				continue
+			}
+			added[dep.Module] = true
+			if err := depMgr.AddDependency(dep); err != nil {
+				ui.Warning(fmt.Sprintf("Could not add dependency %s: %v", dep.Module, err))
+			}
+		}
+	}
+	ui.Info("Updating go.mod...")
+	if err := depMgr.UpdateGoMod(); err != nil {
+		ui.Warning(fmt.Sprintf("Could not update go.mod: %v", err))
+		ui.Dim("Tip: Run 'go mod tidy' manually")
+	}
+
+	if fromManifest {
+		if err := updateManifestLock(lock, listed, features, effectiveDatabase); err != nil {
+			return err
+		}
+	}
+
+	ui.Success(fmt.Sprintf("%d features generated and integrated successfully!", len(features)))
+	ui.NextSteps(append([]string{"Run: go mod tidy"}, serverNextSteps(effectiveDI)...))
+	return nil
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFeatureBatch(t *testing.T) {
+	chdirTemp(t)
+	writeTestFile(t, ".", "features.yaml", `handlers: http,grpc
+validation: true
+features:
+  - name: Product
+    fields: "name:string,price:float64"
+  - name: Order
+    fields: [customer_id:int, total:float64]
+    handlers: http
+    validation: false
+    cache: true
+`)
+
+	batch, err := loadFeatureBatch("features.yaml")
+	require.NoError(t, err)
+	noAudit := false
+	features, err := resolveFeatureBatch(batch, featureBatchOptions{Handlers: "http", Audit: &noAudit})
+	require.NoError(t, err)
+	require.Len(t, features, 2)
+
+	assert.Equal(t, batchFeature{name: "Product", fields: "name:string,price:float64", handlers: "http,grpc", validation: true}, features[0])
+	assert.Equal(t, batchFeature{name: "Order", fields: "customer_id:int,total:float64", handlers: "http", cache: true}, features[1])
+
+	writeTestFile(t, ".", "typo.yaml", "features:\n  - name: Product\n    chache: true\n")
+	_, err = loadFeatureBatch("typo.yaml")
+	assert.ErrorContains(t, err, "field chache not found")
+
+	writeTestFile(t, ".", "empty.yaml", "handlers: http\n")
+	_, err = loadFeatureBatch("empty.yaml")
+	assert.ErrorContains(t, err, "lists no features")
+
+	_, err = resolveFeatureBatch(&featureBatchFile{Features: []featureBatchSpec{{Name: "Product"}, {Name: "product"}}}, featureBatchOptions{})
+	assert.ErrorContains(t, err, "listed twice")
+}
+
+// TestGenerateFeatureBatch_SharedFiles generates features in parallel and
+// checks that the files they share hold every feature exactly once. Run it
+// with -race to check the generators too.
+func TestGenerateFeatureBatch_SharedFiles(t *testing.T) {
+	defer setupDiscardUI(t)()
+	chdirTemp(t)
+
+	names := []string{"Product", "Order", "Customer", "Invoice", "Supplier", "Category", "Shipment", "Payment"}
+	features := make([]batchFeature, len(names))
+	for i, name := range names {
+		features[i] = batchFeature{name: name, fields: "name:string,amount:float64", handlers: "http", validation: true}
+	}
+	sm := NewSafetyManager(false, false, false)
+	durations := generateFeatureBatch(features, 4, func(f batchFeature) {
+		generateCompleteFeature(f.name, f.fields, "postgres", f.handlers, "", f.validation, false, false, false, false, false, false, false, "lowercase", DTOStyleNested, allFeatureLayers(), sm)
+	})
+	require.Len(t, durations, len(names))
+
+	shared := map[string]string{
+		filepath.Join("internal", "usecase", "dto.go"):            "type Create%sInput struct",
+		filepath.Join("internal", "repository", "interfaces.go"):  "type %sRepository interface",
+		filepath.Join("internal", "handler", "http", "routes.go"): "func Setup%sRoutes(",
+		filepath.Join("internal", "messages", "messages.go"):      "%sCreated ",
+		filepath.Join("internal", "messages", "responses.go"):     "%sCreatedSuccessfully",
+		filepath.Join("internal", "domain", "errors.go"):          "ErrInvalid%sName ",
+	}
+	for path, declaration := range shared {
+		content := readParsedGo(t, path)
+		for _, name := range names {
+			assert.Equal(t, 1, strings.Count(content, fmt.Sprintf(declaration, name)), "%s in %s", name, path)
+		}
+	}
+	for _, name := range names {
+		assert.FileExists(t, filepath.Join("internal", "domain", strings.ToLower(name)+".go"))
+	}
+}
+
+func TestFeatureCommand_BatchArgs(t *testing.T) {
+	require.NoError(t, featureCmd.Flags().Set("batch", "features.yaml"))
+	t.Cleanup(func() { _ = featureCmd.Flags().Set("batch", "") })
+	assert.NoError(t, featureCmd.Args(featureCmd, nil))
+	assert.Error(t, featureCmd.Args(featureCmd, []string{"Product"}))
+
+	require.NoError(t, featureCmd.Flags().Set("batch", ""))
+	assert.NoError(t, featureCmd.Args(featureCmd, []string{"Product"}))
+	assert.Error(t, featureCmd.Args(featureCmd, nil))
+}
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestIsFeatureAlreadyRegistered(t *testing.T) {
@@ -82,3 +85,105 @@ func TestFindMainGoPath(t *testing.T) {
 	_ = path
 	_ = found
 }
+
+func TestResolveFeatureLayers(t *testing.T) {
+	t.Parallel()
+	layers, err := resolveFeatureLayers("", "")
+	require.NoError(t, err)
+	assert.True(t, isFullFeature(layers))
+
+	layers, err = resolveFeatureLayers("handler, Messages", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{LayerDomain, LayerUseCase, LayerRepository}, selectedLayerNames(layers))
+	assert.False(t, canIntegrateFeature(layers))
+
+	layers, err = resolveFeatureLayers("", "usecase,domain")
+	require.NoError(t, err)
+	assert.Equal(t, []string{LayerDomain, LayerUseCase}, selectedLayerNames(layers))
+
+	_, err = resolveFeatureLayers("handler", "domain")
+	assert.ErrorContains(t, err, "cannot be used together")
+	_, err = resolveFeatureLayers("", "views")
+	assert.ErrorContains(t, err, `unknown layer "views"`)
+	_, err = resolveFeatureLayers("domain,usecase,repository,handler,messages", "")
+	assert.ErrorContains(t, err, "no layers left")
+}
+
+func TestGenerateCompleteFeature_OnlySelectedLayers(t *testing.T) {
+	cleanup := ensureTestUI(t)
+	defer cleanup()
+	chdirTemp(t)
+
+	layers, err := resolveFeatureLayers("", "domain,usecase")
+	require.NoError(t, err)
+	generateCompleteFeature("Product", "name:string,price:float64", "postgres", "http", "", true, false, false, false, false, false, false, false, "lowercase", DTOStyleNested, layers, NewSafetyManager(false, false, false))
+
+	assert.FileExists(t, filepath.Join("internal", "domain", "product.go"))
+	assert.FileExists(t, filepath.Join("internal", "usecase", "product_service.go"))
+	assert.NoFileExists(t, filepath.Join("internal", "repository", "postgres_product_repository.go"))
+	assert.Contains(t, readParsedGo(t, filepath.Join("internal", "repository", "interfaces.go")), "type ProductRepository interface {", "the use case gets its repository port")
+	assert.NoDirExists(t, filepath.Join("internal", "handler"))
+	// The use case wraps validation errors in the shared error kinds.
+	assert.FileExists(t, filepath.Join("internal", "messages", "errors.go"))
+
+	// A later handler-only run leaves the domain and use case untouched.
+	servicePath := filepath.Join("internal", "usecase", "product_service.go")
+	require.NoError(t, os.WriteFile(servicePath, []byte("package usecase\n// edited\n"), 0o644))
+	layers, err = resolveFeatureLayers("", "handler")
+	require.NoError(t, err)
+	generateCompleteFeature("Product", readEntityFieldsString("Product"), "postgres", "http", "", true, false, false, false, false, false, false, false, "lowercase", DTOStyleNested, layers, NewSafetyManager(false, true, false))
+
+	assert.FileExists(t, filepath.Join("internal", "handler", "http", "product_handler.go"))
+	service, err := os.ReadFile(servicePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(service), "// edited")
+}
+
+func TestCheckFeatureLayerDependencies(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	layers, err := resolveFeatureLayers("", "handler")
+	require.NoError(t, err)
+	assert.EqualError(t, checkFeatureLayerDependencies("Product", layers), "Product is missing layers: handler needs usecase; add them to --layers or generate them first")
+	layers, err = resolveFeatureLayers("", "usecase,repository")
+	require.NoError(t, err)
+	assert.ErrorContains(t, checkFeatureLayerDependencies("Product", layers), "usecase needs domain, repository needs domain;")
+
+	layers, err = resolveFeatureLayers("", "domain,usecase")
+	require.NoError(t, err)
+	assert.NoError(t, checkFeatureLayerDependencies("Product", layers), "a missing repository is generated as an interface")
+
+	sm := NewSafetyManager(false, true, false)
+	require.NoError(t, generateEntity("Product", "name:string", true, false, false, false, false, false, false, true, "lowercase", sm))
+	generateUseCaseWithFields("ProductService", "Product", "create,read,update,delete,list", false, false, "name:string", "", sm)
+	generateRepository("Product", DBPostgres, false, false, false, false, "name:string", sm)
+	layers, err = resolveFeatureLayers("", "handler")
+	require.NoError(t, err)
+	assert.NoError(t, checkFeatureLayerDependencies("Product", layers))
+	assert.True(t, canIntegrateFeature(presentFeatureLayers("Product", layers)), "the project has the other wired layers")
+}
+
+func TestAddFeatureToDIWithoutHTTPHandler(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	sm := NewSafetyManager(false, false, false)
+	writeTestFile(t, ".", "internal/handler/cli/task_commands.go", "package cli\n")
+	generateDI("Task", DBPostgres, DIManual, "", false, sm)
+	container := readParsedGo(t, filepath.Join("internal", "di", "container.go"))
+	assert.NotContains(t, container, "handler/http")
+	assert.Contains(t, container, "func (c *Container) TaskUseCase() usecase.TaskUseCase {")
+
+	writeTestFile(t, ".", "internal/handler/http/tag_handler.go", "package http\n\ntype TagHandler struct{}\n")
+	addFeatureToDI("Tag", DBPostgres, false, sm)
+	container = readParsedGo(t, filepath.Join("internal", "di", "container.go"))
+	assert.Contains(t, container, `"testproject/internal/handler/http"`)
+	assert.Contains(t, container, "c.tagHandler = http.NewTagHandler(c.tagUC)")
+
+	writeTestFile(t, ".", "internal/handler/worker/note_worker.go", "package worker\n")
+	addFeatureToDI("Note", DBPostgres, false, sm)
+	container = readParsedGo(t, filepath.Join("internal", "di", "container.go"))
+	assert.Contains(t, container, "func (c *Container) NoteUseCase() usecase.NoteUseCase {")
+	assert.NotContains(t, container, "NoteHandler")
+}
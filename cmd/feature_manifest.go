@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// featureManifestFile is the manifest goca feature --go-generate records
+// features in, in the format of --batch files.
+const featureManifestFile = "goca.features.yaml"
+
+// featureManifestLock records a hash of every feature of the manifest as it
+// was last generated, so goca feature --from-manifest skips unchanged ones.
+const featureManifestLock = ".goca/features.lock.json"
+
+// goGenerateFile holds the go:generate directive of the project. It runs
+// from internal/domain, so the directive moves goca to the project root.
+var goGenerateFile = filepath.Join(DirInternal, DirDomain, "generate.go")
+
+// goGenerateDirective is the content of goGenerateFile.
+const goGenerateDirective = `package domain
+
+// go generate ./... regenerates the features of goca.features.yaml that
+// changed since they were last generated, and leaves the others untouched.
+//go:generate goca -C ../.. feature --from-manifest ` + featureManifestFile + `
+`
+
+// manifestExclusiveFlags are the feature flags goca.features.yaml cannot
+// record, so --from-manifest regenerates the feature without them.
+var manifestExclusiveFlags = []string{
+	"skip", "only", "layers", "gateway", "multi-tenant", "tenant-isolation",
+	"middleware-types", "integration-tests", "mocks", "api-version",
+}
+
+// manifestFeatureHash identifies a feature of the manifest with its resolved
+// options and the database it is generated for.
+func manifestFeatureHash(f batchFeature, database string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%+v", database, f)))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadManifestLock reads featureManifestLock, keyed by feature name. A
+// missing or unreadable lock is empty.
+func loadManifestLock() map[string]string {
+	lock := make(map[string]string)
+	data, err := os.ReadFile(featureManifestLock)
+	if err != nil {
+		return lock
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		ui.Warning(fmt.Sprintf("Ignoring %s: %v", featureManifestLock, err))
+		return make(map[string]string)
+	}
+	return lock
+}
+
+// saveManifestLock writes featureManifestLock.
+func saveManifestLock(lock map[string]string) error {
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(featureManifestLock), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(featureManifestLock, append(data, '\n'), 0o644)
+}
+
+// changedManifestFeatures returns the features of the manifest to regenerate:
+// those whose hash differs from the lock, or all of them with force. A
+// feature missing from the lock whose entity already exists is adopted into
+// the lock instead, so a manifest written for an existing project does not
+// overwrite its code on the first go generate.
+func changedManifestFeatures(features []batchFeature, database string, lock map[string]string, force bool) []batchFeature {
+	var changed []batchFeature
+	for _, f := range features {
+		hash := manifestFeatureHash(f, database)
+		switch {
+		case force:
+		case lock[f.name] == hash:
+			continue
+		case lock[f.name] == "":
+			if _, err := findEntityFile(f.name); err == nil {
+				ui.Dim(fmt.Sprintf("   %s already exists; recorded as generated (use --force to regenerate it)", f.name))
+				lock[f.name] = hash
+				continue
+			}
+		}
+		changed = append(changed, f)
+	}
+	return changed
+}
+
+// readFeatureManifest reads the manifest at path, or returns an empty one
+// when it does not exist yet.
+func readFeatureManifest(path string) (*featureBatchFile, error) {
+	var manifest featureBatchFile
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &manifest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&manifest); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// manifestSpec returns the manifest entry of a generated feature. Every
+// option is written out, so the defaults of the manifest and of .goca.yaml
+// do not change how the feature is regenerated.
+func manifestSpec(f batchFeature) featureBatchSpec {
+	return featureBatchSpec{
+		Name:   f.name,
+		Fields: featureBatchFields(f.fields),
+		featureBatchOptions: featureBatchOptions{
+			Handlers:      f.handlers,
+			Validation:    &f.validation,
+			BusinessRules: &f.businessRules,
+			Cache:         &f.cache,
+			Versioned:     &f.versioned,
+			Audit:         &f.audit,
+			Resilience:    &f.resilience,
+			CQRS:          &f.cqrs,
+		},
+	}
+}
+
+// recordManifestFeature is goca feature --go-generate: it adds the feature to
+// goca.features.yaml, or replaces its entry, records it in the lock as
+// generated and writes the go:generate directive of the project.
+func recordManifestFeature(f batchFeature, database string, sm *SafetyManager) error {
+	manifest, err := readFeatureManifest(featureManifestFile)
+	if err != nil {
+		return err
+	}
+	spec := manifestSpec(f)
+	replaced := false
+	for i, existing := range manifest.Features {
+		if strings.EqualFold(strings.TrimSpace(existing.Name), f.name) {
+			manifest.Features[i] = spec
+			replaced = true
+		}
+	}
+	if !replaced {
+		manifest.Features = append(manifest.Features, spec)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(manifest); err != nil {
+		return err
+	}
+	if err := writeGoFileMerged(featureManifestFile, buf.String(), sm); err != nil {
+		return err
+	}
+
+	// The manifest may set the database for every feature.
+	if manifest.Database != "" {
+		database = manifest.Database
+	}
+	lock := loadManifestLock()
+	lock[f.name] = manifestFeatureHash(f, database)
+	if err := saveManifestLock(lock); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(goGenerateFile); errors.Is(err, os.ErrNotExist) {
+		return writeGoFile(goGenerateFile, goGenerateDirective, sm)
+	}
+	return nil
+}
+
+// unrecordedManifestFlags returns the flags given to goca feature that
+// goca.features.yaml cannot record.
+func unrecordedManifestFlags(cmd *cobra.Command) []string {
+	var flags []string
+	for _, name := range manifestExclusiveFlags {
+		if cmd.Flags().Changed(name) {
+			flags = append(flags, "--"+name)
+		}
+	}
+	return flags
+}
+
+// updateManifestLock records the regenerated features in the lock and drops
+// the features no longer listed in the manifest.
+func updateManifestLock(lock map[string]string, listed []string, generated []batchFeature, database string) error {
+	for _, f := range generated {
+		lock[f.name] = manifestFeatureHash(f, database)
+	}
+	keep := make(map[string]bool, len(listed))
+	for _, name := range listed {
+		keep[name] = true
+	}
+	var dropped []string
+	for name := range lock {
+		if !keep[name] {
+			dropped = append(dropped, name)
+			delete(lock, name)
+		}
+	}
+	if len(dropped) > 0 {
+		sort.Strings(dropped)
+		ui.Dim(fmt.Sprintf("   No longer in the manifest: %s (their code is kept)", strings.Join(dropped, ", ")))
+	}
+	return saveManifestLock(lock)
+}
+
+// syncManifestFields applies the fields of a changed feature whose entity
+// exists like goca field add and remove: fields no longer in the manifest,
+// or whose type changed, are removed and new ones added. Regenerating the
+// feature rewrites its own files, but the files it shares with other
+// features, such as dto.go, keep their declarations.
+func syncManifestFields(f batchFeature, dtoStyle string, sm *SafetyManager) error {
+	entityFile, err := findEntityFile(f.name)
+	if err != nil {
+		return nil // a new feature
+	}
+	src, err := parseGoSource(entityFile)
+	if err != nil {
+		return err
+	}
+	existing := readEntityFields(src.file, f.name)
+	validation := findMethod(src.file, f.name, "Validate") != nil
+	// parseFieldsWithValidation always prepends the ID field.
+	wanted := parseFieldsWithValidation(f.fields, validation)[1:]
+
+	current := make(map[string]string, len(existing))
+	for _, field := range existing {
+		current[field.Name] = field.Type
+	}
+	kept := make(map[string]bool, len(wanted))
+	var added []Field
+	for _, field := range wanted {
+		if current[field.Name] == field.Type {
+			kept[field.Name] = true
+		} else {
+			added = append(added, field)
+		}
+	}
+	var removed, remaining []Field
+	for _, field := range existing {
+		switch {
+		case isSystemField(field.Name):
+		case kept[field.Name]:
+			remaining = append(remaining, field)
+		default:
+			removed = append(removed, field)
+		}
+	}
+
+	if len(removed) > 0 {
+		ui.Dim(fmt.Sprintf("   Removing fields from %s: %s", f.name, strings.Join(sortedFieldNames(removed), ", ")))
+		if err := removeFieldsFromLayers(entityFile, f.name, removed, remaining, sm); err != nil {
+			return err
+		}
+	}
+	if len(added) > 0 {
+		ui.Dim(fmt.Sprintf("   Adding fields to %s: %s", f.name, strings.Join(sortedFieldNames(added), ", ")))
+		if err := addFieldsToLayers(entityFile, f.name, remaining, added, validation, dtoStyle, sm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortedFieldNames returns the names of fields, sorted.
+func sortedFieldNames(fields []Field) []string {
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.Name
+	}
+	sort.Strings(names)
+	return names
+}
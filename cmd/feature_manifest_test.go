@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordManifestFeature(t *testing.T) {
+	defer setupDiscardUI(t)()
+	chdirTemp(t)
+	sm := NewSafetyManager(false, false, false)
+
+	product := batchFeature{name: "Product", fields: "name:string,price:float64", handlers: "http", validation: true}
+	require.NoError(t, recordManifestFeature(product, "postgres", sm))
+	order := batchFeature{name: "Order", fields: "total:float64", handlers: "http,grpc", cache: true}
+	require.NoError(t, recordManifestFeature(order, "postgres", sm))
+	product.fields = "name:string,price:float64,sku:string"
+	require.NoError(t, recordManifestFeature(product, "postgres", sm))
+
+	directive := readParsedGo(t, goGenerateFile)
+	assert.Contains(t, directive, "//go:generate goca -C ../.. feature --from-manifest goca.features.yaml\n")
+
+	// Every option is recorded, so the defaults do not change the features.
+	batch, err := loadFeatureBatch(featureManifestFile)
+	require.NoError(t, err)
+	yes := true
+	features, err := resolveFeatureBatch(batch, featureBatchOptions{Handlers: "grpc", Audit: &yes, Cache: &yes})
+	require.NoError(t, err)
+	assert.Equal(t, []batchFeature{product, order}, features)
+
+	lock := loadManifestLock()
+	assert.Empty(t, changedManifestFeatures(features, "postgres", lock, false))
+	assert.Len(t, changedManifestFeatures(features, "postgres", lock, true), 2)
+	assert.Len(t, changedManifestFeatures(features, "mysql", lock, false), 2)
+
+	features[1].fields = "total:float64,notes:string"
+	assert.Equal(t, []batchFeature{features[1]}, changedManifestFeatures(features, "postgres", lock, false))
+
+	require.NoError(t, updateManifestLock(lock, []string{"Order"}, features[1:], "postgres"))
+	lock = loadManifestLock()
+	assert.NotContains(t, lock, "Product")
+	assert.Equal(t, manifestFeatureHash(features[1], "postgres"), lock["Order"])
+}
+
+func TestChangedManifestFeatures_AdoptsExistingEntities(t *testing.T) {
+	defer setupDiscardUI(t)()
+	chdirTemp(t)
+	writeTestFile(t, filepath.Join("internal", "domain"), "customer.go", "package domain\n\ntype Customer struct {\n\tID   uint\n\tName string\n}\n")
+
+	features := []batchFeature{
+		{name: "Customer", fields: "name:string", handlers: "http"},
+		{name: "Invoice", fields: "total:float64", handlers: "http"},
+	}
+	lock := map[string]string{}
+	changed := changedManifestFeatures(features, "postgres", lock, false)
+	assert.Equal(t, features[1:], changed)
+	assert.Equal(t, manifestFeatureHash(features[0], "postgres"), lock["Customer"])
+}
+
+func TestSyncManifestFields(t *testing.T) {
+	defer setupDiscardUI(t)()
+	chdirTemp(t)
+	sm := NewSafetyManager(false, true, false)
+
+	generateCompleteFeature("Product", "name:string,price:float64,code:string", "postgres", "http", "", false, false, false, false, false, false, false, false, "lowercase", DTOStyleNested, allFeatureLayers(), sm)
+
+	// price changes type, code is removed and stock added.
+	f := batchFeature{name: "Product", fields: "name:string,price:int64,stock:int", handlers: "http"}
+	require.NoError(t, syncManifestFields(f, DTOStyleNested, sm))
+
+	entity := readParsedGo(t, filepath.Join("internal", "domain", "product.go"))
+	assert.Contains(t, entity, "Price int64")
+	assert.Contains(t, entity, "Stock int")
+	assert.NotContains(t, entity, "Code ")
+	dto := readParsedGo(t, filepath.Join("internal", "usecase", "dto.go"))
+	assert.Contains(t, dto, "Stock int")
+	assert.NotContains(t, dto, "Code ")
+
+	// A new feature has nothing to sync.
+	assert.NoError(t, syncManifestFields(batchFeature{name: "Order", fields: "total:float64"}, DTOStyleNested, sm))
+}
+
+func TestFeatureCommand_FromManifestArgs(t *testing.T) {
+	require.NoError(t, featureCmd.Flags().Set("from-manifest", featureManifestFile))
+	t.Cleanup(func() { _ = featureCmd.Flags().Set("from-manifest", "") })
+	assert.NoError(t, featureCmd.Args(featureCmd, nil))
+	assert.Error(t, featureCmd.Args(featureCmd, []string{"Product"}))
+
+	require.NoError(t, featureCmd.Flags().Set("batch", "features.yaml"))
+	t.Cleanup(func() { _ = featureCmd.Flags().Set("batch", "") })
+	assert.ErrorContains(t, featureCmd.Args(featureCmd, nil), "cannot be used together")
+}
@@ -0,0 +1,870 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Backends of goca featureflags.
+const (
+	FlagsBackendFile   = "file"
+	FlagsBackendConsul = "consul"
+	FlagsBackendEtcd   = "etcd"
+)
+
+// ValidFlagsBackends are the accepted --backend values.
+var ValidFlagsBackends = []string{FlagsBackendFile, FlagsBackendConsul, FlagsBackendEtcd}
+
+// featureFlagsDir is the package reading the feature flags.
+var featureFlagsDir = filepath.Join(DirPkg, "featureflags")
+
+// runtimeConfigFile holds the flags of the file backend.
+const runtimeConfigFile = ".goca-runtime.yaml"
+
+// defaultFlagsRefresh is the reload interval without --refresh or
+// features.feature_flags.refresh.
+const defaultFlagsRefresh = "30s"
+
+var featureFlagsCmd = &cobra.Command{
+	Use:     "featureflags",
+	Aliases: []string{"flags"},
+	Short:   "Generate the feature flags and runtime settings package",
+	Long: `Generates pkg/featureflags, which reads feature flags and runtime settings
+and reloads them while the service runs, so features can ship dark and be
+turned on without a deploy:
+
+  - pkg/featureflags/featureflags.go  Flags, with the typed accessors Bool,
+                                       String, Int, Float64, Duration and the
+                                       percentage rollouts of EnabledFor
+  - pkg/featureflags/file.go          The YAML file backend (.goca-runtime.yaml)
+  - pkg/featureflags/consul.go        The Consul KV backend
+  - pkg/featureflags/etcd.go          The etcd v3 backend
+  - pkg/featureflags/env.go           FLAG_ environment variables, which
+                                       override every backend
+  - pkg/featureflags/open.go          Open, which reads the configured backend
+  - .goca-runtime.yaml                The flags of the file backend
+
+The backend is read from FEATURE_FLAGS_BACKEND (file, consul or etcd) and the
+flags are reloaded every FEATURE_FLAGS_REFRESH. The settings are added to
+pkg/config and to .env and .env.example. The defaults come from
+features.feature_flags in .goca.yaml:
+
+  features:
+    feature_flags:
+      backend: consul
+      refresh: 10s
+
+With --entity, internal/usecase/<entity>_feature_gate.go wraps the use case
+of the entity so that its creates are served only while the
+<entity>_create flag is on.
+
+Examples:
+  goca featureflags
+  goca featureflags --backend etcd --refresh 10s
+  goca featureflags --entity Order`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		backend, _ := cmd.Flags().GetString("backend")
+		refresh, _ := cmd.Flags().GetString("refresh")
+		entity, _ := cmd.Flags().GetString("entity")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		force, _ := cmd.Flags().GetBool("force")
+
+		ci := NewConfigIntegration()
+		_ = ci.LoadConfigForProject()
+		settings := ci.GetFeatureFlagsConfig()
+		if backend == "" {
+			backend = settings.Backend
+		}
+		if backend == "" {
+			backend = FlagsBackendFile
+		}
+		if !contains(ValidFlagsBackends, backend) {
+			return fmt.Errorf("unsupported --backend %q: use one of %s", backend, strings.Join(ValidFlagsBackends, ", "))
+		}
+		if refresh == "" {
+			refresh = settings.Refresh
+		}
+		if refresh == "" {
+			refresh = defaultFlagsRefresh
+		}
+		if d, err := time.ParseDuration(refresh); err != nil || d <= 0 {
+			return fmt.Errorf("invalid --refresh %q: use a positive duration such as 30s", refresh)
+		}
+
+		gate := false
+		if entity != "" {
+			ok, reason := featureGateEntity(entity)
+			if !ok {
+				return fmt.Errorf("cannot gate %s: %s", entity, reason)
+			}
+			gate = true
+		}
+
+		ui.Header("Goca Feature Flags")
+		ui.Blank()
+		ui.KeyValue("Backend", backend)
+		ui.KeyValue("Refresh", refresh)
+		if gate {
+			ui.KeyValue("Gated use case", fmt.Sprintf("%s create (flag %s)", entity, featureGateFlag(entity)))
+		}
+		ui.Blank()
+
+		sm := NewSafetyManager(dryRun, force, false)
+		generateFeatureFlagsPackage(backend, refresh, sm)
+		writeRuntimeConfig(entity, sm)
+		if gate {
+			generateFeatureGate(entity, sm)
+		}
+		if dryRun {
+			sm.PrintSummary()
+			return nil
+		}
+
+		projectRoot, _ := os.Getwd()
+		depMgr := NewDependencyManager(projectRoot, false)
+		for _, dep := range depMgr.GetRequiredDependenciesForFeature("", map[string]bool{"featureflags": true}) {
+			if err := depMgr.AddDependency(dep); err != nil {
+				ui.Warning(fmt.Sprintf("Could not add dependency %s: %v", dep.Module, err))
+			}
+		}
+
+		ui.Blank()
+		steps := []string{
+			"Open the flags in main.go: flags, err := featureflags.Open(ctx, cfg.FeatureFlags)",
+			"Reload them in the background: go flags.Watch(ctx, cfg.FeatureFlags.RefreshInterval)",
+		}
+		if backend == FlagsBackendFile {
+			steps = append(steps, "Edit "+runtimeConfigFile+" to turn flags on and off while the service runs")
+		} else {
+			steps = append(steps, fmt.Sprintf("Store the flags in %s under FEATURE_FLAGS_PREFIX, one key per flag", backend))
+		}
+		if gate {
+			steps = append(steps, fmt.Sprintf("Wrap the use case in internal/di: usecase.New%sFeatureGate(uc, flags)", entity))
+		}
+		ui.NextSteps(steps)
+		return nil
+	},
+}
+
+// featureGateEntity reports whether the creates of entity can be gated, or
+// why not.
+func featureGateEntity(entity string) (bool, string) {
+	if _, err := findEntityFile(entity); err != nil {
+		return false, fmt.Sprintf("no %s entity", entity)
+	}
+	if !contains(useCaseOperations(entity), "create") {
+		return false, fmt.Sprintf("%sUseCase declares no Create%s", entity, entity)
+	}
+	return true, ""
+}
+
+// featureGateFlag is the flag turning the creates of entity on and off.
+func featureGateFlag(entity string) string {
+	return toSnakeCase(entity) + "_create"
+}
+
+// generateFeatureFlagsPackage writes pkg/featureflags and adds its settings
+// to pkg/config and the .env files. Like pkg/notification, each file is only
+// rewritten with --force, so a backend can be edited or removed.
+func generateFeatureFlagsPackage(backend, refresh string, sm *SafetyManager) {
+	importPath := getImportPath(getModuleName())
+	files := []struct{ name, content string }{
+		{"featureflags.go", featureFlagsTemplate},
+		{"file.go", featureFlagsFileTemplate},
+		{"env.go", featureFlagsEnvTemplate},
+		{"consul.go", featureFlagsConsulTemplate},
+		{"etcd.go", featureFlagsEtcdTemplate},
+		{"open.go", fmt.Sprintf(featureFlagsOpenTemplate, importPath)},
+	}
+	for _, file := range files {
+		writeOnce(filepath.Join(featureFlagsDir, file.name), file.content, file.name, sm)
+	}
+
+	address := flagsBackendAddress(backend)
+	prefix := path.Base(getModuleName()) + "/flags"
+	load := fmt.Sprintf(featureFlagsConfigLoad, backend, runtimeConfigFile, address, prefix, refresh)
+	if err := ensureConfigSection("FeatureFlags", "FeatureFlagsConfig", featureFlagsConfigDecl, load, sm); err != nil {
+		ui.Warning(fmt.Sprintf("Could not add the feature flags settings to pkg/config: %v", err))
+	}
+	block := fmt.Sprintf(featureFlagsEnvBlock, backend, runtimeConfigFile, address, prefix, refresh)
+	for _, name := range []string{".env", ".env.example"} {
+		if err := appendEnvBlock(name, "FEATURE_FLAGS_BACKEND", block, sm); err != nil {
+			ui.Warning(fmt.Sprintf("Could not add the feature flags settings to %s: %v", name, err))
+		}
+	}
+}
+
+// flagsBackendAddress is the default address of the server of backend.
+func flagsBackendAddress(backend string) string {
+	switch backend {
+	case FlagsBackendConsul:
+		return "http://localhost:8500"
+	case FlagsBackendEtcd:
+		return "http://localhost:2379"
+	}
+	return ""
+}
+
+// writeRuntimeConfig writes the .goca-runtime.yaml read by the file backend,
+// with the gate flag of entity when given. An existing file is kept unless
+// --force is given.
+func writeRuntimeConfig(entity string, sm *SafetyManager) {
+	if _, err := os.Stat(runtimeConfigFile); err == nil && !sm.Force {
+		return
+	}
+	var flags string
+	if entity != "" {
+		flags = fmt.Sprintf("# Serves Create%s; turn it off to stop creates without a deploy.\n%s: true\n", entity, featureGateFlag(entity))
+	} else {
+		flags = "# A dark feature, off until turned on.\nnew_checkout: false\n"
+	}
+	if err := writeFile(runtimeConfigFile, runtimeConfigHeader+flags+runtimeConfigExample, sm); err != nil {
+		ui.Error(fmt.Sprintf("Error writing %s: %v", runtimeConfigFile, err))
+	}
+}
+
+// generateFeatureGate writes internal/usecase/<entity>_feature_gate.go, the
+// use case decorator serving creates only while the gate flag is on.
+func generateFeatureGate(entity string, sm *SafetyManager) {
+	filename := filepath.Join(DirInternal, DirUseCase, toSnakeCase(entity)+"_feature_gate.go")
+	content := fmt.Sprintf(featureGateTemplate, getImportPath(getModuleName()), entity, strings.ToLower(entity), featureGateFlag(entity), strings.ReplaceAll(toSnakeCase(entity), "_", " "))
+	writeOnce(filename, content, "feature gate", sm)
+}
+
+func init() {
+	featureFlagsCmd.Flags().String("backend", "", "Default backend: file, consul or etcd (features.feature_flags.backend, file by default)")
+	featureFlagsCmd.Flags().String("refresh", "", "Default reload interval (features.feature_flags.refresh, 30s by default)")
+	featureFlagsCmd.Flags().String("entity", "", "Entity whose creates are gated by the <entity>_create flag")
+	featureFlagsCmd.Flags().Bool("dry-run", false, "Preview changes without creating files")
+	featureFlagsCmd.Flags().Bool("force", false, "Overwrite existing files without asking")
+}
+
+// featureFlagsConfigDecl declares the feature flags settings of pkg/config.
+const featureFlagsConfigDecl = `// FeatureFlagsConfig selects where feature flags are read from.
+type FeatureFlagsConfig struct {
+	// Backend is file, consul or etcd. FLAG_ environment variables override
+	// every backend.
+	Backend string
+	// File is the YAML file of the file backend.
+	File string
+	// Address, Prefix and Token reach the flags in Consul or etcd.
+	Address string
+	Prefix  string
+	Token   string
+	// RefreshInterval is how often Watch reloads the flags.
+	RefreshInterval time.Duration
+}
+`
+
+// featureFlagsConfigLoad is the FeatureFlags element of the Config literal
+// Load returns, formatted with the backend, the file, the address, the
+// prefix and the refresh interval.
+const featureFlagsConfigLoad = `		FeatureFlags: FeatureFlagsConfig{
+			Backend:         getEnv("FEATURE_FLAGS_BACKEND", %q),
+			File:            getEnv("FEATURE_FLAGS_FILE", %q),
+			Address:         getEnv("FEATURE_FLAGS_ADDRESS", %q),
+			Prefix:          getEnv("FEATURE_FLAGS_PREFIX", %q),
+			Token:           getEnv("FEATURE_FLAGS_TOKEN", ""),
+			RefreshInterval: getEnvAsDuration("FEATURE_FLAGS_REFRESH", %q),
+		},
+`
+
+// featureFlagsEnvBlock is appended to the .env files, formatted like
+// featureFlagsConfigLoad.
+const featureFlagsEnvBlock = `# Feature flags (file, consul or etcd); FLAG_<NAME> overrides a flag
+FEATURE_FLAGS_BACKEND=%s
+FEATURE_FLAGS_FILE=%s
+FEATURE_FLAGS_ADDRESS=%s
+FEATURE_FLAGS_PREFIX=%s
+FEATURE_FLAGS_TOKEN=
+FEATURE_FLAGS_REFRESH=%s
+`
+
+const runtimeConfigHeader = `# Feature flags and runtime settings, reloaded while the service runs
+# (pkg/featureflags). Nested keys are read with dots, e.g. limits.page_size.
+# FLAG_<NAME> environment variables override them.
+
+`
+
+const runtimeConfigExample = `
+# A feature rolled out to a stable share of the users, see EnabledFor.
+new_search: "10%"
+
+limits:
+  page_size: 50
+`
+
+const featureFlagsTemplate = `// Package featureflags reads feature flags and runtime settings from layered
+// sources and reloads them while the service runs, so features can ship dark
+// and be turned on without a deploy.
+package featureflags
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Source loads flag values by name. Names are lower case and nested keys are
+// joined with dots, e.g. "limits.page_size".
+type Source interface {
+	Name() string
+	Load(ctx context.Context) (map[string]string, error)
+}
+
+// Flags holds the values of its sources; later sources override earlier
+// ones. It is safe for concurrent use.
+type Flags struct {
+	sources []Source
+
+	mu        sync.RWMutex
+	values    map[string]string
+	listeners []func(changed []string)
+}
+
+// New returns the flags of sources, empty until Reload is called.
+func New(sources ...Source) *Flags {
+	return &Flags{sources: sources, values: map[string]string{}}
+}
+
+// Reload loads every source and replaces the values. When a source fails,
+// the previous values are kept and the error is returned. The listeners of
+// OnChange are called with the names whose value changed.
+func (f *Flags) Reload(ctx context.Context) error {
+	values := make(map[string]string)
+	for _, source := range f.sources {
+		loaded, err := source.Load(ctx)
+		if err != nil {
+			return fmt.Errorf("load feature flags from %s: %w", source.Name(), err)
+		}
+		for name, value := range loaded {
+			values[normalize(name)] = value
+		}
+	}
+
+	f.mu.Lock()
+	changed := changedNames(f.values, values)
+	f.values = values
+	listeners := append([]func([]string){}, f.listeners...)
+	f.mu.Unlock()
+
+	if len(changed) > 0 {
+		for _, listener := range listeners {
+			listener(changed)
+		}
+	}
+	return nil
+}
+
+// Watch reloads the flags every interval until ctx is done. A failed reload
+// is logged and keeps the previous values.
+func (f *Flags) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := f.Reload(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Feature flags not reloaded: %v", err)
+			}
+		}
+	}
+}
+
+// OnChange registers fn, called after each reload that changes values.
+func (f *Flags) OnChange(fn func(changed []string)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.listeners = append(f.listeners, fn)
+}
+
+// Lookup returns the raw value of name.
+func (f *Flags) Lookup(name string) (string, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	value, ok := f.values[normalize(name)]
+	return value, ok
+}
+
+// Snapshot returns a copy of the current values.
+func (f *Flags) Snapshot() map[string]string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	values := make(map[string]string, len(f.values))
+	for name, value := range f.values {
+		values[name] = value
+	}
+	return values
+}
+
+// Enabled reports whether the boolean flag name is on. Unset and invalid
+// values are off.
+func (f *Flags) Enabled(name string) bool {
+	return f.Bool(name, false)
+}
+
+// EnabledFor reports whether name is on for key, e.g. a user ID. A value
+// such as "25%" turns the flag on for a stable quarter of the keys; a
+// boolean applies to every key.
+func (f *Flags) EnabledFor(name, key string) bool {
+	value, ok := f.Lookup(name)
+	if !ok {
+		return false
+	}
+	if strings.HasSuffix(value, "%") {
+		percent, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, "%")), 64)
+		if err != nil {
+			return false
+		}
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(normalize(name) + ":" + key))
+		return float64(h.Sum32()%10000) < percent*100
+	}
+	enabled, _ := strconv.ParseBool(value)
+	return enabled
+}
+
+// Bool returns the boolean flag name, or def when it is unset or invalid.
+func (f *Flags) Bool(name string, def bool) bool {
+	value, ok := f.Lookup(name)
+	if !ok {
+		return def
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+// String returns the value of name, or def when it is unset.
+func (f *Flags) String(name, def string) string {
+	if value, ok := f.Lookup(name); ok {
+		return value
+	}
+	return def
+}
+
+// Int returns the integer value of name, or def when it is unset or invalid.
+func (f *Flags) Int(name string, def int) int {
+	value, ok := f.Lookup(name)
+	if !ok {
+		return def
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return def
+	}
+	return i
+}
+
+// Float64 returns the number value of name, or def when it is unset or
+// invalid.
+func (f *Flags) Float64(name string, def float64) float64 {
+	value, ok := f.Lookup(name)
+	if !ok {
+		return def
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// Duration returns the duration value of name, e.g. "1m30s", or def when it
+// is unset or invalid.
+func (f *Flags) Duration(name string, def time.Duration) time.Duration {
+	value, ok := f.Lookup(name)
+	if !ok {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return def
+	}
+	return d
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// changedNames returns the sorted names whose value differs between old and
+// current.
+func changedNames(old, current map[string]string) []string {
+	var changed []string
+	for name, value := range current {
+		if previous, ok := old[name]; !ok || previous != value {
+			changed = append(changed, name)
+		}
+	}
+	for name := range old {
+		if _, ok := current[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+`
+
+const featureFlagsFileTemplate = `package featureflags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileSource reads flags from a YAML file such as .goca-runtime.yaml. Nested
+// mappings are joined with dots, and a missing file has no flags.
+type FileSource struct {
+	Path string
+}
+
+// NewFileSource returns the source of the YAML file at path.
+func NewFileSource(path string) *FileSource {
+	return &FileSource{Path: path}
+}
+
+func (s *FileSource) Name() string {
+	return s.Path
+}
+
+func (s *FileSource) Load(context.Context) (map[string]string, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", s.Path, err)
+	}
+	values := make(map[string]string)
+	flatten("", doc, values)
+	return values, nil
+}
+
+// flatten stores the values of doc in values, joining nested keys with dots.
+func flatten(prefix string, doc map[string]interface{}, values map[string]string) {
+	for key, value := range doc {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flatten(name, v, values)
+		case nil:
+		default:
+			values[name] = fmt.Sprint(v)
+		}
+	}
+}
+`
+
+const featureFlagsEnvTemplate = `package featureflags
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// EnvSource reads flags from the environment variables starting with
+// Prefix: with the FLAG_ prefix, FLAG_NEW_CHECKOUT=true sets new_checkout and
+// a double underscore nests, FLAG_LIMITS__PAGE_SIZE=100 sets
+// limits.page_size.
+type EnvSource struct {
+	Prefix string
+}
+
+// NewEnvSource returns the source of the variables starting with prefix.
+func NewEnvSource(prefix string) *EnvSource {
+	return &EnvSource{Prefix: prefix}
+}
+
+func (s *EnvSource) Name() string {
+	return "environment"
+}
+
+func (s *EnvSource) Load(context.Context) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, variable := range os.Environ() {
+		name, value, _ := strings.Cut(variable, "=")
+		if !strings.HasPrefix(name, s.Prefix) {
+			continue
+		}
+		name = strings.ReplaceAll(strings.TrimPrefix(name, s.Prefix), "__", ".")
+		if name == "" {
+			continue
+		}
+		values[strings.ToLower(name)] = value
+	}
+	return values, nil
+}
+`
+
+const featureFlagsConsulTemplate = `package featureflags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ConsulSource reads flags from the Consul KV store under Prefix: the key
+// <prefix>/limits/page_size sets limits.page_size.
+type ConsulSource struct {
+	Address string
+	Prefix  string
+	// Token is the ACL token, sent when set.
+	Token  string
+	Client *http.Client
+}
+
+// NewConsulSource returns the source of the keys under prefix in the Consul
+// agent at address, e.g. http://localhost:8500.
+func NewConsulSource(address, prefix, token string) *ConsulSource {
+	return &ConsulSource{
+		Address: strings.TrimRight(address, "/"),
+		Prefix:  strings.Trim(prefix, "/"),
+		Token:   token,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *ConsulSource) Name() string {
+	return "consul " + s.Address
+}
+
+func (s *ConsulSource) Load(ctx context.Context) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.Address+"/v1/kv/"+s.Prefix+"/?recurse=true", nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.Token != "" {
+		req.Header.Set("X-Consul-Token", s.Token)
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	values := make(map[string]string)
+	if resp.StatusCode == http.StatusNotFound {
+		return values, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("consul: %s", resp.Status)
+	}
+	var pairs []struct {
+		Key   string
+		Value []byte
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return nil, fmt.Errorf("consul: %w", err)
+	}
+	for _, pair := range pairs {
+		name := strings.Trim(strings.TrimPrefix(pair.Key, s.Prefix), "/")
+		if name == "" || strings.HasSuffix(pair.Key, "/") {
+			continue
+		}
+		values[strings.ReplaceAll(name, "/", ".")] = string(pair.Value)
+	}
+	return values, nil
+}
+`
+
+const featureFlagsEtcdTemplate = `package featureflags
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// EtcdSource reads flags from etcd under Prefix through the JSON gateway of
+// its v3 API: the key <prefix>/limits/page_size sets limits.page_size.
+type EtcdSource struct {
+	Address string
+	Prefix  string
+	// Token is the token of /v3/auth/authenticate, sent when set.
+	Token  string
+	Client *http.Client
+}
+
+// NewEtcdSource returns the source of the keys under prefix in the etcd
+// server at address, e.g. http://localhost:2379.
+func NewEtcdSource(address, prefix, token string) *EtcdSource {
+	return &EtcdSource{
+		Address: strings.TrimRight(address, "/"),
+		Prefix:  strings.Trim(prefix, "/"),
+		Token:   token,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *EtcdSource) Name() string {
+	return "etcd " + s.Address
+}
+
+func (s *EtcdSource) Load(ctx context.Context) (map[string]string, error) {
+	prefix := []byte(s.Prefix + "/")
+	body, err := json.Marshal(map[string][]byte{"key": prefix, "range_end": prefixEnd(prefix)})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Address+"/v3/kv/range", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Token != "" {
+		req.Header.Set("Authorization", s.Token)
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("etcd: %s", resp.Status)
+	}
+
+	var out struct {
+		Kvs []struct {
+			Key   []byte ` + "`json:\"key\"`" + `
+			Value []byte ` + "`json:\"value\"`" + `
+		} ` + "`json:\"kvs\"`" + `
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("etcd: %w", err)
+	}
+	values := make(map[string]string, len(out.Kvs))
+	for _, kv := range out.Kvs {
+		name := strings.TrimPrefix(string(kv.Key), string(prefix))
+		values[strings.ReplaceAll(name, "/", ".")] = string(kv.Value)
+	}
+	return values, nil
+}
+
+// prefixEnd is the end of the range of the keys starting with prefix.
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return []byte{0}
+}
+`
+
+const featureFlagsOpenTemplate = `package featureflags
+
+import (
+	"context"
+	"fmt"
+
+	"%[1]s/pkg/config"
+)
+
+// EnvPrefix starts the environment variables overriding every backend.
+const EnvPrefix = "FLAG_"
+
+// Open returns the flags of the backend of cfg, overridden by the FLAG_
+// environment variables, with their first values loaded. Run Watch to
+// reload them.
+func Open(ctx context.Context, cfg config.FeatureFlagsConfig) (*Flags, error) {
+	var backend Source
+	switch cfg.Backend {
+	case "", "file":
+		backend = NewFileSource(cfg.File)
+	case "consul":
+		backend = NewConsulSource(cfg.Address, cfg.Prefix, cfg.Token)
+	case "etcd":
+		backend = NewEtcdSource(cfg.Address, cfg.Prefix, cfg.Token)
+	default:
+		return nil, fmt.Errorf("unknown feature flags backend %%q", cfg.Backend)
+	}
+
+	flags := New(backend, NewEnvSource(EnvPrefix))
+	if err := flags.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+`
+
+// featureGateTemplate is formatted with the import path, the entity, its
+// lower case name, the gate flag and the entity in words.
+const featureGateTemplate = `package usecase
+
+import (
+	"fmt"
+
+	"%[1]s/internal/messages"
+	"%[1]s/pkg/featureflags"
+)
+
+// %[2]sCreateFlag turns the creates of %[2]s on and off.
+const %[2]sCreateFlag = %[4]q
+
+// %[3]sFeatureGate serves the %[2]s use case behind feature flags.
+type %[3]sFeatureGate struct {
+	%[2]sUseCase
+	flags *featureflags.Flags
+}
+
+// New%[2]sFeatureGate wraps inner so that Create%[2]s is served only while
+// the %[4]s flag is on, which it is when unset. Turning it off rejects
+// creates as forbidden without a deploy; gate a new code path the same way
+// to ship it dark.
+func New%[2]sFeatureGate(inner %[2]sUseCase, flags *featureflags.Flags) %[2]sUseCase {
+	return &%[3]sFeatureGate{%[2]sUseCase: inner, flags: flags}
+}
+
+func (g *%[3]sFeatureGate) Create%[2]s(input Create%[2]sInput) (Create%[2]sOutput, error) {
+	if !g.flags.Bool(%[2]sCreateFlag, true) {
+		return Create%[2]sOutput{}, fmt.Errorf("%%w: creating %[5]s records is disabled", messages.ErrForbidden)
+	}
+	return g.%[2]sUseCase.Create%[2]s(input)
+}
+`
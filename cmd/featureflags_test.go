@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateFeatureFlags(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	writeTestFile(t, ".", "go.mod", "module example.com/shop\n\ngo 1.21\n")
+	writeTestFile(t, ".", filepath.Join("pkg", "config", "config.go"), legacyConfig)
+	writeTestFile(t, ".", "internal/domain/order_item.go", "package domain\n\ntype OrderItem struct {\n\tID  uint\n\tSKU string\n}\n")
+	writeTestFile(t, ".", "internal/usecase/order_item_usecase.go", "package usecase\n\ntype OrderItemUseCase interface {\n\tCreateOrderItem(input CreateOrderItemInput) (CreateOrderItemOutput, error)\n}\n")
+	writeTestFile(t, ".", ".env", "PORT=8080\n")
+
+	ok, _ := featureGateEntity("OrderItem")
+	assert.True(t, ok)
+	_, reason := featureGateEntity("Tag")
+	assert.Equal(t, "no Tag entity", reason)
+
+	sm := NewSafetyManager(false, false, false)
+	generateFeatureFlagsPackage(FlagsBackendConsul, "10s", sm)
+	writeRuntimeConfig("OrderItem", sm)
+	generateFeatureGate("OrderItem", sm)
+
+	flags := readParsedGo(t, filepath.Join(featureFlagsDir, "featureflags.go"))
+	assert.Contains(t, flags, "func (f *Flags) EnabledFor(name, key string) bool {")
+	assert.Contains(t, readParsedGo(t, filepath.Join(featureFlagsDir, "open.go")), `"example.com/shop/pkg/config"`)
+	assert.Contains(t, readParsedGo(t, filepath.Join(featureFlagsDir, "etcd.go")), `s.Address+"/v3/kv/range"`)
+	for _, name := range []string{"file.go", "env.go", "consul.go"} {
+		readParsedGo(t, filepath.Join(featureFlagsDir, name))
+	}
+
+	config := readParsedGo(t, filepath.Join(DirPkg, DirConfig, "config.go"))
+	assert.Contains(t, config, `Backend:         getEnv("FEATURE_FLAGS_BACKEND", "consul"),`)
+	assert.Contains(t, config, `Address:         getEnv("FEATURE_FLAGS_ADDRESS", "http://localhost:8500"),`)
+	assert.Contains(t, config, `RefreshInterval: getEnvAsDuration("FEATURE_FLAGS_REFRESH", "10s"),`)
+
+	runtime, err := os.ReadFile(runtimeConfigFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(runtime), "\norder_item_create: true\n")
+
+	gate := readParsedGo(t, filepath.Join(DirInternal, DirUseCase, "order_item_feature_gate.go"))
+	assert.Contains(t, gate, "func NewOrderItemFeatureGate(inner OrderItemUseCase, flags *featureflags.Flags) OrderItemUseCase {")
+	assert.Contains(t, gate, `const OrderItemCreateFlag = "order_item_create"`)
+	assert.Contains(t, gate, `fmt.Errorf("%w: creating order item records is disabled", messages.ErrForbidden)`)
+
+	// A rerun keeps the edited files and does not append the .env block twice.
+	writeTestFile(t, ".", runtimeConfigFile, "order_item_create: false\n")
+	generateFeatureFlagsPackage(FlagsBackendConsul, "10s", sm)
+	writeRuntimeConfig("OrderItem", sm)
+	runtime, err = os.ReadFile(runtimeConfigFile)
+	require.NoError(t, err)
+	assert.Equal(t, "order_item_create: false\n", string(runtime))
+	env, err := os.ReadFile(".env")
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(env), "FEATURE_FLAGS_BACKEND=consul"))
+
+	cm := NewConfigManager()
+	cm.validateFeatures(&FeatureConfig{FeatureFlags: FeatureFlagsConfig{Backend: "redis", Refresh: "soon"}})
+	assert.Len(t, cm.GetErrors(), 2)
+}
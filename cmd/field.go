@@ -0,0 +1,602 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var fieldCmd = &cobra.Command{
+	Use:   "field",
+	Short: "Add, rename or remove fields of an existing entity",
+	Long: `Evolves an existing feature one field at a time instead of regenerating it.
+The entity, its validation, errors, seeds and tests, the DTOs and use case
+mappings, and the repository finders, cache decorator and mocks are edited in
+place, leaving the rest of each file untouched.`,
+}
+
+var fieldAddCmd = &cobra.Command{
+	Use:   "add <entity> <fields>",
+	Short: "Add fields to an existing entity",
+	Long: `Adds fields to an existing entity and wires them through every generated
+layer:
+
+  - entity struct, Validate checks, validation errors and nested struct types
+  - Go and SQL seeds, and the entity tests
+  - create, output and update DTOs, and the service's create and update mappings
+  - repository finders for searchable fields (email, name, code, ...) and
+    SumBy/MaxBy aggregates for numeric fields, in the interfaces, every
+    implementation, the cache decorator and the mocks
+  - the conditions of the repository's <Entity>Criteria
+
+Fields use the same syntax as --fields; several fields can be given as a
+comma-separated list or as separate arguments. With --migrate an ALTER TABLE
+migration and its rollback are written to the migrations directory.
+
+With --expand-contract, NOT NULL and unique columns are added without
+downtime: an expand migration adds them as nullable columns, a data migration
+backfills the existing rows in batches, and an enforce migration adding the
+constraints is staged until goca migrate advance moves it into the migrations.
+
+Examples:
+  goca field add User phone:string --migrate
+  goca field add User tier:string --expand-contract
+  goca field add Product sku:string,weight:float64
+  goca field add Order notes:*string --dry-run`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runFieldAdd,
+}
+
+var fieldRenameCmd = &cobra.Command{
+	Use:   "rename <entity> <field> <new-name[:type]>",
+	Short: "Rename a field of an existing entity or change its type",
+	Long: `Renames a field in every generated layer, like goca field remove followed by
+goca field add, while the entity's own methods keep using the renamed field.
+A type after the new name, e.g. price_cents:int64, also changes the type.
+
+With --migrate a migration renames the column, or copies it into a column of
+the new type. With --expand-contract the change is split into phases that keep
+both the running and the next version of the application working:
+
+  expand    add the new column as nullable       migrations, applied now
+  backfill  copy the old column in batches       go run ./cmd/datamigrate
+  enforce   add NOT NULL and unique constraints  staged
+  contract  drop the old column                  staged
+
+Staged phases are moved into the migrations directory one deploy at a time
+with goca migrate advance.
+
+Examples:
+  goca field rename User name full_name --migrate
+  goca field rename Product price price_cents:int64 --expand-contract`,
+	Args: cobra.ExactArgs(3),
+	RunE: runFieldRename,
+}
+
+var fieldRemoveCmd = &cobra.Command{
+	Use:   "remove <entity> <fields>",
+	Short: "Remove fields from an existing entity",
+	Long: `Removes fields from an existing entity and from the code generated for them:
+struct fields, validation checks, seeds, test values and field tests, DTO
+fields and mappings, repository finders and criteria conditions. Business rule
+methods that depend on a removed field are removed too. Validation error variables are kept.
+
+References goca did not generate, such as hand-written handlers, are reported
+so they can be updated. With --migrate a DROP COLUMN migration and its rollback
+are written to the migrations directory.
+
+Examples:
+  goca field remove User phone --migrate
+  goca field remove Product sku,weight`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runFieldRemove,
+}
+
+func runFieldAdd(cmd *cobra.Command, args []string) error {
+	entity, spec := args[0], strings.Join(args[1:], ",")
+	migrate, _ := cmd.Flags().GetBool("migrate")
+	expandContract, _ := cmd.Flags().GetBool("expand-contract")
+	database, _ := cmd.Flags().GetString("database")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	backup, _ := cmd.Flags().GetBool("backup")
+
+	if err := NewCommandValidator().ValidateEntityCommand(entity, spec); err != nil {
+		return err
+	}
+	entityFile, err := findEntityFile(entity)
+	if err != nil {
+		return err
+	}
+	src, err := parseGoSource(entityFile)
+	if err != nil {
+		return err
+	}
+	existing := readEntityFields(src.file, entity)
+	validation := findMethod(src.file, entity, "Validate") != nil
+
+	// parseFieldsWithValidation always prepends the ID field.
+	fields := parseFieldsWithValidation(spec, validation)[1:]
+	for _, field := range fields {
+		if fieldNames(existing)[field.Name] {
+			return fmt.Errorf("%s already has a field %s", entity, field.Name)
+		}
+	}
+	if hasBSONTags(existing) {
+		addBSONTags(fields)
+	}
+
+	configIntegration := NewConfigIntegration()
+	if err := configIntegration.LoadConfigForProject(); err != nil {
+		ui.Warning(fmt.Sprintf("Could not load configuration: %v", err))
+	}
+	dtoStyle := configIntegration.GetDTOStyle("")
+	safetyMgr := NewSafetyManager(dryRun, false, backup)
+	if dryRun {
+		ui.DryRun("Previewing changes without creating files")
+	}
+
+	ui.Header(fmt.Sprintf("Adding %d field(s) to %s", len(fields), entity))
+	printFieldTable(fields)
+
+	if err := addFieldsToLayers(entityFile, entity, existing, fields, validation, dtoStyle, safetyMgr); err != nil {
+		return err
+	}
+
+	var nextSteps []string
+	if expandContract {
+		database := configIntegration.GetDatabaseType(database)
+		steps, err := writeExpandContract(configIntegration, addColumnsChange(entity, database, fields), database, safetyMgr)
+		if err != nil {
+			return err
+		}
+		nextSteps = append(nextSteps, steps...)
+	} else if migrate {
+		path, err := writeColumnMigration(configIntegration, entity, configIntegration.GetDatabaseType(database), fields, false, safetyMgr)
+		if err != nil {
+			return err
+		}
+		if path != "" {
+			nextSteps = append(nextSteps, "Apply the migration: make migrate-up")
+		}
+	}
+
+	if dryRun {
+		safetyMgr.PrintSummary()
+		return nil
+	}
+
+	if fieldsUseType(fields, FieldJSONType) {
+		projectRoot, _ := os.Getwd()
+		depMgr := NewDependencyManager(projectRoot, false)
+		for _, dep := range depMgr.GetRequiredDependenciesForFeature("", map[string]bool{"json": true}) {
+			if err := depMgr.AddDependency(dep); err != nil {
+				ui.Warning(fmt.Sprintf("Could not add dependency %s: %v", dep.Module, err))
+			}
+		}
+		if err := depMgr.UpdateGoMod(); err != nil {
+			ui.Warning(fmt.Sprintf("Could not update go.mod: %v", err))
+		}
+	}
+
+	ui.Blank()
+	ui.Success(fmt.Sprintf("Added %d field(s) to %s", len(fields), entity))
+	ui.NextSteps(append(nextSteps,
+		"Map the new fields in handlers that copy fields by hand (gRPC, CLI)",
+		"Run: go build ./... && go test ./...",
+	))
+	return nil
+}
+
+func runFieldRemove(cmd *cobra.Command, args []string) error {
+	entity := args[0]
+	migrate, _ := cmd.Flags().GetBool("migrate")
+	database, _ := cmd.Flags().GetString("database")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	backup, _ := cmd.Flags().GetBool("backup")
+
+	if err := NewCommandValidator().ValidateEntityCommand(entity, ""); err != nil {
+		return err
+	}
+	entityFile, err := findEntityFile(entity)
+	if err != nil {
+		return err
+	}
+	src, err := parseGoSource(entityFile)
+	if err != nil {
+		return err
+	}
+	existing := readEntityFields(src.file, entity)
+	fields, err := selectEntityFields(entity, existing, strings.Join(args[1:], ","))
+	if err != nil {
+		return err
+	}
+	removed := fieldNames(fields)
+	var remaining []Field
+	for _, field := range existing {
+		if !isSystemField(field.Name) && !removed[field.Name] {
+			remaining = append(remaining, field)
+		}
+	}
+
+	configIntegration := NewConfigIntegration()
+	if err := configIntegration.LoadConfigForProject(); err != nil {
+		ui.Warning(fmt.Sprintf("Could not load configuration: %v", err))
+	}
+	safetyMgr := NewSafetyManager(dryRun, false, backup)
+	if dryRun {
+		ui.DryRun("Previewing changes without creating files")
+	}
+
+	ui.Header(fmt.Sprintf("Removing %d field(s) from %s", len(fields), entity))
+	printFieldTable(fields)
+
+	if err := removeFieldsFromLayers(entityFile, entity, fields, remaining, safetyMgr); err != nil {
+		return err
+	}
+
+	var nextSteps []string
+	if migrate {
+		path, err := writeColumnMigration(configIntegration, entity, configIntegration.GetDatabaseType(database), fields, true, safetyMgr)
+		if err != nil {
+			return err
+		}
+		if path != "" {
+			nextSteps = append(nextSteps, "Apply the migration: make migrate-up")
+		}
+	}
+
+	if dryRun {
+		safetyMgr.PrintSummary()
+		return nil
+	}
+
+	ui.Blank()
+	ui.Success(fmt.Sprintf("Removed %d field(s) from %s", len(fields), entity))
+	if refs := fieldReferences(entity, fields); len(refs) > 0 {
+		ui.Warning("These files still reference the removed fields:")
+		for _, ref := range refs {
+			ui.Dim("   " + ref)
+		}
+		nextSteps = append(nextSteps, "Update the files listed above")
+	}
+	ui.NextSteps(append(nextSteps, "Run: go build ./... && go test ./..."))
+	return nil
+}
+
+// addFieldsToLayers adds fields to the entity declared in entityFile, whose
+// fields are existing, and wires them through every generated layer.
+func addFieldsToLayers(entityFile, entity string, existing, fields []Field, validation bool, dtoStyle string, sm *SafetyManager) error {
+	var allFields []Field
+	for _, field := range existing {
+		if !isSystemField(field.Name) {
+			allFields = append(allFields, field)
+		}
+	}
+	allFields = append(allFields, fields...)
+
+	if _, err := addEntityFields(entityFile, entity, fields, sm); err != nil {
+		return err
+	}
+	if validation {
+		generateErrorsFile(filepath.Dir(entityFile), entity, fields, sm)
+	}
+	steps := []func() error{
+		func() error { return addSeedFields(entity, fields, sm) },
+		func() error { return addEntityTestFields(entity, allFields, fields, sm) },
+		func() error { return addDTOFields(entity, fields, dtoStyle, sm) },
+		func() error { return addHandlerEnums(entity, fields, sm) },
+		func() error { return addServiceMappings(entity, fields, dtoStyle, sm) },
+		func() error { return syncPresenter(entity, sm) },
+		func() error { return addRepositoryFinders(entity, fields, allFields, sm) },
+		func() error { return syncRepositoryCriteria(entity, allFields, sm) },
+		func() error { return syncSensitiveFields(sm) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeFieldsFromLayers removes fields from the entity declared in
+// entityFile and from every generated layer; remaining are the fields left.
+func removeFieldsFromLayers(entityFile, entity string, fields, remaining []Field, sm *SafetyManager) error {
+	steps := []func() error{
+		func() error { return removeEntityFields(entityFile, entity, fields, sm) },
+		func() error { return removeSeedFields(entity, fields, sm) },
+		func() error { return removeEntityTestFields(entity, fields, sm) },
+		func() error { return removeDTOFields(entity, fields, sm) },
+		func() error { return removeHandlerEnums(entity, fields, sm) },
+		func() error { return removeServiceMappings(entity, fields, sm) },
+		func() error { return syncPresenter(entity, sm) },
+		func() error { return removeRepositoryFinders(entity, fields, sm) },
+		func() error { return syncRepositoryCriteria(entity, remaining, sm) },
+		func() error { return syncSensitiveFields(sm) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runFieldRename(cmd *cobra.Command, args []string) error {
+	entity, name, spec := args[0], args[1], args[2]
+	migrate, _ := cmd.Flags().GetBool("migrate")
+	expandContract, _ := cmd.Flags().GetBool("expand-contract")
+	database, _ := cmd.Flags().GetString("database")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	backup, _ := cmd.Flags().GetBool("backup")
+
+	if err := NewCommandValidator().ValidateEntityCommand(entity, ""); err != nil {
+		return err
+	}
+	entityFile, err := findEntityFile(entity)
+	if err != nil {
+		return err
+	}
+	src, err := parseGoSource(entityFile)
+	if err != nil {
+		return err
+	}
+	existing := readEntityFields(src.file, entity)
+	selected, err := selectEntityFields(entity, existing, name)
+	if err != nil {
+		return err
+	}
+	old := selected[0]
+	if old.Nested != nil {
+		return fmt.Errorf("%s is a struct field; remove it and add the new field instead", old.Name)
+	}
+	if !strings.Contains(spec, ":") {
+		spec += ":" + fieldDSLType(old.Type)
+		if len(old.Enum) > 0 {
+			spec += ":" + EnumModifier + strings.Join(old.Enum, "|")
+		}
+	}
+	if err := NewCommandValidator().ValidateEntityCommand(entity, spec); err != nil {
+		return err
+	}
+	validation := findMethod(src.file, entity, "Validate") != nil
+	fields := parseFieldsWithValidation(spec, validation)[1:]
+	if len(fields) != 1 || fields[0].Nested != nil {
+		return fmt.Errorf("%s can only be renamed to a single field that is not a struct, not %q", old.Name, spec)
+	}
+	renamed := fields[0]
+	if fieldNames(existing)[renamed.Name] {
+		return fmt.Errorf("%s already has a field %s", entity, renamed.Name)
+	}
+
+	configIntegration := NewConfigIntegration()
+	if err := configIntegration.LoadConfigForProject(); err != nil {
+		ui.Warning(fmt.Sprintf("Could not load configuration: %v", err))
+	}
+	dtoStyle := configIntegration.GetDTOStyle("")
+	safetyMgr := NewSafetyManager(dryRun, false, backup)
+	if dryRun {
+		ui.DryRun("Previewing changes without creating files")
+	}
+
+	ui.Header(fmt.Sprintf("Renaming %s.%s to %s", entity, old.Name, renamed.Name))
+	ui.Table([]string{"", "Field", "Type"}, [][]string{
+		{"from", old.Name, old.Type},
+		{"to", renamed.Name, renamed.Type},
+	})
+
+	var allFields []Field
+	for _, field := range existing {
+		if !isSystemField(field.Name) && field.Name != old.Name {
+			allFields = append(allFields, field)
+		}
+	}
+	allFields = append(allFields, renamed)
+	olds, news := []Field{old}, []Field{renamed}
+
+	steps := []func() error{
+		func() error { return renameFieldUses(entityFile, entity, old.Name, renamed.Name, safetyMgr) },
+		func() error { return removeEntityFields(entityFile, entity, olds, safetyMgr) },
+		func() error { return removeSeedFields(entity, olds, safetyMgr) },
+		func() error { return removeEntityTestFields(entity, olds, safetyMgr) },
+		func() error { return removeDTOFields(entity, olds, safetyMgr) },
+		func() error { return removeHandlerEnums(entity, olds, safetyMgr) },
+		func() error { return removeServiceMappings(entity, olds, safetyMgr) },
+		func() error { return removeRepositoryFinders(entity, olds, safetyMgr) },
+		func() error {
+			_, err := addEntityFields(entityFile, entity, news, safetyMgr)
+			return err
+		},
+		func() error {
+			if validation {
+				generateErrorsFile(filepath.Dir(entityFile), entity, news, safetyMgr)
+			}
+			return nil
+		},
+		func() error { return addSeedFields(entity, news, safetyMgr) },
+		func() error { return addEntityTestFields(entity, allFields, news, safetyMgr) },
+		func() error { return addDTOFields(entity, news, dtoStyle, safetyMgr) },
+		func() error { return addHandlerEnums(entity, news, safetyMgr) },
+		func() error { return addServiceMappings(entity, news, dtoStyle, safetyMgr) },
+		func() error { return syncPresenter(entity, safetyMgr) },
+		func() error { return addRepositoryFinders(entity, news, allFields, safetyMgr) },
+		func() error { return syncRepositoryCriteria(entity, allFields, safetyMgr) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+
+	var nextSteps []string
+	if migrate || expandContract {
+		database := configIntegration.GetDatabaseType(database)
+		change := renameColumnChange(entity, database, old, renamed)
+		if expandContract {
+			steps, err := writeExpandContract(configIntegration, change, database, safetyMgr)
+			if err != nil {
+				return err
+			}
+			nextSteps = append(nextSteps, steps...)
+		} else if sqlDialect(database) < 0 {
+			ui.Warning(fmt.Sprintf("Skipping --migrate: %s has no table schema to alter", database))
+		} else {
+			up, down := change.renameMigration(database)
+			if _, err := writeFieldMigration(configIntegration, change.Name, up, down, safetyMgr); err != nil {
+				return err
+			}
+			nextSteps = append(nextSteps, "Apply the migration: make migrate-up")
+		}
+	}
+
+	if dryRun {
+		safetyMgr.PrintSummary()
+		return nil
+	}
+
+	ui.Blank()
+	ui.Success(fmt.Sprintf("Renamed %s.%s to %s", entity, old.Name, renamed.Name))
+	if refs := fieldReferences(entity, olds); len(refs) > 0 {
+		ui.Warning(fmt.Sprintf("These files still reference %s:", old.Name))
+		for _, ref := range refs {
+			ui.Dim("   " + ref)
+		}
+		nextSteps = append(nextSteps, "Update the files listed above")
+	}
+	ui.NextSteps(append(nextSteps, "Run: go build ./... && go test ./..."))
+	return nil
+}
+
+// fieldDSLType returns the --fields type of an entity field's Go type.
+func fieldDSLType(goType string) string {
+	if goType == FieldJSONType {
+		return "json"
+	}
+	if goType == FieldFileType {
+		return FieldFile
+	}
+	return goType
+}
+
+// selectEntityFields returns the entity fields named in a comma-separated list
+// of field names, written like in --fields or as Go names.
+func selectEntityFields(entity string, existing []Field, list string) ([]Field, error) {
+	var fields []Field
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		goName := toGoFieldName(name)
+		if isSystemField(goName) {
+			return nil, fmt.Errorf("%s is managed by goca and cannot be removed", goName)
+		}
+		found := false
+		for _, field := range existing {
+			if field.Name == goName {
+				fields = append(fields, field)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s has no field %s", entity, goName)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no fields to remove from %s", entity)
+	}
+	return fields, nil
+}
+
+// printFieldTable shows the fields a goca field command changes.
+func printFieldTable(fields []Field) {
+	rows := make([][]string, 0, len(fields))
+	for _, field := range fields {
+		rows = append(rows, []string{field.Name, field.Type})
+	}
+	ui.Table([]string{"Field", "Type"}, rows)
+}
+
+// writeColumnMigration writes the migration adding or dropping the columns of
+// fields. It returns "" for databases without SQL migrations.
+func writeColumnMigration(ci *ConfigIntegration, entity, database string, fields []Field, remove bool, sm ...*SafetyManager) (string, error) {
+	if sqlDialect(database) < 0 {
+		ui.Warning(fmt.Sprintf("Skipping --migrate: %s has no table schema to alter", database))
+		return "", nil
+	}
+	table := entityTableName(entity)
+	columns := fieldColumns(fields, database)
+	names := make([]string, 0, len(columns))
+	for _, column := range columns {
+		names = append(names, column.Name)
+	}
+
+	var up, down strings.Builder
+	var name string
+	if remove {
+		name = fmt.Sprintf("remove_%s_from_%s", strings.Join(names, "_"), table)
+		fmt.Fprintf(&up, "-- Remove %s from %s\n", strings.Join(names, ", "), table)
+		writeDropColumns(&up, table, database, columns)
+		fmt.Fprintf(&down, "-- Restore %s in %s\n", strings.Join(names, ", "), table)
+		writeAddColumns(&down, table, database, columns)
+	} else {
+		name = fmt.Sprintf("add_%s_to_%s", strings.Join(names, "_"), table)
+		fmt.Fprintf(&up, "-- Add %s to %s\n", strings.Join(names, ", "), table)
+		writeAddColumns(&up, table, database, columns)
+		fmt.Fprintf(&down, "-- Remove %s from %s\n", strings.Join(names, ", "), table)
+		writeDropColumns(&down, table, database, columns)
+	}
+	return writeFieldMigration(ci, name, up.String(), down.String(), sm...)
+}
+
+// fieldReferences returns the Go files mentioning the entity that still use
+// one of the removed fields through a selector.
+func fieldReferences(entity string, fields []Field) []string {
+	var names []string
+	for name := range dtoFieldNames(fields) {
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	pattern := regexp.MustCompile(`\.(` + strings.Join(names, "|") + `)\b`)
+
+	var refs []string
+	_ = filepath.WalkDir(".", func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != "." && (strings.HasPrefix(d.Name(), ".") || d.Name() == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ExtGo) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err == nil && strings.Contains(string(data), entity) && pattern.Match(data) {
+			refs = append(refs, path)
+		}
+		return nil
+	})
+	return refs
+}
+
+func init() {
+	fieldCmd.AddCommand(fieldAddCmd)
+	fieldCmd.AddCommand(fieldRenameCmd)
+	fieldCmd.AddCommand(fieldRemoveCmd)
+
+	for _, c := range []*cobra.Command{fieldAddCmd, fieldRenameCmd} {
+		c.Flags().Bool("expand-contract", false, "Split the migration into zero-downtime expand, backfill, enforce and contract phases")
+	}
+	for _, c := range []*cobra.Command{fieldAddCmd, fieldRenameCmd, fieldRemoveCmd} {
+		c.Flags().Bool("migrate", false, "Write a SQL migration for the column change")
+		c.Flags().StringP("database", "d", "", fmt.Sprintf("Database type for the migration (%s)", strings.Join(ValidDatabases, ", ")))
+		c.Flags().Bool("dry-run", false, "Preview changes without writing files")
+		c.Flags().Bool("backup", false, "Backup files before editing them")
+	}
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// defaultValuePattern limits string defaults to values that need no escaping
+// in struct tags, SQL and Go literals.
+var defaultValuePattern = regexp.MustCompile(`^[a-zA-Z0-9_.@-]+$`)
+
+// gormTagPattern matches the gorm key of a struct tag.
+var gormTagPattern = regexp.MustCompile(`gorm:"[^"]*"`)
+
+// validateFieldConstraints checks the default value and computed flag of a
+// field definition. It returns the default in its canonical form.
+func validateFieldConstraints(fieldName, fieldType, value string, computed bool, enum []string) (string, error) {
+	if computed {
+		if value != "" {
+			return "", fmt.Errorf("computed field %s cannot have a default", fieldName)
+		}
+		if isStructFieldType(fieldType) || isFileFieldType(fieldType) || isJSONFieldType(fieldType) {
+			return "", fmt.Errorf("field %s of type %s cannot be computed", fieldName, fieldType)
+		}
+		return "", nil
+	}
+	if value == "" {
+		return "", nil
+	}
+	return normalizeFieldDefault(fieldName, fieldType, value, enum)
+}
+
+// normalizeFieldDefault parses the default of a string, number or bool
+// field. Zero values are rejected: they are the default of every field.
+// Negative numbers are rejected too, since Validate does not accept them.
+func normalizeFieldDefault(fieldName, fieldType, value string, enum []string) (string, error) {
+	invalid := func(reason string) error {
+		return fmt.Errorf("invalid default %q on field %s: %s", value, fieldName, reason)
+	}
+	switch {
+	case fieldType == FieldString:
+		if !defaultValuePattern.MatchString(value) {
+			return "", invalid("use letters, digits, '_', '-', '.' and '@'")
+		}
+		if len(enum) > 0 && !contains(enum, value) {
+			return "", invalid("not one of its enum values")
+		}
+		return value, nil
+	case fieldType == FieldBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", invalid("not a bool")
+		}
+		if !b {
+			return "", invalid("false is the zero value of bool")
+		}
+		return "true", nil
+	case isUnsignedIntType(fieldType) || (isSignedNumericType(fieldType) && !strings.HasPrefix(fieldType, "float")):
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", invalid("not an integer")
+		}
+		if n <= 0 {
+			return "", invalid("use a positive number")
+		}
+		return strconv.FormatInt(n, 10), nil
+	case fieldType == FieldFloat32 || fieldType == FieldFloat64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", invalid("not a number")
+		}
+		if f <= 0 {
+			return "", invalid("use a positive number")
+		}
+		return value, nil
+	}
+	return "", fmt.Errorf("field %s of type %s cannot have a default; defaults are strings, numbers and bools", fieldName, fieldType)
+}
+
+// applyDefaultTag records the default or read-only flag of a field in its
+// gorm tag, where GORM applies it and goca reads it back. Computed fields
+// are read but never written by GORM.
+func applyDefaultTag(field *Field) {
+	var options []string
+	switch {
+	case field.Computed:
+		options = []string{"->"}
+	case field.Default != "":
+		gorm := gormTagPattern.FindString(field.Tag)
+		for _, option := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(gorm, `gorm:"`), `"`), ";") {
+			if option != "" && !strings.HasPrefix(option, "default:") {
+				options = append(options, option)
+			}
+		}
+		options = append(options, "default:"+field.Default)
+	default:
+		return
+	}
+
+	gorm := fmt.Sprintf("gorm:%q", strings.Join(options, ";"))
+	if gormTagPattern.MatchString(field.Tag) {
+		field.Tag = gormTagPattern.ReplaceAllLiteralString(field.Tag, gorm)
+		return
+	}
+	field.Tag = "`" + strings.Trim(field.Tag, "`") + " " + gorm + "`"
+}
+
+// fieldConstraintSpec returns the =default suffix of the type and the
+// :computed modifier of a --fields definition, read back from the gorm tag
+// of an entity field. The zero defaults goca puts on numbers and bools are
+// not declared defaults.
+func fieldConstraintSpec(name, fieldType, tag string) (defaultSuffix, modifier string) {
+	options := gormTagOptions(tag)
+	if _, ok := options["->"]; ok {
+		return "", ":" + ComputedModifier
+	}
+	value := strings.Trim(options["default"], `'"`)
+	if value == "" {
+		return "", ""
+	}
+	if value, err := normalizeFieldDefault(name, fieldType, value, nil); err == nil {
+		return DefaultSeparator + value, ""
+	}
+	return "", ""
+}
+
+// hasFieldDefaults reports whether any field declares a default.
+func hasFieldDefaults(fields []Field) bool {
+	for _, field := range fields {
+		if field.Default != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutComputedFields returns the fields inputs may set.
+func withoutComputedFields(fields []Field) []Field {
+	var settable []Field
+	for _, field := range fields {
+		if !field.Computed {
+			settable = append(settable, field)
+		}
+	}
+	return settable
+}
+
+// constructorFields returns the parameters of New<Entity>: the fields that
+// are neither managed by goca, computed nor defaulted.
+func constructorFields(fields []Field) []Field {
+	var params []Field
+	for _, field := range fields {
+		if !isSystemField(field.Name) && !field.Computed && field.Default == "" {
+			params = append(params, field)
+		}
+	}
+	return params
+}
+
+// constructorParamName returns the New<Entity> parameter of a field, in
+// lowerCamelCase and clear of Go keywords and predeclared identifiers.
+func constructorParamName(fieldName string) string {
+	name := fieldJSONName(fieldName, JSONNamingCamel)
+	if token.IsKeyword(name) || types.Universe.Lookup(name) != nil {
+		name += "Value"
+	}
+	return name
+}
+
+// fieldDefaultLiteral returns the Go literal of a field's default.
+func fieldDefaultLiteral(field Field) string {
+	if field.Type == FieldString {
+		return strconv.Quote(field.Default)
+	}
+	return field.Default
+}
+
+// writeEntityConstructor writes New<Entity>, which takes the fields without
+// a default and sets the others to their defaults. Entities without defaults
+// get no constructor.
+func writeEntityConstructor(content *strings.Builder, entityName string, fields []Field) {
+	if !hasFieldDefaults(fields) {
+		return
+	}
+	params := constructorFields(fields)
+	args := make([]string, len(params))
+	for i, field := range params {
+		args[i] = constructorParamName(field.Name) + " " + field.Type
+	}
+
+	fmt.Fprintf(content, "// New%s returns a %s with the given fields and the defaults of the\n// others.\n", entityName, entityName)
+	fmt.Fprintf(content, "func New%s(%s) *%s {\n", entityName, strings.Join(args, ", "), entityName)
+	fmt.Fprintf(content, "\treturn &%s{\n", entityName)
+	for _, field := range fields {
+		switch {
+		case field.Default != "":
+			fmt.Fprintf(content, "\t\t%s: %s,\n", field.Name, fieldDefaultLiteral(field))
+		case !isSystemField(field.Name) && !field.Computed:
+			fmt.Fprintf(content, "\t\t%s: %s,\n", field.Name, constructorParamName(field.Name))
+		}
+	}
+	content.WriteString("\t}\n")
+	content.WriteString("}\n\n")
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const defaultFields = "name:string,status:string=active:enum=active|blocked,priority:int=3,verified:bool=true,full_name:computed"
+
+func TestParseFields_DefaultsAndComputed(t *testing.T) {
+	fields := parseFieldsWithValidation(defaultFields, true)
+	require.Len(t, fields, 6)
+
+	status := fields[2]
+	assert.Equal(t, "active", status.Default)
+	assert.Equal(t, []string{"active", "blocked"}, status.Enum)
+	assert.Contains(t, status.Tag, "default:active")
+	assert.NotContains(t, status.Tag, "required", "defaulted fields may be left out")
+	assert.Contains(t, fields[3].Tag, `gorm:"type:integer;not null;default:3"`)
+
+	fullName := fields[5]
+	assert.True(t, fullName.Computed)
+	assert.Equal(t, FieldString, fullName.Type)
+	assert.Equal(t, "`json:\"full_name\" gorm:\"->\"`", fullName.Tag)
+
+	v := NewFieldValidator()
+	assert.NoError(t, v.ValidateFields("total:float64:computed,rank:int=1,ratio:float64=0.5"))
+	assert.ErrorContains(t, v.ValidateFields("status:string="), "empty default")
+	assert.ErrorContains(t, v.ValidateFields("status:string=pending:enum=active|blocked"), "not one of its enum values")
+	assert.ErrorContains(t, v.ValidateFields("priority:int=high"), "not an integer")
+	assert.ErrorContains(t, v.ValidateFields("priority:int=-1"), "positive number")
+	assert.ErrorContains(t, v.ValidateFields("verified:bool=false"), "zero value")
+	assert.ErrorContains(t, v.ValidateFields("name:string=a b"), "letters, digits")
+	assert.ErrorContains(t, v.ValidateFields("born:time.Time=now"), "cannot have a default")
+	assert.ErrorContains(t, v.ValidateFields("total:int=1:computed"), "cannot have a default")
+}
+
+func TestApplyDefaultTag(t *testing.T) {
+	field := Field{Name: "Status", Type: FieldString, Default: "draft", Tag: "`json:\"status\" gorm:\"type:varchar(255);default:'';not null\"`"}
+	applyDefaultTag(&field)
+	assert.Equal(t, "`json:\"status\" gorm:\"type:varchar(255);not null;default:draft\"`", field.Tag)
+
+	field = Field{Name: "Rank", Type: FieldInt, Default: "2", Tag: "`json:\"rank\"`"}
+	applyDefaultTag(&field)
+	assert.Equal(t, "`json:\"rank\" gorm:\"default:2\"`", field.Tag)
+
+	suffix, modifier := fieldConstraintSpec("Rank", FieldInt, field.Tag)
+	assert.Equal(t, "=2", suffix)
+	assert.Empty(t, modifier)
+	suffix, _ = fieldConstraintSpec("Rank", FieldInt, "`gorm:\"not null;default:0\"`")
+	assert.Empty(t, suffix, "zero defaults are not declared")
+	_, modifier = fieldConstraintSpec("Total", FieldInt, "`gorm:\"->\"`")
+	assert.Equal(t, ":computed", modifier)
+}
+
+func TestGenerateEntity_DefaultsAndComputed(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+
+	sm := NewSafetyManager(false, true, false)
+	require.NoError(t, generateEntity("Task", defaultFields, true, false, false, false, false, false, false, true, "lowercase", sm))
+
+	entity := readParsedGo(t, filepath.Join("internal", "domain", "task.go"))
+	assert.Contains(t, entity, "func NewTask(name string) *Task {\n\treturn &Task{\n\t\tName:     name,\n\t\tStatus:   \"active\",\n\t\tPriority: 3,\n\t\tVerified: true,\n\t}\n}")
+	assert.NotContains(t, entity, "t.FullName", "computed fields are not validated")
+	seeds := readParsedGo(t, filepath.Join("internal", "domain", "task_seeds.go"))
+	assert.NotContains(t, seeds, "full_name")
+
+	// Regeneration reads the defaults and computed fields back.
+	assert.Equal(t, "name:string,status:string=active:enum=active|blocked,priority:int=3,verified:bool=true,full_name:string:computed", readEntityFieldsString("Task"))
+
+	var sb strings.Builder
+	generateCreateMethodWithFields(&sb, "taskService", "Task", defaultFields, DTOStyleNested, false, false, false)
+	create := sb.String()
+	assert.Contains(t, create, "task := *domain.NewTask(input.Name)\n")
+	assert.Contains(t, create, "\tif input.Priority != 0 {\n\t\ttask.Priority = input.Priority\n\t}\n")
+	assert.NotContains(t, create, "input.Verified", "false takes the default of true")
+	assert.NotContains(t, create, "input.FullName")
+	assert.Contains(t, create, "FullName: task.FullName,", "outputs carry computed fields")
+
+	dir := t.TempDir()
+	generateDTOFileWithFields(dir, "Task", []string{"create", "update"}, true, defaultFields, DTOStyleNested)
+	dto := readParsedGo(t, filepath.Join(dir, "dto.go"))
+	assert.Contains(t, dto, "Priority int    `json:\"priority\" validate:\"omitempty")
+	assert.Equal(t, 1, strings.Count(dto, "FullName"), "only the output has the computed field")
+}
+
+func TestFieldAdd_DefaultsAndComputed(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	const fields = "name:string,status:string=active"
+	sm := NewSafetyManager(false, true, false)
+	require.NoError(t, generateEntity("Task", fields, true, false, false, false, false, false, false, true, "lowercase", sm))
+	generateUseCaseWithFields("TaskService", "Task", "create,read,update,delete,list", false, false, fields, "", sm)
+	require.NoError(t, generateEntity("Note", "title:string", true, false, false, false, false, false, false, true, "lowercase", sm))
+	generateUseCaseWithFields("NoteService", "Note", "create,read,update,delete,list", false, false, "title:string", "", sm)
+
+	require.NoError(t, runFieldAdd(fieldAddCmd, []string{"Task", "label:string=todo,owner:string,score:int:computed"}))
+	entity := readParsedGo(t, filepath.Join("internal", "domain", "task.go"))
+	assert.Contains(t, entity, "Status: \"active\",\n\t\tLabel:  \"todo\",\n\t}")
+	service := readParsedGo(t, filepath.Join("internal", "usecase", "task_service.go"))
+	assert.Contains(t, service, "task := *domain.NewTask(input.Name)\n\ttask.Owner = input.Owner\n\tif input.Label != \"\" {\n\t\ttask.Label = input.Label\n\t}\n")
+	assert.NotContains(t, service, "input.Score")
+
+	// Entities without a constructor start the literal at the default.
+	require.NoError(t, runFieldAdd(fieldAddCmd, []string{"Note", "rank:int=2"}))
+	service = readParsedGo(t, filepath.Join("internal", "usecase", "note_service.go"))
+	assert.Contains(t, service, "Rank:  2,\n\t}\n\tif input.Rank != 0 {\n\t\tnote.Rank = input.Rank\n\t}\n")
+
+	columns := fieldColumns(parseFieldsWithValidation("label:string=todo", false)[1:], DBPostgres)
+	assert.Equal(t, "'todo'", columns[0].Default)
+}
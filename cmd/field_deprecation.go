@@ -0,0 +1,345 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Deprecation modifiers of --fields. nickname:string:deprecated keeps a field
+// that new clients should stop sending; nickname:string:deprecated=display_name
+// renames it, accepting nickname from older clients as display_name.
+const (
+	DeprecatedModifier    = "deprecated"
+	DeprecatedForModifier = "deprecated="
+)
+
+// deprecatedTag is the struct tag of the input DTOs marking a deprecated
+// field: deprecated:"true", or the json name of its replacement.
+const deprecatedTag = "deprecated"
+
+// deprecatableTypes are the field types the deprecation modifiers accept:
+// those whose zero value tells whether a request set them.
+var deprecatableTypes = []string{
+	FieldString, FieldInt, FieldInt32, FieldInt64, FieldUint, FieldUint32, FieldUint64,
+	FieldFloat32, FieldFloat64, FieldBool, FieldTime,
+}
+
+// parseDeprecation parses a deprecated or deprecated= modifier into the name
+// of the field replacing it, empty for a plain deprecation. ok is false for
+// other modifiers.
+func parseDeprecation(fieldName, modifier string) (replacement string, ok bool, err error) {
+	modifier = strings.TrimSpace(modifier)
+	if modifier == DeprecatedModifier {
+		return "", true, nil
+	}
+	replacement, ok = strings.CutPrefix(modifier, DeprecatedForModifier)
+	if !ok {
+		return "", false, nil
+	}
+	if replacement = strings.TrimSpace(replacement); replacement == "" {
+		return "", true, fmt.Errorf("%s on field %s names no replacement field", modifier, fieldName)
+	}
+	return replacement, true, nil
+}
+
+// validateDeprecation checks the deprecation of a field definition: only
+// fields clients send, of a deprecatableTypes type, can be deprecated.
+func validateDeprecation(field *Field) error {
+	if !field.Deprecated {
+		return nil
+	}
+	if !contains(deprecatableTypes, field.Type) {
+		return fmt.Errorf("field %s of type %s cannot be deprecated; use one of %s", field.Name, field.Type, strings.Join(deprecatableTypes, ", "))
+	}
+	if field.Computed {
+		return fmt.Errorf("computed field %s cannot be deprecated: clients never send it", field.Name)
+	}
+	if field.ReplacedBy != "" && (field.Default != "" || len(field.Finders) > 0) {
+		return fmt.Errorf("field %s renamed with %s%s is not stored and takes no default or finder", field.Name, DeprecatedForModifier, field.ReplacedBy)
+	}
+	return nil
+}
+
+// withoutDeprecatedAliases returns the fields of the entity: those of fields
+// except the deprecated names of renamed fields, which only input DTOs
+// declare. It fails when an alias names no field of the same type.
+func withoutDeprecatedAliases(fields []Field) ([]Field, error) {
+	stored := make(map[string]Field)
+	for _, field := range fields {
+		if field.ReplacedBy == "" {
+			stored[toGoFieldName(field.Name)] = field
+		}
+	}
+	var result []Field
+	for _, field := range fields {
+		if field.ReplacedBy == "" {
+			result = append(result, field)
+			continue
+		}
+		replacement, ok := stored[toGoFieldName(field.ReplacedBy)]
+		if !ok {
+			return nil, fmt.Errorf("field %s is deprecated for %s, which is not one of the fields", field.Name, field.ReplacedBy)
+		}
+		if replacement.Type != field.Type {
+			return nil, fmt.Errorf("field %s (%s) is deprecated for %s of another type (%s)", field.Name, field.Type, field.ReplacedBy, replacement.Type)
+		}
+	}
+	return result, nil
+}
+
+// deprecatedAliases returns the deprecated names of the renamed fields of
+// fields, with Go names and the json names of the project's convention.
+// ReplacedBy holds the Go name of the field replacing each.
+func deprecatedAliases(fields string) []Field {
+	if fields == "" {
+		return nil
+	}
+	validator := NewFieldValidator()
+	var aliases []Field
+	for _, part := range validator.smartSplitFields(fields) {
+		field, err := validator.ValidateField(strings.TrimSpace(part))
+		if err != nil || field.ReplacedBy == "" {
+			continue
+		}
+		alias := validator.entityField(field.Name, field.Type)
+		alias.JSONName = field.JSONName
+		alias.Deprecated = true
+		alias.ReplacedBy = toGoFieldName(field.ReplacedBy)
+		aliases = append(aliases, alias)
+	}
+	normalizeFieldNames(aliases, projectNaming().JSON)
+	return aliases
+}
+
+// hasDeprecatedFields reports whether fields declares a deprecated field,
+// which gives the input DTOs an Upgrade method.
+func hasDeprecatedFields(fields string) bool {
+	if len(deprecatedAliases(fields)) > 0 {
+		return true
+	}
+	for _, field := range parseFields(fields) {
+		if field.Deprecated {
+			return true
+		}
+	}
+	return false
+}
+
+// deprecatedDTOTag returns the tags of a deprecated input DTO field: its
+// deprecation and swaggerignore, which leaves it out of the OpenAPI schema
+// new clients are written against.
+func deprecatedDTOTag(field Field, fields []Field) string {
+	value := "true"
+	if field.ReplacedBy != "" {
+		value = dtoJSONName(findField(fields, field.ReplacedBy))
+	}
+	return fmt.Sprintf(" %s:%q swaggerignore:\"true\"", deprecatedTag, value)
+}
+
+// findField returns the field of fields named name.
+func findField(fields []Field, name string) Field {
+	for _, field := range fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return Field{Name: name}
+}
+
+// writeDeprecatedComment writes the doc comment of a deprecated input DTO
+// field.
+func writeDeprecatedComment(content *strings.Builder, field Field, fields []Field) {
+	if field.ReplacedBy != "" {
+		fmt.Fprintf(content, "\t// Deprecated: use %s; accepted from older clients only.\n", findField(fields, field.ReplacedBy).Name)
+		return
+	}
+	content.WriteString("\t// Deprecated: accepted from older clients only.\n")
+}
+
+// writeDeprecatedAliasField writes the input DTO field accepting the
+// deprecated name of a renamed field, a pointer in update inputs.
+func writeDeprecatedAliasField(content *strings.Builder, alias Field, fields []Field, pointer bool) {
+	fieldType := alias.Type
+	if pointer {
+		fieldType = "*" + fieldType
+	}
+	writeDeprecatedComment(content, alias, fields)
+	fmt.Fprintf(content, "\t%s %s `json:\"%s,omitempty\"%s`\n", alias.Name, fieldType, dtoJSONName(alias), deprecatedDTOTag(alias, fields))
+}
+
+// deprecatedFieldSet returns the condition under which an input DTO sets a
+// deprecated field: non-nil for pointers, non-zero otherwise.
+func deprecatedFieldSet(expr string, field Field, pointer bool) string {
+	switch {
+	case pointer || isPointerType(field.Type):
+		return expr + " != nil"
+	case field.Type == FieldString:
+		return expr + ` != ""`
+	case field.Type == FieldBool:
+		return expr
+	case field.Type == FieldTime:
+		return "!" + expr + ".IsZero()"
+	default:
+		return expr + " != 0"
+	}
+}
+
+// deprecatedFieldUnset returns the negation of deprecatedFieldSet.
+func deprecatedFieldUnset(expr string, field Field, pointer bool) string {
+	switch {
+	case pointer || isPointerType(field.Type):
+		return expr + " == nil"
+	case field.Type == FieldString:
+		return expr + ` == ""`
+	case field.Type == FieldBool:
+		return "!" + expr
+	case field.Type == FieldTime:
+		return expr + ".IsZero()"
+	default:
+		return expr + " == 0"
+	}
+}
+
+// writeInputUpgrade writes the Upgrade method of an input DTO: it moves the
+// deprecated names older clients send to the fields replacing them, and
+// returns a warning for each deprecated field the input sets. Handlers call
+// it before validating the input, and the use case again for other callers.
+func writeInputUpgrade(content *strings.Builder, input string, fields, aliases []Field, pointer bool) {
+	content.WriteString("// Upgrade moves the deprecated fields older clients send to their\n")
+	content.WriteString("// replacements and returns a warning for each deprecated field the input sets.\n")
+	fmt.Fprintf(content, "func (in *%s) Upgrade() []string {\n", input)
+	content.WriteString("\tvar warnings []string\n")
+	for _, alias := range aliases {
+		replacement := findField(fields, alias.ReplacedBy)
+		fmt.Fprintf(content, "\tif %s {\n", deprecatedFieldSet("in."+alias.Name, alias, pointer))
+		fmt.Fprintf(content, "\t\tif %s {\n", deprecatedFieldUnset("in."+replacement.Name, replacement, pointer))
+		fmt.Fprintf(content, "\t\t\tin.%s = in.%s\n", replacement.Name, alias.Name)
+		content.WriteString("\t\t}\n")
+		zero := "nil"
+		if !pointer {
+			zero = zeroValue(alias.Type)
+		}
+		fmt.Fprintf(content, "\t\tin.%s = %s\n", alias.Name, zero)
+		fmt.Fprintf(content, "\t\twarnings = append(warnings, %q)\n", fmt.Sprintf("%s is deprecated, use %s", dtoJSONName(alias), dtoJSONName(replacement)))
+		content.WriteString("\t}\n")
+	}
+	for _, field := range fields {
+		if !field.Deprecated {
+			continue
+		}
+		fmt.Fprintf(content, "\tif %s {\n", deprecatedFieldSet("in."+field.Name, field, pointer))
+		fmt.Fprintf(content, "\t\twarnings = append(warnings, %q)\n", dtoJSONName(field)+" is deprecated")
+		content.WriteString("\t}\n")
+	}
+	content.WriteString("\treturn warnings\n")
+	content.WriteString("}\n\n")
+}
+
+// zeroValue returns the zero value of a deprecatableTypes type.
+func zeroValue(fieldType string) string {
+	switch fieldType {
+	case FieldString:
+		return `""`
+	case FieldBool:
+		return "false"
+	case FieldTime:
+		return "time.Time{}"
+	default:
+		return "0"
+	}
+}
+
+// inputDeprecations describes the deprecated fields of the use case input
+// DTO named input, as tagged in its struct: nickname (use display_name). It
+// is empty when the input has none, or does not exist.
+func inputDeprecations(input string) []string {
+	dir := filepath.Join(DirInternal, DirUseCase)
+	file := findTypeFile(dir, input)
+	if file == "" {
+		return nil
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	var deprecations []string
+	ast.Inspect(f, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok || spec.Name.Name != input {
+			return true
+		}
+		st, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			return false
+		}
+		for _, field := range st.Fields.List {
+			if field.Tag == nil {
+				continue
+			}
+			raw, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				continue
+			}
+			tag := reflect.StructTag(raw)
+			replacement := tag.Get(deprecatedTag)
+			if replacement == "" {
+				continue
+			}
+			name, _, _ := strings.Cut(tag.Get("json"), ",")
+			if replacement != "true" {
+				name += " (use " + replacement + ")"
+			}
+			deprecations = append(deprecations, name)
+		}
+		return false
+	})
+	return deprecations
+}
+
+// writeDeprecatedFieldsDoc writes the swaggo annotations documenting the
+// deprecated fields an operation still accepts, left out of its schema, and
+// the headers answering requests that use them.
+func writeDeprecatedFieldsDoc(content *strings.Builder, successCode string, deprecations []string) {
+	fmt.Fprintf(content, "// @Description Deprecated fields still accepted from older clients: %s. Requests using them are answered with Deprecation and Warning headers.\n", strings.Join(deprecations, ", "))
+	fmt.Fprintf(content, "// @Header %s {string} Deprecation \"true when the request used a deprecated field\"\n", successCode)
+	fmt.Fprintf(content, "// @Header %s {string} Warning \"a 299 warning per deprecated field used\"\n", successCode)
+}
+
+// writeDeprecatedFieldsUpgrade writes the statement of a handler upgrading
+// the decoded input and announcing the deprecated fields it used.
+func writeDeprecatedFieldsUpgrade(content *strings.Builder) {
+	content.WriteString("\twarnDeprecatedFields(w, input.Upgrade())\n\n")
+}
+
+// generateHTTPDeprecatedFieldsFile writes
+// internal/handler/http/deprecated_fields.go, only rewritten with --force.
+func generateHTTPDeprecatedFieldsFile(dir string, sm ...*SafetyManager) {
+	writeOnce(filepath.Join(dir, "deprecated_fields.go"), httpDeprecatedFieldsSource, "deprecated fields warnings", sm...)
+}
+
+const httpDeprecatedFieldsSource = `package http
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// warnDeprecatedFields announces the deprecated request fields a client used
+// with the Deprecation header (RFC 9745) and a 299 Warning header per field,
+// so clients find them before they are removed.
+func warnDeprecatedFields(w http.ResponseWriter, warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	w.Header().Set("Deprecation", "true")
+	for _, warning := range warnings {
+		w.Header().Add("Warning", "299 - "+strconv.Quote(warning))
+	}
+}
+`
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const deprecationFields = "name:string,display_name:string,nickname:string:deprecated=display_name,legacy_code:string:deprecated,age:int"
+
+func TestDeprecatedModifiers(t *testing.T) {
+	fields := parseFieldsWithValidation(deprecationFields, true)
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.Name
+	}
+	assert.Equal(t, []string{"ID", "Name", "DisplayName", "LegacyCode", "Age"}, names)
+	assert.True(t, fields[3].Deprecated)
+	assert.Equal(t, "`json:\"legacy_code\" gorm:\"type:varchar(255)\"`", fields[3].Tag)
+
+	aliases := deprecatedAliases(deprecationFields)
+	require.Len(t, aliases, 1)
+	assert.Equal(t, "Nickname", aliases[0].Name)
+	assert.Equal(t, "DisplayName", aliases[0].ReplacedBy)
+	assert.True(t, hasDeprecatedFields(deprecationFields))
+	assert.False(t, hasDeprecatedFields("name:string"))
+
+	v := NewFieldValidator()
+	for def, msg := range map[string]string{
+		"meta:json:deprecated":                 "cannot be deprecated",
+		"total:float64:computed:deprecated":    "computed field",
+		"nickname:string:deprecated=":          "names no replacement",
+		"nickname:string=x:deprecated=display": "takes no default",
+	} {
+		_, err := v.ValidateField(def)
+		assert.ErrorContains(t, err, msg, def)
+	}
+	_, err := v.ParseFieldsWithValidation("name:string,nickname:string:deprecated=display_name")
+	assert.ErrorContains(t, err, "not one of the fields")
+	_, err = v.ParseFieldsWithValidation("code:int,nickname:string:deprecated=code")
+	assert.ErrorContains(t, err, "of another type")
+}
+
+func TestDeprecatedInputDTOs(t *testing.T) {
+	var sb strings.Builder
+	generateCreateDTOWithFields(&sb, "User", true, deprecationFields, DTOStyleNested)
+	generateUpdateDTOWithFields(&sb, "User", true, deprecationFields, DTOStyleNested)
+	out := sb.String()
+	assert.Contains(t, out, "\t// Deprecated: accepted from older clients only.\n\tLegacyCode string `json:\"legacy_code\" deprecated:\"true\" swaggerignore:\"true\" validate:\"omitempty,min=1\"`\n")
+	assert.Contains(t, out, "\t// Deprecated: use DisplayName; accepted from older clients only.\n\tNickname string `json:\"nickname,omitempty\" deprecated:\"display_name\" swaggerignore:\"true\"`\n")
+	assert.Contains(t, out, "\tNickname *string `json:\"nickname,omitempty\" deprecated:\"display_name\" swaggerignore:\"true\"`\n")
+	assert.Contains(t, out, "func (in *CreateUserInput) Upgrade() []string {\n\tvar warnings []string\n\tif in.Nickname != \"\" {\n\t\tif in.DisplayName == \"\" {\n\t\t\tin.DisplayName = in.Nickname\n\t\t}\n\t\tin.Nickname = \"\"\n")
+	assert.Contains(t, out, "\tif in.Nickname != nil {\n\t\tif in.DisplayName == nil {\n\t\t\tin.DisplayName = in.Nickname\n\t\t}\n\t\tin.Nickname = nil\n")
+	assert.Contains(t, out, "\t\twarnings = append(warnings, \"legacy_code is deprecated\")\n")
+	assert.NotContains(t, out, "r.LegacyCode")
+
+	sb.Reset()
+	generateCreateMethodWithFields(&sb, "userService", "User", deprecationFields, DTOStyleNested, true, false, false)
+	assert.Contains(t, sb.String(), "(CreateUserOutput, error) {\n\tinput.Upgrade()\n\n\tif err := input.Validate(); err != nil {\n")
+}
+
+func TestDeprecatedFieldsHandler(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	sm := NewSafetyManager(false, true, false)
+	ucDir := filepath.Join(DirInternal, DirUseCase)
+	require.NoError(t, os.MkdirAll(ucDir, 0o755))
+
+	var sb strings.Builder
+	generateCreateHandlerMethod(&sb, "User", "UserHandler", false, true, false, ResponseFormatRaw)
+	assert.NotContains(t, sb.String(), "Upgrade")
+
+	generateDTOFileWithFields(ucDir, "User", []string{OpCreate, OpUpdate}, false, deprecationFields, DTOStyleNested, sm)
+	assert.Equal(t, []string{"legacy_code", "nickname (use display_name)"}, inputDeprecations("CreateUserInput"))
+
+	sb.Reset()
+	generateCreateHandlerMethod(&sb, "User", "UserHandler", false, true, false, ResponseFormatRaw)
+	out := sb.String()
+	assert.Contains(t, out, "// @Description Deprecated fields still accepted from older clients: legacy_code, nickname (use display_name).")
+	assert.Contains(t, out, "// @Header 201 {string} Deprecation \"true when the request used a deprecated field\"\n")
+	assert.Contains(t, out, "\twarnDeprecatedFields(w, input.Upgrade())\n")
+
+	httpDir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	generateHTTPDeprecatedFieldsFile(httpDir, sm)
+	helper := readParsedGo(t, filepath.Join(httpDir, "deprecated_fields.go"))
+	assert.Contains(t, helper, `w.Header().Add("Warning", "299 - "+strconv.Quote(warning))`)
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dslFields = "name:string,shipping_address:struct{street:string, zip_code:string},metadata:jsonb"
+
+// chdirTemp chdirs into a fresh module for generators that write relative paths.
+func chdirTemp(t *testing.T) {
+	t.Helper()
+	origDir, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(origDir) })
+	require.NoError(t, os.Chdir(t.TempDir()))
+	require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0o644))
+}
+
+func TestParseFields_NestedAndJSON(t *testing.T) {
+	fields := parseFieldsWithValidation(dslFields, true)
+	require.Len(t, fields, 4)
+
+	address := fields[2]
+	assert.Equal(t, "ShippingAddress", address.Name)
+	assert.Equal(t, "ShippingAddress", address.Type)
+	assert.Equal(t, "`json:\"shipping_address\" gorm:\"embedded;embeddedPrefix:shipping_address_\"`", address.Tag)
+	require.Len(t, address.Nested, 2)
+	assert.Equal(t, "ZipCode", address.Nested[1].Name)
+	assert.Contains(t, address.Nested[1].Tag, `json:"zip_code"`)
+	assert.Contains(t, address.Nested[1].Tag, `validate:"required"`)
+
+	metadata := fields[3]
+	assert.Equal(t, FieldJSONType, metadata.Type)
+	assert.Equal(t, "`json:\"metadata\"`", metadata.Tag, "JSON fields carry no validate tag")
+}
+
+func TestValidateFields_NestedErrors(t *testing.T) {
+	v := NewFieldValidator()
+	assert.NoError(t, v.ValidateFields(dslFields))
+	assert.NoError(t, v.ValidateFields("settings:json,extra:datatypes.JSON"))
+
+	assert.ErrorContains(t, v.ValidateFields("a:struct{b:struct{c:string}}"), "cannot contain another struct")
+	assert.ErrorContains(t, v.ValidateFields("a:struct{}"), "at least one field")
+	assert.Error(t, v.ValidateFields("a:struct{b:string,b:int}"))
+	assert.Error(t, v.ValidateFields("a:struct{b:unknown-type}"))
+	assert.Error(t, v.ValidateFields("a:string:extra"), "only struct types may contain colons")
+}
+
+func TestGenerateEntity_NestedAndJSON(t *testing.T) {
+	cleanup := ensureTestUI(t)
+	defer cleanup()
+	chdirTemp(t)
+
+	sm := NewSafetyManager(false, true, false)
+	require.NoError(t, generateEntity("Customer", dslFields, true, false, false, false, false, false, false, true, "lowercase", sm))
+
+	entity := readParsedGo(t, filepath.Join("internal", "domain", "customer.go"))
+	assert.Contains(t, entity, `"gorm.io/datatypes"`)
+	assert.Contains(t, entity, "type ShippingAddress struct {")
+	assert.Contains(t, entity, "if c.ShippingAddress.ZipCode == \"\" {\n\t\treturn ErrInvalidCustomerShippingAddressZipCode")
+	assert.NotContains(t, entity, "type ShippingAddress string", "nested types are not stubbed")
+
+	errs := readParsedGo(t, filepath.Join("internal", "domain", "errors.go"))
+	assert.Contains(t, errs, "ErrInvalidCustomerShippingAddressZipCode")
+
+	seeds := readParsedGo(t, filepath.Join("internal", "domain", "customer_seeds.go"))
+	assert.Contains(t, seeds, `import "gorm.io/datatypes"`)
+	assert.Contains(t, seeds, `ShippingAddress: ShippingAddress{Street: "Sample Street 1", ZipCode: "Sample ZipCode 1"}`)
+	assert.Contains(t, seeds, "shipping_address_street, shipping_address_zip_code, metadata")
+
+	tests := readParsedGo(t, filepath.Join("internal", "domain", "customer_test.go"))
+	assert.Contains(t, tests, `"gorm.io/datatypes"`)
+	assert.Contains(t, tests, `ShippingAddress: ShippingAddress{Street: "valid value", ZipCode: "valid value"}`)
+
+	// A second entity reuses the nested type instead of redeclaring it.
+	require.NoError(t, generateEntity("Supplier", "shipping_address:struct{street:string}", false, false, false, false, false, false, false, false, "lowercase", sm))
+	supplier := readParsedGo(t, filepath.Join("internal", "domain", "supplier.go"))
+	assert.NotContains(t, supplier, "type ShippingAddress struct")
+
+	assert.Equal(t, "name:string,shipping_address:struct{street:string,zip_code:string},metadata:json", readEntityFieldsString("Customer"))
+}
+
+func TestGenerateDTO_NestedStyles(t *testing.T) {
+	chdirTemp(t)
+	dir := t.TempDir()
+	ops := []string{"create", "update"}
+
+	generateDTOFileWithFields(dir, "Customer", ops, true, dslFields, DTOStyleNested)
+	nested := readParsedGo(t, filepath.Join(dir, "dto.go"))
+	assert.Contains(t, nested, "ShippingAddress domain.ShippingAddress `json:\"shipping_address\"`")
+	assert.Contains(t, nested, "ShippingAddress *domain.ShippingAddress")
+	assert.Contains(t, nested, "Metadata        datatypes.JSON")
+	assert.Contains(t, nested, `"gorm.io/datatypes"`)
+
+	flatDir := t.TempDir()
+	generateDTOFileWithFields(flatDir, "Customer", ops, false, dslFields, DTOStyleFlat)
+	flat := readParsedGo(t, filepath.Join(flatDir, "dto.go"))
+	assert.Contains(t, flat, "ShippingAddressZipCode string")
+	assert.Contains(t, flat, "ShippingAddressZipCode *string")
+	assert.NotContains(t, flat, "domain.ShippingAddress")
+
+	var sb strings.Builder
+	generateCreateMethodWithFields(&sb, "customerService", "Customer", dslFields, DTOStyleFlat, false, false, false)
+	assert.Contains(t, sb.String(), "ShippingAddress: domain.ShippingAddress{\n\t\t\tStreet: input.ShippingAddressStreet,")
+	assert.Contains(t, sb.String(), "ShippingAddressZipCode: customer.ShippingAddress.ZipCode,")
+
+	sb.Reset()
+	generateUpdateMethodWithFields(&sb, "customerService", "Customer", dslFields, DTOStyleFlat, false)
+	assert.Contains(t, sb.String(), "customer.ShippingAddress.Street = *input.ShippingAddressStreet")
+}
+
+func TestGormRepository_JSONQueries(t *testing.T) {
+	chdirTemp(t)
+	dir := t.TempDir()
+	fields := parseFields("name:string,extra_data:json,address:struct{city:string}")
+
+	generateGormRepositoryWithFields(dir, "Customer", "Postgres", fields, false, false)
+	repo := readParsedGo(t, filepath.Join(dir, "postgres_customer_repository.go"))
+	assert.Contains(t, repo, `"gorm.io/datatypes"`)
+	assert.Contains(t, repo, "var _ CustomerJSONQueries = (*postgresCustomerRepository)(nil)")
+	assert.Contains(t, repo, `p.db.Where(datatypes.JSONQuery("extra_data").Equals(value, path...))`)
+	assert.Contains(t, repo, `datatypes.JSONQuery("extra_data").HasKey(path...)`)
+	assert.NotContains(t, repo, "FindByAddress", "embedded structs get no finder")
+
+	generateGormRepositoryWithFields(dir, "Product", "Postgres", parseFields("name:string"), false, false)
+	plain := readParsedGo(t, filepath.Join(dir, "postgres_product_repository.go"))
+	assert.NotContains(t, plain, "datatypes")
+}
+
+func TestGormColumnName(t *testing.T) {
+	for name, column := range map[string]string{
+		"Metadata":  "metadata",
+		"ExtraData": "extra_data",
+		"UserID":    "user_id",
+		"APIKey":    "api_key",
+		"Address2":  "address2",
+	} {
+		assert.Equal(t, column, gormColumnName(name), name)
+	}
+}
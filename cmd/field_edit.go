@@ -0,0 +1,507 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// goSource is a parsed Go file that is edited in place. Edits are recorded
+// against offsets in the original source and applied together, so code no edit
+// touches keeps its layout and comments.
+type goSource struct {
+	path  string
+	src   []byte
+	fset  *token.FileSet
+	file  *ast.File
+	edits []sourceEdit
+}
+
+// sourceEdit replaces src[start:end] with text.
+type sourceEdit struct {
+	start, end int
+	text       string
+}
+
+// parseGoSource reads and parses the Go file at path.
+func parseGoSource(path string) (*goSource, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return newGoSource(path, src)
+}
+
+// newGoSource parses src as the Go file at path.
+func newGoSource(path string, src []byte) (*goSource, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	return &goSource{path: path, src: src, fset: fset, file: file}, nil
+}
+
+func (s *goSource) offset(pos token.Pos) int {
+	return s.fset.Position(pos).Offset
+}
+
+// lineStart returns the offset of the first byte of the line holding off.
+func (s *goSource) lineStart(off int) int {
+	return bytes.LastIndexByte(s.src[:off], '\n') + 1
+}
+
+// lineEnd returns the offset of the newline ending the line holding off.
+func (s *goSource) lineEnd(off int) int {
+	if i := bytes.IndexByte(s.src[off:], '\n'); i >= 0 {
+		return off + i
+	}
+	return len(s.src)
+}
+
+// insert adds text at pos.
+func (s *goSource) insert(pos token.Pos, text string) {
+	off := s.offset(pos)
+	s.edits = append(s.edits, sourceEdit{start: off, end: off, text: text})
+}
+
+// insertLines adds text, which must end in a newline, before the line holding
+// pos.
+func (s *goSource) insertLines(pos token.Pos, text string) {
+	off := s.lineStart(s.offset(pos))
+	s.edits = append(s.edits, sourceEdit{start: off, end: off, text: text})
+}
+
+// insertLinesAfter adds text, which must end in a newline, after the line
+// holding pos.
+func (s *goSource) insertLinesAfter(pos token.Pos, text string) {
+	off := s.lineEnd(s.offset(pos))
+	if off < len(s.src) {
+		off++
+	}
+	s.edits = append(s.edits, sourceEdit{start: off, end: off, text: text})
+}
+
+// replace replaces node with text.
+func (s *goSource) replace(node ast.Node, text string) {
+	s.edits = append(s.edits, sourceEdit{start: s.offset(node.Pos()), end: s.offset(node.End()), text: text})
+}
+
+// remove deletes node and its trailing comma. A node on lines of its own is
+// removed with those lines and its doc comment.
+func (s *goSource) remove(node ast.Node) {
+	start, end := s.offset(node.Pos()), s.offset(node.End())
+	if doc := nodeDoc(node); doc != nil {
+		start = s.offset(doc.Pos())
+	}
+	rest := bytes.TrimLeft(s.src[end:], " \t")
+	if len(rest) > 0 && rest[0] == ',' {
+		end = len(s.src) - len(rest) + 1
+	}
+
+	lineStart, lineEnd := s.lineStart(start), s.lineEnd(end)
+	tail := strings.TrimSpace(string(s.src[end:lineEnd]))
+	if strings.TrimSpace(string(s.src[lineStart:start])) == "" && (tail == "" || strings.HasPrefix(tail, "//")) {
+		start, end = lineStart, lineEnd
+		if end < len(s.src) {
+			end++
+		}
+	} else {
+		end = len(s.src) - len(bytes.TrimLeft(s.src[end:], " \t"))
+	}
+	s.edits = append(s.edits, sourceEdit{start: start, end: end})
+}
+
+// nodeDoc returns the doc comment of a declaration or field.
+func nodeDoc(node ast.Node) *ast.CommentGroup {
+	switch n := node.(type) {
+	case *ast.Field:
+		return n.Doc
+	case *ast.FuncDecl:
+		return n.Doc
+	case *ast.GenDecl:
+		return n.Doc
+	case *ast.TypeSpec:
+		return n.Doc
+	case *ast.ValueSpec:
+		return n.Doc
+	}
+	return nil
+}
+
+// changed reports whether any edit was recorded.
+func (s *goSource) changed() bool {
+	return len(s.edits) > 0
+}
+
+// apply returns the source with every edit applied. Edits inside a removed
+// range are dropped; insertions at the same offset keep their order.
+func (s *goSource) apply() []byte {
+	edits := append([]sourceEdit(nil), s.edits...)
+	sort.SliceStable(edits, func(i, j int) bool {
+		a, b := edits[i], edits[j]
+		if a.start != b.start {
+			return a.start < b.start
+		}
+		// Insertions first, then the widest removal.
+		if aInsert, bInsert := a.start == a.end, b.start == b.end; aInsert != bInsert {
+			return aInsert
+		}
+		return a.end > b.end
+	})
+
+	var out bytes.Buffer
+	pos := 0
+	for _, e := range edits {
+		if e.start < pos {
+			continue
+		}
+		out.Write(s.src[pos:e.start])
+		out.WriteString(e.text)
+		pos = e.end
+	}
+	out.Write(s.src[pos:])
+	return out.Bytes()
+}
+
+// syncImports adds each import path among paths that src uses but does not
+// import, and drops those it imports but no longer uses. Other imports are
+// left alone.
+func syncImports(src []byte, paths ...string) ([]byte, error) {
+	s, err := newGoSource("", src)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	ast.Inspect(s.file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok && id.Obj == nil {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+
+	var missing []string
+	unused := make(map[*ast.ImportSpec]bool)
+	for _, p := range paths {
+		spec := findImport(s.file, p)
+		switch {
+		case spec == nil && used[path.Base(p)]:
+			missing = append(missing, p)
+		case spec != nil && spec.Name == nil && !used[path.Base(p)]:
+			unused[spec] = true
+		}
+	}
+
+	for _, decl := range s.file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		kept := 0
+		for _, spec := range gen.Specs {
+			if !unused[spec.(*ast.ImportSpec)] {
+				kept++
+			}
+		}
+		if kept == 0 {
+			s.remove(gen)
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if unused[spec.(*ast.ImportSpec)] {
+				s.remove(spec)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		s.addImports(missing)
+	}
+	return s.apply(), nil
+}
+
+// findImport returns the import of importPath in file, or nil.
+func findImport(file *ast.File, importPath string) *ast.ImportSpec {
+	quoted := `"` + importPath + `"`
+	for _, spec := range file.Imports {
+		if spec.Path.Value == quoted {
+			return spec
+		}
+	}
+	return nil
+}
+
+// addImports adds import paths to the first import declaration, creating one
+// after the package clause when the file has none.
+func (s *goSource) addImports(paths []string) {
+	specs := make([]string, len(paths))
+	for i, p := range paths {
+		specs[i] = fmt.Sprintf("%q", p)
+	}
+	s.addImportSpecs(specs)
+}
+
+// addImportSpecs adds import specs, such as `apphttp "mod/internal/handler/http"`,
+// to the import declaration of the file.
+func (s *goSource) addImportSpecs(specs []string) {
+	var lines strings.Builder
+	for _, spec := range specs {
+		lines.WriteString("\t" + spec + "\n")
+	}
+
+	for _, decl := range s.file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if gen.Lparen.IsValid() {
+			s.insertLinesAfter(gen.Lparen, lines.String())
+		} else {
+			s.insert(gen.Specs[0].Pos(), "(\n\t")
+			s.insert(gen.Specs[0].End(), "\n"+lines.String()+")")
+		}
+		return
+	}
+	s.insert(s.file.Name.End(), "\n\nimport (\n"+lines.String()+")")
+}
+
+// findTypeSpec returns the declaration of the named type in file.
+func findTypeSpec(file *ast.File, name string) (*ast.GenDecl, *ast.TypeSpec) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if ts := spec.(*ast.TypeSpec); ts.Name.Name == name {
+				return gen, ts
+			}
+		}
+	}
+	return nil, nil
+}
+
+// findStructType returns the struct type declared as name in file, or nil.
+func findStructType(file *ast.File, name string) *ast.StructType {
+	if _, ts := findTypeSpec(file, name); ts != nil {
+		if st, ok := ts.Type.(*ast.StructType); ok {
+			return st
+		}
+	}
+	return nil
+}
+
+// findMethod returns the method of typeName called name, or nil.
+func findMethod(file *ast.File, typeName, name string) *ast.FuncDecl {
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == name && fn.Recv != nil &&
+			len(fn.Recv.List) == 1 && receiverTypeName(fn.Recv.List[0].Type) == typeName {
+			return fn
+		}
+	}
+	return nil
+}
+
+// receiverVar returns the receiver name of a method.
+func receiverVar(fn *ast.FuncDecl) string {
+	if names := fn.Recv.List[0].Names; len(names) == 1 {
+		return names[0].Name
+	}
+	return ""
+}
+
+// structFieldNames returns the names declared by st.
+func structFieldNames(st *ast.StructType) map[string]bool {
+	names := make(map[string]bool)
+	for _, f := range st.Fields.List {
+		for _, name := range f.Names {
+			names[name.Name] = true
+		}
+	}
+	return names
+}
+
+// findTypeFile returns the non-test Go file in dir that declares typeName, or
+// "" when there is none.
+func findTypeFile(dir, typeName string) string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		if _, ts := findTypeSpec(parsed, typeName); ts != nil {
+			return file
+		}
+	}
+	return ""
+}
+
+// compositeLitType returns the type name of a composite literal such as E{}
+// or domain.E{}.
+func compositeLitType(lit *ast.CompositeLit) string {
+	switch t := lit.Type.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return ""
+}
+
+// typedLiterals returns the composite literals of the named types in node,
+// including elements whose type is elided inside slice and map literals.
+func typedLiterals(node ast.Node, types ...string) []*ast.CompositeLit {
+	wanted := make(map[string]bool)
+	for _, t := range types {
+		wanted[t] = true
+	}
+
+	var lits []*ast.CompositeLit
+	ast.Inspect(node, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		if wanted[compositeLitType(lit)] {
+			lits = append(lits, lit)
+			return true
+		}
+		var elem ast.Expr
+		switch t := lit.Type.(type) {
+		case *ast.ArrayType:
+			elem = t.Elt
+		case *ast.MapType:
+			elem = t.Value
+		}
+		if star, ok := elem.(*ast.StarExpr); ok {
+			elem = star.X
+		}
+		if elem == nil || !wanted[exprTypeName(elem)] {
+			return true
+		}
+		for _, el := range lit.Elts {
+			if kv, ok := el.(*ast.KeyValueExpr); ok {
+				el = kv.Value
+			}
+			if unary, ok := el.(*ast.UnaryExpr); ok {
+				el = unary.X
+			}
+			if inner, ok := el.(*ast.CompositeLit); ok && inner.Type == nil {
+				lits = append(lits, inner)
+			}
+		}
+		return true
+	})
+	return lits
+}
+
+// exprTypeName returns the name of an identifier or qualified type expression.
+func exprTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return ""
+}
+
+// literalKeys returns the keys set by a composite literal.
+func literalKeys(lit *ast.CompositeLit) map[string]bool {
+	keys := make(map[string]bool)
+	for _, el := range lit.Elts {
+		if kv, ok := el.(*ast.KeyValueExpr); ok {
+			if id, ok := kv.Key.(*ast.Ident); ok {
+				keys[id.Name] = true
+			}
+		}
+	}
+	return keys
+}
+
+// appendLiteralElements adds elements to a composite literal, keeping it on
+// one line when it is written on one line.
+func (s *goSource) appendLiteralElements(lit *ast.CompositeLit, elements []string) {
+	if len(elements) == 0 {
+		return
+	}
+	if s.fset.Position(lit.Lbrace).Line == s.fset.Position(lit.Rbrace).Line {
+		text := strings.Join(elements, ", ")
+		if len(lit.Elts) > 0 {
+			text = ", " + text
+		}
+		s.insert(lit.Rbrace, text)
+		return
+	}
+	var lines strings.Builder
+	for _, el := range elements {
+		lines.WriteString("\t" + el + ",\n")
+	}
+	s.insertLiteralLines(lit, lines.String())
+}
+
+// insertLiteralLines adds lines of elements, each ending in a comma, at the end
+// of a composite literal.
+func (s *goSource) insertLiteralLines(lit *ast.CompositeLit, lines string) {
+	if n := len(lit.Elts); n > 0 && !s.hasTrailingComma(lit.Elts[n-1]) {
+		s.insert(lit.Elts[n-1].End(), ",")
+	}
+	if s.fset.Position(lit.Lbrace).Line == s.fset.Position(lit.Rbrace).Line {
+		s.insert(lit.Rbrace, "\n"+lines)
+		return
+	}
+	s.insertLines(lit.Rbrace, lines)
+}
+
+// appendDecls adds declarations at the end of the file.
+func (s *goSource) appendDecls(text string) {
+	s.edits = append(s.edits, sourceEdit{start: len(s.src), end: len(s.src), text: "\n" + text})
+}
+
+// hasTrailingComma reports whether node is followed by a comma.
+func (s *goSource) hasTrailingComma(node ast.Node) bool {
+	rest := bytes.TrimLeft(s.src[s.offset(node.End()):], " \t")
+	return len(rest) > 0 && rest[0] == ','
+}
+
+// lastReturnNil returns the final "return nil" statement of fn, or nil.
+func lastReturnNil(fn *ast.FuncDecl) *ast.ReturnStmt {
+	if fn == nil || fn.Body == nil || len(fn.Body.List) == 0 {
+		return nil
+	}
+	ret, ok := fn.Body.List[len(fn.Body.List)-1].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return nil
+	}
+	if id, ok := ret.Results[0].(*ast.Ident); !ok || id.Name != "nil" {
+		return nil
+	}
+	return ret
+}
+
+// writeEditedGoFile applies the edits of s, syncs the given imports and writes
+// the file back. It reports whether the file changed.
+func writeEditedGoFile(s *goSource, imports []string, sm ...*SafetyManager) (bool, error) {
+	if !s.changed() {
+		return false, nil
+	}
+	content, err := syncImports(s.apply(), imports...)
+	if err != nil {
+		return false, fmt.Errorf("edit of %s does not parse: %w", s.path, err)
+	}
+	return true, writeGoFileMerged(s.path, string(content), sm...)
+}
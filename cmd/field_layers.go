@@ -0,0 +1,1385 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// entityImports are the imports field edits may add to or drop from a file.
+var entityImports = []string{"errors", "fmt", "slices", "strings", "time", "gorm.io/datatypes"}
+
+// findEntityFile returns the non-test file in internal/domain declaring the
+// entity struct.
+func findEntityFile(entity string) (string, error) {
+	file := findTypeFile(filepath.Join(DirInternal, DirDomain), entity)
+	if file == "" {
+		return "", fmt.Errorf("entity %s not found in %s", entity, filepath.Join(DirInternal, DirDomain))
+	}
+	return file, nil
+}
+
+// readEntityFields returns the fields of the entity struct in file, with the
+// fields of nested struct types declared next to it and the values of enum
+// fields.
+func readEntityFields(file *ast.File, entity string) []Field {
+	st := findStructType(file, entity)
+	if st == nil {
+		return nil
+	}
+	fields := structFields(file, st)
+	enums := entityEnums(file, entity)
+	for i := range fields {
+		fields[i].Enum = enums[fields[i].Name]
+	}
+	return fields
+}
+
+// structFields converts the fields of st to Field values.
+func structFields(file *ast.File, st *ast.StructType) []Field {
+	var fields []Field
+	for _, f := range st.Fields.List {
+		tag := ""
+		if f.Tag != nil {
+			tag = f.Tag.Value
+		}
+		typ := types.ExprString(f.Type)
+		var nested []Field
+		if typ == FieldFileType {
+			nested = fileMetadataFields()
+		} else if strings.Contains(tag, "embedded") {
+			if sub := findStructType(file, typ); sub != nil {
+				nested = structFields(file, sub)
+			}
+		}
+		for _, name := range f.Names {
+			fields = append(fields, Field{Name: name.Name, Type: typ, Tag: tag, Nested: nested})
+		}
+	}
+	return fields
+}
+
+// dtoFieldNames returns the DTO field names that carry fields: the field name,
+// and <Field><Sub> for the nested fields of flat DTOs.
+func dtoFieldNames(fields []Field) map[string]bool {
+	names := make(map[string]bool)
+	for _, field := range fields {
+		names[field.Name] = true
+		for _, sub := range field.Nested {
+			names[field.Name+sub.Name] = true
+		}
+	}
+	return names
+}
+
+// addEntityFields adds fields to the entity struct and their checks to its
+// Validate method. It reports whether the entity validates its fields.
+func addEntityFields(path, entity string, fields []Field, sm ...*SafetyManager) (bool, error) {
+	s, err := parseGoSource(path)
+	if err != nil {
+		return false, err
+	}
+	st := findStructType(s.file, entity)
+
+	// New fields go before the timestamps, like a freshly generated entity.
+	pos := st.Fields.Closing
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 1 && f.Names[0].Name != "ID" && isSystemField(f.Names[0].Name) {
+			pos = f.Pos()
+			if f.Doc != nil {
+				pos = f.Doc.Pos()
+			}
+			break
+		}
+	}
+	var lines strings.Builder
+	for _, field := range fields {
+		fmt.Fprintf(&lines, "\t%s %s %s\n", field.Name, field.Type, field.Tag)
+	}
+	s.insertLines(pos, lines.String())
+
+	validate := findMethod(s.file, entity, "Validate")
+	validation := validate != nil
+	if ret := lastReturnNil(validate); ret != nil {
+		recv := receiverVar(validate)
+		var checks strings.Builder
+		for _, field := range fields {
+			writeFieldChecks(&checks, recv, entity, field)
+		}
+		if checks.Len() > 0 {
+			s.insertLines(ret.Pos(), checks.String())
+		}
+	}
+
+	var decls strings.Builder
+	writeNestedStructs(&decls, filepath.Dir(path), "", entity, fields)
+	var stubbed []Field
+	for _, field := range fields {
+		if base := customTypeBase(field.Type); field.Nested == nil && base != "" && !domainTypeDeclared(filepath.Dir(path), "", base) {
+			stubbed = append(stubbed, field)
+		}
+	}
+	writeCustomTypeStubs(&decls, entity, stubbed)
+	writeEnumValues(&decls, entity, fields)
+	if decls.Len() > 0 {
+		s.appendDecls(decls.String())
+	}
+	addConstructorDefaults(s, entity, fields)
+	if fieldsUseType(fields, FieldFileType) {
+		generateDomainFileType(filepath.Dir(path), sm...)
+	}
+
+	_, err = writeEditedGoFile(s, entityImports, sm...)
+	return validation, err
+}
+
+// addConstructorDefaults sets the defaults of the new fields in New<Entity>.
+// Its parameters stay as they are, so its callers keep compiling; services
+// set the other new fields after the call.
+func addConstructorDefaults(s *goSource, entity string, fields []Field) {
+	ctor := findFunc(s.file, "New"+entity)
+	if ctor == nil || ctor.Body == nil {
+		return
+	}
+	var lines strings.Builder
+	for _, field := range fields {
+		if field.Default != "" {
+			fmt.Fprintf(&lines, "\t\t%s: %s,\n", field.Name, fieldDefaultLiteral(field))
+		}
+	}
+	if lits := typedLiterals(ctor.Body, entity); len(lits) > 0 && lines.Len() > 0 {
+		s.insertLiteralLines(lits[0], lines.String())
+	}
+}
+
+// addEntityTestFields gives the new fields valid values in every entity
+// literal of the domain tests. Where the entity has validation tests, the new
+// fields get their own invalid cases and field tests.
+func addEntityTestFields(entity string, allFields, fields []Field, sm ...*SafetyManager) error {
+	testFiles, _ := filepath.Glob(filepath.Join(DirInternal, DirDomain, "*_test.go"))
+	for _, path := range testFiles {
+		s, err := parseGoSource(path)
+		if err != nil {
+			return err
+		}
+		for _, lit := range typedLiterals(s.file, entity) {
+			if len(lit.Elts) == 0 {
+				continue
+			}
+			keys := literalKeys(lit)
+			var elements []string
+			for _, field := range fields {
+				if !keys[field.Name] {
+					elements = append(elements, field.Name+": "+getValidFieldValue(field))
+				}
+			}
+			s.appendLiteralElements(lit, elements)
+		}
+
+		if table := validateTestTable(s.file, entity); table != nil {
+			entityLower := strings.ToLower(string(entity[0])) + entity[1:]
+			var cases strings.Builder
+			for _, field := range fields {
+				if fieldHasValidationRule(field) {
+					generateInvalidTestCase(&cases, entity, entityLower, field, allFields)
+				}
+			}
+			if cases.Len() > 0 {
+				s.insertLiteralLines(table, cases.String())
+			}
+			var tests strings.Builder
+			generateFieldTestsFor(&tests, entity, fields, allFields)
+			if tests.Len() > 0 {
+				s.appendDecls(tests.String())
+			}
+		}
+
+		if _, err := writeEditedGoFile(s, entityImports, sm...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateTestTable returns the table of cases in Test<Entity>_Validate.
+func validateTestTable(file *ast.File, entity string) *ast.CompositeLit {
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Name.Name != "Test"+entity+"_Validate" {
+			continue
+		}
+		var table *ast.CompositeLit
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			if lit, ok := n.(*ast.CompositeLit); ok && table == nil {
+				if arr, ok := lit.Type.(*ast.ArrayType); ok {
+					if _, ok := arr.Elt.(*ast.StructType); ok {
+						table = lit
+					}
+				}
+			}
+			return table == nil
+		})
+		return table
+	}
+	return nil
+}
+
+// seedsFile returns the domain file declaring Get<Entity>Seeds.
+func seedsFile(entity string) string {
+	files, _ := filepath.Glob(filepath.Join(DirInternal, DirDomain, "*.go"))
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		s, err := parseGoSource(path)
+		if err != nil {
+			continue
+		}
+		if findFunc(s.file, "Get"+entity+"Seeds") != nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// findFunc returns the top-level function called name, or nil.
+func findFunc(file *ast.File, name string) *ast.FuncDecl {
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == name {
+			return fn
+		}
+	}
+	return nil
+}
+
+// addSeedFields adds sample values for the new fields to the Go and SQL seeds.
+func addSeedFields(entity string, fields []Field, sm ...*SafetyManager) error {
+	path := seedsFile(entity)
+	if path == "" {
+		return nil
+	}
+	s, err := parseGoSource(path)
+	if err != nil {
+		return err
+	}
+
+	for i, lit := range typedLiterals(findFunc(s.file, "Get"+entity+"Seeds"), entity) {
+		var elements []string
+		for _, field := range fields {
+			if value, ok := generateSampleValue(field, i+1); ok {
+				elements = append(elements, field.Name+": "+value)
+			}
+		}
+		s.appendLiteralElements(lit, elements)
+	}
+
+	editSQLSeeds(s, entity, func(record int, columns, values []string) ([]string, []string) {
+		return append(columns, getNonSystemFieldNames(fields)...), append(values, getSQLFieldValues(fields, record)...)
+	})
+
+	_, err = writeEditedGoFile(s, entityImports, sm...)
+	return err
+}
+
+// sqlInsertPattern matches the INSERT statements written by writeSQLSeeds.
+var sqlInsertPattern = regexp.MustCompile(`(?m)^(INSERT INTO \S+ \()(.*)(\) VALUES \()(.*)(\);)$`)
+
+// editSQLSeeds rewrites the columns and values of each INSERT statement in
+// GetSQL<Entity>Seeds. Records are numbered from 1.
+func editSQLSeeds(s *goSource, entity string, edit func(record int, columns, values []string) ([]string, []string)) {
+	fn := findFunc(s.file, "GetSQL"+entity+"Seeds")
+	if fn == nil {
+		return
+	}
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		lit, ok := n.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING || !strings.HasPrefix(lit.Value, "`") {
+			return true
+		}
+		record := 0
+		edited := sqlInsertPattern.ReplaceAllStringFunc(lit.Value, func(stmt string) string {
+			record++
+			m := sqlInsertPattern.FindStringSubmatch(stmt)
+			columns, values := edit(record, splitSQLList(m[2]), splitSQLList(m[4]))
+			return m[1] + strings.Join(columns, ", ") + m[3] + strings.Join(values, ", ") + m[5]
+		})
+		if edited != lit.Value {
+			s.edits = append(s.edits, sourceEdit{start: s.offset(lit.Pos()), end: s.offset(lit.End()), text: edited})
+		}
+		return false
+	})
+}
+
+// splitSQLList splits a comma-separated SQL list, keeping commas inside quoted
+// strings.
+func splitSQLList(list string) []string {
+	var items []string
+	var cur strings.Builder
+	quoted := false
+	for _, r := range list {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			items = append(items, strings.TrimSpace(cur.String()))
+			cur.Reset()
+			continue
+		}
+		cur.WriteRune(r)
+	}
+	if strings.TrimSpace(cur.String()) != "" {
+		items = append(items, strings.TrimSpace(cur.String()))
+	}
+	return items
+}
+
+// addDTOFields adds the new fields to the create, output and update DTOs and to
+// the create DTO's Validate method.
+func addDTOFields(entity string, fields []Field, dtoStyle string, sm ...*SafetyManager) error {
+	path := findTypeFile(filepath.Join(DirInternal, DirUseCase), "Create"+entity+"Input")
+	if path == "" {
+		return nil
+	}
+	s, err := parseGoSource(path)
+	if err != nil {
+		return err
+	}
+	dto := dtoFields(fields, dtoStyle)
+	settable := withoutComputedFields(dto)
+
+	validate := findMethod(s.file, "Create"+entity+"Input", "Validate")
+	if st := findStructType(s.file, "Create"+entity+"Input"); st != nil {
+		var lines strings.Builder
+		for _, field := range settable {
+			writeCreateInputField(&lines, entity, field, validate != nil)
+		}
+		s.insertLines(st.Fields.Closing, lines.String())
+	}
+	if ret := lastReturnNil(validate); ret != nil {
+		var checks strings.Builder
+		for _, field := range settable {
+			writeCreateInputValidation(&checks, receiverVar(validate), field)
+		}
+		if checks.Len() > 0 {
+			s.insertLines(ret.Pos(), checks.String())
+		}
+	}
+	if st := findStructType(s.file, "Create"+entity+"Output"); st != nil {
+		pos := st.Fields.Closing
+		for _, f := range st.Fields.List {
+			if len(f.Names) == 1 && f.Names[0].Name == "Message" {
+				pos = f.Pos()
+			}
+		}
+		var lines strings.Builder
+		for _, field := range dto {
+			writeCreateOutputField(&lines, field)
+		}
+		s.insertLines(pos, lines.String())
+	}
+	if st := findStructType(s.file, "Update"+entity+"Input"); st != nil {
+		validation := strings.Contains(string(s.src[s.offset(st.Pos()):s.offset(st.End())]), `validate:"`)
+		var lines strings.Builder
+		for _, field := range settable {
+			writeUpdateInputField(&lines, entity, field, validation)
+		}
+		s.insertLines(st.Fields.Closing, lines.String())
+	}
+
+	_, err = writeEditedGoFile(s, entityImports, sm...)
+	return err
+}
+
+// addHandlerEnums registers the validations of the new enum fields, which the
+// DTOs name in their validate tags, in the entity's HTTP handler when it
+// validates requests.
+func addHandlerEnums(entity string, fields []Field, sm ...*SafetyManager) error {
+	var lines strings.Builder
+	for _, field := range fields {
+		if len(field.Enum) > 0 {
+			writeEnumRegistration(&lines, entity, field.Name)
+		}
+	}
+	path := findTypeFile(filepath.Join(DirInternal, DirHandler, DirHTTP), entity+"Handler")
+	if lines.Len() == 0 || path == "" {
+		return nil
+	}
+	s, err := parseGoSource(path)
+	if err != nil {
+		return err
+	}
+	if !bytes.Contains(s.src, []byte("validateRequest(")) {
+		return nil
+	}
+
+	if init := findFunc(s.file, "init"); init != nil {
+		s.insertLines(init.Body.Rbrace, lines.String())
+	} else {
+		s.appendDecls("func init() {\n" + lines.String() + "}\n")
+	}
+	// The domain import joins the project's imports.
+	importPath := getImportPath(getModuleName())
+	domainImport := strconv.Quote(importPath + "/internal/domain")
+	imported := false
+	for _, spec := range s.file.Imports {
+		imported = imported || spec.Path.Value == domainImport
+	}
+	for _, spec := range s.file.Imports {
+		if !imported && spec.Path.Value == strconv.Quote(importPath+"/internal/usecase") {
+			s.insertLines(spec.Pos(), "\t"+domainImport+"\n")
+			imported = true
+		}
+	}
+	if !imported {
+		s.addImports([]string{importPath + "/internal/domain"})
+	}
+
+	_, err = writeEditedGoFile(s, nil, sm...)
+	return err
+}
+
+// findServiceFiles returns the use case files whose services implement
+// Create<Entity> or Update<Entity>: the combined service and, with --cqrs,
+// the command service.
+func findServiceFiles(entity string) []string {
+	var paths []string
+	files, _ := filepath.Glob(filepath.Join(DirInternal, DirUseCase, "*.go"))
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		s, err := parseGoSource(path)
+		if err != nil {
+			continue
+		}
+		for _, decl := range s.file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv != nil && fn.Body != nil &&
+				(fn.Name.Name == "Create"+entity || fn.Name.Name == "Update"+entity) {
+				paths = append(paths, path)
+				break
+			}
+		}
+	}
+	return paths
+}
+
+// addServiceMappings maps the new fields in the services' Create<Entity> and
+// Update<Entity> methods.
+func addServiceMappings(entity string, fields []Field, dtoStyle string, sm ...*SafetyManager) error {
+	for _, path := range findServiceFiles(entity) {
+		if err := addServiceFileMappings(path, entity, fields, dtoStyle, sm...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addServiceFileMappings maps the new fields in the service methods of path.
+func addServiceFileMappings(path, entity string, fields []Field, dtoStyle string, sm ...*SafetyManager) error {
+	s, err := parseGoSource(path)
+	if err != nil {
+		return err
+	}
+
+	for _, decl := range s.file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || fn.Body == nil {
+			continue
+		}
+		switch fn.Name.Name {
+		case "Create" + entity:
+			addCreateMappings(s, fn, entity, fields, dtoStyle)
+		case "Update" + entity:
+			addUpdateMappings(s, fn, withoutComputedFields(fields), dtoStyle)
+		}
+	}
+
+	_, err = writeEditedGoFile(s, entityImports, sm...)
+	return err
+}
+
+// addCreateMappings copies the new fields from the input into the entity and
+// from the entity into the output of Create<Entity>. Defaulted fields start at
+// their default and take the input when it is set; an entity built with
+// New<Entity> gets the new fields assigned after the call.
+func addCreateMappings(s *goSource, fn *ast.FuncDecl, entity string, fields []Field, dtoStyle string) {
+	settable := withoutComputedFields(fields)
+	entityVar := ""
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 || entityVar != "" {
+			return true
+		}
+		id, isIdent := assign.Lhs[0].(*ast.Ident)
+		if !isIdent {
+			return true
+		}
+		var overrides strings.Builder
+		if lit, ok := assign.Rhs[0].(*ast.CompositeLit); ok && compositeLitType(lit) == entity {
+			entityVar = id.Name
+			var lines strings.Builder
+			for _, field := range settable {
+				if field.Default != "" {
+					fmt.Fprintf(&lines, "\t\t%s: %s,\n", field.Name, fieldDefaultLiteral(field))
+					continue
+				}
+				writeCreateMapping(&lines, field, dtoStyle)
+			}
+			s.insertLiteralLines(lit, lines.String())
+		} else if isConstructorCall(assign.Rhs[0], entity) {
+			entityVar = id.Name
+			for _, field := range settable {
+				if field.Default == "" {
+					fmt.Fprintf(&overrides, "\t%s.%s = %s\n", entityVar, field.Name, createInputValue(field, dtoStyle))
+				}
+			}
+		} else {
+			return true
+		}
+		writeDefaultOverrides(&overrides, entityVar, settable)
+		if overrides.Len() > 0 {
+			s.insertLinesAfter(assign.End(), overrides.String())
+		}
+		return false
+	})
+	if entityVar == "" {
+		return
+	}
+
+	for _, lit := range typedLiterals(fn.Body, "Create"+entity+"Output") {
+		if len(lit.Elts) == 0 {
+			continue
+		}
+		var lines strings.Builder
+		for _, field := range fields {
+			writeCreateOutputMapping(&lines, entityVar, field, dtoStyle)
+		}
+		message := literalElement(lit, "Message")
+		if message == nil {
+			s.insertLiteralLines(lit, lines.String())
+			continue
+		}
+		s.insertLines(message.Pos(), lines.String())
+	}
+}
+
+// isConstructorCall reports whether expr calls New<Entity>, dereferenced or
+// not.
+func isConstructorCall(expr ast.Expr, entity string) bool {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	call, ok := expr.(*ast.CallExpr)
+	return ok && exprTypeName(call.Fun) == "New"+entity
+}
+
+// literalElement returns the element of lit with the given key, or nil.
+func literalElement(lit *ast.CompositeLit, key string) *ast.KeyValueExpr {
+	for _, el := range lit.Elts {
+		if kv, ok := el.(*ast.KeyValueExpr); ok {
+			if id, ok := kv.Key.(*ast.Ident); ok && id.Name == key {
+				return kv
+			}
+		}
+	}
+	return nil
+}
+
+// addUpdateMappings applies the new optional fields in Update<Entity>, after
+// the existing "if input.X != nil" blocks.
+func addUpdateMappings(s *goSource, fn *ast.FuncDecl, fields []Field, dtoStyle string) {
+	var entityVar string
+	var last ast.Stmt
+	for _, stmt := range fn.Body.List {
+		switch st := stmt.(type) {
+		case *ast.AssignStmt:
+			if call, ok := st.Rhs[0].(*ast.CallExpr); ok && entityVar == "" {
+				if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "FindByID" {
+					if id, ok := st.Lhs[0].(*ast.Ident); ok {
+						entityVar = id.Name
+					}
+				}
+			}
+		case *ast.IfStmt:
+			if cond, ok := st.Cond.(*ast.BinaryExpr); ok && cond.Op == token.NEQ {
+				if sel, ok := cond.X.(*ast.SelectorExpr); ok && exprTypeName(sel.X) == "input" {
+					last = st
+				}
+			}
+		}
+	}
+	if entityVar == "" {
+		return
+	}
+
+	var lines strings.Builder
+	for _, field := range fields {
+		writeUpdateMapping(&lines, entityVar, field, dtoStyle)
+	}
+	if last != nil {
+		s.insertLinesAfter(last.End(), lines.String())
+		return
+	}
+	if n := len(fn.Body.List); n > 0 {
+		s.insertLines(fn.Body.List[n-1].Pos(), lines.String())
+	}
+}
+
+// addRepositoryFinders declares the finders of the new searchable fields and
+// the aggregates of the new numeric fields in the repository interfaces and
+// implements them in every repository and mock of the entity. Multi-column
+// finders may match any field in known. Aggregates need the <Entity>Spec of
+// repositories generated with Count.
+func addRepositoryFinders(entity string, fields, known []Field, sm ...*SafetyManager) error {
+	methods := generateSearchMethods(fields, entity)
+	if hasDeclaredFinders(fields) {
+		methods = declaredSearchMethods(fields, known, entity)
+	}
+	if findTypeFile(filepath.Join(DirInternal, DirRepository), entity+"Spec") != "" {
+		methods = append(methods, generateAggregateMethods(fields, entity)...)
+	}
+	if len(methods) == 0 {
+		return nil
+	}
+
+	if err := addInterfaceFinders(entity, methods, sm...); err != nil {
+		return err
+	}
+
+	for _, dir := range []string{filepath.Join(DirInternal, DirRepository), filepath.Join(DirInternal, "mocks")} {
+		files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+		for _, path := range files {
+			if strings.HasSuffix(path, "_test.go") {
+				continue
+			}
+			s, err := parseGoSource(path)
+			if err != nil {
+				return err
+			}
+			var impls strings.Builder
+			for _, repo := range entityRepositories(s.file, entity) {
+				for _, m := range methods {
+					if findMethod(s.file, repo.name, m.MethodName) == nil {
+						writeRepositoryFinder(&impls, s.file, repo, entity, m)
+					}
+				}
+			}
+			if impls.Len() > 0 {
+				s.appendDecls(impls.String())
+			}
+			imports := []string{"fmt", "context", getImportPath(getModuleName()) + "/internal/repository"}
+			if _, err := writeEditedGoFile(s, imports, sm...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addInterfaceFinders declares finders in <Entity>Searcher, creating it for
+// segregated interfaces, or in <Entity>Repository.
+func addInterfaceFinders(entity string, methods []SearchMethod, sm ...*SafetyManager) error {
+	path := findTypeFile(filepath.Join(DirInternal, DirRepository), entity+"Repository")
+	if path == "" {
+		return nil
+	}
+	s, err := parseGoSource(path)
+	if err != nil {
+		return err
+	}
+
+	var signatures strings.Builder
+	for _, m := range methods {
+		if !interfaceDeclares(s.file, entity, m.MethodName) {
+			signatures.WriteString(m.generateSearchMethodSignature() + "\n")
+		}
+	}
+	if signatures.Len() == 0 {
+		return nil
+	}
+
+	_, repository := findTypeSpec(s.file, entity+"Repository")
+	repoIface, _ := repository.Type.(*ast.InterfaceType)
+	switch {
+	case findInterfaceType(s.file, entity+"Searcher") != nil:
+		s.insertLines(findInterfaceType(s.file, entity+"Searcher").Methods.Closing, signatures.String())
+	case findInterfaceType(s.file, entity+"Writer") != nil && repoIface != nil:
+		writer, _ := findTypeSpec(s.file, entity+"Writer")
+		var searcher strings.Builder
+		writeSearcherInterface(&searcher, entity, signatures.String())
+		s.insertLinesAfter(writer.End(), "\n"+strings.TrimSuffix(searcher.String(), "\n"))
+		s.insertLines(repoIface.Methods.Closing, "\t"+entity+"Searcher\n")
+	case repoIface != nil:
+		// Finders follow FindByID and any existing finder.
+		pos := repoIface.Methods.Closing
+		for _, m := range repoIface.Methods.List {
+			if len(m.Names) == 1 && strings.HasPrefix(m.Names[0].Name, "FindBy") {
+				pos = m.End()
+			}
+		}
+		if pos == repoIface.Methods.Closing {
+			s.insertLines(pos, signatures.String())
+		} else {
+			s.insertLinesAfter(pos, signatures.String())
+		}
+	}
+
+	_, err = writeEditedGoFile(s, nil, sm...)
+	return err
+}
+
+// findInterfaceType returns the interface type declared as name in file, or nil.
+func findInterfaceType(file *ast.File, name string) *ast.InterfaceType {
+	if _, ts := findTypeSpec(file, name); ts != nil {
+		if it, ok := ts.Type.(*ast.InterfaceType); ok {
+			return it
+		}
+	}
+	return nil
+}
+
+// interfaceDeclares reports whether an interface of the entity declares method.
+func interfaceDeclares(file *ast.File, entity, method string) bool {
+	for _, suffix := range []string{"Repository", "Reader", "Writer", "Searcher"} {
+		if it := findInterfaceType(file, entity+suffix); it != nil {
+			for _, m := range it.Methods.List {
+				if len(m.Names) == 1 && m.Names[0].Name == method {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// entityRepository is a type implementing an entity's repository.
+type entityRepository struct {
+	name   string
+	recv   string
+	fields map[string]bool
+}
+
+// entityRepositories returns the types in file whose FindByID returns the
+// entity: repositories, cache decorators and mocks.
+func entityRepositories(file *ast.File, entity string) []entityRepository {
+	var repos []entityRepository
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || fn.Name.Name != "FindByID" || fn.Type.Results == nil || len(fn.Type.Results.List) == 0 {
+			continue
+		}
+		star, ok := fn.Type.Results.List[0].Type.(*ast.StarExpr)
+		if !ok || exprTypeName(star.X) != entity {
+			continue
+		}
+		repo := entityRepository{name: receiverTypeName(fn.Recv.List[0].Type), recv: receiverVar(fn)}
+		if st := findStructType(file, repo.name); st != nil {
+			repo.fields = structFieldNames(st)
+		}
+		repos = append(repos, repo)
+	}
+	return repos
+}
+
+// writeRepositoryFinder writes one finder in the style of the repository: a
+// GORM or MongoDB query, a delegate for the cache and resilience decorators
+// and mocks, or a FindAll scan for the other backends. Elasticsearch and
+// DynamoDB use the specQuery and scanInput helpers of their repository, and
+// their finders the searchDocuments and scanAll helpers when it has them.
+func writeRepositoryFinder(b *strings.Builder, file *ast.File, repo entityRepository, entity string, m SearchMethod) {
+	switch {
+	case m.isAggregate() && findMethod(file, repo.name, "specQuery") != nil:
+		writeElasticsearchAggregate(b, repo.name, m)
+	case m.isAggregate() && findMethod(file, repo.name, "scanInput") != nil:
+		writeDynamoDBAggregate(b, repo.name, entity, m)
+	case !m.isAggregate() && findMethod(file, repo.name, "searchDocuments") != nil:
+		writeElasticsearchFinder(b, repo.name, entity, m)
+	case !m.isAggregate() && findMethod(file, repo.name, "scanAll") != nil:
+		writeDynamoDBFinder(b, repo.name, entity, m)
+	case strings.HasPrefix(repo.name, "Mock"):
+		writeMockFinder(b, entity, m)
+	case repo.name == "Cached"+entity+"Repository" && repo.fields["inner"]:
+		generateCacheSearchMethodDelegate(b, entity, m)
+	case repo.name == "Resilient"+entity+"Repository" && repo.fields["inner"]:
+		writeResilientFinder(b, entity, m)
+	case repo.fields["db"]:
+		b.WriteString(m.generateSearchMethodImplementation(repo.recv, repo.name, entity))
+	case repo.fields["collection"]:
+		doc := readMongoDocument(entity, nil)
+		b.WriteString(generateMongoSearchMethodImplementation(m, repo.name, entity, doc))
+		if m.Kind == SearchCriteria && file.Scope.Lookup("mongo"+entity+"Keys") == nil {
+			writeMongoKeys(b, entity, doc)
+		}
+	case findMethod(file, repo.name, "FindAll") != nil:
+		writeDelegatingFinder(b, repo.recv, repo.name, entity, m)
+	}
+}
+
+// usesFields reports whether node reads or writes one of names through a
+// selector such as p.Name. Nodes in skip are ignored.
+func usesFields(node ast.Node, names map[string]bool, skip map[ast.Node]bool) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if found || skip[n] {
+			return false
+		}
+		if sel, ok := n.(*ast.SelectorExpr); ok && names[sel.Sel.Name] {
+			// A selector on an unresolved identifier is a package member.
+			if id, ok := sel.X.(*ast.Ident); !ok || id.Obj != nil {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// renameFieldUses renames the uses of the field from to to in the methods of
+// the entity other than Validate, whose checks are regenerated for the renamed
+// field, so hand-written business rules keep working.
+func renameFieldUses(path, entity, from, to string, sm ...*SafetyManager) error {
+	s, err := parseGoSource(path)
+	if err != nil {
+		return err
+	}
+	names := map[string]bool{from: true}
+	for _, decl := range s.file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || fn.Name.Name == "Validate" || receiverTypeName(fn.Recv.List[0].Type) != entity {
+			continue
+		}
+		ast.Inspect(fn, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok && usesFields(sel, names, nil) && sel.Sel.Name == from {
+				s.replace(sel.Sel, to)
+			}
+			return true
+		})
+	}
+	_, err = writeEditedGoFile(s, entityImports, sm...)
+	return err
+}
+
+// removeFieldUses removes the top-level statements of fn that use one of
+// names. A function whose return statement uses them is removed entirely.
+func removeFieldUses(s *goSource, fn *ast.FuncDecl, names map[string]bool, skip map[ast.Node]bool) {
+	if fn.Body == nil {
+		return
+	}
+	var stmts []ast.Stmt
+	for _, stmt := range fn.Body.List {
+		if !usesFields(stmt, names, skip) {
+			continue
+		}
+		if _, ok := stmt.(*ast.ReturnStmt); ok {
+			s.remove(fn)
+			return
+		}
+		stmts = append(stmts, stmt)
+	}
+	for _, stmt := range stmts {
+		s.remove(stmt)
+	}
+}
+
+// removeStructFields removes the fields in names from st.
+func removeStructFields(s *goSource, st *ast.StructType, names map[string]bool) {
+	if st == nil {
+		return
+	}
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 1 && names[f.Names[0].Name] {
+			s.remove(f)
+		}
+	}
+}
+
+// removeLiteralElements removes the elements keyed by names from the literals
+// of the given types in node and returns the removed elements.
+func removeLiteralElements(s *goSource, node ast.Node, names map[string]bool, types ...string) map[ast.Node]bool {
+	removed := make(map[ast.Node]bool)
+	for _, lit := range typedLiterals(node, types...) {
+		for _, el := range lit.Elts {
+			if kv, ok := el.(*ast.KeyValueExpr); ok {
+				if id, ok := kv.Key.(*ast.Ident); ok && names[id.Name] {
+					s.remove(kv)
+					removed[kv] = true
+				}
+			}
+		}
+	}
+	return removed
+}
+
+// fieldNames returns the names of fields.
+func fieldNames(fields []Field) map[string]bool {
+	names := make(map[string]bool)
+	for _, field := range fields {
+		names[field.Name] = true
+	}
+	return names
+}
+
+// removeEntityFields removes fields from the entity struct, together with the
+// statements of its methods that use them and nested struct types declared
+// only for them.
+func removeEntityFields(path, entity string, fields []Field, sm ...*SafetyManager) error {
+	s, err := parseGoSource(path)
+	if err != nil {
+		return err
+	}
+	names := fieldNames(fields)
+	removeStructFields(s, findStructType(s.file, entity), names)
+	for _, decl := range s.file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv != nil && receiverTypeName(fn.Recv.List[0].Type) == entity {
+			removeFieldUses(s, fn, names, nil)
+		}
+	}
+
+	for _, field := range fields {
+		if field.Nested == nil {
+			continue
+		}
+		gen, ts := findTypeSpec(s.file, field.Type)
+		if ts == nil || domainTypeReferenced(filepath.Dir(path), path, field.Type) {
+			continue
+		}
+		if len(gen.Specs) == 1 {
+			s.remove(gen)
+		} else {
+			s.remove(ts)
+		}
+	}
+	removeEnumValues(s, entity, fields)
+
+	_, err = writeEditedGoFile(s, entityImports, sm...)
+	return err
+}
+
+// removeEnumValues removes the <Entity><Field>Values declarations of the
+// fields.
+func removeEnumValues(s *goSource, entity string, fields []Field) {
+	for _, decl := range s.file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR || len(gen.Specs) != 1 {
+			continue
+		}
+		vs := gen.Specs[0].(*ast.ValueSpec)
+		for _, field := range fields {
+			if len(vs.Names) == 1 && vs.Names[0].Name == entity+field.Name+"Values" {
+				s.remove(gen)
+			}
+		}
+	}
+}
+
+// removeHandlerEnums removes the registrations of the fields' enum
+// validations from the entity's HTTP handler, and its init function once it
+// registers none.
+func removeHandlerEnums(entity string, fields []Field, sm ...*SafetyManager) error {
+	path := findTypeFile(filepath.Join(DirInternal, DirHandler, DirHTTP), entity+"Handler")
+	if path == "" {
+		return nil
+	}
+	s, err := parseGoSource(path)
+	if err != nil {
+		return err
+	}
+	init := findFunc(s.file, "init")
+	if init == nil {
+		return nil
+	}
+	values := make(map[string]bool)
+	for _, field := range fields {
+		values[entity+field.Name+"Values"] = true
+	}
+	var registrations []ast.Stmt
+	for _, stmt := range init.Body.List {
+		expr, ok := stmt.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		if call, ok := expr.X.(*ast.CallExpr); ok && len(call.Args) == 2 {
+			if sel, ok := call.Args[1].(*ast.SelectorExpr); ok && values[sel.Sel.Name] {
+				registrations = append(registrations, stmt)
+			}
+		}
+	}
+	if len(registrations) == 0 {
+		return nil
+	}
+	if len(registrations) == len(init.Body.List) {
+		s.remove(init)
+	} else {
+		for _, stmt := range registrations {
+			s.remove(stmt)
+		}
+	}
+
+	_, err = writeEditedGoFile(s, []string{getImportPath(getModuleName()) + "/internal/domain"}, sm...)
+	return err
+}
+
+// domainTypeReferenced reports whether a Go file in dir other than exclude
+// mentions typeName as a type outside composite literals, such as the seeds of
+// the removed field. Selectors and keys of the same name are not references.
+func domainTypeReferenced(dir, exclude, typeName string) bool {
+	pattern := regexp.MustCompile(`(?:^|[^.\w])` + regexp.QuoteMeta(typeName) + `(?:[^{:\w]|$)`)
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, path := range files {
+		if filepath.Clean(path) == filepath.Clean(exclude) || strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		if data, err := os.ReadFile(path); err == nil && pattern.Match(data) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeEntityTestFields removes the fields from the entity literals of the
+// domain tests, along with their invalid cases, field tests and assertions.
+func removeEntityTestFields(entity string, fields []Field, sm ...*SafetyManager) error {
+	names := fieldNames(fields)
+	testFiles, _ := filepath.Glob(filepath.Join(DirInternal, DirDomain, "*_test.go"))
+	for _, path := range testFiles {
+		s, err := parseGoSource(path)
+		if err != nil {
+			return err
+		}
+		skip := removeLiteralElements(s, s.file, names, entity)
+
+		if table := validateTestTable(s.file, entity); table != nil {
+			for _, el := range table.Elts {
+				if lit, ok := el.(*ast.CompositeLit); ok && invalidCaseFor(lit, fields) {
+					s.remove(lit)
+				}
+			}
+		}
+
+		for _, decl := range s.file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || !strings.HasPrefix(fn.Name.Name, "Test"+entity+"_") {
+				continue
+			}
+			if field := strings.SplitN(strings.TrimPrefix(fn.Name.Name, "Test"+entity+"_"), "_", 2)[0]; names[field] {
+				s.remove(fn)
+				continue
+			}
+			removeFieldUses(s, fn, names, skip)
+		}
+
+		if _, err := writeEditedGoFile(s, entityImports, sm...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// invalidCaseFor reports whether a Test<Entity>_Validate case expects the
+// error of one of fields.
+func invalidCaseFor(lit *ast.CompositeLit, fields []Field) bool {
+	kv := literalElement(lit, "errMsg")
+	if kv == nil {
+		return false
+	}
+	msg, ok := kv.Value.(*ast.BasicLit)
+	if !ok {
+		return false
+	}
+	for _, field := range fields {
+		if strings.EqualFold(strings.Trim(msg.Value, "\"`"), field.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeSeedFields removes the fields from the Go and SQL seeds.
+func removeSeedFields(entity string, fields []Field, sm ...*SafetyManager) error {
+	path := seedsFile(entity)
+	if path == "" {
+		return nil
+	}
+	s, err := parseGoSource(path)
+	if err != nil {
+		return err
+	}
+	removeLiteralElements(s, findFunc(s.file, "Get"+entity+"Seeds"), fieldNames(fields), entity)
+
+	dropped := make(map[string]bool)
+	for _, column := range getNonSystemFieldNames(fields) {
+		dropped[column] = true
+	}
+	editSQLSeeds(s, entity, func(_ int, columns, values []string) ([]string, []string) {
+		var keptColumns, keptValues []string
+		for i, column := range columns {
+			if dropped[column] || i >= len(values) {
+				continue
+			}
+			keptColumns = append(keptColumns, column)
+			keptValues = append(keptValues, values[i])
+		}
+		return keptColumns, keptValues
+	})
+
+	_, err = writeEditedGoFile(s, entityImports, sm...)
+	return err
+}
+
+// removeDTOFields removes the fields from the create, output and update DTOs
+// and their checks from the DTO methods.
+func removeDTOFields(entity string, fields []Field, sm ...*SafetyManager) error {
+	path := findTypeFile(filepath.Join(DirInternal, DirUseCase), "Create"+entity+"Input")
+	if path == "" {
+		return nil
+	}
+	s, err := parseGoSource(path)
+	if err != nil {
+		return err
+	}
+	names := dtoFieldNames(fields)
+	dtos := []string{"Create" + entity + "Input", "Create" + entity + "Output", "Update" + entity + "Input"}
+	for _, dto := range dtos {
+		removeStructFields(s, findStructType(s.file, dto), names)
+	}
+	for _, decl := range s.file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv != nil && contains(dtos, receiverTypeName(fn.Recv.List[0].Type)) {
+			removeFieldUses(s, fn, names, nil)
+		}
+	}
+
+	_, err = writeEditedGoFile(s, entityImports, sm...)
+	return err
+}
+
+// removeServiceMappings removes the mappings of the fields from the services'
+// Create<Entity> and Update<Entity> methods.
+func removeServiceMappings(entity string, fields []Field, sm ...*SafetyManager) error {
+	for _, path := range findServiceFiles(entity) {
+		if err := removeServiceFileMappings(path, entity, fields, sm...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeServiceFileMappings removes the mappings of the fields from the
+// service methods of path.
+func removeServiceFileMappings(path, entity string, fields []Field, sm ...*SafetyManager) error {
+	s, err := parseGoSource(path)
+	if err != nil {
+		return err
+	}
+	names := dtoFieldNames(fields)
+	skip := removeLiteralElements(s, s.file, names, entity, "Create"+entity+"Output")
+	for _, decl := range s.file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv != nil &&
+			(fn.Name.Name == "Create"+entity || fn.Name.Name == "Update"+entity) {
+			removeFieldUses(s, fn, names, skip)
+		}
+	}
+
+	_, err = writeEditedGoFile(s, entityImports, sm...)
+	return err
+}
+
+// removeRepositoryFinders removes the finders and JSON queries of the fields
+// from the repository interfaces, implementations and mocks. Interfaces left
+// empty are removed with the declarations that refer to them.
+func removeRepositoryFinders(entity string, fields []Field, sm ...*SafetyManager) error {
+	methods := make(map[string]bool)
+	removed := make(map[string]bool)
+	for _, field := range fields {
+		removed[field.Name] = true
+		methods["FindBy"+field.Name+"Path"] = true
+		methods["FindWith"+field.Name+"Key"] = true
+	}
+	// Finders matching a removed field, alone or with other fields.
+	declared, _ := declaredFinders(entity)
+	for _, finder := range declared {
+		for _, column := range finder.columns() {
+			if removed[column.Name] {
+				methods[finder.MethodName] = true
+			}
+		}
+	}
+	for _, field := range fields {
+		methods["FindBy"+field.Name] = true
+	}
+
+	for _, dir := range []string{filepath.Join(DirInternal, DirRepository), filepath.Join(DirInternal, "mocks")} {
+		files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+		for _, path := range files {
+			if strings.HasSuffix(path, "_test.go") {
+				continue
+			}
+			s, err := parseGoSource(path)
+			if err != nil {
+				return err
+			}
+
+			emptied := removeInterfaceMethods(s, entity, methods)
+			repos := make(map[string]bool)
+			for _, repo := range entityRepositories(s.file, entity) {
+				repos[repo.name] = true
+			}
+			for _, decl := range s.file.Decls {
+				if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv != nil && methods[fn.Name.Name] && repos[receiverTypeName(fn.Recv.List[0].Type)] {
+					s.remove(fn)
+				}
+			}
+			removeInterfaceUses(s, emptied)
+
+			if _, err := writeEditedGoFile(s, entityImports, sm...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// declaredFinders returns the finders declared by the entity's repository
+// interfaces, and whether the interfaces exist.
+func declaredFinders(entity string) ([]SearchMethod, bool) {
+	path := findTypeFile(filepath.Join(DirInternal, DirRepository), entity+"Repository")
+	if path == "" {
+		return nil, false
+	}
+	s, err := parseGoSource(path)
+	if err != nil {
+		return nil, false
+	}
+	var methods []SearchMethod
+	for _, suffix := range []string{"Repository", "Reader", "Writer", "Searcher"} {
+		it := findInterfaceType(s.file, entity+suffix)
+		if it == nil {
+			continue
+		}
+		for _, m := range it.Methods.List {
+			if method, ok := interfaceFinder(m, entity); ok {
+				methods = append(methods, method)
+			}
+		}
+	}
+	return methods, true
+}
+
+// interfaceFinder converts an interface method to the finder, aggregate or
+// criteria Search it declares.
+func interfaceFinder(m *ast.Field, entity string) (SearchMethod, bool) {
+	fn, ok := m.Type.(*ast.FuncType)
+	if len(m.Names) != 1 || !ok {
+		return SearchMethod{}, false
+	}
+	name := m.Names[0].Name
+	if name == "Search" && len(fn.Params.List) == 2 && types.ExprString(fn.Params.List[1].Type) == entity+"Criteria" {
+		return newCriteriaSearchMethod(entity), true
+	}
+	names := finderMethodColumns(name)
+	if names == nil {
+		return SearchMethod{}, false
+	}
+	for _, kind := range []string{AggregateSum, AggregateMax} {
+		if method := newAggregateMethod(kind, Field{Name: names[0]}, entity); method.MethodName == name {
+			return method, true
+		}
+	}
+	var columns []Field
+	for _, param := range fn.Params.List {
+		for range param.Names {
+			if len(columns) == len(names) {
+				return SearchMethod{}, false
+			}
+			columns = append(columns, Field{Name: names[len(columns)], Type: types.ExprString(param.Type)})
+		}
+	}
+	if len(columns) != len(names) {
+		return SearchMethod{}, false
+	}
+	kind := FinderOne
+	switch {
+	case strings.HasPrefix(name, "FindAllBy"):
+		kind = FinderAll
+	case strings.HasPrefix(name, "ExistsBy"):
+		kind = FinderExists
+	}
+	method := newSearchMethod(kind, columns, entity)
+	return method, method.MethodName == name
+}
+
+// removeInterfaceMethods removes methods from the entity's interfaces in s and
+// the interfaces they leave empty, whose names it returns.
+func removeInterfaceMethods(s *goSource, entity string, methods map[string]bool) map[string]bool {
+	emptied := make(map[string]bool)
+	for _, decl := range s.file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			it, ok := ts.Type.(*ast.InterfaceType)
+			if !ok || !strings.HasPrefix(ts.Name.Name, entity) {
+				continue
+			}
+			kept := 0
+			for _, m := range it.Methods.List {
+				if len(m.Names) == 1 && methods[m.Names[0].Name] {
+					s.remove(m)
+					continue
+				}
+				kept++
+			}
+			if kept == 0 && len(it.Methods.List) > 0 {
+				emptied[ts.Name.Name] = true
+				if len(gen.Specs) == 1 {
+					s.remove(gen)
+				} else {
+					s.remove(ts)
+				}
+			}
+		}
+	}
+	return emptied
+}
+
+// removeInterfaceUses removes embeddings of the named interfaces and the
+// "var _ I = ..." assertions against them.
+func removeInterfaceUses(s *goSource, names map[string]bool) {
+	if len(names) == 0 {
+		return
+	}
+	for _, decl := range s.file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			switch sp := spec.(type) {
+			case *ast.TypeSpec:
+				if it, ok := sp.Type.(*ast.InterfaceType); ok {
+					for _, m := range it.Methods.List {
+						if len(m.Names) == 0 && names[exprTypeName(m.Type)] {
+							s.remove(m)
+						}
+					}
+				}
+			case *ast.ValueSpec:
+				if sp.Type != nil && names[exprTypeName(sp.Type)] {
+					if len(gen.Specs) == 1 {
+						s.remove(gen)
+					} else {
+						s.remove(sp)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationColumn is a table column added or dropped by goca field.
+type migrationColumn struct {
+	Name    string
+	Type    string
+	NotNull bool
+	Default string
+	Unique  bool
+}
+
+// sqlColumnTypes maps Go field types to the column types GORM migrates them to
+// on PostgreSQL, MySQL (and MariaDB and TiDB), SQLite, SQL Server and
+// Oracle.
+var sqlColumnTypes = map[string][5]string{
+	"string":      {"text", "longtext", "text", "nvarchar(MAX)", "clob"},
+	"int":         {"bigint", "bigint", "integer", "bigint", "integer"},
+	"int64":       {"bigint", "bigint", "integer", "bigint", "integer"},
+	"int32":       {"integer", "int", "integer", "int", "integer"},
+	"int16":       {"smallint", "smallint", "integer", "smallint", "integer"},
+	"int8":        {"smallint", "tinyint", "integer", "smallint", "smallint"},
+	"uint":        {"bigint", "bigint unsigned", "integer", "bigint", "integer"},
+	"uint64":      {"bigint", "bigint unsigned", "integer", "bigint", "integer"},
+	"uint32":      {"bigint", "int unsigned", "integer", "bigint", "integer"},
+	"uint16":      {"integer", "smallint unsigned", "integer", "int", "integer"},
+	"uint8":       {"smallint", "tinyint unsigned", "integer", "tinyint", "smallint"},
+	"float64":     {"double precision", "double", "real", "float", "float"},
+	"float32":     {"real", "float", "real", "real", "float"},
+	"bool":        {"boolean", "boolean", "numeric", "bit", "number(1)"},
+	"time.Time":   {"timestamptz", "datetime(3)", "datetime", "datetimeoffset", "timestamp with time zone"},
+	"[]byte":      {"bytea", "longblob", "blob", "varbinary(MAX)", "blob"},
+	FieldJSONType: {"jsonb", "JSON", "JSON", "nvarchar(MAX)", "JSON"},
+	// Soft-delete timestamps.
+	"gorm.DeletedAt": {"timestamptz", "datetime(3)", "datetime", "datetimeoffset", "timestamp with time zone"},
+}
+
+// sqlDialect returns the index of a database in sqlColumnTypes, or -1 for
+// databases without SQL migrations.
+func sqlDialect(database string) int {
+	switch database {
+	case DBPostgres, DBPostgresJSON:
+		return 0
+	case DBMySQL, DBMariaDB, DBTiDB:
+		return 1
+	case DBSQLite:
+		return 2
+	case DBSQLServer:
+		return 3
+	case DBOracle:
+		return 4
+	}
+	return -1
+}
+
+// sqlColumnType returns the column type of a Go field type. Custom types are
+// stored like strings.
+func sqlColumnType(fieldType, database string) string {
+	types, ok := sqlColumnTypes[strings.TrimPrefix(fieldType, "*")]
+	if !ok {
+		types = sqlColumnTypes["string"]
+	}
+	return types[sqlDialect(database)]
+}
+
+// sqlZeroDefault returns the default that fills a new NOT NULL column of the
+// given Go type in existing rows.
+func sqlZeroDefault(fieldType string) string {
+	switch {
+	case fieldType == FieldBool:
+		return "false"
+	case fieldType == "time.Time":
+		return "CURRENT_TIMESTAMP"
+	case isNumericType(fieldType):
+		return "0"
+	}
+	return "''"
+}
+
+// columnLiteral returns a column default or backfill value in the dialect of
+// database. Oracle stores booleans as the numbers 0 and 1.
+func columnLiteral(value, database string) string {
+	if database == DBOracle {
+		switch value {
+		case "false":
+			return "0"
+		case "true":
+			return "1"
+		}
+	}
+	return value
+}
+
+// gormTagOptions returns the options of the gorm key in a struct tag, keyed by
+// lowercase name.
+func gormTagOptions(tag string) map[string]string {
+	options := make(map[string]string)
+	gorm := reflect.StructTag(strings.Trim(tag, "`")).Get("gorm")
+	for _, option := range strings.Split(gorm, ";") {
+		key, value, _ := strings.Cut(option, ":")
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			options[key] = strings.TrimSpace(value)
+		}
+	}
+	return options
+}
+
+// fieldColumns returns the columns GORM stores fields in, with the type,
+// constraints and unique index their gorm tags declare.
+func fieldColumns(fields []Field, database string) []migrationColumn {
+	var columns []migrationColumn
+	for _, field := range fields {
+		options := gormTagOptions(field.Tag)
+		if field.Nested != nil {
+			for _, column := range fieldColumns(field.Nested, database) {
+				column.Name = options["embeddedprefix"] + column.Name
+				columns = append(columns, column)
+			}
+			continue
+		}
+
+		column := migrationColumn{Name: gormColumnName(field.Name), Type: options["type"], Default: options["default"]}
+		if name := options["column"]; name != "" {
+			column.Name = name
+		}
+		if column.Type == "" {
+			column.Type = sqlColumnType(field.Type, database)
+		}
+		if field.Type == FieldString && column.Default != "" && !strings.HasPrefix(column.Default, "'") {
+			// GORM quotes string defaults itself.
+			column.Default = "'" + strings.Trim(column.Default, `"`) + "'"
+		}
+		_, column.NotNull = options["not null"]
+		_, unique := options["uniqueindex"]
+		_, uniqueColumn := options["unique"]
+		column.Unique = unique || uniqueColumn
+		if column.NotNull && column.Default == "" {
+			// Existing rows need a value for the new column.
+			column.Default = sqlZeroDefault(field.Type)
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+// writeAddColumns writes the statements adding columns to table.
+func writeAddColumns(content *strings.Builder, table, database string, columns []migrationColumn) {
+	add := "ADD COLUMN"
+	if database == DBSQLServer || database == DBOracle {
+		add = "ADD"
+	}
+	for _, column := range columns {
+		fmt.Fprintf(content, "ALTER TABLE %s %s %s %s", table, add, column.Name, column.Type)
+		notNull, def := "", ""
+		if column.NotNull {
+			notNull = " NOT NULL"
+		}
+		if column.Default != "" {
+			def = " DEFAULT " + columnLiteral(column.Default, database)
+		}
+		if database == DBOracle {
+			// Oracle takes the default before the constraint.
+			content.WriteString(def + notNull)
+		} else {
+			content.WriteString(notNull + def)
+		}
+		content.WriteString(";\n")
+		if column.Unique {
+			writeCreateUniqueIndex(content, table, column)
+		}
+	}
+}
+
+// writeDropColumns writes the statements dropping columns, and their unique
+// indexes, from table.
+func writeDropColumns(content *strings.Builder, table, database string, columns []migrationColumn) {
+	for _, column := range columns {
+		if column.Unique {
+			writeDropUniqueIndex(content, table, database, column)
+		}
+		fmt.Fprintf(content, "ALTER TABLE %s DROP COLUMN %s;\n", table, column.Name)
+	}
+}
+
+// writeCreateUniqueIndex writes the statement creating a column's unique
+// index.
+func writeCreateUniqueIndex(content *strings.Builder, table string, column migrationColumn) {
+	fmt.Fprintf(content, "CREATE UNIQUE INDEX %s ON %s (%s);\n", columnIndexName(table, column), table, column.Name)
+}
+
+// writeDropUniqueIndex writes the statement dropping a column's unique index.
+func writeDropUniqueIndex(content *strings.Builder, table, database string, column migrationColumn) {
+	if isMySQLCompatible(database) || database == DBSQLServer {
+		fmt.Fprintf(content, "DROP INDEX %s ON %s;\n", columnIndexName(table, column), table)
+	} else {
+		fmt.Fprintf(content, "DROP INDEX %s;\n", columnIndexName(table, column))
+	}
+}
+
+// columnIndexName returns the name GORM gives a column's uniqueIndex.
+func columnIndexName(table string, column migrationColumn) string {
+	return "idx_" + table + "_" + column.Name
+}
+
+// entityTableName returns the table of an entity: the name its TableName
+// method returns, or the default of the project's naming configuration.
+func entityTableName(entity string) string {
+	if table := declaredTableName(entity); table != "" {
+		return table
+	}
+	return defaultTableName(entity, projectNaming())
+}
+
+// declaredTableName returns the table an entity's TableName method returns,
+// or "" when it has none.
+func declaredTableName(entity string) string {
+	files, _ := filepath.Glob(filepath.Join(DirInternal, DirDomain, "*.go"))
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		s, err := parseGoSource(path)
+		if err != nil {
+			continue
+		}
+		fn := findMethod(s.file, entity, "TableName")
+		if fn == nil || fn.Body == nil || len(fn.Body.List) != 1 {
+			continue
+		}
+		if ret, ok := fn.Body.List[0].(*ast.ReturnStmt); ok && len(ret.Results) == 1 {
+			if lit, ok := ret.Results[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				if table, err := strconv.Unquote(lit.Value); err == nil {
+					return table
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// migrationFilePattern matches golang-migrate file names and the files of
+// data migrations.
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_.*\.(sql|go)$`)
+
+// nextMigrationVersion returns the version of a new migration in dir: a UTC
+// timestamp for timestamp versioning, otherwise the next sequence number
+// padded like the existing ones.
+func nextMigrationVersion(dir, versioning string) string {
+	if versioning == "timestamp" {
+		return time.Now().UTC().Format("20060102150405")
+	}
+	highest, width := 0, 3
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		m := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		if n, err := strconv.Atoi(m[1]); err == nil && n > highest {
+			highest = n
+			width = max(width, len(m[1]))
+		}
+	}
+	return fmt.Sprintf("%0*d", width, highest+1)
+}
+
+// writeFieldMigration writes the up and down migration of a field change to
+// the project's migrations directory and returns the up migration's path.
+func writeFieldMigration(ci *ConfigIntegration, name, up, down string, sm ...*SafetyManager) (string, error) {
+	dir := projectMigrationsDir(ci)
+	base := filepath.Join(dir, nextMigrationVersion(dir, migrationVersioning(ci))+"_"+name)
+	if err := writeFile(base+".up.sql", up, sm...); err != nil {
+		return "", err
+	}
+	if err := writeFile(base+".down.sql", down, sm...); err != nil {
+		return "", err
+	}
+	return base + ".up.sql", nil
+}
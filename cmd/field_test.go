@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readTree returns the contents of the files under internal, keyed by path.
+func readTree(t *testing.T) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	require.NoError(t, filepath.WalkDir(DirInternal, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		files[path] = string(data)
+		return err
+	}))
+	return files
+}
+
+func TestFieldAddRemove_RoundTrip(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	const fields = "name:string,price:float64"
+	sm := NewSafetyManager(false, true, false)
+	require.NoError(t, generateEntity("Product", fields, true, false, false, false, false, false, false, true, "lowercase", sm))
+	generateUseCaseWithFields("ProductService", "Product", "create,read,update,delete,list", false, false, fields, "", sm)
+	generateRepository("Product", DBPostgres, false, false, false, false, fields, sm)
+	require.NoError(t, generateMocks("Product", false, true, false, false, sm))
+	before := readTree(t)
+
+	require.NoError(t, runFieldAdd(fieldAddCmd, []string{"Product", "email:string", "stock:int"}))
+
+	entity := readParsedGo(t, filepath.Join("internal", "domain", "product.go"))
+	assert.Contains(t, entity, "Email string")
+	assert.Contains(t, entity, "if p.Stock < 0 {\n\t\treturn ErrInvalidProductStock")
+	errs := readParsedGo(t, filepath.Join("internal", "domain", "errors.go"))
+	assert.Contains(t, errs, "ErrInvalidProductEmail")
+	seeds := readParsedGo(t, filepath.Join("internal", "domain", "product_seeds.go"))
+	assert.Contains(t, seeds, "INSERT INTO products (name, price, email, stock) VALUES")
+	tests := readParsedGo(t, filepath.Join("internal", "domain", "product_test.go"))
+	assert.Contains(t, tests, "func TestProduct_Email_EdgeCases(")
+	assert.Contains(t, tests, "Price: tt.value,\n\t\t\t\tEmail: \"test@example.com\",")
+	dto := readParsedGo(t, filepath.Join("internal", "usecase", "dto.go"))
+	assert.Contains(t, dto, "Stock *int")
+	service := readParsedGo(t, filepath.Join("internal", "usecase", "product_service.go"))
+	assert.Contains(t, service, "Email: input.Email,")
+	assert.Contains(t, service, "if input.Stock != nil {\n\t\tproduct.Stock = *input.Stock\n\t}")
+	interfaces := readParsedGo(t, filepath.Join("internal", "repository", "interfaces.go"))
+	assert.Contains(t, interfaces, "FindByEmail(email string) (*domain.Product, error)")
+	repo := readParsedGo(t, filepath.Join("internal", "repository", "postgres_product_repository.go"))
+	assert.Contains(t, repo, `Where("email = ?", email)`)
+	mock := readParsedGo(t, filepath.Join("internal", "mocks", "mock_product_repository.go"))
+	assert.Contains(t, mock, "func (m *MockProductRepository) FindByEmail(email string)")
+
+	err := runFieldAdd(fieldAddCmd, []string{"Product", "email:string"})
+	assert.ErrorContains(t, err, "already has a field Email")
+
+	require.NoError(t, runFieldRemove(fieldRemoveCmd, []string{"Product", "email,stock"}))
+
+	// Everything but the kept validation errors is back to the original.
+	after := readTree(t)
+	errorsFile := filepath.Join("internal", "domain", "errors.go")
+	for path, content := range before {
+		if path != errorsFile {
+			assert.Equal(t, content, after[path], path)
+		}
+	}
+	assert.Len(t, after, len(before))
+}
+
+func TestSelectEntityFields(t *testing.T) {
+	existing := []Field{{Name: "ID", Type: "uint"}, {Name: "Email", Type: "string"}, {Name: "StockCount", Type: "int"}}
+
+	fields, err := selectEntityFields("Product", existing, "email, stock_count")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Email", "StockCount"}, []string{fields[0].Name, fields[1].Name})
+
+	_, err = selectEntityFields("Product", existing, "id")
+	assert.ErrorContains(t, err, "managed by goca")
+	_, err = selectEntityFields("Product", existing, "phone")
+	assert.ErrorContains(t, err, "Product has no field Phone")
+}
+
+func TestGoSource_EditsAndImports(t *testing.T) {
+	src := `package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+type User struct {
+	Name  string
+	Email string // contact
+}
+
+func (u *User) Validate() error {
+	if u.Name == "" {
+		return errors.New("name")
+	}
+	if !strings.Contains(u.Email, "@") {
+		return errors.New("email")
+	}
+	return nil
+}
+`
+	s, err := newGoSource("user.go", []byte(src))
+	require.NoError(t, err)
+	removeStructFields(s, findStructType(s.file, "User"), map[string]bool{"Email": true})
+	removeFieldUses(s, findMethod(s.file, "User", "Validate"), map[string]bool{"Email": true}, nil)
+	s.insertLines(lastReturnNil(findMethod(s.file, "User", "Validate")).Pos(), "\tif len(u.Name) > 100 {\n\t\treturn fmt.Errorf(\"name too long\")\n\t}\n")
+
+	out, err := syncImports(s.apply(), "errors", "fmt", "strings")
+	require.NoError(t, err)
+	got := string(out)
+	assert.NotContains(t, got, "Email")
+	assert.NotContains(t, got, `"strings"`)
+	assert.Contains(t, got, `"fmt"`)
+	assert.Contains(t, got, "if len(u.Name) > 100 {")
+}
+
+func TestFieldColumns_Migrations(t *testing.T) {
+	fields := parseFieldsWithValidation("email:string,address:struct{city:string},active:bool", false)[1:]
+	fields[0].Tag = "`json:\"email\" gorm:\"type:varchar(255);uniqueIndex;not null\"`"
+
+	columns := fieldColumns(fields, DBPostgres)
+	require.Len(t, columns, 3)
+	assert.Equal(t, migrationColumn{Name: "email", Type: "varchar(255)", NotNull: true, Default: "''", Unique: true}, columns[0])
+	assert.Equal(t, "address_city", columns[1].Name)
+
+	var up, down strings.Builder
+	writeAddColumns(&up, "users", DBPostgres, columns[:1])
+	writeDropColumns(&down, "users", DBMySQL, columns[:1])
+	assert.Equal(t, "ALTER TABLE users ADD COLUMN email varchar(255) NOT NULL DEFAULT '';\n"+
+		"CREATE UNIQUE INDEX idx_users_email ON users (email);\n", up.String())
+	assert.Equal(t, "DROP INDEX idx_users_email ON users;\nALTER TABLE users DROP COLUMN email;\n", down.String())
+
+	up.Reset()
+	writeAddColumns(&up, "users", DBSQLServer, columns[2:])
+	assert.Equal(t, "ALTER TABLE users ADD active boolean NOT NULL DEFAULT false;\n", up.String())
+}
+
+func TestNextMigrationVersion(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, "001", nextMigrationVersion(dir, ""))
+
+	for _, name := range []string{"001_init.up.sql", "0009_orders.up.sql", "notes.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+	assert.Equal(t, "0010", nextMigrationVersion(dir, "sequential"))
+	assert.Len(t, nextMigrationVersion(dir, "timestamp"), 14)
+}
@@ -50,12 +50,14 @@ func (v *FieldValidator) ValidateFields(fields string) error {
 	return nil
 }
 
-// smartSplitFields splits fields while respecting parentheses and brackets.
+// smartSplitFields splits fields while respecting parentheses, brackets and
+// the braces of nested struct{...} definitions.
 func (v *FieldValidator) smartSplitFields(fields string) []string {
 	var result []string
 	var current strings.Builder
 	parenLevel := 0
 	bracketLevel := 0
+	braceLevel := 0
 
 	for _, char := range fields {
 		switch char {
@@ -71,15 +73,21 @@ func (v *FieldValidator) smartSplitFields(fields string) []string {
 		case ']':
 			bracketLevel--
 			current.WriteRune(char)
+		case '{':
+			braceLevel++
+			current.WriteRune(char)
+		case '}':
+			braceLevel--
+			current.WriteRune(char)
 		case ',':
-			if parenLevel == 0 && bracketLevel == 0 {
+			if parenLevel == 0 && bracketLevel == 0 && braceLevel == 0 {
 				// We're at the top level, this comma separates fields
 				if current.Len() > 0 {
 					result = append(result, current.String())
 					current.Reset()
 				}
 			} else {
-				// We're inside parentheses or brackets, keep the comma
+				// We're inside parentheses, brackets or braces, keep the comma
 				current.WriteRune(char)
 			}
 		default:
@@ -101,13 +109,87 @@ func (v *FieldValidator) ValidateField(fieldDef string) (*Field, error) {
 		return nil, errors.New("definición de campo vacía")
 	}
 
-	parts := strings.Split(fieldDef, ":")
-	if len(parts) != 2 {
+	// Only the first colon separates name and type; nested struct{...} types
+	// contain colons of their own.
+	fieldName, fieldType, ok := strings.Cut(fieldDef, ":")
+	fieldName = strings.TrimSpace(fieldName)
+	fieldType = strings.TrimSpace(fieldType)
+	if !ok {
 		return nil, fmt.Errorf("%s. Recibido: '%s'", ErrInvalidFieldSyntax, fieldDef)
 	}
 
-	fieldName := strings.TrimSpace(parts[0])
-	fieldType := strings.TrimSpace(parts[1])
+	// status:string=active declares a default value after the type.
+	var defaultValue string
+	if !isStructFieldType(fieldType) {
+		typ, rest, hasModifiers := strings.Cut(fieldType, ":")
+		if typ, value, ok := strings.Cut(typ, DefaultSeparator); ok {
+			if defaultValue = strings.TrimSpace(value); defaultValue == "" {
+				return nil, fmt.Errorf("empty default on field %s", fieldName)
+			}
+			fieldType = strings.TrimSpace(typ)
+			if hasModifiers {
+				fieldType += ":" + rest
+			}
+		}
+	}
+
+	// Further colons separate modifiers: email:string:findby,
+	// status:string:enum=draft|published, notes:string:hide=http,
+	// firstName:string:json=firstName, total:float64:computed,
+	// password:string:sensitive or nickname:string:deprecated=display_name.
+	var modifiers []string
+	var enum, hidden []string
+	var jsonName, replacedBy string
+	var computed, sensitive, deprecated bool
+	if !isStructFieldType(fieldType) && strings.Contains(fieldType, ":") {
+		parts := strings.Split(fieldType, ":")
+		fieldType = strings.TrimSpace(parts[0])
+		for _, modifier := range parts[1:] {
+			if strings.TrimSpace(modifier) == ComputedModifier {
+				computed = true
+				continue
+			}
+			if strings.TrimSpace(modifier) == SensitiveModifier {
+				sensitive = true
+				continue
+			}
+			if replacement, ok, err := parseDeprecation(fieldName, modifier); ok {
+				if err != nil {
+					return nil, err
+				}
+				deprecated, replacedBy = true, replacement
+				continue
+			}
+			protocols, ok, err := parseVisibility(fieldName, modifier)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				hidden = protocols
+				continue
+			}
+			if name, ok, err := parseJSONModifier(fieldName, modifier); ok {
+				if err != nil {
+					return nil, err
+				}
+				jsonName = name
+				continue
+			}
+			values, ok := strings.CutPrefix(strings.TrimSpace(modifier), EnumModifier)
+			if !ok {
+				modifiers = append(modifiers, modifier)
+				continue
+			}
+			if enum, err = parseEnumValues(fieldName, fieldType, values); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// full_name:computed is a read-only string.
+	if fieldType == ComputedModifier {
+		fieldType, computed = FieldString, true
+	}
 
 	// Validate field name
 	if err := v.ValidateFieldName(fieldName); err != nil {
@@ -119,10 +201,121 @@ func (v *FieldValidator) ValidateField(fieldDef string) (*Field, error) {
 		return nil, err
 	}
 
-	return &Field{
-		Name: capitalizeFirst(fieldName),
-		Type: fieldType,
-	}, nil
+	defaultValue, err := validateFieldConstraints(fieldName, fieldType, defaultValue, computed, enum)
+	if err != nil {
+		return nil, err
+	}
+
+	finders, err := v.parseFinderModifiers(fieldName, fieldType, modifiers)
+	if err != nil {
+		return nil, err
+	}
+
+	field := &Field{
+		Name:       capitalizeFirst(fieldName),
+		Type:       fieldType,
+		Finders:    finders,
+		Enum:       enum,
+		Hidden:     hidden,
+		JSONName:   jsonName,
+		Default:    defaultValue,
+		Computed:   computed,
+		Sensitive:  sensitive,
+		Deprecated: deprecated,
+		ReplacedBy: replacedBy,
+	}
+	if err := validateDeprecation(field); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+// parseEnumValues parses the values of an enum= modifier, separated by |. They
+// become validate tag parameters and Go string literals, so they are limited
+// to letters, digits, '_', '-' and '.'.
+func parseEnumValues(fieldName, fieldType, values string) ([]string, error) {
+	if fieldType != FieldString {
+		return nil, fmt.Errorf("field %s of type %s cannot be an enum; enum values are strings", fieldName, fieldType)
+	}
+	validValue := regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+	var enum []string
+	seen := make(map[string]bool)
+	for _, value := range strings.Split(values, "|") {
+		value = strings.TrimSpace(value)
+		if !validValue.MatchString(value) {
+			return nil, fmt.Errorf("invalid enum value %q on field %s; use letters, digits, '_', '-' and '.'", value, fieldName)
+		}
+		if seen[value] {
+			return nil, fmt.Errorf("duplicate enum value %q on field %s", value, fieldName)
+		}
+		seen[value] = true
+		enum = append(enum, value)
+	}
+	return enum, nil
+}
+
+// parseFinderModifiers parses the finder modifiers of a field definition:
+// findby, findallby or existsby, followed by "+field" for every other field
+// a multi-column finder matches.
+func (v *FieldValidator) parseFinderModifiers(fieldName, fieldType string, modifiers []string) ([]FieldFinder, error) {
+	var finders []FieldFinder
+	for _, modifier := range modifiers {
+		parts := strings.Split(strings.TrimSpace(modifier), "+")
+		kind := strings.ToLower(strings.TrimSpace(parts[0]))
+		if !contains(ValidFinderKinds, kind) {
+			return nil, fmt.Errorf("unknown modifier %q on field %s; use %s", modifier, fieldName, strings.Join(ValidFinderKinds, ", "))
+		}
+		if !isFinderFieldType(fieldType) {
+			return nil, fmt.Errorf("field %s of type %s cannot have a finder", fieldName, fieldType)
+		}
+		finder := FieldFinder{Kind: kind}
+		for _, with := range parts[1:] {
+			with = strings.TrimSpace(with)
+			if err := v.ValidateFieldName(with); err != nil {
+				return nil, fmt.Errorf("finder %q on field %s: %w", modifier, fieldName, err)
+			}
+			finder.With = append(finder.With, with)
+		}
+		finders = append(finders, finder)
+	}
+	return finders, nil
+}
+
+// applyFinderSpecs adds the finders of a --finders value to the field
+// definitions in fields as modifiers. Finders are comma separated, each
+// [findby:|findallby:|existsby:]field[+field...]; findby is the default kind.
+func applyFinderSpecs(fields, finders string) (string, error) {
+	v := NewFieldValidator()
+	parts := v.smartSplitFields(fields)
+	for _, spec := range strings.Split(finders, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		kind, columns := FinderOne, spec
+		if prefix, rest, ok := strings.Cut(spec, ":"); ok {
+			kind, columns = strings.ToLower(strings.TrimSpace(prefix)), rest
+		}
+		first, with, _ := strings.Cut(columns, "+")
+		modifier := kind
+		if with != "" {
+			modifier += "+" + with
+		}
+
+		found := false
+		for i, part := range parts {
+			name, _, _ := strings.Cut(part, ":")
+			if toGoFieldName(strings.TrimSpace(name)) == toGoFieldName(strings.TrimSpace(first)) {
+				parts[i] = strings.TrimSpace(part) + ":" + modifier
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("finder %q: no field %s in --fields", spec, strings.TrimSpace(first))
+		}
+	}
+	return strings.Join(parts, ","), nil
 }
 
 // ValidateFieldName validates a field name.
@@ -155,6 +348,13 @@ func (v *FieldValidator) ValidateFieldType(fieldType string) error {
 		return errors.New("field type cannot be empty")
 	}
 
+	if isJSONFieldType(fieldType) || isFileFieldType(fieldType) {
+		return nil
+	}
+	if isStructFieldType(fieldType) {
+		return v.validateNestedStruct(fieldType)
+	}
+
 	// Check if it's a valid basic type
 	for _, validType := range ValidFieldTypes {
 		if fieldType == validType {
@@ -166,6 +366,84 @@ func (v *FieldValidator) ValidateFieldType(fieldType string) error {
 	return v.validateComplexType(fieldType)
 }
 
+// isJSONFieldType reports whether fieldType declares a JSON column.
+func isJSONFieldType(fieldType string) bool {
+	lower := strings.ToLower(fieldType)
+	return lower == FieldJSON || lower == FieldJSONB || fieldType == FieldJSONType
+}
+
+// isFileFieldType reports whether fieldType declares an uploaded file.
+func isFileFieldType(fieldType string) bool {
+	lower := strings.ToLower(fieldType)
+	return lower == FieldFile || lower == FieldBlob || fieldType == FieldFileType
+}
+
+// isFileField reports whether field holds the metadata of an uploaded file.
+func isFileField(field Field) bool {
+	return field.Type == FieldFileType
+}
+
+// fileMetadataFields are the fields of the domain File type.
+func fileMetadataFields() []Field {
+	return []Field{
+		{Name: "Key", Type: FieldString, Tag: "`json:\"key\"`"},
+		{Name: "URL", Type: FieldString, Tag: "`json:\"url\"`"},
+		{Name: "Size", Type: FieldInt64, Tag: "`json:\"size\"`"},
+		{Name: "ContentType", Type: FieldString, Tag: "`json:\"content_type\"`"},
+	}
+}
+
+// isStructFieldType reports whether fieldType is a nested struct{...} definition.
+func isStructFieldType(fieldType string) bool {
+	return strings.HasPrefix(fieldType, FieldStructOpen) && strings.HasSuffix(fieldType, "}")
+}
+
+// nestedFieldDefs returns the field definitions inside a struct{...} type.
+func (v *FieldValidator) nestedFieldDefs(fieldType string) []string {
+	inner := strings.TrimSuffix(strings.TrimPrefix(fieldType, FieldStructOpen), "}")
+	var defs []string
+	for _, def := range v.smartSplitFields(inner) {
+		if def = strings.TrimSpace(def); def != "" {
+			defs = append(defs, def)
+		}
+	}
+	return defs
+}
+
+// validateNestedStruct validates the fields of a struct{...} type. Nesting is
+// limited to one level so every nested struct maps to embedded columns.
+func (v *FieldValidator) validateNestedStruct(fieldType string) error {
+	defs := v.nestedFieldDefs(fieldType)
+	if len(defs) == 0 {
+		return fmt.Errorf("nested struct %s must declare at least one field", fieldType)
+	}
+
+	names := make(map[string]bool)
+	for _, def := range defs {
+		field, err := v.ValidateField(def)
+		if err != nil {
+			return fmt.Errorf("invalid nested field: %w", err)
+		}
+		if isStructFieldType(field.Type) {
+			return fmt.Errorf("nested struct field %s cannot contain another struct{...}; declare it as json instead", field.Name)
+		}
+		if isFileFieldType(field.Type) {
+			return fmt.Errorf("nested struct field %s cannot be a file; declare it on the entity instead", field.Name)
+		}
+		if len(field.Hidden) > 0 {
+			return fmt.Errorf("nested struct field %s cannot be hidden; hide the fields of the entity instead", field.Name)
+		}
+		if field.Deprecated {
+			return fmt.Errorf("nested struct field %s cannot be deprecated; deprecate the fields of the entity instead", field.Name)
+		}
+		if names[field.Name] {
+			return fmt.Errorf("campo duplicado: %s", field.Name)
+		}
+		names[field.Name] = true
+	}
+	return nil
+}
+
 // validateComplexType handles validation of complex Go types.
 func (v *FieldValidator) validateComplexType(fieldType string) error {
 	// Trim leading/trailing whitespace but preserve internal spaces for channel/func parsing
@@ -509,26 +787,94 @@ func (v *FieldValidator) ParseFieldsWithValidation(fields string) ([]Field, erro
 			return nil, err
 		}
 
-		// Generate GORM tag based on field type
-		gormTag := getGormTag(field.Name, field.Type)
-		tag := fmt.Sprintf("`json:\"%s\" gorm:\"%s\"`", strings.ToLower(field.Name), gormTag)
+		entityField := v.entityField(field.Name, field.Type)
+		entityField.Finders = field.Finders
+		entityField.Enum = field.Enum
+		entityField.Hidden = field.Hidden
+		entityField.JSONName = field.JSONName
+		entityField.Default = field.Default
+		entityField.Computed = field.Computed
+		entityField.Sensitive = field.Sensitive
+		entityField.Deprecated = field.Deprecated
+		entityField.ReplacedBy = field.ReplacedBy
+		applyDefaultTag(&entityField)
+		fieldsList = append(fieldsList, entityField)
+	}
+
+	// Renamed fields are only accepted by input DTOs; see deprecatedAliases.
+	return withoutDeprecatedAliases(fieldsList)
+}
 
-		fieldsList = append(fieldsList, Field{
-			Name: field.Name,
-			Type: field.Type,
-			Tag:  tag,
-		})
+// entityField builds the entity field for a validated name and DSL type.
+// json/jsonb become datatypes.JSON columns and struct{...} becomes a named
+// struct embedded into the entity table with a "<field>_" column prefix, like
+// the domain File of file/blob fields.
+func (v *FieldValidator) entityField(name, fieldType string) Field {
+	column := fieldJSONName(name, JSONNamingSnake)
+
+	switch {
+	case isJSONFieldType(fieldType):
+		// datatypes.JSON picks jsonb on PostgreSQL and JSON on MySQL/SQLite.
+		return Field{
+			Name: name,
+			Type: FieldJSONType,
+			Tag:  fmt.Sprintf("`json:\"%s\"`", column),
+		}
+	case isFileFieldType(fieldType):
+		return Field{
+			Name:   name,
+			Type:   FieldFileType,
+			Tag:    fmt.Sprintf("`json:\"%s\" gorm:\"embedded;embeddedPrefix:%s_\"`", column, column),
+			Nested: fileMetadataFields(),
+		}
+	case isStructFieldType(fieldType):
+		var nested []Field
+		for _, def := range v.nestedFieldDefs(fieldType) {
+			sub, err := v.ValidateField(def)
+			if err != nil {
+				continue // already rejected by ValidateFields
+			}
+			field := v.entityField(sub.Name, sub.Type)
+			field.JSONName = sub.JSONName
+			nested = append(nested, field)
+		}
+		return Field{
+			Name:   name,
+			Type:   name,
+			Tag:    fmt.Sprintf("`json:\"%s\" gorm:\"embedded;embeddedPrefix:%s_\"`", column, column),
+			Nested: nested,
+		}
+	case isValueObjectType(fieldType):
+		// Value objects declare their JSON column type with GormDBDataType.
+		return Field{
+			Name: name,
+			Type: fieldType,
+			Tag:  fmt.Sprintf("`json:\"%s\"`", column),
+		}
+	case isPointerType(fieldType):
+		// Pointer fields are optional, so the column stays nullable.
+		return Field{
+			Name: name,
+			Type: fieldType,
+			Tag:  fmt.Sprintf("`json:\"%s\"`", column),
+		}
+	default:
+		// Generate GORM tag based on field type
+		return Field{
+			Name: name,
+			Type: fieldType,
+			Tag:  fmt.Sprintf("`json:\"%s\" gorm:\"%s\"`", column, getGormTag(name, fieldType)),
+		}
 	}
-
-	return fieldsList, nil
 }
 
-// capitalizeFirst capitalizes the first letter of a string.
+// capitalizeFirst capitalizes the first letter of a string, keeping the case
+// of the others so firstName still has two words.
 func capitalizeFirst(s string) string {
 	if s == "" {
 		return s
 	}
-	return strings.ToUpper(string(s[0])) + strings.ToLower(s[1:])
+	return strings.ToUpper(s[:1]) + s[1:]
 }
 
 // GenerateQueryMethodsForFields generates appropriate query methods based on field types.
@@ -0,0 +1,399 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Visibility modifiers of --fields. internal_notes:string:hide=http hides a
+// field from the listed handler protocols, notes:string:only=grpc|cli from
+// every other one. Hidden fields are neither returned nor accepted by the
+// handlers of those protocols.
+const (
+	HideModifier = "hide="
+	OnlyModifier = "only="
+)
+
+// visibilityTag is the struct tag of the domain entity recording the
+// protocols a field is hidden from, e.g. hide:"http,websocket".
+const visibilityTag = "hide"
+
+// VisibilityProtocols are the handler types fields can be hidden from: those
+// that read or return entities.
+var VisibilityProtocols = []string{HandlerHTTP, HandlerGRPC, HandlerCLI, HandlerWebSocket}
+
+// parseVisibility parses a hide= or only= modifier into the protocols the
+// field is hidden from, in VisibilityProtocols order. ok is false for other
+// modifiers.
+func parseVisibility(fieldName, modifier string) (hidden []string, ok bool, err error) {
+	modifier = strings.TrimSpace(modifier)
+	values, hide := strings.CutPrefix(modifier, HideModifier)
+	if !hide {
+		if values, ok = strings.CutPrefix(modifier, OnlyModifier); !ok {
+			return nil, false, nil
+		}
+	}
+
+	listed := make(map[string]bool)
+	for _, protocol := range strings.Split(values, "|") {
+		protocol = strings.ToLower(strings.TrimSpace(protocol))
+		if !contains(VisibilityProtocols, protocol) {
+			return nil, true, fmt.Errorf("invalid protocol %q in %s on field %s; use %s", protocol, modifier, fieldName, strings.Join(VisibilityProtocols, ", "))
+		}
+		listed[protocol] = true
+	}
+	for _, protocol := range VisibilityProtocols {
+		if listed[protocol] == hide {
+			hidden = append(hidden, protocol)
+		}
+	}
+	if len(hidden) == 0 {
+		return nil, true, fmt.Errorf("%s on field %s hides it from no protocol", modifier, fieldName)
+	}
+	return hidden, true, nil
+}
+
+// addVisibilityTags records the protocols each field is hidden from in its
+// struct tag, so handlers generated later find them in the domain entity.
+func addVisibilityTags(fieldsList []Field) {
+	for i, f := range fieldsList {
+		if len(f.Hidden) == 0 {
+			continue
+		}
+		inner := strings.Trim(f.Tag, "`")
+		fieldsList[i].Tag = fmt.Sprintf("`%s %s:%q`", inner, visibilityTag, strings.Join(f.Hidden, ","))
+	}
+}
+
+// optionalValidateTag returns the validate tag of a hidden field: the
+// protocols it is hidden from cannot set it, so it is no longer required.
+func optionalValidateTag(tag string) string {
+	rules := strings.Split(tag, ",")
+	if rules[0] != "required" {
+		return tag
+	}
+	if len(rules) == 1 {
+		return ""
+	}
+	return strings.Join(append([]string{"omitempty"}, rules[1:]...), ",")
+}
+
+// fieldHiddenProtocols returns the protocols f is hidden from, declared with
+// a modifier or read from its struct tag.
+func fieldHiddenProtocols(f Field) []string {
+	if len(f.Hidden) > 0 {
+		return f.Hidden
+	}
+	value := reflect.StructTag(strings.Trim(f.Tag, "`")).Get(visibilityTag)
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// fieldHiddenFrom reports whether f is hidden from protocol.
+func fieldHiddenFrom(f Field, protocol string) bool {
+	return contains(fieldHiddenProtocols(f), protocol)
+}
+
+// withoutHiddenFields returns the fields of fields not hidden from protocol.
+func withoutHiddenFields(fields []Field, protocol string) []Field {
+	var visible []Field
+	for _, f := range fields {
+		if !fieldHiddenFrom(f, protocol) {
+			visible = append(visible, f)
+		}
+	}
+	return visible
+}
+
+// projection describes the projections a handler package needs to keep the
+// fields hidden from its protocol out of the responses and requests of an
+// entity.
+type projection struct {
+	entity   string
+	protocol string
+	hidden   map[string]bool
+
+	// entityFields are the fields of the domain entity, hidden ones included.
+	entityFields  []Field
+	domainImports []string
+
+	// createOutput are the fields of usecase.Create<Entity>Output when it
+	// carries a hidden field.
+	createOutput   []Field
+	usecaseImports []string
+
+	// createInput and updateInput are the hidden fields the use case inputs
+	// declare.
+	createInput []string
+	updateInput []string
+}
+
+// loadProjection returns the projection of entity for protocol, or nil when
+// no field of the entity is hidden from it.
+func loadProjection(entity, protocol string) *projection {
+	file, err := findEntityFile(entity)
+	if err != nil {
+		return nil
+	}
+	src, err := parseGoSource(file)
+	if err != nil {
+		return nil
+	}
+	p := &projection{entity: entity, protocol: protocol, hidden: make(map[string]bool)}
+	p.entityFields = readEntityFields(src.file, entity)
+	for _, f := range p.entityFields {
+		if fieldHiddenFrom(f, protocol) {
+			p.hidden[f.Name] = true
+		}
+	}
+	if len(p.hidden) == 0 {
+		return nil
+	}
+	p.domainImports = fileImportPaths(src.file)
+
+	usecaseDir := filepath.Join(DirInternal, DirUseCase)
+	for _, dto := range []struct {
+		name   string
+		fields *[]Field
+		hidden *[]string
+	}{
+		{"Create" + entity + "Output", &p.createOutput, nil},
+		{"Create" + entity + "Input", nil, &p.createInput},
+		{"Update" + entity + "Input", nil, &p.updateInput},
+	} {
+		path := findTypeFile(usecaseDir, dto.name)
+		if path == "" {
+			continue
+		}
+		s, err := parseGoSource(path)
+		if err != nil {
+			continue
+		}
+		fields := readEntityFields(s.file, dto.name)
+		var hidden []string
+		for _, f := range fields {
+			if p.hidden[f.Name] {
+				hidden = append(hidden, f.Name)
+			}
+		}
+		if len(hidden) == 0 {
+			continue
+		}
+		if dto.fields != nil {
+			*dto.fields = fields
+			p.usecaseImports = fileImportPaths(s.file)
+		} else {
+			*dto.hidden = hidden
+		}
+	}
+	return p
+}
+
+// fileImportPaths returns the import paths of file.
+func fileImportPaths(file *ast.File) []string {
+	var paths []string
+	for _, spec := range file.Imports {
+		if path, err := strconv.Unquote(spec.Path.Value); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// createView reports whether the create output has a projection.
+func (p *projection) createView() bool {
+	return p != nil && len(p.createOutput) > 0
+}
+
+// visible returns the fields of fields not hidden by p, which may be nil.
+func (p *projection) visible(fields []Field) []Field {
+	if p == nil {
+		return fields
+	}
+	var visible []Field
+	for _, f := range fields {
+		if !p.hidden[f.Name] {
+			visible = append(visible, f)
+		}
+	}
+	return visible
+}
+
+// hiddenNames returns the hidden fields of the entity in declaration order.
+func (p *projection) hiddenNames() []string {
+	var names []string
+	for _, f := range p.entityFields {
+		if p.hidden[f.Name] {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
+
+// source renders the <entity>_view.go file of the handler package pkg. list
+// adds the projection of usecase.List<Entity>Output and inputs the functions
+// clearing the hidden fields of decoded requests.
+func (p *projection) source(pkg, importPath string, list, inputs bool) (string, error) {
+	entity := p.entity
+	hidden := strings.Join(p.hiddenNames(), ", ")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("import (\n")
+	fmt.Fprintf(&b, "\t\"%s/internal/domain\"\n", importPath)
+	fmt.Fprintf(&b, "\t\"%s/internal/usecase\"\n", importPath)
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(&b, "// %sView is the %s projection of domain.%s: it leaves out %s,\n", entity, p.protocol, entity, hidden)
+	fmt.Fprintf(&b, "// hidden from %s.\n", p.protocol)
+	writeProjectionStruct(&b, entity+"View", p.entityFields, p.hidden, "domain")
+	fmt.Fprintf(&b, "func new%sView(e domain.%s) %sView {\n", entity, entity, entity)
+	writeProjectionCopy(&b, entity+"View", "e", p.entityFields, p.hidden)
+	b.WriteString("}\n\n")
+	fmt.Fprintf(&b, "func new%sViews(entities []domain.%s) []%sView {\n", entity, entity, entity)
+	fmt.Fprintf(&b, "\tviews := make([]%sView, len(entities))\n", entity)
+	b.WriteString("\tfor i, e := range entities {\n")
+	fmt.Fprintf(&b, "\t\tviews[i] = new%sView(e)\n", entity)
+	b.WriteString("\t}\n")
+	b.WriteString("\treturn views\n")
+	b.WriteString("}\n")
+
+	if list {
+		entityLower := strings.ToLower(entity)
+		fmt.Fprintf(&b, "\n// List%sView is usecase.List%sOutput with %sView items.\n", entity, entity, entity)
+		fmt.Fprintf(&b, "type List%sView struct {\n", entity)
+		fmt.Fprintf(&b, "\t%s []%sView `json:\"%s\"`\n", toPlural(entity), entity, toPlural(entityLower))
+		b.WriteString("\tTotal int `json:\"total\"`\n")
+		b.WriteString("\tMessage string `json:\"message\"`\n")
+		b.WriteString("}\n\n")
+		fmt.Fprintf(&b, "func newList%sView(output usecase.List%sOutput) List%sView {\n", entity, entity, entity)
+		fmt.Fprintf(&b, "\treturn List%sView{%s: new%sViews(output.%s), Total: output.Total, Message: output.Message}\n", entity, toPlural(entity), entity, toPlural(entity))
+		b.WriteString("}\n")
+	}
+
+	if p.createView() {
+		fmt.Fprintf(&b, "\n// Create%sView is usecase.Create%sOutput without %s.\n", entity, entity, hidden)
+		writeProjectionStruct(&b, "Create"+entity+"View", p.createOutput, p.hidden, "usecase")
+		fmt.Fprintf(&b, "func newCreate%sView(output usecase.Create%sOutput) Create%sView {\n", entity, entity, entity)
+		writeProjectionCopy(&b, "Create"+entity+"View", "output", p.createOutput, p.hidden)
+		b.WriteString("}\n")
+	}
+
+	if inputs {
+		writeProjectionClear(&b, "Create"+entity+"Input", p.createInput)
+		writeProjectionClear(&b, "Update"+entity+"Input", p.updateInput)
+	}
+
+	imports := append([]string{importPath + "/internal/domain", importPath + "/internal/usecase"}, p.domainImports...)
+	content, err := syncImports([]byte(b.String()), append(imports, p.usecaseImports...)...)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// writeProjectionStruct writes a struct type with the fields of fields that
+// are not hidden, keeping their JSON names. Types declared in pkg are
+// qualified with it.
+func writeProjectionStruct(b *strings.Builder, name string, fields []Field, hidden map[string]bool, pkg string) {
+	fmt.Fprintf(b, "type %s struct {\n", name)
+	for _, f := range fields {
+		if hidden[f.Name] {
+			continue
+		}
+		fmt.Fprintf(b, "\t%s %s", f.Name, qualifyType(f.Type, pkg))
+		if key := reflect.StructTag(strings.Trim(f.Tag, "`")).Get("json"); key != "" {
+			fmt.Fprintf(b, " `json:%q`", key)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("}\n\n")
+}
+
+// writeProjectionCopy writes the return statement copying the visible fields
+// of from into a name literal.
+func writeProjectionCopy(b *strings.Builder, name, from string, fields []Field, hidden map[string]bool) {
+	fmt.Fprintf(b, "\treturn %s{\n", name)
+	for _, f := range fields {
+		if !hidden[f.Name] {
+			fmt.Fprintf(b, "\t\t%s: %s.%s,\n", f.Name, from, f.Name)
+		}
+	}
+	b.WriteString("\t}\n")
+}
+
+// writeProjectionClear writes the function resetting the hidden fields of a
+// decoded use case input, so requests cannot set them.
+func writeProjectionClear(b *strings.Builder, input string, fields []string) {
+	if len(fields) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n// omitHidden%s resets the fields of input hidden from this protocol, so\n", input)
+	b.WriteString("// requests cannot set them.\n")
+	fmt.Fprintf(b, "func omitHidden%s(input *usecase.%s) {\n", input, input)
+	fmt.Fprintf(b, "\tvar zero usecase.%s\n", input)
+	for _, name := range fields {
+		fmt.Fprintf(b, "\tinput.%s = zero.%s\n", name, name)
+	}
+	b.WriteString("}\n")
+}
+
+// qualifyType qualifies the exported identifiers of the type expression typ,
+// declared in package pkg, with pkg.
+func qualifyType(typ, pkg string) string {
+	expr, err := parser.ParseExpr(typ)
+	if err != nil {
+		return typ
+	}
+	var qualify func(n ast.Node) bool
+	qualify = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			return false
+		case *ast.Ident:
+			if ast.IsExported(n.Name) {
+				n.Name = pkg + "." + n.Name
+			}
+		}
+		return true
+	}
+	ast.Inspect(expr, qualify)
+	return types.ExprString(expr)
+}
+
+// generateProjectionFile writes the <entity>_view.go projections of a handler
+// package when fields of the entity are hidden from protocol. It returns the
+// projection, nil when nothing is hidden.
+func generateProjectionFile(dir, pkg, entity, protocol, fileNamingConvention string, list, inputs bool, sm ...*SafetyManager) *projection {
+	p := loadProjection(entity, protocol)
+	if p == nil {
+		return nil
+	}
+
+	var filename string
+	if fileNamingConvention == "snake_case" {
+		filename = filepath.Join(dir, toSnakeCase(entity)+"_view.go")
+	} else if fileNamingConvention == "kebab-case" {
+		filename = filepath.Join(dir, toKebabCase(entity)+"-view.go")
+	} else {
+		filename = filepath.Join(dir, strings.ToLower(entity)+"_view.go")
+	}
+
+	content, err := p.source(pkg, getImportPath(getModuleName()), list, inputs)
+	if err != nil {
+		ui.Error(fmt.Sprintf("Error generating %s projection: %v", protocol, err))
+		return p
+	}
+	if err := writeGoFile(filename, content, sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing %s projection file: %v", protocol, err))
+	}
+	return p
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVisibility(t *testing.T) {
+	hidden, ok, err := parseVisibility("Notes", "hide=websocket|http")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{HandlerHTTP, HandlerWebSocket}, hidden)
+
+	hidden, _, err = parseVisibility("Notes", "only=grpc|cli")
+	require.NoError(t, err)
+	assert.Equal(t, []string{HandlerHTTP, HandlerWebSocket}, hidden)
+
+	_, ok, err = parseVisibility("Notes", "enum=a|b")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = parseVisibility("Notes", "hide=soap")
+	assert.ErrorContains(t, err, `invalid protocol "soap"`)
+	_, _, err = parseVisibility("Notes", "only=http|grpc|cli|websocket")
+	assert.ErrorContains(t, err, "hides it from no protocol")
+
+	assert.Equal(t, "", optionalValidateTag("required"))
+	assert.Equal(t, "omitempty,email", optionalValidateTag("required,email"))
+}
+
+func TestParseFields_Visibility(t *testing.T) {
+	fields := parseFieldsWithValidation("title:string,internal_notes:string:hide=http,status:string:enum=open|closed:only=grpc", true)
+	require.Len(t, fields, 4, "ID is added")
+	assert.Equal(t, "`json:\"internal_notes\" gorm:\"type:varchar(255)\" hide:\"http\"`", fields[2].Tag)
+	assert.Equal(t, []string{HandlerHTTP, HandlerCLI, HandlerWebSocket}, fields[3].Hidden)
+	assert.Equal(t, []string{"open", "closed"}, fields[3].Enum)
+	assert.False(t, fieldHiddenFrom(fields[3], HandlerGRPC))
+
+	// The visibility is read back from the tag of the generated entity.
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+	require.NoError(t, generateEntity("Ticket", "title:string,internal_notes:string:hide=http|cli", true, false, false, false, false, false, false, false, "lowercase"))
+	assert.Contains(t, readEntityFieldsString("Ticket"), "internal_notes:string:hide=http|cli")
+
+	entity := readParsedGo(t, filepath.Join(DirInternal, DirDomain, "ticket.go"))
+	assert.NotContains(t, entity, "t.InternalNotes == \"\"", "hidden fields are optional")
+}
+
+func TestGenerateEntityTests_HiddenFieldsPass(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+	require.NoError(t, generateEntity("Ticket", "title:string,contact_email:string:hide=http,internal_notes:string:only=grpc", true, false, false, false, false, false, false, true, "lowercase"))
+
+	tests := readParsedGo(t, filepath.Join(DirInternal, DirDomain, "ticket_test.go"))
+	assert.Contains(t, tests, "name: \"ticket without optional internalnotes\"")
+	assert.Contains(t, tests, "\t\t{name: \"empty string\", value: \"\", wantErr: false},\n")
+	assert.Contains(t, tests, "\t\t{name: \"empty string\", value: \"\", wantErr: true},\n", "title stays required")
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	writeTestFile(t, ".", "go.mod", "module testproject\n\ngo 1.21\n\nrequire github.com/stretchr/testify v1.11.1\n")
+	test := exec.Command("go", "test", "./internal/domain")
+	test.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOSUMDB=off", "GOWORK=off")
+	out, err := test.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func TestGenerateHTTPHandler_Projection(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	sm := NewSafetyManager(false, true, false)
+	fields := "title:string,internal_notes:string:hide=http|websocket"
+	require.NoError(t, generateEntity("Ticket", fields, true, false, false, false, false, false, false, false, "lowercase", sm))
+	generateUseCaseWithFields("TicketUseCase", "Ticket", "create,read,update,delete,list", false, false, fields, "", sm)
+	generateHTTPHandler("Ticket", false, true, false, "lowercase", sm)
+
+	dir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	view := readParsedGo(t, filepath.Join(dir, "ticket_view.go"))
+	assert.Contains(t, view, "type TicketView struct {")
+	assert.NotContains(t, view, "\tInternalNotes string")
+	assert.Contains(t, view, "func newListTicketView(output usecase.ListTicketOutput) ListTicketView {")
+	assert.Contains(t, view, "input.InternalNotes = zero.InternalNotes")
+
+	handler := readParsedGo(t, filepath.Join(dir, "ticket_handler.go"))
+	assert.Contains(t, handler, "omitHiddenCreateTicketInput(&input)")
+	assert.Contains(t, handler, "omitHiddenUpdateTicketInput(&input)")
+	assert.Contains(t, handler, "newTicketView(*ticket)")
+
+	// Fields visible to a protocol get no projection there.
+	assert.Nil(t, loadProjection("Ticket", HandlerGRPC))
+	wsView := loadProjection("Ticket", HandlerWebSocket)
+	require.NotNil(t, wsView)
+	assert.Equal(t, []string{"InternalNotes"}, wsView.hiddenNames())
+
+	ws := buildWebSocketHandler("testproject", "Ticket", []string{OpCreate, OpRead, OpUpdate}, wsView)
+	assert.Contains(t, ws, `b.hub.Publish(TicketTopic, "ticket.created", newCreateTicketView(output))`)
+	assert.Contains(t, ws, `b.hub.Publish(TicketTopic, "ticket.updated", newTicketView(*ticket))`)
+}
+
+func TestGRPCEntityFields_Hidden(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+	require.NoError(t, generateEntity("Ticket", "title:string,internal_notes:string:hide=grpc", true, false, false, false, false, false, false, false, "lowercase"))
+
+	var names []string
+	for _, f := range grpcEntityFields("Ticket") {
+		names = append(names, f.Name)
+	}
+	assert.Contains(t, names, "Title")
+	assert.NotContains(t, names, "InternalNotes")
+}
@@ -0,0 +1,15 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate features from existing sources",
+	Long: `Generates features from an existing source of truth instead of a --fields
+list, such as a database schema or an OpenAPI specification.`,
+}
+
+func init() {
+	generateCmd.AddCommand(generateFromSQLCmd)
+	generateCmd.AddCommand(generateFromOpenAPICmd)
+}
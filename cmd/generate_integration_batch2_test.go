@@ -33,28 +33,28 @@ func TestDryRunGenerators_Batch2(t *testing.T) {
 		dir := t.TempDir()
 		require.NoError(t, os.Chdir(dir))
 		sm := &SafetyManager{DryRun: true}
-		generateDI("Product,Order", "postgres", false, false, sm)
+		generateDI("Product,Order", "postgres", DIManual, "", false, sm)
 	})
 
 	t.Run("generateDI wire", func(t *testing.T) {
 		dir := t.TempDir()
 		require.NoError(t, os.Chdir(dir))
 		sm := &SafetyManager{DryRun: true}
-		generateDI("Product", "mysql", true, false, sm)
+		generateDI("Product", "mysql", DIWire, "", false, sm)
 	})
 
 	t.Run("generateCompleteFeature", func(t *testing.T) {
 		dir := t.TempDir()
 		require.NoError(t, os.Chdir(dir))
 		sm := &SafetyManager{DryRun: true}
-		generateCompleteFeature("Product", "Name:string,Price:float64", "postgres", "http", true, false, false, "lowercase", sm)
+		generateCompleteFeature("Product", "Name:string,Price:float64", "postgres", "http", "", true, false, false, false, false, false, false, false, "lowercase", DTOStyleNested, allFeatureLayers(), sm)
 	})
 
 	t.Run("generateCompleteFeature grpc", func(t *testing.T) {
 		dir := t.TempDir()
 		require.NoError(t, os.Chdir(dir))
 		sm := &SafetyManager{DryRun: true}
-		generateCompleteFeature("Order", "Total:float64", "mysql", "grpc", false, true, false, "snake", sm)
+		generateCompleteFeature("Order", "Total:float64", "mysql", "grpc", "", false, true, false, false, false, false, false, false, "snake", DTOStyleNested, allFeatureLayers(), sm)
 	})
 
 	t.Run("generateEntityTests", func(t *testing.T) {
@@ -85,7 +85,7 @@ func TestDryRunGenerators_Batch2(t *testing.T) {
 		require.NoError(t, os.Chdir(dir))
 		sm := &SafetyManager{DryRun: true}
 		ci := &ConfigIntegration{config: &GocaConfig{}}
-		createProjectStructure("myproject", "github.com/user/myproject", "postgres", false, "rest", ci, false, "", sm)
+		createProjectStructure("myproject", "github.com/user/myproject", "postgres", "", "", false, "rest", ci, false, "", Preset{}, false, false, sm)
 	})
 
 	t.Run("addEntityToAutoMigration", func(t *testing.T) {
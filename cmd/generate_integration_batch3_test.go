@@ -92,7 +92,7 @@ func TestDryRunGenerators_Batch3(t *testing.T) {
 		dir := t.TempDir()
 		require.NoError(t, os.Chdir(dir))
 		sm := &SafetyManager{DryRun: true}
-		generateGRPCHandler("Product", "lowercase", sm)
+		generateGRPCHandler("Product", "lowercase", false, sm)
 	})
 
 	t.Run("generateCLIHandler", func(t *testing.T) {
@@ -201,7 +201,7 @@ func TestReplaceHelperTODOs(t *testing.T) {
 func TestGenerateTransactionMethods(t *testing.T) {
 	t.Parallel()
 	var sb strings.Builder
-	generateTransactionMethods(&sb, "Product", "ProductRepository")
+	generateTransactionMethods(&sb, "Product", "ProductRepository", false)
 	result := sb.String()
 	assert.Contains(t, result, "SaveWithTx")
 	assert.Contains(t, result, "UpdateWithTx")
@@ -212,7 +212,7 @@ func TestGenerateTransactionMethods(t *testing.T) {
 func TestGenerateBasicMongoCRUDMethods(t *testing.T) {
 	t.Parallel()
 	var sb strings.Builder
-	generateBasicMongoCRUDMethods(&sb, "Product", "ProductRepository")
+	generateBasicMongoCRUDMethods(&sb, "Product", "ProductRepository", readMongoDocument("Product", nil), false)
 	result := sb.String()
 	assert.Contains(t, result, "InsertOne")
 	assert.Contains(t, result, "FindOne")
@@ -227,7 +227,7 @@ func TestGenerateMongoSearchMethodImplementation(t *testing.T) {
 		FieldType:  "string",
 		IsUnique:   true,
 	}
-	result := generateMongoSearchMethodImplementation(method, "ProductRepository", "Product")
+	result := generateMongoSearchMethodImplementation(method, "ProductRepository", "Product", readMongoDocument("Product", nil))
 	assert.Contains(t, result, "FindByName")
 	assert.Contains(t, result, "Product")
 }
@@ -235,7 +235,7 @@ func TestGenerateMongoSearchMethodImplementation(t *testing.T) {
 func TestGenerateUpdateMethod(t *testing.T) {
 	t.Parallel()
 	var sb strings.Builder
-	generateUpdateMethod(&sb, "ProductService", "Product")
+	generateUpdateMethod(&sb, "ProductService", "Product", false)
 	result := sb.String()
 	assert.Contains(t, result, "func (P *ProductService) UpdateProduct")
 	assert.Contains(t, result, "UpdateProductInput")
@@ -4,6 +4,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -55,21 +56,21 @@ func TestCreateGoMod_DryRun(t *testing.T) {
 	tests := []struct {
 		name     string
 		database string
-		auth     bool
+		authMode string
 	}{
-		{"postgres", DBPostgres, false},
-		{"mysql", DBMySQL, false},
-		{"sqlite", DBSQLite, true},
-		{"mongodb", DBMongoDB, false},
-		{"dynamodb", DBDynamoDB, false},
-		{"elasticsearch", DBElasticsearch, false},
-		{"postgres_json", DBPostgresJSON, false},
-		{"sqlserver", DBSQLServer, false},
+		{"postgres", DBPostgres, ""},
+		{"mysql", DBMySQL, ""},
+		{"sqlite", DBSQLite, AuthModeJWT},
+		{"mongodb", DBMongoDB, AuthModeOIDC},
+		{"dynamodb", DBDynamoDB, ""},
+		{"elasticsearch", DBElasticsearch, ""},
+		{"postgres_json", DBPostgresJSON, AuthModeAPIKey},
+		{"sqlserver", DBSQLServer, ""},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			sm2 := NewSafetyManager(true, false, false)
-			createGoMod("testproj", "github.com/test/proj", tc.database, tc.auth, sm2)
+			createGoMod("testproj", "github.com/test/proj", tc.database, "", tc.authMode, false, sm2)
 			assert.NotEmpty(t, sm2.GetPendingFiles())
 		})
 	}
@@ -103,7 +104,7 @@ func TestCreateMainGo_DryRun(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			sm := NewSafetyManager(true, false, false)
-			createMainGo("testproj", "github.com/test/proj", tc.database, sm)
+			createMainGo("testproj", "github.com/test/proj", tc.database, "", false, sm)
 			assert.NotEmpty(t, sm.GetPendingFiles(), "expected pending files for %s", tc.database)
 		})
 	}
@@ -120,6 +121,65 @@ func TestCreateGitignore_DryRun(t *testing.T) {
 	assert.Contains(t, sm.GetPendingFiles()[0].Path, ".gitignore")
 }
 
+func TestCreateGitignore_MergeAppendsMissing(t *testing.T) {
+	cleanup := setupDiscardUI(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".gitignore")
+	require.NoError(t, os.WriteFile(path, []byte("node_modules/\n.env\n"), 0o644))
+
+	sm := NewSafetyManager(false, false, false)
+	sm.Merge = true
+	createGitignore(dir, sm)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "node_modules/\n.env\n"))
+	assert.Contains(t, string(content), "# Added by Goca")
+	assert.Contains(t, string(content), "vendor/")
+	assert.Equal(t, 1, strings.Count(string(content), ".env\n"))
+}
+
+func TestMergeGitignore_NothingMissing(t *testing.T) {
+	merged, changed := mergeGitignore("*.log\nvendor/\n", "# comment\n*.log\nvendor/\n")
+	assert.False(t, changed)
+	assert.Equal(t, "*.log\nvendor/\n", merged)
+}
+
+func TestResolveInitTarget(t *testing.T) {
+	parent, name, err := resolveInitTarget("myproject")
+	require.NoError(t, err)
+	assert.Empty(t, parent)
+	assert.Equal(t, "myproject", name)
+
+	dir := t.TempDir()
+	parent, name, err = resolveInitTarget(filepath.Join(dir, "svc"))
+	require.NoError(t, err)
+	assert.Equal(t, dir, parent)
+	assert.Equal(t, "svc", name)
+
+	file := filepath.Join(dir, "file.txt")
+	require.NoError(t, os.WriteFile(file, []byte("x"), 0o644))
+	_, _, err = resolveInitTarget(file)
+	assert.Error(t, err)
+}
+
+func TestDetectInitConflicts(t *testing.T) {
+	cleanup := setupDiscardUI(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "LICENSE"), []byte("MIT"), 0o644))
+	assert.Empty(t, detectInitConflicts(dir, "github.com/test/proj", DBPostgres, "", "", false, APITypeRest, true))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# mine"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".goca.yaml"), []byte("project: {}"), 0o644))
+	conflicts := detectInitConflicts(dir, "github.com/test/proj", DBPostgres, "", "", false, APITypeRest, true)
+	assert.Contains(t, conflicts, filepath.Join(dir, "README.md"))
+	assert.Contains(t, conflicts, filepath.Join(dir, ".goca.yaml"))
+}
+
 func TestCreateReadme_DryRun(t *testing.T) {
 	cleanup := setupDiscardUI(t)
 	defer cleanup()
@@ -157,8 +217,30 @@ func TestCreateAuth_DryRun(t *testing.T) {
 
 	dir := t.TempDir()
 	sm := NewSafetyManager(true, false, false)
-	createAuth(dir, "github.com/test/proj", sm)
-	assert.Len(t, sm.GetPendingFiles(), 1)
+	createAuth(dir, "github.com/test/proj", AuthModeJWT, sm)
+	assert.Len(t, sm.GetPendingFiles(), 2)
+}
+
+func TestCreateAuth_MintToken(t *testing.T) {
+	cleanup := setupDiscardUI(t)
+	defer cleanup()
+
+	dir := filepath.Join(t.TempDir(), "My_Shop")
+	createAuth(dir, "github.com/test/proj", AuthModeJWT, NewSafetyManager(false, true, false))
+	createMakefile(dir, true, NewSafetyManager(false, true, false))
+
+	jwt := readParsedGo(t, filepath.Join(dir, "pkg", "auth", "jwt.go"))
+	assert.Contains(t, jwt, `key := os.Getenv("JWT_SECRET")`)
+	assert.Contains(t, jwt, "func GenerateTokenWithExpiry(userID int, email, role string, ttl time.Duration) (string, error) {")
+
+	tool := readParsedGo(t, filepath.Join(dir, "cmd", "tools", "mint-token", "main.go"))
+	assert.Contains(t, tool, `"github.com/test/proj/pkg/auth"`)
+	assert.Contains(t, tool, `{Name: "admin", ID: 1, Email: "admin@my-shop.local", Role: "admin"},`)
+
+	makefile, err := os.ReadFile(filepath.Join(dir, "Makefile"))
+	require.NoError(t, err)
+	assert.Contains(t, string(makefile), ".PHONY: help build run test dev-token ")
+	assert.Contains(t, string(makefile), "\n"+devTokenTarget+"fmt:")
 }
 
 func TestCreateEnvFiles_DryRun(t *testing.T) {
@@ -241,7 +323,7 @@ func TestGenerateDTOFileWithFields_DryRun(t *testing.T) {
 	os.Chdir(dir)
 
 	sm := NewSafetyManager(true, false, false)
-	generateDTOFileWithFields(dir, "Product", []string{"create", "get", "list", "update", "delete"}, true, "Name:string,Price:float64", sm)
+	generateDTOFileWithFields(dir, "Product", []string{"create", "get", "list", "update", "delete"}, true, "Name:string,Price:float64", DTOStyleNested, sm)
 	assert.NotEmpty(t, sm.GetPendingFiles())
 }
 
@@ -257,7 +339,7 @@ func TestGenerateUseCaseServiceWithFields_DryRun(t *testing.T) {
 	os.Chdir(dir)
 
 	sm := NewSafetyManager(true, false, false)
-	generateUseCaseServiceWithFields(dir, "Product", "Product", []string{"create", "get", "list", "update", "delete"}, false, false, "Name:string,Price:float64", sm)
+	generateUseCaseServiceWithFields(dir, "Product", "Product", []string{"create", "get", "list", "update", "delete"}, false, false, "Name:string,Price:float64", DTOStyleNested, sm)
 	assert.NotEmpty(t, sm.GetPendingFiles())
 }
 
@@ -273,29 +355,13 @@ func TestGenerateUseCaseServiceWithFields_Async_DryRun(t *testing.T) {
 	os.Chdir(dir)
 
 	sm := NewSafetyManager(true, false, false)
-	generateUseCaseServiceWithFields(dir, "Order", "Order", []string{"create", "get"}, true, true, "Total:float64,Status:string", sm)
+	generateUseCaseServiceWithFields(dir, "Order", "Order", []string{"create", "get"}, true, true, "Total:float64,Status:string", DTOStyleNested, sm)
 	assert.NotEmpty(t, sm.GetPendingFiles())
 }
 
 // --- DI generator functions (DryRun) ---
 
-func TestGenerateWireFile_DryRun(t *testing.T) {
-	// Not parallel: os.Chdir
-	cleanup := setupDiscardUI(t)
-	defer cleanup()
-
-	origDir, _ := os.Getwd()
-	defer os.Chdir(origDir)
-
-	dir := setupProjectDir(t)
-	os.Chdir(dir)
-
-	sm := NewSafetyManager(true, false, false)
-	generateWireFile(dir, []string{"Product", "User"}, "postgres", sm)
-	assert.NotEmpty(t, sm.GetPendingFiles())
-}
-
-func TestGenerateWireGenTemplate_DryRun(t *testing.T) {
+func TestGenerateProviderDI_DryRun(t *testing.T) {
 	// Not parallel: os.Chdir
 	cleanup := setupDiscardUI(t)
 	defer cleanup()
@@ -306,9 +372,11 @@ func TestGenerateWireGenTemplate_DryRun(t *testing.T) {
 	dir := setupProjectDir(t)
 	os.Chdir(dir)
 
-	sm := NewSafetyManager(true, false, false)
-	generateWireGenTemplate(dir, []string{"Product"}, sm)
-	assert.NotEmpty(t, sm.GetPendingFiles())
+	for _, style := range []string{DIWire, DIFx} {
+		sm := NewSafetyManager(true, false, false)
+		generateProviderDI(dir, []string{"Product", "User"}, "postgres", style, false, sm)
+		assert.NotEmpty(t, sm.GetPendingFiles(), style)
+	}
 }
 
 // --- Repository generator functions (DryRun) ---
@@ -371,7 +439,7 @@ func TestGenerateProtoFile_DryRun(t *testing.T) {
 	os.Chdir(dir)
 
 	sm := NewSafetyManager(true, false, false)
-	generateProtoFile(dir, "Product", "snake_case", sm)
+	generateProtoFile(dir, "Product", "snake_case", false, sm)
 	assert.NotEmpty(t, sm.GetPendingFiles())
 }
 
@@ -390,7 +458,7 @@ func TestGenerateEntity_SeedDataOnce(t *testing.T) {
 	require.NoError(t, os.Chdir(dir))
 
 	sm := NewSafetyManager(true, false, false)
-	generateEntity("Product", "Name:string,Price:float64", false, false, false, false, false, "snake_case", sm)
+	generateEntity("Product", "Name:string,Price:float64", false, false, false, false, false, false, false, false, "snake_case", sm)
 
 	seedCount := 0
 	for _, entry := range sm.GetPendingFiles() {
@@ -411,6 +479,8 @@ func TestGetDatabasePort_Extended(t *testing.T) {
 	}{
 		{"postgres", "5432"},
 		{"mysql", "3306"},
+		{"mariadb", "3306"},
+		{"tidb", "4000"},
 		{"mongodb", "27017"},
 		{"sqlserver", "1433"},
 		{"dynamodb", "8000"},
@@ -434,6 +504,7 @@ func TestGetDatabaseUser_Extended(t *testing.T) {
 	}{
 		{"postgres", "postgres"},
 		{"mysql", "root"},
+		{"tidb", "root"},
 		{"mongodb", "admin"},
 		{"sqlserver", "sa"},
 		{"elasticsearch", "elastic"},
@@ -81,7 +81,7 @@ func TestDryRunGenerators_Sequential(t *testing.T) {
 		dir := t.TempDir()
 		require.NoError(t, os.Chdir(dir))
 		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0o644))
-		generateHTTPRoutesFile(filepath.Join(dir, "handler"), "Product", true, sm)
+		generateHTTPRoutesFile(filepath.Join(dir, "handler"), "Product", defaultHTTPRoutes("Product"), true, sm)
 	})
 
 	// Subtest: generateHTTPDTOFile
@@ -100,12 +100,12 @@ func TestDryRunGenerators_Sequential(t *testing.T) {
 		generateManualDI(filepath.Join(dir, "di"), []string{"Product", "User"}, "postgres", false, sm)
 	})
 
-	// Subtest: generateWireDI
+	// Subtest: generateProviderDI
 	t.Run("WireDI", func(t *testing.T) {
 		dir := t.TempDir()
 		require.NoError(t, os.Chdir(dir))
 		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0o644))
-		generateWireDI(filepath.Join(dir, "di"), []string{"Product"}, "postgres", sm)
+		generateProviderDI(filepath.Join(dir, "di"), []string{"Product"}, "postgres", DIWire, false, sm)
 	})
 
 	// Subtest: generateUseCaseWithFields
@@ -114,7 +114,7 @@ func TestDryRunGenerators_Sequential(t *testing.T) {
 		require.NoError(t, os.Chdir(dir))
 		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0o644))
 		require.NoError(t, os.MkdirAll("internal/usecase", 0o755))
-		generateUseCaseWithFields("ProductService", "Product", "create,read,update,delete,list", true, false, "Name:string,Price:float64", sm)
+		generateUseCaseWithFields("ProductService", "Product", "create,read,update,delete,list", true, false, "Name:string,Price:float64", DTOStyleNested, sm)
 	})
 
 	// Subtest: generateUseCase
@@ -132,7 +132,7 @@ func TestDryRunGenerators_Sequential(t *testing.T) {
 		require.NoError(t, os.Chdir(dir))
 		require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0o644))
 		require.NoError(t, os.MkdirAll("internal/domain", 0o755))
-		generateEntity("Product", "Name:string,Price:float64", true, true, true, false, false, "", sm)
+		generateEntity("Product", "Name:string,Price:float64", true, true, true, false, false, false, false, false, "", sm)
 	})
 
 	// Subtest: generateManualDI with mysql
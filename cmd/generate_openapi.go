@@ -0,0 +1,411 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var generateFromOpenAPICmd = &cobra.Command{
+	Use:   "from-openapi",
+	Short: "Generate features from an OpenAPI 3 specification",
+	Long: `Scaffolds features from an OpenAPI 3 document in YAML or JSON. Every
+collection path (/pets) and its item path (/pets/{id}) becomes a feature:
+
+  GET /pets         -> list        GET /pets/{id}          -> read
+  POST /pets        -> create      PUT|PATCH /pets/{id}    -> update
+                                   DELETE /pets/{id}       -> delete
+
+The entity is built from the component schema the operations return, the use
+case and HTTP handler only contain the operations the spec declares, and the
+routes are mounted on the spec's paths. Request and response DTOs use the
+spec's property names, and documented constraints (required, minLength,
+maxLength, minimum, maximum, enum, format) become validate tags.
+
+Nested paths and custom actions such as /pets/{id}/photos are reported and
+not generated.
+
+Examples:
+  goca generate from-openapi --file openapi.yaml
+  goca generate from-openapi --file openapi.json --schemas Pet,Owner
+  goca generate from-openapi --file openapi.yaml --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runGenerateFromOpenAPI,
+}
+
+func runGenerateFromOpenAPI(cmd *cobra.Command, _ []string) error {
+	file, _ := cmd.Flags().GetString("file")
+	schemasFlag, _ := cmd.Flags().GetString("schemas")
+	database, _ := cmd.Flags().GetString("database")
+	validation, _ := cmd.Flags().GetBool("validation")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+	backup, _ := cmd.Flags().GetBool("backup")
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("could not read OpenAPI file: %w", err)
+	}
+	doc, err := parseOpenAPIDocument(data)
+	if err != nil {
+		return fmt.Errorf("could not parse OpenAPI file: %w", err)
+	}
+	resources, skipped := doc.openAPIResources()
+	resources, err = selectOpenAPIResources(doc, resources, schemasFlag)
+	if err != nil {
+		return err
+	}
+
+	configIntegration := NewConfigIntegration()
+	if err := configIntegration.LoadConfigForProject(); err != nil {
+		ui.Warning(fmt.Sprintf("Could not load configuration: %v", err))
+	}
+	effectiveDatabase := configIntegration.GetDatabaseType(database)
+	effectiveDI := configIntegration.GetDIType("")
+	dtoStyle := configIntegration.GetDTOStyle("")
+	fileNamingConvention := "lowercase"
+	if configIntegration.config != nil {
+		fileNamingConvention = configIntegration.GetNamingConvention("file")
+	}
+
+	safetyMgr := NewSafetyManager(dryRun, force, backup)
+	if dryRun {
+		ui.DryRun("Previewing changes without creating files")
+	}
+
+	ui.Header(fmt.Sprintf("Generating %d feature(s) from OpenAPI spec", len(resources)))
+	ui.KeyValue("Database", effectiveDatabase)
+	if len(skipped) > 0 {
+		ui.Blank()
+		ui.Section("Operations not generated")
+		rows := make([][]string, 0, len(skipped))
+		for _, e := range skipped {
+			rows = append(rows, []string{e.Method, e.Path, e.Note})
+		}
+		ui.Table([]string{"Method", "Path", "Note"}, rows)
+	}
+
+	validator := NewCommandValidator()
+	var generated []string
+	usesJSON := false
+	for _, res := range resources {
+		mappings := doc.mapSchemaFields(res.Schema, res.Request)
+		fields := openAPIFieldsSpec(mappings)
+
+		ui.Blank()
+		if res.Path != "" {
+			ui.Section(fmt.Sprintf("%s -> %s", res.Path, res.Entity))
+		} else {
+			ui.Section(fmt.Sprintf("%s (schema only)", res.Entity))
+		}
+		printOpenAPIResource(res, mappings)
+
+		if fields == "" {
+			ui.Warning(fmt.Sprintf("Skipping %s: no properties map to entity fields", res.Entity))
+			continue
+		}
+		if err := validator.ValidateFeatureCommand(res.Entity, fields, effectiveDatabase, HandlerHTTP); err != nil {
+			ui.Warning(fmt.Sprintf("Skipping %s: %v", res.Entity, err))
+			continue
+		}
+
+		if err := generateOpenAPIFeature(res, fields, effectiveDatabase, validation, fileNamingConvention, dtoStyle, safetyMgr); err != nil {
+			ui.Warning(fmt.Sprintf("Skipping %s: %v", res.Entity, err))
+			continue
+		}
+		if !dryRun {
+			if err := applyOpenAPITags(res, mappings, validation, fileNamingConvention, safetyMgr); err != nil {
+				ui.Warning(fmt.Sprintf("Could not apply spec names and constraints to %s: %v", res.Entity, err))
+			}
+			if len(res.Operations) > 0 {
+				autoIntegrateFeature(res.Entity, HandlerHTTP, "", effectiveDatabase, effectiveDI, false, safetyMgr)
+			}
+		}
+
+		generated = append(generated, res.Entity)
+		usesJSON = usesJSON || fieldsUseType(parseFields(fields), FieldJSONType)
+	}
+
+	if dryRun {
+		safetyMgr.PrintSummary()
+		return nil
+	}
+	if len(generated) == 0 {
+		return errors.New("no features were generated")
+	}
+
+	projectRoot, _ := os.Getwd()
+	depMgr := NewDependencyManager(projectRoot, false)
+	requiredDeps := depMgr.GetRequiredDependenciesForFeature(HandlerHTTP, map[string]bool{
+		"validation": validation,
+		DIWire:       effectiveDI == DIWire,
+		DIFx:         effectiveDI == DIFx,
+		"json":       usesJSON,
+	})
+	for _, dep := range requiredDeps {
+		if err := depMgr.AddDependency(dep); err != nil {
+			ui.Warning(fmt.Sprintf("Could not add dependency %s: %v", dep.Module, err))
+		}
+	}
+	if err := depMgr.UpdateGoMod(); err != nil {
+		ui.Warning(fmt.Sprintf("Could not update go.mod: %v", err))
+		ui.Dim("Tip: Run 'go mod tidy' manually")
+	}
+
+	ui.Blank()
+	ui.Success(fmt.Sprintf("Generated %d feature(s): %s", len(generated), strings.Join(generated, ", ")))
+	ui.NextSteps([]string{
+		"Run: go mod tidy",
+		"Review the operations and properties that were not generated above",
+		"Start server: go run cmd/server/main.go",
+	})
+	return nil
+}
+
+// selectOpenAPIResources keeps the resources whose entity or component schema
+// is named in a comma-separated list. Named component schemas that no path
+// uses are returned as schema-only resources, which generate just the entity.
+func selectOpenAPIResources(doc *openAPIDocument, resources []*openAPIResource, list string) ([]*openAPIResource, error) {
+	if strings.TrimSpace(list) == "" {
+		if len(resources) == 0 {
+			return nil, errors.New("the spec has no /resource or /resource/{id} paths; name component schemas with --schemas")
+		}
+		return resources, nil
+	}
+
+	var selected []*openAPIResource
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		var match *openAPIResource
+		for _, res := range resources {
+			if res.SchemaName == name || res.Entity == openAPIEntityName(name) {
+				match = res
+				break
+			}
+		}
+		if match == nil {
+			schema, _ := doc.resolve(&openAPISchema{Ref: "#/components/schemas/" + name})
+			if schema == nil {
+				return nil, fmt.Errorf("schema %q not found in the spec", name)
+			}
+			match = &openAPIResource{Entity: openAPIEntityName(name), Schema: schema, SchemaName: name, Request: schema}
+		}
+		selected = append(selected, match)
+	}
+	return selected, nil
+}
+
+// printOpenAPIResource shows the endpoints of a resource and how each schema
+// property maps to a field.
+func printOpenAPIResource(res *openAPIResource, mappings []openAPIFieldMapping) {
+	if len(res.Endpoints) > 0 {
+		rows := make([][]string, 0, len(res.Endpoints))
+		for _, e := range res.Endpoints {
+			rows = append(rows, []string{e.Method, e.Path, e.Operation, e.Note})
+		}
+		ui.Table([]string{"Method", "Path", "Operation", "Note"}, rows)
+	}
+
+	rows := make([][]string, 0, len(mappings))
+	for _, m := range mappings {
+		rows = append(rows, []string{m.Property, m.Schema, m.Field, m.Create, m.Note})
+	}
+	ui.Table([]string{"Property", "Schema", "Field", "Validation", "Note"}, rows)
+}
+
+// generateOpenAPIFeature generates the layers of one resource. Unlike goca
+// feature, the use case and HTTP handler only cover the operations the spec
+// declares, and the routes are mounted on the spec's collection path.
+func generateOpenAPIFeature(res *openAPIResource, fields, database string, validation bool, fileNamingConvention, dtoStyle string, safetyMgr *SafetyManager) error {
+	ui.Blank()
+	ui.Info("Generating layers...")
+
+	ui.Step(1, "Generating domain entity...")
+	if err := generateEntity(res.Entity, fields, true, false, false, false, false, false, false, true, fileNamingConvention, safetyMgr); err != nil {
+		return err
+	}
+
+	if len(res.Operations) > 0 {
+		ui.Step(2, "Generating use cases...")
+		generateUseCaseWithFields(res.Entity+"UseCase", res.Entity, strings.Join(res.Operations, ","), validation, false, fields, dtoStyle, safetyMgr)
+
+		ui.Step(3, "Generating repository...")
+		generateRepository(res.Entity, database, false, false, false, false, fields, safetyMgr)
+
+		ui.Step(4, "Generating HTTP handler...")
+		handlerDir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+		_ = os.MkdirAll(handlerDir, 0o755)
+		generateHTTPHandlerFile(handlerDir, res.Entity, validation, false, fileNamingConvention, safetyMgr)
+		routes := httpRoutes{Path: res.Path, Operations: res.Operations, UpdateMethod: res.UpdateMethod}
+		generateHTTPRoutesFile(handlerDir, res.Entity, routes, true, safetyMgr)
+
+		ui.Step(5, "Generating messages...")
+		generateMessages(res.Entity, true, true, true, safetyMgr)
+	}
+
+	ui.Step(6, "Registering entity for auto-migration...")
+	if registered, err := registerEntityForAutoMigration(res.Entity); err != nil {
+		ui.Warning(fmt.Sprintf("Could not register entity for auto-migration: %v", err))
+	} else if registered {
+		ui.Success(fmt.Sprintf("Entity %s registered for GORM auto-migration", res.Entity))
+	}
+	return nil
+}
+
+// applyOpenAPITags gives the entity and its DTOs the spec's property names as
+// json names, and the DTOs the documented constraints as validate tags.
+func applyOpenAPITags(res *openAPIResource, mappings []openAPIFieldMapping, validation bool, fileNamingConvention string, sm ...*SafetyManager) error {
+	entityTags := map[string]map[string]string{}
+	createTags := map[string]map[string]string{}
+	updateTags := map[string]map[string]string{}
+	for _, m := range mappings {
+		if m.GoName == "" {
+			continue
+		}
+		entityTags[m.GoName] = map[string]string{"json": m.Property}
+		createTags[m.GoName] = map[string]string{"json": m.Property}
+		updateTags[m.GoName] = map[string]string{"json": m.Property + ",omitempty"}
+		if validation {
+			createTags[m.GoName]["validate"] = m.Create
+			updateTags[m.GoName]["validate"] = m.Update
+		}
+	}
+
+	entityFile := strings.ToLower(res.Entity)
+	switch fileNamingConvention {
+	case "snake_case":
+		entityFile = toSnakeCase(res.Entity)
+	case "kebab-case":
+		entityFile = toKebabCase(res.Entity)
+	}
+	if err := retagStructFields(filepath.Join(DirInternal, DirDomain, entityFile+".go"), res.Entity, entityTags, sm...); err != nil {
+		return err
+	}
+	if len(res.Operations) == 0 {
+		return nil
+	}
+
+	dtoFile := filepath.Join(DirInternal, DirUseCase, "dto.go")
+	if contains(res.Operations, OpCreate) {
+		if err := retagStructFields(dtoFile, "Create"+res.Entity+"Input", createTags, sm...); err != nil {
+			return err
+		}
+	}
+	if contains(res.Operations, OpUpdate) {
+		if err := retagStructFields(dtoFile, "Update"+res.Entity+"Input", updateTags, sm...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retagStructFields sets struct tag keys on fields of a struct type declared
+// in a Go file. tags maps a field name to the keys to set; an empty value
+// removes the key. Other keys and fields are left as they are.
+func retagStructFields(path, typeName string, tags map[string]map[string]string, sm ...*SafetyManager) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	ast.Inspect(file, func(n ast.Node) bool {
+		ts, ok := n.(*ast.TypeSpec)
+		if !ok || ts.Name.Name != typeName {
+			return true
+		}
+		st, ok := ts.Type.(*ast.StructType)
+		if !ok {
+			return false
+		}
+		found = true
+		for _, f := range st.Fields.List {
+			if len(f.Names) != 1 || tags[f.Names[0].Name] == nil {
+				continue
+			}
+			tag := ""
+			if f.Tag != nil {
+				tag, _ = strconv.Unquote(f.Tag.Value)
+			}
+			for _, key := range []string{"json", "validate"} {
+				if value, ok := tags[f.Names[0].Name][key]; ok {
+					tag = setStructTagKey(tag, key, value)
+				}
+			}
+			f.Tag = &ast.BasicLit{Kind: token.STRING, Value: "`" + tag + "`"}
+		}
+		return false
+	})
+	if !found {
+		return fmt.Errorf("type %s not found in %s", typeName, path)
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return err
+	}
+	return writeGoFileMerged(path, buf.String(), sm...)
+}
+
+// setStructTagKey sets key to value in a struct tag, keeping the order of the
+// other keys. An empty value removes the key.
+func setStructTagKey(tag, key, value string) string {
+	var parts []string
+	replaced := false
+	rest := strings.TrimSpace(tag)
+	for rest != "" {
+		colon := strings.Index(rest, ":")
+		if colon <= 0 {
+			break
+		}
+		name := rest[:colon]
+		quoted, err := strconv.QuotedPrefix(rest[colon+1:])
+		if err != nil {
+			break
+		}
+		rest = strings.TrimSpace(rest[colon+1+len(quoted):])
+		if name == key {
+			if value != "" && !replaced {
+				parts = append(parts, key+":"+strconv.Quote(value))
+			}
+			replaced = true
+			continue
+		}
+		parts = append(parts, name+":"+quoted)
+	}
+	if !replaced && value != "" {
+		parts = append(parts, key+":"+strconv.Quote(value))
+	}
+	return strings.Join(parts, " ")
+}
+
+func init() {
+	generateFromOpenAPICmd.Flags().String("file", "", "OpenAPI 3 document in YAML or JSON")
+	generateFromOpenAPICmd.Flags().String("schemas", "", "Comma-separated schemas to generate (default: every resource path)")
+	generateFromOpenAPICmd.Flags().StringP("database", "d", "", fmt.Sprintf("Database type (%s)", strings.Join(ValidDatabases, ", ")))
+	generateFromOpenAPICmd.Flags().Bool("validation", true, "Include validations in all layers")
+	_ = generateFromOpenAPICmd.MarkFlagRequired("file")
+
+	// Safety flags
+	generateFromOpenAPICmd.Flags().Bool("dry-run", false, "Preview changes without creating files")
+	generateFromOpenAPICmd.Flags().Bool("force", false, "Overwrite existing files without asking")
+	generateFromOpenAPICmd.Flags().Bool("backup", false, "Backup existing files before overwriting")
+}
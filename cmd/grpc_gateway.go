@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// gatewayRegistration matches the Register<Entity>Gateway functions of the
+// gRPC handler package.
+var gatewayRegistration = regexp.MustCompile(`func Register(\w+)Gateway\(`)
+
+// gatewayEntities returns the entities whose REST surface a grpc-gateway
+// serves, sorted by name.
+func gatewayEntities() []string {
+	files, _ := filepath.Glob(filepath.Join(DirInternal, DirHandler, DirGRPC, "*.go"))
+	var entities []string
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, m := range gatewayRegistration.FindAllStringSubmatch(string(data), -1) {
+			entities = append(entities, m[1])
+		}
+	}
+	sort.Strings(entities)
+	return entities
+}
+
+// isGatewayEntity reports whether the REST surface of entity is served by a
+// grpc-gateway in front of its gRPC server instead of an HTTP handler.
+func isGatewayEntity(entity string) bool {
+	for _, e := range gatewayEntities() {
+		if e == entity {
+			return true
+		}
+	}
+	return false
+}
+
+// validateGateway checks that a gateway was requested along with the gRPC
+// handler whose .proto it derives the REST routes from.
+func validateGateway(handlers string) error {
+	for _, h := range strings.Split(handlers, ",") {
+		if strings.TrimSpace(h) == HandlerGRPC {
+			return nil
+		}
+	}
+	return fmt.Errorf("--gateway derives the REST routes from the gRPC handler; add %s to the handlers", HandlerGRPC)
+}
+
+// withoutHTTPHandler drops the HTTP handler from a handler list: with a
+// gateway the REST routes come from the .proto instead.
+func withoutHTTPHandler(handlers string) string {
+	var kept []string
+	for _, h := range strings.Split(handlers, ",") {
+		if h = strings.TrimSpace(h); h != "" && h != HandlerHTTP {
+			kept = append(kept, h)
+		}
+	}
+	return strings.Join(kept, ",")
+}
+
+// generateGRPCGatewayFile writes Register<Entity>Gateway, which mounts the
+// REST surface of the entity's gRPC service on a router.
+func generateGRPCGatewayFile(dir, entity, fileNamingConvention string, sm ...*SafetyManager) {
+	importPath := getImportPath(getModuleName())
+	entityLower := strings.ToLower(entity)
+	routes := defaultHTTPRoutes(entity)
+
+	content := fmt.Sprintf(`//go:build proto
+// +build proto
+
+package grpc
+
+import (
+	"context"
+
+	"github.com/gorilla/mux"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "%[1]s/internal/handler/grpc/%[2]s"
+	"%[1]s/internal/usecase"
+)
+
+// Register%[3]sGateway serves the REST surface of %[3]sService under %[4]s
+// of router. grpc-gateway transcodes the requests following the
+// google.api.http annotations of %[2]s.proto and calls an in-process
+// %[3]sServer, so REST and gRPC share one implementation.
+func Register%[3]sGateway(ctx context.Context, router *mux.Router, uc usecase.%[3]sUseCase) error {
+	gateway := runtime.NewServeMux(runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+		MarshalOptions:   protojson.MarshalOptions{EmitUnpopulated: true},
+		UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+	}))
+	if err := pb.Register%[3]sServiceHandlerServer(ctx, gateway, New%[3]sServer(uc)); err != nil {
+		return err
+	}
+	router.PathPrefix(%[4]q).Handler(gateway)
+	return nil
+}
+`, importPath, entityLower, entity, routes.Path)
+
+	if err := writeGoFile(grpcFilename(dir, entity, "gateway", fileNamingConvention), content, sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing grpc gateway file: %v", err))
+	}
+}
+
+// registerGateway mounts the gateways in main.go. The mounting function lives
+// next to main.go in two files: one built with -tags proto that registers
+// every gateway, and a no-op for the default build, which has no protobuf
+// code yet.
+func registerGateway(entity string, sm ...*SafetyManager) {
+	if len(sm) > 0 && sm[0] != nil && sm[0].DryRun {
+		return
+	}
+	mainPath, found := findMainGoPath()
+	if !found {
+		printGatewayInstructions(entity)
+		return
+	}
+	defer lockSharedFile(mainPath)()
+	content, err := os.ReadFile(mainPath)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not read main.go: %v", err))
+		printGatewayInstructions(entity)
+		return
+	}
+	importPath := getImportPath(getModuleName())
+	updated, ok := wireGatewayIntoMainGo(string(content), importPath)
+	if !ok {
+		ui.Warning("main.go has no router to mount the gRPC gateway on")
+		printGatewayInstructions(entity)
+		return
+	}
+
+	dir := filepath.Dir(mainPath)
+	if err := writeGoFileMerged(filepath.Join(dir, "gateway.go"), gatewayMountSource(importPath, gatewayEntities()), sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Could not write the gateway registrations: %v", err))
+		return
+	}
+	noGateway := filepath.Join(dir, "no_gateway.go")
+	if _, err := os.Stat(noGateway); err != nil {
+		if err := writeGoFile(noGateway, fmt.Sprintf(noGatewayTemplate, importPath), sm...); err != nil {
+			ui.Warning(fmt.Sprintf("Could not write %s: %v", noGateway, err))
+			return
+		}
+	}
+
+	if updated != string(content) {
+		if formatted, err := formatGoSource(updated); err == nil {
+			updated = string(formatted)
+		}
+		if err := writeMainGoInPlace(mainPath, updated); err != nil {
+			ui.Warning(fmt.Sprintf("Could not mount the gRPC gateway in main.go: %v", err))
+			printGatewayInstructions(entity)
+			return
+		}
+	}
+	ui.Dim(fmt.Sprintf("   gRPC gateway for /api/v1%s registered in %s", defaultHTTPRoutes(entity).Path, filepath.Join(dir, "gateway.go")))
+}
+
+// wireGatewayIntoMainGo calls mountGateway on the /api/v1 router of main.go,
+// adding the DI container and the router when no feature did yet. It is
+// idempotent and reports false when main.go has no router to add them to.
+func wireGatewayIntoMainGo(content, importPath string) (string, bool) {
+	updated := ensureContainerScaffold(content)
+	if !strings.Contains(updated, websocketHubAnchor) || !strings.Contains(updated, wiringRoutesMarker) {
+		return content, false
+	}
+	if strings.Contains(updated, "mountGateway(") {
+		return updated, true
+	}
+	updated = ensureMainGoImport(updated, importPath+"/internal/di")
+	updated = ensureMainGoImport(updated, "context")
+	mount := "// REST surface of the gRPC services, served by grpc-gateway (-tags proto)\n" +
+		"\tif err := mountGateway(context.Background(), apiRouter, container); err != nil {\n" +
+		"\t\tlog.Fatalf(\"Failed to mount the gRPC gateway: %v\", err)\n" +
+		"\t}\n\t" + wiringRoutesMarker
+	return strings.Replace(updated, wiringRoutesMarker, mount, 1), true
+}
+
+// gatewayMountSource returns the gateway.go of the main package, registering
+// the gateway of every entity.
+func gatewayMountSource(importPath string, entities []string) string {
+	var b strings.Builder
+	b.WriteString("//go:build proto\n// +build proto\n\n")
+	b.WriteString("package main\n\n")
+	b.WriteString("import (\n\t\"context\"\n\n\t\"github.com/gorilla/mux\"\n\n")
+	fmt.Fprintf(&b, "\t\"%s/internal/di\"\n", importPath)
+	fmt.Fprintf(&b, "\tappgrpc \"%s/internal/handler/grpc\"\n)\n\n", importPath)
+	b.WriteString("// mountGateway serves the REST surface of the gRPC services on router.\n")
+	b.WriteString("func mountGateway(ctx context.Context, router *mux.Router, container *di.Container) error {\n")
+	for _, entity := range entities {
+		fmt.Fprintf(&b, "\tif err := appgrpc.Register%sGateway(ctx, router, container.%sUseCase()); err != nil {\n", entity, entity)
+		b.WriteString("\t\treturn err\n\t}\n")
+	}
+	b.WriteString("\treturn nil\n}\n")
+	return b.String()
+}
+
+// noGatewayTemplate is the no_gateway.go of the main package.
+const noGatewayTemplate = `//go:build !proto
+// +build !proto
+
+package main
+
+import (
+	"context"
+
+	"github.com/gorilla/mux"
+
+	"%s/internal/di"
+)
+
+// mountGateway serves nothing until the protobuf code is generated; build
+// with -tags proto to serve the REST surface of the gRPC services.
+func mountGateway(ctx context.Context, router *mux.Router, container *di.Container) error {
+	return nil
+}
+`
+
+// printGatewayInstructions prints how to mount the gateway by hand.
+func printGatewayInstructions(entity string) {
+	ui.Blank()
+	ui.Section("Manual gRPC gateway integration")
+	ui.Println("Mount the gateway on the /api/v1 router (build with -tags proto):")
+	ui.Dim(fmt.Sprintf("      appgrpc.Register%sGateway(ctx, apiRouter, container.%sUseCase())", entity, entity))
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateGRPCHandler_Gateway(t *testing.T) {
+	defer ensureTestUI(t)()
+	setupCLIProject(t, fullProductUseCase)
+
+	generateGRPCHandler("Product", "lowercase", true, NewSafetyManager(false, true, false))
+	dir := filepath.Join(DirInternal, DirHandler, DirGRPC)
+
+	proto, err := os.ReadFile(filepath.Join(dir, "product.proto"))
+	require.NoError(t, err)
+	assert.Contains(t, string(proto), `import "google/api/annotations.proto";`)
+	assert.Contains(t, string(proto), "put: \"/api/v1/products/{id}\"\n      body: \"*\"")
+	assert.Contains(t, string(proto), "optional double price = 3;")
+
+	mapping := readParsedGo(t, filepath.Join(dir, "product_mapping.go"))
+	assert.Contains(t, mapping, "func productToProto(e *domain.Product) *pb.Product {")
+	assert.Contains(t, mapping, "func createProductInput(req *pb.CreateProductRequest) usecase.CreateProductInput {")
+	assert.Contains(t, mapping, "input.Price = req.Price")
+
+	server := readParsedGo(t, filepath.Join(dir, "product_server.go"))
+	assert.Contains(t, server, "s.usecase.UpdateProduct(int(req.Id), updateProductInput(req))")
+	assert.Contains(t, server, "items = append(items, productToProto(&output.Products[i]))")
+
+	gateway := readParsedGo(t, filepath.Join(dir, "product_gateway.go"))
+	assert.Contains(t, gateway, "pb.RegisterProductServiceHandlerServer(ctx, gateway, NewProductServer(uc))")
+	assert.Contains(t, gateway, `router.PathPrefix("/products").Handler(gateway)`)
+
+	stub := readParsedGo(t, filepath.Join(dir, "product", "placeholder.pb.go"))
+	assert.Contains(t, stub, "func RegisterProductServiceHandlerServer(")
+	assert.True(t, isGatewayEntity("Product"))
+	assert.Equal(t, []string{"Product"}, gatewayEntities())
+}
+
+func TestWireGatewayIntoMainGo(t *testing.T) {
+	main := `package main
+
+import (
+	"log"
+
+	"github.com/gorilla/mux"
+)
+
+func main() {
+	router := mux.NewRouter()
+}
+`
+	updated, ok := wireGatewayIntoMainGo(main, "testproject")
+	require.True(t, ok)
+	assert.Contains(t, updated, `"testproject/internal/di"`)
+	assert.Contains(t, updated, "container := di.NewContainer(db)")
+	assert.Contains(t, updated, "mountGateway(context.Background(), apiRouter, container)")
+	assert.Less(t, strings.Index(updated, "mountGateway("), strings.Index(updated, wiringRoutesMarker))
+
+	again, ok := wireGatewayIntoMainGo(updated, "testproject")
+	require.True(t, ok)
+	assert.Equal(t, updated, again)
+
+	_, ok = wireGatewayIntoMainGo("package main\n\nfunc main() {}\n", "testproject")
+	assert.False(t, ok)
+
+	mount := gatewayMountSource("testproject", []string{"Order", "Product"})
+	assert.Contains(t, mount, "appgrpc.RegisterProductGateway(ctx, router, container.ProductUseCase())")
+	assert.Equal(t, "grpc,cli", withoutHTTPHandler("http, grpc,cli"))
+	assert.Error(t, validateGateway("http"))
+}
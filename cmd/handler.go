@@ -28,6 +28,11 @@ var handlerCmd = &cobra.Command{
 		middleware, _ := cmd.Flags().GetBool("middleware")
 		validation, _ := cmd.Flags().GetBool("validation")
 		swagger, _ := cmd.Flags().GetBool("swagger")
+		contracts, _ := cmd.Flags().GetBool("contracts")
+		gateway, _ := cmd.Flags().GetBool("gateway")
+		jobs, _ := cmd.Flags().GetString("jobs")
+		apiVersionFlag, _ := cmd.Flags().GetString("api-version")
+		presenterFlag, _ := cmd.Flags().GetBool("presenter")
 
 		// Merge only explicitly changed CLI flags with config
 		flags := map[string]interface{}{}
@@ -68,6 +73,25 @@ var handlerCmd = &cobra.Command{
 			effectiveSwagger = configIntegration.config.Generation.Documentation.Swagger.Enabled
 		}
 
+		effectiveContracts := configIntegration.GetContractsEnabled(contracts) && effectiveHandlerType == HandlerHTTP
+		// Only the HTTP, gRPC and CLI handlers present entities.
+		effectivePresenter := configIntegration.GetPresenterEnabled(presenterFlag) &&
+			contains([]string{HandlerHTTP, HandlerGRPC, HandlerCLI}, effectiveHandlerType)
+		if gateway {
+			if err := validateGateway(effectiveHandlerType); err != nil {
+				ui.Error(err.Error())
+				os.Exit(1)
+			}
+		}
+
+		if apiVersionFlag != "" {
+			if err := validateAPIVersion(apiVersionFlag); err != nil {
+				ui.Error(err.Error())
+				os.Exit(1)
+			}
+		}
+		apiVersion := featureAPIVersion(entity, apiVersionFlag)
+
 		// Get naming convention from config
 		fileNamingConvention := "lowercase" // default
 		if configIntegration.config != nil {
@@ -100,6 +124,21 @@ var handlerCmd = &cobra.Command{
 		if effectiveSwagger && effectiveHandlerType == HandlerHTTP {
 			ui.Feature("Including Swagger documentation", false)
 		}
+		if effectiveContracts {
+			ui.Feature("Including Pact contract tests", !contracts)
+		}
+		if gateway {
+			ui.Feature("Including REST gateway", false)
+		}
+		if effectivePresenter {
+			ui.Feature("Presenting entities through a shared presenter", !presenterFlag)
+		}
+		if jobs != "" && effectiveHandlerType == HandlerCron {
+			ui.KeyValue("Scheduled operations", jobs)
+		}
+		if apiVersion != defaultAPIVersion && effectiveHandlerType == HandlerHTTP {
+			ui.KeyValue("API version", apiVersion)
+		}
 
 		// Initialize safety manager
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
@@ -121,14 +160,44 @@ var handlerCmd = &cobra.Command{
 		}
 
 		filesBefore := len(sm.GetCreatedFiles())
-		generateHandler(entity, effectiveHandlerType, effectiveMiddleware, effectiveValidation, effectiveSwagger, fileNamingConvention, sm)
+		if effectivePresenter && (loadPresenter(entity) == nil || force) {
+			if err := generatePresenterFile(entity, fileNamingConvention, sm); err != nil {
+				ui.Warning(err.Error())
+			}
+		}
+		if gateway {
+			generateGRPCHandler(entity, fileNamingConvention, true, sm)
+		} else if effectiveHandlerType == HandlerCron {
+			generateCronHandler(entity, fileNamingConvention, jobs, sm)
+		} else if effectiveHandlerType == HandlerHTTP {
+			generateVersionedHTTPHandler(entity, apiVersion, effectiveMiddleware, effectiveValidation, effectiveSwagger, fileNamingConvention, sm)
+		} else {
+			generateHandler(entity, effectiveHandlerType, effectiveMiddleware, effectiveValidation, effectiveSwagger, fileNamingConvention, sm)
+		}
+		if effectiveContracts {
+			generateContractTests(entity, sm)
+		}
 		filesWritten := len(sm.GetCreatedFiles()) - filesBefore
+		if filesWritten > 0 {
+			runPluginHooks(entity, sm, pluginHookHandler)
+		}
 
 		if dryRun {
 			sm.PrintSummary()
 			return
 		}
 
+		if effectiveHandlerType == HandlerWebSocket && filesWritten > 0 {
+			registerWebSocketRoutes(entity, sm)
+		}
+		if effectiveHandlerType == HandlerGRPC && filesWritten > 0 && isGatewayEntity(entity) {
+			registerGateway(entity, sm)
+		}
+		// v1 routes are wired by goca feature; later versions join them here.
+		if effectiveHandlerType == HandlerHTTP && filesWritten > 0 && apiVersion != defaultAPIVersion {
+			registerVersionedRoutes(entity, apiVersion, sm)
+		}
+
 		// If nothing was written (e.g. files already exist and --force was not
 		// given), don't claim success or touch dependencies.
 		if filesWritten == 0 {
@@ -139,7 +208,13 @@ var handlerCmd = &cobra.Command{
 		// Add required dependencies
 		projectRoot, _ := os.Getwd()
 		depMgr := NewDependencyManager(projectRoot, false)
-		features := map[string]bool{"validation": effectiveValidation}
+		features := map[string]bool{
+			"validation": effectiveValidation,
+			"contracts":  effectiveContracts,
+			"storage":    effectiveHandlerType == HandlerHTTP && len(entityFileFields(entity)) > 0,
+			"zstd":       effectiveHandlerType == HandlerHTTP && compressionUsesZstd(),
+			"gateway":    effectiveHandlerType == HandlerGRPC && isGatewayEntity(entity),
+		}
 		requiredDeps := depMgr.GetRequiredDependenciesForFeature(effectiveHandlerType, features)
 		for _, dep := range requiredDeps {
 			if err := depMgr.AddDependency(dep); err != nil {
@@ -207,7 +282,10 @@ func usecaseExistsForHandler(entity string) bool {
 // project's module files.
 func updateGoModBestEffort(depMgr *DependencyManager, projectRoot string) error {
 	goModPath := filepath.Join(projectRoot, "go.mod")
-	goSumPath := filepath.Join(projectRoot, "go.sum")
+	if depMgr.goModPath != "" {
+		goModPath = depMgr.goModPath
+	}
+	goSumPath := filepath.Join(filepath.Dir(goModPath), "go.sum")
 
 	goModBackup, goModErr := os.ReadFile(goModPath)
 	goSumBackup, goSumErr := os.ReadFile(goSumPath)
@@ -228,15 +306,20 @@ func updateGoModBestEffort(depMgr *DependencyManager, projectRoot string) error
 }
 
 func generateHandler(entity, handlerType string, middleware, validation, swagger bool, fileNamingConvention string, sm ...*SafetyManager) {
+	defer profileStep("handler", entity, handlerType)()
 	switch handlerType {
 	case HandlerHTTP:
-		generateHTTPHandler(entity, middleware, validation, swagger, fileNamingConvention, sm...)
+		generateVersionedHTTPHandler(entity, projectAPIVersion(entity), middleware, validation, swagger, fileNamingConvention, sm...)
 	case HandlerGRPC:
-		generateGRPCHandler(entity, fileNamingConvention, sm...)
+		generateGRPCHandler(entity, fileNamingConvention, isGatewayEntity(entity), sm...)
 	case HandlerCLI:
 		generateCLIHandler(entity, fileNamingConvention, sm...)
 	case "worker":
 		generateWorkerHandler(entity, fileNamingConvention, sm...)
+	case HandlerWebSocket:
+		generateWebSocketHandler(entity, fileNamingConvention, sm...)
+	case HandlerCron:
+		generateCronHandler(entity, fileNamingConvention, "", sm...)
 	case "soap":
 		generateSOAPHandler(entity, fileNamingConvention, sm...)
 	default:
@@ -246,34 +329,69 @@ func generateHandler(entity, handlerType string, middleware, validation, swagger
 }
 
 func generateHTTPHandler(entity string, middleware, validation, swagger bool, fileNamingConvention string, sm ...*SafetyManager) {
+	generateVersionedHTTPHandler(entity, defaultAPIVersion, middleware, validation, swagger, fileNamingConvention, sm...)
+}
+
+// generateVersionedHTTPHandler generates the HTTP handler serving entity
+// under an API version. Versions after v1 get their own handler and
+// Setup<Entity>V<N>Routes next to those of earlier versions, sharing the
+// projections and helpers of the package.
+func generateVersionedHTTPHandler(entity, version string, middleware, validation, swagger bool, fileNamingConvention string, sm ...*SafetyManager) {
 	// Create handlers directory if it doesn't exist
 	handlerDir := filepath.Join(DirInternal, DirHandler, DirHTTP)
 	_ = os.MkdirAll(handlerDir, 0o755)
 
 	// Generate handler file
-	generateHTTPHandlerFile(handlerDir, entity, validation, swagger, fileNamingConvention, sm...)
+	generateVersionedHTTPHandlerFile(handlerDir, entity, version, validation, swagger, fileNamingConvention, sm...)
+
+	// Generate the projections leaving out the fields hidden from HTTP
+	generateProjectionFile(handlerDir, DirHTTP, entity, HandlerHTTP, fileNamingConvention, true, true, sm...)
 
 	// Generate routes file
-	generateHTTPRoutesFile(handlerDir, entity, middleware, sm...)
+	routes := defaultHTTPRoutes(entity)
+	routes.Version = version
+	generateHTTPRoutesFile(handlerDir, entity, routes, middleware, sm...)
+
+	// Announce the retirement of deprecated versions (api.versioning.deprecated)
+	if len(projectVersioning().Deprecated) > 0 {
+		generateHTTPDeprecationFile(handlerDir, sm...)
+	}
 
 	// Generate DTOs for HTTP if validation is enabled
 	if validation {
 		generateHTTPDTOFile(handlerDir, entity, sm...)
 	}
 
-	// Generate Swagger docs if requested
+	// Generate Swagger docs and the /docs endpoint that serves them
 	if swagger {
 		generateSwaggerFile(handlerDir, entity, sm...)
+		generateDocsEndpoint(handlerDir, sm...)
+	}
+
+	// Compress responses of the whole router (api.compression)
+	if projectCompression().Enabled {
+		ensureCompressionMiddleware(sm...)
 	}
 }
 
 func generateHTTPHandlerFile(dir, entity string, validation, swagger bool, fileNamingConvention string, sm ...*SafetyManager) {
+	generateVersionedHTTPHandlerFile(dir, entity, defaultAPIVersion, validation, swagger, fileNamingConvention, sm...)
+}
+
+// generateVersionedHTTPHandlerFile writes the handler of entity for an API
+// version: <entity>_handler.go for v1, <entity>_v<n>_handler.go with a
+// <Entity>V<N>Handler for later versions.
+func generateVersionedHTTPHandlerFile(dir, entity, version string, validation, swagger bool, fileNamingConvention string, sm ...*SafetyManager) {
+	suffix := apiVersionSuffix(version)
+
 	// Apply naming convention to filename
 	var filename string
 	if fileNamingConvention == "snake_case" {
-		filename = filepath.Join(dir, toSnakeCase(entity)+"_handler.go")
+		filename = filepath.Join(dir, toSnakeCase(entity+suffix)+"_handler.go")
 	} else if fileNamingConvention == "kebab-case" {
-		filename = filepath.Join(dir, toKebabCase(entity)+"-handler.go")
+		filename = filepath.Join(dir, toKebabCase(entity+suffix)+"-handler.go")
+	} else if suffix != "" {
+		filename = filepath.Join(dir, strings.ToLower(entity)+"_"+strings.ToLower(suffix)+"_handler.go")
 	} else {
 		filename = filepath.Join(dir, strings.ToLower(entity)+"_handler.go")
 	}
@@ -282,167 +400,343 @@ func generateHTTPHandlerFile(dir, entity string, validation, swagger bool, fileN
 	moduleName := getModuleName()
 	importPath := getImportPath(moduleName)
 
-	var content strings.Builder
-	content.WriteString("package " + DirHTTP + "\n\n")
-	content.WriteString("import (\n")
-	content.WriteString("\t\"encoding/json\"\n")
-	content.WriteString("\t\"net/http\"\n")
-	content.WriteString("\t\"strconv\"\n\n")
-	content.WriteString("\t\"github.com/gorilla/mux\"\n")
-	content.WriteString(fmt.Sprintf("\t\"%s/internal/usecase\"\n", importPath))
-	if validation {
-		content.WriteString("\t\"github.com/go-playground/validator/v10\"\n")
+	// Only the operations the use case declares get a handler method.
+	ops := make(map[string]bool)
+	for _, op := range useCaseOperations(entity) {
+		ops[op] = true
+	}
+	byID := ops[OpRead] || ops[OpUpdate] || ops[OpDelete]
+	// Versioned entities carry their version in ETag and If-Match headers.
+	versioned := (ops[OpRead] || ops[OpUpdate]) && isVersionedEntity(entity)
+	conflicts := versioned && ops[OpUpdate]
+	// Audited entities answer their history and act for the request's user;
+	// multi-tenant entities act for the request's tenant.
+	audited := isAuditedEntity(entity)
+	if audited {
+		byID = true
+	}
+	tenant := isMultiTenantEntity(entity)
+	acting := audited || tenant
+
+	// Envelope and problem handlers write every response through the
+	// helpers in response.go, which also replace json.NewEncoder.
+	format := projectResponseFormat()
+	// Entities with file fields decode requests with decodeRequest, which
+	// stores uploads, and answer a download route per file.
+	files := entityFileFields(entity)
+	uploads := len(files) > 0 && (ops[OpCreate] || ops[OpUpdate])
+	downloads := len(files) > 0 && ops[OpRead]
+	// With api.strict_decoding, request bodies are decoded by decode.go.
+	strict, maxDepth := projectStrictDecoding()
+	usesJSON := (ops[OpCreate] || ops[OpUpdate]) && !uploads && !strict
+	if format == ResponseFormatRaw {
+		usesJSON = usesJSON || ops[OpCreate] || ops[OpRead] || ops[OpList] || ops[OpSearch] || audited
 	}
-	content.WriteString(")\n\n")
 
-	// Handler struct
-	handlerName := fmt.Sprintf("%sHandler", entity)
-	content.WriteString(fmt.Sprintf("type %s struct {\n", handlerName))
-	content.WriteString(fmt.Sprintf("\tusecase usecase.%sUseCase\n", entity))
-	content.WriteString("}\n\n")
+	// Validated handlers register the validations of the entity's enum fields.
+	validates := validation && (ops[OpCreate] || ops[OpUpdate])
+	var enums strings.Builder
+	usesDomain := conflicts
+	// The enums are registered once, by the v1 handler when there is one.
+	registers := validates && (suffix == "" || findTypeFile(dir, entity+"Handler") == "")
+	if registers && writeEnumRegistrations(&enums, entity) {
+		usesDomain = true
+	}
+	handlerName := fmt.Sprintf("%s%sHandler", entity, suffix)
 
-	// Constructor
-	content.WriteString(fmt.Sprintf("func New%s(uc usecase.%sUseCase) *%s {\n",
-		handlerName, entity, handlerName))
-	content.WriteString(fmt.Sprintf("\treturn &%s{usecase: uc}\n", handlerName))
-	content.WriteString("}\n\n")
+	var content strings.Builder
+	writeGoTemplate(&content, handlerTemplates, "handler", httpHandlerHeader{
+		Package: DirHTTP,
+		Module:  importPath,
+		Entity:  entity,
+		Name:    handlerName,
+		JSON:    usesJSON,
+		Errors:  conflicts,
+		Strconv: (byID && idParamType(entity) == "int") || ops[OpCount],
+		Mux:     byID,
+		Domain:  usesDomain,
+		Enums:   enums.String(),
+	})
 
 	// Generate HTTP methods
-	generateCreateHandlerMethod(&content, entity, handlerName, validation, swagger)
-	generateGetHandlerMethod(&content, entity, handlerName, swagger)
-	generateUpdateHandlerMethod(&content, entity, handlerName, validation, swagger)
-	generateDeleteHandlerMethod(&content, entity, handlerName, swagger)
-	generateListHandlerMethod(&content, entity, handlerName, swagger)
+	if ops[OpCreate] {
+		generateCreateHandlerMethod(&content, entity, handlerName, validation, swagger, acting, format)
+	}
+	if ops[OpRead] {
+		generateGetHandlerMethod(&content, entity, handlerName, swagger, versioned, tenant, format)
+	}
+	if ops[OpUpdate] {
+		generateUpdateHandlerMethod(&content, entity, handlerName, validation, swagger, versioned, acting, format)
+	}
+	if ops[OpDelete] {
+		generateDeleteHandlerMethod(&content, entity, handlerName, swagger, acting, format)
+	}
+	if ops[OpList] {
+		generateListHandlerMethod(&content, entity, handlerName, swagger, tenant, format)
+	}
+	if ops[OpCount] {
+		generateCountHandlerMethod(&content, entity, handlerName, swagger, tenant, format)
+	}
+	if ops[OpSearch] {
+		generateSearchHandlerMethod(&content, entity, handlerName, swagger, tenant, format)
+		generateHTTPQueryFile(dir, sm...)
+	}
+	if downloads {
+		for _, field := range files {
+			generateFileHandlerMethod(&content, entity, handlerName, field, swagger, acting, format)
+		}
+	}
+	if len(files) > 0 {
+		generateHTTPFilesFile(dir, sm...)
+		generateStoragePackage(sm...)
+	}
+	if versioned {
+		generateHTTPETagFile(dir, sm...)
+	}
+	if audited {
+		generateHistoryHandlerMethod(&content, entity, handlerName, swagger, format)
+		if ops[OpCreate] || ops[OpUpdate] || ops[OpDelete] {
+			writeActingUseCaseMethod(&content, entity, handlerName)
+		}
+		generateHTTPActorFile(dir, sm...)
+	}
+	if tenant && !audited {
+		writeTenantActingMethod(&content, entity, handlerName)
+		generateHTTPTenantFile(dir, sm...)
+	}
 
-	if err := writeGoFile(filename, content.String(), sm...); err != nil {
+	source := usePresenter(content.String())
+	if suffix != "" {
+		// Swagger routes are unique per method, so later versions document
+		// theirs under the version's prefix.
+		source = strings.ReplaceAll(source, "// @Router /", "// @Router "+apiVersionPrefix(version)+"/")
+	}
+	if err := writeGoFile(filename, source, sm...); err != nil {
 		ui.Error(fmt.Sprintf("Error writing handler file: %v", err))
 		return
 	}
+
+	if format != ResponseFormatRaw {
+		generateHTTPResponseFile(dir, format, sm...)
+	} else {
+		generateHTTPStatusFile(dir, sm...)
+	}
+	if validates {
+		generateHTTPValidationFile(dir, format, sm...)
+	}
+	if strict && (ops[OpCreate] || ops[OpUpdate]) {
+		generateHTTPDecodeFile(dir, format, maxDepth, sm...)
+	}
+	if (ops[OpCreate] && len(inputDeprecations("Create"+entity+"Input")) > 0) || (ops[OpUpdate] && len(inputDeprecations("Update"+entity+"Input")) > 0) {
+		generateHTTPDeprecatedFieldsFile(dir, sm...)
+	}
+}
+
+// writeHandlerID writes the reading of the {id} route variable into id. A
+// numeric ID that does not parse is answered with 400; ObjectIDs are passed
+// on as they are, and the repository finds no entity for malformed ones.
+func writeHandlerID(content *strings.Builder, entity, format string) {
+	content.WriteString("\tvars := mux.Vars(r)\n")
+	if idParamType(entity) == "string" {
+		content.WriteString("\tid := vars[\"id\"]\n\n")
+		return
+	}
+	content.WriteString("\tid, err := strconv.Atoi(vars[\"id\"])\n")
+	content.WriteString("\tif err != nil {\n")
+	writeHandlerError(content, format, "http.StatusBadRequest", fmt.Sprintf("\"Invalid %s ID\"", strings.ToLower(entity)))
+	content.WriteString("\t\treturn\n")
+	content.WriteString("\t}\n\n")
 }
 
 // writeSwaggerAnnotations emits the swaggo godoc annotation block for a handler
-// method when swagger is enabled.
-func writeSwaggerAnnotations(content *strings.Builder, entity, summary, method, route, successCode, successType, bodyType string) {
+// method when swagger is enabled. Envelope responses wrap successType in
+// Envelope, and failures document the body of the response format.
+func writeSwaggerAnnotations(content *strings.Builder, entity, summary, method, route, successCode, successType, bodyType, format string) {
 	entityLower := strings.ToLower(entity)
-	pluralTag := entityLower + "s"
+	pluralTag := toPlural(entityLower)
 	fmt.Fprintf(content, "// %s godoc\n", summary)
 	fmt.Fprintf(content, "// @Summary %s\n", summary)
 	fmt.Fprintf(content, "// @Tags %s\n", pluralTag)
 	content.WriteString("// @Accept json\n")
 	content.WriteString("// @Produce json\n")
 	if strings.Contains(route, "{id}") {
-		fmt.Fprintf(content, "// @Param id path int true \"%s ID\"\n", entity)
+		fmt.Fprintf(content, "// @Param id path %s true \"%s ID\"\n", idParamType(entity), entity)
 	}
 	if bodyType != "" {
 		fmt.Fprintf(content, "// @Param body body %s true \"%s payload\"\n", bodyType, entity)
 	}
-	if successType != "" {
+	if successType != "" && format == ResponseFormatEnvelope {
+		fmt.Fprintf(content, "// @Success %s {object} Envelope{data=%s}\n", successCode, successType)
+	} else if successType != "" {
 		fmt.Fprintf(content, "// @Success %s {object} %s\n", successCode, successType)
 	} else {
 		fmt.Fprintf(content, "// @Success %s\n", successCode)
 	}
-	content.WriteString("// @Failure 400 {object} map[string]string\n")
-	content.WriteString("// @Failure 500 {object} map[string]string\n")
+	switch format {
+	case ResponseFormatEnvelope, ResponseFormatProblem:
+		failureType := "Envelope"
+		if format == ResponseFormatProblem {
+			failureType = "Problem"
+		}
+		fmt.Fprintf(content, "// @Failure 400 {object} %s\n", failureType)
+		if strings.Contains(route, "{id}") {
+			fmt.Fprintf(content, "// @Failure 404 {object} %s\n", failureType)
+		}
+		if bodyType != "" {
+			fmt.Fprintf(content, "// @Failure 422 {object} %s\n", failureType)
+		}
+		fmt.Fprintf(content, "// @Failure 500 {object} %s\n", failureType)
+	default:
+		content.WriteString("// @Failure 400 {object} map[string]string\n")
+		content.WriteString("// @Failure 500 {object} map[string]string\n")
+	}
 	fmt.Fprintf(content, "// @Router %s [%s]\n", route, method)
 }
 
-func generateCreateHandlerMethod(content *strings.Builder, entity, handlerName string, validation, swagger bool) {
+func generateCreateHandlerMethod(content *strings.Builder, entity, handlerName string, validation, swagger, acting bool, format string) {
 	handlerVar := strings.ToLower(string(handlerName[0]))
 	entityLower := strings.ToLower(entity)
 
+	// Fields hidden from HTTP are left out of the output and the input.
+	// Otherwise the output is presented when the entity has a presenter.
+	view := loadProjection(entity, HandlerHTTP)
+	response, successType := "output", fmt.Sprintf("usecase.Create%sOutput", entity)
+	if view.createView() {
+		response, successType = fmt.Sprintf("newCreate%sView(output)", entity), fmt.Sprintf("Create%sView", entity)
+	} else if view == nil && loadPresenter(entity).has("NewCreated"+entity) {
+		response, successType = fmt.Sprintf("presenter.NewCreated%s(output)", entity), fmt.Sprintf("presenter.Created%s", entity)
+	}
+
+	deprecations := inputDeprecations(fmt.Sprintf("Create%sInput", entity))
 	if swagger {
-		writeSwaggerAnnotations(content, entity, fmt.Sprintf("Create %s", entityLower), "post", "/"+entityLower+"s", "201", fmt.Sprintf("usecase.Create%sOutput", entity), fmt.Sprintf("usecase.Create%sInput", entity))
+		writeSwaggerAnnotations(content, entity, fmt.Sprintf("Create %s", entityLower), "post", "/"+toPlural(entityLower), "201", successType, fmt.Sprintf("usecase.Create%sInput", entity), format)
+		if len(deprecations) > 0 {
+			writeDeprecatedFieldsDoc(content, "201", deprecations)
+		}
 	}
 
 	fmt.Fprintf(content, "func (%s *%s) Create%s(w http.ResponseWriter, r *http.Request) {\n",
 		handlerVar, handlerName, entity)
 	fmt.Fprintf(content, "\tvar input usecase.Create%sInput\n\n", entity)
-
-	content.WriteString("\tif err := json.NewDecoder(r.Body).Decode(&input); err != nil {\n")
-	content.WriteString("\t\thttp.Error(w, \"Invalid request body\", http.StatusBadRequest)\n")
-	content.WriteString("\t\treturn\n")
-	content.WriteString("\t}\n\n")
+	writeRequestDecoding(content, entity, OpCreate, format)
+	if view != nil && len(view.createInput) > 0 {
+		fmt.Fprintf(content, "\tomitHiddenCreate%sInput(&input)\n\n", entity)
+	}
+	if len(deprecations) > 0 {
+		writeDeprecatedFieldsUpgrade(content)
+	}
 
 	if validation {
-		content.WriteString("\tif err := validator.New().Struct(input); err != nil {\n")
-		content.WriteString("\t\thttp.Error(w, err.Error(), http.StatusUnprocessableEntity)\n")
-		content.WriteString("\t\treturn\n")
-		content.WriteString("\t}\n\n")
+		writeRequestValidation(content, entity)
 	}
 
-	fmt.Fprintf(content, "\toutput, err := %s.usecase.Create%s(input)\n", handlerVar, entity)
+	fmt.Fprintf(content, "\toutput, err := %s.Create%s(input)\n", handlerUseCase(handlerVar, acting), entity)
 	content.WriteString("\tif err != nil {\n")
-	content.WriteString("\t\thttp.Error(w, err.Error(), http.StatusInternalServerError)\n")
+	writeUploadCleanup(content, entity)
+	writeUseCaseError(content, format, "http.StatusInternalServerError")
 	content.WriteString("\t\treturn\n")
 	content.WriteString("\t}\n\n")
 
-	content.WriteString("\tw.Header().Set(\"Content-Type\", \"application/json\")\n")
-	content.WriteString("\tw.WriteHeader(http.StatusCreated)\n")
-	content.WriteString("\tjson.NewEncoder(w).Encode(output)\n")
+	if format == ResponseFormatRaw {
+		content.WriteString("\tw.Header().Set(\"Content-Type\", \"application/json\")\n")
+		content.WriteString("\tw.WriteHeader(http.StatusCreated)\n")
+		fmt.Fprintf(content, "\tjson.NewEncoder(w).Encode(%s)\n", response)
+	} else {
+		fmt.Fprintf(content, "\trespond(w, http.StatusCreated, %s)\n", response)
+	}
 	content.WriteString("}\n\n")
 }
 
-func generateGetHandlerMethod(content *strings.Builder, entity, handlerName string, swagger bool) {
+func generateGetHandlerMethod(content *strings.Builder, entity, handlerName string, swagger, versioned, acting bool, format string) {
 	handlerVar := strings.ToLower(string(handlerName[0]))
 	entityLower := strings.ToLower(entity)
 
+	// Get returns the domain entity (there is no Get<Entity>Output DTO), its
+	// projection when fields are hidden from HTTP, or its presentation.
+	response, successType := entityLower, fmt.Sprintf("domain.%s", entity)
+	if loadProjection(entity, HandlerHTTP) != nil {
+		response, successType = fmt.Sprintf("new%sView(*%s)", entity, entityLower), entity+"View"
+	} else if loadPresenter(entity).has("New" + entity) {
+		response, successType = fmt.Sprintf("presenter.New%s(*%s)", entity, entityLower), "presenter."+entity
+	}
+
 	if swagger {
-		// Get returns the domain entity (there is no Get<Entity>Output DTO).
-		writeSwaggerAnnotations(content, entity, fmt.Sprintf("Get %s by ID", entityLower), "get", "/"+entityLower+"s/{id}", "200", fmt.Sprintf("domain.%s", entity), "")
+		writeSwaggerAnnotations(content, entity, fmt.Sprintf("Get %s by ID", entityLower), "get", "/"+toPlural(entityLower)+"/{id}", "200", successType, "", format)
 	}
 
 	fmt.Fprintf(content, "func (%s *%s) Get%s(w http.ResponseWriter, r *http.Request) {\n",
 		handlerVar, handlerName, entity)
-	content.WriteString("\tvars := mux.Vars(r)\n")
-	content.WriteString("\tid, err := strconv.Atoi(vars[\"id\"])\n")
-	content.WriteString("\tif err != nil {\n")
-	fmt.Fprintf(content, "\t\thttp.Error(w, \"Invalid %s ID\", http.StatusBadRequest)\n", strings.ToLower(entity))
-	content.WriteString("\t\treturn\n")
-	content.WriteString("\t}\n\n")
+	writeHandlerID(content, entity, format)
 
-	fmt.Fprintf(content, "\t%s, err := %s.usecase.Get%s(id)\n", strings.ToLower(entity), handlerVar, entity)
+	fmt.Fprintf(content, "\t%s, err := %s.Get%s(id)\n", strings.ToLower(entity), handlerUseCase(handlerVar, acting), entity)
 	content.WriteString("\tif err != nil {\n")
-	content.WriteString("\t\thttp.Error(w, err.Error(), http.StatusNotFound)\n")
+	writeUseCaseError(content, format, "http.StatusNotFound")
 	content.WriteString("\t\treturn\n")
 	content.WriteString("\t}\n\n")
 
-	content.WriteString("\tw.Header().Set(\"Content-Type\", \"application/json\")\n")
-	fmt.Fprintf(content, "\tjson.NewEncoder(w).Encode(%s)\n", strings.ToLower(entity))
+	if versioned {
+		fmt.Fprintf(content, "\tw.Header().Set(\"ETag\", etag(%s.Version))\n", entityLower)
+	}
+	if format == ResponseFormatRaw {
+		content.WriteString("\tw.Header().Set(\"Content-Type\", \"application/json\")\n")
+		fmt.Fprintf(content, "\tjson.NewEncoder(w).Encode(%s)\n", response)
+	} else {
+		fmt.Fprintf(content, "\trespond(w, http.StatusOK, %s)\n", response)
+	}
 	content.WriteString("}\n\n")
 }
 
-func generateUpdateHandlerMethod(content *strings.Builder, entity, handlerName string, validation, swagger bool) {
+func generateUpdateHandlerMethod(content *strings.Builder, entity, handlerName string, validation, swagger, versioned, acting bool, format string) {
 	handlerVar := strings.ToLower(string(handlerName[0]))
 	entityLower := strings.ToLower(entity)
 
+	deprecations := inputDeprecations(fmt.Sprintf("Update%sInput", entity))
 	if swagger {
-		writeSwaggerAnnotations(content, entity, fmt.Sprintf("Update %s", entityLower), "put", "/"+entityLower+"s/{id}", "204", "", fmt.Sprintf("usecase.Update%sInput", entity))
+		writeSwaggerAnnotations(content, entity, fmt.Sprintf("Update %s", entityLower), "put", "/"+toPlural(entityLower)+"/{id}", "204", "", fmt.Sprintf("usecase.Update%sInput", entity), format)
+		if len(deprecations) > 0 {
+			writeDeprecatedFieldsDoc(content, "204", deprecations)
+		}
 	}
 
 	fmt.Fprintf(content, "func (%s *%s) Update%s(w http.ResponseWriter, r *http.Request) {\n",
 		handlerVar, handlerName, entity)
-	content.WriteString("\tvars := mux.Vars(r)\n")
-	content.WriteString("\tid, err := strconv.Atoi(vars[\"id\"])\n")
-	content.WriteString("\tif err != nil {\n")
-	fmt.Fprintf(content, "\t\thttp.Error(w, \"Invalid %s ID\", http.StatusBadRequest)\n", strings.ToLower(entity))
-	content.WriteString("\t\treturn\n")
-	content.WriteString("\t}\n\n")
+	writeHandlerID(content, entity, format)
 
 	fmt.Fprintf(content, "\tvar input usecase.Update%sInput\n", entity)
-	content.WriteString("\tif err := json.NewDecoder(r.Body).Decode(&input); err != nil {\n")
-	content.WriteString("\t\thttp.Error(w, \"Invalid request body\", http.StatusBadRequest)\n")
-	content.WriteString("\t\treturn\n")
-	content.WriteString("\t}\n\n")
+	writeRequestDecoding(content, entity, OpUpdate, format)
+	if view := loadProjection(entity, HandlerHTTP); view != nil && len(view.updateInput) > 0 {
+		fmt.Fprintf(content, "\tomitHiddenUpdate%sInput(&input)\n\n", entity)
+	}
+	if len(deprecations) > 0 {
+		writeDeprecatedFieldsUpgrade(content)
+	}
 
 	if validation {
-		content.WriteString("\tif err := validator.New().Struct(input); err != nil {\n")
-		content.WriteString("\t\thttp.Error(w, err.Error(), http.StatusUnprocessableEntity)\n")
+		writeRequestValidation(content, entity)
+	}
+
+	if versioned {
+		// If-Match takes precedence over a version in the body.
+		content.WriteString("\tversion, err := ifMatchVersion(r)\n")
+		content.WriteString("\tif err != nil {\n")
+		writeUploadCleanup(content, entity)
+		writeHandlerError(content, format, "http.StatusBadRequest", "err.Error()")
 		content.WriteString("\t\treturn\n")
+		content.WriteString("\t}\n")
+		content.WriteString("\tif version != nil {\n")
+		content.WriteString("\t\tinput.Version = version\n")
 		content.WriteString("\t}\n\n")
 	}
 
-	fmt.Fprintf(content, "\tif err := %s.usecase.Update%s(id, input); err != nil {\n", handlerVar, entity)
-	content.WriteString("\t\thttp.Error(w, err.Error(), http.StatusInternalServerError)\n")
+	fmt.Fprintf(content, "\tif err := %s.Update%s(id, input); err != nil {\n", handlerUseCase(handlerVar, acting), entity)
+	writeUploadCleanup(content, entity)
+	if versioned {
+		content.WriteString("\t\tif errors.Is(err, domain.ErrConflict) {\n")
+		writeHandlerError(content, format, "http.StatusConflict", "err.Error()")
+		content.WriteString("\t\t\treturn\n")
+		content.WriteString("\t\t}\n")
+	}
+	writeUseCaseError(content, format, "http.StatusInternalServerError")
 	content.WriteString("\t\treturn\n")
 	content.WriteString("\t}\n\n")
 
@@ -450,25 +744,20 @@ func generateUpdateHandlerMethod(content *strings.Builder, entity, handlerName s
 	content.WriteString("}\n\n")
 }
 
-func generateDeleteHandlerMethod(content *strings.Builder, entity, handlerName string, swagger bool) {
+func generateDeleteHandlerMethod(content *strings.Builder, entity, handlerName string, swagger, acting bool, format string) {
 	handlerVar := strings.ToLower(string(handlerName[0]))
 	entityLower := strings.ToLower(entity)
 
 	if swagger {
-		writeSwaggerAnnotations(content, entity, fmt.Sprintf("Delete %s", entityLower), "delete", "/"+entityLower+"s/{id}", "204", "", "")
+		writeSwaggerAnnotations(content, entity, fmt.Sprintf("Delete %s", entityLower), "delete", "/"+toPlural(entityLower)+"/{id}", "204", "", "", format)
 	}
 
 	fmt.Fprintf(content, "func (%s *%s) Delete%s(w http.ResponseWriter, r *http.Request) {\n",
 		handlerVar, handlerName, entity)
-	content.WriteString("\tvars := mux.Vars(r)\n")
-	content.WriteString("\tid, err := strconv.Atoi(vars[\"id\"])\n")
-	content.WriteString("\tif err != nil {\n")
-	fmt.Fprintf(content, "\t\thttp.Error(w, \"Invalid %s ID\", http.StatusBadRequest)\n", strings.ToLower(entity))
-	content.WriteString("\t\treturn\n")
-	content.WriteString("\t}\n\n")
+	writeHandlerID(content, entity, format)
 
-	fmt.Fprintf(content, "\tif err := %s.usecase.Delete%s(id); err != nil {\n", handlerVar, entity)
-	content.WriteString("\t\thttp.Error(w, err.Error(), http.StatusInternalServerError)\n")
+	fmt.Fprintf(content, "\tif err := %s.Delete%s(id); err != nil {\n", handlerUseCase(handlerVar, acting), entity)
+	writeUseCaseError(content, format, "http.StatusInternalServerError")
 	content.WriteString("\t\treturn\n")
 	content.WriteString("\t}\n\n")
 
@@ -476,30 +765,110 @@ func generateDeleteHandlerMethod(content *strings.Builder, entity, handlerName s
 	content.WriteString("}\n\n")
 }
 
-func generateListHandlerMethod(content *strings.Builder, entity, handlerName string, swagger bool) {
+func generateListHandlerMethod(content *strings.Builder, entity, handlerName string, swagger, acting bool, format string) {
 	handlerVar := strings.ToLower(string(handlerName[0]))
 	entityLower := strings.ToLower(entity)
 
+	// Fields hidden from HTTP are left out of the items; otherwise they are
+	// presented when the entity has a presenter.
+	items, output := fmt.Sprintf("output.%s", toPlural(entity)), "output"
+	view := loadProjection(entity, HandlerHTTP) != nil
+	presented := !view && loadPresenter(entity).has("New"+entity+"List")
+	if view {
+		items, output = fmt.Sprintf("new%sViews(output.%s)", entity, toPlural(entity)), fmt.Sprintf("newList%sView(output)", entity)
+	} else if presented {
+		items, output = fmt.Sprintf("presenter.New%s(output.%s)", toPlural(entity), toPlural(entity)), fmt.Sprintf("presenter.New%sList(output)", entity)
+	}
+
 	if swagger {
-		// The use case returns usecase.List<Entity>Output (singular entity name).
-		writeSwaggerAnnotations(content, entity, fmt.Sprintf("List %ss", entityLower), "get", "/"+entityLower+"s", "200", fmt.Sprintf("usecase.List%sOutput", entity), "")
+		// The use case returns usecase.List<Entity>Output (singular entity name);
+		// envelopes carry its items as data and its total in meta.
+		successType := fmt.Sprintf("usecase.List%sOutput", entity)
+		if format == ResponseFormatEnvelope {
+			successType = fmt.Sprintf("[]domain.%s", entity)
+		}
+		if view {
+			successType = fmt.Sprintf("List%sView", entity)
+			if format == ResponseFormatEnvelope {
+				successType = fmt.Sprintf("[]%sView", entity)
+			}
+		} else if presented {
+			successType = fmt.Sprintf("presenter.%sList", entity)
+			if format == ResponseFormatEnvelope {
+				successType = fmt.Sprintf("[]presenter.%s", entity)
+			}
+		}
+		writeSwaggerAnnotations(content, entity, fmt.Sprintf("List %s", toPlural(entityLower)), "get", "/"+toPlural(entityLower), "200", successType, "", format)
 	}
 
-	fmt.Fprintf(content, "func (%s *%s) List%ss(w http.ResponseWriter, r *http.Request) {\n",
-		handlerVar, handlerName, entity)
-	fmt.Fprintf(content, "\toutput, err := %s.usecase.List%ss()\n", handlerVar, entity)
+	fmt.Fprintf(content, "func (%s *%s) List%s(w http.ResponseWriter, r *http.Request) {\n",
+		handlerVar, handlerName, toPlural(entity))
+	fmt.Fprintf(content, "\toutput, err := %s.List%s()\n", handlerUseCase(handlerVar, acting), toPlural(entity))
 	content.WriteString("\tif err != nil {\n")
-	content.WriteString("\t\thttp.Error(w, err.Error(), http.StatusInternalServerError)\n")
+	writeUseCaseError(content, format, "http.StatusInternalServerError")
 	content.WriteString("\t\treturn\n")
 	content.WriteString("\t}\n\n")
 
-	content.WriteString("\tw.Header().Set(\"Content-Type\", \"application/json\")\n")
-	content.WriteString("\tjson.NewEncoder(w).Encode(output)\n")
+	switch format {
+	case ResponseFormatEnvelope:
+		fmt.Fprintf(content, "\trespondList(w, %s, output.Total)\n", items)
+	case ResponseFormatProblem:
+		fmt.Fprintf(content, "\trespond(w, http.StatusOK, %s)\n", output)
+	default:
+		content.WriteString("\tw.Header().Set(\"Content-Type\", \"application/json\")\n")
+		fmt.Fprintf(content, "\tjson.NewEncoder(w).Encode(%s)\n", output)
+	}
+	content.WriteString("}\n\n")
+}
+
+// generateCountHandlerMethod emits Count<Entity>s, which answers HEAD
+// /<entities>/count with the total in X-Total-Count. HEAD responses carry no
+// body, so failures only set the status whatever the response format.
+func generateCountHandlerMethod(content *strings.Builder, entity, handlerName string, swagger, acting bool, format string) {
+	handlerVar := strings.ToLower(string(handlerName[0]))
+	entityLower := strings.ToLower(entity)
+
+	if swagger {
+		writeSwaggerAnnotations(content, entity, fmt.Sprintf("Count %s", toPlural(entityLower)), "head", "/"+toPlural(entityLower)+"/count", "200", "", "", format)
+	}
+
+	fmt.Fprintf(content, "func (%s *%s) Count%s(w http.ResponseWriter, r *http.Request) {\n",
+		handlerVar, handlerName, toPlural(entity))
+	fmt.Fprintf(content, "\ttotal, err := %s.Count%s()\n", handlerUseCase(handlerVar, acting), toPlural(entity))
+	content.WriteString("\tif err != nil {\n")
+	content.WriteString("\t\tw.WriteHeader(http.StatusInternalServerError)\n")
+	content.WriteString("\t\treturn\n")
+	content.WriteString("\t}\n\n")
+	content.WriteString("\tw.Header().Set(\"X-Total-Count\", strconv.FormatInt(total, 10))\n")
+	content.WriteString("\tw.WriteHeader(http.StatusOK)\n")
 	content.WriteString("}\n\n")
 }
 
-func generateHTTPRoutesFile(dir, entity string, middleware bool, sm ...*SafetyManager) {
+// httpRoutes describes where Setup<Entity>Routes mounts an entity's handler.
+type httpRoutes struct {
+	Path         string   // collection path under the API router, e.g. "/products"
+	Operations   []string // operations to route, in cliOperations order
+	UpdateMethod string   // HTTP method of the update route
+	Version      string   // API version of the handler, e.g. "v2"; empty for v1
+}
+
+// defaultHTTPRoutes mounts the operations the use case declares under the
+// pluralized entity name, and the history of audited entities.
+func defaultHTTPRoutes(entity string) httpRoutes {
+	operations := useCaseOperations(entity)
+	if isAuditedEntity(entity) {
+		operations = append(operations, OpHistory)
+	}
+	return httpRoutes{
+		Path:         "/" + toPlural(strings.ToLower(entity)),
+		Operations:   operations,
+		UpdateMethod: "PUT",
+	}
+}
+
+func generateHTTPRoutesFile(dir, entity string, routes httpRoutes, middleware bool, sm ...*SafetyManager) {
 	filename := filepath.Join(dir, "routes.go")
+	defer lockSharedFile(filename)()
 
 	// Detect whether the standalone middleware package exists.
 	middlewarePkgExists := middlewarePackageExists()
@@ -509,12 +878,18 @@ func generateHTTPRoutesFile(dir, entity string, middleware bool, sm ...*SafetyMa
 	// file — the package declaration, imports and middleware helpers are already
 	// present. Idempotent: do nothing when this entity's function already exists.
 	if existing, err := os.ReadFile(filename); err == nil {
-		if strings.Contains(string(existing), fmt.Sprintf("func Setup%sRoutes(", entity)) {
+		if strings.Contains(string(existing), fmt.Sprintf("func Setup%s%sRoutes(", entity, apiVersionSuffix(routes.Version))) {
 			return
 		}
 		var fn strings.Builder
-		writeRouteSetupFunc(&fn, entity, middleware, middlewarePkgExists)
-		merged := strings.TrimRight(string(existing), "\n") + "\n\n" + fn.String()
+		writeRouteSetupFunc(&fn, entity, routes, middleware, middlewarePkgExists)
+		merged := strings.TrimRight(routesFileImports(string(existing), middleware, middlewarePkgExists), "\n") + "\n\n" + fn.String()
+		if middleware && !middlewarePkgExists && !strings.Contains(merged, "func corsMiddleware(") {
+			var helpers strings.Builder
+			helpers.WriteString("\n// Middleware functions\n")
+			generateMiddlewareFunctions(&helpers)
+			merged += helpers.String()
+		}
 		if err := writeGoFileMerged(filename, merged, sm...); err != nil {
 			ui.Error(fmt.Sprintf("Error writing routes file: %v", err))
 		}
@@ -526,20 +901,12 @@ func generateHTTPRoutesFile(dir, entity string, middleware bool, sm ...*SafetyMa
 	importPath := getImportPath(moduleName)
 
 	var content strings.Builder
-	content.WriteString("package http\n\n")
-	content.WriteString("import (\n")
-	if middleware && !middlewarePkgExists {
-		content.WriteString("\t\"log\"\n")
-		content.WriteString("\t\"net/http\"\n\n")
-	}
-	content.WriteString("\t\"github.com/gorilla/mux\"\n")
-	content.WriteString(fmt.Sprintf("\t\"%s/internal/usecase\"\n", importPath))
-	if middleware && middlewarePkgExists {
-		content.WriteString(fmt.Sprintf("\t\"%s/internal/middleware\"\n", importPath))
-	}
-	content.WriteString(")\n\n")
-
-	writeRouteSetupFunc(&content, entity, middleware, middlewarePkgExists)
+	writeGoTemplate(&content, handlerTemplates, "routes.go", httpRoutesFile{
+		Module:            importPath,
+		LocalMiddleware:   middleware && !middlewarePkgExists,
+		PackageMiddleware: middleware && middlewarePkgExists,
+		Setup:             newHTTPRouteSetup(entity, routes, middleware, middlewarePkgExists),
+	})
 
 	if middleware && !middlewarePkgExists {
 		content.WriteString("\n// Middleware functions\n")
@@ -552,60 +919,221 @@ func generateHTTPRoutesFile(dir, entity string, middleware bool, sm ...*SafetyMa
 	}
 }
 
+// routesFileImports adds to the imports of an existing routes.go the ones the
+// middleware of a new Setup<Entity>Routes function needs, when the features
+// before it were generated without middleware or before internal/middleware
+// existed.
+func routesFileImports(existing string, middleware, middlewarePkgExists bool) string {
+	if !middleware {
+		return existing
+	}
+	var imports []string
+	if middlewarePkgExists {
+		imports = []string{getImportPath(getModuleName()) + "/internal/middleware"}
+	} else if !strings.Contains(existing, "func corsMiddleware(") {
+		imports = []string{"log", "net/http"}
+	}
+	for _, imp := range imports {
+		if !strings.Contains(existing, fmt.Sprintf("%q", imp)) {
+			existing = strings.Replace(existing, "import (\n", fmt.Sprintf("import (\n\t%q\n", imp), 1)
+		}
+	}
+	return existing
+}
+
 // writeRouteSetupFunc writes the Setup<Entity>Routes function body into content.
 // Shared by the initial routes.go generation and the append path used when a
 // later feature adds its routes to an existing file.
-func writeRouteSetupFunc(content *strings.Builder, entity string, middleware, middlewarePkgExists bool) {
-	entityLower := strings.ToLower(entity)
-	pluralEntity := entityLower + "s"
-
-	content.WriteString(fmt.Sprintf("func Setup%sRoutes(router *mux.Router, uc usecase.%sUseCase) {\n",
-		entity, entity))
-	content.WriteString(fmt.Sprintf("\thandler := New%sHandler(uc)\n\n", entity))
-
-	if middleware {
-		content.WriteString("\t// Apply middleware\n")
-		content.WriteString(fmt.Sprintf("\t%sRouter := router.PathPrefix(\"/%s\").Subrouter()\n", entityLower, pluralEntity))
-		if middlewarePkgExists {
-			content.WriteString(fmt.Sprintf("\t%sRouter.Use(mux.MiddlewareFunc(middleware.CORS(middleware.DefaultCORSConfig())))\n", entityLower))
-			content.WriteString(fmt.Sprintf("\t%sRouter.Use(mux.MiddlewareFunc(middleware.Logging()))\n\n", entityLower))
-		} else {
-			content.WriteString(fmt.Sprintf("\t%sRouter.Use(corsMiddleware)\n", entityLower))
-			content.WriteString(fmt.Sprintf("\t%sRouter.Use(loggingMiddleware)\n\n", entityLower))
-		}
-
-		content.WriteString(fmt.Sprintf("\t%sRouter.HandleFunc(\"\", handler.Create%s).Methods(\"POST\")\n",
-			entityLower, entity))
-		content.WriteString(fmt.Sprintf("\t%sRouter.HandleFunc(\"/{id}\", handler.Get%s).Methods(\"GET\")\n",
-			entityLower, entity))
-		content.WriteString(fmt.Sprintf("\t%sRouter.HandleFunc(\"/{id}\", handler.Update%s).Methods(\"PUT\")\n",
-			entityLower, entity))
-		content.WriteString(fmt.Sprintf("\t%sRouter.HandleFunc(\"/{id}\", handler.Delete%s).Methods(\"DELETE\")\n",
-			entityLower, entity))
-		content.WriteString(fmt.Sprintf("\t%sRouter.HandleFunc(\"\", handler.List%ss).Methods(\"GET\")\n",
-			entityLower, entity))
-	} else {
-		content.WriteString(fmt.Sprintf("\trouter.HandleFunc(\"/%s\", handler.Create%s).Methods(\"POST\")\n",
-			pluralEntity, entity))
-		content.WriteString(fmt.Sprintf("\trouter.HandleFunc(\"/%s/{id}\", handler.Get%s).Methods(\"GET\")\n",
-			pluralEntity, entity))
-		content.WriteString(fmt.Sprintf("\trouter.HandleFunc(\"/%s/{id}\", handler.Update%s).Methods(\"PUT\")\n",
-			pluralEntity, entity))
-		content.WriteString(fmt.Sprintf("\trouter.HandleFunc(\"/%s/{id}\", handler.Delete%s).Methods(\"DELETE\")\n",
-			pluralEntity, entity))
-		content.WriteString(fmt.Sprintf("\trouter.HandleFunc(\"/%s\", handler.List%ss).Methods(\"GET\")\n",
-			pluralEntity, entity))
+func writeRouteSetupFunc(content *strings.Builder, entity string, routes httpRoutes, middleware, middlewarePkgExists bool) {
+	writeGoTemplate(content, handlerTemplates, "routes", newHTTPRouteSetup(entity, routes, middleware, middlewarePkgExists))
+}
+
+// httpRoute is one route of Setup<Entity>Routes.
+type httpRoute struct {
+	Path    string
+	Handler string
+	Method  string
+}
+
+// httpRouteSetup is the data of the Setup<Entity>Routes template.
+type httpRouteSetup struct {
+	Entity            string
+	Suffix            string // the API version of the handler, e.g. V2
+	Path              string
+	Router            string // the router the routes are registered on
+	Subrouter         bool
+	Middleware        bool
+	PackageMiddleware bool // the middleware comes from internal/middleware
+	Tenant            bool
+	Routes            []httpRoute
+	FileRoutes        string
+}
+
+// newHTTPRouteSetup returns the routes of the operations of entity.
+func newHTTPRouteSetup(entity string, routes httpRoutes, middleware, middlewarePkgExists bool) httpRouteSetup {
+	setup := httpRouteSetup{
+		Entity:            entity,
+		Suffix:            apiVersionSuffix(routes.Version),
+		Path:              routes.Path,
+		Router:            "router",
+		Middleware:        middleware,
+		PackageMiddleware: middlewarePkgExists,
+		// Routes of multi-tenant entities refuse requests without a tenant.
+		Tenant: isMultiTenantEntity(entity),
 	}
 
-	content.WriteString("}\n")
+	// With middleware the routes hang off a subrouter, so paths are relative.
+	prefix := routes.Path
+	if middleware || setup.Tenant {
+		setup.Subrouter = true
+		setup.Router, prefix = strings.ToLower(entity)+"Router", ""
+	}
+
+	// mux matches routes in registration order, so /search precedes /{id}.
+	operations := routes.Operations
+	if contains(operations, OpSearch) {
+		operations = []string{OpSearch}
+		for _, op := range routes.Operations {
+			if op != OpSearch {
+				operations = append(operations, op)
+			}
+		}
+	}
+	for _, op := range operations {
+		var route httpRoute
+		switch op {
+		case OpCreate:
+			route = httpRoute{prefix, "Create" + entity, "POST"}
+		case OpRead:
+			route = httpRoute{prefix + "/{id}", "Get" + entity, "GET"}
+		case OpUpdate:
+			route = httpRoute{prefix + "/{id}", "Update" + entity, routes.UpdateMethod}
+		case OpDelete:
+			route = httpRoute{prefix + "/{id}", "Delete" + entity, "DELETE"}
+		case OpList:
+			route = httpRoute{prefix, "List" + toPlural(entity), "GET"}
+		case OpCount:
+			route = httpRoute{prefix + "/count", "Count" + toPlural(entity), "HEAD"}
+		case OpSearch:
+			route = httpRoute{prefix + "/search", "Search" + toPlural(entity), "GET"}
+		case OpHistory:
+			route = httpRoute{prefix + "/{id}/history", "Get" + entity + "History", "GET"}
+		default:
+			continue
+		}
+		setup.Routes = append(setup.Routes, route)
+	}
+	if contains(operations, OpRead) {
+		var files strings.Builder
+		writeFileRoutes(&files, entity, setup.Router, prefix)
+		setup.FileRoutes = files.String()
+	}
+	return setup
+}
+
+// httpHandlerHeader is the data of the handler template.
+type httpHandlerHeader struct {
+	Package string
+	Module  string
+	Entity  string
+	Name    string // ProductHandler, or ProductV2Handler for a later API version
+	JSON    bool   // the handler decodes or encodes JSON itself
+	Errors  bool   // the handler matches domain.ErrConflict
+	Strconv bool   // the handler parses a numeric ID or formats a count
+	Mux     bool   // the handler reads the {id} route variable
+	Domain  bool
+	Enums   string // the registration of the enum validations
+}
+
+// httpRoutesFile is the data of the routes.go template.
+type httpRoutesFile struct {
+	Module            string
+	LocalMiddleware   bool // the middleware functions are declared in routes.go
+	PackageMiddleware bool // the middleware comes from internal/middleware
+	Setup             httpRouteSetup
 }
 
+// handlerTemplates render an HTTP handler up to its methods, which the
+// generate*HandlerMethod functions append, and routes.go.
+var handlerTemplates = parseGoTemplates("handler", `
+{{- define "handler" -}}
+package {{.Package}}
+
+import (
+{{- if .JSON}}
+	"encoding/json"
+{{- end}}
+{{- if .Errors}}
+	"errors"
+{{- end}}
+	"net/http"
+{{- if .Strconv}}
+	"strconv"
+{{- end}}
+
+{{if .Mux}}	"github.com/gorilla/mux"
+{{end}}
+{{- if .Domain}}	"{{.Module}}/internal/domain"
+{{end}}	"{{.Module}}/internal/usecase"
+)
+
+{{.Enums}}type {{.Name}} struct {
+	usecase usecase.{{.Entity}}UseCase
+}
+
+func New{{.Name}}(uc usecase.{{.Entity}}UseCase) *{{.Name}} {
+	return &{{.Name}}{usecase: uc}
+}
+
+{{end}}
+
+{{- define "routes.go" -}}
+package http
+
+import (
+{{- if .LocalMiddleware}}
+	"log"
+	"net/http"
+{{end}}
+	"github.com/gorilla/mux"
+	"{{.Module}}/internal/usecase"
+{{- if .PackageMiddleware}}
+	"{{.Module}}/internal/middleware"
+{{- end}}
+)
+
+{{template "routes" .Setup}}
+{{- end}}
+
+{{- define "routes" -}}
+func Setup{{.Entity}}{{.Suffix}}Routes(router *mux.Router, uc usecase.{{.Entity}}UseCase) {
+	handler := New{{.Entity}}{{.Suffix}}Handler(uc)
+
+{{if .Subrouter -}}
+{{if .Middleware}}	// Apply middleware
+{{end -}}
+	{{.Router}} := router.PathPrefix("{{.Path}}").Subrouter()
+{{if and .Middleware .PackageMiddleware -}}
+	{{.Router}}.Use(mux.MiddlewareFunc(middleware.CORS(middleware.DefaultCORSConfig())))
+	{{.Router}}.Use(mux.MiddlewareFunc(middleware.Logging()))
+{{else if .Middleware -}}
+	{{.Router}}.Use(corsMiddleware)
+	{{.Router}}.Use(loggingMiddleware)
+{{end -}}
+{{if .Tenant}}	{{.Router}}.Use(tenantMiddleware)
+{{end}}
+{{end -}}
+{{range .Routes}}	{{$.Router}}.HandleFunc("{{.Path}}", handler.{{.Handler}}).Methods("{{.Method}}")
+{{end -}}
+{{.FileRoutes}}}
+{{end}}`)
+
 func generateMiddlewareFunctions(content *strings.Builder) {
 	content.WriteString(`
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 		
 		if r.Method == "OPTIONS" {
@@ -651,20 +1179,25 @@ func generateHTTPDTOFile(dir, entity string, sm ...*SafetyManager) {
 	}
 }
 
+// generateSwaggerFile writes the entity's paths and schemas to swagger.yaml,
+// the OpenAPI document served under /docs. An existing document keeps the
+// paths and schemas of the other entities.
 func generateSwaggerFile(dir, entity string, sm ...*SafetyManager) {
 	filename := filepath.Join(dir, "swagger.yaml")
 	entityLower := strings.ToLower(entity)
 
+	branding := loadBranding(".")
+
 	content := fmt.Sprintf(`openapi: 3.0.0
 info:
   title: %s API
   version: 1.0.0
   description: API for managing %s entities
-
-paths:
-  /%ss:
+%s
+%spaths:
+  /%s:
     get:
-      summary: List all %ss
+      summary: List all %s
       responses:
         '200':
           description: Successful response
@@ -690,7 +1223,7 @@ paths:
               schema:
                 $ref: '#/components/schemas/%s'
 
-  /%ss/{id}:
+  /%s/{id}:
     get:
       summary: Get %s by ID
       parameters:
@@ -729,8 +1262,19 @@ components:
           type: string
         email:
           type: string
-`, entity, entityLower, entityLower, entityLower, entity, entityLower, entity, entity, entity, entityLower, entityLower, entity, entity, entity)
+`, entity, entityLower, openAPIContact(branding), openAPIExternalDocs(branding), toPlural(entityLower), toPlural(entityLower), entity, entityLower, entity, entity, entity, toPlural(entityLower), entityLower, entity, entity, entity)
 
+	if existing, err := os.ReadFile(filename); err == nil {
+		merged, err := mergeOpenAPIDocument(existing, content, openAPIProjectName())
+		if err != nil {
+			ui.Error(fmt.Sprintf("Error merging %s into %s: %v", entity, filename, err))
+			return
+		}
+		if err := writeMergedFileSafe(filename, merged, sm...); err != nil {
+			ui.Error(fmt.Sprintf("Error writing swagger file: %v", err))
+		}
+		return
+	}
 	if err := writeFile(filename, content, sm...); err != nil {
 		ui.Error(fmt.Sprintf("Error writing swagger file: %v", err))
 		return
@@ -738,10 +1282,15 @@ components:
 }
 
 func init() {
-	handlerCmd.Flags().StringP("type", "t", "http", "Handler type (http, grpc, cli, worker, soap)")
+	handlerCmd.Flags().StringP("type", "t", "http", "Handler type (http, grpc, cli, worker, websocket, cron, soap)")
 	handlerCmd.Flags().BoolP("middleware", "m", false, "Include middleware setup")
 	handlerCmd.Flags().Bool("validation", false, "Input validation in handler")
 	handlerCmd.Flags().BoolP("swagger", "s", false, "Generate Swagger documentation (HTTP only)")
+	handlerCmd.Flags().Bool("gateway", false, "Serve the REST routes through a grpc-gateway derived from the .proto (gRPC only)")
+	handlerCmd.Flags().String("jobs", "", "Use case operations scheduled by --type cron (list, count, delete; default from features.scheduler.jobs, else all the use case declares)")
+	handlerCmd.Flags().String("api-version", "", "API version of the HTTP handler, e.g. v2; versions after v1 get their own handler, mounted under /api/<version> next to earlier ones (default from api.versioning)")
+	handlerCmd.Flags().Bool("presenter", false, "Generate internal/presenter first and present the entity through it (HTTP, gRPC and CLI; default from generation.presenter)")
+	handlerCmd.Flags().Bool("contracts", false, "Generate Pact provider verification tests and an example consumer pact (HTTP only, default from testing.contracts)")
 	handlerCmd.Flags().Bool("dry-run", false, "Preview changes without creating files")
 	handlerCmd.Flags().Bool("force", false, "Overwrite existing files without asking")
 	handlerCmd.Flags().Bool("backup", false, "Backup existing files before overwriting")
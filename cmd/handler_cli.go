@@ -0,0 +1,773 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// cliCommandsMarker anchors the command group registrations in cmd/cli/main.go.
+const cliCommandsMarker = "// goca:commands -- entity command groups are registered above this line"
+
+// cliOperations is the canonical order of the generated CLI subcommands.
+var cliOperations = []string{"create", "read", "update", "delete", "list"}
+
+// generateCLIHandler writes the cobra command group for an entity, the shared
+// output helpers and the cmd/cli entrypoint that mounts every group.
+func generateCLIHandler(entity, fileNamingConvention string, sm ...*SafetyManager) {
+	// Create CLI directory
+	cliDir := filepath.Join(DirInternal, DirHandler, DirCLI)
+	_ = os.MkdirAll(cliDir, 0o755)
+
+	// Apply naming convention to filename
+	var filename string
+	if fileNamingConvention == "snake_case" {
+		filename = filepath.Join(cliDir, toSnakeCase(entity)+"_commands.go")
+	} else if fileNamingConvention == "kebab-case" {
+		filename = filepath.Join(cliDir, toKebabCase(entity)+"-commands.go")
+	} else {
+		filename = filepath.Join(cliDir, strings.ToLower(entity)+"_commands.go")
+	}
+
+	// Flags are derived from the real entity fields; without a readable entity
+	// the use case DTOs carry the standard Name/Description fields.
+	fields := []Field{{Name: "Name", Type: "string"}, {Name: "Description", Type: "string"}}
+	if fs := readEntityFieldsString(entity); fs != "" {
+		fields = parseFields(fs)
+	}
+
+	// Fields hidden from the CLI have no flag and are left out of the output.
+	view := generateProjectionFile(cliDir, DirCLI, entity, HandlerCLI, fileNamingConvention, false, false, sm...)
+	content := buildCLICommands(entity, fields, useCaseOperations(entity), entityRequiredFields(entity), view)
+	if err := writeGoFile(filename, content, sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing cli handler file: %v", err))
+		return
+	}
+
+	generateCLIHelpers(cliDir, sm...)
+	generateCLIEntrypoint(entity, sm...)
+}
+
+// buildCLICommands renders the <entity>_commands.go file: a Command() group
+// plus one subcommand per use case operation. Commands print the projections
+// of view when it is not nil, else the presentations of the presenter of the
+// entity when it has one.
+func buildCLICommands(entity string, fields []Field, operations []string, required map[string]bool, view *projection) string {
+	importPath := getImportPath(getModuleName())
+	entityLower := strings.ToLower(entity)
+	var pres *presenter
+	if view == nil {
+		pres = loadPresenter(entity)
+	}
+
+	has := make(map[string]bool, len(operations))
+	for _, op := range operations {
+		has[op] = true
+	}
+
+	var flagFields []Field
+	for _, f := range fields {
+		if isSystemField(f.Name) || fieldHiddenFrom(f, HandlerCLI) || f.Computed {
+			continue
+		}
+		if _, _, ok := cliFlagFor(f, entityLower); ok {
+			flagFields = append(flagFields, f)
+		}
+	}
+
+	var content strings.Builder
+	content.WriteString("package cli\n\n")
+	content.WriteString("import (\n")
+	if has["update"] || has["delete"] {
+		content.WriteString("\t\"fmt\"\n\n")
+	}
+	content.WriteString("\t\"github.com/spf13/cobra\"\n\n")
+	fmt.Fprintf(&content, "\t\"%s/internal/usecase\"\n", importPath)
+	content.WriteString(")\n\n")
+
+	fmt.Fprintf(&content, "// %sCLI exposes the %s use case as cobra commands.\n", entity, entity)
+	fmt.Fprintf(&content, "type %sCLI struct {\n", entity)
+	fmt.Fprintf(&content, "\tusecase usecase.%sUseCase\n", entity)
+	content.WriteString("}\n\n")
+
+	fmt.Fprintf(&content, "func New%sCLI(uc usecase.%sUseCase) *%sCLI {\n", entity, entity, entity)
+	fmt.Fprintf(&content, "\treturn &%sCLI{usecase: uc}\n", entity)
+	content.WriteString("}\n\n")
+
+	// Command group
+	fmt.Fprintf(&content, "// Command returns the %q command group.\n", entityLower)
+	fmt.Fprintf(&content, "func (c *%sCLI) Command() *cobra.Command {\n", entity)
+	content.WriteString("\tcmd := &cobra.Command{\n")
+	fmt.Fprintf(&content, "\t\tUse:   %q,\n", entityLower)
+	fmt.Fprintf(&content, "\t\tShort: %q,\n", "Manage "+strings.ToLower(toPlural(entity)))
+	content.WriteString("\t}\n")
+	content.WriteString("\tcmd.PersistentFlags().StringP(\"output\", \"o\", \"table\", \"Output format (table, json)\")\n")
+	content.WriteString("\tcmd.AddCommand(\n")
+	for _, op := range cliOperations {
+		if has[op] {
+			fmt.Fprintf(&content, "\t\tc.%s(),\n", cliCommandMethod(entity, op))
+		}
+	}
+	content.WriteString("\t)\n")
+	content.WriteString("\treturn cmd\n")
+	content.WriteString("}\n\n")
+
+	for _, op := range cliOperations {
+		if !has[op] {
+			continue
+		}
+		switch op {
+		case "create":
+			writeCLICreateCommand(&content, entity, flagFields, required, view, pres)
+		case "read":
+			writeCLIGetCommand(&content, entity, view, pres)
+		case "update":
+			writeCLIUpdateCommand(&content, entity, flagFields)
+		case "delete":
+			writeCLIDeleteCommand(&content, entity)
+		case "list":
+			writeCLIListCommand(&content, entity, view, pres)
+		}
+	}
+
+	return usePresenter(content.String())
+}
+
+// cliCommandMethod returns the name of the method building an operation's command.
+func cliCommandMethod(entity, op string) string {
+	switch op {
+	case "create":
+		return "Create" + entity + "Command"
+	case "read":
+		return "Get" + entity + "Command"
+	case "update":
+		return "Update" + entity + "Command"
+	case "delete":
+		return "Delete" + entity + "Command"
+	default:
+		return "List" + toPlural(entity) + "Command"
+	}
+}
+
+func writeCLICreateCommand(content *strings.Builder, entity string, fields []Field, required map[string]bool, view *projection, pres *presenter) {
+	entityLower := strings.ToLower(entity)
+
+	fmt.Fprintf(content, "func (c *%sCLI) Create%sCommand() *cobra.Command {\n", entity, entity)
+	content.WriteString("\tcmd := &cobra.Command{\n")
+	content.WriteString("\t\tUse:   \"create\",\n")
+	fmt.Fprintf(content, "\t\tShort: \"Create a new %s\",\n", entityLower)
+	content.WriteString("\t\tArgs:  cobra.NoArgs,\n")
+	content.WriteString("\t\tRunE: func(cmd *cobra.Command, args []string) error {\n")
+	fmt.Fprintf(content, "\t\t\tvar input usecase.Create%sInput\n", entity)
+	for _, f := range fields {
+		getExpr, _, _ := cliFlagFor(f, entityLower)
+		fmt.Fprintf(content, "\t\t\tinput.%s, _ = %s\n", f.Name, getExpr)
+	}
+	content.WriteString("\n")
+	fmt.Fprintf(content, "\t\t\toutput, err := c.usecase.Create%s(input)\n", entity)
+	content.WriteString("\t\t\tif err != nil {\n")
+	content.WriteString("\t\t\t\treturn err\n")
+	content.WriteString("\t\t\t}\n")
+	if view.createView() {
+		fmt.Fprintf(content, "\t\t\treturn render(cmd, newCreate%sView(output))\n", entity)
+	} else if pres.has("NewCreated" + entity) {
+		// Tables have a column per field: render the entity without the message.
+		fmt.Fprintf(content, "\t\t\treturn render(cmd, presenter.NewCreated%s(output).%s)\n", entity, entity)
+	} else {
+		content.WriteString("\t\t\treturn render(cmd, output)\n")
+	}
+	content.WriteString("\t\t},\n")
+	content.WriteString("\t}\n\n")
+	for _, f := range fields {
+		_, flagDecl, _ := cliFlagFor(f, entityLower)
+		content.WriteString(flagDecl)
+		// Bool flags default to false, so they are never required.
+		if required[f.Name] && f.Type != "bool" {
+			fmt.Fprintf(content, "\t_ = cmd.MarkFlagRequired(%q)\n", cliFlagName(f))
+		}
+	}
+	content.WriteString("\treturn cmd\n")
+	content.WriteString("}\n\n")
+}
+
+func writeCLIGetCommand(content *strings.Builder, entity string, view *projection, pres *presenter) {
+	entityLower := strings.ToLower(entity)
+
+	fmt.Fprintf(content, "func (c *%sCLI) Get%sCommand() *cobra.Command {\n", entity, entity)
+	content.WriteString("\treturn &cobra.Command{\n")
+	content.WriteString("\t\tUse:   \"get <id>\",\n")
+	fmt.Fprintf(content, "\t\tShort: \"Get a %s by ID\",\n", entityLower)
+	content.WriteString("\t\tArgs:  cobra.ExactArgs(1),\n")
+	content.WriteString("\t\tRunE: func(cmd *cobra.Command, args []string) error {\n")
+	writeCLIParseID(content)
+	fmt.Fprintf(content, "\t\t\tresult, err := c.usecase.Get%s(id)\n", entity)
+	content.WriteString("\t\t\tif err != nil {\n")
+	content.WriteString("\t\t\t\treturn err\n")
+	content.WriteString("\t\t\t}\n")
+	if view != nil {
+		fmt.Fprintf(content, "\t\t\treturn render(cmd, new%sView(*result))\n", entity)
+	} else if pres.has("New" + entity) {
+		fmt.Fprintf(content, "\t\t\treturn render(cmd, presenter.New%s(*result))\n", entity)
+	} else {
+		content.WriteString("\t\t\treturn render(cmd, result)\n")
+	}
+	content.WriteString("\t\t},\n")
+	content.WriteString("\t}\n")
+	content.WriteString("}\n\n")
+}
+
+func writeCLIUpdateCommand(content *strings.Builder, entity string, fields []Field) {
+	entityLower := strings.ToLower(entity)
+
+	flagNames := make([]string, 0, len(fields))
+	for _, f := range fields {
+		flagNames = append(flagNames, strconv.Quote(cliFlagName(f)))
+	}
+
+	fmt.Fprintf(content, "func (c *%sCLI) Update%sCommand() *cobra.Command {\n", entity, entity)
+	content.WriteString("\tcmd := &cobra.Command{\n")
+	content.WriteString("\t\tUse:   \"update <id>\",\n")
+	fmt.Fprintf(content, "\t\tShort: \"Update a %s; only the given flags are changed\",\n", entityLower)
+	content.WriteString("\t\tArgs:  cobra.ExactArgs(1),\n")
+	content.WriteString("\t\tRunE: func(cmd *cobra.Command, args []string) error {\n")
+	writeCLIParseID(content)
+	fmt.Fprintf(content, "\t\t\tif !anyFlagChanged(cmd, %s) {\n", strings.Join(flagNames, ", "))
+	content.WriteString("\t\t\t\treturn errNothingToUpdate\n")
+	content.WriteString("\t\t\t}\n\n")
+	fmt.Fprintf(content, "\t\t\tvar input usecase.Update%sInput\n", entity)
+	for _, f := range fields {
+		getExpr, _, _ := cliFlagFor(f, entityLower)
+		fmt.Fprintf(content, "\t\t\tif cmd.Flags().Changed(%q) {\n", cliFlagName(f))
+		fmt.Fprintf(content, "\t\t\t\tv, _ := %s\n", getExpr)
+		fmt.Fprintf(content, "\t\t\t\tinput.%s = &v\n", f.Name)
+		content.WriteString("\t\t\t}\n")
+	}
+	content.WriteString("\n")
+	fmt.Fprintf(content, "\t\t\tif err := c.usecase.Update%s(id, input); err != nil {\n", entity)
+	content.WriteString("\t\t\t\treturn err\n")
+	content.WriteString("\t\t\t}\n")
+	fmt.Fprintf(content, "\t\t\tfmt.Fprintf(cmd.OutOrStdout(), \"%s %%d updated\\n\", id)\n", entity)
+	content.WriteString("\t\t\treturn nil\n")
+	content.WriteString("\t\t},\n")
+	content.WriteString("\t}\n\n")
+	for _, f := range fields {
+		_, flagDecl, _ := cliFlagFor(f, entityLower)
+		content.WriteString(flagDecl)
+	}
+	content.WriteString("\treturn cmd\n")
+	content.WriteString("}\n\n")
+}
+
+func writeCLIDeleteCommand(content *strings.Builder, entity string) {
+	entityLower := strings.ToLower(entity)
+
+	fmt.Fprintf(content, "func (c *%sCLI) Delete%sCommand() *cobra.Command {\n", entity, entity)
+	content.WriteString("\treturn &cobra.Command{\n")
+	content.WriteString("\t\tUse:   \"delete <id>\",\n")
+	fmt.Fprintf(content, "\t\tShort: \"Delete a %s by ID\",\n", entityLower)
+	content.WriteString("\t\tArgs:  cobra.ExactArgs(1),\n")
+	content.WriteString("\t\tRunE: func(cmd *cobra.Command, args []string) error {\n")
+	writeCLIParseID(content)
+	fmt.Fprintf(content, "\t\t\tif err := c.usecase.Delete%s(id); err != nil {\n", entity)
+	content.WriteString("\t\t\t\treturn err\n")
+	content.WriteString("\t\t\t}\n")
+	fmt.Fprintf(content, "\t\t\tfmt.Fprintf(cmd.OutOrStdout(), \"%s %%d deleted\\n\", id)\n", entity)
+	content.WriteString("\t\t\treturn nil\n")
+	content.WriteString("\t\t},\n")
+	content.WriteString("\t}\n")
+	content.WriteString("}\n\n")
+}
+
+func writeCLIListCommand(content *strings.Builder, entity string, view *projection, pres *presenter) {
+	fmt.Fprintf(content, "func (c *%sCLI) List%sCommand() *cobra.Command {\n", entity, toPlural(entity))
+	content.WriteString("\treturn &cobra.Command{\n")
+	content.WriteString("\t\tUse:   \"list\",\n")
+	fmt.Fprintf(content, "\t\tShort: \"List all %s\",\n", strings.ToLower(toPlural(entity)))
+	content.WriteString("\t\tArgs:  cobra.NoArgs,\n")
+	content.WriteString("\t\tRunE: func(cmd *cobra.Command, args []string) error {\n")
+	fmt.Fprintf(content, "\t\t\toutput, err := c.usecase.List%s()\n", toPlural(entity))
+	content.WriteString("\t\t\tif err != nil {\n")
+	content.WriteString("\t\t\t\treturn err\n")
+	content.WriteString("\t\t\t}\n")
+	if view != nil {
+		fmt.Fprintf(content, "\t\t\treturn render(cmd, new%sViews(output.%s))\n", entity, toPlural(entity))
+	} else if pres.has("New" + toPlural(entity)) {
+		fmt.Fprintf(content, "\t\t\treturn render(cmd, presenter.New%s(output.%s))\n", toPlural(entity), toPlural(entity))
+	} else {
+		fmt.Fprintf(content, "\t\t\treturn render(cmd, output.%s)\n", toPlural(entity))
+	}
+	content.WriteString("\t\t},\n")
+	content.WriteString("\t}\n")
+	content.WriteString("}\n\n")
+}
+
+func writeCLIParseID(content *strings.Builder) {
+	content.WriteString("\t\t\tid, err := parseID(args[0])\n")
+	content.WriteString("\t\t\tif err != nil {\n")
+	content.WriteString("\t\t\t\treturn err\n")
+	content.WriteString("\t\t\t}\n\n")
+}
+
+// cliFlagName returns the kebab-case flag name for an entity field.
+func cliFlagName(field Field) string {
+	return toKebabCase(field.Name)
+}
+
+// cliFlagFor returns the cobra flag accessor expression and declaration line
+// for a CLI command flag matching the given entity field. It reports ok=false
+// for types without a natural scalar flag (slices, maps, time, custom), which
+// are then omitted from the command.
+func cliFlagFor(field Field, entityLower string) (getExpr, flagDecl string, ok bool) {
+	flag := cliFlagName(field)
+	usage := fmt.Sprintf("%s of the %s", field.Name, entityLower)
+
+	var kind, zero string
+	switch field.Type {
+	case "string":
+		kind, zero = "String", `""`
+	case "int":
+		kind, zero = "Int", "0"
+	case "int64":
+		kind, zero = "Int64", "0"
+	case "uint":
+		kind, zero = "Uint", "0"
+	case "uint64":
+		kind, zero = "Uint64", "0"
+	case "float64":
+		kind, zero = "Float64", "0"
+	case "float32":
+		kind, zero = "Float32", "0"
+	case "bool":
+		kind, zero = "Bool", "false"
+	default:
+		return "", "", false
+	}
+	return fmt.Sprintf("cmd.Flags().Get%s(%q)", kind, flag),
+		fmt.Sprintf("\tcmd.Flags().%s(%q, %s, %q)\n", kind, flag, zero, usage), true
+}
+
+// useCaseOperations returns the operations the entity's use case interface
+// declares (create, read, update, delete, list and the optional count and
+// search). The default operations are assumed when the interface cannot be
+// found.
+func useCaseOperations(entity string) []string {
+	methods := map[string]string{
+		"Create" + entity:           "create",
+		"Get" + entity:              "read",
+		"Update" + entity:           "update",
+		"Delete" + entity:           "delete",
+		"List" + toPlural(entity):   "list",
+		"Count" + toPlural(entity):  OpCount,
+		"Search" + toPlural(entity): OpSearch,
+	}
+
+	files, _ := filepath.Glob(filepath.Join(DirInternal, DirUseCase, "*.go"))
+	for _, file := range files {
+		parsed, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+		if err != nil {
+			continue
+		}
+		obj := parsed.Scope.Lookup(entity + "UseCase")
+		if obj == nil {
+			continue
+		}
+		spec, ok := obj.Decl.(*ast.TypeSpec)
+		if !ok {
+			continue
+		}
+		iface, ok := spec.Type.(*ast.InterfaceType)
+		if !ok {
+			continue
+		}
+
+		found := make(map[string]bool)
+		for _, m := range iface.Methods.List {
+			for _, name := range m.Names {
+				if op, ok := methods[name.Name]; ok {
+					found[op] = true
+				}
+			}
+		}
+		var ops []string
+		for _, op := range cliOperations {
+			if found[op] {
+				ops = append(ops, op)
+			}
+		}
+		for _, op := range []string{OpCount, OpSearch} {
+			if found[op] {
+				ops = append(ops, op)
+			}
+		}
+		return ops
+	}
+	return cliOperations
+}
+
+// entityRequiredFields returns the fields of the domain entity whose validate
+// tag starts with "required".
+func entityRequiredFields(entity string) map[string]bool {
+	required := make(map[string]bool)
+	filename := filepath.Join(DirInternal, DirDomain, strings.ToLower(entity)+".go")
+	parsed, err := parser.ParseFile(token.NewFileSet(), filename, nil, 0)
+	if err != nil {
+		return required
+	}
+
+	obj := parsed.Scope.Lookup(entity)
+	if obj == nil {
+		return required
+	}
+	spec, ok := obj.Decl.(*ast.TypeSpec)
+	if !ok {
+		return required
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return required
+	}
+	for _, f := range st.Fields.List {
+		if f.Tag == nil {
+			continue
+		}
+		tag, err := strconv.Unquote(f.Tag.Value)
+		if err != nil {
+			continue
+		}
+		rules := strings.Split(reflect.StructTag(tag).Get("validate"), ",")
+		if rules[0] != "required" {
+			continue
+		}
+		for _, name := range f.Names {
+			required[name.Name] = true
+		}
+	}
+	return required
+}
+
+// generateCLIHelpers writes internal/handler/cli/cli.go with the ID parsing and
+// table/JSON output helpers shared by every command group. The file does not
+// depend on the entity, so an existing one is kept unless --force was given.
+func generateCLIHelpers(dir string, sm ...*SafetyManager) {
+	filename := filepath.Join(dir, "cli.go")
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+	if _, err := os.Stat(filename); err == nil && !force {
+		return
+	}
+	if err := writeGoFile(filename, cliHelpersTemplate, sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing cli helpers: %v", err))
+	}
+}
+
+// generateCLIEntrypoint creates cmd/cli/main.go on first use and registers the
+// entity's command group above cliCommandsMarker. It is idempotent.
+func generateCLIEntrypoint(entity string, sm ...*SafetyManager) {
+	mainPath := filepath.Join("cmd", "cli", "main.go")
+	defer lockSharedFile(mainPath)()
+	registration := fmt.Sprintf("\troot.AddCommand(cli.New%sCLI(container.%sUseCase()).Command())\n", entity, entity)
+
+	raw, err := os.ReadFile(mainPath)
+	if err != nil {
+		content, ok := buildCLIEntrypoint(registration)
+		if !ok {
+			return
+		}
+		if err := writeGoFile(mainPath, content, sm...); err != nil {
+			ui.Error(fmt.Sprintf("Error writing cli entrypoint: %v", err))
+		}
+		return
+	}
+
+	content := string(raw)
+	if strings.Contains(content, fmt.Sprintf("cli.New%sCLI(", entity)) {
+		return
+	}
+	if !strings.Contains(content, cliCommandsMarker) {
+		ui.Dim(fmt.Sprintf("   Register the command group in %s: %s", mainPath, strings.TrimSpace(registration)))
+		return
+	}
+	content = strings.Replace(content, "\t"+cliCommandsMarker, registration+"\t"+cliCommandsMarker, 1)
+	if err := writeGoFileMerged(mainPath, content, sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Could not register %s commands in %s: %v", entity, mainPath, err))
+	}
+}
+
+// buildCLIEntrypoint renders cmd/cli/main.go for the project database. It
+// reports false for databases the DI container cannot be built from.
+func buildCLIEntrypoint(registration string) (string, bool) {
+	configIntegration := NewConfigIntegration()
+	_ = configIntegration.LoadConfigForProject()
+	database := configIntegration.GetDatabaseType("")
+	importPath := getImportPath(getModuleName())
+
+	std, external, openDatabase, ok := databaseOpener(database, projectSQLiteDriver())
+	if !ok {
+		ui.Dim(fmt.Sprintf("   No CLI entrypoint for %s; mount the command group in your own main: %s", database, strings.TrimSpace(registration)))
+		return "", false
+	}
+	imports := importLines(append([]string{"fmt", "os"}, std...), append([]string{"github.com/spf13/cobra"}, external...))
+
+	name := getCurrentProjectName()
+	return fmt.Sprintf(`package main
+
+import (
+%s
+	"%s/internal/di"
+	"%s/internal/handler/cli"
+	"%s/pkg/config"
+)
+
+// main runs the %s command line interface. Each entity generated with a CLI
+// handler is a command group, e.g. "go run ./cmd/cli <entity> list -o json".
+func main() {
+	cfg := config.Load()
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: connecting to database: %%v\n", err)
+		os.Exit(1)
+	}
+	container := di.NewContainer(db)
+
+	root := &cobra.Command{
+		Use:          %q,
+		Short:        %q,
+		SilenceUsage: true,
+	}
+%s	%s
+
+	if err := root.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+%s`, imports, importPath, importPath, importPath, name, name, name+" command line interface",
+		registration, cliCommandsMarker, openDatabase), true
+}
+
+// databaseOpener returns the standard library and third-party imports of the
+// openDatabase function that connects a generated entrypoint to database, and
+// the function itself. ok is false for databases without one.
+func databaseOpener(database, sqliteDriver string) (std, external []string, openDatabase string, ok bool) {
+	switch database {
+	case DBMongoDB:
+		return []string{"context", "time"},
+			[]string{"go.mongodb.org/mongo-driver/mongo", "go.mongodb.org/mongo-driver/mongo/options"},
+			`func openDatabase(cfg *config.Config) (*mongo.Database, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Connect does not wait for the server, so --help works offline.
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.GetDatabaseURL()))
+	if err != nil {
+		return nil, err
+	}
+	return client.Database(cfg.Database.Name), nil
+}
+`, true
+	case DBDynamoDB, DBElasticsearch:
+		return nil, nil, "", false
+	default:
+		driverImport, driverPackage := gormDriver(database, sqliteDriver)
+		return nil, []string{driverImport, "gorm.io/gorm"}, fmt.Sprintf(`func openDatabase(cfg *config.Config) (*gorm.DB, error) {
+	// The connection is established on first use, so --help works offline.
+	return gorm.Open(%s.Open(cfg.GetDatabaseURL()), &gorm.Config{DisableAutomaticPing: true})
+}
+`, driverPackage), true
+	}
+}
+
+// importLines formats the standard library and third-party imports of an
+// import block, each group sorted, separated by a blank line.
+func importLines(std, external []string) string {
+	var b strings.Builder
+	for i, group := range [][]string{std, external} {
+		if i > 0 && b.Len() > 0 && len(group) > 0 {
+			b.WriteString("\n")
+		}
+		sorted := append([]string(nil), group...)
+		sort.Strings(sorted)
+		for _, path := range sorted {
+			fmt.Fprintf(&b, "\t%q\n", path)
+		}
+	}
+	return b.String()
+}
+
+// gormDriver returns the GORM driver import path and package name for a SQL
+// database; unknown databases fall back to SQLite like the server entrypoint.
+// sqliteDriver picks the SQLite driver (see SQLiteDriverModernc).
+func gormDriver(database, sqliteDriver string) (importPath, pkg string) {
+	switch database {
+	case DBPostgres, DBPostgresJSON:
+		return "gorm.io/driver/postgres", "postgres"
+	case DBMySQL, DBMariaDB, DBTiDB:
+		return "gorm.io/driver/mysql", "mysql"
+	case DBSQLServer:
+		return "gorm.io/driver/sqlserver", "sqlserver"
+	case DBOracle:
+		return oracleDriverModule, "oracle"
+	default:
+		if sqliteDriver == SQLiteDriverModernc {
+			return "github.com/glebarez/sqlite", "sqlite"
+		}
+		return "gorm.io/driver/sqlite", "sqlite"
+	}
+}
+
+const cliHelpersTemplate = `package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// errNothingToUpdate is returned by update commands run without field flags.
+var errNothingToUpdate = errors.New("nothing to update: set at least one field flag")
+
+// parseID parses an entity ID argument.
+func parseID(arg string) (int, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid ID %q: must be a positive integer", arg)
+	}
+	return id, nil
+}
+
+// anyFlagChanged reports whether any of the named flags was set.
+func anyFlagChanged(cmd *cobra.Command, names ...string) bool {
+	for _, name := range names {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// render prints v in the format selected by the --output flag.
+func render(cmd *cobra.Command, v interface{}) error {
+	format, _ := cmd.Flags().GetString("output")
+	switch strings.ToLower(format) {
+	case "json":
+		return printJSON(cmd.OutOrStdout(), v)
+	case "", "table":
+		return printTable(cmd.OutOrStdout(), v)
+	default:
+		return fmt.Errorf("unknown output format %q (use table or json)", format)
+	}
+}
+
+// printJSON prints v as indented JSON.
+func printJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printTable prints a struct, a pointer to one or a slice of either as an
+// aligned table with one column per exported field, headed by its JSON name.
+func printTable(w io.Writer, v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+
+	var rows []reflect.Value
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			rows = append(rows, reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		rows = append(rows, rv)
+	default:
+		_, err := fmt.Fprintln(w, v)
+		return err
+	}
+
+	rowType := rv.Type()
+	if rv.Kind() != reflect.Struct {
+		rowType = rowType.Elem()
+		if rowType.Kind() == reflect.Ptr {
+			rowType = rowType.Elem()
+		}
+	}
+	if rowType.Kind() != reflect.Struct {
+		for _, row := range rows {
+			if _, err := fmt.Fprintln(w, formatCell(row)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	columns := tableColumns(rowType)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = c.header
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		if !row.IsValid() {
+			continue
+		}
+		cells := make([]string, len(columns))
+		for i, c := range columns {
+			cells[i] = formatCell(row.Field(c.index))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+type tableColumn struct {
+	index  int
+	header string
+}
+
+// tableColumns lists the exported fields of t that are not hidden from JSON.
+func tableColumns(t reflect.Type) []tableColumn {
+	var columns []tableColumn
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Name
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		columns = append(columns, tableColumn{index: i, header: strings.ToUpper(name)})
+	}
+	return columns
+}
+
+// formatCell renders a field value, dereferencing pointers; nil is blank.
+func formatCell(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return ""
+	}
+	return fmt.Sprint(v.Interface())
+}
+`
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupCLIProject chdirs into a module with a Product entity and use case.
+func setupCLIProject(t *testing.T, useCase string) {
+	t.Helper()
+	dir := t.TempDir()
+	origDir, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(origDir) })
+	require.NoError(t, os.Chdir(dir))
+	require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0o644))
+
+	require.NoError(t, os.MkdirAll(filepath.Join("internal", "domain"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join("internal", "usecase"), 0o755))
+	entity := "package domain\n\ntype Product struct {\n" +
+		"\tID     uint    `json:\"id\" gorm:\"primaryKey\"`\n" +
+		"\tName   string  `json:\"name\" validate:\"required,min=2\"`\n" +
+		"\tPrice  float64 `json:\"price\" validate:\"gte=0\"`\n" +
+		"\tActive bool    `json:\"active\" validate:\"required\"`\n" +
+		"\tTags   []string `json:\"tags\"`\n" +
+		"}\n"
+	require.NoError(t, os.WriteFile(filepath.Join("internal", "domain", "product.go"), []byte(entity), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join("internal", "usecase", "product_usecase.go"), []byte(useCase), 0o644))
+}
+
+const fullProductUseCase = `package usecase
+
+type ProductUseCase interface {
+	CreateProduct(input CreateProductInput) (CreateProductOutput, error)
+	GetProduct(id int) (*Product, error)
+	UpdateProduct(id int, input UpdateProductInput) error
+	DeleteProduct(id int) error
+	ListProducts() (ListProductOutput, error)
+}
+`
+
+func TestGenerateCLIHandler_FullCRUD(t *testing.T) {
+	cleanup := ensureTestUI(t)
+	defer cleanup()
+	setupCLIProject(t, fullProductUseCase)
+
+	generateCLIHandler("Product", "lowercase", NewSafetyManager(false, true, false))
+
+	commands := readParsedGo(t, filepath.Join("internal", "handler", "cli", "product_commands.go"))
+	for _, method := range []string{"CreateProductCommand", "GetProductCommand", "UpdateProductCommand", "DeleteProductCommand", "ListProductsCommand"} {
+		assert.Contains(t, commands, "c."+method+"(),")
+	}
+	assert.Contains(t, commands, `input.Price, _ = cmd.Flags().GetFloat64("price")`)
+	assert.Contains(t, commands, `cmd.Flags().Bool("active", false, "Active of the product")`)
+	assert.Contains(t, commands, `_ = cmd.MarkFlagRequired("name")`)
+	assert.NotContains(t, commands, `MarkFlagRequired("price")`)
+	assert.NotContains(t, commands, `MarkFlagRequired("active")`, "bool flags are never required")
+	assert.NotContains(t, commands, `"tags"`, "slice fields have no flag")
+	assert.NotContains(t, commands, `"id"`)
+
+	// Update only sets the fields whose flags were given.
+	assert.Contains(t, commands, `if !anyFlagChanged(cmd, "name", "price", "active") {`)
+	assert.Contains(t, commands, "if cmd.Flags().Changed(\"price\") {\n\t\t\t\tv, _ := cmd.Flags().GetFloat64(\"price\")\n\t\t\t\tinput.Price = &v")
+	assert.Contains(t, commands, "return render(cmd, output.Products)")
+
+	helpers := readParsedGo(t, filepath.Join("internal", "handler", "cli", "cli.go"))
+	assert.Contains(t, helpers, "func printTable(w io.Writer, v interface{}) error")
+	assert.Contains(t, helpers, "func parseID(arg string) (int, error)")
+
+	main := readParsedGo(t, filepath.Join("cmd", "cli", "main.go"))
+	assert.Contains(t, main, "root.AddCommand(cli.NewProductCLI(container.ProductUseCase()).Command())")
+	assert.Contains(t, main, `"testproject/internal/handler/cli"`)
+	assert.Contains(t, main, cliCommandsMarker)
+}
+
+func TestGenerateCLIHandler_OnlyDeclaredOperations(t *testing.T) {
+	cleanup := ensureTestUI(t)
+	defer cleanup()
+	setupCLIProject(t, `package usecase
+
+type ProductUseCase interface {
+	CreateProduct(input CreateProductInput) (CreateProductOutput, error)
+	ListProducts() (ListProductOutput, error)
+}
+`)
+
+	assert.Equal(t, []string{"create", "list"}, useCaseOperations("Product"))
+	generateCLIHandler("Product", "snake_case", NewSafetyManager(false, true, false))
+
+	commands := readParsedGo(t, filepath.Join("internal", "handler", "cli", "product_commands.go"))
+	assert.Contains(t, commands, "CreateProductCommand")
+	assert.Contains(t, commands, "ListProductsCommand")
+	assert.NotContains(t, commands, "UpdateProductCommand")
+	assert.NotContains(t, commands, `"fmt"`, "fmt is only needed by update and delete")
+}
+
+func TestGenerateCLIHandler_SharedFilesAcrossEntities(t *testing.T) {
+	cleanup := ensureTestUI(t)
+	defer cleanup()
+	setupCLIProject(t, fullProductUseCase)
+
+	sm := NewSafetyManager(false, false, false)
+	generateCLIHandler("Product", "lowercase", sm)
+
+	helpersPath := filepath.Join("internal", "handler", "cli", "cli.go")
+	require.NoError(t, os.WriteFile(helpersPath, []byte("package cli\n\n// customised\n"), 0o644))
+
+	generateCLIHandler("Order", "lowercase", sm)
+	generateCLIHandler("Order", "lowercase", sm)
+
+	helpers, err := os.ReadFile(helpersPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(helpers), "customised", "existing helpers are kept")
+
+	main := readParsedGo(t, filepath.Join("cmd", "cli", "main.go"))
+	assert.Equal(t, 1, strings.Count(main, "cli.NewOrderCLI("))
+	assert.Less(t, strings.Index(main, "cli.NewProductCLI("), strings.Index(main, "cli.NewOrderCLI("))
+	assert.Less(t, strings.Index(main, "cli.NewOrderCLI("), strings.Index(main, cliCommandsMarker))
+
+	// Without a readable entity the standard DTO fields are used.
+	commands := readParsedGo(t, filepath.Join("internal", "handler", "cli", "order_commands.go"))
+	assert.Contains(t, commands, `cmd.Flags().String("description", "", "Description of the order")`)
+}
+
+func TestBuildCLIEntrypoint_Databases(t *testing.T) {
+	cleanup := ensureTestUI(t)
+	defer cleanup()
+	setupCLIProject(t, fullProductUseCase)
+
+	write := func(database string) {
+		require.NoError(t, os.WriteFile(".goca.yaml", []byte("project:\n  name: shop\n  module: testproject\ndatabase:\n  type: "+database+"\n  port: 5432\n"), 0o644))
+	}
+
+	write(DBMongoDB)
+	content, ok := buildCLIEntrypoint("")
+	require.True(t, ok)
+	assert.Contains(t, content, "func openDatabase(cfg *config.Config) (*mongo.Database, error)")
+
+	write(DBMySQL)
+	content, ok = buildCLIEntrypoint("")
+	require.True(t, ok)
+	assert.Contains(t, content, `"gorm.io/driver/mysql"`)
+	assert.Contains(t, content, "mysql.Open(cfg.GetDatabaseURL())")
+
+	write(DBDynamoDB)
+	_, ok = buildCLIEntrypoint("")
+	assert.False(t, ok)
+}
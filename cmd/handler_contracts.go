@@ -0,0 +1,657 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// pactGoVersion is the pact-go release contract tests are written against.
+const pactGoVersion = "v2.4.2"
+
+const (
+	// pactsDir holds the consumer pacts the API is verified against.
+	pactsDir = "pacts"
+	// contractTenantID is the tenant example pacts of multi-tenant entities
+	// send in the X-Tenant-ID header.
+	contractTenantID = "tenant-a"
+	// contractTimestamp is the example value of time.Time fields.
+	contractTimestamp = "2024-01-15T09:30:00Z"
+)
+
+// pactFile is a Pact specification v3 file.
+type pactFile struct {
+	Consumer     pactParticipant   `json:"consumer"`
+	Provider     pactParticipant   `json:"provider"`
+	Interactions []pactInteraction `json:"interactions"`
+	Metadata     map[string]any    `json:"metadata"`
+}
+
+type pactParticipant struct {
+	Name string `json:"name"`
+}
+
+type pactInteraction struct {
+	Description    string       `json:"description"`
+	ProviderStates []pactState  `json:"providerStates,omitempty"`
+	Request        pactRequest  `json:"request"`
+	Response       pactResponse `json:"response"`
+}
+
+type pactState struct {
+	Name string `json:"name"`
+}
+
+type pactRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    any               `json:"body,omitempty"`
+}
+
+type pactResponse struct {
+	Status        int               `json:"status"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	Body          any               `json:"body,omitempty"`
+	MatchingRules map[string]any    `json:"matchingRules,omitempty"`
+}
+
+// jsonObject is a JSON object that keeps its keys in order, so example bodies
+// list fields in the order of their structs.
+type jsonObject []jsonMember
+
+type jsonMember struct {
+	Key   string
+	Value any
+}
+
+// MarshalJSON writes the members in order.
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(m.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// generateContractTests writes an example consumer pact for the entity's HTTP
+// API in pacts/ and the provider verification that replays it against the
+// generated handler. The pact's bodies are built from the use case DTOs and
+// the entity, so it must run after both exist.
+func generateContractTests(entity string, sm ...*SafetyManager) {
+	domainDir := filepath.Join(DirInternal, DirDomain)
+	entityFields := contractStructFields(domainDir, entity)
+	if entityFields == nil {
+		if len(sm) > 0 && sm[0] != nil && sm[0].DryRun {
+			ui.Dim("   Contract tests are built from the generated entity and DTOs; a dry run skips them")
+			return
+		}
+		ui.Warning(fmt.Sprintf("Skipping contract tests: entity %s not found in %s", entity, domainDir))
+		return
+	}
+
+	provider := contractProviderName()
+	format := projectResponseFormat()
+	// Fields hidden from HTTP are neither sent nor returned by the API.
+	view := loadProjection(entity, HandlerHTTP)
+	example := contractResponseExample(view.visible(entityFields), entity)
+
+	pact := entityPact(entity, provider, format, example, view)
+	data, err := json.MarshalIndent(pact, "", "  ")
+	if err != nil {
+		ui.Error(fmt.Sprintf("Error encoding pact: %v", err))
+		return
+	}
+	pactName := fmt.Sprintf("%s-client-%s.json", strings.ToLower(entity), provider)
+	if err := writeFile(filepath.Join(pactsDir, pactName), string(data)+"\n", sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing pact: %v", err))
+		return
+	}
+
+	dir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	writeOnce(filepath.Join(dir, "contract_test.go"), contractVerifierSource(provider), "contract verifier", sm...)
+
+	exampleJSON, err := json.Marshal(example)
+	if err != nil {
+		ui.Error(fmt.Sprintf("Error encoding contract example: %v", err))
+		return
+	}
+	filename := filepath.Join(dir, strings.ToLower(entity)+"_contract_test.go")
+	if err := writeGoFile(filename, entityContractSource(entity, format, string(exampleJSON)), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing contract test: %v", err))
+		return
+	}
+
+	ensureMakefileContractTargets(sm...)
+}
+
+// contractProviderName returns the name pacts give the API: the project name,
+// else the last element of the module path.
+func contractProviderName() string {
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	name := ""
+	if ci.config != nil {
+		name = ci.GetProjectConfig().Name
+	}
+	if name == "" {
+		name = path.Base(getModuleName())
+	}
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", "-"))
+}
+
+// entityPact returns the example pact of a client calling the operations the
+// entity's use case declares. Responses are matched by type, so the provider
+// only has to answer bodies of the same shape. Fields view hides are left out
+// of the request bodies.
+func entityPact(entity, provider, format string, example jsonObject, view *projection) pactFile {
+	entityLower := strings.ToLower(entity)
+	plural := toPlural(entityLower)
+	routes := defaultHTTPRoutes(entity)
+	collection := "/api/v1" + routes.Path
+	item := collection + "/1"
+	exists := pactState{Name: fmt.Sprintf("%s 1 exists", entityLower)}
+	none := pactState{Name: fmt.Sprintf("no %s exist", plural)}
+
+	headers := func(body bool) map[string]string {
+		h := map[string]string{}
+		if body {
+			h["Content-Type"] = "application/json"
+		}
+		if isMultiTenantEntity(entity) {
+			h["X-Tenant-ID"] = contractTenantID
+		}
+		if len(h) == 0 {
+			return nil
+		}
+		return h
+	}
+	ok := func(status int, body any) pactResponse {
+		if format == ResponseFormatEnvelope {
+			body = jsonObject{{"data", body}}
+		}
+		return pactResponse{
+			Status:        status,
+			Headers:       map[string]string{"Content-Type": "application/json"},
+			Body:          body,
+			MatchingRules: map[string]any{"body": map[string]any{"$": typeMatcher()}},
+		}
+	}
+
+	usecaseDir := filepath.Join(DirInternal, DirUseCase)
+	var interactions []pactInteraction
+	for _, op := range routes.Operations {
+		switch op {
+		case OpCreate:
+			input := contractRequestExample(view.visible(contractStructFields(usecaseDir, "Create"+entity+"Input")), entity, false)
+			output := contractResponseExample(view.visible(contractStructFields(usecaseDir, "Create"+entity+"Output")), entity)
+			interactions = append(interactions, pactInteraction{
+				Description:    fmt.Sprintf("a request to create a new %s", entityLower),
+				ProviderStates: []pactState{none},
+				Request:        pactRequest{Method: "POST", Path: collection, Headers: headers(true), Body: input},
+				Response:       ok(201, output),
+			})
+		case OpRead:
+			interactions = append(interactions, pactInteraction{
+				Description:    fmt.Sprintf("a request for %s 1", entityLower),
+				ProviderStates: []pactState{exists},
+				Request:        pactRequest{Method: "GET", Path: item, Headers: headers(false)},
+				Response:       ok(200, example),
+			}, pactInteraction{
+				Description:    fmt.Sprintf("a request for a missing %s", entityLower),
+				ProviderStates: []pactState{none},
+				Request:        pactRequest{Method: "GET", Path: item, Headers: headers(false)},
+				Response:       contractNotFound(format),
+			})
+		case OpUpdate:
+			input := contractRequestExample(view.visible(contractStructFields(usecaseDir, "Update"+entity+"Input")), entity, true)
+			interactions = append(interactions, pactInteraction{
+				Description:    fmt.Sprintf("a request to update %s 1", entityLower),
+				ProviderStates: []pactState{exists},
+				Request:        pactRequest{Method: routes.UpdateMethod, Path: item, Headers: headers(true), Body: input},
+				Response:       pactResponse{Status: 204},
+			})
+		case OpDelete:
+			interactions = append(interactions, pactInteraction{
+				Description:    fmt.Sprintf("a request to delete %s 1", entityLower),
+				ProviderStates: []pactState{exists},
+				Request:        pactRequest{Method: "DELETE", Path: item, Headers: headers(false)},
+				Response:       pactResponse{Status: 204},
+			})
+		case OpList:
+			list, items := jsonObject{{plural, []any{example}}, {"total", 1}}, "$."+plural
+			if format == ResponseFormatEnvelope {
+				list, items = jsonObject{{"data", []any{example}}, {"meta", jsonObject{{"total", 1}}}}, "$.data"
+			}
+			response := pactResponse{
+				Status:        200,
+				Headers:       map[string]string{"Content-Type": "application/json"},
+				Body:          list,
+				MatchingRules: map[string]any{"body": map[string]any{"$": typeMatcher(), items: typeMatcher(1)}},
+			}
+			interactions = append(interactions, pactInteraction{
+				Description:    fmt.Sprintf("a request to list %s", toPlural(entityLower)),
+				ProviderStates: []pactState{exists},
+				Request:        pactRequest{Method: "GET", Path: collection, Headers: headers(false)},
+				Response:       response,
+			})
+		}
+	}
+
+	return pactFile{
+		Consumer:     pactParticipant{Name: entityLower + "-client"},
+		Provider:     pactParticipant{Name: provider},
+		Interactions: interactions,
+		Metadata:     map[string]any{"pactSpecification": map[string]string{"version": "3.0.0"}},
+	}
+}
+
+// typeMatcher returns a matching rule matching values by type, and arrays
+// with at least min elements of the example's type.
+func typeMatcher(min ...int) map[string]any {
+	matcher := map[string]any{"match": "type"}
+	if len(min) > 0 {
+		matcher["min"] = min[0]
+	}
+	return map[string]any{"matchers": []any{matcher}}
+}
+
+// contractNotFound returns the 404 the API answers for a missing entity in
+// the project's response format. Raw handlers answer plain text, so only the
+// status is checked.
+func contractNotFound(format string) pactResponse {
+	switch format {
+	case ResponseFormatEnvelope:
+		return pactResponse{
+			Status:  404,
+			Headers: map[string]string{"Content-Type": "application/json"},
+			Body:    jsonObject{{"error", jsonObject{{"code", "not_found"}, {"message", "not found"}}}},
+			MatchingRules: map[string]any{"body": map[string]any{
+				"$.error.message": typeMatcher(),
+			}},
+		}
+	case ResponseFormatProblem:
+		return pactResponse{
+			Status:  404,
+			Headers: map[string]string{"Content-Type": "application/problem+json"},
+			Body:    jsonObject{{"title", "Not Found"}, {"status", 404}},
+		}
+	}
+	return pactResponse{Status: 404}
+}
+
+// contractStructFields returns the fields of the struct called name declared
+// in dir, or nil when there is none.
+func contractStructFields(dir, name string) []Field {
+	file := findTypeFile(dir, name)
+	if file == "" {
+		return nil
+	}
+	src, err := parseGoSource(file)
+	if err != nil {
+		return nil
+	}
+	return readEntityFields(src.file, name)
+}
+
+// contractRequestExample returns the example body a client sends for fields:
+// every field but the ones JSON skips.
+func contractRequestExample(fields []Field, entity string, updated bool) jsonObject {
+	body := jsonObject{}
+	for _, f := range fields {
+		key, tag, ok := contractJSONKey(f)
+		if !ok {
+			continue
+		}
+		body = append(body, jsonMember{key, contractValue(f, tag, entity, updated, true)})
+	}
+	return body
+}
+
+// contractResponseExample returns the example body of a response encoding
+// fields. Fields that can be absent or null (pointers, omitempty, slices,
+// maps and the soft-delete timestamp) are left out: a pact only lists what
+// every response carries.
+func contractResponseExample(fields []Field, entity string) jsonObject {
+	body := jsonObject{}
+	for _, f := range fields {
+		key, tag, ok := contractJSONKey(f)
+		typ := f.Type
+		if !ok || strings.Contains(tag.Get("json"), "omitempty") || strings.HasPrefix(typ, "*") ||
+			strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "map[") ||
+			typ == "gorm.DeletedAt" || typ == FieldJSONType {
+			continue
+		}
+		body = append(body, jsonMember{key, contractValue(f, tag, entity, false, false)})
+	}
+	return body
+}
+
+// contractJSONKey returns the key encoding/json writes f under and its tag,
+// or false when f is not encoded.
+func contractJSONKey(f Field) (string, reflect.StructTag, bool) {
+	tag := reflect.StructTag(strings.Trim(f.Tag, "`"))
+	name := strings.Split(tag.Get("json"), ",")[0]
+	switch {
+	case name == "-" || !ast.IsExported(f.Name):
+		return "", tag, false
+	case name == "":
+		name = f.Name
+	}
+	return name, tag, true
+}
+
+// contractValue returns the example JSON value of f. Values follow the
+// literals of generated tests, adjusted to the field's validation rules.
+func contractValue(f Field, tag reflect.StructTag, entity string, updated, request bool) any {
+	typ := strings.TrimPrefix(f.Type, "*")
+	switch {
+	case typ == "time.Time":
+		return contractTimestamp
+	case typ == FieldJSONType || strings.HasPrefix(typ, "map["):
+		return jsonObject{}
+	case typ == "[]byte":
+		return ""
+	case strings.HasPrefix(typ, "[]"):
+		return []any{}
+	}
+
+	nested := f.Nested
+	if nested == nil && !isBasicType(typ) {
+		nested = contractStructFields(filepath.Join(DirInternal, DirDomain), strings.TrimPrefix(typ, "domain."))
+	}
+	if nested != nil {
+		if request {
+			return contractRequestExample(nested, entity, updated)
+		}
+		return contractResponseExample(nested, entity)
+	}
+
+	if f.Name == "TenantID" {
+		return contractTenantID
+	}
+
+	rules := tag.Get("validate")
+	for _, rule := range strings.Split(rules, ",") {
+		switch {
+		case strings.HasPrefix(rule, "oneof="):
+			if options := strings.Fields(strings.TrimPrefix(rule, "oneof=")); len(options) > 0 {
+				return contractLiteral(options[0], typ)
+			}
+		case rule == "url" || rule == "uri" || rule == "http_url":
+			return fmt.Sprintf("https://example.com/%s/1", toPlural(strings.ToLower(entity)))
+		case rule == "uuid" || rule == "uuid4":
+			return "0b6e1f3c-4a58-4c1e-9d2f-6a7b8c9d0e1f"
+		}
+	}
+
+	literal := testLiteral(f.Name, typ, entity)
+	if updated {
+		literal = updatedTestLiteral(f.Name, typ, entity)
+	}
+	var value any
+	if err := json.Unmarshal([]byte(literal), &value); err != nil {
+		return strings.Trim(literal, `"`)
+	}
+	return value
+}
+
+// contractLiteral returns the oneof option s as a JSON number or bool when the
+// field has that type.
+func contractLiteral(s, typ string) any {
+	if typ == "string" {
+		return s
+	}
+	var value any
+	if err := json.Unmarshal([]byte(s), &value); err != nil {
+		return s
+	}
+	return value
+}
+
+// isBasicType reports whether typ is a Go predeclared type.
+func isBasicType(typ string) bool {
+	switch typ {
+	case "string", "bool", "byte", "rune", "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+		return true
+	}
+	return false
+}
+
+// contractVerifierSource returns internal/handler/http/contract_test.go, the
+// provider test every entity's contract test registers with. It only builds
+// with -tags contract, since pact-go needs cgo and the Pact FFI library.
+func contractVerifierSource(provider string) string {
+	return fmt.Sprintf(`//go:build contract
+
+package %s
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/pact-foundation/pact-go/v2/models"
+	"github.com/pact-foundation/pact-go/v2/provider"
+)
+
+// contractProvider is the name consumers give this API in their pacts.
+const contractProvider = %q
+
+// contractSetups mount the routes of each entity over an in-memory use case
+// and register the provider states their pacts rely on.
+var contractSetups []func(router *mux.Router, states models.StateHandlers)
+
+// TestProviderContracts verifies the API against the consumer pacts in
+// pacts/, or against the pacts published to PACT_BROKER_URL when it is set.
+// Results are published to the broker when PACT_PROVIDER_VERSION is set.
+func TestProviderContracts(t *testing.T) {
+	router := mux.NewRouter()
+	api := router.PathPrefix("/api/v1").Subrouter()
+	states := models.StateHandlers{}
+	for _, setup := range contractSetups {
+		setup(api, states)
+	}
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	request := provider.VerifyRequest{
+		Provider:        contractProvider,
+		ProviderBaseURL: server.URL,
+		PactDirs:        []string{filepath.Join("..", "..", "..", %q)},
+		StateHandlers:   states,
+	}
+	if broker := os.Getenv("PACT_BROKER_URL"); broker != "" {
+		request.PactDirs = nil
+		request.BrokerURL = broker
+		request.BrokerToken = os.Getenv("PACT_BROKER_TOKEN")
+		request.ProviderVersion = os.Getenv("PACT_PROVIDER_VERSION")
+		request.PublishVerificationResults = request.ProviderVersion != ""
+	}
+	if err := provider.NewVerifier().VerifyProvider(t, request); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// contractCopy copies the JSON fields of from into to.
+func contractCopy(from, to any) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, to)
+}
+`, DirHTTP, provider, pactsDir)
+}
+
+// entityContractSource returns internal/handler/http/<entity>_contract_test.go:
+// an in-memory use case answering the pact's interactions and the provider
+// states that seed it with the pact's example entity.
+func entityContractSource(entity, format, exampleJSON string) string {
+	entityLower := strings.ToLower(entity)
+	plural := toPlural(entityLower)
+	ucType := entityLower + "ContractUseCase"
+	importPath := getImportPath(getModuleName())
+	ops := map[string]bool{}
+	for _, op := range useCaseOperations(entity) {
+		ops[op] = true
+	}
+	notFound := fmt.Sprintf("errors.New(%q)", entityLower+" not found")
+	if format != ResponseFormatRaw {
+		notFound = "messages.ErrNotFound"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "//go:build contract\n\npackage %s\n\n", DirHTTP)
+	b.WriteString("import (\n\t\"encoding/json\"\n")
+	if format == ResponseFormatRaw {
+		b.WriteString("\t\"errors\"\n")
+	}
+	b.WriteString("\n\t\"github.com/gorilla/mux\"\n\t\"github.com/pact-foundation/pact-go/v2/models\"\n\n")
+	fmt.Fprintf(&b, "\t\"%s/internal/domain\"\n", importPath)
+	if format != ResponseFormatRaw {
+		fmt.Fprintf(&b, "\t\"%s/internal/messages\"\n", importPath)
+	}
+	fmt.Fprintf(&b, "\t\"%s/internal/usecase\"\n", importPath)
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(&b, "// %sContractExample is the %s the pacts in pacts/ expect.\n", entityLower, entityLower)
+	fmt.Fprintf(&b, "const %sContractExample = `%s`\n\n", entityLower, exampleJSON)
+
+	b.WriteString("func init() {\n")
+	fmt.Fprintf(&b, "\tcontractSetups = append(contractSetups, setup%sContract)\n", entity)
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// setup%sContract mounts the %s routes over an in-memory use case and\n", entity, entityLower)
+	b.WriteString("// registers the provider states of their pact.\n")
+	fmt.Fprintf(&b, "func setup%sContract(router *mux.Router, states models.StateHandlers) {\n", entity)
+	fmt.Fprintf(&b, "\tuc := &%s{%s: map[int]*domain.%s{}}\n", ucType, plural, entity)
+	fmt.Fprintf(&b, "\tSetup%sRoutes(router, uc)\n\n", entity)
+	fmt.Fprintf(&b, "\tstates[\"no %s exist\"] = func(bool, models.ProviderState) (models.ProviderStateResponse, error) {\n", plural)
+	fmt.Fprintf(&b, "\t\tuc.%s = map[int]*domain.%s{}\n", plural, entity)
+	b.WriteString("\t\treturn nil, nil\n")
+	b.WriteString("\t}\n")
+	fmt.Fprintf(&b, "\tstates[\"%s 1 exists\"] = func(bool, models.ProviderState) (models.ProviderStateResponse, error) {\n", entityLower)
+	fmt.Fprintf(&b, "\t\tvar %s domain.%s\n", entityLower, entity)
+	fmt.Fprintf(&b, "\t\terr := json.Unmarshal([]byte(%sContractExample), &%s)\n", entityLower, entityLower)
+	fmt.Fprintf(&b, "\t\tuc.%s = map[int]*domain.%s{1: &%s}\n", plural, entity, entityLower)
+	b.WriteString("\t\treturn nil, err\n")
+	b.WriteString("\t}\n")
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// %s answers the pact's requests from memory. Operations the\n", ucType)
+	b.WriteString("// pacts do not exercise fall through to the nil embedded use case.\n")
+	fmt.Fprintf(&b, "type %s struct {\n", ucType)
+	fmt.Fprintf(&b, "\tusecase.%sUseCase\n", entity)
+	fmt.Fprintf(&b, "\t%s map[int]*domain.%s\n", plural, entity)
+	b.WriteString("}\n")
+
+	if ops[OpCreate] {
+		fmt.Fprintf(&b, "\nfunc (u *%s) Create%s(input usecase.Create%sInput) (usecase.Create%sOutput, error) {\n", ucType, entity, entity, entity)
+		fmt.Fprintf(&b, "\tvar output usecase.Create%sOutput\n", entity)
+		fmt.Fprintf(&b, "\tvar %s domain.%s\n", entityLower, entity)
+		fmt.Fprintf(&b, "\tif err := contractCopy(input, &%s); err != nil {\n", entityLower)
+		b.WriteString("\t\treturn output, err\n")
+		b.WriteString("\t}\n")
+		fmt.Fprintf(&b, "\tid := len(u.%s) + 1\n", plural)
+		fmt.Fprintf(&b, "\tif err := contractCopy(map[string]int{\"id\": id}, &%s); err != nil {\n", entityLower)
+		b.WriteString("\t\treturn output, err\n")
+		b.WriteString("\t}\n")
+		fmt.Fprintf(&b, "\tu.%s[id] = &%s\n", plural, entityLower)
+		fmt.Fprintf(&b, "\treturn output, contractCopy(%s, &output)\n", entityLower)
+		b.WriteString("}\n")
+	}
+	if ops[OpRead] {
+		fmt.Fprintf(&b, "\nfunc (u *%s) Get%s(id int) (*domain.%s, error) {\n", ucType, entity, entity)
+		fmt.Fprintf(&b, "\t%s, ok := u.%s[id]\n", entityLower, plural)
+		b.WriteString("\tif !ok {\n")
+		fmt.Fprintf(&b, "\t\treturn nil, %s\n", notFound)
+		b.WriteString("\t}\n")
+		fmt.Fprintf(&b, "\treturn %s, nil\n", entityLower)
+		b.WriteString("}\n")
+	}
+	if ops[OpUpdate] {
+		fmt.Fprintf(&b, "\nfunc (u *%s) Update%s(id int, input usecase.Update%sInput) error {\n", ucType, entity, entity)
+		fmt.Fprintf(&b, "\t%s, ok := u.%s[id]\n", entityLower, plural)
+		b.WriteString("\tif !ok {\n")
+		fmt.Fprintf(&b, "\t\treturn %s\n", notFound)
+		b.WriteString("\t}\n")
+		fmt.Fprintf(&b, "\treturn contractCopy(input, %s)\n", entityLower)
+		b.WriteString("}\n")
+	}
+	if ops[OpDelete] {
+		fmt.Fprintf(&b, "\nfunc (u *%s) Delete%s(id int) error {\n", ucType, entity)
+		fmt.Fprintf(&b, "\tif _, ok := u.%s[id]; !ok {\n", plural)
+		fmt.Fprintf(&b, "\t\treturn %s\n", notFound)
+		b.WriteString("\t}\n")
+		fmt.Fprintf(&b, "\tdelete(u.%s, id)\n", plural)
+		b.WriteString("\treturn nil\n")
+		b.WriteString("}\n")
+	}
+	if ops[OpList] {
+		fmt.Fprintf(&b, "\nfunc (u *%s) List%s() (usecase.List%sOutput, error) {\n", ucType, toPlural(entity), entity)
+		fmt.Fprintf(&b, "\toutput := usecase.List%sOutput{Total: len(u.%s)}\n", entity, plural)
+		fmt.Fprintf(&b, "\tfor _, %s := range u.%s {\n", entityLower, plural)
+		fmt.Fprintf(&b, "\t\toutput.%s = append(output.%s, *%s)\n", toPlural(entity), toPlural(entity), entityLower)
+		b.WriteString("\t}\n")
+		b.WriteString("\treturn output, nil\n")
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+// ensureMakefileContractTargets adds the test-contract and pact-install
+// targets to the project's Makefile.
+func ensureMakefileContractTargets(sm ...*SafetyManager) {
+	const makefile = "Makefile"
+	raw, err := os.ReadFile(makefile)
+	if err != nil {
+		ui.Dim("   Run `go test -tags contract ./internal/handler/http` to verify the pacts in pacts/")
+		return
+	}
+	content := string(raw)
+	if strings.Contains(content, "\ntest-contract:") {
+		return
+	}
+
+	targets := fmt.Sprintf("test-contract: ## Verify the API against the consumer pacts in pacts/ (needs pact-install)\n"+
+		"\tgo test -tags contract -count=1 -run TestProviderContracts ./internal/handler/http\n\n"+
+		"pact-install: ## Install the Pact FFI library contract tests link against\n"+
+		"\tgo run github.com/pact-foundation/pact-go/v2@%s -l INFO install\n\n", pactGoVersion)
+
+	if strings.Contains(content, "\nlint:") {
+		content = strings.Replace(content, "\nlint:", "\n"+targets+"lint:", 1)
+	} else {
+		content = strings.TrimRight(content, "\n") + "\n\n" + targets
+	}
+	content = strings.Replace(content, ".PHONY: help build run test ", ".PHONY: help build run test test-contract pact-install ", 1)
+
+	if err := writeMergedFileSafe(makefile, content, sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Could not add contract targets to Makefile: %v", err))
+	}
+}
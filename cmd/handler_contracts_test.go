@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContractExamples(t *testing.T) {
+	chdirTemp(t)
+	fields := []Field{
+		{Name: "ID", Type: "uint", Tag: "`json:\"id\"`"},
+		{Name: "Name", Type: "string", Tag: "`json:\"name\" validate:\"required\"`"},
+		{Name: "Status", Type: "string", Tag: "`json:\"status\" validate:\"oneof=draft published\"`"},
+		{Name: "Level", Type: "int", Tag: "`json:\"level\" validate:\"oneof=3 5\"`"},
+		{Name: "Homepage", Type: "string", Tag: "`json:\"homepage\" validate:\"url\"`"},
+		{Name: "PublishedAt", Type: "time.Time", Tag: "`json:\"published_at\"`"},
+		{Name: "Notes", Type: "*string", Tag: "`json:\"notes,omitempty\"`"},
+		{Name: "Tags", Type: "[]string", Tag: "`json:\"tags\"`"},
+		{Name: "Secret", Type: "string", Tag: "`json:\"-\"`"},
+		{Name: "DeletedAt", Type: "gorm.DeletedAt", Tag: "`json:\"deleted_at\"`"},
+		{Name: "Address", Type: "Address", Nested: []Field{{Name: "City", Type: "string", Tag: "`json:\"city\"`"}}},
+	}
+
+	response, err := json.Marshal(contractResponseExample(fields, "Article"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":1,"name":"Test Article","status":"draft","level":3,"homepage":"https://example.com/articles/1",`+
+		`"published_at":"2024-01-15T09:30:00Z","Address":{"city":"test_city"}}`, string(response))
+
+	request, err := json.Marshal(contractRequestExample(fields, "Article", true))
+	require.NoError(t, err)
+	assert.Contains(t, string(request), `"name":"Updated Article"`)
+	assert.Contains(t, string(request), `"notes":"updated_notes","tags":[]`)
+	assert.NotContains(t, string(request), "Secret")
+}
+
+func TestGenerateContractTests(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	const fields = "name:string,price:float64"
+	sm := NewSafetyManager(false, false, false)
+	writeTestFile(t, ".", ".goca.yaml", "project:\n  name: shop\n  module: example.com/shop\ngeneration:\n  response_format: envelope\n")
+	writeTestFile(t, ".", "Makefile", ".PHONY: help build run test clean\n\ntest: ## Run tests\n\tgo test ./...\n\nlint: ## Run linter\n\tgolangci-lint run\n")
+	require.NoError(t, generateEntity("Product", fields, true, false, false, false, false, false, false, false, "lowercase", sm))
+	generateUseCaseWithFields("ProductService", "Product", "create,read,update,delete,list", true, false, fields, "", sm)
+	generateHandler("Product", HandlerHTTP, false, true, false, "lowercase", sm)
+
+	generateContractTests("Product", sm)
+
+	raw, err := os.ReadFile(filepath.Join(pactsDir, "product-client-shop.json"))
+	require.NoError(t, err)
+	var pact struct {
+		Consumer     pactParticipant `json:"consumer"`
+		Provider     pactParticipant `json:"provider"`
+		Interactions []struct {
+			Description    string      `json:"description"`
+			ProviderStates []pactState `json:"providerStates"`
+			Request        struct {
+				Method string         `json:"method"`
+				Path   string         `json:"path"`
+				Body   map[string]any `json:"body"`
+			} `json:"request"`
+			Response struct {
+				Status        int                       `json:"status"`
+				Body          map[string]any            `json:"body"`
+				MatchingRules map[string]map[string]any `json:"matchingRules"`
+			} `json:"response"`
+		} `json:"interactions"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &pact))
+	assert.Equal(t, "product-client", pact.Consumer.Name)
+	assert.Equal(t, "shop", pact.Provider.Name)
+	require.Len(t, pact.Interactions, 6)
+
+	create := pact.Interactions[0]
+	assert.Equal(t, "POST", create.Request.Method)
+	assert.Equal(t, "/api/v1/products", create.Request.Path)
+	assert.Equal(t, map[string]any{"name": "Test Product", "price": 9.99}, create.Request.Body)
+	assert.Equal(t, 201, create.Response.Status)
+	assert.Contains(t, create.Response.Body["data"], "id")
+	assert.Equal(t, []pactState{{Name: "no products exist"}}, create.ProviderStates)
+
+	missing := pact.Interactions[2]
+	assert.Equal(t, 404, missing.Response.Status)
+	assert.Equal(t, map[string]any{"code": "not_found", "message": "not found"}, missing.Response.Body["error"])
+
+	list := pact.Interactions[5]
+	assert.Equal(t, "/api/v1/products", list.Request.Path)
+	assert.Contains(t, list.Response.MatchingRules["body"], "$.data")
+	assert.Equal(t, map[string]any{"total": float64(1)}, list.Response.Body["meta"])
+
+	verifier, err := os.ReadFile(filepath.Join("internal", "handler", "http", "contract_test.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(verifier), "//go:build contract\n")
+	assert.Contains(t, string(verifier), `const contractProvider = "shop"`)
+
+	test := readParsedGo(t, filepath.Join("internal", "handler", "http", "product_contract_test.go"))
+	assert.Contains(t, test, `const productContractExample = `+"`"+`{"id":1,"name":"Test Product","price":9.99}`+"`")
+	assert.Contains(t, test, "SetupProductRoutes(router, uc)")
+	assert.Contains(t, test, `states["product 1 exists"]`)
+	assert.Contains(t, test, "return nil, messages.ErrNotFound")
+
+	makefile, err := os.ReadFile("Makefile")
+	require.NoError(t, err)
+	assert.Contains(t, string(makefile), ".PHONY: help build run test test-contract pact-install clean\n")
+	assert.Contains(t, string(makefile), "\tgo test -tags contract -count=1 -run TestProviderContracts ./internal/handler/http\n\npact-install:")
+	assert.Contains(t, string(makefile), "pact-go/v2@"+pactGoVersion+" -l INFO install\n\nlint:")
+
+	// A second run keeps the Makefile targets single.
+	generateContractTests("Product", NewSafetyManager(false, true, false))
+	makefile, err = os.ReadFile("Makefile")
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(makefile), "\ntest-contract:"))
+}
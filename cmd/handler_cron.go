@@ -0,0 +1,540 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// schedulerJobsMarker anchors the job registrations in cmd/scheduler/main.go.
+const schedulerJobsMarker = "// goca:jobs -- entity jobs are registered above this line"
+
+// cronOperations are the use case operations a cron handler can schedule, in
+// the order of the generated jobs: list syncs, count reports and delete
+// cleans up.
+var cronOperations = []string{OpList, OpCount, OpDelete}
+
+// defaultCronSchedules are the schedules of jobs without one in
+// features.scheduler.jobs.
+var defaultCronSchedules = map[string]string{
+	OpList:   "*/15 * * * *",
+	OpCount:  "@hourly",
+	OpDelete: "0 3 * * *",
+}
+
+// schedulerConfigDecl declares the scheduler settings of pkg/config.
+const schedulerConfigDecl = `// SchedulerConfig bounds the runs of the scheduled jobs of cmd/scheduler.
+type SchedulerConfig struct {
+	// JobTimeout bounds one run of a job. Zero disables it.
+	JobTimeout time.Duration
+	// ShutdownTimeout is how long a stopping scheduler waits for the running
+	// jobs before canceling their context. Zero waits without limit.
+	ShutdownTimeout time.Duration
+}
+`
+
+// schedulerConfigLoad is the Scheduler element of the Config literal Load
+// returns.
+const schedulerConfigLoad = `		Scheduler: SchedulerConfig{
+			JobTimeout:      getEnvAsDuration("SCHEDULER_JOB_TIMEOUT", "10m"),
+			ShutdownTimeout: getEnvAsDuration("SCHEDULER_SHUTDOWN_TIMEOUT", "30s"),
+		},
+`
+
+// cronJob is a job of a cron handler: the operation it runs, its name and
+// method, and its schedule.
+type cronJob struct {
+	Op       string
+	Name     string
+	Method   string
+	Schedule string
+}
+
+// generateCronHandler writes the scheduled jobs of an entity, the shared
+// scheduler and the cmd/scheduler entrypoint that runs every entity's jobs.
+// jobs lists the operations to schedule; when empty they come from
+// features.scheduler.jobs, or are every operation of the use case a job can
+// run.
+func generateCronHandler(entity, fileNamingConvention, jobs string, sm ...*SafetyManager) {
+	schedulerDir := filepath.Join(DirInternal, DirHandler, DirScheduler)
+	_ = os.MkdirAll(schedulerDir, 0o755)
+
+	configIntegration := NewConfigIntegration()
+	_ = configIntegration.LoadConfigForProject()
+	cronJobs, err := resolveCronJobs(entity, jobs, useCaseOperations(entity), configIntegration.GetSchedulerConfig())
+	if err != nil {
+		ui.Error(err.Error())
+		return
+	}
+
+	// Apply naming convention to filename
+	var filename string
+	if fileNamingConvention == "snake_case" {
+		filename = filepath.Join(schedulerDir, toSnakeCase(entity)+"_jobs.go")
+	} else if fileNamingConvention == "kebab-case" {
+		filename = filepath.Join(schedulerDir, toKebabCase(entity)+"-jobs.go")
+	} else {
+		filename = filepath.Join(schedulerDir, strings.ToLower(entity)+"_jobs.go")
+	}
+
+	importPath := getImportPath(getModuleName())
+	if err := writeGoFile(filename, buildCronJobs(entity, importPath, cronJobs), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing cron handler file: %v", err))
+		return
+	}
+
+	generateSchedulerFile(schedulerDir, importPath, sm...)
+	if err := ensureSchedulerConfig(sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Could not add the scheduler settings to pkg/config: %v", err))
+	}
+	generateSchedulerEntrypoint(entity, sm...)
+}
+
+// resolveCronJobs returns the jobs of entity, one per selected operation in
+// the order of cronOperations. Without a selection, the operations with a
+// schedule in cfg are used, then every operation the use case declares.
+func resolveCronJobs(entity, jobs string, available []string, cfg SchedulerConfig) ([]cronJob, error) {
+	schedules := cfg.schedules(entity)
+
+	selected := make(map[string]bool)
+	for _, op := range strings.Split(jobs, ",") {
+		if op = strings.ToLower(strings.TrimSpace(op)); op == "" {
+			continue
+		}
+		if !contains(cronOperations, op) {
+			return nil, fmt.Errorf("operation %q cannot be scheduled: use %s", op, strings.Join(cronOperations, ", "))
+		}
+		if !contains(available, op) {
+			return nil, fmt.Errorf("%sUseCase has no %s operation to schedule", entity, op)
+		}
+		selected[op] = true
+	}
+	if len(selected) == 0 {
+		for op := range schedules {
+			if contains(cronOperations, op) && contains(available, op) {
+				selected[op] = true
+			}
+		}
+	}
+	if len(selected) == 0 {
+		for _, op := range cronOperations {
+			selected[op] = contains(available, op)
+		}
+	}
+	if selected[OpDelete] && !contains(available, OpList) {
+		return nil, fmt.Errorf("the delete job of %s needs the list operation to find the records to clean up", entity)
+	}
+
+	name := toSnakeCase(entity)
+	plural := toPlural(entity)
+	var result []cronJob
+	for _, op := range cronOperations {
+		if !selected[op] {
+			continue
+		}
+		job := cronJob{Op: op, Schedule: defaultCronSchedules[op]}
+		switch op {
+		case OpList:
+			job.Name, job.Method = name+".sync", "Sync"+plural
+		case OpCount:
+			job.Name, job.Method = name+".report", "Report"+plural
+		case OpDelete:
+			job.Name, job.Method = name+".cleanup", "Cleanup"+plural
+		}
+		if schedule, ok := schedules[op]; ok {
+			job.Schedule = schedule
+		}
+		if err := validateCronSchedule(job.Schedule); err != nil {
+			return nil, fmt.Errorf("%s: %w", job.Name, err)
+		}
+		result = append(result, job)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("%sUseCase has none of the operations a job can run (%s)", entity, strings.Join(cronOperations, ", "))
+	}
+	return result, nil
+}
+
+// schedules returns the schedules features.scheduler.jobs sets for entity,
+// keyed by lowercase operation. The entity may be written as its type name,
+// in lowercase or in snake_case.
+func (c SchedulerConfig) schedules(entity string) map[string]string {
+	schedules := make(map[string]string)
+	for _, key := range []string{toSnakeCase(entity), strings.ToLower(entity), entity} {
+		for op, schedule := range c.Jobs[key] {
+			schedules[strings.ToLower(op)] = schedule
+		}
+	}
+	return schedules
+}
+
+// buildCronJobs renders the <entity>_jobs.go file: the <Entity>Jobs type and
+// one method per job.
+func buildCronJobs(entity, importPath string, jobs []cronJob) string {
+	entityLower := strings.ToLower(entity)
+	pluralLower := strings.ToLower(toPlural(entity))
+
+	has := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		has[job.Op] = true
+	}
+
+	var b strings.Builder
+	b.WriteString("package scheduler\n\n")
+	b.WriteString("import (\n\t\"context\"\n\t\"fmt\"\n\t\"log\"\n\n")
+	if has[OpList] || has[OpDelete] {
+		fmt.Fprintf(&b, "\t\"%s/internal/domain\"\n", importPath)
+	}
+	fmt.Fprintf(&b, "\t\"%s/internal/usecase\"\n", importPath)
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(&b, "// %sJobs runs operations of the %s use case on a schedule.\n", entity, entity)
+	fmt.Fprintf(&b, "type %sJobs struct {\n", entity)
+	fmt.Fprintf(&b, "\tusecase usecase.%sUseCase\n", entity)
+	if has[OpList] {
+		fmt.Fprintf(&b, "\n\t// Sync receives every %s on each run of Sync%s, e.g. to push them\n", entityLower, toPlural(entity))
+		b.WriteString("\t// to a search index. When nil the run only logs their number.\n")
+		fmt.Fprintf(&b, "\tSync func(ctx context.Context, %s []domain.%s) error\n", pluralLower, entity)
+	}
+	if has[OpDelete] {
+		fmt.Fprintf(&b, "\n\t// Expired selects the %s Cleanup%s deletes. When nil nothing is\n", pluralLower, toPlural(entity))
+		b.WriteString("\t// deleted.\n")
+		fmt.Fprintf(&b, "\tExpired func(%s domain.%s) bool\n", entityLower, entity)
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "func New%sJobs(uc usecase.%sUseCase) *%sJobs {\n", entity, entity, entity)
+	fmt.Fprintf(&b, "\treturn &%sJobs{usecase: uc}\n", entity)
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// Jobs returns the scheduled jobs of %s. The schedules come from\n", pluralLower)
+	b.WriteString("// features.scheduler.jobs of .goca.yaml when the handler was generated.\n")
+	fmt.Fprintf(&b, "func (j *%sJobs) Jobs() []Job {\n", entity)
+	b.WriteString("\treturn []Job{\n")
+	for _, job := range jobs {
+		fmt.Fprintf(&b, "\t\t{Name: %q, Schedule: %q, Run: j.%s},\n", job.Name, job.Schedule, job.Method)
+	}
+	b.WriteString("\t}\n")
+	b.WriteString("}\n")
+
+	for _, job := range jobs {
+		b.WriteString("\n")
+		switch job.Op {
+		case OpList:
+			writeCronSyncJob(&b, entity, job)
+		case OpCount:
+			writeCronReportJob(&b, entity, job)
+		case OpDelete:
+			writeCronCleanupJob(&b, entity, job)
+		}
+	}
+	return b.String()
+}
+
+func writeCronSyncJob(b *strings.Builder, entity string, job cronJob) {
+	pluralLower := strings.ToLower(toPlural(entity))
+
+	fmt.Fprintf(b, "// %s lists the %s and hands them to Sync.\n", job.Method, pluralLower)
+	fmt.Fprintf(b, "func (j *%sJobs) %s(ctx context.Context) error {\n", entity, job.Method)
+	fmt.Fprintf(b, "\toutput, err := j.usecase.List%s()\n", toPlural(entity))
+	b.WriteString("\tif err != nil {\n")
+	fmt.Fprintf(b, "\t\treturn fmt.Errorf(\"list %s: %%w\", err)\n", pluralLower)
+	b.WriteString("\t}\n")
+	b.WriteString("\tif j.Sync == nil {\n")
+	fmt.Fprintf(b, "\t\tlog.Printf(\"%s: %%d %s\", len(output.%s))\n", job.Name, pluralLower, toPlural(entity))
+	b.WriteString("\t\treturn nil\n")
+	b.WriteString("\t}\n")
+	fmt.Fprintf(b, "\treturn j.Sync(ctx, output.%s)\n", toPlural(entity))
+	b.WriteString("}\n")
+}
+
+func writeCronReportJob(b *strings.Builder, entity string, job cronJob) {
+	pluralLower := strings.ToLower(toPlural(entity))
+
+	fmt.Fprintf(b, "// %s logs the number of %s.\n", job.Method, pluralLower)
+	fmt.Fprintf(b, "func (j *%sJobs) %s(ctx context.Context) error {\n", entity, job.Method)
+	fmt.Fprintf(b, "\tcount, err := j.usecase.Count%s()\n", toPlural(entity))
+	b.WriteString("\tif err != nil {\n")
+	fmt.Fprintf(b, "\t\treturn fmt.Errorf(\"count %s: %%w\", err)\n", pluralLower)
+	b.WriteString("\t}\n")
+	fmt.Fprintf(b, "\tlog.Printf(\"%s: %%d %s\", count)\n", job.Name, pluralLower)
+	b.WriteString("\treturn nil\n")
+	b.WriteString("}\n")
+}
+
+func writeCronCleanupJob(b *strings.Builder, entity string, job cronJob) {
+	entityLower := strings.ToLower(entity)
+	pluralLower := strings.ToLower(toPlural(entity))
+
+	fmt.Fprintf(b, "// %s deletes the %s Expired selects. It stops when ctx is\n", job.Method, pluralLower)
+	b.WriteString("// done, as the job timeout elapses or the scheduler shuts down.\n")
+	fmt.Fprintf(b, "func (j *%sJobs) %s(ctx context.Context) error {\n", entity, job.Method)
+	b.WriteString("\tif j.Expired == nil {\n")
+	b.WriteString("\t\treturn nil\n")
+	b.WriteString("\t}\n")
+	fmt.Fprintf(b, "\toutput, err := j.usecase.List%s()\n", toPlural(entity))
+	b.WriteString("\tif err != nil {\n")
+	fmt.Fprintf(b, "\t\treturn fmt.Errorf(\"list %s: %%w\", err)\n", pluralLower)
+	b.WriteString("\t}\n\n")
+	b.WriteString("\tdeleted := 0\n")
+	fmt.Fprintf(b, "\tfor _, %s := range output.%s {\n", entityLower, toPlural(entity))
+	fmt.Fprintf(b, "\t\tif !j.Expired(%s) {\n", entityLower)
+	b.WriteString("\t\t\tcontinue\n")
+	b.WriteString("\t\t}\n")
+	b.WriteString("\t\tif err := ctx.Err(); err != nil {\n")
+	fmt.Fprintf(b, "\t\t\treturn fmt.Errorf(\"%s stopped after %%d deletions: %%w\", deleted, err)\n", job.Name)
+	b.WriteString("\t\t}\n")
+	fmt.Fprintf(b, "\t\tif err := j.usecase.Delete%s(int(%s.ID)); err != nil {\n", entity, entityLower)
+	fmt.Fprintf(b, "\t\t\treturn fmt.Errorf(\"delete %s %%v: %%w\", %s.ID, err)\n", entityLower, entityLower)
+	b.WriteString("\t\t}\n")
+	b.WriteString("\t\tdeleted++\n")
+	b.WriteString("\t}\n")
+	fmt.Fprintf(b, "\tlog.Printf(\"%s: deleted %%d %s\", deleted)\n", job.Name, pluralLower)
+	b.WriteString("\treturn nil\n")
+	b.WriteString("}\n")
+}
+
+// generateSchedulerFile writes internal/handler/scheduler/scheduler.go, the
+// scheduler every entity's jobs run on. Like the worker pool, it is only
+// rewritten with --force.
+func generateSchedulerFile(dir, importPath string, sm ...*SafetyManager) {
+	filename := filepath.Join(dir, "scheduler.go")
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+	if _, err := os.Stat(filename); err == nil && !force {
+		return
+	}
+
+	content := fmt.Sprintf(schedulerTemplate, importPath)
+	if err := writeGoFile(filename, content, sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing scheduler: %v", err))
+	}
+}
+
+// ensureSchedulerConfig adds SchedulerConfig to the pkg/config of projects
+// created before it was generated.
+func ensureSchedulerConfig(sm ...*SafetyManager) error {
+	return ensureConfigSection("Scheduler", "SchedulerConfig", schedulerConfigDecl, schedulerConfigLoad, sm...)
+}
+
+// generateSchedulerEntrypoint creates cmd/scheduler/main.go on first use and
+// registers the entity's jobs above schedulerJobsMarker. It is idempotent.
+func generateSchedulerEntrypoint(entity string, sm ...*SafetyManager) {
+	registration := fmt.Sprintf("\tjobs = append(jobs, scheduler.New%sJobs(container.%sUseCase()).Jobs()...)\n", entity, entity)
+	registerSchedulerJobs(fmt.Sprintf("scheduler.New%sJobs(", entity), registration, sm...)
+}
+
+// registerSchedulerJobs creates cmd/scheduler/main.go on first use and adds
+// registration above schedulerJobsMarker unless the file already contains
+// key. The DI container is declared once a registration uses it.
+func registerSchedulerJobs(key, registration string, sm ...*SafetyManager) {
+	mainPath := filepath.Join("cmd", "scheduler", "main.go")
+	defer lockSharedFile(mainPath)()
+
+	raw, err := os.ReadFile(mainPath)
+	if err != nil {
+		content, ok := buildSchedulerEntrypoint(registration)
+		if !ok {
+			return
+		}
+		if err := writeGoFile(mainPath, content, sm...); err != nil {
+			ui.Error(fmt.Sprintf("Error writing scheduler entrypoint: %v", err))
+		}
+		return
+	}
+
+	content := string(raw)
+	if strings.Contains(content, key) {
+		return
+	}
+	if !strings.Contains(content, schedulerJobsMarker) {
+		ui.Dim(fmt.Sprintf("   Register the jobs in %s: %s", mainPath, strings.TrimSpace(registration)))
+		return
+	}
+	if strings.Contains(registration, "container.") && !strings.Contains(content, schedulerContainerDecl) {
+		content = strings.Replace(content, "\n\n\tvar jobs []scheduler.Job\n", "\n"+schedulerContainerDecl+"\n\tvar jobs []scheduler.Job\n", 1)
+		content = ensureMainGoImport(content, getImportPath(getModuleName())+"/internal/di")
+	}
+	content = strings.Replace(content, "\t"+schedulerJobsMarker, registration+"\t"+schedulerJobsMarker, 1)
+	if err := writeGoFileMerged(mainPath, content, sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Could not register %s in %s: %v", strings.TrimSpace(registration), mainPath, err))
+	}
+}
+
+// schedulerContainerDecl declares the DI container in cmd/scheduler/main.go
+// for the jobs running use cases.
+const schedulerContainerDecl = "\tcontainer := di.NewContainer(db)\n"
+
+// buildSchedulerEntrypoint renders cmd/scheduler/main.go for the project
+// database. It reports false for databases the DI container cannot be built
+// from.
+func buildSchedulerEntrypoint(registration string) (string, bool) {
+	configIntegration := NewConfigIntegration()
+	_ = configIntegration.LoadConfigForProject()
+	database := configIntegration.GetDatabaseType("")
+	importPath := getImportPath(getModuleName())
+
+	std, external, openDatabase, ok := databaseOpener(database, projectSQLiteDriver())
+	if !ok {
+		ui.Dim(fmt.Sprintf("   No scheduler entrypoint for %s; run the jobs from your own main: %s", database, strings.TrimSpace(registration)))
+		return "", false
+	}
+	imports := importLines(append([]string{"context", "flag", "fmt", "log", "os", "os/signal", "syscall"}, std...), external)
+	diImport, container := "", ""
+	if strings.Contains(registration, "container.") {
+		diImport, container = fmt.Sprintf("\t%q\n", importPath+"/internal/di"), schedulerContainerDecl
+	}
+
+	return fmt.Sprintf(`package main
+
+import (
+%s
+%s	"%s/internal/handler/scheduler"
+	"%s/pkg/config"
+)
+
+// main runs the jobs of every entity generated with a cron handler or a
+// retention window on their schedules until SIGINT or SIGTERM, then waits for
+// the running ones. With -once it runs them once and exits, e.g. "go run
+// ./cmd/scheduler -once -job product.cleanup".
+func main() {
+	once := flag.Bool("once", false, "run the jobs once and exit instead of on their schedules")
+	only := flag.String("job", "", "run only the named job")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg := config.Load()
+	db, err := openDatabase(cfg)
+	if err != nil {
+		fail("connecting to database: %%v", err)
+	}
+%s
+	var jobs []scheduler.Job
+%s	%s
+
+	if *only != "" {
+		var selected []scheduler.Job
+		for _, job := range jobs {
+			if job.Name == *only {
+				selected = append(selected, job)
+			}
+		}
+		if len(selected) == 0 {
+			fail("unknown job %%q", *only)
+		}
+		jobs = selected
+	}
+
+	s := scheduler.New(cfg.Scheduler)
+	if *once {
+		for _, job := range jobs {
+			if err := s.RunOnce(ctx, job); err != nil {
+				fail("%%s: %%v", job.Name, err)
+			}
+		}
+		return
+	}
+	log.Printf("scheduling %%d jobs", len(jobs))
+	if err := s.Run(ctx, jobs...); err != nil {
+		fail("%%v", err)
+	}
+}
+
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "Error: "+format+"\n", args...)
+	os.Exit(1)
+}
+
+%s`, imports, diImport, importPath, importPath, container, registration, schedulerJobsMarker, openDatabase), true
+}
+
+const schedulerTemplate = `package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"%s/pkg/config"
+)
+
+// Job is a use case operation run on a schedule.
+type Job struct {
+	// Name identifies the job in logs and selects it with -job.
+	Name string
+	// Schedule is a cron expression (minute hour day month weekday), a
+	// descriptor such as @daily or @every <duration>. Prefix it with
+	// CRON_TZ=<zone> to use another time zone than the local one.
+	Schedule string
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler runs jobs on their schedules. A run that is due while the
+// previous one of the job is still going is skipped, and a run that panics
+// fails alone.
+type Scheduler struct {
+	cfg  config.SchedulerConfig
+	cron *cron.Cron
+}
+
+// New returns a scheduler bounding runs by cfg. It runs nothing until Run.
+func New(cfg config.SchedulerConfig) *Scheduler {
+	chain := cron.WithChain(cron.Recover(cron.DefaultLogger), cron.SkipIfStillRunning(cron.DefaultLogger))
+	return &Scheduler{cfg: cfg, cron: cron.New(chain)}
+}
+
+// Run runs jobs on their schedules until ctx is done. It then starts no new
+// runs and waits for the running ones, canceling their context once the
+// shutdown timeout elapses.
+func (s *Scheduler) Run(ctx context.Context, jobs ...Job) error {
+	runCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, job := range jobs {
+		job := job
+		if _, err := s.cron.AddFunc(job.Schedule, func() { s.run(runCtx, job) }); err != nil {
+			return fmt.Errorf("%%s: schedule %%q: %%w", job.Name, job.Schedule, err)
+		}
+	}
+	s.cron.Start()
+	<-ctx.Done()
+
+	stopped := s.cron.Stop()
+	if s.cfg.ShutdownTimeout > 0 {
+		select {
+		case <-stopped.Done():
+			return nil
+		case <-time.After(s.cfg.ShutdownTimeout):
+			log.Printf("scheduler: canceling the jobs still running after %%s", s.cfg.ShutdownTimeout)
+			cancel()
+		}
+	}
+	<-stopped.Done()
+	return nil
+}
+
+// RunOnce runs job now, within the job timeout.
+func (s *Scheduler) RunOnce(ctx context.Context, job Job) error {
+	if s.cfg.JobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.JobTimeout)
+		defer cancel()
+	}
+	return job.Run(ctx)
+}
+
+// run is a scheduled run of job; failures are logged.
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	start := time.Now()
+	if err := s.RunOnce(ctx, job); err != nil {
+		log.Printf("%%s: %%v", job.Name, err)
+		return
+	}
+	log.Printf("%%s: done in %%s", job.Name, time.Since(start).Round(time.Millisecond))
+}
+`
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateCronHandler(t *testing.T) {
+	defer ensureTestUI(t)()
+	setupCLIProject(t, fullProductUseCase)
+	writeTestFile(t, ".", filepath.Join("pkg", "config", "config.go"), legacyConfig)
+	require.NoError(t, os.WriteFile(".goca.yaml", []byte("project:\n  name: shop\n  module: testproject\ndatabase:\n  type: postgres\nfeatures:\n  scheduler:\n    jobs:\n      product:\n        list: \"@every 10m\"\n"), 0o644))
+
+	sm := NewSafetyManager(false, false, false)
+	generateCronHandler("Product", "lowercase", "", sm)
+
+	dir := filepath.Join(DirInternal, DirHandler, DirScheduler)
+	jobs := readParsedGo(t, filepath.Join(dir, "product_jobs.go"))
+	assert.Contains(t, jobs, "func NewProductJobs(uc usecase.ProductUseCase) *ProductJobs {")
+	assert.Contains(t, jobs, `{Name: "product.sync", Schedule: "@every 10m", Run: j.SyncProducts},`)
+	assert.NotContains(t, jobs, "CleanupProducts", "configured jobs select the operations")
+	assert.Contains(t, jobs, "return j.Sync(ctx, output.Products)")
+
+	scheduler := readParsedGo(t, filepath.Join(dir, "scheduler.go"))
+	assert.Contains(t, scheduler, `"testproject/pkg/config"`)
+	assert.Contains(t, scheduler, "cron.WithChain(cron.Recover(cron.DefaultLogger), cron.SkipIfStillRunning(cron.DefaultLogger))")
+	assert.Contains(t, scheduler, "case <-time.After(s.cfg.ShutdownTimeout):")
+
+	config := readParsedGo(t, filepath.Join("pkg", "config", "config.go"))
+	assert.Contains(t, config, "\tServer    ServerConfig\n\tScheduler SchedulerConfig\n}")
+	assert.Contains(t, config, `ShutdownTimeout: getEnvAsDuration("SCHEDULER_SHUTDOWN_TIMEOUT", "30s"),`)
+
+	main := readParsedGo(t, filepath.Join("cmd", "scheduler", "main.go"))
+	assert.Contains(t, main, "container := di.NewContainer(db)")
+	assert.Contains(t, main, "jobs = append(jobs, scheduler.NewProductJobs(container.ProductUseCase()).Jobs()...)")
+	assert.Contains(t, main, `"gorm.io/driver/postgres"`)
+	assert.Contains(t, main, "if err := s.Run(ctx, jobs...); err != nil {")
+
+	// A second entity is registered once, above the marker.
+	generateCronHandler("Order", "lowercase", "list", sm)
+	generateCronHandler("Order", "lowercase", "list", sm)
+	main = readParsedGo(t, filepath.Join("cmd", "scheduler", "main.go"))
+	assert.Equal(t, 1, strings.Count(main, "scheduler.NewOrderJobs("))
+	assert.Less(t, strings.Index(main, "scheduler.NewProductJobs("), strings.Index(main, "scheduler.NewOrderJobs("))
+	assert.Less(t, strings.Index(main, "scheduler.NewOrderJobs("), strings.Index(main, schedulerJobsMarker))
+}
+
+func TestResolveCronJobs(t *testing.T) {
+	all := []string{OpCreate, OpRead, OpUpdate, OpDelete, OpList, OpCount}
+
+	jobs, err := resolveCronJobs("OrderItem", "", all, SchedulerConfig{})
+	require.NoError(t, err)
+	require.Len(t, jobs, 3)
+	assert.Equal(t, cronJob{Op: OpList, Name: "order_item.sync", Method: "SyncOrderItems", Schedule: "*/15 * * * *"}, jobs[0])
+	assert.Equal(t, cronJob{Op: OpCount, Name: "order_item.report", Method: "ReportOrderItems", Schedule: "@hourly"}, jobs[1])
+	assert.Equal(t, cronJob{Op: OpDelete, Name: "order_item.cleanup", Method: "CleanupOrderItems", Schedule: "0 3 * * *"}, jobs[2])
+
+	cfg := SchedulerConfig{Jobs: map[string]map[string]string{"OrderItem": {"Delete": "0 4 * * *"}}}
+	jobs, err = resolveCronJobs("OrderItem", "", all, cfg)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "0 4 * * *", jobs[0].Schedule)
+
+	jobs, err = resolveCronJobs("OrderItem", "count, list", all, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"order_item.sync", "order_item.report"}, []string{jobs[0].Name, jobs[1].Name})
+
+	_, err = resolveCronJobs("OrderItem", "create", all, SchedulerConfig{})
+	assert.ErrorContains(t, err, `operation "create" cannot be scheduled`)
+	_, err = resolveCronJobs("OrderItem", "count", []string{OpList}, SchedulerConfig{})
+	assert.ErrorContains(t, err, "has no count operation")
+	_, err = resolveCronJobs("OrderItem", "delete", []string{OpDelete}, SchedulerConfig{})
+	assert.ErrorContains(t, err, "needs the list operation")
+	_, err = resolveCronJobs("OrderItem", "", []string{OpCreate}, SchedulerConfig{})
+	assert.ErrorContains(t, err, "none of the operations")
+	_, err = resolveCronJobs("OrderItem", "", all, SchedulerConfig{Jobs: map[string]map[string]string{"order_item": {"list": "every day"}}})
+	assert.ErrorContains(t, err, "order_item.sync: invalid")
+
+	cm := NewConfigManager()
+	cm.validateFeatures(&FeatureConfig{Scheduler: SchedulerConfig{Jobs: map[string]map[string]string{
+		"product": {"list": "@every 5m", "count": "* *", "create": "@daily"},
+	}}})
+	require.Len(t, cm.GetErrors(), 2)
+	assert.Equal(t, "features.scheduler.jobs.product.count", cm.GetErrors()[0].Field)
+	assert.Equal(t, "features.scheduler.jobs.product.create", cm.GetErrors()[1].Field)
+}
+
+func TestBuildCronJobs_ReportOnly(t *testing.T) {
+	content := buildCronJobs("Product", "testproject", []cronJob{{Op: OpCount, Name: "product.report", Method: "ReportProducts", Schedule: "@hourly"}})
+	assert.NotContains(t, content, "internal/domain", "only sync and cleanup take entities")
+	assert.NotContains(t, content, "Expired")
+	assert.Contains(t, content, "count, err := j.usecase.CountProducts()")
+}
@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultMaxRequestDepth bounds the nesting of strictly decoded request
+// bodies when api.max_depth is not set.
+const defaultMaxRequestDepth = 32
+
+// projectStrictDecoding returns whether the current project enables
+// api.strict_decoding, and the maximum nesting of request bodies.
+func projectStrictDecoding() (bool, int) {
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	cfg := ci.GetAPIConfig()
+	if cfg.MaxDepth <= 0 {
+		cfg.MaxDepth = defaultMaxRequestDepth
+	}
+	return cfg.StrictDecoding, cfg.MaxDepth
+}
+
+// writeStrictRequestDecoding writes the statement that decodes a JSON
+// request body into input with decodeJSON of decode.go, which answers a
+// rejected body itself.
+func writeStrictRequestDecoding(content *strings.Builder) {
+	content.WriteString("\tif !decodeJSON(w, r, &input) {\n")
+	content.WriteString("\t\treturn\n")
+	content.WriteString("\t}\n\n")
+}
+
+// generateHTTPDecodeFile writes internal/handler/http/decode.go, the strict
+// decoder of request bodies: unknown fields, values of the wrong type, bodies
+// nested deeper than maxDepth and trailing data are answered with 400 and the
+// rejected fields in the body of the response format. Like validation.go, it
+// is only rewritten with --force.
+func generateHTTPDecodeFile(dir, format string, maxDepth int, sm ...*SafetyManager) {
+	filename := filepath.Join(dir, "decode.go")
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+	if _, err := os.Stat(filename); err == nil && !force {
+		return
+	}
+
+	var content strings.Builder
+	content.WriteString("package " + DirHTTP + "\n\n")
+	content.WriteString("import (\n")
+	content.WriteString("\t\"bytes\"\n")
+	content.WriteString("\t\"encoding/json\"\n")
+	content.WriteString("\t\"errors\"\n")
+	content.WriteString("\t\"fmt\"\n")
+	content.WriteString("\t\"io\"\n")
+	content.WriteString("\t\"net/http\"\n")
+	content.WriteString("\t\"reflect\"\n")
+	content.WriteString("\t\"strconv\"\n")
+	content.WriteString("\t\"strings\"\n")
+	content.WriteString(")\n\n")
+
+	fmt.Fprintf(&content, "// maxRequestDepth bounds the nesting of objects and arrays in request\n// bodies (api.max_depth in .goca.yaml).\nconst maxRequestDepth = %d\n\n", maxDepth)
+	content.WriteString(decodeTemplate)
+	switch format {
+	case ResponseFormatEnvelope:
+		content.WriteString(envelopeDecodeTemplate)
+	case ResponseFormatProblem:
+		content.WriteString(problemDecodeTemplate)
+	default:
+		content.WriteString(rawDecodeTemplate)
+	}
+
+	if err := writeGoFile(filename, content.String(), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing request decoder: %v", err))
+	}
+}
+
+const decodeTemplate = `// BodyFieldError describes why a field of a request body was rejected.
+type BodyFieldError struct {
+	Field   string ` + "`json:\"field\"`" + `
+	Rule    string ` + "`json:\"rule\"`" + `
+	Message string ` + "`json:\"message\"`" + `
+}
+
+// BodyError is a request body the strict decoder rejected.
+type BodyError struct {
+	Message string
+	Fields  []BodyFieldError
+}
+
+func (e *BodyError) Error() string {
+	return e.Message
+}
+
+// decodeJSON decodes the JSON body of r into input with decodeStrict. It
+// answers a rejected body with 400 and the rejected fields, and then reports
+// false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, input any) bool {
+	err := decodeStrict(r.Body, input)
+	if err == nil {
+		return true
+	}
+	respondBodyError(w, r, err)
+	return false
+}
+
+// decodeStrict decodes a single JSON value from body into input. Unknown
+// fields, values of the wrong type, nesting deeper than maxRequestDepth and
+// trailing data are reported as a *BodyError; read errors, such as a body
+// over the limit of http.MaxBytesReader, are returned as they are.
+func decodeStrict(body io.Reader, input any) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if err := checkDepth(data); err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(input); err != nil {
+		return translateDecodeError(err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return &BodyError{Message: "request body must contain a single JSON value"}
+	}
+	return nil
+}
+
+// checkDepth rejects JSON that nests objects and arrays deeper than
+// maxRequestDepth, before it is decoded.
+func checkDepth(data []byte) error {
+	depth, inString, escaped := 0, false, false
+	for _, c := range data {
+		switch {
+		case escaped:
+			escaped = false
+		case inString:
+			switch c {
+			case '\\':
+				escaped = true
+			case '"':
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{' || c == '[':
+			if depth++; depth > maxRequestDepth {
+				return &BodyError{Message: fmt.Sprintf("request body is nested deeper than %d levels", maxRequestDepth)}
+			}
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+	return nil
+}
+
+// translateDecodeError turns an encoding/json error into a *BodyError whose
+// fields are named by their JSON path, e.g. address.city.
+func translateDecodeError(err error) error {
+	var syntax *json.SyntaxError
+	var mismatch *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return &BodyError{Message: "request body is empty"}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &BodyError{Message: "request body is not valid JSON: unexpected end of input"}
+	case errors.As(err, &syntax):
+		return &BodyError{Message: fmt.Sprintf("request body is not valid JSON at offset %d", syntax.Offset)}
+	case errors.As(err, &mismatch):
+		if mismatch.Field == "" {
+			return &BodyError{Message: "request body must be " + jsonKind(mismatch.Type)}
+		}
+		return &BodyError{
+			Message: "request body has fields of the wrong type",
+			Fields:  []BodyFieldError{{Field: mismatch.Field, Rule: "type", Message: "must be " + jsonKind(mismatch.Type)}},
+		}
+	}
+	// encoding/json reports unknown fields as a plain error.
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		if unquoted, uerr := strconv.Unquote(name); uerr == nil {
+			name = unquoted
+		}
+		return &BodyError{
+			Message: "request body has unknown fields",
+			Fields:  []BodyFieldError{{Field: name, Rule: "unknown", Message: "is not a known field"}},
+		}
+	}
+	return &BodyError{Message: "invalid request body: " + err.Error()}
+}
+
+// jsonKind names the JSON value a Go type decodes from.
+func jsonKind(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return "a " + t.String()
+}
+
+// bodyErrorStatus returns the status answering a decodeStrict error.
+func bodyErrorStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+`
+
+const rawDecodeTemplate = `// bodyErrorResponse is the body of a rejected request.
+type bodyErrorResponse struct {
+	Error  string           ` + "`json:\"error\"`" + `
+	Fields []BodyFieldError ` + "`json:\"fields,omitempty\"`" + `
+}
+
+// respondBodyError answers a decodeStrict error with its message and the
+// rejected fields.
+func respondBodyError(w http.ResponseWriter, r *http.Request, err error) {
+	body := bodyErrorResponse{Error: err.Error()}
+	var invalid *BodyError
+	if errors.As(err, &invalid) {
+		body.Fields = invalid.Fields
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(bodyErrorStatus(err))
+	_ = json.NewEncoder(w).Encode(body)
+}
+`
+
+const envelopeDecodeTemplate = `// bodyErrorBody is the error of a rejected request envelope, with the
+// rejected fields.
+type bodyErrorBody struct {
+	ErrorBody
+	Fields []BodyFieldError ` + "`json:\"fields,omitempty\"`" + `
+}
+
+// respondBodyError answers a decodeStrict error with an error envelope
+// listing the rejected fields.
+func respondBodyError(w http.ResponseWriter, r *http.Request, err error) {
+	status := bodyErrorStatus(err)
+	body := bodyErrorBody{ErrorBody: ErrorBody{
+		Code:    strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_"),
+		Message: err.Error(),
+	}}
+	var invalid *BodyError
+	if errors.As(err, &invalid) {
+		body.Fields = invalid.Fields
+	}
+	writeJSON(w, status, "application/json", struct {
+		Error bodyErrorBody ` + "`json:\"error\"`" + `
+	}{Error: body})
+}
+`
+
+const problemDecodeTemplate = `// bodyProblem is a problem details body with the rejected fields as its
+// errors extension member.
+type bodyProblem struct {
+	Problem
+	Errors []BodyFieldError ` + "`json:\"errors,omitempty\"`" + `
+}
+
+// respondBodyError answers a decodeStrict error with a problem listing the
+// rejected fields.
+func respondBodyError(w http.ResponseWriter, r *http.Request, err error) {
+	status := bodyErrorStatus(err)
+	problem := bodyProblem{Problem: Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   err.Error(),
+		Instance: r.URL.Path,
+	}}
+	var invalid *BodyError
+	if errors.As(err, &invalid) {
+		problem.Errors = invalid.Fields
+	}
+	writeJSON(w, status, "application/problem+json", problem)
+}
+`
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeStrictDecodingConfig(t *testing.T, format string) {
+	t.Helper()
+	config := "project:\n  name: shop\n  module: example.com/shop\ngeneration:\n  response_format: " + format +
+		"\napi:\n  type: rest\n  strict_decoding: true\n  max_depth: 8\n"
+	require.NoError(t, os.WriteFile(".goca.yaml", []byte(config), 0o644))
+}
+
+func TestGenerateHTTPHandler_StrictDecoding(t *testing.T) {
+	for _, format := range []string{ResponseFormatRaw, ResponseFormatEnvelope, ResponseFormatProblem} {
+		t.Run(format, func(t *testing.T) {
+			chdirTemp(t)
+			defer ensureTestUI(t)()
+			writeStrictDecodingConfig(t, format)
+
+			handler := generateProductHandler(t)
+			assert.Contains(t, handler, "if !decodeJSON(w, r, &input) {")
+			assert.NotContains(t, handler, "json.NewDecoder")
+
+			decode := readParsedGo(t, filepath.Join(DirInternal, DirHandler, DirHTTP, "decode.go"))
+			assert.Contains(t, decode, "const maxRequestDepth = 8")
+			assert.Contains(t, decode, "decoder.DisallowUnknownFields()")
+			assert.Contains(t, decode, "func respondBodyError(w http.ResponseWriter, r *http.Request, err error) {")
+			switch format {
+			case ResponseFormatEnvelope:
+				assert.Contains(t, decode, "type bodyErrorBody struct {")
+			case ResponseFormatProblem:
+				assert.Contains(t, decode, `writeJSON(w, status, "application/problem+json", problem)`)
+			default:
+				assert.Contains(t, decode, "type bodyErrorResponse struct {")
+			}
+		})
+	}
+}
+
+func TestGenerateHTTPHandler_StrictDecodingUploads(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+	writeStrictDecodingConfig(t, ResponseFormatRaw)
+	writeTestFile(t, ".", filepath.Join("pkg", "config", "config.go"), legacyConfig)
+
+	fields := "Title:string,Avatar:file"
+	sm := NewSafetyManager(false, true, false)
+	require.NoError(t, generateEntity("Profile", fields, true, false, false, false, false, false, false, true, "lowercase", sm))
+	generateUseCaseWithFields("ProfileUseCase", "Profile", "create,read,update,delete,list", false, false, fields, "standard", sm)
+	generateHTTPHandler("Profile", false, false, false, "lowercase", sm)
+
+	handler := readParsedGo(t, filepath.Join(DirInternal, DirHandler, DirHTTP, "profile_handler.go"))
+	assert.Contains(t, handler, "respondBodyError(w, r, err)")
+
+	files := readParsedGo(t, filepath.Join(DirInternal, DirHandler, DirHTTP, "files.go"))
+	assert.Contains(t, files, "decodeStrict(r.Body, input)")
+	assert.Contains(t, files, "decodeStrict(part, input)")
+	assert.NotContains(t, files, `"encoding/json"`)
+	assert.FileExists(t, filepath.Join(DirInternal, DirHandler, DirHTTP, "decode.go"))
+}
+
+func TestGenerateHTTPHandler_LenientDecoding(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	handler := generateProductHandler(t)
+	assert.Contains(t, handler, "json.NewDecoder(r.Body).Decode(&input)")
+	assert.NoFileExists(t, filepath.Join(DirInternal, DirHandler, DirHTTP, "decode.go"))
+}
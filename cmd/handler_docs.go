@@ -0,0 +1,366 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// swaggerUIVersion and redocVersion pin the UI bundles of the generated docs
+// pages.
+const (
+	swaggerUIVersion = "5.17.14"
+	redocVersion     = "2.1.5"
+)
+
+// maxDocsBundleSize caps the download of a docs UI bundle.
+const maxDocsBundleSize = 16 << 20
+
+// docsBundleCDN serves the UI bundles vendored next to the docs pages.
+var docsBundleCDN = "https://cdn.jsdelivr.net/npm"
+
+// docsBundle is a file of a UI bundle the docs pages load.
+type docsBundle struct {
+	name, url string
+}
+
+// swaggerUIBundles returns the files of the Swagger UI bundle.
+func swaggerUIBundles() []docsBundle {
+	base := fmt.Sprintf("%s/swagger-ui-dist@%s/", docsBundleCDN, swaggerUIVersion)
+	return []docsBundle{{"swagger-ui.css", base + "swagger-ui.css"}, {"swagger-ui-bundle.js", base + "swagger-ui-bundle.js"}}
+}
+
+// redocBundles returns the files of the Redoc bundle.
+func redocBundles() []docsBundle {
+	return []docsBundle{{"redoc.standalone.js", fmt.Sprintf("%s/redoc@%s/bundles/redoc.standalone.js", docsBundleCDN, redocVersion)}}
+}
+
+// generateDocsEndpoint writes internal/handler/http/docs.go, which embeds
+// swagger.yaml and the docs directory next to it and serves them under /docs:
+// a Swagger UI and a Redoc page and their bundles, downloaded once here so
+// the docs work offline. The route is wired into main.go when one is found.
+func generateDocsEndpoint(dir string, sm ...*SafetyManager) {
+	filename := filepath.Join(dir, "docs.go")
+	embedded := projectEmbedsAssets()
+	content := fmt.Sprintf(docsEndpointTemplate, swaggerUIVersion, redocVersion)
+	pages := []struct{ name, content string }{
+		{"index.html", strings.ReplaceAll(swaggerUIIndexTemplate, "{{version}}", swaggerUIVersion)},
+		{"redoc.html", strings.ReplaceAll(redocIndexTemplate, "{{version}}", redocVersion)},
+	}
+	bundles := append(swaggerUIBundles(), redocBundles()...)
+	if embedded {
+		content = serveEmbeddedSwaggerUI(content, getModuleName())
+		pages, bundles = pages[1:], redocBundles()
+	}
+
+	// docs.go and the pages do not depend on the entity, so a later handler
+	// keeps the existing files unless --force was given.
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+	if _, err := os.Stat(filename); err != nil || force {
+		if err := writeGoFile(filename, content, sm...); err != nil {
+			ui.Error(fmt.Sprintf("Error writing docs endpoint: %v", err))
+			return
+		}
+	}
+	docsDir := filepath.Join(dir, "docs")
+	for _, page := range pages {
+		path := filepath.Join(docsDir, page.name)
+		if _, err := os.Stat(path); err != nil || force {
+			if err := writeFile(path, page.content, sm...); err != nil {
+				ui.Error(fmt.Sprintf("Error writing %s: %v", path, err))
+				return
+			}
+		}
+	}
+	if !vendorDocsBundles(docsDir, bundles, sm...) {
+		ui.Dim("   /docs loads the missing UI bundles from the CDN until go generate ./" + filepath.ToSlash(dir) + " vendors them")
+	}
+
+	wireDocsIntoMainGo(sm...)
+}
+
+// vendorDocsBundles downloads the bundles missing from dir, so the pages in it
+// work offline. It stops at the first failure, leaving the pages to load the
+// rest from the CDN, and reports whether every bundle is in dir. Dry runs
+// download nothing.
+func vendorDocsBundles(dir string, bundles []docsBundle, sm ...*SafetyManager) bool {
+	if len(sm) > 0 && sm[0] != nil && sm[0].DryRun {
+		return true
+	}
+	for _, bundle := range bundles {
+		path := filepath.Join(dir, bundle.name)
+		if fileExists(path) {
+			continue
+		}
+		body, err := downloadDocsBundle(bundle.url)
+		if err == nil {
+			err = writeFile(path, string(body), sm...)
+		}
+		if err != nil {
+			ui.Warning(fmt.Sprintf("Could not vendor %s: %v", bundle.name, err))
+			return false
+		}
+	}
+	return true
+}
+
+// downloadDocsBundle returns the body of a UI bundle URL.
+func downloadDocsBundle(url string) ([]byte, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url) //#nosec G107 -- a pinned CDN bundle URL
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxDocsBundleSize))
+}
+
+// serveEmbeddedSwaggerUI rewrites the docs endpoint of a project initialized
+// with --embed-assets to serve the Swagger UI of internal/assets, which
+// ASSETS_DIR can override, under /docs/. The docs directory keeps the Redoc
+// page.
+func serveEmbeddedSwaggerUI(content, moduleName string) string {
+	content = strings.Replace(content, "\t\"github.com/gorilla/mux\"\n",
+		"\t\"github.com/gorilla/mux\"\n\n\t\""+getImportPath(moduleName)+"/internal/assets\"\n", 1)
+	content = strings.Replace(content, "//\tGET /docs/             the Swagger UI\n",
+		"//\tGET /docs/             the Swagger UI of internal/assets\n", 1)
+	content = strings.Replace(content, docsPagesComment, embeddedDocsPagesComment, 1)
+	content = strings.Replace(content, fmt.Sprintf(docsSwaggerUIGenerate, swaggerUIVersion), "", 1)
+	return strings.Replace(content, docsPagesHandler, embeddedDocsPagesHandler, 1)
+}
+
+// mergeOpenAPIDocument adds the paths and component schemas of an entity's
+// OpenAPI document to an existing one, replacing those of the same name, and
+// titles the result after the project.
+func mergeOpenAPIDocument(existing []byte, entityDoc, project string) (string, error) {
+	var base, add yaml.Node
+	if err := yaml.Unmarshal(existing, &base); err != nil {
+		return "", err
+	}
+	if err := yaml.Unmarshal([]byte(entityDoc), &add); err != nil {
+		return "", err
+	}
+	if len(base.Content) == 0 {
+		return entityDoc, nil
+	}
+	root := base.Content[0]
+	for _, keys := range [][]string{{"paths"}, {"components", "schemas"}} {
+		from := add.Content[0]
+		for _, key := range keys {
+			if i := findMappingKey(from, key); i >= 0 {
+				from = from.Content[i+1]
+			}
+		}
+		to := root
+		for _, key := range keys {
+			child, err := mappingChild(to, key, yaml.MappingNode)
+			if err != nil {
+				return "", err
+			}
+			to = child
+		}
+		for i := 0; i+1 < len(from.Content); i += 2 {
+			if j := findMappingKey(to, from.Content[i].Value); j >= 0 {
+				to.Content[j+1] = from.Content[i+1]
+			} else {
+				to.Content = append(to.Content, from.Content[i], from.Content[i+1])
+			}
+		}
+	}
+	if info, err := mappingChild(root, "info", yaml.MappingNode); err == nil && project != "" {
+		setMappingScalar(info, "title", project+" API", "")
+		setMappingScalar(info, "description", "API for managing the "+project+" entities", "")
+	}
+
+	var buf strings.Builder
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&base); err != nil {
+		return "", err
+	}
+	_ = enc.Close()
+	return buf.String(), nil
+}
+
+// openAPIProjectName returns the project name a merged OpenAPI document is
+// titled after.
+func openAPIProjectName() string {
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	return ci.GetProjectConfig().Name
+}
+
+// wireDocsIntoMainGo registers apphttp.SetupDocsRoutes on the root router of
+// main.go. It is idempotent and leaves main.go alone when the router anchor
+// is missing.
+func wireDocsIntoMainGo(sm ...*SafetyManager) {
+	mainPath, found := findMainGoPath()
+	if !found {
+		ui.Dim("   Register the docs endpoint in main.go: apphttp.SetupDocsRoutes(router)")
+		return
+	}
+	defer lockSharedFile(mainPath)()
+
+	raw, err := os.ReadFile(mainPath)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not read main.go: %v", err))
+		return
+	}
+	content := string(raw)
+
+	call := "apphttp.SetupDocsRoutes(router)"
+	if strings.Contains(content, call) {
+		return
+	}
+
+	anchor := "\trouter := mux.NewRouter()\n"
+	if !strings.Contains(content, anchor) {
+		ui.Dim("   Register the docs endpoint in main.go: apphttp.SetupDocsRoutes(router)")
+		return
+	}
+
+	moduleName := getModuleName()
+	if moduleName == "" {
+		ui.Warning("Could not determine module name from go.mod")
+		return
+	}
+
+	content = ensureMainGoImport(content, fmt.Sprintf("apphttp \"%s/internal/handler/http\"", moduleName))
+	content = strings.Replace(content, anchor, anchor+
+		"\n\t// API documentation (/docs); disabled in production unless DOCS_ENABLED=true\n"+
+		"\t"+call+"\n", 1)
+
+	if err := writeGoFileMerged(mainPath, content, sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Could not register docs endpoint in main.go: %v", err))
+		return
+	}
+	ui.Dim("   Docs endpoint registered at /docs")
+}
+
+// docsPagesComment documents the docs directory of docsEndpointTemplate;
+// embeddedDocsPagesComment replaces it for --embed-assets projects.
+const docsPagesComment = `// docsPages holds the Swagger UI and Redoc pages and the bundles they load,
+// so /docs works offline. go generate downloads the bundles again; the pages
+// load missing ones from the CDN.
+`
+
+const embeddedDocsPagesComment = `// docsPages holds the Redoc page and the bundle it loads, so /docs/redoc.html
+// works offline. go generate downloads the bundle again; the page loads it
+// from the CDN when it is missing.
+`
+
+// docsSwaggerUIGenerate vendors the Swagger UI bundle into the docs
+// directory; --embed-assets projects vendor it into internal/assets instead.
+const docsSwaggerUIGenerate = `//go:generate curl -fsSL -o docs/swagger-ui.css https://cdn.jsdelivr.net/npm/swagger-ui-dist@%[1]s/swagger-ui.css
+//go:generate curl -fsSL -o docs/swagger-ui-bundle.js https://cdn.jsdelivr.net/npm/swagger-ui-dist@%[1]s/swagger-ui-bundle.js
+`
+
+// docsPagesHandler serves the docs directory of docsEndpointTemplate;
+// embeddedDocsPagesHandler replaces it for --embed-assets projects.
+const docsPagesHandler = `	router.PathPrefix("/docs/").Handler(http.StripPrefix("/docs/", http.FileServer(http.FS(pages)))).Methods("GET")
+`
+
+const embeddedDocsPagesHandler = `	redoc := http.StripPrefix("/docs/", http.FileServer(http.FS(pages)))
+	router.Handle("/docs/redoc.html", redoc).Methods("GET")
+	router.Handle("/docs/redoc.standalone.js", redoc).Methods("GET")
+	router.PathPrefix("/docs/").Handler(http.StripPrefix("/docs/", assets.SwaggerUIHandler())).Methods("GET")
+`
+
+const docsEndpointTemplate = `package http
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// openAPISpec is the generated OpenAPI document, compiled into the binary so
+// the docs endpoint works without the source tree.
+//
+//go:embed swagger.yaml
+var openAPISpec []byte
+
+` + docsPagesComment + `//
+//go:embed docs
+var docsPages embed.FS
+
+` + docsSwaggerUIGenerate + `//go:generate curl -fsSL -o docs/redoc.standalone.js https://cdn.jsdelivr.net/npm/redoc@%[2]s/bundles/redoc.standalone.js
+
+// DocsEnabled reports whether the /docs endpoint is served. DOCS_ENABLED
+// (true/false) takes precedence; otherwise docs are on in every environment
+// except ENVIRONMENT=production.
+func DocsEnabled() bool {
+	if v, ok := os.LookupEnv("DOCS_ENABLED"); ok {
+		enabled, err := strconv.ParseBool(v)
+		return err == nil && enabled
+	}
+	return os.Getenv("ENVIRONMENT") != "production"
+}
+
+// SetupDocsRoutes serves the API documentation:
+//
+//	GET /docs              redirect to the Swagger UI (or Redoc when DOCS_UI=redoc)
+//	GET /docs/             the Swagger UI
+//	GET /docs/redoc.html   the Redoc page
+//	GET /docs/openapi.yaml the embedded OpenAPI document
+func SetupDocsRoutes(router *mux.Router) {
+	if !DocsEnabled() {
+		return
+	}
+
+	router.HandleFunc("/docs/openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write(openAPISpec)
+	}).Methods("GET")
+
+	router.HandleFunc("/docs", func(w http.ResponseWriter, r *http.Request) {
+		page := "/docs/"
+		if strings.EqualFold(os.Getenv("DOCS_UI"), "redoc") {
+			page = "/docs/redoc.html"
+		}
+		http.Redirect(w, r, page, http.StatusFound)
+	}).Methods("GET")
+
+	pages, err := fs.Sub(docsPages, "docs")
+	if err != nil {
+		panic(err) // docs is embedded
+	}
+` + docsPagesHandler + `}
+`
+
+const redocIndexTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>API Documentation</title>
+</head>
+<body>
+  <main role="main" aria-label="API documentation">
+    <redoc spec-url="openapi.yaml"></redoc>
+  </main>
+  <noscript>JavaScript is required to browse the docs. The raw document is at <a href="openapi.yaml">openapi.yaml</a>.</noscript>
+  <script src="redoc.standalone.js"></script>
+  <script>
+    // Load the bundle from the CDN when it is not vendored next to this page.
+    if (!window.Redoc) {
+      document.write('<script src="https://cdn.jsdelivr.net/npm/redoc@{{version}}/bundles/redoc.standalone.js"><\/script>');
+    }
+  </script>
+</body>
+</html>
+`
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	// Generated docs endpoints vendor their UI bundles from this server
+	// instead of the CDN.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "/* %s */\n", r.URL.Path)
+	}))
+	docsBundleCDN = server.URL
+	code := m.Run()
+	server.Close()
+	os.Exit(code)
+}
+
+func TestGenerateDocsEndpoint(t *testing.T) {
+	cleanup := ensureTestUI(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(dir)
+
+	require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0o644))
+	mainPath := filepath.Join("cmd", "server", "main.go")
+	require.NoError(t, os.MkdirAll(filepath.Dir(mainPath), 0o755))
+	mainSrc := "package main\n\nimport (\n\t\"github.com/gorilla/mux\"\n)\n\nfunc main() {\n\trouter := mux.NewRouter()\n\t_ = router\n}\n"
+	require.NoError(t, os.WriteFile(mainPath, []byte(mainSrc), 0o644))
+
+	handlerDir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	sm := NewSafetyManager(false, true, false)
+	generateDocsEndpoint(handlerDir, sm)
+
+	content, err := os.ReadFile(filepath.Join(handlerDir, "docs.go"))
+	require.NoError(t, err)
+	src := string(content)
+
+	_, err = parser.ParseFile(token.NewFileSet(), "docs.go", src, parser.ParseComments)
+	require.NoError(t, err)
+	assert.Contains(t, src, "//go:embed swagger.yaml")
+	assert.Contains(t, src, `router.HandleFunc("/docs/openapi.yaml"`)
+	assert.Contains(t, src, `os.LookupEnv("DOCS_ENABLED")`)
+	assert.Contains(t, src, "swagger-ui-dist@"+swaggerUIVersion)
+	assert.Contains(t, src, "redoc@"+redocVersion)
+	for _, name := range []string{"index.html", "redoc.html", "swagger-ui.css", "swagger-ui-bundle.js", "redoc.standalone.js"} {
+		assert.FileExists(t, filepath.Join(handlerDir, "docs", name))
+	}
+
+	// main.go gains the import and the route registration exactly once.
+	generateDocsEndpoint(handlerDir, sm)
+	mainContent, err := os.ReadFile(mainPath)
+	require.NoError(t, err)
+	main := string(mainContent)
+	assert.Contains(t, main, `apphttp "testproject/internal/handler/http"`)
+	assert.Equal(t, 1, strings.Count(main, "apphttp.SetupDocsRoutes(router)"))
+}
+
+func TestGenerateHTTPHandler_SwaggerGeneratesDocsEndpoint(t *testing.T) {
+	cleanup := ensureTestUI(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(dir)
+
+	require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0o644))
+
+	generateHTTPHandler("Product", false, false, false, "", NewSafetyManager(false, true, false))
+	_, err := os.Stat(filepath.Join(DirInternal, DirHandler, DirHTTP, "docs.go"))
+	assert.True(t, os.IsNotExist(err), "docs endpoint must only be generated with swagger")
+
+	generateHTTPHandler("Product", false, false, true, "", NewSafetyManager(false, true, false))
+	_, err = os.Stat(filepath.Join(DirInternal, DirHandler, DirHTTP, "docs.go"))
+	assert.NoError(t, err)
+}
+
+func TestGenerateDocsEndpoint_KeepsExistingFile(t *testing.T) {
+	cleanup := ensureTestUI(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(dir)
+
+	require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0o644))
+	handlerDir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	require.NoError(t, os.MkdirAll(handlerDir, 0o755))
+	custom := "package http\n\n// customised\n"
+	require.NoError(t, os.WriteFile(filepath.Join(handlerDir, "docs.go"), []byte(custom), 0o644))
+
+	// A second handler must not fail on (or clobber) the shared docs.go.
+	sm := NewSafetyManager(false, false, false)
+	generateDocsEndpoint(handlerDir, sm)
+	content, err := os.ReadFile(filepath.Join(handlerDir, "docs.go"))
+	require.NoError(t, err)
+	assert.Equal(t, custom, string(content))
+}
+
+func TestGenerateSwaggerFile_MergesFeatures(t *testing.T) {
+	defer setupDiscardUI(t)()
+	chdirTemp(t)
+	writeTestFile(t, ".", "go.mod", "module example.com/shop\n\ngo 1.21\n\nrequire github.com/gorilla/mux v1.8.1\n")
+	writeTestFile(t, ".", "go.sum", "github.com/gorilla/mux v1.8.1 h1:TuBL49tXwgrFYWhqrNgrUNEY92u81SPhu7sTdzQEiWY=\n"+
+		"github.com/gorilla/mux v1.8.1/go.mod h1:AKf9I4AEqPTmMytcMc0KkNouC66V3BtZ4qD5fmWSiMQ=\n")
+	writeTestFile(t, ".", ".goca.yaml", "project:\n  name: shop\n  module: example.com/shop\n")
+
+	handlerDir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	sm := NewSafetyManager(false, false, false)
+	for _, entity := range []string{"Product", "Order", "Product"} {
+		generateSwaggerFile(handlerDir, entity, sm)
+		generateDocsEndpoint(handlerDir, sm)
+	}
+
+	content, err := os.ReadFile(filepath.Join(handlerDir, "swagger.yaml"))
+	require.NoError(t, err)
+	spec := string(content)
+	assert.Contains(t, spec, "  title: shop API\n")
+	for _, want := range []string{"  /products:\n", "  /products/{id}:\n", "  /orders:\n", "  /orders/{id}:\n", "    Product:\n", "    CreateOrderRequest:\n"} {
+		assert.Equal(t, 1, strings.Count(spec, want), want)
+	}
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	writeTestFile(t, ".", "cmd/check/main.go", `package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	apphttp "example.com/shop/internal/handler/http"
+)
+
+func main() {
+	router := mux.NewRouter()
+	apphttp.SetupDocsRoutes(router)
+	get := func(path string) (int, string) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code, rec.Body.String()
+	}
+	_, spec := get("/docs/openapi.yaml")
+	docs, _ := get("/docs")
+	_, page := get("/docs/")
+	bundle, _ := get("/docs/swagger-ui-bundle.js")
+	fmt.Printf("%t|%t|%d|%t|%d", strings.Contains(spec, "/products:"), strings.Contains(spec, "/orders:"),
+		docs, strings.Contains(page, "SwaggerUIBundle"), bundle)
+}
+`)
+	run := exec.Command("go", "run", "./cmd/check")
+	run.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+	out, err := run.CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.Equal(t, "true|true|302|true|200", string(out))
+}
@@ -13,29 +13,28 @@ func TestGenerateCreateHandlerMethod(t *testing.T) {
 	t.Run("without validation", func(t *testing.T) {
 		t.Parallel()
 		var b strings.Builder
-		generateCreateHandlerMethod(&b, "Product", "ProductHandler", false, false)
+		generateCreateHandlerMethod(&b, "Product", "ProductHandler", false, false, false, ResponseFormatRaw)
 		output := b.String()
 		assert.Contains(t, output, "func (p *ProductHandler) CreateProduct(")
 		assert.Contains(t, output, "CreateProductInput")
 		assert.Contains(t, output, "json.NewDecoder")
 		assert.Contains(t, output, "StatusCreated")
-		assert.NotContains(t, output, "validator.New()")
+		assert.NotContains(t, output, "validateRequest")
 	})
 
 	t.Run("with validation", func(t *testing.T) {
 		t.Parallel()
 		var b strings.Builder
-		generateCreateHandlerMethod(&b, "Product", "ProductHandler", true, false)
+		generateCreateHandlerMethod(&b, "Product", "ProductHandler", true, false, false, ResponseFormatRaw)
 		output := b.String()
-		assert.Contains(t, output, "validator.New().Struct(input)")
-		assert.Contains(t, output, "StatusUnprocessableEntity")
+		assert.Contains(t, output, "if !validateRequest(w, r, &input) {\n\t\treturn\n\t}")
 	})
 }
 
 func TestGenerateGetHandlerMethod(t *testing.T) {
 	t.Parallel()
 	var b strings.Builder
-	generateGetHandlerMethod(&b, "Product", "ProductHandler", false)
+	generateGetHandlerMethod(&b, "Product", "ProductHandler", false, false, false, ResponseFormatRaw)
 	output := b.String()
 	assert.Contains(t, output, "func (p *ProductHandler) GetProduct(")
 	assert.Contains(t, output, "mux.Vars(r)")
@@ -50,27 +49,27 @@ func TestGenerateUpdateHandlerMethod(t *testing.T) {
 	t.Run("without validation", func(t *testing.T) {
 		t.Parallel()
 		var b strings.Builder
-		generateUpdateHandlerMethod(&b, "Product", "ProductHandler", false, false)
+		generateUpdateHandlerMethod(&b, "Product", "ProductHandler", false, false, false, false, ResponseFormatRaw)
 		output := b.String()
 		assert.Contains(t, output, "func (p *ProductHandler) UpdateProduct(")
 		assert.Contains(t, output, "UpdateProductInput")
 		assert.Contains(t, output, "StatusNoContent")
-		assert.NotContains(t, output, "validator.New()")
+		assert.NotContains(t, output, "validateRequest")
 	})
 
 	t.Run("with validation", func(t *testing.T) {
 		t.Parallel()
 		var b strings.Builder
-		generateUpdateHandlerMethod(&b, "Product", "ProductHandler", true, false)
+		generateUpdateHandlerMethod(&b, "Product", "ProductHandler", true, false, false, false, ResponseFormatRaw)
 		output := b.String()
-		assert.Contains(t, output, "validator.New().Struct(input)")
+		assert.Contains(t, output, "if !validateRequest(w, r, &input) {\n\t\treturn\n\t}")
 	})
 }
 
 func TestGenerateDeleteHandlerMethod(t *testing.T) {
 	t.Parallel()
 	var b strings.Builder
-	generateDeleteHandlerMethod(&b, "Product", "ProductHandler", false)
+	generateDeleteHandlerMethod(&b, "Product", "ProductHandler", false, false, ResponseFormatRaw)
 	output := b.String()
 	assert.Contains(t, output, "func (p *ProductHandler) DeleteProduct(")
 	assert.Contains(t, output, "mux.Vars(r)")
@@ -80,7 +79,7 @@ func TestGenerateDeleteHandlerMethod(t *testing.T) {
 func TestGenerateListHandlerMethod(t *testing.T) {
 	t.Parallel()
 	var b strings.Builder
-	generateListHandlerMethod(&b, "Product", "ProductHandler", false)
+	generateListHandlerMethod(&b, "Product", "ProductHandler", false, false, ResponseFormatRaw)
 	output := b.String()
 	assert.Contains(t, output, "func (p *ProductHandler) ListProducts(")
 	assert.Contains(t, output, "ListProducts()")
@@ -94,7 +93,7 @@ func TestHandlerMethods_SwaggerAnnotations(t *testing.T) {
 	t.Run("create has annotations", func(t *testing.T) {
 		t.Parallel()
 		var b strings.Builder
-		generateCreateHandlerMethod(&b, "Product", "ProductHandler", false, true)
+		generateCreateHandlerMethod(&b, "Product", "ProductHandler", false, true, false, ResponseFormatRaw)
 		out := b.String()
 		assert.Contains(t, out, "@Summary Create product")
 		assert.Contains(t, out, "@Router /products [post]")
@@ -105,7 +104,7 @@ func TestHandlerMethods_SwaggerAnnotations(t *testing.T) {
 	t.Run("get has path param and router", func(t *testing.T) {
 		t.Parallel()
 		var b strings.Builder
-		generateGetHandlerMethod(&b, "Product", "ProductHandler", true)
+		generateGetHandlerMethod(&b, "Product", "ProductHandler", true, false, false, ResponseFormatRaw)
 		out := b.String()
 		assert.Contains(t, out, "@Router /products/{id} [get]")
 		assert.Contains(t, out, "@Param id path int true")
@@ -114,7 +113,7 @@ func TestHandlerMethods_SwaggerAnnotations(t *testing.T) {
 	t.Run("no annotations when swagger disabled", func(t *testing.T) {
 		t.Parallel()
 		var b strings.Builder
-		generateCreateHandlerMethod(&b, "Product", "ProductHandler", false, false)
+		generateCreateHandlerMethod(&b, "Product", "ProductHandler", false, false, false, ResponseFormatRaw)
 		assert.NotContains(t, b.String(), "@Summary")
 	})
 }
@@ -7,14 +7,45 @@ import (
 	"strings"
 )
 
-func generateGRPCHandler(entity, fileNamingConvention string, sm ...*SafetyManager) {
+// generateGRPCHandler writes the .proto, server, DTO mappings and placeholder
+// protobuf package of an entity. With gateway the .proto carries
+// google.api.http annotations and a grpc-gateway registration serves its REST
+// surface.
+func generateGRPCHandler(entity, fileNamingConvention string, gateway bool, sm ...*SafetyManager) {
 	// Create gRPC directory
 	grpcDir := filepath.Join(DirInternal, DirHandler, DirGRPC)
 	_ = os.MkdirAll(grpcDir, 0o755)
 
-	generateProtoFile(grpcDir, entity, fileNamingConvention, sm...)
+	generateProtoFile(grpcDir, entity, fileNamingConvention, gateway, sm...)
 	generateGRPCServerFile(grpcDir, entity, fileNamingConvention, sm...)
-	generateGRPCStubPackage(grpcDir, entity, sm...)
+	generateGRPCMappingFile(grpcDir, entity, fileNamingConvention, sm...)
+	generateGRPCStubPackage(grpcDir, entity, gateway, sm...)
+	if gateway {
+		generateGRPCGatewayFile(grpcDir, entity, fileNamingConvention, sm...)
+	}
+}
+
+// grpcFilename returns the path of a gRPC file of entity following the file
+// naming convention, e.g. order_server.go or order-server.go.
+func grpcFilename(dir, entity, suffix, fileNamingConvention string) string {
+	switch fileNamingConvention {
+	case "snake_case":
+		return filepath.Join(dir, toSnakeCase(entity)+"_"+suffix+".go")
+	case "kebab-case":
+		return filepath.Join(dir, toKebabCase(entity)+"-"+suffix+".go")
+	default:
+		return filepath.Join(dir, strings.ToLower(entity)+"_"+suffix+".go")
+	}
+}
+
+// protocCommand is the protoc invocation generating the code of an entity's
+// .proto, as documented in the generated files.
+func protocCommand(entity string, gateway bool) string {
+	command := "protoc --go_out=. --go-grpc_out=."
+	if gateway {
+		command = "protoc -I . -I third_party/googleapis --go_out=. --go-grpc_out=. --grpc-gateway_out=."
+	}
+	return fmt.Sprintf("%s internal/handler/grpc/%s.proto", command, strings.ToLower(entity))
 }
 
 // generateGRPCStubPackage writes a placeholder protobuf package so a freshly
@@ -23,12 +54,21 @@ func generateGRPCHandler(entity, fileNamingConvention string, sm ...*SafetyManag
 // gated behind the "proto" build tag, so the default build ignores it; building
 // with -tags proto compiles the scaffold against these stubs. Once the real
 // *.pb.go files are produced with protoc, this file should be deleted.
-func generateGRPCStubPackage(grpcDir, entity string, sm ...*SafetyManager) {
+func generateGRPCStubPackage(grpcDir, entity string, gateway bool, sm ...*SafetyManager) {
 	entityLower := strings.ToLower(entity)
 	pkgDir := filepath.Join(grpcDir, entityLower)
 	_ = os.MkdirAll(pkgDir, 0o755)
 
-	fields := grpcEntityFields(entity)
+	entityFields := grpcEntityFields(entity)
+	writeFields := func(c *strings.Builder, fields []Field, optional bool) {
+		for _, f := range fields {
+			goType := protoGoType(f.Type)
+			if optional {
+				goType = "*" + goType
+			}
+			fmt.Fprintf(c, "\t%s %s\n", protoGoFieldName(f.Name), goType)
+		}
+	}
 
 	var c strings.Builder
 	c.WriteString("//go:build proto\n")
@@ -37,24 +77,24 @@ func generateGRPCStubPackage(grpcDir, entity string, sm ...*SafetyManager) {
 	c.WriteString("// It exists so the gRPC server scaffold compiles (under -tags proto) and so\n")
 	c.WriteString("// `go mod tidy`/`go vet` resolve the import locally instead of attempting a\n")
 	c.WriteString("// remote module lookup. Generate the real code with protoc, e.g.:\n//\n")
-	fmt.Fprintf(&c, "//\tprotoc --go_out=. --go-grpc_out=. internal/handler/grpc/%s.proto\n//\n", entityLower)
+	fmt.Fprintf(&c, "//\t%s\n//\n", protocCommand(entity, gateway))
 	c.WriteString("// then DELETE this placeholder file (its types would collide with the\n")
 	c.WriteString("// generated ones).\n")
 	fmt.Fprintf(&c, "package %s\n\n", entityLower)
+	if gateway {
+		c.WriteString("import (\n\t\"context\"\n\n\t\"github.com/grpc-ecosystem/grpc-gateway/v2/runtime\"\n)\n\n")
+	}
 
+	fmt.Fprintf(&c, "type %sServiceServer interface{}\n\n", entity)
 	fmt.Fprintf(&c, "type Unimplemented%sServiceServer struct{}\n\n", entity)
 
 	fmt.Fprintf(&c, "type %s struct {\n", entity)
 	c.WriteString("\tId int32\n")
-	for _, f := range fields {
-		fmt.Fprintf(&c, "\t%s %s\n", protoGoFieldName(f.Name), f.Type)
-	}
+	writeFields(&c, entityFields, false)
 	c.WriteString("}\n\n")
 
 	fmt.Fprintf(&c, "type Create%sRequest struct {\n", entity)
-	for _, f := range fields {
-		fmt.Fprintf(&c, "\t%s %s\n", protoGoFieldName(f.Name), f.Type)
-	}
+	writeFields(&c, withoutComputedFields(entityFields), false)
 	c.WriteString("}\n\n")
 
 	fmt.Fprintf(&c, "type Create%sResponse struct {\n", entity)
@@ -66,16 +106,39 @@ func generateGRPCStubPackage(grpcDir, entity string, sm ...*SafetyManager) {
 
 	fmt.Fprintf(&c, "type %sResponse struct {\n", entity)
 	fmt.Fprintf(&c, "\t%s *%s\n", entity, entity)
+	c.WriteString("}\n\n")
+
+	fmt.Fprintf(&c, "type Update%sRequest struct {\n", entity)
+	c.WriteString("\tId int32\n")
+	writeFields(&c, withoutComputedFields(entityFields), true)
+	c.WriteString("}\n\n")
+
+	fmt.Fprintf(&c, "type Update%sResponse struct {\n\tMessage string\n}\n\n", entity)
+	fmt.Fprintf(&c, "type Delete%sRequest struct {\n\tId int32\n}\n\n", entity)
+	fmt.Fprintf(&c, "type Delete%sResponse struct {\n\tMessage string\n}\n\n", entity)
+	fmt.Fprintf(&c, "type List%sRequest struct{}\n\n", toPlural(entity))
+
+	fmt.Fprintf(&c, "type List%sResponse struct {\n", toPlural(entity))
+	fmt.Fprintf(&c, "\t%s []*%s\n", toPlural(entity), entity)
+	c.WriteString("\tTotal int32\n")
 	c.WriteString("}\n")
 
+	if gateway {
+		fmt.Fprintf(&c, "\nfunc Register%sServiceHandlerServer(ctx context.Context, mux *runtime.ServeMux, server %sServiceServer) error {\n", entity, entity)
+		c.WriteString("\treturn nil\n}\n")
+	}
+
 	filename := filepath.Join(pkgDir, "placeholder.pb.go")
 	if err := writeGoFile(filename, c.String(), sm...); err != nil {
 		ui.Error(fmt.Sprintf("Error writing grpc stub package: %v", err))
 	}
 }
 
-func generateProtoFile(dir, entity, fileNamingConvention string, sm ...*SafetyManager) {
+func generateProtoFile(dir, entity, fileNamingConvention string, gateway bool, sm ...*SafetyManager) {
 	entityLower := strings.ToLower(entity)
+	// protoc-gen-go turns snake_case field names back into the PascalCase
+	// names the server refers to.
+	entityField := toSnakeCase(entity)
 
 	// Apply naming convention to filename
 	var filename string
@@ -90,40 +153,58 @@ func generateProtoFile(dir, entity, fileNamingConvention string, sm ...*SafetyMa
 	var content strings.Builder
 	content.WriteString("syntax = \"proto3\";\n\n")
 	content.WriteString(fmt.Sprintf("package %s;\n\n", entityLower))
+	if gateway {
+		content.WriteString("import \"google/api/annotations.proto\";\n\n")
+	}
 	content.WriteString(fmt.Sprintf("option go_package = \"./%s\";\n\n", entityLower))
 
+	// The gateway serves the REST routes the HTTP handler would, under /api/v1.
+	collection := "/api/v1" + defaultHTTPRoutes(entity).Path
+	rpc := func(signature, method, path string, body bool) {
+		if !gateway {
+			fmt.Fprintf(&content, "  rpc %s;\n", signature)
+			return
+		}
+		fmt.Fprintf(&content, "  rpc %s {\n", signature)
+		content.WriteString("    option (google.api.http) = {\n")
+		fmt.Fprintf(&content, "      %s: \"%s\"\n", method, path)
+		if body {
+			content.WriteString("      body: \"*\"\n")
+		}
+		content.WriteString("    };\n  }\n")
+	}
+
 	content.WriteString(fmt.Sprintf("service %sService {\n", entity))
-	content.WriteString(fmt.Sprintf("  rpc Create%s(Create%sRequest) returns (Create%sResponse);\n", entity, entity, entity))
-	content.WriteString(fmt.Sprintf("  rpc Get%s(Get%sRequest) returns (%sResponse);\n", entity, entity, entity))
-	content.WriteString(fmt.Sprintf("  rpc Update%s(Update%sRequest) returns (Update%sResponse);\n", entity, entity, entity))
-	content.WriteString(fmt.Sprintf("  rpc Delete%s(Delete%sRequest) returns (Delete%sResponse);\n", entity, entity, entity))
-	content.WriteString(fmt.Sprintf("  rpc List%ss(List%ssRequest) returns (List%ssResponse);\n", entity, entity, entity))
+	rpc(fmt.Sprintf("Create%s(Create%sRequest) returns (Create%sResponse)", entity, entity, entity), "post", collection, true)
+	rpc(fmt.Sprintf("Get%s(Get%sRequest) returns (%sResponse)", entity, entity, entity), "get", collection+"/{id}", false)
+	rpc(fmt.Sprintf("Update%s(Update%sRequest) returns (Update%sResponse)", entity, entity, entity), "put", collection+"/{id}", true)
+	rpc(fmt.Sprintf("Delete%s(Delete%sRequest) returns (Delete%sResponse)", entity, entity, entity), "delete", collection+"/{id}", false)
+	rpc(fmt.Sprintf("List%s(List%sRequest) returns (List%sResponse)", toPlural(entity), toPlural(entity), toPlural(entity)), "get", collection, false)
 	content.WriteString("}\n\n")
 
 	// Derive the proto fields from the real entity definition so the message
 	// shape matches the usecase output DTO. Fall back to id/name/email when the
 	// entity cannot be read.
+	// Requests leave out the computed fields.
 	entityFields := grpcEntityFields(entity)
+	writeFields := func(fields []Field, idx int, label string) {
+		for _, f := range fields {
+			fmt.Fprintf(&content, "  %s%s %s = %d;\n", label, protoType(f.Type), toSnakeCase(f.Name), idx)
+			idx++
+		}
+	}
 
 	content.WriteString(fmt.Sprintf("message %s {\n", entity))
 	content.WriteString("  int32 id = 1;\n")
-	idx := 2
-	for _, f := range entityFields {
-		fmt.Fprintf(&content, "  %s %s = %d;\n", protoType(f.Type), toSnakeCase(f.Name), idx)
-		idx++
-	}
+	writeFields(entityFields, 2, "")
 	content.WriteString("}\n\n")
 
 	content.WriteString(fmt.Sprintf("message Create%sRequest {\n", entity))
-	idx = 1
-	for _, f := range entityFields {
-		fmt.Fprintf(&content, "  %s %s = %d;\n", protoType(f.Type), toSnakeCase(f.Name), idx)
-		idx++
-	}
+	writeFields(withoutComputedFields(entityFields), 1, "")
 	content.WriteString("}\n\n")
 
 	content.WriteString(fmt.Sprintf("message Create%sResponse {\n", entity))
-	content.WriteString(fmt.Sprintf("  %s %s = 1;\n", entity, entityLower))
+	content.WriteString(fmt.Sprintf("  %s %s = 1;\n", entity, entityField))
 	content.WriteString("  string message = 2;\n")
 	content.WriteString("}\n\n")
 
@@ -132,13 +213,13 @@ func generateProtoFile(dir, entity, fileNamingConvention string, sm ...*SafetyMa
 	content.WriteString("}\n\n")
 
 	content.WriteString(fmt.Sprintf("message %sResponse {\n", entity))
-	content.WriteString(fmt.Sprintf("  %s %s = 1;\n", entity, entityLower))
+	content.WriteString(fmt.Sprintf("  %s %s = 1;\n", entity, entityField))
 	content.WriteString("}\n\n")
 
+	// Update fields are optional: an unset field leaves the entity unchanged.
 	content.WriteString(fmt.Sprintf("message Update%sRequest {\n", entity))
 	content.WriteString("  int32 id = 1;\n")
-	content.WriteString("  string name = 2;\n")
-	content.WriteString("  string email = 3;\n")
+	writeFields(withoutComputedFields(entityFields), 2, "optional ")
 	content.WriteString("}\n\n")
 
 	content.WriteString(fmt.Sprintf("message Update%sResponse {\n", entity))
@@ -153,11 +234,11 @@ func generateProtoFile(dir, entity, fileNamingConvention string, sm ...*SafetyMa
 	content.WriteString("  string message = 1;\n")
 	content.WriteString("}\n\n")
 
-	content.WriteString(fmt.Sprintf("message List%ssRequest {\n", entity))
+	content.WriteString(fmt.Sprintf("message List%sRequest {\n", toPlural(entity)))
 	content.WriteString("}\n\n")
 
-	content.WriteString(fmt.Sprintf("message List%ssResponse {\n", entity))
-	content.WriteString(fmt.Sprintf("  repeated %s %ss = 1;\n", entity, entityLower))
+	content.WriteString(fmt.Sprintf("message List%sResponse {\n", toPlural(entity)))
+	content.WriteString(fmt.Sprintf("  repeated %s %s = 1;\n", entity, toPlural(entityField)))
 	content.WriteString("  int32 total = 2;\n")
 	content.WriteString("}\n")
 
@@ -173,16 +254,17 @@ func generateGRPCServerFile(dir, entity, fileNamingConvention string, sm ...*Saf
 	importPath := getImportPath(moduleName)
 
 	entityLower := strings.ToLower(entity)
+	filename := grpcFilename(dir, entity, "server", fileNamingConvention)
 
-	// Apply naming convention to filename
-	var filename string
-	if fileNamingConvention == "snake_case" {
-		filename = filepath.Join(dir, toSnakeCase(entity)+"_server.go")
-	} else if fileNamingConvention == "kebab-case" {
-		filename = filepath.Join(dir, toKebabCase(entity)+"-server.go")
-	} else {
-		filename = filepath.Join(dir, entityLower+"_server.go")
+	toProto := strings.ToLower(entity[:1]) + entity[1:] + "ToProto"
+
+	// Only the operations the use case declares get an RPC implementation; the
+	// others answer codes.Unimplemented.
+	ops := make(map[string]bool)
+	for _, op := range useCaseOperations(entity) {
+		ops[op] = true
 	}
+	presented, presentedCreate := grpcPresenter(entity)
 
 	var content strings.Builder
 	// This server depends on protobuf-generated code that must be produced with
@@ -198,6 +280,10 @@ func generateGRPCServerFile(dir, entity, fileNamingConvention string, sm ...*Saf
 	content.WriteString("package grpc\n\n")
 	content.WriteString("import (\n")
 	content.WriteString("\t\"context\"\n\n")
+	if ops[OpRead] {
+		content.WriteString("\t\"google.golang.org/grpc/codes\"\n")
+		content.WriteString("\t\"google.golang.org/grpc/status\"\n\n")
+	}
 	content.WriteString(fmt.Sprintf("\t\"%s/internal/usecase\"\n", importPath))
 	content.WriteString(fmt.Sprintf("\tpb \"%s/internal/handler/grpc/%s\"\n", importPath, entityLower))
 	content.WriteString(")\n\n")
@@ -209,66 +295,196 @@ func generateGRPCServerFile(dir, entity, fileNamingConvention string, sm ...*Saf
 
 	content.WriteString(fmt.Sprintf("func New%sServer(uc usecase.%sUseCase) *%sServer {\n", entity, entity, entity))
 	content.WriteString(fmt.Sprintf("\treturn &%sServer{usecase: uc}\n", entity))
-	content.WriteString("}\n\n")
-
-	// The usecase output DTOs are FLAT (output.ID, output.Name, ...), so the
-	// gRPC server maps flat output fields onto the nested protobuf message.
-	entityFields := grpcEntityFields(entity)
+	content.WriteString("}\n")
 
-	content.WriteString(fmt.Sprintf("func (s *%sServer) Create%s(ctx context.Context, req *pb.Create%sRequest) (*pb.Create%sResponse, error) {\n", entity, entity, entity, entity))
-	content.WriteString(fmt.Sprintf("\tinput := usecase.Create%sInput{\n", entity))
-	for _, f := range entityFields {
-		fmt.Fprintf(&content, "\t\t%s: req.%s,\n", f.Name, protoGoFieldName(f.Name))
+	// The messages are mapped by the functions of the mapping file, which the
+	// REST gateway shares through this server.
+	if ops[OpCreate] {
+		input, created := fmt.Sprintf("create%sInput(req)", entity), fmt.Sprintf("created%sToProto(output)", entity)
+		if presentedCreate {
+			input = fmt.Sprintf("%sFromCreateRequest(req).CreateInput()", strings.ToLower(entity[:1])+entity[1:])
+			created = fmt.Sprintf("%s(presenter.NewCreated%s(output).%s)", toProto, entity, entity)
+		}
+		fmt.Fprintf(&content, "\nfunc (s *%sServer) Create%s(ctx context.Context, req *pb.Create%sRequest) (*pb.Create%sResponse, error) {\n", entity, entity, entity, entity)
+		fmt.Fprintf(&content, "\toutput, err := s.usecase.Create%s(%s)\n", entity, input)
+		content.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n\n")
+		fmt.Fprintf(&content, "\treturn &pb.Create%sResponse{\n", entity)
+		fmt.Fprintf(&content, "\t\t%s: %s,\n", entity, created)
+		content.WriteString("\t\tMessage: output.Message,\n")
+		content.WriteString("\t}, nil\n}\n")
+	}
+
+	if ops[OpRead] {
+		found := "found"
+		if presented {
+			found = fmt.Sprintf("presenter.New%s(*found)", entity)
+		}
+		fmt.Fprintf(&content, "\nfunc (s *%sServer) Get%s(ctx context.Context, req *pb.Get%sRequest) (*pb.%sResponse, error) {\n", entity, entity, entity, entity)
+		fmt.Fprintf(&content, "\tfound, err := s.usecase.Get%s(int(req.Id))\n", entity)
+		content.WriteString("\tif err != nil {\n\t\treturn nil, status.Error(codes.NotFound, err.Error())\n\t}\n\n")
+		fmt.Fprintf(&content, "\treturn &pb.%sResponse{%s: %s(%s)}, nil\n}\n", entity, entity, toProto, found)
+	}
+
+	if ops[OpUpdate] {
+		fmt.Fprintf(&content, "\nfunc (s *%sServer) Update%s(ctx context.Context, req *pb.Update%sRequest) (*pb.Update%sResponse, error) {\n", entity, entity, entity, entity)
+		fmt.Fprintf(&content, "\tif err := s.usecase.Update%s(int(req.Id), update%sInput(req)); err != nil {\n", entity, entity)
+		content.WriteString("\t\treturn nil, err\n\t}\n\n")
+		fmt.Fprintf(&content, "\treturn &pb.Update%sResponse{Message: \"%s updated successfully\"}, nil\n}\n", entity, entity)
+	}
+
+	if ops[OpDelete] {
+		fmt.Fprintf(&content, "\nfunc (s *%sServer) Delete%s(ctx context.Context, req *pb.Delete%sRequest) (*pb.Delete%sResponse, error) {\n", entity, entity, entity, entity)
+		fmt.Fprintf(&content, "\tif err := s.usecase.Delete%s(int(req.Id)); err != nil {\n", entity)
+		content.WriteString("\t\treturn nil, err\n\t}\n\n")
+		fmt.Fprintf(&content, "\treturn &pb.Delete%sResponse{Message: \"%s deleted successfully\"}, nil\n}\n", entity, entity)
+	}
+
+	if ops[OpList] {
+		fmt.Fprintf(&content, "\nfunc (s *%sServer) List%s(ctx context.Context, req *pb.List%sRequest) (*pb.List%sResponse, error) {\n", entity, toPlural(entity), toPlural(entity), toPlural(entity))
+		fmt.Fprintf(&content, "\toutput, err := s.usecase.List%s()\n", toPlural(entity))
+		content.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n\n")
+		fmt.Fprintf(&content, "\titems := make([]*pb.%s, 0, len(output.%s))\n", entity, toPlural(entity))
+		if presented {
+			fmt.Fprintf(&content, "\tfor _, item := range presenter.New%s(output.%s) {\n", toPlural(entity), toPlural(entity))
+			fmt.Fprintf(&content, "\t\titems = append(items, %s(item))\n", toProto)
+		} else {
+			fmt.Fprintf(&content, "\tfor i := range output.%s {\n", toPlural(entity))
+			fmt.Fprintf(&content, "\t\titems = append(items, %s(&output.%s[i]))\n", toProto, toPlural(entity))
+		}
+		content.WriteString("\t}\n")
+		fmt.Fprintf(&content, "\treturn &pb.List%sResponse{%s: items, Total: int32(output.Total)}, nil\n}\n", toPlural(entity), toPlural(entity))
 	}
-	content.WriteString("\t}\n\n")
 
-	content.WriteString(fmt.Sprintf("\toutput, err := s.usecase.Create%s(input)\n", entity))
-	content.WriteString("\tif err != nil {\n")
-	content.WriteString("\t\treturn nil, err\n")
-	content.WriteString("\t}\n\n")
+	if err := writeGoFile(filename, usePresenter(content.String()), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing grpc server file: %v", err))
+		return
+	}
+}
 
-	content.WriteString(fmt.Sprintf("\treturn &pb.Create%sResponse{\n", entity))
-	content.WriteString(fmt.Sprintf("\t\t%s: &pb.%s{\n", entity, entity))
-	content.WriteString("\t\t\tId: int32(output.ID),\n")
-	for _, f := range entityFields {
-		fmt.Fprintf(&content, "\t\t\t%s: output.%s,\n", protoGoFieldName(f.Name), f.Name)
+// generateGRPCMappingFile writes the mappings between the protobuf messages of
+// an entity and its use case DTOs, or its presenter when it has one. The gRPC
+// server uses them, and so does the REST gateway through it, so both protocols
+// map fields the same way.
+func generateGRPCMappingFile(dir, entity, fileNamingConvention string, sm ...*SafetyManager) {
+	importPath := getImportPath(getModuleName())
+	entityLower := strings.ToLower(entity)
+	ops := make(map[string]bool)
+	for _, op := range useCaseOperations(entity) {
+		ops[op] = true
+	}
+	fields := grpcEntityFields(entity)
+	toProto := strings.ToLower(entity[:1]) + entity[1:] + "ToProto"
+
+	var body strings.Builder
+	presented, presentedCreate := grpcPresenter(entity)
+	if presented {
+		fields = loadPresenter(entity).presents(fields)
+		if ops[OpRead] || ops[OpList] || (ops[OpCreate] && presentedCreate) {
+			fmt.Fprintf(&body, "\n// %s maps a presented %s onto its protobuf message.\n", toProto, entity)
+			fmt.Fprintf(&body, "func %s(p presenter.%s) *pb.%s {\n", toProto, entity, entity)
+			fmt.Fprintf(&body, "\treturn &pb.%s{\n\t\tId: int32(p.ID),\n", entity)
+			for _, f := range fields {
+				fmt.Fprintf(&body, "\t\t%s: %s,\n", protoGoFieldName(f.Name), protoConvert("p."+f.Name, f.Type, protoGoType(f.Type)))
+			}
+			body.WriteString("\t}\n}\n")
+		}
+	}
+	if presentedCreate && ops[OpCreate] {
+		fromRequest := strings.ToLower(entity[:1]) + entity[1:] + "FromCreateRequest"
+		fmt.Fprintf(&body, "\n// %s maps a create request onto a presented %s, whose\n", fromRequest, entity)
+		body.WriteString("// CreateInput is the use case input.\n")
+		fmt.Fprintf(&body, "func %s(req *pb.Create%sRequest) presenter.%s {\n", fromRequest, entity, entity)
+		fmt.Fprintf(&body, "\treturn presenter.%s{\n", entity)
+		for _, f := range withoutComputedFields(fields) {
+			fmt.Fprintf(&body, "\t\t%s: %s,\n", f.Name, protoConvert("req."+protoGoFieldName(f.Name), protoGoType(f.Type), f.Type))
+		}
+		body.WriteString("\t}\n}\n")
+	}
+	if !presented && (ops[OpRead] || ops[OpList]) {
+		fmt.Fprintf(&body, "\n// %s maps a domain.%s onto its protobuf message.\n", toProto, entity)
+		fmt.Fprintf(&body, "func %s(e *domain.%s) *pb.%s {\n", toProto, entity, entity)
+		fmt.Fprintf(&body, "\treturn &pb.%s{\n\t\tId: int32(e.ID),\n", entity)
+		for _, f := range fields {
+			fmt.Fprintf(&body, "\t\t%s: %s,\n", protoGoFieldName(f.Name), protoConvert("e."+f.Name, f.Type, protoGoType(f.Type)))
+		}
+		body.WriteString("\t}\n}\n")
 	}
-	content.WriteString("\t\t},\n")
-	content.WriteString("\t\tMessage: output.Message,\n")
-	content.WriteString("\t}, nil\n")
-	content.WriteString("}\n\n")
 
-	content.WriteString(fmt.Sprintf("func (s *%sServer) Get%s(ctx context.Context, req *pb.Get%sRequest) (*pb.%sResponse, error) {\n", entity, entity, entity, entity))
-	content.WriteString(fmt.Sprintf("\t%s, err := s.usecase.Get%s(int(req.Id))\n", entityLower, entity))
-	content.WriteString("\tif err != nil {\n")
-	content.WriteString("\t\treturn nil, err\n")
-	content.WriteString("\t}\n\n")
+	if ops[OpCreate] && !presentedCreate {
+		fmt.Fprintf(&body, "\n// created%sToProto maps the output of Create%s onto the protobuf message.\n", entity, entity)
+		fmt.Fprintf(&body, "func created%sToProto(output usecase.Create%sOutput) *pb.%s {\n", entity, entity, entity)
+		fmt.Fprintf(&body, "\treturn &pb.%s{\n\t\tId: int32(output.ID),\n", entity)
+		for _, f := range fields {
+			fmt.Fprintf(&body, "\t\t%s: %s,\n", protoGoFieldName(f.Name), protoConvert("output."+f.Name, f.Type, protoGoType(f.Type)))
+		}
+		body.WriteString("\t}\n}\n")
 
-	content.WriteString(fmt.Sprintf("\treturn &pb.%sResponse{\n", entity))
-	content.WriteString(fmt.Sprintf("\t\t%s: &pb.%s{\n", entity, entity))
-	content.WriteString(fmt.Sprintf("\t\t\tId: int32(%s.ID),\n", entityLower))
-	for _, f := range entityFields {
-		fmt.Fprintf(&content, "\t\t\t%s: %s.%s,\n", protoGoFieldName(f.Name), entityLower, f.Name)
+		fmt.Fprintf(&body, "\n// create%sInput maps a create request onto the use case input.\n", entity)
+		fmt.Fprintf(&body, "func create%sInput(req *pb.Create%sRequest) usecase.Create%sInput {\n", entity, entity, entity)
+		fmt.Fprintf(&body, "\treturn usecase.Create%sInput{\n", entity)
+		for _, f := range withoutComputedFields(fields) {
+			fmt.Fprintf(&body, "\t\t%s: %s,\n", f.Name, protoConvert("req."+protoGoFieldName(f.Name), protoGoType(f.Type), f.Type))
+		}
+		body.WriteString("\t}\n}\n")
+	}
+
+	if ops[OpUpdate] {
+		fmt.Fprintf(&body, "\n// update%sInput maps an update request onto the use case input. Fields the\n", entity)
+		body.WriteString("// request leaves unset stay unchanged.\n")
+		fmt.Fprintf(&body, "func update%sInput(req *pb.Update%sRequest) usecase.Update%sInput {\n", entity, entity, entity)
+		fmt.Fprintf(&body, "\tvar input usecase.Update%sInput\n", entity)
+		for _, f := range withoutComputedFields(fields) {
+			name := protoGoFieldName(f.Name)
+			if protoGoType(f.Type) == f.Type {
+				fmt.Fprintf(&body, "\tinput.%s = req.%s\n", f.Name, name)
+				continue
+			}
+			fmt.Fprintf(&body, "\tif req.%s != nil {\n", name)
+			fmt.Fprintf(&body, "\t\tv := %s\n", protoConvert("*req."+name, protoGoType(f.Type), f.Type))
+			fmt.Fprintf(&body, "\t\tinput.%s = &v\n", f.Name)
+			body.WriteString("\t}\n")
+		}
+		body.WriteString("\treturn input\n}\n")
 	}
-	content.WriteString("\t\t},\n")
-	content.WriteString("\t}, nil\n")
-	content.WriteString("}\n")
 
-	if err := writeGoFile(filename, content.String(), sm...); err != nil {
-		ui.Error(fmt.Sprintf("Error writing grpc server file: %v", err))
-		return
+	var content strings.Builder
+	content.WriteString("//go:build proto\n")
+	content.WriteString("// +build proto\n\n")
+	content.WriteString("package grpc\n\n")
+	content.WriteString("import (\n")
+	if !presented && (ops[OpRead] || ops[OpList]) {
+		fmt.Fprintf(&content, "\t\"%s/internal/domain\"\n", importPath)
+	}
+	if (ops[OpCreate] && !presentedCreate) || ops[OpUpdate] {
+		fmt.Fprintf(&content, "\t\"%s/internal/usecase\"\n", importPath)
 	}
+	fmt.Fprintf(&content, "\tpb \"%s/internal/handler/grpc/%s\"\n", importPath, entityLower)
+	content.WriteString(")\n")
+	content.WriteString(body.String())
+
+	if err := writeGoFile(grpcFilename(dir, entity, "mapping", fileNamingConvention), usePresenter(content.String()), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing grpc mapping file: %v", err))
+	}
+}
+
+// grpcPresenter reports whether the gRPC handler of entity presents entities
+// through its presenter, and whether it also maps creates through it.
+func grpcPresenter(entity string) (presented, presentedCreate bool) {
+	p := loadPresenter(entity)
+	presented = p.has("New"+entity) && p.has("New"+toPlural(entity))
+	return presented, presented && p.has("NewCreated"+entity) && p.has("CreateInput")
 }
 
 // grpcEntityFields returns the non-system fields of an entity (excluding ID,
-// which is always emitted explicitly) for use in proto/gRPC generation. It
-// falls back to Name/Email when the entity definition cannot be read so a
-// freshly scaffolded project still produces a coherent server.
+// which is always emitted explicitly, and the fields hidden from gRPC) for use
+// in proto/gRPC generation. It falls back to Name/Email when the entity
+// definition cannot be read so a freshly scaffolded project still produces a
+// coherent server.
 func grpcEntityFields(entity string) []Field {
 	var fields []Field
 	if fs := readEntityFieldsString(entity); fs != "" {
 		for _, f := range parseFields(fs) {
-			if isSystemField(f.Name) {
+			if isSystemField(f.Name) || fieldHiddenFrom(f, HandlerGRPC) {
 				continue
 			}
 			// Only scalar proto-mappable fields are supported by the scaffold.
@@ -308,248 +524,36 @@ func protoType(goType string) string {
 	}
 }
 
-// protoGoFieldName returns the Go field name protoc-gen-go produces for a proto
-// field whose snake_case name derives from the given entity field. protoc
-// converts snake_case to PascalCase, which for our PascalCase field names is the
-// field name itself.
-func protoGoFieldName(fieldName string) string {
-	return fieldName
-}
-
-// cliFlagFor returns the cobra flag accessor expression and declaration line
-// for a CLI command flag matching the given entity field. It reports ok=false
-// for types without a natural scalar flag (slices, maps, time, custom), which
-// are then omitted from the command.
-func cliFlagFor(field Field, entityLower string) (getExpr, flagDecl string, ok bool) {
-	flag := strings.ToLower(field.Name)
-	usage := fmt.Sprintf("%s of the %s", field.Name, entityLower)
-	switch field.Type {
-	case "string":
-		return fmt.Sprintf("cmd.Flags().GetString(%q)", flag),
-			fmt.Sprintf("\tcmd.Flags().String(%q, \"\", %q)\n", flag, usage), true
-	case "int":
-		return fmt.Sprintf("cmd.Flags().GetInt(%q)", flag),
-			fmt.Sprintf("\tcmd.Flags().Int(%q, 0, %q)\n", flag, usage), true
-	case "int64":
-		return fmt.Sprintf("cmd.Flags().GetInt64(%q)", flag),
-			fmt.Sprintf("\tcmd.Flags().Int64(%q, 0, %q)\n", flag, usage), true
-	case "uint":
-		return fmt.Sprintf("cmd.Flags().GetUint(%q)", flag),
-			fmt.Sprintf("\tcmd.Flags().Uint(%q, 0, %q)\n", flag, usage), true
-	case "uint64":
-		return fmt.Sprintf("cmd.Flags().GetUint64(%q)", flag),
-			fmt.Sprintf("\tcmd.Flags().Uint64(%q, 0, %q)\n", flag, usage), true
-	case "float64":
-		return fmt.Sprintf("cmd.Flags().GetFloat64(%q)", flag),
-			fmt.Sprintf("\tcmd.Flags().Float64(%q, 0, %q)\n", flag, usage), true
-	case "float32":
-		return fmt.Sprintf("cmd.Flags().GetFloat32(%q)", flag),
-			fmt.Sprintf("\tcmd.Flags().Float32(%q, 0, %q)\n", flag, usage), true
-	case "bool":
-		return fmt.Sprintf("cmd.Flags().GetBool(%q)", flag),
-			fmt.Sprintf("\tcmd.Flags().Bool(%q, false, %q)\n", flag, usage), true
+// protoGoType returns the Go type protoc-gen-go declares for the proto
+// equivalent of a Go scalar type, e.g. int32 for int.
+func protoGoType(goType string) string {
+	switch protoType(goType) {
+	case "float":
+		return "float32"
+	case "double":
+		return "float64"
+	case "":
+		return goType
 	default:
-		return "", "", false
+		return protoType(goType)
 	}
 }
 
-func generateCLIHandler(entity, fileNamingConvention string, sm ...*SafetyManager) {
-	// Create CLI directory
-	cliDir := filepath.Join(DirInternal, DirHandler, DirCLI)
-	_ = os.MkdirAll(cliDir, 0o755)
-
-	// Get the module name from go.mod
-	moduleName := getModuleName()
-	importPath := getImportPath(moduleName)
-
-	entityLower := strings.ToLower(entity)
-
-	// Apply naming convention to filename
-	var filename string
-	if fileNamingConvention == "snake_case" {
-		filename = filepath.Join(cliDir, toSnakeCase(entity)+"_commands.go")
-	} else if fileNamingConvention == "kebab-case" {
-		filename = filepath.Join(cliDir, toKebabCase(entity)+"-commands.go")
-	} else {
-		filename = filepath.Join(cliDir, entityLower+"_commands.go")
-	}
-
-	var content strings.Builder
-	content.WriteString("package cli\n\n")
-	content.WriteString("import (\n")
-	content.WriteString("\t\"fmt\"\n")
-	content.WriteString("\t\"strconv\"\n\n")
-	content.WriteString("\t\"github.com/spf13/cobra\"\n")
-	content.WriteString(fmt.Sprintf("\t\"%s/internal/usecase\"\n", importPath))
-	content.WriteString(")\n\n")
-
-	// CLI struct
-	content.WriteString(fmt.Sprintf("type %sCLI struct {\n", entity))
-	content.WriteString(fmt.Sprintf("\tusecase usecase.%sUseCase\n", entity))
-	content.WriteString("}\n\n")
-
-	// Constructor
-	content.WriteString(fmt.Sprintf("func New%sCLI(uc usecase.%sUseCase) *%sCLI {\n", entity, entity, entity))
-	content.WriteString(fmt.Sprintf("\treturn &%sCLI{usecase: uc}\n", entity))
-	content.WriteString("}\n\n")
-
-	// Create command — flags and input are derived from the real entity fields.
-	var cliFields []Field
-	if fs := readEntityFieldsString(entity); fs != "" {
-		cliFields = parseFields(fs)
-	}
-	content.WriteString(fmt.Sprintf("func (c *%sCLI) Create%sCommand() *cobra.Command {\n", entity, entity))
-	content.WriteString("\tcmd := &cobra.Command{\n")
-	content.WriteString(fmt.Sprintf("\t\tUse:   \"create\",\n\t\tShort: \"Create a new %s\",\n", entityLower))
-	content.WriteString("\t\tRun: func(cmd *cobra.Command, args []string) {\n")
-
-	var flagDecls strings.Builder
-	for _, f := range cliFields {
-		if isSystemField(f.Name) {
-			continue
-		}
-		getExpr, flagDecl, ok := cliFlagFor(f, entityLower)
-		if !ok {
-			continue
-		}
-		fmt.Fprintf(&content, "\t\t\t%s, _ := %s\n", strings.ToLower(f.Name), getExpr)
-		flagDecls.WriteString(flagDecl)
-	}
-	content.WriteString("\n")
-
-	fmt.Fprintf(&content, "\t\t\tinput := usecase.Create%sInput{\n", entity)
-	for _, f := range cliFields {
-		if isSystemField(f.Name) {
-			continue
-		}
-		if _, _, ok := cliFlagFor(f, entityLower); !ok {
-			continue
-		}
-		fmt.Fprintf(&content, "\t\t\t\t%s: %s,\n", f.Name, strings.ToLower(f.Name))
-	}
-	content.WriteString("\t\t\t}\n\n")
-
-	fmt.Fprintf(&content, "\t\t\toutput, err := c.usecase.Create%s(input)\n", entity)
-	content.WriteString("\t\t\tif err != nil {\n")
-	content.WriteString("\t\t\t\tfmt.Printf(\"Error: %v\\n\", err)\n")
-	content.WriteString("\t\t\t\treturn\n")
-	content.WriteString("\t\t\t}\n\n")
-	fmt.Fprintf(&content, "\t\t\tfmt.Printf(\"%s created: %%+v\\n\", output)\n", entity)
-	content.WriteString("\t\t},\n")
-	content.WriteString("\t}\n\n")
-	content.WriteString(flagDecls.String())
-	content.WriteString("\treturn cmd\n")
-	content.WriteString("}\n\n")
-
-	// Get command
-	content.WriteString(fmt.Sprintf("func (c *%sCLI) Get%sCommand() *cobra.Command {\n", entity, entity))
-	content.WriteString("\treturn &cobra.Command{\n")
-	content.WriteString("\t\tUse:   \"get [id]\",\n")
-	content.WriteString(fmt.Sprintf("\t\tShort: \"Get %s by ID\",\n", entityLower))
-	content.WriteString("\t\tArgs:  cobra.ExactArgs(1),\n")
-	content.WriteString("\t\tRun: func(cmd *cobra.Command, args []string) {\n")
-	content.WriteString("\t\t\tid, err := strconv.Atoi(args[0])\n")
-	content.WriteString("\t\t\tif err != nil {\n")
-	content.WriteString("\t\t\t\tfmt.Printf(\"Invalid ID: %v\\n\", err)\n")
-	content.WriteString("\t\t\t\treturn\n")
-	content.WriteString("\t\t\t}\n\n")
-
-	content.WriteString(fmt.Sprintf("\t\t\t%s, err := c.usecase.Get%s(id)\n", entityLower, entity))
-	content.WriteString("\t\t\tif err != nil {\n")
-	content.WriteString("\t\t\t\tfmt.Printf(\"Error: %v\\n\", err)\n")
-	content.WriteString("\t\t\t\treturn\n")
-	content.WriteString("\t\t\t}\n\n")
-
-	content.WriteString(fmt.Sprintf("\t\t\tfmt.Printf(\"%s: %%+v\\n\", %s)\n", entity, entityLower))
-	content.WriteString("\t\t},\n")
-	content.WriteString("\t}\n")
-	content.WriteString("}\n")
-
-	if err := writeGoFile(filename, content.String(), sm...); err != nil {
-		ui.Error(fmt.Sprintf("Error writing cli handler file: %v", err))
-		return
+// protoConvert returns expr converted from one Go type to another, or expr
+// itself when the types match.
+func protoConvert(expr, from, to string) string {
+	if from == to {
+		return expr
 	}
+	return fmt.Sprintf("%s(%s)", to, expr)
 }
 
-func generateWorkerHandler(entity, fileNamingConvention string, sm ...*SafetyManager) {
-	// Create worker directory
-	workerDir := filepath.Join(DirInternal, DirHandler, DirWorker)
-	_ = os.MkdirAll(workerDir, 0o755)
-
-	// Get the module name from go.mod
-	moduleName := getModuleName()
-
-	entityLower := strings.ToLower(entity)
-
-	// Apply naming convention to filename
-	var filename string
-	if fileNamingConvention == "snake_case" {
-		filename = filepath.Join(workerDir, toSnakeCase(entity)+"_worker.go")
-	} else if fileNamingConvention == "kebab-case" {
-		filename = filepath.Join(workerDir, toKebabCase(entity)+"-worker.go")
-	} else {
-		filename = filepath.Join(workerDir, entityLower+"_worker.go")
-	}
-
-	content := fmt.Sprintf(`package worker
-
-import (
-	"encoding/json"
-	"log"
-	
-	"%s/internal/usecase"
-)
-
-type %sWorker struct {
-	usecase usecase.%sUseCase
-}
-
-func New%sWorker(uc usecase.%sUseCase) *%sWorker {
-	return &%sWorker{usecase: uc}
-}
-
-func (w *%sWorker) Process%sJob(jobData []byte) error {
-	var input usecase.Create%sInput
-	
-	if err := json.Unmarshal(jobData, &input); err != nil {
-		log.Printf("Failed to unmarshal job data: %%v", err)
-		return err
-	}
-	
-	output, err := w.usecase.Create%s(input)
-	if err != nil {
-		log.Printf("Failed to process %s job: %%v", err)
-		return err
-	}
-	
-	log.Printf("%s job completed: %%v", output.Message)
-	return nil
-}
-
-func (w *%sWorker) ProcessBatch%sJob(jobData []byte) error {
-	var inputs []usecase.Create%sInput
-	
-	if err := json.Unmarshal(jobData, &inputs); err != nil {
-		log.Printf("Failed to unmarshal batch job data: %%v", err)
-		return err
-	}
-	
-	for _, input := range inputs {
-		if _, err := w.usecase.Create%s(input); err != nil {
-			log.Printf("Failed to process %s in batch: %%v", err)
-			// Continue processing other items
-		}
-	}
-	
-	log.Printf("Batch %s job completed")
-	return nil
-}
-`, moduleName, entity, entity, entity, entity, entity, entity, entity, entity, entity, entity, entityLower, entity, entity, entity, entity, entity, entityLower, entity)
-
-	if err := writeFile(filename, content, sm...); err != nil {
-		ui.Error(fmt.Sprintf("Error writing worker file: %v", err))
-		return
-	}
+// protoGoFieldName returns the Go field name protoc-gen-go produces for a proto
+// field whose snake_case name derives from the given entity field. protoc
+// converts snake_case to PascalCase, which for our PascalCase field names is the
+// field name itself.
+func protoGoFieldName(fieldName string) string {
+	return fieldName
 }
 
 func generateSOAPHandler(entity, fileNamingConvention string, sm ...*SafetyManager) {
@@ -657,7 +661,7 @@ func (c *%sSOAPClient) Create%s(name, email string) (*Create%sResponse, error) {
 }
 `, moduleName, entity, entity, entity, entity, entity, entity, entity, entity, entity, entity, entity, entity, entity, entity, entity, entity)
 
-	if err := writeFile(filename, content, sm...); err != nil {
+	if err := writeGoFile(filename, content, sm...); err != nil {
 		ui.Error(fmt.Sprintf("Error writing soap file: %v", err))
 		return
 	}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// generateHTTPQueryFile writes internal/handler/http/query.go with bindQuery,
+// which search handlers use to fill a criteria from query parameters. Like
+// response.go, it is shared by every handler and only rewritten with --force.
+func generateHTTPQueryFile(dir string, sm ...*SafetyManager) {
+	filename := filepath.Join(dir, "query.go")
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+	if _, err := os.Stat(filename); err == nil && !force {
+		return
+	}
+	if err := writeGoFile(filename, "package "+DirHTTP+"\n"+httpQuerySource, sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing query binder: %v", err))
+	}
+}
+
+// httpQuerySource is query.go after its package clause.
+const httpQuerySource = `
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// bindQuery sets the fields of the struct dst points to from the query
+// parameters named by their query tags. Pointer fields stay nil when their
+// parameter is absent; times are parsed as RFC 3339.
+func bindQuery(values url.Values, dst interface{}) error {
+	v := reflect.ValueOf(dst).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Tag.Get("query")
+		raw := values.Get(name)
+		if name == "" || raw == "" {
+			continue
+		}
+		field := v.Field(i)
+		if field.Kind() == reflect.Ptr {
+			field.Set(reflect.New(field.Type().Elem()))
+			field = field.Elem()
+		}
+		if err := setQueryValue(field, raw); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// setQueryValue parses raw into field.
+func setQueryValue(field reflect.Value, raw string) error {
+	if field.Type() == reflect.TypeOf(time.Time{}) {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+`
+
+// generateSearchHandlerMethod emits Search<Entity>s, which answers GET
+// /<entities>/search with the entities matching the criteria bound from the
+// query string, e.g. ?name_like=pro&price_max=10.
+func generateSearchHandlerMethod(content *strings.Builder, entity, handlerName string, swagger, acting bool, format string) {
+	handlerVar := strings.ToLower(string(handlerName[0]))
+	entityLower := strings.ToLower(entity)
+
+	// Fields hidden from HTTP are left out of the results.
+	results, successType := toPlural(entityLower), fmt.Sprintf("[]domain.%s", entity)
+	if loadProjection(entity, HandlerHTTP) != nil {
+		results, successType = fmt.Sprintf("new%sViews(%s)", entity, toPlural(entityLower)), fmt.Sprintf("[]%sView", entity)
+	}
+
+	if swagger {
+		writeSwaggerAnnotations(content, entity, fmt.Sprintf("Search %s", toPlural(entityLower)), "get", "/"+toPlural(entityLower)+"/search", "200", successType, "", format)
+	}
+
+	fmt.Fprintf(content, "func (%s *%s) Search%s(w http.ResponseWriter, r *http.Request) {\n",
+		handlerVar, handlerName, toPlural(entity))
+	fmt.Fprintf(content, "\tvar input usecase.Search%sInput\n", entity)
+	content.WriteString("\tif err := bindQuery(r.URL.Query(), &input); err != nil {\n")
+	writeHandlerError(content, format, "http.StatusBadRequest", "err.Error()")
+	content.WriteString("\t\treturn\n")
+	content.WriteString("\t}\n\n")
+
+	fmt.Fprintf(content, "\t%s, err := %s.Search%s(input)\n", toPlural(entityLower), handlerUseCase(handlerVar, acting), toPlural(entity))
+	content.WriteString("\tif err != nil {\n")
+	writeUseCaseError(content, format, "http.StatusInternalServerError")
+	content.WriteString("\t\treturn\n")
+	content.WriteString("\t}\n\n")
+
+	switch format {
+	case ResponseFormatEnvelope:
+		fmt.Fprintf(content, "\trespondList(w, %s, len(%s))\n", results, toPlural(entityLower))
+	case ResponseFormatProblem:
+		fmt.Fprintf(content, "\trespond(w, http.StatusOK, %s)\n", results)
+	default:
+		content.WriteString("\tw.Header().Set(\"Content-Type\", \"application/json\")\n")
+		fmt.Fprintf(content, "\tjson.NewEncoder(w).Encode(%s)\n", results)
+	}
+	content.WriteString("}\n\n")
+}
@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// projectResponseFormat returns the current project's
+// generation.response_format; unknown values fall back to raw.
+func projectResponseFormat() string {
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	if format := ci.GetResponseFormat(); format == ResponseFormatEnvelope || format == ResponseFormatProblem {
+		return format
+	}
+	return ResponseFormatRaw
+}
+
+// validationError returns the expression a use case returns for a failed
+// validation. Wrapped in messages.ErrValidation, it makes handlers answer 422
+// instead of 500.
+func validationError(wrap bool) string {
+	if wrap {
+		return "fmt.Errorf(\"%w: %w\", messages.ErrValidation, err)"
+	}
+	return "err"
+}
+
+// writeHandlerError writes the statement that rejects a request with the
+// status constant and message expression.
+func writeHandlerError(content *strings.Builder, format, status, message string) {
+	if format == ResponseFormatRaw {
+		fmt.Fprintf(content, "\t\thttp.Error(w, %s, %s)\n", message, status)
+		return
+	}
+	fmt.Fprintf(content, "\t\trespondError(w, r, %s, %s)\n", status, message)
+}
+
+// writeUseCaseError writes the statement that reports a use case error. Raw
+// handlers answer the status useCaseStatus maps a known error kind to, and
+// rawStatus otherwise; the other formats let errorStatus pick it.
+func writeUseCaseError(content *strings.Builder, format, rawStatus string) {
+	if format == ResponseFormatRaw {
+		writeHandlerError(content, format, "useCaseStatus(err, "+rawStatus+")", "err.Error()")
+		return
+	}
+	content.WriteString("\t\trespondUseCaseError(w, r, err)\n")
+}
+
+// generateHTTPResponseFile writes internal/handler/http/response.go with the
+// respond helpers of an envelope or problem format and the mapper from domain
+// errors to status codes. Like docs.go, it is shared by every handler and only
+// rewritten with --force.
+func generateHTTPResponseFile(dir, format string, sm ...*SafetyManager) {
+	generateMessageErrorKinds(sm...)
+	generateDomainErrorKinds(filepath.Join(DirInternal, DirDomain), sm...)
+
+	filename := filepath.Join(dir, "response.go")
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+	if _, err := os.Stat(filename); err == nil && !force {
+		return
+	}
+
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	notFound, driverImport := notFoundSentinel(ci.GetDatabaseType(""))
+	wrap := ci.GetGenerationConfig().ErrorWrapping
+	if wrap {
+		generateAppErrors(sm...)
+	}
+
+	var content strings.Builder
+	content.WriteString("package " + DirHTTP + "\n\n")
+	content.WriteString("import (\n")
+	content.WriteString("\t\"encoding/json\"\n")
+	content.WriteString("\t\"errors\"\n")
+	content.WriteString("\t\"log\"\n")
+	content.WriteString("\t\"net/http\"\n")
+	if format == ResponseFormatEnvelope {
+		content.WriteString("\t\"strings\"\n")
+	}
+	content.WriteString("\n")
+	writeErrorKindImports(&content, driverImport)
+	if wrap {
+		fmt.Fprintf(&content, "\t%q\n", appErrorsImport())
+	}
+	content.WriteString(")\n\n")
+
+	if format == ResponseFormatEnvelope {
+		content.WriteString(envelopeResponseTemplate)
+	} else {
+		content.WriteString(problemResponseTemplate)
+	}
+	if wrap {
+		content.WriteString(wrappedServerErrorLog(errorStatusTemplate))
+	} else {
+		content.WriteString(errorStatusTemplate)
+	}
+	content.WriteString(errorStatusCases(notFound))
+	content.WriteString("\t}\n\treturn http.StatusInternalServerError\n}\n\n")
+	content.WriteString(writeJSONTemplate)
+
+	if err := writeGoFile(filename, content.String(), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing response helpers: %v", err))
+	}
+}
+
+// generateHTTPStatusFile writes internal/handler/http/status.go with
+// useCaseStatus, which raw handlers answer use case errors with. Like
+// response.go, it is shared by every handler and only rewritten with --force.
+func generateHTTPStatusFile(dir string, sm ...*SafetyManager) {
+	generateMessageErrorKinds(sm...)
+	generateDomainErrorKinds(filepath.Join(DirInternal, DirDomain), sm...)
+
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	notFound, driverImport := notFoundSentinel(ci.GetDatabaseType(""))
+	wrap := ci.GetGenerationConfig().ErrorWrapping
+	if wrap {
+		generateAppErrors(sm...)
+	}
+
+	var content strings.Builder
+	content.WriteString("package " + DirHTTP + "\n\n")
+	content.WriteString("import (\n")
+	content.WriteString("\t\"errors\"\n")
+	if wrap {
+		content.WriteString("\t\"log\"\n")
+	}
+	content.WriteString("\t\"net/http\"\n\n")
+	writeErrorKindImports(&content, driverImport)
+	if wrap {
+		fmt.Fprintf(&content, "\t%q\n", appErrorsImport())
+	}
+	content.WriteString(")\n\n")
+	content.WriteString("// useCaseStatus maps the error kinds of the messages package, and the errors\n")
+	content.WriteString("// repositories translate database errors to, to HTTP status codes. Other\n")
+	if wrap {
+		content.WriteString("// errors get fallback, and are logged with their stack when it is a server\n")
+		content.WriteString("// error.\n")
+	} else {
+		content.WriteString("// errors get fallback.\n")
+	}
+	content.WriteString("func useCaseStatus(err error, fallback int) int {\n")
+	content.WriteString("\tswitch {\n")
+	content.WriteString(errorStatusCases(notFound))
+	content.WriteString("\t}\n")
+	if wrap {
+		content.WriteString("\tif fallback >= http.StatusInternalServerError {\n")
+		content.WriteString("\t\tlog.Printf(\"%v%s\", err, apperrors.Stack(err))\n")
+		content.WriteString("\t}\n")
+	}
+	content.WriteString("\treturn fallback\n}\n")
+
+	writeOnce(filepath.Join(dir, "status.go"), content.String(), "status mapping", sm...)
+}
+
+// writeErrorKindImports writes the imports of the error kinds errorStatusCases
+// matches, and of the database driver's not-found error when there is one.
+func writeErrorKindImports(content *strings.Builder, driverImport string) {
+	importPath := getImportPath(getModuleName())
+	fmt.Fprintf(content, "\t\"%s/internal/domain\"\n", importPath)
+	fmt.Fprintf(content, "\t\"%s/internal/messages\"\n", importPath)
+	if driverImport != "" {
+		fmt.Fprintf(content, "\t%q\n", driverImport)
+	}
+}
+
+// errorStatusCases returns the switch cases mapping error kinds to status
+// codes, with notFound appended to the not-found case.
+func errorStatusCases(notFound string) string {
+	return strings.Replace(errorStatusCasesTemplate, "{{notFound}}", notFound, 1)
+}
+
+// notFoundSentinel returns the errors.Is case matching the database driver's
+// not-found error, and the driver import it needs. Databases without one
+// rely on messages.ErrNotFound alone.
+func notFoundSentinel(database string) (match, importPath string) {
+	switch {
+	case sqlDialect(database) >= 0:
+		return ", errors.Is(err, gorm.ErrRecordNotFound)", "gorm.io/gorm"
+	case database == DBMongoDB:
+		return ", errors.Is(err, mongo.ErrNoDocuments)", "go.mongodb.org/mongo-driver/mongo"
+	}
+	return "", ""
+}
+
+// generateMessageErrorKinds writes internal/messages/errors.go, the error
+// kinds use cases wrap so the HTTP layer can map them to status codes.
+func generateMessageErrorKinds(sm ...*SafetyManager) {
+	filename := filepath.Join(DirInternal, "messages", "errors.go")
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+	if _, err := os.Stat(filename); err == nil && !force {
+		return
+	}
+	if err := writeGoFile(filename, messageErrorKindsTemplate, sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing error kinds: %v", err))
+	}
+}
+
+const messageErrorKindsTemplate = `package messages
+
+import "errors"
+
+// Error kinds the HTTP layer maps to status codes. Wrap them to classify an
+// error, e.g. fmt.Errorf("%w: order is closed", messages.ErrConflict).
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrValidation   = errors.New("validation failed")
+	ErrConflict     = errors.New("conflict")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+)
+`
+
+const envelopeResponseTemplate = `// Envelope is the body of every response: data on success, error on failure
+// and meta for details such as list totals.
+type Envelope struct {
+	Data  any            ` + "`json:\"data,omitempty\"`" + `
+	Error *ErrorBody     ` + "`json:\"error,omitempty\"`" + `
+	Meta  map[string]any ` + "`json:\"meta,omitempty\"`" + `
+}
+
+// ErrorBody describes why a request failed.
+type ErrorBody struct {
+	Code    string ` + "`json:\"code\"`" + `
+	Message string ` + "`json:\"message\"`" + `
+}
+
+// respond writes data in an envelope.
+func respond(w http.ResponseWriter, status int, data any) {
+	writeJSON(w, status, "application/json", Envelope{Data: data})
+}
+
+// respondList writes a list in an envelope with its total in meta.
+func respondList(w http.ResponseWriter, items any, total int) {
+	writeJSON(w, http.StatusOK, "application/json", Envelope{Data: items, Meta: map[string]any{"total": total}})
+}
+
+// respondError writes an error envelope whose code is the snake_case status
+// text, e.g. not_found.
+func respondError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	code := strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+	writeJSON(w, status, "application/json", Envelope{Error: &ErrorBody{Code: code, Message: message}})
+}
+
+`
+
+const problemResponseTemplate = `// Problem is an RFC 7807 problem details body.
+type Problem struct {
+	Type     string ` + "`json:\"type\"`" + `
+	Title    string ` + "`json:\"title\"`" + `
+	Status   int    ` + "`json:\"status\"`" + `
+	Detail   string ` + "`json:\"detail,omitempty\"`" + `
+	Instance string ` + "`json:\"instance,omitempty\"`" + `
+}
+
+// respond writes data as plain JSON.
+func respond(w http.ResponseWriter, status int, data any) {
+	writeJSON(w, status, "application/json", data)
+}
+
+// respondError writes an application/problem+json body for the request.
+func respondError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	writeJSON(w, status, "application/problem+json", Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   message,
+		Instance: r.URL.Path,
+	})
+}
+
+`
+
+// serverErrorLog is the statement respondUseCaseError logs server errors
+// with; wrappedServerErrorLog appends their stack to it.
+const serverErrorLog = `log.Printf("%s %s: %v", r.Method, r.URL.Path, err)`
+
+const errorStatusTemplate = `// respondUseCaseError answers with the status errorStatus maps err to. Server
+// errors are logged and their details kept from the client.
+func respondUseCaseError(w http.ResponseWriter, r *http.Request, err error) {
+	status := errorStatus(err)
+	message := err.Error()
+	if status >= http.StatusInternalServerError {
+		log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+		message = http.StatusText(status)
+	}
+	respondError(w, r, status, message)
+}
+
+// errorStatus maps the error kinds of the messages package, and the errors
+// repositories translate database errors to, to HTTP status codes.
+func errorStatus(err error) int {
+	switch {
+`
+
+const errorStatusCasesTemplate = `	case errors.Is(err, messages.ErrNotFound), errors.Is(err, domain.ErrNotFound){{notFound}}:
+		return http.StatusNotFound
+	case errors.Is(err, messages.ErrValidation):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, messages.ErrConflict), errors.Is(err, domain.ErrAlreadyExists), errors.Is(err, domain.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, messages.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, messages.ErrForbidden):
+		return http.StatusForbidden
+`
+
+const writeJSONTemplate = `// writeJSON writes body as JSON with the status and content type.
+func writeJSON(w http.ResponseWriter, status int, contentType string, body any) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+`
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeResponseFormatConfig(t *testing.T, format, database string) {
+	t.Helper()
+	config := "project:\n  name: shop\n  module: example.com/shop\ndatabase:\n  type: " + database +
+		"\n  port: 5432\ngeneration:\n  response_format: " + format + "\n"
+	require.NoError(t, os.WriteFile(".goca.yaml", []byte(config), 0o644))
+}
+
+// generateProductHandler generates the Product use case and HTTP handler and
+// returns the handler source.
+func generateProductHandler(t *testing.T) string {
+	t.Helper()
+	sm := NewSafetyManager(false, true, false)
+	generateUseCaseWithFields("ProductService", "Product", "create,read,update,delete,list", false, false, "name:string", "", sm)
+	generateHTTPHandlerFile(filepath.Join(DirInternal, DirHandler, DirHTTP), "Product", true, true, "", sm)
+	return readParsedGo(t, filepath.Join(DirInternal, DirHandler, DirHTTP, "product_handler.go"))
+}
+
+func TestGenerateHTTPHandler_EnvelopeFormat(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+	writeResponseFormatConfig(t, ResponseFormatEnvelope, DBPostgres)
+
+	handler := generateProductHandler(t)
+	assert.NotContains(t, handler, "http.Error(")
+	assert.Contains(t, handler, "respondError(w, r, http.StatusBadRequest, \"Invalid product ID\")")
+	assert.Contains(t, handler, "respondUseCaseError(w, r, err)")
+	assert.Contains(t, handler, "respond(w, http.StatusCreated, output)")
+	assert.Contains(t, handler, "respondList(w, output.Products, output.Total)")
+	assert.Contains(t, handler, "// @Success 200 {object} Envelope{data=[]domain.Product}")
+	assert.Contains(t, handler, "// @Failure 404 {object} Envelope")
+
+	response := readParsedGo(t, filepath.Join(DirInternal, DirHandler, DirHTTP, "response.go"))
+	assert.Contains(t, response, "type Envelope struct {")
+	assert.Contains(t, response, "case errors.Is(err, messages.ErrNotFound), errors.Is(err, domain.ErrNotFound), errors.Is(err, gorm.ErrRecordNotFound):")
+	assert.Contains(t, response, "case errors.Is(err, messages.ErrConflict), errors.Is(err, domain.ErrAlreadyExists), errors.Is(err, domain.ErrConflict):")
+	readParsedGo(t, filepath.Join(DirInternal, DirDomain, "repository_errors.go"))
+	readParsedGo(t, filepath.Join(DirInternal, "messages", "errors.go"))
+
+	service := readParsedGo(t, filepath.Join(DirInternal, DirUseCase, "product_service.go"))
+	assert.Contains(t, service, `return CreateProductOutput{}, fmt.Errorf("%w: %w", messages.ErrValidation, err)`)
+}
+
+func TestGenerateHTTPHandler_ProblemFormat(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+	writeResponseFormatConfig(t, ResponseFormatProblem, DBDynamoDB)
+
+	handler := generateProductHandler(t)
+	assert.Contains(t, handler, "respond(w, http.StatusOK, output)")
+	assert.Contains(t, handler, "// @Failure 422 {object} Problem")
+
+	response := readParsedGo(t, filepath.Join(DirInternal, DirHandler, DirHTTP, "response.go"))
+	assert.Contains(t, response, `"application/problem+json"`)
+	assert.Contains(t, response, "case errors.Is(err, messages.ErrNotFound), errors.Is(err, domain.ErrNotFound):")
+	assert.NotContains(t, response, "gorm")
+}
+
+func TestGenerateHTTPHandler_RawFormat(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	handler := generateProductHandler(t)
+	assert.Contains(t, handler, "http.Error(w, err.Error(), useCaseStatus(err, http.StatusNotFound))")
+	assert.NotContains(t, handler, "respond")
+	assert.NoFileExists(t, filepath.Join(DirInternal, DirHandler, DirHTTP, "response.go"))
+
+	status := readParsedGo(t, filepath.Join(DirInternal, DirHandler, DirHTTP, "status.go"))
+	assert.Contains(t, status, "func useCaseStatus(err error, fallback int) int {")
+	assert.Contains(t, status, "case errors.Is(err, messages.ErrValidation):\n\t\treturn http.StatusUnprocessableEntity")
+	assert.Contains(t, status, "return fallback")
+	readParsedGo(t, filepath.Join(DirInternal, "messages", "errors.go"))
+}
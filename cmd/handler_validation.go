@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// writeRequestValidation writes the statement that validates the decoded
+// input of a create or update handler with the shared validator of
+// validation.go. Files uploaded with an invalid input are discarded.
+func writeRequestValidation(content *strings.Builder, entity string) {
+	content.WriteString("\tif !validateRequest(w, r, &input) {\n")
+	writeUploadCleanup(content, entity)
+	content.WriteString("\t\treturn\n")
+	content.WriteString("\t}\n\n")
+}
+
+// writeEnumRegistrations writes the init function registering the validations
+// of the entity's enum fields, which its DTOs name in their validate tags. It
+// reports whether the handler refers to the domain package for their values.
+func writeEnumRegistrations(content *strings.Builder, entity string) bool {
+	enums := entityEnumValues(entity)
+	if len(enums) == 0 {
+		return false
+	}
+	names := make([]string, 0, len(enums))
+	for field := range enums {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	content.WriteString("func init() {\n")
+	for _, field := range names {
+		writeEnumRegistration(content, entity, field)
+	}
+	content.WriteString("}\n\n")
+	return true
+}
+
+// writeEnumRegistration writes the registration of one enum field.
+func writeEnumRegistration(content *strings.Builder, entity, field string) {
+	fmt.Fprintf(content, "\tregisterEnum(%q, domain.%s%sValues)\n", enumValidationTag(entity, field), entity, field)
+}
+
+// generateHTTPValidationFile writes internal/handler/http/validation.go, the
+// validator every handler checks its requests with. Invalid requests are
+// answered with 422 and the rejected fields in the body of the response
+// format. Like response.go, it is only rewritten with --force.
+func generateHTTPValidationFile(dir, format string, sm ...*SafetyManager) {
+	filename := filepath.Join(dir, "validation.go")
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+	if _, err := os.Stat(filename); err == nil && !force {
+		return
+	}
+
+	var content strings.Builder
+	content.WriteString("package " + DirHTTP + "\n\n")
+	content.WriteString("import (\n")
+	if format == ResponseFormatRaw {
+		content.WriteString("\t\"encoding/json\"\n")
+	}
+	content.WriteString("\t\"errors\"\n")
+	content.WriteString("\t\"fmt\"\n")
+	content.WriteString("\t\"net/http\"\n")
+	content.WriteString("\t\"reflect\"\n")
+	content.WriteString("\t\"strings\"\n\n")
+	content.WriteString("\t\"github.com/go-playground/validator/v10\"\n")
+	content.WriteString(")\n\n")
+
+	content.WriteString(validationTemplate)
+	switch format {
+	case ResponseFormatEnvelope:
+		content.WriteString(envelopeValidationTemplate)
+	case ResponseFormatProblem:
+		content.WriteString(problemValidationTemplate)
+	default:
+		content.WriteString(rawValidationTemplate)
+	}
+
+	if err := writeGoFile(filename, content.String(), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing validation helpers: %v", err))
+	}
+}
+
+const validationTemplate = `// validate checks decoded requests against their validate tags. Handlers
+// share it, so custom validations are registered once.
+var validate = newValidator()
+
+// enumValues holds the values each registered enum validation accepts.
+var enumValues = map[string][]string{}
+
+// newValidator returns a validator that reports fields by their JSON names.
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			return ""
+		case "":
+			return f.Name
+		}
+		return name
+	})
+	return v
+}
+
+// registerEnum registers the validation tag, which accepts only values.
+// Handlers register the enum fields of their entity from init.
+func registerEnum(tag string, values []string) {
+	allowed := make(map[string]bool, len(values))
+	for _, value := range values {
+		allowed[value] = true
+	}
+	enumValues[tag] = values
+	if err := validate.RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+		return allowed[fl.Field().String()]
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// FieldError describes why a request field was rejected.
+type FieldError struct {
+	Field   string ` + "`json:\"field\"`" + `
+	Rule    string ` + "`json:\"rule\"`" + `
+	Message string ` + "`json:\"message\"`" + `
+}
+
+// validateRequest validates the decoded input of a request. It answers an
+// invalid input with 422 and the rejected fields, and then reports false.
+func validateRequest(w http.ResponseWriter, r *http.Request, input any) bool {
+	err := validate.Struct(input)
+	if err == nil {
+		return true
+	}
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		respondValidatorError(w, r, err)
+		return false
+	}
+	fields := make([]FieldError, len(invalid))
+	for i, fe := range invalid {
+		// The namespace starts with the struct's name: CreateOrderInput.status.
+		_, field, _ := strings.Cut(fe.Namespace(), ".")
+		fields[i] = FieldError{Field: field, Rule: fe.Tag(), Message: fieldErrorMessage(fe)}
+	}
+	respondInvalid(w, r, fields)
+	return false
+}
+
+// fieldErrorMessage explains a failed validation to the client.
+func fieldErrorMessage(fe validator.FieldError) string {
+	if values, ok := enumValues[fe.Tag()]; ok {
+		return "must be one of " + strings.Join(values, ", ")
+	}
+	unit := ""
+	if fe.Kind() == reflect.String {
+		unit = " characters"
+	}
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		return fmt.Sprintf("must be at least %s%s", fe.Param(), unit)
+	case "max":
+		return fmt.Sprintf("must be at most %s%s", fe.Param(), unit)
+	case "len":
+		return fmt.Sprintf("must be exactly %s%s", fe.Param(), unit)
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	}
+	return fmt.Sprintf("failed the %s validation", fe.Tag())
+}
+
+`
+
+const rawValidationTemplate = `// validationErrorBody is the body of a 422 response.
+type validationErrorBody struct {
+	Error  string       ` + "`json:\"error\"`" + `
+	Fields []FieldError ` + "`json:\"fields\"`" + `
+}
+
+// respondInvalid answers 422 with the rejected fields.
+func respondInvalid(w http.ResponseWriter, r *http.Request, fields []FieldError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	_ = json.NewEncoder(w).Encode(validationErrorBody{Error: "validation failed", Fields: fields})
+}
+
+// respondValidatorError answers an input the validator cannot check.
+func respondValidatorError(w http.ResponseWriter, r *http.Request, err error) {
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+`
+
+const envelopeValidationTemplate = `// validationErrorBody is the error of a 422 envelope, with the rejected
+// fields.
+type validationErrorBody struct {
+	ErrorBody
+	Fields []FieldError ` + "`json:\"fields\"`" + `
+}
+
+// respondInvalid answers 422 with an error envelope listing the rejected
+// fields.
+func respondInvalid(w http.ResponseWriter, r *http.Request, fields []FieldError) {
+	writeJSON(w, http.StatusUnprocessableEntity, "application/json", struct {
+		Error validationErrorBody ` + "`json:\"error\"`" + `
+	}{
+		Error: validationErrorBody{
+			ErrorBody: ErrorBody{Code: "unprocessable_entity", Message: "validation failed"},
+			Fields:    fields,
+		},
+	})
+}
+
+// respondValidatorError answers an input the validator cannot check.
+func respondValidatorError(w http.ResponseWriter, r *http.Request, err error) {
+	respondUseCaseError(w, r, err)
+}
+`
+
+const problemValidationTemplate = `// validationProblem is a problem details body with the rejected fields as
+// its errors extension member.
+type validationProblem struct {
+	Problem
+	Errors []FieldError ` + "`json:\"errors\"`" + `
+}
+
+// respondInvalid answers 422 with a problem listing the rejected fields.
+func respondInvalid(w http.ResponseWriter, r *http.Request, fields []FieldError) {
+	writeJSON(w, http.StatusUnprocessableEntity, "application/problem+json", validationProblem{
+		Problem: Problem{
+			Type:     "about:blank",
+			Title:    http.StatusText(http.StatusUnprocessableEntity),
+			Status:   http.StatusUnprocessableEntity,
+			Detail:   "validation failed",
+			Instance: r.URL.Path,
+		},
+		Errors: fields,
+	})
+}
+
+// respondValidatorError answers an input the validator cannot check.
+func respondValidatorError(w http.ResponseWriter, r *http.Request, err error) {
+	respondUseCaseError(w, r, err)
+}
+`
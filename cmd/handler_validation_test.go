@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFields_Enum(t *testing.T) {
+	fields := parseFieldsWithValidation("status:string:enum=draft|placed:findallby,note:string", true)
+	require.Len(t, fields, 3)
+	assert.Equal(t, []string{"draft", "placed"}, fields[1].Enum)
+	assert.Equal(t, []FieldFinder{{Kind: FinderAll}}, fields[1].Finders)
+	assert.Nil(t, fields[2].Enum)
+	assert.Equal(t, "required,order_status", dtoValidationTag("Order", fields[1]))
+	assert.Equal(t, "omitempty,order_status", dtoUpdateValidationTag("Order", fields[1]))
+
+	v := NewFieldValidator()
+	for def, message := range map[string]string{
+		"priority:int:enum=1|2":          "cannot be an enum",
+		"status:string:enum=a b|c":       "invalid enum value",
+		"status:string:enum=":            "invalid enum value",
+		"status:string:enum=draft|draft": "duplicate enum value",
+	} {
+		_, err := v.ValidateField(def)
+		assert.ErrorContains(t, err, message, def)
+	}
+}
+
+func TestGenerateHTTPHandler_Validation(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+	writeResponseFormatConfig(t, ResponseFormatEnvelope, DBPostgres)
+
+	sm := NewSafetyManager(false, true, false)
+	require.NoError(t, generateEntity("Order", "customer:string,payment_status:string:enum=pending|paid", true, false, false, false, false, false, false, false, "lowercase", sm))
+	entity := readParsedGo(t, filepath.Join("internal", "domain", "order.go"))
+	assert.Contains(t, entity, `var OrderPaymentStatusValues = []string{"pending", "paid"}`)
+	assert.Contains(t, entity, "if !slices.Contains(OrderPaymentStatusValues, o.PaymentStatus) {\n\t\treturn ErrInvalidOrderPaymentStatusValue")
+
+	// The enum is read back from the entity.
+	fields := readEntityFieldsString("Order")
+	assert.Equal(t, "customer:string,payment_status:string:enum=pending|paid", fields)
+	generateUseCaseWithFields("OrderService", "Order", "create,read,update,delete,list", true, false, fields, "", sm)
+	dto := readParsedGo(t, filepath.Join("internal", "usecase", "dto.go"))
+	assert.Contains(t, dto, "`json:\"payment_status\" validate:\"required,order_payment_status\"`")
+
+	dir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	generateHTTPHandlerFile(dir, "Order", true, false, "", sm)
+	handler := readParsedGo(t, filepath.Join(dir, "order_handler.go"))
+	assert.Contains(t, handler, "func init() {\n\tregisterEnum(\"order_payment_status\", domain.OrderPaymentStatusValues)\n}")
+	assert.Contains(t, handler, "if !validateRequest(w, r, &input) {")
+	assert.NotContains(t, handler, "go-playground/validator")
+
+	validation := readParsedGo(t, filepath.Join(dir, "validation.go"))
+	assert.Contains(t, validation, "var validate = newValidator()")
+	assert.Contains(t, validation, "ErrorBody: ErrorBody{Code: \"unprocessable_entity\", Message: \"validation failed\"},")
+	assert.Contains(t, validation, `"github.com/go-playground/validator/v10"`)
+}
+
+func TestFieldAddRemove_Enum(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	sm := NewSafetyManager(false, true, false)
+	require.NoError(t, generateEntity("Ticket", "title:string", true, false, false, false, false, false, false, true, "lowercase", sm))
+	generateUseCaseWithFields("TicketService", "Ticket", "create,read,update,delete,list", true, false, "title:string", "", sm)
+	dir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	generateHTTPHandlerFile(dir, "Ticket", true, false, "", sm)
+
+	require.NoError(t, runFieldAdd(fieldAddCmd, []string{"Ticket", "kind:string:enum=bug|task"}))
+	handler := readParsedGo(t, filepath.Join(dir, "ticket_handler.go"))
+	assert.Contains(t, handler, "func init() {\n\tregisterEnum(\"ticket_kind\", domain.TicketKindValues)\n}")
+	assert.Contains(t, handler, "\"testproject/internal/domain\"\n\t\"testproject/internal/usecase\"")
+	tests := readParsedGo(t, filepath.Join("internal", "domain", "ticket_test.go"))
+	assert.Contains(t, tests, `{name: "value outside the enum", value: "not-a-value", wantErr: true},`)
+
+	require.NoError(t, runFieldRemove(fieldRemoveCmd, []string{"Ticket", "kind"}))
+	handler = readParsedGo(t, filepath.Join(dir, "ticket_handler.go"))
+	assert.NotContains(t, handler, "func init()")
+	assert.NotContains(t, handler, "internal/domain")
+	entity := readParsedGo(t, filepath.Join("internal", "domain", "ticket.go"))
+	assert.NotContains(t, entity, "TicketKindValues")
+	assert.NotContains(t, entity, "slices")
+}
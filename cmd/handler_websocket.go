@@ -0,0 +1,434 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// websocketHubAnchor is the main.go line after which the WebSocket hub is
+// started, so it exists before the feature routes that use it.
+const websocketHubAnchor = "\tapiRouter := router.PathPrefix(\"/api/v1\").Subrouter()\n"
+
+// generateWebSocketHandler generates the shared WebSocket hub and the entity's
+// broadcaster and route. The routes are wired into main.go separately by
+// registerWebSocketRoutes, once the HTTP routes are in place.
+func generateWebSocketHandler(entity, fileNamingConvention string, sm ...*SafetyManager) {
+	wsDir := filepath.Join(DirInternal, DirHandler, DirWebSocket)
+	_ = os.MkdirAll(wsDir, 0o755)
+
+	var filename string
+	if fileNamingConvention == "snake_case" {
+		filename = filepath.Join(wsDir, toSnakeCase(entity)+"_ws.go")
+	} else if fileNamingConvention == "kebab-case" {
+		filename = filepath.Join(wsDir, toKebabCase(entity)+"-ws.go")
+	} else {
+		filename = filepath.Join(wsDir, strings.ToLower(entity)+"_ws.go")
+	}
+
+	// Events carry the projection when fields are hidden from websocket clients.
+	view := generateProjectionFile(wsDir, DirWebSocket, entity, HandlerWebSocket, fileNamingConvention, false, false, sm...)
+	content := buildWebSocketHandler(getImportPath(getModuleName()), entity, useCaseOperations(entity), view)
+	if err := writeGoFile(filename, content, sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing websocket handler file: %v", err))
+		return
+	}
+
+	generateWebSocketHub(wsDir, sm...)
+}
+
+// generateWebSocketHub writes hub.go once; it is shared by every entity.
+func generateWebSocketHub(dir string, sm ...*SafetyManager) {
+	filename := filepath.Join(dir, "hub.go")
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+	if _, err := os.Stat(filename); err == nil && !force {
+		return
+	}
+	if err := writeGoFile(filename, websocketHubTemplate, sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing websocket hub: %v", err))
+	}
+}
+
+// buildWebSocketHandler renders <entity>_ws.go: a use case decorator that
+// publishes the changes of the operations the use case declares, and the
+// route streaming them. Events publish the projections of view when it is
+// not nil.
+func buildWebSocketHandler(importPath, entity string, operations []string, view *projection) string {
+	entityLower := strings.ToLower(entity)
+	has := make(map[string]bool, len(operations))
+	for _, op := range operations {
+		has[op] = true
+	}
+
+	var b strings.Builder
+	b.WriteString("package ws\n\n")
+	b.WriteString("import (\n\t\"github.com/gorilla/mux\"\n\n")
+	fmt.Fprintf(&b, "\t\"%s/internal/usecase\"\n", importPath)
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(&b, "// %sTopic is the topic %s changes are published on.\n", entity, entityLower)
+	fmt.Fprintf(&b, "const %sTopic = %q\n\n", entity, entityLower)
+
+	fmt.Fprintf(&b, "// %sBroadcaster is a usecase.%sUseCase that publishes the changes it\n", entity, entity)
+	fmt.Fprintf(&b, "// makes to the subscribers of %sTopic. Other methods are passed through.\n", entity)
+	fmt.Fprintf(&b, "type %sBroadcaster struct {\n", entity)
+	fmt.Fprintf(&b, "\tusecase.%sUseCase\n", entity)
+	b.WriteString("\thub *Hub\n")
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// New%sBroadcaster wraps uc so the changes it makes are pushed through hub.\n", entity)
+	fmt.Fprintf(&b, "func New%sBroadcaster(uc usecase.%sUseCase, hub *Hub) *%sBroadcaster {\n", entity, entity, entity)
+	fmt.Fprintf(&b, "\treturn &%sBroadcaster{%sUseCase: uc, hub: hub}\n", entity, entity)
+	b.WriteString("}\n\n")
+
+	if has[OpCreate] {
+		fmt.Fprintf(&b, "// Create%s creates the %s and publishes %s.created.\n", entity, entityLower, entityLower)
+		fmt.Fprintf(&b, "func (b *%sBroadcaster) Create%s(input usecase.Create%sInput) (usecase.Create%sOutput, error) {\n", entity, entity, entity, entity)
+		fmt.Fprintf(&b, "\toutput, err := b.%sUseCase.Create%s(input)\n", entity, entity)
+		b.WriteString("\tif err == nil {\n")
+		if view.createView() {
+			fmt.Fprintf(&b, "\t\tb.hub.Publish(%sTopic, \"%s.created\", newCreate%sView(output))\n", entity, entityLower, entity)
+		} else {
+			fmt.Fprintf(&b, "\t\tb.hub.Publish(%sTopic, \"%s.created\", output)\n", entity, entityLower)
+		}
+		b.WriteString("\t}\n")
+		b.WriteString("\treturn output, err\n")
+		b.WriteString("}\n\n")
+	}
+
+	if has[OpUpdate] {
+		fmt.Fprintf(&b, "// Update%s updates the %s and publishes %s.updated.\n", entity, entityLower, entityLower)
+		fmt.Fprintf(&b, "func (b *%sBroadcaster) Update%s(id int, input usecase.Update%sInput) error {\n", entity, entity, entity)
+		fmt.Fprintf(&b, "\tif err := b.%sUseCase.Update%s(id, input); err != nil {\n", entity, entity)
+		b.WriteString("\t\treturn err\n")
+		b.WriteString("\t}\n")
+		if has[OpRead] {
+			b.WriteString("\t// Subscribers get the stored state; the ID alone if it cannot be read.\n")
+			fmt.Fprintf(&b, "\tif %s, err := b.%sUseCase.Get%s(id); err == nil {\n", entityLower, entity, entity)
+			if view != nil {
+				fmt.Fprintf(&b, "\t\tb.hub.Publish(%sTopic, \"%s.updated\", new%sView(*%s))\n", entity, entityLower, entity, entityLower)
+			} else {
+				fmt.Fprintf(&b, "\t\tb.hub.Publish(%sTopic, \"%s.updated\", %s)\n", entity, entityLower, entityLower)
+			}
+			b.WriteString("\t\treturn nil\n")
+			b.WriteString("\t}\n")
+		}
+		fmt.Fprintf(&b, "\tb.hub.Publish(%sTopic, \"%s.updated\", map[string]int{\"id\": id})\n", entity, entityLower)
+		b.WriteString("\treturn nil\n")
+		b.WriteString("}\n\n")
+	}
+
+	if has[OpDelete] {
+		fmt.Fprintf(&b, "// Delete%s deletes the %s and publishes %s.deleted with its ID.\n", entity, entityLower, entityLower)
+		fmt.Fprintf(&b, "func (b *%sBroadcaster) Delete%s(id int) error {\n", entity, entity)
+		fmt.Fprintf(&b, "\tif err := b.%sUseCase.Delete%s(id); err != nil {\n", entity, entity)
+		b.WriteString("\t\treturn err\n")
+		b.WriteString("\t}\n")
+		fmt.Fprintf(&b, "\tb.hub.Publish(%sTopic, \"%s.deleted\", map[string]int{\"id\": id})\n", entity, entityLower)
+		b.WriteString("\treturn nil\n")
+		b.WriteString("}\n\n")
+	}
+
+	fmt.Fprintf(&b, "// Setup%sRoutes mounts /ws/%s, a WebSocket endpoint streaming the events\n", entity, toPlural(entityLower))
+	fmt.Fprintf(&b, "// of %sTopic.\n", entity)
+	fmt.Fprintf(&b, "func Setup%sRoutes(router *mux.Router, hub *Hub) {\n", entity)
+	fmt.Fprintf(&b, "\trouter.HandleFunc(\"/ws/%s\", hub.Subscribe(%sTopic)).Methods(\"GET\")\n", toPlural(entityLower), entity)
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// registerWebSocketRoutes wires the entity's WebSocket endpoint into main.go
+// and wraps the use case of its HTTP routes with the broadcaster, printing
+// manual steps when main.go does not have the expected layout.
+func registerWebSocketRoutes(entity string, sm ...*SafetyManager) {
+	if len(sm) > 0 && sm[0] != nil && sm[0].DryRun {
+		return
+	}
+	mainPath, found := findMainGoPath()
+	if !found {
+		printWebSocketInstructions(entity)
+		return
+	}
+	defer lockSharedFile(mainPath)()
+	content, err := os.ReadFile(mainPath)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not read main.go: %v", err))
+		printWebSocketInstructions(entity)
+		return
+	}
+	updated, ok := wireWebSocketIntoMainGo(string(content), entity, getImportPath(getModuleName()))
+	if !ok {
+		ui.Warning("main.go has no /api/v1 router to register the WebSocket routes on")
+		printWebSocketInstructions(entity)
+		return
+	}
+	if updated == string(content) {
+		ui.Dim("   WebSocket routes already registered")
+		return
+	}
+	if err := writeMainGoInPlace(mainPath, updated); err != nil {
+		ui.Warning(fmt.Sprintf("Could not register WebSocket routes in main.go: %v", err))
+		printWebSocketInstructions(entity)
+		return
+	}
+	ui.Dim(fmt.Sprintf("   WebSocket endpoint /api/v1/ws/%s registered in %s", toPlural(strings.ToLower(entity)), mainPath))
+}
+
+// wireWebSocketIntoMainGo starts the hub after the /api/v1 router, registers
+// the entity's WebSocket route and wraps the use case passed to its HTTP
+// routes with the broadcaster. It is idempotent and reports false when main.go
+// has no /api/v1 router.
+func wireWebSocketIntoMainGo(content, entity, importPath string) (string, bool) {
+	if !strings.Contains(content, websocketHubAnchor) || !strings.Contains(content, wiringRoutesMarker) {
+		return content, false
+	}
+	updated := ensureMainGoImport(content, importPath+"/internal/handler/ws")
+
+	if !strings.Contains(updated, "hub := ws.NewHub()") {
+		hub := websocketHubAnchor +
+			"\n\t// WebSocket hub pushing entity changes to subscribers\n" +
+			"\thub := ws.NewHub()\n" +
+			"\tgo hub.Run()\n" +
+			"\tdefer hub.Close()\n\n"
+		updated = strings.Replace(updated, websocketHubAnchor, hub, 1)
+	}
+
+	useCase := fmt.Sprintf("container.%sUseCase()", entity)
+	httpRoutes := fmt.Sprintf("apphttp.Setup%sRoutes(apiRouter, %s)", entity, useCase)
+	updated = strings.Replace(updated, httpRoutes,
+		fmt.Sprintf("apphttp.Setup%sRoutes(apiRouter, ws.New%sBroadcaster(%s, hub))", entity, entity, useCase), 1)
+
+	routeCall := fmt.Sprintf("ws.Setup%sRoutes(apiRouter, hub)", entity)
+	if !strings.Contains(updated, routeCall) {
+		insertion := fmt.Sprintf("\t%s // %s websocket\n%s", routeCall, strings.ToLower(entity), wiringRoutesMarker)
+		updated = strings.Replace(updated, wiringRoutesMarker, insertion, 1)
+	}
+	return updated, true
+}
+
+// printWebSocketInstructions prints how to wire the WebSocket routes by hand.
+func printWebSocketInstructions(entity string) {
+	ui.Blank()
+	ui.Section("Manual WebSocket integration")
+	ui.Println("1. Start the hub in main(), before registering routes:")
+	ui.Dim("      hub := ws.NewHub()")
+	ui.Dim("      go hub.Run()")
+	ui.Dim("      defer hub.Close()")
+	ui.Println("2. Wrap the use case so its changes are published:")
+	ui.Dim(fmt.Sprintf("      uc := ws.New%sBroadcaster(container.%sUseCase(), hub)", entity, entity))
+	ui.Println("3. Mount the endpoint:")
+	ui.Dim(fmt.Sprintf("      ws.Setup%sRoutes(router, hub)", entity))
+}
+
+// websocketHubTemplate is internal/handler/ws/hub.go.
+const websocketHubTemplate = `// Package ws pushes entity changes to WebSocket subscribers.
+package ws
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// writeWait is the time allowed to write a message to a client.
+	writeWait = 10 * time.Second
+	// pongWait is the time allowed to read the next pong from a client.
+	pongWait = 60 * time.Second
+	// pingPeriod is how often clients are pinged; it must be below pongWait.
+	pingPeriod = pongWait * 9 / 10
+	// maxMessageSize limits the messages clients may send.
+	maxMessageSize = 512
+	// sendBuffer is the number of events queued per client. Clients that fall
+	// further behind are disconnected.
+	sendBuffer = 64
+)
+
+// Event is a change pushed to the subscribers of a topic.
+type Event struct {
+	Topic string      ` + "`json:\"topic\"`" + `
+	Type  string      ` + "`json:\"type\"`" + `
+	Data  interface{} ` + "`json:\"data\"`" + `
+}
+
+// Hub tracks the connected clients of every topic and broadcasts events to
+// them. Run must be running for clients to connect and events to be sent.
+type Hub struct {
+	upgrader   websocket.Upgrader
+	clients    map[string]map[*client]bool
+	register   chan *client
+	unregister chan *client
+	broadcast  chan Event
+	done       chan struct{}
+	closeOnce  sync.Once
+}
+
+// NewHub creates a hub. Browsers may only connect from the origin the API is
+// served from; set Upgrader.CheckOrigin to accept other origins.
+func NewHub() *Hub {
+	return &Hub{
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+		},
+		clients:    make(map[string]map[*client]bool),
+		register:   make(chan *client),
+		unregister: make(chan *client),
+		broadcast:  make(chan Event, 256),
+		done:       make(chan struct{}),
+	}
+}
+
+// Run registers clients and delivers events until Close is called, then
+// disconnects every client.
+func (h *Hub) Run() {
+	for {
+		select {
+		case c := <-h.register:
+			if h.clients[c.topic] == nil {
+				h.clients[c.topic] = make(map[*client]bool)
+			}
+			h.clients[c.topic][c] = true
+		case c := <-h.unregister:
+			h.remove(c)
+		case event := <-h.broadcast:
+			message, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("websocket: encoding %s event: %v", event.Type, err)
+				continue
+			}
+			for c := range h.clients[event.Topic] {
+				select {
+				case c.send <- message:
+				default:
+					// The client does not keep up; disconnect it.
+					h.remove(c)
+				}
+			}
+		case <-h.done:
+			for _, clients := range h.clients {
+				for c := range clients {
+					h.remove(c)
+				}
+			}
+			return
+		}
+	}
+}
+
+// Close stops Run and disconnects every client. It is safe to call more than
+// once.
+func (h *Hub) Close() {
+	h.closeOnce.Do(func() { close(h.done) })
+}
+
+// Publish sends an event to the subscribers of topic. Events published after
+// Close are dropped.
+func (h *Hub) Publish(topic, eventType string, data interface{}) {
+	select {
+	case h.broadcast <- Event{Topic: topic, Type: eventType, Data: data}:
+	case <-h.done:
+	}
+}
+
+// Subscribe returns a handler that upgrades the request to a WebSocket
+// connection receiving the events of topic.
+func (h *Hub) Subscribe(topic string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := h.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// Upgrade has already replied with an HTTP error.
+			log.Printf("websocket: upgrading connection: %v", err)
+			return
+		}
+		c := &client{hub: h, conn: conn, topic: topic, send: make(chan []byte, sendBuffer)}
+		select {
+		case h.register <- c:
+		case <-h.done:
+			conn.Close()
+			return
+		}
+		go c.writePump()
+		go c.readPump()
+	}
+}
+
+// remove unregisters c and closes its send channel, which ends its writePump.
+func (h *Hub) remove(c *client) {
+	clients := h.clients[c.topic]
+	if !clients[c] {
+		return
+	}
+	delete(clients, c)
+	close(c.send)
+	if len(clients) == 0 {
+		delete(h.clients, c.topic)
+	}
+}
+
+// client is a WebSocket connection subscribed to one topic.
+type client struct {
+	hub   *Hub
+	conn  *websocket.Conn
+	topic string
+	send  chan []byte
+}
+
+// readPump reads until the connection fails, discarding what the client
+// sends, and unregisters the client. Missing pongs end the connection.
+func (c *client) readPump() {
+	defer func() {
+		select {
+		case c.hub.unregister <- c:
+		case <-c.hub.done:
+		}
+		c.conn.Close()
+	}()
+	c.conn.SetReadLimit(maxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("websocket: %s client: %v", c.topic, err)
+			}
+			return
+		}
+	}
+}
+
+// writePump sends queued events and pings to the client, and closes the
+// connection once the hub removes the client.
+func (c *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+	for {
+		select {
+		case message, ok := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				_ = c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+`
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateWebSocketHandler(t *testing.T) {
+	defer ensureTestUI(t)()
+	setupCLIProject(t, fullProductUseCase)
+
+	generateWebSocketHandler("Product", "lowercase", NewSafetyManager(false, true, false))
+
+	handler := readParsedGo(t, filepath.Join("internal", "handler", "ws", "product_ws.go"))
+	assert.Contains(t, handler, `const ProductTopic = "product"`)
+	assert.Contains(t, handler, "func NewProductBroadcaster(uc usecase.ProductUseCase, hub *Hub) *ProductBroadcaster")
+	assert.Contains(t, handler, `b.hub.Publish(ProductTopic, "product.created", output)`)
+	assert.Contains(t, handler, "if product, err := b.ProductUseCase.GetProduct(id); err == nil {")
+	assert.Contains(t, handler, `b.hub.Publish(ProductTopic, "product.deleted", map[string]int{"id": id})`)
+	assert.Contains(t, handler, `router.HandleFunc("/ws/products", hub.Subscribe(ProductTopic)).Methods("GET")`)
+
+	hub := readParsedGo(t, filepath.Join("internal", "handler", "ws", "hub.go"))
+	assert.Contains(t, hub, `"github.com/gorilla/websocket"`)
+	assert.Contains(t, hub, "func (h *Hub) Publish(topic, eventType string, data interface{})")
+}
+
+func TestBuildWebSocketHandler_DeclaredOperations(t *testing.T) {
+	handler := buildWebSocketHandler("testproject", "Note", []string{OpCreate, OpUpdate}, nil)
+	assert.Contains(t, handler, "func (b *NoteBroadcaster) CreateNote(")
+	assert.Contains(t, handler, `b.hub.Publish(NoteTopic, "note.updated", map[string]int{"id": id})`)
+	assert.NotContains(t, handler, "GetNote", "without a get operation updates publish the ID")
+	assert.NotContains(t, handler, "DeleteNote")
+}
+
+func TestWireWebSocketIntoMainGo(t *testing.T) {
+	main := `package main
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func main() {
+	router := mux.NewRouter()
+` + websocketHubAnchor + `	apphttp.SetupProductRoutes(apiRouter, container.ProductUseCase()) // product routes
+` + wiringRoutesMarker + `
+}
+`
+	updated, ok := wireWebSocketIntoMainGo(main, "Product", "testproject")
+	require.True(t, ok)
+	assert.Contains(t, updated, `"testproject/internal/handler/ws"`)
+	assert.Contains(t, updated, "apphttp.SetupProductRoutes(apiRouter, ws.NewProductBroadcaster(container.ProductUseCase(), hub))")
+	assert.Contains(t, updated, "ws.SetupProductRoutes(apiRouter, hub)")
+	assert.Less(t, strings.Index(updated, "hub := ws.NewHub()"), strings.Index(updated, "apphttp.SetupProductRoutes"))
+
+	again, ok := wireWebSocketIntoMainGo(updated, "Product", "testproject")
+	require.True(t, ok)
+	assert.Equal(t, updated, again)
+
+	_, ok = wireWebSocketIntoMainGo("package main\n\nfunc main() {}\n", "Product", "testproject")
+	assert.False(t, ok)
+}
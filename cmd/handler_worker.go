@@ -0,0 +1,582 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// workerConfigDecl declares the worker pool settings of pkg/config.
+const workerConfigDecl = `// WorkerConfig sizes the pool background workers process messages with.
+type WorkerConfig struct {
+	// Concurrency is the number of messages processed at the same time.
+	Concurrency int
+	// QueueSize is the number of messages waiting for a free goroutine before
+	// producers are blocked.
+	QueueSize int
+	// MessageTimeout bounds the processing of one message. Zero disables it.
+	MessageTimeout time.Duration
+}
+`
+
+// workerConfigLoad is the Worker element of the Config literal Load returns.
+const workerConfigLoad = `		Worker: WorkerConfig{
+			Concurrency:    getEnvAsInt("WORKER_CONCURRENCY", 4),
+			QueueSize:      getEnvAsInt("WORKER_QUEUE_SIZE", 100),
+			MessageTimeout: getEnvAsDuration("WORKER_MESSAGE_TIMEOUT", "30s"),
+		},
+`
+
+func generateWorkerHandler(entity, fileNamingConvention string, sm ...*SafetyManager) {
+	workerDir := filepath.Join(DirInternal, DirHandler, DirWorker)
+	_ = os.MkdirAll(workerDir, 0o755)
+
+	moduleName := getModuleName()
+	entityLower := strings.ToLower(entity)
+
+	// Apply naming convention to filename
+	var filename string
+	if fileNamingConvention == "snake_case" {
+		filename = filepath.Join(workerDir, toSnakeCase(entity)+"_worker.go")
+	} else if fileNamingConvention == "kebab-case" {
+		filename = filepath.Join(workerDir, toKebabCase(entity)+"-worker.go")
+	} else {
+		filename = filepath.Join(workerDir, entityLower+"_worker.go")
+	}
+
+	content := fmt.Sprintf(`package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"%[1]s/internal/usecase"
+)
+
+// %[2]sWorker processes %[3]s jobs on a Pool.
+type %[2]sWorker struct {
+	usecase usecase.%[2]sUseCase
+	pool    *Pool
+}
+
+func New%[2]sWorker(uc usecase.%[2]sUseCase, pool *Pool) *%[2]sWorker {
+	return &%[2]sWorker{usecase: uc, pool: pool}
+}
+
+// Enqueue%[2]sJob queues a %[3]s job, waiting while the pool's queue is full.
+func (w *%[2]sWorker) Enqueue%[2]sJob(ctx context.Context, jobData []byte) error {
+	return w.pool.Submit(ctx, func(ctx context.Context) error {
+		return w.Process%[2]sJob(ctx, jobData)
+	})
+}
+
+// EnqueueBatch%[2]sJob queues a batch of %[3]s jobs, waiting while the pool's
+// queue is full.
+func (w *%[2]sWorker) EnqueueBatch%[2]sJob(ctx context.Context, jobData []byte) error {
+	return w.pool.Submit(ctx, func(ctx context.Context) error {
+		return w.ProcessBatch%[2]sJob(ctx, jobData)
+	})
+}
+
+func (w *%[2]sWorker) Process%[2]sJob(ctx context.Context, jobData []byte) error {
+	var input usecase.Create%[2]sInput
+	if err := json.Unmarshal(jobData, &input); err != nil {
+		return fmt.Errorf("unmarshal %[3]s job: %%w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	output, err := w.usecase.Create%[2]s(input)
+	if err != nil {
+		return fmt.Errorf("process %[3]s job: %%w", err)
+	}
+
+	log.Printf("%[2]s job completed: %%v", output.Message)
+	return nil
+}
+
+// ProcessBatch%[2]sJob creates every %[3]s of the batch, skipping those that
+// fail. It stops when ctx is done, as the message timeout elapses.
+func (w *%[2]sWorker) ProcessBatch%[2]sJob(ctx context.Context, jobData []byte) error {
+	var inputs []usecase.Create%[2]sInput
+	if err := json.Unmarshal(jobData, &inputs); err != nil {
+		return fmt.Errorf("unmarshal %[3]s batch job: %%w", err)
+	}
+
+	for i, input := range inputs {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("%[3]s batch stopped after %%d of %%d: %%w", i, len(inputs), err)
+		}
+		if _, err := w.usecase.Create%[2]s(input); err != nil {
+			log.Printf("Failed to process %[3]s in batch: %%v", err)
+		}
+	}
+
+	log.Printf("Batch %[2]s job completed")
+	return nil
+}
+`, moduleName, entity, entityLower)
+
+	if err := writeGoFile(filename, content, sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing worker file: %v", err))
+		return
+	}
+
+	generateWorkerPoolFile(workerDir, moduleName, sm...)
+	if err := ensureWorkerConfig(sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Could not add the worker settings to pkg/config: %v", err))
+	}
+	generateHealthPackage(sm...)
+	registerWorkerReadiness(sm...)
+}
+
+// generateWorkerPoolFile writes internal/handler/worker/pool.go, the pool
+// every worker processes its jobs on. Like response.go, it is only rewritten
+// with --force.
+func generateWorkerPoolFile(dir, moduleName string, sm ...*SafetyManager) {
+	filename := filepath.Join(dir, "pool.go")
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+	if existing, err := os.ReadFile(filename); err == nil && !force {
+		if !strings.Contains(string(existing), "health.Register(") {
+			ui.Dim("   pool.go predates the worker heartbeat; regenerate it with --force to fail readiness when the pool stalls")
+		}
+		return
+	}
+
+	content := fmt.Sprintf(workerPoolTemplate, moduleName)
+	if err := writeGoFile(filename, content, sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing worker pool: %v", err))
+	}
+}
+
+// ensureWorkerConfig adds WorkerConfig to the pkg/config of projects created
+// before it was generated.
+func ensureWorkerConfig(sm ...*SafetyManager) error {
+	return ensureConfigSection("Worker", "WorkerConfig", workerConfigDecl, workerConfigLoad, sm...)
+}
+
+// healthPackagePath is pkg/health/health.go, the registry of the checks the
+// readiness endpoint runs besides the database.
+var healthPackagePath = filepath.Join(DirPkg, "health", "health.go")
+
+// readinessAnchor is the success response of the readinessHandler of every
+// generated main.go; the registered checks run before it.
+const readinessAnchor = "\tw.WriteHeader(http.StatusOK)\n\tw.Write([]byte(\"Ready\"))\n"
+
+// readinessHealthCheck fails readinessHandler when a check of pkg/health
+// fails.
+const readinessHealthCheck = `	// Registered checks, such as the heartbeats of background workers
+	if err := health.Check(r.Context()); err != nil {
+		http.Error(w, fmt.Sprintf("Not ready: %v", err), http.StatusServiceUnavailable)
+		return
+	}
+
+`
+
+// generateHealthPackage writes pkg/health on first use. Like pool.go, it is
+// only rewritten with --force.
+func generateHealthPackage(sm ...*SafetyManager) {
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+	if _, err := os.Stat(healthPackagePath); err == nil && !force {
+		return
+	}
+	if err := writeGoFile(healthPackagePath, healthPackageTemplate, sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing health package: %v", err))
+	}
+}
+
+// registerWorkerReadiness makes the readinessHandler of main.go run the
+// checks of pkg/health, which the worker pool registers its heartbeat with,
+// printing the manual step when main.go does not have the expected layout.
+func registerWorkerReadiness(sm ...*SafetyManager) {
+	if len(sm) > 0 && sm[0] != nil && sm[0].DryRun {
+		return
+	}
+	mainPath, found := findMainGoPath()
+	if !found {
+		printWorkerReadinessInstructions()
+		return
+	}
+	defer lockSharedFile(mainPath)()
+	content, err := os.ReadFile(mainPath)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not read main.go: %v", err))
+		printWorkerReadinessInstructions()
+		return
+	}
+	updated, ok := wireHealthChecksIntoMainGo(string(content), getImportPath(getModuleName()))
+	if !ok {
+		ui.Warning("main.go has no readinessHandler to add the worker heartbeat to")
+		printWorkerReadinessInstructions()
+		return
+	}
+	if updated == string(content) {
+		return
+	}
+	if err := writeMainGoInPlace(mainPath, updated); err != nil {
+		ui.Warning(fmt.Sprintf("Could not add the worker heartbeat to main.go: %v", err))
+		printWorkerReadinessInstructions()
+		return
+	}
+	ui.Dim(fmt.Sprintf("   Worker heartbeat checked by /health/ready in %s", mainPath))
+}
+
+// wireHealthChecksIntoMainGo runs the checks of pkg/health in readinessHandler
+// before it reports ready. It is idempotent and reports false when main.go
+// has no generated readinessHandler.
+func wireHealthChecksIntoMainGo(content, importPath string) (string, bool) {
+	if strings.Contains(content, "health.Check(r.Context())") {
+		return content, true
+	}
+	start := strings.Index(content, "func readinessHandler(")
+	if start == -1 {
+		return content, false
+	}
+	anchor := strings.Index(content[start:], readinessAnchor)
+	if anchor == -1 {
+		return content, false
+	}
+	at := start + anchor
+	updated := content[:at] + readinessHealthCheck + content[at:]
+	return ensureMainGoImport(updated, importPath+"/pkg/health"), true
+}
+
+// printWorkerReadinessInstructions prints how to check the worker heartbeat
+// by hand.
+func printWorkerReadinessInstructions() {
+	ui.Blank()
+	ui.Section("Manual worker readiness")
+	ui.Println("Fail the readiness endpoint when the worker pool stalls:")
+	ui.Dim("      if err := health.Check(r.Context()); err != nil {")
+	ui.Dim("          http.Error(w, err.Error(), http.StatusServiceUnavailable)")
+	ui.Dim("          return")
+	ui.Dim("      }")
+}
+
+// healthPackageTemplate is pkg/health/health.go.
+const healthPackageTemplate = `// Package health collects the checks the readiness endpoint runs besides the
+// database, such as the heartbeats of background workers.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Checker reports whether a component is able to do its work.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to Checker.
+type CheckerFunc func(ctx context.Context) error
+
+// Check calls f.
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+var (
+	mu       sync.RWMutex
+	checkers = make(map[string]Checker)
+)
+
+// Register adds checker under name, replacing the one registered before
+// under the same name.
+func Register(name string, checker Checker) {
+	mu.Lock()
+	defer mu.Unlock()
+	checkers[name] = checker
+}
+
+// Unregister removes the checker registered under name.
+func Unregister(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(checkers, name)
+}
+
+// Check runs the registered checkers in the order of their names and returns
+// the errors of those that fail, prefixed with their names. Checkers run one
+// after another, so they should not block.
+func Check(ctx context.Context) error {
+	mu.RLock()
+	names := make([]string, 0, len(checkers))
+	registered := make(map[string]Checker, len(checkers))
+	for name, checker := range checkers {
+		names = append(names, name)
+		registered[name] = checker
+	}
+	mu.RUnlock()
+
+	sort.Strings(names)
+	var errs []error
+	for _, name := range names {
+		if err := registered[name].Check(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+`
+
+const workerPoolTemplate = `package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"%[1]s/pkg/config"
+	"%[1]s/pkg/health"
+)
+
+// poolName names the pool in the readiness checks and labels its metrics.
+const poolName = "worker"
+
+// minStallTimeout is the least time a busy pool may go without progress
+// before Check reports it stalled.
+const minStallTimeout = time.Minute
+
+// ErrPoolClosed is returned by Submit once the pool is closed.
+var ErrPoolClosed = errors.New("worker pool is closed")
+
+var (
+	queueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "worker_queue_depth",
+		Help: "Jobs waiting in the queue for a free worker.",
+	}, []string{"queue"})
+	jobsInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "worker_jobs_in_flight",
+		Help: "Jobs being processed.",
+	}, []string{"queue"})
+	queueLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "worker_lag_seconds",
+		Help: "Time the last job started waited in the queue.",
+	}, []string{"queue"})
+	lastHeartbeat = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "worker_last_heartbeat_timestamp_seconds",
+		Help: "Unix time a job last started or finished.",
+	}, []string{"queue"})
+	jobsProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "worker_jobs_total",
+		Help: "Jobs processed, by result.",
+	}, []string{"queue", "result"})
+)
+
+// Job processes one message. Its context is canceled when the message timeout
+// elapses or the pool's context is done; work that ignores it is not
+// interrupted.
+type Job func(ctx context.Context) error
+
+// queuedJob is a job waiting in the queue since queuedAt.
+type queuedJob struct {
+	job      Job
+	queuedAt time.Time
+}
+
+// PoolStats is a snapshot of the progress of a pool.
+type PoolStats struct {
+	Queued   int
+	InFlight int
+	// Processed and Failed count the jobs that succeeded and failed.
+	Processed uint64
+	Failed    uint64
+	// Lag is the time the last job started waited in the queue.
+	Lag time.Duration
+	// LastHeartbeat is when a job last started or finished, or the idle
+	// pool was given work.
+	LastHeartbeat time.Time
+}
+
+// Pool processes jobs on a fixed number of goroutines. Its queue is bounded:
+// Submit blocks while it is full, slowing producers down instead of buffering
+// without limit. A job that panics fails alone; the goroutine running it goes
+// on with the next one.
+//
+// Every job started or finished is a heartbeat. Start registers the pool
+// with pkg/health, so readiness fails while the pool has work but no
+// heartbeat for twice the message timeout, at least a minute: its goroutines
+// are stuck in jobs that ignore their context.
+type Pool struct {
+	cfg          config.WorkerConfig
+	stallTimeout time.Duration
+	queue        chan queuedJob
+	wg           sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+
+	heartbeat atomic.Int64 // unix nanoseconds
+	lag       atomic.Int64 // nanoseconds
+	inFlight  atomic.Int64
+	processed atomic.Uint64
+	failed    atomic.Uint64
+}
+
+// NewPool returns a pool sized by cfg. It processes nothing until Start.
+func NewPool(cfg config.WorkerConfig) *Pool {
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+	if cfg.QueueSize < 0 {
+		cfg.QueueSize = 0
+	}
+	stallTimeout := 2 * cfg.MessageTimeout
+	if stallTimeout < minStallTimeout {
+		stallTimeout = minStallTimeout
+	}
+	return &Pool{cfg: cfg, stallTimeout: stallTimeout, queue: make(chan queuedJob, cfg.QueueSize)}
+}
+
+// Start starts the pool's goroutines and registers its heartbeat check with
+// pkg/health. ctx is the parent of every job's context.
+func (p *Pool) Start(ctx context.Context) {
+	p.beat()
+	health.Register(poolName, p)
+	for i := 0; i < p.cfg.Concurrency; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for queued := range p.queue {
+				p.process(ctx, queued)
+			}
+		}()
+	}
+}
+
+// Submit queues job, waiting while the queue is full until ctx is done.
+func (p *Pool) Submit(ctx context.Context, job Job) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	// An idle pool has made no progress for a while; the wait for the
+	// job starts now.
+	if len(p.queue) == 0 && p.inFlight.Load() == 0 {
+		p.beat()
+	}
+	select {
+	case p.queue <- queuedJob{job: job, queuedAt: time.Now()}:
+		queueDepth.WithLabelValues(poolName).Set(float64(len(p.queue)))
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Len returns the number of jobs waiting in the queue.
+func (p *Pool) Len() int {
+	return len(p.queue)
+}
+
+// Stats returns the progress of the pool.
+func (p *Pool) Stats() PoolStats {
+	return PoolStats{
+		Queued:        len(p.queue),
+		InFlight:      int(p.inFlight.Load()),
+		Processed:     p.processed.Load(),
+		Failed:        p.failed.Load(),
+		Lag:           time.Duration(p.lag.Load()),
+		LastHeartbeat: time.Unix(0, p.heartbeat.Load()),
+	}
+}
+
+// Check implements health.Checker: it fails once the pool is closed, or
+// while it has work but no heartbeat within the stall timeout.
+func (p *Pool) Check(ctx context.Context) error {
+	p.mu.RLock()
+	closed := p.closed
+	p.mu.RUnlock()
+	if closed {
+		return ErrPoolClosed
+	}
+
+	stats := p.Stats()
+	if stats.Queued == 0 && stats.InFlight == 0 {
+		return nil
+	}
+	if idle := time.Since(stats.LastHeartbeat); idle > p.stallTimeout {
+		return fmt.Errorf("worker pool stalled: no progress for %%s with %%d jobs queued and %%d in flight",
+			idle.Round(time.Second), stats.Queued, stats.InFlight)
+	}
+	return nil
+}
+
+// Close stops accepting jobs and waits until the queued ones are processed.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+}
+
+// process runs a job taken from the queue, recording its heartbeats and
+// metrics.
+func (p *Pool) process(ctx context.Context, queued queuedJob) {
+	lag := time.Since(queued.queuedAt)
+	p.lag.Store(int64(lag))
+	p.inFlight.Add(1)
+	p.beat()
+	queueDepth.WithLabelValues(poolName).Set(float64(len(p.queue)))
+	queueLag.WithLabelValues(poolName).Set(lag.Seconds())
+	jobsInFlight.WithLabelValues(poolName).Inc()
+
+	err := p.run(ctx, queued.job)
+
+	p.inFlight.Add(-1)
+	p.beat()
+	jobsInFlight.WithLabelValues(poolName).Dec()
+	if err != nil {
+		p.failed.Add(1)
+		jobsProcessed.WithLabelValues(poolName, "error").Inc()
+		log.Printf("worker: %%v", err)
+		return
+	}
+	p.processed.Add(1)
+	jobsProcessed.WithLabelValues(poolName, "ok").Inc()
+}
+
+// beat records a heartbeat.
+func (p *Pool) beat() {
+	now := time.Now()
+	p.heartbeat.Store(now.UnixNano())
+	lastHeartbeat.WithLabelValues(poolName).Set(float64(now.UnixNano()) / 1e9)
+}
+
+// run processes job within the message timeout, turning a panic into an
+// error.
+func (p *Pool) run(ctx context.Context, job Job) (err error) {
+	if p.cfg.MessageTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.cfg.MessageTimeout)
+		defer cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %%v\n%%s", r, debug.Stack())
+		}
+	}()
+	return job(ctx)
+}
+`
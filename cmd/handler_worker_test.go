@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// legacyConfig is a pkg/config generated before the worker settings.
+const legacyConfig = `package config
+
+import "os"
+
+type Config struct {
+	Port   string
+	Server ServerConfig
+}
+
+type ServerConfig struct {
+	ReadTimeout string
+}
+
+func Load() *Config {
+	return &Config{
+		Port:   getEnv("PORT", "8080"),
+		Server: ServerConfig{ReadTimeout: getEnv("SERVER_READ_TIMEOUT", "10s")},
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+`
+
+func TestGenerateWorkerHandler_Pool(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+	writeTestFile(t, ".", filepath.Join("pkg", "config", "config.go"), legacyConfig)
+
+	generateWorkerHandler("Email", "lowercase", NewSafetyManager(false, false, false))
+
+	dir := filepath.Join(DirInternal, DirHandler, DirWorker)
+	worker := readParsedGo(t, filepath.Join(dir, "email_worker.go"))
+	assert.Contains(t, worker, "func NewEmailWorker(uc usecase.EmailUseCase, pool *Pool) *EmailWorker {")
+	assert.Contains(t, worker, "return w.pool.Submit(ctx, func(ctx context.Context) error {\n\t\treturn w.ProcessEmailJob(ctx, jobData)")
+	assert.Contains(t, worker, "return fmt.Errorf(\"email batch stopped after %d of %d: %w\", i, len(inputs), err)")
+
+	pool := readParsedGo(t, filepath.Join(dir, "pool.go"))
+	assert.Contains(t, pool, `"testproject/pkg/config"`)
+	assert.Contains(t, pool, "ctx, cancel = context.WithTimeout(ctx, p.cfg.MessageTimeout)")
+	assert.Contains(t, pool, "if r := recover(); r != nil {")
+	assert.Contains(t, pool, "case p.queue <- queuedJob{job: job, queuedAt: time.Now()}:")
+	assert.Contains(t, pool, "func (p *Pool) Len() int {\n\treturn len(p.queue)\n}")
+	assert.Contains(t, pool, `"testproject/pkg/health"`)
+	assert.Contains(t, pool, "health.Register(poolName, p)")
+	assert.Contains(t, pool, "func (p *Pool) Check(ctx context.Context) error {")
+	assert.Contains(t, pool, `Name: "worker_queue_depth",`)
+	assert.Contains(t, pool, `Name: "worker_lag_seconds",`)
+
+	registry := readParsedGo(t, healthPackagePath)
+	assert.Contains(t, registry, "func Register(name string, checker Checker) {")
+	assert.Contains(t, registry, "return errors.Join(errs...)")
+
+	config := readParsedGo(t, filepath.Join("pkg", "config", "config.go"))
+	assert.Contains(t, config, "\tServer ServerConfig\n\tWorker WorkerConfig\n}")
+	assert.Contains(t, config, "}\n\n// WorkerConfig sizes the pool")
+	assert.Contains(t, config, `Concurrency:    getEnvAsInt("WORKER_CONCURRENCY", 4),`)
+	assert.Contains(t, config, "import (\n\t\"os\"\n\t\"time\"\n)")
+
+	// A second worker leaves the pool and the settings alone.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pool.go"), []byte("package worker\n"), 0o644))
+	generateWorkerHandler("Invoice", "lowercase", NewSafetyManager(false, false, false))
+	pool = readParsedGo(t, filepath.Join(dir, "pool.go"))
+	assert.Equal(t, "package worker\n", pool)
+	updated := readParsedGo(t, filepath.Join("pkg", "config", "config.go"))
+	assert.Equal(t, config, updated)
+}
+
+func TestWireHealthChecksIntoMainGo(t *testing.T) {
+	main := `package main
+
+import (
+	"fmt"
+	"net/http"
+)
+
+func readinessHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if all dependencies are ready
+	if err := checkDatabase(); err != nil {
+		http.Error(w, fmt.Sprintf("Database not ready: %v", err), http.StatusServiceUnavailable)
+		return
+	}
+
+` + readinessAnchor + `}
+`
+	updated, ok := wireHealthChecksIntoMainGo(main, "testproject")
+	require.True(t, ok)
+	assert.Contains(t, updated, `"testproject/pkg/health"`)
+	assert.Contains(t, updated, "if err := health.Check(r.Context()); err != nil {")
+	assert.Less(t, strings.Index(updated, "checkDatabase()"), strings.Index(updated, "health.Check"))
+	assert.Less(t, strings.Index(updated, "health.Check"), strings.Index(updated, readinessAnchor))
+
+	again, ok := wireHealthChecksIntoMainGo(updated, "testproject")
+	require.True(t, ok)
+	assert.Equal(t, updated, again)
+
+	_, ok = wireHealthChecksIntoMainGo("package main\n\nfunc main() {}\n", "testproject")
+	assert.False(t, ok)
+}
@@ -0,0 +1,807 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// validateImportExport reports why goca feature --import-export cannot
+// generate the bulk endpoints of a feature: the batches are saved in GORM
+// transactions through the combined service, which bypasses the audit log,
+// the tenant scope and the command and query services.
+func validateImportExport(database, handlers string, audit, multiTenant, cqrs bool) error {
+	switch {
+	case !isGormDatabase(database):
+		return fmt.Errorf("--import-export saves batches in GORM transactions and does not support %s", database)
+	case !strings.Contains(handlers, HandlerHTTP):
+		return errors.New("--import-export serves its endpoints through the HTTP handler")
+	case audit:
+		return errors.New("--import-export cannot be combined with --audit")
+	case multiTenant:
+		return errors.New("--import-export cannot be combined with --multi-tenant")
+	case cqrs:
+		return errors.New("--import-export cannot be combined with --cqrs")
+	}
+	return nil
+}
+
+// generateImportExport writes the bulk import and export of entity on top of
+// its generated layers: SaveBatch and FindPage on the GORM repository and the
+// service, which implement the <Entity>BatchRepository and
+// <Entity>BatchUseCase side interfaces, and the HTTP handler serving
+// POST /<entities>/import and GET /<entities>/export.
+func generateImportExport(entity string, sm ...*SafetyManager) error {
+	repoDir := filepath.Join(DirInternal, DirRepository)
+	if findTypeFile(repoDir, entity+"Criteria") == "" {
+		return fmt.Errorf("%s has no %sCriteria to filter exports by: it needs a string, number, bool or time field", entity, entity)
+	}
+	repoName, repoVar, err := gormRepositoryType(repoDir, entity)
+	if err != nil {
+		return err
+	}
+	serviceName := strings.ToLower(entity) + "Service"
+	if findTypeFile(filepath.Join(DirInternal, DirUseCase), serviceName) == "" {
+		return fmt.Errorf("%s not found in %s", serviceName, filepath.Join(DirInternal, DirUseCase))
+	}
+	entityFile, err := findEntityFile(entity)
+	if err != nil {
+		return err
+	}
+	src, err := parseGoSource(entityFile)
+	if err != nil {
+		return err
+	}
+	validates := findMethod(src.file, entity, "Validate") != nil
+
+	importPath := getImportPath(getModuleName())
+	entityLower := strings.ToLower(entity)
+	httpDir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	files := map[string]string{
+		filepath.Join(repoDir, entityLower+"_batch.go"):                 batchRepositorySource(entity, repoName, repoVar, importPath),
+		filepath.Join(DirInternal, DirUseCase, entityLower+"_batch.go"): batchUseCaseSource(entity, serviceName, importPath, validates),
+		filepath.Join(httpDir, entityLower+"_import_export_handler.go"): importExportHandlerSource(entity, importPath, projectResponseFormat()),
+	}
+	for path, content := range files {
+		if err := writeGoFile(path, content, sm...); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+	}
+	writeOnce(filepath.Join(DirInternal, DirUseCase, "batch.go"), batchRowErrorSource, "batch row errors", sm...)
+	writeOnce(filepath.Join(httpDir, "import_export.go"), "package "+DirHTTP+"\n"+fmt.Sprintf(importExportSource, importPath), "import and export helpers", sm...)
+	generateHTTPQueryFile(httpDir, sm...)
+	return nil
+}
+
+// gormRepositoryType returns the GORM repository of entity in dir, the
+// unexported <database><Entity>Repository struct holding a db *gorm.DB, and
+// the receiver its methods use.
+func gormRepositoryType(dir, entity string) (name, recv string, err error) {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, parseErr := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+		if parseErr != nil {
+			continue
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok || ast.IsExported(ts.Name.Name) || !strings.HasSuffix(ts.Name.Name, entity+"Repository") || !holdsGormDB(st) {
+					continue
+				}
+				recv = strings.ToLower(ts.Name.Name[:1])
+				if fn := findMethod(file, ts.Name.Name, "Save"); fn != nil && receiverVar(fn) != "" {
+					recv = receiverVar(fn)
+				}
+				return ts.Name.Name, recv, nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("no GORM repository of %s found in %s", entity, dir)
+}
+
+// holdsGormDB reports whether st has a db *gorm.DB field.
+func holdsGormDB(st *ast.StructType) bool {
+	for _, f := range st.Fields.List {
+		star, ok := f.Type.(*ast.StarExpr)
+		if !ok || len(f.Names) != 1 || f.Names[0].Name != "db" {
+			continue
+		}
+		if sel, ok := star.X.(*ast.SelectorExpr); ok && sel.Sel.Name == "DB" {
+			if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "gorm" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// batchRepositorySource returns internal/repository/<entity>_batch.go: the
+// <Entity>BatchRepository interface and its GORM implementation.
+func batchRepositorySource(entity, repoName, recv, importPath string) string {
+	entityVar := strings.ToLower(entity)
+	var b strings.Builder
+	b.WriteString("package repository\n\n")
+	b.WriteString("import (\n")
+	b.WriteString("\t\"context\"\n\n")
+	fmt.Fprintf(&b, "\t\"%s/internal/domain\"\n\n", importPath)
+	b.WriteString("\t\"gorm.io/gorm\"\n")
+	b.WriteString("\t\"gorm.io/gorm/clause\"\n")
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(&b, "// %sBatchRepository saves and pages through %s entities in bulk, for\n", entity, entity)
+	fmt.Fprintf(&b, "// imports and exports. The GORM repository implements it:\n")
+	fmt.Fprintf(&b, "// batch, ok := repo.(repository.%sBatchRepository)\n", entity)
+	fmt.Fprintf(&b, "type %sBatchRepository interface {\n", entity)
+	fmt.Fprintf(&b, "\tSaveBatch(ctx context.Context, %s []domain.%s) error\n", toPlural(entityVar), entity)
+	fmt.Fprintf(&b, "\tFindPage(ctx context.Context, criteria %sCriteria, limit, offset int) ([]domain.%s, int64, error)\n", entity, entity)
+	b.WriteString("}\n\n")
+
+	b.WriteString("// SaveBatch inserts the entities in one transaction, 100 per statement.\n")
+	b.WriteString("// When one fails none is saved.\n")
+	fmt.Fprintf(&b, "func (%s *%s) SaveBatch(ctx context.Context, %s []domain.%s) error {\n", recv, repoName, toPlural(entityVar), entity)
+	fmt.Fprintf(&b, "\tif len(%s) == 0 {\n", toPlural(entityVar))
+	b.WriteString("\t\treturn nil\n")
+	b.WriteString("\t}\n")
+	fmt.Fprintf(&b, "\treturn %s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {\n", recv)
+	fmt.Fprintf(&b, "\t\treturn tx.CreateInBatches(%s, 100).Error\n", toPlural(entityVar))
+	b.WriteString("\t})\n")
+	b.WriteString("}\n\n")
+
+	b.WriteString("// FindPage returns at most limit entities matching criteria after the first\n")
+	b.WriteString("// offset, in primary key order, and the number of entities matching.\n")
+	fmt.Fprintf(&b, "func (%s *%s) FindPage(ctx context.Context, criteria %sCriteria, limit, offset int) ([]domain.%s, int64, error) {\n", recv, repoName, entity, entity)
+	b.WriteString("\tfilter := func(db *gorm.DB) *gorm.DB {\n")
+	b.WriteString("\t\tfor _, f := range criteria.Filters() {\n")
+	b.WriteString("\t\t\tvalue := f.Value\n")
+	b.WriteString("\t\t\tif f.Op == FilterLike {\n")
+	b.WriteString("\t\t\t\tvalue = \"%\" + f.Value.(string) + \"%\"\n")
+	b.WriteString("\t\t\t}\n")
+	b.WriteString("\t\t\tdb = db.Where(f.Column+\" \"+f.Op+\" ?\", value)\n")
+	b.WriteString("\t\t}\n")
+	b.WriteString("\t\treturn db\n")
+	b.WriteString("\t}\n")
+	fmt.Fprintf(&b, "\tdb := %s.db.WithContext(ctx)\n", recv)
+	b.WriteString("\tvar total int64\n")
+	fmt.Fprintf(&b, "\tif err := db.Model(&domain.%s{}).Scopes(filter).Count(&total).Error; err != nil {\n", entity)
+	b.WriteString("\t\treturn nil, 0, err\n")
+	b.WriteString("\t}\n")
+	fmt.Fprintf(&b, "\t%s := []domain.%s{}\n", toPlural(entityVar), entity)
+	b.WriteString("\torder := clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey}}\n")
+	fmt.Fprintf(&b, "\terr := db.Scopes(filter).Order(order).Limit(limit).Offset(offset).Find(&%s).Error\n", toPlural(entityVar))
+	fmt.Fprintf(&b, "\treturn %s, total, err\n", toPlural(entityVar))
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// batchUseCaseSource returns internal/usecase/<entity>_batch.go: the
+// <Entity>BatchUseCase interface and its methods on the service, which
+// validate the entities like Create<Entity> does when the entity has a
+// Validate method.
+func batchUseCaseSource(entity, serviceName, importPath string, validates bool) string {
+	entityVar := strings.ToLower(entity)
+	recv := string(serviceName[0])
+	var b strings.Builder
+	b.WriteString("package usecase\n\n")
+	b.WriteString("import (\n")
+	b.WriteString("\t\"context\"\n")
+	b.WriteString("\t\"errors\"\n\n")
+	fmt.Fprintf(&b, "\t\"%s/internal/domain\"\n", importPath)
+	fmt.Fprintf(&b, "\t\"%s/internal/repository\"\n", importPath)
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(&b, "// %sBatchUseCase saves and reads %s entities in bulk, for imports and\n", entity, entity)
+	fmt.Fprintf(&b, "// exports. The service implements it when its repository implements\n")
+	fmt.Fprintf(&b, "// repository.%sBatchRepository; handlers find it by type assertion.\n", entity)
+	fmt.Fprintf(&b, "type %sBatchUseCase interface {\n", entity)
+	fmt.Fprintf(&b, "\tSaveBatch(ctx context.Context, %s []domain.%s) ([]BatchRowError, error)\n", toPlural(entityVar), entity)
+	fmt.Fprintf(&b, "\tFindPage(ctx context.Context, input Export%sInput, limit, offset int) ([]domain.%s, int64, error)\n", entity, entity)
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// Export%sInput filters FindPage; see repository.%sCriteria.\n", entity, entity)
+	fmt.Fprintf(&b, "type Export%sInput = repository.%sCriteria\n\n", entity, entity)
+
+	fmt.Fprintf(&b, "// errNo%sBatches is returned when the repository cannot save batches, such\n", entity)
+	b.WriteString("// as one wrapped by a decorator.\n")
+	fmt.Fprintf(&b, "var errNo%sBatches = errors.New(\"the %s repository does not implement repository.%sBatchRepository\")\n\n", entity, entityVar, entity)
+
+	if validates {
+		b.WriteString("// SaveBatch validates the entities and saves the valid ones in one\n")
+		b.WriteString("// transaction. It returns the entities it rejected; when saving fails, none\n")
+		b.WriteString("// of the others is saved.\n")
+	} else {
+		b.WriteString("// SaveBatch saves the entities in one transaction; when saving fails, none\n")
+		b.WriteString("// is saved.\n")
+	}
+	fmt.Fprintf(&b, "func (%s *%s) SaveBatch(ctx context.Context, %s []domain.%s) ([]BatchRowError, error) {\n", recv, serviceName, toPlural(entityVar), entity)
+	fmt.Fprintf(&b, "\trepo, ok := %s.repo.(repository.%sBatchRepository)\n", recv, entity)
+	b.WriteString("\tif !ok {\n")
+	fmt.Fprintf(&b, "\t\treturn nil, errNo%sBatches\n", entity)
+	b.WriteString("\t}\n")
+	if validates {
+		b.WriteString("\tvar rejected []BatchRowError\n")
+		fmt.Fprintf(&b, "\tvalid := make([]domain.%s, 0, len(%s))\n", entity, toPlural(entityVar))
+		fmt.Fprintf(&b, "\tfor i := range %s {\n", toPlural(entityVar))
+		fmt.Fprintf(&b, "\t\tif err := %s[i].Validate(); err != nil {\n", toPlural(entityVar))
+		b.WriteString("\t\t\trejected = append(rejected, BatchRowError{Index: i, Err: err})\n")
+		b.WriteString("\t\t\tcontinue\n")
+		b.WriteString("\t\t}\n")
+		fmt.Fprintf(&b, "\t\tvalid = append(valid, %s[i])\n", toPlural(entityVar))
+		b.WriteString("\t}\n")
+		b.WriteString("\treturn rejected, repo.SaveBatch(ctx, valid)\n")
+	} else {
+		fmt.Fprintf(&b, "\treturn nil, repo.SaveBatch(ctx, %s)\n", toPlural(entityVar))
+	}
+	b.WriteString("}\n\n")
+
+	b.WriteString("// FindPage returns at most limit entities matching input after the first\n")
+	b.WriteString("// offset, and the number of entities matching.\n")
+	fmt.Fprintf(&b, "func (%s *%s) FindPage(ctx context.Context, input Export%sInput, limit, offset int) ([]domain.%s, int64, error) {\n", recv, serviceName, entity, entity)
+	fmt.Fprintf(&b, "\trepo, ok := %s.repo.(repository.%sBatchRepository)\n", recv, entity)
+	b.WriteString("\tif !ok {\n")
+	fmt.Fprintf(&b, "\t\treturn nil, 0, errNo%sBatches\n", entity)
+	b.WriteString("\t}\n")
+	b.WriteString("\treturn repo.FindPage(ctx, input, limit, offset)\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// batchRowErrorSource is internal/usecase/batch.go, shared by the batch use
+// cases of every entity.
+const batchRowErrorSource = `package usecase
+
+// BatchRowError is an entity a SaveBatch rejected: its index in the batch and
+// why.
+type BatchRowError struct {
+	Index int
+	Err   error
+}
+`
+
+// importExportHandlerSource returns
+// internal/handler/http/<entity>_import_export_handler.go, whose routes are
+// registered in main.go before those of the entity handler: /<entities>/{id}
+// would otherwise match /<entities>/export.
+func importExportHandlerSource(entity, importPath, format string) string {
+	entityVar := strings.ToLower(entity)
+	handlerName := entity + "ImportExportHandler"
+	path := "/" + toPlural(entityVar)
+
+	// Fields hidden from HTTP are left out of exports.
+	rowType, rows := "domain."+entity, toPlural(entityVar)
+	if loadProjection(entity, HandlerHTTP) != nil {
+		rowType, rows = entity+"View", fmt.Sprintf("new%sViews(%s)", entity, toPlural(entityVar))
+	}
+
+	var b strings.Builder
+	b.WriteString("package " + DirHTTP + "\n\n")
+	b.WriteString("import (\n")
+	if format == ResponseFormatRaw {
+		b.WriteString("\t\"encoding/json\"\n")
+	}
+	b.WriteString("\t\"log\"\n")
+	b.WriteString("\t\"net/http\"\n\n")
+	b.WriteString("\t\"github.com/gorilla/mux\"\n")
+	fmt.Fprintf(&b, "\t\"%s/internal/domain\"\n", importPath)
+	fmt.Fprintf(&b, "\t\"%s/internal/usecase\"\n", importPath)
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(&b, "// %s imports and exports %s entities in bulk.\n", handlerName, entity)
+	fmt.Fprintf(&b, "type %s struct {\n", handlerName)
+	fmt.Fprintf(&b, "\tusecase usecase.%sBatchUseCase\n", entity)
+	b.WriteString("}\n\n")
+	fmt.Fprintf(&b, "func New%s(uc usecase.%sBatchUseCase) *%s {\n", handlerName, entity, handlerName)
+	fmt.Fprintf(&b, "\treturn &%s{usecase: uc}\n", handlerName)
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// Setup%sImportExportRoutes mounts POST %s/import and GET %s/export.\n", entity, path, path)
+	fmt.Fprintf(&b, "// Register them before Setup%sRoutes, whose %s/{id} would match\n", entity, path)
+	fmt.Fprintf(&b, "// %s/export. uc must implement usecase.%sBatchUseCase, as the service does;\n", path, entity)
+	b.WriteString("// the routes are left out when it does not.\n")
+	fmt.Fprintf(&b, "func Setup%sImportExportRoutes(router *mux.Router, uc usecase.%sUseCase) {\n", entity, entity)
+	fmt.Fprintf(&b, "\tbatch, ok := uc.(usecase.%sBatchUseCase)\n", entity)
+	b.WriteString("\tif !ok {\n")
+	fmt.Fprintf(&b, "\t\tlog.Printf(\"%s import and export routes not registered: %%T does not implement usecase.%sBatchUseCase\", uc)\n", entityVar, entity)
+	b.WriteString("\t\treturn\n")
+	b.WriteString("\t}\n")
+	fmt.Fprintf(&b, "\thandler := New%s(batch)\n", handlerName)
+	fmt.Fprintf(&b, "\trouter.HandleFunc(\"%s/import\", handler.Import%s).Methods(\"POST\")\n", path, toPlural(entity))
+	fmt.Fprintf(&b, "\trouter.HandleFunc(\"%s/export\", handler.Export%s).Methods(\"GET\")\n", path, toPlural(entity))
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// Import%s saves the %s entities of a CSV file (Content-Type: text/csv),\n", toPlural(entity), entity)
+	b.WriteString("// whose header row names their JSON fields, or of a JSON array, chunk rows\n")
+	b.WriteString("// per transaction (?chunk=, default 500). It answers a report of the rows\n")
+	b.WriteString("// imported and of those that failed and why.\n")
+	fmt.Fprintf(&b, "func (h *%s) Import%s(w http.ResponseWriter, r *http.Request) {\n", handlerName, toPlural(entity))
+	b.WriteString("\tchunk, err := importChunk(r)\n")
+	b.WriteString("\tif err != nil {\n")
+	writeHandlerError(&b, format, "http.StatusBadRequest", "err.Error()")
+	b.WriteString("\t\treturn\n")
+	b.WriteString("\t}\n\n")
+	b.WriteString("\tvar report ImportReport\n")
+	fmt.Fprintf(&b, "\terr = readImport(r, chunk, &report, func(%s []domain.%s, rows []int) {\n", toPlural(entityVar), entity)
+	fmt.Fprintf(&b, "\t\trejected, err := h.usecase.SaveBatch(r.Context(), %s)\n", toPlural(entityVar))
+	b.WriteString("\t\treport.saved(rows, rejected, err)\n")
+	b.WriteString("\t})\n")
+	b.WriteString("\tif err != nil {\n")
+	writeHandlerError(&b, format, "http.StatusBadRequest", "err.Error()")
+	b.WriteString("\t\treturn\n")
+	b.WriteString("\t}\n\n")
+	if format == ResponseFormatRaw {
+		b.WriteString("\tw.Header().Set(\"Content-Type\", \"application/json\")\n")
+		b.WriteString("\tjson.NewEncoder(w).Encode(report)\n")
+	} else {
+		b.WriteString("\trespond(w, http.StatusOK, report)\n")
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// Export%s streams the %s entities matching the criteria bound from the\n", toPlural(entity), entity)
+	b.WriteString("// query string as a CSV file (?format=csv) or a JSON array (the default),\n")
+	fmt.Fprintf(&b, "// e.g. %s/export?format=csv&name_like=pro.\n", path)
+	fmt.Fprintf(&b, "func (h *%s) Export%s(w http.ResponseWriter, r *http.Request) {\n", handlerName, toPlural(entity))
+	b.WriteString("\tformat, err := exportFormat(r)\n")
+	b.WriteString("\tif err != nil {\n")
+	writeHandlerError(&b, format, "http.StatusBadRequest", "err.Error()")
+	b.WriteString("\t\treturn\n")
+	b.WriteString("\t}\n")
+	fmt.Fprintf(&b, "\tvar input usecase.Export%sInput\n", entity)
+	b.WriteString("\tif err := bindQuery(r.URL.Query(), &input); err != nil {\n")
+	writeHandlerError(&b, format, "http.StatusBadRequest", "err.Error()")
+	b.WriteString("\t\treturn\n")
+	b.WriteString("\t}\n\n")
+	fmt.Fprintf(&b, "\terr = exportRows(w, format, \"%s\", func(offset int) ([]%s, int64, error) {\n", toPlural(entityVar), rowType)
+	fmt.Fprintf(&b, "\t\t%s, total, err := h.usecase.FindPage(r.Context(), input, exportPageSize, offset)\n", toPlural(entityVar))
+	fmt.Fprintf(&b, "\t\treturn %s, total, err\n", rows)
+	b.WriteString("\t})\n")
+	b.WriteString("\tif err != nil {\n")
+	writeUseCaseError(&b, format, "http.StatusInternalServerError")
+	b.WriteString("\t}\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// importExportSource is internal/handler/http/import_export.go after its
+// package clause, shared by the import and export handlers of every entity.
+// Like query.go, it is only rewritten with --force. Its %s is the import path
+// of the project.
+const importExportSource = `
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"%s/internal/usecase"
+)
+
+// Import and export sizes: the rows an import saves per transaction, unless
+// its chunk parameter asks for up to maxImportChunkSize, the rows an export
+// reads per query and the row errors an import report lists.
+const (
+	importChunkSize    = 500
+	maxImportChunkSize = 5000
+	exportPageSize     = 500
+	maxImportErrors    = 1000
+)
+
+// ImportRowError is why a row of an import failed. Rows are numbered from 1,
+// after the header of a CSV file.
+type ImportRowError struct {
+	Row   int    ` + "`json:\"row\"`" + `
+	Error string ` + "`json:\"error\"`" + `
+}
+
+// ImportReport is the result of an import. Errors lists the first
+// maxImportErrors failed rows.
+type ImportReport struct {
+	Imported int              ` + "`json:\"imported\"`" + `
+	Failed   int              ` + "`json:\"failed\"`" + `
+	Errors   []ImportRowError ` + "`json:\"errors,omitempty\"`" + `
+}
+
+// fail records that row failed with err.
+func (r *ImportReport) fail(row int, err error) {
+	r.Failed++
+	if len(r.Errors) < maxImportErrors {
+		r.Errors = append(r.Errors, ImportRowError{Row: row, Error: err.Error()})
+	}
+}
+
+// saved records the result of saving the rows of a chunk: the rows the use
+// case rejected and, when saving failed, the others.
+func (r *ImportReport) saved(rows []int, rejected []usecase.BatchRowError, err error) {
+	failed := make(map[int]bool, len(rejected))
+	for _, e := range rejected {
+		failed[e.Index] = true
+		r.fail(rows[e.Index], e.Err)
+	}
+	for i, row := range rows {
+		switch {
+		case failed[i]:
+		case err != nil:
+			r.fail(row, err)
+		default:
+			r.Imported++
+		}
+	}
+}
+
+// importChunk returns the rows per transaction an import asks for.
+func importChunk(r *http.Request) (int, error) {
+	raw := r.URL.Query().Get("chunk")
+	if raw == "" {
+		return importChunkSize, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 || n > maxImportChunkSize {
+		return 0, fmt.Errorf("chunk must be a number from 1 to %%d", maxImportChunkSize)
+	}
+	return n, nil
+}
+
+// readImport streams the rows of an import body, a CSV file with a header row
+// when its Content-Type is text/csv and a JSON array otherwise, and calls save
+// with every chunkSize rows and their numbers. Rows that cannot be decoded are
+// reported and left out; a malformed body stops the import at the row it
+// breaks, after the chunks already saved. It fails when the body does not
+// start like a CSV file or a JSON array.
+func readImport[T any](r *http.Request, chunkSize int, report *ImportReport, save func(rows []T, numbers []int)) error {
+	rows := make([]T, 0, chunkSize)
+	numbers := make([]int, 0, chunkSize)
+	add := func(row T, number int) {
+		rows = append(rows, row)
+		numbers = append(numbers, number)
+		if len(rows) == chunkSize {
+			save(rows, numbers)
+			rows, numbers = make([]T, 0, chunkSize), make([]int, 0, chunkSize)
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var err error
+	if mediaType == "text/csv" {
+		err = readCSVImport(r.Body, report, add)
+	} else {
+		err = readJSONImport(r.Body, report, add)
+	}
+	if err != nil {
+		return err
+	}
+	if len(rows) > 0 {
+		save(rows, numbers)
+	}
+	return nil
+}
+
+// readCSVImport decodes the records of a CSV body into rows, matching the
+// header with the JSON names of their fields. Unknown columns and empty cells
+// are ignored.
+func readCSVImport[T any](body io.Reader, report *ImportReport, add func(T, int)) error {
+	reader := csv.NewReader(body)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading the CSV header: %%w", err)
+	}
+	var zero T
+	fields := make(map[string]int)
+	for _, column := range exportColumns(reflect.TypeOf(zero)) {
+		fields[column.name] = column.index
+	}
+
+	for number := 1; ; number++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			report.fail(number, err)
+			continue
+		}
+		if err != nil {
+			report.fail(number, err)
+			return nil
+		}
+
+		var row T
+		v := reflect.ValueOf(&row).Elem()
+		var cellErr error
+		for i, name := range header {
+			index, ok := fields[strings.TrimSpace(name)]
+			if !ok || record[i] == "" {
+				continue
+			}
+			if err := setCell(v.Field(index), record[i]); err != nil {
+				cellErr = fmt.Errorf("invalid %%s: %%w", name, err)
+				break
+			}
+		}
+		if cellErr != nil {
+			report.fail(number, cellErr)
+			continue
+		}
+		add(row, number)
+	}
+}
+
+// readJSONImport decodes the objects of a JSON array body one at a time.
+func readJSONImport[T any](body io.Reader, report *ImportReport, add func(T, int)) error {
+	decoder := json.NewDecoder(body)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return errors.New("the body must be a JSON array, or a CSV file sent as text/csv")
+	}
+	for number := 1; decoder.More(); number++ {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			report.fail(number, err)
+			return nil
+		}
+		var row T
+		if err := json.Unmarshal(raw, &row); err != nil {
+			report.fail(number, err)
+			continue
+		}
+		add(row, number)
+	}
+	return nil
+}
+
+// exportFormat returns the format an export asks for: csv or json.
+func exportFormat(r *http.Request) (string, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		return "json", nil
+	case "csv":
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported format %%q: use csv or json", format)
+	}
+}
+
+// exportRows writes the rows next returns, page by page from offset 0 until a
+// page is short, as name.csv or name.json, with the number of rows matching
+// in X-Total-Count. It fails when the first page does; once rows are written
+// a failure can only cut the response short, so it is logged.
+func exportRows[T any](w http.ResponseWriter, format, name string, next func(offset int) ([]T, int64, error)) error {
+	page, total, err := next(0)
+	if err != nil {
+		return err
+	}
+
+	var zero T
+	columns := exportColumns(reflect.TypeOf(zero))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%%q", name+"."+format))
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	var write func(T) error
+	var finish func() error
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		writer := csv.NewWriter(w)
+		header := make([]string, len(columns))
+		for i, column := range columns {
+			header[i] = column.name
+		}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		write = func(row T) error {
+			v := reflect.ValueOf(row)
+			record := make([]string, len(columns))
+			for i, column := range columns {
+				cell, err := formatCell(v.Field(column.index))
+				if err != nil {
+					return err
+				}
+				record[i] = cell
+			}
+			return writer.Write(record)
+		}
+		finish = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		separator := "[\n"
+		write = func(row T) error {
+			data, err := json.Marshal(row)
+			if err != nil {
+				return err
+			}
+			_, err = io.WriteString(w, separator+string(data))
+			separator = ",\n"
+			return err
+		}
+		finish = func() error {
+			if separator == "[\n" {
+				_, err := io.WriteString(w, "[]\n")
+				return err
+			}
+			_, err := io.WriteString(w, "\n]\n")
+			return err
+		}
+	}
+
+	for offset := 0; ; {
+		for _, row := range page {
+			if err := write(row); err != nil {
+				log.Printf("export %%s: %%v", name, err)
+				return nil
+			}
+		}
+		if len(page) < exportPageSize {
+			break
+		}
+		offset += len(page)
+		if page, _, err = next(offset); err != nil {
+			log.Printf("export %%s: %%v", name, err)
+			return nil
+		}
+	}
+	if err := finish(); err != nil {
+		log.Printf("export %%s: %%v", name, err)
+	}
+	return nil
+}
+
+// exportColumn is a field of the rows of an import or export: its index in
+// the struct and the JSON name that is its CSV column.
+type exportColumn struct {
+	index int
+	name  string
+}
+
+// exportColumns returns the fields of the struct type t encoded in JSON.
+func exportColumns(t reflect.Type) []exportColumn {
+	var columns []exportColumn
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		columns = append(columns, exportColumn{index: i, name: name})
+	}
+	return columns
+}
+
+// textCell reports whether values of t are written as plain text in CSV
+// cells, times in RFC 3339. Other values are written as JSON.
+func textCell(t reflect.Type) bool {
+	if t == reflect.TypeOf(time.Time{}) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// setCell sets field from a CSV cell written by formatCell.
+func setCell(field reflect.Value, cell string) error {
+	if field.Kind() == reflect.Ptr {
+		field.Set(reflect.New(field.Type().Elem()))
+		field = field.Elem()
+	}
+	if textCell(field.Type()) {
+		return setQueryValue(field, cell)
+	}
+	return json.Unmarshal([]byte(cell), field.Addr().Interface())
+}
+
+// formatCell returns the CSV cell of field; nil pointers are empty.
+func formatCell(field reflect.Value) (string, error) {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return "", nil
+		}
+		field = field.Elem()
+	}
+	if t, ok := field.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339Nano), nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(field.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(field.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(field.Float(), 'f', -1, field.Type().Bits()), nil
+	}
+	data, err := json.Marshal(field.Interface())
+	return string(data), err
+}
+`
+
+// wireImportExportIntoMainGo registers the import and export routes of
+// entity in main.go on the line before its /api/v1 routes. It is idempotent
+// and reports false when main.go does not register those routes.
+func wireImportExportIntoMainGo(content, entity string) (string, bool) {
+	call := fmt.Sprintf("apphttp.Setup%sImportExportRoutes(apiRouter, container.%sUseCase())", entity, entity)
+	if strings.Contains(content, call) {
+		return content, true
+	}
+	idx := strings.Index(content, fmt.Sprintf("apphttp.Setup%sRoutes(apiRouter, ", entity))
+	if idx < 0 {
+		return content, false
+	}
+	lineStart := strings.LastIndex(content[:idx], "\n") + 1
+	line := content[lineStart:idx] + call + " // " + strings.ToLower(entity) + " import/export\n"
+	return content[:lineStart] + line + content[lineStart:], true
+}
+
+// registerImportExportRoutes wires the import and export routes of entity
+// into main.go, printing the manual step when main.go does not have the
+// expected layout.
+func registerImportExportRoutes(entity string, sm ...*SafetyManager) {
+	if len(sm) > 0 && sm[0] != nil && sm[0].DryRun {
+		return
+	}
+	manual := func() {
+		ui.Println("Register the import and export routes before the other routes of " + entity + ":")
+		ui.Dim(fmt.Sprintf("      apphttp.Setup%sImportExportRoutes(apiRouter, container.%sUseCase())", entity, entity))
+	}
+	mainPath, found := findMainGoPath()
+	if !found {
+		manual()
+		return
+	}
+	defer lockSharedFile(mainPath)()
+	content, err := os.ReadFile(mainPath)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not read main.go: %v", err))
+		manual()
+		return
+	}
+	updated, ok := wireImportExportIntoMainGo(string(content), entity)
+	if !ok {
+		ui.Warning(fmt.Sprintf("main.go does not register the /api/v1 routes of %s", entity))
+		manual()
+		return
+	}
+	if updated == string(content) {
+		return
+	}
+	if err := writeMainGoInPlace(mainPath, updated); err != nil {
+		ui.Warning(fmt.Sprintf("Could not register the import and export routes in main.go: %v", err))
+		manual()
+		return
+	}
+	ui.Dim(fmt.Sprintf("   /api/v1/%s/import and /export registered in %s", toPlural(strings.ToLower(entity)), mainPath))
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportExportGeneration(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	sm := NewSafetyManager(false, true, false)
+	fields := "name:string,price:float64"
+	require.NoError(t, generateEntity("Product", fields, true, false, false, false, false, false, false, false, "lowercase", sm))
+	generateUseCaseWithFields("ProductUseCase", "Product", "create,read,update,delete,list", true, false, fields, "", sm)
+	generateRepository("Product", DBPostgres, false, false, false, false, fields, sm)
+	require.NoError(t, generateImportExport("Product", sm))
+
+	repo := readParsedGo(t, filepath.Join(DirInternal, DirRepository, "product_batch.go"))
+	assert.Contains(t, repo, "type ProductBatchRepository interface {")
+	assert.Contains(t, repo, "func (p *postgresProductRepository) SaveBatch(ctx context.Context, products []domain.Product) error {")
+	assert.Contains(t, repo, "tx.CreateInBatches(products, 100)")
+	assert.Contains(t, repo, "func (p *postgresProductRepository) FindPage(ctx context.Context, criteria ProductCriteria, limit, offset int) ([]domain.Product, int64, error) {")
+
+	uc := readParsedGo(t, filepath.Join(DirInternal, DirUseCase, "product_batch.go"))
+	assert.Contains(t, uc, "type ExportProductInput = repository.ProductCriteria")
+	assert.Contains(t, uc, "func (p *productService) SaveBatch(ctx context.Context, products []domain.Product) ([]BatchRowError, error) {")
+	assert.Contains(t, uc, "products[i].Validate()")
+	readParsedGo(t, filepath.Join(DirInternal, DirUseCase, "batch.go"))
+
+	handler := readParsedGo(t, filepath.Join(DirInternal, DirHandler, DirHTTP, "product_import_export_handler.go"))
+	assert.Contains(t, handler, "func SetupProductImportExportRoutes(router *mux.Router, uc usecase.ProductUseCase) {")
+	assert.Contains(t, handler, `router.HandleFunc("/products/import", handler.ImportProducts).Methods("POST")`)
+	assert.Contains(t, handler, `router.HandleFunc("/products/export", handler.ExportProducts).Methods("GET")`)
+	shared := readParsedGo(t, filepath.Join(DirInternal, DirHandler, DirHTTP, "import_export.go"))
+	assert.Contains(t, shared, "func readImport[T any](")
+	assert.Contains(t, shared, `"testproject/internal/usecase"`)
+	readParsedGo(t, filepath.Join(DirInternal, DirHandler, DirHTTP, "query.go"))
+}
+
+func TestImportExportNeedsGormRepository(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	sm := NewSafetyManager(false, true, false)
+	fields := "name:string"
+	require.NoError(t, generateEntity("Product", fields, true, false, false, false, false, false, false, false, "lowercase", sm))
+	generateRepository("Product", DBMongoDB, false, false, false, false, fields, sm)
+	assert.ErrorContains(t, generateImportExport("Product", sm), "no GORM repository of Product")
+
+	assert.NoError(t, validateImportExport(DBPostgres, "http,grpc", false, false, false))
+	assert.ErrorContains(t, validateImportExport(DBMongoDB, "http", false, false, false), "does not support mongodb")
+	assert.ErrorContains(t, validateImportExport(DBPostgres, "grpc", false, false, false), "HTTP handler")
+	assert.ErrorContains(t, validateImportExport(DBPostgres, "http", true, false, false), "--audit")
+}
+
+func TestWireImportExportIntoMainGo(t *testing.T) {
+	main := "func main() {\n\tapphttp.SetupProductRoutes(apiRouter, container.ProductUseCase()) // product routes\n" + wiringRoutesMarker + "\n}\n"
+	updated, ok := wireImportExportIntoMainGo(main, "Product")
+	require.True(t, ok)
+	assert.Less(t, strings.Index(updated, "apphttp.SetupProductImportExportRoutes(apiRouter, container.ProductUseCase())"),
+		strings.Index(updated, "apphttp.SetupProductRoutes("), "registered before /products/{id}")
+
+	again, ok := wireImportExportIntoMainGo(updated, "Product")
+	require.True(t, ok)
+	assert.Equal(t, updated, again)
+
+	_, ok = wireImportExportIntoMainGo(main, "Order")
+	assert.False(t, ok)
+}
@@ -0,0 +1,556 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// ingestDir is the package holding the generated ingestion jobs.
+var ingestDir = filepath.Join(DirInternal, "ingest")
+
+// Sources and pagination styles of goca ingest.
+const (
+	IngestSourceREST = "rest"
+
+	IngestPaginateLink   = "link"
+	IngestPaginatePage   = "page"
+	IngestPaginateCursor = "cursor"
+)
+
+// ValidIngestSources are the values of --source.
+var ValidIngestSources = []string{IngestSourceREST}
+
+// ValidIngestPaginations are the values of --pagination.
+var ValidIngestPaginations = []string{IngestPaginateLink, IngestPaginatePage, IngestPaginateCursor}
+
+// cronDescriptors are the schedules robfig/cron accepts besides five cron
+// fields and @every <duration>.
+var cronDescriptors = []string{"@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly"}
+
+// cronFieldPattern matches a field of a standard cron expression.
+var cronFieldPattern = regexp.MustCompile(`^[0-9A-Za-z*/,?-]+$`)
+
+var ingestCmd = &cobra.Command{
+	Use:   "ingest <Name>",
+	Short: "Generate a scheduled worker ingesting an external API",
+	Long: `Generates a polling ingestion job that pages through an external API on a
+schedule and upserts what it returns into an entity's table.
+
+  - internal/ingest/<name>.go  The job: endpoint, schedule and the mapping of
+                               API records to the entity, to fill in
+  - internal/ingest/ingest.go  Pagination, rate limiting, retries and the
+                               ingest_checkpoints record
+  - cmd/ingest/main.go         Opens the database and runs the jobs
+
+Every page is upserted on the --key column in the same transaction as the
+job's checkpoint, so a worker that is stopped or fails resumes at the page it
+was on. A completed run records when it started; the next run asks the API
+for the records changed since then.
+
+Requests are throttled to --rate per second. Responses with status 429, or
+403 with X-RateLimit-Remaining: 0, wait for Retry-After or X-RateLimit-Reset;
+5xx responses and network errors are retried with backoff.
+
+Run the workers with:
+
+  go run ./cmd/ingest                  # run the jobs on their schedules
+  go run ./cmd/ingest -once            # run every job once and exit
+  go run ./cmd/ingest -once -job git_hub_repos
+
+Examples:
+  goca ingest GitHubRepos --source=rest --schedule "*/30 * * * *"
+  goca ingest Invoices --url https://api.example.com/v1/invoices --pagination cursor
+  goca ingest Orders --entity Order --key ExternalID --pagination page --rate 5`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		job := ingestJob{Name: args[0]}
+		job.Source, _ = cmd.Flags().GetString("source")
+		job.Schedule, _ = cmd.Flags().GetString("schedule")
+		job.Entity, _ = cmd.Flags().GetString("entity")
+		job.Key, _ = cmd.Flags().GetString("key")
+		job.URL, _ = cmd.Flags().GetString("url")
+		job.Pagination, _ = cmd.Flags().GetString("pagination")
+		job.PageSize, _ = cmd.Flags().GetInt("page-size")
+		job.Rate, _ = cmd.Flags().GetFloat64("rate")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if !dataMigrationNamePattern.MatchString(job.Name) {
+			return fmt.Errorf("invalid ingestion job name %q: use PascalCase letters and digits, e.g. GitHubRepos", job.Name)
+		}
+		if job.Entity == "" {
+			job.Entity = ingestEntityName(job.Name)
+		}
+		if err := job.validate(); err != nil {
+			return err
+		}
+		if _, err := os.Stat(job.path()); err == nil && !force {
+			return fmt.Errorf("%s already exists; use --force to regenerate it", job.path())
+		}
+
+		ci := NewConfigIntegration()
+		_ = ci.LoadConfigForProject()
+		database := ci.GetDatabaseType("")
+		if sqlDialect(database) < 0 {
+			return fmt.Errorf("goca ingest only supports SQL databases, not %s", database)
+		}
+		key, err := resolveIngestKey(job.Entity, job.Key)
+		if err != nil {
+			return err
+		}
+		job.Key = key.Name
+		job.Column = fieldColumns([]Field{key}, database)[0].Name
+
+		ui.Header("Goca Ingest")
+		ui.Blank()
+		ui.KeyValue("Job", toSnakeCase(job.Name))
+		ui.KeyValue("Entity", job.Entity)
+		ui.KeyValue("Upsert key", fmt.Sprintf("%s (%s)", job.Key, job.Column))
+		ui.KeyValue("Schedule", job.Schedule)
+		ui.KeyValue("Pagination", job.Pagination)
+		ui.KeyValue("Rate limit", fmt.Sprintf("%g requests/s", job.Rate))
+		ui.Blank()
+
+		sm := NewSafetyManager(dryRun, force, false)
+		if err := ensureIngestUniqueKey(ci, job.Entity, key, database, sm); err != nil {
+			return err
+		}
+		if err := generateIngestion(job, database, sm); err != nil {
+			return err
+		}
+		if dryRun {
+			sm.PrintSummary()
+			return nil
+		}
+
+		projectRoot, _ := os.Getwd()
+		depMgr := NewDependencyManager(projectRoot, false)
+		for _, dep := range depMgr.GetRequiredDependenciesForFeature("", map[string]bool{"ingest": true}) {
+			if err := depMgr.AddDependency(dep); err != nil {
+				ui.Warning(fmt.Sprintf("Could not add dependency %s: %v", dep.Module, err))
+			}
+		}
+
+		ui.Blank()
+		ui.NextSteps([]string{
+			"Map the API's records to " + job.Entity + " in " + job.path(),
+			"Run it once with 'go run ./cmd/ingest -once -job " + toSnakeCase(job.Name) + "'",
+			"Deploy 'go run ./cmd/ingest' as a long-running worker",
+		})
+		return nil
+	},
+}
+
+// ingestJob is an ingestion job being generated.
+type ingestJob struct {
+	Name   string
+	Entity string
+	// Key is the entity field identifying records across runs, stored in
+	// Column; rows are upserted on it.
+	Key        string
+	Column     string
+	Source     string
+	Schedule   string
+	URL        string
+	Pagination string
+	PageSize   int
+	Rate       float64
+}
+
+// path returns the file of the job.
+func (j ingestJob) path() string {
+	return filepath.Join(ingestDir, toSnakeCase(j.Name)+".go")
+}
+
+// validate checks the flags of the job.
+func (j ingestJob) validate() error {
+	if !contains(ValidIngestSources, j.Source) {
+		return fmt.Errorf("unsupported --source %q: use one of %s", j.Source, strings.Join(ValidIngestSources, ", "))
+	}
+	if !contains(ValidIngestPaginations, j.Pagination) {
+		return fmt.Errorf("unsupported --pagination %q: use one of %s", j.Pagination, strings.Join(ValidIngestPaginations, ", "))
+	}
+	if err := validateCronSchedule(j.Schedule); err != nil {
+		return err
+	}
+	if j.URL != "" {
+		u, err := url.Parse(j.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid --url %q: use an absolute http or https URL", j.URL)
+		}
+	}
+	if j.PageSize < 1 {
+		return fmt.Errorf("--page-size must be at least 1")
+	}
+	if j.Rate <= 0 {
+		return fmt.Errorf("--rate must be greater than 0")
+	}
+	return nil
+}
+
+// validateCronSchedule checks a schedule the way robfig/cron's standard parser
+// reads it: five fields, a descriptor such as @hourly, or @every <duration>.
+func validateCronSchedule(schedule string) error {
+	schedule = strings.TrimSpace(schedule)
+	if every, ok := strings.CutPrefix(schedule, "@every "); ok {
+		if d, err := time.ParseDuration(strings.TrimSpace(every)); err != nil || d <= 0 {
+			return fmt.Errorf("invalid --schedule %q: @every needs a positive duration such as 15m", schedule)
+		}
+		return nil
+	}
+	if strings.HasPrefix(schedule, "@") {
+		if !contains(cronDescriptors, schedule) {
+			return fmt.Errorf("invalid --schedule %q: use one of %s or @every <duration>", schedule, strings.Join(cronDescriptors, ", "))
+		}
+		return nil
+	}
+	fields := strings.Fields(schedule)
+	if len(fields) != 5 {
+		return fmt.Errorf("invalid --schedule %q: use five cron fields (minute hour day month weekday), e.g. \"*/30 * * * *\"", schedule)
+	}
+	for _, field := range fields {
+		if !cronFieldPattern.MatchString(field) {
+			return fmt.Errorf("invalid --schedule %q: unexpected %q", schedule, field)
+		}
+	}
+	return nil
+}
+
+// ingestEntityName returns the entity a job ingests by default: the job name
+// with its last word singular, e.g. GitHubRepos -> GitHubRepo.
+func ingestEntityName(name string) string {
+	last := strings.LastIndexFunc(name, func(r rune) bool { return r >= 'A' && r <= 'Z' })
+	if last < 0 {
+		return name
+	}
+	word := singularizeWord(strings.ToLower(name[last:]))
+	return name[:last] + strings.ToUpper(word[:1]) + word[1:]
+}
+
+// resolveIngestKey returns the field of entity named key, ignoring case so
+// ExternalID also finds the Externalid of --fields "externalID:int64".
+func resolveIngestKey(entity, key string) (Field, error) {
+	file, err := findEntityFile(entity)
+	if err != nil {
+		return Field{}, fmt.Errorf("%w; generate it first, e.g. goca feature %s --fields \"external_id:int64,name:string\", or pick it with --entity", err, entity)
+	}
+	src, err := parseGoSource(file)
+	if err != nil {
+		return Field{}, err
+	}
+	for _, field := range readEntityFields(src.file, entity) {
+		if strings.EqualFold(field.Name, key) && field.Nested == nil {
+			return field, nil
+		}
+	}
+	return Field{}, fmt.Errorf("%s has no %s field to upsert on; add it with goca field add %s \"%s:int64\" or pick one with --key", entity, key, entity, toSnakeCase(key))
+}
+
+// ensureIngestUniqueKey adds uniqueIndex to the gorm tag of the key field,
+// which the upserts of the job conflict on, and writes the migration creating
+// the index when the project has a migrations directory.
+func ensureIngestUniqueKey(ci *ConfigIntegration, entity string, key Field, database string, sm *SafetyManager) error {
+	column := fieldColumns([]Field{key}, database)[0]
+	if column.Unique {
+		return nil
+	}
+	file, err := findEntityFile(entity)
+	if err != nil {
+		return err
+	}
+	src, err := parseGoSource(file)
+	if err != nil {
+		return err
+	}
+	for _, field := range findStructType(src.file, entity).Fields.List {
+		if len(field.Names) != 1 || field.Names[0].Name != key.Name {
+			continue
+		}
+		if field.Tag == nil {
+			src.insert(field.Type.End(), " "+withGormOption("", "uniqueIndex"))
+		} else {
+			src.replace(field.Tag, withGormOption(field.Tag.Value, "uniqueIndex"))
+		}
+	}
+	if _, err := writeEditedGoFile(src, nil, sm); err != nil {
+		return err
+	}
+	ui.Success(fmt.Sprintf("Added a unique index on %s.%s for the upserts", entity, key.Name))
+
+	if _, err := os.Stat(projectMigrationsDir(ci)); err != nil {
+		return nil
+	}
+	table := entityTableName(entity)
+	column.Unique = true
+	var up, down strings.Builder
+	fmt.Fprintf(&up, "-- Upsert ingested %s rows on %s\n", table, column.Name)
+	writeCreateUniqueIndex(&up, table, column)
+	writeDropUniqueIndex(&down, table, database, column)
+	path, err := writeFieldMigration(ci, fmt.Sprintf("add_unique_index_%s_to_%s", column.Name, table), up.String(), down.String(), sm)
+	if err != nil {
+		return err
+	}
+	ui.Success("Migration: " + path)
+	return nil
+}
+
+// withGormOption returns a struct tag with option appended to its gorm key.
+func withGormOption(tag, option string) string {
+	if tag == "" {
+		return "`gorm:\"" + option + "\"`"
+	}
+	start := strings.Index(tag, `gorm:"`)
+	if start < 0 {
+		return strings.TrimSuffix(tag, "`") + ` gorm:"` + option + "\"`"
+	}
+	start += len(`gorm:"`)
+	end := start + strings.Index(tag[start:], `"`)
+	if end == start {
+		return tag[:start] + option + tag[end:]
+	}
+	return tag[:end] + ";" + option + tag[end:]
+}
+
+// generateIngestion writes the job, the runtime, regenerated on every run,
+// and the worker entrypoint, written once so it can be customized.
+func generateIngestion(j ingestJob, database string, sm *SafetyManager) error {
+	importPath := getImportPath(getModuleName())
+	file, err := findEntityFile(j.Entity)
+	if err != nil {
+		return err
+	}
+	src, err := parseGoSource(file)
+	if err != nil {
+		return err
+	}
+	content, err := syncImports([]byte(buildIngestJob(j, importPath, readEntityFields(src.file, j.Entity))), "time", "gorm.io/datatypes")
+	if err != nil {
+		return fmt.Errorf("generated ingestion job does not parse: %w", err)
+	}
+	if err := writeGoFile(j.path(), string(content), sm); err != nil {
+		return fmt.Errorf("writing ingestion job: %w", err)
+	}
+	if err := writeGoFileMerged(filepath.Join(ingestDir, "ingest.go"), ingestRuntimeSource, sm); err != nil {
+		return fmt.Errorf("writing ingestion runtime: %w", err)
+	}
+
+	mainPath := filepath.Join("cmd", "ingest", "main.go")
+	if _, err := os.Stat(mainPath); err == nil {
+		return nil
+	}
+	main, ok := buildIngestMain(importPath, database, projectSQLiteDriver())
+	if !ok {
+		return fmt.Errorf("goca ingest does not support %s", database)
+	}
+	if err := writeGoFile(mainPath, main, sm); err != nil {
+		return fmt.Errorf("writing ingestion entrypoint: %w", err)
+	}
+	return nil
+}
+
+// buildIngestJob returns the file of a new ingestion job: its registration,
+// the record the API returns and its mapping to the entity.
+func buildIngestJob(j ingestJob, importPath string, fields []Field) string {
+	fn := strings.ToLower(j.Name[:1]) + j.Name[1:]
+	record := strings.ToLower(j.Entity[:1]) + j.Entity[1:] + "Record"
+	endpoint := j.URL
+	if endpoint == "" {
+		endpoint = "https://api.example.com/" + strings.ReplaceAll(toSnakeCase(j.Name), "_", "-")
+	}
+	pagination := map[string]string{
+		IngestPaginateLink:   "PaginateLink",
+		IngestPaginatePage:   "PaginatePage",
+		IngestPaginateCursor: "PaginateCursor",
+	}[j.Pagination]
+
+	var mapped []Field
+	for _, field := range fields {
+		if !isSystemField(field.Name) {
+			mapped = append(mapped, field)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("package ingest\n\n")
+	b.WriteString("import (\n\t\"encoding/json\"\n\t\"net/http\"\n\t\"net/url\"\n\t\"time\"\n\n")
+	fmt.Fprintf(&b, "\t\"%s/internal/domain\"\n\n\t\"gorm.io/datatypes\"\n)\n\n", importPath)
+
+	b.WriteString("func init() {\n")
+	b.WriteString("\t// TODO: point URL at the API and authenticate in Header, e.g.\n")
+	b.WriteString("\t// \"Authorization\": {\"Bearer \" + os.Getenv(\"API_TOKEN\")}.\n")
+	b.WriteString("\tregister(Job{\n")
+	fmt.Fprintf(&b, "\t\tName:              %q,\n", toSnakeCase(j.Name))
+	fmt.Fprintf(&b, "\t\tSchedule:          %q,\n", j.Schedule)
+	fmt.Fprintf(&b, "\t\tURL:               %q,\n", endpoint)
+	b.WriteString("\t\tHeader:            http.Header{\"Accept\": {\"application/json\"}},\n")
+	fmt.Fprintf(&b, "\t\tPagination:        %s,\n", pagination)
+	fmt.Fprintf(&b, "\t\tPageSize:          %d,\n", j.PageSize)
+	fmt.Fprintf(&b, "\t\tRequestsPerSecond: %g,\n", j.Rate)
+	fmt.Fprintf(&b, "\t\tKey:               %q,\n", j.Column)
+	fmt.Fprintf(&b, "\t\tSince:             %sSince,\n", fn)
+	fmt.Fprintf(&b, "\t\tDecode:            decode%s,\n", j.Name)
+	b.WriteString("\t})\n")
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// %s is an item of the API's response. TODO: match the JSON names\n", record)
+	b.WriteString("// and types of the API.\n")
+	fmt.Fprintf(&b, "type %s struct {\n", record)
+	for _, field := range mapped {
+		name := jsonFieldName(field)
+		if field.Name == j.Key {
+			name = "id"
+		}
+		fmt.Fprintf(&b, "\t%s %s `json:\"%s\"`\n", field.Name, ingestRecordType(field), name)
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// %sSince limits a run to the records changed since the last completed\n", fn)
+	b.WriteString("// one. TODO: use the API's filter parameter, or remove Since to fetch every\n")
+	b.WriteString("// record on every run.\n")
+	fmt.Fprintf(&b, "func %sSince(u *url.URL, since time.Time) {\n", fn)
+	b.WriteString("\tq := u.Query()\n")
+	b.WriteString("\tq.Set(\"since\", since.UTC().Format(time.RFC3339))\n")
+	b.WriteString("\tu.RawQuery = q.Encode()\n")
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// decode%s decodes a page of the API's response into %s rows.\n", j.Name, j.Entity)
+	fmt.Fprintf(&b, "func decode%s(body []byte) (Page, error) {\n", j.Name)
+	if j.Pagination == IngestPaginateCursor {
+		b.WriteString("\tvar response struct {\n")
+		fmt.Fprintf(&b, "\t\tData       []%s `json:\"data\"`\n", record)
+		b.WriteString("\t\tNextCursor string `json:\"next_cursor\"`\n")
+		b.WriteString("\t}\n")
+		b.WriteString("\tif err := json.Unmarshal(body, &response); err != nil {\n")
+		b.WriteString("\t\treturn Page{}, err\n")
+		b.WriteString("\t}\n")
+		b.WriteString("\trecords := response.Data\n\n")
+	} else {
+		fmt.Fprintf(&b, "\tvar records []%s\n", record)
+		b.WriteString("\tif err := json.Unmarshal(body, &records); err != nil {\n")
+		b.WriteString("\t\treturn Page{}, err\n")
+		b.WriteString("\t}\n\n")
+	}
+	fmt.Fprintf(&b, "\trows := make([]domain.%s, 0, len(records))\n", j.Entity)
+	b.WriteString("\tfor _, r := range records {\n")
+	fmt.Fprintf(&b, "\t\trows = append(rows, domain.%s{\n", j.Entity)
+	for _, field := range mapped {
+		fmt.Fprintf(&b, "\t\t\t%s: r.%s,\n", field.Name, field.Name)
+	}
+	b.WriteString("\t\t})\n")
+	b.WriteString("\t}\n")
+	if j.Pagination == IngestPaginateCursor {
+		b.WriteString("\treturn Page{Rows: rows, Cursor: response.NextCursor}, nil\n")
+	} else {
+		b.WriteString("\treturn Page{Rows: rows}, nil\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// jsonFieldName returns the JSON name of a field: the name of its json tag,
+// or its snake_case name.
+func jsonFieldName(field Field) string {
+	name, _, _ := strings.Cut(reflect.StructTag(strings.Trim(field.Tag, "`")).Get("json"), ",")
+	if name == "" || name == "-" {
+		return toSnakeCase(field.Name)
+	}
+	return name
+}
+
+// ingestRecordType returns the type of a field in the record of a job, which
+// lives outside the domain package.
+func ingestRecordType(field Field) string {
+	if base := customTypeBase(field.Type); base != "" {
+		return strings.Replace(field.Type, base, "domain."+base, 1)
+	}
+	return field.Type
+}
+
+// buildIngestMain returns cmd/ingest/main.go, or false when the database has
+// no generated GORM opener.
+func buildIngestMain(importPath, database, sqliteDriver string) (string, bool) {
+	if sqlDialect(database) < 0 {
+		return "", false
+	}
+	std, external, openDatabase, ok := databaseOpener(database, sqliteDriver)
+	if !ok {
+		return "", false
+	}
+	imports := importLines(append([]string{"context", "flag", "fmt", "log", "net/http", "os", "os/signal", "syscall", "time"}, std...), external)
+
+	return fmt.Sprintf(`package main
+
+import (
+%s
+	"%s/internal/ingest"
+	"%s/pkg/config"
+)
+
+// main runs the ingestion jobs of internal/ingest on their schedules, or once
+// with -once. An interrupted job resumes at its checkpoint on the next run.
+func main() {
+	once := flag.Bool("once", false, "run the jobs once and exit instead of on their schedules")
+	only := flag.String("job", "", "run only the named job")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg := config.Load()
+	db, err := openDatabase(cfg)
+	if err != nil {
+		fail("connecting to database: %%v", err)
+	}
+
+	jobs := ingest.Jobs()
+	if *only != "" {
+		job, ok := ingest.Lookup(*only)
+		if !ok {
+			fail("unknown job %%q", *only)
+		}
+		jobs = []ingest.Job{job}
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	if *once {
+		for _, job := range jobs {
+			if err := ingest.Run(ctx, db, client, job); err != nil {
+				fail("%%v", err)
+			}
+		}
+		return
+	}
+	log.Printf("scheduling %%d ingestion jobs", len(jobs))
+	if err := ingest.Serve(ctx, db, client, jobs); err != nil {
+		fail("%%v", err)
+	}
+}
+
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "Error: "+format+"\n", args...)
+	os.Exit(1)
+}
+
+%s`, imports, importPath, importPath, openDatabase), true
+}
+
+func init() {
+	ingestCmd.Flags().String("source", IngestSourceREST, "Kind of API to ingest ("+strings.Join(ValidIngestSources, ", ")+")")
+	ingestCmd.Flags().String("schedule", "*/30 * * * *", "Cron schedule of the job, @hourly-style descriptor or @every <duration>")
+	ingestCmd.Flags().String("entity", "", "Entity the records are stored as (default: the name singular, e.g. GitHubRepo)")
+	ingestCmd.Flags().String("key", "ExternalID", "Entity field holding the API's record ID; rows are upserted on it")
+	ingestCmd.Flags().String("url", "", "Endpoint of the first page")
+	ingestCmd.Flags().String("pagination", IngestPaginateLink, "How pages are followed ("+strings.Join(ValidIngestPaginations, ", ")+")")
+	ingestCmd.Flags().Int("page-size", 100, "Records requested per page")
+	ingestCmd.Flags().Float64("rate", 1, "Most requests per second sent to the API")
+	ingestCmd.Flags().Bool("dry-run", false, "Show the files that would be generated without writing them")
+	ingestCmd.Flags().Bool("force", false, "Overwrite an existing job")
+}
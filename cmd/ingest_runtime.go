@@ -0,0 +1,425 @@
+package cmd
+
+// ingestRuntimeSource is internal/ingest/ingest.go, the runtime of the jobs
+// goca ingest generates.
+const ingestRuntimeSource = `// Code generated by goca ingest. DO NOT EDIT.
+
+// Package ingest polls external APIs on a schedule and upserts the records
+// they return, resuming from a checkpoint after restarts and failures.
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"golang.org/x/time/rate"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Pagination styles of Job.Pagination.
+const (
+	// PaginateLink follows the rel="next" URL of the Link header.
+	PaginateLink = "link"
+	// PaginatePage requests page 1, 2, ... until a page is shorter than
+	// PageSize.
+	PaginatePage = "page"
+	// PaginateCursor passes the cursor of the last page in CursorParam.
+	PaginateCursor = "cursor"
+)
+
+const (
+	// maxAttempts bounds the requests of a page that fail with a rate limit,
+	// a server error or a network error.
+	maxAttempts = 6
+	// maxWait bounds a wait for a rate limit to reset.
+	maxWait = time.Hour
+)
+
+// Job polls one API endpoint.
+type Job struct {
+	// Name identifies the job's checkpoint and selects it in cmd/ingest.
+	Name string
+	// Schedule is a cron expression, a descriptor such as @hourly or
+	// @every <duration>.
+	Schedule string
+	// URL is the endpoint of the first page.
+	URL    string
+	Header http.Header
+	// Pagination is PaginateLink, PaginatePage or PaginateCursor.
+	Pagination string
+	// PageSize is sent in SizeParam; PaginatePage stops at a shorter page.
+	PageSize int
+	// PageParam, SizeParam and CursorParam name the query parameters of the
+	// pagination; they default to page, per_page and cursor.
+	PageParam   string
+	SizeParam   string
+	CursorParam string
+	// RequestsPerSecond and Burst throttle the requests of the job.
+	RequestsPerSecond float64
+	Burst             int
+	// Key is the column identifying records across runs; rows are upserted
+	// on it, so it needs a unique index.
+	Key string
+	// Since, when set, limits a run to the records changed since the start of
+	// the last completed run. Without it every run fetches every record.
+	Since func(u *url.URL, since time.Time)
+	// Decode decodes the body of a page.
+	Decode func(body []byte) (Page, error)
+}
+
+// Page is a decoded page of an API response.
+type Page struct {
+	// Rows is a slice of the models to upsert.
+	Rows interface{}
+	// Cursor is the cursor of the next page under PaginateCursor, "" on the
+	// last page.
+	Cursor string
+}
+
+// Checkpoint records the progress of a job.
+type Checkpoint struct {
+	Job string ` + "`gorm:\"primaryKey;size:100\"`" + `
+	// Next is the URL of the next page of an interrupted run, "" when the
+	// last run completed.
+	Next string
+	// StartedAt is when the current or last run started.
+	StartedAt time.Time
+	// Since is when the last completed run started, nil before the first.
+	Since *time.Time
+	// Rows counts the rows upserted by the current or last run.
+	Rows      int64
+	UpdatedAt time.Time
+}
+
+// TableName names the checkpoint table.
+func (Checkpoint) TableName() string { return "ingest_checkpoints" }
+
+var registry = map[string]Job{}
+
+// register adds a job; every job file calls it from init.
+func register(job Job) {
+	registry[job.Name] = job
+}
+
+// Jobs returns the registered jobs ordered by name.
+func Jobs() []Job {
+	jobs := make([]Job, 0, len(registry))
+	for _, job := range registry {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Name < jobs[j].Name })
+	return jobs
+}
+
+// Lookup returns the registered job named name.
+func Lookup(name string) (Job, bool) {
+	job, ok := registry[name]
+	return job, ok
+}
+
+// Serve runs jobs on their schedules until ctx is done. A run that is due
+// while the previous one of the job is still going is skipped.
+func Serve(ctx context.Context, db *gorm.DB, client *http.Client, jobs []Job) error {
+	c := cron.New(cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
+	for _, job := range jobs {
+		job := job
+		_, err := c.AddFunc(job.Schedule, func() {
+			if err := Run(ctx, db, client, job); err != nil && ctx.Err() == nil {
+				log.Printf("%s: %v", job.Name, err)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("%s: schedule %q: %w", job.Name, job.Schedule, err)
+		}
+	}
+	c.Start()
+	<-ctx.Done()
+	<-c.Stop().Done()
+	return nil
+}
+
+// Run fetches the pages of job and upserts their rows, each page in one
+// transaction with the checkpoint. A run that was interrupted resumes at the
+// page it stopped at.
+func Run(ctx context.Context, db *gorm.DB, client *http.Client, job Job) error {
+	job = withDefaults(job)
+	db = db.WithContext(ctx)
+	if err := db.AutoMigrate(&Checkpoint{}); err != nil {
+		return fmt.Errorf("%s: creating ingest_checkpoints: %w", job.Name, err)
+	}
+	checkpoint := Checkpoint{Job: job.Name}
+	if err := db.FirstOrInit(&checkpoint, Checkpoint{Job: job.Name}).Error; err != nil {
+		return fmt.Errorf("%s: reading checkpoint: %w", job.Name, err)
+	}
+
+	next := checkpoint.Next
+	if next == "" {
+		first, err := firstPage(job, checkpoint.Since)
+		if err != nil {
+			return fmt.Errorf("%s: %w", job.Name, err)
+		}
+		next = first
+		checkpoint.StartedAt = time.Now().UTC()
+		checkpoint.Rows = 0
+	} else {
+		log.Printf("%s: resuming at %s", job.Name, next)
+	}
+
+	limiter := rate.NewLimiter(rate.Limit(job.RequestsPerSecond), job.Burst)
+	start := time.Now()
+	for pages := 1; next != ""; pages++ {
+		body, header, err := fetch(ctx, client, limiter, job, next)
+		if err != nil {
+			return fmt.Errorf("%s: %w", job.Name, err)
+		}
+		page, err := job.Decode(body)
+		if err != nil {
+			return fmt.Errorf("%s: decoding %s: %w", job.Name, next, err)
+		}
+		rows := 0
+		if page.Rows != nil {
+			rows = reflect.ValueOf(page.Rows).Len()
+		}
+
+		following, err := nextPage(job, next, header, page, rows)
+		if err != nil {
+			return fmt.Errorf("%s: %w", job.Name, err)
+		}
+		checkpoint.Next = following
+		checkpoint.Rows += int64(rows)
+		if following == "" {
+			started := checkpoint.StartedAt
+			checkpoint.Since = &started
+		}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if rows > 0 {
+				upsert := clause.OnConflict{Columns: []clause.Column{{Name: job.Key}}, UpdateAll: true}
+				if err := tx.Clauses(upsert).Create(page.Rows).Error; err != nil {
+					return fmt.Errorf("upserting %d rows: %w", rows, err)
+				}
+			}
+			return tx.Save(&checkpoint).Error
+		})
+		if err != nil {
+			return fmt.Errorf("%s: page %d: %w", job.Name, pages, err)
+		}
+		next = following
+
+		if wait := rateLimitReset(header); next != "" && header.Get("X-RateLimit-Remaining") == "0" && wait > 0 {
+			log.Printf("%s: rate limit exhausted, waiting %s", job.Name, wait.Round(time.Second))
+			if err := sleep(ctx, wait); err != nil {
+				return fmt.Errorf("%s: %w", job.Name, err)
+			}
+		}
+	}
+	log.Printf("%s: done, %d rows in %s", job.Name, checkpoint.Rows, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// withDefaults fills in the optional fields of job.
+func withDefaults(job Job) Job {
+	if job.PageParam == "" {
+		job.PageParam = "page"
+	}
+	if job.SizeParam == "" {
+		job.SizeParam = "per_page"
+	}
+	if job.CursorParam == "" {
+		job.CursorParam = "cursor"
+	}
+	if job.RequestsPerSecond <= 0 {
+		job.RequestsPerSecond = 1
+	}
+	if job.Burst < 1 {
+		job.Burst = 1
+	}
+	return job
+}
+
+// firstPage returns the URL of the first page of a run.
+func firstPage(job Job, since *time.Time) (string, error) {
+	u, err := url.Parse(job.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", job.URL, err)
+	}
+	q := u.Query()
+	if job.PageSize > 0 {
+		q.Set(job.SizeParam, strconv.Itoa(job.PageSize))
+	}
+	if job.Pagination == PaginatePage {
+		q.Set(job.PageParam, "1")
+	}
+	u.RawQuery = q.Encode()
+	if job.Since != nil && since != nil {
+		job.Since(u, *since)
+	}
+	return u.String(), nil
+}
+
+// linkNext matches the rel="next" URL of a Link header.
+var linkNext = regexp.MustCompile(` + "`<([^>]+)>\\s*;[^,]*rel=\"?next\"?`" + `)
+
+// nextPage returns the URL of the page after current, "" after the last page.
+func nextPage(job Job, current string, header http.Header, page Page, rows int) (string, error) {
+	switch job.Pagination {
+	case PaginateLink:
+		m := linkNext.FindStringSubmatch(strings.Join(header.Values("Link"), ","))
+		if m == nil {
+			return "", nil
+		}
+		base, err := url.Parse(current)
+		if err != nil {
+			return "", err
+		}
+		next, err := base.Parse(m[1])
+		if err != nil {
+			return "", fmt.Errorf("invalid Link header: %w", err)
+		}
+		return next.String(), nil
+	case PaginatePage:
+		if rows == 0 || (job.PageSize > 0 && rows < job.PageSize) {
+			return "", nil
+		}
+		u, err := url.Parse(current)
+		if err != nil {
+			return "", err
+		}
+		q := u.Query()
+		n, _ := strconv.Atoi(q.Get(job.PageParam))
+		q.Set(job.PageParam, strconv.Itoa(max(n, 1)+1))
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	case PaginateCursor:
+		if page.Cursor == "" {
+			return "", nil
+		}
+		u, err := url.Parse(current)
+		if err != nil {
+			return "", err
+		}
+		q := u.Query()
+		q.Set(job.CursorParam, page.Cursor)
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	}
+	return "", fmt.Errorf("unknown pagination %q", job.Pagination)
+}
+
+// errRetry marks the responses fetch retries.
+var errRetry = errors.New("retryable response")
+
+// fetch GETs a page, waiting for the limiter before each request. Rate
+// limited responses wait for the limit to reset; server and network errors
+// are retried with exponential backoff.
+func fetch(ctx context.Context, client *http.Client, limiter *rate.Limiter, job Job, target string) ([]byte, http.Header, error) {
+	for attempt := 1; ; attempt++ {
+		if err := limiter.Wait(ctx); err != nil {
+			return nil, nil, err
+		}
+		body, header, wait, err := get(ctx, client, job, target)
+		if err == nil {
+			return body, header, nil
+		}
+		if !errors.Is(err, errRetry) || attempt == maxAttempts {
+			return nil, nil, err
+		}
+		if wait <= 0 {
+			wait = min(time.Duration(1<<(attempt-1))*time.Second, time.Minute)
+		}
+		log.Printf("%s: %v; retrying in %s", job.Name, err, wait.Round(time.Second))
+		if err := sleep(ctx, wait); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// get sends one request. A retryable failure wraps errRetry and returns how
+// long the API asked to wait, or 0.
+func get(ctx context.Context, client *http.Client, job Job, target string) ([]byte, http.Header, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	for name, values := range job.Header {
+		req.Header[name] = values
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, 0, ctx.Err()
+		}
+		return nil, nil, 0, fmt.Errorf("%w: GET %s: %v", errRetry, target, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("%w: reading %s: %v", errRetry, target, err)
+	}
+
+	switch {
+	case resp.StatusCode < 300:
+		return body, resp.Header, 0, nil
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		wait := retryAfter(resp.Header)
+		if wait <= 0 {
+			wait = rateLimitReset(resp.Header)
+		}
+		return nil, nil, wait, fmt.Errorf("%w: GET %s: rate limited (%s)", errRetry, target, resp.Status)
+	case resp.StatusCode >= 500:
+		return nil, nil, retryAfter(resp.Header), fmt.Errorf("%w: GET %s: %s", errRetry, target, resp.Status)
+	}
+	if len(body) > 512 {
+		body = body[:512]
+	}
+	return nil, nil, 0, fmt.Errorf("GET %s: %s: %s", target, resp.Status, strings.TrimSpace(string(body)))
+}
+
+// retryAfter returns the wait of a Retry-After header, in seconds or as a
+// date.
+func retryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return min(time.Duration(seconds)*time.Second, maxWait)
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return min(time.Until(at), maxWait)
+	}
+	return 0
+}
+
+// rateLimitReset returns the time until the X-RateLimit-Reset header, a Unix
+// timestamp.
+func rateLimitReset(header http.Header) time.Duration {
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return min(time.Until(time.Unix(reset, 0)), maxWait)
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+`
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCronSchedule(t *testing.T) {
+	for _, schedule := range []string{"*/30 * * * *", "0 9 * * MON-FRI", "15 2 1,15 * ?", "@hourly", "@every 90s"} {
+		assert.NoError(t, validateCronSchedule(schedule), schedule)
+	}
+	for _, schedule := range []string{"*/30 * * *", "0 0 * * * *", "@often", "@every soon", "@every -5m", "* * * * $"} {
+		assert.Error(t, validateCronSchedule(schedule), schedule)
+	}
+}
+
+func TestIngestHelpers(t *testing.T) {
+	assert.Equal(t, "GitHubRepo", ingestEntityName("GitHubRepos"))
+	assert.Equal(t, "APIKey", ingestEntityName("APIKeys"))
+	assert.Equal(t, "Category", ingestEntityName("Categories"))
+
+	assert.Equal(t, "`gorm:\"uniqueIndex\"`", withGormOption("", "uniqueIndex"))
+	assert.Equal(t, "`json:\"id\" gorm:\"uniqueIndex\"`", withGormOption("`json:\"id\"`", "uniqueIndex"))
+	assert.Equal(t, "`json:\"id\" gorm:\"not null;uniqueIndex\" validate:\"required\"`",
+		withGormOption("`json:\"id\" gorm:\"not null\" validate:\"required\"`", "uniqueIndex"))
+
+	assert.Equal(t, "domain.Status", ingestRecordType(Field{Name: "Status", Type: "Status"}))
+	assert.Equal(t, "[]domain.Tag", ingestRecordType(Field{Name: "Tags", Type: "[]Tag"}))
+	assert.Equal(t, "*time.Time", ingestRecordType(Field{Name: "PushedAt", Type: "*time.Time"}))
+}
+
+func TestGenerateIngestion(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	writeTestFile(t, ".", "go.mod", "module example.com/sync\n\ngo 1.22\n")
+	sm := NewSafetyManager(false, false, false)
+	require.NoError(t, generateEntity("GitHubRepo", "external_id:int64,full_name:string,stars:int,pushed_at:time.Time", true, false, false, false, false, false, false, false, "lowercase", sm))
+	require.NoError(t, os.MkdirAll(DirMigrations, 0o755))
+
+	key, err := resolveIngestKey("GitHubRepo", "externalid")
+	require.NoError(t, err)
+	assert.Equal(t, "ExternalID", key.Name)
+	_, err = resolveIngestKey("GitHubRepo", "Slug")
+	assert.ErrorContains(t, err, "goca field add GitHubRepo")
+	_, err = resolveIngestKey("Invoice", "ExternalID")
+	assert.ErrorContains(t, err, "goca feature Invoice")
+
+	require.NoError(t, ensureIngestUniqueKey(nil, "GitHubRepo", key, DBPostgres, sm))
+	entity := readParsedGo(t, findTypeFile(filepath.Join(DirInternal, DirDomain), "GitHubRepo"))
+	assert.Contains(t, entity, "`json:\"external_id\" gorm:\"not null;uniqueIndex\"")
+	up, err := os.ReadFile(filepath.Join(DirMigrations, "001_add_unique_index_external_id_to_git_hub_repos.up.sql"))
+	require.NoError(t, err)
+	assert.Contains(t, string(up), "CREATE UNIQUE INDEX idx_git_hub_repos_external_id ON git_hub_repos (external_id);\n")
+
+	job := ingestJob{Name: "GitHubRepos", Entity: "GitHubRepo", Key: "ExternalID", Column: "external_id",
+		Source: IngestSourceREST, Schedule: "*/30 * * * *", Pagination: IngestPaginateCursor, PageSize: 50, Rate: 2}
+	require.NoError(t, generateIngestion(job, DBPostgres, sm))
+
+	source := readParsedGo(t, filepath.Join(ingestDir, "git_hub_repos.go"))
+	assert.Contains(t, source, `Name:              "git_hub_repos",`)
+	assert.Contains(t, source, `URL:               "https://api.example.com/git-hub-repos",`)
+	assert.Contains(t, source, "Pagination:        PaginateCursor,")
+	assert.Contains(t, source, `Key:               "external_id",`)
+	assert.Contains(t, source, "ExternalID int64     `json:\"id\"`")
+	assert.Contains(t, source, "PushedAt   time.Time `json:\"pushed_at\"`")
+	assert.Contains(t, source, "return Page{Rows: rows, Cursor: response.NextCursor}, nil")
+	assert.Contains(t, source, `"example.com/sync/internal/domain"`)
+	assert.NotContains(t, source, "gorm.io/datatypes")
+
+	runtime := readParsedGo(t, filepath.Join(ingestDir, "ingest.go"))
+	assert.Contains(t, runtime, "clause.OnConflict{Columns: []clause.Column{{Name: job.Key}}, UpdateAll: true}")
+	main := readParsedGo(t, filepath.Join("cmd", "ingest", "main.go"))
+	assert.Contains(t, main, `"example.com/sync/internal/ingest"`)
+	assert.Contains(t, main, "ingest.Serve(ctx, db, client, jobs)")
+	assert.Contains(t, main, `"gorm.io/driver/postgres"`)
+
+	// The job is the developer's once written.
+	assert.Error(t, generateIngestion(job, DBPostgres, sm))
+}
@@ -10,13 +10,24 @@ import (
 )
 
 var initCmd = &cobra.Command{
-	Use:   "init <project-name>",
+	Use:   "init <project-name|directory>",
 	Short: "Initialize Clean Architecture project",
 	Long: `Creates the base structure of a Go project following Clean Architecture principles, 
 including directories, configuration files and layer structure.
 
 Use --template to initialize with predefined configurations:
-  goca init myproject --module github.com/user/myproject --template rest-api`,
+  goca init myproject --module github.com/user/myproject --template rest-api
+
+Use --preset to apply a bundle of flags (api, microservice, monolith, cli-app
+or a preset defined under presets in .goca.yaml). The preset is recorded in
+the project's .goca.yaml, so goca feature applies its flags too:
+  goca init myproject --module github.com/user/myproject --preset microservice
+
+Pass "." or a path to scaffold into an existing directory. The project name is
+taken from the directory name. Files that already exist are reported as
+conflicts; use --merge to keep them (new .gitignore entries are appended) or
+--force to overwrite them:
+  goca init . --module github.com/user/myproject --merge`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		// Allow 0 args if --list-templates is set
 		listTemplates, _ := cmd.Flags().GetBool("list-templates")
@@ -31,12 +42,22 @@ Use --template to initialize with predefined configurations:
 		return cobra.MaximumNArgs(1)(cmd, args)
 	},
 	Run: func(cmd *cobra.Command, args []string) {
+		preset, err := applyPreset(cmd, presetInit)
+		if err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
 		module, _ := cmd.Flags().GetString("module")
 		database, _ := cmd.Flags().GetString("database")
 		auth, _ := cmd.Flags().GetBool("auth")
+		authMode, _ := cmd.Flags().GetString("auth-mode")
+		tracing, _ := cmd.Flags().GetBool("tracing")
+		sqliteDriver, _ := cmd.Flags().GetString("sqlite-driver")
 		api, _ := cmd.Flags().GetString("api")
 		config, _ := cmd.Flags().GetBool("config")
 		template, _ := cmd.Flags().GetString("template")
+		k8s, _ := cmd.Flags().GetBool("k8s")
+		embedAssets, _ := cmd.Flags().GetBool("embed-assets")
 		listTemplates, _ := cmd.Flags().GetBool("list-templates")
 
 		// Handle --list-templates flag
@@ -80,14 +101,69 @@ Use --template to initialize with predefined configurations:
 			ui.Error(err.Error())
 			os.Exit(1)
 		}
+		if err := validateSQLiteDriverFlag(sqliteDriver); err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+		if cmd.Flags().Changed("auth-mode") {
+			if err := validateAuthModeFlag(authMode); err != nil {
+				ui.Error(err.Error())
+				os.Exit(1)
+			}
+			auth = true
+		}
+		if database != DBSQLite {
+			if cmd.Flags().Changed("sqlite-driver") {
+				ui.Warning(fmt.Sprintf("--sqlite-driver only applies to --database sqlite; ignoring it for %s", database))
+			}
+			sqliteDriver = ""
+		}
+
+		// "." or a path scaffolds into that directory; everything below works
+		// relative to its parent so the project name is the directory name.
+		target := projectName
+		parentDir, name, err := resolveInitTarget(target)
+		if err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+		if parentDir != "" {
+			if err := os.Chdir(parentDir); err != nil {
+				ui.Error(fmt.Sprintf("cannot enter '%s': %v", parentDir, err))
+				os.Exit(1)
+			}
+			projectName = name
+		}
+
+		// Without --auth-mode the mode comes from features.auth.type of an
+		// existing .goca.yaml or of the template, jwt otherwise.
+		if !auth {
+			authMode = ""
+		} else if !cmd.Flags().Changed("auth-mode") {
+			authMode = configuredAuthMode(projectName, template)
+		}
+		if authMode == AuthModeAPIKey && !isGormDatabase(database) {
+			ui.Error(fmt.Sprintf("--auth-mode api-key stores the keys with GORM, which --database %s does not use", database))
+			os.Exit(1)
+		}
 
-		// Refuse to scaffold into a non-empty directory unless --force (INIT-B16).
+		// Refuse to overwrite files in an existing directory unless --force or
+		// --merge is given (INIT-B16). Unrelated files (LICENSE, docs, ...) are
+		// not conflicts, so only the files init would generate are checked.
 		force, _ := cmd.Flags().GetBool("force")
+		merge, _ := cmd.Flags().GetBool("merge")
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
-		if !dryRun && !force {
+		if !dryRun && !force && !merge {
 			if nonEmpty, err := directoryHasFiles(projectName); err == nil && nonEmpty {
-				ui.Error(fmt.Sprintf("directory '%s' already exists and is not empty; use --force to scaffold into it anyway", projectName))
-				os.Exit(1)
+				conflicts := detectInitConflicts(projectName, module, database, sqliteDriver, authMode, tracing, api, config || template != "")
+				if len(conflicts) > 0 {
+					ui.Error(fmt.Sprintf("directory '%s' already contains files that init would generate:", target))
+					for _, c := range conflicts {
+						ui.Dim("  - " + c)
+					}
+					ui.Dim("Use --merge to keep existing files or --force to overwrite them")
+					os.Exit(1)
+				}
 			}
 		}
 
@@ -115,9 +191,27 @@ Use --template to initialize with predefined configurations:
 
 		ui.Header(fmt.Sprintf("Initializing project '%s' with module '%s'", projectName, module))
 		ui.KeyValue("Database", database)
+		if sqliteDriver != "" {
+			ui.KeyValue("SQLite driver", sqliteDriver)
+		}
 		ui.KeyValue("API", api)
-		if auth {
-			ui.Feature("Including authentication", false)
+		if preset.Name != "" {
+			ui.KeyValue("Preset", preset.Name)
+		}
+		if authMode != "" {
+			ui.Feature(fmt.Sprintf("Including authentication (%s)", authMode), false)
+		}
+		if tracing {
+			ui.Feature("Including OpenTelemetry tracing", false)
+		}
+		if k8s {
+			ui.Feature("Including Kubernetes manifests", false)
+		}
+		if embedAssets {
+			ui.Feature("Embedding Swagger UI, email templates and migrations", false)
+		}
+		if branding := loadBranding(projectName); branding.OrgName != "" {
+			ui.Feature(fmt.Sprintf("Applying %s branding from existing .goca.yaml", branding.OrgName), false)
 		}
 		if config {
 			ui.Feature("Generating YAML configuration", false)
@@ -138,12 +232,13 @@ Use --template to initialize with predefined configurations:
 		// Initialize safety manager
 		backup, _ := cmd.Flags().GetBool("backup")
 		sm := NewSafetyManager(dryRun, force, backup)
+		sm.Merge = merge
 
 		if dryRun {
 			ui.DryRun("Previewing changes without creating files")
 		}
 
-		createProjectStructure(projectName, module, database, auth, api, configIntegration, config, template, sm)
+		createProjectStructure(projectName, module, database, sqliteDriver, authMode, tracing, api, configIntegration, config, template, preset, k8s, embedAssets, sm)
 		stop()
 
 		if dryRun {
@@ -151,20 +246,30 @@ Use --template to initialize with predefined configurations:
 			return
 		}
 
+		projectDir := "./" + projectName
+		if parentDir != "" {
+			projectDir = target
+		}
+
 		ui.Success(fmt.Sprintf("Project '%s' created successfully!", projectName))
-		ui.KeyValue("Directory", fmt.Sprintf("./%s", projectName))
+		ui.KeyValue("Directory", projectDir)
 
 		if config || template != "" {
-			ui.KeyValue("Configuration file", fmt.Sprintf("./%s/.goca.yaml", projectName))
+			ui.KeyValue("Configuration file", filepath.Join(projectDir, ".goca.yaml"))
+		}
+		if kept := sm.GetSkippedFiles(); len(kept) > 0 {
+			ui.KeyValue("Kept existing files", fmt.Sprintf("%d", len(kept)))
 		}
 
-		nextSteps := []string{
-			fmt.Sprintf("cd %s", projectName),
-			"go mod tidy",
+		var nextSteps []string
+		if target != "." {
+			nextSteps = append(nextSteps, fmt.Sprintf("cd %s", projectDir))
 		}
+		nextSteps = append(nextSteps, "go mod tidy")
 		if config || template != "" {
 			nextSteps = append(nextSteps, "Edit .goca.yaml to customize your project")
 		}
+		nextSteps = append(nextSteps, authNextSteps(authMode)...)
 		nextSteps = append(nextSteps, "goca feature User --fields \"name:string,email:string\"")
 		if !(config || template != "") {
 			nextSteps = append(nextSteps, fmt.Sprintf("Or use a template: goca init %s --module %s --template rest-api", projectName, module))
@@ -175,7 +280,7 @@ Use --template to initialize with predefined configurations:
 
 // validDatabases / validAPIs are the allowed values for the corresponding flags.
 var (
-	validDatabases = []string{DBPostgres, DBPostgresJSON, DBMySQL, DBMongoDB, DBSQLite, DBSQLServer, DBDynamoDB, DBElasticsearch}
+	validDatabases = []string{DBPostgres, DBPostgresJSON, DBMySQL, DBMariaDB, DBTiDB, DBMongoDB, DBSQLite, DBSQLServer, DBOracle, DBDynamoDB, DBElasticsearch}
 	validAPIs      = []string{APITypeRest, APITypeGRPC, APITypeGraphQL}
 )
 
@@ -199,6 +304,28 @@ func validateAPIFlag(api string) error {
 	return fmt.Errorf("invalid --api '%s'; valid values: %s", api, strings.Join(validAPIs, ", "))
 }
 
+// validateSQLiteDriverFlag rejects --sqlite-driver values other than mattn and
+// modernc.
+func validateSQLiteDriverFlag(driver string) error {
+	for _, d := range ValidSQLiteDrivers {
+		if driver == d {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid --sqlite-driver '%s'; valid values: %s", driver, strings.Join(ValidSQLiteDrivers, ", "))
+}
+
+// validateAuthModeFlag rejects --auth-mode values other than jwt, api-key and
+// oidc.
+func validateAuthModeFlag(mode string) error {
+	for _, m := range ValidAuthModes {
+		if mode == m {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid --auth-mode '%s'; valid values: %s", mode, strings.Join(ValidAuthModes, ", "))
+}
+
 // directoryHasFiles reports whether dir exists and contains at least one entry.
 func directoryHasFiles(dir string) (bool, error) {
 	entries, err := os.ReadDir(dir)
@@ -211,10 +338,49 @@ func directoryHasFiles(dir string) (bool, error) {
 	return len(entries) > 0, nil
 }
 
-// persistInitChoices records the --api and --auth selections into the generated
-// .goca.yaml. The config generator rebuilds the file from defaults and ignores
-// these flags, so we patch the rendered YAML directly (INIT-B1, INIT-B14).
-func persistInitChoices(configPath, api string, auth bool) error {
+// resolveInitTarget interprets the init argument. A plain project name returns
+// an empty parent so the project is created under the working directory; "."
+// or a path returns the parent directory to work from and the directory name,
+// which becomes the project name.
+func resolveInitTarget(target string) (parent, name string, err error) {
+	if target != "." && target != ".." && !strings.ContainsAny(target, `/\`) {
+		return "", target, nil
+	}
+	abs, err := filepath.Abs(target)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid target directory '%s': %w", target, err)
+	}
+	if info, statErr := os.Stat(abs); statErr == nil && !info.IsDir() {
+		return "", "", fmt.Errorf("target '%s' is not a directory", target)
+	}
+	name = filepath.Base(abs)
+	if name == string(filepath.Separator) || name == "." {
+		return "", "", fmt.Errorf("cannot initialize a project at '%s'", abs)
+	}
+	return filepath.Dir(abs), name, nil
+}
+
+// detectInitConflicts renders the project in dry-run mode and returns the
+// files that already exist in projectName and would be overwritten.
+func detectInitConflicts(projectName, module, database, sqliteDriver, authMode string, tracing bool, api string, generateConfig bool) []string {
+	sm := NewSafetyManager(true, false, false)
+	createProjectStructure(projectName, module, database, sqliteDriver, authMode, tracing, api, nil, false, "", Preset{}, false, false, sm)
+
+	conflicts := sm.GetConflicts()
+	if generateConfig {
+		configPath := filepath.Join(projectName, ".goca.yaml")
+		if _, err := os.Stat(configPath); err == nil {
+			conflicts = append(conflicts, configPath)
+		}
+	}
+	return conflicts
+}
+
+// persistInitChoices records the --api, --auth, --auth-mode, --tracing and
+// --sqlite-driver selections into the generated .goca.yaml. The config generator rebuilds the
+// file from defaults and ignores these flags, so we patch the rendered YAML
+// directly (INIT-B1, INIT-B14).
+func persistInitChoices(configPath, api, sqliteDriver, authMode string, tracing bool) error {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return err
@@ -225,13 +391,43 @@ func persistInitChoices(configPath, api string, auth bool) error {
 	// specifically so we do not touch other "enabled: false" keys. Be tolerant of
 	// the marshaler's indentation by locating the "auth:" line and rewriting the
 	// first "enabled: false" that follows it.
-	if auth {
+	if authMode != "" {
 		if idx := strings.Index(content, "auth:"); idx >= 0 {
 			rest := content[idx:]
 			if rel := strings.Index(rest, "enabled: false"); rel >= 0 {
 				abs := idx + rel
 				content = content[:abs] + "enabled: true" + content[abs+len("enabled: false"):]
 			}
+			// The auth block defaults to type: jwt; record the other modes.
+			rest = content[idx:]
+			if rel := strings.Index(rest, "type: "+AuthModeJWT); rel >= 0 && authMode != AuthModeJWT {
+				abs := idx + rel
+				content = content[:abs] + "type: " + authMode + content[abs+len("type: "+AuthModeJWT):]
+			}
+		}
+	}
+
+	// Flip features.monitoring.tracing so `goca feature` generates traced use
+	// cases by default. "tracing:" also appears under logging, so anchor on the
+	// monitoring block.
+	if tracing {
+		if idx := strings.Index(content, "monitoring:"); idx >= 0 {
+			rest := content[idx:]
+			if rel := strings.Index(rest, "tracing: false"); rel >= 0 {
+				abs := idx + rel
+				content = content[:abs] + "tracing: true" + content[abs+len("tracing: false"):]
+			}
+		}
+	}
+
+	// Record the SQLite driver next to database.type so later commands (goca
+	// handler --type cli) import the same driver as main.go.
+	if sqliteDriver != "" && !strings.Contains(content, "driver: ") {
+		if idx := strings.Index(content, "type: sqlite\n"); idx >= 0 {
+			lineStart := strings.LastIndex(content[:idx], "\n") + 1
+			indent := content[lineStart:idx]
+			end := idx + len("type: sqlite\n")
+			content = content[:end] + indent + "driver: " + sqliteDriver + "\n" + content[end:]
 		}
 	}
 
@@ -246,7 +442,8 @@ func persistInitChoices(configPath, api string, auth bool) error {
 	return os.WriteFile(configPath, []byte(content), 0o600)
 }
 
-func createProjectStructure(projectName, module, database string, auth bool, api string, configIntegration *ConfigIntegration, generateConfig bool, template string, sm ...*SafetyManager) {
+func createProjectStructure(projectName, module, database, sqliteDriver, authMode string, tracing bool, api string, configIntegration *ConfigIntegration, generateConfig bool, template string, preset Preset, k8s, embedAssets bool, sm ...*SafetyManager) {
+	defer profileStep("project structure", projectName)()
 	// Create main directories
 	dirs := []string{
 		filepath.Join(projectName, "cmd", "server"),
@@ -258,9 +455,12 @@ func createProjectStructure(projectName, module, database string, auth bool, api
 		filepath.Join(projectName, "pkg", "logger"),
 	}
 
-	if auth {
+	if authMode != "" {
 		dirs = append(dirs, filepath.Join(projectName, "pkg", "auth"))
 	}
+	if tracing {
+		dirs = append(dirs, filepath.Join(projectName, "pkg", "tracing"))
+	}
 
 	// In dry-run mode nothing is written to disk: file generators record their
 	// intent via the SafetyManager instead, and the side effects below
@@ -275,10 +475,10 @@ func createProjectStructure(projectName, module, database string, auth bool, api
 	}
 
 	// Create go.mod
-	createGoMod(projectName, module, database, auth, sm...)
+	createGoMod(projectName, module, database, sqliteDriver, authMode, tracing, sm...)
 
 	// Create main.go
-	createMainGo(projectName, module, database, sm...)
+	createMainGo(projectName, module, database, sqliteDriver, tracing, sm...)
 
 	// Create .gitignore
 	createGitignore(projectName, sm...)
@@ -294,24 +494,33 @@ func createProjectStructure(projectName, module, database string, auth bool, api
 
 	// Create migrations
 	createMigrations(projectName, sm...)
+	if embedAssets {
+		createEmbeddedAssets(projectName, module, sm...)
+	}
 
 	// Create Makefile and Docker files
-	createMakefile(projectName, sm...)
-	createDockerfiles(projectName, database, sm...)
+	createMakefile(projectName, authMode == AuthModeJWT, sm...)
+	createDockerfiles(projectName, database, sqliteDriver, sm...)
 
 	// Create logger
 	createLogger(projectName, module, sm...)
 
-	if auth {
-		createAuth(projectName, module, sm...)
+	if authMode != "" {
+		createAuth(projectName, module, authMode, sm...)
+	}
+
+	if tracing {
+		createTracingPackage(projectName, sm...)
 	}
 
 	// Generate .goca.yaml configuration file if requested or template is used
 	if generateConfig && configIntegration != nil && !dryRun {
 		configPath := filepath.Join(projectName, ".goca.yaml")
 
-		// Use template configuration if specified
-		if template != "" {
+		if _, err := os.Stat(configPath); err == nil && len(sm) > 0 && sm[0] != nil && sm[0].Merge {
+			sm[0].keepExisting(configPath, 0)
+		} else if template != "" {
+			// Use template configuration if specified
 			templateConfig, err := GetTemplateConfig(template)
 			if err != nil {
 				ui.Warning(fmt.Sprintf("Failed to get template config: %v", err))
@@ -322,6 +531,11 @@ func createProjectStructure(projectName, module, database string, auth bool, api
 				if err := writeFile(configPath, configContent, sm...); err != nil {
 					ui.Warning(fmt.Sprintf("Failed to write config file: %v", err))
 				} else {
+					if preset.Name != "" {
+						if err := persistPreset(configPath, preset); err != nil {
+							ui.Warning(fmt.Sprintf("Failed to record preset in config file: %v", err))
+						}
+					}
 					ui.FileCreated(fmt.Sprintf("Generated configuration file from template '%s': %s", template, configPath))
 				}
 			}
@@ -333,9 +547,14 @@ func createProjectStructure(projectName, module, database string, auth bool, api
 				// GenerateConfigFile rebuilds the config from scratch, dropping the
 				// merged --auth/--api flags, so persist them into the written file
 				// (INIT-B1, INIT-B14).
-				if err := persistInitChoices(configPath, api, auth); err != nil {
+				if err := persistInitChoices(configPath, api, sqliteDriver, authMode, tracing); err != nil {
 					ui.Warning(fmt.Sprintf("Failed to record api/auth in config file: %v", err))
 				}
+				if preset.Name != "" {
+					if err := persistPreset(configPath, preset); err != nil {
+						ui.Warning(fmt.Sprintf("Failed to record preset in config file: %v", err))
+					}
+				}
 				ui.FileCreated(fmt.Sprintf("Generated configuration file: %s", configPath))
 			}
 		}
@@ -346,12 +565,24 @@ func createProjectStructure(projectName, module, database string, auth bool, api
 		return
 	}
 
+	// The manifests read .env and .goca.yaml, so they come after both and
+	// before the initial commit.
+	if k8s {
+		createK8sManifests(projectName, sm...)
+	}
+
 	// Download dependencies after creating go.mod
 	if err := downloadDependencies(projectName); err != nil {
 		ui.Warning(fmt.Sprintf("Failed to download dependencies: %v", err))
 		ui.Dim("Tip: Run 'go mod download' manually in the project directory")
 	}
 
+	// Leave an existing repository alone when scaffolding in place.
+	if _, err := os.Stat(filepath.Join(projectName, ".git")); err == nil {
+		ui.Info("Existing Git repository detected; skipping git init")
+		return
+	}
+
 	// Initialize Git repository
 	ui.Info("Initializing Git repository...")
 	if err := initializeGitRepository(projectName); err != nil {
@@ -364,13 +595,21 @@ func createProjectStructure(projectName, module, database string, auth bool, api
 
 func init() {
 	initCmd.Flags().StringP("module", "m", "", "Go module name (e.g: github.com/user/project)")
-	initCmd.Flags().StringP("database", "d", "sqlite", "Database type (postgres, mysql, sqlite, mongodb, sqlserver, dynamodb, elasticsearch)")
+	initCmd.Flags().StringP("database", "d", "sqlite", "Database type (postgres, mysql, mariadb, tidb, sqlite, mongodb, sqlserver, oracle, dynamodb, elasticsearch)")
+	initCmd.Flags().String("sqlite-driver", SQLiteDriverMattn, "SQLite driver: mattn (cgo) or modernc (pure Go, builds with CGO_ENABLED=0)")
 	initCmd.Flags().StringP("api", "a", "rest", "API type (rest, graphql, grpc)")
 	initCmd.Flags().Bool("auth", false, "Include authentication system")
+	initCmd.Flags().String("auth-mode", "", "Authentication mode (jwt, api-key, oidc); implies --auth. Default: features.auth.type of .goca.yaml, or jwt")
+	initCmd.Flags().Bool("tracing", false, "Include OpenTelemetry tracing (OTLP exporter, HTTP and database instrumentation)")
 	initCmd.Flags().Bool("config", true, "Generate .goca.yaml configuration file")
 	initCmd.Flags().StringP("template", "t", "", "Use predefined template (minimal, rest-api, microservice, monolith, enterprise)")
 	initCmd.Flags().Bool("list-templates", false, "List available project templates")
+	initCmd.Flags().String("preset", "", "Apply a preset of flags (api, microservice, monolith, cli-app, or one defined in .goca.yaml)")
+	initCmd.Flags().Bool("k8s", false, "Generate Kubernetes manifests like goca deploy k8s")
+	initCmd.Flags().Bool("embed-assets", false, "Compile the Swagger UI, email templates and migrations into the binary with go:embed")
 	initCmd.Flags().Bool("dry-run", false, "Preview changes without creating files")
 	initCmd.Flags().Bool("force", false, "Overwrite existing files without asking")
+	initCmd.Flags().Bool("merge", false, "Keep existing files when initializing into a non-empty directory")
+	initCmd.MarkFlagsMutuallyExclusive("force", "merge")
 	initCmd.Flags().Bool("backup", false, "Backup existing files before overwriting")
 }
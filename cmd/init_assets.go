@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// assetsDir is the package embedding the static files of a project
+// initialized with --embed-assets.
+var assetsDir = filepath.Join(DirInternal, "assets")
+
+// projectEmbedsAssets reports whether the project in the working directory
+// was initialized with --embed-assets.
+func projectEmbedsAssets() bool {
+	return fileExists(filepath.Join(assetsDir, "assets.go"))
+}
+
+// createEmbeddedAssets writes the internal/assets package, which compiles the
+// Swagger UI, the email templates and the SQL migrations into the binary, so
+// a single binary serves and migrates without the source tree. The Swagger UI
+// bundle is downloaded into it.
+func createEmbeddedAssets(projectName, module string, sm ...*SafetyManager) {
+	dir := filepath.Join(projectName, assetsDir)
+	files := []struct{ path, content string }{
+		{filepath.Join(dir, "assets.go"), fmt.Sprintf(assetsPackageTemplate, swaggerUIVersion, swaggerUIVersion, getImportPath(module))},
+		{filepath.Join(dir, "swagger-ui", "index.html"), strings.ReplaceAll(swaggerUIIndexTemplate, "{{version}}", swaggerUIVersion)},
+		{filepath.Join(dir, "templates", "email", "welcome.txt"), welcomeEmailText},
+		{filepath.Join(dir, "templates", "email", "welcome.html"), welcomeEmailHTML},
+		{filepath.Join(dir, "templates", "email", "password_reset.txt"), passwordResetEmailText},
+		{filepath.Join(dir, "templates", "email", "password_reset.html"), passwordResetEmailHTML},
+		{filepath.Join(projectName, DirMigrations, "embed.go"), migrationsEmbedSource},
+	}
+	for _, f := range files {
+		write := writeFile
+		if strings.HasSuffix(f.path, ".go") {
+			write = writeGoFile
+		}
+		if err := write(f.path, f.content, sm...); err != nil {
+			ui.Warning(fmt.Sprintf("Error writing %s: %v", f.path, err))
+		}
+	}
+	if !vendorDocsBundles(filepath.Join(dir, "swagger-ui"), swaggerUIBundles(), sm...) {
+		ui.Dim("   The Swagger UI loads its bundle from the CDN until go generate ./internal/assets vendors it")
+	}
+}
+
+const assetsPackageTemplate = `// Package assets holds the static files compiled into the binary: the
+// Swagger UI, the email templates and the SQL migrations.
+//
+// Setting ASSETS_DIR reads the Swagger UI and the email templates from
+// $ASSETS_DIR/swagger-ui and $ASSETS_DIR/templates first, falling back to the
+// embedded defaults for the files it does not contain. The migrations are
+// always the embedded ones, since they must match the code.
+package assets
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+
+	"%[3]s/migrations"
+)
+
+// goca vendors the Swagger UI bundle when it creates the project; go generate
+// downloads it again.
+//
+//go:generate curl -fsSL -o swagger-ui/swagger-ui.css https://cdn.jsdelivr.net/npm/swagger-ui-dist@%[1]s/swagger-ui.css
+//go:generate curl -fsSL -o swagger-ui/swagger-ui-bundle.js https://cdn.jsdelivr.net/npm/swagger-ui-dist@%[2]s/swagger-ui-bundle.js
+
+//go:embed swagger-ui
+var swaggerUI embed.FS
+
+//go:embed templates
+var templates embed.FS
+
+// SwaggerUI returns the Swagger UI files, index.html at the root. The page
+// loads the OpenAPI document from openapi.yaml next to it.
+func SwaggerUI() fs.FS {
+	return overlay("swagger-ui", swaggerUI)
+}
+
+// SwaggerUIHandler serves SwaggerUI; mount it below a prefix with
+// http.StripPrefix.
+func SwaggerUIHandler() http.Handler {
+	return http.FileServer(http.FS(SwaggerUI()))
+}
+
+// EmailTemplates returns the email templates: email/<name>.txt and the
+// optional email/<name>.html.
+func EmailTemplates() fs.FS {
+	return overlay("templates", templates)
+}
+
+// Migrations returns the SQL migrations of the migrations directory.
+func Migrations() fs.FS {
+	return migrations.FS
+}
+
+// Email is a rendered email.
+type Email struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// RenderEmail renders the email/<name>.txt template, whose "subject" block
+// is the subject, and email/<name>.html when it exists, with data.
+func RenderEmail(name string, data any) (Email, error) {
+	var email Email
+	files := EmailTemplates()
+
+	text, err := texttemplate.ParseFS(files, "email/"+name+".txt")
+	if err != nil {
+		return email, fmt.Errorf("email %%s: %%w", name, err)
+	}
+	var buf bytes.Buffer
+	if subject := text.Lookup("subject"); subject != nil {
+		if err := subject.Execute(&buf, data); err != nil {
+			return email, fmt.Errorf("email %%s subject: %%w", name, err)
+		}
+		email.Subject = strings.TrimSpace(buf.String())
+		buf.Reset()
+	}
+	if err := text.Execute(&buf, data); err != nil {
+		return email, fmt.Errorf("email %%s: %%w", name, err)
+	}
+	email.Text = strings.TrimSpace(buf.String()) + "\n"
+
+	if _, err := fs.Stat(files, "email/"+name+".html"); err != nil {
+		return email, nil
+	}
+	html, err := htmltemplate.ParseFS(files, "email/"+name+".html")
+	if err != nil {
+		return email, fmt.Errorf("email %%s: %%w", name, err)
+	}
+	buf.Reset()
+	if err := html.Execute(&buf, data); err != nil {
+		return email, fmt.Errorf("email %%s: %%w", name, err)
+	}
+	email.HTML = buf.String()
+	return email, nil
+}
+
+// overlay returns the directory dir of embedded, read from $ASSETS_DIR/dir
+// first when ASSETS_DIR is set.
+func overlay(dir string, embedded embed.FS) fs.FS {
+	sub, err := fs.Sub(embedded, dir)
+	if err != nil {
+		panic(err) // dir is embedded
+	}
+	root := os.Getenv("ASSETS_DIR")
+	if root == "" {
+		return sub
+	}
+	return overlayFS{disk: os.DirFS(filepath.Join(root, dir)), embedded: sub}
+}
+
+// overlayFS opens the files of disk, falling back to the embedded ones.
+type overlayFS struct {
+	disk     fs.FS
+	embedded fs.FS
+}
+
+// Open implements fs.FS.
+func (o overlayFS) Open(name string) (fs.File, error) {
+	if f, err := o.disk.Open(name); err == nil {
+		return f, nil
+	}
+	return o.embedded.Open(name)
+}
+`
+
+const swaggerUIIndexTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>API Documentation</title>
+  <link rel="stylesheet" href="swagger-ui.css" onerror="this.onerror=null; this.href='https://cdn.jsdelivr.net/npm/swagger-ui-dist@{{version}}/swagger-ui.css'">
+</head>
+<body>
+  <main id="swagger-ui" role="main" aria-label="API documentation"></main>
+  <noscript>JavaScript is required to browse the docs. The raw document is at <a href="openapi.yaml">openapi.yaml</a>.</noscript>
+  <script src="swagger-ui-bundle.js"></script>
+  <script>
+    // Load the bundle from the CDN when it is not vendored next to this page.
+    if (!window.SwaggerUIBundle) {
+      document.write('<script src="https://cdn.jsdelivr.net/npm/swagger-ui-dist@{{version}}/swagger-ui-bundle.js"><\/script>');
+    }
+  </script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "openapi.yaml", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
+
+const welcomeEmailText = `{{define "subject"}}Welcome to {{.AppName}}{{end}}Hi {{.Name}},
+
+Your {{.AppName}} account is ready. Sign in at {{.URL}}.
+
+The {{.AppName}} team
+`
+
+const welcomeEmailHTML = `<!DOCTYPE html>
+<html lang="en">
+<body style="font-family: sans-serif; line-height: 1.5;">
+  <p>Hi {{.Name}},</p>
+  <p>Your {{.AppName}} account is ready. <a href="{{.URL}}">Sign in</a>.</p>
+  <p>The {{.AppName}} team</p>
+</body>
+</html>
+`
+
+const passwordResetEmailText = `{{define "subject"}}Reset your {{.AppName}} password{{end}}Hi {{.Name}},
+
+Reset your password at {{.URL}}. The link expires in {{.ExpiresIn}}.
+
+If you did not ask for a new password, ignore this email.
+
+The {{.AppName}} team
+`
+
+const passwordResetEmailHTML = `<!DOCTYPE html>
+<html lang="en">
+<body style="font-family: sans-serif; line-height: 1.5;">
+  <p>Hi {{.Name}},</p>
+  <p><a href="{{.URL}}">Reset your password</a>. The link expires in {{.ExpiresIn}}.</p>
+  <p>If you did not ask for a new password, ignore this email.</p>
+  <p>The {{.AppName}} team</p>
+</body>
+</html>
+`
+
+const migrationsEmbedSource = `// Package migrations embeds the SQL migrations of this directory, so the
+// binary applies them without the source tree. golang-migrate ignores this
+// file when it reads the directory.
+package migrations
+
+import "embed"
+
+// FS holds the *.sql migrations. The package needs at least one to compile.
+//
+//go:embed *.sql
+var FS embed.FS
+`
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateEmbeddedAssets(t *testing.T) {
+	defer setupDiscardUI(t)()
+	chdirTemp(t)
+	sm := NewSafetyManager(false, true, false)
+	createMigrations("shop", sm)
+	createEmbeddedAssets("shop", "example.com/shop", sm)
+
+	src := readParsedGo(t, filepath.Join("shop", assetsDir, "assets.go"))
+	assert.Contains(t, src, `"example.com/shop/migrations"`)
+	assert.Contains(t, src, "//go:embed swagger-ui\n")
+	assert.Contains(t, src, "swagger-ui-dist@"+swaggerUIVersion+"/swagger-ui-bundle.js")
+	assert.Contains(t, readParsedGo(t, filepath.Join("shop", DirMigrations, "embed.go")), "//go:embed *.sql\n")
+	for _, name := range []string{"swagger-ui/index.html", "swagger-ui/swagger-ui-bundle.js", "templates/email/welcome.txt", "templates/email/password_reset.html"} {
+		assert.FileExists(t, filepath.Join("shop", assetsDir, name))
+	}
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	writeTestFile(t, "shop", "go.mod", "module example.com/shop\n\ngo 1.21\n")
+	writeTestFile(t, "shop", "cmd/check/main.go", `package main
+
+import (
+	"fmt"
+	"io/fs"
+
+	"example.com/shop/internal/assets"
+)
+
+func main() {
+	email, err := assets.RenderEmail("welcome", map[string]string{"AppName": "Shop", "Name": "Ada", "URL": "https://shop.test"})
+	if err != nil {
+		panic(err)
+	}
+	index, _ := fs.ReadFile(assets.SwaggerUI(), "index.html")
+	migrations, _ := fs.Glob(assets.Migrations(), "*.sql")
+	fmt.Printf("%s|%t|%t|%d", email.Subject, len(email.HTML) > 0, len(index) > 0, len(migrations))
+}
+`)
+	run := func(env ...string) string {
+		cmd := exec.Command("go", "run", "./cmd/check")
+		cmd.Dir = "shop"
+		cmd.Env = append(append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off"), env...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return string(out)
+	}
+	assert.Equal(t, "Welcome to Shop|true|true|2", run())
+
+	// ASSETS_DIR overrides single files and falls back to the embedded ones.
+	override := t.TempDir()
+	writeTestFile(t, override, "templates/email/welcome.txt", `{{define "subject"}}Hello from {{.AppName}}{{end}}Hi`)
+	assert.Equal(t, "Hello from Shop|true|true|2", run("ASSETS_DIR="+override))
+}
+
+func TestGenerateDocsEndpoint_EmbeddedAssets(t *testing.T) {
+	defer setupDiscardUI(t)()
+	chdirTemp(t)
+	writeTestFile(t, ".", "go.mod", "module example.com/shop\n\ngo 1.21\n")
+	writeTestFile(t, assetsDir, "assets.go", "package assets\n")
+
+	handlerDir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	generateDocsEndpoint(handlerDir, NewSafetyManager(false, true, false))
+
+	src := readParsedGo(t, filepath.Join(handlerDir, "docs.go"))
+	assert.Contains(t, src, `"example.com/shop/internal/assets"`)
+	assert.Contains(t, src, `router.PathPrefix("/docs/").Handler(http.StripPrefix("/docs/", assets.SwaggerUIHandler()))`)
+	assert.Contains(t, src, `router.Handle("/docs/redoc.html", redoc)`)
+	assert.Contains(t, src, "redoc@"+redocVersion)
+	assert.NotContains(t, src, "swagger-ui-dist@")
+	assert.FileExists(t, filepath.Join(handlerDir, "docs", "redoc.standalone.js"))
+	assert.NoFileExists(t, filepath.Join(handlerDir, "docs", "index.html"), "the Swagger UI comes from internal/assets")
+}
@@ -0,0 +1,786 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configuredAuthMode returns the auth mode of features.auth.type in the
+// .goca.yaml of projectDir, else in the config of template, else jwt. Types
+// that are not an --auth-mode (oauth2, session, basic) fall back to jwt.
+func configuredAuthMode(projectDir, template string) string {
+	var sources [][]byte
+	if path := NewConfigManager().findConfigFile(projectDir); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			sources = append(sources, bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF}))
+		}
+	}
+	if template != "" {
+		if config, err := GetTemplateConfig(template); err == nil {
+			sources = append(sources, []byte(config))
+		}
+	}
+
+	for _, data := range sources {
+		var partial struct {
+			Features struct {
+				Auth struct {
+					Type string `yaml:"type"`
+				} `yaml:"auth"`
+			} `yaml:"features"`
+		}
+		if err := yaml.Unmarshal(data, &partial); err != nil || partial.Features.Auth.Type == "" {
+			continue
+		}
+		if validateAuthModeFlag(partial.Features.Auth.Type) == nil {
+			return partial.Features.Auth.Type
+		}
+		break
+	}
+	return AuthModeJWT
+}
+
+// authNextSteps returns the steps wiring the authentication of mode into
+// cmd/server/main.go, which init leaves to the developer like the routes.
+func authNextSteps(mode string) []string {
+	switch mode {
+	case AuthModeAPIKey:
+		return []string{
+			"Change ADMIN_API_KEY in .env: it authenticates as admin to create the first keys",
+			"Add &domain.APIKey{} to runAutoMigrations in cmd/server/main.go",
+			"Serve the key endpoints: keys := usecase.NewAPIKeyService(repository.NewGormAPIKeyRepository(db)); apphttp.SetupAPIKeyRoutes(router, keys)",
+			"Protect routes with api.Use(auth.APIKeyMiddleware(keys.Authenticate))",
+		}
+	case AuthModeOIDC:
+		return []string{
+			"Set OIDC_ISSUER and OIDC_CLIENT_ID in .env",
+			"Protect routes with verifier, err := auth.NewOIDCVerifier(ctx, auth.OIDCConfigFromEnv()); api.Use(verifier.Middleware)",
+		}
+	}
+	return nil
+}
+
+// createAPIKeyAuth writes the api-key mode: the key middleware in pkg/auth,
+// and the APIKey entity with its repository, service and management
+// endpoints.
+func createAPIKeyAuth(projectName, module string, sm ...*SafetyManager) {
+	files := []struct{ path, content string }{
+		{filepath.Join(projectName, DirPkg, DirAuth, "principal.go"), principalTemplate},
+		{filepath.Join(projectName, DirPkg, DirAuth, "apikey.go"), apiKeyAuthTemplate},
+		{filepath.Join(projectName, DirInternal, DirDomain, "api_key.go"), apiKeyEntityTemplate},
+		{filepath.Join(projectName, DirInternal, DirRepository, "api_key_repository.go"), fmt.Sprintf(apiKeyRepositoryTemplate, module)},
+		{filepath.Join(projectName, DirInternal, DirUseCase, "api_key_service.go"), fmt.Sprintf(apiKeyServiceTemplate, module)},
+		{filepath.Join(projectName, DirInternal, DirHandler, DirHTTP, "api_key_handler.go"), fmt.Sprintf(apiKeyHandlerTemplate, module)},
+	}
+	for _, f := range files {
+		if err := writeGoFile(f.path, f.content, sm...); err != nil {
+			ui.Warning(fmt.Sprintf("Error creating %s: %v", f.path, err))
+		}
+	}
+
+	appendAuthEnv(projectName, "ADMIN_API_KEY",
+		"# API keys (ADMIN_API_KEY authenticates as admin to create the first keys)\nADMIN_API_KEY=\n",
+		"# API keys (ADMIN_API_KEY authenticates as admin to create the first keys)\nADMIN_API_KEY=dev-admin-key-change-in-production\n", sm...)
+}
+
+// createOIDCAuth writes the oidc mode: pkg/auth verifying the bearer tokens
+// of the issuer configured in .env.
+func createOIDCAuth(projectName string, sm ...*SafetyManager) {
+	files := []struct{ name, content string }{{"principal.go", principalTemplate}, {"oidc.go", oidcAuthTemplate}}
+	for _, f := range files {
+		path := filepath.Join(projectName, DirPkg, DirAuth, f.name)
+		if err := writeGoFile(path, f.content, sm...); err != nil {
+			ui.Warning(fmt.Sprintf("Error creating %s: %v", path, err))
+		}
+	}
+
+	block := "# OIDC Configuration (tokens must be issued by OIDC_ISSUER for OIDC_CLIENT_ID)\n" +
+		"OIDC_ISSUER=%s\nOIDC_CLIENT_ID=%s\nOIDC_ROLES_CLAIM=roles\n"
+	appendAuthEnv(projectName, "OIDC_ISSUER",
+		fmt.Sprintf(block, "https://accounts.example.com", "your-client-id"),
+		fmt.Sprintf(block, "", ""), sm...)
+}
+
+// appendAuthEnv adds the settings of an auth mode to .env.example and .env,
+// unless key is already set.
+func appendAuthEnv(projectName, key, example, dev string, sm ...*SafetyManager) {
+	var s *SafetyManager
+	if len(sm) > 0 {
+		s = sm[0]
+	}
+	for _, env := range []struct{ name, block string }{{".env.example", example}, {".env", dev}} {
+		if err := appendEnvBlock(filepath.Join(projectName, env.name), key, env.block, s); err != nil {
+			ui.Warning(fmt.Sprintf("Could not add %s to %s: %v", key, env.name, err))
+		}
+	}
+}
+
+// principalTemplate is pkg/auth/principal.go of the api-key and oidc modes:
+// the caller the middleware stores in the request context.
+const principalTemplate = `package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject identifies the caller: the key ID for API keys, the sub claim
+	// for OIDC tokens.
+	Subject string   ` + "`json:\"subject\"`" + `
+	Name    string   ` + "`json:\"name,omitempty\"`" + `
+	Email   string   ` + "`json:\"email,omitempty\"`" + `
+	Roles   []string ` + "`json:\"roles,omitempty\"`" + `
+	// Method is how the caller authenticated: api-key or oidc.
+	Method string ` + "`json:\"method\"`" + `
+	// Claims are all the claims of an OIDC token.
+	Claims map[string]interface{} ` + "`json:\"-\"`" + `
+}
+
+// HasRole reports whether the principal was granted role.
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal the auth middleware stored in
+// ctx.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// RequireRole rejects requests whose principal lacks role with 403. Use it
+// after the auth middleware.
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				http.Error(w, "unauthenticated", http.StatusUnauthorized)
+				return
+			}
+			if !p.HasRole(role) {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// bearerToken returns the token of an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+`
+
+// apiKeyAuthTemplate is pkg/auth/apikey.go: key generation and the
+// middleware authenticating requests by key.
+const apiKeyAuthTemplate = `package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"os"
+)
+
+// APIKeyHeader is the header clients send their key in. An
+// "Authorization: Bearer <key>" header is accepted too.
+const APIKeyHeader = "X-API-Key"
+
+// keyPrefix starts every generated key, so leaked keys are easy to spot.
+const keyPrefix = "ak_"
+
+// ErrInvalidAPIKey is returned for unknown, revoked and expired keys.
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// GenerateAPIKey returns a new random key, the prefix identifying it in
+// listings and the hash to store. Only the hash is kept: the key is shown
+// once, when it is created.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+	key = keyPrefix + hex.EncodeToString(secret)
+	return key, key[:len(keyPrefix)+8], HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the hex SHA-256 of a key. Keys are 256 random bits, so a
+// fast hash is enough to keep a database leak from exposing them.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// KeyAuthenticator returns the principal of a key, or ErrInvalidAPIKey.
+type KeyAuthenticator func(ctx context.Context, key string) (*Principal, error)
+
+// APIKeyMiddleware authenticates requests by the key of the X-API-Key or
+// Authorization header and stores its principal in the request context.
+// ADMIN_API_KEY, when set, authenticates as admin, to create the first keys.
+func APIKeyMiddleware(authenticate KeyAuthenticator) func(http.Handler) http.Handler {
+	admin := os.Getenv("ADMIN_API_KEY")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(APIKeyHeader)
+			if key == "" {
+				key = bearerToken(r)
+			}
+			if key == "" {
+				unauthorized(w, "missing API key")
+				return
+			}
+
+			var principal *Principal
+			if admin != "" && subtle.ConstantTimeCompare([]byte(key), []byte(admin)) == 1 {
+				principal = &Principal{Subject: "admin", Name: "admin", Roles: []string{"admin"}, Method: "api-key"}
+			} else {
+				p, err := authenticate(r.Context(), key)
+				if errors.Is(err, ErrInvalidAPIKey) {
+					unauthorized(w, err.Error())
+					return
+				}
+				if err != nil {
+					http.Error(w, "authentication failed", http.StatusInternalServerError)
+					return
+				}
+				principal = p
+			}
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+func unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	http.Error(w, message, http.StatusUnauthorized)
+}
+`
+
+// apiKeyEntityTemplate is internal/domain/api_key.go.
+const apiKeyEntityTemplate = `package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrAPIKeyNotFound      = errors.New("API key not found")
+	ErrInvalidAPIKeyName   = errors.New("API key name is required")
+	ErrInvalidAPIKeyRoles  = errors.New("API key roles cannot contain commas")
+	ErrInvalidAPIKeyExpiry = errors.New("API key expires_in must be a positive duration such as 720h")
+)
+
+// APIKey is a key clients authenticate with. Only the SHA-256 of the key is
+// stored; Prefix, its first characters, tells keys apart in listings.
+type APIKey struct {
+	ID         uint       ` + "`json:\"id\" gorm:\"primaryKey;autoIncrement\"`" + `
+	Name       string     ` + "`json:\"name\" gorm:\"type:varchar(100);not null\"`" + `
+	Prefix     string     ` + "`json:\"prefix\" gorm:\"type:varchar(20);not null\"`" + `
+	Hash       string     ` + "`json:\"-\" gorm:\"type:varchar(64);uniqueIndex;not null\"`" + `
+	Roles      string     ` + "`json:\"roles\" gorm:\"type:varchar(255)\"`" + `
+	ExpiresAt  *time.Time ` + "`json:\"expires_at,omitempty\"`" + `
+	LastUsedAt *time.Time ` + "`json:\"last_used_at,omitempty\"`" + `
+	RevokedAt  *time.Time ` + "`json:\"revoked_at,omitempty\"`" + `
+	CreatedAt  time.Time  ` + "`json:\"created_at\"`" + `
+}
+
+func (k *APIKey) Validate() error {
+	if strings.TrimSpace(k.Name) == "" {
+		return ErrInvalidAPIKeyName
+	}
+	return nil
+}
+
+// Active reports whether the key may authenticate at now: it is neither
+// revoked nor expired.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// RoleList returns the comma separated Roles.
+func (k *APIKey) RoleList() []string {
+	var roles []string
+	for _, role := range strings.Split(k.Roles, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+`
+
+// apiKeyRepositoryTemplate is internal/repository/api_key_repository.go; %[1]s
+// is the module path.
+const apiKeyRepositoryTemplate = `package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"%[1]s/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository stores the API keys.
+type APIKeyRepository interface {
+	Save(ctx context.Context, key *domain.APIKey) error
+	FindByHash(ctx context.Context, hash string) (*domain.APIKey, error)
+	FindAll(ctx context.Context) ([]domain.APIKey, error)
+	// Revoke sets the revocation time of a key that is not revoked yet.
+	Revoke(ctx context.Context, id uint, at time.Time) error
+	// Touch records when a key was last used.
+	Touch(ctx context.Context, id uint, at time.Time) error
+}
+
+type gormAPIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewGormAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &gormAPIKeyRepository{db: db}
+}
+
+func (g *gormAPIKeyRepository) Save(ctx context.Context, key *domain.APIKey) error {
+	return g.db.WithContext(ctx).Create(key).Error
+}
+
+func (g *gormAPIKeyRepository) FindByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	key := &domain.APIKey{}
+	err := g.db.WithContext(ctx).Where("hash = ?", hash).First(key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (g *gormAPIKeyRepository) FindAll(ctx context.Context) ([]domain.APIKey, error) {
+	var keys []domain.APIKey
+	if err := g.db.WithContext(ctx).Order("id").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (g *gormAPIKeyRepository) Revoke(ctx context.Context, id uint, at time.Time) error {
+	result := g.db.WithContext(ctx).Model(&domain.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (g *gormAPIKeyRepository) Touch(ctx context.Context, id uint, at time.Time) error {
+	return g.db.WithContext(ctx).Model(&domain.APIKey{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
+`
+
+// apiKeyServiceTemplate is internal/usecase/api_key_service.go; %[1]s is the
+// module path.
+const apiKeyServiceTemplate = `package usecase
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"%[1]s/internal/domain"
+	"%[1]s/internal/repository"
+	"%[1]s/pkg/auth"
+)
+
+// touchInterval bounds how often the last use of a key is written, so
+// authenticating does not write on every request.
+const touchInterval = time.Minute
+
+// CreateAPIKeyInput describes a new key. ExpiresIn is a Go duration such as
+// 720h; keys without one do not expire.
+type CreateAPIKeyInput struct {
+	Name      string   ` + "`json:\"name\" validate:\"required\"`" + `
+	Roles     []string ` + "`json:\"roles,omitempty\"`" + `
+	ExpiresIn string   ` + "`json:\"expires_in,omitempty\"`" + `
+}
+
+// CreateAPIKeyOutput carries the key, which is only returned on creation.
+type CreateAPIKeyOutput struct {
+	Key    string         ` + "`json:\"key\"`" + `
+	APIKey *domain.APIKey ` + "`json:\"api_key\"`" + `
+}
+
+// APIKeyService creates, lists and revokes the API keys, and authenticates
+// the keys of requests.
+type APIKeyService struct {
+	repo repository.APIKeyRepository
+	now  func() time.Time
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{repo: repo, now: time.Now}
+}
+
+func (s *APIKeyService) Create(ctx context.Context, input CreateAPIKeyInput) (*CreateAPIKeyOutput, error) {
+	for _, role := range input.Roles {
+		if strings.Contains(role, ",") {
+			return nil, domain.ErrInvalidAPIKeyRoles
+		}
+	}
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	apiKey := &domain.APIKey{
+		Name:   strings.TrimSpace(input.Name),
+		Prefix: prefix,
+		Hash:   hash,
+		Roles:  strings.Join(input.Roles, ","),
+	}
+	if input.ExpiresIn != "" {
+		ttl, err := time.ParseDuration(input.ExpiresIn)
+		if err != nil || ttl <= 0 {
+			return nil, domain.ErrInvalidAPIKeyExpiry
+		}
+		expiresAt := s.now().Add(ttl)
+		apiKey.ExpiresAt = &expiresAt
+	}
+	if err := apiKey.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, apiKey); err != nil {
+		return nil, err
+	}
+	return &CreateAPIKeyOutput{Key: key, APIKey: apiKey}, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context) ([]domain.APIKey, error) {
+	return s.repo.FindAll(ctx)
+}
+
+func (s *APIKeyService) Revoke(ctx context.Context, id uint) error {
+	return s.repo.Revoke(ctx, id, s.now())
+}
+
+// Authenticate returns the principal of an active key, or
+// auth.ErrInvalidAPIKey. It is the auth.KeyAuthenticator of
+// auth.APIKeyMiddleware.
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (*auth.Principal, error) {
+	apiKey, err := s.repo.FindByHash(ctx, auth.HashAPIKey(key))
+	if errors.Is(err, domain.ErrAPIKeyNotFound) {
+		return nil, auth.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if !apiKey.Active(now) {
+		return nil, auth.ErrInvalidAPIKey
+	}
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= touchInterval {
+		// Losing a last use is harmless; it does not fail the request.
+		_ = s.repo.Touch(ctx, apiKey.ID, now)
+	}
+	return &auth.Principal{
+		Subject: strconv.FormatUint(uint64(apiKey.ID), 10),
+		Name:    apiKey.Name,
+		Roles:   apiKey.RoleList(),
+		Method:  "api-key",
+	}, nil
+}
+`
+
+// apiKeyHandlerTemplate is internal/handler/http/api_key_handler.go; %[1]s is
+// the module path.
+const apiKeyHandlerTemplate = `package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"%[1]s/internal/domain"
+	"%[1]s/internal/usecase"
+	"%[1]s/pkg/auth"
+	"github.com/gorilla/mux"
+)
+
+type APIKeyHandler struct {
+	service *usecase.APIKeyService
+}
+
+func NewAPIKeyHandler(service *usecase.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: service}
+}
+
+// SetupAPIKeyRoutes registers the key management endpoints under /api-keys.
+// They require a key with the admin role, or ADMIN_API_KEY.
+func SetupAPIKeyRoutes(router *mux.Router, service *usecase.APIKeyService) {
+	handler := NewAPIKeyHandler(service)
+
+	keyRouter := router.PathPrefix("/api-keys").Subrouter()
+	keyRouter.Use(auth.APIKeyMiddleware(service.Authenticate), auth.RequireRole("admin"))
+
+	keyRouter.HandleFunc("", handler.CreateAPIKey).Methods("POST")
+	keyRouter.HandleFunc("", handler.ListAPIKeys).Methods("GET")
+	keyRouter.HandleFunc("/{id}", handler.RevokeAPIKey).Methods("DELETE")
+}
+
+// Create API key godoc
+// @Summary Create API key
+// @Description The key is only returned in this response.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param body body usecase.CreateAPIKeyInput true "API key payload"
+// @Success 201 {object} usecase.CreateAPIKeyOutput
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var input usecase.CreateAPIKeyInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	output, err := h.service.Create(r.Context(), input)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidAPIKeyName) || errors.Is(err, domain.ErrInvalidAPIKeyRoles) || errors.Is(err, domain.ErrInvalidAPIKeyExpiry) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(output)
+}
+
+// List API keys godoc
+// @Summary List API keys
+// @Tags api-keys
+// @Produce json
+// @Success 200 {array} domain.APIKey
+// @Failure 500 {object} map[string]string
+// @Router /api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.List(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+}
+
+// Revoke API key godoc
+// @Summary Revoke API key
+// @Tags api-keys
+// @Param id path int true "API key ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid API key ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Revoke(r.Context(), uint(id)); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+`
+
+// oidcAuthTemplate is pkg/auth/oidc.go: verification of the tokens of an
+// OpenID Connect issuer with go-oidc.
+const oidcAuthTemplate = `package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+)
+
+// OIDCConfig configures the verification of the tokens of an OIDC issuer.
+type OIDCConfig struct {
+	// Issuer is the URL of the provider, whose discovery document gives the
+	// signing keys, e.g. https://accounts.google.com.
+	Issuer string
+	// ClientID is the audience tokens must be issued for.
+	ClientID string
+	// RolesClaim is the claim holding the roles of the caller: roles by
+	// default, groups or realm_access.roles (Keycloak) for instance.
+	RolesClaim string
+}
+
+// OIDCConfigFromEnv reads OIDC_ISSUER, OIDC_CLIENT_ID and OIDC_ROLES_CLAIM.
+func OIDCConfigFromEnv() OIDCConfig {
+	return OIDCConfig{
+		Issuer:     os.Getenv("OIDC_ISSUER"),
+		ClientID:   os.Getenv("OIDC_CLIENT_ID"),
+		RolesClaim: os.Getenv("OIDC_ROLES_CLAIM"),
+	}
+}
+
+// OIDCVerifier verifies bearer tokens against the issuer and maps their
+// claims to a Principal.
+type OIDCVerifier struct {
+	verifier   *oidc.IDTokenVerifier
+	rolesClaim string
+}
+
+// NewOIDCVerifier fetches the discovery document of the issuer; ctx bounds
+// that request.
+func NewOIDCVerifier(ctx context.Context, cfg OIDCConfig) (*OIDCVerifier, error) {
+	if cfg.Issuer == "" {
+		return nil, errors.New("OIDC_ISSUER is not set")
+	}
+	if cfg.ClientID == "" {
+		return nil, errors.New("OIDC_CLIENT_ID is not set")
+	}
+	provider, err := oidc.NewProvider(ctx, cfg.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("discovering OIDC issuer %s: %w", cfg.Issuer, err)
+	}
+	rolesClaim := cfg.RolesClaim
+	if rolesClaim == "" {
+		rolesClaim = "roles"
+	}
+	return &OIDCVerifier{
+		verifier:   provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+		rolesClaim: rolesClaim,
+	}, nil
+}
+
+// Verify checks the signature, issuer, audience and expiry of a token and
+// returns its principal.
+func (v *OIDCVerifier) Verify(ctx context.Context, rawToken string) (*Principal, error) {
+	token, err := v.verifier.Verify(ctx, rawToken)
+	if err != nil {
+		return nil, err
+	}
+	var claims map[string]interface{}
+	if err := token.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	principal := &Principal{
+		Subject: token.Subject,
+		Roles:   claimStrings(claims, v.rolesClaim),
+		Method:  "oidc",
+		Claims:  claims,
+	}
+	principal.Email, _ = claims["email"].(string)
+	principal.Name, _ = claims["name"].(string)
+	if principal.Name == "" {
+		principal.Name, _ = claims["preferred_username"].(string)
+	}
+	return principal, nil
+}
+
+// Middleware authenticates requests by their bearer token and stores the
+// principal in the request context.
+func (v *OIDCVerifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := bearerToken(r)
+		if token == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+		principal, err := v.Verify(r.Context(), token)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", ` + "`Bearer error=\"invalid_token\"`" + `)
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// claimStrings returns the strings of a claim, a list or a single string.
+// Dotted paths such as realm_access.roles descend into objects.
+func claimStrings(claims map[string]interface{}, path string) []string {
+	var value interface{} = claims
+	for _, part := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[part]
+	}
+
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+`
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAuth_APIKey(t *testing.T) {
+	defer setupDiscardUI(t)()
+
+	dir := t.TempDir()
+	sm := NewSafetyManager(false, true, false)
+	createEnvFiles(dir, DBPostgres, sm)
+	createAuth(dir, "github.com/test/proj", AuthModeAPIKey, sm)
+
+	middleware := readParsedGo(t, filepath.Join(dir, "pkg", "auth", "apikey.go"))
+	assert.Contains(t, middleware, "func APIKeyMiddleware(authenticate KeyAuthenticator) func(http.Handler) http.Handler {")
+	assert.Contains(t, readParsedGo(t, filepath.Join(dir, "internal", "domain", "api_key.go")), "Hash       string     `json:\"-\"")
+	assert.Contains(t, readParsedGo(t, filepath.Join(dir, "internal", "repository", "api_key_repository.go")), `"github.com/test/proj/internal/domain"`)
+	assert.Contains(t, readParsedGo(t, filepath.Join(dir, "internal", "usecase", "api_key_service.go")), "func (s *APIKeyService) Authenticate(ctx context.Context, key string) (*auth.Principal, error) {")
+	assert.Contains(t, readParsedGo(t, filepath.Join(dir, "internal", "handler", "http", "api_key_handler.go")), `keyRouter.HandleFunc("/{id}", handler.RevokeAPIKey).Methods("DELETE")`)
+	assert.NoFileExists(t, filepath.Join(dir, "pkg", "auth", "jwt.go"))
+
+	env, err := os.ReadFile(filepath.Join(dir, ".env"))
+	require.NoError(t, err)
+	assert.Contains(t, string(env), "\nADMIN_API_KEY=dev-admin-key-change-in-production\n")
+}
+
+func TestCreateAuth_OIDC(t *testing.T) {
+	defer setupDiscardUI(t)()
+
+	dir := t.TempDir()
+	sm := NewSafetyManager(false, true, false)
+	createEnvFiles(dir, DBMongoDB, sm)
+	createAuth(dir, "github.com/test/proj", AuthModeOIDC, sm)
+	createGoMod(dir, "github.com/test/proj", DBMongoDB, "", AuthModeOIDC, false, sm)
+
+	oidc := readParsedGo(t, filepath.Join(dir, "pkg", "auth", "oidc.go"))
+	assert.Contains(t, oidc, `provider.Verifier(&oidc.Config{ClientID: cfg.ClientID})`)
+	assert.Contains(t, oidc, "func claimStrings(claims map[string]interface{}, path string) []string {")
+	assert.FileExists(t, filepath.Join(dir, "pkg", "auth", "principal.go"))
+
+	example, err := os.ReadFile(filepath.Join(dir, ".env.example"))
+	require.NoError(t, err)
+	assert.Contains(t, string(example), "OIDC_ISSUER=https://accounts.example.com\nOIDC_CLIENT_ID=your-client-id\nOIDC_ROLES_CLAIM=roles\n")
+
+	gomod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	require.NoError(t, err)
+	assert.Contains(t, string(gomod), "github.com/coreos/go-oidc/v3")
+	assert.NotContains(t, string(gomod), "golang-jwt")
+}
+
+func TestConfiguredAuthMode(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, AuthModeJWT, configuredAuthMode(dir, ""))
+	assert.Equal(t, AuthModeJWT, configuredAuthMode(dir, "monolith"))
+
+	writeTestFile(t, dir, ".goca.yaml", "features:\n  auth:\n    enabled: true\n    type: oidc\n")
+	assert.Equal(t, AuthModeOIDC, configuredAuthMode(dir, "monolith"), "the project config comes before the template")
+
+	writeTestFile(t, dir, ".goca.yaml", "features:\n  auth:\n    type: oauth2\n")
+	assert.Equal(t, AuthModeJWT, configuredAuthMode(dir, ""))
+}
+
+func TestPersistInitChoices_AuthMode(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".goca.yaml")
+	writeTestFile(t, dir, ".goca.yaml", "features:\n  auth:\n    enabled: false\n    type: jwt\n  cache:\n    type: redis\n")
+
+	require.NoError(t, persistInitChoices(path, APITypeRest, "", AuthModeAPIKey, false))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "auth:\n    enabled: true\n    type: api-key\n")
+
+	cm := NewConfigManager()
+	cm.validateFeatures(&FeatureConfig{Auth: AuthConfig{Enabled: true, Type: AuthModeAPIKey}})
+	assert.Empty(t, cm.GetErrors())
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 func createMigrations(projectName string, sm ...*SafetyManager) {
@@ -106,7 +107,12 @@ psql -h localhost -U postgres -d your_db -f migrations/001_initial.down.sql
 	}
 }
 
-func createMakefile(projectName string, sm ...*SafetyManager) {
+// devTokenTarget runs the mint-token tool of --auth projects with the
+// variables of .env.
+const devTokenTarget = "dev-token: ## Print JWTs of the development users (ARGS=\"-user admin\" for one)\n" +
+	"\t@set -a; [ ! -f .env ] || . ./.env; set +a; go run ./cmd/tools/mint-token $(ARGS)\n\n"
+
+func createMakefile(projectName string, auth bool, sm ...*SafetyManager) {
 	makefileContent := fmt.Sprintf(`# Makefile for %s
 .PHONY: help build run test clean docker-build docker-run deps lint migrate-up migrate-down
 
@@ -198,20 +204,38 @@ sec-scan: ## Run security scan
 api-docs: ## Generate API documentation
 	swag init -g cmd/server/main.go
 `, projectName, projectName, projectName, projectName, projectName, projectName, projectName, projectName)
+	if auth {
+		makefileContent = strings.Replace(makefileContent, ".PHONY: help build run test ", ".PHONY: help build run test dev-token ", 1)
+		makefileContent = strings.Replace(makefileContent, "\nfmt:", "\n"+devTokenTarget+"fmt:", 1)
+	}
 
 	if err := writeFile(filepath.Join(projectName, "Makefile"), makefileContent, sm...); err != nil {
 		ui.Warning(fmt.Sprintf("Error creating Makefile: %v", err))
 	}
 }
 
-func createDockerfiles(projectName, database string, sm ...*SafetyManager) {
+func createDockerfiles(projectName, database, sqliteDriver string, sm ...*SafetyManager) {
+	// The mattn SQLite driver is cgo, so it needs a C toolchain and
+	// CGO_ENABLED=1; the binary links against musl, which alpine provides.
+	toolchain := ""
+	build := fmt.Sprintf(`RUN CGO_ENABLED=0 GOOS=linux go build -a -installsuffix cgo -ldflags '-extldflags "-static"' -o bin/%s cmd/server/main.go`, projectName)
+	if database == DBSQLite && sqliteDriver != SQLiteDriverModernc {
+		toolchain = "# Install the C toolchain required by the cgo SQLite driver\nRUN apk add --no-cache gcc musl-dev\n\n"
+		build = fmt.Sprintf("RUN CGO_ENABLED=1 GOOS=linux go build -o bin/%s cmd/server/main.go", projectName)
+	}
+	// The SQLite database file lives in /data so it can be kept on a volume.
+	dataDir := ""
+	if database == DBSQLite {
+		dataDir = "\n# Directory of the SQLite database file\nRUN mkdir -p /data\n"
+	}
+
 	// Dockerfile
 	dockerfileContent := fmt.Sprintf(`# Build stage
 FROM golang:1.21-alpine AS builder
 
 WORKDIR /app
 
-# Install dependencies
+%s# Install dependencies
 COPY go.mod go.sum ./
 RUN go mod download
 
@@ -219,14 +243,14 @@ RUN go mod download
 COPY . .
 
 # Build the application
-RUN CGO_ENABLED=0 GOOS=linux go build -a -installsuffix cgo -ldflags '-extldflags "-static"' -o bin/%s cmd/server/main.go
+%s
 
 # Final stage
 FROM alpine:latest
 
 # Install ca-certificates for HTTPS
 RUN apk --no-cache add ca-certificates
-
+%s
 WORKDIR /root/
 
 # Copy the binary from builder stage
@@ -244,16 +268,19 @@ HEALTHCHECK --interval=30s --timeout=3s --start-period=5s --retries=3 \
 
 # Run the application
 CMD ["./%s"]
-`, projectName, projectName, projectName)
+`, toolchain, build, dataDir, projectName, projectName)
 
 	if err := writeFile(filepath.Join(projectName, "Dockerfile"), dockerfileContent, sm...); err != nil {
 		ui.Warning(fmt.Sprintf("Error creating Dockerfile: %v", err))
 	}
 
 	// Docker Compose. SQLite is file-based, so emit only the app service with
-	// no DB container (INIT-B6).
+	// no DB container (INIT-B6); the database file is kept on a volume. TiDB
+	// needs its database created by a client (see tidbDockerCompose), and
+	// Oracle a longer first start (see oracleDockerCompose).
 	var dockerComposeContent string
-	if database == DBSQLite {
+	switch database {
+	case DBSQLite:
 		dockerComposeContent = fmt.Sprintf(`version: '3.8'
 
 services:
@@ -262,10 +289,19 @@ services:
     ports:
       - "8080:8080"
     environment:
-      - DB_NAME=%s
+      - DB_PATH=/data/%s.db
+    volumes:
+      - sqlite_data:/data
     restart: unless-stopped
+
+volumes:
+  sqlite_data:
 `, projectName, projectName)
-	} else {
+	case DBTiDB:
+		dockerComposeContent = tidbDockerCompose(projectName)
+	case DBOracle:
+		dockerComposeContent = oracleDockerCompose(projectName)
+	default:
 		port := getDatabasePort(database)
 		dockerComposeContent = fmt.Sprintf(`version: '3.8'
 
@@ -353,6 +389,10 @@ func getDatabaseImage(database string) string {
 	switch database {
 	case DBMySQL:
 		return "mysql:8.0"
+	case DBMariaDB:
+		return mariadbImage
+	case DBTiDB:
+		return tidbImage
 	case DBMongoDB:
 		return "mongo:7.0"
 	case DBSQLServer:
@@ -370,6 +410,8 @@ func getDatabaseEnvVars(database, projectName string) string {
 	switch database {
 	case DBMySQL:
 		return fmt.Sprintf("\n      - MYSQL_ROOT_PASSWORD=password\n      - MYSQL_DATABASE=%s", projectName)
+	case DBMariaDB:
+		return fmt.Sprintf("\n      - MARIADB_ROOT_PASSWORD=password\n      - MARIADB_DATABASE=%s", projectName)
 	case DBMongoDB:
 		return fmt.Sprintf("\n      - MONGO_INITDB_ROOT_USERNAME=admin\n      - MONGO_INITDB_ROOT_PASSWORD=password\n      - MONGO_INITDB_DATABASE=%s", projectName)
 	case DBSQLServer:
@@ -387,6 +429,10 @@ func getDatabaseHealthCheck(database string) string {
 	switch database {
 	case DBMySQL:
 		return `["CMD", "mysqladmin", "ping", "-h", "localhost"]`
+	case DBMariaDB:
+		// MariaDB 11 images no longer ship mysqladmin; healthcheck.sh
+		// waits for InnoDB too.
+		return `["CMD", "healthcheck.sh", "--connect", "--innodb_initialized"]`
 	case DBMongoDB:
 		// mongo:7.0 ships mongosh, not the removed legacy mongo shell (INIT-B7).
 		return `["CMD", "mongosh", "--eval", "db.adminCommand('ping')"]`
@@ -405,7 +451,7 @@ func getDatabaseHealthCheck(database string) string {
 // image, so the named volume is mounted at the correct path (INIT-B5).
 func getDatabaseVolumePath(database string) string {
 	switch database {
-	case DBMySQL:
+	case DBMySQL, DBMariaDB:
 		return "/var/lib/mysql"
 	case DBMongoDB:
 		return "/data/db"
@@ -5,61 +5,64 @@ import (
 	"path/filepath"
 )
 
-func createMainGo(projectName, module, database string, sm ...*SafetyManager) {
-	// For MongoDB and other NoSQL databases, generate a different main.go
-	if database == DBMongoDB {
-		createMongoDBMainGo(projectName, module, sm...)
-		return
-	}
+// mainGoData is the data of the main.go templates.
+type mainGoData struct {
+	Module string
+	// DriverImport and DriverPackage are the GORM driver of the database.
+	DriverImport  string
+	DriverPackage string
+	// SQLite databases are files, so there is no host to log.
+	SQLite bool
+	// PasswordRequired skips connecting in development when no password is
+	// set (avoids connection-retry spam).
+	PasswordRequired bool
+}
 
-	// For DynamoDB and Elasticsearch, generate specific implementations
-	if database == DBDynamoDB {
-		createDynamoDBMainGo(projectName, module, sm...)
-		return
+// createMainGo writes cmd/server/main.go, rendered from the template of the
+// database.
+func createMainGo(projectName, module, database, sqliteDriver string, tracing bool, sm ...*SafetyManager) {
+	data := mainGoData{Module: module}
+	var tmpl string
+	switch database {
+	case DBMongoDB:
+		tmpl = mongoDBMainGoTemplate
+	case DBDynamoDB:
+		tmpl = dynamoDBMainGoTemplate
+	case DBElasticsearch:
+		tmpl = elasticsearchMainGoTemplate
+	default:
+		tmpl = gormMainGoTemplate
+		data.DriverImport, data.DriverPackage = gormDriver(database, sqliteDriver)
+		data.SQLite = database == DBSQLite
+		// File-based SQLite always connects, and so does TiDB, whose root
+		// user has no password by default.
+		data.PasswordRequired = database != DBSQLite && database != DBTiDB
 	}
 
-	if database == DBElasticsearch {
-		createElasticsearchMainGo(projectName, module, sm...)
+	content, err := renderGoTemplate("main.go", tmpl, data)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Error writing main.go: %v", err))
 		return
 	}
 
-	// Determine database driver import based on database type (GORM databases)
-	var dbDriverImport string
-	var dbDriverPackage string
-	var requiresGorm bool
+	content = applyBrandingToMainGo(content, loadBranding(projectName))
 
-	switch database {
-	case DBPostgres, DBPostgresJSON:
-		dbDriverImport = `"gorm.io/driver/postgres"`
-		dbDriverPackage = "postgres"
-		requiresGorm = true
-	case DBMySQL:
-		dbDriverImport = `"gorm.io/driver/mysql"`
-		dbDriverPackage = "mysql"
-		requiresGorm = true
-	case DBSQLite:
-		dbDriverImport = `"gorm.io/driver/sqlite"`
-		dbDriverPackage = "sqlite"
-		requiresGorm = true
-	case DBSQLServer:
-		dbDriverImport = `"gorm.io/driver/sqlserver"`
-		dbDriverPackage = "sqlserver"
-		requiresGorm = true
-	default:
-		dbDriverImport = `"gorm.io/driver/sqlite"`
-		dbDriverPackage = "sqlite"
-		requiresGorm = true
+	if tracing {
+		content = applyTracingToMainGo(content, projectName, module, database)
 	}
 
-	// Password-based drivers skip connecting in development when no password
-	// is set (avoids connection-retry spam); file-based SQLite always connects.
-	degradedBlock := ""
-	if database != DBSQLite {
-		degradedBlock = "// Check if this is development mode without database\n\tif cfg.Environment == \"development\" && cfg.Database.Password == \"\" {\n\t\tlog.Println(\"Warning: Development mode detected: No database password set\")\n\t\tlog.Println(\"To connect to database, set environment variables:\")\n\t\tlog.Println(\"   DB_HOST=localhost\")\n\t\tlog.Println(\"   DB_PORT=<port>\") \n\t\tlog.Println(\"   DB_USER=<user>\")\n\t\tlog.Println(\"   DB_PASSWORD=your_password\")\n\t\tlog.Println(\"   DB_NAME=your_database\")\n\t\tlog.Println(\"Server will continue without database connection...\")\n\t\treturn nil, fmt.Errorf(\"development mode: database not configured\")\n\t}"
+	if err := writeGoFile(filepath.Join(projectName, "cmd", "server", "main.go"), content, sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Error writing main.go: %v", err))
+		return
 	}
+}
+
+// gormMainGoTemplate is the main.go of the GORM databases. The server starts
+// without a database when it cannot connect, and the health checks report it.
+const gormMainGoTemplate = `package main
 
-	// Build imports
-	importLines := `"context"
+import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -69,39 +72,255 @@ func createMainGo(projectName, module, database string, sm ...*SafetyManager) {
 	"syscall"
 	"time"
 
-	"github.com/gorilla/mux"`
+	"github.com/gorilla/mux"
+	"{{.DriverImport}}"
+	"gorm.io/gorm"
+	"{{.Module}}/pkg/config"
+	"{{.Module}}/pkg/logger"
+)
 
-	if requiresGorm {
-		importLines += `
-	"gorm.io/gorm"`
+type HealthStatus struct {
+	Status    string            ` + "`" + `json:"status"` + "`" + `
+	Timestamp time.Time         ` + "`" + `json:"timestamp"` + "`" + `
+	Services  map[string]string ` + "`" + `json:"services"` + "`" + `
+	Version   string            ` + "`" + `json:"version"` + "`" + `
+}
+
+var (
+	// Build information (set by build flags)
+	Version   = "dev"
+	BuildTime = "unknown"
+	db        *gorm.DB
+)
+
+func main() {
+	// Load configuration
+	cfg := config.Load()
+
+	// Initialize logger
+	logger.Init()
+
+	log.Printf("Starting application v%s (built: %s)", Version, BuildTime)
+	log.Printf("Environment: %s", cfg.Environment)
+
+	// Connect to database with retry
+	var err error
+	db, err = connectToDatabase(cfg)
+	if err != nil {
+		log.Printf("Warning: Database connection failed: %v", err)
+		log.Printf("Server will start in degraded mode. Check your database configuration.")
+		log.Printf("Tip: Configure database environment variables in .env file")
+		db = nil // Ensure db is nil for health checks
+	} else {
+		log.Printf("Database connected successfully")
+
+		// Run auto-migrations if database is connected
+		if err := runAutoMigrations(db); err != nil {
+			log.Printf("Warning: Auto-migration failed: %v", err)
+			log.Printf("Tip: You may need to run migrations manually")
+		} else {
+			log.Printf("Database schema is up to date")
+		}
 	}
 
-	importLines += fmt.Sprintf(`
-	%s
-	"%s/pkg/config"
-	"%s/pkg/logger"`, dbDriverImport, module, module)
+	// Setup router
+	router := mux.NewRouter()
 
-	// Generate main.go content with database-specific connection
-	content := fmt.Sprintf(`package main
+	// Health check endpoint with comprehensive checks
+	router.HandleFunc("/health", healthCheckHandler).Methods("GET")
+	router.HandleFunc("/health/ready", readinessHandler).Methods("GET")
+	router.HandleFunc("/health/live", livenessHandler).Methods("GET")
 
-import (
-	%s
-)
+	// Setup HTTP server with timeouts
+	server := &http.Server{
+		Addr:         ":" + cfg.Port,
+		Handler:      router,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
 
-type HealthStatus struct {
-	Status    string            `+"`"+`json:"status"`+"`"+`
-	Timestamp time.Time         `+"`"+`json:"timestamp"`+"`"+`
-	Services  map[string]string `+"`"+`json:"services"`+"`"+`
-	Version   string            `+"`"+`json:"version"`+"`"+"\n}\n\nvar (\n\t// Build information (set by build flags)\n\tVersion   = \"dev\"\n\tBuildTime = \"unknown\"\n\tdb        *gorm.DB\n)\n\nfunc main() {\n\t// Load configuration\n\tcfg := config.Load()\n\t\n\t// Initialize logger\n\tlogger.Init()\n\t\n\tlog.Printf(\"Starting application v%%s (built: %%s)\", Version, BuildTime)\n\tlog.Printf(\"Environment: %%s\", cfg.Environment)\n\t\n\t// Connect to database with retry\n\tvar err error\n\tdb, err = connectToDatabase(cfg)\n\tif err != nil {\n\t\tlog.Printf(\"Warning: Database connection failed: %%v\", err)\n\t\tlog.Printf(\"Server will start in degraded mode. Check your database configuration.\")\n\t\tlog.Printf(\"Tip: Configure database environment variables in .env file\")\n\t\tdb = nil // Ensure db is nil for health checks\n\t} else {\n\t\tlog.Printf(\"Database connected successfully\")\n\t\t\n\t\t// Run auto-migrations if database is connected\n\t\tif err := runAutoMigrations(db); err != nil {\n\t\t\tlog.Printf(\"Warning: Auto-migration failed: %%v\", err)\n\t\t\tlog.Printf(\"Tip: You may need to run migrations manually\")\n\t\t} else {\n\t\t\tlog.Printf(\"Database schema is up to date\")\n\t\t}\n\t}\n\t\n\t// Setup router\n\trouter := mux.NewRouter()\n\t\n\t// Health check endpoint with comprehensive checks\n\trouter.HandleFunc(\"/health\", healthCheckHandler).Methods(\"GET\")\n\trouter.HandleFunc(\"/health/ready\", readinessHandler).Methods(\"GET\")\n\trouter.HandleFunc(\"/health/live\", livenessHandler).Methods(\"GET\")\n\t\n\t// Setup HTTP server with timeouts\n\tserver := &http.Server{\n\t\tAddr:         \":\" + cfg.Port,\n\t\tHandler:      router,\n\t\tReadTimeout:  cfg.Server.ReadTimeout,\n\t\tWriteTimeout: cfg.Server.WriteTimeout,\n\t\tIdleTimeout:  cfg.Server.IdleTimeout,\n\t}\n\t\n\t// Start server in goroutine\n\tgo func() {\n\t\tlog.Printf(\"Server starting on port %%s\", cfg.Port)\n\t\tif err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {\n\t\t\tlog.Fatalf(\"Server startup failed: %%v\", err)\n\t\t}\n\t}()\n\t\n\t// Wait for interrupt signal to gracefully shutdown\n\tquit := make(chan os.Signal, 1)\n\tsignal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)\n\t<-quit\n\t\n\tlog.Println(\"Shutting down server...\")\n\t\n\t// Graceful shutdown with timeout\n\tctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)\n\tdefer cancel()\n\t\n\tif err := server.Shutdown(ctx); err != nil {\n\t\tlog.Printf(\"Server forced to shutdown: %%v\", err)\n\t}\n\t\n\tlog.Println(\"Server exited\")\n}\n\nfunc connectToDatabase(cfg *config.Config) (*gorm.DB, error) {\n\tdsn := cfg.GetDatabaseURL()\n\t\n\tlog.Printf(\"Connecting to database at %%s:%%s/%%s\", cfg.Database.Host, cfg.Database.Port, cfg.Database.Name)\n\t\n\t%s\n\t\n\t// Retry connection up to 5 times\n\tfor i := 0; i < 5; i++ {\n\t\tdb, err := gorm.Open(%s.Open(dsn), &gorm.Config{})\n\t\tif err != nil {\n\t\t\tlog.Printf(\"Attempt %%d: Failed to open database connection: %%v\", i+1, err)\n\t\t\ttime.Sleep(time.Duration(i+1) * time.Second)\n\t\t\tcontinue\n\t\t}\n\t\t\n\t\t// Get underlying sql.DB for connection pool configuration\n\t\tsqlDB, err := db.DB()\n\t\tif err != nil {\n\t\t\tlog.Printf(\"Attempt %%d: Failed to get underlying SQL DB: %%v\", i+1, err)\n\t\t\ttime.Sleep(time.Duration(i+1) * time.Second)\n\t\t\tcontinue\n\t\t}\n\t\t\n\t\t// Configure connection pool\n\t\tsqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)\n\t\tsqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)\n\t\tsqlDB.SetConnMaxLifetime(cfg.Database.MaxLifetime)\n\t\t\n\t\t// Test the connection\n\t\tctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)\n\t\terr = sqlDB.PingContext(ctx)\n\t\tcancel()\n\t\t\n\t\tif err == nil {\n\t\t\treturn db, nil\n\t\t}\n\t\t\n\t\tlog.Printf(\"Attempt %%d: Database ping failed: %%v\", i+1, err)\n\t\tsqlDBClose, _ := db.DB()\n\t\tif sqlDBClose != nil {\n\t\t\tsqlDBClose.Close()\n\t\t}\n\t\ttime.Sleep(time.Duration(i+1) * time.Second)\n\t}\n\t\n\treturn nil, fmt.Errorf(\"failed to connect to database after 5 attempts\")\n}\n\nfunc healthCheckHandler(w http.ResponseWriter, r *http.Request) {\n\tstatus := HealthStatus{\n\t\tStatus:    \"healthy\",\n\t\tTimestamp: time.Now(),\n\t\tServices:  make(map[string]string),\n\t\tVersion:   Version,\n\t}\n\t\n\t// Check database\n\tif err := checkDatabase(); err != nil {\n\t\tstatus.Status = \"degraded\"\n\t\tstatus.Services[\"database\"] = fmt.Sprintf(\"error: %%v\", err)\n\t\t// Don't fail the whole health check for database issues in development\n\t\tlog.Printf(\"Database health check failed: %%v\", err)\n\t} else {\n\t\tstatus.Services[\"database\"] = \"healthy\"\n\t}\n\t\n\t// Always return 200 for basic health check - let readiness handle critical dependencies\n\tw.Header().Set(\"Content-Type\", \"application/json\")\n\tjson.NewEncoder(w).Encode(status)\n}\n\nfunc readinessHandler(w http.ResponseWriter, r *http.Request) {\n\t// Check if all dependencies are ready\n\tif err := checkDatabase(); err != nil {\n\t\thttp.Error(w, fmt.Sprintf(\"Database not ready: %%v\", err), http.StatusServiceUnavailable)\n\t\treturn\n\t}\n\t\n\tw.WriteHeader(http.StatusOK)\n\tw.Write([]byte(\"Ready\"))\n}\n\nfunc livenessHandler(w http.ResponseWriter, r *http.Request) {\n\t// Basic liveness check\n\tw.WriteHeader(http.StatusOK)\n\tw.Write([]byte(\"Alive\"))\n}\n\nfunc checkDatabase() error {\n\tif db == nil {\n\t\treturn fmt.Errorf(\"database connection is nil\")\n\t}\n\t\n\tsqlDB, err := db.DB()\n\tif err != nil {\n\t\treturn fmt.Errorf(\"failed to get underlying sql DB: %%w\", err)\n\t}\n\t\n\tctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)\n\tdefer cancel()\n\t\n\treturn sqlDB.PingContext(ctx)\n}\n\nfunc runAutoMigrations(database *gorm.DB) error {\n\tif database == nil {\n\t\treturn fmt.Errorf(\"database connection is nil\")\n\t}\n\t\n\t// Auto-migrate domain entities using GORM\n\tlog.Println(\"Running GORM auto-migrations...\")\n\t\n\t// Create a slice of all domain entities to migrate\n\tentities := []interface{}{\n\t\t// Add domain entities here as they are created\n\t\t// Example: &domain.User{}, &domain.Product{}\n\t}\n\t\n\t// Run auto-migration for all entities\n\tfor _, entity := range entities {\n\t\tif err := database.AutoMigrate(entity); err != nil {\n\t\t\treturn fmt.Errorf(\"failed to auto-migrate entity %%T: %%w\", entity, err)\n\t\t}\n\t}\n\t\n\t// For now, just ensure the connection works\n\tsqlDB, err := database.DB()\n\tif err != nil {\n\t\treturn fmt.Errorf(\"failed to get underlying SQL DB: %%w\", err)\n\t}\n\t\n\tif err := sqlDB.Ping(); err != nil {\n\t\treturn fmt.Errorf(\"database ping failed: %%w\", err)\n\t}\n\t\n\tlog.Println(\"GORM auto-migrations completed successfully\")\n\treturn nil\n}\n\n", importLines, degradedBlock, dbDriverPackage)
+	// Start server in goroutine
+	go func() {
+		log.Printf("Server starting on port %s", cfg.Port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Server startup failed: %v", err)
+		}
+	}()
 
-	if err := writeGoFile(filepath.Join(projectName, "cmd", "server", "main.go"), content, sm...); err != nil {
-		ui.Warning(fmt.Sprintf("Error writing main.go: %v", err))
+	// Wait for interrupt signal to gracefully shutdown
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Println("Shutting down server...")
+
+	// Graceful shutdown with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Server forced to shutdown: %v", err)
+	}
+
+	log.Println("Server exited")
+}
+
+func connectToDatabase(cfg *config.Config) (*gorm.DB, error) {
+	dsn := cfg.GetDatabaseURL()
+
+{{if .SQLite}}	log.Printf("Opening SQLite database %s", dsn)
+{{else}}	log.Printf("Connecting to database at %s:%s/%s", cfg.Database.Host, cfg.Database.Port, cfg.Database.Name)
+{{end}}{{if .PasswordRequired}}
+	// Check if this is development mode without database
+	if cfg.Environment == "development" && cfg.Database.Password == "" {
+		log.Println("Warning: Development mode detected: No database password set")
+		log.Println("To connect to database, set environment variables:")
+		log.Println("   DB_HOST=localhost")
+		log.Println("   DB_PORT=<port>")
+		log.Println("   DB_USER=<user>")
+		log.Println("   DB_PASSWORD=your_password")
+		log.Println("   DB_NAME=your_database")
+		log.Println("Server will continue without database connection...")
+		return nil, fmt.Errorf("development mode: database not configured")
+	}
+{{end}}
+	// Retry connection up to 5 times
+	for i := 0; i < 5; i++ {
+		db, err := gorm.Open({{.DriverPackage}}.Open(dsn), &gorm.Config{})
+		if err != nil {
+			log.Printf("Attempt %d: Failed to open database connection: %v", i+1, err)
+			time.Sleep(time.Duration(i+1) * time.Second)
+			continue
+		}
+
+		// Get underlying sql.DB for connection pool configuration
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Printf("Attempt %d: Failed to get underlying SQL DB: %v", i+1, err)
+			time.Sleep(time.Duration(i+1) * time.Second)
+			continue
+		}
+
+		// Configure connection pool
+		sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+		sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+		sqlDB.SetConnMaxLifetime(cfg.Database.MaxLifetime)
+
+		// Test the connection
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err = sqlDB.PingContext(ctx)
+		cancel()
+
+		if err == nil {
+			return db, nil
+		}
+
+		log.Printf("Attempt %d: Database ping failed: %v", i+1, err)
+		sqlDBClose, _ := db.DB()
+		if sqlDBClose != nil {
+			sqlDBClose.Close()
+		}
+		time.Sleep(time.Duration(i+1) * time.Second)
+	}
+
+	return nil, fmt.Errorf("failed to connect to database after 5 attempts")
+}
+
+func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
+	status := HealthStatus{
+		Status:    "healthy",
+		Timestamp: time.Now(),
+		Services:  make(map[string]string),
+		Version:   Version,
+	}
+
+	// Check database
+	if err := checkDatabase(); err != nil {
+		status.Status = "degraded"
+		status.Services["database"] = fmt.Sprintf("error: %v", err)
+		// Don't fail the whole health check for database issues in development
+		log.Printf("Database health check failed: %v", err)
+	} else {
+		status.Services["database"] = "healthy"
+	}
+
+	// Always return 200 for basic health check - let readiness handle critical dependencies
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+func readinessHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if all dependencies are ready
+	if err := checkDatabase(); err != nil {
+		http.Error(w, fmt.Sprintf("Database not ready: %v", err), http.StatusServiceUnavailable)
 		return
 	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Ready"))
+}
+
+func livenessHandler(w http.ResponseWriter, r *http.Request) {
+	// Basic liveness check
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Alive"))
 }
 
-func createMongoDBMainGo(projectName, module string, sm ...*SafetyManager) {
-	content := fmt.Sprintf(`package main
+func checkDatabase() error {
+	if db == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get underlying sql DB: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	return sqlDB.PingContext(ctx)
+}
+
+func runAutoMigrations(database *gorm.DB) error {
+	if database == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+
+	// Auto-migrate domain entities using GORM
+	log.Println("Running GORM auto-migrations...")
+
+	// Create a slice of all domain entities to migrate
+	entities := []interface{}{
+		// Add domain entities here as they are created
+		// Example: &domain.User{}, &domain.Product{}
+	}
+
+	// Run auto-migration for all entities
+	for _, entity := range entities {
+		if err := database.AutoMigrate(entity); err != nil {
+			return fmt.Errorf("failed to auto-migrate entity %T: %w", entity, err)
+		}
+	}
+
+	// For now, just ensure the connection works
+	sqlDB, err := database.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get underlying SQL DB: %w", err)
+	}
+
+	if err := sqlDB.Ping(); err != nil {
+		return fmt.Errorf("database ping failed: %w", err)
+	}
+
+	log.Println("GORM auto-migrations completed successfully")
+	return nil
+}
+`
+
+// mongoDBMainGoTemplate is the main.go of a MongoDB project.
+const mongoDBMainGoTemplate = `package main
 
 import (
 	"context"
@@ -118,29 +337,209 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
-	"%s/pkg/config"
-	"%s/pkg/logger"
+	"{{.Module}}/pkg/config"
+	"{{.Module}}/pkg/logger"
 )
 
 type HealthStatus struct {
-	Status    string            `+"`"+`json:"status"`+"`"+`
-	Timestamp time.Time         `+"`"+`json:"timestamp"`+"`"+`
-	Services  map[string]string `+"`"+`json:"services"`+"`"+`
-	Version   string            `+"`"+`json:"version"`+"`"+"\n}\n\nvar (\n\t// Build information (set by build flags)\n\tVersion   = \"dev\"\n\tBuildTime = \"unknown\"\n\tmongoClient *mongo.Client\n)\n\nfunc main() {\n\t// Load configuration\n\tcfg := config.Load()\n\t\n\t// Initialize logger\n\tlogger.Init()\n\t\n\tlog.Printf(\"Starting application v%%s (built: %%s)\", Version, BuildTime)\n\tlog.Printf(\"Environment: %%s\", cfg.Environment)\n\t\n\t// Connect to MongoDB with retry\n\tvar err error\n\tmongoClient, err = connectToMongoDB(cfg)\n\tif err != nil {\n\t\tlog.Printf(\"Warning: MongoDB connection failed: %%v\", err)\n\t\tlog.Printf(\"Server will start in degraded mode. Check your database configuration.\")\n\t\tlog.Printf(\"Tip: Configure MongoDB environment variables in .env file\")\n\t\tmongoClient = nil\n\t} else {\n\t\tlog.Printf(\"MongoDB connected successfully\")\n\t}\n\t\n\t// Setup router\n\trouter := mux.NewRouter()\n\t\n\t// Health check endpoint with comprehensive checks\n\trouter.HandleFunc(\"/health\", healthCheckHandler).Methods(\"GET\")\n\trouter.HandleFunc(\"/health/ready\", readinessHandler).Methods(\"GET\")\n\trouter.HandleFunc(\"/health/live\", livenessHandler).Methods(\"GET\")\n\t\n\t// Setup HTTP server with timeouts\n\tserver := &http.Server{\n\t\tAddr:         \":\" + cfg.Port,\n\t\tHandler:      router,\n\t\tReadTimeout:  cfg.Server.ReadTimeout,\n\t\tWriteTimeout: cfg.Server.WriteTimeout,\n\t\tIdleTimeout:  cfg.Server.IdleTimeout,\n\t}\n\t\n\t// Start server in goroutine\n\tgo func() {\n\t\tlog.Printf(\"Server starting on port %%s\", cfg.Port)\n\t\tif err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {\n\t\t\tlog.Fatalf(\"Server startup failed: %%v\", err)\n\t\t}\n\t}()\n\t\n\t// Wait for interrupt signal to gracefully shutdown\n\tquit := make(chan os.Signal, 1)\n\tsignal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)\n\t<-quit\n\t\n\tlog.Println(\"Shutting down server...\")\n\t\n\t// Graceful shutdown with timeout\n\tctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)\n\tdefer cancel()\n\t\n\tif err := server.Shutdown(ctx); err != nil {\n\t\tlog.Printf(\"Server forced to shutdown: %%v\", err)\n\t}\n\t\n\t// Disconnect MongoDB\n\tif mongoClient != nil {\n\t\tif err := mongoClient.Disconnect(ctx); err != nil {\n\t\t\tlog.Printf(\"Error disconnecting from MongoDB: %%v\", err)\n\t\t}\n\t}\n\t\n\tlog.Println(\"Server exited\")\n}\n\nfunc connectToMongoDB(cfg *config.Config) (*mongo.Client, error) {\n\tdsn := cfg.GetDatabaseURL()\n\t\n\tlog.Printf(\"Connecting to MongoDB at %%s\", cfg.Database.Host)\n\t\n\t// Check if this is development mode without database\n\tif cfg.Environment == \"development\" && cfg.Database.Password == \"\" {\n\t\tlog.Println(\"Warning: Development mode detected: No database password set\")\n\t\tlog.Println(\"To connect to MongoDB, set environment variables:\")\n\t\tlog.Println(\"   DB_HOST=localhost\")\n\t\tlog.Println(\"   DB_PORT=27017\")\n\t\tlog.Println(\"   DB_USER=<user>\")\n\t\tlog.Println(\"   DB_PASSWORD=your_password\")\n\t\tlog.Println(\"   DB_NAME=your_database\")\n\t\tlog.Println(\"Server will continue without database connection...\")\n\t\treturn nil, fmt.Errorf(\"development mode: database not configured\")\n\t}\n\t\n\t// Create MongoDB client options\n\tclientOptions := options.Client().ApplyURI(dsn)\n\t\n\t// Retry connection up to 5 times\n\tfor i := 0; i < 5; i++ {\n\t\tctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)\n\t\tclient, err := mongo.Connect(ctx, clientOptions)\n\t\t\n\t\tif err != nil {\n\t\t\tcancel()\n\t\t\tlog.Printf(\"Attempt %%d: Failed to connect to MongoDB: %%v\", i+1, err)\n\t\t\ttime.Sleep(time.Duration(i+1) * time.Second)\n\t\t\tcontinue\n\t\t}\n\t\t\n\t\t// Ping the database\n\t\terr = client.Ping(ctx, readpref.Primary())\n\t\tcancel()\n\t\t\n\t\tif err == nil {\n\t\t\treturn client, nil\n\t\t}\n\t\t\n\t\tlog.Printf(\"Attempt %%d: MongoDB ping failed: %%v\", i+1, err)\n\t\tclient.Disconnect(context.Background())\n\t\ttime.Sleep(time.Duration(i+1) * time.Second)\n\t}\n\t\n\treturn nil, fmt.Errorf(\"failed to connect to MongoDB after 5 attempts\")\n}\n\nfunc healthCheckHandler(w http.ResponseWriter, r *http.Request) {\n\tstatus := HealthStatus{\n\t\tStatus:    \"healthy\",\n\t\tTimestamp: time.Now(),\n\t\tServices:  make(map[string]string),\n\t\tVersion:   Version,\n\t}\n\t\n\t// Check database\n\tif err := checkMongoDB(); err != nil {\n\t\tstatus.Status = \"degraded\"\n\t\tstatus.Services[\"database\"] = fmt.Sprintf(\"error: %%v\", err)\n\t\tlog.Printf(\"MongoDB health check failed: %%v\", err)\n\t} else {\n\t\tstatus.Services[\"database\"] = \"healthy\"\n\t}\n\t\n\tw.Header().Set(\"Content-Type\", \"application/json\")\n\tjson.NewEncoder(w).Encode(status)\n}\n\nfunc readinessHandler(w http.ResponseWriter, r *http.Request) {\n\t// Check if all dependencies are ready\n\tif err := checkMongoDB(); err != nil {\n\t\thttp.Error(w, fmt.Sprintf(\"MongoDB not ready: %%v\", err), http.StatusServiceUnavailable)\n\t\treturn\n\t}\n\t\n\tw.WriteHeader(http.StatusOK)\n\tw.Write([]byte(\"Ready\"))\n}\n\nfunc livenessHandler(w http.ResponseWriter, r *http.Request) {\n\t// Basic liveness check\n\tw.WriteHeader(http.StatusOK)\n\tw.Write([]byte(\"Alive\"))\n}\n\nfunc checkMongoDB() error {\n\tif mongoClient == nil {\n\t\treturn fmt.Errorf(\"MongoDB client is nil\")\n\t}\n\t\n\tctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)\n\tdefer cancel()\n\t\n\treturn mongoClient.Ping(ctx, readpref.Primary())\n}\n", module, module)
+	Status    string            ` + "`" + `json:"status"` + "`" + `
+	Timestamp time.Time         ` + "`" + `json:"timestamp"` + "`" + `
+	Services  map[string]string ` + "`" + `json:"services"` + "`" + `
+	Version   string            ` + "`" + `json:"version"` + "`" + `
+}
 
-	if err := writeGoFile(filepath.Join(projectName, "cmd", "server", "main.go"), content, sm...); err != nil {
-		ui.Warning(fmt.Sprintf("Error writing main.go: %v", err))
+var (
+	// Build information (set by build flags)
+	Version     = "dev"
+	BuildTime   = "unknown"
+	mongoClient *mongo.Client
+)
+
+func main() {
+	// Load configuration
+	cfg := config.Load()
+
+	// Initialize logger
+	logger.Init()
+
+	log.Printf("Starting application v%s (built: %s)", Version, BuildTime)
+	log.Printf("Environment: %s", cfg.Environment)
+
+	// Connect to MongoDB with retry
+	var err error
+	mongoClient, err = connectToMongoDB(cfg)
+	if err != nil {
+		log.Printf("Warning: MongoDB connection failed: %v", err)
+		log.Printf("Server will start in degraded mode. Check your database configuration.")
+		log.Printf("Tip: Configure MongoDB environment variables in .env file")
+		mongoClient = nil
+	} else {
+		log.Printf("MongoDB connected successfully")
+	}
+
+	// Setup router
+	router := mux.NewRouter()
+
+	// Health check endpoint with comprehensive checks
+	router.HandleFunc("/health", healthCheckHandler).Methods("GET")
+	router.HandleFunc("/health/ready", readinessHandler).Methods("GET")
+	router.HandleFunc("/health/live", livenessHandler).Methods("GET")
+
+	// Setup HTTP server with timeouts
+	server := &http.Server{
+		Addr:         ":" + cfg.Port,
+		Handler:      router,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+
+	// Start server in goroutine
+	go func() {
+		log.Printf("Server starting on port %s", cfg.Port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Server startup failed: %v", err)
+		}
+	}()
+
+	// Wait for interrupt signal to gracefully shutdown
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Println("Shutting down server...")
+
+	// Graceful shutdown with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Server forced to shutdown: %v", err)
+	}
+
+	// Disconnect MongoDB
+	if mongoClient != nil {
+		if err := mongoClient.Disconnect(ctx); err != nil {
+			log.Printf("Error disconnecting from MongoDB: %v", err)
+		}
+	}
+
+	log.Println("Server exited")
+}
+
+func connectToMongoDB(cfg *config.Config) (*mongo.Client, error) {
+	dsn := cfg.GetDatabaseURL()
+
+	log.Printf("Connecting to MongoDB at %s", cfg.Database.Host)
+
+	// Check if this is development mode without database
+	if cfg.Environment == "development" && cfg.Database.Password == "" {
+		log.Println("Warning: Development mode detected: No database password set")
+		log.Println("To connect to MongoDB, set environment variables:")
+		log.Println("   DB_HOST=localhost")
+		log.Println("   DB_PORT=27017")
+		log.Println("   DB_USER=<user>")
+		log.Println("   DB_PASSWORD=your_password")
+		log.Println("   DB_NAME=your_database")
+		log.Println("Server will continue without database connection...")
+		return nil, fmt.Errorf("development mode: database not configured")
+	}
+
+	// Create MongoDB client options
+	clientOptions := options.Client().ApplyURI(dsn)
+
+	// Size the connection pool
+	clientOptions.SetMaxPoolSize(uint64(cfg.Database.MaxOpenConns))
+	clientOptions.SetMaxConnIdleTime(cfg.Database.MaxLifetime)
+
+	// Retry connection up to 5 times
+	for i := 0; i < 5; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		client, err := mongo.Connect(ctx, clientOptions)
+
+		if err != nil {
+			cancel()
+			log.Printf("Attempt %d: Failed to connect to MongoDB: %v", i+1, err)
+			time.Sleep(time.Duration(i+1) * time.Second)
+			continue
+		}
+
+		// Ping the database
+		err = client.Ping(ctx, readpref.Primary())
+		cancel()
+
+		if err == nil {
+			return client, nil
+		}
+
+		log.Printf("Attempt %d: MongoDB ping failed: %v", i+1, err)
+		client.Disconnect(context.Background())
+		time.Sleep(time.Duration(i+1) * time.Second)
+	}
+
+	return nil, fmt.Errorf("failed to connect to MongoDB after 5 attempts")
+}
+
+func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
+	status := HealthStatus{
+		Status:    "healthy",
+		Timestamp: time.Now(),
+		Services:  make(map[string]string),
+		Version:   Version,
+	}
+
+	// Check database
+	if err := checkMongoDB(); err != nil {
+		status.Status = "degraded"
+		status.Services["database"] = fmt.Sprintf("error: %v", err)
+		log.Printf("MongoDB health check failed: %v", err)
+	} else {
+		status.Services["database"] = "healthy"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+func readinessHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if all dependencies are ready
+	if err := checkMongoDB(); err != nil {
+		http.Error(w, fmt.Sprintf("MongoDB not ready: %v", err), http.StatusServiceUnavailable)
 		return
 	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Ready"))
+}
+
+func livenessHandler(w http.ResponseWriter, r *http.Request) {
+	// Basic liveness check
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Alive"))
+}
+
+func checkMongoDB() error {
+	if mongoClient == nil {
+		return fmt.Errorf("MongoDB client is nil")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	return mongoClient.Ping(ctx, readpref.Primary())
 }
+`
 
-func createDynamoDBMainGo(projectName, module string, sm ...*SafetyManager) {
-	// Placeholder for DynamoDB implementation
-	// For now, create a simple main.go that warns about missing implementation
-	content := fmt.Sprintf(`package main
+// dynamoDBMainGoTemplate is the main.go of a DynamoDB project. The client
+// reaches the endpoint of Config.GetDatabaseURL (DynamoDB Local by default)
+// and is kept when the first ListTables fails, so the server starts degraded
+// and the health checks report the outage.
+const dynamoDBMainGoTemplate = `package main
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -148,33 +547,60 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/gorilla/mux"
-	"%s/pkg/config"
-	"%s/pkg/logger"
+	"{{.Module}}/pkg/config"
+	"{{.Module}}/pkg/logger"
 )
 
 type HealthStatus struct {
-	Status    string            `+"`"+`json:"status"`+"`"+`
-	Timestamp time.Time         `+"`"+`json:"timestamp"`+"`"+`
-	Version   string            `+"`"+`json:"version"`+"`"+`
+	Status    string            ` + "`" + `json:"status"` + "`" + `
+	Timestamp time.Time         ` + "`" + `json:"timestamp"` + "`" + `
+	Services  map[string]string ` + "`" + `json:"services"` + "`" + `
+	Version   string            ` + "`" + `json:"version"` + "`" + `
 }
 
 var (
-	Version   = "dev"
-	BuildTime = "unknown"
+	// Build information (set by build flags)
+	Version      = "dev"
+	BuildTime    = "unknown"
+	dynamoClient *dynamodb.Client
 )
 
 func main() {
+	// Load configuration
 	cfg := config.Load()
+
+	// Initialize logger
 	logger.Init()
-	
-	log.Printf("Starting application v%%s (built: %%s)", Version, BuildTime)
-	log.Println("Warning: DynamoDB integration is not yet fully implemented")
-	log.Println("Please implement DynamoDB client initialization in main.go")
-	
+
+	log.Printf("Starting application v%s (built: %s)", Version, BuildTime)
+	log.Printf("Environment: %s", cfg.Environment)
+
+	// Connect to DynamoDB with retry
+	var err error
+	dynamoClient, err = connectToDynamoDB(cfg)
+	if err != nil {
+		log.Printf("Warning: DynamoDB connection failed: %v", err)
+		log.Printf("Server will start in degraded mode. Check your database configuration.")
+		log.Printf("Tip: Configure DynamoDB environment variables in .env file")
+	} else {
+		log.Printf("DynamoDB connected successfully")
+	}
+
+	// Setup router
 	router := mux.NewRouter()
+
+	// Health check endpoint with comprehensive checks
 	router.HandleFunc("/health", healthCheckHandler).Methods("GET")
-	
+	router.HandleFunc("/health/ready", readinessHandler).Methods("GET")
+	router.HandleFunc("/health/live", livenessHandler).Methods("GET")
+
+	// Setup HTTP server with timeouts
 	server := &http.Server{
 		Addr:         ":" + cfg.Port,
 		Handler:      router,
@@ -182,77 +608,202 @@ func main() {
 		WriteTimeout: cfg.Server.WriteTimeout,
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
-	
+
+	// Start server in goroutine
 	go func() {
-		log.Printf("Server starting on port %%s", cfg.Port)
+		log.Printf("Server starting on port %s", cfg.Port)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server startup failed: %%v", err)
+			log.Fatalf("Server startup failed: %v", err)
 		}
 	}()
-	
+
+	// Wait for interrupt signal to gracefully shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
+
+	log.Println("Shutting down server...")
+
+	// Graceful shutdown with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Server forced to shutdown: %v", err)
+	}
+
 	log.Println("Server exited")
 }
 
+func connectToDynamoDB(cfg *config.Config) (*dynamodb.Client, error) {
+	endpoint := cfg.GetDatabaseURL()
+
+	log.Printf("Connecting to DynamoDB at %s (region %s)", endpoint, cfg.Database.Region)
+
+	// Size the connection pool of the SDK's HTTP client
+	httpClient := awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+		tr.MaxConnsPerHost = cfg.Database.MaxOpenConns
+		tr.MaxIdleConnsPerHost = cfg.Database.MaxIdleConns
+		tr.IdleConnTimeout = cfg.Database.MaxLifetime
+	})
+
+	options := []func(*awsconfig.LoadOptions) error{
+		awsconfig.WithRegion(cfg.Database.Region),
+		awsconfig.WithHTTPClient(httpClient),
+	}
+	// DB_USER and DB_PASSWORD are static access keys, which DynamoDB Local
+	// accepts; without a password the default AWS credential chain applies.
+	if cfg.Database.User != "" && cfg.Database.Password != "" {
+		options = append(options, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(cfg.Database.User, cfg.Database.Password, "")))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, options...)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	client := dynamodb.NewFromConfig(awsCfg, func(o *dynamodb.Options) {
+		o.BaseEndpoint = aws.String(endpoint)
+	})
+
+	// Retry the first request up to 5 times
+	for i := 0; i < 5; i++ {
+		if err = pingDynamoDB(client); err == nil {
+			return client, nil
+		}
+		log.Printf("Attempt %d: DynamoDB ping failed: %v", i+1, err)
+		time.Sleep(time.Duration(i+1) * time.Second)
+	}
+
+	// The client stays usable: requests fail until DynamoDB is reachable
+	return client, fmt.Errorf("failed to reach DynamoDB after 5 attempts: %w", err)
+}
+
 func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	status := HealthStatus{
 		Status:    "healthy",
 		Timestamp: time.Now(),
+		Services:  make(map[string]string),
 		Version:   Version,
 	}
+
+	// Check database
+	if err := checkDynamoDB(); err != nil {
+		status.Status = "degraded"
+		status.Services["database"] = fmt.Sprintf("error: %v", err)
+		log.Printf("DynamoDB health check failed: %v", err)
+	} else {
+		status.Services["database"] = "healthy"
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(status)
 }
-`, module, module)
 
-	if err := writeGoFile(filepath.Join(projectName, "cmd", "server", "main.go"), content, sm...); err != nil {
-		ui.Warning(fmt.Sprintf("Error writing main.go: %v", err))
+func readinessHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if all dependencies are ready
+	if err := checkDynamoDB(); err != nil {
+		http.Error(w, fmt.Sprintf("DynamoDB not ready: %v", err), http.StatusServiceUnavailable)
 		return
 	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Ready"))
 }
 
-func createElasticsearchMainGo(projectName, module string, sm ...*SafetyManager) {
-	// Placeholder for Elasticsearch implementation
-	content := fmt.Sprintf(`package main
+func livenessHandler(w http.ResponseWriter, r *http.Request) {
+	// Basic liveness check
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Alive"))
+}
+
+func checkDynamoDB() error {
+	if dynamoClient == nil {
+		return fmt.Errorf("DynamoDB client is nil")
+	}
+	return pingDynamoDB(dynamoClient)
+}
+
+// pingDynamoDB lists at most one table, the cheapest authenticated request.
+func pingDynamoDB(client *dynamodb.Client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	_, err := client.ListTables(ctx, &dynamodb.ListTablesInput{Limit: aws.Int32(1)})
+	return err
+}
+`
+
+// elasticsearchMainGoTemplate is the main.go of an Elasticsearch project.
+// Like the DynamoDB one, it keeps the client when the cluster does not answer
+// the first pings.
+const elasticsearchMainGoTemplate = `package main
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/gorilla/mux"
-	"%s/pkg/config"
-	"%s/pkg/logger"
+	"{{.Module}}/pkg/config"
+	"{{.Module}}/pkg/logger"
 )
 
 type HealthStatus struct {
-	Status    string            `+"`"+`json:"status"`+"`"+`
-	Timestamp time.Time         `+"`"+`json:"timestamp"`+"`"+`
-	Version   string            `+"`"+`json:"version"`+"`"+`
+	Status    string            ` + "`" + `json:"status"` + "`" + `
+	Timestamp time.Time         ` + "`" + `json:"timestamp"` + "`" + `
+	Services  map[string]string ` + "`" + `json:"services"` + "`" + `
+	Version   string            ` + "`" + `json:"version"` + "`" + `
 }
 
 var (
+	// Build information (set by build flags)
 	Version   = "dev"
 	BuildTime = "unknown"
+	esClient  *elasticsearch.Client
 )
 
 func main() {
+	// Load configuration
 	cfg := config.Load()
+
+	// Initialize logger
 	logger.Init()
-	
-	log.Printf("Starting application v%%s (built: %%s)", Version, BuildTime)
-	log.Println("Warning: Elasticsearch integration is not yet fully implemented")
-	log.Println("Please implement Elasticsearch client initialization in main.go")
-	
+
+	log.Printf("Starting application v%s (built: %s)", Version, BuildTime)
+	log.Printf("Environment: %s", cfg.Environment)
+
+	// Connect to Elasticsearch with retry
+	var err error
+	esClient, err = connectToElasticsearch(cfg)
+	if err != nil {
+		log.Printf("Warning: Elasticsearch connection failed: %v", err)
+		log.Printf("Server will start in degraded mode. Check your database configuration.")
+		log.Printf("Tip: Configure Elasticsearch environment variables in .env file")
+	} else {
+		log.Printf("Elasticsearch connected successfully")
+	}
+
+	// Setup router
 	router := mux.NewRouter()
+
+	// Health check endpoint with comprehensive checks
 	router.HandleFunc("/health", healthCheckHandler).Methods("GET")
-	
+	router.HandleFunc("/health/ready", readinessHandler).Methods("GET")
+	router.HandleFunc("/health/live", livenessHandler).Methods("GET")
+
+	// Setup HTTP server with timeouts
 	server := &http.Server{
 		Addr:         ":" + cfg.Port,
 		Handler:      router,
@@ -260,33 +811,131 @@ func main() {
 		WriteTimeout: cfg.Server.WriteTimeout,
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
-	
+
+	// Start server in goroutine
 	go func() {
-		log.Printf("Server starting on port %%s", cfg.Port)
+		log.Printf("Server starting on port %s", cfg.Port)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server startup failed: %%v", err)
+			log.Fatalf("Server startup failed: %v", err)
 		}
 	}()
-	
+
+	// Wait for interrupt signal to gracefully shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
+
+	log.Println("Shutting down server...")
+
+	// Graceful shutdown with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Server forced to shutdown: %v", err)
+	}
+
 	log.Println("Server exited")
 }
 
+func connectToElasticsearch(cfg *config.Config) (*elasticsearch.Client, error) {
+	address := cfg.GetDatabaseURL()
+
+	log.Printf("Connecting to Elasticsearch at %s", address)
+
+	esCfg := elasticsearch.Config{
+		Addresses: []string{address},
+		// Size the connection pool of the client's transport
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			DialContext:         (&net.Dialer{Timeout: 5 * time.Second}).DialContext,
+			MaxConnsPerHost:     cfg.Database.MaxOpenConns,
+			MaxIdleConnsPerHost: cfg.Database.MaxIdleConns,
+			IdleConnTimeout:     cfg.Database.MaxLifetime,
+		},
+	}
+	// Clusters without security (the docker-compose one) take no credentials
+	if cfg.Database.Password != "" {
+		esCfg.Username = cfg.Database.User
+		esCfg.Password = cfg.Database.Password
+	}
+
+	client, err := elasticsearch.NewClient(esCfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Elasticsearch configuration: %w", err)
+	}
+
+	// Retry the ping up to 5 times
+	for i := 0; i < 5; i++ {
+		if err = pingElasticsearch(client); err == nil {
+			return client, nil
+		}
+		log.Printf("Attempt %d: Elasticsearch ping failed: %v", i+1, err)
+		time.Sleep(time.Duration(i+1) * time.Second)
+	}
+
+	// The client stays usable: requests fail until the cluster is reachable
+	return client, fmt.Errorf("failed to reach Elasticsearch after 5 attempts: %w", err)
+}
+
 func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	status := HealthStatus{
 		Status:    "healthy",
 		Timestamp: time.Now(),
+		Services:  make(map[string]string),
 		Version:   Version,
 	}
+
+	// Check database
+	if err := checkElasticsearch(); err != nil {
+		status.Status = "degraded"
+		status.Services["database"] = fmt.Sprintf("error: %v", err)
+		log.Printf("Elasticsearch health check failed: %v", err)
+	} else {
+		status.Services["database"] = "healthy"
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(status)
 }
-`, module, module)
 
-	if err := writeGoFile(filepath.Join(projectName, "cmd", "server", "main.go"), content, sm...); err != nil {
-		ui.Warning(fmt.Sprintf("Error writing main.go: %v", err))
+func readinessHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if all dependencies are ready
+	if err := checkElasticsearch(); err != nil {
+		http.Error(w, fmt.Sprintf("Elasticsearch not ready: %v", err), http.StatusServiceUnavailable)
 		return
 	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Ready"))
+}
+
+func livenessHandler(w http.ResponseWriter, r *http.Request) {
+	// Basic liveness check
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Alive"))
+}
+
+func checkElasticsearch() error {
+	if esClient == nil {
+		return fmt.Errorf("Elasticsearch client is nil")
+	}
+	return pingElasticsearch(esClient)
+}
+
+// pingElasticsearch sends a HEAD / to the cluster.
+func pingElasticsearch(client *elasticsearch.Client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	res, err := client.Ping(client.Ping.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("ping returned %s", res.Status())
+	}
+	return nil
 }
+`
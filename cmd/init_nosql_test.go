@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateMainGo_NoSQLStores(t *testing.T) {
+	defer ensureTestUI(t)()
+	sm := NewSafetyManager(false, true, false)
+
+	dir := t.TempDir()
+	createMainGo(dir, "github.com/test/search", DBElasticsearch, "", false, sm)
+	main := readParsedGo(t, filepath.Join(dir, "cmd", "server", "main.go"))
+	assert.Contains(t, main, "esClient  *elasticsearch.Client")
+	assert.Contains(t, main, "client, err := elasticsearch.NewClient(esCfg)")
+	assert.Contains(t, main, "MaxConnsPerHost:     cfg.Database.MaxOpenConns,")
+	assert.Contains(t, main, "res, err := client.Ping(client.Ping.WithContext(ctx))")
+	assert.Contains(t, main, `router.HandleFunc("/health/ready", readinessHandler)`)
+	assert.NotContains(t, main, "not yet fully implemented")
+
+	dir = t.TempDir()
+	createMainGo(dir, "github.com/test/orders", DBDynamoDB, "", false, sm)
+	main = readParsedGo(t, filepath.Join(dir, "cmd", "server", "main.go"))
+	assert.Contains(t, main, "dynamoClient *dynamodb.Client")
+	assert.Contains(t, main, "awsconfig.WithRegion(cfg.Database.Region),")
+	assert.Contains(t, main, "o.BaseEndpoint = aws.String(endpoint)")
+	assert.Contains(t, main, "client.ListTables(ctx, &dynamodb.ListTablesInput{Limit: aws.Int32(1)})")
+	assert.Contains(t, main, `router.HandleFunc("/health/live", livenessHandler)`)
+
+	dir = t.TempDir()
+	createMainGo(dir, "github.com/test/catalog", DBMongoDB, "", false, sm)
+	main = readParsedGo(t, filepath.Join(dir, "cmd", "server", "main.go"))
+	assert.Contains(t, main, "clientOptions.SetMaxPoolSize(uint64(cfg.Database.MaxOpenConns))")
+	assert.Contains(t, main, "return mongoClient.Ping(ctx, readpref.Primary())")
+}
+
+func TestCreateConfig_NoSQLEndpoints(t *testing.T) {
+	defer ensureTestUI(t)()
+	sm := NewSafetyManager(false, true, false)
+
+	dir := filepath.Join(t.TempDir(), "orders")
+	createConfig(dir, "", DBDynamoDB, sm)
+	config := readParsedGo(t, filepath.Join(dir, "pkg", "config", "config.go"))
+	assert.Contains(t, config, "Region       string")
+	assert.Contains(t, config, `Region:       getEnv("AWS_REGION", "us-east-1"),`)
+	assert.Contains(t, config, `return fmt.Sprintf("%s://%s:%s", scheme, c.Database.Host, c.Database.Port)`)
+	assert.NotContains(t, config, `".db"`)
+
+	createEnvFiles(dir, DBDynamoDB, sm)
+	env, err := os.ReadFile(filepath.Join(dir, ".env"))
+	require.NoError(t, err)
+	assert.Contains(t, string(env), "DB_PASSWORD=local")
+	assert.Contains(t, string(env), "AWS_REGION=us-east-1")
+
+	dir = filepath.Join(t.TempDir(), "search")
+	createConfig(dir, "", DBElasticsearch, sm)
+	config = readParsedGo(t, filepath.Join(dir, "pkg", "config", "config.go"))
+	assert.Contains(t, config, `scheme = "https"`)
+	assert.NotContains(t, config, "Region")
+}
+
+func TestContainerDBArg(t *testing.T) {
+	assert.Equal(t, "db", containerDBArg("var (\n\tdb        *gorm.DB\n)\n"))
+	assert.Equal(t, "mongoClient.Database(cfg.Database.Name)", containerDBArg("var (\n\tmongoClient *mongo.Client\n)\n"))
+	assert.Equal(t, "esClient", containerDBArg("var (\n\tesClient  *elasticsearch.Client\n)\n"))
+	assert.Equal(t, "dynamoClient", containerDBArg("var (\n\tdynamoClient *dynamodb.Client\n)\n"))
+
+	main := "package main\n\nfunc main() {\n\trouter := mux.NewRouter()\n}\n\nvar esClient *elasticsearch.Client\n"
+	assert.Contains(t, ensureContainerScaffold(main), "container := di.NewContainer(esClient)")
+
+	goType, importPath := dbHandleType(DBDynamoDB)
+	assert.Equal(t, "*dynamodb.Client", goType)
+	assert.Equal(t, "github.com/aws/aws-sdk-go-v2/service/dynamodb", importPath)
+	goType, _ = dbHandleType(DBElasticsearch)
+	assert.Equal(t, "*elasticsearch.Client", goType)
+}
@@ -3,6 +3,8 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"go/ast"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -11,7 +13,7 @@ import (
 	"golang.org/x/text/language"
 )
 
-func createGoMod(projectName, module, database string, auth bool, sm ...*SafetyManager) {
+func createGoMod(projectName, module, database, sqliteDriver, authMode string, tracing bool, sm ...*SafetyManager) {
 	var dependencies string
 
 	// Base dependencies (common to all)
@@ -23,39 +25,56 @@ func createGoMod(projectName, module, database string, auth bool, sm ...*SafetyM
 		baseDeps += `
 	gorm.io/gorm v1.25.5
 	gorm.io/driver/postgres v1.5.4`
-	case DBMySQL:
+	case DBMySQL, DBMariaDB, DBTiDB:
 		baseDeps += `
 	gorm.io/gorm v1.25.5
 	gorm.io/driver/mysql v1.5.2`
 	case DBSQLite:
 		baseDeps += `
 	gorm.io/gorm v1.25.5
-	gorm.io/driver/sqlite v1.5.4`
+	` + sqliteDriverRequire(sqliteDriver)
 	case DBSQLServer:
 		baseDeps += `
 	gorm.io/gorm v1.25.5
 	gorm.io/driver/sqlserver v1.5.2`
+	case DBOracle:
+		// go mod tidy raises GORM to the version gorm-oracle requires.
+		baseDeps += `
+	gorm.io/gorm v1.25.5
+	` + oracleDriverModule + ` ` + oracleDriverVersion
 	case DBMongoDB:
 		baseDeps += `
 	go.mongodb.org/mongo-driver v1.12.1`
 	case DBDynamoDB:
 		baseDeps += `
-	github.com/aws/aws-sdk-go-v2 v1.21.0
-	github.com/aws/aws-sdk-go-v2/service/dynamodb v1.21.5
-	github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue v1.10.39`
+	github.com/aws/aws-sdk-go-v2 v1.36.3
+	github.com/aws/aws-sdk-go-v2/config v1.29.14
+	github.com/aws/aws-sdk-go-v2/credentials v1.17.67
+	github.com/aws/aws-sdk-go-v2/service/dynamodb v1.43.1
+	github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue v1.18.12`
 	case DBElasticsearch:
 		baseDeps += `
 	github.com/elastic/go-elasticsearch/v8 v8.10.1`
 	default: // sqlite as the safe fallback (matches createMainGo/databaseURLBody)
 		baseDeps += `
 	gorm.io/gorm v1.25.5
-	gorm.io/driver/sqlite v1.5.4`
+	` + sqliteDriverRequire(sqliteDriver)
 	}
 
-	// Add JWT dependency if auth is enabled
-	if auth {
+	// Add the dependency of the auth mode: jwt signs tokens with golang-jwt,
+	// oidc verifies them with go-oidc and api-key only needs GORM.
+	switch authMode {
+	case AuthModeJWT:
 		baseDeps += `
 	github.com/golang-jwt/jwt/v4 v4.5.2`
+	case AuthModeOIDC:
+		baseDeps += `
+	github.com/coreos/go-oidc/v3 v3.12.0`
+	}
+
+	// Add OpenTelemetry SDK and database instrumentation if tracing is enabled
+	if tracing {
+		baseDeps += tracingGoModDeps(database)
 	}
 
 	dependencies = fmt.Sprintf(`require (
@@ -73,17 +92,32 @@ go 1.21
 		ui.Warning(fmt.Sprintf("Error writing go.mod: %v", err))
 		return
 	}
-} // downloadDependencies downloads Go module dependencies for the project
+}
+
+// sqliteDriverRequire returns the go.mod requirement of the SQLite driver.
+// modernc builds without cgo; any other value selects gorm.io/driver/sqlite,
+// which wraps the cgo-based mattn/go-sqlite3.
+func sqliteDriverRequire(sqliteDriver string) string {
+	if sqliteDriver == SQLiteDriverModernc {
+		return "github.com/glebarez/sqlite v1.11.0"
+	}
+	return "gorm.io/driver/sqlite v1.5.4"
+}
 
+// downloadDependencies downloads Go module dependencies for the project
 func downloadDependencies(projectName string) error {
 	// First run go mod tidy to resolve dependencies and create go.sum
+	stopProfile := profileStep("go mod tidy")
 	cmd := exec.Command("go", "mod", "tidy")
 	cmd.Dir = projectName
-	if err := cmd.Run(); err != nil {
+	err := cmd.Run()
+	stopProfile()
+	if err != nil {
 		return fmt.Errorf("go mod tidy failed: %w", err)
 	}
 
 	// Then download the dependencies
+	defer profileStep("go mod download")()
 	cmd = exec.Command("go", "mod", "download")
 	cmd.Dir = projectName
 	if err := cmd.Run(); err != nil {
@@ -137,12 +171,57 @@ Thumbs.db
 /tmp/
 /dist/
 `
-	if err := writeFile(filepath.Join(projectName, ".gitignore"), content, sm...); err != nil {
+	path := filepath.Join(projectName, ".gitignore")
+
+	// When merging into an existing project, append the entries it is missing
+	// instead of keeping or replacing the user's file.
+	if len(sm) > 0 && sm[0] != nil && sm[0].Merge {
+		if existing, err := os.ReadFile(path); err == nil {
+			merged, changed := mergeGitignore(string(existing), content)
+			if !changed {
+				sm[0].keepExisting(path, len(existing))
+				return
+			}
+			if err := sm[0].WriteMergedFile(path, merged); err != nil {
+				ui.Warning(fmt.Sprintf("Error writing .gitignore: %v", err))
+			}
+			return
+		}
+	}
+
+	if err := writeFile(path, content, sm...); err != nil {
 		ui.Warning(fmt.Sprintf("Error writing .gitignore: %v", err))
 		return
 	}
 }
 
+// mergeGitignore appends the patterns from generated that are not already in
+// existing. It reports whether anything was added.
+func mergeGitignore(existing, generated string) (string, bool) {
+	present := make(map[string]bool)
+	for _, line := range strings.Split(existing, "\n") {
+		present[strings.TrimSpace(line)] = true
+	}
+
+	var missing []string
+	for _, line := range strings.Split(generated, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || present[line] {
+			continue
+		}
+		present[line] = true
+		missing = append(missing, line)
+	}
+	if len(missing) == 0 {
+		return existing, false
+	}
+
+	if existing != "" && !strings.HasSuffix(existing, "\n") {
+		existing += "\n"
+	}
+	return existing + "\n# Added by Goca\n" + strings.Join(missing, "\n") + "\n", true
+}
+
 // initializeGitRepository initializes a Git repository in the project directory.
 func initializeGitRepository(projectName string) error {
 	// Check if git is available
@@ -183,9 +262,10 @@ func createReadme(projectName, module, database string, sm ...*SafetyManager) {
 	dbDisplay := getDatabaseDisplayName(database)
 	dbSection := getReadmeDatabaseSection(database, projectName)
 	dbTroubleshooting := getReadmeDatabaseTroubleshooting(database, projectName)
+	branding := loadBranding(projectName)
 	content := fmt.Sprintf(`# %s
 
-Generated with Goca - Go Clean Architecture Code Generator
+%s%s
 
 ## Architecture
 
@@ -307,11 +387,9 @@ Application runs but cannot connect to database.
 
 ## Additional Resources
 
-- [Goca Documentation](https://github.com/sazardev/goca)
-- [Clean Architecture Principles](https://blog.cleancoder.com/uncle-bob/2012/08/13/the-clean-architecture.html)
-- [Complete Tutorial](https://github.com/sazardev/goca/wiki/Complete-Tutorial)
+%s
 
-## Contributing
+%s## Contributing
 
 This project was generated with Goca. To contribute:
 
@@ -322,8 +400,9 @@ This project was generated with Goca. To contribute:
 
 ---
 
-Generated with [Goca](https://github.com/sazardev/goca)
-`, cases.Title(language.English).String(projectName), dbDisplay, dbSection, projectName, projectName, dbTroubleshooting, dbDisplay)
+%s
+`, cases.Title(language.English).String(projectName), readmeBadges(branding), readmeOrgLine(branding), dbDisplay, dbSection, defaultDatabaseName(database, projectName), projectName, dbTroubleshooting, dbDisplay,
+		readmeResources(branding), readmeSupportSection(branding), readmeFooter(branding))
 
 	if err := writeFile(filepath.Join(projectName, "README.md"), content, sm...); err != nil {
 		ui.Warning(fmt.Sprintf("Error writing README.md: %v", err))
@@ -338,12 +417,18 @@ func getDatabaseDisplayName(database string) string {
 		return "PostgreSQL"
 	case DBMySQL:
 		return "MySQL"
+	case DBMariaDB:
+		return "MariaDB"
+	case DBTiDB:
+		return "TiDB"
 	case DBMongoDB:
 		return "MongoDB"
 	case DBSQLite:
 		return "SQLite"
 	case DBSQLServer:
 		return "SQL Server"
+	case DBOracle:
+		return "Oracle"
 	case DBDynamoDB:
 		return "DynamoDB"
 	case DBElasticsearch:
@@ -358,16 +443,27 @@ func getReadmeDatabaseSection(database, projectName string) string {
 	fence := func(s string) string { return "```bash\n" + s + "\n```" }
 	switch database {
 	case DBSQLite:
-		return "SQLite is file-based; no server is required. The database file is created automatically on first run."
+		return "SQLite is file-based; no server is required. The database file (`DB_PATH` in `.env`) is created automatically on first run."
 	case DBMySQL:
 		return "#### Option A: Using Docker (Recommended)\n" +
 			fence(fmt.Sprintf("# Run MySQL\ndocker run --name mysql-dev \\\n  -e MYSQL_ROOT_PASSWORD=password \\\n  -e MYSQL_DATABASE=%s \\\n  -p 3306:3306 \\\n  -d mysql:8.0\n\n# Or using docker-compose\ndocker-compose up -d", projectName))
+	case DBMariaDB:
+		return "#### Option A: Using Docker (Recommended)\n" +
+			fence(fmt.Sprintf("# Run MariaDB\ndocker run --name mariadb-dev \\\n  -e MARIADB_ROOT_PASSWORD=password \\\n  -e MARIADB_DATABASE=%s \\\n  -p 3306:3306 \\\n  -d %s\n\n# Or using docker-compose\ndocker-compose up -d", projectName, mariadbImage))
+	case DBTiDB:
+		return "#### Option A: Using Docker (Recommended)\n" +
+			fence(fmt.Sprintf("# Run TiDB (user root, no password)\ndocker run --name tidb-dev \\\n  -p 4000:4000 \\\n  -d %s\n\n# Create the database\nmysql -h 127.0.0.1 -P 4000 -u root -e 'CREATE DATABASE IF NOT EXISTS `%s`'\n\n# Or using docker-compose, which creates it\ndocker-compose up -d", tidbImage, projectName)) +
+			"\n\nFor TiDB Cloud, set `DB_SSL_MODE=require` to connect over TLS."
 	case DBMongoDB:
 		return "#### Option A: Using Docker (Recommended)\n" +
 			fence(fmt.Sprintf("# Run MongoDB\ndocker run --name mongo-dev \\\n  -e MONGO_INITDB_ROOT_USERNAME=admin \\\n  -e MONGO_INITDB_ROOT_PASSWORD=password \\\n  -e MONGO_INITDB_DATABASE=%s \\\n  -p 27017:27017 \\\n  -d mongo:7.0\n\n# Or using docker-compose\ndocker-compose up -d", projectName))
 	case DBSQLServer:
 		return "#### Option A: Using Docker (Recommended)\n" +
 			fence("# Run SQL Server\ndocker run --name sqlserver-dev \\\n  -e ACCEPT_EULA=Y \\\n  -e MSSQL_SA_PASSWORD=Your_password123 \\\n  -p 1433:1433 \\\n  -d mcr.microsoft.com/mssql/server:2022-latest\n\n# Or using docker-compose\ndocker-compose up -d")
+	case DBOracle:
+		return "#### Option A: Using Docker (Recommended)\n" +
+			fence(fmt.Sprintf("# Run Oracle Database Free; the first start takes a minute or two\ndocker run --name oracle-dev \\\n  -e ORACLE_PASSWORD=password \\\n  -e APP_USER=%s \\\n  -e APP_USER_PASSWORD=password \\\n  -p 1521:1521 \\\n  -d %s\n\n# Or using docker-compose\ndocker-compose up -d", oracleAppUser, oracleImage)) +
+			fmt.Sprintf("\n\n`DB_NAME` is the service name of the database, `%s` in Oracle Database Free.", oracleServiceName)
 	case DBDynamoDB:
 		return "#### Option A: Using Docker (Recommended)\n" +
 			fence("# Run DynamoDB Local\ndocker run --name dynamodb-dev \\\n  -p 8000:8000 \\\n  -d amazon/dynamodb-local:latest\n\n# Or using docker-compose\ndocker-compose up -d")
@@ -392,10 +488,10 @@ func getReadmeDatabaseTroubleshooting(database, projectName string) string {
 	if database == DBSQLite {
 		return "### Error: \"unable to open database file\"\n" +
 			"SQLite cannot create or access the database file.\n\n" +
-			"**Solution:** Ensure the application has write permission in its working directory."
+			"**Solution:** Ensure the directory of `DB_PATH` exists and the application can write to it."
 	}
 
-	envBlock := fmt.Sprintf("# Configure in .env\nDB_HOST=localhost\nDB_PORT=%s\nDB_USER=%s\nDB_PASSWORD=password\nDB_NAME=%s", port, user, projectName)
+	envBlock := fmt.Sprintf("# Configure in .env\nDB_HOST=localhost\nDB_PORT=%s\nDB_USER=%s\nDB_PASSWORD=password\nDB_NAME=%s", port, user, defaultDatabaseName(database, projectName))
 
 	return fmt.Sprintf("### Error: \"connection refused\"\n%s database is not running.\n\n**Solution:** Start the database service (see step 2) and verify it is reachable on port %s.\n\n### Error: \"database not configured\"\nDatabase environment variables are not configured.\n\n**Solution:**\n%s", display, port, fence(envBlock))
 }
@@ -408,6 +504,21 @@ func createConfig(projectName, _, database string, sm ...*SafetyManager) {
 	if strings.Contains(dbURLBody, "fmt.") {
 		fmtImport = "\t\"fmt\"\n"
 	}
+	// Oracle's DSN is a URL whose user and password may need escaping.
+	if strings.Contains(dbURLBody, "url.") {
+		fmtImport += "\t\"net/url\"\n"
+	}
+	// SQLite is configured with a file path, or a full driver DSN for
+	// pragmas and URI options. DynamoDB also needs the AWS region.
+	dbFields, dbLoad := "", ""
+	switch database {
+	case DBSQLite:
+		dbFields = "\tPath         string\n\tDSN          string\n"
+		dbLoad = fmt.Sprintf("\t\t\tPath:         getEnv(\"DB_PATH\", %q),\n\t\t\tDSN:          getEnv(\"DB_DSN\", \"\"),\n", filepath.Base(projectName)+".db")
+	case DBDynamoDB:
+		dbFields = "\tRegion       string\n"
+		dbLoad = "\t\t\tRegion:       getEnv(\"AWS_REGION\", \"us-east-1\"),\n"
+	}
 	content := fmt.Sprintf(`package config
 
 import (
@@ -423,6 +534,7 @@ type Config struct {
 	LogLevel    string
 	Database    DatabaseConfig
 	Server      ServerConfig
+	Worker      WorkerConfig
 }
 
 type DatabaseConfig struct {
@@ -431,7 +543,7 @@ type DatabaseConfig struct {
 	User         string
 	Password     string
 	Name         string
-	SSLMode      string
+%s	SSLMode      string
 	MaxOpenConns int
 	MaxIdleConns int
 	MaxLifetime  time.Duration
@@ -443,6 +555,7 @@ type ServerConfig struct {
 	IdleTimeout  time.Duration
 }
 
+%s
 func Load() *Config {
 	return &Config{
 		Port:        getEnv("PORT", "8080"),
@@ -454,7 +567,7 @@ func Load() *Config {
 			User:         getEnv("DB_USER", "%s"),
 			Password:     getEnv("DB_PASSWORD", ""),
 			Name:         getEnv("DB_NAME", "%s"),
-			SSLMode:      getEnv("DB_SSL_MODE", "disable"),
+%s			SSLMode:      getEnv("DB_SSL_MODE", "disable"),
 			MaxOpenConns: getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
 			MaxIdleConns: getEnvAsInt("DB_MAX_IDLE_CONNS", 25),
 			MaxLifetime:  getEnvAsDuration("DB_MAX_LIFETIME", "5m"),
@@ -464,7 +577,7 @@ func Load() *Config {
 			WriteTimeout: getEnvAsDuration("SERVER_WRITE_TIMEOUT", "10s"),
 			IdleTimeout:  getEnvAsDuration("SERVER_IDLE_TIMEOUT", "60s"),
 		},
-	}
+%s	}
 }
 
 func (c *Config) GetDatabaseURL() string {
@@ -498,7 +611,7 @@ func getEnvAsDuration(key string, defaultValue string) time.Duration {
 	duration, _ := time.ParseDuration(defaultValue)
 	return duration
 }
-`, fmtImport, getConfigDefaultPort(database), getDatabaseUser(database), projectName, dbURLBody)
+`, fmtImport, dbFields, workerConfigDecl, getConfigDefaultPort(database), getDatabaseUser(database), defaultDatabaseName(database, projectName), dbLoad, workerConfigLoad, dbURLBody)
 
 	if err := writeGoFile(filepath.Join(projectName, "pkg", "config", "config.go"), content, sm...); err != nil {
 		ui.Warning(fmt.Sprintf("Error writing config.go: %v", err))
@@ -506,20 +619,65 @@ func getEnvAsDuration(key string, defaultValue string) time.Duration {
 	}
 }
 
+// ensureConfigSection adds the typ settings of pkg/config, declared by decl,
+// to projects created before they were generated: the Config field, its
+// element load of the literal Load returns and the declaration.
+func ensureConfigSection(field, typ, decl, load string, sm ...*SafetyManager) error {
+	path := filepath.Join(DirPkg, DirConfig, "config.go")
+	s, err := parseGoSource(path)
+	if err != nil {
+		return err
+	}
+	if _, ts := findTypeSpec(s.file, typ); ts != nil {
+		return nil
+	}
+
+	config := findStructType(s.file, "Config")
+	loadFunc := findFunc(s.file, "Load")
+	var lits []*ast.CompositeLit
+	if loadFunc != nil {
+		lits = typedLiterals(loadFunc, "Config")
+	}
+	if config == nil || len(lits) == 0 {
+		return fmt.Errorf("%s has no Config struct returned by Load", path)
+	}
+
+	s.insertLines(config.Fields.Closing, fmt.Sprintf("\t%s %s\n", field, typ))
+	s.insertLiteralLines(lits[0], load)
+	if gen, _ := findTypeSpec(s.file, "ServerConfig"); gen != nil {
+		s.insertLinesAfter(gen.End(), "\n"+decl)
+	} else {
+		s.appendDecls(decl)
+	}
+	_, err = writeEditedGoFile(s, []string{"time"}, sm...)
+	return err
+}
+
 // databaseURLBody returns the body of Config.GetDatabaseURL for the given
 // database driver, so the generated DSN matches the configured database
 // instead of always emitting a PostgreSQL connection string.
 func databaseURLBody(database string) string {
 	switch database {
 	case DBSQLite:
-		// GORM's sqlite driver expects a file path (or :memory:).
-		return "\tname := c.Database.Name\n\tif name == \"\" {\n\t\tname = \"app\"\n\t}\n\treturn name + \".db\""
-	case DBMySQL:
+		// GORM's sqlite drivers expect a file path (or :memory:); DB_DSN
+		// overrides it with a full DSN such as "file:app.db?cache=shared".
+		return "\tif c.Database.DSN != \"\" {\n\t\treturn c.Database.DSN\n\t}\n\tif c.Database.Path != \"\" {\n\t\treturn c.Database.Path\n\t}\n\tname := c.Database.Name\n\tif name == \"\" {\n\t\tname = \"app\"\n\t}\n\treturn name + \".db\""
+	case DBMySQL, DBMariaDB:
 		return "\treturn fmt.Sprintf(\"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local\",\n\t\tc.Database.User, c.Database.Password, c.Database.Host, c.Database.Port, c.Database.Name)"
+	case DBTiDB:
+		// TiDB Cloud only accepts TLS connections.
+		return "\tdsn := fmt.Sprintf(\"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local\",\n\t\tc.Database.User, c.Database.Password, c.Database.Host, c.Database.Port, c.Database.Name)\n\tif c.Database.SSLMode != \"\" && c.Database.SSLMode != \"disable\" {\n\t\tdsn += \"&tls=true\"\n\t}\n\treturn dsn"
+	case DBOracle:
+		// go-ora's URL; the path is the service name of the database.
+		return "\tu := url.URL{\n\t\tScheme: \"oracle\",\n\t\tUser:   url.UserPassword(c.Database.User, c.Database.Password),\n\t\tHost:   c.Database.Host + \":\" + c.Database.Port,\n\t\tPath:   c.Database.Name,\n\t}\n\treturn u.String()"
 	case DBSQLServer:
 		return "\treturn fmt.Sprintf(\"sqlserver://%s:%s@%s:%s?database=%s\",\n\t\tc.Database.User, c.Database.Password, c.Database.Host, c.Database.Port, c.Database.Name)"
 	case DBMongoDB:
 		return "\tif c.Database.User != \"\" && c.Database.Password != \"\" {\n\t\treturn fmt.Sprintf(\"mongodb://%s:%s@%s:%s\", c.Database.User, c.Database.Password, c.Database.Host, c.Database.Port)\n\t}\n\treturn fmt.Sprintf(\"mongodb://%s:%s\", c.Database.Host, c.Database.Port)"
+	case DBElasticsearch, DBDynamoDB:
+		// The HTTP endpoint of the cluster, or of DynamoDB: DynamoDB Local by
+		// default, dynamodb.<region>.amazonaws.com on port 443 with TLS in AWS.
+		return "\tscheme := \"http\"\n\tif c.Database.SSLMode != \"\" && c.Database.SSLMode != \"disable\" {\n\t\tscheme = \"https\"\n\t}\n\treturn fmt.Sprintf(\"%s://%s:%s\", scheme, c.Database.Host, c.Database.Port)"
 	case DBPostgres, DBPostgresJSON:
 		return "\treturn fmt.Sprintf(\"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s\",\n\t\tc.Database.Host, c.Database.Port, c.Database.User, c.Database.Password, c.Database.Name, c.Database.SSLMode)"
 	default: // safe fallback: sqlite file path (matches createGoMod/createMainGo)
@@ -559,43 +717,94 @@ func Error(v ...interface{}) {
 	}
 }
 
-func createAuth(projectName, module string, sm ...*SafetyManager) {
+// createAuth writes the authentication of the given --auth-mode. In jwt mode
+// that is pkg/auth, which issues and validates the JWTs the auth middleware
+// accepts, and the mint-token tool printing tokens of the development users.
+func createAuth(projectName, module, mode string, sm ...*SafetyManager) {
+	switch mode {
+	case AuthModeAPIKey:
+		createAPIKeyAuth(projectName, module, sm...)
+		return
+	case AuthModeOIDC:
+		createOIDCAuth(projectName, sm...)
+		return
+	}
+
 	content := `package auth
 
 import (
 	"errors"
+	"os"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
-var jwtSecret = []byte("your-secret-key") // Change this!
-
+// Claims are the claims of the tokens the API issues.
 type Claims struct {
 	UserID int    ` + "`json:\"user_id\"`" + `
 	Email  string ` + "`json:\"email\"`" + `
+	Role   string ` + "`json:\"role,omitempty\"`" + `
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID int, email string) (string, error) {
+// secret returns the signing key, read from JWT_SECRET like the auth
+// middleware does.
+func secret() ([]byte, error) {
+	key := os.Getenv("JWT_SECRET")
+	if key == "" {
+		return nil, errors.New("JWT_SECRET is not set")
+	}
+	return []byte(key), nil
+}
+
+// expiry returns how long tokens are valid: JWT_EXPIRY, 24h by default.
+func expiry() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("JWT_EXPIRY")); err == nil && d > 0 {
+		return d
+	}
+	return 24 * time.Hour
+}
+
+// GenerateToken signs a token for a user, valid for JWT_EXPIRY.
+func GenerateToken(userID int, email, role string) (string, error) {
+	return GenerateTokenWithExpiry(userID, email, role, expiry())
+}
+
+// GenerateTokenWithExpiry signs a token for a user, valid for ttl.
+func GenerateTokenWithExpiry(userID int, email, role string, ttl time.Duration) (string, error) {
+	key, err := secret()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
 	claims := Claims{
 		UserID: userID,
 		Email:  email,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    os.Getenv("JWT_ISSUER"),
+			Subject:   strconv.Itoa(userID),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
 }
 
+// ValidateToken parses a token and returns its claims when it is valid.
 func ValidateToken(tokenString string) (*Claims, error) {
+	key, err := secret()
+	if err != nil {
+		return nil, err
+	}
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return key, nil
 	})
-
 	if err != nil {
 		return nil, err
 	}
@@ -610,17 +819,125 @@ func ValidateToken(tokenString string) (*Claims, error) {
 	if err := writeGoFile(filepath.Join(projectName, "pkg", "auth", "jwt.go"), content, sm...); err != nil {
 		ui.Warning(fmt.Sprintf("Error creating JWT file: %v", err))
 	}
+
+	mintToken := filepath.Join(projectName, DirCmd, "tools", "mint-token", "main.go")
+	if err := writeGoFile(mintToken, fmt.Sprintf(mintTokenTemplate, module, k8sName(filepath.Base(projectName))), sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Error creating mint-token tool: %v", err))
+	}
 }
 
-func createEnvFiles(projectName, database string, sm ...*SafetyManager) {
-	// Create .env.example
-	envExampleContent := fmt.Sprintf(`# Server Configuration
-PORT=8080
-ENVIRONMENT=development
-LOG_LEVEL=info
+// mintTokenTemplate is cmd/tools/mint-token/main.go, printing tokens of the
+// development users signed with pkg/auth.
+const mintTokenTemplate = `// Command mint-token prints JWTs of the development users, to call the
+// protected endpoints locally without logging in:
+//
+//	make dev-token
+//	make -s dev-token ARGS="-user admin -ttl 1h"
+//
+// The tokens are signed by pkg/auth with JWT_SECRET, like the ones the API
+// issues; make dev-token loads it from .env. Edit devUsers to match the users
+// your database is seeded with.
+package main
 
-# Database Configuration
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"%[1]s/pkg/auth"
+)
+
+// devUser is a user of local development.
+type devUser struct {
+	Name  string
+	ID    int
+	Email string
+	Role  string
+}
+
+// devUsers are the seed users tokens are minted for.
+var devUsers = []devUser{
+	{Name: "admin", ID: 1, Email: "admin@%[2]s.local", Role: "admin"},
+	{Name: "user", ID: 2, Email: "user@%[2]s.local", Role: "user"},
+}
+
+func main() {
+	name := flag.String("user", "", "Only print the token of this user")
+	ttl := flag.Duration("ttl", 0, "Token lifetime (default JWT_EXPIRY)")
+	flag.Parse()
+
+	if os.Getenv("JWT_SECRET") == "" {
+		fail("JWT_SECRET is not set: export it, or run make dev-token, which loads .env")
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, u := range devUsers {
+		if *name != "" && u.Name != *name {
+			continue
+		}
+		token, err := mint(u, *ttl)
+		if err != nil {
+			fail(fmt.Sprintf("minting a token for %%s: %%v", u.Name, err))
+		}
+		if *name != "" {
+			// A bare token, for TOKEN=$(make -s dev-token ARGS="-user admin").
+			fmt.Println(token)
+			return
+		}
+		fmt.Fprintf(w, "%%s\t%%s\t%%s\n", u.Name, u.Role, token)
+	}
+	if *name != "" {
+		fail(fmt.Sprintf("no development user named %%q", *name))
+	}
+	_ = w.Flush()
+	fmt.Println()
+	fmt.Println("curl -H \"Authorization: Bearer <token>\" http://localhost:8080/api/v1/...")
+}
+
+func mint(u devUser, ttl time.Duration) (string, error) {
+	if ttl > 0 {
+		return auth.GenerateTokenWithExpiry(u.ID, u.Email, u.Role, ttl)
+	}
+	return auth.GenerateToken(u.ID, u.Email, u.Role)
+}
+
+func fail(msg string) {
+	fmt.Fprintln(os.Stderr, msg)
+	os.Exit(1)
+}
+`
+
+// databaseEnvBlock returns the database variables of the generated .env
+// files. SQLite has no server to reach, so it only sets the file path;
+// DynamoDB Local takes static keys and the AWS region.
+func databaseEnvBlock(database, projectName string) string {
+	if database == DBSQLite {
+		return fmt.Sprintf(`DB_PATH=%s.db
+# DB_DSN overrides DB_PATH with a full driver DSN, e.g. file:%s.db?cache=shared
+DB_DSN=
+DB_MAX_OPEN_CONNS=25
+DB_MAX_IDLE_CONNS=25
+DB_MAX_LIFETIME=5m
+`, filepath.Base(projectName), filepath.Base(projectName))
+	}
+	if database == DBDynamoDB {
+		return `# DynamoDB Local. For AWS, set DB_HOST=dynamodb.<region>.amazonaws.com,
+# DB_PORT=443 and DB_SSL_MODE=require, and leave DB_PASSWORD empty to use
+# the default AWS credential chain.
 DB_HOST=localhost
+DB_PORT=8000
+DB_USER=local
+DB_PASSWORD=local
+DB_SSL_MODE=disable
+AWS_REGION=us-east-1
+DB_MAX_OPEN_CONNS=25
+DB_MAX_IDLE_CONNS=25
+DB_MAX_LIFETIME=5m
+`
+	}
+	return fmt.Sprintf(`DB_HOST=localhost
 DB_PORT=%s
 DB_USER=%s
 DB_PASSWORD=
@@ -629,12 +946,28 @@ DB_SSL_MODE=disable
 DB_MAX_OPEN_CONNS=25
 DB_MAX_IDLE_CONNS=25
 DB_MAX_LIFETIME=5m
+`, getDatabasePort(database), getDatabaseUser(database), defaultDatabaseName(database, projectName))
+}
+
+func createEnvFiles(projectName, database string, sm ...*SafetyManager) {
+	// Create .env.example
+	envExampleContent := fmt.Sprintf(`# Server Configuration
+PORT=8080
+ENVIRONMENT=development
+LOG_LEVEL=info
 
+# Database Configuration
+%s
 # Server Timeouts
 SERVER_READ_TIMEOUT=10s
 SERVER_WRITE_TIMEOUT=10s
 SERVER_IDLE_TIMEOUT=60s
 
+# Worker Pool
+WORKER_CONCURRENCY=4
+WORKER_QUEUE_SIZE=100
+WORKER_MESSAGE_TIMEOUT=30s
+
 # JWT Configuration (if using auth)
 JWT_SECRET=your-super-secret-jwt-key-change-in-production
 JWT_ISSUER=%s
@@ -647,7 +980,7 @@ JWT_EXPIRY=24h
 # SMTP_PORT=587
 # SMTP_USER=
 # SMTP_PASSWORD=
-`, getDatabasePort(database), getDatabaseUser(database), projectName, projectName)
+`, databaseEnvBlock(database, projectName), projectName)
 
 	if err := writeFile(filepath.Join(projectName, ".env.example"), envExampleContent, sm...); err != nil {
 		ui.Warning(fmt.Sprintf("Error creating .env.example file: %v", err))
@@ -660,26 +993,22 @@ ENVIRONMENT=development
 LOG_LEVEL=info
 
 # Database Configuration
-DB_HOST=localhost
-DB_PORT=%s
-DB_USER=%s
-DB_PASSWORD=
-DB_NAME=%s
-DB_SSL_MODE=disable
-DB_MAX_OPEN_CONNS=25
-DB_MAX_IDLE_CONNS=25
-DB_MAX_LIFETIME=5m
-
+%s
 # Server Timeouts
 SERVER_READ_TIMEOUT=10s
 SERVER_WRITE_TIMEOUT=10s
 SERVER_IDLE_TIMEOUT=60s
 
+# Worker Pool
+WORKER_CONCURRENCY=4
+WORKER_QUEUE_SIZE=100
+WORKER_MESSAGE_TIMEOUT=30s
+
 # JWT Configuration
 JWT_SECRET=dev-secret-change-in-production
 JWT_ISSUER=%s
 JWT_EXPIRY=24h
-`, getDatabasePort(database), getDatabaseUser(database), projectName, projectName)
+`, databaseEnvBlock(database, projectName), projectName)
 
 	if err := writeFile(filepath.Join(projectName, ".env"), envContent, sm...); err != nil {
 		ui.Warning(fmt.Sprintf("Error creating .env file: %v", err))
@@ -694,12 +1023,16 @@ func getConfigDefaultPort(database string) string {
 
 func getDatabasePort(database string) string {
 	switch database {
-	case DBMySQL:
+	case DBMySQL, DBMariaDB:
 		return "3306"
+	case DBTiDB:
+		return "4000"
 	case DBMongoDB:
 		return "27017"
 	case DBSQLServer:
 		return "1433"
+	case DBOracle:
+		return "1521"
 	case DBDynamoDB:
 		return "8000"
 	case DBElasticsearch:
@@ -713,12 +1046,14 @@ func getDatabasePort(database string) string {
 
 func getDatabaseUser(database string) string {
 	switch database {
-	case DBMySQL:
+	case DBMySQL, DBMariaDB, DBTiDB:
 		return "root"
 	case DBMongoDB:
 		return "admin"
 	case DBSQLServer:
 		return "sa"
+	case DBOracle:
+		return oracleAppUser
 	case DBElasticsearch:
 		return "elastic"
 	case DBDynamoDB:
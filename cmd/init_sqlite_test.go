@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteDriverSelection(t *testing.T) {
+	importPath, pkg := gormDriver(DBSQLite, "")
+	assert.Equal(t, "gorm.io/driver/sqlite", importPath)
+	assert.Equal(t, "sqlite", pkg)
+
+	importPath, pkg = gormDriver(DBSQLite, SQLiteDriverModernc)
+	assert.Equal(t, "github.com/glebarez/sqlite", importPath)
+	assert.Equal(t, "sqlite", pkg)
+
+	importPath, _ = gormDriver(DBPostgres, SQLiteDriverModernc)
+	assert.Equal(t, "gorm.io/driver/postgres", importPath)
+
+	assert.NoError(t, validateSQLiteDriverFlag(SQLiteDriverMattn))
+	assert.NoError(t, validateSQLiteDriverFlag(SQLiteDriverModernc))
+	assert.Error(t, validateSQLiteDriverFlag("cgo"))
+}
+
+func TestCreateGoMod_SQLiteDriver(t *testing.T) {
+	defer ensureTestUI(t)()
+	sm := NewSafetyManager(false, true, false)
+
+	dir := t.TempDir()
+	createGoMod(dir, "github.com/test/proj", DBSQLite, SQLiteDriverMattn, "", false, sm)
+	content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "gorm.io/driver/sqlite v1.5.4")
+
+	dir = t.TempDir()
+	createGoMod(dir, "github.com/test/proj", DBSQLite, SQLiteDriverModernc, "", false, sm)
+	content, err = os.ReadFile(filepath.Join(dir, "go.mod"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "github.com/glebarez/sqlite")
+	assert.NotContains(t, string(content), "gorm.io/driver/sqlite")
+}
+
+func TestCreateConfig_SQLitePath(t *testing.T) {
+	defer ensureTestUI(t)()
+	dir := filepath.Join(t.TempDir(), "shop")
+	createConfig(dir, "", DBSQLite, NewSafetyManager(false, true, false))
+
+	content, err := os.ReadFile(filepath.Join(dir, "pkg", "config", "config.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `getEnv("DB_PATH", "shop.db")`)
+	assert.Contains(t, string(content), `getEnv("DB_DSN", "")`)
+	assert.Contains(t, string(content), "return c.Database.DSN")
+
+	createEnvFiles(dir, DBSQLite, NewSafetyManager(false, true, false))
+	env, err := os.ReadFile(filepath.Join(dir, ".env"))
+	require.NoError(t, err)
+	assert.Contains(t, string(env), "DB_PATH=shop.db")
+	assert.NotContains(t, string(env), "DB_HOST")
+}
+
+func TestCreateDockerfiles_SQLite(t *testing.T) {
+	defer ensureTestUI(t)()
+
+	dir := t.TempDir()
+	createDockerfiles(dir, DBSQLite, SQLiteDriverMattn, NewSafetyManager(false, true, false))
+	dockerfile, err := os.ReadFile(filepath.Join(dir, "Dockerfile"))
+	require.NoError(t, err)
+	assert.Contains(t, string(dockerfile), "CGO_ENABLED=1")
+	assert.Contains(t, string(dockerfile), "gcc musl-dev")
+	compose, err := os.ReadFile(filepath.Join(dir, "docker-compose.yml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(compose), "DB_PATH=/data/")
+	assert.Contains(t, string(compose), "sqlite_data:/data")
+	assert.NotContains(t, string(compose), "database:")
+
+	dir = t.TempDir()
+	createDockerfiles(dir, DBSQLite, SQLiteDriverModernc, NewSafetyManager(false, true, false))
+	dockerfile, err = os.ReadFile(filepath.Join(dir, "Dockerfile"))
+	require.NoError(t, err)
+	assert.Contains(t, string(dockerfile), "CGO_ENABLED=0")
+	assert.NotContains(t, string(dockerfile), "gcc")
+}
+
+func TestPersistInitChoices_SQLiteDriver(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".goca.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("database:\n    type: sqlite\n    port: 0\n"), 0o600))
+
+	require.NoError(t, persistInitChoices(path, APITypeRest, SQLiteDriverModernc, "", false))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "type: sqlite\n    driver: modernc\n    port: 0")
+}
+
+func TestValidateDatabase_SQLite(t *testing.T) {
+	cm := NewConfigManager()
+	cm.validateDatabase(&DatabaseConfig{Type: DBSQLite, Driver: SQLiteDriverModernc, Connection: ConnectionConfig{MaxOpen: 1}})
+	assert.Empty(t, cm.errors)
+
+	cm = NewConfigManager()
+	cm.validateDatabase(&DatabaseConfig{Type: DBPostgres, Driver: SQLiteDriverModernc, Port: 5432, Connection: ConnectionConfig{MaxOpen: 1}})
+	assert.Len(t, cm.errors, 1)
+}
@@ -56,8 +56,11 @@ func runInitWizard(projectName string) (name, module, database, api string, auth
 					huh.NewOption("SQLite", "sqlite"),
 					huh.NewOption("PostgreSQL", "postgres"),
 					huh.NewOption("MySQL", "mysql"),
+					huh.NewOption("MariaDB", "mariadb"),
+					huh.NewOption("TiDB", "tidb"),
 					huh.NewOption("MongoDB", "mongodb"),
 					huh.NewOption("SQL Server", "sqlserver"),
+					huh.NewOption("Oracle", "oracle"),
 					huh.NewOption("DynamoDB", "dynamodb"),
 					huh.NewOption("Elasticsearch", "elasticsearch"),
 				).
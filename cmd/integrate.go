@@ -86,7 +86,7 @@ func detectExistingFeatures() []string {
 		}
 		name := strings.TrimSuffix(entry.Name(), ".go")
 		// Skip shared/common files, seed files and test files.
-		if name == "errors" || name == "validations" || name == "common" ||
+		if name == "errors" || name == "repository_errors" || name == "validations" || name == "common" ||
 			strings.HasSuffix(name, "_seeds") || strings.HasSuffix(name, "_test") || name == "" {
 
 			continue
@@ -214,10 +214,16 @@ func createOrUpdateDIContainer(features []string, sm ...*SafetyManager) {
 	configIntegration := NewConfigIntegration()
 	_ = configIntegration.LoadConfigForProject()
 	database := configIntegration.GetDatabaseType("")
-
-	if _, err := os.Stat(diPath); os.IsNotExist(err) {
+	style := configIntegration.GetDIType("")
+
+	if style != DIManual {
+		// Provider files are skipped when present and the container is rebuilt
+		// from all of them, so regenerating is safe.
+		ui.Dim(fmt.Sprintf("   Generating %s providers...", style))
+		generateDI(strings.Join(features, ","), database, style, "", false, sm...)
+	} else if _, err := os.Stat(diPath); os.IsNotExist(err) {
 		ui.Dim("   Creating DI container...")
-		generateDI(strings.Join(features, ","), database, false, false, sm...)
+		generateDI(strings.Join(features, ","), database, DIManual, "", false, sm...)
 	} else {
 		ui.Dim("   Updating existing DI container...")
 		for _, feature := range features {
@@ -304,12 +310,12 @@ func createCompleteMainGoWithFeatures(mainPath string, features []string, module
 		routesSB.WriteString(fmt.Sprintf(`
 	// %s routes
 	%sHandler := container.%sHandler()
-	router.HandleFunc("/api/v1/%ss", %sHandler.Create%s).Methods("POST")
-	router.HandleFunc("/api/v1/%ss/{id}", %sHandler.Get%s).Methods("GET")
-	router.HandleFunc("/api/v1/%ss/{id}", %sHandler.Update%s).Methods("PUT")
-	router.HandleFunc("/api/v1/%ss/{id}", %sHandler.Delete%s).Methods("DELETE")
-	router.HandleFunc("/api/v1/%ss", %sHandler.List%ss).Methods("GET")
-`, feature, featureLower, feature, featureLower, featureLower, feature, featureLower, featureLower, feature, featureLower, featureLower, feature, featureLower, featureLower, feature, featureLower, featureLower, feature))
+	router.HandleFunc("/api/v1/%s", %sHandler.Create%s).Methods("POST")
+	router.HandleFunc("/api/v1/%s/{id}", %sHandler.Get%s).Methods("GET")
+	router.HandleFunc("/api/v1/%s/{id}", %sHandler.Update%s).Methods("PUT")
+	router.HandleFunc("/api/v1/%s/{id}", %sHandler.Delete%s).Methods("DELETE")
+	router.HandleFunc("/api/v1/%s", %sHandler.List%s).Methods("GET")
+`, feature, featureLower, feature, toPlural(featureLower), featureLower, feature, toPlural(featureLower), featureLower, feature, toPlural(featureLower), featureLower, feature, toPlural(featureLower), featureLower, feature, toPlural(featureLower), featureLower, toPlural(feature)))
 	}
 
 	newMainContent := fmt.Sprintf(`package main
@@ -554,12 +560,13 @@ func addMissingFeaturesToMain(mainPath string, features []string, contentStr, mo
 	newContent := contentStr
 	changed := false
 
-	// The generated DI container is GORM-based and takes a *gorm.DB. NoSQL
-	// main.go files (e.g. MongoDB, which expose a mongoClient instead of db)
-	// are not auto-wired here; doing so would reference an undefined `db` and
-	// break compilation. Leave such projects untouched.
-	if !strings.Contains(newContent, "*gorm.DB") {
-		ui.Warning("main.go is not GORM-based; skipping automatic DI/route wiring")
+	// The DI container takes the handle main.go declares: a *gorm.DB, or the
+	// client of the MongoDB, Elasticsearch and DynamoDB mains. A main.go
+	// declaring none of them is not auto-wired here; doing so would reference
+	// an undefined `db` and break compilation. Leave such projects untouched.
+	dbArg := containerDBArg(newContent)
+	if dbArg == "db" && !strings.Contains(newContent, "*gorm.DB") {
+		ui.Warning("main.go declares no known database handle; skipping automatic DI/route wiring")
 		ui.Dim("   Wire the container and routes manually for this database.")
 		return
 	}
@@ -581,7 +588,7 @@ func addMissingFeaturesToMain(mainPath string, features []string, contentStr, mo
 		routerPattern := "router := mux.NewRouter()"
 		if strings.Contains(newContent, routerPattern) {
 			newContent = strings.Replace(newContent, routerPattern,
-				routerPattern+"\n\n\t// Setup dependency injection container\n\tcontainer := di.NewContainer(db)", 1)
+				routerPattern+"\n\n\t// Setup dependency injection container\n\tcontainer := di.NewContainer("+dbArg+")", 1)
 			changed = true
 		}
 	}
@@ -615,19 +622,19 @@ func addMissingFeaturesToMain(mainPath string, features []string, contentStr, mo
 	for _, feature := range features {
 		featureLower := strings.ToLower(feature)
 
-		if strings.Contains(newContent, fmt.Sprintf("/api/v1/%ss", featureLower)) {
+		if strings.Contains(newContent, fmt.Sprintf("/api/v1/%s", toPlural(featureLower))) {
 			continue
 		}
 
 		routeBlock := fmt.Sprintf(`	// %s routes
 	%sHandler := container.%sHandler()
-	router.HandleFunc("/api/v1/%ss", %sHandler.Create%s).Methods("POST")
-	router.HandleFunc("/api/v1/%ss/{id}", %sHandler.Get%s).Methods("GET")
-	router.HandleFunc("/api/v1/%ss/{id}", %sHandler.Update%s).Methods("PUT")
-	router.HandleFunc("/api/v1/%ss/{id}", %sHandler.Delete%s).Methods("DELETE")
-	router.HandleFunc("/api/v1/%ss", %sHandler.List%ss).Methods("GET")
+	router.HandleFunc("/api/v1/%s", %sHandler.Create%s).Methods("POST")
+	router.HandleFunc("/api/v1/%s/{id}", %sHandler.Get%s).Methods("GET")
+	router.HandleFunc("/api/v1/%s/{id}", %sHandler.Update%s).Methods("PUT")
+	router.HandleFunc("/api/v1/%s/{id}", %sHandler.Delete%s).Methods("DELETE")
+	router.HandleFunc("/api/v1/%s", %sHandler.List%s).Methods("GET")
 
-`, feature, featureLower, feature, featureLower, featureLower, feature, featureLower, featureLower, feature, featureLower, featureLower, feature, featureLower, featureLower, feature, featureLower, featureLower, feature)
+`, feature, featureLower, feature, toPlural(featureLower), featureLower, feature, toPlural(featureLower), featureLower, feature, toPlural(featureLower), featureLower, feature, toPlural(featureLower), featureLower, feature, toPlural(featureLower), featureLower, toPlural(feature))
 
 		// Anchor: the HTTP server setup comment present in the generated main.go.
 		markers := []string{"// Setup HTTP server", "server := &http.Server{"}
@@ -706,7 +713,7 @@ func verifyIntegration(features []string) {
 				// Check individual feature routes
 				for _, feature := range features {
 					featureLower := strings.ToLower(feature)
-					if strings.Contains(contentStr, fmt.Sprintf("/api/v1/%ss", featureLower)) {
+					if strings.Contains(contentStr, fmt.Sprintf("/api/v1/%s", toPlural(featureLower))) {
 						ui.Dim(fmt.Sprintf("   %s routes integrated", feature))
 					} else {
 						ui.Warning(fmt.Sprintf("%s routes missing", feature))
@@ -13,7 +13,13 @@ var interfacesCmd = &cobra.Command{
 	Use:   "interfaces <entity>",
 	Short: "Generate interfaces only for TDD",
 	Long: `Generates only the contract interfaces between layers, 
-useful for Test-Driven Development (TDD).`,
+useful for Test-Driven Development (TDD).
+
+Use case and repository contracts are written where their layer defines them:
+<Entity>UseCase and its DTOs in internal/usecase, <Entity>Repository in
+internal/repository/interfaces.go. Services depend on that repository
+interface, so there is a single definition for both layers. Handler
+interfaces are written to internal/interfaces.`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		entity := args[0]
@@ -71,130 +77,31 @@ useful for Test-Driven Development (TDD).`,
 }
 
 func generateInterfaces(entity string, usecase, repository, handler bool, sm ...*SafetyManager) {
-	// Create interfaces directory
-	interfacesDir := filepath.Join("internal", "interfaces")
-	_ = os.MkdirAll(interfacesDir, 0o755)
+	defer profileStep("interfaces", entity)()
 
+	// The use case and repository contracts have one definition each, owned
+	// by their layer, so goca usecase, goca repository and goca feature later
+	// extend these files instead of declaring a second copy.
 	if usecase {
-		generateUseCaseInterfaceFile(interfacesDir, entity, sm...)
+		dir := filepath.Join(DirInternal, DirUseCase)
+		operations := []string{OpCreate, OpRead, OpUpdate, OpDelete, OpList}
+		generateDTOFileWithFields(dir, entity, operations, false, "", DTOStyleNested, sm...)
+		generateUseCaseInterface(dir, entity, entity, operations, sm...)
 	}
 
 	if repository {
-		generateRepositoryInterfaceFile(interfacesDir, entity, sm...)
+		generateRepositoryInterface(filepath.Join(DirInternal, DirRepository), entity, false, sm...)
 	}
 
+	// Handlers have no layer contract of their own, so their interfaces stay
+	// in internal/interfaces.
 	if handler {
+		interfacesDir := filepath.Join(DirInternal, DirInterfaces)
+		_ = os.MkdirAll(interfacesDir, 0o755)
 		generateHandlerInterfaceFile(interfacesDir, entity, sm...)
 	}
 }
 
-func generateUseCaseInterfaceFile(dir, entity string, sm ...*SafetyManager) {
-	// Get the module name from go.mod
-	moduleName := getModuleName()
-
-	entityLower := strings.ToLower(entity)
-	filename := filepath.Join(dir, entityLower+"_usecase.go")
-
-	var content strings.Builder
-	content.WriteString("package interfaces\n\n")
-	content.WriteString(fmt.Sprintf("import \"%s/internal/domain\"\n\n", moduleName))
-
-	// DTO interfaces
-	content.WriteString(fmt.Sprintf("// %s UseCase DTOs\n", entity))
-	content.WriteString(fmt.Sprintf("type Create%sInput interface {\n", entity))
-	content.WriteString("\tGetName() string\n")
-	content.WriteString("\tGetEmail() string\n")
-	content.WriteString("\tValidate() error\n")
-	content.WriteString("}\n\n")
-
-	content.WriteString(fmt.Sprintf("type Create%sOutput interface {\n", entity))
-	content.WriteString(fmt.Sprintf("\tGet%s() domain.%s\n", entity, entity))
-	content.WriteString("\tGetMessage() string\n")
-	content.WriteString("}\n\n")
-
-	content.WriteString(fmt.Sprintf("type Update%sInput interface {\n", entity))
-	content.WriteString("\tGetName() string\n")
-	content.WriteString("\tGetEmail() string\n")
-	content.WriteString("\tValidate() error\n")
-	content.WriteString("}\n\n")
-
-	// UseCase interface
-	content.WriteString(fmt.Sprintf("// %s UseCase interface\n", entity))
-	content.WriteString(fmt.Sprintf("type %sUseCase interface {\n", entity))
-	content.WriteString(fmt.Sprintf("\tCreate%s(input Create%sInput) (Create%sOutput, error)\n",
-		entity, entity, entity))
-	content.WriteString(fmt.Sprintf("\tGet%s(id int) (*domain.%s, error)\n", entity, entity))
-	content.WriteString(fmt.Sprintf("\tUpdate%s(id int, input Update%sInput) error\n", entity, entity))
-	content.WriteString(fmt.Sprintf("\tDelete%s(id int) error\n", entity))
-	content.WriteString(fmt.Sprintf("\tList%ss() ([]domain.%s, error)\n", entity, entity))
-	content.WriteString("}\n")
-
-	if err := writeGoFile(filename, content.String(), sm...); err != nil {
-		fmt.Printf("Error creating usecase interface file: %v\n", err)
-	}
-}
-
-func generateRepositoryInterfaceFile(dir, entity string, sm ...*SafetyManager) {
-	generateRepositoryInterfaceFileWithFields(dir, entity, "", sm...)
-}
-
-func generateRepositoryInterfaceFileWithFields(dir, entity, fields string, sm ...*SafetyManager) {
-	// Get the module name from go.mod
-	moduleName := getModuleName()
-
-	entityLower := strings.ToLower(entity)
-	filename := filepath.Join(dir, entityLower+"_repository.go")
-
-	var content strings.Builder
-	content.WriteString("package interfaces\n\n")
-	content.WriteString(fmt.Sprintf("import (\n\t\"%s/internal/domain\"\n\t\"gorm.io/gorm\"\n)\n\n", moduleName))
-
-	content.WriteString(fmt.Sprintf("// %s Repository interface\n", entity))
-	content.WriteString(fmt.Sprintf("type %sRepository interface {\n", entity))
-
-	// Basic CRUD operations
-	content.WriteString(fmt.Sprintf("\tSave(%s *domain.%s) error\n", entityLower, entity))
-	content.WriteString(fmt.Sprintf("\tFindByID(id int) (*domain.%s, error)\n", entity))
-
-	// Generate dynamic query methods based on fields
-	if fields != "" {
-		validator := NewFieldValidator()
-		fieldsList, err := validator.ParseFieldsWithValidation(fields)
-		if err == nil {
-			queryMethods := validator.GenerateQueryMethodsForFields(entity, fieldsList)
-			for _, method := range queryMethods {
-				if method.MethodName != "FindByID" { // Skip ID as it's already added
-					content.WriteString(fmt.Sprintf("\t%s(%s %s) (*domain.%s, error)\n",
-						method.MethodName, method.Field, method.Type, entity))
-				}
-			}
-		}
-	}
-
-	content.WriteString(fmt.Sprintf("\tUpdate(%s *domain.%s) error\n", entityLower, entity))
-	content.WriteString("\tDelete(id int) error\n")
-	content.WriteString(fmt.Sprintf("\tFindAll() ([]domain.%s, error)\n", entity))
-
-	// Query operations
-	content.WriteString("\tCount() (int, error)\n")
-	content.WriteString("\tExists(id int) (bool, error)\n")
-
-	// Batch operations
-	content.WriteString(fmt.Sprintf("\tSaveBatch(%ss []domain.%s) error\n", entityLower, entity))
-	content.WriteString("\tDeleteBatch(ids []int) error\n")
-
-	// Transaction operations
-	content.WriteString(fmt.Sprintf("\tSaveWithTx(tx *gorm.DB, %s *domain.%s) error\n", entityLower, entity))
-	content.WriteString(fmt.Sprintf("\tUpdateWithTx(tx *gorm.DB, %s *domain.%s) error\n", entityLower, entity))
-	content.WriteString("\tDeleteWithTx(tx *gorm.DB, id int) error\n")
-
-	content.WriteString("}\n")
-
-	if err := writeGoFile(filename, content.String(), sm...); err != nil {
-		fmt.Printf("Error creating repository interface file: %v\n", err)
-	}
-}
-
 func generateHandlerInterfaceFile(dir, entity string, sm ...*SafetyManager) {
 	entityLower := strings.ToLower(entity)
 	filename := filepath.Join(dir, entityLower+"_handler.go")
@@ -216,7 +123,7 @@ func generateHandlerInterfaceFile(dir, entity string, sm ...*SafetyManager) {
 	content.WriteString(fmt.Sprintf("\tGet%s(w http.ResponseWriter, r *http.Request)\n", entity))
 	content.WriteString(fmt.Sprintf("\tUpdate%s(w http.ResponseWriter, r *http.Request)\n", entity))
 	content.WriteString(fmt.Sprintf("\tDelete%s(w http.ResponseWriter, r *http.Request)\n", entity))
-	content.WriteString(fmt.Sprintf("\tList%ss(w http.ResponseWriter, r *http.Request)\n", entity))
+	content.WriteString(fmt.Sprintf("\tList%s(w http.ResponseWriter, r *http.Request)\n", toPlural(entity)))
 	content.WriteString("}\n\n")
 
 	// gRPC Handler interface
@@ -230,8 +137,8 @@ func generateHandlerInterfaceFile(dir, entity string, sm ...*SafetyManager) {
 		entity, entity, entity))
 	content.WriteString(fmt.Sprintf("\tDelete%s(ctx context.Context, req *Delete%sRequest) (*Delete%sResponse, error)\n",
 		entity, entity, entity))
-	content.WriteString(fmt.Sprintf("\tList%ss(ctx context.Context, req *List%ssRequest) (*List%ssResponse, error)\n",
-		entity, entity, entity))
+	content.WriteString(fmt.Sprintf("\tList%s(ctx context.Context, req *List%sRequest) (*List%sResponse, error)\n",
+		toPlural(entity), toPlural(entity), toPlural(entity)))
 	content.WriteString("}\n\n")
 
 	// CLI Handler interface
@@ -241,7 +148,7 @@ func generateHandlerInterfaceFile(dir, entity string, sm ...*SafetyManager) {
 	content.WriteString(fmt.Sprintf("\tGet%sCommand() interface{}\n", entity))
 	content.WriteString(fmt.Sprintf("\tUpdate%sCommand() interface{}\n", entity))
 	content.WriteString(fmt.Sprintf("\tDelete%sCommand() interface{}\n", entity))
-	content.WriteString(fmt.Sprintf("\tList%ssCommand() interface{}\n", entity))
+	content.WriteString(fmt.Sprintf("\tList%sCommand() interface{}\n", toPlural(entity)))
 	content.WriteString("}\n\n")
 
 	// Request/Response interfaces for gRPC
@@ -299,13 +206,13 @@ func generateGRPCRequestResponseInterfaces(content *strings.Builder, entity stri
 	content.WriteString("}\n\n")
 
 	// List Request interface
-	fmt.Fprintf(content, "type List%ssRequest interface {\n", entity)
+	fmt.Fprintf(content, "type List%sRequest interface {\n", toPlural(entity))
 	content.WriteString("\t// No fields for basic list\n")
 	content.WriteString("}\n\n")
 
 	// List Response interface
-	fmt.Fprintf(content, "type List%ssResponse interface {\n", entity)
-	fmt.Fprintf(content, "\tGet%ss() []*domain.%s\n", entity, entity)
+	fmt.Fprintf(content, "type List%sResponse interface {\n", toPlural(entity))
+	fmt.Fprintf(content, "\tGet%s() []*domain.%s\n", toPlural(entity), entity)
 	content.WriteString("\tGetTotal() int32\n")
 	content.WriteString("}\n")
 }
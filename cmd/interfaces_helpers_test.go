@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -58,20 +59,37 @@ func TestDryRunGenerateInterfaces(t *testing.T) {
 	require.NoError(t, os.Chdir(tmpDir))
 	sm := &SafetyManager{DryRun: true}
 
-	t.Run("generate usecase interface", func(t *testing.T) {
-		generateUseCaseInterfaceFile(tmpDir, "Product", sm)
-		// DryRun — no files created, just exercises code paths
-	})
-
 	t.Run("generate handler interface", func(t *testing.T) {
 		generateHandlerInterfaceFile(tmpDir, "Product", sm)
 	})
 
-	t.Run("generate repository interface with fields", func(t *testing.T) {
-		generateRepositoryInterfaceFileWithFields(tmpDir, "Product", "Name:string,Price:float64", sm)
-	})
-
 	t.Run("generate all interfaces", func(t *testing.T) {
 		generateInterfaces("Product", true, true, true, sm)
 	})
 }
+
+func TestGenerateInterfacesUsesLayerContracts(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+
+	generateInterfaces("Product", true, true, false, NewSafetyManager(false, false, false))
+
+	repo, err := os.ReadFile(filepath.Join("internal", "repository", "interfaces.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(repo), "package repository")
+	assert.Contains(t, string(repo), "type ProductRepository interface")
+
+	uc, err := os.ReadFile(filepath.Join("internal", "usecase", "product_usecase.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(uc), "type ProductUseCase interface")
+	dto, err := os.ReadFile(filepath.Join("internal", "usecase", "dto.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(dto), "type CreateProductInput struct")
+
+	assert.NoDirExists(t, filepath.Join("internal", "interfaces"))
+
+	// goca repository reuses the contract instead of declaring a second one.
+	generateRepositoryInterface(filepath.Join("internal", "repository"), "Product", false)
+	repo, _ = os.ReadFile(filepath.Join("internal", "repository", "interfaces.go"))
+	assert.Equal(t, 1, strings.Count(string(repo), "type ProductRepository interface"))
+}
@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// errLintViolations is returned by goca lint when a rule is violated.
+var errLintViolations = errors.New("architecture rules violated")
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Enforce the Clean Architecture dependency rules of the project",
+	Long: `Checks the imports of every Go file of the project, generated or written by
+hand, against the dependency rules of Clean Architecture:
+
+  domain-imports-outer-layer     internal/domain must not import the use case,
+                                 repository, handler or DI packages
+  usecase-imports-infrastructure internal/usecase must not import the gorm ORM
+                                 or its drivers, net/http or the handler packages
+  handler-imports-repository     internal/handler must not import repositories;
+                                 handlers go through the use cases
+
+Each violation is reported as file:line:column. Test files are skipped unless
+--tests is given. goca lint exits with status 1 when a rule is violated, so it
+can gate CI.
+
+Exceptions are declared under lint in .goca.yaml:
+
+  lint:
+    disable: [handler-imports-repository]
+    allow:
+      - rule: usecase-imports-infrastructure
+        path: internal/usecase/export_*.go
+        import: net/http
+        reason: streams CSV exports`,
+	Example: `  goca lint
+  goca lint --output json
+  goca lint --output github`,
+	RunE: runLint,
+}
+
+func init() {
+	lintCmd.Flags().String("output", "text", "Output format: text, json or github (workflow annotations)")
+	lintCmd.Flags().Bool("tests", false, "Also check _test.go files")
+}
+
+// lintRule forbids the files under dir to import packages.
+type lintRule struct {
+	name    string
+	dir     string
+	forbid  []string // import paths; their subpackages are forbidden too
+	message string
+}
+
+// lintViolation is a forbidden import.
+type lintViolation struct {
+	Rule    string `json:"rule"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Import  string `json:"import"`
+	Message string `json:"message"`
+}
+
+func (v lintViolation) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s %q", v.File, v.Line, v.Column, v.Rule, v.Message, v.Import)
+}
+
+// lintRules returns the dependency rules of a project whose module is
+// module.
+func lintRules(module string) []lintRule {
+	internal := func(dirs ...string) []string {
+		paths := make([]string, len(dirs))
+		for i, dir := range dirs {
+			paths[i] = module + "/" + DirInternal + "/" + dir
+		}
+		return paths
+	}
+	return []lintRule{
+		{
+			name:    "domain-imports-outer-layer",
+			dir:     filepath.Join(DirInternal, DirDomain),
+			forbid:  internal(DirUseCase, DirRepository, DirHandler, "di"),
+			message: "domain imports an outer layer",
+		},
+		{
+			name:    "usecase-imports-infrastructure",
+			dir:     filepath.Join(DirInternal, DirUseCase),
+			forbid:  append([]string{"gorm.io/gorm", "gorm.io/driver", "net/http"}, internal(DirHandler)...),
+			message: "use case imports infrastructure",
+		},
+		{
+			name:    "handler-imports-repository",
+			dir:     filepath.Join(DirInternal, DirHandler),
+			forbid:  internal(DirRepository),
+			message: "handler imports a repository instead of a use case",
+		},
+	}
+}
+
+func runLint(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	output, _ := cmd.Flags().GetString("output")
+	tests, _ := cmd.Flags().GetBool("tests")
+	if output != "text" && output != "json" && output != "github" {
+		return fmt.Errorf("invalid --output %q: must be text, json or github", output)
+	}
+	// Keep stdout machine-readable: the summary and errors go to stderr.
+	if output != "text" && ui != nil {
+		prevWriter := ui.writer
+		ui.writer = os.Stderr
+		defer func() { ui.writer = prevWriter }()
+	}
+
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	violations, err := lintProject(getImportPath(getModuleName()), ci.GetLintConfig(), tests)
+	if err != nil {
+		return err
+	}
+
+	switch output {
+	case "json":
+		if violations == nil {
+			violations = []lintViolation{}
+		}
+		out, err := json.MarshalIndent(violations, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	case "github":
+		for _, v := range violations {
+			fmt.Printf("::error file=%s,line=%d,col=%d,title=%s::%s %q\n", v.File, v.Line, v.Column, v.Rule, v.Message, v.Import)
+		}
+	default:
+		printLintReport(violations)
+	}
+
+	if len(violations) > 0 {
+		err := fmt.Errorf("%w: %d violation(s)", errLintViolations, len(violations))
+		if output != "text" {
+			// Execute() would print the error on stdout after the report, so
+			// report it on stderr and exit with the same code here.
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+		return err
+	}
+	return nil
+}
+
+// lintProject returns the imports of the project that break a rule and no
+// exception of cfg allows, sorted by file and position.
+func lintProject(module string, cfg LintConfig, tests bool) ([]lintViolation, error) {
+	disabled := make(map[string]bool, len(cfg.Disable))
+	for _, name := range cfg.Disable {
+		disabled[name] = true
+	}
+
+	var violations []lintViolation
+	for _, rule := range lintRules(module) {
+		if disabled[rule.name] || !dirExists(rule.dir) {
+			continue
+		}
+		files, err := analyzeGoFiles(rule.dir, !tests)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", rule.dir, err)
+		}
+		for _, file := range files {
+			found, err := lintFile(rule, file)
+			if err != nil {
+				return nil, err
+			}
+			for _, v := range found {
+				if !lintAllowed(cfg.Allow, v) {
+					violations = append(violations, v)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].File != violations[j].File {
+			return violations[i].File < violations[j].File
+		}
+		return violations[i].Line < violations[j].Line
+	})
+	return violations, nil
+}
+
+// lintFile returns the imports of file that rule forbids.
+func lintFile(rule lintRule, file string) ([]lintViolation, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, file, nil, parser.ImportsOnly)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", file, err)
+	}
+	var found []lintViolation
+	for _, imp := range node.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		for _, forbidden := range rule.forbid {
+			if path == forbidden || strings.HasPrefix(path, forbidden+"/") {
+				pos := fset.Position(imp.Pos())
+				found = append(found, lintViolation{
+					Rule:    rule.name,
+					File:    filepath.ToSlash(file),
+					Line:    pos.Line,
+					Column:  pos.Column,
+					Import:  path,
+					Message: rule.message,
+				})
+				break
+			}
+		}
+	}
+	return found, nil
+}
+
+// lintAllowed reports whether an exception allows v.
+func lintAllowed(allow []LintException, v lintViolation) bool {
+	for _, e := range allow {
+		if e.Rule != v.Rule {
+			continue
+		}
+		if e.Path != "" && !matchProtectedPattern(e.Path, v.File) {
+			continue
+		}
+		if e.Import != "" && v.Import != e.Import && !strings.HasPrefix(v.Import, e.Import+"/") {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// printLintReport prints the violations the way go vet does, followed by a
+// count per rule.
+func printLintReport(violations []lintViolation) {
+	if len(violations) == 0 {
+		ui.Success("No architecture rule violated")
+		return
+	}
+	perRule := make(map[string]int)
+	for _, v := range violations {
+		fmt.Println(v.String())
+		perRule[v.Rule]++
+	}
+	ui.Blank()
+	rules := make([]string, 0, len(perRule))
+	for rule := range perRule {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	for _, rule := range rules {
+		ui.KeyValue(rule, strconv.Itoa(perRule[rule]))
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupLintProject(t *testing.T) {
+	t.Helper()
+	chdirTemp(t)
+	writeTestFile(t, ".", "internal/domain/order.go", "package domain\n\nimport (\n\t\"time\"\n\n\t\"testproject/internal/usecase\"\n)\n\nvar _ = usecase.OrderService{}\nvar _ time.Time\n")
+	writeTestFile(t, ".", "internal/domain/errors.go", "package domain\n\nimport \"errors\"\n\nvar ErrNotFound = errors.New(\"not found\")\n")
+	writeTestFile(t, ".", "internal/usecase/order_service.go", "package usecase\n\nimport (\n\t\"net/http\"\n\n\t\"gorm.io/datatypes\"\n\t\"gorm.io/gorm\"\n)\n")
+	writeTestFile(t, ".", "internal/usecase/order_service_test.go", "package usecase\n\nimport \"net/http/httptest\"\n")
+	writeTestFile(t, ".", "internal/handler/http/order_handler.go", "package http\n\nimport \"testproject/internal/repository\"\n")
+	writeTestFile(t, ".", "internal/handler/http/routes.go", "package http\n\nimport repo \"testproject/internal/repository/postgres\"\n")
+}
+
+func TestLintProject(t *testing.T) {
+	setupLintProject(t)
+
+	violations, err := lintProject("testproject", LintConfig{}, false)
+	require.NoError(t, err)
+	var got []string
+	for _, v := range violations {
+		got = append(got, v.String())
+	}
+	assert.Equal(t, []string{
+		`internal/domain/order.go:6:2: domain-imports-outer-layer: domain imports an outer layer "testproject/internal/usecase"`,
+		`internal/handler/http/order_handler.go:3:8: handler-imports-repository: handler imports a repository instead of a use case "testproject/internal/repository"`,
+		`internal/handler/http/routes.go:3:8: handler-imports-repository: handler imports a repository instead of a use case "testproject/internal/repository/postgres"`,
+		`internal/usecase/order_service.go:4:2: usecase-imports-infrastructure: use case imports infrastructure "net/http"`,
+		`internal/usecase/order_service.go:7:2: usecase-imports-infrastructure: use case imports infrastructure "gorm.io/gorm"`,
+	}, got)
+
+	violations, err = lintProject("testproject", LintConfig{}, true)
+	require.NoError(t, err)
+	assert.Len(t, violations, 6)
+}
+
+func TestLintProject_Exceptions(t *testing.T) {
+	setupLintProject(t)
+
+	cfg := LintConfig{
+		Disable: []string{"domain-imports-outer-layer"},
+		Allow: []LintException{
+			{Rule: "handler-imports-repository", Path: "internal/handler/**/routes.go"},
+			{Rule: "usecase-imports-infrastructure", Path: "internal/usecase", Import: "net/http"},
+			{Rule: "handler-imports-repository", Import: "gorm.io/gorm"},
+		},
+	}
+	violations, err := lintProject("testproject", cfg, true)
+	require.NoError(t, err)
+	require.Len(t, violations, 2)
+	assert.Equal(t, "internal/handler/http/order_handler.go", violations[0].File)
+	assert.Equal(t, "gorm.io/gorm", violations[1].Import)
+}
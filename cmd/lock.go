@@ -0,0 +1,349 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// generationLockFile records every file goca generated in a project, the
+// command that generated it and its content hash, for goca status and goca
+// regen. It lives at the root of the project and is meant to be committed.
+const generationLockFile = ".goca.lock"
+
+// generationLock is the content of generationLockFile.
+type generationLock struct {
+	GocaVersion string `json:"goca_version"`
+	// Features are the commands that generated each feature, in the order
+	// they ran, which goca regen runs again.
+	Features map[string][]lockInvocation `json:"features,omitempty"`
+	// Files are keyed by their slash-separated path from the project root.
+	Files map[string]lockedFile `json:"files"`
+}
+
+// lockInvocation is a goca command and the options it ran with.
+type lockInvocation struct {
+	Command string            `json:"command"`
+	Args    []string          `json:"args,omitempty"`
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// lockedFile is a generated file as goca last wrote it.
+type lockedFile struct {
+	Generator string            `json:"generator"`
+	Feature   string            `json:"feature,omitempty"`
+	Options   map[string]string `json:"options,omitempty"`
+	SHA256    string            `json:"sha256"`
+}
+
+// lockFeatureCommands are the commands whose first argument is the feature
+// they generate or change.
+var lockFeatureCommands = map[string]bool{
+	"feature": true, "entity": true, "repository": true, "handler": true,
+	"messages": true, "interfaces": true, "mocks": true, "presenter": true,
+	"test-integration": true, "archive": true, "cdc": true,
+	"field add": true, "field remove": true, "field rename": true,
+}
+
+// regenCommands are the commands goca regen runs again. The others change a
+// feature in ways that cannot be repeated, such as adding a field, and the
+// commands it runs pick up their result from the code.
+var regenCommands = map[string]bool{
+	"feature": true, "entity": true, "usecase": true, "repository": true,
+	"handler": true, "messages": true, "interfaces": true, "mocks": true,
+	"presenter": true, "test-integration": true, "archive": true, "cdc": true,
+}
+
+// lockInvocationKeys are the options that tell apart invocations of a command
+// with the same arguments, such as the handler types of a feature.
+var lockInvocationKeys = map[string][]string{
+	"handler": {"type"},
+}
+
+// unrecordedOptions only affect how a command writes its files, not what it
+// generates.
+var unrecordedOptions = map[string]bool{
+	"force": true, "dry-run": true, "backup": true, "merge": true, "help": true,
+}
+
+// untrackedLockFiles are the generated files the lock does not track.
+var untrackedLockFiles = map[string]bool{"go.mod": true, "go.sum": true}
+
+// lockRecorder collects the files written by the running command.
+type lockRecorder struct {
+	mu         sync.Mutex
+	invocation lockInvocation
+	feature    string
+	// renamed holds the old and new names of a feature goca rename feature
+	// renamed.
+	renamed [2]string
+	files   map[string]string // absolute path -> SHA-256
+	removed map[string]bool
+}
+
+// lockRecording is the recorder of the running command, nil when nothing is
+// recorded (as in the unit tests, which call the generators directly).
+var lockRecording *lockRecorder
+
+// startLockRecording records the files the command cmd writes from now on.
+func startLockRecording(cmd *cobra.Command, args []string) {
+	inv := lockInvocation{Command: lockCommandName(cmd), Args: args, Options: make(map[string]string)}
+	cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		if !f.Changed || unrecordedOptions[f.Name] {
+			return
+		}
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			inv.Options[f.Name] = strings.Join(slice.GetSlice(), ",")
+			return
+		}
+		inv.Options[f.Name] = f.Value.String()
+	})
+	r := &lockRecorder{invocation: inv, files: make(map[string]string), removed: make(map[string]bool)}
+	switch {
+	case inv.Options["entity"] != "":
+		r.feature = inv.Options["entity"]
+	case inv.Command == "rename feature" && len(args) > 1:
+		r.feature = args[1]
+		r.renamed = [2]string{args[0], args[1]}
+	case lockFeatureCommands[inv.Command] && len(args) > 0 && inv.Options["batch"] == "" && inv.Options["from-manifest"] == "":
+		r.feature = args[0]
+	}
+	lockRecording = r
+}
+
+// lockCommandName returns the path of cmd below the root command, such as
+// "field add".
+func lockCommandName(cmd *cobra.Command) string {
+	return strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+}
+
+// recordGenerated records that the running command wrote content to path.
+// go.mod and go.sum are left out: the go command keeps changing them.
+func recordGenerated(path, content string) {
+	r := lockRecording
+	if r == nil || untrackedLockFiles[filepath.Base(path)] {
+		return
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return
+	}
+	sum := sha256.Sum256([]byte(content))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.files[abs] = hex.EncodeToString(sum[:])
+	delete(r.removed, abs)
+}
+
+// forgetGenerated records that the running command removed path.
+func forgetGenerated(path string) {
+	r := lockRecording
+	if r == nil {
+		return
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.files, abs)
+	r.removed[abs] = true
+}
+
+// saveGenerationLock merges the files recorded by the running command into
+// the lock of the project each belongs to.
+func saveGenerationLock() error {
+	r := lockRecording
+	lockRecording = nil
+	if r == nil || (len(r.files) == 0 && len(r.removed) == 0) {
+		return nil
+	}
+
+	byRoot := make(map[string][]string)
+	for path := range r.files {
+		if root, ok := findLockRoot(filepath.Dir(path)); ok {
+			byRoot[root] = append(byRoot[root], path)
+		}
+	}
+	for path := range r.removed {
+		if root, ok := findLockRoot(filepath.Dir(path)); ok {
+			byRoot[root] = append(byRoot[root], path)
+		}
+	}
+
+	var errs []error
+	for root, paths := range byRoot {
+		lock, err := readGenerationLock(root)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		r.apply(lock, root, paths)
+		errs = append(errs, writeGenerationLock(root, lock))
+	}
+	return errors.Join(errs...)
+}
+
+// apply merges the recorded paths of the project at root into lock.
+func (r *lockRecorder) apply(lock *generationLock, root string, paths []string) {
+	lock.GocaVersion = Version
+	if old, renamed := r.renamed[0], r.renamed[1]; old != "" {
+		lock.renameFeature(old, renamed)
+	}
+	for _, path := range paths {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		if r.removed[path] {
+			delete(lock.Files, rel)
+			continue
+		}
+		lock.Files[rel] = lockedFile{
+			Generator: r.invocation.Command,
+			Feature:   r.feature,
+			Options:   r.invocation.Options,
+			SHA256:    r.files[path],
+		}
+	}
+	if r.feature != "" && regenCommands[r.invocation.Command] {
+		lock.addInvocation(r.feature, r.invocation)
+	}
+}
+
+// addInvocation records that inv generated feature, replacing an earlier
+// invocation of the same command for it.
+func (l *generationLock) addInvocation(feature string, inv lockInvocation) {
+	if l.Features == nil {
+		l.Features = make(map[string][]lockInvocation)
+	}
+	name := l.featureName(feature)
+	invocations := l.Features[name]
+	for i, existing := range invocations {
+		if existing.key() == inv.key() {
+			invocations = append(invocations[:i], invocations[i+1:]...)
+			break
+		}
+	}
+	l.Features[name] = append(invocations, inv)
+}
+
+// key identifies the invocations that generate the same files.
+func (inv lockInvocation) key() string {
+	parts := append([]string{inv.Command}, inv.Args...)
+	for _, option := range lockInvocationKeys[inv.Command] {
+		parts = append(parts, "--"+option+"="+inv.Options[option])
+	}
+	return strings.ToLower(strings.Join(parts, "\x00"))
+}
+
+// featureName returns the name feature is recorded under, matched without
+// regard to case.
+func (l *generationLock) featureName(feature string) string {
+	for name := range l.Features {
+		if strings.EqualFold(name, feature) {
+			return name
+		}
+	}
+	return feature
+}
+
+// renameFeature moves the invocations and files of feature old to renamed.
+func (l *generationLock) renameFeature(old, renamed string) {
+	name := l.featureName(old)
+	if invocations, ok := l.Features[name]; ok {
+		delete(l.Features, name)
+		for i, inv := range invocations {
+			args := make([]string, len(inv.Args))
+			for j, arg := range inv.Args {
+				args[j] = strings.Replace(arg, old, renamed, 1)
+			}
+			invocations[i].Args = args
+			if strings.EqualFold(inv.Options["entity"], old) {
+				options := make(map[string]string, len(inv.Options))
+				for k, v := range inv.Options {
+					options[k] = v
+				}
+				options["entity"] = renamed
+				invocations[i].Options = options
+			}
+		}
+		l.Features[renamed] = invocations
+	}
+	for path, f := range l.Files {
+		if strings.EqualFold(f.Feature, old) {
+			f.Feature = renamed
+			l.Files[path] = f
+		}
+	}
+}
+
+// findLockRoot returns the project directory dir belongs to: the nearest
+// directory declaring .goca.yaml or go.mod.
+func findLockRoot(dir string) (string, bool) {
+	dir = filepath.Clean(dir)
+	for {
+		for _, marker := range []string{".goca.yaml", "go.mod"} {
+			if info, err := os.Stat(filepath.Join(dir, marker)); err == nil && !info.IsDir() {
+				return dir, true
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// readGenerationLock reads the lock of the project at root, empty when it has
+// none.
+func readGenerationLock(root string) (*generationLock, error) {
+	lock := &generationLock{Files: make(map[string]lockedFile)}
+	data, err := os.ReadFile(filepath.Join(root, generationLockFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filepath.Join(root, generationLockFile), err)
+	}
+	if lock.Files == nil {
+		lock.Files = make(map[string]lockedFile)
+	}
+	return lock, nil
+}
+
+// writeGenerationLock writes lock to the project at root.
+func writeGenerationLock(root string, lock *generationLock) error {
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	//#nosec G306 // the lock is committed with the project
+	return os.WriteFile(filepath.Join(root, generationLockFile), append(data, '\n'), 0o644)
+}
+
+// lockedFeatures returns the features of lock, sorted.
+func (l *generationLock) lockedFeatures() []string {
+	names := make([]string, 0, len(l.Features))
+	for name := range l.Features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
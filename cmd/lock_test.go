@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lockTestRoot returns a command tree with the flags of the generators the
+// lock tests run.
+func lockTestRoot() (root, feature, handler *cobra.Command) {
+	root = &cobra.Command{Use: "goca"}
+	root.PersistentFlags().Bool("verbose", false, "")
+	feature = &cobra.Command{Use: "feature", Run: func(*cobra.Command, []string) {}}
+	feature.Flags().String("fields", "", "")
+	feature.Flags().Bool("validation", false, "")
+	feature.Flags().Bool("force", false, "")
+	feature.Flags().Bool("dry-run", false, "")
+	handler = &cobra.Command{Use: "handler", Run: func(*cobra.Command, []string) {}}
+	handler.Flags().String("type", "http", "")
+	handler.Flags().Bool("force", false, "")
+	root.AddCommand(feature, handler)
+	return root, feature, handler
+}
+
+// runRecorded records the files written by write as the command cmd run with
+// args, and saves the lock.
+func runRecorded(t *testing.T, cmd *cobra.Command, args []string, write func()) {
+	t.Helper()
+	require.NoError(t, cmd.ParseFlags(args))
+	startLockRecording(cmd, cmd.Flags().Args())
+	write()
+	require.NoError(t, saveGenerationLock())
+}
+
+func TestGenerationLock(t *testing.T) {
+	chdirTemp(t)
+	writeTestFile(t, ".", "go.mod", "module example.com/shop\n\ngo 1.21\n")
+	_, feature, handler := lockTestRoot()
+	entity := filepath.Join(DirInternal, DirDomain, "product.go")
+
+	runRecorded(t, feature, []string{"Product", "--fields", "name:string", "--validation", "--force", "--verbose"}, func() {
+		require.NoError(t, writeFile(entity, "package domain\n"))
+		require.NoError(t, writeFile("go.mod", "module example.com/shop\n\ngo 1.21\n"))
+	})
+	runRecorded(t, handler, []string{"Product", "--type", "grpc"}, func() {
+		require.NoError(t, writeGoFile(filepath.Join(DirInternal, DirHandler, DirGRPC, "product_server.go"), "package grpc\n"))
+	})
+	runRecorded(t, handler, []string{"Product", "--type", "grpc"}, func() {})
+
+	lock, err := readGenerationLock(".")
+	require.NoError(t, err)
+	assert.Len(t, lock.Files, 2, "go.mod is not tracked")
+	f := lock.Files["internal/domain/product.go"]
+	assert.Equal(t, "feature", f.Generator)
+	assert.Equal(t, "Product", f.Feature)
+	assert.Equal(t, map[string]string{"fields": "name:string", "validation": "true"}, f.Options)
+	require.Len(t, lock.Features["Product"], 2)
+	assert.Equal(t, "grpc", lock.Features["Product"][1].Options["type"])
+
+	// A rerun of the same handler type replaces its invocation.
+	runRecorded(t, handler, []string{"Product", "--type", "grpc"}, func() {
+		require.NoError(t, writeGoFile(filepath.Join(DirInternal, DirHandler, DirGRPC, "product_server.go"), "package grpc\n"))
+	})
+	lock, err = readGenerationLock(".")
+	require.NoError(t, err)
+	assert.Len(t, lock.Features["Product"], 2)
+	assert.Nil(t, lockRecording)
+}
+
+func TestGenerationLockStatus(t *testing.T) {
+	chdirTemp(t)
+	writeTestFile(t, ".", "go.mod", "module example.com/shop\n\ngo 1.21\n")
+	_, feature, _ := lockTestRoot()
+	runRecorded(t, feature, []string{"Product"}, func() {
+		require.NoError(t, writeFile(filepath.Join(DirInternal, DirDomain, "product.go"), "package domain\n"))
+		require.NoError(t, writeFile(filepath.Join(DirInternal, DirDomain, "errors.go"), "package domain\n"))
+		require.NoError(t, writeFile(filepath.Join(DirInternal, DirDomain, "order.go"), "package domain\n"))
+	})
+	writeTestFile(t, filepath.Join(DirInternal, DirDomain), "product.go", "package domain\n\n// edited\n")
+	require.NoError(t, os.Remove(filepath.Join(DirInternal, DirDomain, "errors.go")))
+	writeTestFile(t, filepath.Join(DirInternal, DirUseCase), "extra.go", "package usecase\n")
+
+	root, lock, err := loadProjectLock()
+	require.NoError(t, err)
+	status := compareGenerationLock(root, lock)
+	assert.Equal(t, []string{"internal/domain/product.go"}, status.modified)
+	assert.Equal(t, []string{"internal/domain/errors.go"}, status.missing)
+	assert.Equal(t, []string{"internal/usecase/extra.go"}, status.untracked)
+	assert.Equal(t, 1, status.unchanged)
+}
+
+func TestRegenArgs(t *testing.T) {
+	root, _, _ := lockTestRoot()
+	lock := &generationLock{Features: map[string][]lockInvocation{
+		"Product": {
+			{Command: "feature", Args: []string{"Product"}, Options: map[string]string{"fields": "name:string", "validation": "true"}},
+			{Command: "handler", Args: []string{"Product"}, Options: map[string]string{"type": "grpc"}},
+		},
+	}}
+	current := func(string) string { return "name:string,sku:string" }
+
+	commands, err := regenArgs(root, lock, "product", false, current)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"feature", "Product", "--fields=name:string,sku:string", "--validation=true", "--force"},
+		{"handler", "Product", "--type=grpc", "--force"},
+	}, commands)
+
+	commands, err = regenArgs(root, lock, "Product", true, current)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"feature", "Product", "--fields=name:string,sku:string", "--validation=true", "--force", "--dry-run"}, commands[0])
+	assert.Equal(t, []string{"handler", "Product", "--type=grpc", "--force"}, commands[1])
+
+	_, err = regenArgs(root, lock, "Order", false, current)
+	assert.ErrorContains(t, err, "recorded features: Product")
+}
+
+func TestGenerationLockRenameFeature(t *testing.T) {
+	lock := &generationLock{
+		Features: map[string][]lockInvocation{"Product": {{Command: "usecase", Args: []string{"ProductService"}, Options: map[string]string{"entity": "Product"}}}},
+		Files:    map[string]lockedFile{"internal/domain/product.go": {Generator: "feature", Feature: "Product"}},
+	}
+	lock.renameFeature("Product", "Item")
+	require.Contains(t, lock.Features, "Item")
+	assert.Equal(t, []string{"ItemService"}, lock.Features["Item"][0].Args)
+	assert.Equal(t, "Item", lock.Features["Item"][0].Options["entity"])
+	assert.Equal(t, "Item", lock.Files["internal/domain/product.go"].Feature)
+}
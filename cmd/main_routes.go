@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// registerRoutesFuncs are the functions of package main that feature routes
+// are added to when main.go has no wiringRoutesMarker, so projects that moved
+// their routing out of main() keep being wired:
+//
+//	func RegisterRoutes(apiRouter *mux.Router, container *di.Container) {
+//		apphttp.SetupProductRoutes(apiRouter, container.ProductUseCase())
+//	}
+var registerRoutesFuncs = []string{"RegisterRoutes", "registerRoutes"}
+
+// findRegisterRoutes returns the file of the main package that declares one of
+// registerRoutesFuncs, looking at main.go (whose current content is given)
+// first and then at the other files of its directory. It returns nil when no
+// file declares one.
+func findRegisterRoutes(mainPath, content string) (*goSource, *ast.FuncDecl, error) {
+	mainSrc, err := newGoSource(mainPath, []byte(content))
+	if err != nil {
+		return nil, nil, err
+	}
+	if fn := registerRoutesFunc(mainSrc.file); fn != nil {
+		return mainSrc, fn, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(mainPath), "*.go"))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") || filepath.Clean(file) == filepath.Clean(mainPath) {
+			continue
+		}
+		src, err := parseGoSource(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		if src.file.Name.Name != mainSrc.file.Name.Name {
+			continue
+		}
+		if fn := registerRoutesFunc(src.file); fn != nil {
+			return src, fn, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// registerRoutesFunc returns the declaration of the first of
+// registerRoutesFuncs in file.
+func registerRoutesFunc(file *ast.File) *ast.FuncDecl {
+	for _, name := range registerRoutesFuncs {
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == name && fn.Body != nil {
+				return fn
+			}
+		}
+	}
+	return nil
+}
+
+// wireFeatureIntoRegisterRoutes appends the route registration of a feature
+// to the end of fn, passing the router and container parameters of fn, and
+// writes the file back. The router is the parameter named after the
+// subrouter of the version, e.g. apiV2Router, or for the default version the
+// first *mux.Router parameter. It is idempotent.
+func wireFeatureIntoRegisterRoutes(src *goSource, fn *ast.FuncDecl, featureName, moduleName, version string) error {
+	suffix := apiVersionSuffix(version)
+	setup := fmt.Sprintf("Setup%s%sRoutes(", featureName, suffix)
+	body := string(src.src[src.offset(fn.Body.Lbrace):src.offset(fn.Body.Rbrace)])
+	if strings.Contains(body, "."+setup) {
+		return nil
+	}
+
+	routerParam, containerParam := registerRoutesParams(fn, version)
+	if routerParam == "" {
+		return fmt.Errorf("%s in %s has no %s *mux.Router parameter for the %s routes", fn.Name.Name, src.path, apiVersionRouter(version), version) //nolint:err113
+	}
+	if containerParam == "" {
+		return fmt.Errorf("%s in %s has no *di.Container parameter", fn.Name.Name, src.path) //nolint:err113
+	}
+
+	handlerPath := moduleName + "/internal/handler/http"
+	pkg := "apphttp"
+	if imp := findImport(src.file, handlerPath); imp == nil {
+		src.addImportSpecs([]string{fmt.Sprintf("%s %q", pkg, handlerPath)})
+	} else if imp.Name != nil {
+		pkg = imp.Name.Name
+	} else {
+		pkg = path.Base(handlerPath)
+	}
+
+	comment := strings.ToLower(featureName)
+	if suffix != "" {
+		comment += " " + version
+	}
+	call := fmt.Sprintf("%s.%s%s, %s.%sUseCase()) // %s routes", pkg, setup, routerParam, containerParam, featureName, comment)
+	if src.fset.Position(fn.Body.Lbrace).Line == src.fset.Position(fn.Body.Rbrace).Line {
+		src.insert(fn.Body.Rbrace, "\n\t"+call+"\n")
+	} else {
+		src.insertLines(fn.Body.Rbrace, "\t"+call+"\n")
+	}
+
+	formatted, err := format.Source(src.apply())
+	if err != nil {
+		return fmt.Errorf("could not format %s: %w", src.path, err)
+	}
+	return writeMainGoInPlace(src.path, string(formatted))
+}
+
+// registerRoutesParams returns the names of the router and DI container
+// parameters of fn the routes of version are registered with.
+func registerRoutesParams(fn *ast.FuncDecl, version string) (router, container string) {
+	var firstRouter string
+	for _, field := range fn.Type.Params.List {
+		typ := pointerTypeName(field.Type)
+		for _, name := range field.Names {
+			if name.Name == "_" {
+				continue
+			}
+			switch typ {
+			case "Router":
+				if name.Name == apiVersionRouter(version) {
+					router = name.Name
+				}
+				if firstRouter == "" {
+					firstRouter = name.Name
+				}
+			case "Container":
+				if container == "" {
+					container = name.Name
+				}
+			}
+		}
+	}
+	if router == "" && apiVersionSuffix(version) == "" {
+		router = firstRouter
+	}
+	return router, container
+}
+
+// pointerTypeName returns Name for a parameter of type *pkg.Name or *Name.
+func pointerTypeName(expr ast.Expr) string {
+	star, ok := expr.(*ast.StarExpr)
+	if !ok {
+		return ""
+	}
+	switch x := star.X.(type) {
+	case *ast.SelectorExpr:
+		return x.Sel.Name
+	case *ast.Ident:
+		return x.Name
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRoutesStubProject writes a dependency-free project whose main.go was
+// customized to register its routes from RegisterRoutes in routes.go.
+func writeRoutesStubProject(t *testing.T, routes string) {
+	t.Helper()
+	writeTestFile(t, ".", "pkg/mux/mux.go", "package mux\n\ntype Router struct{}\n")
+	writeTestFile(t, ".", "internal/di/container.go", `package di
+
+type Container struct{}
+
+func NewContainer() *Container { return &Container{} }
+
+func (c *Container) ProductUseCase() string { return "product" }
+
+func (c *Container) OrderUseCase() string { return "order" }
+`)
+	writeTestFile(t, ".", "internal/handler/http/routes.go", `package http
+
+import "testproject/pkg/mux"
+
+func SetupProductRoutes(router *mux.Router, uc string) {}
+
+func SetupOrderRoutes(router *mux.Router, uc string) {}
+
+func SetupOrderV2Routes(router *mux.Router, uc string) {}
+`)
+	writeTestFile(t, ".", "cmd/server/main.go", `package main
+
+import (
+	"testproject/internal/di"
+	"testproject/pkg/mux"
+)
+
+func main() {
+	RegisterRoutes(&mux.Router{}, &mux.Router{}, di.NewContainer())
+}
+`)
+	writeTestFile(t, ".", "cmd/server/routes.go", routes)
+}
+
+func TestUpdateMainRoutes_RegisterRoutes(t *testing.T) {
+	defer setupDiscardUI(t)()
+	chdirTemp(t)
+	writeRoutesStubProject(t, `package main
+
+import (
+	"testproject/internal/di"
+	"testproject/pkg/mux"
+)
+
+// RegisterRoutes mounts the API.
+func RegisterRoutes(api *mux.Router, apiV2Router *mux.Router, c *di.Container) {}
+`)
+
+	updateMainRoutes("Product", "v1")
+	updateMainRoutes("Order", "v1")
+	updateMainRoutes("Order", "v2")
+	updateMainRoutes("Product", "v1")
+
+	routes := readParsedGo(t, filepath.Join("cmd", "server", "routes.go"))
+	assert.Contains(t, routes, `apphttp "testproject/internal/handler/http"`)
+	assert.Equal(t, 1, strings.Count(routes, "apphttp.SetupProductRoutes(api, c.ProductUseCase())"))
+	assert.Contains(t, routes, "\tapphttp.SetupOrderRoutes(api, c.OrderUseCase())           // order routes\n")
+	assert.Contains(t, routes, "\tapphttp.SetupOrderV2Routes(apiV2Router, c.OrderUseCase()) // order v2 routes\n}")
+	assert.NotContains(t, readParsedGo(t, filepath.Join("cmd", "server", "main.go")), "Setup")
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	build := exec.Command("go", "build", "./...")
+	build.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+	out, err := build.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func TestWireFeatureIntoMainGo_RegisterRoutesErrors(t *testing.T) {
+	defer setupDiscardUI(t)()
+	chdirTemp(t)
+	writeRoutesStubProject(t, `package main
+
+import (
+	http "testproject/internal/handler/http"
+	"testproject/pkg/mux"
+)
+
+func registerRoutes(router *mux.Router) {
+	_ = http.SetupOrderRoutes
+}
+`)
+	mainPath := filepath.Join("cmd", "server", "main.go")
+	content, err := os.ReadFile(mainPath)
+	require.NoError(t, err)
+
+	err = wireFeatureIntoMainGo(mainPath, "Product", "testproject", "v1", string(content))
+	assert.ErrorContains(t, err, "no *di.Container parameter")
+	err = wireFeatureIntoMainGo(mainPath, "Order", "testproject", "v2", string(content))
+	assert.ErrorContains(t, err, "no apiV2Router *mux.Router parameter")
+
+	// Without a marker or RegisterRoutes the routes are not silently dropped.
+	require.NoError(t, os.Remove(filepath.Join("cmd", "server", "routes.go")))
+	err = wireFeatureIntoMainGo(mainPath, "Product", "testproject", "v1", string(content))
+	assert.ErrorContains(t, err, "neither the")
+}
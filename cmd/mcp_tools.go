@@ -15,6 +15,12 @@ import (
 // returns its combined stdout/stderr output. Using os.Args[0] ensures the
 // running binary is always in sync with this MCP server instance.
 func runGocaSubcommand(ctx context.Context, args []string) (string, error) {
+	return runGocaSubcommandIn(ctx, "", nil, args)
+}
+
+// runGocaSubcommandIn is runGocaSubcommand started in dir ("" for the current
+// directory) with env added to its environment.
+func runGocaSubcommandIn(ctx context.Context, dir string, env, args []string) (string, error) {
 	binary, err := os.Executable()
 	if err != nil {
 		if len(os.Args) > 0 {
@@ -39,6 +45,10 @@ func runGocaSubcommand(ctx context.Context, args []string) (string, error) {
 
 	//#nosec G702 — binary is from os.Executable(); args validated above
 	cmd := exec.CommandContext(ctx, binary, safeArgs...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	cmd.Stdout = &out
 	cmd.Stderr = &out
 
@@ -48,6 +48,33 @@ func toolFeature() mcp.Tool {
 		mcp.WithBoolean("mocks",
 			mcp.Description("Generate testify mock stubs for repository and use-case interfaces"),
 		),
+		mcp.WithBoolean("contracts",
+			mcp.Description("Generate Pact contract tests and an example consumer pact for the HTTP handler"),
+		),
+		mcp.WithBoolean("versioned",
+			mcp.Description("Add a Version column for optimistic locking (409 on stale updates)"),
+		),
+		mcp.WithBoolean("audit",
+			mcp.Description("Record CreatedBy/UpdatedBy and an audit log with a history endpoint (GORM databases)"),
+		),
+		mcp.WithBoolean("multi_tenant",
+			mcp.Description("Scope every query to the request's tenant (X-Tenant-ID header or tenant_id claim; GORM databases)"),
+		),
+		mcp.WithString("tenant_isolation",
+			mcp.Description("Tenant isolation strategy: column (default) or schema (PostgreSQL only)"),
+		),
+		mcp.WithBoolean("presenter",
+			mcp.Description("Generate the presenter the HTTP, gRPC and CLI handlers present the entity through"),
+		),
+		mcp.WithBoolean("import_export",
+			mcp.Description("Generate bulk CSV/JSON import and filtered export endpoints (GORM databases)"),
+		),
+		mcp.WithString("skip",
+			mcp.Description("Comma-separated layers to leave out: domain, usecase, repository, handler, messages (optional)"),
+		),
+		mcp.WithString("layers",
+			mcp.Description("Comma-separated layers to generate instead of all of them, e.g. \"domain,usecase\"; the layers they depend on must be generated too or exist already (optional)"),
+		),
 		mcp.WithBoolean("dry_run",
 			mcp.Description("Preview files that would be created without writing to disk (recommended before committing)"),
 		),
@@ -70,6 +97,15 @@ func handleFeature(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 	args = appendIfTrue(args, req.GetBool("business_rules", false), "--business-rules")
 	args = appendIfTrue(args, req.GetBool("integration_tests", false), "--integration-tests")
 	args = appendIfTrue(args, req.GetBool("mocks", false), "--mocks")
+	args = appendIfTrue(args, req.GetBool("contracts", false), "--contracts")
+	args = appendIfTrue(args, req.GetBool("versioned", false), "--versioned")
+	args = appendIfTrue(args, req.GetBool("audit", false), "--audit")
+	args = appendIfTrue(args, req.GetBool("multi_tenant", false), "--multi-tenant")
+	args = appendIfSet(args, req.GetString("tenant_isolation", ""), "--tenant-isolation")
+	args = appendIfTrue(args, req.GetBool("presenter", false), "--presenter")
+	args = appendIfTrue(args, req.GetBool("import_export", false), "--import-export")
+	args = appendIfSet(args, req.GetString("skip", ""), "--skip")
+	args = appendIfSet(args, req.GetString("layers", ""), "--layers")
 	args = appendIfTrue(args, req.GetBool("dry_run", false), "--dry-run")
 	args = appendIfTrue(args, req.GetBool("force", false), "--force")
 
@@ -104,6 +140,15 @@ func toolEntity() mcp.Tool {
 		mcp.WithBoolean("soft_delete",
 			mcp.Description("Add DeletedAt soft-delete field"),
 		),
+		mcp.WithBoolean("versioned",
+			mcp.Description("Add a Version column for optimistic locking"),
+		),
+		mcp.WithBoolean("audit",
+			mcp.Description("Add CreatedBy/UpdatedBy columns and the AuditLog entity"),
+		),
+		mcp.WithBoolean("multi_tenant",
+			mcp.Description("Add a TenantID column and the tenant context helpers"),
+		),
 		mcp.WithBoolean("tests",
 			mcp.Description("Generate unit tests for the entity"),
 		),
@@ -128,6 +173,9 @@ func handleEntity(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 	args = appendIfTrue(args, req.GetBool("business_rules", false), "--business-rules")
 	args = appendIfTrue(args, req.GetBool("timestamps", false), "--timestamps")
 	args = appendIfTrue(args, req.GetBool("soft_delete", false), "--soft-delete")
+	args = appendIfTrue(args, req.GetBool("versioned", false), "--versioned")
+	args = appendIfTrue(args, req.GetBool("audit", false), "--audit")
+	args = appendIfTrue(args, req.GetBool("multi_tenant", false), "--multi-tenant")
 	args = appendIfTrue(args, req.GetBool("tests", false), "--tests")
 	args = appendIfTrue(args, req.GetBool("dry_run", false), "--dry-run")
 	args = appendIfTrue(args, req.GetBool("force", false), "--force")
@@ -172,6 +220,7 @@ func handleUsecase(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 	args := []string{"usecase", name}
 	args = appendIfSet(args, req.GetString("fields", ""), "--fields")
 	args = appendIfTrue(args, req.GetBool("validation", false), "--validation")
+	args = appendIfTrue(args, req.GetBool("contracts", false), "--contracts")
 	args = appendIfTrue(args, req.GetBool("dry_run", false), "--dry-run")
 	args = appendIfTrue(args, req.GetBool("force", false), "--force")
 
@@ -236,6 +285,9 @@ func toolHTTPHandler() mcp.Tool {
 		mcp.WithBoolean("validation",
 			mcp.Description("Add request validation in the handler"),
 		),
+		mcp.WithBoolean("contracts",
+			mcp.Description("Generate Pact contract tests and an example consumer pact (HTTP only)"),
+		),
 		mcp.WithBoolean("dry_run",
 			mcp.Description("Preview without writing files"),
 		),
@@ -71,6 +71,7 @@ organized by feature to maintain consistency in the application.`,
 }
 
 func generateMessages(entity string, errors, responses, constants bool, sm ...*SafetyManager) {
+	defer profileStep("messages", entity)()
 	// Directory paths only — the file writers (SafetyManager.WriteFile) create
 	// the parent directory when a file is actually written, which keeps
 	// --dry-run from leaving empty directories behind.
@@ -95,6 +96,7 @@ func generateUseCaseMessages(entity string, sm ...*SafetyManager) {
 	// so --dry-run does not leave an empty directory behind.
 	messagesDir := filepath.Join("internal", "messages")
 	filename := filepath.Join(messagesDir, "messages.go")
+	defer lockSharedFile(filename)()
 	entityLower := strings.ToLower(entity)
 
 	// Check if file exists and read existing content
@@ -141,6 +143,7 @@ func generateUseCaseMessages(entity string, sm ...*SafetyManager) {
 
 func generateResponseMessages(dir, entity string, sm ...*SafetyManager) {
 	filename := filepath.Join(dir, "responses.go")
+	defer lockSharedFile(filename)()
 	entityLower := strings.ToLower(entity)
 
 	// Check if file exists and read existing content
@@ -176,7 +179,7 @@ func generateResponseMessages(dir, entity string, sm ...*SafetyManager) {
 	existingContent.WriteString(fmt.Sprintf("\t%sUpdatedSuccessfully = \"%s updated successfully\"\n", entity, entityLower))
 	existingContent.WriteString(fmt.Sprintf("\t%sDeletedSuccessfully = \"%s deleted successfully\"\n", entity, entityLower))
 	existingContent.WriteString(fmt.Sprintf("\t%sFoundSuccessfully   = \"%s found successfully\"\n", entity, entityLower))
-	existingContent.WriteString(fmt.Sprintf("\t%ssListedSuccessfully = \"%ss listed successfully\"\n", entity, entityLower))
+	existingContent.WriteString(fmt.Sprintf("\t%sListedSuccessfully = \"%s listed successfully\"\n", toPlural(entity), toPlural(entityLower)))
 
 	// Operation messages
 	existingContent.WriteString(fmt.Sprintf("\t%sProcessingStarted   = \"%s processing started\"\n", entity, entityLower))
@@ -209,7 +212,7 @@ func generateConstants(dir, entity string, sm ...*SafetyManager) {
 	content.WriteString(fmt.Sprintf("\tMax%sNameLength = 100\n", entity))
 
 	// Database constants
-	content.WriteString(fmt.Sprintf("\t%sTableName     = \"%ss\"\n", entity, entityLower))
+	content.WriteString(fmt.Sprintf("\t%sTableName     = %q\n", entity, entityTableName(entity)))
 	content.WriteString(fmt.Sprintf("\t%sIDColumn      = \"id\"\n", entity))
 	content.WriteString(fmt.Sprintf("\t%sNameColumn    = \"name\"\n", entity))
 	content.WriteString(fmt.Sprintf("\t%sEmailColumn   = \"email\"\n", entity))
@@ -220,7 +223,7 @@ func generateConstants(dir, entity string, sm ...*SafetyManager) {
 
 	// API constants
 	content.WriteString(fmt.Sprintf("\t%sAPIVersion    = \"v1\"\n", entity))
-	content.WriteString(fmt.Sprintf("\t%sEndpoint      = \"/%ss\"\n", entity, entityLower))
+	content.WriteString(fmt.Sprintf("\t%sEndpoint      = \"/%s\"\n", entity, toPlural(entityLower)))
 	content.WriteString(fmt.Sprintf("\tMax%sPerPage    = 100\n", entity))
 	content.WriteString(fmt.Sprintf("\tDefault%sPerPage = 20\n", entity))
 
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -10,7 +11,8 @@ import (
 
 // validMiddlewareTypes lists all supported middleware type keys.
 var validMiddlewareTypes = []string{
-	"cors", "logging", "auth", "rate-limit", "recovery", "request-id", "timeout",
+	"cors", "logging", "auth", "rate-limit", "recovery", "request-id", "timeout", "load-shed", "metrics",
+	"compression", "access-log",
 }
 
 var middlewareCmd = &cobra.Command{
@@ -26,10 +28,22 @@ Supported middleware types:
   recovery    — Panic recovery returning JSON 500 responses
   request-id  — Inject X-Request-ID into context and response
   timeout     — Per-request context deadline
+  load-shed   — 503 with Retry-After past a max-in-flight ceiling or while degraded
+  metrics     — Prometheus request counts and durations per route template,
+                plus a Grafana dashboard and an observability compose profile
+  compression — gzip/zstd responses past a size and content-type threshold,
+                and bounded decompression of request bodies
+  access-log  — slog record per request with its X-Request-ID, and
+                optionally the JSON bodies with sensitive fields redacted
 
 Use --types to select which middleware to generate (comma-separated).
 Default: cors,logging,recovery
 
+Use --router to match the router the middleware is registered on (mux or
+chi). The metrics middleware labels requests with the route template the
+router matched, e.g. /users/{id}, rather than the raw path, and comes with a
+metrics_test.go asserting it.
+
 A middleware.go file with a Chain() helper is always generated for composing
 multiple middleware functions.`,
 	Args: cobra.ExactArgs(1),
@@ -44,6 +58,7 @@ multiple middleware functions.`,
 		}
 
 		typesStr, _ := cmd.Flags().GetString("types")
+		router, _ := cmd.Flags().GetString("router")
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
 		force, _ := cmd.Flags().GetBool("force")
 		backup, _ := cmd.Flags().GetBool("backup")
@@ -52,6 +67,9 @@ multiple middleware functions.`,
 		if err := validateMiddlewareTypes(types); err != nil {
 			return err
 		}
+		if !slices.Contains(ValidRouters, router) {
+			return fmt.Errorf("invalid router %q: use one of %s", router, strings.Join(ValidRouters, ", "))
+		}
 
 		sm := NewSafetyManager(dryRun, force, backup)
 
@@ -59,9 +77,10 @@ multiple middleware functions.`,
 		ui.Blank()
 		ui.KeyValue("Name", name)
 		ui.KeyValue("Types", strings.Join(types, ", "))
+		ui.KeyValue("Router", router)
 		ui.Blank()
 
-		if err := generateMiddlewarePackage(name, types, sm); err != nil {
+		if err := generateMiddlewarePackage(name, types, router, sm); err != nil {
 			return err
 		}
 
@@ -76,12 +95,34 @@ multiple middleware functions.`,
 		ui.Info("Next steps:")
 		ui.Step(1, "Import the middleware package in your routes")
 		ui.Step(2, "Use middleware.Chain() to compose middleware functions")
+		if contains(types, "metrics") {
+			if router == RouterChi {
+				ui.Step(3, "Register router.Use(middleware.Metrics()) before the routes and router.Handle(\"/metrics\", middleware.MetricsHandler())")
+			} else {
+				ui.Step(3, "Register router.Use(mux.MiddlewareFunc(middleware.Metrics())) and router.Handle(\"/metrics\", middleware.MetricsHandler())")
+			}
+			ui.Step(4, "Export the connection pool with middleware.RegisterDBStats(sqlDB, \"main\") and job queues with middleware.RegisterQueueDepth(\"jobs\", pool.Len)")
+			ui.Step(5, "Run docker compose --profile observability up and open the dashboard at http://localhost:3000")
+		}
+		if router == RouterChi {
+			ui.Dim("   chi needs: go get github.com/go-chi/chi/v5@" + chiVersion)
+		}
+		if contains(types, "compression") {
+			ui.Dim("   Register router.Use(mux.MiddlewareFunc(middleware.Compression(middleware.DefaultCompressionConfig())))")
+			if contains(projectCompression().Algorithms, "zstd") {
+				ui.Dim("   zstd needs: go get github.com/klauspost/compress@" + klauspostCompressVersion)
+			}
+		}
+		if contains(types, "access-log") {
+			ui.Dim("   Register middleware.Chain(middleware.RequestID(), middleware.AccessLog(middleware.DefaultAccessLogConfig()))")
+		}
 		return nil
 	},
 }
 
 func init() {
 	middlewareCmd.Flags().String("types", "cors,logging,recovery", "Middleware types to generate (comma-separated)")
+	middlewareCmd.Flags().String("router", RouterMux, "Router the middleware is registered on: "+strings.Join(ValidRouters, ", "))
 	middlewareCmd.Flags().Bool("dry-run", false, "Preview changes without creating files")
 	middlewareCmd.Flags().Bool("force", false, "Overwrite existing files without asking")
 	middlewareCmd.Flags().Bool("backup", false, "Backup existing files before overwriting")
@@ -15,24 +15,35 @@ func middlewarePackageExists() bool {
 	return err == nil
 }
 
+// chiVersion pins github.com/go-chi/chi/v5 for middleware generated with
+// --router chi.
+const chiVersion = "v5.1.0"
+
 // middlewareTypeToFile maps a middleware type key to its output filename and
-// the generator function that produces the file content.
+// the generator function that produces the file content. Middleware that
+// integrates with the router is generated by forRouter instead.
 var middlewareTypeToFile = map[string]struct {
-	filename string
-	generate func() string
+	filename  string
+	generate  func() string
+	forRouter func(router string) string
 }{
-	"cors":       {filename: "cors.go", generate: generateCORSMiddleware},
-	"logging":    {filename: "logging.go", generate: generateLoggingMiddleware},
-	"auth":       {filename: "auth.go", generate: generateAuthMiddleware},
-	"rate-limit": {filename: "rate_limit.go", generate: generateRateLimitMiddleware},
-	"recovery":   {filename: "recovery.go", generate: generateRecoveryMiddleware},
-	"request-id": {filename: "request_id.go", generate: generateRequestIDMiddleware},
-	"timeout":    {filename: "timeout.go", generate: generateTimeoutMiddleware},
+	"cors":        {filename: "cors.go", generate: generateCORSMiddleware},
+	"logging":     {filename: "logging.go", generate: generateLoggingMiddleware},
+	"auth":        {filename: "auth.go", generate: generateAuthMiddleware},
+	"rate-limit":  {filename: "rate_limit.go", generate: generateRateLimitMiddleware},
+	"recovery":    {filename: "recovery.go", generate: generateRecoveryMiddleware},
+	"request-id":  {filename: "request_id.go", generate: generateRequestIDMiddleware},
+	"timeout":     {filename: "timeout.go", generate: generateTimeoutMiddleware},
+	"load-shed":   {filename: "load_shed.go", generate: generateLoadShedMiddleware},
+	"metrics":     {filename: "metrics.go", forRouter: generateMetricsMiddleware},
+	"compression": {filename: "compression.go", generate: generateCompressionMiddleware},
+	"access-log":  {filename: "access_log.go", generate: generateAccessLogMiddleware},
 }
 
 // generateMiddlewarePackage creates the internal/middleware/ package with the
-// requested middleware types and the Chain() helper.
-func generateMiddlewarePackage(name string, types []string, sm *SafetyManager) error {
+// requested middleware types for router and the Chain() helper.
+func generateMiddlewarePackage(name string, types []string, router string, sm *SafetyManager) error {
+	defer profileStep("middleware")()
 	module := getModuleName()
 	middlewareDir := filepath.Join(DirInternal, dirMiddleware)
 
@@ -48,11 +59,33 @@ func generateMiddlewarePackage(name string, types []string, sm *SafetyManager) e
 		if !ok {
 			return fmt.Errorf("unknown middleware type: %s", t)
 		}
+		content := ""
+		if entry.forRouter != nil {
+			content = entry.forRouter(router)
+		} else {
+			content = entry.generate()
+		}
 		filePath := filepath.Join(middlewareDir, entry.filename)
-		if err := writeGoFile(filePath, entry.generate(), sm); err != nil {
+		if err := writeGoFile(filePath, content, sm); err != nil {
 			return fmt.Errorf("writing %s: %w", entry.filename, err)
 		}
 	}
 
+	if contains(types, "access-log") {
+		fieldsPath := filepath.Join(middlewareDir, "sensitive_fields.go")
+		if err := writeGoFileMerged(fieldsPath, generateSensitiveFields(), sm); err != nil {
+			return fmt.Errorf("writing sensitive_fields.go: %w", err)
+		}
+	}
+
+	if contains(types, "metrics") {
+		testPath := filepath.Join(middlewareDir, "metrics_test.go")
+		if err := writeGoFile(testPath, generateMetricsMiddlewareTest(router), sm); err != nil {
+			return fmt.Errorf("writing metrics_test.go: %w", err)
+		}
+		if err := generateObservability(sm); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -329,3 +329,247 @@ func generateTimeoutMiddleware() string {
 	b.WriteString("}\n")
 	return b.String()
 }
+
+// generateLoadShedMiddleware returns load_shed.go content.
+func generateLoadShedMiddleware() string {
+	var b strings.Builder
+	b.WriteString("package middleware\n\n")
+	b.WriteString("import (\n")
+	b.WriteString("\t\"encoding/json\"\n")
+	b.WriteString("\t\"math\"\n")
+	b.WriteString("\t\"net/http\"\n")
+	b.WriteString("\t\"strconv\"\n")
+	b.WriteString("\t\"sync/atomic\"\n")
+	b.WriteString("\t\"time\"\n")
+	b.WriteString(")\n\n")
+	b.WriteString("// LoadShedConfig holds the limits past which requests are rejected.\n")
+	b.WriteString("type LoadShedConfig struct {\n")
+	b.WriteString("\t// MaxInFlight is the number of requests served at the same time.\n")
+	b.WriteString("\t// Zero disables the limit.\n")
+	b.WriteString("\tMaxInFlight int\n")
+	b.WriteString("\t// RetryAfter is sent to rejected clients in the Retry-After header.\n")
+	b.WriteString("\tRetryAfter time.Duration\n")
+	b.WriteString("\t// Degraded reports whether the service is degraded, e.g. from the\n")
+	b.WriteString("\t// last result of a health check. It runs on every request, so it must\n")
+	b.WriteString("\t// be cheap. Nil never sheds.\n")
+	b.WriteString("\tDegraded func() bool\n")
+	b.WriteString("}\n\n")
+	b.WriteString("// DefaultLoadShedConfig returns sensible defaults (100 requests in flight,\n")
+	b.WriteString("// retry after 5s).\n")
+	b.WriteString("func DefaultLoadShedConfig() LoadShedConfig {\n")
+	b.WriteString("\treturn LoadShedConfig{\n")
+	b.WriteString("\t\tMaxInFlight: 100,\n")
+	b.WriteString("\t\tRetryAfter:  5 * time.Second,\n")
+	b.WriteString("\t}\n")
+	b.WriteString("}\n\n")
+	b.WriteString("// LoadShed returns middleware that answers 503 with a Retry-After header\n")
+	b.WriteString("// instead of serving a request while the service is degraded or\n")
+	b.WriteString("// MaxInFlight requests are already being served.\n")
+	b.WriteString("func LoadShed(cfg LoadShedConfig) Middleware {\n")
+	b.WriteString("\tvar inFlight atomic.Int64\n")
+	b.WriteString("\tretryAfter := strconv.Itoa(int(math.Max(1, math.Ceil(cfg.RetryAfter.Seconds()))))\n\n")
+	b.WriteString("\treturn func(next http.Handler) http.Handler {\n")
+	b.WriteString("\t\treturn http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {\n")
+	b.WriteString("\t\t\tif cfg.Degraded != nil && cfg.Degraded() {\n")
+	b.WriteString("\t\t\t\tshed(w, retryAfter, \"service degraded\")\n")
+	b.WriteString("\t\t\t\treturn\n")
+	b.WriteString("\t\t\t}\n")
+	b.WriteString("\t\t\tdefer inFlight.Add(-1)\n")
+	b.WriteString("\t\t\tif n := inFlight.Add(1); cfg.MaxInFlight > 0 && n > int64(cfg.MaxInFlight) {\n")
+	b.WriteString("\t\t\t\tshed(w, retryAfter, \"too many requests in flight\")\n")
+	b.WriteString("\t\t\t\treturn\n")
+	b.WriteString("\t\t\t}\n")
+	b.WriteString("\t\t\tnext.ServeHTTP(w, r)\n")
+	b.WriteString("\t\t})\n")
+	b.WriteString("\t}\n")
+	b.WriteString("}\n\n")
+	b.WriteString("func shed(w http.ResponseWriter, retryAfter, msg string) {\n")
+	b.WriteString("\tw.Header().Set(\"Retry-After\", retryAfter)\n")
+	b.WriteString("\tw.Header().Set(\"Content-Type\", \"application/json\")\n")
+	b.WriteString("\tw.WriteHeader(http.StatusServiceUnavailable)\n")
+	b.WriteString("\t_ = json.NewEncoder(w).Encode(map[string]string{\"error\": msg})\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// generateMetricsMiddleware returns metrics.go content for router. The series
+// it exports are the ones the rules of goca deploy slo are written against.
+func generateMetricsMiddleware(router string) string {
+	var b strings.Builder
+	b.WriteString("package middleware\n\n")
+	b.WriteString("import (\n")
+	b.WriteString("\t\"database/sql\"\n")
+	b.WriteString("\t\"net/http\"\n")
+	b.WriteString("\t\"strconv\"\n")
+	b.WriteString("\t\"time\"\n\n")
+	if router == RouterChi {
+		b.WriteString("\t\"github.com/go-chi/chi/v5\"\n")
+	} else {
+		b.WriteString("\t\"github.com/gorilla/mux\"\n")
+	}
+	b.WriteString("\t\"github.com/prometheus/client_golang/prometheus\"\n")
+	b.WriteString("\t\"github.com/prometheus/client_golang/prometheus/collectors\"\n")
+	b.WriteString("\t\"github.com/prometheus/client_golang/prometheus/promauto\"\n")
+	b.WriteString("\t\"github.com/prometheus/client_golang/prometheus/promhttp\"\n")
+	b.WriteString(")\n\n")
+	b.WriteString("// unmatchedRoute labels the requests that matched no route, so unknown\n")
+	b.WriteString("// paths share one series.\n")
+	b.WriteString("const unmatchedRoute = \"unmatched\"\n\n")
+	b.WriteString("var (\n")
+	b.WriteString("\thttpRequests = promauto.NewCounterVec(prometheus.CounterOpts{\n")
+	b.WriteString("\t\tName: \"http_requests_total\",\n")
+	b.WriteString("\t\tHelp: \"HTTP requests by method, route template and status code.\",\n")
+	b.WriteString("\t}, []string{\"method\", \"route\", \"code\"})\n\n")
+	b.WriteString("\thttpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{\n")
+	b.WriteString("\t\tName:    \"http_request_duration_seconds\",\n")
+	b.WriteString("\t\tHelp:    \"HTTP request durations by method and route template.\",\n")
+	b.WriteString("\t\tBuckets: prometheus.DefBuckets,\n")
+	b.WriteString("\t}, []string{\"method\", \"route\"})\n")
+	b.WriteString(")\n\n")
+	b.WriteString("// metricsWriter wraps http.ResponseWriter to capture the status code.\n")
+	b.WriteString("type metricsWriter struct {\n")
+	b.WriteString("\thttp.ResponseWriter\n")
+	b.WriteString("\tstatusCode int\n")
+	b.WriteString("}\n\n")
+	b.WriteString("func (mw *metricsWriter) WriteHeader(code int) {\n")
+	b.WriteString("\tmw.statusCode = code\n")
+	b.WriteString("\tmw.ResponseWriter.WriteHeader(code)\n")
+	b.WriteString("}\n\n")
+	if router == RouterChi {
+		b.WriteString("// Metrics returns middleware that counts requests and observes their\n")
+		b.WriteString("// durations, labelled with the chi route pattern, e.g. /users/{id}, rather\n")
+		b.WriteString("// than the path so IDs do not multiply the series. Register it with\n")
+		b.WriteString("// router.Use before the routes.\n")
+	} else {
+		b.WriteString("// Metrics returns middleware that counts requests and observes their\n")
+		b.WriteString("// durations, labelled with the mux route template, e.g. /users/{id}, rather\n")
+		b.WriteString("// than the path so IDs do not multiply the series. Register it with\n")
+		b.WriteString("// router.Use.\n")
+	}
+	b.WriteString("func Metrics() Middleware {\n")
+	b.WriteString("\treturn func(next http.Handler) http.Handler {\n")
+	b.WriteString("\t\treturn http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {\n")
+	b.WriteString("\t\t\tstart := time.Now()\n")
+	b.WriteString("\t\t\tmw := &metricsWriter{ResponseWriter: w, statusCode: http.StatusOK}\n")
+	b.WriteString("\t\t\tnext.ServeHTTP(mw, r)\n\n")
+	b.WriteString("\t\t\troute := routeTemplate(r)\n")
+	b.WriteString("\t\t\thttpRequests.WithLabelValues(r.Method, route, strconv.Itoa(mw.statusCode)).Inc()\n")
+	b.WriteString("\t\t\thttpDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())\n")
+	b.WriteString("\t\t})\n")
+	b.WriteString("\t}\n")
+	b.WriteString("}\n\n")
+	if router == RouterChi {
+		b.WriteString("// routeTemplate returns the pattern of the chi route r matched, joined\n")
+		b.WriteString("// across subrouters. It is complete once the route has served r.\n")
+		b.WriteString("func routeTemplate(r *http.Request) string {\n")
+		b.WriteString("\tif rctx := chi.RouteContext(r.Context()); rctx != nil {\n")
+		b.WriteString("\t\tif pattern := rctx.RoutePattern(); pattern != \"\" {\n")
+		b.WriteString("\t\t\treturn pattern\n")
+		b.WriteString("\t\t}\n")
+		b.WriteString("\t}\n")
+		b.WriteString("\treturn unmatchedRoute\n")
+		b.WriteString("}\n\n")
+	} else {
+		b.WriteString("// routeTemplate returns the path template of the mux route r matched,\n")
+		b.WriteString("// including the prefixes of its subrouters.\n")
+		b.WriteString("func routeTemplate(r *http.Request) string {\n")
+		b.WriteString("\tif current := mux.CurrentRoute(r); current != nil {\n")
+		b.WriteString("\t\tif template, err := current.GetPathTemplate(); err == nil {\n")
+		b.WriteString("\t\t\treturn template\n")
+		b.WriteString("\t\t}\n")
+		b.WriteString("\t}\n")
+		b.WriteString("\treturn unmatchedRoute\n")
+		b.WriteString("}\n\n")
+	}
+	b.WriteString("// MetricsHandler serves the collected metrics for Prometheus to scrape,\n")
+	b.WriteString("// e.g. router.Handle(\"/metrics\", middleware.MetricsHandler()).\n")
+	b.WriteString("func MetricsHandler() http.Handler {\n")
+	b.WriteString("\treturn promhttp.Handler()\n")
+	b.WriteString("}\n\n")
+	b.WriteString("// RegisterDBStats exports the connection pool statistics of db as the\n")
+	b.WriteString("// go_sql_* metrics, labelled db_name=name. With GORM, pass the *sql.DB of\n")
+	b.WriteString("// db.DB().\n")
+	b.WriteString("func RegisterDBStats(db *sql.DB, name string) error {\n")
+	b.WriteString("\treturn prometheus.Register(collectors.NewDBStatsCollector(db, name))\n")
+	b.WriteString("}\n\n")
+	b.WriteString("// RegisterQueueDepth exports the number of jobs waiting in a queue as\n")
+	b.WriteString("// worker_queue_depth{queue=queue}, read from depth at every scrape, e.g.\n")
+	b.WriteString("// RegisterQueueDepth(\"orders\", pool.Len).\n")
+	b.WriteString("func RegisterQueueDepth(queue string, depth func() int) error {\n")
+	b.WriteString("\treturn prometheus.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{\n")
+	b.WriteString("\t\tName:        \"worker_queue_depth\",\n")
+	b.WriteString("\t\tHelp:        \"Jobs waiting in the queue for a free worker.\",\n")
+	b.WriteString("\t\tConstLabels: prometheus.Labels{\"queue\": queue},\n")
+	b.WriteString("\t}, func() float64 { return float64(depth()) }))\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// generateMetricsMiddlewareTest returns metrics_test.go content for router,
+// asserting that requests are labelled with their route template.
+func generateMetricsMiddlewareTest(router string) string {
+	var b strings.Builder
+	b.WriteString("package middleware\n\n")
+	b.WriteString("import (\n")
+	b.WriteString("\t\"net/http\"\n")
+	b.WriteString("\t\"net/http/httptest\"\n")
+	b.WriteString("\t\"testing\"\n\n")
+	if router == RouterChi {
+		b.WriteString("\t\"github.com/go-chi/chi/v5\"\n")
+	} else {
+		b.WriteString("\t\"github.com/gorilla/mux\"\n")
+	}
+	b.WriteString("\t\"github.com/prometheus/client_golang/prometheus/testutil\"\n")
+	b.WriteString(")\n\n")
+	b.WriteString("// TestMetricsRouteLabel checks that requests are labelled with the route\n")
+	b.WriteString("// template, so every ID shares one series.\n")
+	b.WriteString("func TestMetricsRouteLabel(t *testing.T) {\n")
+	b.WriteString("\tuser := func(w http.ResponseWriter, r *http.Request) {\n")
+	if router == RouterChi {
+		b.WriteString("\t\tif chi.URLParam(r, \"id\") == \"0\" {\n")
+	} else {
+		b.WriteString("\t\tif mux.Vars(r)[\"id\"] == \"0\" {\n")
+	}
+	b.WriteString("\t\t\tw.WriteHeader(http.StatusNotFound)\n")
+	b.WriteString("\t\t}\n")
+	b.WriteString("\t}\n")
+	if router == RouterChi {
+		b.WriteString("\trouter := chi.NewRouter()\n")
+		b.WriteString("\trouter.Use(Metrics())\n")
+		b.WriteString("\trouter.Route(\"/metrics-test\", func(r chi.Router) {\n")
+		b.WriteString("\t\tr.Get(\"/users/{id}\", user)\n")
+		b.WriteString("\t})\n\n")
+	} else {
+		b.WriteString("\trouter := mux.NewRouter()\n")
+		b.WriteString("\trouter.Use(mux.MiddlewareFunc(Metrics()))\n")
+		b.WriteString("\tapi := router.PathPrefix(\"/metrics-test\").Subrouter()\n")
+		b.WriteString("\tapi.HandleFunc(\"/users/{id}\", user).Methods(http.MethodGet)\n\n")
+	}
+	b.WriteString("\tseries := testutil.CollectAndCount(httpRequests)\n")
+	b.WriteString("\tfor _, id := range []string{\"1\", \"2\", \"3\", \"0\"} {\n")
+	b.WriteString("\t\trouter.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, \"/metrics-test/users/\"+id, nil))\n")
+	b.WriteString("\t}\n\n")
+	b.WriteString("\tif got := testutil.CollectAndCount(httpRequests) - series; got != 2 {\n")
+	b.WriteString("\t\tt.Errorf(\"new series = %d, want 2: one per route template and status code\", got)\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\tif got := testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, \"/metrics-test/users/{id}\", \"200\")); got != 3 {\n")
+	b.WriteString("\t\tt.Errorf(\"GET /metrics-test/users/{id} 200 = %v, want 3\", got)\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\tif got := testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, \"/metrics-test/users/{id}\", \"404\")); got != 1 {\n")
+	b.WriteString("\t\tt.Errorf(\"GET /metrics-test/users/{id} 404 = %v, want 1\", got)\n")
+	b.WriteString("\t}\n")
+	b.WriteString("}\n\n")
+	b.WriteString("// TestMetricsUnmatchedRoute checks that requests outside the routes share\n")
+	b.WriteString("// one series.\n")
+	b.WriteString("func TestMetricsUnmatchedRoute(t *testing.T) {\n")
+	b.WriteString("\thandler := Metrics()(http.NotFoundHandler())\n")
+	b.WriteString("\tbefore := testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, unmatchedRoute, \"404\"))\n")
+	b.WriteString("\tfor _, path := range []string{\"/metrics-test/a\", \"/metrics-test/b\"} {\n")
+	b.WriteString("\t\thandler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))\n")
+	b.WriteString("\t}\n\n")
+	b.WriteString("\tif got := testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, unmatchedRoute, \"404\")) - before; got != 2 {\n")
+	b.WriteString("\t\tt.Errorf(\"GET unmatched 404 = %v, want 2\", got)\n")
+	b.WriteString("\t}\n")
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"go/format"
+	"go/parser"
+	"go/token"
 	"os"
 	"path/filepath"
 	"strings"
@@ -156,6 +159,61 @@ func TestGenerateTimeoutMiddleware(t *testing.T) {
 	assert.Contains(t, out, "context.WithTimeout")
 }
 
+func TestGenerateLoadShedMiddleware(t *testing.T) {
+	out := generateLoadShedMiddleware()
+	assert.Contains(t, out, "package middleware")
+	assert.Contains(t, out, "func LoadShed(cfg LoadShedConfig) Middleware")
+	assert.Contains(t, out, "func DefaultLoadShedConfig()")
+	assert.Contains(t, out, "if n := inFlight.Add(1); cfg.MaxInFlight > 0 && n > int64(cfg.MaxInFlight) {")
+	assert.Contains(t, out, "if cfg.Degraded != nil && cfg.Degraded() {")
+	assert.Contains(t, out, `w.Header().Set("Retry-After", retryAfter)`)
+	assert.Contains(t, out, "StatusServiceUnavailable")
+}
+
+func TestGenerateMetricsMiddleware(t *testing.T) {
+	out := generateMetricsMiddleware(RouterMux)
+	assert.Contains(t, out, "func Metrics() Middleware")
+	assert.Contains(t, out, `Name: "http_requests_total",`)
+	assert.Contains(t, out, `}, []string{"method", "route", "code"})`)
+	assert.Contains(t, out, "if template, err := current.GetPathTemplate(); err == nil {")
+	assert.Contains(t, out, "return promhttp.Handler()")
+	assert.Contains(t, out, "return prometheus.Register(collectors.NewDBStatsCollector(db, name))")
+	assert.Contains(t, out, `Name:        "worker_queue_depth",`)
+	assert.NotContains(t, out, "chi")
+
+	// chi fills in the route pattern while routing, so it is read after the
+	// request is served.
+	out = generateMetricsMiddleware(RouterChi)
+	assert.Contains(t, out, `"github.com/go-chi/chi/v5"`)
+	assert.Contains(t, out, "if pattern := rctx.RoutePattern(); pattern != \"\" {")
+	assert.Contains(t, out, "\t\t\tnext.ServeHTTP(mw, r)\n\n\t\t\troute := routeTemplate(r)\n")
+	assert.NotContains(t, out, "gorilla/mux")
+
+	for _, router := range ValidRouters {
+		for name, src := range map[string]string{
+			"metrics.go":      generateMetricsMiddleware(router),
+			"metrics_test.go": generateMetricsMiddlewareTest(router),
+		} {
+			_, err := parser.ParseFile(token.NewFileSet(), name, src, parser.AllErrors)
+			require.NoError(t, err, "%s for %s", name, router)
+			formatted, err := format.Source([]byte(src))
+			require.NoError(t, err)
+			assert.Equal(t, string(formatted), src, "%s for %s is not gofmt-ed", name, router)
+		}
+	}
+}
+
+func TestGenerateMetricsMiddlewareTest(t *testing.T) {
+	out := generateMetricsMiddlewareTest(RouterMux)
+	assert.Contains(t, out, "api := router.PathPrefix(\"/metrics-test\").Subrouter()")
+	assert.Contains(t, out, `httpRequests.WithLabelValues(http.MethodGet, "/metrics-test/users/{id}", "200")); got != 3 {`)
+	assert.Contains(t, out, "func TestMetricsUnmatchedRoute(t *testing.T) {")
+
+	out = generateMetricsMiddlewareTest(RouterChi)
+	assert.Contains(t, out, "router.Route(\"/metrics-test\", func(r chi.Router) {")
+	assert.Contains(t, out, `if chi.URLParam(r, "id") == "0" {`)
+}
+
 // ─── generateMiddlewarePackage ───────────────────────────────────────────────
 
 func TestGenerateMiddlewarePackage_DryRun(t *testing.T) {
@@ -169,7 +227,7 @@ func TestGenerateMiddlewarePackage_DryRun(t *testing.T) {
 	require.NoError(t, os.Chdir(tmpDir))
 	defer func() { _ = os.Chdir(origDir) }()
 
-	err := generateMiddlewarePackage("MyApp", []string{"cors", "logging", "recovery"}, sm)
+	err := generateMiddlewarePackage("MyApp", []string{"cors", "logging", "recovery"}, RouterMux, sm)
 	require.NoError(t, err)
 
 	pending := sm.GetPendingFiles()
@@ -197,12 +255,13 @@ func TestGenerateMiddlewarePackage_AllTypes_DryRun(t *testing.T) {
 	require.NoError(t, os.Chdir(tmpDir))
 	defer func() { _ = os.Chdir(origDir) }()
 
-	err := generateMiddlewarePackage("MyApp", validMiddlewareTypes, sm)
+	err := generateMiddlewarePackage("MyApp", validMiddlewareTypes, RouterMux, sm)
 	require.NoError(t, err)
 
 	pending := sm.GetPendingFiles()
-	// 8 files: middleware.go + 7 type files
-	assert.Len(t, pending, 8)
+	// 18 files: middleware.go + 11 type files + metrics_test.go + the 4
+	// observability files of metrics + sensitive_fields.go of access-log
+	assert.Len(t, pending, 18)
 }
 
 func TestGenerateMiddlewarePackage_RealFiles(t *testing.T) {
@@ -216,7 +275,7 @@ func TestGenerateMiddlewarePackage_RealFiles(t *testing.T) {
 	require.NoError(t, os.Chdir(tmpDir))
 	defer func() { _ = os.Chdir(origDir) }()
 
-	err := generateMiddlewarePackage("TestProj", []string{"cors", "timeout"}, sm)
+	err := generateMiddlewarePackage("TestProj", []string{"cors", "timeout"}, RouterMux, sm)
 	require.NoError(t, err)
 
 	middlewareDir := filepath.Join("internal", "middleware")
@@ -256,7 +315,7 @@ func TestGenerateMiddlewarePackage_UnknownType(t *testing.T) {
 	require.NoError(t, os.Chdir(tmpDir))
 	defer func() { _ = os.Chdir(origDir) }()
 
-	err := generateMiddlewarePackage("MyApp", []string{"nonexistent"}, sm)
+	err := generateMiddlewarePackage("MyApp", []string{"nonexistent"}, RouterMux, sm)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "nonexistent")
 }
@@ -267,13 +326,16 @@ func TestAllTemplatesCompileAsGoCode(t *testing.T) {
 	// Verify every template generator produces valid Go that at least
 	// starts with "package middleware".
 	generators := map[string]func() string{
-		"cors":       generateCORSMiddleware,
-		"logging":    generateLoggingMiddleware,
-		"auth":       generateAuthMiddleware,
-		"rate-limit": generateRateLimitMiddleware,
-		"recovery":   generateRecoveryMiddleware,
-		"request-id": generateRequestIDMiddleware,
-		"timeout":    generateTimeoutMiddleware,
+		"cors":        generateCORSMiddleware,
+		"logging":     generateLoggingMiddleware,
+		"auth":        generateAuthMiddleware,
+		"rate-limit":  generateRateLimitMiddleware,
+		"recovery":    generateRecoveryMiddleware,
+		"request-id":  generateRequestIDMiddleware,
+		"timeout":     generateTimeoutMiddleware,
+		"load-shed":   generateLoadShedMiddleware,
+		"metrics":     func() string { return generateMetricsMiddleware(RouterMux) },
+		"compression": generateCompressionMiddleware,
 	}
 	for name, gen := range generators {
 		t.Run(name, func(t *testing.T) {
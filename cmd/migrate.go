@@ -0,0 +1,458 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// migrateSnapshotPath is the default schema snapshot goca migrate squash
+// checks the squashed migrations against.
+const migrateSnapshotPath = ".goca/schema.sql"
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Maintain the project's schema and data migrations",
+	Long: `Maintains the golang-migrate files in the project's migrations directory
+(database.migrations.directory in .goca.yaml, migrations/ by default) and the
+Go data migrations in internal/datamigration.`,
+}
+
+var migrateSquashCmd = &cobra.Command{
+	Use:   "squash",
+	Short: "Collapse the migrations up to a version into a baseline",
+	Long: `Collapses every migration up to and including --to into a single baseline
+migration, <version>_baseline.up.sql and .down.sql, and removes the squashed
+files.
+
+The baseline keeps the version of the last squashed migration, so databases
+already migrated to that version or later skip it, while new databases run it
+instead of the whole history. Its up file holds the squashed up migrations in
+order; its down file holds their down migrations in reverse order.
+
+Before squashing, the tables and columns the squashed migrations create are
+checked against a schema snapshot: a schema dump of a database with those
+migrations applied, .goca/schema.sql by default. Record it with, e.g.:
+
+  pg_dump --schema-only "$DATABASE_URL" > .goca/schema.sql
+  mysqldump --no-data app > .goca/schema.sql
+  sqlite3 app.db .schema > .goca/schema.sql
+
+Squashing is refused when a table or column is missing from the snapshot,
+when a squashed migration has no down file, or when a squashed file is
+listed in generation.protected.
+
+Examples:
+  goca migrate squash --to 014
+  goca migrate squash --to 20260101120000 --snapshot db/schema.sql
+  goca migrate squash --to 014 --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		to, _ := cmd.Flags().GetString("to")
+		snapshotPath, _ := cmd.Flags().GetString("snapshot")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		backup, _ := cmd.Flags().GetBool("backup")
+
+		ci := NewConfigIntegration()
+		_ = ci.LoadConfigForProject()
+		if database := ci.GetDatabaseType(""); sqlDialect(database) < 0 {
+			return fmt.Errorf("goca migrate squash only supports SQL databases, not %s", database)
+		}
+
+		dir := projectMigrationsDir(ci)
+		migrations, err := readMigrations(dir)
+		if err != nil {
+			return err
+		}
+		squashed, err := selectSquashedMigrations(migrations, to)
+		if err != nil {
+			return err
+		}
+
+		raw, err := os.ReadFile(snapshotPath)
+		if err != nil {
+			return fmt.Errorf("no schema snapshot at %s: dump the schema of a database migrated to %s there (e.g. pg_dump --schema-only) or pass --snapshot", snapshotPath, squashed[len(squashed)-1].Version)
+		}
+		snapshot, err := parseSQLSchema(string(raw))
+		if err != nil {
+			return fmt.Errorf("reading schema snapshot %s: %w", snapshotPath, err)
+		}
+		up, down, err := readMigrationSources(squashed)
+		if err != nil {
+			return err
+		}
+		if err := checkSquashSnapshot(up, snapshot, snapshotPath); err != nil {
+			return err
+		}
+		for _, m := range squashed {
+			for _, path := range []string{m.Up, m.Down} {
+				if err := checkProtectedPath(path); err != nil {
+					return err
+				}
+			}
+		}
+
+		version := squashed[len(squashed)-1].Version
+		ui.Header("Goca Migrate Squash")
+		ui.Blank()
+		ui.KeyValue("Directory", dir)
+		ui.KeyValue("Baseline", version+"_baseline")
+		ui.KeyValue("Snapshot", snapshotPath)
+		ui.Blank()
+		var rows [][]string
+		for _, m := range squashed {
+			rows = append(rows, []string{m.Version, m.Name})
+		}
+		ui.Table([]string{"Version", "Squashed migration"}, rows)
+		ui.Blank()
+
+		base := filepath.Join(dir, version+"_baseline")
+		sm := NewSafetyManager(dryRun, true, false)
+		if err := sm.WriteFile(base+".up.sql", buildBaselineMigration(squashed, up, false)); err != nil {
+			return err
+		}
+		if err := sm.WriteFile(base+".down.sql", buildBaselineMigration(squashed, down, true)); err != nil {
+			return err
+		}
+		if dryRun {
+			sm.PrintSummary()
+			return nil
+		}
+
+		if err := removeSquashedMigrations(squashed, base, backup, sm); err != nil {
+			return err
+		}
+		ui.Blank()
+		ui.Success(fmt.Sprintf("Squashed %d migrations into %s", len(squashed), base+".up.sql"))
+		return nil
+	},
+}
+
+// sqlMigration is a golang-migrate migration: an up file and its down file.
+type sqlMigration struct {
+	Version string
+	Name    string
+	Up      string
+	Down    string
+}
+
+// migrationPairPattern matches the up and down files of golang-migrate
+// migrations.
+var migrationPairPattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// projectMigrationsDir returns the migrations directory of the project.
+func projectMigrationsDir(ci *ConfigIntegration) string {
+	if ci != nil && ci.config != nil && ci.config.Database.Migrations.Directory != "" {
+		return ci.config.Database.Migrations.Directory
+	}
+	return DirMigrations
+}
+
+// migrationVersioning returns the database.migrations.versioning of the
+// project: "timestamp", or "" for sequence numbers.
+func migrationVersioning(ci *ConfigIntegration) string {
+	if ci != nil && ci.config != nil {
+		return ci.config.Database.Migrations.Versioning
+	}
+	return ""
+}
+
+// readMigrations returns the migrations in dir ordered by version.
+func readMigrations(dir string) ([]sqlMigration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading migrations: %w", err)
+	}
+	byVersion := make(map[string]*sqlMigration)
+	for _, entry := range entries {
+		m := migrationPairPattern.FindStringSubmatch(entry.Name())
+		if m == nil || entry.IsDir() {
+			continue
+		}
+		migration := byVersion[m[1]]
+		if migration == nil {
+			migration = &sqlMigration{Version: m[1], Name: m[2]}
+			byVersion[m[1]] = migration
+		}
+		if migration.Name != m[2] {
+			return nil, fmt.Errorf("migrations %s_%s and %s_%s share version %s", m[1], migration.Name, m[1], m[2], m[1])
+		}
+		path := filepath.Join(dir, entry.Name())
+		if m[3] == "up" {
+			migration.Up = path
+		} else {
+			migration.Down = path
+		}
+	}
+
+	migrations := make([]sqlMigration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrationVersionNumber(migrations[i].Version) < migrationVersionNumber(migrations[j].Version)
+	})
+	return migrations, nil
+}
+
+// migrationVersionNumber returns the numeric value of a migration version, so
+// 9 and 010 compare like golang-migrate compares them.
+func migrationVersionNumber(version string) uint64 {
+	n, _ := strconv.ParseUint(version, 10, 64)
+	return n
+}
+
+// selectSquashedMigrations returns the migrations up to and including the
+// version to, which must be the version of one of them.
+func selectSquashedMigrations(migrations []sqlMigration, to string) ([]sqlMigration, error) {
+	target, err := strconv.ParseUint(to, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("--to must be a migration version such as 014, got %q", to)
+	}
+
+	var squashed []sqlMigration
+	found := false
+	for _, m := range migrations {
+		n := migrationVersionNumber(m.Version)
+		if n > target {
+			break
+		}
+		found = found || n == target
+		squashed = append(squashed, m)
+	}
+	if !found {
+		return nil, fmt.Errorf("no migration with version %s", to)
+	}
+	if len(squashed) < 2 {
+		return nil, fmt.Errorf("nothing to squash: %s is the first migration", to)
+	}
+	for _, m := range squashed {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %s_%s needs both an up and a down file to be squashed", m.Version, m.Name)
+		}
+	}
+	return squashed, nil
+}
+
+// readMigrationSources returns the up and down SQL of migrations.
+func readMigrationSources(migrations []sqlMigration) (up, down []string, err error) {
+	for _, m := range migrations {
+		upSQL, err := os.ReadFile(m.Up)
+		if err != nil {
+			return nil, nil, err
+		}
+		downSQL, err := os.ReadFile(m.Down)
+		if err != nil {
+			return nil, nil, err
+		}
+		up = append(up, string(upSQL))
+		down = append(down, string(downSQL))
+	}
+	return up, down, nil
+}
+
+// replayMigrations returns the tables and columns the up migrations leave
+// behind, applying CREATE TABLE, DROP TABLE and ALTER TABLE ... ADD/DROP
+// [COLUMN] and RENAME in order.
+func replayMigrations(ups []string) (*sqlSchema, error) {
+	schema := &sqlSchema{}
+	for _, ddl := range ups {
+		tokens, err := tokenizeSQL(ddl)
+		if err != nil {
+			return nil, err
+		}
+		for _, stmt := range splitSQLStatements(tokens) {
+			switch {
+			case sqlKeywordsAt(stmt, 0, "create") && sqlIndexOfKeyword(stmt, "table") >= 0 && sqlIndexOfKeyword(stmt, "table") <= 3:
+				table, err := parseCreateTable(stmt)
+				if err != nil {
+					return nil, err
+				}
+				if table != nil && schema.table(table.Name) == nil {
+					schema.Tables = append(schema.Tables, table)
+				}
+			case sqlKeywordsAt(stmt, 0, "drop", "table"):
+				pos := skipKeywords(stmt, 2, "if", "exists")
+				for _, part := range splitTopLevel(stmt[pos:]) {
+					name, _ := readQualifiedName(part, 0)
+					schema.dropTable(name)
+				}
+			case sqlKeywordsAt(stmt, 0, "alter", "table"):
+				replayAlterTable(schema, stmt)
+			}
+		}
+	}
+	return schema, nil
+}
+
+// replayAlterTable applies the column changes of an ALTER TABLE statement.
+func replayAlterTable(schema *sqlSchema, stmt []string) {
+	pos := skipKeywords(stmt, 2, "only", "if", "exists")
+	name, pos := readQualifiedName(stmt, pos)
+	table := schema.table(name)
+	if table == nil {
+		return
+	}
+
+	for _, action := range splitTopLevel(stmt[pos:]) {
+		switch {
+		case sqlKeywordsAt(action, 0, "rename", "to"):
+			table.Name, _ = readQualifiedName(action, 2)
+		case sqlKeywordsAt(action, 0, "rename"):
+			pos := skipKeywords(action, 1, "column")
+			if sqlKeywordsAt(action, pos+1, "to") && pos+2 < len(action) {
+				table.renameColumn(sqlIdent(action[pos]), sqlIdent(action[pos+2]))
+			}
+		case sqlKeywordsAt(action, 0, "drop"):
+			pos := skipKeywords(action, 1, "column", "if", "exists")
+			if pos < len(action) && !isSQLConstraintKeyword(action, 1) {
+				table.dropColumn(sqlIdent(action[pos]))
+			}
+		case sqlKeywordsAt(action, 0, "add"):
+			pos := skipKeywords(action, 1, "column", "if", "not", "exists")
+			if pos < len(action) && !isSQLConstraintKeyword(action, 1) {
+				parseColumnDefinition(table, action[pos:])
+			}
+		}
+	}
+}
+
+// isSQLConstraintKeyword reports whether the ADD or DROP action at pos
+// targets a constraint or index rather than a column.
+func isSQLConstraintKeyword(action []string, pos int) bool {
+	for _, kw := range []string{"constraint", "primary", "foreign", "unique", "check", "index", "key", "default"} {
+		if sqlKeywordsAt(action, pos, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// dropTable removes the table with the given name, ignoring case.
+func (s *sqlSchema) dropTable(name string) {
+	for i, t := range s.Tables {
+		if strings.EqualFold(t.Name, name) {
+			s.Tables = append(s.Tables[:i], s.Tables[i+1:]...)
+			return
+		}
+	}
+}
+
+// column returns the column with the given name, ignoring case.
+func (t *sqlTable) column(name string) *sqlColumn {
+	for i := range t.Columns {
+		if strings.EqualFold(t.Columns[i].Name, name) {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+// dropColumn removes the column with the given name, ignoring case.
+func (t *sqlTable) dropColumn(name string) {
+	for i, c := range t.Columns {
+		if strings.EqualFold(c.Name, name) {
+			t.Columns = append(t.Columns[:i], t.Columns[i+1:]...)
+			return
+		}
+	}
+}
+
+// renameColumn renames a column, ignoring case.
+func (t *sqlTable) renameColumn(from, to string) {
+	if c := t.column(from); c != nil {
+		c.Name = to
+	}
+}
+
+// checkSquashSnapshot verifies that every table and column the up migrations
+// create is in the snapshot, i.e. the snapshot was taken from a database with
+// the migrations applied.
+func checkSquashSnapshot(ups []string, snapshot *sqlSchema, snapshotPath string) error {
+	migrated, err := replayMigrations(ups)
+	if err != nil {
+		return fmt.Errorf("reading squashed migrations: %w", err)
+	}
+
+	var missing []string
+	for _, table := range migrated.Tables {
+		recorded := snapshot.table(table.Name)
+		if recorded == nil {
+			missing = append(missing, "table "+table.Name)
+			continue
+		}
+		for _, column := range table.Columns {
+			if recorded.column(column.Name) == nil {
+				missing = append(missing, "column "+table.Name+"."+column.Name)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("schema snapshot %s does not match the squashed migrations; missing %s. Apply the migrations and record the snapshot again, or squash to an earlier version", snapshotPath, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// buildBaselineMigration returns the up or down file of the baseline of
+// migrations. Down migrations are written in reverse order.
+func buildBaselineMigration(migrations []sqlMigration, sources []string, down bool) string {
+	first, last := migrations[0], migrations[len(migrations)-1]
+	var b strings.Builder
+	fmt.Fprintf(&b, "-- Baseline of migrations %s to %s, generated by goca migrate squash.\n", first.Version, last.Version)
+	if down {
+		b.WriteString("-- Reverts the baseline by running the squashed down migrations in reverse.\n")
+	} else {
+		fmt.Fprintf(&b, "-- Databases already at version %s or later skip it.\n", last.Version)
+	}
+
+	for i := range migrations {
+		j, suffix := i, ".up.sql"
+		if down {
+			j, suffix = len(migrations)-1-i, ".down.sql"
+		}
+		fmt.Fprintf(&b, "\n-- %s_%s%s\n", migrations[j].Version, migrations[j].Name, suffix)
+		if sql := strings.TrimSpace(sources[j]); sql != "" {
+			b.WriteString(sql)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// removeSquashedMigrations deletes the files of the squashed migrations,
+// except the baseline at base, backing them up first when backup is set.
+func removeSquashedMigrations(migrations []sqlMigration, base string, backup bool, sm *SafetyManager) error {
+	for _, m := range migrations {
+		for _, path := range []string{m.Up, m.Down} {
+			if path == base+".up.sql" || path == base+".down.sql" {
+				continue
+			}
+			if backup {
+				if err := sm.BackupFile(path); err != nil {
+					return fmt.Errorf("backing up %s: %w", path, err)
+				}
+			}
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("removing squashed migration: %w", err)
+			}
+			ui.Dim("   removed " + path)
+		}
+	}
+	return nil
+}
+
+func init() {
+	migrateSquashCmd.Flags().String("to", "", "Version of the last migration to squash (required)")
+	migrateSquashCmd.Flags().String("snapshot", migrateSnapshotPath, "Schema dump of a database with the squashed migrations applied")
+	migrateSquashCmd.Flags().Bool("dry-run", false, "Show the baseline that would be written without changing any file")
+	migrateSquashCmd.Flags().Bool("backup", false, "Back up the squashed migration files before removing them")
+	_ = migrateSquashCmd.MarkFlagRequired("to")
+
+	migrateCmd.AddCommand(migrateSquashCmd)
+}
@@ -0,0 +1,500 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// dataMigrationDir is the package holding the generated data migrations.
+var dataMigrationDir = filepath.Join(DirInternal, "datamigration")
+
+// dataMigrationNamePattern matches data migration names: PascalCase Go
+// identifiers such as AddDefaultTenant.
+var dataMigrationNamePattern = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+var migrateDataCmd = &cobra.Command{
+	Use:   "data <Name>",
+	Short: "Generate a batched Go data migration",
+	Long: `Generates a data migration: Go code that backfills or rewrites rows, which
+SQL schema migrations do not cover.
+
+  - internal/datamigration/<version>_<name>.go  The migration to fill in
+  - internal/datamigration/runner.go            Batching, progress, dry-run and
+                                                the data_migrations record
+  - cmd/datamigrate/main.go                     Opens the database and runs them
+
+Run the pending data migrations with:
+
+  go run ./cmd/datamigrate             # apply
+  go run ./cmd/datamigrate -dry-run    # run one batch of each and roll back
+
+A data migration runs after the schema migration that was the latest when it
+was generated. The runner reads the version of golang-migrate's
+schema_migrations table and stops at the first data migration whose schema
+migration has not been applied yet.
+
+Each batch runs in its own transaction and is repeated until it changes no
+rows, so a batch must only select rows it has not migrated yet. Interrupted
+migrations resume where they stopped.
+
+Examples:
+  goca migrate data AddDefaultTenant
+  goca migrate data NormalizeEmails --batch-size 1000`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		batchSize, _ := cmd.Flags().GetInt("batch-size")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if !dataMigrationNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid data migration name %q: use PascalCase letters and digits, e.g. AddDefaultTenant", name)
+		}
+		if batchSize < 1 {
+			return fmt.Errorf("--batch-size must be at least 1")
+		}
+
+		ci := NewConfigIntegration()
+		_ = ci.LoadConfigForProject()
+		database := ci.GetDatabaseType("")
+		if sqlDialect(database) < 0 {
+			return fmt.Errorf("goca migrate data only supports SQL databases, not %s", database)
+		}
+
+		migration := dataMigration{
+			Version:       nextMigrationVersion(dataMigrationDir, migrationVersioning(ci)),
+			Name:          name,
+			SchemaVersion: latestSchemaVersion(projectMigrationsDir(ci)),
+			BatchSize:     batchSize,
+		}
+
+		ui.Header("Goca Migrate Data")
+		ui.Blank()
+		ui.KeyValue("Migration", migration.Version+"_"+toSnakeCase(name))
+		if migration.SchemaVersion == "" {
+			ui.KeyValue("Runs after", "any schema migration")
+		} else {
+			ui.KeyValue("Runs after", "schema migration "+migration.SchemaVersion)
+		}
+		ui.KeyValue("Batch size", fmt.Sprint(batchSize))
+		ui.Blank()
+
+		sm := NewSafetyManager(dryRun, true, false)
+		if err := generateDataMigration(migration, database, sm); err != nil {
+			return err
+		}
+		if dryRun {
+			sm.PrintSummary()
+			return nil
+		}
+
+		ui.Blank()
+		ui.NextSteps([]string{
+			"Fill in the batch in " + migration.path(),
+			"Preview it with 'go run ./cmd/datamigrate -dry-run'",
+			"Apply it with 'go run ./cmd/datamigrate' after 'make migrate-up'",
+		})
+		return nil
+	},
+}
+
+// dataMigration is a data migration being generated.
+type dataMigration struct {
+	Version string
+	Name    string
+	// SchemaVersion is the schema migration it runs after, "" for none.
+	SchemaVersion string
+	BatchSize     int
+	// Table, Pending and Statement fill in the migration: the rows of Table
+	// matching the Pending condition are migrated by Statement, an UPDATE of
+	// at most ? rows. The migration is left to fill in when Statement is "".
+	Table     string
+	Pending   string
+	Statement string
+}
+
+// path returns the file of the migration.
+func (m dataMigration) path() string {
+	return filepath.Join(dataMigrationDir, m.Version+"_"+toSnakeCase(m.Name)+".go")
+}
+
+// latestSchemaVersion returns the version of the last schema migration in
+// dir, or "" when there is none.
+func latestSchemaVersion(dir string) string {
+	migrations, err := readMigrations(dir)
+	if err != nil || len(migrations) == 0 {
+		return ""
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// generateDataMigration writes the migration, the runner, regenerated on every
+// run, and the runner entrypoint, written once so it can be customized.
+func generateDataMigration(m dataMigration, database string, sm *SafetyManager) error {
+	if err := writeGoFile(m.path(), buildDataMigration(m), sm); err != nil {
+		return fmt.Errorf("writing data migration: %w", err)
+	}
+	if err := writeGoFile(filepath.Join(dataMigrationDir, "runner.go"), buildDataMigrationRunner(), sm); err != nil {
+		return fmt.Errorf("writing data migration runner: %w", err)
+	}
+
+	mainPath := filepath.Join("cmd", "datamigrate", "main.go")
+	if _, err := os.Stat(mainPath); err == nil {
+		return nil
+	}
+	content, ok := buildDataMigrateMain(getImportPath(getModuleName()), database, projectSQLiteDriver())
+	if !ok {
+		return fmt.Errorf("goca migrate data does not support %s", database)
+	}
+	if err := writeGoFile(mainPath, content, sm); err != nil {
+		return fmt.Errorf("writing data migration entrypoint: %w", err)
+	}
+	return nil
+}
+
+// buildDataMigration returns the file of a new data migration.
+func buildDataMigration(m dataMigration) string {
+	fn := strings.ToLower(m.Name[:1]) + m.Name[1:]
+	schemaVersion := "0"
+	if m.SchemaVersion != "" {
+		schemaVersion = strings.TrimLeft(m.SchemaVersion, "0")
+		if schemaVersion == "" {
+			schemaVersion = "0"
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("package datamigration\n\n")
+	b.WriteString("import (\n\t\"context\"\n\n\t\"gorm.io/gorm\"\n)\n\n")
+	b.WriteString("func init() {\n")
+	b.WriteString("\tregister(Migration{\n")
+	fmt.Fprintf(&b, "\t\tVersion:       %q,\n", m.Version)
+	fmt.Fprintf(&b, "\t\tName:          %q,\n", toSnakeCase(m.Name))
+	fmt.Fprintf(&b, "\t\tSchemaVersion: %s,\n", schemaVersion)
+	fmt.Fprintf(&b, "\t\tBatchSize:     %d,\n", m.BatchSize)
+	fmt.Fprintf(&b, "\t\tPending:       %sPending,\n", fn)
+	fmt.Fprintf(&b, "\t\tBatch:         %s,\n", fn)
+	b.WriteString("\t})\n")
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// %sPending counts the rows %s still has to migrate. It is only used\n", fn, fn)
+	b.WriteString("// to log progress; return -1 when counting is too expensive.\n")
+	fmt.Fprintf(&b, "func %sPending(ctx context.Context, db *gorm.DB) (int64, error) {\n", fn)
+	b.WriteString("\tvar pending int64\n")
+	if m.Statement != "" {
+		fmt.Fprintf(&b, "\terr := db.Table(%q).Where(%q).Count(&pending).Error\n", m.Table, m.Pending)
+		b.WriteString("\treturn pending, err\n")
+	} else {
+		b.WriteString("\t// TODO: count the rows to migrate, e.g.\n")
+		b.WriteString("\t// err := db.Table(\"users\").Where(\"tenant_id IS NULL\").Count(&pending).Error\n")
+		b.WriteString("\treturn pending, nil\n")
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// %s migrates up to limit rows and returns how many it changed. It is\n", fn)
+	b.WriteString("// called in a new transaction until it changes no rows, so it must only select\n")
+	b.WriteString("// rows it has not migrated yet.\n")
+	fmt.Fprintf(&b, "func %s(ctx context.Context, tx *gorm.DB, limit int) (int64, error) {\n", fn)
+	if m.Statement != "" {
+		fmt.Fprintf(&b, "\tresult := tx.Exec(`%s`, limit)\n", m.Statement)
+		b.WriteString("\treturn result.RowsAffected, result.Error\n")
+		b.WriteString("}\n")
+		return b.String()
+	}
+	b.WriteString("\t// TODO: migrate one batch, e.g.\n")
+	b.WriteString("\t// result := tx.Exec(`UPDATE users SET tenant_id = 1 WHERE id IN (\n")
+	b.WriteString("\t// \tSELECT id FROM users WHERE tenant_id IS NULL ORDER BY id LIMIT ?)`, limit)\n")
+	b.WriteString("\t// return result.RowsAffected, result.Error\n")
+	b.WriteString("\treturn 0, nil\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// buildDataMigrationRunner returns internal/datamigration/runner.go.
+func buildDataMigrationRunner() string {
+	return `// Code generated by goca migrate data. DO NOT EDIT.
+
+// Package datamigration backfills and rewrites data in batches, each data
+// migration after the schema migration it depends on.
+package datamigration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultBatchSize is used by migrations without a batch size.
+const defaultBatchSize = 500
+
+// Migration is a data migration.
+type Migration struct {
+	// Version orders the data migrations that run after the same schema
+	// migration.
+	Version string
+	Name    string
+	// SchemaVersion is the golang-migrate version the data migration runs
+	// after.
+	SchemaVersion uint64
+	// BatchSize is the most rows one call of Batch migrates.
+	BatchSize int
+	// Pending counts the rows still to migrate, or returns -1; it is only
+	// used to log progress.
+	Pending func(ctx context.Context, db *gorm.DB) (int64, error)
+	// Batch migrates up to limit rows and returns how many it changed. It is
+	// called in a new transaction until it changes no rows.
+	Batch func(ctx context.Context, tx *gorm.DB, limit int) (int64, error)
+}
+
+// Options configure a run.
+type Options struct {
+	// DryRun runs the first batch of every pending migration and rolls it
+	// back.
+	DryRun bool
+	// BatchSize, when positive, overrides the batch size of every migration.
+	BatchSize int
+}
+
+// Applied records a completed data migration.
+type Applied struct {
+	Version   string ` + "`gorm:\"primaryKey\"`" + `
+	Name      string
+	Rows      int64
+	AppliedAt time.Time
+}
+
+// TableName keeps the record next to golang-migrate's schema_migrations.
+func (Applied) TableName() string { return "data_migrations" }
+
+// errRollback rolls back the transaction of a dry-run batch.
+var errRollback = errors.New("dry run")
+
+var registry []Migration
+
+// register adds a data migration; every migration file calls it from init.
+func register(m Migration) {
+	registry = append(registry, m)
+}
+
+// Migrations returns the data migrations in the order they run: by schema
+// migration, then by version.
+func Migrations() []Migration {
+	sorted := append([]Migration(nil), registry...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].SchemaVersion != sorted[j].SchemaVersion {
+			return sorted[i].SchemaVersion < sorted[j].SchemaVersion
+		}
+		return sorted[i].Version < sorted[j].Version
+	})
+	return sorted
+}
+
+// Run applies the pending data migrations in order. It stops at the first
+// one whose schema migration has not been applied yet.
+func Run(ctx context.Context, db *gorm.DB, opts Options) error {
+	db = db.WithContext(ctx)
+	applied, err := appliedVersions(db, opts.DryRun)
+	if err != nil {
+		return err
+	}
+	schemaVersion, tracked, err := currentSchemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, m := range Migrations() {
+		if applied[m.Version] {
+			continue
+		}
+		pending++
+		if tracked && m.SchemaVersion > schemaVersion {
+			return fmt.Errorf("%s_%s runs after schema migration %d but the database is at %d; apply the schema migrations first", m.Version, m.Name, m.SchemaVersion, schemaVersion)
+		}
+		if err := runMigration(ctx, db, m, opts); err != nil {
+			return err
+		}
+	}
+	if pending == 0 {
+		log.Println("data migrations are up to date")
+	}
+	return nil
+}
+
+// appliedVersions returns the versions recorded in data_migrations, creating
+// the table unless dryRun is set.
+func appliedVersions(db *gorm.DB, dryRun bool) (map[string]bool, error) {
+	applied := make(map[string]bool)
+	if !db.Migrator().HasTable(&Applied{}) {
+		if dryRun {
+			return applied, nil
+		}
+		if err := db.AutoMigrate(&Applied{}); err != nil {
+			return nil, fmt.Errorf("creating data_migrations: %w", err)
+		}
+	}
+	var rows []Applied
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("reading data_migrations: %w", err)
+	}
+	for _, row := range rows {
+		applied[row.Version] = true
+	}
+	return applied, nil
+}
+
+// currentSchemaVersion returns the version golang-migrate recorded. tracked
+// is false for databases without schema_migrations, e.g. auto-migrated ones.
+func currentSchemaVersion(db *gorm.DB) (version uint64, tracked bool, err error) {
+	if !db.Migrator().HasTable("schema_migrations") {
+		return 0, false, nil
+	}
+	var state struct {
+		Version uint64
+		Dirty   bool
+	}
+	if err := db.Table("schema_migrations").Select("version, dirty").Limit(1).Scan(&state).Error; err != nil {
+		return 0, false, fmt.Errorf("reading schema_migrations: %w", err)
+	}
+	if state.Dirty {
+		return 0, false, fmt.Errorf("schema migration %d is dirty; fix it before migrating data", state.Version)
+	}
+	return state.Version, true, nil
+}
+
+// runMigration runs the batches of m until one changes no rows, then records
+// it. In a dry run only the first batch runs, and it is rolled back.
+func runMigration(ctx context.Context, db *gorm.DB, m Migration, opts Options) error {
+	label := m.Version + "_" + m.Name
+	size := m.BatchSize
+	if opts.BatchSize > 0 {
+		size = opts.BatchSize
+	}
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+
+	total := int64(-1)
+	if m.Pending != nil {
+		count, err := m.Pending(ctx, db)
+		if err != nil {
+			return fmt.Errorf("%s: counting pending rows: %w", label, err)
+		}
+		total = count
+	}
+	if total >= 0 {
+		log.Printf("%s: %d rows to migrate in batches of %d", label, total, size)
+	} else {
+		log.Printf("%s: migrating in batches of %d", label, size)
+	}
+
+	start := time.Now()
+	var done int64
+	for batch := 1; ; batch++ {
+		var changed int64
+		err := db.Transaction(func(tx *gorm.DB) error {
+			n, err := m.Batch(ctx, tx, size)
+			if err != nil {
+				return err
+			}
+			changed = n
+			if opts.DryRun {
+				return errRollback
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errRollback) {
+			return fmt.Errorf("%s: batch %d: %w", label, batch, err)
+		}
+		if opts.DryRun {
+			log.Printf("%s: the first batch would change %d rows (rolled back)", label, changed)
+			return nil
+		}
+		if changed == 0 {
+			break
+		}
+
+		done += changed
+		if total > 0 {
+			log.Printf("%s: batch %d changed %d rows (%d/%d, %d%%)", label, batch, changed, done, total, min(done*100/total, 100))
+		} else {
+			log.Printf("%s: batch %d changed %d rows (%d so far)", label, batch, changed, done)
+		}
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("%s: stopped after %d rows: %w", label, done, err)
+		}
+	}
+
+	if err := db.Create(&Applied{Version: m.Version, Name: m.Name, Rows: done, AppliedAt: time.Now()}).Error; err != nil {
+		return fmt.Errorf("%s: recording in data_migrations: %w", label, err)
+	}
+	log.Printf("%s: done, %d rows in %s", label, done, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+`
+}
+
+// buildDataMigrateMain returns cmd/datamigrate/main.go, or false when the
+// database has no generated GORM opener.
+func buildDataMigrateMain(importPath, database, sqliteDriver string) (string, bool) {
+	if sqlDialect(database) < 0 {
+		return "", false
+	}
+	std, external, openDatabase, ok := databaseOpener(database, sqliteDriver)
+	if !ok {
+		return "", false
+	}
+	imports := importLines(append([]string{"context", "flag", "fmt", "os", "os/signal", "syscall"}, std...), external)
+
+	return fmt.Sprintf(`package main
+
+import (
+%s
+	"%s/internal/datamigration"
+	"%s/pkg/config"
+)
+
+// main applies the pending data migrations of internal/datamigration. Run it
+// after the schema migrations; -dry-run runs one batch of each and rolls it
+// back.
+func main() {
+	dryRun := flag.Bool("dry-run", false, "run the first batch of every pending migration and roll it back")
+	batchSize := flag.Int("batch-size", 0, "override the batch size of every migration")
+	flag.Parse()
+
+	// An interrupted run stops after the current batch and resumes later.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg := config.Load()
+	db, err := openDatabase(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: connecting to database: %%v\n", err)
+		os.Exit(1)
+	}
+
+	opts := datamigration.Options{DryRun: *dryRun, BatchSize: *batchSize}
+	if err := datamigration.Run(ctx, db, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %%v\n", err)
+		os.Exit(1)
+	}
+}
+
+%s`, imports, importPath, importPath, openDatabase), true
+}
+
+func init() {
+	migrateDataCmd.Flags().Int("batch-size", 500, "Rows migrated per batch and transaction")
+	migrateDataCmd.Flags().Bool("dry-run", false, "Show the files that would be generated without writing them")
+
+	migrateCmd.AddCommand(migrateDataCmd)
+}
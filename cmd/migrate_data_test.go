@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildDataMigration(t *testing.T) {
+	content := buildDataMigration(dataMigration{Version: "002", Name: "AddDefaultTenant", SchemaVersion: "014", BatchSize: 250})
+
+	assert.Contains(t, content, `Version:       "002",`)
+	assert.Contains(t, content, `Name:          "add_default_tenant",`)
+	assert.Contains(t, content, "SchemaVersion: 14,")
+	assert.Contains(t, content, "BatchSize:     250,")
+	assert.Contains(t, content, "func addDefaultTenant(ctx context.Context, tx *gorm.DB, limit int) (int64, error) {")
+	assert.Contains(t, content, "func addDefaultTenantPending(ctx context.Context, db *gorm.DB) (int64, error) {")
+	_, err := parser.ParseFile(token.NewFileSet(), "migration.go", content, 0)
+	assert.NoError(t, err)
+
+	content = buildDataMigration(dataMigration{Version: "20260101120000", Name: "Backfill", BatchSize: 500})
+	assert.Contains(t, content, "SchemaVersion: 0,")
+}
+
+func TestBuildDataMigrationRunner(t *testing.T) {
+	content := buildDataMigrationRunner()
+	assert.Contains(t, content, `func (Applied) TableName() string { return "data_migrations" }`)
+	assert.Contains(t, content, `db.Migrator().HasTable("schema_migrations")`)
+	assert.Contains(t, content, "return errRollback")
+	_, err := parser.ParseFile(token.NewFileSet(), "runner.go", content, 0)
+	assert.NoError(t, err)
+}
+
+func TestBuildDataMigrateMain(t *testing.T) {
+	content, ok := buildDataMigrateMain("example.com/shop", DBMySQL, "")
+	require.True(t, ok)
+	assert.Contains(t, content, `"gorm.io/driver/mysql"`)
+	assert.Contains(t, content, `"example.com/shop/internal/datamigration"`)
+	assert.Contains(t, content, "datamigration.Run(ctx, db, opts)")
+	_, err := parser.ParseFile(token.NewFileSet(), "main.go", content, 0)
+	assert.NoError(t, err)
+
+	_, ok = buildDataMigrateMain("example.com/shop", DBMongoDB, "")
+	assert.False(t, ok)
+}
+
+func TestMigrateDataCommand(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	writeTestFile(t, ".", "migrations/001_create_users.up.sql", "CREATE TABLE users (id bigint);\n")
+	writeTestFile(t, ".", "migrations/003_add_tenant.up.sql", "ALTER TABLE users ADD COLUMN tenant_id bigint;\n")
+
+	require.NoError(t, migrateDataCmd.RunE(migrateDataCmd, []string{"AddDefaultTenant"}))
+	require.NoError(t, migrateDataCmd.RunE(migrateDataCmd, []string{"NormalizeEmails"}))
+
+	first, err := os.ReadFile(filepath.Join(dataMigrationDir, "001_add_default_tenant.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(first), "SchemaVersion: 3,")
+	assert.FileExists(t, filepath.Join(dataMigrationDir, "002_normalize_emails.go"))
+	assert.FileExists(t, filepath.Join(dataMigrationDir, "runner.go"))
+	assert.FileExists(t, filepath.Join("cmd", "datamigrate", "main.go"))
+
+	assert.ErrorContains(t, migrateDataCmd.RunE(migrateDataCmd, []string{"add-tenant"}), "invalid data migration name")
+}
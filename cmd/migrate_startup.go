@@ -0,0 +1,618 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// golangMigrateVersion pins the golang-migrate module the startup migrations
+// run with.
+const golangMigrateVersion = "v4.18.3"
+
+// schemaMigrationDir is the package applying the schema migrations when the
+// server starts.
+var schemaMigrationDir = filepath.Join(DirInternal, "schemamigration")
+
+var migrateStartupCmd = &cobra.Command{
+	Use:   "startup",
+	Short: "Apply pending migrations when the server starts",
+	Long: `Makes the server apply the pending golang-migrate migrations of the
+migrations directory before it serves, instead of leaving them to
+'make migrate-up':
+
+  - internal/schemamigration/schemamigration.go  Runs the migrations under a
+                                                  database lock
+  - cmd/server/main.go                           Calls it before connecting
+
+Instances started together take a database lock first, so only one of them
+migrates while the others wait for it: pg_try_advisory_lock on PostgreSQL,
+GET_LOCK on MySQL, MariaDB and TiDB, and sp_getapplock on SQL Server. SQLite
+databases are not shared between instances and take no lock.
+
+A failure - the database is unreachable, the lock is not acquired within
+lock_timeout, the database is dirty from an earlier failed migration or a
+migration fails - is logged and the server starts on the current schema.
+In strict mode the server exits with status 1 before serving instead.
+
+The defaults come from database.migrations in .goca.yaml:
+
+  database:
+    migrations:
+      directory: migrations
+      startup:
+        strict: true
+        lock_timeout: 1m
+
+and are overridden at runtime by MIGRATE_ON_STARTUP, MIGRATE_STRICT,
+MIGRATE_LOCK_TIMEOUT and MIGRATIONS_DIR.
+
+Examples:
+  goca migrate startup
+  goca migrate startup --force`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		force, _ := cmd.Flags().GetBool("force")
+
+		ci := NewConfigIntegration()
+		_ = ci.LoadConfigForProject()
+		database := ci.GetDatabaseType("")
+		if sqlDialect(database) < 0 {
+			return fmt.Errorf("goca migrate startup only supports SQL databases, not %s", database)
+		}
+		if database == DBOracle {
+			return fmt.Errorf("goca migrate startup does not support oracle: golang-migrate has no Oracle driver")
+		}
+		if database == DBSQLite && projectSQLiteDriver() == SQLiteDriverModernc {
+			return fmt.Errorf("goca migrate startup needs the mattn SQLite driver: golang-migrate's pure-Go SQLite driver registers the same database/sql name as the modernc one")
+		}
+
+		settings := resolveStartupMigrations(ci)
+		ui.Header("Goca Migrate Startup")
+		ui.Blank()
+		if settings.embedded {
+			ui.KeyValue("Migrations", settings.dir+" (embedded)")
+		} else {
+			ui.KeyValue("Migrations", settings.dir)
+		}
+		ui.KeyValue("Lock", settings.lockName)
+		ui.KeyValue("Lock timeout", settings.lockTimeout.String())
+		ui.KeyValue("Strict", fmt.Sprint(settings.strict))
+		ui.Blank()
+
+		sm := NewSafetyManager(dryRun, force, false)
+		if err := generateStartupMigrations(database, settings, sm); err != nil {
+			return err
+		}
+		if dryRun {
+			sm.PrintSummary()
+			return nil
+		}
+
+		projectRoot, _ := os.Getwd()
+		depMgr := NewDependencyManager(projectRoot, false)
+		for _, dep := range depMgr.GetRequiredDependenciesForFeature("", map[string]bool{"migrate": true}) {
+			if err := depMgr.AddDependency(dep); err != nil {
+				ui.Warning(fmt.Sprintf("Could not add dependency %s: %v", dep.Module, err))
+			}
+		}
+
+		ui.Blank()
+		steps := []string{"Start the server: pending migrations in " + settings.dir + " are applied first"}
+		if !settings.strict {
+			steps = append(steps, "Set MIGRATE_STRICT=true in production to stop on a failed migration")
+		}
+		steps = append(steps, "Keep runAutoMigrations in main.go free of entities the migrations create")
+		ui.NextSteps(steps)
+		return nil
+	},
+}
+
+// startupMigrationSettings are the defaults baked into the generated
+// internal/schemamigration package.
+type startupMigrationSettings struct {
+	dir         string
+	strict      bool
+	lockTimeout time.Duration
+	lockName    string
+	// embedded reads the migrations compiled into internal/assets unless
+	// MIGRATIONS_DIR names a directory.
+	embedded bool
+}
+
+// resolveStartupMigrations returns database.migrations.startup of the
+// project with the defaults filled in.
+func resolveStartupMigrations(ci *ConfigIntegration) startupMigrationSettings {
+	s := startupMigrationSettings{
+		dir:         projectMigrationsDir(ci),
+		lockTimeout: time.Minute,
+		lockName:    path.Base(getModuleName()) + ".schema_migrations",
+		embedded:    projectEmbedsAssets(),
+	}
+	if ci != nil && ci.config != nil {
+		startup := ci.config.Database.Migrations.Startup
+		s.strict = startup.Strict
+		if d, err := time.ParseDuration(startup.LockTimeout); err == nil && d > 0 {
+			s.lockTimeout = d
+		}
+	}
+	// GET_LOCK names are limited to 64 characters.
+	if len(s.lockName) > 64 {
+		s.lockName = s.lockName[len(s.lockName)-64:]
+	}
+	return s
+}
+
+// startupMigrationDialect returns the database/sql driver name, the
+// golang-migrate database driver and the locking code of the generated
+// package for a SQL database.
+func startupMigrationDialect(database string) (driverName, migrateDriver, lockCode string) {
+	switch sqlDialect(database) {
+	case 0:
+		return "pgx", "github.com/golang-migrate/migrate/v4/database/pgx/v5", schemaMigrationPostgresLock
+	case 1:
+		return "mysql", "github.com/golang-migrate/migrate/v4/database/mysql", schemaMigrationMySQLLock
+	case 3:
+		return "sqlserver", "github.com/golang-migrate/migrate/v4/database/sqlserver", schemaMigrationSQLServerLock
+	}
+	return "sqlite3", "github.com/golang-migrate/migrate/v4/database/sqlite3", schemaMigrationSQLiteLock
+}
+
+// generateStartupMigrations writes internal/schemamigration and calls it
+// from main.go. Like the other shared packages, an existing package is only
+// rewritten with --force.
+func generateStartupMigrations(database string, s startupMigrationSettings, sm *SafetyManager) error {
+	filename := filepath.Join(schemaMigrationDir, "schemamigration.go")
+	if _, err := os.Stat(filename); err != nil || sm.Force {
+		if err := writeGoFile(filename, buildSchemaMigration(database, getImportPath(getModuleName()), s), sm); err != nil {
+			return fmt.Errorf("writing startup migrations: %w", err)
+		}
+	}
+	wireStartupMigrationsIntoMainGo(sm)
+	return nil
+}
+
+// buildSchemaMigration returns internal/schemamigration/schemamigration.go
+// for a SQL database.
+func buildSchemaMigration(database, importPath string, s startupMigrationSettings) string {
+	driverName, migrateDriver, lockCode := startupMigrationDialect(database)
+	std := []string{"context", "database/sql", "errors", "fmt", "log", "os", "path/filepath", "strconv", "time"}
+	switch driverName {
+	case "pgx":
+		std = append(std, "hash/fnv")
+	case "mysql":
+		std = append(std, "strings")
+	}
+
+	var b strings.Builder
+	b.WriteString("// Package schemamigration applies the golang-migrate migrations of the\n")
+	b.WriteString("// project when the server starts, one instance at a time.\n")
+	b.WriteString("package schemamigration\n\nimport (\n")
+	b.WriteString(importLines(std, nil))
+	b.WriteString("\n")
+	// The third-party imports sorted by path, as gofmt keeps them.
+	external := map[string]string{
+		importPath + "/pkg/config":                         "",
+		"github.com/golang-migrate/migrate/v4":             "",
+		migrateDriver:                                      "migratedb ",
+		"github.com/golang-migrate/migrate/v4/source/file": "_ ",
+	}
+	if s.embedded {
+		external["github.com/golang-migrate/migrate/v4/database"] = ""
+		external["github.com/golang-migrate/migrate/v4/source/iofs"] = ""
+		external[importPath+"/internal/assets"] = ""
+	}
+	paths := make([]string, 0, len(external))
+	for p := range external {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		fmt.Fprintf(&b, "\t%s%q\n", external[p], p)
+	}
+	b.WriteString(")\n\n")
+	fmt.Fprintf(&b, "// driverName is the database/sql driver the migrations connect with.\nconst driverName = %q\n\n", driverName)
+	b.WriteString(schemaMigrationSettingsTemplate)
+	b.WriteString("// DefaultSettings returns the settings of database.migrations in .goca.yaml\n")
+	b.WriteString("// when the package was generated.\n")
+	b.WriteString("func DefaultSettings() Settings {\n")
+	b.WriteString("\treturn Settings{\n")
+	b.WriteString("\t\tEnabled:     true,\n")
+	fmt.Fprintf(&b, "\t\tStrict:      %t,\n", s.strict)
+	if s.embedded {
+		b.WriteString("\t\tDir:         \"\", // the migrations embedded in internal/assets\n")
+	} else {
+		fmt.Fprintf(&b, "\t\tDir:         %q,\n", filepath.ToSlash(s.dir))
+	}
+	fmt.Fprintf(&b, "\t\tLockTimeout: %s,\n", durationLiteral(s.lockTimeout))
+	fmt.Fprintf(&b, "\t\tLockName:    %q,\n", s.lockName)
+	b.WriteString("\t}\n")
+	b.WriteString("}\n\n")
+	if s.embedded {
+		b.WriteString(strings.Replace(schemaMigrationRunTemplate, schemaMigrationFileSource, schemaMigrationNewMigrate, 1))
+		b.WriteString(schemaMigrationEmbeddedSource)
+	} else {
+		b.WriteString(schemaMigrationRunTemplate)
+	}
+	b.WriteString(lockCode)
+	return b.String()
+}
+
+// wireStartupMigrationsIntoMainGo calls schemamigration.RunOnStartup in
+// main.go before the database connection. It is idempotent and leaves
+// main.go alone when the connection anchor is missing.
+func wireStartupMigrationsIntoMainGo(sm ...*SafetyManager) {
+	call := "schemamigration.RunOnStartup(context.Background(), cfg)"
+	mainPath, found := findMainGoPath()
+	if !found {
+		ui.Dim("   Call the startup migrations in main.go before serving: " + call)
+		return
+	}
+	defer lockSharedFile(mainPath)()
+
+	raw, err := os.ReadFile(mainPath)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not read main.go: %v", err))
+		return
+	}
+	content := string(raw)
+	if strings.Contains(content, "schemamigration.RunOnStartup(") {
+		return
+	}
+
+	anchor := "\t// Connect to database with retry\n"
+	if !strings.Contains(content, anchor) {
+		ui.Dim("   Call the startup migrations in main.go before serving: " + call)
+		return
+	}
+
+	content = ensureMainGoImport(content, getImportPath(getModuleName())+"/internal/schemamigration")
+	content = strings.Replace(content, anchor,
+		"\t// Apply the pending schema migrations before serving; in strict mode\n"+
+			"\t// a failed migration stops the server (goca migrate startup)\n"+
+			"\tif err := "+call+"; err != nil {\n"+
+			"\t\tlog.Fatalf(\"Startup migrations failed: %v\", err)\n"+
+			"\t}\n\n"+anchor, 1)
+
+	if err := writeGoFileMerged(mainPath, content, sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Could not call the startup migrations from main.go: %v", err))
+		return
+	}
+	ui.Dim("   Startup migrations called from main.go")
+}
+
+const schemaMigrationSettingsTemplate = `// Settings controls the migrations applied on startup.
+type Settings struct {
+	// Enabled applies the migrations on startup.
+	Enabled bool
+	// Strict makes RunOnStartup fail when the migrations fail, instead of
+	// letting the server start on the current schema.
+	Strict bool
+	// Dir is the directory of the migration files.
+	Dir string
+	// LockTimeout bounds the wait for the migrations of another instance.
+	LockTimeout time.Duration
+	// LockName identifies the lock the instances migrate under.
+	LockName string
+}
+
+// SettingsFromEnv returns DefaultSettings overridden by the
+// MIGRATE_ON_STARTUP, MIGRATE_STRICT, MIGRATE_LOCK_TIMEOUT and MIGRATIONS_DIR
+// environment variables.
+func SettingsFromEnv() Settings {
+	s := DefaultSettings()
+	if v, err := strconv.ParseBool(os.Getenv("MIGRATE_ON_STARTUP")); err == nil {
+		s.Enabled = v
+	}
+	if v, err := strconv.ParseBool(os.Getenv("MIGRATE_STRICT")); err == nil {
+		s.Strict = v
+	}
+	if d, err := time.ParseDuration(os.Getenv("MIGRATE_LOCK_TIMEOUT")); err == nil && d > 0 {
+		s.LockTimeout = d
+	}
+	if dir := os.Getenv("MIGRATIONS_DIR"); dir != "" {
+		s.Dir = dir
+	}
+	return s
+}
+
+`
+
+// schemaMigrationFileSource opens the migrations directory in
+// schemaMigrationRunTemplate; projects embedding their assets call
+// newMigrate of schemaMigrationEmbeddedSource instead.
+const schemaMigrationFileSource = `	m, err := migrate.NewWithDatabaseInstance("file://"+filepath.ToSlash(s.Dir), driverName, driver)
+	if err != nil {
+		driver.Close()
+		return fmt.Errorf("reading migrations from %s: %w", s.Dir, err)
+	}
+`
+
+const schemaMigrationNewMigrate = `	m, err := newMigrate(s.Dir, driver)
+	if err != nil {
+		driver.Close()
+		return err
+	}
+`
+
+const schemaMigrationEmbeddedSource = `// newMigrate reads the migrations of dir, or the ones compiled into the
+// binary when dir is empty.
+func newMigrate(dir string, driver database.Driver) (*migrate.Migrate, error) {
+	if dir != "" {
+		m, err := migrate.NewWithDatabaseInstance("file://"+filepath.ToSlash(dir), driverName, driver)
+		if err != nil {
+			return nil, fmt.Errorf("reading migrations from %s: %w", dir, err)
+		}
+		return m, nil
+	}
+	source, err := iofs.New(assets.Migrations(), ".")
+	if err != nil {
+		return nil, fmt.Errorf("reading embedded migrations: %w", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", source, driverName, driver)
+	if err != nil {
+		return nil, fmt.Errorf("reading embedded migrations: %w", err)
+	}
+	return m, nil
+}
+
+`
+
+const schemaMigrationRunTemplate = `// ErrLockTimeout reports that another instance held the migration lock for
+// longer than Settings.LockTimeout.
+var ErrLockTimeout = errors.New("migration lock not acquired")
+
+const (
+	// connectAttempts is how often the database is tried while it starts.
+	connectAttempts = 5
+	// lockPollInterval is the pause between two attempts to take the lock.
+	lockPollInterval = time.Second
+)
+
+// RunOnStartup applies the pending migrations with SettingsFromEnv.
+//
+// A failure - the database is unreachable, the lock is not acquired within
+// LockTimeout, the database is dirty from an earlier failed migration or a
+// migration fails - is returned in strict mode, and the server must not
+// serve. Otherwise it is logged and RunOnStartup returns nil, leaving the
+// server on the current schema.
+func RunOnStartup(ctx context.Context, cfg *config.Config) error {
+	s := SettingsFromEnv()
+	if !s.Enabled {
+		log.Println("Startup migrations disabled (MIGRATE_ON_STARTUP=false)")
+		return nil
+	}
+	err := Run(ctx, cfg.GetDatabaseURL(), s)
+	if err == nil || s.Strict {
+		return err
+	}
+	log.Printf("Warning: Startup migrations failed: %v", err)
+	log.Printf("Server will start on the current schema (set MIGRATE_STRICT=true to stop instead)")
+	return nil
+}
+
+// Run applies the pending migrations of s.Dir to the database at dsn. Only
+// one instance migrates at a time: the others wait for its lock, then find
+// nothing left to apply.
+func Run(ctx context.Context, dsn string, s Settings) error {
+	dsn = migrationDSN(dsn)
+	lock, err := acquireLock(ctx, dsn, s)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+	return up(dsn, s)
+}
+
+// up applies the pending migrations on a connection pool of its own, which
+// closing the migrate instance closes.
+func up(dsn string, s Settings) error {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	driver, err := migratedb.WithInstance(db, &migratedb.Config{})
+	if err != nil {
+		db.Close()
+		return fmt.Errorf("preparing migrations: %w", err)
+	}
+	m, err := migrate.NewWithDatabaseInstance("file://"+filepath.ToSlash(s.Dir), driverName, driver)
+	if err != nil {
+		driver.Close()
+		return fmt.Errorf("reading migrations from %s: %w", s.Dir, err)
+	}
+	defer m.Close()
+	m.LockTimeout = s.LockTimeout
+
+	var dirty migrate.ErrDirty
+	switch err := m.Up(); {
+	case errors.Is(err, migrate.ErrNoChange):
+	case errors.As(err, &dirty):
+		return fmt.Errorf("database is dirty at version %d: a migration failed part-way; repair the schema, then mark the last good version with 'migrate force'", dirty.Version)
+	case err != nil:
+		return fmt.Errorf("applying migrations: %w", err)
+	}
+
+	version, _, err := m.Version()
+	switch {
+	case errors.Is(err, migrate.ErrNilVersion):
+		log.Printf("No migrations in %s", s.Dir)
+	case err != nil:
+		return fmt.Errorf("reading schema version: %w", err)
+	default:
+		log.Printf("Database schema is at migration %d", version)
+	}
+	return nil
+}
+
+// migrationLock is the lock the instances sharing the database migrate
+// under. It belongs to the session of conn, so the database releases it when
+// the process dies mid-migration.
+type migrationLock struct {
+	db   *sql.DB
+	conn *sql.Conn
+	name string
+}
+
+// acquireLock connects to the database and takes the migration lock,
+// waiting up to s.LockTimeout for another instance to release it.
+func acquireLock(ctx context.Context, dsn string, s Settings) (*migrationLock, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	conn, err := connect(ctx, db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	lock := &migrationLock{db: db, conn: conn, name: s.LockName}
+
+	ctx, cancel := context.WithTimeout(ctx, s.LockTimeout)
+	defer cancel()
+	for waiting := false; ; waiting = true {
+		acquired, err := tryLock(ctx, conn, s.LockName)
+		if err != nil && ctx.Err() == nil {
+			lock.close()
+			return nil, fmt.Errorf("acquiring migration lock: %w", err)
+		}
+		if acquired {
+			return lock, nil
+		}
+		if !waiting {
+			log.Printf("Waiting up to %s for another instance to finish migrating", s.LockTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			lock.close()
+			return nil, fmt.Errorf("%w %q within %s", ErrLockTimeout, s.LockName, s.LockTimeout)
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// connect returns a connection of db, retrying while the database starts.
+func connect(ctx context.Context, db *sql.DB) (*sql.Conn, error) {
+	var err error
+	for i := 0; i < connectAttempts; i++ {
+		if i > 0 {
+			time.Sleep(time.Duration(i) * time.Second)
+		}
+		var conn *sql.Conn
+		if conn, err = db.Conn(ctx); err == nil {
+			if err = conn.PingContext(ctx); err == nil {
+				return conn, nil
+			}
+			conn.Close()
+		}
+		log.Printf("Attempt %d: Migrations cannot reach the database: %v", i+1, err)
+	}
+	return nil, fmt.Errorf("connecting to database: %w", err)
+}
+
+// release gives the lock back to the other instances.
+func (l *migrationLock) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := unlock(ctx, l.conn, l.name); err != nil {
+		log.Printf("Warning: releasing migration lock: %v", err)
+	}
+	l.close()
+}
+
+func (l *migrationLock) close() {
+	l.conn.Close()
+	l.db.Close()
+}
+
+`
+
+const schemaMigrationPostgresLock = `// tryLock takes the session advisory lock keyed by name without waiting.
+func tryLock(ctx context.Context, conn *sql.Conn, name string) (bool, error) {
+	var acquired bool
+	err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", lockKey(name)).Scan(&acquired)
+	return acquired, err
+}
+
+func unlock(ctx context.Context, conn *sql.Conn, name string) error {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockKey(name))
+	return err
+}
+
+// lockKey maps name to the 64-bit key of an advisory lock.
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+func migrationDSN(dsn string) string { return dsn }
+`
+
+const schemaMigrationMySQLLock = `// tryLock takes the named lock without waiting. GET_LOCK returns 1 when it
+// took the lock, 0 when another session holds it and NULL on error.
+func tryLock(ctx context.Context, conn *sql.Conn, name string) (bool, error) {
+	var acquired sql.NullInt64
+	err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", name).Scan(&acquired)
+	return acquired.Int64 == 1, err
+}
+
+func unlock(ctx context.Context, conn *sql.Conn, name string) error {
+	_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", name)
+	return err
+}
+
+// migrationDSN lets a migration file hold several statements.
+func migrationDSN(dsn string) string {
+	if strings.Contains(dsn, "?") {
+		return dsn + "&multiStatements=true"
+	}
+	return dsn + "?multiStatements=true"
+}
+`
+
+const schemaMigrationSQLServerLock = `// tryLock takes the session application lock named name without waiting.
+// sp_getapplock returns 0 or 1 when it took the lock and a negative code
+// otherwise.
+func tryLock(ctx context.Context, conn *sql.Conn, name string) (bool, error) {
+	var result int
+	err := conn.QueryRowContext(ctx, "DECLARE @result int; "+
+		"EXEC @result = sp_getapplock @Resource = @p1, @LockMode = 'Exclusive', @LockOwner = 'Session', @LockTimeout = 0; "+
+		"SELECT @result", name).Scan(&result)
+	return result >= 0, err
+}
+
+func unlock(ctx context.Context, conn *sql.Conn, name string) error {
+	_, err := conn.ExecContext(ctx, "EXEC sp_releaseapplock @Resource = @p1, @LockOwner = 'Session'", name)
+	return err
+}
+
+func migrationDSN(dsn string) string { return dsn }
+`
+
+const schemaMigrationSQLiteLock = `// tryLock always succeeds: SQLite has no advisory locks, and a SQLite
+// database is not shared between instances.
+func tryLock(ctx context.Context, conn *sql.Conn, name string) (bool, error) {
+	return true, nil
+}
+
+func unlock(ctx context.Context, conn *sql.Conn, name string) error {
+	return nil
+}
+
+func migrationDSN(dsn string) string { return dsn }
+`
+
+func init() {
+	migrateStartupCmd.Flags().Bool("dry-run", false, "Show the files that would be generated without writing them")
+	migrateStartupCmd.Flags().Bool("force", false, "Regenerate internal/schemamigration with the current settings")
+
+	migrateCmd.AddCommand(migrateStartupCmd)
+}
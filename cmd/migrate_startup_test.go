@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSchemaMigration(t *testing.T) {
+	s := startupMigrationSettings{dir: "db/migrations", strict: true, lockTimeout: 90 * time.Second, lockName: "shop.schema_migrations"}
+	tests := []struct {
+		database string
+		want     []string
+	}{
+		{DBPostgres, []string{`migratedb "github.com/golang-migrate/migrate/v4/database/pgx/v5"`, `const driverName = "pgx"`, "pg_try_advisory_lock($1)", `"hash/fnv"`}},
+		{DBMariaDB, []string{`migratedb "github.com/golang-migrate/migrate/v4/database/mysql"`, "GET_LOCK(?, 0)", `"?multiStatements=true"`}},
+		{DBSQLServer, []string{`migratedb "github.com/golang-migrate/migrate/v4/database/sqlserver"`, "sp_getapplock @Resource = @p1"}},
+		{DBSQLite, []string{`migratedb "github.com/golang-migrate/migrate/v4/database/sqlite3"`, "return true, nil"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.database, func(t *testing.T) {
+			content := buildSchemaMigration(tt.database, "example.com/shop", s)
+			formatted, err := format.Source([]byte(content))
+			require.NoError(t, err)
+			assert.Equal(t, string(formatted), content)
+
+			assert.Contains(t, content, `"example.com/shop/pkg/config"`)
+			assert.Contains(t, content, "Strict:      true,")
+			assert.Contains(t, content, `Dir:         "db/migrations",`)
+			assert.Contains(t, content, "LockTimeout: 90 * time.Second,")
+			assert.Contains(t, content, `LockName:    "shop.schema_migrations",`)
+			for _, want := range tt.want {
+				assert.Contains(t, content, want)
+			}
+		})
+	}
+}
+
+func TestBuildSchemaMigration_Embedded(t *testing.T) {
+	s := startupMigrationSettings{dir: "migrations", lockTimeout: time.Minute, lockName: "shop.schema_migrations", embedded: true}
+	content := buildSchemaMigration(DBPostgres, "example.com/shop", s)
+	formatted, err := format.Source([]byte(content))
+	require.NoError(t, err)
+	assert.Equal(t, string(formatted), content)
+
+	assert.Contains(t, content, `"example.com/shop/internal/assets"`)
+	assert.Contains(t, content, `"github.com/golang-migrate/migrate/v4/source/iofs"`)
+	assert.Contains(t, content, `Dir:         "", // the migrations embedded in internal/assets`)
+	assert.Contains(t, content, "m, err := newMigrate(s.Dir, driver)")
+	assert.Contains(t, content, `migrate.NewWithInstance("iofs", source, driverName, driver)`)
+	assert.Equal(t, 1, strings.Count(content, "migrate.NewWithDatabaseInstance("))
+}
+
+func TestResolveStartupMigrations(t *testing.T) {
+	chdirTemp(t)
+	writeTestFile(t, ".", "go.mod", "module example.com/"+strings.Repeat("x", 70)+"\n")
+	writeTestFile(t, ".", ".goca.yaml", "project:\n  name: shop\n  module: example.com/shop\ndatabase:\n  type: mysql\n  port: 3306\n  migrations:\n    directory: db/migrations\n    startup:\n      strict: true\n")
+
+	ci := NewConfigIntegration()
+	require.NoError(t, ci.LoadConfigForProject())
+	s := resolveStartupMigrations(ci)
+	assert.Equal(t, "db/migrations", s.dir)
+	assert.True(t, s.strict)
+	assert.Equal(t, time.Minute, s.lockTimeout)
+	assert.Len(t, s.lockName, 64)
+	assert.True(t, strings.HasSuffix(s.lockName, ".schema_migrations"))
+
+	cm := NewConfigManager()
+	cm.validateDatabase(&DatabaseConfig{Type: "mysql", Port: 3306, Connection: ConnectionConfig{MaxOpen: 1}, Migrations: MigrationConfig{Startup: StartupMigrationConfig{LockTimeout: "soon"}}})
+	require.Len(t, cm.GetErrors(), 1)
+	assert.Equal(t, "database.migrations.startup.lock_timeout", cm.GetErrors()[0].Field)
+}
+
+func TestMigrateStartupCommand(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	mainPath := filepath.Join("cmd", "server", "main.go")
+	writeTestFile(t, ".", mainPath, "package main\n\nimport (\n\t\"context\"\n\t\"log\"\n\n\t\"testproject/pkg/config\"\n)\n\nfunc main() {\n\tcfg := config.Load()\n\n\t// Connect to database with retry\n\t_ = cfg\n\t_ = context.Background()\n\tlog.Println()\n}\n")
+
+	sm := NewSafetyManager(false, false, false)
+	settings := resolveStartupMigrations(nil)
+	require.NoError(t, generateStartupMigrations(DBPostgres, settings, sm))
+	require.NoError(t, generateStartupMigrations(DBPostgres, settings, sm))
+
+	readParsedGo(t, filepath.Join(schemaMigrationDir, "schemamigration.go"))
+	main := readParsedGo(t, mainPath)
+	assert.Contains(t, main, `"testproject/internal/schemamigration"`)
+	assert.Equal(t, 1, strings.Count(main, "schemamigration.RunOnStartup(context.Background(), cfg)"))
+	assert.Less(t, strings.Index(main, "RunOnStartup"), strings.Index(main, "// Connect to database with retry"))
+}
+
+func TestMigrateStartupCommand_Unsupported(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+
+	writeTestFile(t, ".", ".goca.yaml", "project:\n  name: shop\n  module: testproject\ndatabase:\n  type: mongodb\n  port: 27017\n")
+	assert.ErrorContains(t, migrateStartupCmd.RunE(migrateStartupCmd, nil), "only supports SQL databases")
+
+	require.NoError(t, os.WriteFile(".goca.yaml", []byte("project:\n  name: shop\n  module: testproject\ndatabase:\n  type: sqlite\n  driver: modernc\n"), 0o644))
+	assert.ErrorContains(t, migrateStartupCmd.RunE(migrateStartupCmd, nil), "mattn SQLite driver")
+	assert.NoDirExists(t, schemaMigrationDir)
+}
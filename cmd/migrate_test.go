@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAndSelectSquashedMigrations(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "001_create_users.up.sql", "CREATE TABLE users (id bigserial PRIMARY KEY);\n")
+	writeTestFile(t, dir, "001_create_users.down.sql", "DROP TABLE users;\n")
+	writeTestFile(t, dir, "002_add_email.up.sql", "ALTER TABLE users ADD COLUMN email text;\n")
+	writeTestFile(t, dir, "002_add_email.down.sql", "ALTER TABLE users DROP COLUMN email;\n")
+	writeTestFile(t, dir, "010_add_name.up.sql", "ALTER TABLE users ADD COLUMN name text;\n")
+	writeTestFile(t, dir, "README.md", "not a migration\n")
+
+	migrations, err := readMigrations(dir)
+	require.NoError(t, err)
+	require.Len(t, migrations, 3)
+	assert.Equal(t, "001", migrations[0].Version)
+	assert.Equal(t, "add_email", migrations[1].Name)
+	assert.Equal(t, filepath.Join(dir, "002_add_email.down.sql"), migrations[1].Down)
+
+	squashed, err := selectSquashedMigrations(migrations, "2")
+	require.NoError(t, err)
+	assert.Len(t, squashed, 2)
+
+	_, err = selectSquashedMigrations(migrations, "003")
+	assert.ErrorContains(t, err, "no migration with version")
+	_, err = selectSquashedMigrations(migrations, "001")
+	assert.ErrorContains(t, err, "nothing to squash")
+	_, err = selectSquashedMigrations(migrations, "010")
+	assert.ErrorContains(t, err, "010_add_name needs both an up and a down file")
+	_, err = selectSquashedMigrations(migrations, "latest")
+	assert.ErrorContains(t, err, "--to must be a migration version")
+}
+
+func TestReplayMigrations(t *testing.T) {
+	schema, err := replayMigrations([]string{
+		"CREATE TABLE users (id bigserial PRIMARY KEY, nickname text);\nCREATE TABLE sessions (id bigint);",
+		"ALTER TABLE users ADD COLUMN email text NOT NULL DEFAULT '';\nALTER TABLE users DROP COLUMN nickname;",
+		"ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);\nALTER TABLE users RENAME COLUMN email TO mail;",
+		"DROP TABLE IF EXISTS sessions;\nALTER TABLE users RENAME TO accounts;",
+	})
+	require.NoError(t, err)
+	require.Len(t, schema.Tables, 1)
+
+	accounts := schema.table("accounts")
+	require.NotNil(t, accounts)
+	var columns []string
+	for _, c := range accounts.Columns {
+		columns = append(columns, c.Name)
+	}
+	assert.Equal(t, []string{"id", "mail"}, columns)
+	assert.True(t, accounts.column("mail").NotNull)
+}
+
+func TestCheckSquashSnapshot(t *testing.T) {
+	ups := []string{
+		"CREATE TABLE users (id bigserial PRIMARY KEY);",
+		"ALTER TABLE users ADD COLUMN email text;",
+	}
+
+	snapshot, err := parseSQLSchema("CREATE TABLE public.users (\n    id bigint NOT NULL,\n    email text,\n    name text\n);\n")
+	require.NoError(t, err)
+	assert.NoError(t, checkSquashSnapshot(ups, snapshot, "schema.sql"))
+
+	stale, err := parseSQLSchema("CREATE TABLE users (id bigint NOT NULL);")
+	require.NoError(t, err)
+	err = checkSquashSnapshot(append(ups, "CREATE TABLE orders (id bigint);"), stale, "schema.sql")
+	assert.ErrorContains(t, err, "missing column users.email, table orders")
+}
+
+func TestBuildBaselineMigration(t *testing.T) {
+	migrations := []sqlMigration{
+		{Version: "001", Name: "create_users"},
+		{Version: "002", Name: "add_email"},
+	}
+
+	up := buildBaselineMigration(migrations, []string{"CREATE TABLE users (id int);\n", "ALTER TABLE users ADD COLUMN email text;"}, false)
+	assert.Contains(t, up, "-- Baseline of migrations 001 to 002")
+	assert.Contains(t, up, "Databases already at version 002 or later skip it.")
+	assert.Less(t, strings.Index(up, "-- 001_create_users.up.sql"), strings.Index(up, "-- 002_add_email.up.sql"))
+
+	down := buildBaselineMigration(migrations, []string{"DROP TABLE users;", "ALTER TABLE users DROP COLUMN email;"}, true)
+	assert.Less(t, strings.Index(down, "-- 002_add_email.down.sql\nALTER TABLE users DROP COLUMN email;"), strings.Index(down, "-- 001_create_users.down.sql\nDROP TABLE users;"))
+}
+
+func TestMigrateSquashCommand(t *testing.T) {
+	defer ensureTestUI(t)()
+	dir := t.TempDir()
+	old, _ := os.Getwd()
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(old) })
+
+	writeTestFile(t, dir, "migrations/001_create_users.up.sql", "CREATE TABLE users (id bigserial PRIMARY KEY);\n")
+	writeTestFile(t, dir, "migrations/001_create_users.down.sql", "DROP TABLE users;\n")
+	writeTestFile(t, dir, "migrations/002_add_email.up.sql", "ALTER TABLE users ADD COLUMN email text;\n")
+	writeTestFile(t, dir, "migrations/002_add_email.down.sql", "ALTER TABLE users DROP COLUMN email;\n")
+	writeTestFile(t, dir, "migrations/003_add_name.up.sql", "ALTER TABLE users ADD COLUMN name text;\n")
+	writeTestFile(t, dir, "migrations/003_add_name.down.sql", "ALTER TABLE users DROP COLUMN name;\n")
+
+	run := func() error {
+		require.NoError(t, migrateSquashCmd.Flags().Set("to", "002"))
+		t.Cleanup(func() { _ = migrateSquashCmd.Flags().Set("to", "") })
+		return migrateSquashCmd.RunE(migrateSquashCmd, nil)
+	}
+
+	assert.ErrorContains(t, run(), "no schema snapshot at .goca/schema.sql")
+
+	writeTestFile(t, dir, ".goca/schema.sql", "CREATE TABLE users (id bigint NOT NULL, email text);\n")
+	require.NoError(t, run())
+
+	entries, err := os.ReadDir("migrations")
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{"002_baseline.down.sql", "002_baseline.up.sql", "003_add_name.down.sql", "003_add_name.up.sql"}, names)
+
+	up, err := os.ReadFile(filepath.Join("migrations", "002_baseline.up.sql"))
+	require.NoError(t, err)
+	assert.Contains(t, string(up), "CREATE TABLE users (id bigserial PRIMARY KEY);\n\n-- 002_add_email.up.sql\nALTER TABLE users ADD COLUMN email text;\n")
+}
@@ -95,6 +95,7 @@ func init() {
 
 // generateMocks generates mock files based on flags.
 func generateMocks(entityName string, all, repository, usecase, handler bool, sm ...*SafetyManager) error {
+	defer profileStep("mocks", entityName)()
 	// Create mocks directory
 	mocksDir := filepath.Join("internal", "mocks")
 	if err := os.MkdirAll(mocksDir, 0o755); err != nil {
@@ -103,19 +104,21 @@ func generateMocks(entityName string, all, repository, usecase, handler bool, sm
 
 	importPath := getImportPath(getModuleName())
 
-	// Recover the entity's fields so the generated mocks include the same
-	// per-field finder methods (FindBy<Field>) that the real repository
-	// interface declares.
-	var fields []Field
-	if fs := readEntityFieldsString(entityName); fs != "" {
-		fields = parseFields(fs)
+	// Recover the finders the real repository interface declares so the
+	// generated mock implements it; without an interface they are derived from
+	// the entity's fields.
+	finders, declared := declaredFinders(entityName)
+	if !declared {
+		if fs := readEntityFieldsString(entityName); fs != "" {
+			finders = generateRepositoryMethods(parseFields(fs), entityName)
+		}
 	}
 
 	// Generate repository mock
 	if all || repository {
 		mockFile := filepath.Join(mocksDir, fmt.Sprintf("mock_%s_repository.go", strings.ToLower(entityName)))
-		content := fixGeneratedModulePath(generateRepositoryMock(entityName, fields), importPath)
-		if err := writeFile(mockFile, content, sm...); err != nil {
+		content := fixGeneratedModulePath(generateRepositoryMock(entityName, finders), importPath)
+		if err := writeGoFile(mockFile, content, sm...); err != nil {
 			return err
 		}
 	}
@@ -124,7 +127,7 @@ func generateMocks(entityName string, all, repository, usecase, handler bool, sm
 	if all || usecase {
 		mockFile := filepath.Join(mocksDir, fmt.Sprintf("mock_%s_usecase.go", strings.ToLower(entityName)))
 		content := fixGeneratedModulePath(generateUseCaseMock(entityName), importPath)
-		if err := writeFile(mockFile, content, sm...); err != nil {
+		if err := writeGoFile(mockFile, content, sm...); err != nil {
 			return err
 		}
 	}
@@ -133,7 +136,7 @@ func generateMocks(entityName string, all, repository, usecase, handler bool, sm
 	if all || handler {
 		mockFile := filepath.Join(mocksDir, fmt.Sprintf("mock_%s_handler.go", strings.ToLower(entityName)))
 		content := fixGeneratedModulePath(generateHandlerMock(entityName), importPath)
-		if err := writeFile(mockFile, content, sm...); err != nil {
+		if err := writeGoFile(mockFile, content, sm...); err != nil {
 			return err
 		}
 	}
@@ -147,7 +150,7 @@ func generateMocks(entityName string, all, repository, usecase, handler bool, sm
 
 		exampleFile := filepath.Join(examplesDir, fmt.Sprintf("%s_mock_examples_test.go", strings.ToLower(entityName)))
 		exampleContent := fixGeneratedModulePath(generateMockUsageExamples(entityName), importPath)
-		if err := writeFile(exampleFile, exampleContent, sm...); err != nil {
+		if err := writeGoFile(exampleFile, exampleContent, sm...); err != nil {
 			return err
 		}
 	}
@@ -156,17 +159,19 @@ func generateMocks(entityName string, all, repository, usecase, handler bool, sm
 }
 
 // generateRepositoryMock generates a mock that satisfies repository.<Entity>Repository.
-// The generated finder methods (FindBy<Field>) mirror those produced for the real
-// repository interface by generateSearchMethods, so the mock implements the
+// finders are the finder and aggregate methods (FindBy<Field>, SumBy<Field>,
+// ...) the real repository interface declares, so the mock implements the
 // interface exactly.
-func generateRepositoryMock(entityName string, fields []Field) string {
+func generateRepositoryMock(entityName string, finders []SearchMethod) string {
 	lowerEntity := strings.ToLower(entityName)
 
 	var b strings.Builder
 	b.WriteString("package mocks\n\n")
 	b.WriteString("import (\n")
+	b.WriteString("\t\"context\"\n\n")
 	b.WriteString("\t\"github.com/stretchr/testify/mock\"\n")
 	b.WriteString("\t\"github.com/sazardev/goca/internal/domain\"\n")
+	b.WriteString("\t\"github.com/sazardev/goca/internal/repository\"\n")
 	b.WriteString(")\n\n")
 
 	fmt.Fprintf(&b, "// Mock%sRepository is a mock implementation of repository.%sRepository\n", entityName, entityName)
@@ -179,18 +184,17 @@ func generateRepositoryMock(entityName string, fields []Field) string {
 
 	// FindByID
 	fmt.Fprintf(&b, "// FindByID mocks the FindByID method\n")
-	fmt.Fprintf(&b, "func (m *Mock%sRepository) FindByID(id int) (*domain.%s, error) {\n", entityName, entityName)
+	fmt.Fprintf(&b, "func (m *Mock%sRepository) FindByID(id %s) (*domain.%s, error) {\n", entityName, idParamType(entityName), entityName)
 	fmt.Fprintf(&b, "\targs := m.Called(id)\n\tif args.Get(0) == nil {\n\t\treturn nil, args.Error(1)\n\t}\n")
 	fmt.Fprintf(&b, "\treturn args.Get(0).(*domain.%s), args.Error(1)\n}\n\n", entityName)
 
-	// Per-field finders, matching generateSearchMethods.
-	for _, method := range generateSearchMethods(fields, entityName) {
-		paramName := strings.ToLower(method.FieldName)
-		fmt.Fprintf(&b, "// %s mocks the %s method\n", method.MethodName, method.MethodName)
-		fmt.Fprintf(&b, "func (m *Mock%sRepository) %s(%s %s) (*domain.%s, error) {\n",
-			entityName, method.MethodName, paramName, method.FieldType, entityName)
-		fmt.Fprintf(&b, "\targs := m.Called(%s)\n\tif args.Get(0) == nil {\n\t\treturn nil, args.Error(1)\n\t}\n", paramName)
-		fmt.Fprintf(&b, "\treturn args.Get(0).(*domain.%s), args.Error(1)\n}\n\n", entityName)
+	// ExistsByID
+	fmt.Fprintf(&b, "// ExistsByID mocks the ExistsByID method\n")
+	fmt.Fprintf(&b, "func (m *Mock%sRepository) ExistsByID(ctx context.Context, id %s) (bool, error) {\n", entityName, idParamType(entityName))
+	b.WriteString("\targs := m.Called(ctx, id)\n\treturn args.Bool(0), args.Error(1)\n}\n\n")
+
+	for _, method := range finders {
+		writeMockFinder(&b, entityName, method)
 	}
 
 	// Update
@@ -200,7 +204,7 @@ func generateRepositoryMock(entityName string, fields []Field) string {
 
 	// Delete
 	fmt.Fprintf(&b, "// Delete mocks the Delete method\n")
-	fmt.Fprintf(&b, "func (m *Mock%sRepository) Delete(id int) error {\n", entityName)
+	fmt.Fprintf(&b, "func (m *Mock%sRepository) Delete(id %s) error {\n", entityName, idParamType(entityName))
 	fmt.Fprintf(&b, "\targs := m.Called(id)\n\treturn args.Error(0)\n}\n\n")
 
 	// FindAll
@@ -209,6 +213,23 @@ func generateRepositoryMock(entityName string, fields []Field) string {
 	fmt.Fprintf(&b, "\targs := m.Called()\n\tif args.Get(0) == nil {\n\t\treturn nil, args.Error(1)\n\t}\n")
 	fmt.Fprintf(&b, "\treturn args.Get(0).([]domain.%s), args.Error(1)\n}\n\n", entityName)
 
+	// FindAllPaged
+	fmt.Fprintf(&b, "// FindAllPaged mocks the FindAllPaged method\n")
+	fmt.Fprintf(&b, "func (m *Mock%sRepository) FindAllPaged(ctx context.Context, limit, offset int) ([]domain.%s, int64, error) {\n", entityName, entityName)
+	fmt.Fprintf(&b, "\targs := m.Called(ctx, limit, offset)\n\tif args.Get(0) == nil {\n\t\treturn nil, args.Get(1).(int64), args.Error(2)\n\t}\n")
+	fmt.Fprintf(&b, "\treturn args.Get(0).([]domain.%s), args.Get(1).(int64), args.Error(2)\n}\n\n", entityName)
+
+	// Count
+	fmt.Fprintf(&b, "// Count mocks the Count method\n")
+	fmt.Fprintf(&b, "func (m *Mock%sRepository) Count(ctx context.Context, spec repository.%sSpec) (int64, error) {\n", entityName, entityName)
+	b.WriteString("\targs := m.Called(ctx, spec)\n\treturn args.Get(0).(int64), args.Error(1)\n}\n\n")
+
+	// WithContext, on multi-tenant entities: the mock ignores tenants
+	if isMultiTenantEntity(entityName) {
+		fmt.Fprintf(&b, "// WithContext returns the mock itself; it does not scope by tenant\n")
+		fmt.Fprintf(&b, "func (m *Mock%sRepository) WithContext(ctx context.Context) repository.%sRepository {\n\treturn m\n}\n\n", entityName, entityName)
+	}
+
 	fmt.Fprintf(&b, "// NewMock%sRepository creates a new mock repository\n", entityName)
 	fmt.Fprintf(&b, "func NewMock%sRepository() *Mock%sRepository {\n\treturn &Mock%sRepository{}\n}\n",
 		entityName, entityName, entityName)
@@ -216,6 +237,29 @@ func generateRepositoryMock(entityName string, fields []Field) string {
 	return b.String()
 }
 
+// writeMockFinder writes the Mock<Entity>Repository method for one finder.
+func writeMockFinder(b *strings.Builder, entityName string, method SearchMethod) {
+	if method.Spec != "" {
+		method.Spec = "repository." + method.Spec
+	}
+	fmt.Fprintf(b, "// %s mocks the %s method\n", method.MethodName, method.MethodName)
+	fmt.Fprintf(b, "func (m *Mock%sRepository) %s(%s) %s {\n",
+		entityName, method.MethodName, method.params(), method.ReturnType)
+	fmt.Fprintf(b, "\targs := m.Called(%s)\n", method.args())
+	switch method.Kind {
+	case FinderExists:
+		b.WriteString("\treturn args.Bool(0), args.Error(1)\n}\n\n")
+	case AggregateSum, AggregateMax:
+		b.WriteString("\treturn args.Get(0).(float64), args.Error(1)\n}\n\n")
+	case FinderAll, SearchCriteria:
+		b.WriteString("\tif args.Get(0) == nil {\n\t\treturn nil, args.Error(1)\n\t}\n")
+		fmt.Fprintf(b, "\treturn args.Get(0).([]domain.%s), args.Error(1)\n}\n\n", entityName)
+	default:
+		b.WriteString("\tif args.Get(0) == nil {\n\t\treturn nil, args.Error(1)\n\t}\n")
+		fmt.Fprintf(b, "\treturn args.Get(0).(*domain.%s), args.Error(1)\n}\n\n", entityName)
+	}
+}
+
 // generateUseCaseMock generates a mock that satisfies usecase.<Entity>UseCase
 // exactly: Create<Entity>, Get<Entity>, Update<Entity>, Delete<Entity> and
 // List<Entity>s, with the same signatures the real interface declares.
@@ -240,25 +284,25 @@ func generateUseCaseMock(entityName string) string {
 
 	// Get<Entity>(id int) (*domain.<Entity>, error)
 	fmt.Fprintf(&b, "// Get%s mocks the Get%s method\n", entityName, entityName)
-	fmt.Fprintf(&b, "func (m *Mock%sUseCase) Get%s(id int) (*domain.%s, error) {\n", entityName, entityName, entityName)
+	fmt.Fprintf(&b, "func (m *Mock%sUseCase) Get%s(id %s) (*domain.%s, error) {\n", entityName, entityName, idParamType(entityName), entityName)
 	fmt.Fprintf(&b, "\targs := m.Called(id)\n\tif args.Get(0) == nil {\n\t\treturn nil, args.Error(1)\n\t}\n")
 	fmt.Fprintf(&b, "\treturn args.Get(0).(*domain.%s), args.Error(1)\n}\n\n", entityName)
 
 	// Update<Entity>(id int, input Update<Entity>Input) error
 	fmt.Fprintf(&b, "// Update%s mocks the Update%s method\n", entityName, entityName)
-	fmt.Fprintf(&b, "func (m *Mock%sUseCase) Update%s(id int, input usecase.Update%sInput) error {\n",
-		entityName, entityName, entityName)
+	fmt.Fprintf(&b, "func (m *Mock%sUseCase) Update%s(id %s, input usecase.Update%sInput) error {\n",
+		entityName, entityName, idParamType(entityName), entityName)
 	fmt.Fprintf(&b, "\targs := m.Called(id, input)\n\treturn args.Error(0)\n}\n\n")
 
 	// Delete<Entity>(id int) error
 	fmt.Fprintf(&b, "// Delete%s mocks the Delete%s method\n", entityName, entityName)
-	fmt.Fprintf(&b, "func (m *Mock%sUseCase) Delete%s(id int) error {\n", entityName, entityName)
+	fmt.Fprintf(&b, "func (m *Mock%sUseCase) Delete%s(id %s) error {\n", entityName, entityName, idParamType(entityName))
 	fmt.Fprintf(&b, "\targs := m.Called(id)\n\treturn args.Error(0)\n}\n\n")
 
 	// List<Entity>s() (List<Entity>Output, error)
-	fmt.Fprintf(&b, "// List%ss mocks the List%ss method\n", entityName, entityName)
-	fmt.Fprintf(&b, "func (m *Mock%sUseCase) List%ss() (usecase.List%sOutput, error) {\n",
-		entityName, entityName, entityName)
+	fmt.Fprintf(&b, "// List%s mocks the List%s method\n", toPlural(entityName), toPlural(entityName))
+	fmt.Fprintf(&b, "func (m *Mock%sUseCase) List%s() (usecase.List%sOutput, error) {\n",
+		entityName, toPlural(entityName), entityName)
 	fmt.Fprintf(&b, "\targs := m.Called()\n")
 	fmt.Fprintf(&b, "\treturn args.Get(0).(usecase.List%sOutput), args.Error(1)\n}\n\n", entityName)
 
@@ -304,8 +348,8 @@ func (m *Mock%sHandler) Delete%s(w http.ResponseWriter, r *http.Request) {
 	m.Called(w, r)
 }
 
-// List%ss mocks the List%ss HTTP handler method
-func (m *Mock%sHandler) List%ss(w http.ResponseWriter, r *http.Request) {
+// List%s mocks the List%s HTTP handler method
+func (m *Mock%sHandler) List%s(w http.ResponseWriter, r *http.Request) {
 	m.Called(w, r)
 }
 
@@ -319,13 +363,18 @@ func NewMock%sHandler() *Mock%sHandler {
 		entityName, entityName, entityName, entityName,
 		entityName, entityName, entityName, entityName,
 		entityName, entityName, entityName, entityName,
-		entityName, entityName, entityName, entityName,
+		toPlural(entityName), toPlural(entityName), entityName, toPlural(entityName),
 		entityName, entityName, entityName, entityName,
 	)
 } // generateMockUsageExamples generates example test files showing how to use mocks
 
 func generateMockUsageExamples(entityName string) string {
 	lowerEntity := strings.ToLower(entityName)
+	// The IDs the examples stub and look up, ObjectID hex strings for string IDs.
+	id, missing := "1", "999"
+	if idParamType(entityName) == "string" {
+		id, missing = `"64b7f0c2a1e4d3b2c1a09f8e"`, `"64b7f0c2a1e4d3b2c1a09f8f"`
+	}
 
 	return fmt.Sprintf(`package examples
 
@@ -351,11 +400,11 @@ var (
 func TestMock%[1]sRepository_Usage(t *testing.T) {
 	mockRepo := mocks.NewMock%[1]sRepository()
 
-	expected := &domain.%[1]s{ID: 1}
-	mockRepo.On("FindByID", 1).Return(expected, nil)
+	expected := &domain.%[1]s{ID: %[3]s}
+	mockRepo.On("FindByID", %[3]s).Return(expected, nil)
 	mockRepo.On("Save", mock.AnythingOfType("*domain.%[1]s")).Return(nil)
 
-	got, err := mockRepo.FindByID(1)
+	got, err := mockRepo.FindByID(%[3]s)
 	assert.NoError(t, err)
 	assert.Equal(t, expected, got)
 
@@ -370,9 +419,9 @@ func TestMock%[1]sRepository_NotFound(t *testing.T) {
 	mockRepo := mocks.NewMock%[1]sRepository()
 
 	expectedErr := errors.New("%[2]s not found")
-	mockRepo.On("FindByID", 999).Return(nil, expectedErr)
+	mockRepo.On("FindByID", %[4]s).Return(nil, expectedErr)
 
-	got, err := mockRepo.FindByID(999)
+	got, err := mockRepo.FindByID(%[4]s)
 	assert.Nil(t, got)
 	assert.Equal(t, expectedErr, err)
 
@@ -383,17 +432,17 @@ func TestMock%[1]sRepository_NotFound(t *testing.T) {
 func TestMock%[1]sUseCase_Usage(t *testing.T) {
 	mockUC := mocks.NewMock%[1]sUseCase()
 
-	mockUC.On("Get%[1]s", 1).Return(&domain.%[1]s{ID: 1}, nil)
-	mockUC.On("Delete%[1]s", 1).Return(nil)
+	mockUC.On("Get%[1]s", %[3]s).Return(&domain.%[1]s{ID: %[3]s}, nil)
+	mockUC.On("Delete%[1]s", %[3]s).Return(nil)
 
-	got, err := mockUC.Get%[1]s(1)
+	got, err := mockUC.Get%[1]s(%[3]s)
 	assert.NoError(t, err)
 	assert.NotNil(t, got)
 
-	err = mockUC.Delete%[1]s(1)
+	err = mockUC.Delete%[1]s(%[3]s)
 	assert.NoError(t, err)
 
 	mockUC.AssertExpectations(t)
 }
-`, entityName, lowerEntity)
+`, entityName, lowerEntity, id, missing)
 }
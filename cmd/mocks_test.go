@@ -9,7 +9,7 @@ import (
 func TestGenerateRepositoryMock(t *testing.T) {
 	t.Parallel()
 	fields := parseFields("name:string,email:string,age:int")
-	result := generateRepositoryMock("Product", fields)
+	result := generateRepositoryMock("Product", generateSearchMethods(fields, "Product"))
 	assert.Contains(t, result, "MockProductRepository")
 	assert.Contains(t, result, "mock.Mock")
 	assert.Contains(t, result, "func (m *MockProductRepository) Save(")
@@ -18,8 +18,9 @@ func TestGenerateRepositoryMock(t *testing.T) {
 	assert.Contains(t, result, "func (m *MockProductRepository) Delete(id int)")
 	assert.Contains(t, result, "func (m *MockProductRepository) FindAll(")
 	// Per-field finders matching the real repository interface.
-	assert.Contains(t, result, "func (m *MockProductRepository) FindByName(name string) (*domain.Product, error)")
 	assert.Contains(t, result, "func (m *MockProductRepository) FindByEmail(email string) (*domain.Product, error)")
+	assert.NotContains(t, result, "FindByName")
+	assert.NotContains(t, result, "FindByAge")
 	assert.NotContains(t, result, "TODO")
 	assert.Contains(t, result, "NewMockProductRepository")
 	assert.Contains(t, result, "domain.Product")
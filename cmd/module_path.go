@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// findModuleRoot walks up from dir to the nearest directory containing a
+// go.mod, mirroring how the go command locates the main module. The nearest
+// go.mod wins, so nested modules resolve to themselves rather than to the
+// repository's root module.
+func findModuleRoot(dir string) (string, bool) {
+	dir = filepath.Clean(dir)
+	for {
+		if info, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil && !info.IsDir() {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// parseModulePath returns the path declared by the module directive of a
+// go.mod file, or "" when there is none. Comments, quoted paths and the
+// parenthesised form are accepted; replace directives never affect the result
+// since they only redirect where dependencies are loaded from.
+func parseModulePath(data []byte) string {
+	inBlock := false
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case inBlock:
+			if line == ")" {
+				inBlock = false
+			} else if line != "" {
+				return unquoteModulePath(line)
+			}
+		case line == "module (" || line == "module(":
+			inBlock = true
+		case strings.HasPrefix(line, "module ") || strings.HasPrefix(line, "module\t"):
+			return unquoteModulePath(strings.TrimSpace(line[len("module"):]))
+		}
+	}
+	return ""
+}
+
+// unquoteModulePath strips the optional "..." or `...` quoting of a module path.
+func unquoteModulePath(path string) string {
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		return unquoted
+	}
+	return path
+}
+
+// moduleImportPath returns the import path of the package directory dir: the
+// module path of the nearest go.mod joined with dir's location below the
+// module root. A project generated in a subdirectory of a repository whose
+// go.mod sits at the root therefore imports <module>/<subdir>/internal/...
+func moduleImportPath(dir string) (string, bool) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	root, ok := findModuleRoot(absDir)
+	if !ok {
+		return "", false
+	}
+	data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", false
+	}
+	modulePath := parseModulePath(data)
+	if modulePath == "" {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(root, absDir)
+	if err != nil || rel == "." {
+		return modulePath, true
+	}
+	return modulePath + "/" + filepath.ToSlash(rel), true
+}
+
+// isOwnImport reports whether importPath belongs to modulePath. A plain prefix
+// check would also match a sibling such as example.com/apiclient for
+// example.com/api.
+func isOwnImport(importPath, modulePath string) bool {
+	return importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/")
+}
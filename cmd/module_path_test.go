@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseModulePath(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		goMod    string
+		expected string
+	}{
+		{"plain", "module github.com/acme/api\n\ngo 1.21\n", "github.com/acme/api"},
+		{"vanity domain", "module go.acme.dev/api\n", "go.acme.dev/api"},
+		{"major version", "module go.acme.dev/api/v2\n", "go.acme.dev/api/v2"},
+		{"no domain", "module myapp\n", "myapp"},
+		{"quoted", "module \"go.acme.dev/api\"\n", "go.acme.dev/api"},
+		{"backquoted", "module `go.acme.dev/api`\n", "go.acme.dev/api"},
+		{"trailing comment", "module go.acme.dev/api // vanity import\n", "go.acme.dev/api"},
+		{"commented out first", "// module example.com/old\nmodule example.com/new\n", "example.com/new"},
+		{"block form", "module (\n\tgo.acme.dev/api\n)\n", "go.acme.dev/api"},
+		{
+			"replace directives",
+			"module example.com/mono/services/api\n\nrequire example.com/mono v0.0.0\n\n" +
+				"replace example.com/mono => ../..\n\nreplace (\n\texample.com/mono/shared => ../shared\n)\n",
+			"example.com/mono/services/api",
+		},
+		{"self replace", "replace example.com/api => ./\nmodule example.com/api\n", "example.com/api"},
+		{"missing", "go 1.21\n", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.expected, parseModulePath([]byte(tc.goMod)))
+		})
+	}
+}
+
+func TestModuleImportPath(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	write("go.mod", "module go.acme.dev/mono\n\ngo 1.21\n")
+	write("services/billing/go.mod", "module go.acme.dev/billing/v2\n\nreplace go.acme.dev/mono => ../..\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "services", "api"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "services", "billing", "cmd"), 0o755))
+
+	cases := []struct {
+		name     string
+		dir      string
+		expected string
+	}{
+		{"module root", root, "go.acme.dev/mono"},
+		{"subdirectory of root module", filepath.Join(root, "services", "api"), "go.acme.dev/mono/services/api"},
+		{"nested module", filepath.Join(root, "services", "billing"), "go.acme.dev/billing/v2"},
+		{"subdirectory of nested module", filepath.Join(root, "services", "billing", "cmd"), "go.acme.dev/billing/v2/cmd"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := moduleImportPath(tc.dir)
+			require.True(t, ok)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+
+	_, ok := moduleImportPath(t.TempDir())
+	assert.False(t, ok, "a directory outside any module has no import path")
+}
+
+func TestGetModuleName_Subdirectory(t *testing.T) {
+	// Not parallel: os.Chdir
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module go.acme.dev/mono\n"), 0o644))
+	projectDir := filepath.Join(root, "apps", "shop")
+	require.NoError(t, os.MkdirAll(projectDir, 0o755))
+
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	require.NoError(t, os.Chdir(projectDir))
+
+	assert.Equal(t, "go.acme.dev/mono/apps/shop", getModuleName())
+	assert.Equal(t, "go.acme.dev/mono/apps/shop", getImportPath(getModuleName()))
+
+	require.NoError(t, os.Chdir(t.TempDir()))
+	assert.Equal(t, "myproject", getModuleName())
+}
+
+func TestIsOwnImport(t *testing.T) {
+	t.Parallel()
+	assert.True(t, isOwnImport("example.com/api", "example.com/api"))
+	assert.True(t, isOwnImport("example.com/api/internal/domain", "example.com/api"))
+	assert.False(t, isOwnImport("example.com/apiclient", "example.com/api"))
+	assert.False(t, isOwnImport("github.com/other/api", "example.com/api"))
+}
+
+func TestNewDependencyManager_UsesEnclosingModule(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module go.acme.dev/mono\n"), 0o644))
+	projectDir := filepath.Join(root, "apps", "shop")
+	require.NoError(t, os.MkdirAll(projectDir, 0o755))
+
+	dm := NewDependencyManager(projectDir, true)
+	assert.Equal(t, filepath.Join(root, "go.mod"), dm.goModPath)
+}
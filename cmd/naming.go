@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/jinzhu/inflection"
+)
+
+// Table naming conventions of architecture.naming.tables.
+const (
+	TableNamingPlural   = "plural"
+	TableNamingSingular = "singular"
+)
+
+// JSON naming conventions of architecture.naming.json.
+const (
+	JSONNamingSnake = "snake_case"
+	JSONNamingCamel = "camelCase"
+)
+
+// JSONModifier overrides the json name of a --fields field:
+// firstName:string:json=firstName.
+const JSONModifier = "json="
+
+// sqlIdentifierPattern matches the table names accepted by --table.
+var sqlIdentifierPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
+
+// jsonNamePattern matches the names accepted by the json= modifier.
+var jsonNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.-]*$`)
+
+// projectNaming returns architecture.naming from the current project's
+// configuration.
+func projectNaming() NamingConfig {
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	return ci.GetArchitectureConfig().Naming
+}
+
+// gormTableName returns the table GORM's default naming strategy derives from
+// an entity: its snake_case name, pluralized. Category -> categories.
+func gormTableName(entity string) string {
+	return inflection.Plural(gormColumnName(entity))
+}
+
+// defaultTableName returns the table of an entity without a TableName
+// method: its architecture.naming.table_names entry, or the name GORM
+// derives from it, kept singular when architecture.naming.tables is singular.
+func defaultTableName(entity string, naming NamingConfig) string {
+	if table := naming.TableNames[entity]; table != "" {
+		return table
+	}
+	if naming.Tables == TableNamingSingular {
+		return gormColumnName(entity)
+	}
+	return gormTableName(entity)
+}
+
+// configuredTableName returns the table an entity is mapped to by --table or
+// the project's naming configuration, or "" when GORM derives the same name
+// and no TableName method is needed.
+func configuredTableName(entity, flag string) string {
+	table := flag
+	if table == "" {
+		table = defaultTableName(entity, projectNaming())
+	}
+	if table == gormTableName(entity) {
+		return ""
+	}
+	return table
+}
+
+// validateTableName checks a --table value.
+func validateTableName(table string) error {
+	if table != "" && !sqlIdentifierPattern.MatchString(table) {
+		return fmt.Errorf("invalid table name %q; use letters, digits and '_', optionally prefixed by a schema", table)
+	}
+	return nil
+}
+
+// nameWords splits a field name into lowercase words at '_', '-' and spaces
+// and at case changes: firstName, first_name and FirstName all give first,
+// name, and HTTPServer gives http, server.
+func nameWords(name string) []string {
+	var words []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, strings.ToLower(string(cur)))
+			cur = cur[:0]
+		}
+	}
+	runes := []rune(name)
+	for i, r := range runes {
+		if r == '_' || r == '-' || r == ' ' {
+			flush()
+			continue
+		}
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return words
+}
+
+// fieldJSONName returns the json name of a field for the given
+// architecture.naming.json convention: first_name, or firstName with
+// camelCase.
+func fieldJSONName(name, convention string) string {
+	words := nameWords(name)
+	if convention != JSONNamingCamel {
+		return strings.Join(words, "_")
+	}
+	for i := 1; i < len(words); i++ {
+		words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+	}
+	return strings.Join(words, "")
+}
+
+// parseJSONModifier parses a json= modifier of a field definition, reporting
+// whether modifier is one.
+func parseJSONModifier(fieldName, modifier string) (string, bool, error) {
+	value, ok := strings.CutPrefix(strings.TrimSpace(modifier), JSONModifier)
+	if !ok {
+		return "", false, nil
+	}
+	if !jsonNamePattern.MatchString(value) {
+		return "", true, fmt.Errorf("invalid json name %q on field %s", value, fieldName)
+	}
+	return value, true, nil
+}
+
+// generateEntityTableName writes the TableName method of an entity mapped to
+// a table GORM does not derive from it: the --table value, or else the
+// project's naming configuration unless the entity already has a TableName
+// method.
+func generateEntityTableName(entity, flag string, sm ...*SafetyManager) {
+	if flag == "" && declaredTableName(entity) != "" {
+		return
+	}
+	table := configuredTableName(entity, flag)
+	if table == "" {
+		return
+	}
+	if err := generateTableNameMethod(entity, table, sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Could not write TableName for %s: %v", entity, err))
+	}
+}
+
+// fieldColumn returns the column of an entity field: the column of its gorm
+// tag, or the name GORM derives from the field.
+func fieldColumn(field Field) string {
+	if column := gormTagOptions(field.Tag)["column"]; column != "" {
+		return column
+	}
+	return gormColumnName(field.Name)
+}
+
+// jsonModifier returns the json= modifier recreating the json name of an
+// entity field, or "" when it is the one derived from the field's name.
+func jsonModifier(name, tag, convention string) string {
+	jsonName := jsonTagName(tag)
+	if jsonName == "" || jsonName == "-" || jsonName == fieldJSONName(name, convention) {
+		return ""
+	}
+	return ":" + JSONModifier + jsonName
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeNamingConfig writes a .goca.yaml with the given architecture.naming
+// option.
+func writeNamingConfig(t *testing.T, option string) {
+	t.Helper()
+	config := "project:\n  name: shop\n  module: example.com/shop\ndatabase:\n  type: postgres\n  port: 5432\n" +
+		"architecture:\n  naming:\n    " + option + "\n"
+	require.NoError(t, os.WriteFile(".goca.yaml", []byte(config), 0o644))
+}
+
+func TestDefaultTableName(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		entity string
+		naming NamingConfig
+		want   string
+	}{
+		{"Product", NamingConfig{}, "products"},
+		{"Category", NamingConfig{}, "categories"},
+		{"Person", NamingConfig{}, "people"},
+		{"APIKey", NamingConfig{}, "api_keys"},
+		{"OrderItem", NamingConfig{Tables: TableNamingSingular}, "order_item"},
+		{"Person", NamingConfig{TableNames: map[string]string{"Person": "staff"}}, "staff"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.want, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, defaultTableName(tc.entity, tc.naming))
+		})
+	}
+}
+
+func TestFieldJSONName(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name, snake, camel string
+	}{
+		{"name", "name", "name"},
+		{"firstName", "first_name", "firstName"},
+		{"First_name", "first_name", "firstName"},
+		{"UserID", "user_id", "userId"},
+		{"HTTPServer", "http_server", "httpServer"},
+		{"zip-code", "zip_code", "zipCode"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.snake, fieldJSONName(tc.name, JSONNamingSnake))
+			assert.Equal(t, tc.camel, fieldJSONName(tc.name, JSONNamingCamel))
+		})
+	}
+}
+
+func TestParseFields_JSONNames(t *testing.T) {
+	chdirTemp(t)
+
+	fields := parseFields("firstName:string,email:string:json=emailAddress,address:struct{zipCode:string}")
+	require.Len(t, fields, 4)
+	assert.Equal(t, "FirstName", fields[1].Name)
+	assert.Equal(t, "first_name", jsonTagName(fields[1].Tag))
+	assert.Equal(t, "emailAddress", jsonTagName(fields[2].Tag))
+	assert.Contains(t, fields[3].Tag, "embeddedPrefix:address_")
+	assert.Equal(t, "zip_code", jsonTagName(fields[3].Nested[0].Tag))
+
+	_, err := NewFieldValidator().ValidateField("email:string:json=bad name")
+	assert.Error(t, err)
+
+	writeNamingConfig(t, "json: camelCase")
+	fields = parseFields("first_name:string")
+	assert.Equal(t, "firstName", jsonTagName(fields[1].Tag))
+}
+
+func TestGenerateEntity_TableNames(t *testing.T) {
+	chdirTemp(t)
+	sm := NewSafetyManager(false, true, false)
+
+	// GORM already derives categories, so no TableName method is needed.
+	require.NoError(t, generateEntity("Category", "name:string", false, false, false, false, false, false, false, false, "lowercase", sm))
+	assert.NoFileExists(t, filepath.Join("internal", "domain", "category_table.go"))
+	seeds := readParsedGo(t, filepath.Join("internal", "domain", "category_seeds.go"))
+	assert.Contains(t, seeds, "INSERT INTO categories (name)")
+
+	generateEntityTableName("Person", "staff", sm)
+	require.NoError(t, generateEntity("Person", "fullName:string:json=fullName", false, false, false, false, false, false, false, false, "lowercase", sm))
+	table := readParsedGo(t, filepath.Join("internal", "domain", "person_table.go"))
+	assert.Contains(t, table, "func (Person) TableName() string {\n\treturn \"staff\"\n}")
+	seeds = readParsedGo(t, filepath.Join("internal", "domain", "person_seeds.go"))
+	assert.Contains(t, seeds, "INSERT INTO staff (full_name)")
+	assert.Equal(t, "full_name:string:json=fullName", readEntityFieldsString("Person"))
+
+	// Entities of a project with singular tables get a TableName method.
+	writeNamingConfig(t, "tables: singular")
+	require.NoError(t, generateEntity("OrderItem", "quantity:int", false, false, false, false, false, false, false, false, "lowercase", sm))
+	table = readParsedGo(t, filepath.Join("internal", "domain", "orderitem_table.go"))
+	assert.Contains(t, table, "return \"order_item\"")
+	assert.Equal(t, "order_item", entityTableName("OrderItem"))
+}
+
+func TestValidateTableName(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, validateTableName("people"))
+	assert.NoError(t, validateTableName("billing.invoices"))
+	assert.Error(t, validateTableName("people; drop"))
+	assert.Error(t, validateTableName("1people"))
+}
+
+func TestGeneratedPluralNames(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	require.NoError(t, os.WriteFile("go.mod", []byte("module example.com/shop\n\ngo 1.21\n"), 0o644))
+	sm := NewSafetyManager(false, true, false)
+
+	require.NoError(t, generateEntity("Category", "name:string", true, false, false, false, false, false, false, false, "lowercase", sm))
+	generateUseCase("CategoryService", "Category", "create,read,update,delete,list", true, false, sm)
+	generateHandler("Category", HandlerHTTP, false, true, true, "lowercase", sm)
+	generateConstants(filepath.Join("internal", "constants"), "Category", sm)
+
+	usecase := readParsedGo(t, filepath.Join("internal", "usecase", "category_service.go"))
+	assert.Contains(t, usecase, "ListCategories() (ListCategoryOutput, error)")
+	handler := readParsedGo(t, filepath.Join("internal", "handler", "http", "category_handler.go"))
+	assert.Contains(t, handler, ") ListCategories(w http.ResponseWriter, r *http.Request) {")
+	assert.Contains(t, handler, "// @Router /categories [get]")
+	routes := readParsedGo(t, filepath.Join("internal", "handler", "http", "routes.go"))
+	assert.Contains(t, routes, `router.HandleFunc("/categories", handler.ListCategories).Methods("GET")`)
+	constants := readParsedGo(t, filepath.Join("internal", "constants", "constants.go"))
+	assert.Regexp(t, `CategoryEndpoint += "/categories"`, constants)
+	for _, src := range []string{usecase, handler, routes, constants} {
+		assert.NotRegexp(t, `(?i)categorys\b`, src)
+	}
+}
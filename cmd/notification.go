@@ -0,0 +1,914 @@
+package cmd
+
+import (
+	"fmt"
+	"net/mail"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Providers of goca notification.
+const (
+	NotificationSMTP     = "smtp"
+	NotificationSendGrid = "sendgrid"
+	NotificationSES      = "ses"
+	NotificationLog      = "log"
+)
+
+// ValidNotificationProviders are the accepted --provider values.
+var ValidNotificationProviders = []string{NotificationSMTP, NotificationSendGrid, NotificationSES, NotificationLog}
+
+// notificationDir is the package sending the notifications.
+var notificationDir = filepath.Join(DirPkg, "notification")
+
+// defaultNotificationFrom is the sender without --from or
+// features.notification.from.
+const defaultNotificationFrom = "no-reply@example.com"
+
+var notificationCmd = &cobra.Command{
+	Use:   "notification",
+	Short: "Generate the email notification subsystem",
+	Long: `Generates pkg/notification, which sends templated emails through SMTP,
+SendGrid or Amazon SES:
+
+  - pkg/notification/notification.go  The Notifier interface, Message and New,
+                                       which opens the configured provider
+  - pkg/notification/smtp.go           SMTP with STARTTLS and PLAIN auth
+  - pkg/notification/sendgrid.go       The SendGrid v3 Mail Send API
+  - pkg/notification/ses.go            Amazon SES (v2 API)
+  - pkg/notification/templates.go      Render, filling the welcome and
+                                       password reset templates
+  - internal/handler/worker/notification_worker.go
+                                       Sends on the worker pool, so requests
+                                       do not wait for the provider
+
+The provider is read from NOTIFICATION_PROVIDER (smtp, sendgrid, ses or log,
+which only logs the messages). Its settings are added to pkg/config and to
+.env and .env.example. The defaults come from features.notification in
+.goca.yaml:
+
+  features:
+    notification:
+      provider: ses
+      from: no-reply@shop.example.com
+
+When the User entity exists and has an Email field (see --entity),
+internal/usecase/user_notifications.go wraps its use case to send the welcome
+email after CreateUser.
+
+In a project initialized with --embed-assets, Render uses the email
+templates of internal/assets instead of its own.
+
+Examples:
+  goca notification
+  goca notification --provider sendgrid --from no-reply@shop.example.com
+  goca notification --entity Customer --sync`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		provider, _ := cmd.Flags().GetString("provider")
+		from, _ := cmd.Flags().GetString("from")
+		entity, _ := cmd.Flags().GetString("entity")
+		sync, _ := cmd.Flags().GetBool("sync")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		force, _ := cmd.Flags().GetBool("force")
+
+		ci := NewConfigIntegration()
+		_ = ci.LoadConfigForProject()
+		settings := ci.GetNotificationConfig()
+		if provider == "" {
+			provider = settings.Provider
+		}
+		if provider == "" {
+			provider = NotificationSMTP
+		}
+		if !contains(ValidNotificationProviders, provider) {
+			return fmt.Errorf("unsupported --provider %q: use one of %s", provider, strings.Join(ValidNotificationProviders, ", "))
+		}
+		if from == "" {
+			from = settings.From
+		}
+		if from == "" {
+			from = defaultNotificationFrom
+		}
+		if _, err := mail.ParseAddress(from); err != nil {
+			return fmt.Errorf("invalid --from %q: %w", from, err)
+		}
+		sync = sync || settings.Sync
+
+		welcome, reason := notificationWelcomeEntity(entity)
+
+		ui.Header("Goca Notification")
+		ui.Blank()
+		ui.KeyValue("Provider", provider)
+		ui.KeyValue("From", from)
+		if sync {
+			ui.KeyValue("Dispatch", "synchronous")
+		} else {
+			ui.KeyValue("Dispatch", "worker pool")
+		}
+		if welcome {
+			ui.KeyValue("Welcome email", entity+" create")
+		} else {
+			ui.KeyValue("Welcome email", "skipped: "+reason)
+		}
+		ui.Blank()
+
+		sm := NewSafetyManager(dryRun, force, false)
+		generateNotificationPackage(provider, from, sm)
+		if !sync {
+			generateNotificationWorker(sm)
+		}
+		if welcome {
+			generateWelcomeNotification(entity, sm)
+		}
+		if dryRun {
+			sm.PrintSummary()
+			return nil
+		}
+
+		projectRoot, _ := os.Getwd()
+		depMgr := NewDependencyManager(projectRoot, false)
+		featureType := "worker"
+		if sync {
+			featureType = ""
+		}
+		for _, dep := range depMgr.GetRequiredDependenciesForFeature(featureType, map[string]bool{"notification": true}) {
+			if err := depMgr.AddDependency(dep); err != nil {
+				ui.Warning(fmt.Sprintf("Could not add dependency %s: %v", dep.Module, err))
+			}
+		}
+
+		ui.Blank()
+		steps := []string{"Set NOTIFICATION_PROVIDER and its settings in .env (" + notificationProviderSettings(provider) + ")"}
+		steps = append(steps, "Open the notifier in main.go: notifier, err := notification.New(ctx, cfg.Notification)")
+		if !sync {
+			steps = append(steps, "Send on the worker pool: pool := worker.NewPool(cfg.Worker); pool.Start(ctx); notifier = worker.NewNotificationWorker(notifier, pool)")
+		}
+		if welcome {
+			steps = append(steps, fmt.Sprintf("Wrap the use case in internal/di: usecase.New%sNotifications(uc, notifier, cfg.Notification.AppName, cfg.Notification.BaseURL)", entity))
+		}
+		ui.NextSteps(steps)
+		return nil
+	},
+}
+
+// notificationProviderSettings names the variables a provider needs.
+func notificationProviderSettings(provider string) string {
+	switch provider {
+	case NotificationSendGrid:
+		return "SENDGRID_API_KEY"
+	case NotificationSES:
+		return "SES_REGION and the AWS credentials"
+	case NotificationLog:
+		return "nothing to set; messages are only logged"
+	}
+	return "SMTP_HOST, SMTP_PORT, SMTP_USER, SMTP_PASSWORD"
+}
+
+// notificationWelcomeEntity reports whether the welcome email can be sent
+// on creates of entity, or why not.
+func notificationWelcomeEntity(entity string) (bool, string) {
+	if entity == "" {
+		return false, "no --entity"
+	}
+	file, err := findEntityFile(entity)
+	if err != nil {
+		return false, fmt.Sprintf("no %s entity", entity)
+	}
+	if !hasStringField(file, entity, "Email") {
+		return false, fmt.Sprintf("%s has no Email string field", entity)
+	}
+	if !contains(useCaseOperations(entity), "create") {
+		return false, fmt.Sprintf("%sUseCase declares no Create%s", entity, entity)
+	}
+	return true, ""
+}
+
+// hasStringField reports whether the entity struct in file has a string
+// field name.
+func hasStringField(file, entity, name string) bool {
+	src, err := parseGoSource(file)
+	if err != nil {
+		return false
+	}
+	for _, field := range readEntityFields(src.file, entity) {
+		if field.Name == name && field.Type == "string" {
+			return true
+		}
+	}
+	return false
+}
+
+// generateNotificationPackage writes pkg/notification and adds its settings
+// to pkg/config and the .env files. Like pkg/storage, each file is only
+// rewritten with --force, so a provider can be edited or removed.
+func generateNotificationPackage(provider, from string, sm *SafetyManager) {
+	importPath := getImportPath(getModuleName())
+	files := []struct{ name, content string }{
+		{"notification.go", fmt.Sprintf(notificationTemplate, importPath)},
+		{"smtp.go", fmt.Sprintf(notificationSMTPTemplate, importPath)},
+		{"sendgrid.go", fmt.Sprintf(notificationSendGridTemplate, importPath)},
+		{"ses.go", fmt.Sprintf(notificationSESTemplate, importPath)},
+	}
+	if projectEmbedsAssets() {
+		files = append(files, struct{ name, content string }{"templates.go", fmt.Sprintf(notificationAssetsTemplatesSource, importPath)})
+	} else {
+		files = append(files, struct{ name, content string }{"templates.go", notificationTemplatesSource})
+		for name, content := range map[string]string{
+			"welcome.txt":         welcomeEmailText,
+			"welcome.html":        welcomeEmailHTML,
+			"password_reset.txt":  passwordResetEmailText,
+			"password_reset.html": passwordResetEmailHTML,
+		} {
+			filename := filepath.Join(notificationDir, "templates", name)
+			if _, err := os.Stat(filename); err == nil && !sm.Force {
+				continue
+			}
+			if err := writeFile(filename, content, sm); err != nil {
+				ui.Error(fmt.Sprintf("Error writing %s: %v", filename, err))
+			}
+		}
+	}
+	for _, file := range files {
+		writeOnce(filepath.Join(notificationDir, file.name), file.content, file.name, sm)
+	}
+
+	appName := path.Base(getModuleName())
+	load := fmt.Sprintf(notificationConfigLoad, provider, from, appName)
+	if err := ensureConfigSection("Notification", "NotificationConfig", notificationConfigDecl, load, sm); err != nil {
+		ui.Warning(fmt.Sprintf("Could not add the notification settings to pkg/config: %v", err))
+	}
+	block := fmt.Sprintf(notificationEnvBlock, provider, from, appName)
+	for _, name := range []string{".env", ".env.example"} {
+		if err := appendEnvBlock(name, "NOTIFICATION_PROVIDER", block, sm); err != nil {
+			ui.Warning(fmt.Sprintf("Could not add the notification settings to %s: %v", name, err))
+		}
+	}
+}
+
+// appendEnvBlock appends block to the env file at name unless it already
+// sets key. A missing file is left alone.
+func appendEnvBlock(name, key, block string, sm *SafetyManager) error {
+	raw, err := os.ReadFile(name)
+	if err != nil {
+		return nil
+	}
+	content := string(raw)
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), key+"=") {
+			return nil
+		}
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return writeMergedFileSafe(name, content+"\n"+block, sm)
+}
+
+// generateNotificationWorker writes the worker sending notifications on the
+// pool of internal/handler/worker, with the pool itself on first use.
+func generateNotificationWorker(sm *SafetyManager) {
+	workerDir := filepath.Join(DirInternal, DirHandler, DirWorker)
+	moduleName := getModuleName()
+	writeOnce(filepath.Join(workerDir, "notification_worker.go"),
+		fmt.Sprintf(notificationWorkerTemplate, getImportPath(moduleName)), "notification worker", sm)
+
+	generateWorkerPoolFile(workerDir, moduleName, sm)
+	if err := ensureWorkerConfig(sm); err != nil {
+		ui.Warning(fmt.Sprintf("Could not add the worker settings to pkg/config: %v", err))
+	}
+	generateHealthPackage(sm)
+	registerWorkerReadiness(sm)
+}
+
+// generateWelcomeNotification writes internal/usecase/<entity>_notifications.go,
+// the use case decorator sending the welcome email after a create.
+func generateWelcomeNotification(entity string, sm *SafetyManager) {
+	name := "Email"
+	if file, err := findEntityFile(entity); err == nil && hasStringField(file, entity, "Name") {
+		name = "Name"
+	}
+	filename := filepath.Join(DirInternal, DirUseCase, strings.ToLower(entity)+"_notifications.go")
+	content := fmt.Sprintf(welcomeNotificationTemplate, getImportPath(getModuleName()), entity, strings.ToLower(entity), name)
+	writeOnce(filename, content, "welcome notification", sm)
+}
+
+func init() {
+	notificationCmd.Flags().String("provider", "", "Default provider: smtp, sendgrid, ses or log (features.notification.provider, smtp by default)")
+	notificationCmd.Flags().String("from", "", "Default sender address (features.notification.from)")
+	notificationCmd.Flags().String("entity", "User", "Entity whose use case sends the welcome email on create")
+	notificationCmd.Flags().Bool("sync", false, "Send in the request instead of on the worker pool")
+	notificationCmd.Flags().Bool("dry-run", false, "Preview changes without creating files")
+	notificationCmd.Flags().Bool("force", false, "Overwrite existing files without asking")
+}
+
+// notificationConfigDecl declares the notification settings of pkg/config.
+const notificationConfigDecl = `// NotificationConfig selects how notifications are sent.
+type NotificationConfig struct {
+	// Provider is smtp, sendgrid, ses or log.
+	Provider string
+	// From is the sender address.
+	From string
+	// AppName and BaseURL fill the message templates.
+	AppName string
+	BaseURL string
+	// SMTPHost, SMTPPort, SMTPUser and SMTPPassword reach the SMTP server.
+	// Without SMTPUser, messages are sent unauthenticated.
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUser     string
+	SMTPPassword string
+	// SendGridAPIKey authenticates with the SendGrid API.
+	SendGridAPIKey string
+	// SESRegion is the AWS region of SES. The AWS configuration's region by
+	// default.
+	SESRegion string
+}
+`
+
+// notificationConfigLoad is the Notification element of the Config literal
+// Load returns, formatted with the provider, the sender and the app name.
+const notificationConfigLoad = `		Notification: NotificationConfig{
+			Provider:       getEnv("NOTIFICATION_PROVIDER", %q),
+			From:           getEnv("NOTIFICATION_FROM", %q),
+			AppName:        getEnv("NOTIFICATION_APP_NAME", %q),
+			BaseURL:        getEnv("NOTIFICATION_BASE_URL", "http://localhost:8080"),
+			SMTPHost:       getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:       getEnvAsInt("SMTP_PORT", 587),
+			SMTPUser:       getEnv("SMTP_USER", ""),
+			SMTPPassword:   getEnv("SMTP_PASSWORD", ""),
+			SendGridAPIKey: getEnv("SENDGRID_API_KEY", ""),
+			SESRegion:      getEnv("SES_REGION", ""),
+		},
+`
+
+// notificationEnvBlock is appended to the .env files, formatted like
+// notificationConfigLoad.
+const notificationEnvBlock = `# Notifications (smtp, sendgrid, ses or log)
+NOTIFICATION_PROVIDER=%s
+NOTIFICATION_FROM=%s
+NOTIFICATION_APP_NAME=%s
+NOTIFICATION_BASE_URL=http://localhost:8080
+SMTP_HOST=localhost
+SMTP_PORT=587
+SMTP_USER=
+SMTP_PASSWORD=
+SENDGRID_API_KEY=
+SES_REGION=
+`
+
+const notificationTemplate = `// Package notification sends email notifications through the provider of
+// config.NotificationConfig: SMTP, SendGrid, Amazon SES, or the log in
+// development.
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+
+	"%s/pkg/config"
+)
+
+// Message is an email to send.
+type Message struct {
+	To      []string
+	Subject string
+	// Text is the plain text body. HTML, when set, is the alternative mail
+	// clients show instead.
+	Text string
+	HTML string
+}
+
+// Notifier sends messages.
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New opens the notifier of cfg.Provider.
+func New(ctx context.Context, cfg config.NotificationConfig) (Notifier, error) {
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return nil, fmt.Errorf("invalid NOTIFICATION_FROM %%q: %%w", cfg.From, err)
+	}
+	switch cfg.Provider {
+	case "", "smtp":
+		smtp, err := NewSMTP(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return smtp, nil
+	case "sendgrid":
+		sendGrid, err := NewSendGrid(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return sendGrid, nil
+	case "ses":
+		ses, err := NewSES(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return ses, nil
+	case "log":
+		return Log{}, nil
+	default:
+		return nil, fmt.Errorf("unknown notification provider %%q; use smtp, sendgrid, ses or log", cfg.Provider)
+	}
+}
+
+// Log writes messages to the log instead of sending them, for development.
+type Log struct{}
+
+// Send implements Notifier.
+func (Log) Send(_ context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	log.Printf("Notification to %%s: %%s\n%%s", strings.Join(msg.To, ", "), msg.Subject, msg.Text)
+	return nil
+}
+
+// validate rejects messages without a recipient or a body, and malformed
+// recipients, which could otherwise inject headers.
+func (m Message) validate() error {
+	if len(m.To) == 0 {
+		return errors.New("notification has no recipient")
+	}
+	for _, to := range m.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("invalid recipient %%q: %%w", to, err)
+		}
+	}
+	if m.Text == "" && m.HTML == "" {
+		return errors.New("notification has no body")
+	}
+	return nil
+}
+
+// addresses returns the bare addresses of the recipients of m.
+func (m Message) addresses() []string {
+	addresses := make([]string, 0, len(m.To))
+	for _, to := range m.To {
+		if addr, err := mail.ParseAddress(to); err == nil {
+			addresses = append(addresses, addr.Address)
+		}
+	}
+	return addresses
+}
+`
+
+const notificationSMTPTemplate = `package notification
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"%s/pkg/config"
+)
+
+// SMTP sends messages through an SMTP server, upgrading the connection with
+// STARTTLS when the server offers it. Without SMTP_USER it sends
+// unauthenticated, as development servers such as Mailpit expect.
+type SMTP struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTP returns the SMTP notifier of cfg.
+func NewSMTP(cfg config.NotificationConfig) (*SMTP, error) {
+	if cfg.SMTPHost == "" {
+		return nil, errors.New("SMTP_HOST is required by the smtp notification provider")
+	}
+	s := &SMTP{addr: net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)), from: cfg.From}
+	if cfg.SMTPUser != "" {
+		s.auth = smtp.PlainAuth("", cfg.SMTPUser, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+	return s, nil
+}
+
+// Send implements Notifier. net/smtp takes no context, so a canceled ctx
+// stops the wait for the server, not a delivery in progress.
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	from, err := mail.ParseAddress(s.from)
+	if err != nil {
+		return fmt.Errorf("invalid sender %%q: %%w", s.from, err)
+	}
+	body, err := buildMIME(s.from, msg)
+	if err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(s.addr, s.auth, from.Address, msg.addresses(), body)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("smtp: %%w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// buildMIME returns msg as a MIME message: text/plain, or
+// multipart/alternative with the HTML part last when it has one.
+func buildMIME(from string, msg Message) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %%s\r\n", from)
+	fmt.Fprintf(&b, "To: %%s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&b, "Subject: %%s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %%s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+
+	if msg.HTML == "" {
+		b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&b, msg.Text); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&b)
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%%s\r\n\r\n", parts.Boundary())
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", msg.Text},
+		{"text/html", msg.HTML},
+	} {
+		if part.body == "" {
+			continue
+		}
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// writeQuotedPrintable writes body to w in the quoted-printable encoding.
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := io.WriteString(qp, body); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+`
+
+const notificationSendGridTemplate = `package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"%s/pkg/config"
+)
+
+// sendGridEndpoint is the v3 Mail Send API.
+const sendGridEndpoint = "https://api.sendgrid.com/v3/mail/send"
+
+// SendGrid sends messages through the SendGrid v3 Mail Send API.
+type SendGrid struct {
+	apiKey   string
+	from     *mail.Address
+	endpoint string
+	client   *http.Client
+}
+
+// NewSendGrid returns the SendGrid notifier of cfg.
+func NewSendGrid(cfg config.NotificationConfig) (*SendGrid, error) {
+	if cfg.SendGridAPIKey == "" {
+		return nil, errors.New("SENDGRID_API_KEY is required by the sendgrid notification provider")
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender %%q: %%w", cfg.From, err)
+	}
+	return &SendGrid{
+		apiKey:   cfg.SendGridAPIKey,
+		from:     from,
+		endpoint: sendGridEndpoint,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Send implements Notifier.
+func (s *SendGrid) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	to := make([]map[string]string, 0, len(msg.To))
+	for _, addr := range msg.addresses() {
+		to = append(to, map[string]string{"email": addr})
+	}
+	// SendGrid requires the text/plain content first.
+	var content []map[string]string
+	if msg.Text != "" {
+		content = append(content, map[string]string{"type": "text/plain", "value": msg.Text})
+	}
+	if msg.HTML != "" {
+		content = append(content, map[string]string{"type": "text/html", "value": msg.HTML})
+	}
+	body, err := json.Marshal(map[string]any{
+		"personalizations": []map[string]any{{"to": to}},
+		"from":             map[string]string{"email": s.from.Address, "name": s.from.Name},
+		"subject":          msg.Subject,
+		"content":          content,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sendgrid: %%w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("sendgrid: %%s: %%s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+`
+
+const notificationSESTemplate = `package notification
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+
+	"%s/pkg/config"
+)
+
+// SES sends messages through Amazon SES. Credentials are read from the
+// environment, the shared AWS configuration or the instance role.
+type SES struct {
+	client *sesv2.Client
+	from   string
+}
+
+// NewSES returns the SES notifier of cfg.
+func NewSES(ctx context.Context, cfg config.NotificationConfig) (*SES, error) {
+	var opts []func(*awsconfig.LoadOptions) error
+	if cfg.SESRegion != "" {
+		opts = append(opts, awsconfig.WithRegion(cfg.SESRegion))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("load AWS configuration: %%w", err)
+	}
+	return &SES{client: sesv2.NewFromConfig(awsCfg), from: cfg.From}, nil
+}
+
+// Send implements Notifier.
+func (s *SES) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	body := &types.Body{}
+	if msg.Text != "" {
+		body.Text = utf8Content(msg.Text)
+	}
+	if msg.HTML != "" {
+		body.Html = utf8Content(msg.HTML)
+	}
+	_, err := s.client.SendEmail(ctx, &sesv2.SendEmailInput{
+		FromEmailAddress: aws.String(s.from),
+		Destination:      &types.Destination{ToAddresses: msg.To},
+		Content: &types.EmailContent{Simple: &types.Message{
+			Subject: utf8Content(msg.Subject),
+			Body:    body,
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("ses: %%w", err)
+	}
+	return nil
+}
+
+// utf8Content returns s as SES content.
+func utf8Content(s string) *types.Content {
+	return &types.Content{Data: aws.String(s), Charset: aws.String("UTF-8")}
+}
+`
+
+// notificationTemplatesSource renders the templates embedded in
+// pkg/notification/templates.
+const notificationTemplatesSource = `package notification
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+)
+
+// templates holds the message templates: templates/<name>.txt, whose
+// "subject" block is the subject, and the optional templates/<name>.html.
+//
+//go:embed templates
+var templates embed.FS
+
+// Render renders the message template name with data. The recipients are
+// left to the caller.
+func Render(name string, data any) (Message, error) {
+	var msg Message
+
+	text, err := texttemplate.ParseFS(templates, "templates/"+name+".txt")
+	if err != nil {
+		return msg, fmt.Errorf("template %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if subject := text.Lookup("subject"); subject != nil {
+		if err := subject.Execute(&buf, data); err != nil {
+			return msg, fmt.Errorf("template %s subject: %w", name, err)
+		}
+		msg.Subject = strings.TrimSpace(buf.String())
+		buf.Reset()
+	}
+	if err := text.Execute(&buf, data); err != nil {
+		return msg, fmt.Errorf("template %s: %w", name, err)
+	}
+	msg.Text = strings.TrimSpace(buf.String()) + "\n"
+
+	if _, err := fs.Stat(templates, "templates/"+name+".html"); err != nil {
+		return msg, nil
+	}
+	html, err := htmltemplate.ParseFS(templates, "templates/"+name+".html")
+	if err != nil {
+		return msg, fmt.Errorf("template %s: %w", name, err)
+	}
+	buf.Reset()
+	if err := html.Execute(&buf, data); err != nil {
+		return msg, fmt.Errorf("template %s: %w", name, err)
+	}
+	msg.HTML = buf.String()
+	return msg, nil
+}
+`
+
+// notificationAssetsTemplatesSource renders the email templates of
+// internal/assets in projects initialized with --embed-assets.
+const notificationAssetsTemplatesSource = `package notification
+
+import "%s/internal/assets"
+
+// Render renders the email template name of internal/assets with data; see
+// assets.RenderEmail. The recipients are left to the caller.
+func Render(name string, data any) (Message, error) {
+	email, err := assets.RenderEmail(name, data)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{Subject: email.Subject, Text: email.Text, HTML: email.HTML}, nil
+}
+`
+
+const notificationWorkerTemplate = `package worker
+
+import (
+	"context"
+	"fmt"
+
+	"%s/pkg/notification"
+)
+
+// NotificationWorker sends notifications on a Pool, so callers do not wait
+// for the provider. It is a notification.Notifier wrapping the provider's.
+type NotificationWorker struct {
+	notifier notification.Notifier
+	pool     *Pool
+}
+
+// NewNotificationWorker returns a worker sending with notifier on pool.
+func NewNotificationWorker(notifier notification.Notifier, pool *Pool) *NotificationWorker {
+	return &NotificationWorker{notifier: notifier, pool: pool}
+}
+
+// Send queues msg, waiting while the pool's queue is full. ctx only bounds
+// that wait; the pool logs and counts a failed send.
+func (w *NotificationWorker) Send(ctx context.Context, msg notification.Message) error {
+	return w.pool.Submit(ctx, func(ctx context.Context) error {
+		if err := w.notifier.Send(ctx, msg); err != nil {
+			return fmt.Errorf("notification %%q: %%w", msg.Subject, err)
+		}
+		return nil
+	})
+}
+`
+
+// welcomeNotificationTemplate is formatted with the import path, the entity,
+// its lower-case name and the input field greeting the recipient.
+const welcomeNotificationTemplate = `package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"%[1]s/pkg/notification"
+)
+
+// welcomeTimeout bounds the sending, or the queueing on a worker pool, of
+// a welcome email.
+const welcomeTimeout = 10 * time.Second
+
+// %[3]sNotifications sends the welcome email of a created %[2]s.
+type %[3]sNotifications struct {
+	%[2]sUseCase
+	notifier notification.Notifier
+	appName  string
+	baseURL  string
+}
+
+// New%[2]sNotifications wraps inner so that Create%[2]s sends the welcome
+// template to the new %[2]s. A failed send is logged and does not fail the
+// create. Pass a worker.NotificationWorker to send in the background.
+func New%[2]sNotifications(inner %[2]sUseCase, notifier notification.Notifier, appName, baseURL string) %[2]sUseCase {
+	return &%[3]sNotifications{%[2]sUseCase: inner, notifier: notifier, appName: appName, baseURL: baseURL}
+}
+
+func (n *%[3]sNotifications) Create%[2]s(input Create%[2]sInput) (Create%[2]sOutput, error) {
+	output, err := n.%[2]sUseCase.Create%[2]s(input)
+	if err != nil {
+		return output, err
+	}
+
+	msg, err := notification.Render("welcome", map[string]any{
+		"AppName": n.appName,
+		"Name":    input.%[4]s,
+		"URL":     n.baseURL,
+	})
+	if err == nil {
+		msg.To = []string{input.Email}
+		ctx, cancel := context.WithTimeout(context.Background(), welcomeTimeout)
+		defer cancel()
+		err = n.notifier.Send(ctx, msg)
+	}
+	if err != nil {
+		log.Printf("Welcome email not sent: %%v", err)
+	}
+	return output, nil
+}
+`
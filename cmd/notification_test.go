@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const userWithEmailUseCase = `package usecase
+
+type UserUseCase interface {
+	CreateUser(input CreateUserInput) (CreateUserOutput, error)
+	GetUser(id int) (*User, error)
+}
+`
+
+func TestNotificationWelcomeEntity(t *testing.T) {
+	chdirTemp(t)
+	writeTestFile(t, ".", "go.mod", "module testproject\n\ngo 1.21\n")
+	writeTestFile(t, ".", "internal/domain/user.go", "package domain\n\ntype User struct {\n\tID    uint\n\tName  string\n\tEmail string\n}\n")
+	writeTestFile(t, ".", "internal/domain/tag.go", "package domain\n\ntype Tag struct {\n\tID    uint\n\tEmail []string\n}\n")
+	writeTestFile(t, ".", "internal/usecase/user_usecase.go", userWithEmailUseCase)
+
+	ok, _ := notificationWelcomeEntity("User")
+	assert.True(t, ok)
+	for entity, reason := range map[string]string{
+		"":        "no --entity",
+		"Account": "no Account entity",
+		"Tag":     "Tag has no Email string field",
+	} {
+		ok, got := notificationWelcomeEntity(entity)
+		assert.False(t, ok, entity)
+		assert.Equal(t, reason, got, entity)
+	}
+
+	writeTestFile(t, ".", "internal/usecase/user_usecase.go", "package usecase\n\ntype UserUseCase interface {\n\tGetUser(id int) (*User, error)\n}\n")
+	_, reason := notificationWelcomeEntity("User")
+	assert.Equal(t, "UserUseCase declares no CreateUser", reason)
+}
+
+func TestGenerateNotification(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	writeTestFile(t, ".", "go.mod", "module example.com/shop\n\ngo 1.21\n")
+	writeTestFile(t, ".", filepath.Join("pkg", "config", "config.go"), legacyConfig)
+	writeTestFile(t, ".", "internal/domain/user.go", "package domain\n\ntype User struct {\n\tID    uint\n\tName  string\n\tEmail string\n}\n")
+	writeTestFile(t, ".", "internal/usecase/user_usecase.go", userWithEmailUseCase)
+	writeTestFile(t, ".", ".env", "PORT=8080")
+
+	sm := NewSafetyManager(false, false, false)
+	generateNotificationPackage(NotificationSendGrid, "Shop <no-reply@shop.test>", sm)
+	generateNotificationWorker(sm)
+	generateWelcomeNotification("User", sm)
+
+	pkg := readParsedGo(t, filepath.Join(notificationDir, "notification.go"))
+	assert.Contains(t, pkg, `"example.com/shop/pkg/config"`)
+	assert.Contains(t, readParsedGo(t, filepath.Join(notificationDir, "ses.go")), "sesv2.NewFromConfig")
+	assert.Contains(t, readParsedGo(t, filepath.Join(notificationDir, "templates.go")), "//go:embed templates\n")
+	assert.FileExists(t, filepath.Join(notificationDir, "templates", "welcome.html"))
+
+	config := readParsedGo(t, filepath.Join(DirPkg, DirConfig, "config.go"))
+	assert.Contains(t, config, `Provider:       getEnv("NOTIFICATION_PROVIDER", "sendgrid"),`)
+	assert.Contains(t, config, `AppName:        getEnv("NOTIFICATION_APP_NAME", "shop"),`)
+	assert.Contains(t, config, "type WorkerConfig struct {")
+
+	worker := readParsedGo(t, filepath.Join(DirInternal, DirHandler, DirWorker, "notification_worker.go"))
+	assert.Contains(t, worker, "func NewNotificationWorker(notifier notification.Notifier, pool *Pool) *NotificationWorker {")
+	assert.FileExists(t, filepath.Join(DirInternal, DirHandler, DirWorker, "pool.go"))
+
+	welcome := readParsedGo(t, filepath.Join(DirInternal, DirUseCase, "user_notifications.go"))
+	assert.Contains(t, welcome, "func NewUserNotifications(inner UserUseCase, notifier notification.Notifier, appName, baseURL string) UserUseCase {")
+	assert.Contains(t, welcome, `"Name":    input.Name,`)
+	assert.Contains(t, welcome, "msg.To = []string{input.Email}")
+
+	// A rerun keeps the edited files and does not append the .env block twice.
+	custom := "package notification\n\n// edited\n"
+	require.NoError(t, os.WriteFile(filepath.Join(notificationDir, "smtp.go"), []byte(custom), 0o644))
+	generateNotificationPackage(NotificationSMTP, defaultNotificationFrom, sm)
+	smtp, err := os.ReadFile(filepath.Join(notificationDir, "smtp.go"))
+	require.NoError(t, err)
+	assert.Equal(t, custom, string(smtp))
+	env, err := os.ReadFile(".env")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(env), "PORT=8080\n\n# Notifications"))
+	assert.Equal(t, 1, strings.Count(string(env), "NOTIFICATION_PROVIDER=sendgrid"))
+	assert.NoFileExists(t, ".env.example")
+}
+
+func TestValidateNotificationConfig(t *testing.T) {
+	cm := NewConfigManager()
+	cm.validateFeatures(&FeatureConfig{Notification: NotificationConfig{Provider: "pigeon", From: "not an address"}})
+	require.Len(t, cm.GetErrors(), 2)
+	assert.Equal(t, "features.notification.provider", cm.GetErrors()[0].Field)
+	assert.Equal(t, "features.notification.from", cm.GetErrors()[1].Field)
+
+	cm = NewConfigManager()
+	cm.validateFeatures(&FeatureConfig{Notification: NotificationConfig{Provider: NotificationSES, From: "Shop <no-reply@shop.test>"}})
+	assert.Empty(t, cm.GetErrors())
+}
@@ -0,0 +1,644 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openAPIDocument is the subset of an OpenAPI 3 document used to scaffold
+// features. Paths and schemas keep the order they are declared in.
+type openAPIDocument struct {
+	OpenAPI    string                             `yaml:"openapi"`
+	Paths      openAPIOrderedMap[openAPIPathItem] `yaml:"paths"`
+	Components struct {
+		Schemas openAPIOrderedMap[*openAPISchema] `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+// openAPIOrderedMap is a YAML mapping that remembers the order of its keys.
+type openAPIOrderedMap[T any] struct {
+	Keys   []string
+	Values map[string]T
+}
+
+// UnmarshalYAML decodes a mapping node, keeping the key order.
+func (m *openAPIOrderedMap[T]) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping", node.Line)
+	}
+	m.Values = make(map[string]T, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		var value T
+		if err := node.Content[i+1].Decode(&value); err != nil {
+			return err
+		}
+		if _, ok := m.Values[key]; !ok {
+			m.Keys = append(m.Keys, key)
+		}
+		m.Values[key] = value
+	}
+	return nil
+}
+
+// openAPIPathItem holds the operations declared for one path, and the
+// parameters they share.
+type openAPIPathItem struct {
+	Parameters []openAPIParameter `yaml:"parameters"`
+	Get        *openAPIOperation  `yaml:"get"`
+	Post       *openAPIOperation  `yaml:"post"`
+	Put        *openAPIOperation  `yaml:"put"`
+	Patch      *openAPIOperation  `yaml:"patch"`
+	Delete     *openAPIOperation  `yaml:"delete"`
+}
+
+// openAPIMethodOperation is an operation together with its HTTP method.
+type openAPIMethodOperation struct {
+	Method    string
+	Operation *openAPIOperation
+}
+
+// operations returns the declared operations in a fixed method order.
+func (p openAPIPathItem) operations() []openAPIMethodOperation {
+	var ops []openAPIMethodOperation
+	for _, op := range []openAPIMethodOperation{
+		{"GET", p.Get}, {"POST", p.Post}, {"PUT", p.Put}, {"PATCH", p.Patch}, {"DELETE", p.Delete},
+	} {
+		if op.Operation != nil {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// openAPIOperation is a single HTTP operation.
+type openAPIOperation struct {
+	OperationID string                          `yaml:"operationId"`
+	Parameters  []openAPIParameter              `yaml:"parameters"`
+	RequestBody *openAPIBody                    `yaml:"requestBody"`
+	Responses   openAPIOrderedMap[*openAPIBody] `yaml:"responses"`
+}
+
+// openAPIParameter is a path, query, header or cookie parameter.
+type openAPIParameter struct {
+	Name     string         `yaml:"name"`
+	In       string         `yaml:"in"`
+	Required bool           `yaml:"required"`
+	Schema   *openAPISchema `yaml:"schema"`
+}
+
+// openAPIBody is a request body or a response.
+type openAPIBody struct {
+	Required bool                                `yaml:"required"`
+	Content  openAPIOrderedMap[openAPIMediaType] `yaml:"content"`
+}
+
+// openAPIMediaType is the schema of one media type of a body.
+type openAPIMediaType struct {
+	Schema *openAPISchema `yaml:"schema"`
+}
+
+// schema returns the JSON schema of the body, or the first schema declared.
+func (b *openAPIBody) schema() *openAPISchema {
+	if b == nil {
+		return nil
+	}
+	if mt, ok := b.Content.Values["application/json"]; ok && mt.Schema != nil {
+		return mt.Schema
+	}
+	for _, key := range b.Content.Keys {
+		if s := b.Content.Values[key].Schema; s != nil {
+			return s
+		}
+	}
+	return nil
+}
+
+// successSchema returns the schema of the first 2xx response.
+func (o *openAPIOperation) successSchema() *openAPISchema {
+	for _, code := range o.Responses.Keys {
+		if strings.HasPrefix(code, "2") {
+			if s := o.Responses.Values[code].schema(); s != nil {
+				return s
+			}
+		}
+	}
+	return nil
+}
+
+// openAPISchema is a JSON schema as used by OpenAPI 3.0 and 3.1.
+type openAPISchema struct {
+	Ref              string                            `yaml:"$ref"`
+	Type             openAPIType                       `yaml:"type"`
+	Format           string                            `yaml:"format"`
+	Properties       openAPIOrderedMap[*openAPISchema] `yaml:"properties"`
+	Required         []string                          `yaml:"required"`
+	Items            *openAPISchema                    `yaml:"items"`
+	AllOf            []*openAPISchema                  `yaml:"allOf"`
+	Enum             []any                             `yaml:"enum"`
+	MinLength        *int                              `yaml:"minLength"`
+	MaxLength        *int                              `yaml:"maxLength"`
+	Minimum          *float64                          `yaml:"minimum"`
+	Maximum          *float64                          `yaml:"maximum"`
+	ExclusiveMinimum openAPIBound                      `yaml:"exclusiveMinimum"`
+	ExclusiveMaximum openAPIBound                      `yaml:"exclusiveMaximum"`
+	Nullable         bool                              `yaml:"nullable"`
+	ReadOnly         bool                              `yaml:"readOnly"`
+}
+
+// openAPIType is a schema type. OpenAPI 3.1 allows a list such as
+// [string, "null"], which is read as a nullable string.
+type openAPIType struct {
+	Name     string
+	Nullable bool
+}
+
+// UnmarshalYAML accepts a single type name or a list of type names.
+func (t *openAPIType) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		t.Name = node.Value
+		return nil
+	}
+	var names []string
+	if err := node.Decode(&names); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if name == "null" {
+			t.Nullable = true
+		} else if t.Name == "" {
+			t.Name = name
+		}
+	}
+	return nil
+}
+
+// openAPIBound is exclusiveMinimum or exclusiveMaximum: a flag on minimum or
+// maximum in OpenAPI 3.0, or the bound itself in OpenAPI 3.1.
+type openAPIBound struct {
+	Flag  bool
+	Value *float64
+}
+
+// UnmarshalYAML accepts a boolean flag or a number.
+func (b *openAPIBound) UnmarshalYAML(node *yaml.Node) error {
+	if node.Tag == "!!bool" {
+		return node.Decode(&b.Flag)
+	}
+	return node.Decode(&b.Value)
+}
+
+// parseOpenAPIDocument reads an OpenAPI 3 document in YAML or JSON.
+func parseOpenAPIDocument(data []byte) (*openAPIDocument, error) {
+	var doc openAPIDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		if doc.OpenAPI == "" {
+			return nil, errors.New("not an OpenAPI 3 document: missing openapi version")
+		}
+		return nil, fmt.Errorf("unsupported OpenAPI version %s; only 3.x is supported", doc.OpenAPI)
+	}
+	return &doc, nil
+}
+
+// resolve follows $ref pointers to component schemas and merges allOf parts,
+// returning the schema and the component name it came from.
+func (d *openAPIDocument) resolve(s *openAPISchema) (*openAPISchema, string) {
+	name := ""
+	for depth := 0; s != nil && s.Ref != "" && depth < 32; depth++ {
+		name = strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		s = d.Components.Schemas.Values[name]
+	}
+	if s == nil || len(s.AllOf) == 0 {
+		return s, name
+	}
+
+	merged := *s
+	merged.AllOf, merged.Required = nil, nil
+	merged.Properties = openAPIOrderedMap[*openAPISchema]{Values: map[string]*openAPISchema{}}
+	parts := append(append([]*openAPISchema{}, s.AllOf...), &openAPISchema{Properties: s.Properties, Required: s.Required})
+	for _, part := range parts {
+		part, _ = d.resolve(part)
+		if part == nil {
+			continue
+		}
+		for _, key := range part.Properties.Keys {
+			if _, ok := merged.Properties.Values[key]; !ok {
+				merged.Properties.Keys = append(merged.Properties.Keys, key)
+			}
+			merged.Properties.Values[key] = part.Properties.Values[key]
+		}
+		merged.Required = append(merged.Required, part.Required...)
+	}
+	if merged.Type.Name == "" {
+		merged.Type.Name = "object"
+	}
+	return &merged, name
+}
+
+// openAPIResource groups the operations on a collection path and its item
+// path, e.g. /pets and /pets/{id}, into one feature.
+type openAPIResource struct {
+	Entity       string
+	Path         string
+	Schema       *openAPISchema // stored representation, from responses
+	SchemaName   string         // component the entity schema came from
+	Request      *openAPISchema // create/update body, for DTO constraints
+	Operations   []string       // in cliOperations order
+	UpdateMethod string
+	Endpoints    []openAPIEndpoint
+}
+
+// openAPIEndpoint is one spec operation and what it maps to.
+type openAPIEndpoint struct {
+	Method    string
+	Path      string
+	Operation string // goca operation, empty when not generated
+	Note      string
+}
+
+// openAPIResources groups the document's paths into resources. Operations
+// that do not fit the collection/item pattern are returned as skipped
+// endpoints.
+func (d *openAPIDocument) openAPIResources() ([]*openAPIResource, []openAPIEndpoint) {
+	var resources []*openAPIResource
+	byPath := map[string]*openAPIResource{}
+	var skipped []openAPIEndpoint
+
+	for _, path := range d.Paths.Keys {
+		item := d.Paths.Values[path]
+		collection, isItem, ok := splitOpenAPIPath(path)
+		for _, entry := range item.operations() {
+			method, op := entry.Method, entry.Operation
+			if !ok {
+				skipped = append(skipped, openAPIEndpoint{Method: method, Path: path, Note: "not generated: nested or custom path"})
+				continue
+			}
+			res := byPath[collection]
+			if res == nil {
+				res = &openAPIResource{Path: collection, UpdateMethod: "PUT"}
+				byPath[collection] = res
+				resources = append(resources, res)
+			}
+
+			opName := openAPIOperationFor(method, isItem)
+			endpoint := openAPIEndpoint{Method: method, Path: path, Operation: opName}
+			switch {
+			case opName == "":
+				endpoint.Note = "not generated: no matching CRUD operation"
+			case contains(res.Operations, opName):
+				endpoint.Operation, endpoint.Note = "", "not generated: "+opName+" is already mapped"
+			default:
+				res.Operations = append(res.Operations, opName)
+				if opName == OpUpdate {
+					res.UpdateMethod = method
+				}
+				res.learnSchemas(d, opName, op)
+			}
+			res.Endpoints = append(res.Endpoints, endpoint)
+		}
+	}
+
+	for _, res := range resources {
+		sort.SliceStable(res.Operations, func(i, j int) bool {
+			return operationIndex(res.Operations[i]) < operationIndex(res.Operations[j])
+		})
+		if res.SchemaName != "" {
+			res.Entity = openAPIEntityName(res.SchemaName)
+		} else {
+			res.Entity = openAPIEntityName(res.Path[strings.LastIndex(res.Path, "/")+1:])
+		}
+		if res.Schema == nil {
+			res.Schema, res.SchemaName = d.resolve(&openAPISchema{Ref: "#/components/schemas/" + res.Entity})
+		}
+		if res.Request == nil {
+			res.Request = res.Schema
+		}
+	}
+	return resources, skipped
+}
+
+// learnSchemas records the entity and request schemas an operation exposes.
+// Response schemas describe the stored entity; request bodies describe the
+// input DTOs.
+func (r *openAPIResource) learnSchemas(d *openAPIDocument, opName string, op *openAPIOperation) {
+	if body := op.RequestBody.schema(); body != nil && (opName == OpCreate || opName == OpUpdate) {
+		if r.Request == nil || opName == OpCreate {
+			r.Request, _ = d.resolve(body)
+		}
+	}
+
+	response := op.successSchema()
+	if response != nil && opName == OpList {
+		response, _ = d.resolve(response)
+		if response != nil {
+			response = response.Items
+		}
+	}
+	if response == nil && opName == OpCreate {
+		response = op.RequestBody.schema()
+	}
+	if response == nil || (r.Schema != nil && r.SchemaName != "") {
+		return
+	}
+	if schema, name := d.resolve(response); schema != nil && schema.Type.Name != "array" {
+		r.Schema, r.SchemaName = schema, name
+	}
+}
+
+// splitOpenAPIPath returns the collection path of a path and whether the path
+// addresses a single item. Only /things and /things/{id} are supported.
+func splitOpenAPIPath(path string) (collection string, item, ok bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	isParam := func(s string) bool { return strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") }
+	last := len(segments) - 1
+	for i, segment := range segments {
+		if segment == "" || (isParam(segment) && i != last) {
+			return "", false, false
+		}
+	}
+	if isParam(segments[last]) {
+		if last == 0 {
+			return "", false, false
+		}
+		return "/" + strings.Join(segments[:last], "/"), true, true
+	}
+	return "/" + strings.Join(segments, "/"), false, true
+}
+
+// openAPIOperationFor maps an HTTP method on a collection or item path to a
+// goca operation.
+func openAPIOperationFor(method string, item bool) string {
+	switch {
+	case !item && method == "GET":
+		return OpList
+	case !item && method == "POST":
+		return OpCreate
+	case item && method == "GET":
+		return OpRead
+	case item && (method == "PUT" || method == "PATCH"):
+		return OpUpdate
+	case item && method == "DELETE":
+		return OpDelete
+	}
+	return ""
+}
+
+// operationIndex orders operations like cliOperations.
+func operationIndex(op string) int {
+	for i, o := range cliOperations {
+		if o == op {
+			return i
+		}
+	}
+	return len(cliOperations)
+}
+
+// openAPIEntityName derives an entity name from a schema name or a path
+// segment, e.g. Pet, pets and order-items -> OrderItem.
+func openAPIEntityName(name string) string {
+	return sqlEntityName(gormColumnName(name))
+}
+
+// openAPIManagedProperties are generated by goca rather than sent by clients.
+var openAPIManagedProperties = map[string]string{
+	"id":         "entity ID",
+	"created_at": "managed timestamp",
+	"updated_at": "managed timestamp",
+	"deleted_at": "soft-delete column",
+}
+
+// openAPIFieldMapping is the outcome of mapping one schema property.
+type openAPIFieldMapping struct {
+	Property string
+	Schema   string // type and format as written in the spec
+	Field    string // field DSL entry, empty when skipped
+	GoName   string
+	Create   string // validate tag of the create DTO field
+	Update   string // validate tag of the update DTO field
+	Note     string
+}
+
+// mapSchemaFields maps the properties of the entity schema to the field DSL.
+// Validation constraints and required properties come from the request schema
+// when the spec has one, so the DTOs enforce what clients must send.
+func (d *openAPIDocument) mapSchemaFields(schema, request *openAPISchema) []openAPIFieldMapping {
+	if schema == nil {
+		return nil
+	}
+	if request == nil {
+		request = schema
+	}
+	validator := NewFieldValidator()
+
+	mappings := make([]openAPIFieldMapping, 0, len(schema.Properties.Keys))
+	for _, prop := range schema.Properties.Keys {
+		property, _ := d.resolve(schema.Properties.Values[prop])
+		m := openAPIFieldMapping{Property: prop, Schema: openAPISchemaLabel(schema.Properties.Values[prop], property)}
+		name := gormColumnName(prop)
+		if strings.ContainsAny(prop, "-. ") {
+			name = strings.NewReplacer("-", "_", ".", "_", " ", "_").Replace(strings.ToLower(prop))
+		}
+
+		if reason, ok := openAPIManagedProperties[name]; ok {
+			m.Note = "skipped: " + reason
+		} else if property == nil {
+			m.Note = "skipped: unresolved $ref"
+		} else if property.ReadOnly {
+			m.Note = "skipped: read-only"
+		} else if err := validator.ValidateReservedNames(name); err != nil || !isSQLFieldName(name) {
+			m.Note = "skipped: not usable as a Go field name"
+		} else {
+			constraints := property
+			if rp, ok := request.Properties.Values[prop]; ok {
+				constraints, _ = d.resolve(rp)
+			}
+			required := contains(request.Required, prop)
+
+			fieldType, note := d.openAPIFieldType(schema.Properties.Values[prop], property, !required)
+			m.Field, m.GoName, m.Note = name+":"+fieldType, toGoFieldName(name), note
+			m.Create, m.Update = openAPIValidateTags(constraints, fieldType, required)
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings
+}
+
+// openAPIFieldType maps a property to a field DSL type. Optional and nullable
+// scalars become pointers so an absent value is not mistaken for a zero one.
+func (d *openAPIDocument) openAPIFieldType(raw, s *openAPISchema, optional bool) (string, string) {
+	if raw.Ref != "" || s.Type.Name == "array" {
+		return FieldJSON, "stored as JSON"
+	}
+	if s.Type.Name == "object" || (s.Type.Name == "" && len(s.Properties.Keys) > 0) {
+		var subs []string
+		for _, key := range s.Properties.Keys {
+			sub, _ := d.resolve(s.Properties.Values[key])
+			subType, ok := openAPIScalarType(sub)
+			name := gormColumnName(key)
+			if !ok || !isSQLFieldName(name) {
+				return FieldJSON, "stored as JSON"
+			}
+			subs = append(subs, name+":"+subType)
+		}
+		if len(subs) == 0 {
+			return FieldJSON, "stored as JSON"
+		}
+		return FieldStructOpen + strings.Join(subs, ",") + "}", "embedded struct"
+	}
+
+	fieldType, ok := openAPIScalarType(s)
+	if !ok {
+		return FieldJSON, "stored as JSON"
+	}
+	if (optional || s.Nullable || s.Type.Nullable) && fieldType != FieldTime && fieldType != FieldBytes {
+		return "*" + fieldType, "optional"
+	}
+	return fieldType, ""
+}
+
+// openAPIScalarType maps a scalar schema to a Go type.
+func openAPIScalarType(s *openAPISchema) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	switch s.Type.Name {
+	case "string":
+		switch s.Format {
+		case "date-time", "date":
+			return FieldTime, true
+		case "byte", "binary":
+			return FieldBytes, true
+		}
+		return FieldString, true
+	case "integer":
+		switch s.Format {
+		case "int32":
+			return FieldInt32, true
+		case "int64":
+			return FieldInt64, true
+		}
+		return FieldInt, true
+	case "number":
+		if s.Format == "float" {
+			return FieldFloat32, true
+		}
+		return FieldFloat64, true
+	case "boolean":
+		return FieldBool, true
+	}
+	return "", false
+}
+
+// openAPISchemaLabel describes a property for the mapping table.
+func openAPISchemaLabel(raw, s *openAPISchema) string {
+	if raw != nil && raw.Ref != "" {
+		return strings.TrimPrefix(raw.Ref, "#/components/schemas/")
+	}
+	if s == nil {
+		return ""
+	}
+	label := s.Type.Name
+	if s.Type.Name == "array" && s.Items != nil {
+		label = "array of " + openAPISchemaLabel(s.Items, s.Items)
+	}
+	if s.Format != "" {
+		label += " (" + s.Format + ")"
+	}
+	return label
+}
+
+// openAPIStringFormats maps string formats to validator tags.
+var openAPIStringFormats = map[string]string{
+	"email":    "email",
+	"uuid":     "uuid",
+	"uri":      "url",
+	"url":      "url",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+	"hostname": "hostname",
+}
+
+// openAPIValidateTags turns the documented constraints of a property into
+// validator tags for the create and update DTOs. Update fields are optional,
+// so their checks only apply when a value is sent.
+func openAPIValidateTags(s *openAPISchema, fieldType string, required bool) (string, string) {
+	var checks []string
+	number := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+
+	switch s.Type.Name {
+	case "string":
+		if s.MinLength != nil {
+			checks = append(checks, "min="+strconv.Itoa(*s.MinLength))
+		}
+		if s.MaxLength != nil {
+			checks = append(checks, "max="+strconv.Itoa(*s.MaxLength))
+		}
+		if tag, ok := openAPIStringFormats[s.Format]; ok {
+			checks = append(checks, tag)
+		}
+	case "integer", "number":
+		switch {
+		case s.ExclusiveMinimum.Value != nil:
+			checks = append(checks, "gt="+number(*s.ExclusiveMinimum.Value))
+		case s.Minimum != nil && s.ExclusiveMinimum.Flag:
+			checks = append(checks, "gt="+number(*s.Minimum))
+		case s.Minimum != nil:
+			checks = append(checks, "gte="+number(*s.Minimum))
+		}
+		switch {
+		case s.ExclusiveMaximum.Value != nil:
+			checks = append(checks, "lt="+number(*s.ExclusiveMaximum.Value))
+		case s.Maximum != nil && s.ExclusiveMaximum.Flag:
+			checks = append(checks, "lt="+number(*s.Maximum))
+		case s.Maximum != nil:
+			checks = append(checks, "lte="+number(*s.Maximum))
+		}
+	}
+	if oneOf := openAPIOneOf(s.Enum); oneOf != "" && (s.Type.Name == "string" || s.Type.Name == "integer") {
+		checks = append(checks, oneOf)
+	}
+
+	create := checks
+	switch {
+	case required && fieldType != FieldBool && !strings.HasPrefix(fieldType, FieldStructOpen) && fieldType != FieldJSON:
+		create = append([]string{"required"}, checks...)
+	case len(checks) > 0:
+		create = append([]string{"omitempty"}, checks...)
+	}
+	return strings.Join(create, ","), strings.Join(append([]string{"omitempty"}, checks...), ",")
+}
+
+// openAPIOneOf returns a oneof tag for enum values the validator can express.
+func openAPIOneOf(values []any) string {
+	if len(values) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		s := fmt.Sprint(v)
+		if v == nil || s == "" || strings.ContainsAny(s, " ,|'\"") {
+			return ""
+		}
+		parts = append(parts, s)
+	}
+	return "oneof=" + strings.Join(parts, " ")
+}
+
+// openAPIFieldsSpec joins the mapped fields into a --fields string.
+func openAPIFieldsSpec(mappings []openAPIFieldMapping) string {
+	var fields []string
+	for _, m := range mappings {
+		if m.Field != "" {
+			fields = append(fields, m.Field)
+		}
+	}
+	return strings.Join(fields, ",")
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const petStoreSpec = `openapi: 3.0.3
+info: {title: Pets, version: "1.0"}
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/Pet'}
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/NewPet'}
+      responses:
+        201:
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Pet'}
+  /pets/{petId}:
+    patch:
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/NewPet'}
+      responses:
+        "204": {description: updated}
+    delete:
+      responses:
+        "204": {description: deleted}
+  /pets/{petId}/photos:
+    post:
+      responses:
+        "204": {description: uploaded}
+  /order-items:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [quantity]
+              properties:
+                quantity: {type: integer, format: int32, minimum: 1, maximum: 100}
+components:
+  schemas:
+    NewPet:
+      type: object
+      required: [name, species]
+      properties:
+        name: {type: string, minLength: 1, maxLength: 80}
+        species: {type: string, enum: [dog, cat]}
+        ownerEmail: {type: string, format: email}
+        weight: {type: number, format: float, minimum: 0}
+        tags: {type: array, items: {type: string}}
+        address:
+          type: object
+          properties:
+            city: {type: string}
+    Pet:
+      allOf:
+        - $ref: '#/components/schemas/NewPet'
+        - type: object
+          properties:
+            id: {type: integer, format: int64}
+            score: {type: integer, readOnly: true}
+            type: {type: string}
+`
+
+func TestOpenAPIResources(t *testing.T) {
+	doc, err := parseOpenAPIDocument([]byte(petStoreSpec))
+	require.NoError(t, err)
+
+	resources, skipped := doc.openAPIResources()
+	require.Len(t, resources, 2)
+	assert.Equal(t, []openAPIEndpoint{{Method: "POST", Path: "/pets/{petId}/photos", Note: "not generated: nested or custom path"}}, skipped)
+
+	pets := resources[0]
+	assert.Equal(t, "Pet", pets.Entity)
+	assert.Equal(t, "Pet", pets.SchemaName)
+	assert.Equal(t, "/pets", pets.Path)
+	assert.Equal(t, []string{OpCreate, OpUpdate, OpDelete, OpList}, pets.Operations)
+	assert.Equal(t, "PATCH", pets.UpdateMethod)
+	assert.Len(t, pets.Schema.Properties.Keys, 9, "allOf parts are merged")
+
+	items := resources[1]
+	assert.Equal(t, "OrderItem", items.Entity, "inline schemas are named after the path")
+	assert.Equal(t, []string{OpCreate}, items.Operations)
+
+	_, err = parseOpenAPIDocument([]byte("swagger: \"2.0\"\n"))
+	assert.Error(t, err)
+}
+
+func TestMapSchemaFields(t *testing.T) {
+	doc, err := parseOpenAPIDocument([]byte(petStoreSpec))
+	require.NoError(t, err)
+	resources, _ := doc.openAPIResources()
+
+	pets := doc.mapSchemaFields(resources[0].Schema, resources[0].Request)
+	assert.Equal(t, "name:string,species:string,owner_email:*string,weight:*float32,tags:json,address:struct{city:string}", openAPIFieldsSpec(pets))
+	assert.NoError(t, NewFieldValidator().ValidateFields(openAPIFieldsSpec(pets)))
+
+	assert.Equal(t, "required,min=1,max=80", pets[0].Create)
+	assert.Equal(t, "omitempty,min=1,max=80", pets[0].Update)
+	assert.Equal(t, "required,oneof=dog cat", pets[1].Create)
+	assert.Equal(t, "OwnerEmail", pets[2].GoName)
+	assert.Equal(t, "omitempty,email", pets[2].Create)
+	assert.Equal(t, "omitempty,gte=0", pets[3].Create)
+	assert.Equal(t, "skipped: entity ID", pets[6].Note)
+	assert.Equal(t, "skipped: read-only", pets[7].Note)
+	assert.Equal(t, "skipped: not usable as a Go field name", pets[8].Note)
+
+	items := doc.mapSchemaFields(resources[1].Schema, resources[1].Request)
+	assert.Equal(t, "quantity:int32", openAPIFieldsSpec(items))
+	assert.Equal(t, "required,gte=1,lte=100", items[0].Create)
+}
+
+func TestOpenAPIValidateTags_OpenAPI31(t *testing.T) {
+	doc, err := parseOpenAPIDocument([]byte(`{
+  "openapi": "3.1.0",
+  "components": {"schemas": {"Reading": {
+    "type": "object",
+    "required": ["value"],
+    "properties": {
+      "value": {"type": "number", "exclusiveMinimum": 0, "exclusiveMaximum": 10},
+      "note": {"type": ["string", "null"]},
+      "active": {"type": "boolean"}
+    }
+  }}}
+}`))
+	require.NoError(t, err)
+	schema, _ := doc.resolve(&openAPISchema{Ref: "#/components/schemas/Reading"})
+
+	fields := doc.mapSchemaFields(schema, schema)
+	assert.Equal(t, "value:float64,note:*string,active:*bool", openAPIFieldsSpec(fields))
+	assert.Equal(t, "required,gt=0,lt=10", fields[0].Create)
+	assert.Equal(t, "", fields[2].Create)
+	assert.Equal(t, "omitempty", fields[2].Update)
+}
+
+func TestSetStructTagKey(t *testing.T) {
+	tag := `json:"name" gorm:"not null" validate:"required"`
+	assert.Equal(t, `json:"fullName" gorm:"not null" validate:"required"`, setStructTagKey(tag, "json", "fullName"))
+	assert.Equal(t, `json:"name" gorm:"not null"`, setStructTagKey(tag, "validate", ""))
+	assert.Equal(t, `json:"name" validate:"max=5"`, setStructTagKey(`json:"name"`, "validate", "max=5"))
+}
+
+func TestGenerateOpenAPIFeature_DeclaredOperationsOnly(t *testing.T) {
+	cleanup := ensureTestUI(t)
+	defer cleanup()
+	chdirTemp(t)
+
+	doc, err := parseOpenAPIDocument([]byte(petStoreSpec))
+	require.NoError(t, err)
+	resources, _ := doc.openAPIResources()
+	pets := resources[0]
+	mappings := doc.mapSchemaFields(pets.Schema, pets.Request)
+
+	require.NoError(t, generateOpenAPIFeature(pets, openAPIFieldsSpec(mappings), DBPostgres, true, "lowercase", DTOStyleNested, nil))
+	require.NoError(t, applyOpenAPITags(pets, mappings, true, "lowercase"))
+
+	handler := readParsedGo(t, filepath.Join("internal", "handler", "http", "pet_handler.go"))
+	assert.Contains(t, handler, "func (p *PetHandler) DeletePet(")
+	assert.NotContains(t, handler, "func (p *PetHandler) GetPet(")
+
+	routes := readParsedGo(t, filepath.Join("internal", "handler", "http", "routes.go"))
+	assert.Contains(t, routes, `router.PathPrefix("/pets")`)
+	assert.Contains(t, routes, `HandleFunc("/{id}", handler.UpdatePet).Methods("PATCH")`)
+	assert.NotContains(t, routes, "handler.GetPet")
+
+	dto := readParsedGo(t, filepath.Join("internal", "usecase", "dto.go"))
+	assert.Contains(t, dto, "`json:\"ownerEmail\" validate:\"omitempty,email\"`")
+	assert.Contains(t, dto, "`json:\"name,omitempty\" validate:\"omitempty,min=1,max=80\"`")
+
+	entity, err := os.ReadFile(filepath.Join("internal", "domain", "pet.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(entity), "`json:\"ownerEmail\"`")
+}
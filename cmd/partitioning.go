@@ -0,0 +1,428 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Partition intervals of goca feature --partitioned-by.
+const (
+	PartitionDay   = "day"
+	PartitionMonth = "month"
+	PartitionYear  = "year"
+)
+
+// ValidPartitionIntervals are the intervals a table can be partitioned by.
+var ValidPartitionIntervals = []string{PartitionDay, PartitionMonth, PartitionYear}
+
+// defaultPartitionPremake is the number of periods partitions are created
+// ahead of when database.partitions.premake is not set.
+const defaultPartitionPremake = 3
+
+// partitionIntervals holds, per interval, the Go expressions the generated
+// partitions file truncates a time to its period and moves periods with, and
+// the layout of the partition name suffix.
+var partitionIntervals = map[string]struct{ start, add, layout string }{
+	PartitionDay:   {"time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)", "start.AddDate(0, 0, n)", "20060102"},
+	PartitionMonth: {"time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)", "start.AddDate(0, n, 0)", "200601"},
+	PartitionYear:  {"time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)", "start.AddDate(n, 0, 0)", "2006"},
+}
+
+// partitionSpecCompletions returns the --partitioned-by values on
+// created_at, the column most log and event entities are partitioned by.
+func partitionSpecCompletions() []string {
+	values := make([]string, len(ValidPartitionIntervals))
+	for i, interval := range ValidPartitionIntervals {
+		values[i] = "created_at:" + interval
+	}
+	return values
+}
+
+// partitionSpec is the value of --partitioned-by: the time column a table is
+// range partitioned on and the length of its partitions.
+type partitionSpec struct {
+	Column   string
+	Interval string
+}
+
+// parsePartitionSpec parses column[:interval], e.g. created_at:month. The
+// interval defaults to month.
+func parsePartitionSpec(value string) (partitionSpec, error) {
+	column, interval, _ := strings.Cut(strings.TrimSpace(value), ":")
+	spec := partitionSpec{Column: strings.TrimSpace(column), Interval: strings.TrimSpace(interval)}
+	if spec.Interval == "" {
+		spec.Interval = PartitionMonth
+	}
+	if spec.Column == "" {
+		return spec, fmt.Errorf("invalid --partitioned-by %q: expected column:interval, e.g. created_at:month", value)
+	}
+	if _, ok := partitionIntervals[spec.Interval]; !ok {
+		return spec, fmt.Errorf("invalid partition interval %q (use %s)", spec.Interval, strings.Join(ValidPartitionIntervals, ", "))
+	}
+	return spec, nil
+}
+
+// partitionField returns the entity field stored in the partition column:
+// CreatedAt for created_at, which is added when fields lack it, or the
+// time.Time field of fields stored in the column.
+func (p partitionSpec) partitionField(fields string) (Field, error) {
+	for _, field := range parseFieldsWithValidation(fields, false) {
+		if gormColumnName(field.Name) != p.Column {
+			continue
+		}
+		if field.Type != "time.Time" {
+			return Field{}, fmt.Errorf("--partitioned-by needs a time.Time column, and %s is %s", p.Column, field.Type)
+		}
+		return field, nil
+	}
+	if p.Column == "created_at" {
+		return createdAtField, nil
+	}
+	return Field{}, fmt.Errorf("--partitioned-by column %s is not one of the --fields", p.Column)
+}
+
+// createdAtField is the field added to an entity partitioned by created_at
+// whose fields do not declare it.
+var createdAtField = Field{Name: "CreatedAt", Type: "time.Time", Tag: "`json:\"created_at\" gorm:\"autoCreateTime\"`"}
+
+// validatePartitioning reports why a feature cannot be generated with
+// --partitioned-by: partitions are PostgreSQL declarative partitioning,
+// created by a GORM callback of the repository.
+func validatePartitioning(database string, spec partitionSpec, fields string, layers map[string]bool, multiTenant bool) error {
+	switch {
+	case database != DBPostgres:
+		return fmt.Errorf("--partitioned-by uses PostgreSQL declarative partitioning and does not support %s", database)
+	case !layers[LayerDomain] || !layers[LayerRepository]:
+		return errors.New("--partitioned-by needs the domain and repository layers")
+	case multiTenant:
+		return errors.New("--partitioned-by cannot be combined with --multi-tenant")
+	}
+	_, err := spec.partitionField(fields)
+	return err
+}
+
+// partitionsFile returns internal/repository/<entity>_partitions.go.
+func partitionsFile(entity string) string {
+	return filepath.Join(DirInternal, DirRepository, strings.ToLower(entity)+"_partitions.go")
+}
+
+// isPartitionedEntity reports whether the entity's table is partitioned:
+// its repository registers the partitions callback and the entity is left
+// out of GORM auto-migration, which cannot create partitioned tables.
+func isPartitionedEntity(entity string) bool {
+	_, err := os.Stat(partitionsFile(entity))
+	return err == nil
+}
+
+// writePartitionsRegistration writes the statement of a GORM repository
+// constructor registering the partitions callback of a partitioned entity.
+func writePartitionsRegistration(content *strings.Builder, entity string) {
+	if isPartitionedEntity(entity) {
+		fmt.Fprintf(content, "\tregister%sPartitions(db)\n", entity)
+	}
+}
+
+// partitionConfig returns database.partitions of the current project, with
+// partitions created three periods ahead by default.
+func partitionConfig() PartitionConfig {
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	cfg := ci.GetDatabaseConfig().Partitions
+	if cfg.Premake == 0 {
+		cfg.Premake = defaultPartitionPremake
+	}
+	return cfg
+}
+
+// generatePartitions writes internal/repository/<entity>_partitions.go. It
+// runs before the repository is generated, whose constructor registers the
+// partitions callback once the file exists.
+func generatePartitions(entity string, spec partitionSpec, fields string, sm ...*SafetyManager) error {
+	field, err := spec.partitionField(fields)
+	if err != nil {
+		return err
+	}
+	return writeGoFile(partitionsFile(entity), buildPartitions(entity, entityTableName(entity), field.Name, spec, partitionConfig()), sm...)
+}
+
+// buildPartitions returns the partitions file of entity.
+func buildPartitions(entity, table, field string, spec partitionSpec, cfg PartitionConfig) string {
+	interval := partitionIntervals[spec.Interval]
+	name := strings.ToLower(entity[:1]) + entity[1:]
+	return strings.NewReplacer(
+		"{{Entity}}", entity,
+		"{{entity}}", name,
+		"{{table}}", table,
+		"{{column}}", spec.Column,
+		"{{field}}", field,
+		"{{interval}}", spec.Interval,
+		"{{layout}}", interval.layout,
+		"{{start}}", interval.start,
+		"{{add}}", interval.add,
+		"{{retention}}", fmt.Sprint(cfg.Retention),
+		"{{premake}}", fmt.Sprint(cfg.Premake),
+	).Replace(partitionsTemplate)
+}
+
+// completePartitioning finishes a partitioned feature once its layers are
+// generated: the entity gets CreatedAt when it is the partition key, and a
+// migration creates the partitioned table GORM auto-migration cannot.
+func completePartitioning(entity string, spec partitionSpec, sm ...*SafetyManager) (string, error) {
+	path, err := findEntityFile(entity)
+	if err != nil {
+		return "", err
+	}
+	s, err := parseGoSource(path)
+	if err != nil {
+		return "", err
+	}
+	fields := readEntityFields(s.file, entity)
+	declared := false
+	for _, field := range fields {
+		declared = declared || gormColumnName(field.Name) == spec.Column
+	}
+	if !declared {
+		if _, err := addEntityFields(path, entity, []Field{createdAtField}, sm...); err != nil {
+			return "", err
+		}
+		fields = append(fields, createdAtField)
+	}
+
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	table := entityTableName(entity)
+	up := partitionedTableMigration(table, spec, fields)
+	down := fmt.Sprintf("-- Drop %s and its partitions\nDROP TABLE IF EXISTS %s;\n", table, table)
+	return writeFieldMigration(ci, "create_"+table+"_partitioned", up, down, sm...)
+}
+
+// partitionedTableMigration returns the statement creating table range
+// partitioned on the partition column. PostgreSQL requires the partition key
+// in the primary key and in unique indexes, so both include it.
+func partitionedTableMigration(table string, spec partitionSpec, fields []Field) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "-- Create %s partitioned by %s of %s. The repository creates the partition of\n", table, spec.Interval, spec.Column)
+	b.WriteString("-- each row it inserts, and Maintain creates the next ones and drops the expired.\n")
+	fmt.Fprintf(&b, "CREATE TABLE %s (\n", table)
+	b.WriteString("    id bigserial,\n")
+	var unique []string
+	for _, column := range fieldColumns(fields, DBPostgres) {
+		if column.Name == "id" {
+			continue
+		}
+		fmt.Fprintf(&b, "    %s %s", column.Name, column.Type)
+		if column.NotNull || column.Name == spec.Column {
+			b.WriteString(" NOT NULL")
+		}
+		if column.Default != "" {
+			fmt.Fprintf(&b, " DEFAULT %s", column.Default)
+		} else if column.Name == spec.Column {
+			b.WriteString(" DEFAULT CURRENT_TIMESTAMP")
+		}
+		b.WriteString(",\n")
+		if column.Unique {
+			unique = append(unique, column.Name)
+		}
+	}
+	fmt.Fprintf(&b, "    PRIMARY KEY (id, %s)\n", spec.Column)
+	fmt.Fprintf(&b, ") PARTITION BY RANGE (%s);\n", spec.Column)
+	for _, column := range unique {
+		fmt.Fprintf(&b, "CREATE UNIQUE INDEX idx_%s_%s ON %s (%s, %s);\n", table, column, table, column, spec.Column)
+	}
+	return b.String()
+}
+
+const partitionsTemplate = `package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The {{table}} table is range partitioned by {{interval}} of {{column}}
+// (goca feature --partitioned-by), one partition per period named
+// {{table}}_p<{{layout}}>, with the period start in UTC.
+const (
+	{{entity}}PartitionTable    = "{{table}}"
+	{{entity}}PartitionField    = "{{field}}"
+	{{entity}}PartitionLayout   = "{{layout}}"
+	{{entity}}PartitionCallback = "partitions:{{table}}"
+)
+
+// Defaults of {{Entity}}Partitions, from database.partitions in .goca.yaml.
+const (
+	// {{Entity}}PartitionRetention is the number of periods before the
+	// current one whose partitions are kept. 0 keeps every partition.
+	{{Entity}}PartitionRetention = {{retention}}
+	// {{Entity}}PartitionPremake is the number of periods after the current
+	// one partitions are created for ahead of time.
+	{{Entity}}PartitionPremake = {{premake}}
+)
+
+// {{entity}}PartitionsCreated caches the partitions known to exist, so only
+// the first insert of a period creates one.
+var {{entity}}PartitionsCreated sync.Map
+
+// {{entity}}Period returns the start of the period holding t.
+func {{entity}}Period(t time.Time) time.Time {
+	t = t.UTC()
+	return {{start}}
+}
+
+// {{entity}}PeriodAdd returns the start of the period n periods after start.
+func {{entity}}PeriodAdd(start time.Time, n int) time.Time {
+	return {{add}}
+}
+
+// {{entity}}PartitionName returns the partition of the period starting at start.
+func {{entity}}PartitionName(start time.Time) string {
+	return {{entity}}PartitionTable + "_p" + start.Format({{entity}}PartitionLayout)
+}
+
+// register{{Entity}}Partitions makes every insert into {{table}} create the
+// partitions of its rows first, so Save, transactions and batch inserts
+// never miss one. It registers the callback once per database.
+func register{{Entity}}Partitions(db *gorm.DB) {
+	create := db.Callback().Create()
+	if create.Get({{entity}}PartitionCallback) != nil {
+		return
+	}
+	_ = create.Before("gorm:create").Register({{entity}}PartitionCallback, ensure{{Entity}}Partitions)
+}
+
+// ensure{{Entity}}Partitions is the callback of register{{Entity}}Partitions.
+// Rows without a {{field}} get the current time, which picks their partition.
+func ensure{{Entity}}Partitions(tx *gorm.DB) {
+	if tx.Error != nil || tx.Statement.Schema == nil || tx.Statement.Table != {{entity}}PartitionTable {
+		return
+	}
+	field := tx.Statement.Schema.LookUpField({{entity}}PartitionField)
+	if field == nil {
+		return
+	}
+	ctx := tx.Statement.Context
+	ensure := func(row reflect.Value) {
+		value, zero := field.ValueOf(ctx, row)
+		t, _ := value.(time.Time)
+		if zero {
+			t = time.Now()
+			if err := field.Set(ctx, row, t); err != nil {
+				_ = tx.AddError(err)
+				return
+			}
+		}
+		if err := create{{Entity}}Partition(tx.Session(&gorm.Session{NewDB: true}), {{entity}}Period(t)); err != nil {
+			_ = tx.AddError(err)
+		}
+	}
+	rows := reflect.Indirect(tx.Statement.ReflectValue)
+	switch rows.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rows.Len(); i++ {
+			ensure(reflect.Indirect(rows.Index(i)))
+		}
+	case reflect.Struct:
+		ensure(rows)
+	}
+}
+
+// create{{Entity}}Partition creates the partition of the period starting at
+// start unless it exists.
+func create{{Entity}}Partition(db *gorm.DB, start time.Time) error {
+	name := {{entity}}PartitionName(start)
+	if _, ok := {{entity}}PartitionsCreated.Load(name); ok {
+		return nil
+	}
+	sql := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s')",
+		name, {{entity}}PartitionTable, start.Format(time.RFC3339), {{entity}}PeriodAdd(start, 1).Format(time.RFC3339))
+	if err := db.Exec(sql).Error; err != nil {
+		return fmt.Errorf("create partition %s: %w", name, err)
+	}
+	{{entity}}PartitionsCreated.Store(name, true)
+	return nil
+}
+
+// {{Entity}}Partitions creates and drops the partitions of {{table}}. Run
+// Maintain periodically, e.g. daily from a ticker or a cron job.
+type {{Entity}}Partitions struct {
+	db *gorm.DB
+	// Retention is the number of periods before the current one whose
+	// partitions Maintain keeps. 0 keeps every partition.
+	Retention int
+	// Premake is the number of periods after the current one Maintain
+	// creates partitions for.
+	Premake int
+}
+
+// New{{Entity}}Partitions returns the partitions of {{table}} with the
+// retention and premake of .goca.yaml.
+func New{{Entity}}Partitions(db *gorm.DB) *{{Entity}}Partitions {
+	return &{{Entity}}Partitions{db: db, Retention: {{Entity}}PartitionRetention, Premake: {{Entity}}PartitionPremake}
+}
+
+// CreateNext creates the partitions of the current period and the n
+// following ones.
+func (p *{{Entity}}Partitions) CreateNext(ctx context.Context, n int) error {
+	start := {{entity}}Period(time.Now())
+	for i := 0; i <= n; i++ {
+		if err := create{{Entity}}Partition(p.db.WithContext(ctx), {{entity}}PeriodAdd(start, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List returns the partitions of {{table}}, oldest first.
+func (p *{{Entity}}Partitions) List(ctx context.Context) ([]string, error) {
+	var names []string
+	err := p.db.WithContext(ctx).Raw(` + "`" + `SELECT c.relname FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class t ON t.oid = i.inhparent
+		WHERE t.relname = ? ORDER BY c.relname` + "`" + `, {{entity}}PartitionTable).Scan(&names).Error
+	return names, err
+}
+
+// DropBefore drops the partitions whose period ends at or before cutoff and
+// returns their names. Partitions not named by goca are left alone.
+func (p *{{Entity}}Partitions) DropBefore(ctx context.Context, cutoff time.Time) ([]string, error) {
+	names, err := p.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var dropped []string
+	for _, name := range names {
+		start, err := time.Parse({{entity}}PartitionLayout, strings.TrimPrefix(name, {{entity}}PartitionTable+"_p"))
+		if err != nil || {{entity}}PeriodAdd(start, 1).After(cutoff) {
+			continue
+		}
+		if err := p.db.WithContext(ctx).Exec("DROP TABLE IF EXISTS " + name).Error; err != nil {
+			return dropped, fmt.Errorf("drop partition %s: %w", name, err)
+		}
+		{{entity}}PartitionsCreated.Delete(name)
+		dropped = append(dropped, name)
+	}
+	return dropped, nil
+}
+
+// Maintain creates the partitions of the next Premake periods and, with a
+// Retention, drops those older than Retention periods. It returns the
+// dropped partitions.
+func (p *{{Entity}}Partitions) Maintain(ctx context.Context) ([]string, error) {
+	if err := p.CreateNext(ctx, p.Premake); err != nil {
+		return nil, err
+	}
+	if p.Retention <= 0 {
+		return nil, nil
+	}
+	return p.DropBefore(ctx, {{entity}}PeriodAdd({{entity}}Period(time.Now()), -p.Retention))
+}
+`
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePartitionSpec(t *testing.T) {
+	spec, err := parsePartitionSpec("created_at:day")
+	require.NoError(t, err)
+	assert.Equal(t, partitionSpec{Column: "created_at", Interval: PartitionDay}, spec)
+
+	spec, err = parsePartitionSpec("occurred_at")
+	require.NoError(t, err)
+	assert.Equal(t, PartitionMonth, spec.Interval)
+
+	_, err = parsePartitionSpec("created_at:week")
+	assert.ErrorContains(t, err, "invalid partition interval")
+	_, err = parsePartitionSpec(":month")
+	assert.Error(t, err)
+}
+
+func TestValidatePartitioning(t *testing.T) {
+	layers := allFeatureLayers()
+	createdAt := partitionSpec{Column: "created_at", Interval: PartitionMonth}
+	assert.NoError(t, validatePartitioning(DBPostgres, createdAt, "kind:string", layers, false))
+	assert.ErrorContains(t, validatePartitioning(DBMySQL, createdAt, "kind:string", layers, false), "does not support mysql")
+	assert.ErrorContains(t, validatePartitioning(DBPostgres, createdAt, "kind:string", layers, true), "--multi-tenant")
+
+	occurredAt := partitionSpec{Column: "occurred_at", Interval: PartitionDay}
+	assert.NoError(t, validatePartitioning(DBPostgres, occurredAt, "kind:string,occurred_at:time.Time", layers, false))
+	assert.ErrorContains(t, validatePartitioning(DBPostgres, occurredAt, "kind:string", layers, false), "not one of the --fields")
+	assert.ErrorContains(t, validatePartitioning(DBPostgres, occurredAt, "occurred_at:string", layers, false), "needs a time.Time column")
+}
+
+func TestBuildPartitions(t *testing.T) {
+	src := buildPartitions("AuditEvent", "audit_events", "CreatedAt", partitionSpec{Column: "created_at", Interval: PartitionDay}, PartitionConfig{Retention: 30, Premake: 7})
+	_, err := parser.ParseFile(token.NewFileSet(), "audit_event_partitions.go", src, parser.ParseComments)
+	require.NoError(t, err, src)
+	assert.NotContains(t, src, "{{")
+	assert.Contains(t, src, "func registerAuditEventPartitions(db *gorm.DB) {")
+	assert.Contains(t, src, `auditEventPartitionLayout   = "20060102"`)
+	assert.Contains(t, src, "return start.AddDate(0, 0, n)")
+	assert.Contains(t, src, "AuditEventPartitionRetention = 30")
+	assert.Contains(t, src, "AuditEventPartitionPremake = 7")
+	assert.Contains(t, src, "func (p *AuditEventPartitions) Maintain(ctx context.Context) ([]string, error) {")
+}
+
+func TestPartitionedTableMigration(t *testing.T) {
+	fields := []Field{
+		{Name: "Code", Type: "string", Tag: "`json:\"code\" gorm:\"uniqueIndex\"`"},
+		{Name: "Amount", Type: "float64", Tag: "`json:\"amount\"`"},
+		createdAtField,
+	}
+	up := partitionedTableMigration("payments", partitionSpec{Column: "created_at", Interval: PartitionMonth}, fields)
+	assert.Contains(t, up, "CREATE TABLE payments (\n    id bigserial,\n")
+	assert.Contains(t, up, "    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,\n    PRIMARY KEY (id, created_at)\n) PARTITION BY RANGE (created_at);\n")
+	assert.Contains(t, up, "CREATE UNIQUE INDEX idx_payments_code ON payments (code, created_at);\n")
+}
+
+func TestPartitionedFeature(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	sm := NewSafetyManager(false, true, false)
+	spec := partitionSpec{Column: "created_at", Interval: PartitionMonth}
+
+	require.NoError(t, generatePartitions("Event", spec, "kind:string", sm))
+	assert.True(t, isPartitionedEntity("Event"))
+	readParsedGo(t, partitionsFile("Event"))
+
+	repoDir := filepath.Join(DirInternal, DirRepository)
+	generateGormRepositoryWithFields(repoDir, "Event", "Postgres", parseFieldsWithValidation("kind:string", false), false, false, sm)
+	repo := readParsedGo(t, filepath.Join(repoDir, "postgres_event_repository.go"))
+	assert.Contains(t, repo, "func NewPostgresEventRepository(db *gorm.DB) EventRepository {\n\tregisterEventPartitions(db)\n")
+
+	require.NoError(t, generateEntity("Event", "kind:string", false, false, false, false, false, false, false, false, "lowercase", sm))
+	migration, err := completePartitioning("Event", spec, sm)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("migrations", "001_create_events_partitioned.up.sql"), migration)
+	up, err := os.ReadFile(migration)
+	require.NoError(t, err)
+	assert.Contains(t, string(up), ") PARTITION BY RANGE (created_at);")
+	entity := readParsedGo(t, filepath.Join(DirInternal, DirDomain, "event.go"))
+	assert.Contains(t, entity, "CreatedAt time.Time `json:\"created_at\" gorm:\"autoCreateTime\"`")
+}
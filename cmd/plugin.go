@@ -0,0 +1,801 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// pluginProtocol is the version of the JSON protocol goca speaks with plugins.
+const pluginProtocol = "goca/v1"
+
+// pluginTimeout bounds a single plugin call.
+const pluginTimeout = 2 * time.Minute
+
+// pluginDir is the project directory goca plugin install puts plugins in.
+var pluginDir = filepath.Join(".goca", "plugins")
+
+// Plugin hooks. The after:<layer> hooks run once a command has generated the
+// layer for an entity; after:feature runs once goca feature has generated all
+// of its layers. describe asks a plugin about itself.
+const (
+	pluginHookEntity     = "after:entity"
+	pluginHookUseCase    = "after:usecase"
+	pluginHookRepository = "after:repository"
+	pluginHookHandler    = "after:handler"
+	pluginHookFeature    = "after:feature"
+	pluginHookDescribe   = "describe"
+)
+
+// pluginHooks are the hooks plugins can be run for.
+var pluginHooks = []string{pluginHookEntity, pluginHookUseCase, pluginHookRepository, pluginHookHandler, pluginHookFeature}
+
+// featureLayerHooks maps the layers of goca feature to their hooks.
+var featureLayerHooks = []struct{ layer, hook string }{
+	{LayerDomain, pluginHookEntity},
+	{LayerUseCase, pluginHookUseCase},
+	{LayerRepository, pluginHookRepository},
+	{LayerHandler, pluginHookHandler},
+}
+
+// featurePluginHooks returns the hooks goca feature runs for the selected
+// layers, ending with after:feature.
+func featurePluginHooks(layers map[string]bool) []string {
+	var hooks []string
+	for _, lh := range featureLayerHooks {
+		if layers[lh.layer] {
+			hooks = append(hooks, lh.hook)
+		}
+	}
+	return append(hooks, pluginHookFeature)
+}
+
+// pluginNamePattern matches plugin names: the part of goca-<name> after the
+// prefix.
+var pluginNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// pluginRequest is the JSON document a plugin reads from stdin.
+type pluginRequest struct {
+	Protocol string            `json:"protocol"`
+	Hook     string            `json:"hook"`
+	Project  pluginProject     `json:"project"`
+	Entity   *pluginEntity     `json:"entity,omitempty"`
+	Files    []string          `json:"files,omitempty"`
+	Config   map[string]string `json:"config,omitempty"`
+	DryRun   bool              `json:"dry_run"`
+}
+
+// pluginProject describes the project a plugin runs in.
+type pluginProject struct {
+	Name     string `json:"name"`
+	Module   string `json:"module"`
+	Database string `json:"database"`
+	Root     string `json:"root"`
+}
+
+// pluginEntity describes the entity a hook runs for.
+type pluginEntity struct {
+	Name   string        `json:"name"`
+	Table  string        `json:"table"`
+	Fields []pluginField `json:"fields,omitempty"`
+}
+
+// pluginField is a field of the entity struct.
+type pluginField struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Column string `json:"column"`
+	JSON   string `json:"json,omitempty"`
+	Tag    string `json:"tag,omitempty"`
+}
+
+// pluginResponse is the JSON document a plugin writes to stdout. An empty
+// output is an empty response: the plugin has nothing to do for the hook.
+type pluginResponse struct {
+	Files    []pluginFile `json:"files"`
+	Messages []string     `json:"messages"`
+	Error    string       `json:"error"`
+}
+
+// pluginFile is a file a plugin asks goca to write, relative to the project
+// root.
+type pluginFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// pluginInfo is a plugin's response to the describe hook.
+type pluginInfo struct {
+	Name        string   `json:"name"`
+	Version     string   `json:"version"`
+	Description string   `json:"description"`
+	Hooks       []string `json:"hooks"`
+}
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Install, list and run generator plugins",
+	Long: `Manages plugins: external generators that run after goca's built-in ones,
+e.g. to generate Terraform for a feature or a Backstage catalog-info.yaml.
+
+A plugin is an executable named goca-<name>, looked up in .goca/plugins and
+then on the PATH. goca runs it once per hook with a JSON request on stdin and
+reads a JSON response from stdout; whatever the plugin prints on stderr is
+shown to the user.
+
+The request describes the hook, the project, the entity and the files the
+built-in generators wrote:
+
+  {
+    "protocol": "goca/v1",
+    "hook": "after:feature",
+    "project": {"name": "shop", "module": "github.com/acme/shop", "database": "postgres", "root": "/src/shop"},
+    "entity": {"name": "Product", "table": "products", "fields": [
+      {"name": "Name", "type": "string", "column": "name", "json": "name", "tag": "json:\"name\""}
+    ]},
+    "files": ["internal/domain/product.go"],
+    "config": {"region": "eu-west-1"},
+    "dry_run": false
+  }
+
+The response lists the files to write, relative to the project root, and
+messages to show. Files go through the same checks as generated files:
+existing files are only overwritten with --force, and --dry-run previews them.
+
+  {"files": [{"path": "deploy/product.tf", "content": "..."}], "messages": ["..."]}
+
+A response with "error" set fails the plugin. A plugin that has nothing to do
+for a hook prints nothing.
+
+Hooks:
+  after:entity      goca entity, and the domain layer of goca feature
+  after:usecase     goca usecase, and the use case layer of goca feature
+  after:repository  goca repository, and the repository layer of goca feature
+  after:handler     goca handler, and the handler layer of goca feature
+  after:feature     goca feature, after all of its layers
+  describe          goca plugin list and install; answer with
+                    {"name", "version", "description", "hooks"}
+
+Plugins run for the hooks of generation commands when they are enabled in
+.goca.yaml, in ascending priority order:
+
+  features:
+    plugins:
+      - name: terraform
+        enabled: true
+        priority: 10
+        config:
+          region: eu-west-1`,
+}
+
+var pluginInstallCmd = &cobra.Command{
+	Use:   "install <path|package[@version]>",
+	Short: "Install a plugin into .goca/plugins and enable it",
+	Long: `Installs a plugin into .goca/plugins and enables it in .goca.yaml.
+
+The source is an executable, which is copied, a directory holding a main
+package, which is built with go build, or the import path of a remote
+package, which is built with go install (at @latest unless a version is
+given). The plugin is named after the executable, directory or package
+without its goca- prefix; override the name with --name.
+
+Examples:
+  goca plugin install ./bin/goca-backstage
+  goca plugin install ./tools/goca-catalog
+  goca plugin install github.com/acme/goca-terraform@v1.2.0
+  goca plugin install github.com/acme/generators/cmd/tf --name terraform`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("name")
+
+		ui.Header("Goca Plugin Install")
+		ui.Blank()
+		plugin, err := installPlugin(args[0], name)
+		if err != nil {
+			return err
+		}
+		if err := registerPlugin(plugin); err != nil {
+			return err
+		}
+
+		ui.KeyValue("Plugin", plugin.Name)
+		if plugin.Version != "" {
+			ui.KeyValue("Version", plugin.Version)
+		}
+		pluginPath, _ := findPlugin(plugin.Name)
+		ui.KeyValue("Installed", pluginPath)
+		if info, err := describePlugin(pluginPath); err != nil {
+			ui.Warning(fmt.Sprintf("The plugin does not answer describe: %v", err))
+		} else if len(info.Hooks) > 0 {
+			ui.KeyValue("Hooks", strings.Join(info.Hooks, ", "))
+		}
+		ui.Blank()
+		ui.Success(fmt.Sprintf("Plugin '%s' installed and enabled in .goca.yaml", plugin.Name))
+		ui.NextSteps([]string{
+			"Configure it under features.plugins in .goca.yaml",
+			fmt.Sprintf("Try it: goca plugin run %s <Entity> --dry-run", plugin.Name),
+		})
+		return nil
+	},
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the project's plugins",
+	Long: `Lists the plugins configured in .goca.yaml and the plugins installed in
+.goca/plugins, with the hooks they answer.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ci := NewConfigIntegration()
+		_ = ci.LoadConfigForProject()
+
+		ui.Header("Goca Plugins")
+		ui.Blank()
+		plugins := listedPlugins(ci)
+		if len(plugins) == 0 {
+			ui.Info("No plugins. Install one with: goca plugin install <path|package>")
+			return nil
+		}
+
+		rows := make([][]string, 0, len(plugins))
+		for _, p := range plugins {
+			status := "disabled"
+			if p.Enabled {
+				status = "enabled"
+			}
+			location, hooks := "not installed", ""
+			if pluginPath, err := findPlugin(p.Name); err == nil {
+				location = pluginPath
+				if info, err := describePlugin(pluginPath); err == nil {
+					hooks = strings.Join(info.Hooks, ", ")
+				}
+			}
+			rows = append(rows, []string{p.Name, p.Version, status, fmt.Sprint(p.Priority), hooks, location})
+		}
+		ui.Table([]string{"Plugin", "Version", "Status", "Priority", "Hooks", "Location"}, rows)
+		return nil
+	},
+}
+
+var pluginRunCmd = &cobra.Command{
+	Use:   "run <plugin> [entity]",
+	Short: "Run a plugin for a hook",
+	Long: `Runs a plugin for one hook, after:feature by default, whether or not it is
+enabled in .goca.yaml. With an entity, the request describes the entity in
+internal/domain.
+
+Examples:
+  goca plugin run terraform Product
+  goca plugin run backstage --hook after:handler Order --dry-run`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hook, _ := cmd.Flags().GetString("hook")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		force, _ := cmd.Flags().GetBool("force")
+		backup, _ := cmd.Flags().GetBool("backup")
+
+		if !isValidPluginHook(hook) {
+			return fmt.Errorf("invalid hook %q: use one of %s", hook, strings.Join(pluginHooks, ", "))
+		}
+		entity := ""
+		if len(args) == 2 {
+			entity = args[1]
+			if _, err := findEntityFile(entity); err != nil {
+				return err
+			}
+		}
+
+		ci := NewConfigIntegration()
+		_ = ci.LoadConfigForProject()
+		plugin := PluginConfig{Name: args[0]}
+		for _, p := range ci.GetFeatureConfig().Plugins {
+			if p.Name == plugin.Name {
+				plugin = p
+			}
+		}
+
+		ui.Header("Goca Plugin Run")
+		ui.Blank()
+		ui.KeyValue("Plugin", plugin.Name)
+		ui.KeyValue("Hook", hook)
+		if entity != "" {
+			ui.KeyValue("Entity", entity)
+		}
+		ui.Blank()
+
+		sm := NewSafetyManager(dryRun, force, backup)
+		if dryRun {
+			ui.DryRun("Previewing changes without creating files")
+		}
+		if err := runPlugin(plugin, newPluginRequest(ci, hook, entity, nil, dryRun), sm); err != nil {
+			return fmt.Errorf("plugin %s: %w", plugin.Name, err)
+		}
+
+		if dryRun {
+			sm.PrintSummary()
+			return nil
+		}
+		ui.Success(fmt.Sprintf("Plugin '%s' ran for %s", plugin.Name, hook))
+		return nil
+	},
+}
+
+// isValidPluginHook reports whether hook is a hook plugins can be run for.
+func isValidPluginHook(hook string) bool {
+	for _, h := range pluginHooks {
+		if h == hook {
+			return true
+		}
+	}
+	return false
+}
+
+// pluginExecutable returns the executable name of a plugin.
+func pluginExecutable(name string) string {
+	if runtime.GOOS == "windows" {
+		return "goca-" + name + ".exe"
+	}
+	return "goca-" + name
+}
+
+// findPlugin returns the path of a plugin's executable: .goca/plugins/goca-<name>,
+// or goca-<name> on the PATH.
+func findPlugin(name string) (string, error) {
+	local := filepath.Join(pluginDir, pluginExecutable(name))
+	if info, err := os.Stat(local); err == nil && !info.IsDir() {
+		return local, nil
+	}
+	if found, err := exec.LookPath(pluginExecutable(name)); err == nil {
+		return found, nil
+	}
+	return "", fmt.Errorf("plugin %s not found: no %s in %s or on the PATH", name, pluginExecutable(name), pluginDir)
+}
+
+// enabledPlugins returns the plugins enabled in the configuration, in
+// ascending priority order.
+func enabledPlugins(ci *ConfigIntegration) []PluginConfig {
+	var plugins []PluginConfig
+	for _, p := range ci.GetFeatureConfig().Plugins {
+		if p.Enabled && p.Name != "" {
+			plugins = append(plugins, p)
+		}
+	}
+	sort.SliceStable(plugins, func(i, j int) bool { return plugins[i].Priority < plugins[j].Priority })
+	return plugins
+}
+
+// listedPlugins returns the plugins in the configuration followed by the
+// plugins installed in .goca/plugins that the configuration does not list.
+func listedPlugins(ci *ConfigIntegration) []PluginConfig {
+	plugins := append([]PluginConfig(nil), ci.GetFeatureConfig().Plugins...)
+	listed := make(map[string]bool)
+	for _, p := range plugins {
+		listed[p.Name] = true
+	}
+	entries, _ := os.ReadDir(pluginDir)
+	for _, entry := range entries {
+		name := strings.TrimSuffix(strings.TrimPrefix(entry.Name(), "goca-"), ".exe")
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), "goca-") || listed[name] {
+			continue
+		}
+		plugins = append(plugins, PluginConfig{Name: name})
+	}
+	return plugins
+}
+
+// runPluginHooks runs the enabled plugins for each hook, after the built-in
+// generators of a command have written files through sm. Plugin failures are
+// reported as warnings: the generated code is already in place.
+func runPluginHooks(entity string, sm *SafetyManager, hooks ...string) {
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	plugins := enabledPlugins(ci)
+	if len(plugins) == 0 {
+		return
+	}
+
+	files := append([]string(nil), sm.GetCreatedFiles()...)
+	for _, hook := range hooks {
+		req := newPluginRequest(ci, hook, entity, files, sm.DryRun)
+		for _, p := range plugins {
+			if err := runPlugin(p, req, sm); err != nil {
+				ui.Warning(fmt.Sprintf("Plugin %s (%s): %v", p.Name, hook, err))
+			}
+		}
+	}
+}
+
+// newPluginRequest returns the request for a hook. entity may be empty.
+func newPluginRequest(ci *ConfigIntegration, hook, entity string, files []string, dryRun bool) pluginRequest {
+	root, _ := os.Getwd()
+	req := pluginRequest{
+		Protocol: pluginProtocol,
+		Hook:     hook,
+		Project: pluginProject{
+			Name:     ci.GetProjectConfig().Name,
+			Module:   getModuleName(),
+			Database: ci.GetDatabaseType(""),
+			Root:     root,
+		},
+		Files:  make([]string, 0, len(files)),
+		DryRun: dryRun,
+	}
+	for _, file := range files {
+		req.Files = append(req.Files, filepath.ToSlash(file))
+	}
+	if entity != "" {
+		req.Entity = newPluginEntity(entity)
+	}
+	return req
+}
+
+// newPluginEntity describes an entity. The fields are read from its struct in
+// internal/domain; they are omitted when it has not been written, as in
+// dry runs.
+func newPluginEntity(entity string) *pluginEntity {
+	info := &pluginEntity{Name: entity, Table: entityTableName(entity)}
+	file, err := findEntityFile(entity)
+	if err != nil {
+		return info
+	}
+	s, err := parseGoSource(file)
+	if err != nil {
+		return info
+	}
+	for _, field := range readEntityFields(s.file, entity) {
+		tag := strings.Trim(field.Tag, "`")
+		column := gormTagOptions(field.Tag)["column"]
+		if column == "" {
+			column = gormColumnName(field.Name)
+		}
+		jsonName, _, _ := strings.Cut(reflect.StructTag(tag).Get("json"), ",")
+		info.Fields = append(info.Fields, pluginField{
+			Name:   field.Name,
+			Type:   field.Type,
+			Column: column,
+			JSON:   jsonName,
+			Tag:    tag,
+		})
+	}
+	return info
+}
+
+// runPlugin runs a plugin with the request, with its configuration, and
+// writes the files it returns through sm.
+func runPlugin(p PluginConfig, req pluginRequest, sm *SafetyManager) error {
+	pluginPath, err := findPlugin(p.Name)
+	if err != nil {
+		return err
+	}
+	req.Config = p.Config
+
+	var resp pluginResponse
+	if err := callPlugin(pluginPath, req, &resp); err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+	for _, message := range resp.Messages {
+		ui.Info(fmt.Sprintf("[%s] %s", p.Name, message))
+	}
+	for _, file := range resp.Files {
+		target, err := pluginFilePath(file.Path)
+		if err != nil {
+			return err
+		}
+		write := writeFile
+		if strings.HasSuffix(target, ".go") {
+			write = writeGoFile
+		}
+		if err := write(target, file.Content, sm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pluginFilePath validates the path of a file a plugin returned: plugins may
+// only write inside the project.
+func pluginFilePath(p string) (string, error) {
+	clean := path.Clean(filepath.ToSlash(p))
+	if p == "" || path.IsAbs(clean) || filepath.IsAbs(p) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("invalid file path %q: plugins may only write files inside the project", p)
+	}
+	return filepath.FromSlash(clean), nil
+}
+
+// describePlugin asks a plugin about itself.
+func describePlugin(pluginPath string) (pluginInfo, error) {
+	var info pluginInfo
+	err := callPlugin(pluginPath, pluginRequest{Protocol: pluginProtocol, Hook: pluginHookDescribe}, &info)
+	return info, err
+}
+
+// callPlugin runs a plugin executable with req as JSON on stdin and decodes
+// its stdout into resp. Empty output leaves resp untouched.
+func callPlugin(pluginPath string, req pluginRequest, resp any) error {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("encoding request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pluginTimeout)
+	defer cancel()
+	var stdout bytes.Buffer
+	c := exec.CommandContext(ctx, pluginPath) //#nosec G204 -- the plugin was installed by the user
+	c.Stdin = bytes.NewReader(input)
+	c.Stdout = &stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("timed out after %s", pluginTimeout)
+		}
+		return err
+	}
+
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(stdout.Bytes(), resp); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
+
+// installPlugin installs a plugin into .goca/plugins from an executable, a
+// local Go package or a remote Go package, and returns its configuration
+// entry.
+func installPlugin(source, name string) (PluginConfig, error) {
+	plugin := PluginConfig{Name: name, Enabled: true}
+	if err := os.MkdirAll(pluginDir, 0o755); err != nil {
+		return plugin, fmt.Errorf("creating %s: %w", pluginDir, err)
+	}
+	dir, err := filepath.Abs(pluginDir)
+	if err != nil {
+		return plugin, err
+	}
+
+	info, statErr := os.Stat(source)
+	switch {
+	case statErr == nil && !info.IsDir():
+		if plugin.Name == "" {
+			plugin.Name = pluginNameFrom(filepath.Base(source))
+		}
+		if err := validatePluginName(plugin.Name); err != nil {
+			return plugin, err
+		}
+		return plugin, copyPluginExecutable(source, filepath.Join(dir, pluginExecutable(plugin.Name)))
+
+	case statErr == nil:
+		// A package of the project or next to it: build it in place.
+		abs, err := filepath.Abs(source)
+		if err != nil {
+			return plugin, err
+		}
+		if plugin.Name == "" {
+			plugin.Name = strings.TrimPrefix(filepath.Base(abs), "goca-")
+		}
+		if err := validatePluginName(plugin.Name); err != nil {
+			return plugin, err
+		}
+		ui.Info(fmt.Sprintf("Building %s...", source))
+		build := exec.Command("go", "build", "-o", filepath.Join(dir, pluginExecutable(plugin.Name)), ".") //#nosec G204 -- the package is given by the user
+		build.Dir = abs
+		return plugin, runGoTool(build, "go build "+source)
+	}
+
+	pkg, version, _ := strings.Cut(source, "@")
+	if version == "" {
+		version = "latest"
+	}
+	binary := path.Base(pkg)
+	if plugin.Name == "" {
+		plugin.Name = strings.TrimPrefix(binary, "goca-")
+	}
+	if err := validatePluginName(plugin.Name); err != nil {
+		return plugin, err
+	}
+	plugin.Version = version
+
+	ui.Info(fmt.Sprintf("Building %s@%s...", pkg, version))
+	install := exec.Command("go", "install", pkg+"@"+version) //#nosec G204 -- the package is given by the user
+	install.Env = append(os.Environ(), "GOBIN="+dir)
+	if err := runGoTool(install, fmt.Sprintf("go install %s@%s", pkg, version)); err != nil {
+		return plugin, err
+	}
+
+	// go install names the executable after the package.
+	built := filepath.Join(dir, binary)
+	if runtime.GOOS == "windows" {
+		built += ".exe"
+	}
+	if target := filepath.Join(dir, pluginExecutable(plugin.Name)); built != target {
+		if err := os.Rename(built, target); err != nil {
+			return plugin, fmt.Errorf("renaming %s: %w", built, err)
+		}
+	}
+	return plugin, nil
+}
+
+// runGoTool runs a go command, showing its output.
+func runGoTool(c *exec.Cmd, description string) error {
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("%s: %w", description, err)
+	}
+	return nil
+}
+
+// pluginNameFrom returns the plugin name of an executable.
+func pluginNameFrom(base string) string {
+	return strings.TrimPrefix(strings.TrimSuffix(base, filepath.Ext(base)), "goca-")
+}
+
+// validatePluginName checks that name can name a goca-<name> executable.
+func validatePluginName(name string) error {
+	if !pluginNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid plugin name %q: use lowercase letters, digits, - and _, or set --name", name)
+	}
+	return nil
+}
+
+// copyPluginExecutable copies a plugin executable to target.
+func copyPluginExecutable(source, target string) error {
+	in, err := os.Open(source) //#nosec G304 -- the plugin is given by the user
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o755) //#nosec G302 -- plugins are executables
+	if err != nil {
+		return fmt.Errorf("installing %s: %w", target, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("installing %s: %w", target, err)
+	}
+	return out.Close()
+}
+
+// registerPlugin enables a plugin in the project's configuration file. Other
+// settings and the plugin's existing configuration are kept. Projects without
+// a configuration file get a .goca.yaml naming the project and its module,
+// which a valid configuration requires.
+func registerPlugin(plugin PluginConfig) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	var raw []byte
+	configPath := NewConfigManager().findConfigFile(wd)
+	if configPath == "" {
+		configPath = filepath.Join(wd, ".goca.yaml")
+		raw = []byte(fmt.Sprintf("project:\n  name: %q\n  module: %q\n", filepath.Base(wd), getModuleName()))
+	} else if raw, err = os.ReadFile(configPath); err != nil { //#nosec G304 -- the project's configuration file
+		return fmt.Errorf("reading %s: %w", configPath, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return fmt.Errorf("parsing %s: %w", configPath, err)
+	}
+	if err := setPluginEntry(&doc, plugin); err != nil {
+		return fmt.Errorf("updating %s: %w", configPath, err)
+	}
+
+	var buf strings.Builder
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("encoding %s: %w", configPath, err)
+	}
+	_ = enc.Close()
+	if err := os.WriteFile(configPath, []byte(buf.String()), 0o644); err != nil { //#nosec G306 -- configuration file
+		return fmt.Errorf("writing %s: %w", configPath, err)
+	}
+	return nil
+}
+
+// setPluginEntry adds the plugin to features.plugins in a yaml.Node document,
+// or enables and updates the version of its existing entry.
+func setPluginEntry(doc *yaml.Node, plugin PluginConfig) error {
+	if doc.Kind == 0 {
+		doc.Kind = yaml.DocumentNode
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode}}
+	}
+	features, err := mappingChild(doc.Content[0], "features", yaml.MappingNode)
+	if err != nil {
+		return err
+	}
+	plugins, err := mappingChild(features, "plugins", yaml.SequenceNode)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range plugins.Content {
+		if i := findMappingKey(entry, "name"); entry.Kind == yaml.MappingNode && i >= 0 && entry.Content[i+1].Value == plugin.Name {
+			setMappingScalar(entry, "enabled", "true", "!!bool")
+			if plugin.Version != "" {
+				setMappingScalar(entry, "version", plugin.Version, "!!str")
+			}
+			return nil
+		}
+	}
+
+	entry := &yaml.Node{Kind: yaml.MappingNode}
+	setMappingScalar(entry, "name", plugin.Name, "!!str")
+	if plugin.Version != "" {
+		setMappingScalar(entry, "version", plugin.Version, "!!str")
+	}
+	setMappingScalar(entry, "enabled", "true", "!!bool")
+	plugins.Content = append(plugins.Content, entry)
+	return nil
+}
+
+// mappingChild returns the value of key in a mapping node, adding an empty
+// node of the given kind when the key is missing or null.
+func mappingChild(node *yaml.Node, key string, kind yaml.Kind) (*yaml.Node, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a mapping around %q", key)
+	}
+	i := findMappingKey(node, key)
+	if i < 0 {
+		child := &yaml.Node{Kind: kind}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, child)
+		return child, nil
+	}
+	child := node.Content[i+1]
+	if child.Kind == yaml.ScalarNode && child.Tag == "!!null" {
+		*child = yaml.Node{Kind: kind}
+	}
+	if child.Kind != kind {
+		return nil, fmt.Errorf("unexpected value for %q", key)
+	}
+	return child, nil
+}
+
+// setMappingScalar sets key to a scalar value in a mapping node.
+func setMappingScalar(node *yaml.Node, key, value, tag string) {
+	if i := findMappingKey(node, key); i >= 0 {
+		node.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Value: value, Tag: tag}
+		return
+	}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Value: value, Tag: tag},
+	)
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginInstallCmd)
+	pluginCmd.AddCommand(pluginListCmd)
+	pluginCmd.AddCommand(pluginRunCmd)
+
+	pluginInstallCmd.Flags().String("name", "", "Plugin name (default: the executable or package name without goca-)")
+
+	pluginRunCmd.Flags().String("hook", pluginHookFeature, "Hook to run the plugin for")
+	pluginRunCmd.Flags().Bool("dry-run", false, "Preview the plugin's files without writing them")
+	pluginRunCmd.Flags().Bool("force", false, "Overwrite existing files")
+	pluginRunCmd.Flags().Bool("backup", false, "Backup files before overwriting")
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// writeTestPlugin writes a shell plugin that saves its request to
+// <name>-request.json and prints response.
+func writeTestPlugin(t *testing.T, dir, name, response string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell plugins need a POSIX shell")
+	}
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	script := "#!/bin/sh\n" +
+		"input=$(cat)\n" +
+		"case \"$input\" in\n" +
+		"  *'\"hook\":\"describe\"'*) echo '{\"name\":\"" + name + "\",\"hooks\":[\"after:feature\"]}' ;;\n" +
+		"  *) printf '%s\\n' \"$input\" >> " + name + "-request.json; cat <<'EOF'\n" + response + "\nEOF\n" +
+		"  ;;\n" +
+		"esac\n"
+	path := filepath.Join(dir, "goca-"+name)
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o755))
+	return path
+}
+
+// readPluginRequests returns the requests a test plugin received.
+func readPluginRequests(t *testing.T, name string) []pluginRequest {
+	t.Helper()
+	f, err := os.Open(name + "-request.json")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	var requests []pluginRequest
+	dec := json.NewDecoder(f)
+	for dec.More() {
+		var req pluginRequest
+		require.NoError(t, dec.Decode(&req))
+		requests = append(requests, req)
+	}
+	return requests
+}
+
+func TestPluginFilePath(t *testing.T) {
+	for _, p := range []string{"", "/etc/passwd", "../outside.tf", "deploy/../../outside.tf"} {
+		_, err := pluginFilePath(p)
+		assert.Error(t, err, p)
+	}
+	got, err := pluginFilePath("deploy/./product.tf")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("deploy", "product.tf"), got)
+}
+
+func TestEnabledPluginsOrder(t *testing.T) {
+	ci := &ConfigIntegration{config: &GocaConfig{Features: FeatureConfig{Plugins: []PluginConfig{
+		{Name: "late", Enabled: true, Priority: 20},
+		{Name: "off", Enabled: false},
+		{Name: "early", Enabled: true, Priority: 5},
+	}}}}
+	var names []string
+	for _, p := range enabledPlugins(ci) {
+		names = append(names, p.Name)
+	}
+	assert.Equal(t, []string{"early", "late"}, names)
+	assert.Equal(t, []string{pluginHookEntity, pluginHookHandler, pluginHookFeature},
+		featurePluginHooks(map[string]bool{LayerDomain: true, LayerHandler: true}))
+}
+
+func TestRunPluginHooks(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	writeTestPlugin(t, pluginDir, "catalog",
+		`{"files":[{"path":"catalog-info.yaml","content":"kind: Component\n"},{"path":"internal/catalog/catalog.go","content":"package catalog\nconst Name=\"shop\"\n"}],"messages":["catalog written"]}`)
+	writeTestPlugin(t, pluginDir, "escape", `{"files":[{"path":"../escape.txt","content":"x"}]}`)
+	writeTestPlugin(t, pluginDir, "broken", `{"error":"no terraform workspace"}`)
+	writeTestFile(t, ".", ".goca.yaml", "project:\n  name: shop\n  module: example.com/shop\nfeatures:\n  plugins:\n"+
+		"    - name: catalog\n      enabled: true\n      config:\n        owner: team-a\n"+
+		"    - name: escape\n      enabled: true\n"+
+		"    - name: broken\n      enabled: true\n")
+
+	sm := NewSafetyManager(false, false, false)
+	require.NoError(t, generateEntity("Product", "name:string,price:float64", true, false, false, false, false, false, false, false, "lowercase", sm))
+	runPluginHooks("Product", sm, pluginHookEntity, pluginHookFeature)
+
+	content, err := os.ReadFile("catalog-info.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "kind: Component\n", string(content))
+	assert.Contains(t, readParsedGo(t, filepath.Join("internal", "catalog", "catalog.go")), `const Name = "shop"`, "Go files are formatted")
+	assert.NoFileExists(t, filepath.Join("..", "escape.txt"))
+	assert.FileExists(t, "broken-request.json", "a failing plugin does not stop the others")
+
+	requests := readPluginRequests(t, "catalog")
+	require.Len(t, requests, 2)
+	req := requests[0]
+	assert.Equal(t, pluginProtocol, req.Protocol)
+	assert.Equal(t, pluginHookEntity, req.Hook)
+	assert.Equal(t, pluginHookFeature, requests[1].Hook)
+	assert.Equal(t, "shop", req.Project.Name)
+	assert.Equal(t, map[string]string{"owner": "team-a"}, req.Config)
+	assert.Contains(t, req.Files, "internal/domain/product.go")
+	assert.NotContains(t, req.Files, "catalog-info.yaml")
+	require.NotNil(t, req.Entity)
+	assert.Equal(t, "products", req.Entity.Table)
+	assert.Contains(t, req.Entity.Fields, pluginField{Name: "Price", Type: "float64", Column: "price", JSON: "price",
+		Tag: `json:"price" gorm:"type:decimal(10,2);not null;default:0" validate:"required,gte=0"`})
+
+	// Without --force the second run must not overwrite the plugin's files.
+	writeTestFile(t, ".", "catalog-info.yaml", "kind: Edited\n")
+	runPluginHooks("Product", NewSafetyManager(false, false, false), pluginHookFeature)
+	content, err = os.ReadFile("catalog-info.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "kind: Edited\n", string(content))
+}
+
+func TestPluginRunCommand(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	writeTestPlugin(t, pluginDir, "terraform", `{"files":[{"path":"deploy/main.tf","content":"terraform {}\n"}]}`)
+	require.NoError(t, pluginRunCmd.Flags().Set("dry-run", "true"))
+	t.Cleanup(func() { _ = pluginRunCmd.Flags().Set("dry-run", "false") })
+	require.NoError(t, pluginRunCmd.RunE(pluginRunCmd, []string{"terraform"}))
+	assert.NoFileExists(t, filepath.Join("deploy", "main.tf"))
+	req := readPluginRequests(t, "terraform")[0]
+	assert.True(t, req.DryRun)
+	assert.Equal(t, pluginHookFeature, req.Hook)
+	assert.Nil(t, req.Entity)
+
+	assert.ErrorContains(t, pluginRunCmd.RunE(pluginRunCmd, []string{"missing"}), "plugin missing not found")
+	assert.ErrorContains(t, pluginRunCmd.RunE(pluginRunCmd, []string{"terraform", "Ghost"}), "entity Ghost not found")
+	require.NoError(t, pluginRunCmd.Flags().Set("hook", "before:feature"))
+	t.Cleanup(func() { _ = pluginRunCmd.Flags().Set("hook", pluginHookFeature) })
+	assert.ErrorContains(t, pluginRunCmd.RunE(pluginRunCmd, []string{"terraform"}), "invalid hook")
+}
+
+func TestPluginInstallLocal(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	source := writeTestPlugin(t, "bin", "backstage", `{}`)
+	writeTestFile(t, ".", ".goca.yaml", "# Project settings\nproject:\n  name: shop\n  module: example.com/shop\nfeatures:\n  plugins:\n    - name: backstage\n      enabled: false\n      config:\n        owner: team-a\n")
+	require.NoError(t, pluginInstallCmd.RunE(pluginInstallCmd, []string{source}))
+
+	info, err := os.Stat(filepath.Join(pluginDir, "goca-backstage"))
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&0o100, "the installed plugin is executable")
+
+	raw, err := os.ReadFile(".goca.yaml")
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "# Project settings")
+	var cfg GocaConfig
+	require.NoError(t, yaml.Unmarshal(raw, &cfg))
+	require.Len(t, cfg.Features.Plugins, 1)
+	assert.True(t, cfg.Features.Plugins[0].Enabled)
+	assert.Equal(t, "team-a", cfg.Features.Plugins[0].Config["owner"])
+
+	require.NoError(t, pluginInstallCmd.Flags().Set("name", "Bad Name"))
+	t.Cleanup(func() { _ = pluginInstallCmd.Flags().Set("name", "") })
+	assert.ErrorContains(t, pluginInstallCmd.RunE(pluginInstallCmd, []string{source}), "invalid plugin name")
+}
+
+func TestSetPluginEntry(t *testing.T) {
+	var doc yaml.Node
+	require.NoError(t, setPluginEntry(&doc, PluginConfig{Name: "terraform", Version: "1.0"}))
+	out, err := yaml.Marshal(&doc)
+	require.NoError(t, err)
+	assert.Equal(t, "features:\n    plugins:\n        - name: terraform\n          version: \"1.0\"\n          enabled: true\n", string(out))
+
+	require.NoError(t, yaml.Unmarshal([]byte("features:\n  plugins:\n"), &doc))
+	require.NoError(t, setPluginEntry(&doc, PluginConfig{Name: "terraform"}))
+	var cfg GocaConfig
+	require.NoError(t, doc.Decode(&cfg))
+	assert.Equal(t, []PluginConfig{{Name: "terraform", Enabled: true}}, cfg.Features.Plugins)
+
+	require.NoError(t, yaml.Unmarshal([]byte("features: true\n"), &doc))
+	assert.Error(t, setPluginEntry(&doc, PluginConfig{Name: "terraform"}))
+}
@@ -0,0 +1,334 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// presenterDir is the package of the presenters, shared by the handlers of
+// every protocol.
+var presenterDir = filepath.Join(DirInternal, DirPresenter)
+
+var presenterCmd = &cobra.Command{
+	Use:   "presenter <entity>",
+	Short: "Generate the presenter mapping an entity between domain, DTOs and transports",
+	Long: `Generates internal/presenter/<entity>_presenter.go, which maps an entity
+between its domain type, its use case DTOs and the messages of the handlers,
+so the HTTP, gRPC and CLI handlers stop copying fields by hand:
+
+  - <Entity>                  The presented entity, with the JSON names of the
+                              domain entity
+  - New<Entity>, New<Entity>s Present domain entities
+  - Created<Entity>           The response to a create, built from
+                              usecase.Create<Entity>Output by NewCreated<Entity>
+  - <Entity>List              The response to a list, built from
+                              usecase.List<Entity>Output by New<Entity>List
+  - (<Entity>).CreateInput    The use case input creating the entity
+
+Handlers generated once the presenter exists return presented entities: HTTP
+encodes them, gRPC maps them onto its protobuf messages and the CLI renders
+them. Regenerate the handlers with --force to adopt a new presenter.
+Protocols that fields are hidden from keep their own projections.
+
+goca field add, remove and rename regenerate the presenter, and
+goca feature --presenter or generation.presenter in .goca.yaml generate it
+with the feature.
+
+Examples:
+  goca presenter Product
+  goca presenter Product --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		entity := args[0]
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if !entityExistsForHandler(entity) {
+			return fmt.Errorf("entity %s not found in internal/domain; generate it first with: goca entity %s", entity, entity)
+		}
+		ci := NewConfigIntegration()
+		_ = ci.LoadConfigForProject()
+
+		ui.Header(fmt.Sprintf("Generating presenter for %s", entity))
+		sm := NewSafetyManager(dryRun, force, false)
+		if err := generatePresenterFile(entity, ci.GetNamingConvention("file"), sm); err != nil {
+			return err
+		}
+		if dryRun {
+			sm.PrintSummary()
+			return nil
+		}
+
+		ui.Blank()
+		ui.Success(fmt.Sprintf("Presenter for %s generated", entity))
+		ui.NextSteps([]string{
+			fmt.Sprintf("Regenerate the handlers to use it: goca handler %s --type http --force", entity),
+			"Run: go build ./...",
+		})
+		return nil
+	},
+}
+
+func init() {
+	presenterCmd.Flags().Bool("dry-run", false, "Preview changes without creating files")
+	presenterCmd.Flags().Bool("force", false, "Overwrite the existing presenter without asking")
+}
+
+// presenter lists the functions and methods the presenter of an entity
+// declares. Handlers call only those, so a presenter generated before its
+// use case gained an operation still compiles.
+type presenter struct {
+	entity string
+	fields []Field
+	funcs  map[string]bool
+}
+
+// loadPresenter returns the presenter of entity, or nil when the project has
+// none.
+func loadPresenter(entity string) *presenter {
+	file := findTypeFile(presenterDir, entity)
+	if file == "" {
+		return nil
+	}
+	src, err := parseGoSource(file)
+	if err != nil {
+		return nil
+	}
+	p := &presenter{entity: entity, fields: readEntityFields(src.file, entity), funcs: make(map[string]bool)}
+	for _, decl := range src.file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			p.funcs[fn.Name.Name] = true
+		}
+	}
+	return p
+}
+
+// has reports whether the presenter p, which may be nil, declares the
+// function or method name.
+func (p *presenter) has(name string) bool {
+	return p != nil && p.funcs[name]
+}
+
+// presents returns the fields of fields the presenter p carries.
+func (p *presenter) presents(fields []Field) []Field {
+	names := fieldNames(p.fields)
+	var presented []Field
+	for _, f := range fields {
+		if names[f.Name] {
+			presented = append(presented, f)
+		}
+	}
+	return presented
+}
+
+// presenterFilename returns the path of the presenter of entity following the
+// file naming convention.
+func presenterFilename(entity, fileNamingConvention string) string {
+	switch fileNamingConvention {
+	case "snake_case":
+		return filepath.Join(presenterDir, toSnakeCase(entity)+"_presenter.go")
+	case "kebab-case":
+		return filepath.Join(presenterDir, toKebabCase(entity)+"-presenter.go")
+	default:
+		return filepath.Join(presenterDir, strings.ToLower(entity)+"_presenter.go")
+	}
+}
+
+// presenterFields returns the fields of the domain entity the presenter
+// carries: those encoded in JSON.
+func presenterFields(fields []Field) []Field {
+	var presented []Field
+	for _, f := range fields {
+		if reflect.StructTag(strings.Trim(f.Tag, "`")).Get("json") != "-" {
+			presented = append(presented, f)
+		}
+	}
+	return presented
+}
+
+// usecaseDTO reads the fields of a use case DTO and the imports of its file;
+// ok is false when the use case does not declare it.
+func usecaseDTO(name string) (fields []Field, imports []string, ok bool) {
+	path := findTypeFile(filepath.Join(DirInternal, DirUseCase), name)
+	if path == "" {
+		return nil, nil, false
+	}
+	src, err := parseGoSource(path)
+	if err != nil {
+		return nil, nil, false
+	}
+	return readEntityFields(src.file, name), fileImportPaths(src.file), true
+}
+
+// presenterSource renders the presenter of entity from its domain entity and
+// use case DTOs.
+func presenterSource(entity string) (string, error) {
+	file, err := findEntityFile(entity)
+	if err != nil {
+		return "", err
+	}
+	src, err := parseGoSource(file)
+	if err != nil {
+		return "", err
+	}
+	fields := presenterFields(readEntityFields(src.file, entity))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("entity %s has no fields to present", entity)
+	}
+	importPath := getImportPath(getModuleName())
+	imports := append([]string{importPath + "/internal/domain", importPath + "/internal/usecase"}, fileImportPaths(src.file)...)
+	entityLower := strings.ToLower(entity)
+
+	var b strings.Builder
+	b.WriteString("package presenter\n\n")
+	b.WriteString("import (\n")
+	fmt.Fprintf(&b, "\t\"%s/internal/domain\"\n", importPath)
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(&b, "// %s is how every handler presents a domain.%s: HTTP encodes it,\n", entity, entity)
+	b.WriteString("// gRPC maps it onto its protobuf message and the CLI renders it.\n")
+	writeProjectionStruct(&b, entity, fields, nil, "domain")
+
+	fmt.Fprintf(&b, "// New%s presents a domain.%s.\n", entity, entity)
+	fmt.Fprintf(&b, "func New%s(e domain.%s) %s {\n", entity, entity, entity)
+	writeProjectionCopy(&b, entity, "e", fields, nil)
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// New%s presents a list of domain.%s.\n", toPlural(entity), entity)
+	fmt.Fprintf(&b, "func New%s(entities []domain.%s) []%s {\n", toPlural(entity), entity, entity)
+	fmt.Fprintf(&b, "\tpresented := make([]%s, len(entities))\n", entity)
+	b.WriteString("\tfor i, e := range entities {\n")
+	fmt.Fprintf(&b, "\t\tpresented[i] = New%s(e)\n", entity)
+	b.WriteString("\t}\n")
+	b.WriteString("\treturn presented\n")
+	b.WriteString("}\n")
+
+	if output, outputImports, ok := usecaseDTO("Create" + entity + "Output"); ok {
+		imports = append(imports, outputImports...)
+		fmt.Fprintf(&b, "\n// Created%s is the response to a create: the %s and the message of\n", entity, entityLower)
+		b.WriteString("// the use case.\n")
+		fmt.Fprintf(&b, "type Created%s struct {\n", entity)
+		fmt.Fprintf(&b, "\t%s\n", entity)
+		b.WriteString("\tMessage string `json:\"message\"`\n")
+		b.WriteString("}\n\n")
+		fmt.Fprintf(&b, "// NewCreated%s presents the output of Create%s.\n", entity, entity)
+		fmt.Fprintf(&b, "func NewCreated%s(output usecase.Create%sOutput) Created%s {\n", entity, entity, entity)
+		fmt.Fprintf(&b, "\treturn Created%s{\n", entity)
+		fmt.Fprintf(&b, "\t\t%s: %s{\n", entity, entity)
+		writeConvertedFields(&b, "\t\t\t", "output", fields, "domain", output, "usecase")
+		b.WriteString("\t\t},\n")
+		b.WriteString("\t\tMessage: output.Message,\n")
+		b.WriteString("\t}\n")
+		b.WriteString("}\n")
+	}
+
+	if list, listImports, ok := usecaseDTO("List" + entity + "Output"); ok {
+		imports = append(imports, listImports...)
+		fmt.Fprintf(&b, "\n// %sList is the response to a list.\n", entity)
+		fmt.Fprintf(&b, "type %sList struct {\n", entity)
+		for _, f := range list {
+			typ := qualifyType(f.Type, "usecase")
+			if typ == "[]domain."+entity {
+				typ = "[]" + entity
+			}
+			fmt.Fprintf(&b, "\t%s %s", f.Name, typ)
+			if key := reflect.StructTag(strings.Trim(f.Tag, "`")).Get("json"); key != "" {
+				fmt.Fprintf(&b, " `json:%q`", key)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("}\n\n")
+		fmt.Fprintf(&b, "// New%sList presents the output of List%s.\n", entity, toPlural(entity))
+		fmt.Fprintf(&b, "func New%sList(output usecase.List%sOutput) %sList {\n", entity, entity, entity)
+		fmt.Fprintf(&b, "\treturn %sList{\n", entity)
+		for _, f := range list {
+			if qualifyType(f.Type, "usecase") == "[]domain."+entity {
+				fmt.Fprintf(&b, "\t\t%s: New%s(output.%s),\n", f.Name, toPlural(entity), f.Name)
+			} else {
+				fmt.Fprintf(&b, "\t\t%s: output.%s,\n", f.Name, f.Name)
+			}
+		}
+		b.WriteString("\t}\n")
+		b.WriteString("}\n")
+	}
+
+	if input, inputImports, ok := usecaseDTO("Create" + entity + "Input"); ok {
+		imports = append(imports, inputImports...)
+		fmt.Fprintf(&b, "\n// CreateInput returns the use case input creating a %s like p.\n", entityLower)
+		fmt.Fprintf(&b, "func (p %s) CreateInput() usecase.Create%sInput {\n", entity, entity)
+		fmt.Fprintf(&b, "\treturn usecase.Create%sInput{\n", entity)
+		writeConvertedFields(&b, "\t\t", "p", input, "usecase", fields, "domain")
+		b.WriteString("\t}\n")
+		b.WriteString("}\n")
+	}
+
+	content, err := syncImports([]byte(b.String()), imports...)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// writeConvertedFields writes the key-value pairs setting each field of to,
+// declared in package toPkg, that from, of the struct with the fields
+// fromFields declared in fromPkg, also has. Numbers of different types are
+// converted; other fields whose types differ are left out.
+func writeConvertedFields(b *strings.Builder, indent, from string, to []Field, toPkg string, fromFields []Field, fromPkg string) {
+	types := make(map[string]string, len(fromFields))
+	for _, f := range fromFields {
+		types[f.Name] = qualifyType(f.Type, fromPkg)
+	}
+	for _, f := range to {
+		fromType, ok := types[f.Name]
+		if !ok {
+			continue
+		}
+		toType := qualifyType(f.Type, toPkg)
+		switch {
+		case fromType == toType:
+			fmt.Fprintf(b, "%s%s: %s.%s,\n", indent, f.Name, from, f.Name)
+		case isNumericType(fromType) && isNumericType(toType):
+			fmt.Fprintf(b, "%s%s: %s(%s.%s),\n", indent, f.Name, toType, from, f.Name)
+		}
+	}
+}
+
+// generatePresenterFile writes the presenter of entity.
+func generatePresenterFile(entity, fileNamingConvention string, sm ...*SafetyManager) error {
+	defer profileStep("presenter", entity)()
+	content, err := presenterSource(entity)
+	if err != nil {
+		return fmt.Errorf("cannot generate the presenter of %s: %w", entity, err)
+	}
+	return writeGoFile(presenterFilename(entity, fileNamingConvention), content, sm...)
+}
+
+// syncPresenter regenerates the presenter of entity, if the project has one,
+// after its fields changed.
+func syncPresenter(entity string, sm *SafetyManager) error {
+	path := findTypeFile(presenterDir, entity)
+	if path == "" {
+		return nil
+	}
+	content, err := presenterSource(entity)
+	if err != nil {
+		return err
+	}
+	return writeGoFileMerged(path, content, sm)
+}
+
+// usePresenter adds the presenter import to the handler source src when it
+// calls the presenter.
+func usePresenter(src string) string {
+	content, err := syncImports([]byte(src), getImportPath(getModuleName())+"/internal/"+DirPresenter)
+	if err != nil {
+		return src
+	}
+	return string(content)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresenterGeneration(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	sm := NewSafetyManager(false, true, false)
+	fields := "name:string,stock:int"
+	require.NoError(t, generateEntity("Product", fields, true, false, false, false, false, false, false, false, "lowercase", sm))
+	generateUseCaseWithFields("ProductUseCase", "Product", "create,read,update,delete,list", false, false, fields, "", sm)
+	assert.Nil(t, loadPresenter("Product"))
+	require.NoError(t, generatePresenterFile("Product", "lowercase", sm))
+
+	path := filepath.Join(presenterDir, "product_presenter.go")
+	src := readParsedGo(t, path)
+	assert.Contains(t, src, "type Product struct {")
+	assert.Contains(t, src, "func NewProduct(e domain.Product) Product {")
+	assert.Contains(t, src, "func NewProducts(entities []domain.Product) []Product {")
+	assert.Contains(t, src, "func NewCreatedProduct(output usecase.CreateProductOutput) CreatedProduct {")
+	assert.Contains(t, src, "func NewProductList(output usecase.ListProductOutput) ProductList {")
+	assert.Contains(t, src, "func (p Product) CreateInput() usecase.CreateProductInput {")
+
+	p := loadPresenter("Product")
+	require.NotNil(t, p)
+	assert.True(t, p.has("NewCreatedProduct"))
+	assert.False(t, (*presenter)(nil).has("NewProduct"))
+	assert.Len(t, p.presents([]Field{{Name: "Name"}, {Name: "Secret"}}), 1)
+
+	// Handlers generated once the presenter exists present the entity.
+	generateHTTPHandler("Product", false, true, false, "lowercase", sm)
+	handler := readParsedGo(t, filepath.Join(DirInternal, DirHandler, DirHTTP, "product_handler.go"))
+	assert.Contains(t, handler, "presenter.NewCreatedProduct(output)")
+	assert.Contains(t, handler, "presenter.NewProduct(*product)")
+	assert.Contains(t, handler, "presenter.NewProductList(output)")
+
+	generateCLIHandler("Product", "lowercase", sm)
+	commands := readParsedGo(t, filepath.Join(DirInternal, DirHandler, DirCLI, "product_commands.go"))
+	assert.Contains(t, commands, "presenter.NewCreatedProduct(output).Product")
+	assert.Contains(t, commands, "presenter.NewProducts(output.Products)")
+
+	generateGRPCHandler("Product", "lowercase", false, sm)
+	mapping := readParsedGo(t, filepath.Join(DirInternal, DirHandler, DirGRPC, "product_mapping.go"))
+	assert.Contains(t, mapping, "func productToProto(p presenter.Product) *pb.Product {")
+	assert.Contains(t, mapping, "func productFromCreateRequest(req *pb.CreateProductRequest) presenter.Product {")
+
+	// Adding a field regenerates the presenter.
+	require.NoError(t, runFieldAdd(fieldAddCmd, []string{"Product", "sku:string"}))
+	assert.Contains(t, readParsedGo(t, path), "Sku:   e.Sku,")
+}
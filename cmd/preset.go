@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Commands a preset sets flags of.
+const (
+	presetInit    = "init"
+	presetFeature = "feature"
+)
+
+// Preset is a named set of flag values for goca init and goca feature. Flags
+// given on the command line take precedence over the preset's.
+type Preset struct {
+	Name        string `json:"-"                     yaml:"-"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Extends names the preset whose flags this one starts from.
+	Extends string            `json:"extends,omitempty" yaml:"extends,omitempty"`
+	Init    map[string]string `json:"init,omitempty"    yaml:"init,omitempty"`
+	Feature map[string]string `json:"feature,omitempty" yaml:"feature,omitempty"`
+}
+
+// builtinPresets are the presets every project can use. A preset of the same
+// name in .goca.yaml overrides their flags one by one.
+var builtinPresets = []Preset{
+	{
+		Name:        "api",
+		Description: "REST API with validated HTTP handlers",
+		Init:        map[string]string{"api": APITypeRest, "template": "rest-api"},
+		Feature:     map[string]string{"handlers": HandlerHTTP, "validation": "true"},
+	},
+	{
+		Name:        "microservice",
+		Description: "HTTP and gRPC handlers, OpenTelemetry tracing, health probes and Kubernetes manifests",
+		Init:        map[string]string{"template": "microservice", "tracing": "true", "k8s": "true"},
+		Feature:     map[string]string{"handlers": HandlerHTTP + "," + HandlerGRPC, "validation": "true", "tracing": "true"},
+	},
+	{
+		Name:        "monolith",
+		Description: "HTTP handlers with validation and business rules",
+		Init:        map[string]string{"template": "monolith"},
+		Feature:     map[string]string{"handlers": HandlerHTTP, "validation": "true", "business-rules": "true"},
+	},
+	{
+		Name:        "cli-app",
+		Description: "Cobra CLI delivery instead of HTTP handlers and routes",
+		Init:        map[string]string{"template": "minimal"},
+		Feature:     map[string]string{"handlers": HandlerCLI, "validation": "true"},
+	},
+}
+
+// lookupBuiltinPreset returns the built-in preset with the given name.
+func lookupBuiltinPreset(name string) (Preset, bool) {
+	for _, p := range builtinPresets {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
+
+// loadConfigPresets reads the default preset and the presets defined in the
+// .goca.yaml found in projectDir. Only those keys are decoded, so a config
+// that fails full validation still provides its presets.
+func loadConfigPresets(projectDir string) (string, map[string]Preset) {
+	configPath := NewConfigManager().findConfigFile(projectDir)
+	if configPath == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return "", nil
+	}
+	data = bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF})
+
+	var partial struct {
+		Preset  string            `yaml:"preset"`
+		Presets map[string]Preset `yaml:"presets"`
+	}
+	if err := yaml.Unmarshal(data, &partial); err != nil {
+		return "", nil
+	}
+	return partial.Preset, partial.Presets
+}
+
+// resolvePreset returns the named preset with the flags of the preset it
+// extends, or of the built-in preset it overrides, filled in.
+func resolvePreset(name string, defined map[string]Preset) (Preset, error) {
+	return resolvePresetChain(name, defined, map[string]bool{})
+}
+
+func resolvePresetChain(name string, defined map[string]Preset, seen map[string]bool) (Preset, error) {
+	if seen[name] {
+		return Preset{}, fmt.Errorf("preset %q extends itself", name)
+	}
+	seen[name] = true
+
+	builtin, isBuiltin := lookupBuiltinPreset(name)
+	own, isDefined := defined[name]
+	switch {
+	case !isDefined && !isBuiltin:
+		return Preset{}, fmt.Errorf("unknown preset %q; available presets: %s", name, strings.Join(presetNames(defined), ", "))
+	case !isDefined:
+		return builtin, nil
+	}
+
+	base := builtin
+	if own.Extends != "" {
+		var err error
+		if base, err = resolvePresetChain(own.Extends, defined, seen); err != nil {
+			return Preset{}, fmt.Errorf("preset %q: %w", name, err)
+		}
+	}
+	resolved := Preset{
+		Name:        name,
+		Description: own.Description,
+		Init:        mergePresetFlags(base.Init, own.Init),
+		Feature:     mergePresetFlags(base.Feature, own.Feature),
+	}
+	if resolved.Description == "" {
+		resolved.Description = base.Description
+	}
+	return resolved, nil
+}
+
+// mergePresetFlags returns the flags of base overridden by those of own.
+func mergePresetFlags(base, own map[string]string) map[string]string {
+	if len(base) == 0 && len(own) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(base)+len(own))
+	for flag, value := range base {
+		merged[flag] = value
+	}
+	for flag, value := range own {
+		merged[flag] = value
+	}
+	return merged
+}
+
+// presetNames returns the names of the built-in presets followed by those
+// defined in .goca.yaml.
+func presetNames(defined map[string]Preset) []string {
+	var names, custom []string
+	for _, p := range builtinPresets {
+		names = append(names, p.Name)
+	}
+	for name := range defined {
+		if _, ok := lookupBuiltinPreset(name); !ok {
+			custom = append(custom, name)
+		}
+	}
+	sort.Strings(custom)
+	return append(names, custom...)
+}
+
+// applyPreset sets the flags of cmd that the preset selected with --preset
+// assigns to command, leaving the flags given on the command line alone. goca
+// feature falls back to the preset recorded in .goca.yaml. It returns the
+// applied preset, without a name when there is none.
+func applyPreset(cmd *cobra.Command, command string) (Preset, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		wd = "."
+	}
+	configured, defined := loadConfigPresets(wd)
+	name, _ := cmd.Flags().GetString("preset")
+	if name == "" && command == presetFeature {
+		name = configured
+	}
+	if name == "" {
+		return Preset{}, nil
+	}
+
+	preset, err := resolvePreset(name, defined)
+	if err != nil {
+		return Preset{}, err
+	}
+	flags := preset.Init
+	if command == presetFeature {
+		flags = preset.Feature
+	}
+	names := make([]string, 0, len(flags))
+	for flag := range flags {
+		names = append(names, flag)
+	}
+	sort.Strings(names)
+	for _, flag := range names {
+		f := cmd.Flags().Lookup(flag)
+		if f == nil {
+			return Preset{}, fmt.Errorf("preset %q sets --%s, which goca %s does not have", name, flag, command)
+		}
+		if f.Changed {
+			continue
+		}
+		if err := cmd.Flags().Set(flag, flags[flag]); err != nil {
+			return Preset{}, fmt.Errorf("preset %q sets --%s: %w", name, flag, err)
+		}
+	}
+	return preset, nil
+}
+
+// persistPreset records the preset goca init applied in the generated
+// .goca.yaml, so goca feature applies it by default. A preset that is not a
+// built-in one is written out under presets, since the project cannot resolve
+// it otherwise.
+func persistPreset(configPath string, preset Preset) error {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+	content := string(data)
+	if strings.Contains(content, "\npreset:") {
+		return nil
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += fmt.Sprintf("preset: %s\n", preset.Name)
+
+	if builtin, ok := lookupBuiltinPreset(preset.Name); !ok || !reflect.DeepEqual(builtin, preset) {
+		var definition bytes.Buffer
+		enc := yaml.NewEncoder(&definition)
+		enc.SetIndent(2)
+		if err := enc.Encode(map[string]map[string]Preset{"presets": {preset.Name: preset}}); err != nil {
+			return err
+		}
+		content += definition.String()
+	}
+	return os.WriteFile(configPath, []byte(content), 0o600)
+}
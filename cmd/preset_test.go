@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvePreset(t *testing.T) {
+	preset, err := resolvePreset("microservice", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "http,grpc", preset.Feature["handlers"])
+	assert.Equal(t, "true", preset.Init["k8s"])
+
+	defined := map[string]Preset{
+		"microservice": {Feature: map[string]string{"handlers": "grpc"}},
+		"jobs":         {Extends: "cli-app", Feature: map[string]string{"handlers": "cli,worker"}},
+		"loop":         {Extends: "loop"},
+	}
+	preset, err = resolvePreset("microservice", defined)
+	require.NoError(t, err)
+	assert.Equal(t, "grpc", preset.Feature["handlers"])
+	assert.Equal(t, "true", preset.Feature["tracing"], "flags not overridden come from the built-in preset")
+	assert.NotEmpty(t, preset.Description)
+
+	preset, err = resolvePreset("jobs", defined)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"template": "minimal"}, preset.Init)
+	assert.Equal(t, "cli,worker", preset.Feature["handlers"])
+
+	_, err = resolvePreset("loop", defined)
+	assert.ErrorContains(t, err, "extends itself")
+	_, err = resolvePreset("nope", defined)
+	assert.ErrorContains(t, err, "available presets: api, microservice, monolith, cli-app, jobs, loop")
+}
+
+func TestApplyPreset(t *testing.T) {
+	chdirTemp(t)
+
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "feature"}
+		cmd.Flags().String("preset", "", "")
+		cmd.Flags().String("handlers", HandlerHTTP, "")
+		cmd.Flags().Bool("validation", false, "")
+		cmd.Flags().Bool("tracing", false, "")
+		return cmd
+	}
+
+	cmd := newCmd()
+	require.NoError(t, cmd.ParseFlags([]string{"--preset", "microservice", "--handlers", "http"}))
+	preset, err := applyPreset(cmd, presetFeature)
+	require.NoError(t, err)
+	assert.Equal(t, "microservice", preset.Name)
+	handlers, _ := cmd.Flags().GetString("handlers")
+	tracing, _ := cmd.Flags().GetBool("tracing")
+	assert.Equal(t, HandlerHTTP, handlers, "flags on the command line win")
+	assert.True(t, tracing)
+
+	// goca feature falls back to the preset recorded in .goca.yaml.
+	writeTestFile(t, ".", ".goca.yaml", "preset: tool\npresets:\n  tool:\n    extends: cli-app\n")
+	cmd = newCmd()
+	preset, err = applyPreset(cmd, presetFeature)
+	require.NoError(t, err)
+	assert.Equal(t, "tool", preset.Name)
+	handlers, _ = cmd.Flags().GetString("handlers")
+	assert.Equal(t, HandlerCLI, handlers)
+
+	cmd = newCmd()
+	require.NoError(t, cmd.ParseFlags([]string{"--preset", "monolith"}))
+	_, err = applyPreset(cmd, presetFeature)
+	assert.ErrorContains(t, err, "sets --business-rules, which goca feature does not have")
+}
+
+func TestPersistPreset(t *testing.T) {
+	chdirTemp(t)
+
+	builtin, _ := lookupBuiltinPreset("api")
+	writeTestFile(t, ".", ".goca.yaml", "project:\n  name: shop\n")
+	require.NoError(t, persistPreset(".goca.yaml", builtin))
+	require.NoError(t, persistPreset(".goca.yaml", builtin))
+	data, err := os.ReadFile(".goca.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "project:\n  name: shop\npreset: api\n", string(data))
+
+	custom, err := resolvePreset("jobs", map[string]Preset{"jobs": {Extends: "cli-app", Feature: map[string]string{"handlers": "worker"}}})
+	require.NoError(t, err)
+	writeTestFile(t, ".", ".goca.yaml", "project:\n  name: shop")
+	require.NoError(t, persistPreset(".goca.yaml", custom))
+	configured, defined := loadConfigPresets(".")
+	assert.Equal(t, "jobs", configured)
+	preset, err := resolvePreset(configured, defined)
+	require.NoError(t, err)
+	assert.Equal(t, custom, preset)
+	data, err = os.ReadFile(".goca.yaml")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "presets:\n  jobs:\n    description: Cobra CLI delivery")
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// profileSlowestCalls is how many of the slowest single steps the generation
+// profile lists.
+const profileSlowestCalls = 5
+
+// generationProfiler records how long each generation step takes and how many
+// files it generates, for --profile-generation. Steps nest: a feature step
+// runs entity, repository and handler steps, which in turn run gofmt and
+// template rendering steps.
+type generationProfiler struct {
+	mu      sync.Mutex
+	start   time.Time
+	steps   map[string]*profileStats
+	order   []string
+	running []*profileCall
+	slowest []profileCall
+	files   int
+	bytes   int
+}
+
+// profileStats aggregates every call of one step.
+type profileStats struct {
+	Name  string
+	Calls int
+	Total time.Duration
+	Self  time.Duration
+	Files int
+}
+
+// profileCall is a single run of a step.
+type profileCall struct {
+	label    string
+	stats    *profileStats
+	depth    int
+	start    time.Time
+	children time.Duration
+	duration time.Duration
+}
+
+// profiler is nil unless --profile-generation is set, which makes every
+// profiling hook a no-op.
+var profiler *generationProfiler
+
+// startGenerationProfile enables profiling for the rest of the run.
+func startGenerationProfile() {
+	profiler = &generationProfiler{start: time.Now(), steps: make(map[string]*profileStats)}
+}
+
+// profileStep starts timing a step and returns the function that stops it,
+// meant to be deferred. The optional detail, such as the entity name, labels
+// the call in the list of slowest steps.
+func profileStep(name string, detail ...string) func() {
+	p := profiler
+	if p == nil {
+		return func() {}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats, ok := p.steps[name]
+	if !ok {
+		stats = &profileStats{Name: name}
+		p.steps[name] = stats
+		p.order = append(p.order, name)
+	}
+	label := strings.TrimSpace(name + " " + strings.Join(detail, " "))
+	call := &profileCall{label: label, stats: stats, depth: len(p.running), start: time.Now()}
+	p.running = append(p.running, call)
+	return func() { p.stop(call) }
+}
+
+// stop ends a call, charging its duration to its step and to the step that
+// ran it.
+func (p *generationProfiler) stop(call *profileCall) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	call.duration = time.Since(call.start)
+	for i := len(p.running) - 1; i >= 0; i-- {
+		if p.running[i] == call {
+			p.running = p.running[:i]
+			break
+		}
+	}
+
+	call.stats.Calls++
+	call.stats.Total += call.duration
+	call.stats.Self += call.duration - call.children
+	if n := len(p.running); n > 0 {
+		p.running[n-1].children += call.duration
+	}
+
+	// The command itself always spans the whole run.
+	if call.depth == 0 {
+		return
+	}
+	p.slowest = append(p.slowest, *call)
+	sort.SliceStable(p.slowest, func(i, j int) bool { return p.slowest[i].duration > p.slowest[j].duration })
+	if len(p.slowest) > profileSlowestCalls {
+		p.slowest = p.slowest[:profileSlowestCalls]
+	}
+}
+
+// profileFile counts a generated file against the innermost running step.
+func profileFile(size int) {
+	p := profiler
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.files++
+	p.bytes += size
+	if n := len(p.running); n > 0 {
+		p.running[n-1].stats.Files++
+	}
+}
+
+// stats returns the steps ordered by self time, slowest first.
+func (p *generationProfiler) stats() []profileStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := make([]profileStats, 0, len(p.order))
+	for _, name := range p.order {
+		stats = append(stats, *p.steps[name])
+	}
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].Self > stats[j].Self })
+	return stats
+}
+
+// printGenerationProfile prints the per-step durations, file counts and
+// slowest steps collected during the run.
+func printGenerationProfile() {
+	p := profiler
+	if p == nil || ui == nil {
+		return
+	}
+	elapsed := time.Since(p.start)
+
+	ui.Blank()
+	ui.Header("Generation profile")
+	ui.KeyValue("Total time", formatProfileDuration(elapsed))
+	ui.KeyValue("Files generated", fmt.Sprintf("%d (%s)", p.files, formatProfileBytes(p.bytes)))
+
+	rows := [][]string{}
+	for _, s := range p.stats() {
+		share := 0.0
+		if elapsed > 0 {
+			share = float64(s.Self) / float64(elapsed) * 100
+		}
+		rows = append(rows, []string{
+			s.Name,
+			strconv.Itoa(s.Calls),
+			formatProfileDuration(s.Total),
+			formatProfileDuration(s.Self),
+			fmt.Sprintf("%.1f%%", share),
+			strconv.Itoa(s.Files),
+		})
+	}
+	ui.Blank()
+	ui.Table([]string{"Step", "Calls", "Total", "Self", "Share", "Files"}, rows)
+
+	if len(p.slowest) > 0 {
+		rows = rows[:0]
+		for i, call := range p.slowest {
+			rows = append(rows, []string{strconv.Itoa(i + 1), call.label, formatProfileDuration(call.duration)})
+		}
+		ui.Blank()
+		ui.Section("Slowest steps")
+		ui.Table([]string{"#", "Step", "Time"}, rows)
+	}
+}
+
+// formatProfileDuration formats a duration in milliseconds, or in seconds
+// from one second up.
+func formatProfileDuration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%.2fms", float64(d)/float64(time.Millisecond))
+	}
+	return fmt.Sprintf("%.2fs", d.Seconds())
+}
+
+// formatProfileBytes formats a byte count as B, KB or MB.
+func formatProfileBytes(n int) string {
+	switch {
+	case n < 1024:
+		return fmt.Sprintf("%d B", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	}
+	return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileStep_DisabledIsNoop(t *testing.T) {
+	require.Nil(t, profiler)
+	profileStep("entity", "User")()
+	profileFile(10)
+	assert.Nil(t, profiler)
+}
+
+func TestProfileStep_NestedSteps(t *testing.T) {
+	startGenerationProfile()
+	defer func() { profiler = nil }()
+
+	stopCommand := profileStep("goca feature")
+	for _, entity := range []string{"User", "Order"} {
+		stopEntity := profileStep("entity", entity)
+		profileFile(100)
+		stopFmt := profileStep("gofmt")
+		time.Sleep(2 * time.Millisecond)
+		stopFmt()
+		profileFile(50)
+		stopEntity()
+	}
+	stopCommand()
+
+	stats := make(map[string]profileStats)
+	for _, s := range profiler.stats() {
+		stats[s.Name] = s
+	}
+	entity, gofmt := stats["entity"], stats["gofmt"]
+	assert.Equal(t, 2, entity.Calls)
+	assert.Equal(t, 4, entity.Files, "files count against the innermost step")
+	assert.Equal(t, 0, gofmt.Files)
+	assert.Equal(t, entity.Total-gofmt.Total, entity.Self, "self time excludes nested steps")
+	assert.Equal(t, 4, profiler.files)
+	assert.Equal(t, 300, profiler.bytes)
+
+	require.Len(t, profiler.slowest, 4, "the command step is not a slowest step")
+	assert.Contains(t, []string{"entity User", "entity Order"}, profiler.slowest[0].label)
+}
+
+func TestPrintGenerationProfile(t *testing.T) {
+	var out bytes.Buffer
+	origUI := ui
+	ui = NewUIRenderer(&out, true, 1)
+	defer func() { ui = origUI }()
+	startGenerationProfile()
+	defer func() { profiler = nil }()
+
+	stopCommand := profileStep("goca feature")
+	profileStep("go mod tidy")()
+	stopCommand()
+	printGenerationProfile()
+
+	assert.Contains(t, out.String(), "Generation profile")
+	assert.Contains(t, out.String(), "go mod tidy")
+	assert.Contains(t, out.String(), "Slowest steps")
+}
+
+func TestFormatProfileValues(t *testing.T) {
+	assert.Equal(t, "0.75ms", formatProfileDuration(750*time.Microsecond))
+	assert.Equal(t, "1.50s", formatProfileDuration(1500*time.Millisecond))
+	assert.Equal(t, "512 B", formatProfileBytes(512))
+	assert.Equal(t, "1.5 KB", formatProfileBytes(1536))
+}
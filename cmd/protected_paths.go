@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrProtectedPath is returned when a generator would overwrite a file listed
+// in generation.protected.
+var ErrProtectedPath = errors.New("protected path")
+
+// projectProtectedPaths returns the current project's generation.protected
+// patterns.
+func projectProtectedPaths() []string {
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	return ci.GetGenerationConfig().Protected
+}
+
+// checkProtectedPath returns an error wrapping ErrProtectedPath when filePath
+// exists and matches generation.protected. New files are never blocked, and
+// --force does not lift the protection.
+func checkProtectedPath(filePath string) error {
+	if _, err := os.Stat(filePath); err != nil {
+		return nil
+	}
+	patterns := projectProtectedPaths()
+	if len(patterns) == 0 {
+		return nil
+	}
+	rel := projectRelativePath(filePath)
+	for _, pattern := range patterns {
+		if matchProtectedPattern(pattern, rel) {
+			return fmt.Errorf("%w: %s matches %q in generation.protected of .goca.yaml and is never overwritten, even with --force; remove it from the list to regenerate it",
+				ErrProtectedPath, rel, pattern)
+		}
+	}
+	return nil
+}
+
+// projectRelativePath returns filePath relative to the working directory (the
+// project root) with forward slashes.
+func projectRelativePath(filePath string) string {
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return filepath.ToSlash(filepath.Clean(filePath))
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return filepath.ToSlash(abs)
+	}
+	rel, err := filepath.Rel(wd, abs)
+	if err != nil {
+		return filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// matchProtectedPattern reports whether rel matches pattern. Patterns use
+// path.Match syntax per segment, "**" matches any number of directories, and
+// a pattern that matches a directory protects everything below it.
+func matchProtectedPattern(pattern, rel string) bool {
+	pattern = strings.Trim(path.Clean(filepath.ToSlash(pattern)), "/")
+	if pattern == "" || pattern == "." {
+		return false
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+// matchSegments matches path segments against pattern segments. Leftover path
+// segments match, so directory patterns cover their contents.
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], segments[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchProtectedPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"internal/usecase/payment_service.go", "internal/usecase/payment_service.go", true},
+		{"internal/usecase/payment_service.go", "internal/usecase/order_service.go", false},
+		{"internal/usecase/*_service.go", "internal/usecase/order_service.go", true},
+		{"internal/usecase/*.go", "internal/usecase/sub/order.go", false},
+		{"internal/usecase", "internal/usecase/sub/order.go", true},
+		{"internal/usecase/", "internal/usecase/dto.go", true},
+		{"**/payment*.go", "internal/repository/postgres_payment_repository.go", false},
+		{"**/postgres_payment*.go", "internal/repository/postgres_payment_repository.go", true},
+		{"internal/**/dto.go", "internal/usecase/dto.go", true},
+		{"./internal/domain/user.go", "internal/domain/user.go", true},
+		{"", "internal/domain/user.go", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchProtectedPattern(tt.pattern, tt.path), "%s vs %s", tt.pattern, tt.path)
+	}
+}
+
+func TestProtectedPathsBlockOverwrite(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	require.NoError(t, os.WriteFile(".goca.yaml", []byte(`project:
+  name: shop
+  module: testproject
+database:
+  type: postgres
+  port: 5432
+generation:
+  protected:
+    - internal/usecase/payment_service.go
+`), 0o644))
+	protected := filepath.Join("internal", "usecase", "payment_service.go")
+	require.NoError(t, os.MkdirAll(filepath.Dir(protected), 0o755))
+	require.NoError(t, os.WriteFile(protected, []byte("package usecase\n// custom\n"), 0o644))
+
+	err := writeGoFile(protected, "package usecase\n", NewSafetyManager(false, true, false))
+	assert.True(t, errors.Is(err, ErrProtectedPath))
+	err = writeGoFileMerged(protected, "package usecase\n")
+	assert.True(t, errors.Is(err, ErrProtectedPath))
+	err = writeFile(protected, "package usecase\n", NewSafetyManager(true, true, false))
+	assert.True(t, errors.Is(err, ErrProtectedPath))
+
+	content, _ := os.ReadFile(protected)
+	assert.Contains(t, string(content), "// custom")
+
+	// Files that do not exist yet and files outside the list are written.
+	assert.NoError(t, writeFile(filepath.Join("internal", "usecase", "order_service.go"), "package usecase\n"))
+
+	sm := NewSafetyManager(false, false, false)
+	sm.Merge = true
+	assert.NoError(t, sm.WriteFile(protected, "package usecase\n"))
+}
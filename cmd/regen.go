@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var regenCmd = &cobra.Command{
+	Use:   "regen <feature>",
+	Short: "Regenerate a feature with the options recorded in .goca.lock",
+	Long: `Runs again, with --force, the goca commands that generated a feature, with
+the options recorded in .goca.lock: goca feature, then the handler,
+repository and other generators run for it afterwards, in the order they ran.
+
+--fields is replaced by the current fields of the entity, so fields added,
+removed or renamed with goca field are kept. Changes made by hand to the
+generated files are overwritten; run goca status first to see them.
+
+Features generated with goca feature --batch or --from-manifest are not
+recorded as features; regenerate them with goca feature --from-manifest.
+
+Examples:
+  goca regen Product
+  goca regen Product --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		root, lock, err := loadProjectLock()
+		if err != nil {
+			return err
+		}
+		commands, err := regenArgs(cmd.Root(), lock, args[0], dryRun, readEntityFieldsString)
+		if err != nil {
+			return err
+		}
+
+		ui.Header(fmt.Sprintf("Regenerating %s", lock.featureName(args[0])))
+		for i, argv := range commands {
+			ui.Step(i+1, "goca "+strings.Join(argv, " "))
+			if err := runRegenCommand(root, argv); err != nil {
+				return fmt.Errorf("goca %s: %w", strings.Join(argv, " "), err)
+			}
+		}
+		ui.Blank()
+		ui.Success(fmt.Sprintf("%s regenerated", lock.featureName(args[0])))
+		ui.NextSteps([]string{"Run: go build ./... && go test ./..."})
+		return nil
+	},
+}
+
+func init() {
+	regenCmd.Flags().Bool("dry-run", false, "Preview the files each command would write")
+}
+
+// regenArgs returns the arguments of the goca commands regenerating feature,
+// with --force, and --dry-run with dryRun. currentFields returns the current
+// --fields of an entity, which replace the recorded ones.
+func regenArgs(root *cobra.Command, lock *generationLock, feature string, dryRun bool, currentFields func(string) string) ([][]string, error) {
+	name := lock.featureName(feature)
+	invocations := lock.Features[name]
+	if len(invocations) == 0 {
+		return nil, fmt.Errorf("%s records no command generating %s; recorded features: %s", generationLockFile, feature, strings.Join(lock.lockedFeatures(), ", "))
+	}
+
+	var commands [][]string
+	for _, inv := range invocations {
+		argv := append(strings.Fields(inv.Command), inv.Args...)
+		cmd, _, err := root.Find(argv)
+		if err != nil || cmd == root {
+			return nil, fmt.Errorf("%s records the unknown command goca %s", generationLockFile, inv.Command)
+		}
+
+		options := make([]string, 0, len(inv.Options))
+		for option := range inv.Options {
+			options = append(options, option)
+		}
+		sort.Strings(options)
+		for _, option := range options {
+			value := inv.Options[option]
+			if option == "fields" {
+				if fields := currentFields(name); fields != "" {
+					value = fields
+				}
+			}
+			argv = append(argv, "--"+option+"="+value)
+		}
+
+		if cmd.Flags().Lookup("force") != nil {
+			argv = append(argv, "--force")
+		}
+		if dryRun && cmd.Flags().Lookup("dry-run") != nil {
+			argv = append(argv, "--dry-run")
+		}
+		commands = append(commands, argv)
+	}
+	return commands, nil
+}
+
+// runRegenCommand runs the goca binary with argv in the project at root.
+func runRegenCommand(root string, argv []string) error {
+	binary, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot determine the goca binary: %w", err)
+	}
+	//#nosec G204 -- the binary is goca itself and argv comes from .goca.lock
+	cmd := exec.Command(binary, append([]string{"--no-interactive"}, argv...)...)
+	cmd.Dir = root
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,596 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jinzhu/inflection"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+)
+
+// renameMaxFileSize skips files too large to be generated source.
+const renameMaxFileSize = 2 << 20
+
+var renameCmd = &cobra.Command{
+	Use:   "rename",
+	Short: "Rename generated code across the project",
+	Long: `Renames what goca generated for a name everywhere the project uses it,
+instead of editing a dozen files by hand.`,
+}
+
+var renameFeatureCmd = &cobra.Command{
+	Use:   "feature <old> <new>",
+	Short: "Rename an entity and its feature across the project",
+	Long: `Renames an entity and everything generated for it: the struct, its files and
+directories, the repository, use case and handler interfaces and
+implementations, DI container fields, route paths, proto messages and
+packages, seed functions, errors, messages and constants.
+
+Every spelling of the name is renamed where it is a whole word or part of an
+identifier: Cliente, Clientes, cliente, clientes, cliente_item and
+cliente-items alike, so ClienteRepository becomes CustomerRepository and
+/clientes becomes /customers. Words that only start with the name, such as
+Production for Product, are left alone, and so are other entities whose name
+contains it. Only files that mention the entity or are named after it are
+edited; existing migrations, go.mod and the module path in imports are kept
+as they are. Files in generation.protected stop the rename.
+
+For SQL databases a migration renames the table, so the data moves along with
+the entity; GORM would otherwise create an empty table for the new name.
+
+Use --dry-run to review the diff of every file before renaming.
+
+Examples:
+  goca rename feature Cliente Customer --dry-run
+  goca rename feature Cliente Customer
+  goca rename feature OrderLine OrderItem --skip-migration`,
+	Args: cobra.ExactArgs(2),
+	RunE: runRenameFeature,
+}
+
+func init() {
+	renameCmd.AddCommand(renameFeatureCmd)
+
+	renameFeatureCmd.Flags().StringP("database", "d", "", fmt.Sprintf("Database type for the migration (%s)", strings.Join(ValidDatabases, ", ")))
+	renameFeatureCmd.Flags().Bool("skip-migration", false, "Do not write the migration renaming the table")
+	renameFeatureCmd.Flags().Bool("dry-run", false, "Show the diff of every file without renaming anything")
+	renameFeatureCmd.Flags().Bool("backup", false, "Backup files before editing them")
+}
+
+// renamedFile is a project file rewritten, and possibly moved, by a rename.
+type renamedFile struct {
+	From, To      string
+	Before, After string
+}
+
+func runRenameFeature(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	from, to := args[0], args[1]
+	database, _ := cmd.Flags().GetString("database")
+	skipMigration, _ := cmd.Flags().GetBool("skip-migration")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	backup, _ := cmd.Flags().GetBool("backup")
+
+	validator := NewCommandValidator()
+	for _, name := range []string{from, to} {
+		if err := validator.ValidateEntityCommand(name, ""); err != nil {
+			return err
+		}
+	}
+	if from == to {
+		return fmt.Errorf("%s is already named %s", from, to)
+	}
+	if _, err := findEntityFile(from); err != nil {
+		return err
+	}
+	entities := domainEntityNames()
+	for _, entity := range entities {
+		if entity == to {
+			return fmt.Errorf("entity %s already exists", to)
+		}
+	}
+
+	configIntegration := NewConfigIntegration()
+	if err := configIntegration.LoadConfigForProject(); err != nil {
+		ui.Warning(fmt.Sprintf("Could not load configuration: %v", err))
+	}
+	safetyMgr := NewSafetyManager(dryRun, false, backup)
+	if dryRun {
+		ui.DryRun("Previewing changes without renaming files")
+	}
+
+	renamer := newEntityRenamer(from, to, entities)
+	renamer.keep(getModuleName(), configIntegration.GetProjectConfig().Module)
+	oldTable := entityTableName(from)
+	newTable := renamer.rename(oldTable)
+	columns := renamedColumns(renamer, entities, from, newTable)
+	files, err := planFeatureRename(".", renamer, projectMigrationsDir(configIntegration), safetyMgr.BackupDir)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no files mention %s", from)
+	}
+
+	ui.Header(fmt.Sprintf("Renaming %s to %s", from, to))
+	rows := make([][]string, 0, len(files))
+	for _, f := range files {
+		action := "edit"
+		if f.To != f.From {
+			action = "move to " + f.To
+		}
+		rows = append(rows, []string{f.From, action})
+	}
+	ui.Table([]string{"File", "Change"}, rows)
+
+	if dryRun {
+		for i, f := range files {
+			safetyMgr.planChange(f.From, rows[i][1], f.After)
+			ui.Blank()
+			ui.Diff(renameDiff(f))
+		}
+	} else if err := applyFeatureRename(files, safetyMgr); err != nil {
+		return err
+	}
+
+	var nextSteps []string
+	database = configIntegration.GetDatabaseType(database)
+	switch {
+	case skipMigration || newTable == oldTable && len(columns) == 0:
+	case sqlDialect(database) < 0:
+		ui.Warning(fmt.Sprintf("Skipping the table migration: %s has no table schema to alter", database))
+	default:
+		up, down := renameTableMigration(database, oldTable, newTable, columns)
+		name := fmt.Sprintf("rename_%s_to_%s", oldTable, newTable)
+		upPath, err := writeFieldMigration(configIntegration, name, up, down, safetyMgr)
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			downPath := strings.TrimSuffix(upPath, ".up.sql") + ".down.sql"
+			for _, f := range []renamedFile{{From: os.DevNull, To: upPath, After: up}, {From: os.DevNull, To: downPath, After: down}} {
+				ui.Blank()
+				ui.Diff(renameDiff(f))
+			}
+		}
+		nextSteps = append(nextSteps, "Apply the migration: make migrate-up")
+	}
+
+	if dryRun {
+		safetyMgr.PrintSummary()
+		return nil
+	}
+
+	ui.Blank()
+	ui.Success(fmt.Sprintf("Renamed %s to %s in %d file(s)", from, to, len(files)))
+	if protoRenamed(files) {
+		nextSteps = append(nextSteps, "Regenerate the gRPC code: "+protocCommand(to, false))
+	}
+	ui.NextSteps(append(nextSteps, "Run: go build ./... && go test ./..."))
+	return nil
+}
+
+// domainEntityNames returns the entities of internal/domain: the struct
+// types declared in a file named after them.
+func domainEntityNames() []string {
+	files, _ := filepath.Glob(filepath.Join(DirInternal, DirDomain, "*.go"))
+	var names []string
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		s, err := parseGoSource(path)
+		if err != nil {
+			continue
+		}
+		base := strings.TrimSuffix(filepath.Base(path), ".go")
+		for _, decl := range s.file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				if _, ok := ts.Type.(*ast.StructType); ok && (base == strings.ToLower(ts.Name.Name) || base == gormColumnName(ts.Name.Name)) {
+					names = append(names, ts.Name.Name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// entityRenamer replaces the spellings of an entity name with those of
+// another.
+type entityRenamer struct {
+	names     map[string]string
+	pattern   *regexp.Regexp
+	pascal    *regexp.Regexp
+	protected []string
+}
+
+// entityVariantsPerSpelling is the number of variants entityNameVariants
+// returns per spelling: the plural, the naive plural and the singular.
+const entityVariantsPerSpelling = 3
+
+// newEntityRenamer returns the renamer of from to to. Entities among
+// entities whose name contains from, such as OrderItem for Order, keep their
+// names.
+func newEntityRenamer(from, to string, entities []string) *entityRenamer {
+	r := &entityRenamer{names: make(map[string]string)}
+	var all, pascal []string
+	olds, news := entityNameVariants(from), entityNameVariants(to)
+	for i, old := range olds {
+		if _, ok := r.names[old]; ok {
+			continue
+		}
+		// Naive plurals become proper ones: Categorys -> Groups.
+		if i%entityVariantsPerSpelling == 1 {
+			r.names[old] = news[i-1]
+		} else {
+			r.names[old] = news[i]
+		}
+		all = append(all, old)
+		if i < entityVariantsPerSpelling {
+			pascal = append(pascal, old)
+		}
+	}
+	r.pattern = variantsPattern(all)
+	r.pascal = variantsPattern(pascal)
+
+	for _, entity := range entities {
+		if entity != from && strings.Contains(entity, from) {
+			r.keep(entityNameVariants(entity)...)
+		}
+	}
+	return r
+}
+
+// keep leaves names as they are wherever they appear, such as the module
+// path in import paths: the rename cannot change go.mod.
+func (r *entityRenamer) keep(names ...string) {
+	for _, name := range names {
+		if name != "" {
+			r.protected = append(r.protected, name)
+		}
+	}
+	sort.SliceStable(r.protected, func(i, j int) bool { return len(r.protected[i]) > len(r.protected[j]) })
+}
+
+// entityNameVariants returns the spellings of an entity name, plural before
+// singular: Categories, Category, categories, category, ..., and
+// CATEGORY. Each plural is followed by the one goca generated before it
+// pluralized like GORM, the name with an s appended (Categorys), so renames
+// fix code generated back then too. The Pascal case spellings come first.
+func entityNameVariants(entity string) []string {
+	snake := gormColumnName(entity)
+	r, size := utf8.DecodeRuneInString(entity)
+	camel := string(unicode.ToLower(r)) + entity[size:]
+	lower := strings.ToLower(entity)
+	kebab := strings.ReplaceAll(snake, "_", "-")
+	var variants []string
+	for _, name := range []string{entity, camel, snake, kebab, lower} {
+		variants = append(variants, inflection.Plural(name), name+"s", name)
+	}
+	return append(variants, strings.ToUpper(snake))
+}
+
+// variantsPattern matches any of names, longest first.
+func variantsPattern(names []string) *regexp.Regexp {
+	sorted := append([]string(nil), names...)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	quoted := make([]string, len(sorted))
+	for i, name := range sorted {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+	return regexp.MustCompile(strings.Join(quoted, "|"))
+}
+
+// rename replaces the spellings of the entity name in text.
+func (r *entityRenamer) rename(text string) string {
+	masked, restore := r.mask(text)
+	var b strings.Builder
+	last := 0
+	for _, m := range r.pattern.FindAllStringIndex(masked, -1) {
+		end := r.wordEnd(masked, m[0], m[1])
+		if end < 0 {
+			continue
+		}
+		b.WriteString(masked[last:m[0]])
+		b.WriteString(r.names[masked[m[0]:end]])
+		last = end
+	}
+	b.WriteString(masked[last:])
+	return restore(b.String())
+}
+
+// mentions reports whether text names the entity in Pascal case, as a type
+// or in an identifier.
+func (r *entityRenamer) mentions(text string) bool {
+	masked, _ := r.mask(text)
+	for _, m := range r.pascal.FindAllStringIndex(masked, -1) {
+		if end := r.wordEnd(masked, m[0], m[1]); end >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// wordEnd returns the end of the longest spelling of the name starting at
+// start and ending at end or before it that is a whole word, or -1. The
+// pattern prefers Categorys in categoryservice, but category is the word.
+func (r *entityRenamer) wordEnd(text string, start, end int) int {
+	for ; end > start; end-- {
+		if _, ok := r.names[text[start:end]]; ok && wordMatch(text, start, end) {
+			return end
+		}
+	}
+	return -1
+}
+
+// mask hides the names of the protected entities and the kept names from the
+// pattern.
+func (r *entityRenamer) mask(text string) (string, func(string) string) {
+	if len(r.protected) == 0 {
+		return text, func(s string) string { return s }
+	}
+	pairs := make([]string, 0, 2*len(r.protected))
+	unpairs := make([]string, 0, 2*len(r.protected))
+	for i, name := range r.protected {
+		placeholder := fmt.Sprintf("\x00%d\x00", i)
+		pairs = append(pairs, name, placeholder)
+		unpairs = append(unpairs, placeholder, name)
+	}
+	return strings.NewReplacer(pairs...).Replace(text), strings.NewReplacer(unpairs...).Replace
+}
+
+// wordMatch reports whether text[start:end] is a whole spelling of the name:
+// not followed by a lowercase letter, as in Production, and, unless it
+// starts a Pascal case identifier part, not preceded by a letter or digit.
+func wordMatch(text string, start, end int) bool {
+	first, _ := utf8.DecodeRuneInString(text[start:])
+	if next, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && unicode.IsLower(next) {
+		return false
+	}
+	upper := strings.ToUpper(text[start:end]) == text[start:end]
+	if upper && end < len(text) {
+		if next, _ := utf8.DecodeRuneInString(text[end:]); unicode.IsUpper(next) || unicode.IsDigit(next) {
+			return false
+		}
+	}
+	if unicode.IsUpper(first) && !upper || start == 0 {
+		return true
+	}
+	prev, _ := utf8.DecodeLastRuneInString(text[:start])
+	return !unicode.IsLetter(prev) && !unicode.IsDigit(prev)
+}
+
+// planFeatureRename returns the files under root that mention the entity or
+// are named after it, with their renamed paths and contents. Hidden
+// directories, vendor, the migrations, the backups, go.mod and go.sum are
+// skipped.
+func planFeatureRename(root string, r *entityRenamer, skip ...string) ([]renamedFile, error) {
+	skipped := map[string]bool{"vendor": true, "node_modules": true}
+	for _, dir := range skip {
+		skipped[filepath.Clean(dir)] = true
+	}
+
+	var files []renamedFile
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || skipped[filepath.Clean(path)] || skipped[d.Name()]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || d.Name() == "go.mod" || d.Name() == "go.sum" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() > renameMaxFileSize {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.IndexByte(data, 0) >= 0 {
+			return nil
+		}
+
+		target := r.rename(filepath.ToSlash(path))
+		before := string(data)
+		if target == filepath.ToSlash(path) && !r.mentions(before) {
+			return nil
+		}
+		after := r.rename(before)
+		// Longer or shorter names can break the alignment of gofmt'ed files.
+		if strings.HasSuffix(path, ".go") {
+			if clean, err := format.Source(data); err == nil && bytes.Equal(clean, data) {
+				if formatted, err := format.Source([]byte(after)); err == nil {
+					after = string(formatted)
+				}
+			}
+		}
+		if target == filepath.ToSlash(path) && after == before {
+			return nil
+		}
+		files = append(files, renamedFile{From: path, To: filepath.FromSlash(target), Before: before, After: after})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range files {
+		if f.To == f.From {
+			continue
+		}
+		if _, err := os.Stat(f.To); err == nil {
+			return nil, fmt.Errorf("cannot move %s: %s already exists", f.From, f.To)
+		}
+	}
+	return files, nil
+}
+
+// applyFeatureRename writes the renamed files, removes the ones that moved
+// and the directories they leave empty. Nothing is changed when any of them
+// is in generation.protected.
+func applyFeatureRename(files []renamedFile, sm *SafetyManager) error {
+	for _, f := range files {
+		for _, path := range []string{f.From, f.To} {
+			if err := checkProtectedPath(path); err != nil {
+				return err
+			}
+		}
+	}
+	for _, f := range files {
+		if sm.Backup {
+			if err := sm.BackupFile(f.From); err != nil {
+				return fmt.Errorf("failed to backup file %s: %w", f.From, err)
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(f.To), 0o755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(f.To), err)
+		}
+		//#nosec G703 // paths are project files found by planFeatureRename
+		if err := os.WriteFile(f.To, []byte(f.After), 0o644); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", f.To, err)
+		}
+		recordGenerated(f.To, f.After)
+		if f.To == f.From {
+			ui.FileCreated(f.To)
+			continue
+		}
+		if err := os.Remove(f.From); err != nil {
+			return err
+		}
+		forgetGenerated(f.From)
+		ui.FileCreated(f.To)
+		for dir := filepath.Dir(f.From); dir != "." && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+			if entries, err := os.ReadDir(dir); err != nil || len(entries) > 0 || os.Remove(dir) != nil {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// renameDiff returns the diff of a renamed file, or of a new one from
+// os.DevNull.
+func renameDiff(f renamedFile) string {
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        diffLines(f.Before),
+		B:        diffLines(f.After),
+		FromFile: filepath.ToSlash(f.From),
+		ToFile:   filepath.ToSlash(f.To),
+		Context:  3,
+	})
+	if diff == "" {
+		return fmt.Sprintf("--- %s\n+++ %s\n", filepath.ToSlash(f.From), filepath.ToSlash(f.To))
+	}
+	return diff
+}
+
+// diffLines splits text into the lines of a diff; an empty text has none.
+func diffLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return difflib.SplitLines(strings.TrimSuffix(text, "\n"))
+}
+
+// protoRenamed reports whether a proto file was renamed or edited.
+func protoRenamed(files []renamedFile) bool {
+	for _, f := range files {
+		if strings.HasSuffix(f.From, ".proto") {
+			return true
+		}
+	}
+	return false
+}
+
+// columnRename is a column renamed along with an entity, such as the
+// order_id foreign key of order_items when Order is renamed.
+type columnRename struct {
+	Table, From, To string
+}
+
+// renamedColumns returns the columns of entities whose field names contain
+// the renamed entity's name. The columns of the renamed entity itself are in
+// table, its new table.
+func renamedColumns(r *entityRenamer, entities []string, renamed, table string) []columnRename {
+	var columns []columnRename
+	for _, entity := range entities {
+		path, err := findEntityFile(entity)
+		if err != nil {
+			continue
+		}
+		s, err := parseGoSource(path)
+		if err != nil {
+			continue
+		}
+		entityTable := table
+		if entity != renamed {
+			entityTable = entityTableName(entity)
+		}
+		for _, field := range readEntityFields(s.file, entity) {
+			if field.Nested != nil || r.rename(field.Name) == field.Name {
+				continue
+			}
+			column := gormTagOptions(field.Tag)["column"]
+			if column == "" {
+				column = gormColumnName(field.Name)
+			}
+			if to := r.rename(column); to != column {
+				columns = append(columns, columnRename{Table: entityTable, From: column, To: to})
+			}
+		}
+	}
+	return columns
+}
+
+// renameTableMigration returns the migration renaming a table and the
+// columns named after its entity, and its rollback.
+func renameTableMigration(database, from, to string, columns []columnRename) (up, down string) {
+	writeTable := func(b *strings.Builder, from, to string) {
+		if from == to {
+			return
+		}
+		if database == DBSQLServer {
+			fmt.Fprintf(b, "EXEC sp_rename '%s', '%s';\n", from, to)
+			return
+		}
+		fmt.Fprintf(b, "ALTER TABLE %s RENAME TO %s;\n", from, to)
+	}
+	var u, d strings.Builder
+	fmt.Fprintf(&u, "-- Rename table %s to %s\n", from, to)
+	writeTable(&u, from, to)
+	for _, c := range columns {
+		writeRenameColumn(&u, c.Table, database, c.From, c.To)
+	}
+	fmt.Fprintf(&d, "-- Rename table %s back to %s\n", to, from)
+	for i := len(columns) - 1; i >= 0; i-- {
+		c := columns[i]
+		writeRenameColumn(&d, c.Table, database, c.To, c.From)
+	}
+	writeTable(&d, to, from)
+	return u.String(), d.String()
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntityRenamer(t *testing.T) {
+	r := newEntityRenamer("OrderLine", "Purchase", []string{"OrderLine", "OrderLineItem"})
+	for in, want := range map[string]string{
+		"OrderLineRepository":               "PurchaseRepository",
+		"ListOrderLines(ctx)":               "ListPurchases(ctx)",
+		"postgresOrderLineRepository":       "postgresPurchaseRepository",
+		"orderLine := &domain.OrderLine{}":  "purchase := &domain.Purchase{}",
+		`router.HandleFunc("/order-lines")`: `router.HandleFunc("/purchases")`,
+		"INSERT INTO order_lines (sku)":     "INSERT INTO purchases (sku)",
+		"internal/grpc/orderline/x.pb.go":   "internal/grpc/purchase/x.pb.go",
+		"ORDER_LINE_TTL":                    "PURCHASE_TTL",
+		"OrderLineItem and order_line_item": "OrderLineItem and order_line_item",
+		"OrderLiner, orderlines2":           "OrderLiner, purchases2",
+	} {
+		assert.Equal(t, want, r.rename(in), in)
+	}
+
+	// Irregular plurals, and the naive ones goca generated before, become
+	// the new plural.
+	r = newEntityRenamer("Category", "Person", nil)
+	for in, want := range map[string]string{
+		"ListCategories()": "ListPeople()",
+		"ListCategorys()":  "ListPeople()",
+		`router.PathPrefix("/categorys").Subrouter()`: `router.PathPrefix("/people").Subrouter()`,
+		"categorys, err := s.repo.FindAll()":          "people, err := s.repo.FindAll()",
+		"CategoryStatusActive and category_service":   "PersonStatusActive and person_service",
+	} {
+		assert.Equal(t, want, r.rename(in), in)
+	}
+
+	r = newEntityRenamer("Product", "Customer", nil)
+	assert.Equal(t, "Production customers and reproduction", r.rename("Production products and reproduction"))
+	assert.Equal(t, "// customer routes disabled in production", r.rename("// product routes disabled in production"))
+	assert.True(t, r.mentions("var p domain.Product"))
+	assert.False(t, r.mentions("product routes"), "only the Pascal case names select a file")
+}
+
+func TestRenameFeature(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	sm := NewSafetyManager(false, true, false)
+	for entity, fields := range map[string]string{"Order": "customer:string,order_number:string", "OrderItem": "order_id:uint,sku:string"} {
+		require.NoError(t, generateEntity(entity, fields, true, false, false, false, false, false, false, true, "lowercase", sm))
+		generateUseCaseWithFields(entity+"Service", entity, "create,read,update,delete,list", false, false, fields, "", sm)
+		generateRepository(entity, DBPostgres, false, false, false, false, fields, sm)
+	}
+	require.NoError(t, renameFeatureCmd.Flags().Set("database", DBPostgres))
+	t.Cleanup(func() { _ = renameFeatureCmd.Flags().Set("database", "") })
+
+	require.NoError(t, runRenameFeature(renameFeatureCmd, []string{"Order", "Purchase"}))
+
+	assert.NoFileExists(t, filepath.Join("internal", "domain", "order.go"))
+	entity := readParsedGo(t, filepath.Join("internal", "domain", "purchase.go"))
+	assert.Contains(t, entity, "type Purchase struct {")
+	assert.Contains(t, entity, "PurchaseNumber string")
+	assert.Contains(t, readParsedGo(t, filepath.Join("internal", "domain", "errors.go")), "ErrInvalidPurchaseCustomer")
+	assert.Contains(t, readParsedGo(t, filepath.Join("internal", "repository", "postgres_purchase_repository.go")), "func NewPostgresPurchaseRepository(db *gorm.DB) PurchaseRepository {")
+	assert.Contains(t, readParsedGo(t, filepath.Join("internal", "usecase", "purchase_service.go")), "func NewPurchaseService(")
+
+	item := readParsedGo(t, filepath.Join("internal", "domain", "orderitem.go"))
+	assert.Contains(t, item, "type OrderItem struct {", "entities containing the name keep it")
+	assert.Contains(t, item, "PurchaseID uint")
+
+	files, err := filepath.Glob(filepath.Join(DirMigrations, "*_rename_orders_to_purchases.up.sql"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	up, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Equal(t, "-- Rename table orders to purchases\n"+
+		"ALTER TABLE orders RENAME TO purchases;\n"+
+		"ALTER TABLE purchases RENAME COLUMN order_number TO purchase_number;\n"+
+		"ALTER TABLE order_items RENAME COLUMN order_id TO purchase_id;\n", string(up))
+
+	err = runRenameFeature(renameFeatureCmd, []string{"Purchase", "OrderItem"})
+	assert.ErrorContains(t, err, "entity OrderItem already exists")
+}
+
+func TestRenameFeature_DryRun(t *testing.T) {
+	chdirTemp(t)
+	var out bytes.Buffer
+	origUI := ui
+	ui = NewUIRenderer(&out, true, 1)
+	t.Cleanup(func() { ui = origUI })
+	require.NoError(t, os.WriteFile("go.mod", []byte("module example.com/shop\n\ngo 1.21\n"), 0o644))
+
+	sm := NewSafetyManager(false, true, false)
+	require.NoError(t, generateEntity("Category", "name:string", true, false, false, false, false, false, false, false, "lowercase", sm))
+	generateUseCase("CategoryService", "Category", "create,read,update,delete,list", true, false, sm)
+	generateHandler("Category", HandlerHTTP, false, true, false, "lowercase", sm)
+	// Routes generated before goca pluralized like GORM.
+	writeTestFile(t, ".", "internal/handler/http/legacy.go", "package http\n\nconst legacyCategoryPath = \"/categorys\"\n")
+	require.NoError(t, renameFeatureCmd.Flags().Set("database", DBPostgres))
+	require.NoError(t, renameFeatureCmd.Flags().Set("dry-run", "true"))
+	t.Cleanup(func() {
+		_ = renameFeatureCmd.Flags().Set("database", "")
+		_ = renameFeatureCmd.Flags().Set("dry-run", "false")
+	})
+
+	require.NoError(t, runRenameFeature(renameFeatureCmd, []string{"Category", "Group"}))
+
+	assert.FileExists(t, filepath.Join("internal", "domain", "category.go"), "dry runs change nothing")
+	assert.NoDirExists(t, DirMigrations)
+	diff := out.String()
+	assert.Contains(t, diff, "--- internal/handler/http/category_handler.go\n+++ internal/handler/http/group_handler.go\n")
+	assert.Contains(t, diff, "-\trouter.HandleFunc(\"/categories\", handler.ListCategories).Methods(\"GET\")\n")
+	assert.Contains(t, diff, "+\trouter.HandleFunc(\"/groups\", handler.ListGroups).Methods(\"GET\")\n")
+	assert.Contains(t, diff, "-const legacyCategoryPath = \"/categorys\"\n+const legacyGroupPath = \"/groups\"\n")
+	assert.Contains(t, diff, "--- /dev/null\n+++ migrations/")
+	assert.Contains(t, diff, "+ALTER TABLE categories RENAME TO groups;\n")
+	assert.Contains(t, diff, "move to internal/domain/group.go")
+}
+
+func TestRenameTableMigration_SQLServer(t *testing.T) {
+	up, down := renameTableMigration(DBSQLServer, "orders", "purchases", []columnRename{{Table: "purchases", From: "order_number", To: "purchase_number"}})
+	assert.Equal(t, "-- Rename table orders to purchases\nEXEC sp_rename 'orders', 'purchases';\nEXEC sp_rename 'purchases.order_number', 'purchase_number', 'COLUMN';\n", up)
+	assert.Equal(t, "-- Rename table purchases back to orders\nEXEC sp_rename 'purchases.purchase_number', 'order_number', 'COLUMN';\nEXEC sp_rename 'purchases', 'orders';\n", down)
+}
+
+func TestRenameFeature_KeepsModulePath(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+	goMod := "module github.com/acme/product-api\n\ngo 1.21\n"
+	require.NoError(t, os.WriteFile("go.mod", []byte(goMod), 0o644))
+	require.NoError(t, os.WriteFile(".goca.yaml", []byte("project:\n  name: product-api\n  module: github.com/acme/product-api\ndatabase:\n  type: postgres\n"), 0o644))
+
+	sm := NewSafetyManager(false, true, false)
+	fields := "name:string,price:float64"
+	require.NoError(t, generateEntity("Product", fields, true, false, false, false, false, false, false, false, "lowercase", sm))
+	generateRepository("Product", DBPostgres, true, false, false, false, fields, sm)
+	generateMessages("Product", true, true, true, sm)
+	generateUseCaseWithFields("ProductService", "Product", "create,read,update,delete,list", false, false, fields, "", sm)
+
+	require.NoError(t, runRenameFeature(renameFeatureCmd, []string{"Product", "Item"}))
+
+	data, err := os.ReadFile("go.mod")
+	require.NoError(t, err)
+	assert.Equal(t, goMod, string(data))
+	config, err := os.ReadFile(".goca.yaml")
+	require.NoError(t, err)
+	assert.Contains(t, string(config), "module: github.com/acme/product-api")
+	service := readParsedGo(t, filepath.Join("internal", "usecase", "item_service.go"))
+	assert.Contains(t, service, `"github.com/acme/product-api/internal/domain"`)
+	assert.Contains(t, service, "func NewItemService(")
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	build := exec.Command("go", "build", "./...")
+	build.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+	out, err := build.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func TestRenameFeature_ProtectedPath(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+	require.NoError(t, os.WriteFile(".goca.yaml", []byte("project:\n  name: shop\n  module: example.com/shop\ndatabase:\n  type: postgres\ngeneration:\n  protected:\n    - internal/usecase/product_service.go\n"), 0o644))
+
+	sm := NewSafetyManager(false, true, false)
+	fields := "name:string"
+	require.NoError(t, generateEntity("Product", fields, true, false, false, false, false, false, false, false, "lowercase", sm))
+	generateUseCaseWithFields("ProductService", "Product", "create,read,update,delete,list", false, false, fields, "", sm)
+	before, err := os.ReadFile(filepath.Join("internal", "usecase", "product_service.go"))
+	require.NoError(t, err)
+
+	err = runRenameFeature(renameFeatureCmd, []string{"Product", "Item"})
+	assert.True(t, errors.Is(err, ErrProtectedPath))
+
+	after, err := os.ReadFile(filepath.Join("internal", "usecase", "product_service.go"))
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+	assert.FileExists(t, filepath.Join("internal", "domain", "product.go"), "nothing is renamed")
+	assert.NoFileExists(t, filepath.Join("internal", "domain", "item.go"))
+}
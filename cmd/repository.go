@@ -23,6 +23,7 @@ well-defined interfaces and database-specific implementations.`,
 		implementation, _ := cmd.Flags().GetBool(ImplementationFlag)
 		cache, _ := cmd.Flags().GetBool(CacheFlag)
 		transactions, _ := cmd.Flags().GetBool(TransactionsFlag)
+		resilience, _ := cmd.Flags().GetBool("resilience")
 		fields, _ := cmd.Flags().GetString("fields")
 
 		// Initialize config integration
@@ -59,6 +60,17 @@ well-defined interfaces and database-specific implementations.`,
 			return
 		}
 
+		if finders, _ := cmd.Flags().GetString("finders"); finders != "" {
+			if fields == "" {
+				fields = readEntityFieldsString(entity)
+			}
+			var err error
+			if fields, err = applyFinderSpecs(fields, finders); err != nil {
+				ui.Error(err.Error())
+				return
+			}
+		}
+
 		if effectiveDatabase != "" {
 			if err := validator.ValidateDatabase(effectiveDatabase); err != nil {
 				ui.Error(fmt.Sprintf("Invalid database: %v", err))
@@ -87,6 +99,10 @@ well-defined interfaces and database-specific implementations.`,
 		if transactions {
 			ui.Feature("Including transactions", false)
 		}
+		effectiveResilience := configIntegration.GetResilienceEnabled(resilience) && !interfaceOnly
+		if effectiveResilience {
+			ui.Feature("Including retries and circuit breaker", !resilience)
+		}
 		if fields != "" {
 			ui.Feature(fmt.Sprintf("Custom fields: %s", fields), false)
 		}
@@ -106,6 +122,11 @@ well-defined interfaces and database-specific implementations.`,
 		}
 
 		generateRepository(entity, effectiveDatabase, interfaceOnly, implementation, cache, transactions, fields, sm)
+		if effectiveResilience {
+			createResiliencePackage(configIntegration.GetResilienceConfig(), sm)
+			generateResilienceDecorator(entity, sm)
+		}
+		runPluginHooks(entity, sm, pluginHookRepository)
 
 		if dryRun {
 			sm.PrintSummary()
@@ -117,6 +138,7 @@ well-defined interfaces and database-specific implementations.`,
 }
 
 func generateRepository(entity, database string, interfaceOnly, implementation, cache, transactions bool, fields string, sm ...*SafetyManager) {
+	defer profileStep("repository", entity, database)()
 	// Create repository directory if it doesn't exist
 	repoDir := "internal/repository"
 	_ = os.MkdirAll(repoDir, 0o755)
@@ -154,6 +176,14 @@ func generateRepository(entity, database string, interfaceOnly, implementation,
 		} else {
 			generateRepositoryImplementation(repoDir, entity, database, cache, transactions, sm...)
 		}
+		generateRepositoryErrors(repoDir, database, sm...)
+		if isAuditedEntity(entity) && isGormDatabase(database) {
+			generateAuditLogRepository(repoDir, sm...)
+		}
+		if isMultiTenantEntity(entity) && isGormDatabase(database) {
+			generateTenancyPackage(sm...)
+			writeRepositoryTenantScope(repoDir, entity, database, sm...)
+		}
 	}
 
 	// Generate cache decorator when --cache is enabled
@@ -167,6 +197,7 @@ func generateRepository(entity, database string, interfaceOnly, implementation,
 
 func generateRepositoryInterface(dir, entity string, transactions bool, sm ...*SafetyManager) {
 	filename := filepath.Join(dir, "interfaces.go")
+	defer lockSharedFile(filename)()
 
 	// Get the module name from go.mod
 	moduleName := getModuleName()
@@ -194,30 +225,87 @@ func generateRepositoryInterface(dir, entity string, transactions bool, sm ...*S
 		// File doesn't exist, create header
 		content.WriteString("package repository\n\n")
 		if transactions {
-			content.WriteString(fmt.Sprintf("import (\n\t\"%s/internal/domain\"\n\t\"gorm.io/gorm\"\n)\n\n", getImportPath(moduleName)))
+			content.WriteString(fmt.Sprintf("import (\n\t\"context\"\n\n\t\"%s/internal/domain\"\n\t\"gorm.io/gorm\"\n)\n\n", getImportPath(moduleName)))
 		} else {
-			content.WriteString(fmt.Sprintf("import \"%s/internal/domain\"\n\n", getImportPath(moduleName)))
+			content.WriteString(fmt.Sprintf("import (\n\t\"context\"\n\n\t\"%s/internal/domain\"\n)\n\n", getImportPath(moduleName)))
 		}
 	}
 
-	content.WriteString(fmt.Sprintf("type %sRepository interface {\n", entity))
-	content.WriteString(fmt.Sprintf("\tSave(%s *domain.%s) error\n", strings.ToLower(entity), entity))
-	content.WriteString(fmt.Sprintf("\tFindByID(id int) (*domain.%s, error)\n", entity))
-	content.WriteString(fmt.Sprintf("\tUpdate(%s *domain.%s) error\n", strings.ToLower(entity), entity))
-	content.WriteString("\tDelete(id int) error\n")
-	content.WriteString(fmt.Sprintf("\tFindAll() ([]domain.%s, error)\n", entity))
+	writeRepositoryInterfaces(&content, entity, nil, transactions, repositoryInterfacesSegregated())
+	writeRepositoryInterfaceFile(filename, content.String(), sm...)
+}
 
+// writeRepositoryInterfaceFile writes interfaces.go, importing the context
+// package the Count signatures use when they are appended to an older file.
+func writeRepositoryInterfaceFile(filename, content string, sm ...*SafetyManager) {
+	if src, err := syncImports([]byte(content), "context"); err == nil {
+		content = string(src)
+	}
+	if err := writeGoFileMerged(filename, content, sm...); err != nil {
+		fmt.Printf("Error writing file %s: %v\n", filename, err)
+	}
+}
+
+// writeRepositoryInterfaces writes the <Entity>Repository contract. With
+// segregated interfaces it embeds <Entity>Reader, <Entity>Writer and, when the
+// entity has finders, <Entity>Searcher, so callers can depend on one of them.
+// The <Entity>Spec that Count and the aggregates filter by precedes them.
+func writeRepositoryInterfaces(content *strings.Builder, entity string, searchMethods []SearchMethod, transactions, segregated bool) {
+	entityLower := strings.ToLower(entity)
+	save := fmt.Sprintf("\tSave(%s *domain.%s) error\n", entityLower, entity)
+	id := idParamType(entity)
+	findByID := fmt.Sprintf("\tFindByID(id %s) (*domain.%s, error)\n", id, entity)
+	update := fmt.Sprintf("\tUpdate(%s *domain.%s) error\n", entityLower, entity)
+	remove := fmt.Sprintf("\tDelete(id %s) error\n", id)
+	findAll := fmt.Sprintf("\tFindAll() ([]domain.%s, error)\n", entity) + pagedSignature(entity)
+	count, existsByID := countSignatures(entity)
+	var tx string
 	if transactions {
-		content.WriteString(fmt.Sprintf("\tSaveWithTx(tx *gorm.DB, %s *domain.%s) error\n", strings.ToLower(entity), entity))
-		content.WriteString(fmt.Sprintf("\tUpdateWithTx(tx *gorm.DB, %s *domain.%s) error\n", strings.ToLower(entity), entity))
-		content.WriteString("\tDeleteWithTx(tx *gorm.DB, id int) error\n")
+		tx = fmt.Sprintf("\tSaveWithTx(tx *gorm.DB, %s *domain.%s) error\n", entityLower, entity) +
+			fmt.Sprintf("\tUpdateWithTx(tx *gorm.DB, %s *domain.%s) error\n", entityLower, entity) +
+			"\tDeleteWithTx(tx *gorm.DB, id int) error\n"
+	}
+	// Repositories of multi-tenant entities can be scoped to a tenant.
+	tenant := isMultiTenantEntity(entity)
+	var search strings.Builder
+	for _, method := range searchMethods {
+		search.WriteString(method.generateSearchMethodSignature() + "\n")
 	}
 
-	content.WriteString("}\n")
+	writeRepositorySpec(content, entity)
+	if !segregated {
+		fmt.Fprintf(content, "type %sRepository interface {\n", entity)
+		content.WriteString(save + findByID + existsByID + search.String() + update + remove + findAll + count + tx)
+		if tenant {
+			writeTenantRepositoryMethod(content, entity)
+		}
+		content.WriteString("}\n\n")
+		return
+	}
 
-	if err := writeGoFileMerged(filename, content.String(), sm...); err != nil {
-		fmt.Printf("Error writing file %s: %v\n", filename, err)
+	fmt.Fprintf(content, "// %sReader loads and counts %s entities.\n", entity, entity)
+	fmt.Fprintf(content, "type %sReader interface {\n%s%s%s%s}\n\n", entity, findByID, existsByID, findAll, count)
+	fmt.Fprintf(content, "// %sWriter persists %s entities.\n", entity, entity)
+	fmt.Fprintf(content, "type %sWriter interface {\n%s%s%s%s}\n\n", entity, save, update, remove, tx)
+	if len(searchMethods) > 0 {
+		writeSearcherInterface(content, entity, search.String())
+	}
+	fmt.Fprintf(content, "// %sRepository combines every %s repository contract.\n", entity, entity)
+	fmt.Fprintf(content, "type %sRepository interface {\n\t%sReader\n\t%sWriter\n", entity, entity, entity)
+	if len(searchMethods) > 0 {
+		fmt.Fprintf(content, "\t%sSearcher\n", entity)
+	}
+	if tenant {
+		writeTenantRepositoryMethod(content, entity)
 	}
+	content.WriteString("}\n\n")
+}
+
+// writeSearcherInterface writes the <Entity>Searcher interface holding the
+// given finder signatures.
+func writeSearcherInterface(content *strings.Builder, entity, signatures string) {
+	fmt.Fprintf(content, "// %sSearcher finds %s entities by field.\n", entity, entity)
+	fmt.Fprintf(content, "type %sSearcher interface {\n%s}\n\n", entity, signatures)
 }
 
 func generateRepositoryImplementation(dir, entity, database string, cache, transactions bool, sm ...*SafetyManager) {
@@ -226,7 +314,7 @@ func generateRepositoryImplementation(dir, entity, database string, cache, trans
 		generatePostgresRepository(dir, entity, cache, transactions, sm...)
 	case DBPostgresJSON:
 		generatePostgresJSONRepository(dir, entity, cache, transactions, sm...)
-	case DBMySQL:
+	case DBMySQL, DBMariaDB, DBTiDB, DBOracle:
 		generateMySQLRepository(dir, entity, cache, transactions, sm...)
 	case DBMongoDB:
 		generateMongoRepository(dir, entity, cache, transactions, sm...)
@@ -255,7 +343,9 @@ func init() {
 	repositoryCmd.Flags().BoolP(ImplementationFlag, "", false, ImplementationFlagUsage)
 	repositoryCmd.Flags().BoolP(CacheFlag, "c", false, CacheFlagUsage)
 	repositoryCmd.Flags().BoolP(TransactionsFlag, "t", false, TransactionsFlagUsage)
+	repositoryCmd.Flags().Bool("resilience", false, "Generate a retry and circuit breaker decorator for the repository (default from features.resilience.enabled)")
 	repositoryCmd.Flags().StringP("fields", "f", "", "Entity fields \"field:type,field2:type\"")
+	repositoryCmd.Flags().String("finders", "", "Repository finders \"email,findallby:status+tenant_id,existsby:email\" (default: derived from field names)")
 	repositoryCmd.Flags().Bool("dry-run", false, "Preview changes without creating files")
 	repositoryCmd.Flags().Bool("force", false, "Overwrite existing files without asking")
 	repositoryCmd.Flags().Bool("backup", false, "Backup existing files before overwriting")
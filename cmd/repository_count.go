@@ -0,0 +1,326 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// writeRepositorySpec writes the <Entity>Spec type Count and the aggregates
+// filter by.
+func writeRepositorySpec(content *strings.Builder, entity string) {
+	fmt.Fprintf(content, "// %sSpec selects the %s entities Count and the aggregates run over.\n", entity, entity)
+	content.WriteString("// Where maps stored column names to the values they must equal; an empty\n")
+	content.WriteString("// spec selects every entity.\n")
+	fmt.Fprintf(content, "type %sSpec struct {\n", entity)
+	content.WriteString("\tWhere map[string]interface{}\n")
+	content.WriteString("}\n\n")
+}
+
+// countSignatures returns the Count and ExistsByID interface methods.
+func countSignatures(entity string) (count, existsByID string) {
+	return fmt.Sprintf("\tCount(ctx context.Context, spec %sSpec) (int64, error)\n", entity),
+		fmt.Sprintf("\tExistsByID(ctx context.Context, id %s) (bool, error)\n", idParamType(entity))
+}
+
+// writeGormCountMethods writes Count and ExistsByID for a repository whose
+// receiver exposes a `db *gorm.DB`.
+func writeGormCountMethods(content *strings.Builder, recv, repoName, entity string) {
+	fmt.Fprintf(content, "func (%s *%s) Count(ctx context.Context, spec %sSpec) (int64, error) {\n", recv, repoName, entity)
+	content.WriteString("\tvar count int64\n")
+	fmt.Fprintf(content, "\tresult := %s.db.WithContext(ctx).Model(&domain.%s{}).Where(spec.Where).Count(&count)\n", recv, entity)
+	content.WriteString("\treturn count, result.Error\n")
+	content.WriteString("}\n\n")
+
+	fmt.Fprintf(content, "func (%s *%s) ExistsByID(ctx context.Context, id int) (bool, error) {\n", recv, repoName)
+	content.WriteString("\tvar count int64\n")
+	fmt.Fprintf(content, "\tresult := %s.db.WithContext(ctx).Model(&domain.%s{}).Where(\"id = ?\", id).Count(&count)\n", recv, entity)
+	content.WriteString("\treturn count > 0, result.Error\n")
+	content.WriteString("}\n\n")
+}
+
+// generateAggregateImplementation generates the GORM implementation of a
+// SumBy or MaxBy aggregate. Empty selections aggregate to zero.
+func (sm SearchMethod) generateAggregateImplementation(receiverName, receiverType, entity string) string {
+	var implementation strings.Builder
+	fmt.Fprintf(&implementation, "func (%s *%s) %s(%s) %s {\n",
+		receiverName, receiverType, sm.MethodName, sm.params(), sm.ReturnType)
+	implementation.WriteString("\tvar value float64\n")
+	fmt.Fprintf(&implementation, "\tresult := %s.db.WithContext(ctx).Model(&domain.%s{}).Where(spec.Where).Select(%q).Scan(&value)\n",
+		receiverName, entity, fmt.Sprintf("COALESCE(%s(%s), 0)", sm.aggregateFunction(), gormColumnName(sm.FieldName)))
+	implementation.WriteString("\treturn value, result.Error\n")
+	implementation.WriteString("}\n\n")
+	return implementation.String()
+}
+
+// aggregateFunction returns the SQL function of the aggregate, SUM or MAX.
+func (sm SearchMethod) aggregateFunction() string {
+	if sm.Kind == AggregateMax {
+		return "MAX"
+	}
+	return "SUM"
+}
+
+// writeMongoSpecFilter writes the bson filter of spec; a nil map would encode
+// as null, which MongoDB rejects as a filter.
+func writeMongoSpecFilter(content *strings.Builder) {
+	content.WriteString("\tfilter := bson.M{}\n")
+	content.WriteString("\tfor key, value := range spec.Where {\n")
+	content.WriteString("\t\tfilter[key] = value\n")
+	content.WriteString("\t}\n")
+}
+
+// writeMongoCountMethods writes Count and ExistsByID for a MongoDB repository
+// storing doc.
+func writeMongoCountMethods(content *strings.Builder, recv, repoName, entity string, doc mongoDocument) {
+	fmt.Fprintf(content, "func (%s *%s) Count(ctx context.Context, spec %sSpec) (int64, error) {\n", recv, repoName, entity)
+	writeMongoSpecFilter(content)
+	fmt.Fprintf(content, "\treturn %s.collection.CountDocuments(ctx, filter)\n", recv)
+	content.WriteString("}\n\n")
+
+	if !doc.objectIDs {
+		fmt.Fprintf(content, "func (%s *%s) ExistsByID(ctx context.Context, id int) (bool, error) {\n", recv, repoName)
+		fmt.Fprintf(content, "\tcount, err := %s.collection.CountDocuments(ctx, bson.M{%q: id})\n", recv, doc.key("ID"))
+		content.WriteString("\treturn count > 0, err\n")
+		content.WriteString("}\n\n")
+		return
+	}
+	// A string that is not an ObjectID names no document.
+	fmt.Fprintf(content, "func (%s *%s) ExistsByID(ctx context.Context, id string) (bool, error) {\n", recv, repoName)
+	content.WriteString("\toid, err := primitive.ObjectIDFromHex(id)\n")
+	content.WriteString("\tif err != nil {\n\t\treturn false, nil\n\t}\n")
+	fmt.Fprintf(content, "\tcount, err := %s.collection.CountDocuments(ctx, bson.M{\"_id\": oid})\n", recv)
+	content.WriteString("\treturn count > 0, err\n")
+	content.WriteString("}\n\n")
+}
+
+// generateMongoAggregateImplementation generates a SumBy or MaxBy aggregate
+// as a $match/$group pipeline.
+func generateMongoAggregateImplementation(method SearchMethod, repoName string, doc mongoDocument) string {
+	var implementation strings.Builder
+	fmt.Fprintf(&implementation, "func (m *%s) %s(%s) %s {\n", repoName, method.MethodName, method.params(), method.ReturnType)
+	writeMongoSpecFilter(&implementation)
+	operator := "$sum"
+	if method.Kind == AggregateMax {
+		operator = "$max"
+	}
+	implementation.WriteString("\tcursor, err := m.collection.Aggregate(ctx, mongo.Pipeline{\n")
+	implementation.WriteString("\t\t{{Key: \"$match\", Value: filter}},\n")
+	fmt.Fprintf(&implementation, "\t\t{{Key: \"$group\", Value: bson.M{\"_id\": nil, \"value\": bson.M{%q: %q}}}},\n",
+		operator, "$"+doc.key(method.FieldName))
+	implementation.WriteString("\t})\n")
+	implementation.WriteString("\tif err != nil {\n\t\treturn 0, err\n\t}\n")
+	implementation.WriteString("\tdefer cursor.Close(ctx)\n")
+	implementation.WriteString("\tvar rows []struct {\n")
+	implementation.WriteString("\t\tValue float64 `bson:\"value\"`\n")
+	implementation.WriteString("\t}\n")
+	implementation.WriteString("\tif err := cursor.All(ctx, &rows); err != nil || len(rows) == 0 {\n")
+	implementation.WriteString("\t\treturn 0, err\n")
+	implementation.WriteString("\t}\n")
+	implementation.WriteString("\treturn rows[0].Value, nil\n")
+	implementation.WriteString("}\n\n")
+	return implementation.String()
+}
+
+// writeElasticsearchCountMethods writes Count, backed by the _count API,
+// ExistsByID and the specQuery helper the aggregates share.
+func writeElasticsearchCountMethods(content *strings.Builder, repoName, entity string) {
+	fmt.Fprintf(content, "func (e *%s) Count(ctx context.Context, spec %sSpec) (int64, error) {\n", repoName, entity)
+	content.WriteString("\tvar buf bytes.Buffer\n")
+	content.WriteString("\tif err := json.NewEncoder(&buf).Encode(e.specQuery(spec)); err != nil {\n")
+	content.WriteString("\t\treturn 0, err\n\t}\n")
+	content.WriteString("\treq := esapi.CountRequest{\n")
+	content.WriteString("\t\tIndex: []string{e.index},\n")
+	content.WriteString("\t\tBody:  &buf,\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tres, err := req.Do(ctx, e.client)\n")
+	content.WriteString("\tif err != nil {\n\t\treturn 0, err\n\t}\n")
+	content.WriteString("\tdefer res.Body.Close()\n")
+	content.WriteString("\tvar cr struct {\n")
+	content.WriteString("\t\tCount int64 `json:\"count\"`\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tif err := json.NewDecoder(res.Body).Decode(&cr); err != nil {\n")
+	content.WriteString("\t\treturn 0, err\n\t}\n")
+	content.WriteString("\treturn cr.Count, nil\n")
+	content.WriteString("}\n\n")
+
+	fmt.Fprintf(content, "func (e *%s) ExistsByID(ctx context.Context, id int) (bool, error) {\n", repoName)
+	content.WriteString("\treq := esapi.ExistsRequest{\n")
+	content.WriteString("\t\tIndex:      e.index,\n")
+	content.WriteString("\t\tDocumentID: strconv.Itoa(id),\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tres, err := req.Do(ctx, e.client)\n")
+	content.WriteString("\tif err != nil {\n\t\treturn false, err\n\t}\n")
+	content.WriteString("\tdefer res.Body.Close()\n")
+	content.WriteString("\treturn res.StatusCode == 200, nil\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString("// specQuery returns the search body matching every term of spec.\n")
+	fmt.Fprintf(content, "func (e *%s) specQuery(spec %sSpec) map[string]interface{} {\n", repoName, entity)
+	content.WriteString("\tfilters := make([]interface{}, 0, len(spec.Where))\n")
+	content.WriteString("\tfor field, value := range spec.Where {\n")
+	content.WriteString("\t\tfilters = append(filters, map[string]interface{}{\"term\": map[string]interface{}{field: value}})\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\treturn map[string]interface{}{\n")
+	content.WriteString("\t\t\"query\": map[string]interface{}{\"bool\": map[string]interface{}{\"filter\": filters}},\n")
+	content.WriteString("\t}\n")
+	content.WriteString("}\n\n")
+}
+
+// writeElasticsearchAggregate writes a SumBy or MaxBy aggregate as a sum or
+// max aggregation over the documents matching the spec.
+func writeElasticsearchAggregate(b *strings.Builder, repoName string, m SearchMethod) {
+	fmt.Fprintf(b, "func (e *%s) %s(%s) %s {\n", repoName, m.MethodName, m.params(), m.ReturnType)
+	b.WriteString("\tbody := e.specQuery(spec)\n")
+	b.WriteString("\tbody[\"size\"] = 0\n")
+	fmt.Fprintf(b, "\tbody[\"aggs\"] = map[string]interface{}{\"value\": map[string]interface{}{%q: map[string]interface{}{\"field\": %q}}}\n",
+		strings.ToLower(m.aggregateFunction()), gormColumnName(m.FieldName))
+	b.WriteString("\tvar buf bytes.Buffer\n")
+	b.WriteString("\tif err := json.NewEncoder(&buf).Encode(body); err != nil {\n")
+	b.WriteString("\t\treturn 0, err\n\t}\n")
+	b.WriteString("\treq := esapi.SearchRequest{\n")
+	b.WriteString("\t\tIndex: []string{e.index},\n")
+	b.WriteString("\t\tBody:  &buf,\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\tres, err := req.Do(ctx, e.client)\n")
+	b.WriteString("\tif err != nil {\n\t\treturn 0, err\n\t}\n")
+	b.WriteString("\tdefer res.Body.Close()\n")
+	b.WriteString("\tvar sr struct {\n")
+	b.WriteString("\t\tAggregations struct {\n")
+	b.WriteString("\t\t\tValue struct {\n")
+	b.WriteString("\t\t\t\tValue *float64 `json:\"value\"`\n")
+	b.WriteString("\t\t\t} `json:\"value\"`\n")
+	b.WriteString("\t\t} `json:\"aggregations\"`\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\tif err := json.NewDecoder(res.Body).Decode(&sr); err != nil || sr.Aggregations.Value.Value == nil {\n")
+	b.WriteString("\t\treturn 0, err\n\t}\n")
+	b.WriteString("\treturn *sr.Aggregations.Value.Value, nil\n")
+	b.WriteString("}\n\n")
+}
+
+// writeDynamoDBCountMethods writes Count, a paginated COUNT scan, ExistsByID
+// and the scanInput helper the aggregates share.
+func writeDynamoDBCountMethods(content *strings.Builder, repoName, entity string) {
+	fmt.Fprintf(content, "func (d *%s) Count(ctx context.Context, spec %sSpec) (int64, error) {\n", repoName, entity)
+	content.WriteString("\tinput, err := d.scanInput(spec)\n")
+	content.WriteString("\tif err != nil {\n\t\treturn 0, err\n\t}\n")
+	content.WriteString("\tinput.Select = types.SelectCount\n")
+	content.WriteString("\tvar count int64\n")
+	content.WriteString("\tpaginator := dynamodb.NewScanPaginator(d.client, input)\n")
+	content.WriteString("\tfor paginator.HasMorePages() {\n")
+	content.WriteString("\t\tpage, err := paginator.NextPage(ctx)\n")
+	content.WriteString("\t\tif err != nil {\n\t\t\treturn 0, fmt.Errorf(\"failed to count: %w\", err)\n\t\t}\n")
+	content.WriteString("\t\tcount += int64(page.Count)\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\treturn count, nil\n")
+	content.WriteString("}\n\n")
+
+	fmt.Fprintf(content, "func (d *%s) ExistsByID(ctx context.Context, id int) (bool, error) {\n", repoName)
+	content.WriteString("\tresult, err := d.client.GetItem(ctx, &dynamodb.GetItemInput{\n")
+	content.WriteString("\t\tTableName: &d.tableName,\n")
+	content.WriteString("\t\tKey: map[string]types.AttributeValue{\n")
+	content.WriteString("\t\t\t\"id\": &types.AttributeValueMemberN{Value: strconv.Itoa(id)},\n")
+	content.WriteString("\t\t},\n")
+	content.WriteString("\t})\n")
+	content.WriteString("\tif err != nil {\n\t\treturn false, fmt.Errorf(\"failed to get item: %w\", err)\n\t}\n")
+	content.WriteString("\treturn len(result.Item) > 0, nil\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString("// scanInput returns a scan of the items whose attributes equal spec.\n")
+	fmt.Fprintf(content, "func (d *%s) scanInput(spec %sSpec) (*dynamodb.ScanInput, error) {\n", repoName, entity)
+	content.WriteString("\tinput := &dynamodb.ScanInput{TableName: &d.tableName}\n")
+	content.WriteString("\tif len(spec.Where) == 0 {\n\t\treturn input, nil\n\t}\n")
+	content.WriteString("\tnames := make(map[string]string, len(spec.Where))\n")
+	content.WriteString("\tvalues := make(map[string]types.AttributeValue, len(spec.Where))\n")
+	content.WriteString("\tvar conditions []string\n")
+	content.WriteString("\tfor attribute, value := range spec.Where {\n")
+	content.WriteString("\t\tkey := strconv.Itoa(len(conditions))\n")
+	content.WriteString("\t\tav, err := attributevalue.Marshal(value)\n")
+	content.WriteString("\t\tif err != nil {\n\t\t\treturn nil, fmt.Errorf(\"failed to marshal: %w\", err)\n\t\t}\n")
+	content.WriteString("\t\tnames[\"#a\"+key] = attribute\n")
+	content.WriteString("\t\tvalues[\":v\"+key] = av\n")
+	content.WriteString("\t\tconditions = append(conditions, \"#a\"+key+\" = :v\"+key)\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tfilter := strings.Join(conditions, \" AND \")\n")
+	content.WriteString("\tinput.FilterExpression = &filter\n")
+	content.WriteString("\tinput.ExpressionAttributeNames = names\n")
+	content.WriteString("\tinput.ExpressionAttributeValues = values\n")
+	content.WriteString("\treturn input, nil\n")
+	content.WriteString("}\n\n")
+}
+
+// writeDynamoDBAggregate writes a SumBy or MaxBy aggregate computed over the
+// pages of a scan, since DynamoDB has no server-side aggregation.
+func writeDynamoDBAggregate(b *strings.Builder, repoName, entity string, m SearchMethod) {
+	fmt.Fprintf(b, "func (d *%s) %s(%s) %s {\n", repoName, m.MethodName, m.params(), m.ReturnType)
+	b.WriteString("\tinput, err := d.scanInput(spec)\n")
+	b.WriteString("\tif err != nil {\n\t\treturn 0, err\n\t}\n")
+	b.WriteString("\tvar value float64\n")
+	if m.Kind == AggregateMax {
+		b.WriteString("\tseen := false\n")
+	}
+	b.WriteString("\tpaginator := dynamodb.NewScanPaginator(d.client, input)\n")
+	b.WriteString("\tfor paginator.HasMorePages() {\n")
+	b.WriteString("\t\tpage, err := paginator.NextPage(ctx)\n")
+	b.WriteString("\t\tif err != nil {\n\t\t\treturn 0, fmt.Errorf(\"failed to scan: %w\", err)\n\t\t}\n")
+	fmt.Fprintf(b, "\t\tvar items []domain.%s\n", entity)
+	b.WriteString("\t\tif err := attributevalue.UnmarshalListOfMaps(page.Items, &items); err != nil {\n")
+	b.WriteString("\t\t\treturn 0, fmt.Errorf(\"failed to unmarshal: %w\", err)\n\t\t}\n")
+	b.WriteString("\t\tfor i := range items {\n")
+	if m.Kind == AggregateMax {
+		fmt.Fprintf(b, "\t\t\tif v := float64(items[i].%s); !seen || v > value {\n", m.FieldName)
+		b.WriteString("\t\t\t\tvalue, seen = v, true\n")
+		b.WriteString("\t\t\t}\n")
+	} else {
+		fmt.Fprintf(b, "\t\t\tvalue += float64(items[i].%s)\n", m.FieldName)
+	}
+	b.WriteString("\t\t}\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\treturn value, nil\n")
+	b.WriteString("}\n\n")
+}
+
+// appendAggregates appends the aggregates of the entity's numeric fields to an
+// already-generated Elasticsearch or DynamoDB repository file.
+func appendAggregates(dir, file, repoName, entity string, fields []Field, sm ...*SafetyManager) {
+	var b strings.Builder
+	for _, m := range generateAggregateMethods(fields, entity) {
+		if strings.HasPrefix(repoName, "dynamodb") {
+			writeDynamoDBAggregate(&b, repoName, entity, m)
+		} else {
+			writeElasticsearchAggregate(&b, repoName, m)
+		}
+	}
+	appendToRepoFile(filepath.Join(dir, file), b.String(), nil, sm...)
+}
+
+// writeSQLiteCountMethods writes Count and ExistsByID for the database/sql
+// SQLite repository, which stores each entity as a JSON document.
+func writeSQLiteCountMethods(content *strings.Builder, repoName, entity string) {
+	table := entityTableName(entity)
+
+	fmt.Fprintf(content, "func (s *%s) Count(ctx context.Context, spec %sSpec) (int64, error) {\n", repoName, entity)
+	fmt.Fprintf(content, "\tquery := \"SELECT COUNT(*) FROM %s\"\n", table)
+	content.WriteString("\tvar conditions []string\n")
+	content.WriteString("\tvar args []interface{}\n")
+	content.WriteString("\tfor key, value := range spec.Where {\n")
+	content.WriteString("\t\tconditions = append(conditions, \"json_extract(data, '$.' || ?) = ?\")\n")
+	content.WriteString("\t\targs = append(args, key, value)\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tif len(conditions) > 0 {\n")
+	content.WriteString("\t\tquery += \" WHERE \" + strings.Join(conditions, \" AND \")\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tvar count int64\n")
+	content.WriteString("\tif err := s.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {\n")
+	content.WriteString("\t\treturn 0, fmt.Errorf(\"failed to count: %w\", err)\n\t}\n")
+	content.WriteString("\treturn count, nil\n")
+	content.WriteString("}\n\n")
+
+	fmt.Fprintf(content, "func (s *%s) ExistsByID(ctx context.Context, id int) (bool, error) {\n", repoName)
+	content.WriteString("\tvar count int64\n")
+	fmt.Fprintf(content, "\tquery := \"SELECT COUNT(*) FROM %s WHERE id = ?\"\n", table)
+	content.WriteString("\tif err := s.db.QueryRowContext(ctx, query, id).Scan(&count); err != nil {\n")
+	content.WriteString("\t\treturn false, fmt.Errorf(\"failed to query: %w\", err)\n\t}\n")
+	content.WriteString("\treturn count > 0, nil\n")
+	content.WriteString("}\n\n")
+}
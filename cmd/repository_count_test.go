@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAggregateMethods(t *testing.T) {
+	methods := generateAggregateMethods(parseFields("name:string,price:float64,stock:int,category_id:uint"), "Product")
+	require.Len(t, methods, 4, "text fields and foreign keys are not aggregated")
+	assert.Equal(t, "SumByPrice", methods[0].MethodName)
+	assert.Equal(t, "MaxByStock", methods[3].MethodName)
+	assert.Equal(t, "\tSumByPrice(ctx context.Context, spec ProductSpec) (float64, error)", methods[0].generateSearchMethodSignature())
+	assert.Equal(t, "ctx, spec", methods[0].args())
+}
+
+func TestRepositoryCount_Backends(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	dir := t.TempDir()
+	fields := parseFields("name:string,price:float64")
+
+	generateRepositoryInterfaceWithFields(dir, "Product", fields, false)
+	iface := readParsedGo(t, filepath.Join(dir, "interfaces.go"))
+	assert.Contains(t, iface, "type ProductSpec struct {\n\tWhere map[string]interface{}\n}")
+	assert.Contains(t, iface, "Count(ctx context.Context, spec ProductSpec) (int64, error)")
+	assert.Contains(t, iface, "ExistsByID(ctx context.Context, id int) (bool, error)")
+	assert.Contains(t, iface, `"context"`)
+
+	generateGormRepositoryWithFields(dir, "Product", "Postgres", fields, false, false)
+	gorm := readParsedGo(t, filepath.Join(dir, "postgres_product_repository.go"))
+	assert.Contains(t, gorm, "p.db.WithContext(ctx).Model(&domain.Product{}).Where(spec.Where).Count(&count)")
+	assert.Contains(t, gorm, `Where(spec.Where).Select("COALESCE(SUM(price), 0)").Scan(&value)`)
+	assert.Contains(t, gorm, `Select("COALESCE(MAX(price), 0)")`)
+
+	generateMongoRepositoryWithFields(dir, "Product", fields, false, false)
+	mongo := readParsedGo(t, filepath.Join(dir, "mongo_product_repository.go"))
+	assert.Contains(t, mongo, "return m.collection.CountDocuments(ctx, filter)")
+	assert.Contains(t, mongo, `{{Key: "$group", Value: bson.M{"_id": nil, "value": bson.M{"$max": "$price"}}}}`)
+
+	generateElasticsearchRepositoryWithFields(dir, "Product", fields, false, false)
+	es := readParsedGo(t, filepath.Join(dir, "elasticsearch_product_repository.go"))
+	assert.Contains(t, es, "req := esapi.CountRequest{")
+	assert.Contains(t, es, `map[string]interface{}{"sum": map[string]interface{}{"field": "price"}}`)
+
+	generateDynamoDBRepositoryWithFields(dir, "Product", fields, false, false)
+	dynamo := readParsedGo(t, filepath.Join(dir, "dynamodb_product_repository.go"))
+	assert.Contains(t, dynamo, "input.Select = types.SelectCount")
+	assert.Contains(t, dynamo, "value += float64(items[i].Price)")
+
+	mock := generateRepositoryMock("Product", generateRepositoryMethods(fields, "Product"))
+	_, err := parser.ParseFile(token.NewFileSet(), "", mock, 0)
+	require.NoError(t, err, mock)
+	assert.Contains(t, mock, "func (m *MockProductRepository) Count(ctx context.Context, spec repository.ProductSpec) (int64, error)")
+	assert.Contains(t, mock, "func (m *MockProductRepository) SumByPrice(ctx context.Context, spec repository.ProductSpec) (float64, error)")
+}
+
+func TestInterfaceFinder_Aggregates(t *testing.T) {
+	src := `package repository
+
+type ProductRepository interface {
+	ExistsByID(ctx context.Context, id int) (bool, error)
+	MaxByPrice(ctx context.Context, spec ProductSpec) (float64, error)
+	Count(ctx context.Context, spec ProductSpec) (int64, error)
+}
+`
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	require.NoError(t, err)
+	var names []string
+	for _, m := range findInterfaceType(file, "ProductRepository").Methods.List {
+		if method, ok := interfaceFinder(m, "Product"); ok {
+			names = append(names, method.MethodName)
+			assert.Equal(t, AggregateMax, method.Kind)
+			assert.Equal(t, "Price", method.columns()[0].Name)
+		}
+	}
+	assert.Equal(t, []string{"MaxByPrice"}, names)
+}
+
+func TestCountOperation(t *testing.T) {
+	defer ensureTestUI(t)()
+	setupCLIProject(t, strings.Replace(fullProductUseCase, "}\n", "\tCountProducts() (int64, error)\n}\n", 1))
+
+	assert.Equal(t, []string{OpCreate, OpRead, OpUpdate, OpDelete, OpList, OpCount}, useCaseOperations("Product"))
+
+	dir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	generateHTTPHandlerFile(dir, "Product", false, false, "lowercase")
+	handler := readParsedGo(t, filepath.Join(dir, "product_handler.go"))
+	assert.Contains(t, handler, "total, err := p.usecase.CountProducts()")
+	assert.Contains(t, handler, `w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))`)
+
+	var routes strings.Builder
+	writeRouteSetupFunc(&routes, "Product", defaultHTTPRoutes("Product"), false, false)
+	assert.Contains(t, routes.String(), `router.HandleFunc("/products/count", handler.CountProducts).Methods("HEAD")`)
+
+	usecaseDir := filepath.Join(DirInternal, DirUseCase)
+	generateUseCaseServiceWithFields(usecaseDir, "ProductUseCase", "Product", []string{OpList, OpCount}, false, false, "", DTOStyleNested)
+	service := readParsedGo(t, filepath.Join(usecaseDir, "product_service.go"))
+	assert.Contains(t, service, "return p.repo.Count(context.Background(), repository.ProductSpec{})")
+}
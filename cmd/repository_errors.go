@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// generateDomainErrorKinds writes internal/domain/repository_errors.go with
+// the errors repositories translate database errors to. Projects whose
+// conflict.go already declares ErrConflict for --versioned keep it there. It
+// is shared by every entity and only rewritten with --force.
+func generateDomainErrorKinds(dir string, sm ...*SafetyManager) {
+	source := domainErrorKindsSource
+	if _, err := os.Stat(filepath.Join(dir, "conflict.go")); err == nil {
+		source = strings.Replace(source, domainErrConflictDecl, "", 1)
+	}
+	writeOnce(filepath.Join(dir, "repository_errors.go"), source, "repository errors", sm...)
+}
+
+const domainErrConflictDecl = `	// ErrConflict is returned when a write conflicts with the stored state,
+	// such as an update based on a stale version or a reference to a missing
+	// row.
+	ErrConflict = errors.New("conflict")
+`
+
+const domainErrorKindsSource = `package domain
+
+import "errors"
+
+// Errors repositories return in place of the errors of their database, so use
+// cases and handlers can tell them apart without importing a driver.
+var (
+	// ErrNotFound is returned when no entity has the ID or the finder's values.
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists is returned when a write breaks a unique constraint.
+	ErrAlreadyExists = errors.New("already exists")
+` + domainErrConflictDecl + `)
+`
+
+// generateRepositoryErrors writes the domain error kinds and the translation
+// of the errors of database to them: internal/repository/errors.go with the
+// error type shared by every backend, and a <backend>_errors.go per backend so
+// repositories of several databases can live side by side.
+func generateRepositoryErrors(dir, database string, sm ...*SafetyManager) {
+	generateDomainErrorKinds(filepath.Join(DirInternal, DirDomain), sm...)
+
+	domainImport := fmt.Sprintf("%q", getImportPath(getModuleName())+"/internal/domain")
+	writeOnce(filepath.Join(dir, "errors.go"), repositoryErrorSource, "repository errors", sm...)
+	switch {
+	case database == DBMongoDB:
+		writeOnce(filepath.Join(dir, "mongo_errors.go"), fmt.Sprintf(mongoErrorsSource, domainImport), "MongoDB error translation", sm...)
+	case database == DBElasticsearch:
+		writeOnce(filepath.Join(dir, "elasticsearch_errors.go"), fmt.Sprintf(elasticsearchErrorsSource, domainImport), "Elasticsearch error translation", sm...)
+	case database == DBDynamoDB:
+		writeOnce(filepath.Join(dir, "dynamodb_errors.go"), fmt.Sprintf(dynamoDBErrorsSource, domainImport), "DynamoDB error translation", sm...)
+	case isGormDatabase(database):
+		writeOnce(filepath.Join(dir, "gorm_errors.go"), fmt.Sprintf(gormErrorsSource, domainImport), "GORM error translation", sm...)
+	}
+}
+
+const repositoryErrorSource = `package repository
+
+// dbError is a database error translated to an error kind of the domain
+// package. Its message is the kind's, so responses do not reveal the schema,
+// and errors.Is and errors.As match both the kind and the database error.
+type dbError struct {
+	kind error
+	err  error
+}
+
+func (e *dbError) Error() string { return e.kind.Error() }
+
+func (e *dbError) Unwrap() []error { return []error{e.kind, e.err} }
+`
+
+const gormErrorsSource = `package repository
+
+import (
+	"errors"
+
+	%s
+
+	"gorm.io/gorm"
+)
+
+// translateGormError returns err as domain.ErrNotFound, ErrAlreadyExists or
+// ErrConflict when it is a missing record, a unique constraint violation or a
+// foreign key violation. The dialector of db recognizes the codes of its
+// driver, whether or not gorm.Config.TranslateError is set.
+func translateGormError(db *gorm.DB, err error) error {
+	if err == nil {
+		return nil
+	}
+	translated := err
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		translated = translator.Translate(err)
+	}
+	switch {
+	case errors.Is(translated, gorm.ErrRecordNotFound):
+		return &dbError{kind: domain.ErrNotFound, err: err}
+	case errors.Is(translated, gorm.ErrDuplicatedKey):
+		return &dbError{kind: domain.ErrAlreadyExists, err: err}
+	case errors.Is(translated, gorm.ErrForeignKeyViolated):
+		return &dbError{kind: domain.ErrConflict, err: err}
+	}
+	return err
+}
+`
+
+const mongoErrorsSource = `package repository
+
+import (
+	"errors"
+
+	%s
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// translateMongoError returns err as domain.ErrNotFound or ErrAlreadyExists
+// when no document matched or a write broke a unique index.
+func translateMongoError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return &dbError{kind: domain.ErrNotFound, err: err}
+	case mongo.IsDuplicateKeyError(err):
+		return &dbError{kind: domain.ErrAlreadyExists, err: err}
+	}
+	return err
+}
+`
+
+const elasticsearchErrorsSource = `package repository
+
+import (
+	"fmt"
+	"net/http"
+
+	%s
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// translateElasticsearchResponse returns the error of a failed response, as
+// domain.ErrNotFound for a missing document or ErrConflict for a version
+// conflict, or nil when the request succeeded.
+func translateElasticsearchResponse(res *esapi.Response) error {
+	if !res.IsError() {
+		return nil
+	}
+	err := fmt.Errorf("elasticsearch: %%s", res.String())
+	switch res.StatusCode {
+	case http.StatusNotFound:
+		return &dbError{kind: domain.ErrNotFound, err: err}
+	case http.StatusConflict:
+		return &dbError{kind: domain.ErrConflict, err: err}
+	}
+	return err
+}
+`
+
+const dynamoDBErrorsSource = `package repository
+
+import (
+	"errors"
+
+	%s
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// translateDynamoDBError returns err as domain.ErrConflict when a condition
+// of the write failed.
+func translateDynamoDBError(err error) error {
+	var conditionFailed *types.ConditionalCheckFailedException
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &conditionFailed):
+		return &dbError{kind: domain.ErrConflict, err: err}
+	}
+	return err
+}
+`
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateRepositoryErrors(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+	sm := NewSafetyManager(false, true, false)
+
+	generateRepository("Product", DBPostgres, false, false, false, true, "name:string,email:string", sm)
+	domainErrors := readParsedGo(t, filepath.Join(DirInternal, DirDomain, "repository_errors.go"))
+	assert.Contains(t, domainErrors, `ErrAlreadyExists = errors.New("already exists")`)
+	assert.Contains(t, domainErrors, `ErrConflict = errors.New("conflict")`)
+	assert.Contains(t, readParsedGo(t, filepath.Join(DirInternal, DirRepository, "errors.go")), "func (e *dbError) Unwrap() []error {")
+
+	gormErrors := readParsedGo(t, filepath.Join(DirInternal, DirRepository, "gorm_errors.go"))
+	assert.Contains(t, gormErrors, "if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {")
+	assert.Contains(t, gormErrors, "case errors.Is(translated, gorm.ErrDuplicatedKey):\n\t\treturn &dbError{kind: domain.ErrAlreadyExists, err: err}")
+
+	repo := readParsedGo(t, filepath.Join(DirInternal, DirRepository, "postgres_product_repository.go"))
+	assert.Contains(t, repo, "return translateGormError(p.db, result.Error)")
+	assert.Contains(t, repo, "return nil, translateGormError(p.db, result.Error)")
+	assert.Contains(t, repo, "return translateGormError(tx, result.Error)")
+	assert.NotContains(t, repo, "return result.Error")
+
+	// Each backend adds its own translation next to the others.
+	generateRepository("Order", DBMongoDB, false, false, false, false, "total:float64", sm)
+	mongoErrors := readParsedGo(t, filepath.Join(DirInternal, DirRepository, "mongo_errors.go"))
+	assert.Contains(t, mongoErrors, "case mongo.IsDuplicateKeyError(err):")
+	assert.Contains(t, readParsedGo(t, filepath.Join(DirInternal, DirRepository, "mongo_order_repository.go")), "return nil, translateMongoError(err)")
+	assert.FileExists(t, filepath.Join(DirInternal, DirRepository, "gorm_errors.go"))
+
+	generateRepository("Invoice", DBDynamoDB, false, false, false, false, "total:float64", sm)
+	dynamo := readParsedGo(t, filepath.Join(DirInternal, DirRepository, "dynamodb_invoice_repository.go"))
+	assert.Contains(t, dynamo, "if result.Item == nil {\n\t\treturn nil, domain.ErrNotFound\n\t}")
+	assert.Contains(t, readParsedGo(t, filepath.Join(DirInternal, DirRepository, "dynamodb_errors.go")), "var conditionFailed *types.ConditionalCheckFailedException")
+}
+
+func TestGenerateDomainErrorKinds_LegacyConflict(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+	dir := filepath.Join(DirInternal, DirDomain)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "conflict.go"), []byte("package domain\n\nimport \"errors\"\n\nvar ErrConflict = errors.New(\"version conflict\")\n"), 0o644))
+
+	generateDomainErrorKinds(dir, NewSafetyManager(false, true, false))
+	domainErrors := readParsedGo(t, filepath.Join(dir, "repository_errors.go"))
+	assert.Contains(t, domainErrors, "ErrNotFound")
+	assert.NotContains(t, domainErrors, "ErrConflict")
+}
@@ -9,6 +9,7 @@ import (
 
 func generateRepositoryInterfaceWithFields(dir, entity string, fields []Field, transactions bool, sm ...*SafetyManager) {
 	filename := filepath.Join(dir, "interfaces.go")
+	defer lockSharedFile(filename)()
 
 	// Get the module name from go.mod
 	moduleName := getModuleName()
@@ -33,37 +34,15 @@ func generateRepositoryInterfaceWithFields(dir, entity string, fields []Field, t
 		// File doesn't exist, create header
 		content.WriteString("package repository\n\n")
 		if transactions {
-			content.WriteString(fmt.Sprintf("import (\n\t\"%s/internal/domain\"\n\t\"gorm.io/gorm\"\n)\n\n", getImportPath(moduleName)))
+			content.WriteString(fmt.Sprintf("import (\n\t\"context\"\n\n\t\"%s/internal/domain\"\n\t\"gorm.io/gorm\"\n)\n\n", getImportPath(moduleName)))
 		} else {
-			content.WriteString(fmt.Sprintf("import \"%s/internal/domain\"\n\n", getImportPath(moduleName)))
+			content.WriteString(fmt.Sprintf("import (\n\t\"context\"\n\n\t\"%s/internal/domain\"\n)\n\n", getImportPath(moduleName)))
 		}
 	}
 
-	content.WriteString(fmt.Sprintf("type %sRepository interface {\n", entity))
-	content.WriteString(fmt.Sprintf("\tSave(%s *domain.%s) error\n", strings.ToLower(entity), entity))
-	content.WriteString(fmt.Sprintf("\tFindByID(id int) (*domain.%s, error)\n", entity))
-
-	// Generate dynamic search methods based on actual fields
-	searchMethods := generateSearchMethods(fields, entity)
-	for _, method := range searchMethods {
-		content.WriteString(method.generateSearchMethodSignature() + "\n")
-	}
-
-	content.WriteString(fmt.Sprintf("\tUpdate(%s *domain.%s) error\n", strings.ToLower(entity), entity))
-	content.WriteString("\tDelete(id int) error\n")
-	content.WriteString(fmt.Sprintf("\tFindAll() ([]domain.%s, error)\n", entity))
-
-	if transactions {
-		content.WriteString(fmt.Sprintf("\tSaveWithTx(tx *gorm.DB, %s *domain.%s) error\n", strings.ToLower(entity), entity))
-		content.WriteString(fmt.Sprintf("\tUpdateWithTx(tx *gorm.DB, %s *domain.%s) error\n", strings.ToLower(entity), entity))
-		content.WriteString("\tDeleteWithTx(tx *gorm.DB, id int) error\n")
-	}
-
-	content.WriteString("}\n\n")
-
-	if err := writeGoFileMerged(filename, content.String(), sm...); err != nil {
-		fmt.Printf("Error writing file %s: %v\n", filename, err)
-	}
+	generateRepositoryCriteria(dir, entity, fields, sm...)
+	writeRepositoryInterfaces(&content, entity, generateRepositoryMethods(fields, entity), transactions, repositoryInterfacesSegregated())
+	writeRepositoryInterfaceFile(filename, content.String(), sm...)
 }
 
 // generateRepositoryImplementationWithFields generates repository implementations with dynamic methods.
@@ -71,7 +50,7 @@ func generateRepositoryImplementationWithFields(dir, entity, database string, fi
 	switch database {
 	case DBPostgres:
 		generatePostgresRepositoryWithFields(dir, entity, fields, cache, transactions, sm...)
-	case DBMySQL:
+	case DBMySQL, DBMariaDB, DBTiDB, DBOracle:
 		generateMySQLRepositoryWithFields(dir, entity, fields, cache, transactions, sm...)
 	case DBMongoDB:
 		generateMongoRepositoryWithFields(dir, entity, fields, cache, transactions, sm...)
@@ -99,7 +78,10 @@ func generateRepositoryImplementationWithFields(dir, entity, database string, fi
 
 func generatePostgresJSONRepositoryWithFields(dir, entity string, fields []Field, cache, transactions bool, sm ...*SafetyManager) {
 	generatePostgresJSONRepository(dir, entity, cache, transactions, sm...)
-	appendGormFinders(dir, "postgres_json_"+strings.ToLower(entity)+"_repository.go", fmt.Sprintf("postgresJSON%sRepository", entity), entity, fields, sm...)
+	file := "postgres_json_" + strings.ToLower(entity) + "_repository.go"
+	repoName := fmt.Sprintf("postgresJSON%sRepository", entity)
+	appendGormFinders(dir, file, repoName, entity, fields, sm...)
+	appendJSONQueries(dir, file, repoName, entity, fields, sm...)
 }
 
 func generateSQLServerRepositoryWithFields(dir, entity string, fields []Field, cache, transactions bool, sm ...*SafetyManager) {
@@ -109,18 +91,27 @@ func generateSQLServerRepositoryWithFields(dir, entity string, fields []Field, c
 
 func generateElasticsearchRepositoryWithFields(dir, entity string, fields []Field, cache, transactions bool, sm ...*SafetyManager) {
 	generateElasticsearchRepository(dir, entity, cache, transactions, sm...)
-	appendDelegatingFinders(dir, "elasticsearch_"+strings.ToLower(entity)+"_repository.go", fmt.Sprintf("elasticsearch%sRepository", entity), "e", entity, fields, sm...)
+	file := "elasticsearch_" + strings.ToLower(entity) + "_repository.go"
+	repoName := fmt.Sprintf("elasticsearch%sRepository", entity)
+	appendQueryFinders(dir, file, repoName, entity, fields, sm...)
+	appendAggregates(dir, file, repoName, entity, fields, sm...)
+	appendCriteriaSearch(dir, file, repoName, entity, fields, sm...)
 }
 
 func generateDynamoDBRepositoryWithFields(dir, entity string, fields []Field, cache, transactions bool, sm ...*SafetyManager) {
 	generateDynamoDBRepository(dir, entity, cache, transactions, sm...)
-	appendDelegatingFinders(dir, "dynamodb_"+strings.ToLower(entity)+"_repository.go", fmt.Sprintf("dynamodb%sRepository", entity), "d", entity, fields, sm...)
+	file := "dynamodb_" + strings.ToLower(entity) + "_repository.go"
+	repoName := fmt.Sprintf("dynamodb%sRepository", entity)
+	appendQueryFinders(dir, file, repoName, entity, fields, sm...)
+	appendAggregates(dir, file, repoName, entity, fields, sm...)
+	appendCriteriaSearch(dir, file, repoName, entity, fields, sm...)
 }
 
-// appendGormFinders appends GORM-based per-field finder implementations to an
-// already-generated repository file whose receiver exposes a `db *gorm.DB`.
+// appendGormFinders appends GORM-based per-field finder and aggregate
+// implementations to an already-generated repository file whose receiver
+// exposes a `db *gorm.DB`.
 func appendGormFinders(dir, file, repoName, entity string, fields []Field, sm ...*SafetyManager) {
-	methods := generateSearchMethods(fields, entity)
+	methods := generateRepositoryMethods(fields, entity)
 	if len(methods) == 0 {
 		return
 	}
@@ -131,29 +122,178 @@ func appendGormFinders(dir, file, repoName, entity string, fields []Field, sm ..
 	appendToRepoFile(filepath.Join(dir, file), b.String(), nil, sm...)
 }
 
-// appendDelegatingFinders appends per-field finders that reuse FindAll and filter
-// in memory — used for backends (Elasticsearch, DynamoDB) where a dedicated query
-// per field is out of scope but the interface still requires the method.
-func appendDelegatingFinders(dir, file, repoName, recv, entity string, fields []Field, sm ...*SafetyManager) {
+// appendJSONQueries appends the JSON column helpers to an already-generated
+// GORM repository file.
+func appendJSONQueries(dir, file, repoName, entity string, fields []Field, sm ...*SafetyManager) {
+	var b strings.Builder
+	if writeJSONQueries(&b, strings.ToLower(string(repoName[0])), repoName, entity, fields) {
+		appendToRepoFile(filepath.Join(dir, file), b.String(), []string{"gorm.io/datatypes"}, sm...)
+	}
+}
+
+// writeJSONQueries writes the <Entity>JSONQueries interface and its GORM
+// implementation: per JSON field a FindBy<Field>Path and a FindWith<Field>Key
+// query built with datatypes.JSONQuery, which renders the jsonb operators on
+// PostgreSQL and JSON_EXTRACT on MySQL and SQLite. It reports whether the
+// entity has any JSON field.
+func writeJSONQueries(content *strings.Builder, recv, repoName, entity string, fields []Field) bool {
+	var jsonFields []Field
+	for _, field := range fields {
+		if field.Type == FieldJSONType {
+			jsonFields = append(jsonFields, field)
+		}
+	}
+	if len(jsonFields) == 0 {
+		return false
+	}
+
+	entityLower := strings.ToLower(entity)
+	fmt.Fprintf(content, "// %sJSONQueries queries the JSON columns of %s. It is not part of\n", entity, entity)
+	fmt.Fprintf(content, "// %sRepository; assert the repository to use it:\n", entity)
+	content.WriteString("//\n")
+	fmt.Fprintf(content, "//\tq, ok := repo.(%sJSONQueries)\n", entity)
+	fmt.Fprintf(content, "type %sJSONQueries interface {\n", entity)
+	for _, field := range jsonFields {
+		fmt.Fprintf(content, "\tFindBy%sPath(value interface{}, path ...string) ([]domain.%s, error)\n", field.Name, entity)
+		fmt.Fprintf(content, "\tFindWith%sKey(path ...string) ([]domain.%s, error)\n", field.Name, entity)
+	}
+	content.WriteString("}\n\n")
+	fmt.Fprintf(content, "var _ %sJSONQueries = (*%s)(nil)\n\n", entity, repoName)
+
+	for _, field := range jsonFields {
+		column := fieldColumn(field)
+
+		fmt.Fprintf(content, "// FindBy%sPath returns the %s whose %s value at path equals value.\n", field.Name, toPlural(entityLower), column)
+		fmt.Fprintf(content, "func (%s *%s) FindBy%sPath(value interface{}, path ...string) ([]domain.%s, error) {\n", recv, repoName, field.Name, entity)
+		fmt.Fprintf(content, "\tvar %s []domain.%s\n", toPlural(entityLower), entity)
+		fmt.Fprintf(content, "\tresult := %s.db.Where(datatypes.JSONQuery(%q).Equals(value, path...)).Find(&%s)\n", recv, column, toPlural(entityLower))
+		content.WriteString("\tif result.Error != nil {\n")
+		content.WriteString("\t\treturn nil, result.Error\n")
+		content.WriteString("\t}\n")
+		fmt.Fprintf(content, "\treturn %s, nil\n", toPlural(entityLower))
+		content.WriteString("}\n\n")
+
+		fmt.Fprintf(content, "// FindWith%sKey returns the %s whose %s document contains the key path.\n", field.Name, toPlural(entityLower), column)
+		fmt.Fprintf(content, "func (%s *%s) FindWith%sKey(path ...string) ([]domain.%s, error) {\n", recv, repoName, field.Name, entity)
+		fmt.Fprintf(content, "\tvar %s []domain.%s\n", toPlural(entityLower), entity)
+		fmt.Fprintf(content, "\tresult := %s.db.Where(datatypes.JSONQuery(%q).HasKey(path...)).Find(&%s)\n", recv, column, toPlural(entityLower))
+		content.WriteString("\tif result.Error != nil {\n")
+		content.WriteString("\t\treturn nil, result.Error\n")
+		content.WriteString("\t}\n")
+		fmt.Fprintf(content, "\treturn %s, nil\n", toPlural(entityLower))
+		content.WriteString("}\n\n")
+	}
+	return true
+}
+
+// appendQueryFinders appends the per-field finders of an Elasticsearch or
+// DynamoDB repository: a term query or a filtered scan read through the
+// searchDocuments or scanAll helper.
+func appendQueryFinders(dir, file, repoName, entity string, fields []Field, sm ...*SafetyManager) {
 	methods := generateSearchMethods(fields, entity)
 	if len(methods) == 0 {
 		return
 	}
-	entityLower := strings.ToLower(entity)
 	var b strings.Builder
 	for _, m := range methods {
-		paramName := strings.ToLower(m.FieldName)
-		fmt.Fprintf(&b, "func (%s *%s) %s(%s %s) %s {\n", recv, repoName, m.MethodName, paramName, m.FieldType, m.ReturnType)
-		fmt.Fprintf(&b, "\titems, err := %s.FindAll()\n", recv)
+		if strings.HasPrefix(repoName, "dynamodb") {
+			writeDynamoDBFinder(&b, repoName, entity, m)
+		} else {
+			writeElasticsearchFinder(&b, repoName, entity, m)
+		}
+	}
+	appendToRepoFile(filepath.Join(dir, file), b.String(), nil, sm...)
+}
+
+// writeElasticsearchFinder writes one finder as a bool filter of term queries.
+// String fields are matched on their keyword sub-field, which dynamic mapping
+// adds next to the analyzed text.
+func writeElasticsearchFinder(b *strings.Builder, repoName, entity string, m SearchMethod) {
+	var terms []string
+	for _, column := range m.columns() {
+		key := gormColumnName(column.Name)
+		if column.Type == "string" {
+			key += ".keyword"
+		}
+		terms = append(terms, fmt.Sprintf("%q: %s", key, strings.ToLower(column.Name)))
+	}
+	fmt.Fprintf(b, "func (e *%s) %s(%s) %s {\n", repoName, m.MethodName, m.params(), m.ReturnType)
+	fmt.Fprintf(b, "\tbody := e.specQuery(%sSpec{Where: map[string]interface{}{%s}})\n", entity, strings.Join(terms, ", "))
+	writeQueryFinderResult(b, m, "e.searchDocuments(context.Background(), body, %s)")
+}
+
+// writeDynamoDBFinder writes one finder as a scan filtered on the attributes.
+func writeDynamoDBFinder(b *strings.Builder, repoName, entity string, m SearchMethod) {
+	var attributes []string
+	for _, column := range m.columns() {
+		attributes = append(attributes, fmt.Sprintf("%q: %s", column.Name, strings.ToLower(column.Name)))
+	}
+	fmt.Fprintf(b, "func (d *%s) %s(%s) %s {\n", repoName, m.MethodName, m.params(), m.ReturnType)
+	fmt.Fprintf(b, "\tinput, err := d.scanInput(%sSpec{Where: map[string]interface{}{%s}})\n", entity, strings.Join(attributes, ", "))
+	switch m.Kind {
+	case FinderExists:
+		b.WriteString("\tif err != nil {\n\t\treturn false, err\n\t}\n")
+	default:
+		b.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+	}
+	writeQueryFinderResult(b, m, "d.scanAll(context.Background(), input, %s)")
+}
+
+// writeQueryFinderResult finishes a finder that reads its matches with query,
+// a format string taking the limit: every match, or only the first one.
+func writeQueryFinderResult(b *strings.Builder, m SearchMethod, query string) {
+	switch m.Kind {
+	case FinderAll:
+		fmt.Fprintf(b, "\treturn %s\n", fmt.Sprintf(query, "0"))
+	case FinderExists:
+		fmt.Fprintf(b, "\titems, err := %s\n", fmt.Sprintf(query, "1"))
+		b.WriteString("\tif err != nil {\n\t\treturn false, err\n\t}\n")
+		b.WriteString("\treturn len(items) > 0, nil\n")
+	default:
+		fmt.Fprintf(b, "\titems, err := %s\n", fmt.Sprintf(query, "1"))
 		b.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+		b.WriteString("\tif len(items) == 0 {\n\t\treturn nil, domain.ErrNotFound\n\t}\n")
+		b.WriteString("\treturn &items[0], nil\n")
+	}
+	b.WriteString("}\n\n")
+}
+
+// writeDelegatingFinder writes one finder that scans FindAll for matches. It
+// serves repositories generated before searchDocuments and scanAll existed.
+func writeDelegatingFinder(b *strings.Builder, recv, repoName, entity string, m SearchMethod) {
+	var conditions []string
+	for _, column := range m.columns() {
+		conditions = append(conditions, fmt.Sprintf("items[i].%s == %s", column.Name, strings.ToLower(column.Name)))
+	}
+	match := strings.Join(conditions, " && ")
+
+	fmt.Fprintf(b, "func (%s *%s) %s(%s) %s {\n", recv, repoName, m.MethodName, m.params(), m.ReturnType)
+	fmt.Fprintf(b, "\titems, err := %s.FindAll()\n", recv)
+	switch m.Kind {
+	case FinderAll:
+		b.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+		fmt.Fprintf(b, "\tvar matches []domain.%s\n", entity)
 		b.WriteString("\tfor i := range items {\n")
-		fmt.Fprintf(&b, "\t\tif items[i].%s == %s {\n", m.FieldName, paramName)
+		fmt.Fprintf(b, "\t\tif %s {\n", match)
+		b.WriteString("\t\t\tmatches = append(matches, items[i])\n\t\t}\n")
+		b.WriteString("\t}\n")
+		b.WriteString("\treturn matches, nil\n")
+	case FinderExists:
+		b.WriteString("\tif err != nil {\n\t\treturn false, err\n\t}\n")
+		b.WriteString("\tfor i := range items {\n")
+		fmt.Fprintf(b, "\t\tif %s {\n", match)
+		b.WriteString("\t\t\treturn true, nil\n\t\t}\n")
+		b.WriteString("\t}\n")
+		b.WriteString("\treturn false, nil\n")
+	default:
+		b.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+		b.WriteString("\tfor i := range items {\n")
+		fmt.Fprintf(b, "\t\tif %s {\n", match)
 		b.WriteString("\t\t\treturn &items[i], nil\n\t\t}\n")
 		b.WriteString("\t}\n")
-		fmt.Fprintf(&b, "\treturn nil, fmt.Errorf(\"%s not found\")\n", entityLower)
-		b.WriteString("}\n\n")
+		b.WriteString("\treturn nil, domain.ErrNotFound\n")
 	}
-	appendToRepoFile(filepath.Join(dir, file), b.String(), []string{"fmt"}, sm...)
+	b.WriteString("}\n\n")
 }
 
 // appendToRepoFile appends generated method source to an existing repository
@@ -199,51 +339,28 @@ func generatePostgresRepositoryWithFields(dir, entity string, fields []Field, ca
 // driver-prefixed so they match what the DI container references
 // (New<Driver><Entity>Repository).
 func generateGormRepositoryWithFields(dir, entity, driver string, fields []Field, cache, transactions bool, sm ...*SafetyManager) {
-	entityLower := strings.ToLower(entity)
-	driverLower := strings.ToLower(driver)
-	filename := filepath.Join(dir, driverLower+"_"+entityLower+"_repository.go")
-
-	// Get the module name from go.mod
-	moduleName := getModuleName()
+	data := newGormRepository(entity, driver, isVersionedEntity(entity))
+	data.Module = getImportPath(getModuleName())
+	data.Cache, data.Transactions = cache, transactions
+	data.JSON = fieldsUseType(fields, FieldJSONType)
+	data.Partitioned = isPartitionedEntity(entity)
+	filename := filepath.Join(dir, strings.ToLower(driver)+"_"+data.Var+"_repository.go")
 
 	var content strings.Builder
-	content.WriteString("package repository\n\n")
-	content.WriteString("import (\n")
-	content.WriteString(fmt.Sprintf("\t\"%s/internal/domain\"\n", getImportPath(moduleName)))
-	if cache {
-		content.WriteString("\t// Cache imports (Redis, etc.)\n")
-		content.WriteString("\t// \"github.com/go-redis/redis/v8\"\n")
-	}
-	if transactions {
-		content.WriteString("\t// SQL transaction support\n")
-		content.WriteString("\t// \"database/sql/driver\"\n")
-	}
-	content.WriteString("\n\t\"gorm.io/gorm\"\n")
-	content.WriteString(")\n\n")
-
-	// Repository structure
-	repoName := fmt.Sprintf("%s%sRepository", driverLower, entity)
-	content.WriteString(fmt.Sprintf("type %s struct {\n", repoName))
-	content.WriteString("\tdb *gorm.DB\n")
-	content.WriteString("}\n\n")
-
-	content.WriteString(fmt.Sprintf("func New%s%sRepository(db *gorm.DB) %sRepository {\n", driver, entity, entity))
-	content.WriteString(fmt.Sprintf("\treturn &%s{\n", repoName))
-	content.WriteString("\t\tdb: db,\n")
-	content.WriteString("\t}\n")
-	content.WriteString("}\n\n")
-
-	// Generate basic CRUD methods
-	generateBasicCRUDMethods(&content, entity, repoName)
+	writeGoTemplate(&content, gormRepositoryTemplates, "repository", data)
+	writeGormCountMethods(&content, "p", data.Repo, entity)
+	writeGormFindAllPaged(&content, "p", data.Repo, entity)
 
-	// Generate dynamic search methods based on fields
-	searchMethods := generateSearchMethods(fields, entity)
+	// Generate dynamic search methods, aggregates and Search based on fields
+	searchMethods := generateRepositoryMethods(fields, entity)
 	for _, method := range searchMethods {
-		content.WriteString(method.generateSearchMethodImplementation("p", repoName, entity))
+		content.WriteString(method.generateSearchMethodImplementation("p", data.Repo, entity))
 	}
 
+	writeJSONQueries(&content, "p", data.Repo, entity, fields)
+
 	if transactions {
-		generateTransactionMethods(&content, entity, repoName)
+		generateTransactionMethods(&content, entity, data.Repo, data.Versioned)
 	}
 
 	if err := writeGoFile(filename, content.String(), sm...); err != nil {
@@ -251,71 +368,150 @@ func generateGormRepositoryWithFields(dir, entity, driver string, fields []Field
 	}
 }
 
-// generateBasicCRUDMethods generates basic CRUD methods.
-func generateBasicCRUDMethods(content *strings.Builder, entity, repoName string) {
+// gormRepository is the data of gormRepositoryTemplates.
+type gormRepository struct {
+	Module       string
+	Entity       string // Product
+	Var          string // product: the entity parameter
+	Plural       string // products: the FindAll result
+	Driver       string // Postgres: the constructor prefix
+	Repo         string // postgresProductRepository
+	Cache        bool
+	Transactions bool
+	JSON         bool // the entity has JSON columns, queried with gorm.io/datatypes
+	Partitioned  bool // the constructor registers the partitions of the table
+	Versioned    bool // updates check the version of the entity
+}
+
+func newGormRepository(entity, driver string, versioned bool) gormRepository {
 	entityLower := strings.ToLower(entity)
+	return gormRepository{
+		Entity:    entity,
+		Var:       entityLower,
+		Plural:    toPlural(entityLower),
+		Driver:    driver,
+		Repo:      strings.ToLower(driver) + entity + "Repository",
+		Versioned: versioned,
+	}
+}
 
-	// Save method
-	fmt.Fprintf(content, "func (p *%s) Save(%s *domain.%s) error {\n", repoName, entityLower, entity)
-	fmt.Fprintf(content, "\tresult := p.db.Create(%s)\n", entityLower)
-	content.WriteString("\treturn result.Error\n")
-	content.WriteString("}\n\n")
+// VersionedUpdate returns the body of Update for a versioned entity.
+func (r gormRepository) VersionedUpdate() string {
+	var b strings.Builder
+	writeGormVersionedUpdate(&b, "p.db", r.Var)
+	return b.String()
+}
 
-	// FindByID method
-	fmt.Fprintf(content, "func (p *%s) FindByID(id int) (*domain.%s, error) {\n", repoName, entity)
-	fmt.Fprintf(content, "\t%s := &domain.%s{}\n", entityLower, entity)
-	fmt.Fprintf(content, "\tresult := p.db.First(%s, id)\n", entityLower)
-	content.WriteString("\tif result.Error != nil {\n")
-	content.WriteString("\t\treturn nil, result.Error\n")
-	content.WriteString("\t}\n")
-	fmt.Fprintf(content, "\treturn %s, nil\n", entityLower)
-	content.WriteString("}\n\n")
+// gormRepositoryTemplates renders the GORM repository of an entity up to its
+// CRUD methods; the other methods are appended by the shared writers.
+var gormRepositoryTemplates = parseGoTemplates("gorm repository", `
+{{- define "repository" -}}
+package repository
 
-	// Update method
-	fmt.Fprintf(content, "func (p *%s) Update(%s *domain.%s) error {\n", repoName, entityLower, entity)
-	fmt.Fprintf(content, "\tresult := p.db.Save(%s)\n", entityLower)
-	content.WriteString("\treturn result.Error\n")
-	content.WriteString("}\n\n")
+import (
+	"context"
+
+	"{{.Module}}/internal/domain"
+{{- if .Cache}}
+	// Cache imports (Redis, etc.)
+	// "github.com/go-redis/redis/v8"
+{{- end}}
+{{- if .Transactions}}
+	// SQL transaction support
+	// "database/sql/driver"
+{{- end}}
+
+{{if .JSON}}	"gorm.io/datatypes"
+{{end}}	"gorm.io/gorm"
+)
 
-	// Delete method
-	fmt.Fprintf(content, "func (p *%s) Delete(id int) error {\n", repoName)
-	fmt.Fprintf(content, "\tresult := p.db.Delete(&domain.%s{}, id)\n", entity)
-	content.WriteString("\treturn result.Error\n")
-	content.WriteString("}\n\n")
+type {{.Repo}} struct {
+	db *gorm.DB
+}
 
-	// FindAll method
-	fmt.Fprintf(content, "func (p *%s) FindAll() ([]domain.%s, error) {\n", repoName, entity)
-	fmt.Fprintf(content, "\tvar %ss []domain.%s\n", entityLower, entity)
-	fmt.Fprintf(content, "\tresult := p.db.Find(&%ss)\n", entityLower)
-	content.WriteString("\tif result.Error != nil {\n")
-	content.WriteString("\t\treturn nil, result.Error\n")
-	content.WriteString("\t}\n")
-	fmt.Fprintf(content, "\treturn %ss, nil\n", entityLower)
-	content.WriteString("}\n\n")
+func New{{.Driver}}{{.Entity}}Repository(db *gorm.DB) {{.Entity}}Repository {
+{{- if .Partitioned}}
+	register{{.Entity}}Partitions(db)
+{{- end}}
+	return &{{.Repo}}{
+		db: db,
+	}
+}
+
+{{template "crud" .}}
+{{- end}}
+
+{{- define "crud" -}}
+func (p *{{.Repo}}) Save({{.Var}} *domain.{{.Entity}}) error {
+	result := p.db.Create({{.Var}})
+	return translateGormError(p.db, result.Error)
+}
+
+func (p *{{.Repo}}) FindByID(id int) (*domain.{{.Entity}}, error) {
+	{{.Var}} := &domain.{{.Entity}}{}
+	result := p.db.First({{.Var}}, id)
+	if result.Error != nil {
+		return nil, translateGormError(p.db, result.Error)
+	}
+	return {{.Var}}, nil
+}
+
+func (p *{{.Repo}}) Update({{.Var}} *domain.{{.Entity}}) error {
+{{if .Versioned}}{{.VersionedUpdate}}{{else -}}
+	result := p.db.Save({{.Var}})
+	return translateGormError(p.db, result.Error)
+{{end -}}
+}
+
+func (p *{{.Repo}}) Delete(id int) error {
+	result := p.db.Delete(&domain.{{.Entity}}{}, id)
+	return translateGormError(p.db, result.Error)
+}
+
+func (p *{{.Repo}}) FindAll() ([]domain.{{.Entity}}, error) {
+	var {{.Plural}} []domain.{{.Entity}}
+	result := p.db.Find(&{{.Plural}})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return {{.Plural}}, nil
+}
+
+{{end}}`)
+
+// generateBasicCRUDMethods generates basic CRUD methods.
+func generateBasicCRUDMethods(content *strings.Builder, entity, repoName string, versioned bool) {
+	data := newGormRepository(entity, "", versioned)
+	data.Repo = repoName
+	writeGoTemplate(content, gormRepositoryTemplates, "crud", data)
 }
 
 // generateTransactionMethods generates methods that support transactions.
-func generateTransactionMethods(content *strings.Builder, entity, repoName string) {
+func generateTransactionMethods(content *strings.Builder, entity, repoName string, versioned bool) {
 	entityLower := strings.ToLower(entity)
 
 	// SaveWithTx
 	fmt.Fprintf(content, "func (p *%s) SaveWithTx(tx *gorm.DB, %s *domain.%s) error {\n",
 		repoName, entityLower, entity)
 	fmt.Fprintf(content, "\tresult := tx.Create(%s)\n", entityLower)
-	content.WriteString("\treturn result.Error\n")
+	content.WriteString("\treturn translateGormError(tx, result.Error)\n")
 	content.WriteString("}\n\n")
 
 	// UpdateWithTx
 	fmt.Fprintf(content, "func (p *%s) UpdateWithTx(tx *gorm.DB, %s *domain.%s) error {\n",
 		repoName, entityLower, entity)
-	fmt.Fprintf(content, "\tresult := tx.Save(%s)\n", entityLower)
-	content.WriteString("\treturn result.Error\n")
+	if versioned {
+		writeGormVersionedUpdate(content, "tx", entityLower)
+	} else {
+		fmt.Fprintf(content, "\tresult := tx.Save(%s)\n", entityLower)
+		content.WriteString("\treturn translateGormError(tx, result.Error)\n")
+	}
 	content.WriteString("}\n\n")
 
 	// DeleteWithTx
 	fmt.Fprintf(content, "func (p *%s) DeleteWithTx(tx *gorm.DB, id int) error {\n", repoName)
 	fmt.Fprintf(content, "\tresult := tx.Delete(&domain.%s{}, id)\n", entity)
-	content.WriteString("\treturn result.Error\n")
+	content.WriteString("\treturn translateGormError(tx, result.Error)\n")
 	content.WriteString("}\n\n")
 }
 
@@ -336,10 +532,23 @@ func generateMongoRepositoryWithFields(dir, entity string, fields []Field, cache
 	// Get the module name from go.mod
 	moduleName := getModuleName()
 
+	// Search matches Like filters with quoted regular expressions.
+	searchMethods := generateRepositoryMethods(fields, entity)
+	searches := len(criteriaFields(fields)) > 0
+	doc := readMongoDocument(entity, fields)
+	indexKeys, _ := doc.indexes(searchMethods)
+	indexed := !doc.objectIDs || len(indexKeys) > 0
+
 	var content strings.Builder
 	content.WriteString("package repository\n\n")
 	content.WriteString("import (\n")
 	content.WriteString("\t\"context\"\n")
+	if indexed {
+		content.WriteString("\t\"log\"\n")
+	}
+	if searches {
+		content.WriteString("\t\"regexp\"\n")
+	}
 	content.WriteString("\t\"time\"\n")
 	content.WriteString(fmt.Sprintf("\t\"%s/internal/domain\"\n", getImportPath(moduleName)))
 	if cache {
@@ -348,32 +557,43 @@ func generateMongoRepositoryWithFields(dir, entity string, fields []Field, cache
 	}
 	if transactions {
 		content.WriteString("\t// MongoDB transaction support\n")
-		content.WriteString("\t// \"go.mongodb.org/mongo-driver/mongo/options\"\n")
 		content.WriteString("\t// \"go.mongodb.org/mongo-driver/mongo/writeconcern\"\n")
 	}
-	content.WriteString("\n\t\"go.mongodb.org/mongo-driver/mongo\"\n")
-	content.WriteString("\t\"go.mongodb.org/mongo-driver/bson\"\n")
+	content.WriteString("\n\t\"go.mongodb.org/mongo-driver/bson\"\n")
+	if doc.objectIDs {
+		content.WriteString("\t\"go.mongodb.org/mongo-driver/bson/primitive\"\n")
+	}
+	content.WriteString("\t\"go.mongodb.org/mongo-driver/mongo\"\n")
+	content.WriteString("\t\"go.mongodb.org/mongo-driver/mongo/options\"\n")
 	content.WriteString(")\n\n")
 
 	// MongoDB repository structure
 	repoName := fmt.Sprintf("mongo%sRepository", entity)
 	content.WriteString(fmt.Sprintf("type %s struct {\n", repoName))
 	content.WriteString("\tcollection *mongo.Collection\n")
+	if !doc.objectIDs {
+		content.WriteString("\tcounters   *mongo.Collection\n")
+	}
 	content.WriteString("}\n\n")
 
-	content.WriteString(fmt.Sprintf("func NewMongo%sRepository(db *mongo.Database) %sRepository {\n", entity, entity))
-	content.WriteString(fmt.Sprintf("\treturn &%s{\n", repoName))
-	content.WriteString(fmt.Sprintf("\t\tcollection: db.Collection(\"%ss\"),\n", entityLower))
-	content.WriteString("\t}\n")
-	content.WriteString("}\n\n")
+	writeMongoConstructor(&content, entity, repoName, doc, indexed)
+	if indexed {
+		writeMongoIndexes(&content, repoName, doc, searchMethods)
+	}
+	if !doc.objectIDs {
+		writeMongoNextID(&content, repoName)
+	}
 
-	// Generate basic MongoDB methods (simplified for brevity)
-	generateBasicMongoCRUDMethods(&content, entity, repoName)
+	generateBasicMongoCRUDMethods(&content, entity, repoName, doc, isVersionedEntity(entity))
+	writeMongoFindAllPaged(&content, entity, repoName, doc)
+	writeMongoCountMethods(&content, "m", repoName, entity, doc)
 
-	// Generate dynamic search methods for MongoDB
-	searchMethods := generateSearchMethods(fields, entity)
+	// Generate dynamic search methods, aggregates and Search for MongoDB
 	for _, method := range searchMethods {
-		content.WriteString(generateMongoSearchMethodImplementation(method, repoName, entity))
+		content.WriteString(generateMongoSearchMethodImplementation(method, repoName, entity, doc))
+	}
+	if searches {
+		writeMongoKeys(&content, entity, doc)
 	}
 
 	if err := writeGoFile(filename, content.String(), sm...); err != nil {
@@ -382,83 +602,167 @@ func generateMongoRepositoryWithFields(dir, entity string, fields []Field, cache
 }
 
 // generateBasicMongoCRUDMethods generates basic CRUD methods for MongoDB.
-func generateBasicMongoCRUDMethods(content *strings.Builder, entity, repoName string) {
+// Entities are matched by the key of their ID field; updates and deletes of
+// missing entities return domain.ErrNotFound. With ObjectIDs the entity is
+// written without its ID, which MongoDB keeps in _id, and IDs that are not
+// ObjectIDs match nothing.
+func generateBasicMongoCRUDMethods(content *strings.Builder, entity, repoName string, doc mongoDocument, versioned bool) {
 	entityLower := strings.ToLower(entity)
+	idKey := doc.key("ID")
+	idParam := "int"
+	if doc.objectIDs {
+		idParam = "string"
+	}
 
 	// Save method
 	fmt.Fprintf(content, "func (m *%s) Save(%s *domain.%s) error {\n", repoName, entityLower, entity)
 	content.WriteString("\tctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)\n")
 	content.WriteString("\tdefer cancel()\n")
-	fmt.Fprintf(content, "\t_, err := m.collection.InsertOne(ctx, %s)\n", entityLower)
-	content.WriteString("\treturn err\n")
+	if doc.objectIDs {
+		fmt.Fprintf(content, "\tdocument := *%s\n", entityLower)
+		content.WriteString("\tdocument.ID = \"\"\n")
+		content.WriteString("\tresult, err := m.collection.InsertOne(ctx, document)\n")
+		content.WriteString("\tif err != nil {\n")
+		content.WriteString("\t\treturn translateMongoError(err)\n")
+		content.WriteString("\t}\n")
+		fmt.Fprintf(content, "\t%s.ID = result.InsertedID.(primitive.ObjectID).Hex()\n", entityLower)
+		content.WriteString("\treturn nil\n")
+	} else {
+		fmt.Fprintf(content, "\tif %s.ID == 0 {\n", entityLower)
+		content.WriteString("\t\tid, err := m.nextID(ctx)\n")
+		content.WriteString("\t\tif err != nil {\n")
+		content.WriteString("\t\t\treturn err\n")
+		content.WriteString("\t\t}\n")
+		fmt.Fprintf(content, "\t\t%s.ID = %s(id)\n", entityLower, doc.idType)
+		content.WriteString("\t}\n")
+		fmt.Fprintf(content, "\t_, err := m.collection.InsertOne(ctx, %s)\n", entityLower)
+		content.WriteString("\treturn translateMongoError(err)\n")
+	}
 	content.WriteString("}\n\n")
 
 	// FindByID method
-	fmt.Fprintf(content, "func (m *%s) FindByID(id int) (*domain.%s, error) {\n", repoName, entity)
+	fmt.Fprintf(content, "func (m *%s) FindByID(id %s) (*domain.%s, error) {\n", repoName, idParam, entity)
+	idValue := "id"
+	if doc.objectIDs {
+		writeMongoObjectID(content, "id", "nil, ")
+		idValue = "oid"
+	}
 	content.WriteString("\tctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)\n")
 	content.WriteString("\tdefer cancel()\n")
 	fmt.Fprintf(content, "\t%s := &domain.%s{}\n", entityLower, entity)
-	content.WriteString("\terr := m.collection.FindOne(ctx, bson.M{\"id\": id}).Decode(" + entityLower + ")\n")
+	assign := ":="
+	if doc.objectIDs {
+		assign = "="
+	}
+	fmt.Fprintf(content, "\terr %s m.collection.FindOne(ctx, bson.M{%q: %s}).Decode(%s)\n", assign, idKey, idValue, entityLower)
 	content.WriteString("\tif err != nil {\n")
-	content.WriteString("\t\treturn nil, err\n")
+	content.WriteString("\t\treturn nil, translateMongoError(err)\n")
 	content.WriteString("\t}\n")
 	fmt.Fprintf(content, "\treturn %s, nil\n", entityLower)
 	content.WriteString("}\n\n")
 
 	// Update method
 	fmt.Fprintf(content, "func (m *%s) Update(%s *domain.%s) error {\n", repoName, entityLower, entity)
-	content.WriteString("\tctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)\n")
-	content.WriteString("\tdefer cancel()\n")
-	fmt.Fprintf(content, "\t_, err := m.collection.ReplaceOne(ctx, bson.M{\"id\": %s.ID}, %s)\n", entityLower, entityLower)
-	content.WriteString("\treturn err\n")
+	idValue, replacement := entityLower+".ID", entityLower
+	if doc.objectIDs {
+		writeMongoObjectID(content, entityLower+".ID", "")
+		idValue, replacement = "oid", "document"
+	}
+	if versioned {
+		writeMongoVersionedUpdate(content, entityLower, idKey, idValue, replacement, doc.key("Version"))
+	} else {
+		content.WriteString("\tctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)\n")
+		content.WriteString("\tdefer cancel()\n")
+		if doc.objectIDs {
+			writeMongoReplacement(content, entityLower)
+		}
+		fmt.Fprintf(content, "\tresult, err := m.collection.ReplaceOne(ctx, bson.M{%q: %s}, %s)\n", idKey, idValue, replacement)
+		content.WriteString("\tif err == nil && result.MatchedCount == 0 {\n")
+		content.WriteString("\t\terr = mongo.ErrNoDocuments\n")
+		content.WriteString("\t}\n")
+		content.WriteString("\treturn translateMongoError(err)\n")
+	}
 	content.WriteString("}\n\n")
 
 	// Delete method
-	fmt.Fprintf(content, "func (m *%s) Delete(id int) error {\n", repoName)
+	fmt.Fprintf(content, "func (m *%s) Delete(id %s) error {\n", repoName, idParam)
+	idValue = "id"
+	if doc.objectIDs {
+		writeMongoObjectID(content, "id", "")
+		idValue = "oid"
+	}
 	content.WriteString("\tctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)\n")
 	content.WriteString("\tdefer cancel()\n")
-	content.WriteString("\t_, err := m.collection.DeleteOne(ctx, bson.M{\"id\": id})\n")
-	content.WriteString("\treturn err\n")
+	fmt.Fprintf(content, "\tresult, err := m.collection.DeleteOne(ctx, bson.M{%q: %s})\n", idKey, idValue)
+	content.WriteString("\tif err == nil && result.DeletedCount == 0 {\n")
+	content.WriteString("\t\terr = mongo.ErrNoDocuments\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\treturn translateMongoError(err)\n")
 	content.WriteString("}\n\n")
 
 	// FindAll method
 	fmt.Fprintf(content, "func (m *%s) FindAll() ([]domain.%s, error) {\n", repoName, entity)
 	content.WriteString("\tctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)\n")
 	content.WriteString("\tdefer cancel()\n")
-	content.WriteString("\tcursor, err := m.collection.Find(ctx, bson.M{})\n")
+	fmt.Fprintf(content, "\tcursor, err := m.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: %q, Value: 1}}))\n", idKey)
 	content.WriteString("\tif err != nil {\n")
 	content.WriteString("\t\treturn nil, err\n")
 	content.WriteString("\t}\n")
 	content.WriteString("\tdefer cursor.Close(ctx)\n")
-	fmt.Fprintf(content, "\tvar %ss []domain.%s\n", entityLower, entity)
-	fmt.Fprintf(content, "\tif err := cursor.All(ctx, &%ss); err != nil {\n", entityLower)
+	fmt.Fprintf(content, "\t%s := []domain.%s{}\n", toPlural(entityLower), entity)
+	fmt.Fprintf(content, "\tif err := cursor.All(ctx, &%s); err != nil {\n", toPlural(entityLower))
 	content.WriteString("\t\treturn nil, err\n")
 	content.WriteString("\t}\n")
-	fmt.Fprintf(content, "\treturn %ss, nil\n", entityLower)
+	fmt.Fprintf(content, "\treturn %s, nil\n", toPlural(entityLower))
 	content.WriteString("}\n\n")
 }
 
 // generateMongoSearchMethodImplementation generates search method implementation for MongoDB.
-func generateMongoSearchMethodImplementation(method SearchMethod, repoName, entity string) string {
-	paramName := strings.ToLower(method.FieldName)
+func generateMongoSearchMethodImplementation(method SearchMethod, repoName, entity string, doc mongoDocument) string {
+	switch {
+	case method.isAggregate():
+		return generateMongoAggregateImplementation(method, repoName, doc)
+	case method.Kind == SearchCriteria:
+		return generateMongoCriteriaSearch(repoName, entity)
+	}
 	entityVar := strings.ToLower(entity)
 
+	var filter []string
+	for _, column := range method.columns() {
+		filter = append(filter, fmt.Sprintf("%q: %s", doc.key(column.Name), strings.ToLower(column.Name)))
+	}
+
 	var implementation strings.Builder
-	implementation.WriteString(fmt.Sprintf("func (m *%s) %s(%s %s) %s {\n",
-		repoName, method.MethodName, paramName, method.FieldType, method.ReturnType))
+	implementation.WriteString(fmt.Sprintf("func (m *%s) %s(%s) %s {\n",
+		repoName, method.MethodName, method.params(), method.ReturnType))
 
 	implementation.WriteString("\tctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)\n")
 	implementation.WriteString("\tdefer cancel()\n")
-	implementation.WriteString(fmt.Sprintf("\t%s := &domain.%s{}\n", entityVar, entity))
-	implementation.WriteString(fmt.Sprintf("\terr := m.collection.FindOne(ctx, bson.M{\"%s\": %s}).Decode(%s)\n",
-		strings.ToLower(method.FieldName), paramName, entityVar))
-	implementation.WriteString("\tif err != nil {\n")
-	implementation.WriteString("\t\treturn nil, err\n")
-	implementation.WriteString("\t}\n")
-	implementation.WriteString(fmt.Sprintf("\treturn %s, nil\n", entityVar))
+	implementation.WriteString(fmt.Sprintf("\tfilter := bson.M{%s}\n", strings.Join(filter, ", ")))
+	switch method.Kind {
+	case FinderAll:
+		implementation.WriteString("\tcursor, err := m.collection.Find(ctx, filter)\n")
+		implementation.WriteString("\tif err != nil {\n")
+		implementation.WriteString("\t\treturn nil, err\n")
+		implementation.WriteString("\t}\n")
+		implementation.WriteString("\tdefer cursor.Close(ctx)\n")
+		implementation.WriteString(fmt.Sprintf("\tvar %s []domain.%s\n", toPlural(entityVar), entity))
+		implementation.WriteString(fmt.Sprintf("\tif err := cursor.All(ctx, &%s); err != nil {\n", toPlural(entityVar)))
+		implementation.WriteString("\t\treturn nil, err\n")
+		implementation.WriteString("\t}\n")
+		implementation.WriteString(fmt.Sprintf("\treturn %s, nil\n", toPlural(entityVar)))
+	case FinderExists:
+		implementation.WriteString("\tcount, err := m.collection.CountDocuments(ctx, filter)\n")
+		implementation.WriteString("\treturn count > 0, err\n")
+	default:
+		implementation.WriteString(fmt.Sprintf("\t%s := &domain.%s{}\n", entityVar, entity))
+		implementation.WriteString(fmt.Sprintf("\terr := m.collection.FindOne(ctx, filter).Decode(%s)\n", entityVar))
+		implementation.WriteString("\tif err != nil {\n")
+		implementation.WriteString("\t\treturn nil, translateMongoError(err)\n")
+		implementation.WriteString("\t}\n")
+		implementation.WriteString(fmt.Sprintf("\treturn %s, nil\n", entityVar))
+	}
 	implementation.WriteString("}\n\n")
 
 	return implementation.String()
 }
-
-// generatePostgresJSONRepository generates a repository for PostgreSQL with JSONB support
@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateField_FinderModifiers(t *testing.T) {
+	v := NewFieldValidator()
+
+	field, err := v.ValidateField("email:string:findby:existsby")
+	require.NoError(t, err)
+	assert.Equal(t, "string", field.Type)
+	assert.Equal(t, []FieldFinder{{Kind: FinderOne}, {Kind: FinderExists}}, field.Finders)
+
+	field, err = v.ValidateField("status:string:findallby+tenant_id")
+	require.NoError(t, err)
+	assert.Equal(t, []FieldFinder{{Kind: FinderAll, With: []string{"tenant_id"}}}, field.Finders)
+
+	_, err = v.ValidateField("email:string:unique")
+	assert.ErrorContains(t, err, "unknown modifier")
+	_, err = v.ValidateField("tags:[]string:findby")
+	assert.ErrorContains(t, err, "cannot have a finder")
+
+	field, err = v.ValidateField("address:struct{city:string}")
+	require.NoError(t, err)
+	assert.Empty(t, field.Finders, "colons inside struct types are not modifiers")
+}
+
+func TestApplyFinderSpecs(t *testing.T) {
+	fields, err := applyFinderSpecs("email:string,status:string,tenant_id:uint", "email, findallby:status+tenant_id,existsby:email")
+	require.NoError(t, err)
+	assert.Equal(t, "email:string:findby:existsby,status:string:findallby+tenant_id,tenant_id:uint", fields)
+
+	_, err = applyFinderSpecs("email:string", "phone")
+	assert.ErrorContains(t, err, "no field phone")
+}
+
+func TestGenerateSearchMethods_OnlyNaturalKeys(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+
+	fields := "name:string,price:float64,stock:int,title:string,code:string,slug:string,username:string"
+	var names []string
+	for _, method := range generateSearchMethods(parseFields(fields), "Product") {
+		names = append(names, method.MethodName)
+	}
+	assert.Equal(t, []string{"FindByCode", "FindBySlug", "FindByUsername"}, names)
+
+	sm := NewSafetyManager(false, true, false)
+	writeTestFile(t, ".", ".goca.yaml", "project:\n  name: shop\n  module: example.com/shop\ndatabase:\n  type: mongodb\n")
+	generateRepository("Product", DBMongoDB, false, false, false, false, "name:string,price:float64,stock:int", sm)
+	for _, file := range []string{"interfaces.go", "mongo_product_repository.go"} {
+		src := readParsedGo(t, filepath.Join(DirInternal, DirRepository, file))
+		assert.NotContains(t, src, "FindByName", file)
+		assert.NotContains(t, src, "FindByStock", file)
+	}
+}
+
+func TestGenerateSearchMethods_Declared(t *testing.T) {
+	defer ensureTestUI(t)()
+
+	heuristic := generateSearchMethods(parseFields("email:string,price:float64"), "User")
+	require.Len(t, heuristic, 1)
+	assert.Equal(t, "FindByEmail", heuristic[0].MethodName)
+
+	fields := parseFieldsWithValidation("email:string:existsby,status:string:findallby+tenant_id:findallby+missing,tenant_id:uint", true)
+	methods := generateSearchMethods(fields, "User")
+	require.Len(t, methods, 2, "declared finders replace the derived ones; unknown columns are skipped")
+
+	assert.Equal(t, "ExistsByEmail", methods[0].MethodName)
+	assert.Equal(t, "(bool, error)", methods[0].ReturnType)
+	assert.Equal(t, "FindAllByStatusAndTenantID", methods[1].MethodName)
+	assert.Equal(t, "([]domain.User, error)", methods[1].ReturnType)
+	assert.Equal(t, "status string, tenantid uint", methods[1].params())
+	assert.Equal(t, "status, tenantid", methods[1].args())
+}
+
+func TestRepositoryFinders_Backends(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	dir := t.TempDir()
+	fields := parseFieldsWithValidation("email:string:findby:existsby,status:string:findallby+tenant_id,tenant_id:uint", true)
+
+	generateGormRepositoryWithFields(dir, "Account", "Postgres", fields, false, false)
+	gorm := readParsedGo(t, filepath.Join(dir, "postgres_account_repository.go"))
+	assert.Contains(t, gorm, `p.db.Where("email = ?", email).First(account)`)
+	assert.Contains(t, gorm, `p.db.Where("email = ?", email).Model(&domain.Account{}).Count(&count)`)
+	assert.Contains(t, gorm, `p.db.Where("status = ? AND tenant_id = ?", status, tenantid).Find(&accounts)`)
+
+	generateMongoRepositoryWithFields(dir, "Account", fields, false, false)
+	mongo := readParsedGo(t, filepath.Join(dir, "mongo_account_repository.go"))
+	assert.Contains(t, mongo, `filter := bson.M{"status": status, "tenantid": tenantid}`)
+	assert.Contains(t, mongo, "m.collection.CountDocuments(ctx, filter)")
+
+	mock := generateRepositoryMock("Account", generateSearchMethods(fields, "Account"))
+	assert.Contains(t, mock, "func (m *MockAccountRepository) ExistsByEmail(email string) (bool, error)")
+	assert.Contains(t, mock, "func (m *MockAccountRepository) FindAllByStatusAndTenantID(status string, tenantid uint) ([]domain.Account, error)")
+}
+
+func TestGeneratePostgresRepository_NoImplicitFinders(t *testing.T) {
+	chdirTemp(t)
+	dir := t.TempDir()
+	generatePostgresRepository(dir, "Product", false, false)
+	repo := readParsedGo(t, filepath.Join(dir, "postgres_product_repository.go"))
+	assert.NotContains(t, repo, "FindByEmail")
+}
+
+func TestInterfaceFinder(t *testing.T) {
+	src := `package repository
+
+type AccountRepository interface {
+	FindByID(id int) (*domain.Account, error)
+	FindAllByStatusAndTenantID(status string, tenantid uint) ([]domain.Account, error)
+	ExistsByEmail(email string) (bool, error)
+	FindByStatus(a, b string) (*domain.Account, error)
+}
+`
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	require.NoError(t, err)
+	it := findInterfaceType(file, "AccountRepository")
+	require.NotNil(t, it)
+
+	var names []string
+	for _, m := range it.Methods.List {
+		if method, ok := interfaceFinder(m, "Account"); ok {
+			names = append(names, method.MethodName)
+			if method.Kind == FinderAll {
+				assert.Equal(t, []Field{{Name: "Status", Type: "string"}, {Name: "TenantID", Type: "uint"}}, method.Columns)
+			}
+		}
+	}
+	assert.Equal(t, []string{"FindAllByStatusAndTenantID", "ExistsByEmail"}, names)
+}
+
+func TestRepositoryFinders_QueryEveryPage(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	fields := "email:string:findby:existsby,status:string:findallby"
+	require.NoError(t, generateEntity("Account", fields, true, false, false, false, false, false, false, false, "lowercase"))
+	generateRepository("Account", DBElasticsearch, false, false, false, false, fields)
+	generateRepository("Account", DBDynamoDB, false, true, false, false, fields)
+
+	es := readParsedGo(t, filepath.Join(DirInternal, DirRepository, "elasticsearch_account_repository.go"))
+	assert.Contains(t, es, `body := e.specQuery(AccountSpec{Where: map[string]interface{}{"status.keyword": status}})`)
+	assert.Contains(t, es, `body["search_after"] = sr.Hits.Hits[len(sr.Hits.Hits)-1].Sort`)
+	assert.NotContains(t, es, "items[i].Status == status")
+	dynamo := readParsedGo(t, filepath.Join(DirInternal, DirRepository, "dynamodb_account_repository.go"))
+	assert.Contains(t, dynamo, `input, err := d.scanInput(AccountSpec{Where: map[string]interface{}{"Email": email}})`)
+	assert.Contains(t, dynamo, "paginator := dynamodb.NewScanPaginator(d.client, input)")
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	// More matches than an Elasticsearch search returns by default (10) and
+	// than fit in one page of searchDocuments (500) or of the fake scan (100).
+	const active = 1203
+	esServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query       map[string]map[string][]map[string]map[string]interface{} `json:"query"`
+			Size        int                                                       `json:"size"`
+			SearchAfter []float64                                                 `json:"search_after"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		term := body.Query["bool"]["filter"][0]["term"]
+		after := 0
+		if len(body.SearchAfter) > 0 {
+			after = int(body.SearchAfter[0])
+		}
+		var hits []map[string]interface{}
+		for id := after + 1; id <= active+5 && len(hits) < body.Size; id++ {
+			doc := fakeAccount(id, active)
+			if doc["status"] == term["status.keyword"] || (term["email.keyword"] != nil && doc["email"] == term["email.keyword"]) {
+				hits = append(hits, map[string]interface{}{"_source": doc, "sort": []int{id}})
+			}
+		}
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"hits": map[string]interface{}{"hits": hits}})
+	}))
+	defer esServer.Close()
+	dynamoServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var scan struct {
+			ExpressionAttributeNames  map[string]string
+			ExpressionAttributeValues map[string]map[string]string
+			ExclusiveStartKey         map[string]map[string]string
+		}
+		_ = json.NewDecoder(r.Body).Decode(&scan)
+		start := 0
+		if key := scan.ExclusiveStartKey["ID"]["N"]; key != "" {
+			start, _ = strconv.Atoi(key)
+		}
+		attribute, want := scan.ExpressionAttributeNames["#a0"], scan.ExpressionAttributeValues[":v0"]["S"]
+		items := []map[string]interface{}{}
+		last := start
+		for id := start + 1; id <= active+5 && id <= start+100; id++ {
+			doc := fakeAccount(id, active)
+			last = id
+			if doc[strings.ToLower(attribute)] == want {
+				items = append(items, map[string]interface{}{
+					"ID": map[string]string{"N": strconv.Itoa(id)}, "Email": map[string]string{"S": doc["email"].(string)},
+					"Status": map[string]string{"S": doc["status"].(string)},
+				})
+			}
+		}
+		out := map[string]interface{}{"Items": items, "Count": len(items)}
+		if last < active+5 {
+			out["LastEvaluatedKey"] = map[string]interface{}{"ID": map[string]string{"N": strconv.Itoa(last)}}
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		_ = json.NewEncoder(w).Encode(out)
+	}))
+	defer dynamoServer.Close()
+
+	writeTestFile(t, ".", "go.mod", "module testproject\n\ngo 1.24\n\nrequire (\n"+
+		"\tgithub.com/aws/aws-sdk-go-v2 v1.47.1\n\tgithub.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue v1.21.8\n"+
+		"\tgithub.com/aws/aws-sdk-go-v2/service/dynamodb v1.70.0\n\tgithub.com/elastic/go-elasticsearch/v8 v8.10.1\n)\n")
+	writeTestFile(t, ".", "cmd/check/main.go", `package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/elastic/go-elasticsearch/v8"
+
+	"testproject/internal/domain"
+	"testproject/internal/repository"
+)
+
+func check(repo repository.AccountRepository) string {
+	all, err := repo.FindAllByStatus("active")
+	if err != nil {
+		return err.Error()
+	}
+	one, err := repo.FindByEmail("user1205@example.com")
+	if err != nil {
+		return err.Error()
+	}
+	exists, err := repo.ExistsByEmail("user7@example.com")
+	if err != nil {
+		return err.Error()
+	}
+	_, err = repo.FindByEmail("nobody@example.com")
+	return fmt.Sprintf("%d|%d|%t|%t", len(all), one.ID, exists, errors.Is(err, domain.ErrNotFound))
+}
+
+func main() {
+	es, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{os.Args[1]}})
+	if err != nil {
+		panic(err)
+	}
+	dynamo := dynamodb.New(dynamodb.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(os.Args[2]),
+		Credentials:  aws.AnonymousCredentials{},
+	})
+	fmt.Print(check(repository.NewElasticsearchAccountRepository(es)), " ", check(repository.NewDynamoDBAccountRepository(dynamo)))
+}
+`)
+	run := exec.Command("go", "run", "./cmd/check", esServer.URL, dynamoServer.URL)
+	run.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOSUMDB=off", "GOWORK=off")
+	out, err := run.CombinedOutput()
+	if err != nil && strings.Contains(string(out), "GOPROXY=off") {
+		t.Skip("Elasticsearch and AWS clients are not in the module cache")
+	}
+	require.NoError(t, err, string(out))
+	assert.Equal(t, "1203|1205|true|true 1203|1205|true|true", string(out))
+}
+
+// fakeAccount returns the document of account id: the first active accounts
+// are active, the others inactive.
+func fakeAccount(id, active int) map[string]interface{} {
+	status := "active"
+	if id > active {
+		status = "inactive"
+	}
+	return map[string]interface{}{"id": id, "email": fmt.Sprintf("user%d@example.com", id), "status": status}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSegregatedConfig(t *testing.T) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(".goca.yaml", []byte("project:\n  name: shop\n  module: example.com/shop\ngeneration:\n  interfaces:\n    segregated: true\n"), 0o644))
+}
+
+func TestWriteRepositoryInterfaces(t *testing.T) {
+	search := generateSearchMethods([]Field{{Name: "Email", Type: "string"}}, "User")
+	require.NotEmpty(t, search)
+
+	t.Run("combined", func(t *testing.T) {
+		var content strings.Builder
+		writeRepositoryInterfaces(&content, "User", search, false, false)
+		out := content.String()
+		assert.Contains(t, out, "type UserRepository interface {\n\tSave(user *domain.User) error\n")
+		assert.Contains(t, out, "FindByEmail(")
+		assert.NotContains(t, out, "UserReader")
+	})
+
+	t.Run("segregated", func(t *testing.T) {
+		var content strings.Builder
+		writeRepositoryInterfaces(&content, "User", search, true, true)
+		out := content.String()
+		assert.Contains(t, out, "type UserReader interface {\n\tFindByID(id int) (*domain.User, error)\n\tExistsByID(ctx context.Context, id int) (bool, error)\n\tFindAll() ([]domain.User, error)\n\tFindAllPaged(ctx context.Context, limit, offset int) ([]domain.User, int64, error)\n\tCount(ctx context.Context, spec UserSpec) (int64, error)\n}")
+		assert.Contains(t, out, "type UserWriter interface {")
+		assert.Contains(t, out, "\tDeleteWithTx(tx *gorm.DB, id int) error\n}")
+		assert.Contains(t, out, "type UserSearcher interface {")
+		assert.Contains(t, out, "type UserRepository interface {\n\tUserReader\n\tUserWriter\n\tUserSearcher\n}")
+	})
+
+	t.Run("segregated without finders", func(t *testing.T) {
+		var content strings.Builder
+		writeRepositoryInterfaces(&content, "User", nil, false, true)
+		out := content.String()
+		assert.NotContains(t, out, "UserSearcher")
+		assert.Contains(t, out, "type UserRepository interface {\n\tUserReader\n\tUserWriter\n}")
+	})
+}
+
+func TestServiceRepositoryInterface(t *testing.T) {
+	chdirTemp(t)
+	assert.Equal(t, "UserRepository", serviceRepositoryInterface("User", []string{OpRead}), "combined unless enabled")
+
+	writeSegregatedConfig(t)
+	assert.Equal(t, "UserReader", serviceRepositoryInterface("User", []string{OpRead, OpList}))
+	assert.Equal(t, "UserWriter", serviceRepositoryInterface("User", []string{OpCreate, OpDelete}))
+	assert.Equal(t, "UserRepository", serviceRepositoryInterface("User", []string{OpUpdate}))
+	assert.Equal(t, "UserRepository", serviceRepositoryInterface("User", []string{OpCreate, OpRead}))
+
+	dir := filepath.Join(DirInternal, DirRepository)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "interfaces.go"), []byte("package repository\n\ntype UserRepository interface{}\n"), 0o644))
+	assert.Equal(t, "UserRepository", serviceRepositoryInterface("User", []string{OpRead}), "existing combined-only interfaces are kept")
+}
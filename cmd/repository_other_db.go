@@ -14,6 +14,7 @@ func generatePostgresJSONRepository(dir, entity string, cache, transactions bool
 	var content strings.Builder
 	content.WriteString("package repository\n\n")
 	content.WriteString("import (\n")
+	content.WriteString("\t\"context\"\n")
 	content.WriteString("\t\"gorm.io/datatypes\"\n")
 	content.WriteString("\t\"gorm.io/gorm\"\n")
 	content.WriteString(fmt.Sprintf("\t\"%s/internal/domain\"\n", getImportPath(moduleName)))
@@ -27,45 +28,48 @@ func generatePostgresJSONRepository(dir, entity string, cache, transactions bool
 
 	// Save method with JSONB support
 	content.WriteString(fmt.Sprintf("func (p *%s) Save(%s *domain.%s) error {\n", repoName, entityLower, entity))
-	content.WriteString(fmt.Sprintf("\treturn p.db.Create(%s).Error\n", entityLower))
+	content.WriteString(fmt.Sprintf("\treturn translateGormError(p.db, p.db.Create(%s).Error)\n", entityLower))
 	content.WriteString("}\n\n")
 
 	// FindByID method
 	content.WriteString(fmt.Sprintf("func (p *%s) FindByID(id int) (*domain.%s, error) {\n", repoName, entity))
 	content.WriteString(fmt.Sprintf("\tvar %s domain.%s\n", entityLower, entity))
 	content.WriteString(fmt.Sprintf("\tif err := p.db.First(&%s, id).Error; err != nil {\n", entityLower))
-	content.WriteString("\t\treturn nil, err\n")
+	content.WriteString("\t\treturn nil, translateGormError(p.db, err)\n")
 	content.WriteString("\t}\n")
 	content.WriteString(fmt.Sprintf("\treturn &%s, nil\n", entityLower))
 	content.WriteString("}\n\n")
 
 	// FindByJSONField - Query nested JSON fields
 	content.WriteString(fmt.Sprintf("func (p *%s) FindByJSONField(jsonField, value string) ([]domain.%s, error) {\n", repoName, entity))
-	content.WriteString(fmt.Sprintf("\tvar %ss []domain.%s\n", entityLower, entity))
-	content.WriteString(fmt.Sprintf("\tif err := p.db.Where(\"data @> ?\", datatypes.JSONQuery(jsonField)).Find(&%ss).Error; err != nil {\n", entityLower))
+	content.WriteString(fmt.Sprintf("\tvar %s []domain.%s\n", toPlural(entityLower), entity))
+	content.WriteString(fmt.Sprintf("\tif err := p.db.Where(\"data @> ?\", datatypes.JSONQuery(jsonField)).Find(&%s).Error; err != nil {\n", toPlural(entityLower)))
 	content.WriteString("\t\treturn nil, err\n")
 	content.WriteString("\t}\n")
-	content.WriteString(fmt.Sprintf("\treturn %ss, nil\n", entityLower))
+	content.WriteString(fmt.Sprintf("\treturn %s, nil\n", toPlural(entityLower)))
 	content.WriteString("}\n\n")
 
 	// Update method
 	content.WriteString(fmt.Sprintf("func (p *%s) Update(%s *domain.%s) error {\n", repoName, entityLower, entity))
-	content.WriteString(fmt.Sprintf("\treturn p.db.Save(%s).Error\n", entityLower))
+	content.WriteString(fmt.Sprintf("\treturn translateGormError(p.db, p.db.Save(%s).Error)\n", entityLower))
 	content.WriteString("}\n\n")
 
 	// Delete method
 	content.WriteString(fmt.Sprintf("func (p *%s) Delete(id int) error {\n", repoName))
-	content.WriteString(fmt.Sprintf("\treturn p.db.Delete(&domain.%s{}, id).Error\n", entity))
+	content.WriteString(fmt.Sprintf("\treturn translateGormError(p.db, p.db.Delete(&domain.%s{}, id).Error)\n", entity))
 	content.WriteString("}\n\n")
 
 	// FindAll method
 	content.WriteString(fmt.Sprintf("func (p *%s) FindAll() ([]domain.%s, error) {\n", repoName, entity))
-	content.WriteString(fmt.Sprintf("\tvar %ss []domain.%s\n", entityLower, entity))
-	content.WriteString(fmt.Sprintf("\tif err := p.db.Find(&%ss).Error; err != nil {\n", entityLower))
+	content.WriteString(fmt.Sprintf("\tvar %s []domain.%s\n", toPlural(entityLower), entity))
+	content.WriteString(fmt.Sprintf("\tif err := p.db.Find(&%s).Error; err != nil {\n", toPlural(entityLower)))
 	content.WriteString("\t\treturn nil, err\n")
 	content.WriteString("\t}\n")
-	content.WriteString(fmt.Sprintf("\treturn %ss, nil\n", entityLower))
-	content.WriteString("}\n")
+	content.WriteString(fmt.Sprintf("\treturn %s, nil\n", toPlural(entityLower)))
+	content.WriteString("}\n\n")
+
+	writeGormCountMethods(&content, "p", repoName, entity)
+	writeGormFindAllPaged(&content, "p", repoName, entity)
 
 	if err := writeGoFile(filename, content.String(), sm...); err != nil {
 		fmt.Printf("Error creating PostgreSQL JSON repository file: %v\n", err)
@@ -81,6 +85,7 @@ func generateSQLServerRepository(dir, entity string, cache, transactions bool, s
 	var content strings.Builder
 	content.WriteString("package repository\n\n")
 	content.WriteString("import (\n")
+	content.WriteString("\t\"context\"\n")
 	content.WriteString("\t\"fmt\"\n")
 	content.WriteString("\t\"gorm.io/gorm\"\n")
 	content.WriteString(fmt.Sprintf("\t\"%s/internal/domain\"\n", getImportPath(moduleName)))
@@ -95,7 +100,7 @@ func generateSQLServerRepository(dir, entity string, cache, transactions bool, s
 	// Save method
 	content.WriteString(fmt.Sprintf("func (s *%s) Save(%s *domain.%s) error {\n", repoName, entityLower, entity))
 	content.WriteString(fmt.Sprintf("\tif err := s.db.Create(%s).Error; err != nil {\n", entityLower))
-	content.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"failed to save %s: %%w\", err)\n", entityLower))
+	content.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"failed to save %s: %%w\", translateGormError(s.db, err))\n", entityLower))
 	content.WriteString("\t}\n")
 	content.WriteString("\treturn nil\n")
 	content.WriteString("}\n\n")
@@ -104,10 +109,7 @@ func generateSQLServerRepository(dir, entity string, cache, transactions bool, s
 	content.WriteString(fmt.Sprintf("func (s *%s) FindByID(id int) (*domain.%s, error) {\n", repoName, entity))
 	content.WriteString(fmt.Sprintf("\tvar %s domain.%s\n", entityLower, entity))
 	content.WriteString(fmt.Sprintf("\tif err := s.db.WithContext(s.db.Statement.Context).First(&%s, id).Error; err != nil {\n", entityLower))
-	content.WriteString("\t\tif err == gorm.ErrRecordNotFound {\n")
-	content.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"%s not found\")\n", entity))
-	content.WriteString("\t\t}\n")
-	content.WriteString("\t\treturn nil, err\n")
+	content.WriteString("\t\treturn nil, translateGormError(s.db, err)\n")
 	content.WriteString("\t}\n")
 	content.WriteString(fmt.Sprintf("\treturn &%s, nil\n", entityLower))
 	content.WriteString("}\n\n")
@@ -115,7 +117,7 @@ func generateSQLServerRepository(dir, entity string, cache, transactions bool, s
 	// Update method
 	content.WriteString(fmt.Sprintf("func (s *%s) Update(%s *domain.%s) error {\n", repoName, entityLower, entity))
 	content.WriteString(fmt.Sprintf("\tif err := s.db.Save(%s).Error; err != nil {\n", entityLower))
-	content.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"failed to update %s: %%w\", err)\n", entityLower))
+	content.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"failed to update %s: %%w\", translateGormError(s.db, err))\n", entityLower))
 	content.WriteString("\t}\n")
 	content.WriteString("\treturn nil\n")
 	content.WriteString("}\n\n")
@@ -123,19 +125,22 @@ func generateSQLServerRepository(dir, entity string, cache, transactions bool, s
 	// Delete method
 	content.WriteString(fmt.Sprintf("func (s *%s) Delete(id int) error {\n", repoName))
 	content.WriteString(fmt.Sprintf("\tif err := s.db.Delete(&domain.%s{}, id).Error; err != nil {\n", entity))
-	content.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"failed to delete %s: %%w\", err)\n", entityLower))
+	content.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"failed to delete %s: %%w\", translateGormError(s.db, err))\n", entityLower))
 	content.WriteString("\t}\n")
 	content.WriteString("\treturn nil\n")
 	content.WriteString("}\n\n")
 
 	// FindAll method
 	content.WriteString(fmt.Sprintf("func (s *%s) FindAll() ([]domain.%s, error) {\n", repoName, entity))
-	content.WriteString(fmt.Sprintf("\tvar %ss []domain.%s\n", entityLower, entity))
-	content.WriteString(fmt.Sprintf("\tif err := s.db.Find(&%ss).Error; err != nil {\n", entityLower))
-	content.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"failed to fetch %ss: %%w\", err)\n", entityLower))
+	content.WriteString(fmt.Sprintf("\tvar %s []domain.%s\n", toPlural(entityLower), entity))
+	content.WriteString(fmt.Sprintf("\tif err := s.db.Find(&%s).Error; err != nil {\n", toPlural(entityLower)))
+	content.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"failed to fetch %s: %%w\", err)\n", toPlural(entityLower)))
 	content.WriteString("\t}\n")
-	content.WriteString(fmt.Sprintf("\treturn %ss, nil\n", entityLower))
-	content.WriteString("}\n")
+	content.WriteString(fmt.Sprintf("\treturn %s, nil\n", toPlural(entityLower)))
+	content.WriteString("}\n\n")
+
+	writeGormCountMethods(&content, "s", repoName, entity)
+	writeGormFindAllPaged(&content, "s", repoName, entity)
 
 	if err := writeGoFile(filename, content.String(), sm...); err != nil {
 		fmt.Printf("Error creating SQL Server repository file: %v\n", err)
@@ -177,7 +182,7 @@ func generateElasticsearchRepository(dir, entity string, cache, transactions boo
 	content.WriteString("\tres, err := req.Do(context.Background(), e.client)\n")
 	content.WriteString("\tif err != nil {\n\t\treturn err\n\t}\n")
 	content.WriteString("\tdefer res.Body.Close()\n")
-	content.WriteString("\treturn nil\n")
+	content.WriteString("\treturn translateElasticsearchResponse(res)\n")
 	content.WriteString("}\n\n")
 
 	// FindByID method
@@ -189,6 +194,7 @@ func generateElasticsearchRepository(dir, entity string, cache, transactions boo
 	content.WriteString("\tres, err := req.Do(context.Background(), e.client)\n")
 	content.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
 	content.WriteString("\tdefer res.Body.Close()\n")
+	content.WriteString("\tif err := translateElasticsearchResponse(res); err != nil {\n\t\treturn nil, err\n\t}\n")
 	// An Elasticsearch GET wraps the document under "_source"; decode that
 	// rather than the envelope so the returned entity is actually populated.
 	content.WriteString("\tvar envelope struct {\n")
@@ -236,34 +242,12 @@ func generateElasticsearchRepository(dir, entity string, cache, transactions boo
 
 	// FindAll method
 	content.WriteString(fmt.Sprintf("func (e *%s) FindAll() ([]domain.%s, error) {\n", repoName, entity))
-	content.WriteString("\tsearchBody := map[string]interface{}{\n")
-	content.WriteString("\t\t\"query\": map[string]interface{}{\n")
-	content.WriteString("\t\t\t\"match_all\": map[string]interface{}{},\n")
-	content.WriteString("\t\t},\n")
-	content.WriteString("\t}\n")
-	content.WriteString("\tvar buf bytes.Buffer\n")
-	content.WriteString("\tif err := json.NewEncoder(&buf).Encode(searchBody); err != nil {\n")
-	content.WriteString("\t\treturn nil, err\n\t}\n")
-	content.WriteString("\treq := esapi.SearchRequest{\n")
-	content.WriteString("\t\tIndex: []string{e.index},\n")
-	content.WriteString("\t\tBody:  &buf,\n")
-	content.WriteString("\t}\n")
-	content.WriteString("\tres, err := req.Do(context.Background(), e.client)\n")
-	content.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
-	content.WriteString("\tdefer res.Body.Close()\n")
-	// Extract each hit's _source into the domain slice; previously the response
-	// body was never read and an empty slice was returned.
-	content.WriteString("\tvar sr struct {\n")
-	content.WriteString("\t\tHits struct {\n")
-	content.WriteString(fmt.Sprintf("\t\t\tHits []struct {\n\t\t\t\tSource domain.%s `json:\"_source\"`\n\t\t\t} `json:\"hits\"`\n", entity))
-	content.WriteString("\t\t} `json:\"hits\"`\n")
+	content.WriteString("\tbody := map[string]interface{}{\n")
+	content.WriteString("\t\t\"query\": map[string]interface{}{\"match_all\": map[string]interface{}{}},\n")
 	content.WriteString("\t}\n")
-	content.WriteString("\tif err := json.NewDecoder(res.Body).Decode(&sr); err != nil {\n")
-	content.WriteString("\t\treturn nil, err\n\t}\n")
-	content.WriteString(fmt.Sprintf("\tresults := make([]domain.%s, 0, len(sr.Hits.Hits))\n", entity))
-	content.WriteString("\tfor _, h := range sr.Hits.Hits {\n\t\tresults = append(results, h.Source)\n\t}\n")
-	content.WriteString("\treturn results, nil\n")
+	content.WriteString("\treturn e.searchDocuments(context.Background(), body, 0)\n")
 	content.WriteString("}\n\n")
+	writeElasticsearchSearchDocuments(&content, repoName, entity)
 
 	// Delete method
 	content.WriteString(fmt.Sprintf("func (e *%s) Delete(id int) error {\n", repoName))
@@ -274,13 +258,16 @@ func generateElasticsearchRepository(dir, entity string, cache, transactions boo
 	content.WriteString("\tres, err := req.Do(context.Background(), e.client)\n")
 	content.WriteString("\tif err != nil {\n\t\treturn err\n\t}\n")
 	content.WriteString("\tdefer res.Body.Close()\n")
-	content.WriteString("\treturn nil\n")
+	content.WriteString("\treturn translateElasticsearchResponse(res)\n")
 	content.WriteString("}\n\n")
 
 	// Update method (stub)
 	content.WriteString(fmt.Sprintf("func (e *%s) Update(%s *domain.%s) error {\n", repoName, entityLower, entity))
 	content.WriteString(fmt.Sprintf("\treturn e.Save(%s)\n", entityLower))
-	content.WriteString("}\n")
+	content.WriteString("}\n\n")
+
+	writeElasticsearchCountMethods(&content, repoName, entity)
+	writeElasticsearchFindAllPaged(&content, repoName, entity)
 
 	if err := writeGoFile(filename, content.String(), sm...); err != nil {
 		fmt.Printf("Error creating Elasticsearch repository file: %v\n", err)
@@ -301,7 +288,9 @@ func generateDynamoDBRepository(dir, entity string, cache, transactions bool, sm
 	content.WriteString("\t\"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue\"\n")
 	content.WriteString("\t\"github.com/aws/aws-sdk-go-v2/service/dynamodb\"\n")
 	content.WriteString("\t\"github.com/aws/aws-sdk-go-v2/service/dynamodb/types\"\n")
+	content.WriteString("\t\"sort\"\n")
 	content.WriteString("\t\"strconv\"\n")
+	content.WriteString("\t\"strings\"\n")
 	content.WriteString(fmt.Sprintf("\t\"%s/internal/domain\"\n", getImportPath(moduleName)))
 	content.WriteString(")\n\n")
 
@@ -319,7 +308,7 @@ func generateDynamoDBRepository(dir, entity string, cache, transactions bool, sm
 	content.WriteString("\t\tTableName: &d.tableName,\n")
 	content.WriteString("\t\tItem:      av,\n")
 	content.WriteString("\t})\n")
-	content.WriteString("\treturn err\n")
+	content.WriteString("\treturn translateDynamoDBError(err)\n")
 	content.WriteString("}\n\n")
 
 	// FindByID method
@@ -331,6 +320,7 @@ func generateDynamoDBRepository(dir, entity string, cache, transactions bool, sm
 	content.WriteString("\t\t},\n")
 	content.WriteString("\t})\n")
 	content.WriteString("\tif err != nil {\n\t\treturn nil, fmt.Errorf(\"failed to get item: %w\", err)\n\t}\n")
+	content.WriteString("\tif result.Item == nil {\n\t\treturn nil, domain.ErrNotFound\n\t}\n")
 	content.WriteString(fmt.Sprintf("\tvar %s domain.%s\n", entityLower, entity))
 	content.WriteString(fmt.Sprintf("\terr = attributevalue.UnmarshalMap(result.Item, &%s)\n", entityLower))
 	content.WriteString("\tif err != nil {\n\t\treturn nil, fmt.Errorf(\"failed to unmarshal: %w\", err)\n\t}\n")
@@ -350,26 +340,86 @@ func generateDynamoDBRepository(dir, entity string, cache, transactions bool, sm
 	content.WriteString("\t\t\t\"id\": &types.AttributeValueMemberN{Value: strconv.Itoa(id)},\n")
 	content.WriteString("\t\t},\n")
 	content.WriteString("\t})\n")
-	content.WriteString("\treturn err\n")
+	content.WriteString("\treturn translateDynamoDBError(err)\n")
 	content.WriteString("}\n\n")
 
 	// FindAll method
 	content.WriteString(fmt.Sprintf("func (d *%s) FindAll() ([]domain.%s, error) {\n", repoName, entity))
-	content.WriteString("\tresult, err := d.client.Scan(context.Background(), &dynamodb.ScanInput{\n")
-	content.WriteString("\t\tTableName: &d.tableName,\n")
-	content.WriteString("\t})\n")
-	content.WriteString("\tif err != nil {\n\t\treturn nil, fmt.Errorf(\"failed to scan: %w\", err)\n\t}\n")
-	content.WriteString(fmt.Sprintf("\tvar %ss []domain.%s\n", entityLower, entity))
-	content.WriteString(fmt.Sprintf("\terr = attributevalue.UnmarshalListOfMaps(result.Items, &%ss)\n", entityLower))
-	content.WriteString("\tif err != nil {\n\t\treturn nil, fmt.Errorf(\"failed to unmarshal: %w\", err)\n\t}\n")
-	content.WriteString(fmt.Sprintf("\treturn %ss, nil\n", entityLower))
-	content.WriteString("}\n")
+	content.WriteString("\treturn d.scanAll(context.Background(), &dynamodb.ScanInput{TableName: &d.tableName}, 0)\n")
+	content.WriteString("}\n\n")
+	writeDynamoDBScanAll(&content, repoName, entity)
+
+	writeDynamoDBCountMethods(&content, repoName, entity)
+	writeDynamoDBFindAllPaged(&content, repoName, entity)
 
 	if err := writeGoFile(filename, content.String(), sm...); err != nil {
 		fmt.Printf("Error creating DynamoDB repository file: %v\n", err)
 	}
 }
 
+// writeElasticsearchSearchDocuments writes the searchDocuments helper behind
+// FindAll and the finders. It sorts by id and reads page after page with
+// search_after, so results are not cut at the 10 hits of a plain search.
+func writeElasticsearchSearchDocuments(content *strings.Builder, repoName, entity string) {
+	content.WriteString("// searchDocuments returns the documents matching body sorted by id, reading\n")
+	content.WriteString("// every page of hits. A positive limit stops after that many documents.\n")
+	fmt.Fprintf(content, "func (e *%s) searchDocuments(ctx context.Context, body map[string]interface{}, limit int) ([]domain.%s, error) {\n", repoName, entity)
+	content.WriteString("\tsize := 500\n")
+	content.WriteString("\tif limit > 0 && limit < size {\n\t\tsize = limit\n\t}\n")
+	content.WriteString("\tbody[\"sort\"] = []interface{}{\"id\"}\n")
+	content.WriteString("\tbody[\"size\"] = size\n")
+	fmt.Fprintf(content, "\tresults := make([]domain.%s, 0)\n", entity)
+	content.WriteString("\tfor {\n")
+	content.WriteString("\t\tvar buf bytes.Buffer\n")
+	content.WriteString("\t\tif err := json.NewEncoder(&buf).Encode(body); err != nil {\n")
+	content.WriteString("\t\t\treturn nil, err\n\t\t}\n")
+	content.WriteString("\t\treq := esapi.SearchRequest{\n")
+	content.WriteString("\t\t\tIndex: []string{e.index},\n")
+	content.WriteString("\t\t\tBody:  &buf,\n")
+	content.WriteString("\t\t}\n")
+	content.WriteString("\t\tres, err := req.Do(ctx, e.client)\n")
+	content.WriteString("\t\tif err != nil {\n\t\t\treturn nil, err\n\t\t}\n")
+	content.WriteString("\t\tvar sr struct {\n")
+	content.WriteString("\t\t\tHits struct {\n")
+	content.WriteString("\t\t\t\tHits []struct {\n")
+	fmt.Fprintf(content, "\t\t\t\t\tSource domain.%s `json:\"_source\"`\n", entity)
+	content.WriteString("\t\t\t\t\tSort   []interface{} `json:\"sort\"`\n")
+	content.WriteString("\t\t\t\t} `json:\"hits\"`\n")
+	content.WriteString("\t\t\t} `json:\"hits\"`\n")
+	content.WriteString("\t\t}\n")
+	content.WriteString("\t\terr = translateElasticsearchResponse(res)\n")
+	content.WriteString("\t\tif err == nil {\n\t\t\terr = json.NewDecoder(res.Body).Decode(&sr)\n\t\t}\n")
+	content.WriteString("\t\tres.Body.Close()\n")
+	content.WriteString("\t\tif err != nil {\n\t\t\treturn nil, err\n\t\t}\n")
+	content.WriteString("\t\tfor _, h := range sr.Hits.Hits {\n\t\t\tresults = append(results, h.Source)\n\t\t}\n")
+	content.WriteString("\t\tif len(sr.Hits.Hits) < size || (limit > 0 && len(results) >= limit) {\n")
+	content.WriteString("\t\t\treturn results, nil\n\t\t}\n")
+	content.WriteString("\t\tbody[\"search_after\"] = sr.Hits.Hits[len(sr.Hits.Hits)-1].Sort\n")
+	content.WriteString("\t}\n")
+	content.WriteString("}\n\n")
+}
+
+// writeDynamoDBScanAll writes the scanAll helper behind FindAll and the
+// finders, which follows LastEvaluatedKey past the 1 MB page of a scan.
+func writeDynamoDBScanAll(content *strings.Builder, repoName, entity string) {
+	content.WriteString("// scanAll returns the items of every page of the scan. A positive limit\n")
+	content.WriteString("// stops after that many items.\n")
+	fmt.Fprintf(content, "func (d *%s) scanAll(ctx context.Context, input *dynamodb.ScanInput, limit int) ([]domain.%s, error) {\n", repoName, entity)
+	fmt.Fprintf(content, "\titems := make([]domain.%s, 0)\n", entity)
+	content.WriteString("\tpaginator := dynamodb.NewScanPaginator(d.client, input)\n")
+	content.WriteString("\tfor paginator.HasMorePages() && (limit <= 0 || len(items) < limit) {\n")
+	content.WriteString("\t\tpage, err := paginator.NextPage(ctx)\n")
+	content.WriteString("\t\tif err != nil {\n\t\t\treturn nil, fmt.Errorf(\"failed to scan: %w\", err)\n\t\t}\n")
+	fmt.Fprintf(content, "\t\tvar pageItems []domain.%s\n", entity)
+	content.WriteString("\t\tif err := attributevalue.UnmarshalListOfMaps(page.Items, &pageItems); err != nil {\n")
+	content.WriteString("\t\t\treturn nil, fmt.Errorf(\"failed to unmarshal: %w\", err)\n\t\t}\n")
+	content.WriteString("\t\titems = append(items, pageItems...)\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tif limit > 0 && len(items) > limit {\n\t\titems = items[:limit]\n\t}\n")
+	content.WriteString("\treturn items, nil\n")
+	content.WriteString("}\n\n")
+}
+
 // generateSQLiteRepository generates a repository for SQLite with database/sql.
 func generateSQLiteRepository(dir, entity string, cache, transactions bool, sm ...*SafetyManager) {
 	entityLower := strings.ToLower(entity)
@@ -379,13 +429,16 @@ func generateSQLiteRepository(dir, entity string, cache, transactions bool, sm .
 	var content strings.Builder
 	content.WriteString("package repository\n\n")
 	content.WriteString("import (\n")
+	content.WriteString("\t\"context\"\n")
 	content.WriteString("\t\"database/sql\"\n")
 	content.WriteString("\t\"encoding/json\"\n")
 	content.WriteString("\t\"fmt\"\n")
+	content.WriteString("\t\"strings\"\n")
 	content.WriteString(fmt.Sprintf("\t\"%s/internal/domain\"\n", getImportPath(moduleName)))
 	content.WriteString(")\n\n")
 
 	repoName := fmt.Sprintf("sqlite%sRepository", entity)
+	table := entityTableName(entity)
 	content.WriteString(fmt.Sprintf("type %s struct {\n\tdb *sql.DB\n}\n\n", repoName))
 	content.WriteString(fmt.Sprintf("func NewSQLite%sRepository(db *sql.DB) %sRepository {\n", entity, entity))
 	content.WriteString(fmt.Sprintf("\treturn &%s{db: db}\n", repoName))
@@ -396,7 +449,7 @@ func generateSQLiteRepository(dir, entity string, cache, transactions bool, sm .
 	content.WriteString("\tvar data []byte\n")
 	content.WriteString(fmt.Sprintf("\tdata, err := json.Marshal(%s)\n", entityLower))
 	content.WriteString("\tif err != nil {\n\t\treturn fmt.Errorf(\"failed to marshal: %w\", err)\n\t}\n")
-	content.WriteString(fmt.Sprintf("\tquery := \"INSERT INTO %ss (data) VALUES (?)\"\n", entityLower))
+	content.WriteString(fmt.Sprintf("\tquery := \"INSERT INTO %s (data) VALUES (?)\"\n", table))
 	content.WriteString("\tif _, err := s.db.Exec(query, data); err != nil {\n")
 	content.WriteString("\t\treturn fmt.Errorf(\"failed to insert: %w\", err)\n\t}\n")
 	content.WriteString("\treturn nil\n")
@@ -405,7 +458,7 @@ func generateSQLiteRepository(dir, entity string, cache, transactions bool, sm .
 	// FindByID method
 	content.WriteString(fmt.Sprintf("func (s *%s) FindByID(id int) (*domain.%s, error) {\n", repoName, entity))
 	content.WriteString("\tvar data []byte\n")
-	content.WriteString(fmt.Sprintf("\tquery := \"SELECT data FROM %ss WHERE id = ? LIMIT 1\"\n", entityLower))
+	content.WriteString(fmt.Sprintf("\tquery := \"SELECT data FROM %s WHERE id = ? LIMIT 1\"\n", table))
 	content.WriteString("\tif err := s.db.QueryRow(query, id).Scan(&data); err != nil {\n")
 	content.WriteString(fmt.Sprintf("\t\tif err == sql.ErrNoRows {\n\t\t\treturn nil, fmt.Errorf(\"%s not found\")\n\t\t}\n", entity))
 	content.WriteString("\t\treturn nil, fmt.Errorf(\"failed to query: %w\", err)\n\t}\n")
@@ -419,7 +472,7 @@ func generateSQLiteRepository(dir, entity string, cache, transactions bool, sm .
 	content.WriteString(fmt.Sprintf("func (s *%s) Update(%s *domain.%s) error {\n", repoName, entityLower, entity))
 	content.WriteString(fmt.Sprintf("\tdata, err := json.Marshal(%s)\n", entityLower))
 	content.WriteString("\tif err != nil {\n\t\treturn fmt.Errorf(\"failed to marshal: %w\", err)\n\t}\n")
-	content.WriteString(fmt.Sprintf("\tquery := \"UPDATE %ss SET data = ? WHERE id = ?\"\n", entityLower))
+	content.WriteString(fmt.Sprintf("\tquery := \"UPDATE %s SET data = ? WHERE id = ?\"\n", table))
 	content.WriteString(fmt.Sprintf("\tif _, err := s.db.Exec(query, data, %s.ID); err != nil {\n", entityLower))
 	content.WriteString("\t\treturn fmt.Errorf(\"failed to update: %w\", err)\n\t}\n")
 	content.WriteString("\treturn nil\n")
@@ -427,7 +480,7 @@ func generateSQLiteRepository(dir, entity string, cache, transactions bool, sm .
 
 	// Delete method
 	content.WriteString(fmt.Sprintf("func (s *%s) Delete(id int) error {\n", repoName))
-	content.WriteString(fmt.Sprintf("\tquery := \"DELETE FROM %ss WHERE id = ?\"\n", entityLower))
+	content.WriteString(fmt.Sprintf("\tquery := \"DELETE FROM %s WHERE id = ?\"\n", table))
 	content.WriteString("\tif _, err := s.db.Exec(query, id); err != nil {\n")
 	content.WriteString("\t\treturn fmt.Errorf(\"failed to delete: %w\", err)\n\t}\n")
 	content.WriteString("\treturn nil\n")
@@ -435,21 +488,24 @@ func generateSQLiteRepository(dir, entity string, cache, transactions bool, sm .
 
 	// FindAll method
 	content.WriteString(fmt.Sprintf("func (s *%s) FindAll() ([]domain.%s, error) {\n", repoName, entity))
-	content.WriteString(fmt.Sprintf("\tquery := \"SELECT data FROM %ss\"\n", entityLower))
+	content.WriteString(fmt.Sprintf("\tquery := \"SELECT data FROM %s\"\n", table))
 	content.WriteString("\trows, err := s.db.Query(query)\n")
 	content.WriteString("\tif err != nil {\n\t\treturn nil, fmt.Errorf(\"failed to query: %w\", err)\n\t}\n")
 	content.WriteString("\tdefer rows.Close()\n")
-	content.WriteString(fmt.Sprintf("\tvar %ss []domain.%s\n", entityLower, entity))
+	content.WriteString(fmt.Sprintf("\tvar %s []domain.%s\n", toPlural(entityLower), entity))
 	content.WriteString("\tfor rows.Next() {\n")
 	content.WriteString("\t\tvar data []byte\n")
 	content.WriteString("\t\tif err := rows.Scan(&data); err != nil {\n\t\t\treturn nil, fmt.Errorf(\"failed to scan: %w\", err)\n\t\t}\n")
 	content.WriteString(fmt.Sprintf("\t\tvar %s domain.%s\n", entityLower, entity))
 	content.WriteString(fmt.Sprintf("\t\tif err := json.Unmarshal(data, &%s); err != nil {\n\t\t\treturn nil, fmt.Errorf(\"failed to unmarshal: %%w\", err)\n\t\t}\n", entityLower))
-	content.WriteString(fmt.Sprintf("\t\t%ss = append(%ss, %s)\n", entityLower, entityLower, entityLower))
+	content.WriteString(fmt.Sprintf("\t\t%s = append(%s, %s)\n", toPlural(entityLower), toPlural(entityLower), entityLower))
 	content.WriteString("\t}\n")
 	content.WriteString("\tif err := rows.Err(); err != nil {\n\t\treturn nil, fmt.Errorf(\"rows error: %w\", err)\n\t}\n")
-	content.WriteString(fmt.Sprintf("\treturn %ss, nil\n", entityLower))
-	content.WriteString("}\n")
+	content.WriteString(fmt.Sprintf("\treturn %s, nil\n", toPlural(entityLower)))
+	content.WriteString("}\n\n")
+
+	writeSQLiteCountMethods(&content, repoName, entity)
+	writeSQLiteFindAllPaged(&content, repoName, entity)
 
 	if err := writeGoFile(filename, content.String(), sm...); err != nil {
 		fmt.Printf("Error creating SQLite repository file: %v\n", err)
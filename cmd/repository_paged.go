@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pagedSignature returns the FindAllPaged interface method.
+func pagedSignature(entity string) string {
+	return fmt.Sprintf("\tFindAllPaged(ctx context.Context, limit, offset int) ([]domain.%s, int64, error)\n", entity)
+}
+
+// writeGormFindAllPaged writes FindAllPaged for a repository whose receiver
+// exposes a `db *gorm.DB`: at most limit entities after the first offset, in
+// ID order, and the total number of entities.
+func writeGormFindAllPaged(content *strings.Builder, recv, repoName, entity string) {
+	plural := toPlural(strings.ToLower(entity))
+	fmt.Fprintf(content, "func (%s *%s) FindAllPaged(ctx context.Context, limit, offset int) ([]domain.%s, int64, error) {\n", recv, repoName, entity)
+	fmt.Fprintf(content, "\tdb := %s.db.WithContext(ctx)\n", recv)
+	content.WriteString("\tvar total int64\n")
+	fmt.Fprintf(content, "\tif err := db.Model(&domain.%s{}).Count(&total).Error; err != nil {\n", entity)
+	content.WriteString("\t\treturn nil, 0, err\n")
+	content.WriteString("\t}\n")
+	fmt.Fprintf(content, "\t%s := []domain.%s{}\n", plural, entity)
+	fmt.Fprintf(content, "\terr := db.Order(\"id\").Limit(limit).Offset(offset).Find(&%s).Error\n", plural)
+	fmt.Fprintf(content, "\treturn %s, total, err\n", plural)
+	content.WriteString("}\n\n")
+}
+
+// writeSQLiteFindAllPaged writes FindAllPaged for the database/sql SQLite
+// repository, which stores each entity as a JSON document.
+func writeSQLiteFindAllPaged(content *strings.Builder, repoName, entity string) {
+	table := entityTableName(entity)
+	entityLower := strings.ToLower(entity)
+	plural := toPlural(entityLower)
+	fmt.Fprintf(content, "func (s *%s) FindAllPaged(ctx context.Context, limit, offset int) ([]domain.%s, int64, error) {\n", repoName, entity)
+	content.WriteString("\tvar total int64\n")
+	fmt.Fprintf(content, "\tif err := s.db.QueryRowContext(ctx, \"SELECT COUNT(*) FROM %s\").Scan(&total); err != nil {\n", table)
+	content.WriteString("\t\treturn nil, 0, fmt.Errorf(\"failed to count: %w\", err)\n\t}\n")
+	fmt.Fprintf(content, "\trows, err := s.db.QueryContext(ctx, \"SELECT data FROM %s ORDER BY id LIMIT ? OFFSET ?\", limit, offset)\n", table)
+	content.WriteString("\tif err != nil {\n\t\treturn nil, 0, fmt.Errorf(\"failed to query: %w\", err)\n\t}\n")
+	content.WriteString("\tdefer rows.Close()\n")
+	fmt.Fprintf(content, "\t%s := []domain.%s{}\n", plural, entity)
+	content.WriteString("\tfor rows.Next() {\n")
+	content.WriteString("\t\tvar data []byte\n")
+	content.WriteString("\t\tif err := rows.Scan(&data); err != nil {\n\t\t\treturn nil, 0, fmt.Errorf(\"failed to scan: %w\", err)\n\t\t}\n")
+	fmt.Fprintf(content, "\t\tvar %s domain.%s\n", entityLower, entity)
+	fmt.Fprintf(content, "\t\tif err := json.Unmarshal(data, &%s); err != nil {\n\t\t\treturn nil, 0, fmt.Errorf(\"failed to unmarshal: %%w\", err)\n\t\t}\n", entityLower)
+	fmt.Fprintf(content, "\t\t%s = append(%s, %s)\n", plural, plural, entityLower)
+	content.WriteString("\t}\n")
+	content.WriteString("\tif err := rows.Err(); err != nil {\n\t\treturn nil, 0, fmt.Errorf(\"rows error: %w\", err)\n\t}\n")
+	fmt.Fprintf(content, "\treturn %s, total, nil\n", plural)
+	content.WriteString("}\n\n")
+}
+
+// writeElasticsearchFindAllPaged writes FindAllPaged as a from/size search
+// sorted by ID. track_total_hits counts past the default 10,000 hits.
+func writeElasticsearchFindAllPaged(content *strings.Builder, repoName, entity string) {
+	plural := toPlural(strings.ToLower(entity))
+	fmt.Fprintf(content, "func (e *%s) FindAllPaged(ctx context.Context, limit, offset int) ([]domain.%s, int64, error) {\n", repoName, entity)
+	content.WriteString("\tsearchBody := map[string]interface{}{\n")
+	content.WriteString("\t\t\"query\":            map[string]interface{}{\"match_all\": map[string]interface{}{}},\n")
+	content.WriteString("\t\t\"sort\":             []interface{}{\"id\"},\n")
+	content.WriteString("\t\t\"from\":             offset,\n")
+	content.WriteString("\t\t\"size\":             limit,\n")
+	content.WriteString("\t\t\"track_total_hits\": true,\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tvar buf bytes.Buffer\n")
+	content.WriteString("\tif err := json.NewEncoder(&buf).Encode(searchBody); err != nil {\n")
+	content.WriteString("\t\treturn nil, 0, err\n\t}\n")
+	content.WriteString("\treq := esapi.SearchRequest{\n")
+	content.WriteString("\t\tIndex: []string{e.index},\n")
+	content.WriteString("\t\tBody:  &buf,\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tres, err := req.Do(ctx, e.client)\n")
+	content.WriteString("\tif err != nil {\n\t\treturn nil, 0, err\n\t}\n")
+	content.WriteString("\tdefer res.Body.Close()\n")
+	content.WriteString("\tif err := translateElasticsearchResponse(res); err != nil {\n\t\treturn nil, 0, err\n\t}\n")
+	content.WriteString("\tvar sr struct {\n")
+	content.WriteString("\t\tHits struct {\n")
+	content.WriteString("\t\t\tTotal struct {\n\t\t\t\tValue int64 `json:\"value\"`\n\t\t\t} `json:\"total\"`\n")
+	fmt.Fprintf(content, "\t\t\tHits []struct {\n\t\t\t\tSource domain.%s `json:\"_source\"`\n\t\t\t} `json:\"hits\"`\n", entity)
+	content.WriteString("\t\t} `json:\"hits\"`\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tif err := json.NewDecoder(res.Body).Decode(&sr); err != nil {\n")
+	content.WriteString("\t\treturn nil, 0, err\n\t}\n")
+	fmt.Fprintf(content, "\t%s := make([]domain.%s, 0, len(sr.Hits.Hits))\n", plural, entity)
+	fmt.Fprintf(content, "\tfor _, h := range sr.Hits.Hits {\n\t\t%s = append(%s, h.Source)\n\t}\n", plural, plural)
+	fmt.Fprintf(content, "\treturn %s, sr.Hits.Total.Value, nil\n", plural)
+	content.WriteString("}\n\n")
+}
+
+// writeDynamoDBFindAllPaged writes FindAllPaged for DynamoDB, which neither
+// orders a scan nor skips items: it scans every page, sorts the items by ID
+// and slices the page out of them.
+func writeDynamoDBFindAllPaged(content *strings.Builder, repoName, entity string) {
+	plural := toPlural(strings.ToLower(entity))
+	fmt.Fprintf(content, "func (d *%s) FindAllPaged(ctx context.Context, limit, offset int) ([]domain.%s, int64, error) {\n", repoName, entity)
+	fmt.Fprintf(content, "\t%s := []domain.%s{}\n", plural, entity)
+	content.WriteString("\tpaginator := dynamodb.NewScanPaginator(d.client, &dynamodb.ScanInput{TableName: &d.tableName})\n")
+	content.WriteString("\tfor paginator.HasMorePages() {\n")
+	content.WriteString("\t\tpage, err := paginator.NextPage(ctx)\n")
+	content.WriteString("\t\tif err != nil {\n\t\t\treturn nil, 0, fmt.Errorf(\"failed to scan: %w\", err)\n\t\t}\n")
+	fmt.Fprintf(content, "\t\tvar items []domain.%s\n", entity)
+	content.WriteString("\t\tif err := attributevalue.UnmarshalListOfMaps(page.Items, &items); err != nil {\n")
+	content.WriteString("\t\t\treturn nil, 0, fmt.Errorf(\"failed to unmarshal: %w\", err)\n\t\t}\n")
+	fmt.Fprintf(content, "\t\t%s = append(%s, items...)\n", plural, plural)
+	content.WriteString("\t}\n")
+	fmt.Fprintf(content, "\tsort.Slice(%s, func(i, j int) bool { return %s[i].ID < %s[j].ID })\n", plural, plural, plural)
+	fmt.Fprintf(content, "\ttotal := int64(len(%s))\n", plural)
+	fmt.Fprintf(content, "\tif offset >= len(%s) {\n", plural)
+	fmt.Fprintf(content, "\t\treturn []domain.%s{}, total, nil\n", entity)
+	content.WriteString("\t}\n")
+	fmt.Fprintf(content, "\t%s = %s[offset:]\n", plural, plural)
+	fmt.Fprintf(content, "\tif limit < len(%s) {\n", plural)
+	fmt.Fprintf(content, "\t\t%s = %s[:limit]\n", plural, plural)
+	content.WriteString("\t}\n")
+	fmt.Fprintf(content, "\treturn %s, total, nil\n", plural)
+	content.WriteString("}\n\n")
+}
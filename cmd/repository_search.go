@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// criteriaCondition is one optional field of an <Entity>Criteria: Name is set
+// to filter Field's column with Op.
+type criteriaCondition struct {
+	Name  string // Go field name in the criteria, e.g. PriceMin
+	Type  string // Go type, a pointer unless the zero value means "unset"
+	Field string // entity field the condition filters
+	Op    string // Filter<Op> constant of the generated repository package
+}
+
+// queryName returns the query parameter the condition binds to, e.g. price_min.
+func (c criteriaCondition) queryName() string {
+	return gormColumnName(c.Name)
+}
+
+// criteriaFields returns the fields an <Entity>Criteria can filter by:
+// strings, booleans, numbers and times other than the ID.
+func criteriaFields(fields []Field) []Field {
+	var filterable []Field
+	for _, field := range fields {
+		if field.Name == "ID" {
+			continue
+		}
+		switch {
+		case field.Type == "string", field.Type == "bool", field.Type == "time.Time", isNumericType(field.Type):
+			filterable = append(filterable, field)
+		}
+	}
+	return filterable
+}
+
+// criteriaConditions returns the criteria fields of field: equality for every
+// type, a substring match for strings and ranges for numbers and times.
+// Foreign keys only get equality.
+func criteriaConditions(field Field) []criteriaCondition {
+	eq := criteriaCondition{Name: field.Name, Type: "*" + field.Type, Field: field.Name, Op: "FilterEq"}
+	switch {
+	case field.Type == "string":
+		return []criteriaCondition{eq, {Name: field.Name + "Like", Type: "string", Field: field.Name, Op: "FilterLike"}}
+	case field.Type == "time.Time":
+		return []criteriaCondition{
+			{Name: field.Name + "From", Type: "*time.Time", Field: field.Name, Op: "FilterGte"},
+			{Name: field.Name + "To", Type: "*time.Time", Field: field.Name, Op: "FilterLte"},
+		}
+	case isNumericType(field.Type) && !strings.HasSuffix(field.Name, "ID"):
+		return []criteriaCondition{
+			eq,
+			{Name: field.Name + "Min", Type: "*" + field.Type, Field: field.Name, Op: "FilterGte"},
+			{Name: field.Name + "Max", Type: "*" + field.Type, Field: field.Name, Op: "FilterLte"},
+		}
+	}
+	return []criteriaCondition{eq}
+}
+
+// newCriteriaSearchMethod returns the Search(ctx, <Entity>Criteria) method.
+func newCriteriaSearchMethod(entity string) SearchMethod {
+	return SearchMethod{
+		MethodName: "Search",
+		ReturnType: fmt.Sprintf("([]domain.%s, error)", entity),
+		Kind:       SearchCriteria,
+		Spec:       entity + "Criteria",
+	}
+}
+
+// generateRepositoryCriteria writes <entity>_criteria.go with the
+// <Entity>Criteria of the entity's filterable fields, and the shared Filter
+// type the first time a criteria is generated.
+func generateRepositoryCriteria(dir, entity string, fields []Field, sm ...*SafetyManager) {
+	if len(criteriaFields(fields)) == 0 {
+		return
+	}
+	if _, err := os.Stat(filepath.Join(dir, "filter.go")); os.IsNotExist(err) {
+		if err := writeGoFile(filepath.Join(dir, "filter.go"), repositoryFilterSource, sm...); err != nil {
+			ui.Error(fmt.Sprintf("Error writing repository filter: %v", err))
+		}
+	}
+	filename := filepath.Join(dir, strings.ToLower(entity)+"_criteria.go")
+	if err := writeGoFile(filename, buildRepositoryCriteria(entity, fields), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing %s criteria: %v", entity, err))
+	}
+}
+
+// syncRepositoryCriteria rewrites an existing <entity>_criteria.go for the
+// entity's current fields, after goca field added or removed some.
+func syncRepositoryCriteria(entity string, fields []Field, sm ...*SafetyManager) error {
+	filename := filepath.Join(DirInternal, DirRepository, strings.ToLower(entity)+"_criteria.go")
+	if _, err := os.Stat(filename); err != nil || len(criteriaFields(fields)) == 0 {
+		return nil
+	}
+	return writeGoFileMerged(filename, buildRepositoryCriteria(entity, fields), sm...)
+}
+
+// buildRepositoryCriteria returns the source of <entity>_criteria.go: the
+// criteria struct, whose query tags the HTTP handler binds, and its Filters
+// method, which is all the repository implementations read.
+func buildRepositoryCriteria(entity string, fields []Field) string {
+	var conditions []criteriaCondition
+	for _, field := range criteriaFields(fields) {
+		conditions = append(conditions, criteriaConditions(field)...)
+	}
+	entityLower := strings.ToLower(entity)
+
+	var b strings.Builder
+	b.WriteString("package repository\n\n")
+	if fieldsUseType(criteriaFields(fields), "time.Time") {
+		b.WriteString("import \"time\"\n\n")
+	}
+	fmt.Fprintf(&b, "// %sCriteria selects the %s Search returns. Nil pointers and empty\n", entity, toPlural(entityLower))
+	fmt.Fprintf(&b, "// strings leave their condition out, so the zero value matches every %s.\n", entityLower)
+	b.WriteString("// Like fields match substrings, Min/From and Max/To bounds are inclusive.\n")
+	fmt.Fprintf(&b, "type %sCriteria struct {\n", entity)
+	for _, c := range conditions {
+		fmt.Fprintf(&b, "\t%s %s `query:%q`\n", c.Name, c.Type, c.queryName())
+	}
+	b.WriteString("}\n\n")
+
+	b.WriteString("// Filters returns the conditions set in c.\n")
+	fmt.Fprintf(&b, "func (c %sCriteria) Filters() []Filter {\n", entity)
+	b.WriteString("\tvar filters []Filter\n")
+	for _, c := range conditions {
+		check, value := fmt.Sprintf("c.%s != nil", c.Name), "*c."+c.Name
+		if !strings.HasPrefix(c.Type, "*") {
+			check, value = fmt.Sprintf("c.%s != \"\"", c.Name), "c."+c.Name
+		}
+		fmt.Fprintf(&b, "\tif %s {\n", check)
+		fmt.Fprintf(&b, "\t\tfilters = append(filters, Filter{Field: %q, Column: %q, Op: %s, Value: %s})\n",
+			c.Field, gormColumnName(c.Field), c.Op, value)
+		b.WriteString("\t}\n")
+	}
+	b.WriteString("\treturn filters\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// repositoryFilterSource is internal/repository/filter.go, shared by the
+// criteria of every entity.
+const repositoryFilterSource = `package repository
+
+// Filter operators. They are valid SQL and DynamoDB comparison operators.
+const (
+	FilterEq   = "="
+	FilterLike = "LIKE"
+	FilterGte  = ">="
+	FilterLte  = "<="
+)
+
+// Filter is one condition of a search criteria: the entity field Field,
+// stored in Column, compared with Value by Op.
+type Filter struct {
+	Field  string
+	Column string
+	Op     string
+	Value  interface{}
+}
+`
+
+// generateGormCriteriaSearch generates Search for a repository whose receiver
+// exposes a `db *gorm.DB`. Columns come from the generated criteria,
+// never from user input, so they are safe to interpolate.
+func generateGormCriteriaSearch(recv, repoName, entity string) string {
+	entityLower := strings.ToLower(entity)
+	var b strings.Builder
+	fmt.Fprintf(&b, "func (%s *%s) Search(ctx context.Context, criteria %sCriteria) ([]domain.%s, error) {\n", recv, repoName, entity, entity)
+	fmt.Fprintf(&b, "\tquery := %s.db.WithContext(ctx)\n", recv)
+	b.WriteString("\tfor _, f := range criteria.Filters() {\n")
+	b.WriteString("\t\tvalue := f.Value\n")
+	b.WriteString("\t\tif f.Op == FilterLike {\n")
+	b.WriteString("\t\t\tvalue = \"%\" + f.Value.(string) + \"%\"\n")
+	b.WriteString("\t\t}\n")
+	b.WriteString("\t\tquery = query.Where(f.Column+\" \"+f.Op+\" ?\", value)\n")
+	b.WriteString("\t}\n")
+	fmt.Fprintf(&b, "\tvar %s []domain.%s\n", toPlural(entityLower), entity)
+	fmt.Fprintf(&b, "\tresult := query.Find(&%s)\n", toPlural(entityLower))
+	fmt.Fprintf(&b, "\treturn %s, result.Error\n", toPlural(entityLower))
+	b.WriteString("}\n\n")
+	return b.String()
+}
+
+// generateMongoCriteriaSearch generates Search for a MongoDB repository, which
+// filters by the document keys of mongo<Entity>Keys. Like filters become
+// regular expressions matching the quoted value.
+func generateMongoCriteriaSearch(repoName, entity string) string {
+	entityLower := strings.ToLower(entity)
+	var b strings.Builder
+	fmt.Fprintf(&b, "func (m *%s) Search(ctx context.Context, criteria %sCriteria) ([]domain.%s, error) {\n", repoName, entity, entity)
+	b.WriteString("\tconditions := bson.A{}\n")
+	b.WriteString("\tfor _, f := range criteria.Filters() {\n")
+	fmt.Fprintf(&b, "\t\tkey, ok := mongo%sKeys[f.Field]\n", entity)
+	b.WriteString("\t\tif !ok {\n")
+	b.WriteString("\t\t\tkey = f.Column\n")
+	b.WriteString("\t\t}\n")
+	b.WriteString("\t\tswitch f.Op {\n")
+	b.WriteString("\t\tcase FilterLike:\n")
+	b.WriteString("\t\t\tconditions = append(conditions, bson.M{key: bson.M{\"$regex\": regexp.QuoteMeta(f.Value.(string))}})\n")
+	b.WriteString("\t\tcase FilterGte:\n")
+	b.WriteString("\t\t\tconditions = append(conditions, bson.M{key: bson.M{\"$gte\": f.Value}})\n")
+	b.WriteString("\t\tcase FilterLte:\n")
+	b.WriteString("\t\t\tconditions = append(conditions, bson.M{key: bson.M{\"$lte\": f.Value}})\n")
+	b.WriteString("\t\tdefault:\n")
+	b.WriteString("\t\t\tconditions = append(conditions, bson.M{key: f.Value})\n")
+	b.WriteString("\t\t}\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\tfilter := bson.M{}\n")
+	b.WriteString("\tif len(conditions) > 0 {\n")
+	b.WriteString("\t\tfilter[\"$and\"] = conditions\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\tcursor, err := m.collection.Find(ctx, filter)\n")
+	b.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+	b.WriteString("\tdefer cursor.Close(ctx)\n")
+	fmt.Fprintf(&b, "\tvar %s []domain.%s\n", toPlural(entityLower), entity)
+	fmt.Fprintf(&b, "\tif err := cursor.All(ctx, &%s); err != nil {\n\t\treturn nil, err\n\t}\n", toPlural(entityLower))
+	fmt.Fprintf(&b, "\treturn %s, nil\n", toPlural(entityLower))
+	b.WriteString("}\n\n")
+	return b.String()
+}
+
+// writeElasticsearchCriteriaSearch writes Search as a bool filter of term,
+// wildcard and range queries.
+func writeElasticsearchCriteriaSearch(b *strings.Builder, repoName, entity string) {
+	fmt.Fprintf(b, "func (e *%s) Search(ctx context.Context, criteria %sCriteria) ([]domain.%s, error) {\n", repoName, entity, entity)
+	b.WriteString("\tfilters := make([]interface{}, 0)\n")
+	b.WriteString("\tfor _, f := range criteria.Filters() {\n")
+	b.WriteString("\t\tswitch f.Op {\n")
+	b.WriteString("\t\tcase FilterLike:\n")
+	b.WriteString("\t\t\tfilters = append(filters, map[string]interface{}{\"wildcard\": map[string]interface{}{f.Column: \"*\" + f.Value.(string) + \"*\"}})\n")
+	b.WriteString("\t\tcase FilterGte:\n")
+	b.WriteString("\t\t\tfilters = append(filters, map[string]interface{}{\"range\": map[string]interface{}{f.Column: map[string]interface{}{\"gte\": f.Value}}})\n")
+	b.WriteString("\t\tcase FilterLte:\n")
+	b.WriteString("\t\t\tfilters = append(filters, map[string]interface{}{\"range\": map[string]interface{}{f.Column: map[string]interface{}{\"lte\": f.Value}}})\n")
+	b.WriteString("\t\tdefault:\n")
+	b.WriteString("\t\t\tfilters = append(filters, map[string]interface{}{\"term\": map[string]interface{}{f.Column: f.Value}})\n")
+	b.WriteString("\t\t}\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\tbody := map[string]interface{}{\n")
+	b.WriteString("\t\t\"query\": map[string]interface{}{\"bool\": map[string]interface{}{\"filter\": filters}},\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\tvar buf bytes.Buffer\n")
+	b.WriteString("\tif err := json.NewEncoder(&buf).Encode(body); err != nil {\n")
+	b.WriteString("\t\treturn nil, err\n\t}\n")
+	b.WriteString("\treq := esapi.SearchRequest{\n")
+	b.WriteString("\t\tIndex: []string{e.index},\n")
+	b.WriteString("\t\tBody:  &buf,\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\tres, err := req.Do(ctx, e.client)\n")
+	b.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+	b.WriteString("\tdefer res.Body.Close()\n")
+	b.WriteString("\tvar sr struct {\n")
+	b.WriteString("\t\tHits struct {\n")
+	fmt.Fprintf(b, "\t\t\tHits []struct {\n\t\t\t\tSource domain.%s `json:\"_source\"`\n\t\t\t} `json:\"hits\"`\n", entity)
+	b.WriteString("\t\t} `json:\"hits\"`\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\tif err := json.NewDecoder(res.Body).Decode(&sr); err != nil {\n")
+	b.WriteString("\t\treturn nil, err\n\t}\n")
+	fmt.Fprintf(b, "\tresults := make([]domain.%s, 0, len(sr.Hits.Hits))\n", entity)
+	b.WriteString("\tfor _, h := range sr.Hits.Hits {\n\t\tresults = append(results, h.Source)\n\t}\n")
+	b.WriteString("\treturn results, nil\n")
+	b.WriteString("}\n\n")
+}
+
+// writeDynamoDBCriteriaSearch writes Search as a paginated scan whose filter
+// expression holds the criteria; Like filters use contains().
+func writeDynamoDBCriteriaSearch(b *strings.Builder, repoName, entity string) {
+	fmt.Fprintf(b, "func (d *%s) Search(ctx context.Context, criteria %sCriteria) ([]domain.%s, error) {\n", repoName, entity, entity)
+	b.WriteString("\tinput := &dynamodb.ScanInput{TableName: &d.tableName}\n")
+	b.WriteString("\tif filters := criteria.Filters(); len(filters) > 0 {\n")
+	b.WriteString("\t\tnames := make(map[string]string, len(filters))\n")
+	b.WriteString("\t\tvalues := make(map[string]types.AttributeValue, len(filters))\n")
+	b.WriteString("\t\tconditions := make([]string, 0, len(filters))\n")
+	b.WriteString("\t\tfor i, f := range filters {\n")
+	b.WriteString("\t\t\tname, placeholder := \"#a\"+strconv.Itoa(i), \":v\"+strconv.Itoa(i)\n")
+	b.WriteString("\t\t\tav, err := attributevalue.Marshal(f.Value)\n")
+	b.WriteString("\t\t\tif err != nil {\n\t\t\t\treturn nil, fmt.Errorf(\"failed to marshal: %w\", err)\n\t\t\t}\n")
+	b.WriteString("\t\t\tnames[name] = f.Field\n")
+	b.WriteString("\t\t\tvalues[placeholder] = av\n")
+	b.WriteString("\t\t\tif f.Op == FilterLike {\n")
+	b.WriteString("\t\t\t\tconditions = append(conditions, \"contains(\"+name+\", \"+placeholder+\")\")\n")
+	b.WriteString("\t\t\t} else {\n")
+	b.WriteString("\t\t\t\tconditions = append(conditions, name+\" \"+f.Op+\" \"+placeholder)\n")
+	b.WriteString("\t\t\t}\n")
+	b.WriteString("\t\t}\n")
+	b.WriteString("\t\texpression := strings.Join(conditions, \" AND \")\n")
+	b.WriteString("\t\tinput.FilterExpression = &expression\n")
+	b.WriteString("\t\tinput.ExpressionAttributeNames = names\n")
+	b.WriteString("\t\tinput.ExpressionAttributeValues = values\n")
+	b.WriteString("\t}\n")
+	fmt.Fprintf(b, "\tvar items []domain.%s\n", entity)
+	b.WriteString("\tpaginator := dynamodb.NewScanPaginator(d.client, input)\n")
+	b.WriteString("\tfor paginator.HasMorePages() {\n")
+	b.WriteString("\t\tpage, err := paginator.NextPage(ctx)\n")
+	b.WriteString("\t\tif err != nil {\n\t\t\treturn nil, fmt.Errorf(\"failed to scan: %w\", err)\n\t\t}\n")
+	fmt.Fprintf(b, "\t\tvar pageItems []domain.%s\n", entity)
+	b.WriteString("\t\tif err := attributevalue.UnmarshalListOfMaps(page.Items, &pageItems); err != nil {\n")
+	b.WriteString("\t\t\treturn nil, fmt.Errorf(\"failed to unmarshal: %w\", err)\n\t\t}\n")
+	b.WriteString("\t\titems = append(items, pageItems...)\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\treturn items, nil\n")
+	b.WriteString("}\n\n")
+}
+
+// appendCriteriaSearch appends Search to an already-generated Elasticsearch
+// or DynamoDB repository file when the entity has filterable fields.
+func appendCriteriaSearch(dir, file, repoName, entity string, fields []Field, sm ...*SafetyManager) {
+	if len(criteriaFields(fields)) == 0 {
+		return
+	}
+	var b strings.Builder
+	if strings.HasPrefix(repoName, "dynamodb") {
+		writeDynamoDBCriteriaSearch(&b, repoName, entity)
+	} else {
+		writeElasticsearchCriteriaSearch(&b, repoName, entity)
+	}
+	appendToRepoFile(filepath.Join(dir, file), b.String(), nil, sm...)
+}
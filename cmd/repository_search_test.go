@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildRepositoryCriteria(t *testing.T) {
+	src := buildRepositoryCriteria("Product", parseFields("name:string,price:float64,released:time.Time,category_id:uint,tags:[]string"))
+	_, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	require.NoError(t, err, src)
+
+	assert.Contains(t, src, `import "time"`)
+	assert.Contains(t, src, "NameLike string `query:\"name_like\"`")
+	assert.Contains(t, src, "PriceMin *float64 `query:\"price_min\"`")
+	assert.Contains(t, src, "ReleasedTo *time.Time `query:\"released_to\"`")
+	assert.Contains(t, src, `filters = append(filters, Filter{Field: "CategoryID", Column: "category_id", Op: FilterEq, Value: *c.CategoryID})`)
+	assert.Contains(t, src, `if c.NameLike != "" {`)
+	assert.NotContains(t, src, "CategoryIDMin", "foreign keys only filter by equality")
+	assert.NotContains(t, src, "Tags")
+	assert.NotContains(t, src, "\tID ")
+}
+
+func TestRepositorySearch_Backends(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	dir := t.TempDir()
+	fields := parseFields("name:string,price:float64")
+
+	generateRepositoryInterfaceWithFields(dir, "Product", fields, false)
+	assert.Contains(t, readParsedGo(t, filepath.Join(dir, "interfaces.go")), "Search(ctx context.Context, criteria ProductCriteria) ([]domain.Product, error)")
+	assert.Contains(t, readParsedGo(t, filepath.Join(dir, "filter.go")), "type Filter struct")
+	assert.Contains(t, readParsedGo(t, filepath.Join(dir, "product_criteria.go")), "func (c ProductCriteria) Filters() []Filter")
+
+	generateGormRepositoryWithFields(dir, "Product", "Postgres", fields, false, false)
+	gorm := readParsedGo(t, filepath.Join(dir, "postgres_product_repository.go"))
+	assert.Contains(t, gorm, `query = query.Where(f.Column+" "+f.Op+" ?", value)`)
+
+	generateMongoRepositoryWithFields(dir, "Product", fields, false, false)
+	mongo := readParsedGo(t, filepath.Join(dir, "mongo_product_repository.go"))
+	assert.Contains(t, mongo, `"regexp"`)
+	assert.Contains(t, mongo, `bson.M{key: bson.M{"$regex": regexp.QuoteMeta(f.Value.(string))}}`)
+
+	generateElasticsearchRepositoryWithFields(dir, "Product", fields, false, false)
+	es := readParsedGo(t, filepath.Join(dir, "elasticsearch_product_repository.go"))
+	assert.Contains(t, es, `map[string]interface{}{"wildcard": map[string]interface{}{f.Column: "*" + f.Value.(string) + "*"}}`)
+
+	generateDynamoDBRepositoryWithFields(dir, "Product", fields, false, false)
+	dynamo := readParsedGo(t, filepath.Join(dir, "dynamodb_product_repository.go"))
+	assert.Contains(t, dynamo, `conditions = append(conditions, "contains("+name+", "+placeholder+")")`)
+
+	mock := generateRepositoryMock("Product", generateRepositoryMethods(fields, "Product"))
+	assert.Contains(t, mock, "func (m *MockProductRepository) Search(ctx context.Context, criteria repository.ProductCriteria) ([]domain.Product, error)")
+}
+
+func TestRepositorySearch_NoFilterableFields(t *testing.T) {
+	for _, m := range generateRepositoryMethods(parseFields("tags:[]string"), "Product") {
+		assert.NotEqual(t, SearchCriteria, m.Kind)
+	}
+}
+
+func TestInterfaceFinder_Search(t *testing.T) {
+	src := `package repository
+
+type ProductRepository interface {
+	Search(ctx context.Context, criteria ProductCriteria) ([]domain.Product, error)
+}
+`
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	require.NoError(t, err)
+	method, ok := interfaceFinder(findInterfaceType(file, "ProductRepository").Methods.List[0], "Product")
+	require.True(t, ok)
+	assert.Equal(t, newCriteriaSearchMethod("Product"), method)
+}
+
+func TestSyncRepositoryCriteria(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+
+	require.NoError(t, syncRepositoryCriteria("Product", parseFields("name:string")))
+	_, err := os.Stat(filepath.Join(DirInternal, DirRepository, "product_criteria.go"))
+	assert.True(t, os.IsNotExist(err), "only existing criteria are synced")
+
+	dir := filepath.Join(DirInternal, DirRepository)
+	generateRepositoryCriteria(dir, "Product", parseFields("name:string,price:float64"))
+	require.NoError(t, syncRepositoryCriteria("Product", parseFields("name:string,weight:float64")))
+	criteria := readParsedGo(t, filepath.Join(dir, "product_criteria.go"))
+	assert.Contains(t, criteria, "WeightMax *float64")
+	assert.NotContains(t, criteria, "Price")
+}
+
+func TestSearchOperation(t *testing.T) {
+	defer ensureTestUI(t)()
+	setupCLIProject(t, strings.Replace(fullProductUseCase, "}\n", "\tSearchProducts(input SearchProductInput) ([]domain.Product, error)\n}\n", 1))
+
+	assert.Equal(t, []string{OpCreate, OpRead, OpUpdate, OpDelete, OpList, OpSearch}, useCaseOperations("Product"))
+
+	dir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	generateHTTPHandlerFile(dir, "Product", false, false, "lowercase")
+	handler := readParsedGo(t, filepath.Join(dir, "product_handler.go"))
+	assert.Contains(t, handler, "if err := bindQuery(r.URL.Query(), &input); err != nil {")
+	assert.Contains(t, handler, "products, err := p.usecase.SearchProducts(input)")
+	assert.Contains(t, readParsedGo(t, filepath.Join(dir, "query.go")), "func bindQuery(values url.Values, dst interface{}) error")
+
+	var routes strings.Builder
+	writeRouteSetupFunc(&routes, "Product", defaultHTTPRoutes("Product"), false, false)
+	search := strings.Index(routes.String(), `router.HandleFunc("/products/search", handler.SearchProducts).Methods("GET")`)
+	require.NotEqual(t, -1, search)
+	assert.Less(t, search, strings.Index(routes.String(), `"/products/{id}"`), "/search must be registered before /{id}")
+
+	usecaseDir := filepath.Join(DirInternal, DirUseCase)
+	generateDTOFileWithFields(usecaseDir, "Product", []string{OpCreate}, false, "name:string", DTOStyleNested)
+	generateDTOFileWithFields(usecaseDir, "Product", []string{OpCreate, OpSearch}, false, "name:string", DTOStyleNested)
+	dto := readParsedGo(t, filepath.Join(usecaseDir, "dto.go"))
+	assert.Contains(t, dto, "type SearchProductInput = repository.ProductCriteria")
+	assert.Contains(t, dto, `"testproject/internal/repository"`)
+	assert.Equal(t, 1, strings.Count(dto, "type CreateProductInput struct"))
+
+	generateUseCaseServiceWithFields(usecaseDir, "ProductUseCase", "Product", []string{OpSearch}, false, false, "", DTOStyleNested)
+	service := readParsedGo(t, filepath.Join(usecaseDir, "product_service.go"))
+	assert.Contains(t, service, "repo repository.ProductRepository")
+	assert.Contains(t, service, "return p.repo.Search(context.Background(), input)")
+}
@@ -0,0 +1,648 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gobreakerVersion and prometheusVersion pin the modules pkg/resilience
+// imports.
+const (
+	gobreakerVersion  = "v2.4.0"
+	prometheusVersion = "v1.20.5"
+)
+
+// resilienceSettings are the settings of features.resilience with the
+// defaults filled in.
+type resilienceSettings struct {
+	maxAttempts      int
+	initialBackoff   time.Duration
+	maxBackoff       time.Duration
+	retryWrites      bool
+	failureThreshold uint32
+	openTimeout      time.Duration
+	halfOpenRequests uint32
+}
+
+// resolveResilienceSettings fills in the defaults of cfg. Durations that do
+// not parse keep their default; goca config validate reports them.
+func resolveResilienceSettings(cfg ResilienceConfig) resilienceSettings {
+	duration := func(value string, fallback time.Duration) time.Duration {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			return d
+		}
+		return fallback
+	}
+	s := resilienceSettings{
+		maxAttempts:      cfg.Retry.MaxAttempts,
+		initialBackoff:   duration(cfg.Retry.InitialBackoff, 100*time.Millisecond),
+		maxBackoff:       duration(cfg.Retry.MaxBackoff, 2*time.Second),
+		retryWrites:      cfg.Retry.Writes,
+		failureThreshold: cfg.Breaker.FailureThreshold,
+		openTimeout:      duration(cfg.Breaker.OpenTimeout, 30*time.Second),
+		halfOpenRequests: cfg.Breaker.HalfOpenRequests,
+	}
+	if s.maxAttempts <= 0 {
+		s.maxAttempts = 3
+	}
+	if s.failureThreshold == 0 {
+		s.failureThreshold = 5
+	}
+	if s.halfOpenRequests == 0 {
+		s.halfOpenRequests = 1
+	}
+	return s
+}
+
+// durationLiteral renders d as a Go expression, e.g. 250 * time.Millisecond.
+func durationLiteral(d time.Duration) string {
+	switch {
+	case d%time.Second == 0:
+		return fmt.Sprintf("%d * time.Second", d/time.Second)
+	case d%time.Millisecond == 0:
+		return fmt.Sprintf("%d * time.Millisecond", d/time.Millisecond)
+	}
+	return fmt.Sprintf("time.Duration(%d)", int64(d))
+}
+
+// createResiliencePackage writes pkg/resilience/resilience.go: the retry and
+// circuit breaker policy of the repository decorators, whose defaults are the
+// settings of features.resilience. Like the other shared packages, it is only
+// rewritten with --force.
+func createResiliencePackage(cfg ResilienceConfig, sm ...*SafetyManager) {
+	filename := filepath.Join(DirPkg, "resilience", "resilience.go")
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+	if _, err := os.Stat(filename); err == nil && !force {
+		return
+	}
+
+	s := resolveResilienceSettings(cfg)
+	var b strings.Builder
+	b.WriteString(resiliencePackageHeader)
+	b.WriteString("// DefaultSettings returns the settings of features.resilience in .goca.yaml\n")
+	b.WriteString("// when the package was generated.\n")
+	b.WriteString("func DefaultSettings() Settings {\n")
+	b.WriteString("\treturn Settings{\n")
+	fmt.Fprintf(&b, "\t\tMaxAttempts:      %d,\n", s.maxAttempts)
+	fmt.Fprintf(&b, "\t\tInitialBackoff:   %s,\n", durationLiteral(s.initialBackoff))
+	fmt.Fprintf(&b, "\t\tMaxBackoff:       %s,\n", durationLiteral(s.maxBackoff))
+	fmt.Fprintf(&b, "\t\tRetryWrites:      %t,\n", s.retryWrites)
+	fmt.Fprintf(&b, "\t\tFailureThreshold: %d,\n", s.failureThreshold)
+	fmt.Fprintf(&b, "\t\tOpenTimeout:      %s,\n", durationLiteral(s.openTimeout))
+	fmt.Fprintf(&b, "\t\tHalfOpenRequests: %d,\n", s.halfOpenRequests)
+	b.WriteString("\t}\n")
+	b.WriteString("}\n\n")
+	b.WriteString(resiliencePackageTemplate)
+
+	if err := writeGoFile(filename, b.String(), sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Error writing resilience package: %v", err))
+	}
+}
+
+// generateResilienceDecorator produces
+// internal/repository/resilient_<entity>_repository.go, a decorator
+// implementing every method of <Entity>Repository, including those of the
+// interfaces it embeds, through the policy of pkg/resilience.
+func generateResilienceDecorator(entity string, sm ...*SafetyManager) {
+	defer profileStep("resilience", entity)()
+	repoDir := filepath.Join(DirInternal, DirRepository)
+	path := findTypeFile(repoDir, entity+"Repository")
+	if path == "" {
+		ui.Warning(fmt.Sprintf("No %sRepository interface found; skipping the resilience decorator", entity))
+		return
+	}
+	unlock := lockSharedFile(path)
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	unlock()
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not parse %s: %v", path, err))
+		return
+	}
+
+	importPath := getImportPath(getModuleName())
+	typeName := "Resilient" + entity + "Repository"
+	iface := entity + "Repository"
+
+	// Every import the methods may need, grouped the way goimports does;
+	// syncImports drops the unused ones.
+	imports := []string{"context", importPath + "/internal/domain", importPath + "/pkg/resilience"}
+	for _, spec := range file.Imports {
+		if p, err := strconv.Unquote(spec.Path.Value); err == nil && !slices.Contains(imports, p) {
+			imports = append(imports, p)
+		}
+	}
+	var std, others strings.Builder
+	for _, p := range imports {
+		if strings.HasPrefix(p, importPath+"/") || strings.Contains(strings.Split(p, "/")[0], ".") {
+			fmt.Fprintf(&others, "\t%q\n", p)
+		} else {
+			fmt.Fprintf(&std, "\t%q\n", p)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("package repository\n\n")
+	b.WriteString("import (\n" + std.String() + "\n" + others.String() + ")\n\n")
+	fmt.Fprintf(&b, "// %s is a retry and circuit breaker decorator around\n", typeName)
+	fmt.Fprintf(&b, "// %s. Reads failing with a transient error are retried with\n", iface)
+	b.WriteString("// backoff, writes only when RetryWrites is set, and every call goes through\n")
+	b.WriteString("// the breaker. Methods taking a transaction are passed through.\n")
+	fmt.Fprintf(&b, "type %s struct {\n", typeName)
+	fmt.Fprintf(&b, "\tinner  %s\n", iface)
+	b.WriteString("\tpolicy *resilience.Policy\n")
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// New%s wraps inner with the policy of the\n", typeName)
+	fmt.Fprintf(&b, "// %q breaker, configured by resilience.SettingsFromEnv.\n", "repository."+strings.ToLower(entity))
+	fmt.Fprintf(&b, "func New%s(inner %s) *%s {\n", typeName, iface, typeName)
+	fmt.Fprintf(&b, "\treturn &%s{\n", typeName)
+	b.WriteString("\t\tinner:  inner,\n")
+	fmt.Fprintf(&b, "\t\tpolicy: resilience.New(%q, resilience.SettingsFromEnv()),\n", "repository."+strings.ToLower(entity))
+	b.WriteString("\t}\n")
+	b.WriteString("}\n\n")
+
+	for _, m := range interfaceMethods(file, iface) {
+		writeResilientMethod(&b, typeName, m.Names[0].Name, m.Type.(*ast.FuncType))
+	}
+
+	content, err := syncImports([]byte(b.String()), imports...)
+	if err != nil {
+		ui.Error(fmt.Sprintf("Error generating resilience decorator: %v", err))
+		return
+	}
+	if err := writeGoFile(filepath.Join(repoDir, "resilient_"+strings.ToLower(entity)+"_repository.go"), string(content), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing resilience decorator: %v", err))
+	}
+}
+
+// interfaceMethods returns the methods of the interface name in file,
+// followed by those of the interfaces of file it embeds.
+func interfaceMethods(file *ast.File, name string) []*ast.Field {
+	var methods []*ast.Field
+	seen := make(map[string]bool)
+	var collect func(name string)
+	collect = func(name string) {
+		it := findInterfaceType(file, name)
+		if it == nil || seen[name] {
+			return
+		}
+		seen[name] = true
+		for _, m := range it.Methods.List {
+			if _, ok := m.Type.(*ast.FuncType); ok && len(m.Names) == 1 {
+				if !seen[m.Names[0].Name+"()"] {
+					seen[m.Names[0].Name+"()"] = true
+					methods = append(methods, m)
+				}
+			} else if id, ok := m.Type.(*ast.Ident); ok {
+				collect(id.Name)
+			}
+		}
+	}
+	collect(name)
+	return methods
+}
+
+// writeResilientMethod writes one method of the resilience decorator. Methods
+// returning an error, or a value and an error, run through the policy: with
+// the context they take, if any, and as a read when their name says so.
+// Methods taking a *gorm.DB transaction and the others delegate to inner.
+func writeResilientMethod(b *strings.Builder, typeName, name string, ft *ast.FuncType) {
+	var params, args []string
+	ctx, tx := "", false
+	for i, field := range ft.Params.List {
+		typ := types.ExprString(field.Type)
+		names := make([]string, 0, len(field.Names))
+		for _, n := range field.Names {
+			names = append(names, n.Name)
+		}
+		if len(names) == 0 || names[0] == "_" {
+			names = []string{fmt.Sprintf("arg%d", i)}
+		}
+		params = append(params, strings.Join(names, ", ")+" "+typ)
+		for _, n := range names {
+			if _, variadic := field.Type.(*ast.Ellipsis); variadic {
+				n += "..."
+			}
+			args = append(args, n)
+		}
+		switch {
+		case typ == "context.Context" && ctx == "":
+			ctx = names[0]
+		case typ == "*gorm.DB":
+			tx = true
+		}
+	}
+	var results []string
+	if ft.Results != nil {
+		for _, field := range ft.Results.List {
+			for range max(len(field.Names), 1) {
+				results = append(results, types.ExprString(field.Type))
+			}
+		}
+	}
+
+	fmt.Fprintf(b, "func (r *%s) %s(%s)", typeName, name, strings.Join(params, ", "))
+	switch len(results) {
+	case 0:
+	case 1:
+		b.WriteString(" " + results[0])
+	default:
+		b.WriteString(" (" + strings.Join(results, ", ") + ")")
+	}
+	b.WriteString(" {\n")
+
+	call := fmt.Sprintf("r.inner.%s(%s)", name, strings.Join(args, ", "))
+	wrapped := !tx && len(results) > 0 && len(results) <= 2 && results[len(results)-1] == "error"
+	if ctx == "" {
+		ctx = "context.Background()"
+	}
+	kind := "resilience.Write"
+	if isRepositoryRead(name) {
+		kind = "resilience.Read"
+	}
+	switch {
+	case !wrapped && len(results) == 0:
+		fmt.Fprintf(b, "\t%s\n", call)
+	case !wrapped:
+		fmt.Fprintf(b, "\treturn %s\n", call)
+	case len(results) == 1:
+		fmt.Fprintf(b, "\treturn resilience.Run(%s, r.policy, %q, %s, func() error {\n", ctx, name, kind)
+		fmt.Fprintf(b, "\t\treturn %s\n", call)
+		b.WriteString("\t})\n")
+	default:
+		fmt.Fprintf(b, "\treturn resilience.Do(%s, r.policy, %q, %s, func() (%s, error) {\n", ctx, name, kind, results[0])
+		fmt.Fprintf(b, "\t\treturn %s\n", call)
+		b.WriteString("\t})\n")
+	}
+	b.WriteString("}\n\n")
+}
+
+// writeResilientFinder writes a finder added by goca field to the resilience
+// decorator of the entity.
+func writeResilientFinder(b *strings.Builder, entity string, m SearchMethod) {
+	src := fmt.Sprintf("package p\ntype i interface {\n%s\n}\n", m.generateSearchMethodSignature())
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	if err != nil {
+		return
+	}
+	if it := findInterfaceType(file, "i"); it != nil && len(it.Methods.List) == 1 {
+		writeResilientMethod(b, "Resilient"+entity+"Repository", m.MethodName, it.Methods.List[0].Type.(*ast.FuncType))
+	}
+}
+
+// isRepositoryRead reports whether the repository method name only reads,
+// so retrying it cannot apply a change twice.
+func isRepositoryRead(name string) bool {
+	for _, prefix := range []string{"Find", "Get", "Exists", "Count", "Search", "Sum", "Max", "Min", "Avg", "List"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasResilienceDecorator reports whether a resilience decorator
+// (resilient_<entity>_repository.go) was generated for the entity.
+func hasResilienceDecorator(feature string) bool {
+	path := filepath.Join(DirInternal, DirRepository, "resilient_"+strings.ToLower(feature)+"_repository.go")
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return !info.IsDir()
+}
+
+// resilientRepositoryExpr wraps the repository built by repo in the
+// resilience decorator of the feature when one was generated (goca feature
+// --resilience). A cache decorator goes around it, so cache hits skip it.
+func resilientRepositoryExpr(feature, repo string) string {
+	if hasResilienceDecorator(feature) {
+		return fmt.Sprintf("repository.NewResilient%sRepository(%s)", feature, repo)
+	}
+	return repo
+}
+
+// resiliencePackageHeader is the start of the generated
+// pkg/resilience/resilience.go, up to DefaultSettings.
+const resiliencePackageHeader = `// Package resilience retries transient errors of a dependency with
+// exponential backoff and stops calling it through a circuit breaker while it
+// keeps failing. State changes, retries and rejected calls are exported as
+// Prometheus metrics and reported to the listeners of OnEvent.
+package resilience
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sony/gobreaker/v2"
+)
+
+// Settings tunes the retries and the circuit breaker of a Policy.
+type Settings struct {
+	// MaxAttempts is the number of calls of a retried operation, the first
+	// included; 1 disables retries.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry. It doubles with
+	// every retry up to MaxBackoff, and a random half of it is jitter.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// RetryWrites also retries writes, which apply twice when the failed
+	// call reached the database.
+	RetryWrites bool
+	// FailureThreshold consecutive transient failures open the breaker. It
+	// rejects calls for OpenTimeout, then lets HalfOpenRequests through to
+	// decide whether to close again.
+	FailureThreshold uint32
+	OpenTimeout      time.Duration
+	HalfOpenRequests uint32
+	// Transient reports whether an error is retried and counts as a failure
+	// of the breaker; IsTransient by default. Other errors, such as a record
+	// that does not exist, are returned as they are.
+	Transient func(error) bool
+}
+
+`
+
+// resiliencePackageTemplate is the rest of the generated
+// pkg/resilience/resilience.go.
+const resiliencePackageTemplate = `// SettingsFromEnv returns DefaultSettings overridden by the RESILIENCE_*
+// environment variables: RESILIENCE_MAX_ATTEMPTS, RESILIENCE_INITIAL_BACKOFF,
+// RESILIENCE_MAX_BACKOFF, RESILIENCE_RETRY_WRITES,
+// RESILIENCE_FAILURE_THRESHOLD and RESILIENCE_OPEN_TIMEOUT.
+func SettingsFromEnv() Settings {
+	s := DefaultSettings()
+	if v, err := strconv.Atoi(os.Getenv("RESILIENCE_MAX_ATTEMPTS")); err == nil && v > 0 {
+		s.MaxAttempts = v
+	}
+	s.InitialBackoff = envDuration("RESILIENCE_INITIAL_BACKOFF", s.InitialBackoff)
+	s.MaxBackoff = envDuration("RESILIENCE_MAX_BACKOFF", s.MaxBackoff)
+	if v, err := strconv.ParseBool(os.Getenv("RESILIENCE_RETRY_WRITES")); err == nil {
+		s.RetryWrites = v
+	}
+	if v, err := strconv.ParseUint(os.Getenv("RESILIENCE_FAILURE_THRESHOLD"), 10, 32); err == nil && v > 0 {
+		s.FailureThreshold = uint32(v)
+	}
+	s.OpenTimeout = envDuration("RESILIENCE_OPEN_TIMEOUT", s.OpenTimeout)
+	return s
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
+
+// Kind tells whether an operation may be retried.
+type Kind int
+
+const (
+	// Read operations are retried on transient errors.
+	Read Kind = iota
+	// Write operations are retried only with Settings.RetryWrites.
+	Write
+)
+
+// EventKind names what an Event reports.
+type EventKind string
+
+const (
+	EventStateChange EventKind = "state_change"
+	EventRetry       EventKind = "retry"
+	EventRejected    EventKind = "rejected"
+)
+
+// Event reports a state change of a breaker, a retried call or a call the
+// breaker rejected.
+type Event struct {
+	Breaker   string
+	Kind      EventKind
+	Operation string          // retry and rejected events
+	Attempt   int             // retry events: the attempt that failed
+	From, To  gobreaker.State // state change events
+	Err       error
+}
+
+var (
+	listenersMu sync.RWMutex
+	listeners   []func(Event)
+)
+
+// OnEvent registers fn to be called with every event of every Policy, e.g.
+// to alert when a breaker opens. fn is called synchronously and must not
+// block.
+func OnEvent(fn func(Event)) {
+	listenersMu.Lock()
+	defer listenersMu.Unlock()
+	listeners = append(listeners, fn)
+}
+
+func emit(e Event) {
+	listenersMu.RLock()
+	defer listenersMu.RUnlock()
+	for _, fn := range listeners {
+		fn(e)
+	}
+}
+
+var (
+	breakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "resilience_breaker_state",
+		Help: "State of the circuit breaker: 0 closed, 1 half-open, 2 open.",
+	}, []string{"breaker"})
+
+	breakerTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "resilience_breaker_transitions_total",
+		Help: "Circuit breaker state changes by breaker and new state.",
+	}, []string{"breaker", "state"})
+
+	retries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "resilience_retries_total",
+		Help: "Calls retried after a transient error, by breaker and operation.",
+	}, []string{"breaker", "operation"})
+
+	rejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "resilience_rejected_total",
+		Help: "Calls rejected by an open circuit breaker, by breaker and operation.",
+	}, []string{"breaker", "operation"})
+)
+
+// Policy retries the transient errors of one dependency and guards it with a
+// circuit breaker.
+type Policy struct {
+	name     string
+	settings Settings
+	breaker  *gobreaker.CircuitBreaker[any]
+}
+
+// New returns the Policy of the dependency name, which labels its metrics
+// and events.
+func New(name string, s Settings) *Policy {
+	if s.MaxAttempts < 1 {
+		s.MaxAttempts = 1
+	}
+	if s.FailureThreshold == 0 {
+		s.FailureThreshold = 5
+	}
+	if s.Transient == nil {
+		s.Transient = IsTransient
+	}
+	p := &Policy{name: name, settings: s}
+	p.breaker = gobreaker.NewCircuitBreaker[any](gobreaker.Settings{
+		Name:        name,
+		MaxRequests: s.HalfOpenRequests,
+		Timeout:     s.OpenTimeout,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			return counts.ConsecutiveFailures >= s.FailureThreshold
+		},
+		// Only transient errors tell that the dependency is failing; a
+		// caller giving up says nothing about it.
+		IsSuccessful: func(err error) bool { return err == nil || !s.Transient(err) },
+		IsExcluded:   func(err error) bool { return errors.Is(err, context.Canceled) },
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			breakerState.WithLabelValues(name).Set(float64(to))
+			breakerTransitions.WithLabelValues(name, to.String()).Inc()
+			log.Printf("resilience: circuit breaker %s %s -> %s", name, from, to)
+			emit(Event{Breaker: name, Kind: EventStateChange, From: from, To: to})
+		},
+	})
+	breakerState.WithLabelValues(name).Set(float64(gobreaker.StateClosed))
+	return p
+}
+
+// State returns the current state of the breaker of p.
+func (p *Policy) State() gobreaker.State {
+	return p.breaker.State()
+}
+
+// Do calls fn through p. A transient error of a Read, or of a Write with
+// RetryWrites, is retried up to MaxAttempts times, waiting with exponential
+// backoff unless ctx is done. While the breaker is open fn is not called and
+// the error wraps gobreaker.ErrOpenState.
+func Do[T any](ctx context.Context, p *Policy, operation string, kind Kind, fn func() (T, error)) (T, error) {
+	attempts := p.settings.MaxAttempts
+	if kind == Write && !p.settings.RetryWrites {
+		attempts = 1
+	}
+	backoff := p.settings.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		var result T
+		_, err := p.breaker.Execute(func() (any, error) {
+			var err error
+			result, err = fn()
+			return nil, err
+		})
+		if err == nil {
+			return result, nil
+		}
+		if errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests) {
+			rejections.WithLabelValues(p.name, operation).Inc()
+			emit(Event{Breaker: p.name, Kind: EventRejected, Operation: operation, Err: err})
+			return result, fmt.Errorf("%s %s: %w", p.name, operation, err)
+		}
+		if attempt >= attempts || !p.settings.Transient(err) {
+			return result, err
+		}
+
+		retries.WithLabelValues(p.name, operation).Inc()
+		emit(Event{Breaker: p.name, Kind: EventRetry, Operation: operation, Attempt: attempt, Err: err})
+		if !sleep(ctx, jitter(backoff)) {
+			return result, err
+		}
+		if backoff *= 2; backoff > p.settings.MaxBackoff {
+			backoff = p.settings.MaxBackoff
+		}
+	}
+}
+
+// Run is Do for operations that only return an error.
+func Run(ctx context.Context, p *Policy, operation string, kind Kind, fn func() error) error {
+	_, err := Do(ctx, p, operation, kind, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
+}
+
+// jitter returns a random duration between d/2 and d, so that callers
+// failing together do not retry together.
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)))
+}
+
+// sleep waits for d and reports whether ctx is still live.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// transientMessages match driver errors that are not typed, such as
+// PostgreSQL serialization failures and MySQL deadlocks.
+var transientMessages = []string{
+	"connection refused",
+	"connection reset",
+	"broken pipe",
+	"bad connection",
+	"i/o timeout",
+	"server closed",
+	"too many connections",
+	"deadlock",
+	"could not serialize access",
+}
+
+// IsTransient reports whether err is likely to go away on its own: a timeout,
+// a dropped or refused connection, or the database asking to retry.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range transientMessages {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+`
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateResilienceDecorator(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	sm := NewSafetyManager(false, true, false)
+	generateRepository("Product", "postgres", true, false, false, true, "Name:string,Price:float64", sm)
+	generateResilienceDecorator("Product", sm)
+
+	src := readParsedGo(t, filepath.Join(DirInternal, DirRepository, "resilient_product_repository.go"))
+	assert.Contains(t, src, "func NewResilientProductRepository(inner ProductRepository) *ResilientProductRepository")
+	assert.Contains(t, src, `resilience.New("repository.product", resilience.SettingsFromEnv())`)
+	assert.Contains(t, src, `resilience.Do(context.Background(), r.policy, "FindByID", resilience.Read, func() (*domain.Product, error) {`)
+	assert.Contains(t, src, `resilience.Run(context.Background(), r.policy, "Save", resilience.Write, func() error {`)
+	assert.Contains(t, src, `resilience.Do(ctx, r.policy, "Count", resilience.Read, func() (int64, error) {`)
+	assert.Contains(t, src, "func (r *ResilientProductRepository) SaveWithTx(tx *gorm.DB, product *domain.Product) error {\n\treturn r.inner.SaveWithTx(tx, product)\n}")
+	assert.Contains(t, src, "import (\n\t\"context\"\n\n\t\"gorm.io/gorm\"\n\t\"testproject/internal/domain\"\n\t\"testproject/pkg/resilience\"\n)")
+
+	assert.True(t, hasResilienceDecorator("Product"))
+	assert.Equal(t, "repository.NewResilientProductRepository(repo)", resilientRepositoryExpr("Product", "repo"))
+	assert.Equal(t, "repo", resilientRepositoryExpr("Order", "repo"))
+}
+
+func TestWriteResilientFinder(t *testing.T) {
+	var b strings.Builder
+	writeResilientFinder(&b, "Product", SearchMethod{MethodName: "FindByCode", FieldName: "Code", FieldType: "string", ReturnType: "(*domain.Product, error)"})
+	assert.Contains(t, b.String(), "func (r *ResilientProductRepository) FindByCode(code string) (*domain.Product, error) {")
+	assert.Contains(t, b.String(), `"FindByCode", resilience.Read,`)
+}
+
+func TestGenerateResilienceDecorator_NoInterface(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	generateResilienceDecorator("Product", NewSafetyManager(false, true, false))
+	assert.False(t, hasResilienceDecorator("Product"))
+}
+
+func TestCreateResiliencePackage(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	cfg := ResilienceConfig{
+		Retry:   RetryConfig{MaxAttempts: 4, InitialBackoff: "250ms", Writes: true},
+		Breaker: CircuitBreakerConfig{OpenTimeout: "1m"},
+	}
+	createResiliencePackage(cfg, NewSafetyManager(false, false, false))
+
+	path := filepath.Join(DirPkg, "resilience", "resilience.go")
+	src := readParsedGo(t, path)
+	assert.Contains(t, src, "MaxAttempts:      4,")
+	assert.Contains(t, src, "InitialBackoff:   250 * time.Millisecond,")
+	assert.Contains(t, src, "MaxBackoff:       2 * time.Second,")
+	assert.Contains(t, src, "RetryWrites:      true,")
+	assert.Contains(t, src, "FailureThreshold: 5,")
+	assert.Contains(t, src, "OpenTimeout:      60 * time.Second,")
+	assert.Contains(t, src, "gobreaker.NewCircuitBreaker[any]")
+
+	// Without --force the package is left as it is.
+	createResiliencePackage(ResilienceConfig{}, NewSafetyManager(false, false, false))
+	assert.Contains(t, readParsedGo(t, path), "MaxAttempts:      4,")
+}
+
+func TestResolveResilienceSettings(t *testing.T) {
+	s := resolveResilienceSettings(ResilienceConfig{Retry: RetryConfig{MaxBackoff: "soon"}})
+	assert.Equal(t, resilienceSettings{
+		maxAttempts:      3,
+		initialBackoff:   100 * time.Millisecond,
+		maxBackoff:       2 * time.Second,
+		failureThreshold: 5,
+		openTimeout:      30 * time.Second,
+		halfOpenRequests: 1,
+	}, s)
+
+	assert.Equal(t, "3 * time.Second", durationLiteral(3*time.Second))
+	assert.Equal(t, "1500 * time.Millisecond", durationLiteral(1500*time.Millisecond))
+	assert.Equal(t, "time.Duration(1500)", durationLiteral(1500))
+
+	assert.True(t, isRepositoryRead("FindByEmail"))
+	assert.True(t, isRepositoryRead("SumByTotal"))
+	assert.False(t, isRepositoryRead("Save"))
+}
+
+func TestConfigManager_ValidateResilience(t *testing.T) {
+	cm := NewConfigManager()
+	cm.validateFeatures(&FeatureConfig{Resilience: ResilienceConfig{
+		Retry:   RetryConfig{MaxAttempts: -1, InitialBackoff: "fast", MaxBackoff: "1s"},
+		Breaker: CircuitBreakerConfig{OpenTimeout: "-5s"},
+	}})
+
+	var fields []string
+	for _, e := range cm.GetErrors() {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{
+		"features.resilience.retry.initial_backoff",
+		"features.resilience.breaker.open_timeout",
+		"features.resilience.retry.max_attempts",
+	}, fields)
+}
+
+func TestGenerateResilienceDecorator_DIWiring(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+
+	sm := NewSafetyManager(false, true, false)
+	generateRepository("Product", "postgres", true, false, false, false, "", sm)
+	generateResilienceDecorator("Product", sm)
+	require.True(t, hasResilienceDecorator("Product"))
+
+	generateFeatureProviders(filepath.Join(DirInternal, "di"), providerFeature{Name: "Product"}, "postgres", DIManual, sm)
+	providers := readParsedGo(t, filepath.Join(DirInternal, "di", "product_providers.go"))
+	assert.Contains(t, providers, "repository.NewResilientProductRepository(repository.NewPostgresProductRepository(db))")
+}
@@ -0,0 +1,473 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Retention modes of --retention-mode: what the purge job of an entity does
+// with the soft-deleted rows past their retention window.
+const (
+	RetentionPurge   = "purge"
+	RetentionArchive = "archive"
+)
+
+// ValidRetentionModes are the accepted --retention-mode values.
+var ValidRetentionModes = []string{RetentionPurge, RetentionArchive}
+
+// defaultRetentionSchedule is the schedule of purge jobs without
+// features.retention.schedule.
+const defaultRetentionSchedule = "0 4 * * *"
+
+// retentionConfigDecl declares the retention settings of pkg/config.
+const retentionConfigDecl = `// RetentionConfig tunes the purge jobs of entities with a retention window.
+type RetentionConfig struct {
+	// DryRun reports the rows the jobs would purge without deleting them.
+	DryRun bool
+	// BatchSize is the number of rows deleted per statement.
+	BatchSize int
+	// ArchiveDir is where jobs in archive mode write the rows they purge,
+	// e.g. a mounted cold storage bucket.
+	ArchiveDir string
+	// MetricsAddr serves the retention metrics at /metrics when set, e.g.
+	// ":9102".
+	MetricsAddr string
+}
+`
+
+// retentionConfigLoad is the Retention element of the Config literal Load
+// returns.
+const retentionConfigLoad = `		Retention: RetentionConfig{
+			DryRun:      getEnv("RETENTION_DRY_RUN", "false") == "true",
+			BatchSize:   getEnvAsInt("RETENTION_BATCH_SIZE", 500),
+			ArchiveDir:  getEnv("RETENTION_ARCHIVE_DIR", "archive"),
+			MetricsAddr: getEnv("RETENTION_METRICS_ADDR", ""),
+		},
+`
+
+// parseRetentionWindow parses a --retention window: a number of days ("90d")
+// or weeks ("12w"), or a Go duration ("36h").
+func parseRetentionWindow(window string) (time.Duration, error) {
+	window = strings.TrimSpace(window)
+	invalid := fmt.Errorf("invalid retention window %q: use days (90d), weeks (12w) or a duration (36h)", window)
+	if window == "" {
+		return 0, invalid
+	}
+
+	var d time.Duration
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	if unit, ok := units[window[len(window)-1:]]; ok {
+		n, err := strconv.Atoi(window[:len(window)-1])
+		if err != nil {
+			return 0, invalid
+		}
+		d = time.Duration(n) * unit
+	} else {
+		var err error
+		if d, err = time.ParseDuration(window); err != nil {
+			return 0, invalid
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("retention window %q must be positive", window)
+	}
+	return d, nil
+}
+
+// retentionWindowLiteral returns the Go expression of window, in days when it
+// is a whole number of them.
+func retentionWindowLiteral(window time.Duration) string {
+	switch {
+	case window%(24*time.Hour) == 0:
+		return fmt.Sprintf("%d * 24 * time.Hour", window/(24*time.Hour))
+	case window%time.Hour == 0:
+		return fmt.Sprintf("%d * time.Hour", window/time.Hour)
+	}
+	return durationLiteral(window)
+}
+
+// validateRetention reports why an entity cannot get a purge job: the job
+// hard-deletes soft-deleted rows through GORM.
+func validateRetention(database, mode string, softDelete bool) error {
+	if !contains(ValidRetentionModes, mode) {
+		return fmt.Errorf("invalid retention mode %q; use one of: %s", mode, strings.Join(ValidRetentionModes, ", "))
+	}
+	if !isGormDatabase(database) {
+		return fmt.Errorf("--retention purges rows through GORM and does not support %s", database)
+	}
+	if !softDelete {
+		return errors.New("--retention purges soft-deleted rows and needs --soft-delete")
+	}
+	return nil
+}
+
+// generateRetention writes the retention window of an entity, its purge job
+// and the shared pkg/retention, and registers the job in cmd/scheduler. The
+// entity must exist with a DeletedAt column.
+func generateRetention(entity string, window time.Duration, mode, schedule, fileNamingConvention string, sm ...*SafetyManager) {
+	domainDir := filepath.Join(DirInternal, DirDomain)
+	schedulerDir := filepath.Join(DirInternal, DirHandler, DirScheduler)
+	_ = os.MkdirAll(schedulerDir, 0o755)
+
+	var domainFile, jobFile string
+	if fileNamingConvention == "snake_case" {
+		domainFile = filepath.Join(domainDir, toSnakeCase(entity)+"_retention.go")
+		jobFile = filepath.Join(schedulerDir, toSnakeCase(entity)+"_retention.go")
+	} else if fileNamingConvention == "kebab-case" {
+		domainFile = filepath.Join(domainDir, toKebabCase(entity)+"-retention.go")
+		jobFile = filepath.Join(schedulerDir, toKebabCase(entity)+"-retention.go")
+	} else {
+		domainFile = filepath.Join(domainDir, strings.ToLower(entity)+"_retention.go")
+		jobFile = filepath.Join(schedulerDir, strings.ToLower(entity)+"_retention.go")
+	}
+
+	if err := writeGoFile(domainFile, buildRetentionWindow(entity, window), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing retention window: %v", err))
+		return
+	}
+	importPath := getImportPath(getModuleName())
+	if err := writeGoFile(jobFile, buildRetentionJob(entity, importPath, mode, schedule), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing retention job: %v", err))
+		return
+	}
+
+	writeOnce(filepath.Join(DirPkg, "retention", "retention.go"), retentionPackageTemplate, "retention package", sm...)
+	generateSchedulerFile(schedulerDir, importPath, sm...)
+	if err := ensureSchedulerConfig(sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Could not add the scheduler settings to pkg/config: %v", err))
+	}
+	if err := ensureConfigSection("Retention", "RetentionConfig", retentionConfigDecl, retentionConfigLoad, sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Could not add the retention settings to pkg/config: %v", err))
+	}
+	registration := fmt.Sprintf("\tjobs = append(jobs, scheduler.New%sRetentionJob(db, cfg.Retention))\n", entity)
+	registerSchedulerJobs(fmt.Sprintf("scheduler.New%sRetentionJob(", entity), registration, sm...)
+}
+
+// buildRetentionWindow renders internal/domain/<entity>_retention.go.
+func buildRetentionWindow(entity string, window time.Duration) string {
+	pluralLower := strings.ToLower(toPlural(entity))
+	var b strings.Builder
+	b.WriteString("package domain\n\n")
+	b.WriteString("import \"time\"\n\n")
+	fmt.Fprintf(&b, "// %sRetention is how long deleted %s are kept before the\n", entity, pluralLower)
+	fmt.Fprintf(&b, "// %s.purge job of cmd/scheduler removes them for good.\n", toSnakeCase(entity))
+	fmt.Fprintf(&b, "const %sRetention = %s\n", entity, retentionWindowLiteral(window))
+	return b.String()
+}
+
+// buildRetentionJob renders the <entity>_retention.go file of the scheduler
+// package: the job purging the entity's expired rows.
+func buildRetentionJob(entity, importPath, mode, schedule string) string {
+	pluralLower := strings.ToLower(toPlural(entity))
+	name := toSnakeCase(entity)
+
+	var b strings.Builder
+	b.WriteString("package scheduler\n\n")
+	b.WriteString("import (\n\t\"context\"\n\t\"log\"\n\n\t\"gorm.io/gorm\"\n\n")
+	fmt.Fprintf(&b, "\t\"%s/internal/domain\"\n", importPath)
+	fmt.Fprintf(&b, "\t\"%s/pkg/config\"\n", importPath)
+	fmt.Fprintf(&b, "\t\"%s/pkg/retention\"\n", importPath)
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(&b, "// New%sRetentionJob returns the %s.purge job, which hard-deletes the\n", entity, name)
+	if mode == RetentionArchive {
+		fmt.Fprintf(&b, "// %s deleted more than domain.%sRetention ago after archiving them\n", pluralLower, entity)
+		b.WriteString("// to cfg.ArchiveDir.\n")
+	} else {
+		fmt.Fprintf(&b, "// %s deleted more than domain.%sRetention ago.\n", pluralLower, entity)
+	}
+	fmt.Fprintf(&b, "func New%sRetentionJob(db *gorm.DB, cfg config.RetentionConfig) Job {\n", entity)
+	b.WriteString("\tpolicy := retention.Policy{\n")
+	fmt.Fprintf(&b, "\t\tWindow:    domain.%sRetention,\n", entity)
+	if mode == RetentionArchive {
+		b.WriteString("\t\tMode:      retention.Archive,\n")
+		b.WriteString("\t\tArchiver:  retention.NewFileArchiver(cfg.ArchiveDir),\n")
+	} else {
+		b.WriteString("\t\tMode:      retention.Purge,\n")
+	}
+	b.WriteString("\t\tBatchSize: cfg.BatchSize,\n")
+	b.WriteString("\t\tDryRun:    cfg.DryRun,\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\tretention.ServeMetrics(cfg.MetricsAddr)\n\n")
+	b.WriteString("\treturn Job{\n")
+	fmt.Fprintf(&b, "\t\tName:     %q,\n", name+".purge")
+	fmt.Fprintf(&b, "\t\tSchedule: %q,\n", schedule)
+	b.WriteString("\t\tRun: func(ctx context.Context) error {\n")
+	fmt.Fprintf(&b, "\t\t\treport, err := retention.Run[domain.%s](ctx, db, %q, policy)\n", entity, name)
+	fmt.Fprintf(&b, "\t\t\tlog.Printf(\"%s.purge: %%s\", report)\n", name)
+	b.WriteString("\t\t\treturn err\n")
+	b.WriteString("\t\t},\n")
+	b.WriteString("\t}\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// retentionPackageTemplate is pkg/retention/retention.go, shared by the purge
+// jobs of every entity.
+const retentionPackageTemplate = `// Package retention removes the soft-deleted rows of entities for good once
+// their retention window has passed, archiving them first when asked to.
+package retention
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+// Mode is what a run does with the expired rows.
+type Mode string
+
+const (
+	// Purge hard-deletes the expired rows.
+	Purge Mode = "purge"
+	// Archive hands the expired rows to an Archiver, then hard-deletes them.
+	Archive Mode = "archive"
+)
+
+// defaultBatchSize is the batch size of policies without one.
+const defaultBatchSize = 500
+
+// Policy is the retention of an entity.
+type Policy struct {
+	// Window is how long soft-deleted rows are kept.
+	Window time.Duration
+	Mode   Mode
+	// Archiver keeps the rows of Archive mode.
+	Archiver Archiver
+	// BatchSize is the number of rows deleted per statement; 500 when zero.
+	BatchSize int
+	// DryRun reports the expired rows without deleting them.
+	DryRun bool
+}
+
+// Archiver keeps the rows a run deletes, e.g. in cold storage. A batch is
+// deleted once Archive returns nil, so it must be stored durably by then. A
+// batch whose deletion fails is archived again by the next run.
+type Archiver interface {
+	Archive(ctx context.Context, entity string, rows []json.RawMessage) error
+}
+
+// Report describes a run.
+type Report struct {
+	Entity string
+	// Cutoff is the deletion time rows deleted before expired at.
+	Cutoff time.Time
+	// Expired is the number of rows past the window when the run started.
+	Expired  int64
+	Archived int64
+	Purged   int64
+	DryRun   bool
+	Duration time.Duration
+}
+
+func (r Report) String() string {
+	cutoff := r.Cutoff.UTC().Format(time.RFC3339)
+	if r.DryRun {
+		return fmt.Sprintf("dry run: %d rows deleted before %s would be purged", r.Expired, cutoff)
+	}
+	s := fmt.Sprintf("purged %d of %d rows deleted before %s", r.Purged, r.Expired, cutoff)
+	if r.Archived > 0 {
+		s += fmt.Sprintf(", %d archived", r.Archived)
+	}
+	return s + fmt.Sprintf(" in %s", r.Duration.Round(time.Millisecond))
+}
+
+var (
+	expiredRows = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "retention_expired_rows",
+		Help: "Soft-deleted rows past their retention window at the start of the last run.",
+	}, []string{"entity"})
+	purgedRows = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "retention_purged_rows_total",
+		Help: "Rows deleted for good by retention runs.",
+	}, []string{"entity"})
+	archivedRows = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "retention_archived_rows_total",
+		Help: "Rows archived by retention runs before their deletion.",
+	}, []string{"entity"})
+	runs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "retention_runs_total",
+		Help: "Retention runs by result: success, failure or dry_run.",
+	}, []string{"entity", "result"})
+	lastSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "retention_last_success_timestamp_seconds",
+		Help: "Time of the last retention run that purged every expired row.",
+	}, []string{"entity"})
+)
+
+// Run removes the rows of model T soft-deleted more than p.Window ago, in
+// batches of p.BatchSize. entity names the rows in the report, the metrics
+// and the archives. It stops when ctx is done; the next run picks up the
+// remaining rows.
+func Run[T any](ctx context.Context, db *gorm.DB, entity string, p Policy) (report Report, err error) {
+	start := time.Now()
+	report = Report{Entity: entity, Cutoff: start.Add(-p.Window), DryRun: p.DryRun}
+	defer func() {
+		report.Duration = time.Since(start)
+		purgedRows.WithLabelValues(entity).Add(float64(report.Purged))
+		archivedRows.WithLabelValues(entity).Add(float64(report.Archived))
+		switch {
+		case err != nil:
+			runs.WithLabelValues(entity, "failure").Inc()
+		case p.DryRun:
+			runs.WithLabelValues(entity, "dry_run").Inc()
+		default:
+			runs.WithLabelValues(entity, "success").Inc()
+			lastSuccess.WithLabelValues(entity).SetToCurrentTime()
+		}
+	}()
+
+	if p.Window <= 0 {
+		return report, errors.New("retention window must be positive")
+	}
+	if p.Mode == Archive && p.Archiver == nil {
+		return report, errors.New("archive mode needs an archiver")
+	}
+	expired := func() *gorm.DB {
+		return db.WithContext(ctx).Unscoped().Model(new(T)).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", report.Cutoff)
+	}
+	if err := expired().Count(&report.Expired).Error; err != nil {
+		return report, fmt.Errorf("count expired rows: %w", err)
+	}
+	expiredRows.WithLabelValues(entity).Set(float64(report.Expired))
+	if p.DryRun || report.Expired == 0 {
+		return report, nil
+	}
+
+	batchSize := p.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		var rows []T
+		if err := expired().Limit(batchSize).Find(&rows).Error; err != nil {
+			return report, fmt.Errorf("find expired rows: %w", err)
+		}
+		if len(rows) == 0 {
+			return report, nil
+		}
+		if p.Mode == Archive {
+			if err := archive(ctx, p.Archiver, entity, rows); err != nil {
+				return report, err
+			}
+			report.Archived += int64(len(rows))
+		}
+		result := db.WithContext(ctx).Unscoped().Delete(&rows)
+		if result.Error != nil {
+			return report, fmt.Errorf("delete expired rows: %w", result.Error)
+		}
+		report.Purged += result.RowsAffected
+		if len(rows) < batchSize || result.RowsAffected == 0 {
+			return report, nil
+		}
+	}
+}
+
+func archive[T any](ctx context.Context, archiver Archiver, entity string, rows []T) error {
+	batch := make([]json.RawMessage, len(rows))
+	for i := range rows {
+		data, err := json.Marshal(rows[i])
+		if err != nil {
+			return fmt.Errorf("encode expired row: %w", err)
+		}
+		batch[i] = data
+	}
+	if err := archiver.Archive(ctx, entity, batch); err != nil {
+		return fmt.Errorf("archive expired rows: %w", err)
+	}
+	return nil
+}
+
+// FileArchiver writes each batch to a gzipped JSON Lines file under
+// Dir/<entity>/<date>/, e.g. a mounted cold storage bucket.
+type FileArchiver struct {
+	Dir string
+}
+
+func NewFileArchiver(dir string) *FileArchiver {
+	return &FileArchiver{Dir: dir}
+}
+
+// Archive writes rows to a new file, renamed into place once synced.
+func (a *FileArchiver) Archive(ctx context.Context, entity string, rows []json.RawMessage) error {
+	now := time.Now().UTC()
+	dir := filepath.Join(a.Dir, entity, now.Format("2006-01-02"))
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".archive-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	zw := gzip.NewWriter(tmp)
+	w := bufio.NewWriter(zw)
+	for _, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		w.Write(row)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%d.jsonl.gz", entity, now.UnixNano())
+	return os.Rename(tmp.Name(), filepath.Join(dir, name))
+}
+
+var serveMetrics sync.Once
+
+// ServeMetrics serves the metrics of the default Prometheus registry, the
+// retention ones included, at /metrics on addr. It does nothing when addr is
+// empty and serves once per process.
+func ServeMetrics(addr string) {
+	if addr == "" {
+		return
+	}
+	serveMetrics.Do(func() {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+		go func() {
+			if err := server.ListenAndServe(); err != nil {
+				log.Printf("retention: serving metrics: %v", err)
+			}
+		}()
+	})
+}
+`
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRetentionWindow(t *testing.T) {
+	for window, want := range map[string]time.Duration{
+		"90d": 90 * 24 * time.Hour,
+		"12w": 84 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+	} {
+		got, err := parseRetentionWindow(window)
+		require.NoError(t, err, window)
+		assert.Equal(t, want, got, window)
+	}
+	for _, window := range []string{"", "d", "ninety days", "-3d", "0h"} {
+		_, err := parseRetentionWindow(window)
+		assert.Error(t, err, window)
+	}
+
+	assert.Equal(t, "84 * 24 * time.Hour", retentionWindowLiteral(84*24*time.Hour))
+	assert.Equal(t, "36 * time.Hour", retentionWindowLiteral(36*time.Hour))
+	assert.Equal(t, "90 * time.Second", retentionWindowLiteral(90*time.Second))
+}
+
+func TestValidateRetention(t *testing.T) {
+	assert.NoError(t, validateRetention(DBPostgres, RetentionArchive, true))
+	assert.ErrorContains(t, validateRetention(DBPostgres, "shred", true), `invalid retention mode "shred"`)
+	assert.ErrorContains(t, validateRetention(DBMongoDB, RetentionPurge, true), "does not support mongodb")
+	assert.ErrorContains(t, validateRetention(DBPostgres, RetentionPurge, false), "needs --soft-delete")
+
+	cm := NewConfigManager()
+	cm.validateFeatures(&FeatureConfig{Retention: RetentionConfig{Mode: "shred", Schedule: "daily"}})
+	require.Len(t, cm.GetErrors(), 2)
+	assert.Equal(t, "features.retention.mode", cm.GetErrors()[0].Field)
+	assert.Equal(t, "features.retention.schedule", cm.GetErrors()[1].Field)
+}
+
+func TestGenerateRetention(t *testing.T) {
+	defer ensureTestUI(t)()
+	setupCLIProject(t, fullProductUseCase)
+	writeTestFile(t, ".", filepath.Join("pkg", "config", "config.go"), legacyConfig)
+	require.NoError(t, os.WriteFile(".goca.yaml", []byte("project:\n  name: shop\n  module: testproject\ndatabase:\n  type: postgres\n"), 0o644))
+
+	sm := NewSafetyManager(false, false, false)
+	generateRetention("OrderItem", 90*24*time.Hour, RetentionArchive, "@daily", "lowercase", sm)
+
+	window := readParsedGo(t, filepath.Join(DirInternal, DirDomain, "orderitem_retention.go"))
+	assert.Contains(t, window, "const OrderItemRetention = 90 * 24 * time.Hour")
+
+	job := readParsedGo(t, filepath.Join(DirInternal, DirHandler, DirScheduler, "orderitem_retention.go"))
+	assert.Contains(t, job, "func NewOrderItemRetentionJob(db *gorm.DB, cfg config.RetentionConfig) Job {")
+	assert.Contains(t, job, "Archiver:  retention.NewFileArchiver(cfg.ArchiveDir),")
+	assert.Contains(t, job, `Name:     "order_item.purge",`)
+	assert.Contains(t, job, `Schedule: "@daily",`)
+	assert.Contains(t, job, `retention.Run[domain.OrderItem](ctx, db, "order_item", policy)`)
+
+	pkg := readParsedGo(t, filepath.Join(DirPkg, "retention", "retention.go"))
+	assert.Contains(t, pkg, `Where("deleted_at IS NOT NULL AND deleted_at < ?", report.Cutoff)`)
+	config := readParsedGo(t, filepath.Join(DirPkg, DirConfig, "config.go"))
+	assert.Contains(t, config, `BatchSize:   getEnvAsInt("RETENTION_BATCH_SIZE", 500),`)
+	assert.Contains(t, config, "Scheduler SchedulerConfig")
+
+	// Purge jobs only need the database, so no DI container is built ...
+	mainPath := filepath.Join("cmd", "scheduler", "main.go")
+	main := readParsedGo(t, mainPath)
+	assert.Contains(t, main, "jobs = append(jobs, scheduler.NewOrderItemRetentionJob(db, cfg.Retention))")
+	assert.NotContains(t, main, "container")
+	assert.NotContains(t, main, "internal/di")
+
+	// ... until a cron handler registers use case jobs.
+	generateCronHandler("Product", "lowercase", "list", sm)
+	generateRetention("OrderItem", 90*24*time.Hour, RetentionArchive, "@daily", "lowercase", sm)
+	main = readParsedGo(t, mainPath)
+	assert.Contains(t, main, `"testproject/internal/di"`)
+	assert.Equal(t, 1, strings.Count(main, "container := di.NewContainer(db)"))
+	assert.Equal(t, 1, strings.Count(main, "scheduler.NewOrderItemRetentionJob("))
+	assert.Less(t, strings.Index(main, "container := di.NewContainer(db)"), strings.Index(main, "var jobs []scheduler.Job"))
+	assert.Less(t, strings.Index(main, "scheduler.NewProductJobs("), strings.Index(main, schedulerJobsMarker))
+}
+
+func TestBuildRetentionJob_Purge(t *testing.T) {
+	job := buildRetentionJob("Product", "testproject", RetentionPurge, defaultRetentionSchedule)
+	assert.Contains(t, job, "Mode:      retention.Purge,")
+	assert.NotContains(t, job, "Archiver")
+	assert.Contains(t, job, `Schedule: "0 4 * * *",`)
+}
@@ -37,6 +37,10 @@ var (
 	noInteractive bool
 	quietMode     bool
 	verboseMode   bool
+	chdir         string
+
+	profileGeneration bool
+	stopCommandStep   = func() {}
 )
 
 var rootCmd = &cobra.Command{
@@ -47,7 +51,7 @@ Clean Architecture projects following best practices.
 
 It generates clean, well-structured layered code, allowing you to 
 focus on business logic instead of repetitive configuration tasks.`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		verbosity := 1
 		if quietMode {
 			verbosity = 0
@@ -57,13 +61,46 @@ focus on business logic instead of repetitive configuration tasks.`,
 		}
 		initUI(noColor, verbosity)
 		ui.SetInteractive(!noInteractive)
+		if err := changeDirectory(chdir); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+		startLockRecording(cmd, args)
+		resetGeneratedGoErrors()
+		if profileGeneration {
+			startGenerationProfile()
+			stopCommandStep = profileStep(cmd.CommandPath())
+		}
+		return nil
 	},
 }
 
+// changeDirectory makes dir the working directory for -C/--chdir, so every
+// command runs as if goca had been started there.
+func changeDirectory(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	if err := os.Chdir(dir); err != nil {
+		return fmt.Errorf("cannot change to directory %s: %w", dir, err)
+	}
+	return nil
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	registerCompletions(rootCmd)
+	err := rootCmd.Execute()
+	if err == nil {
+		err = generatedGoError()
+	}
+	stopCommandStep()
+	printGenerationProfile()
+	if lockErr := saveGenerationLock(); lockErr != nil && ui != nil {
+		ui.Warning(fmt.Sprintf("Could not update %s: %v", generationLockFile, lockErr))
+	}
+	if err != nil {
 		if ui != nil {
 			ui.Error(err.Error())
 		} else {
@@ -79,6 +116,8 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&noInteractive, "no-interactive", false, "Disable interactive prompts")
 	rootCmd.PersistentFlags().BoolVarP(&quietMode, "quiet", "q", false, "Suppress all output except errors and success messages")
 	rootCmd.PersistentFlags().BoolVarP(&verboseMode, "verbose", "v", false, "Enable verbose output with debug details")
+	rootCmd.PersistentFlags().StringVarP(&chdir, "chdir", "C", "", "Run as if goca was started in this directory")
+	rootCmd.PersistentFlags().BoolVar(&profileGeneration, "profile-generation", false, "Report per-step generation durations, file counts and the slowest steps")
 
 	// Add subcommands
 	rootCmd.AddCommand(versionCmd)
@@ -99,4 +138,34 @@ func init() {
 	rootCmd.AddCommand(ciCmd)
 	rootCmd.AddCommand(middlewareCmd)
 	rootCmd.AddCommand(analyzeCmd)
+	rootCmd.AddCommand(experimentsCmd)
+	rootCmd.AddCommand(generateCmd)
+	rootCmd.AddCommand(fieldCmd)
+	rootCmd.AddCommand(selfUpdateCmd)
+	rootCmd.AddCommand(deployCmd)
+	rootCmd.AddCommand(seedCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(serveUICmd)
+	rootCmd.AddCommand(pluginCmd)
+	rootCmd.AddCommand(ingestCmd)
+	rootCmd.AddCommand(cdcCmd)
+	rootCmd.AddCommand(archiveCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(explainCmd)
+	rootCmd.AddCommand(breakingCmd)
+	rootCmd.AddCommand(adminCmd)
+	rootCmd.AddCommand(selfTestCmd)
+	rootCmd.AddCommand(benchCmd)
+	rootCmd.AddCommand(auditDepsCmd)
+	rootCmd.AddCommand(notificationCmd)
+	rootCmd.AddCommand(renameCmd)
+	rootCmd.AddCommand(featureFlagsCmd)
+	rootCmd.AddCommand(presenterCmd)
+	rootCmd.AddCommand(completionCmd)
+	rootCmd.AddCommand(docsCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(regenCmd)
+	rootCmd.AddCommand(exampleCmd)
+	rootCmd.AddCommand(valueObjectCmd)
 }
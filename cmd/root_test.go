@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeDirectory(t *testing.T) {
+	origDir, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(origDir) })
+
+	require.NoError(t, changeDirectory(""))
+	wd, _ := os.Getwd()
+	assert.Equal(t, origDir, wd)
+
+	dir := t.TempDir()
+	require.NoError(t, changeDirectory(dir))
+	wd, _ = os.Getwd()
+	want, _ := filepath.EvalSymlinks(dir)
+	got, _ := filepath.EvalSymlinks(wd)
+	assert.Equal(t, want, got)
+
+	err := changeDirectory(filepath.Join(dir, "missing"))
+	assert.ErrorContains(t, err, "cannot change to directory")
+}
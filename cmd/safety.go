@@ -5,13 +5,16 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
 // DryRunEntry represents a file that would be created or modified in dry-run mode.
 type DryRunEntry struct {
 	Path   string
-	Action string // "create" or "overwrite"
+	Action string // "create", "overwrite", "keep", or "edit" and "move to <path>" for goca rename
 	Size   int
+	// content is what would have been written, for goca diff.
+	content string
 }
 
 // backups, and dry-run mode.
@@ -19,10 +22,16 @@ type SafetyManager struct {
 	DryRun       bool
 	Force        bool
 	Backup       bool
+	Merge        bool // keep existing files instead of failing or overwriting them
 	BackupDir    string
 	conflicts    []string
 	createdFiles []string
+	skippedFiles []string
 	pendingFiles []DryRunEntry
+
+	// mu serializes the writes, so features generated in parallel by
+	// goca feature --batch can share one manager.
+	mu sync.Mutex
 }
 
 // NewSafetyManager creates a new safety manager instance.
@@ -93,6 +102,21 @@ func (sm *SafetyManager) BackupFile(filePath string) error {
 
 // WriteFile writes a file with safety checks.
 func (sm *SafetyManager) WriteFile(filePath, content string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	// In merge mode an existing file always wins over the generated one.
+	if sm.Merge {
+		if _, err := os.Stat(filePath); err == nil {
+			sm.keepExisting(filePath, len(content))
+			return nil
+		}
+	}
+
+	if err := checkProtectedPath(filePath); err != nil {
+		return err
+	}
+
 	// Check for conflicts first
 	if err := sm.CheckFileConflict(filePath); err != nil && !sm.Force {
 		return err
@@ -103,7 +127,7 @@ func (sm *SafetyManager) WriteFile(filePath, content string) error {
 		if _, statErr := os.Stat(filePath); statErr == nil {
 			action = "overwrite"
 		}
-		entry := DryRunEntry{Path: filePath, Action: action, Size: len(content)}
+		entry := DryRunEntry{Path: filePath, Action: action, Size: len(content), content: content}
 		sm.pendingFiles = append(sm.pendingFiles, entry)
 		sm.createdFiles = append(sm.createdFiles, filePath)
 		return nil
@@ -122,6 +146,7 @@ func (sm *SafetyManager) WriteFile(filePath, content string) error {
 	}
 
 	sm.createdFiles = append(sm.createdFiles, filePath)
+	recordGenerated(filePath, content)
 	if ui != nil {
 		ui.FileCreated(filePath)
 	} else {
@@ -136,12 +161,19 @@ func (sm *SafetyManager) WriteFile(filePath, content string) error {
 // into content, it overwrites without requiring --force, while still honoring
 // dry-run and backup.
 func (sm *SafetyManager) WriteMergedFile(filePath, content string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if err := checkProtectedPath(filePath); err != nil {
+		return err
+	}
+
 	if sm.DryRun {
 		action := "create"
 		if _, statErr := os.Stat(filePath); statErr == nil {
 			action = "overwrite"
 		}
-		sm.pendingFiles = append(sm.pendingFiles, DryRunEntry{Path: filePath, Action: action, Size: len(content)})
+		sm.pendingFiles = append(sm.pendingFiles, DryRunEntry{Path: filePath, Action: action, Size: len(content), content: content})
 		sm.createdFiles = append(sm.createdFiles, filePath)
 		return nil
 	}
@@ -165,6 +197,7 @@ func (sm *SafetyManager) WriteMergedFile(filePath, content string) error {
 	}
 
 	sm.createdFiles = append(sm.createdFiles, filePath)
+	recordGenerated(filePath, content)
 	if ui != nil {
 		ui.FileCreated(filePath)
 	} else {
@@ -173,18 +206,77 @@ func (sm *SafetyManager) WriteMergedFile(filePath, content string) error {
 	return nil
 }
 
+// planChange records, in dry-run mode, a change a command makes to an
+// existing file without WriteFile, such as goca rename moving it.
+func (sm *SafetyManager) planChange(filePath, action, content string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.pendingFiles = append(sm.pendingFiles, DryRunEntry{Path: filePath, Action: action, Size: len(content), content: content})
+}
+
+// keepExisting records that filePath was left untouched in merge mode.
+func (sm *SafetyManager) keepExisting(filePath string, size int) {
+	sm.skippedFiles = append(sm.skippedFiles, filePath)
+	if sm.DryRun {
+		sm.pendingFiles = append(sm.pendingFiles, DryRunEntry{Path: filePath, Action: "keep", Size: size})
+		return
+	}
+	if ui != nil {
+		ui.Dim("  Kept existing: " + filePath)
+	} else {
+		fmt.Printf("Kept existing: %s\n", filePath)
+	}
+}
+
+// sharedFileLocks holds one mutex per shared file; see lockSharedFile.
+var sharedFileLocks sync.Map
+
+// lockSharedFile locks path until the returned function is called. The
+// generators that rebuild a file several features share (errors.go, dto.go,
+// interfaces.go, routes.go, messages.go, main.go) from its current content
+// hold the lock from the read to the write, so features generated in
+// parallel do not lose or duplicate each other's additions:
+//
+//	defer lockSharedFile(filename)()
+//
+// The lock is not reentrant: the locked section must not call another
+// generator that locks the same file.
+func lockSharedFile(path string) func() {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		key = filepath.Clean(path)
+	}
+	value, _ := sharedFileLocks.LoadOrStore(key, &sync.Mutex{})
+	mu := value.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// GetSkippedFiles returns the existing files that were kept in merge mode.
+func (sm *SafetyManager) GetSkippedFiles() []string {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.skippedFiles
+}
+
 // GetPendingFiles returns the dry-run entries (files that would be created/overwritten).
 func (sm *SafetyManager) GetPendingFiles() []DryRunEntry {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 	return sm.pendingFiles
 }
 
 // GetConflicts returns list of file conflicts found.
 func (sm *SafetyManager) GetConflicts() []string {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 	return sm.conflicts
 }
 
 // GetCreatedFiles returns list of files that would be/were created.
 func (sm *SafetyManager) GetCreatedFiles() []string {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 	return sm.createdFiles
 }
 
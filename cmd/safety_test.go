@@ -104,6 +104,27 @@ func TestSafetyManager_CheckFileConflict_Force_WithBackup(t *testing.T) {
 	assert.Equal(t, "old content", string(content))
 }
 
+func TestSafetyManager_Merge_KeepsExistingFile(t *testing.T) {
+	cleanup := setupDiscardUI(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "README.md")
+	require.NoError(t, os.WriteFile(existing, []byte("mine"), 0o644))
+
+	sm := NewSafetyManager(false, false, false)
+	sm.Merge = true
+
+	require.NoError(t, sm.WriteFile(existing, "generated"))
+	require.NoError(t, sm.WriteFile(filepath.Join(dir, "main.go"), "package main"))
+
+	content, err := os.ReadFile(existing)
+	require.NoError(t, err)
+	assert.Equal(t, "mine", string(content))
+	assert.Equal(t, []string{existing}, sm.GetSkippedFiles())
+	assert.FileExists(t, filepath.Join(dir, "main.go"))
+}
+
 func TestNewNameConflictDetector(t *testing.T) {
 	t.Parallel()
 	ncd := NewNameConflictDetector("/some/path")
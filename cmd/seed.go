@@ -0,0 +1,590 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+// seedLedgerDir is where the generated seed runner records the IDs of the rows
+// it inserted, one file per environment.
+const seedLedgerDir = ".goca/seeds"
+
+// seedProductionEnvs only seed fixture sets declared for them, so the sample
+// data of Get<Entity>Seeds never reaches production.
+var seedProductionEnvs = []string{"prod", "production"}
+
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Load seed data into the database",
+	Long: `Loads the fixtures generated in internal/domain/<entity>_seeds.go into the
+project's database.`,
+}
+
+var seedRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Insert the seed fixtures of an environment",
+	Long: `Inserts the seed fixtures of an environment through the repository layer.
+
+The command collects every Get<Entity>Seeds() function in internal/domain and
+generates:
+
+  - internal/seed/registry.go  Inserts and removes the fixtures of each entity
+  - cmd/seed/main.go           Opens the database and runs the registry
+
+then runs them with 'go run ./cmd/seed'. Rows are saved with the repository of
+the entity from the DI container, so entities need a repository and a DI
+registration.
+
+Per-environment fixture sets are functions named Get<Entity>Seeds<Env>(), e.g.
+GetProductSeedsStaging() for --env staging. Other environments use
+Get<Entity>Seeds(), except prod and production, which only seed the sets
+declared for them.
+
+Entities are seeded after the entities they reference through <Entity>ID
+fields. A reference value n points at the n-th fixture of the related entity
+and is replaced with the ID it was inserted with.
+
+The IDs of the inserted rows are recorded in .goca/seeds/<env>.json; --undo
+deletes those rows and the record.
+
+Examples:
+  goca seed run
+  goca seed run --env staging
+  goca seed run --env staging --undo`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		env, _ := cmd.Flags().GetString("env")
+		undo, _ := cmd.Flags().GetBool("undo")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if err := validateSeedEnv(env); err != nil {
+			return err
+		}
+
+		entities, err := discoverSeedEntities(filepath.Join(DirInternal, DirDomain))
+		if err != nil {
+			return err
+		}
+		entities = filterSeedableEntities(entities, containerRepositoryGetters(filepath.Join(DirInternal, "di")))
+		if len(entities) == 0 {
+			return fmt.Errorf("no seed data found: generate entities with seeds (goca entity <Name> --fields ...) and register their repositories in the DI container (goca di)")
+		}
+		entities, err = orderSeedEntities(entities)
+		if err != nil {
+			return err
+		}
+
+		action := "Insert"
+		if undo {
+			action = "Undo"
+		}
+		ui.Header("Goca Seed")
+		ui.Blank()
+		ui.KeyValue("Environment", env)
+		ui.KeyValue("Action", action)
+		ui.Blank()
+		var rows [][]string
+		for _, entity := range entities {
+			rows = append(rows, []string{entity.Name, entity.setFor(env), strings.Join(entity.referencedEntities(), ", ")})
+		}
+		ui.Table([]string{"Entity", "Fixtures", "After"}, rows)
+		ui.Blank()
+
+		sm := NewSafetyManager(dryRun, true, false)
+		if err := generateSeedRunner(entities, sm); err != nil {
+			return err
+		}
+		if dryRun {
+			sm.PrintSummary()
+			return nil
+		}
+
+		runArgs := []string{"run", "./cmd/seed", "-env", env}
+		if undo {
+			runArgs = append(runArgs, "-undo")
+		}
+		ui.Dim("   go " + strings.Join(runArgs, " "))
+		run := exec.Command("go", runArgs...)
+		run.Stdout = os.Stdout
+		run.Stderr = os.Stderr
+		if err := run.Run(); err != nil {
+			return fmt.Errorf("seed runner failed: %w", err)
+		}
+
+		ui.Blank()
+		if undo {
+			ui.Success(fmt.Sprintf("Seed data of %s removed", env))
+		} else {
+			ui.Success(fmt.Sprintf("Seed data of %s inserted; remove it with 'goca seed run --env %s --undo'", env, env))
+		}
+		return nil
+	},
+}
+
+// seedEntity is an entity with seed fixtures.
+type seedEntity struct {
+	Name string
+	// Sets maps an environment to the function returning its fixtures; the
+	// empty environment is the default Get<Entity>Seeds.
+	Sets map[string]string
+	// References are the fields referencing other seeded entities.
+	References []seedReference
+}
+
+// seedReference is a <Entity>ID field of a seeded entity.
+type seedReference struct {
+	Field  string
+	Entity string
+	Type   string
+}
+
+// setFor returns the function providing the fixtures of env, or "" when the
+// entity seeds nothing there.
+func (e seedEntity) setFor(env string) string {
+	if fn, ok := e.Sets[env]; ok {
+		return fn
+	}
+	for _, prod := range seedProductionEnvs {
+		if env == prod {
+			return ""
+		}
+	}
+	return e.Sets[""]
+}
+
+// referencedEntities returns the entities e references, sorted.
+func (e seedEntity) referencedEntities() []string {
+	var names []string
+	for _, ref := range e.References {
+		names = append(names, ref.Entity)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// seedFuncPattern matches seed functions: Get<Entity>Seeds and
+// Get<Entity>Seeds<Env>.
+var seedFuncPattern = regexp.MustCompile(`^Get([A-Z]\w*?)Seeds([A-Z]\w*)?$`)
+
+// validateSeedEnv checks that env can be part of a function name.
+func validateSeedEnv(env string) error {
+	if env == "" {
+		return fmt.Errorf("--env must not be empty")
+	}
+	for _, r := range env {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return fmt.Errorf("invalid --env %q: use letters and digits only, e.g. dev or staging", env)
+		}
+	}
+	return nil
+}
+
+// discoverSeedEntities collects the seed functions declared in dir and the
+// references between the entities they seed.
+func discoverSeedEntities(dir string) ([]seedEntity, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	var parsed []*ast.File
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s: %w", file, err)
+		}
+		parsed = append(parsed, f)
+	}
+
+	byName := make(map[string]*seedEntity)
+	for _, f := range parsed {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Type.Params.NumFields() != 0 || fn.Type.Results.NumFields() != 1 {
+				continue
+			}
+			m := seedFuncPattern.FindStringSubmatch(fn.Name.Name)
+			if m == nil || types.ExprString(fn.Type.Results.List[0].Type) != "[]"+m[1] {
+				continue
+			}
+			entity := byName[m[1]]
+			if entity == nil {
+				entity = &seedEntity{Name: m[1], Sets: make(map[string]string)}
+				byName[m[1]] = entity
+			}
+			entity.Sets[strings.ToLower(m[2])] = fn.Name.Name
+		}
+	}
+
+	var entities []seedEntity
+	for _, entity := range byName {
+		for _, f := range parsed {
+			st := findStructType(f, entity.Name)
+			if st == nil {
+				continue
+			}
+			for _, field := range structFields(f, st) {
+				target := strings.TrimSuffix(field.Name, "ID")
+				if target == field.Name || target == entity.Name || byName[target] == nil || !isIntegerType(field.Type) {
+					continue
+				}
+				entity.References = append(entity.References, seedReference{Field: field.Name, Entity: target, Type: field.Type})
+			}
+		}
+		entities = append(entities, *entity)
+	}
+	sort.Slice(entities, func(i, j int) bool { return entities[i].Name < entities[j].Name })
+	return entities, nil
+}
+
+// isIntegerType reports whether typ is a built-in integer type.
+func isIntegerType(typ string) bool {
+	switch typ {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return true
+	}
+	return false
+}
+
+// containerGetterPattern matches the repository getters of the DI container.
+var containerGetterPattern = regexp.MustCompile(`func \(c \*Container\) (\w+)Repository\(\)`)
+
+// containerRepositoryGetters returns the entities whose repository the DI
+// container in dir exposes.
+func containerRepositoryGetters(dir string) map[string]bool {
+	getters := make(map[string]bool)
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, m := range containerGetterPattern.FindAllStringSubmatch(string(content), -1) {
+			getters[m[1]] = true
+		}
+	}
+	return getters
+}
+
+// filterSeedableEntities drops, with a warning, the entities whose repository
+// is not in the DI container, and the references to them.
+func filterSeedableEntities(entities []seedEntity, getters map[string]bool) []seedEntity {
+	var seedable []seedEntity
+	for _, entity := range entities {
+		if getters[entity.Name] {
+			seedable = append(seedable, entity)
+			continue
+		}
+		ui.Warning(fmt.Sprintf("%s has seeds but no repository in the DI container; skipped (run 'goca di --features %s')", entity.Name, entity.Name))
+	}
+	for i := range seedable {
+		var refs []seedReference
+		for _, ref := range seedable[i].References {
+			if getters[ref.Entity] {
+				refs = append(refs, ref)
+			}
+		}
+		seedable[i].References = refs
+	}
+	return seedable
+}
+
+// orderSeedEntities sorts entities so every entity comes after the entities
+// it references, alphabetically otherwise.
+func orderSeedEntities(entities []seedEntity) ([]seedEntity, error) {
+	remaining := append([]seedEntity(nil), entities...)
+	seeded := make(map[string]bool)
+	var ordered []seedEntity
+	for len(remaining) > 0 {
+		var next []seedEntity
+		progressed := false
+		for _, entity := range remaining {
+			ready := true
+			for _, ref := range entity.References {
+				if !seeded[ref.Entity] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				ordered = append(ordered, entity)
+				progressed = true
+			} else {
+				next = append(next, entity)
+			}
+		}
+		if !progressed {
+			var names []string
+			for _, entity := range next {
+				names = append(names, entity.Name)
+			}
+			return nil, fmt.Errorf("seed references form a cycle between %s; remove a reference from one of their seeds", strings.Join(names, ", "))
+		}
+		for _, entity := range ordered {
+			seeded[entity.Name] = true
+		}
+		remaining = next
+	}
+	return ordered, nil
+}
+
+// generateSeedRunner writes the seed registry, regenerated on every run, and
+// the runner entrypoint, written once so it can be customized.
+func generateSeedRunner(entities []seedEntity, sm *SafetyManager) error {
+	importPath := getImportPath(getModuleName())
+	if err := writeGoFile(filepath.Join(DirInternal, "seed", "registry.go"), buildSeedRegistry(importPath, entities), sm); err != nil {
+		return fmt.Errorf("writing seed registry: %w", err)
+	}
+
+	mainPath := filepath.Join("cmd", "seed", "main.go")
+	if _, err := os.Stat(mainPath); err == nil {
+		return nil
+	}
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	database := ci.GetDatabaseType("")
+	content, ok := buildSeedMain(importPath, database, projectSQLiteDriver())
+	if !ok {
+		return fmt.Errorf("goca seed run does not support %s; insert the fixtures of internal/domain/*_seeds.go with your own code", database)
+	}
+	if err := writeGoFile(mainPath, content, sm); err != nil {
+		return fmt.Errorf("writing seed runner: %w", err)
+	}
+	return nil
+}
+
+// buildSeedRegistry returns internal/seed/registry.go for entities, which are
+// in seeding order.
+func buildSeedRegistry(importPath string, entities []seedEntity) string {
+	var b strings.Builder
+	b.WriteString("// Code generated by goca seed run. DO NOT EDIT.\n\n")
+	b.WriteString("// Package seed inserts and removes the seed fixtures of internal/domain.\n")
+	b.WriteString("package seed\n\n")
+	b.WriteString("import (\n\t\"fmt\"\n\n")
+	fmt.Fprintf(&b, "\t\"%s/internal/di\"\n", importPath)
+	fmt.Fprintf(&b, "\t\"%s/internal/domain\"\n", importPath)
+	b.WriteString(")\n\n")
+
+	b.WriteString(`// Entity inserts and removes the fixtures of one entity through its
+// repository.
+type Entity struct {
+	Name string
+	// Insert saves the fixtures of env and returns the IDs of the saved rows.
+	// ids holds the IDs of the entities seeded before, by entity name.
+	Insert func(c *di.Container, env string, ids map[string][]int) ([]int, error)
+	// Delete removes a seeded row.
+	Delete func(c *di.Container, id int) error
+}
+
+// Registry lists the seeded entities; entities come after the entities they
+// reference.
+var Registry = []Entity{
+`)
+	for _, entity := range entities {
+		writeSeedRegistryEntry(&b, entity)
+	}
+	b.WriteString("}\n\n")
+
+	b.WriteString(`// resolve maps a reference to the n-th fixture of an entity to the ID it
+// was inserted with. Other values are kept.
+func resolve(ref int, ids []int) int {
+	if ref >= 1 && ref <= len(ids) {
+		return ids[ref-1]
+	}
+	return ref
+}
+`)
+	return b.String()
+}
+
+// writeSeedRegistryEntry writes the Registry entry of entity.
+func writeSeedRegistryEntry(b *strings.Builder, entity seedEntity) {
+	lower := strings.ToLower(entity.Name)
+	fmt.Fprintf(b, "\t{\n\t\tName: %q,\n", entity.Name)
+	b.WriteString("\t\tInsert: func(c *di.Container, env string, ids map[string][]int) ([]int, error) {\n")
+	fmt.Fprintf(b, "\t\t\tvar items []domain.%s\n", entity.Name)
+	b.WriteString("\t\t\tswitch env {\n")
+	var envs []string
+	for env := range entity.Sets {
+		if env != "" {
+			envs = append(envs, env)
+		}
+	}
+	sort.Strings(envs)
+	for _, env := range envs {
+		fmt.Fprintf(b, "\t\t\tcase %q:\n\t\t\t\titems = domain.%s()\n", env, entity.Sets[env])
+	}
+	var prodOnly []string
+	for _, prod := range seedProductionEnvs {
+		if _, ok := entity.Sets[prod]; !ok {
+			prodOnly = append(prodOnly, fmt.Sprintf("%q", prod))
+		}
+	}
+	if fn, ok := entity.Sets[""]; ok {
+		if len(prodOnly) > 0 {
+			fmt.Fprintf(b, "\t\t\tcase %s:\n\t\t\t\t// Production only seeds the sets declared for it.\n", strings.Join(prodOnly, ", "))
+		}
+		fmt.Fprintf(b, "\t\t\tdefault:\n\t\t\t\titems = domain.%s()\n", fn)
+	}
+	b.WriteString("\t\t\t}\n\n")
+
+	b.WriteString("\t\t\tinserted := make([]int, 0, len(items))\n")
+	b.WriteString("\t\t\tfor i := range items {\n")
+	for _, ref := range entity.References {
+		fmt.Fprintf(b, "\t\t\t\titems[i].%s = %s(resolve(int(items[i].%s), ids[%q]))\n", ref.Field, ref.Type, ref.Field, ref.Entity)
+	}
+	fmt.Fprintf(b, "\t\t\t\tif err := c.%sRepository().Save(&items[i]); err != nil {\n", entity.Name)
+	fmt.Fprintf(b, "\t\t\t\t\treturn inserted, fmt.Errorf(\"seeding %s %%d: %%w\", i+1, err)\n", lower)
+	b.WriteString("\t\t\t\t}\n")
+	b.WriteString("\t\t\t\tinserted = append(inserted, int(items[i].ID))\n")
+	b.WriteString("\t\t\t}\n")
+	b.WriteString("\t\t\treturn inserted, nil\n")
+	b.WriteString("\t\t},\n")
+	b.WriteString("\t\tDelete: func(c *di.Container, id int) error {\n")
+	fmt.Fprintf(b, "\t\t\treturn c.%sRepository().Delete(id)\n", entity.Name)
+	b.WriteString("\t\t},\n")
+	b.WriteString("\t},\n")
+}
+
+// buildSeedMain returns cmd/seed/main.go, or false when the database has no
+// generated openDatabase function.
+func buildSeedMain(importPath, database, sqliteDriver string) (string, bool) {
+	std, external, openDatabase, ok := databaseOpener(database, sqliteDriver)
+	if !ok {
+		return "", false
+	}
+	imports := importLines(append([]string{"encoding/json", "flag", "fmt", "os", "path/filepath"}, std...), external)
+
+	return fmt.Sprintf(`package main
+
+import (
+%s
+	"%s/internal/di"
+	"%s/internal/seed"
+	"%s/pkg/config"
+)
+
+// ledgerDir holds, per environment, the IDs of the seeded rows that -undo
+// deletes.
+const ledgerDir = %q
+
+// main inserts the seed fixtures of an environment, or removes them with
+// -undo. It is run by "goca seed run".
+func main() {
+	env := flag.String("env", "dev", "environment whose fixtures are seeded")
+	undo := flag.Bool("undo", false, "delete the rows seeded for -env")
+	flag.Parse()
+
+	cfg := config.Load()
+	db, err := openDatabase(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: connecting to database: %%v\n", err)
+		os.Exit(1)
+	}
+	container := di.NewContainer(db)
+
+	if *undo {
+		err = undoSeeds(container, *env)
+	} else {
+		err = runSeeds(container, *env)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %%v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runSeeds inserts the fixtures of env and records the IDs of the new rows.
+func runSeeds(container *di.Container, env string) error {
+	path := filepath.Join(ledgerDir, env+".json")
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%%s is already seeded (see %%s); run with -undo first", env, path)
+	}
+
+	ids := make(map[string][]int)
+	for _, entity := range seed.Registry {
+		inserted, err := entity.Insert(container, env, ids)
+		if len(inserted) > 0 {
+			ids[entity.Name] = inserted
+		}
+		if err != nil {
+			// Record the rows inserted so far so -undo removes them.
+			_ = writeLedger(path, ids)
+			return err
+		}
+		fmt.Printf("%%-24s %%d rows\n", entity.Name, len(inserted))
+	}
+	return writeLedger(path, ids)
+}
+
+// undoSeeds deletes the rows recorded for env, in reverse seeding order.
+func undoSeeds(container *di.Container, env string) error {
+	path := filepath.Join(ledgerDir, env+".json")
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("no seed record for %%s: %%w", env, err)
+	}
+	ids := make(map[string][]int)
+	if err := json.Unmarshal(raw, &ids); err != nil {
+		return fmt.Errorf("reading %%s: %%w", path, err)
+	}
+
+	for i := len(seed.Registry) - 1; i >= 0; i-- {
+		entity := seed.Registry[i]
+		rows := ids[entity.Name]
+		for j := len(rows) - 1; j >= 0; j-- {
+			if err := entity.Delete(container, rows[j]); err != nil {
+				// Keep the rows not deleted yet on record.
+				ids[entity.Name] = rows[:j+1]
+				_ = writeLedger(path, ids)
+				return fmt.Errorf("deleting %%s %%d: %%w", entity.Name, rows[j], err)
+			}
+		}
+		if len(rows) > 0 {
+			fmt.Printf("%%-24s %%d rows removed\n", entity.Name, len(rows))
+		}
+		delete(ids, entity.Name)
+	}
+	if len(ids) > 0 {
+		_ = writeLedger(path, ids)
+		return fmt.Errorf("rows of entities no longer in the seed registry are left in %%s", path)
+	}
+	return os.Remove(path)
+}
+
+// writeLedger records ids at path.
+func writeLedger(path string, ids map[string][]int) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(ids, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(raw, '\n'), 0o644)
+}
+
+%s`, imports, importPath, importPath, importPath, seedLedgerDir, openDatabase), true
+}
+
+func init() {
+	seedRunCmd.Flags().String("env", "dev", "Environment whose fixture set is seeded")
+	seedRunCmd.Flags().Bool("undo", false, "Delete the rows seeded for --env instead of inserting")
+	seedRunCmd.Flags().Bool("dry-run", false, "Show the seeding order and generated files without running them")
+
+	seedCmd.AddCommand(seedRunCmd)
+}
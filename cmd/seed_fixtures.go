@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// yamlFixturesDir is where entities write their YAML fixtures, one file per
+// table in the format of github.com/go-testfixtures/testfixtures.
+const yamlFixturesDir = "testdata/fixtures"
+
+// generateYAMLFixtures writes testdata/fixtures/<table>.yml with the records
+// of the SQL seeds. Fixtures carry explicit IDs 1 to 3, so <Entity>ID
+// references point at the fixture of the same position, as in goca seed run.
+func generateYAMLFixtures(entityName string, fields []Field, sm ...*SafetyManager) {
+	table := entityTableName(entityName)
+	filename := filepath.Join(yamlFixturesDir, table+".yml")
+
+	var content strings.Builder
+	fmt.Fprintf(&content, "# Fixtures for table %s, loaded by loadFixtures in integration tests.\n", table)
+	for i := 1; i <= 3; i++ {
+		writeYAMLFixtureRecord(&content, fields, i)
+	}
+
+	if err := writeFile(filename, content.String(), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing fixture file: %v", err))
+	}
+}
+
+// writeYAMLFixtureRecord writes a single fixture record: the ID, the columns
+// of the SQL seeds and the system columns that cannot be left NULL.
+func writeYAMLFixtureRecord(content *strings.Builder, fields []Field, recordNum int) {
+	fmt.Fprintf(content, "- id: %d\n", recordNum)
+
+	columns := getNonSystemFieldNames(fields)
+	values := getSQLFieldValues(fields, recordNum)
+	for i, column := range columns {
+		fmt.Fprintf(content, "  %s: %s\n", column, yamlFixtureValue(values[i]))
+	}
+
+	for _, field := range fields {
+		switch field.Name {
+		case versionField.Name:
+			fmt.Fprintf(content, "  %s: 1\n", fieldColumn(field))
+		case tenantField.Name:
+			fmt.Fprintf(content, "  %s: \"tenant-1\"\n", fieldColumn(field))
+		case StringCreatedAt, "UpdatedAt":
+			fmt.Fprintf(content, "  %s: RAW=CURRENT_TIMESTAMP\n", fieldColumn(field))
+		}
+	}
+}
+
+// yamlFixtureValue converts a SQL seed literal to a YAML scalar: quoted
+// strings become double-quoted YAML strings and NOW() is inserted raw.
+func yamlFixtureValue(sqlValue string) string {
+	switch {
+	case sqlValue == "NOW()":
+		return "RAW=CURRENT_TIMESTAMP"
+	case len(sqlValue) >= 2 && strings.HasPrefix(sqlValue, "'") && strings.HasSuffix(sqlValue, "'"):
+		return strconv.Quote(strings.ReplaceAll(sqlValue[1:len(sqlValue)-1], "''", "'"))
+	}
+	return sqlValue
+}
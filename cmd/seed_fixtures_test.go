@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerateSeedData_YAMLFixtures(t *testing.T) {
+	chdirTemp(t)
+	sm := NewSafetyManager(false, true, false)
+
+	require.NoError(t, generateEntity("Order", "customerID:uint,status:string,total:float64,address:struct{city:string}", false, false, true, false, true, false, false, false, "lowercase", sm))
+
+	content, err := os.ReadFile(filepath.Join("testdata", "fixtures", "orders.yml"))
+	require.NoError(t, err)
+	var records []map[string]any
+	require.NoError(t, yaml.Unmarshal(content, &records), string(content))
+	require.Len(t, records, 3)
+
+	second := records[1]
+	assert.Equal(t, 2, second["id"])
+	assert.Equal(t, 2, second["customer_id"])
+	assert.Equal(t, "pending", second["status"])
+	assert.Equal(t, "Sample City 2", second["address_city"])
+	assert.Equal(t, 1, second["version"])
+	assert.Equal(t, "RAW=CURRENT_TIMESTAMP", second["created_at"])
+	assert.NotContains(t, second, "deleted_at")
+}
+
+func TestYAMLFixtureValue(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, `"it's"`, yamlFixtureValue("'it''s'"))
+	assert.Equal(t, `"{\"sample\": 1}"`, yamlFixtureValue(`'{"sample": 1}'`))
+	assert.Equal(t, "RAW=CURRENT_TIMESTAMP", yamlFixtureValue("NOW()"))
+	assert.Equal(t, "99.99", yamlFixtureValue("99.99"))
+	assert.Equal(t, "true", yamlFixtureValue("true"))
+}
+
+func TestGenerateHelpersContent_LoadFixtures(t *testing.T) {
+	chdirTemp(t)
+
+	content := generateHelpersContent("postgres", false, "Order")
+	_, err := parser.ParseFile(token.NewFileSet(), "helpers.go", content, parser.ParseComments)
+	require.NoError(t, err, content)
+	assert.Contains(t, content, `"github.com/go-testfixtures/testfixtures/v3"`)
+	assert.Contains(t, content, "func loadFixtures(t *testing.T, db *gorm.DB, files ...string) {")
+	assert.Contains(t, content, "testfixtures.Dialect(db.Dialector.Name())")
+	assert.Contains(t, content, `loadFixtures(t, db, "orders.yml")`)
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverSeedEntities(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "order.go", "package domain\n\ntype Order struct {\n\tID         uint\n\tCustomerID uint\n\tParentID   *uint\n\tTotal      float64\n}\n")
+	writeTestFile(t, dir, "order_seeds.go", "package domain\n\nfunc GetOrderSeeds() []Order { return nil }\n\nfunc GetSQLOrderSeeds() string { return \"\" }\n")
+	writeTestFile(t, dir, "customer.go", "package domain\n\ntype Customer struct {\n\tID    uint\n\tOrderID uint\n}\n")
+	writeTestFile(t, dir, "customer_seeds.go", "package domain\n\nfunc GetCustomerSeeds() []Customer { return nil }\n\nfunc GetCustomerSeedsStaging() []Customer { return nil }\n")
+
+	entities, err := discoverSeedEntities(dir)
+	require.NoError(t, err)
+	require.Len(t, entities, 2)
+
+	customer, order := entities[0], entities[1]
+	assert.Equal(t, map[string]string{"": "GetCustomerSeeds", "staging": "GetCustomerSeedsStaging"}, customer.Sets)
+	assert.Equal(t, map[string]string{"": "GetOrderSeeds"}, order.Sets)
+	assert.Equal(t, []seedReference{{Field: "CustomerID", Entity: "Customer", Type: "uint"}}, order.References)
+
+	assert.Equal(t, "GetCustomerSeedsStaging", customer.setFor("staging"))
+	assert.Equal(t, "GetCustomerSeeds", customer.setFor("test"))
+	assert.Empty(t, customer.setFor("prod"))
+
+	// Customer also references Order, so the two cannot be ordered.
+	_, err = orderSeedEntities(entities)
+	assert.ErrorContains(t, err, "cycle")
+
+	customer.References = nil
+	ordered, err := orderSeedEntities([]seedEntity{order, customer})
+	require.NoError(t, err)
+	assert.Equal(t, "Customer", ordered[0].Name)
+	assert.Equal(t, "Order", ordered[1].Name)
+}
+
+func TestFilterSeedableEntities(t *testing.T) {
+	defer ensureTestUI(t)()
+	dir := t.TempDir()
+	writeTestFile(t, dir, "container.go", "package di\n\nfunc (c *Container) OrderRepository() repository.OrderRepository {\n\treturn c.orderRepo\n}\n")
+
+	entities := filterSeedableEntities([]seedEntity{
+		{Name: "Customer"},
+		{Name: "Order", References: []seedReference{{Field: "CustomerID", Entity: "Customer", Type: "uint"}}},
+	}, containerRepositoryGetters(dir))
+	require.Len(t, entities, 1)
+	assert.Equal(t, "Order", entities[0].Name)
+	assert.Empty(t, entities[0].References)
+}
+
+func TestBuildSeedRegistry(t *testing.T) {
+	content := buildSeedRegistry("example.com/shop", []seedEntity{
+		{Name: "Customer", Sets: map[string]string{"": "GetCustomerSeeds", "prod": "GetCustomerSeedsProd"}},
+		{Name: "Order", Sets: map[string]string{"": "GetOrderSeeds"}, References: []seedReference{{Field: "CustomerID", Entity: "Customer", Type: "uint"}}},
+	})
+
+	assert.Contains(t, content, `case "prod":`+"\n\t\t\t\titems = domain.GetCustomerSeedsProd()")
+	assert.Contains(t, content, `case "production":`)
+	assert.Contains(t, content, `case "prod", "production":`)
+	assert.Contains(t, content, `items[i].CustomerID = uint(resolve(int(items[i].CustomerID), ids["Customer"]))`)
+	assert.Contains(t, content, "c.OrderRepository().Save(&items[i])")
+	assert.Contains(t, content, "return c.CustomerRepository().Delete(id)")
+	assert.Less(t, strings.Index(content, `Name: "Customer"`), strings.Index(content, `Name: "Order"`))
+}
+
+func TestBuildSeedMain(t *testing.T) {
+	content, ok := buildSeedMain("example.com/shop", DBPostgres, "")
+	require.True(t, ok)
+	assert.Contains(t, content, `"gorm.io/driver/postgres"`)
+	assert.Contains(t, content, `const ledgerDir = ".goca/seeds"`)
+	assert.Contains(t, content, "container := di.NewContainer(db)")
+
+	_, ok = buildSeedMain("example.com/shop", DBDynamoDB, "")
+	assert.False(t, ok)
+}
+
+func TestGenerateSeedRunnerKeepsMain(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	main := filepath.Join("cmd", "seed", "main.go")
+	writeTestFile(t, ".", main, "package main\n\n// custom\nfunc main() {}\n")
+
+	require.NoError(t, generateSeedRunner([]seedEntity{{Name: "Order", Sets: map[string]string{"": "GetOrderSeeds"}}}, NewSafetyManager(false, true, false)))
+	assert.FileExists(t, filepath.Join(DirInternal, "seed", "registry.go"))
+	content, err := os.ReadFile(main)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "// custom")
+}
+
+func TestValidateSeedEnv(t *testing.T) {
+	assert.NoError(t, validateSeedEnv("staging"))
+	assert.Error(t, validateSeedEnv(""))
+	assert.Error(t, validateSeedEnv("../prod"))
+}
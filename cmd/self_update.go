@@ -0,0 +1,335 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// ReleasePublicKey is the base64 Ed25519 public key that signs the
+	// checksums.txt of each release, injected at compile time through
+	// -ldflags. Builds without it verify checksums only.
+	ReleasePublicKey = ""
+
+	// releaseAPIURL is the GitHub API endpoint of the goca repository.
+	releaseAPIURL = "https://api.github.com/repos/sazardev/goca"
+)
+
+// Release assets self-update reads besides the binary.
+const (
+	releaseChecksumsAsset = "checksums.txt"
+	releaseSignatureAsset = "checksums.txt.sig"
+)
+
+// maxReleaseDownload bounds the size of a downloaded release asset.
+const maxReleaseDownload = 256 << 20
+
+// githubRelease is the part of a GitHub release self-update uses.
+type githubRelease struct {
+	TagName string         `json:"tag_name"`
+	Assets  []releaseAsset `json:"assets"`
+}
+
+// releaseAsset is a file attached to a GitHub release.
+type releaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update goca to the latest release",
+	Long: `Checks the GitHub releases of goca and replaces the running binary with the
+latest one, or with the release given by --version.
+
+The download is verified against the release's checksums.txt, whose Ed25519
+signature is checked with the release key built into official binaries.
+Installations managed by Homebrew, Scoop or a system package are left to that
+package manager unless --force is given.
+
+Examples:
+  goca self-update
+  goca self-update --check
+  goca self-update --version v1.14.0`,
+	Args: cobra.NoArgs,
+	RunE: runSelfUpdate,
+}
+
+func init() {
+	selfUpdateCmd.Flags().Bool("check", false, "Only report whether a newer release is available")
+	selfUpdateCmd.Flags().String("version", "", "Install this release instead of the latest, e.g. v1.14.0")
+	selfUpdateCmd.Flags().Bool("force", false, "Reinstall the release and update package-managed installations")
+}
+
+func runSelfUpdate(cmd *cobra.Command, _ []string) error {
+	check, _ := cmd.Flags().GetBool("check")
+	version, _ := cmd.Flags().GetString("version")
+	force, _ := cmd.Flags().GetBool("force")
+
+	ui.Header("Goca Self-Update")
+	release, err := fetchRelease(version)
+	if err != nil {
+		return err
+	}
+	available := strings.TrimPrefix(release.TagName, "v")
+	ui.KeyValue("Installed", Version)
+	ui.KeyValue("Available", available)
+
+	// A pinned --version may downgrade; otherwise only newer releases install.
+	upToDate := available == Version
+	if version == "" && Version != "dev" {
+		upToDate = compareVersions(available, Version) <= 0
+	}
+	if upToDate && !force {
+		ui.Success("goca is up to date")
+		return nil
+	}
+	if check {
+		ui.Info(fmt.Sprintf("Run 'goca self-update' to install %s", release.TagName))
+		return nil
+	}
+
+	target, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot locate the goca binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(target); err == nil {
+		target = resolved
+	}
+	if manager, command := packageManager(target); manager != "" && !force {
+		return fmt.Errorf("goca was installed with %s; update it with %s or pass --force", manager, command)
+	}
+
+	if err := installRelease(release, target); err != nil {
+		return err
+	}
+	ui.Success(fmt.Sprintf("Updated goca %s to %s", Version, available))
+	return nil
+}
+
+// fetchRelease returns the latest release, or the release tagged version.
+func fetchRelease(version string) (*githubRelease, error) {
+	url := releaseAPIURL + "/releases/latest"
+	if version != "" {
+		url = releaseAPIURL + "/releases/tags/v" + strings.TrimPrefix(version, "v")
+	}
+	body, err := downloadReleaseFile(url)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch release information: %w", err)
+	}
+	var release githubRelease
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("invalid release information: %w", err)
+	}
+	if release.TagName == "" {
+		return nil, errors.New("invalid release information: missing tag")
+	}
+	return &release, nil
+}
+
+// installRelease downloads the release binary for this platform, verifies it
+// and replaces target with it.
+func installRelease(release *githubRelease, target string) error {
+	name := releaseBinaryName(runtime.GOOS, runtime.GOARCH)
+	assets := make(map[string]string)
+	for _, asset := range release.Assets {
+		assets[asset.Name] = asset.URL
+	}
+	if assets[name] == "" {
+		return fmt.Errorf("release %s has no binary for %s/%s", release.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+	if assets[releaseChecksumsAsset] == "" {
+		return fmt.Errorf("release %s has no %s", release.TagName, releaseChecksumsAsset)
+	}
+
+	checksums, err := downloadReleaseFile(assets[releaseChecksumsAsset])
+	if err != nil {
+		return fmt.Errorf("cannot download %s: %w", releaseChecksumsAsset, err)
+	}
+	if ReleasePublicKey == "" {
+		ui.Warning("This build has no release signing key; only the checksum is verified")
+	} else {
+		if assets[releaseSignatureAsset] == "" {
+			return fmt.Errorf("release %s is not signed", release.TagName)
+		}
+		signature, err := downloadReleaseFile(assets[releaseSignatureAsset])
+		if err != nil {
+			return fmt.Errorf("cannot download %s: %w", releaseSignatureAsset, err)
+		}
+		if err := verifyChecksumsSignature(checksums, signature, ReleasePublicKey); err != nil {
+			return err
+		}
+	}
+	want, err := releaseChecksum(checksums, name)
+	if err != nil {
+		return err
+	}
+
+	binary, err := downloadReleaseFile(assets[name])
+	if err != nil {
+		return fmt.Errorf("cannot download %s: %w", name, err)
+	}
+	if got := sha256.Sum256(binary); hex.EncodeToString(got[:]) != want {
+		return fmt.Errorf("checksum mismatch for %s", name)
+	}
+
+	// Write next to the binary so the final rename stays on one filesystem.
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".goca-update-*")
+	if err != nil {
+		return fmt.Errorf("cannot write to %s (try again with elevated permissions): %w", filepath.Dir(target), err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil { //#nosec G302 // the binary must stay executable
+		return err
+	}
+	return replaceExecutable(target, tmp.Name())
+}
+
+// downloadReleaseFile returns the body of a GitHub API or download URL.
+// GITHUB_TOKEN, when set, lifts the API's anonymous rate limit.
+func downloadReleaseFile(url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "goca/"+Version)
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" && strings.HasPrefix(url, releaseAPIURL) {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Minute}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxReleaseDownload))
+}
+
+// releaseBinaryName returns the name of the release binary for a platform.
+func releaseBinaryName(goos, goarch string) string {
+	name := "goca-" + goos + "-" + goarch
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// releaseChecksum returns the SHA-256 of name listed in a sha256sum-style
+// checksums file.
+func releaseChecksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s does not list %s", releaseChecksumsAsset, name)
+}
+
+// verifyChecksumsSignature checks the base64 Ed25519 signature of a checksums
+// file against a base64 public key.
+func verifyChecksumsSignature(checksums, signature []byte, publicKey string) error {
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return errors.New("invalid release signing key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil || !ed25519.Verify(key, checksums, sig) {
+		return fmt.Errorf("signature of %s does not match the release signing key", releaseChecksumsAsset)
+	}
+	return nil
+}
+
+// replaceExecutable moves newBinary over target. Windows cannot overwrite a
+// running executable, but it can rename it out of the way.
+func replaceExecutable(target, newBinary string) error {
+	if runtime.GOOS != "windows" {
+		return os.Rename(newBinary, target)
+	}
+	old := target + ".old"
+	_ = os.Remove(old)
+	if err := os.Rename(target, old); err != nil {
+		return err
+	}
+	if err := os.Rename(newBinary, target); err != nil {
+		_ = os.Rename(old, target)
+		return err
+	}
+	return nil
+}
+
+// packageManager returns the package manager that installed the binary at
+// path and the command that updates it, or empty strings.
+func packageManager(path string) (manager, command string) {
+	path = strings.ReplaceAll(path, `\`, "/")
+	switch {
+	case strings.Contains(path, "/Cellar/") || strings.Contains(path, "/homebrew/"):
+		return "Homebrew", "'brew upgrade goca'"
+	case strings.Contains(strings.ToLower(path), "/scoop/"):
+		return "Scoop", "'scoop update goca'"
+	case strings.HasPrefix(path, "/usr/bin/"):
+		return "a system package", "'apt upgrade goca' or 'dnf upgrade goca'"
+	}
+	return "", ""
+}
+
+// compareVersions compares two dotted versions numerically, returning -1, 0
+// or 1. A pre-release (1.2.0-rc.1) sorts before its release.
+func compareVersions(a, b string) int {
+	a, aPre, _ := strings.Cut(strings.TrimPrefix(a, "v"), "-")
+	b, bPre, _ := strings.Cut(strings.TrimPrefix(b, "v"), "-")
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return strings.Compare(aPre, bPre)
+}
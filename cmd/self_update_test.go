@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveRelease serves a v9.9.9 release whose binary is content and returns
+// the release fetched from it.
+func serveRelease(t *testing.T, content []byte, privateKey ed25519.PrivateKey, tamper bool) *githubRelease {
+	t.Helper()
+	name := releaseBinaryName(runtime.GOOS, runtime.GOARCH)
+	sum := sha256.Sum256(content)
+	checksums := []byte(hex.EncodeToString(sum[:]) + "  " + name + "\n")
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, checksums))
+	if tamper {
+		content = append(content, '!')
+	}
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	mux.HandleFunc("/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(githubRelease{TagName: "v9.9.9", Assets: []releaseAsset{
+			{Name: name, URL: server.URL + "/download/" + name},
+			{Name: releaseChecksumsAsset, URL: server.URL + "/download/" + releaseChecksumsAsset},
+			{Name: releaseSignatureAsset, URL: server.URL + "/download/" + releaseSignatureAsset},
+		}})
+	})
+	mux.HandleFunc("/download/"+name, func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write(content) })
+	mux.HandleFunc("/download/"+releaseChecksumsAsset, func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write(checksums) })
+	mux.HandleFunc("/download/"+releaseSignatureAsset, func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte(signature)) })
+
+	origURL := releaseAPIURL
+	releaseAPIURL = server.URL
+	t.Cleanup(func() { releaseAPIURL = origURL })
+
+	release, err := fetchRelease("")
+	require.NoError(t, err)
+	return release
+}
+
+func TestInstallRelease(t *testing.T) {
+	defer ensureTestUI(t)()
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	origKey := ReleasePublicKey
+	ReleasePublicKey = base64.StdEncoding.EncodeToString(publicKey)
+	defer func() { ReleasePublicKey = origKey }()
+
+	target := filepath.Join(t.TempDir(), "goca")
+	require.NoError(t, os.WriteFile(target, []byte("old"), 0o755))
+
+	t.Run("verified", func(t *testing.T) {
+		release := serveRelease(t, []byte("new"), privateKey, false)
+		assert.Equal(t, "v9.9.9", release.TagName)
+		require.NoError(t, installRelease(release, target))
+		data, _ := os.ReadFile(target)
+		assert.Equal(t, "new", string(data))
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		release := serveRelease(t, []byte("evil"), privateKey, true)
+		assert.ErrorContains(t, installRelease(release, target), "checksum mismatch")
+		data, _ := os.ReadFile(target)
+		assert.Equal(t, "new", string(data))
+	})
+
+	t.Run("wrong signing key", func(t *testing.T) {
+		_, otherKey, _ := ed25519.GenerateKey(nil)
+		release := serveRelease(t, []byte("evil"), otherKey, false)
+		assert.ErrorContains(t, installRelease(release, target), "does not match the release signing key")
+	})
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, 1, compareVersions("1.10.0", "1.9.3"))
+	assert.Equal(t, 0, compareVersions("v1.2", "1.2.0"))
+	assert.Equal(t, -1, compareVersions("1.2.0-rc.1", "1.2.0"))
+	assert.Equal(t, -1, compareVersions("1.2.0", "2.0.0"))
+}
+
+func TestPackageManager(t *testing.T) {
+	manager, _ := packageManager("/opt/homebrew/Cellar/goca/1.0.0/bin/goca")
+	assert.Equal(t, "Homebrew", manager)
+	manager, _ = packageManager(`C:\Users\dev\scoop\apps\goca\current\goca.exe`)
+	assert.Equal(t, "Scoop", manager)
+	manager, _ = packageManager("/usr/bin/goca")
+	assert.Equal(t, "a system package", manager)
+	manager, _ = packageManager("/home/dev/go/bin/goca")
+	assert.Empty(t, manager)
+}
@@ -0,0 +1,469 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// errSelfTestFailed is returned by goca self-test when a combination does not
+// compile.
+var errSelfTestFailed = errors.New("generated code failed to compile")
+
+var selfTestCmd = &cobra.Command{
+	Use:   "self-test",
+	Short: "Compile-check the code goca generates for every database, handler and option",
+	Long: `Generates a project for every combination of database, handler type and
+feature option into a temporary directory with this goca binary, then runs
+go mod tidy, go build ./... and go vet ./... on it, and prints a pass/fail
+matrix. Run it before adopting a new release to check that the code it
+generates for your stack compiles.
+
+Each database gets a project from goca init; each combination copies it and
+adds a Product feature with goca feature. Combinations the generators reject,
+such as --audit on MongoDB, are reported as n/a. go mod tidy needs the module
+proxy unless the module cache already has the dependencies.`,
+	Example: `  goca self-test
+  goca self-test --databases postgres,mongodb --handlers http,grpc
+  goca self-test --options plain,validation --parallel 4 --keep`,
+	RunE: runSelfTest,
+}
+
+func init() {
+	selfTestCmd.Flags().String("databases", "", "Databases to test (comma-separated, default all: "+strings.Join(ValidDatabases, ", ")+")")
+	selfTestCmd.Flags().String("handlers", "", "Handler types to test (comma-separated, default all: "+strings.Join(ValidHandlers, ", ")+")")
+	selfTestCmd.Flags().String("options", "", "Feature options to test (comma-separated, default all: "+strings.Join(selfTestOptionNames(), ", ")+")")
+	selfTestCmd.Flags().Int("parallel", 0, "Combinations checked at once (default: the number of CPUs)")
+	selfTestCmd.Flags().Bool("keep", false, "Keep the generated projects instead of deleting them")
+}
+
+// selfTestOption is a set of goca feature flags checked for every database
+// and handler.
+type selfTestOption struct {
+	name string
+	args []string
+	// supports reports why the generators reject the option for a database.
+	supports func(database string) error
+}
+
+// selfTestOptions are the feature options goca self-test checks.
+var selfTestOptions = []selfTestOption{
+	{name: "plain"},
+	{name: "validation", args: []string{"--validation"}},
+	{name: "business-rules", args: []string{"--business-rules"}},
+	{name: "versioned", args: []string{"--versioned"}},
+	{name: "audit", args: []string{"--audit"}, supports: func(database string) error {
+		if !isGormDatabase(database) {
+			return fmt.Errorf("--audit does not support %s", database)
+		}
+		return nil
+	}},
+	{name: "multi-tenant", args: []string{"--multi-tenant"}, supports: func(database string) error {
+		return validateMultiTenant(database, TenantIsolationColumn, false, false)
+	}},
+	{name: "cache", args: []string{"--cache"}},
+	{name: "resilience", args: []string{"--resilience"}},
+	{name: "tracing", args: []string{"--tracing"}},
+	{name: "cqrs", args: []string{"--cqrs"}, supports: func(database string) error {
+		return validateCQRS(database, false)
+	}},
+	{name: "di-fx", args: []string{"--di", DIFx}},
+	{name: "di-modular", args: []string{"--di", DIModular}},
+}
+
+// selfTestOptionNames returns the names of selfTestOptions.
+func selfTestOptionNames() []string {
+	names := make([]string, len(selfTestOptions))
+	for i, o := range selfTestOptions {
+		names[i] = o.name
+	}
+	return names
+}
+
+// Stages of a self-test combination, in order.
+const (
+	selfTestStageInit     = "init"
+	selfTestStageGenerate = "generate"
+	selfTestStageTidy     = "tidy"
+	selfTestStageBuild    = "build"
+	selfTestStageVet      = "vet"
+)
+
+// selfTestFeatureFields are the fields of the feature every combination
+// generates, one of each common kind.
+const selfTestFeatureFields = "name:string,price:float64,stock:int,active:bool"
+
+// selfTestErrorWidth is the width of the error column of the failures table.
+const selfTestErrorWidth = 100
+
+// selfTestCase is one combination of database, handler and option.
+type selfTestCase struct {
+	database string
+	handler  string
+	option   selfTestOption
+}
+
+func (c selfTestCase) dirName() string {
+	return c.database + "-" + c.handler + "-" + c.option.name
+}
+
+// selfTestResult is the outcome of a combination: skipped with a reason,
+// passed, or failed at a stage with the output of its command.
+type selfTestResult struct {
+	selfTestCase
+	skipped  string
+	stage    string
+	output   string
+	duration time.Duration
+}
+
+func (r selfTestResult) failed() bool {
+	return r.stage != ""
+}
+
+// selfTestRunner runs a command in dir and returns its combined output.
+type selfTestRunner func(dir, name string, args ...string) (string, error)
+
+// runSelfTestCommand is the selfTestRunner of goca self-test.
+func runSelfTestCommand(dir, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "NO_COLOR=1")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	return out.String(), err
+}
+
+func runSelfTest(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	databasesFlag, _ := cmd.Flags().GetString("databases")
+	handlersFlag, _ := cmd.Flags().GetString("handlers")
+	optionsFlag, _ := cmd.Flags().GetString("options")
+	parallel, _ := cmd.Flags().GetInt("parallel")
+	keep, _ := cmd.Flags().GetBool("keep")
+
+	cases, err := selfTestCases(databasesFlag, handlersFlag, optionsFlag)
+	if err != nil {
+		return err
+	}
+	goca, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("could not locate the goca binary: %w", err)
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		return errors.New("goca self-test needs the go toolchain on PATH")
+	}
+	root, err := os.MkdirTemp("", "goca-self-test-")
+	if err != nil {
+		return err
+	}
+	if !keep {
+		defer os.RemoveAll(root)
+	}
+	if parallel <= 0 {
+		parallel = runtime.NumCPU()
+	}
+
+	ui.Header("Goca Self-Test")
+	ui.KeyValue("Version", Version)
+	ui.KeyValue("Combinations", strconv.Itoa(len(cases)))
+	ui.KeyValue("Workers", strconv.Itoa(parallel))
+	ui.KeyValue("Directory", root)
+	ui.Blank()
+
+	start := time.Now()
+	results := checkSelfTestCases(root, goca, cases, parallel, runSelfTestCommand)
+	printSelfTestMatrix(results)
+	failed := printSelfTestFailures(results, root, keep)
+	ui.Blank()
+
+	if failed > 0 {
+		ui.Error(fmt.Sprintf("%d of %d combinations failed to compile (%s)", failed, len(results), time.Since(start).Round(time.Second)))
+		return errSelfTestFailed
+	}
+	ui.Success(fmt.Sprintf("Every combination compiles and passes go vet (%s)", time.Since(start).Round(time.Second)))
+	return nil
+}
+
+// selfTestCases returns the combinations of the databases, handlers and
+// options selected by the comma-separated flags, all of them when a flag is
+// empty.
+func selfTestCases(databasesFlag, handlersFlag, optionsFlag string) ([]selfTestCase, error) {
+	databases, err := selectSelfTestValues("database", databasesFlag, ValidDatabases)
+	if err != nil {
+		return nil, err
+	}
+	handlers, err := selectSelfTestValues("handler", handlersFlag, ValidHandlers)
+	if err != nil {
+		return nil, err
+	}
+	optionNames, err := selectSelfTestValues("option", optionsFlag, selfTestOptionNames())
+	if err != nil {
+		return nil, err
+	}
+
+	var cases []selfTestCase
+	for _, database := range databases {
+		for _, handler := range handlers {
+			for _, option := range selfTestOptions {
+				if slices.Contains(optionNames, option.name) {
+					cases = append(cases, selfTestCase{database: database, handler: handler, option: option})
+				}
+			}
+		}
+	}
+	return cases, nil
+}
+
+// selectSelfTestValues splits a comma-separated flag and checks its values
+// against valid.
+func selectSelfTestValues(kind, flag string, valid []string) ([]string, error) {
+	if strings.TrimSpace(flag) == "" {
+		return valid, nil
+	}
+	var values []string
+	for _, v := range strings.Split(flag, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if !slices.Contains(valid, v) {
+			return nil, fmt.Errorf("unknown %s %q; use one of: %s", kind, v, strings.Join(valid, ", "))
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// checkSelfTestCases initializes a project per database under root, checks
+// every case on a copy of it with up to parallel workers, and returns the
+// results in the order of cases.
+func checkSelfTestCases(root, goca string, cases []selfTestCase, parallel int, run selfTestRunner) []selfTestResult {
+	bases := make(map[string]string)
+	baseErrors := make(map[string]string)
+	for _, c := range cases {
+		if _, done := bases[c.database]; done {
+			continue
+		}
+		base := filepath.Join(root, "base-"+c.database)
+		bases[c.database] = base
+		out, err := run(root, goca, "init", filepath.Base(base), "--module", "example.com/selftest", "--database", c.database, "--no-interactive")
+		if err != nil {
+			baseErrors[c.database] = strings.TrimSpace(out + "\n" + err.Error())
+		}
+	}
+
+	results := make([]selfTestResult, len(cases))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var progress sync.Mutex
+	for range min(parallel, max(len(cases), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				c := cases[i]
+				if out, failed := baseErrors[c.database]; failed {
+					results[i] = selfTestResult{selfTestCase: c, stage: selfTestStageInit, output: out}
+					continue
+				}
+				results[i] = checkSelfTestCase(root, bases[c.database], goca, c, run)
+				progress.Lock()
+				ui.Dim(fmt.Sprintf("   %s %s", selfTestStatus(results[i]), c.dirName()))
+				progress.Unlock()
+			}
+		}()
+	}
+	for i := range cases {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// checkSelfTestCase generates the feature of a case into a copy of the base
+// project and compiles it, stopping at the first stage that fails.
+func checkSelfTestCase(root, base, goca string, c selfTestCase, run selfTestRunner) selfTestResult {
+	result := selfTestResult{selfTestCase: c}
+	if c.option.supports != nil {
+		if err := c.option.supports(c.database); err != nil {
+			result.skipped = err.Error()
+			return result
+		}
+	}
+
+	start := time.Now()
+	defer func() { result.duration = time.Since(start) }()
+	dir := filepath.Join(root, c.dirName())
+	if err := copySelfTestProject(base, dir); err != nil {
+		result.stage, result.output = selfTestStageInit, err.Error()
+		return result
+	}
+
+	feature := append([]string{"feature", "Product", "--fields", selfTestFeatureFields, "--handlers", c.handler, "--no-interactive"}, c.option.args...)
+	stages := []struct {
+		name string
+		args []string
+	}{
+		{selfTestStageGenerate, append([]string{goca}, feature...)},
+		{selfTestStageTidy, []string{"go", "mod", "tidy"}},
+		{selfTestStageBuild, []string{"go", "build", "./..."}},
+		{selfTestStageVet, []string{"go", "vet", "./..."}},
+	}
+	for _, stage := range stages {
+		if out, err := run(dir, stage.args[0], stage.args[1:]...); err != nil {
+			result.stage, result.output = stage.name, strings.TrimSpace(out)
+			if result.output == "" {
+				result.output = err.Error()
+			}
+			return result
+		}
+	}
+	return result
+}
+
+// copySelfTestProject copies the base project of a database to dir.
+func copySelfTestProject(base, dir string) error {
+	return filepath.WalkDir(base, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, info.Mode().Perm())
+	})
+}
+
+// selfTestStatus returns the mark of a result in the progress and matrix.
+func selfTestStatus(r selfTestResult) string {
+	switch {
+	case r.skipped != "":
+		return "-"
+	case r.failed():
+		return "✗"
+	}
+	return "✓"
+}
+
+// printSelfTestMatrix prints a database × handler matrix with the combinations
+// of options that passed in each cell.
+func printSelfTestMatrix(results []selfTestResult) {
+	var databases, handlers []string
+	type cell struct{ passed, checked int }
+	cells := make(map[string]*cell)
+	for _, r := range results {
+		if !slices.Contains(databases, r.database) {
+			databases = append(databases, r.database)
+		}
+		if !slices.Contains(handlers, r.handler) {
+			handlers = append(handlers, r.handler)
+		}
+		key := r.database + "\x00" + r.handler
+		if cells[key] == nil {
+			cells[key] = &cell{}
+		}
+		if r.skipped != "" {
+			continue
+		}
+		cells[key].checked++
+		if !r.failed() {
+			cells[key].passed++
+		}
+	}
+
+	rows := make([][]string, 0, len(databases))
+	for _, database := range databases {
+		row := []string{database}
+		for _, handler := range handlers {
+			c := cells[database+"\x00"+handler]
+			switch {
+			case c == nil || c.checked == 0:
+				row = append(row, "n/a")
+			case c.passed == c.checked:
+				row = append(row, fmt.Sprintf("✓ %d/%d", c.passed, c.checked))
+			default:
+				row = append(row, fmt.Sprintf("✗ %d/%d", c.passed, c.checked))
+			}
+		}
+		rows = append(rows, row)
+	}
+	ui.Blank()
+	ui.Table(append([]string{"Database"}, handlers...), rows)
+}
+
+// printSelfTestFailures lists the failed combinations with the stage they
+// failed at and the first error line, or the whole output with --verbose, and
+// returns their number.
+func printSelfTestFailures(results []selfTestResult, root string, keep bool) int {
+	var rows [][]string
+	for _, r := range results {
+		if !r.failed() {
+			continue
+		}
+		rows = append(rows, []string{r.database, r.handler, r.option.name, r.stage, selfTestErrorLine(r.output)})
+		if verboseMode {
+			ui.Blank()
+			ui.Section(r.dirName())
+			ui.Println(r.output)
+		}
+	}
+	if len(rows) == 0 {
+		return 0
+	}
+	ui.Blank()
+	ui.Section("Failures")
+	ui.Table([]string{"Database", "Handler", "Option", "Stage", "Error"}, rows)
+	if keep {
+		ui.Dim(fmt.Sprintf("  The projects are kept in %s/<database>-<handler>-<option>", root))
+	} else {
+		ui.Dim("  Rerun with --keep to inspect the projects, or --verbose for the full output")
+	}
+	return len(rows)
+}
+
+// selfTestErrorLine returns the first compiler or goca error of output, or
+// its last line, shortened to fit the failures table.
+func selfTestErrorLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	line := strings.TrimSpace(lines[len(lines)-1])
+	for _, l := range lines {
+		l = strings.TrimSpace(l)
+		if strings.Contains(l, ".go:") || strings.HasPrefix(l, "✗") || strings.HasPrefix(l, "Error:") {
+			line = l
+			break
+		}
+	}
+	if runes := []rune(line); len(runes) > selfTestErrorWidth {
+		line = string(runes[:selfTestErrorWidth-1]) + "…"
+	}
+	return line
+}
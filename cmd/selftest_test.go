@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfTestCases(t *testing.T) {
+	cases, err := selfTestCases("", "", "")
+	require.NoError(t, err)
+	assert.Len(t, cases, len(ValidDatabases)*len(ValidHandlers)*len(selfTestOptions))
+
+	cases, err = selfTestCases("postgres, mongodb", "grpc", "cqrs,plain")
+	require.NoError(t, err)
+	var names []string
+	for _, c := range cases {
+		names = append(names, c.dirName())
+	}
+	assert.Equal(t, []string{"postgres-grpc-plain", "postgres-grpc-cqrs", "mongodb-grpc-plain", "mongodb-grpc-cqrs"}, names)
+
+	_, err = selfTestCases("cassandra", "", "")
+	assert.ErrorContains(t, err, `unknown database "cassandra"`)
+	_, err = selfTestCases("", "", "graphql")
+	assert.ErrorContains(t, err, `unknown option "graphql"`)
+}
+
+func TestCheckSelfTestCases(t *testing.T) {
+	defer setupDiscardUI(t)()
+	root := t.TempDir()
+
+	var mu sync.Mutex
+	var commands []string
+	run := func(dir, name string, args ...string) (string, error) {
+		command := strings.Join(append([]string{filepath.Base(name)}, args...), " ")
+		mu.Lock()
+		commands = append(commands, filepath.Base(dir)+": "+command)
+		mu.Unlock()
+		switch {
+		case args[0] == "init":
+			return "", os.MkdirAll(filepath.Join(dir, args[1], "internal"), 0o755)
+		case strings.Contains(command, "--cqrs") && filepath.Base(dir) == "postgres-http-cqrs":
+			return "", os.WriteFile(filepath.Join(dir, "cqrs.go"), nil, 0o644)
+		case command == "go build ./..." && filepath.Base(dir) == "postgres-http-cqrs":
+			return "# example.com/selftest/internal/usecase\ninternal/usecase/product_query.go:12:2: undefined: dto", errors.New("exit status 1")
+		}
+		return "", nil
+	}
+
+	cases, err := selfTestCases("postgres,mongodb", "http", "plain,cqrs")
+	require.NoError(t, err)
+	results := checkSelfTestCases(root, "/usr/bin/goca", cases, 2, run)
+	require.Len(t, results, 4)
+
+	assert.Equal(t, "postgres-http-plain", results[0].dirName())
+	assert.False(t, results[0].failed())
+	assert.Empty(t, results[0].skipped)
+
+	assert.Equal(t, selfTestStageBuild, results[1].stage)
+	assert.Equal(t, "internal/usecase/product_query.go:12:2: undefined: dto", selfTestErrorLine(results[1].output))
+	assert.FileExists(t, filepath.Join(root, "postgres-http-cqrs", "cqrs.go"))
+	assert.DirExists(t, filepath.Join(root, "postgres-http-cqrs", "internal"))
+
+	// MongoDB projects cannot use --cqrs.
+	assert.Contains(t, results[3].skipped, "mongodb")
+	assert.Equal(t, "-", selfTestStatus(results[3]))
+
+	assert.Contains(t, commands, filepath.Base(root)+": goca init base-postgres --module example.com/selftest --database postgres --no-interactive")
+	assert.Contains(t, commands, "postgres-http-plain: goca feature Product --fields "+selfTestFeatureFields+" --handlers http --no-interactive")
+	assert.Contains(t, commands, "postgres-http-cqrs: goca feature Product --fields "+selfTestFeatureFields+" --handlers http --no-interactive --cqrs")
+	assert.NotContains(t, commands, "postgres-http-cqrs: go vet ./...")
+	assert.Contains(t, commands, "mongodb-http-plain: go vet ./...")
+}
+
+func TestCheckSelfTestCases_InitFailure(t *testing.T) {
+	defer setupDiscardUI(t)()
+	run := func(dir, name string, args ...string) (string, error) {
+		if args[0] == "init" {
+			return "✗ could not initialize", errors.New("exit status 1")
+		}
+		return "", nil
+	}
+
+	cases, err := selfTestCases("sqlite", "cli", "plain")
+	require.NoError(t, err)
+	results := checkSelfTestCases(t.TempDir(), "goca", cases, 4, run)
+	require.Len(t, results, 1)
+	assert.Equal(t, selfTestStageInit, results[0].stage)
+	assert.Equal(t, "✗ could not initialize", selfTestErrorLine(results[0].output))
+	assert.Equal(t, 1, printSelfTestFailures(results, "", false))
+}
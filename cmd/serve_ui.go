@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// ExperimentServeUI gates goca serve-ui while the designer is soft-launched.
+const ExperimentServeUI = "serve-ui"
+
+// designerAssets holds the page of the web designer.
+//
+//go:embed designer
+var designerAssets embed.FS
+
+var serveUICmd = &cobra.Command{
+	Use:   "serve-ui",
+	Short: "Start the web project designer (experimental)",
+	Long: `Starts a local web UI for composing entities, their fields, relations
+and options visually. The designer previews the files a design generates and
+runs the generation in the current project; every design maps to the goca
+feature commands it shows, so anything done in the UI can be repeated from the
+command line.
+
+The designer listens on the loopback interface only and is experimental:
+enable it with GOCA_EXPERIMENTAL=serve-ui or by adding serve-ui to experimental
+in .goca.yaml.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		if err := requireExperiment(ExperimentServeUI); err != nil {
+			return err
+		}
+
+		addr, _ := cmd.Flags().GetString("addr")
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return fmt.Errorf("invalid --addr %q: %w", addr, err)
+		}
+		if !isLoopbackHost(host) {
+			return fmt.Errorf("--addr must be a loopback address such as 127.0.0.1:7070, since the designer writes to the project")
+		}
+
+		projectDir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat("go.mod"); err != nil {
+			ui.Warning("No go.mod in the current directory; run goca serve-ui from a project created with goca init")
+		}
+
+		server := &http.Server{
+			Addr:              addr,
+			Handler:           newDesignerHandler(projectDir, runGocaSubcommandIn),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		ui.Success(fmt.Sprintf("Project designer running at http://%s", addr))
+		ui.Dim("Press Ctrl+C to stop")
+		return server.ListenAndServe()
+	},
+}
+
+// designerServer serves the designer page and its API for one project.
+type designerServer struct {
+	projectDir string
+	run        uiRunner
+}
+
+// newDesignerHandler returns the handler of the designer for projectDir.
+// Commands run through run, so tests can replace the goca binary.
+func newDesignerHandler(projectDir string, run uiRunner) http.Handler {
+	s := &designerServer{projectDir: projectDir, run: run}
+	assets, _ := fs.Sub(designerAssets, "designer")
+
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.FS(assets)))
+	mux.HandleFunc("/api/project", s.project)
+	mux.HandleFunc("/api/commands", s.commands)
+	mux.HandleFunc("/api/preview", s.preview)
+	mux.HandleFunc("/api/generate", s.generate)
+	return localOnly(mux)
+}
+
+// localOnly rejects requests that did not come from a page of the designer:
+// a Host other than a loopback name guards against DNS rebinding, and a
+// foreign Origin against other sites posting to the API.
+func localOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if !isLoopbackHost(host) {
+			http.Error(w, "forbidden host", http.StatusForbidden)
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+				http.Error(w, "forbidden origin", http.StatusForbidden)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackHost reports whether host is localhost or a loopback IP.
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// designerProject is the answer of GET /api/project: the project the designer
+// generates into and the choices its forms offer.
+type designerProject struct {
+	Module     string   `json:"module"`
+	Database   string   `json:"database"`
+	Entities   []string `json:"entities"`
+	Databases  []string `json:"databases"`
+	Handlers   []string `json:"handlers"`
+	FieldTypes []string `json:"field_types"`
+}
+
+func (s *designerServer) project(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	detector := NewNameConflictDetector(s.projectDir)
+	_ = detector.ScanExistingEntities()
+
+	writeDesignerJSON(w, http.StatusOK, designerProject{
+		Module:     getModuleName(),
+		Database:   ci.GetDatabaseType(""),
+		Entities:   detector.GetExistingEntities(),
+		Databases:  ValidDatabases,
+		Handlers:   ValidHandlers,
+		FieldTypes: ValidFieldTypes,
+	})
+}
+
+// commands answers the goca command lines of the posted design.
+func (s *designerServer) commands(w http.ResponseWriter, r *http.Request) {
+	commands, ok := s.readDesign(w, r)
+	if !ok {
+		return
+	}
+	writeDesignerJSON(w, http.StatusOK, map[string]any{"commands": commands})
+}
+
+// preview answers the files the posted design would create or modify.
+func (s *designerServer) preview(w http.ResponseWriter, r *http.Request) {
+	commands, ok := s.readDesign(w, r)
+	if !ok {
+		return
+	}
+	files, output, err := previewDesign(r.Context(), s.projectDir, commands, s.run)
+	if err != nil {
+		writeDesignerJSON(w, http.StatusUnprocessableEntity, map[string]any{"error": err.Error(), "output": output})
+		return
+	}
+	writeDesignerJSON(w, http.StatusOK, map[string]any{"commands": commands, "files": files, "output": output})
+}
+
+// generate runs the commands of the posted design in the project.
+func (s *designerServer) generate(w http.ResponseWriter, r *http.Request) {
+	commands, ok := s.readDesign(w, r)
+	if !ok {
+		return
+	}
+	var output string
+	for _, args := range commands {
+		out, err := s.run(r.Context(), s.projectDir, nil, args)
+		output += out
+		if err != nil {
+			writeDesignerJSON(w, http.StatusUnprocessableEntity, map[string]any{"error": err.Error(), "output": output})
+			return
+		}
+	}
+	writeDesignerJSON(w, http.StatusOK, map[string]any{"commands": commands, "output": output})
+}
+
+// readDesign decodes the posted design into its commands. It answers the
+// request itself and returns false when the design cannot be generated.
+func (s *designerServer) readDesign(w http.ResponseWriter, r *http.Request) ([][]string, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+	var design uiDesign
+	if err := json.NewDecoder(r.Body).Decode(&design); err != nil {
+		writeDesignerJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid design: " + err.Error()})
+		return nil, false
+	}
+	commands, err := design.commands()
+	if err != nil {
+		writeDesignerJSON(w, http.StatusUnprocessableEntity, map[string]any{"error": err.Error()})
+		return nil, false
+	}
+	return commands, true
+}
+
+// writeDesignerJSON writes body as JSON with the status.
+func writeDesignerJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func init() {
+	serveUICmd.Flags().String("addr", "127.0.0.1:7070", "Loopback address the designer listens on")
+}
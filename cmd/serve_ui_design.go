@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// uiDesign is a project design composed in the web designer: the entities to
+// generate and the options shared by their features.
+type uiDesign struct {
+	Database string     `json:"database"`
+	Handlers []string   `json:"handlers"`
+	Entities []uiEntity `json:"entities"`
+}
+
+// uiEntity is one entity of a design. BelongsTo names the entities it
+// references; each becomes a <entity>_id foreign key field.
+type uiEntity struct {
+	Name          string    `json:"name"`
+	Fields        []uiField `json:"fields"`
+	BelongsTo     []string  `json:"belongs_to"`
+	Validation    bool      `json:"validation"`
+	BusinessRules bool      `json:"business_rules"`
+	Versioned     bool      `json:"versioned"`
+	Cache         bool      `json:"cache"`
+}
+
+// uiField is a field of an entity, written like in --fields.
+type uiField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// uiFile is a file the preview of a design would create or modify.
+type uiFile struct {
+	Path    string `json:"path"`
+	Action  string `json:"action"` // "create" or "modify"
+	Content string `json:"content"`
+}
+
+// fieldsSpec returns the --fields value of e: its fields followed by a
+// foreign key for each referenced entity it does not declare itself.
+func (e uiEntity) fieldsSpec() string {
+	var parts []string
+	declared := make(map[string]bool)
+	for _, f := range e.Fields {
+		name := strings.TrimSpace(f.Name)
+		parts = append(parts, name+":"+strings.TrimSpace(f.Type))
+		declared[gormColumnName(toGoFieldName(name))] = true
+	}
+	for _, ref := range e.BelongsTo {
+		column := gormColumnName(ref) + "_id"
+		if !declared[column] {
+			parts = append(parts, column+":"+FieldUint)
+			declared[column] = true
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// validate checks the design before any command runs, so a mistake is
+// reported for the entity it belongs to instead of half-way through
+// generation.
+func (d uiDesign) validate() error {
+	if len(d.Entities) == 0 {
+		return fmt.Errorf("the design has no entities")
+	}
+	if d.Database != "" && !contains(ValidDatabases, d.Database) {
+		return fmt.Errorf("unknown database %q; use one of: %s", d.Database, strings.Join(ValidDatabases, ", "))
+	}
+	for _, h := range d.Handlers {
+		if !contains(ValidHandlers, h) {
+			return fmt.Errorf("unknown handler %q; use one of: %s", h, strings.Join(ValidHandlers, ", "))
+		}
+	}
+
+	validator := NewFieldValidator()
+	names := make(map[string]bool)
+	for _, e := range d.Entities {
+		if err := validator.ValidateEntityName(e.Name); err != nil {
+			return fmt.Errorf("entity %q: %w", e.Name, err)
+		}
+		if names[e.Name] {
+			return fmt.Errorf("entity %s is declared twice", e.Name)
+		}
+		names[e.Name] = true
+	}
+	for _, e := range d.Entities {
+		for _, ref := range e.BelongsTo {
+			if !names[ref] && !entityExistsForHandler(ref) {
+				return fmt.Errorf("entity %s belongs to %s, which is neither in the design nor in the project", e.Name, ref)
+			}
+		}
+		if err := validator.ValidateFields(e.fieldsSpec()); err != nil {
+			return fmt.Errorf("entity %s: %w", e.Name, err)
+		}
+	}
+	return nil
+}
+
+// commands returns the goca arguments generating the design: one feature per
+// entity, every entity after the entities it belongs to.
+func (d uiDesign) commands() ([][]string, error) {
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+	ordered, err := d.orderedEntities()
+	if err != nil {
+		return nil, err
+	}
+
+	var commands [][]string
+	for _, e := range ordered {
+		args := []string{"feature", e.Name, "--fields", e.fieldsSpec()}
+		if d.Database != "" {
+			args = append(args, "--database", d.Database)
+		}
+		if len(d.Handlers) > 0 {
+			args = append(args, "--handlers", strings.Join(d.Handlers, ","))
+		}
+		args = appendIfTrue(args, e.Validation, "--validation")
+		args = appendIfTrue(args, e.BusinessRules, "--business-rules")
+		args = appendIfTrue(args, e.Versioned, "--versioned")
+		args = appendIfTrue(args, e.Cache, "--cache")
+		commands = append(commands, args)
+	}
+	return commands, nil
+}
+
+// orderedEntities sorts the entities so each comes after the entities of the
+// design it belongs to, keeping the design order otherwise.
+func (d uiDesign) orderedEntities() ([]uiEntity, error) {
+	inDesign := make(map[string]bool)
+	for _, e := range d.Entities {
+		inDesign[e.Name] = true
+	}
+
+	done := make(map[string]bool)
+	remaining := d.Entities
+	var ordered []uiEntity
+	for len(remaining) > 0 {
+		var next []uiEntity
+		for _, e := range remaining {
+			ready := true
+			for _, ref := range e.BelongsTo {
+				if inDesign[ref] && !done[ref] && ref != e.Name {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				ordered = append(ordered, e)
+				done[e.Name] = true
+			} else {
+				next = append(next, e)
+			}
+		}
+		if len(next) == len(remaining) {
+			var names []string
+			for _, e := range next {
+				names = append(names, e.Name)
+			}
+			return nil, fmt.Errorf("relations form a cycle between %s", strings.Join(names, ", "))
+		}
+		remaining = next
+	}
+	return ordered, nil
+}
+
+// uiRunner runs goca with args in dir, adding env to its environment.
+type uiRunner func(ctx context.Context, dir string, env, args []string) (string, error)
+
+// previewCopyPaths are the project files and directories a preview copies:
+// enough for generation and integration to see the project as it is.
+var previewCopyPaths = []string{"go.mod", "go.sum", ".goca.yaml", ".goca.yml", DirInternal, "cmd", "pkg"}
+
+// previewDesign runs the commands of a design on a temporary copy of
+// projectDir and returns the files they create or modify there. The copy
+// resolves modules offline, so a preview never downloads anything.
+func previewDesign(ctx context.Context, projectDir string, commands [][]string, run uiRunner) ([]uiFile, string, error) {
+	tmp, err := os.MkdirTemp("", "goca-preview-")
+	if err != nil {
+		return nil, "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	for _, path := range previewCopyPaths {
+		if err := copyPreviewPath(filepath.Join(projectDir, path), filepath.Join(tmp, path)); err != nil {
+			return nil, "", err
+		}
+	}
+
+	var output strings.Builder
+	for _, args := range commands {
+		out, err := run(ctx, tmp, []string{"GOPROXY=off", "GOFLAGS=-mod=mod"}, args)
+		output.WriteString(out)
+		if err != nil {
+			return nil, output.String(), err
+		}
+	}
+
+	files, err := changedFiles(projectDir, tmp)
+	return files, output.String(), err
+}
+
+// copyPreviewPath copies the file or directory tree at src to dst. A missing
+// src is skipped.
+func copyPreviewPath(src, dst string) error {
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return nil
+	}
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0o644)
+	})
+}
+
+// changedFiles returns the files under dir that do not exist in original or
+// differ from it, sorted by path. Paths are slash-separated and relative.
+func changedFiles(original, dir string) ([]uiFile, error) {
+	var files []uiFile
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		action := "create"
+		if old, err := os.ReadFile(filepath.Join(original, rel)); err == nil {
+			if bytes.Equal(old, data) {
+				return nil
+			}
+			action = "modify"
+		}
+		files = append(files, uiFile{Path: filepath.ToSlash(rel), Action: action, Content: string(data)})
+		return nil
+	})
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, err
+}
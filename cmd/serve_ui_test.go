@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUIDesignCommands(t *testing.T) {
+	chdirTemp(t)
+	design := uiDesign{
+		Database: DBPostgres,
+		Handlers: []string{HandlerHTTP, HandlerGRPC},
+		Entities: []uiEntity{
+			{Name: "Order", Fields: []uiField{{Name: "total", Type: FieldFloat64}}, BelongsTo: []string{"Customer"}, Validation: true, Versioned: true},
+			{Name: "Customer", Fields: []uiField{{Name: "name", Type: FieldString}}, Cache: true},
+		},
+	}
+	commands, err := design.commands()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"feature", "Customer", "--fields", "name:string", "--database", DBPostgres, "--handlers", "http,grpc", "--cache"},
+		{"feature", "Order", "--fields", "total:float64,customer_id:uint", "--database", DBPostgres, "--handlers", "http,grpc", "--validation", "--versioned"},
+	}, commands)
+
+	declared := uiEntity{Name: "Order", Fields: []uiField{{Name: "customer_id", Type: FieldUint}}, BelongsTo: []string{"Customer"}}
+	assert.Equal(t, "customer_id:uint", declared.fieldsSpec(), "a declared foreign key is not added twice")
+}
+
+func TestUIDesignValidate(t *testing.T) {
+	chdirTemp(t)
+	product := uiEntity{Name: "Product", Fields: []uiField{{Name: "name", Type: FieldString}}}
+
+	tests := []struct {
+		name   string
+		design uiDesign
+		err    string
+	}{
+		{"empty", uiDesign{}, "no entities"},
+		{"database", uiDesign{Database: "cassandra", Entities: []uiEntity{product}}, `unknown database "cassandra"`},
+		{"handler", uiDesign{Handlers: []string{"soap"}, Entities: []uiEntity{product}}, `unknown handler "soap"`},
+		{"name", uiDesign{Entities: []uiEntity{{Name: "product", Fields: product.Fields}}}, `entity "product"`},
+		{"duplicate", uiDesign{Entities: []uiEntity{product, product}}, "declared twice"},
+		{"relation", uiDesign{Entities: []uiEntity{{Name: "Order", Fields: product.Fields, BelongsTo: []string{"Customer"}}}}, "neither in the design nor in the project"},
+		{"field type", uiDesign{Entities: []uiEntity{{Name: "Order", Fields: []uiField{{Name: "total", Type: "money"}}}}}, "entity Order"},
+		{"cycle", uiDesign{Entities: []uiEntity{
+			{Name: "Order", Fields: product.Fields, BelongsTo: []string{"Invoice"}},
+			{Name: "Invoice", Fields: product.Fields, BelongsTo: []string{"Order"}},
+		}}, "cycle between Order, Invoice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.design.commands()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	require.NoError(t, os.MkdirAll(filepath.Join(DirInternal, DirDomain), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(DirInternal, DirDomain, "customer.go"), []byte("package domain\n"), 0o644))
+	_, err := uiDesign{Entities: []uiEntity{{Name: "Order", Fields: product.Fields, BelongsTo: []string{"Customer"}}}}.commands()
+	assert.NoError(t, err, "an entity of the project can be referenced")
+}
+
+func TestLocalOnly(t *testing.T) {
+	handler := localOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		host, origin string
+		status       int
+	}{
+		{"127.0.0.1:7070", "", http.StatusOK},
+		{"localhost:7070", "http://localhost:7070", http.StatusOK},
+		{"[::1]:7070", "", http.StatusOK},
+		{"evil.example.com:7070", "", http.StatusForbidden},
+		{"127.0.0.1:7070", "http://evil.example.com", http.StatusForbidden},
+		{"127.0.0.1:7070", "http://127.0.0.1:8080", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/api/commands", nil)
+		req.Host = tt.host
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, tt.status, rec.Code, "%s from %q", tt.host, tt.origin)
+	}
+}
+
+// designerRequest sends body to path of h as the designer page would.
+func designerRequest(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Host = "127.0.0.1:7070"
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestDesignerHandler(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	project, err := os.Getwd()
+	require.NoError(t, err)
+
+	var runs []string
+	run := func(ctx context.Context, dir string, env, args []string) (string, error) {
+		runs = append(runs, dir+" "+strings.Join(args, " "))
+		path := filepath.Join(dir, DirInternal, DirDomain, strings.ToLower(args[1])+".go")
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(path, []byte("package domain\n"), 0o644); err != nil {
+			return "", err
+		}
+		return "generated " + args[1] + "\n", os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module testproject\n\ngo 1.22\n"), 0o644)
+	}
+	h := newDesignerHandler(project, run)
+	design := `{"entities":[{"name":"Product","fields":[{"name":"name","type":"string"}]}]}`
+
+	page := designerRequest(h, http.MethodGet, "/", "")
+	assert.Equal(t, http.StatusOK, page.Code)
+	assert.Contains(t, page.Body.String(), "Goca Project Designer")
+
+	info := designerRequest(h, http.MethodGet, "/api/project", "")
+	assert.Equal(t, http.StatusOK, info.Code)
+	assert.Contains(t, info.Body.String(), `"module":"testproject"`)
+
+	commands := designerRequest(h, http.MethodPost, "/api/commands", design)
+	assert.Equal(t, http.StatusOK, commands.Code)
+	assert.JSONEq(t, `{"commands":[["feature","Product","--fields","name:string"]]}`, commands.Body.String())
+	assert.Empty(t, runs)
+
+	assert.Equal(t, http.StatusBadRequest, designerRequest(h, http.MethodPost, "/api/commands", "{").Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, designerRequest(h, http.MethodPost, "/api/commands", `{"entities":[]}`).Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, designerRequest(h, http.MethodGet, "/api/generate", "").Code)
+
+	preview := designerRequest(h, http.MethodPost, "/api/preview", design)
+	assert.Equal(t, http.StatusOK, preview.Code)
+	assert.Contains(t, preview.Body.String(), `{"path":"go.mod","action":"modify"`)
+	assert.Contains(t, preview.Body.String(), `{"path":"internal/domain/product.go","action":"create","content":"package domain\n"}`)
+	assert.Contains(t, preview.Body.String(), `"output":"generated Product\n"`)
+	_, err = os.Stat(filepath.Join(project, DirInternal, DirDomain, "product.go"))
+	assert.True(t, os.IsNotExist(err), "a preview leaves the project untouched")
+	require.Len(t, runs, 1)
+	assert.NotContains(t, runs[0], project+" ", "a preview runs on a copy")
+
+	generate := designerRequest(h, http.MethodPost, "/api/generate", design)
+	assert.Equal(t, http.StatusOK, generate.Code)
+	assert.FileExists(t, filepath.Join(project, DirInternal, DirDomain, "product.go"))
+	assert.Equal(t, project+" feature Product --fields name:string", runs[1])
+}
+
+func TestServeUIRequiresExperiment(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	t.Setenv(experimentalEnv, "")
+
+	err := serveUICmd.RunE(serveUICmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), experimentalEnv+"="+ExperimentServeUI)
+
+	t.Setenv(experimentalEnv, ExperimentServeUI)
+	require.NoError(t, serveUICmd.Flags().Set("addr", "0.0.0.0:7070"))
+	t.Cleanup(func() { serveUICmd.Flags().Set("addr", "127.0.0.1:7070") })
+	err = serveUICmd.RunE(serveUICmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "loopback")
+}
@@ -0,0 +1,543 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var deploySLOCmd = &cobra.Command{
+	Use:   "slo",
+	Short: "Generate SLO recording and alerting rules and a Grafana dashboard",
+	Long: `Generates monitoring for the service level objectives declared under
+features.monitoring.slo in .goca.yaml, next to the Kubernetes manifests:
+
+  - monitoring/servicemonitor.yaml     Scrapes /metrics of the Service
+  - monitoring/slo-rules.yaml          PrometheusRule with the recording rules and
+                                       multiwindow burn rate alerts of each SLO
+  - monitoring/dashboard.json          Grafana dashboard of the SLOs and endpoints
+  - monitoring/kustomization.yaml      Applies the above, the dashboard as a
+                                       ConfigMap picked up by the Grafana sidecar
+
+The service objectives cover every request, and each endpoint listed gets
+objectives of its own. The rules read http_requests_total and
+http_request_duration_seconds, which 'goca middleware <name> --types metrics'
+exports. 'goca deploy k8s' regenerates these files too when SLOs are declared.
+
+Example .goca.yaml:
+  features:
+    monitoring:
+      slo:
+        window: 30d
+        availability: 99.9
+        latency:
+          threshold: 250ms
+          objective: 99
+        endpoints:
+          - method: POST
+            route: /api/v1/orders
+            availability: 99.95
+            latency:
+              threshold: 1s
+              objective: 95`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		namespace, _ := cmd.Flags().GetString("namespace")
+		output, _ := cmd.Flags().GetString("output")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		force, _ := cmd.Flags().GetBool("force")
+		backup, _ := cmd.Flags().GetBool("backup")
+
+		data, k8sCfg := buildK8sTemplateData(namespace, "")
+		if output == "" {
+			output = k8sCfg.Manifests
+		}
+		cfg := loadSLOConfig()
+		if !sloDeclared(cfg) {
+			return fmt.Errorf("no SLOs declared: add features.monitoring.slo to .goca.yaml (see 'goca deploy slo --help')")
+		}
+		objectives, window, err := buildSLOObjectives(cfg, data.Name)
+		if err != nil {
+			return err
+		}
+
+		ui.Header("Goca Deploy — SLOs")
+		ui.Blank()
+		ui.KeyValue("Service", data.Name)
+		ui.KeyValue("Namespace", data.Namespace)
+		ui.KeyValue("Window", promDuration(window))
+		ui.KeyValue("Objectives", strconv.Itoa(len(objectives)))
+		ui.Blank()
+
+		sm := NewSafetyManager(dryRun, force, backup)
+		dir := filepath.Join(output, "monitoring")
+		ui.Step(1, "Generating SLO monitoring in "+dir)
+		if err := generateSLOMonitoring(dir, data, objectives, window, sm); err != nil {
+			return err
+		}
+
+		if dryRun {
+			sm.PrintSummary()
+			return nil
+		}
+
+		ui.Blank()
+		ui.Success("SLO monitoring generated successfully!")
+		ui.Blank()
+		ui.NextSteps([]string{
+			"Export the metrics: goca middleware api --types metrics, then router.Use it and serve /metrics",
+			"kubectl apply -k " + dir + " (needs the Prometheus Operator CRDs)",
+		})
+		return nil
+	},
+}
+
+func init() {
+	deploySLOCmd.Flags().String("namespace", "", "Kubernetes namespace (default: deploy.kubernetes.namespace or the project name)")
+	deploySLOCmd.Flags().String("output", "", "Directory of the manifests (default: deploy.kubernetes.manifests or k8s)")
+	deploySLOCmd.Flags().Bool("dry-run", false, "Preview changes without creating files")
+	deploySLOCmd.Flags().Bool("force", false, "Overwrite existing files without asking")
+	deploySLOCmd.Flags().Bool("backup", false, "Backup existing files before overwriting")
+
+	deployCmd.AddCommand(deploySLOCmd)
+}
+
+// sloObjective is one SLO: the share of the requests of the service, or of
+// one endpoint, that must succeed or be fast enough.
+type sloObjective struct {
+	Name      string
+	SLI       string  // availability or latency
+	Objective float64 // fraction, e.g. 0.999
+	Threshold float64 // seconds, latency only
+	Method    string
+	Route     string
+}
+
+// errorBudget returns the fraction of requests the objective allows to fail.
+func (o sloObjective) errorBudget() float64 {
+	return 1 - o.Objective
+}
+
+const (
+	sloAvailability = "availability"
+	sloLatency      = "latency"
+)
+
+// sloBurnWindows are the windows of the multiwindow burn rate alerts: an
+// alert fires when both the long and the short window burn budget of the
+// SLO window faster than consuming the budget share in the long window.
+var sloBurnWindows = []struct {
+	alert, severity string
+	long, short     time.Duration
+	budget          float64
+}{
+	{"ErrorBudgetFastBurn", "critical", time.Hour, 5 * time.Minute, 0.02},
+	{"ErrorBudgetFastBurn", "critical", 6 * time.Hour, 30 * time.Minute, 0.05},
+	{"ErrorBudgetSlowBurn", "warning", 24 * time.Hour, 2 * time.Hour, 0.10},
+	{"ErrorBudgetSlowBurn", "warning", 72 * time.Hour, 6 * time.Hour, 0.10},
+}
+
+// prometheusDefBuckets are the buckets of the histogram the metrics
+// middleware exports; latency thresholds must be one of them.
+var prometheusDefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// loadSLOConfig returns features.monitoring.slo of the project's .goca.yaml.
+func loadSLOConfig() SLOConfig {
+	ci := NewConfigIntegration()
+	_ = ci.LoadConfigForProject()
+	return ci.GetFeatureConfig().Monitoring.SLO
+}
+
+// sloDeclared reports whether cfg declares any objective.
+func sloDeclared(cfg SLOConfig) bool {
+	return cfg.Availability > 0 || cfg.Latency.Objective > 0 || len(cfg.Endpoints) > 0
+}
+
+// buildSLOObjectives validates cfg and returns its objectives, the service's
+// first, along with the compliance window (30 days by default).
+func buildSLOObjectives(cfg SLOConfig, service string) ([]sloObjective, time.Duration, error) {
+	window := 30 * 24 * time.Hour
+	if cfg.Window != "" {
+		w, err := parseSLOWindow(cfg.Window)
+		if err != nil {
+			return nil, 0, err
+		}
+		window = w
+	}
+
+	var objectives []sloObjective
+	add := func(prefix, method, route string, availability float64, latency LatencyObjective) error {
+		if availability != 0 {
+			if availability <= 0 || availability >= 100 {
+				return fmt.Errorf("%s: availability must be a percentage between 0 and 100, got %g", prefix, availability)
+			}
+			objectives = append(objectives, sloObjective{
+				Name: prefix + "-" + sloAvailability, SLI: sloAvailability,
+				Objective: availability / 100, Method: method, Route: route,
+			})
+		}
+		if latency.Objective != 0 || latency.Threshold != "" {
+			if latency.Objective <= 0 || latency.Objective >= 100 {
+				return fmt.Errorf("%s: latency objective must be a percentage between 0 and 100, got %g", prefix, latency.Objective)
+			}
+			threshold, err := time.ParseDuration(latency.Threshold)
+			if err != nil || threshold <= 0 {
+				return fmt.Errorf("%s: invalid latency threshold %q (e.g. 250ms)", prefix, latency.Threshold)
+			}
+			if !containsFloat(prometheusDefBuckets, threshold.Seconds()) {
+				ui.Warning(fmt.Sprintf("%s: latency threshold %s is not a bucket of http_request_duration_seconds; add it to the histogram's buckets", prefix, latency.Threshold))
+			}
+			objectives = append(objectives, sloObjective{
+				Name: prefix + "-" + sloLatency, SLI: sloLatency,
+				Objective: latency.Objective / 100, Threshold: threshold.Seconds(), Method: method, Route: route,
+			})
+		}
+		return nil
+	}
+
+	if err := add(service, "", "", cfg.Availability, cfg.Latency); err != nil {
+		return nil, 0, err
+	}
+	for _, e := range cfg.Endpoints {
+		if !strings.HasPrefix(e.Route, "/") {
+			return nil, 0, fmt.Errorf("endpoint SLO route %q must be a path template starting with /", e.Route)
+		}
+		method := strings.ToUpper(e.Method)
+		availability, latency := e.Availability, e.Latency
+		if availability == 0 {
+			availability = cfg.Availability
+		}
+		if latency.Objective == 0 && latency.Threshold == "" {
+			latency = cfg.Latency
+		}
+		if availability == 0 && latency.Objective == 0 {
+			return nil, 0, fmt.Errorf("endpoint SLO %s %s declares no objective and the service has none to inherit", method, e.Route)
+		}
+		if err := add(k8sName(service+"-"+method+e.Route), method, e.Route, availability, latency); err != nil {
+			return nil, 0, err
+		}
+	}
+	return objectives, window, nil
+}
+
+// parseSLOWindow parses a compliance window such as 30d or 168h.
+func parseSLOWindow(s string) (time.Duration, error) {
+	var window time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil {
+			window = time.Duration(n) * 24 * time.Hour
+		}
+	} else if d, err := time.ParseDuration(s); err == nil {
+		window = d
+	}
+	if window < 24*time.Hour {
+		return 0, fmt.Errorf("invalid SLO window %q: use a duration of at least a day, e.g. 30d", s)
+	}
+	return window, nil
+}
+
+// promDuration formats d as a Prometheus duration in its largest whole unit.
+func promDuration(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+}
+
+// promFloat formats v for a PromQL expression.
+func promFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
+
+func containsFloat(values []float64, v float64) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// sloSelector returns the label matchers of the requests an objective is
+// about. The ServiceMonitor makes the Service's name the job label.
+func sloSelector(data K8sTemplateData, o sloObjective) string {
+	matchers := []string{"job=" + strconv.Quote(data.Name), "namespace=" + strconv.Quote(data.Namespace)}
+	if o.Route != "" {
+		matchers = append(matchers, "route="+strconv.Quote(o.Route))
+	}
+	if o.Method != "" {
+		matchers = append(matchers, "method="+strconv.Quote(o.Method))
+	}
+	return strings.Join(matchers, ", ")
+}
+
+// sloErrorRatio returns the expression of the share of the requests that
+// missed the objective over window, indented by indent spaces.
+func sloErrorRatio(data K8sTemplateData, o sloObjective, window string, indent int) string {
+	pad := strings.Repeat(" ", indent)
+	sel := sloSelector(data, o)
+	if o.SLI == sloAvailability {
+		return fmt.Sprintf("%[1]ssum(rate(http_requests_total{%[2]s, code=~\"5..\"}[%[3]s]))\n%[1]s/\n%[1]ssum(rate(http_requests_total{%[2]s}[%[3]s]))\n", pad, sel, window)
+	}
+	// Prometheus 3 writes integral bucket bounds as 1.0, earlier versions as 1.
+	le := strconv.FormatFloat(o.Threshold, 'f', -1, 64)
+	if strings.Contains(le, ".") {
+		le = "le=" + strconv.Quote(le)
+	} else {
+		le = "le=~" + strconv.Quote(le+"|"+le+".0")
+	}
+	return fmt.Sprintf("%[1]s1 - (\n%[1]s  sum(rate(http_request_duration_seconds_bucket{%[2]s, %[4]s}[%[3]s]))\n%[1]s  /\n%[1]s  sum(rate(http_request_duration_seconds_count{%[2]s}[%[3]s]))\n%[1]s)\n", pad, sel, window, le)
+}
+
+// sloRecordingWindows returns the windows the error ratio of every objective
+// is recorded over, shortest first.
+func sloRecordingWindows() []time.Duration {
+	var windows []time.Duration
+	for _, w := range sloBurnWindows {
+		for _, d := range []time.Duration{w.short, w.long} {
+			if !containsDuration(windows, d) {
+				windows = append(windows, d)
+			}
+		}
+	}
+	for i := 1; i < len(windows); i++ {
+		for j := i; j > 0 && windows[j] < windows[j-1]; j-- {
+			windows[j], windows[j-1] = windows[j-1], windows[j]
+		}
+	}
+	return windows
+}
+
+func containsDuration(values []time.Duration, v time.Duration) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// sloRecordLabels returns the labels of an objective's recording rules,
+// indented by indent spaces.
+func sloRecordLabels(data K8sTemplateData, o sloObjective, indent int) string {
+	pad := strings.Repeat(" ", indent)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%sslo: %s\n", pad, o.Name)
+	fmt.Fprintf(&b, "%sservice: %s\n", pad, data.Name)
+	fmt.Fprintf(&b, "%ssli: %s\n", pad, o.SLI)
+	if o.Route != "" {
+		fmt.Fprintf(&b, "%sroute: %s\n", pad, strconv.Quote(o.Route))
+	}
+	if o.Method != "" {
+		fmt.Fprintf(&b, "%smethod: %s\n", pad, o.Method)
+	}
+	return b.String()
+}
+
+// generateSLORules returns the PrometheusRule with a recording group and the
+// burn rate alerts of each objective.
+func generateSLORules(data K8sTemplateData, objectives []sloObjective, window time.Duration) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `apiVersion: monitoring.coreos.com/v1
+kind: PrometheusRule
+metadata:
+  name: %s-slos
+  namespace: %s
+  labels:
+%sspec:
+  groups:
+`, data.Name, data.Namespace, k8sLabels(data, 4))
+
+	windowName := promDuration(window)
+	for _, o := range objectives {
+		match := "{slo=" + strconv.Quote(o.Name) + "}"
+		fmt.Fprintf(&b, "    - name: slo-%s\n", o.Name)
+		b.WriteString("      rules:\n")
+		for _, w := range sloRecordingWindows() {
+			fmt.Fprintf(&b, "        - record: slo:sli_error:ratio_rate%s\n", promDuration(w))
+			b.WriteString("          expr: |\n")
+			b.WriteString(sloErrorRatio(data, o, promDuration(w), 12))
+			b.WriteString("          labels:\n")
+			b.WriteString(sloRecordLabels(data, o, 12))
+		}
+		fmt.Fprintf(&b, "        - record: slo:sli_error:ratio_rate%s\n", windowName)
+		fmt.Fprintf(&b, "          expr: avg_over_time(slo:sli_error:ratio_rate5m%s[%s])\n", match, windowName)
+		b.WriteString("          labels:\n")
+		b.WriteString(sloRecordLabels(data, o, 12))
+
+		budget := o.errorBudget()
+		for i := 0; i < len(sloBurnWindows); i += 2 {
+			alert := sloBurnWindows[i]
+			fmt.Fprintf(&b, "        - alert: %s\n", alert.alert)
+			b.WriteString("          expr: |\n")
+			for j, w := range sloBurnWindows[i : i+2] {
+				if j > 0 {
+					b.WriteString("            or\n")
+				}
+				rate := promFloat(w.budget * float64(window) / float64(w.long))
+				fmt.Fprintf(&b, "            (\n              slo:sli_error:ratio_rate%s%s > (%s * %s)\n              and\n              slo:sli_error:ratio_rate%s%s > (%s * %s)\n            )\n",
+					promDuration(w.long), match, rate, promFloat(budget), promDuration(w.short), match, rate, promFloat(budget))
+			}
+			b.WriteString("          labels:\n")
+			fmt.Fprintf(&b, "            severity: %s\n", alert.severity)
+			fmt.Fprintf(&b, "            slo: %s\n", o.Name)
+			fmt.Fprintf(&b, "            service: %s\n", data.Name)
+			b.WriteString("          annotations:\n")
+			fmt.Fprintf(&b, "            summary: %s\n", strconv.Quote(fmt.Sprintf("%s is burning its %s error budget", o.Name, windowName)))
+			fmt.Fprintf(&b, "            description: %s\n", strconv.Quote(fmt.Sprintf("%s (objective %s%%) will exhaust its error budget early at the current error rate.", sloDescription(o), promFloat(o.Objective*100))))
+		}
+	}
+	return b.String()
+}
+
+// sloDescription describes what an objective measures, e.g. "Latency under
+// 250ms of GET /api/v1/orders".
+func sloDescription(o sloObjective) string {
+	what := "Availability"
+	if o.SLI == sloLatency {
+		what = "Latency under " + time.Duration(o.Threshold*float64(time.Second)).String()
+	}
+	switch {
+	case o.Route == "":
+		return what + " of all requests"
+	case o.Method == "":
+		return what + " of " + o.Route
+	default:
+		return what + " of " + o.Method + " " + o.Route
+	}
+}
+
+// generateServiceMonitor returns the ServiceMonitor scraping /metrics of the
+// Service, whose name becomes the job label the rules select.
+func generateServiceMonitor(data K8sTemplateData) string {
+	return fmt.Sprintf(`apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: %[1]s
+  namespace: %[2]s
+  labels:
+%[3]sspec:
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: %[1]s
+  endpoints:
+    - port: http
+      path: /metrics
+      interval: 30s
+`, data.Name, data.Namespace, k8sLabels(data, 4))
+}
+
+// generateSLOKustomization returns the kustomization of the monitoring
+// directory. The dashboard becomes a ConfigMap labelled for the Grafana
+// dashboard sidecar.
+func generateSLOKustomization(data K8sTemplateData) string {
+	return fmt.Sprintf(`apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namespace: %[1]s
+resources:
+  - servicemonitor.yaml
+  - slo-rules.yaml
+configMapGenerator:
+  - name: %[2]s-slo-dashboard
+    files:
+      - dashboard.json
+    options:
+      disableNameSuffixHash: true
+      labels:
+        grafana_dashboard: "1"
+`, data.Namespace, data.Name)
+}
+
+// generateSLODashboard returns the Grafana dashboard JSON: traffic, latency
+// and errors per endpoint, then the SLI, remaining error budget and error
+// ratios of each objective.
+func generateSLODashboard(data K8sTemplateData, objectives []sloObjective, window time.Duration) (string, error) {
+	service := sloSelector(data, sloObjective{})
+	zero, one := 0, 1
+	percent := &grafanaFields{Defaults: grafanaFieldDefaults{Unit: "percentunit", Min: &zero, Max: &one}}
+
+	var board grafanaBoard
+	add := board.add
+	add("row", "Endpoints", grafanaGridPos{Y: 0, W: 24, H: 1}, nil)
+	add("timeseries", "Requests per second", grafanaGridPos{Y: 1, W: 8, H: 8}, &grafanaFields{Defaults: grafanaFieldDefaults{Unit: "reqps"}},
+		grafanaTarget{Expr: fmt.Sprintf("sum by (method, route) (rate(http_requests_total{%s}[5m]))", service), LegendFormat: "{{method}} {{route}}"})
+	add("timeseries", "p99 latency", grafanaGridPos{X: 8, Y: 1, W: 8, H: 8}, &grafanaFields{Defaults: grafanaFieldDefaults{Unit: "s"}},
+		grafanaTarget{Expr: fmt.Sprintf("histogram_quantile(0.99, sum by (le, method, route) (rate(http_request_duration_seconds_bucket{%s}[5m])))", service), LegendFormat: "{{method}} {{route}}"})
+	add("timeseries", "5xx ratio", grafanaGridPos{X: 16, Y: 1, W: 8, H: 8}, &grafanaFields{Defaults: grafanaFieldDefaults{Unit: "percentunit"}},
+		grafanaTarget{Expr: fmt.Sprintf("sum by (method, route) (rate(http_requests_total{%s, code=~\"5..\"}[5m])) / sum by (method, route) (rate(http_requests_total{%s}[5m]))", service, service), LegendFormat: "{{method}} {{route}}"})
+
+	windowName := promDuration(window)
+	y := 9
+	for _, o := range objectives {
+		match := "{slo=" + strconv.Quote(o.Name) + "}"
+		budget := promFloat(o.errorBudget())
+		add("row", fmt.Sprintf("%s (%s%%)", sloDescription(o), promFloat(o.Objective*100)), grafanaGridPos{Y: y, W: 24, H: 1}, nil)
+		add("stat", "SLI ("+windowName+")", grafanaGridPos{Y: y + 1, W: 6, H: 6}, percent,
+			grafanaTarget{Expr: fmt.Sprintf("1 - slo:sli_error:ratio_rate%s%s", windowName, match)})
+		add("stat", "Error budget remaining", grafanaGridPos{X: 6, Y: y + 1, W: 6, H: 6}, &grafanaFields{Defaults: grafanaFieldDefaults{Unit: "percentunit", Max: &one}},
+			grafanaTarget{Expr: fmt.Sprintf("1 - slo:sli_error:ratio_rate%s%s / %s", windowName, match, budget)})
+		add("timeseries", "Error ratio", grafanaGridPos{X: 12, Y: y + 1, W: 12, H: 6}, &grafanaFields{Defaults: grafanaFieldDefaults{Unit: "percentunit"}},
+			grafanaTarget{Expr: "slo:sli_error:ratio_rate5m" + match, LegendFormat: "5m"},
+			grafanaTarget{Expr: "slo:sli_error:ratio_rate1h" + match, LegendFormat: "1h"},
+			grafanaTarget{Expr: budget, LegendFormat: "error budget"})
+		y += 7
+	}
+
+	uid := k8sName(data.Name + "-slos")
+	if len(uid) > 40 {
+		uid = strings.TrimRight(uid[:40], "-")
+	}
+	dashboard := map[string]any{
+		"uid":           uid,
+		"title":         data.Name + " SLOs",
+		"tags":          []string{"slo", data.Name},
+		"schemaVersion": 39,
+		"refresh":       "1m",
+		"time":          map[string]string{"from": "now-7d", "to": "now"},
+		"templating": map[string]any{
+			"list": []map[string]any{grafanaDatasourceVariable},
+		},
+		"panels": board.panels,
+	}
+	content, err := json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(content) + "\n", nil
+}
+
+// generateSLOMonitoring writes the ServiceMonitor, SLO rules, dashboard and
+// their kustomization to dir.
+func generateSLOMonitoring(dir string, data K8sTemplateData, objectives []sloObjective, window time.Duration, sm ...*SafetyManager) error {
+	dashboard, err := generateSLODashboard(data, objectives, window)
+	if err != nil {
+		return fmt.Errorf("rendering dashboard.json: %w", err)
+	}
+	files := []struct {
+		file    string
+		content string
+	}{
+		{"servicemonitor.yaml", generateServiceMonitor(data)},
+		{"slo-rules.yaml", generateSLORules(data, objectives, window)},
+		{"dashboard.json", dashboard},
+		{"kustomization.yaml", generateSLOKustomization(data)},
+	}
+
+	for _, f := range files {
+		if err := writeFile(filepath.Join(dir, f.file), f.content, sm...); err != nil {
+			return fmt.Errorf("writing %s: %w", f.file, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestBuildSLOObjectives(t *testing.T) {
+	defer ensureTestUI(t)()
+	cfg := SLOConfig{
+		Availability: 99.9,
+		Latency:      LatencyObjective{Threshold: "250ms", Objective: 99},
+		Endpoints: []EndpointObjective{
+			{Method: "post", Route: "/api/v1/orders/{id}", Latency: LatencyObjective{Threshold: "1s", Objective: 95}},
+		},
+	}
+	objectives, window, err := buildSLOObjectives(cfg, "shop")
+	require.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, window)
+	require.Len(t, objectives, 4)
+	assert.Equal(t, "shop-availability", objectives[0].Name)
+	assert.InDelta(t, 0.999, objectives[0].Objective, 1e-9)
+	assert.Equal(t, "shop-latency", objectives[1].Name)
+	assert.Equal(t, 0.25, objectives[1].Threshold)
+	// The endpoint inherits the service's availability objective.
+	assert.Equal(t, "shop-post-api-v1-orders-id-availability", objectives[2].Name)
+	assert.InDelta(t, 0.999, objectives[2].Objective, 1e-9)
+	assert.Equal(t, "shop-post-api-v1-orders-id-latency", objectives[3].Name)
+	assert.Equal(t, sloLatency, objectives[3].SLI)
+	assert.InDelta(t, 0.95, objectives[3].Objective, 1e-9)
+	assert.Equal(t, 1.0, objectives[3].Threshold)
+	assert.Equal(t, "POST", objectives[3].Method)
+	assert.Equal(t, "/api/v1/orders/{id}", objectives[3].Route)
+
+	_, window, err = buildSLOObjectives(SLOConfig{Window: "7d", Availability: 99}, "shop")
+	require.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, window)
+
+	for _, bad := range []SLOConfig{
+		{Availability: 100},
+		{Window: "1h", Availability: 99},
+		{Latency: LatencyObjective{Objective: 99}},
+		{Endpoints: []EndpointObjective{{Route: "orders"}}},
+		{Endpoints: []EndpointObjective{{Route: "/orders"}}},
+	} {
+		_, _, err := buildSLOObjectives(bad, "shop")
+		assert.Error(t, err, "%+v", bad)
+	}
+}
+
+func TestGenerateSLOMonitoring(t *testing.T) {
+	defer ensureTestUI(t)()
+	dir := t.TempDir()
+	data := K8sTemplateData{Name: "shop", Namespace: "store"}
+	objectives := []sloObjective{
+		{Name: "shop-availability", SLI: sloAvailability, Objective: 0.999},
+		{Name: "shop-get-api-v1-orders-latency", SLI: sloLatency, Objective: 0.99, Threshold: 1, Method: "GET", Route: "/api/v1/orders"},
+	}
+	require.NoError(t, generateSLOMonitoring(dir, data, objectives, 30*24*time.Hour, NewSafetyManager(false, true, false)))
+
+	content, err := os.ReadFile(filepath.Join(dir, "slo-rules.yaml"))
+	require.NoError(t, err)
+	rules := string(content)
+	var parsed map[string]any
+	require.NoError(t, yaml.Unmarshal(content, &parsed))
+	assert.Contains(t, rules, "sum(rate(http_requests_total{job=\"shop\", namespace=\"store\", code=~\"5..\"}[1h]))")
+	assert.Contains(t, rules, "sum(rate(http_request_duration_seconds_bucket{job=\"shop\", namespace=\"store\", route=\"/api/v1/orders\", method=\"GET\", le=~\"1|1.0\"}[5m]))")
+	assert.Contains(t, rules, "expr: avg_over_time(slo:sli_error:ratio_rate5m{slo=\"shop-availability\"}[30d])")
+	// A 30 day window pages at a 14.4x and 6x burn rate and tickets at 3x and 1x.
+	assert.Contains(t, rules, "slo:sli_error:ratio_rate1h{slo=\"shop-availability\"} > (14.4 * 0.001)")
+	assert.Contains(t, rules, "slo:sli_error:ratio_rate30m{slo=\"shop-availability\"} > (6 * 0.001)")
+	assert.Contains(t, rules, "slo:sli_error:ratio_rate2h{slo=\"shop-get-api-v1-orders-latency\"} > (3 * 0.01)")
+	assert.Contains(t, rules, "slo:sli_error:ratio_rate3d{slo=\"shop-get-api-v1-orders-latency\"} > (1 * 0.01)")
+
+	content, err = os.ReadFile(filepath.Join(dir, "dashboard.json"))
+	require.NoError(t, err)
+	var dashboard struct {
+		UID    string         `json:"uid"`
+		Panels []grafanaPanel `json:"panels"`
+	}
+	require.NoError(t, json.Unmarshal(content, &dashboard))
+	assert.Equal(t, "shop-slos", dashboard.UID)
+	require.Len(t, dashboard.Panels, 12)
+	assert.Equal(t, "1 - slo:sli_error:ratio_rate30d{slo=\"shop-availability\"} / 0.001", dashboard.Panels[6].Targets[0].Expr)
+
+	kustomization, err := os.ReadFile(filepath.Join(dir, "kustomization.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(kustomization), "  - name: shop-slo-dashboard\n")
+	assert.FileExists(t, filepath.Join(dir, "servicemonitor.yaml"))
+}
@@ -0,0 +1,663 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sqlSchema is the set of tables parsed from a SQL schema dump, in the order
+// they were declared.
+type sqlSchema struct {
+	Tables []*sqlTable
+}
+
+// sqlTable describes a table reconstructed from CREATE TABLE, ALTER TABLE and
+// CREATE INDEX statements.
+type sqlTable struct {
+	Name        string
+	Columns     []sqlColumn
+	PrimaryKey  []string
+	ForeignKeys []sqlForeignKey
+	Indexes     []sqlIndex
+}
+
+// sqlColumn is a single table column. Type is the lowercased base type without
+// length or precision, e.g. "character varying" or "numeric".
+type sqlColumn struct {
+	Name    string
+	Type    string
+	Array   bool
+	NotNull bool
+}
+
+// sqlForeignKey links columns of a table to columns of another table.
+type sqlForeignKey struct {
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+}
+
+// sqlIndex is a regular or unique index over one or more columns.
+type sqlIndex struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+// table returns the table with the given name, ignoring case.
+func (s *sqlSchema) table(name string) *sqlTable {
+	for _, t := range s.Tables {
+		if strings.EqualFold(t.Name, name) {
+			return t
+		}
+	}
+	return nil
+}
+
+// foreignKey returns the single-column foreign key declared on column.
+func (t *sqlTable) foreignKey(column string) (sqlForeignKey, bool) {
+	for _, fk := range t.ForeignKeys {
+		if len(fk.Columns) == 1 && strings.EqualFold(fk.Columns[0], column) {
+			return fk, true
+		}
+	}
+	return sqlForeignKey{}, false
+}
+
+// columnStopWords end the type of a column definition.
+var columnStopWords = map[string]bool{
+	"not": true, "null": true, "primary": true, "references": true, "default": true,
+	"unique": true, "check": true, "constraint": true, "generated": true, "collate": true,
+	"auto_increment": true, "autoincrement": true, "comment": true, "identity": true,
+	"on": true,
+}
+
+// parseSQLSchema parses the DDL of a schema dump, such as the output of
+// pg_dump --schema-only or a hand-written schema.sql. Statements other than
+// CREATE TABLE, ALTER TABLE ... ADD CONSTRAINT and CREATE INDEX are ignored.
+func parseSQLSchema(ddl string) (*sqlSchema, error) {
+	tokens, err := tokenizeSQL(ddl)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &sqlSchema{}
+	var alters, indexes [][]string
+	for _, stmt := range splitSQLStatements(tokens) {
+		switch {
+		case sqlKeywordsAt(stmt, 0, "create") && sqlIndexOfKeyword(stmt, "table") >= 0 && sqlIndexOfKeyword(stmt, "table") <= 3:
+			table, err := parseCreateTable(stmt)
+			if err != nil {
+				return nil, err
+			}
+			if table != nil {
+				schema.Tables = append(schema.Tables, table)
+			}
+		case sqlKeywordsAt(stmt, 0, "alter", "table"):
+			alters = append(alters, stmt)
+		case sqlKeywordsAt(stmt, 0, "create") && sqlIndexOfKeyword(stmt, "index") >= 0 && sqlIndexOfKeyword(stmt, "index") <= 2:
+			indexes = append(indexes, stmt)
+		}
+	}
+
+	// Constraints and indexes may precede or follow their table in a dump, so
+	// they are applied once every table is known.
+	for _, stmt := range alters {
+		applyAlterTable(schema, stmt)
+	}
+	for _, stmt := range indexes {
+		applyCreateIndex(schema, stmt)
+	}
+
+	if len(schema.Tables) == 0 {
+		return nil, fmt.Errorf("no CREATE TABLE statements found")
+	}
+	return schema, nil
+}
+
+// tokenizeSQL splits DDL into identifiers, keywords, literals and punctuation.
+// Comments are dropped; quoted identifiers keep their quotes so they are never
+// mistaken for keywords.
+func tokenizeSQL(ddl string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(ddl); {
+		c := ddl[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(ddl[i:], "--") || c == '#':
+			end := strings.IndexByte(ddl[i:], '\n')
+			if end < 0 {
+				return tokens, nil
+			}
+			i += end + 1
+		case strings.HasPrefix(ddl[i:], "/*"):
+			end := strings.Index(ddl[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 4
+		case c == '\'' || c == '"' || c == '`':
+			end := i + 1
+			for ; end < len(ddl); end++ {
+				if ddl[end] == c {
+					if end+1 < len(ddl) && ddl[end+1] == c {
+						end++
+						continue
+					}
+					break
+				}
+			}
+			if end >= len(ddl) {
+				return nil, fmt.Errorf("unterminated quoted text starting with %.20s", ddl[i:])
+			}
+			tokens = append(tokens, ddl[i:end+1])
+			i = end + 1
+		case c == '$':
+			// Dollar-quoted function bodies: $$...$$ or $tag$...$tag$.
+			tagEnd := strings.IndexByte(ddl[i+1:], '$')
+			if tagEnd < 0 {
+				i++
+				continue
+			}
+			tag := ddl[i : i+tagEnd+2]
+			end := strings.Index(ddl[i+len(tag):], tag)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated dollar-quoted string %s", tag)
+			}
+			tokens = append(tokens, "'body'")
+			i += len(tag) + end + len(tag)
+		case strings.ContainsRune("(),;.", rune(c)):
+			tokens = append(tokens, string(c))
+			i++
+		default:
+			end := i
+			for end < len(ddl) && !strings.ContainsRune(" \t\n\r(),;.'\"`", rune(ddl[end])) {
+				end++
+			}
+			tokens = append(tokens, ddl[i:end])
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// splitSQLStatements groups tokens into statements separated by semicolons.
+func splitSQLStatements(tokens []string) [][]string {
+	var stmts [][]string
+	var current []string
+	for _, tok := range tokens {
+		if tok == ";" {
+			if len(current) > 0 {
+				stmts = append(stmts, current)
+			}
+			current = nil
+			continue
+		}
+		current = append(current, tok)
+	}
+	if len(current) > 0 {
+		stmts = append(stmts, current)
+	}
+	return stmts
+}
+
+// sqlKeywordsAt reports whether the tokens starting at pos match keywords,
+// ignoring case.
+func sqlKeywordsAt(tokens []string, pos int, keywords ...string) bool {
+	if pos+len(keywords) > len(tokens) {
+		return false
+	}
+	for i, kw := range keywords {
+		if !strings.EqualFold(tokens[pos+i], kw) {
+			return false
+		}
+	}
+	return true
+}
+
+// sqlIndexOfKeyword returns the position of the first token equal to keyword.
+func sqlIndexOfKeyword(tokens []string, keyword string) int {
+	for i, tok := range tokens {
+		if strings.EqualFold(tok, keyword) {
+			return i
+		}
+	}
+	return -1
+}
+
+// sqlIdent unquotes an identifier.
+func sqlIdent(tok string) string {
+	if len(tok) >= 2 && (tok[0] == '"' || tok[0] == '`') {
+		q := tok[:1]
+		return strings.ReplaceAll(tok[1:len(tok)-1], q+q, q)
+	}
+	return tok
+}
+
+// readQualifiedName reads schema.name or name at pos and returns the
+// unqualified name and the position after it.
+func readQualifiedName(tokens []string, pos int) (string, int) {
+	if pos >= len(tokens) {
+		return "", pos
+	}
+	name := sqlIdent(tokens[pos])
+	pos++
+	for pos+1 < len(tokens) && tokens[pos] == "." {
+		name = sqlIdent(tokens[pos+1])
+		pos += 2
+	}
+	return name, pos
+}
+
+// skipKeywords advances pos past any of the optional keywords.
+func skipKeywords(tokens []string, pos int, keywords ...string) int {
+	for pos < len(tokens) {
+		matched := false
+		for _, kw := range keywords {
+			if strings.EqualFold(tokens[pos], kw) {
+				pos++
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return pos
+		}
+	}
+	return pos
+}
+
+// readParenGroup returns the tokens between the parenthesis at pos and its
+// match, and the position after the closing parenthesis.
+func readParenGroup(tokens []string, pos int) ([]string, int, bool) {
+	if pos >= len(tokens) || tokens[pos] != "(" {
+		return nil, pos, false
+	}
+	depth := 0
+	for i := pos; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return tokens[pos+1 : i], i + 1, true
+			}
+		}
+	}
+	return nil, pos, false
+}
+
+// splitTopLevel splits tokens on commas that are not inside parentheses.
+func splitTopLevel(tokens []string) [][]string {
+	var parts [][]string
+	var current []string
+	depth := 0
+	for _, tok := range tokens {
+		switch tok {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case ",":
+			if depth == 0 {
+				parts = append(parts, current)
+				current = nil
+				continue
+			}
+		}
+		current = append(current, tok)
+	}
+	if len(current) > 0 {
+		parts = append(parts, current)
+	}
+	return parts
+}
+
+// readColumnList reads a parenthesised list of column names at pos.
+func readColumnList(tokens []string, pos int) ([]string, int) {
+	group, next, ok := readParenGroup(tokens, pos)
+	if !ok {
+		return nil, pos
+	}
+	var columns []string
+	for _, part := range splitTopLevel(group) {
+		if len(part) > 0 {
+			columns = append(columns, sqlIdent(part[0]))
+		}
+	}
+	return columns, next
+}
+
+// readReferences reads REFERENCES table [(columns)] at pos.
+func readReferences(tokens []string, pos int) (string, []string) {
+	if !sqlKeywordsAt(tokens, pos, "references") {
+		return "", nil
+	}
+	table, next := readQualifiedName(tokens, pos+1)
+	columns, _ := readColumnList(tokens, next)
+	if len(columns) == 0 {
+		columns = []string{"id"}
+	}
+	return table, columns
+}
+
+// parseCreateTable parses a CREATE TABLE statement. Tables without a column
+// list (CREATE TABLE ... AS, PARTITION OF) yield nil.
+func parseCreateTable(stmt []string) (*sqlTable, error) {
+	pos := sqlIndexOfKeyword(stmt, "table") + 1
+	pos = skipKeywords(stmt, pos, "if", "not", "exists")
+	name, pos := readQualifiedName(stmt, pos)
+	if name == "" {
+		return nil, fmt.Errorf("CREATE TABLE without a table name")
+	}
+
+	body, _, ok := readParenGroup(stmt, pos)
+	if !ok {
+		return nil, nil
+	}
+
+	table := &sqlTable{Name: name}
+	for _, def := range splitTopLevel(body) {
+		if len(def) == 0 {
+			continue
+		}
+		if sqlKeywordsAt(def, 0, "constraint") && len(def) > 2 {
+			def = def[2:]
+		}
+		switch {
+		case sqlKeywordsAt(def, 0, "primary", "key"):
+			table.PrimaryKey, _ = readColumnList(def, 2)
+		case sqlKeywordsAt(def, 0, "foreign", "key"):
+			columns, next := readColumnList(def, 2)
+			refTable, refColumns := readReferences(def, next)
+			table.ForeignKeys = append(table.ForeignKeys, sqlForeignKey{Columns: columns, RefTable: refTable, RefColumns: refColumns})
+		case sqlKeywordsAt(def, 0, "unique"):
+			pos := skipKeywords(def, 1, "key", "index")
+			indexName := ""
+			if pos < len(def) && def[pos] != "(" {
+				indexName = sqlIdent(def[pos])
+				pos++
+			}
+			columns, _ := readColumnList(def, pos)
+			table.Indexes = append(table.Indexes, sqlIndex{Name: indexName, Columns: columns, Unique: true})
+		case sqlKeywordsAt(def, 0, "key") || sqlKeywordsAt(def, 0, "index"):
+			pos := 1
+			indexName := ""
+			if pos < len(def) && def[pos] != "(" {
+				indexName = sqlIdent(def[pos])
+				pos++
+			}
+			columns, _ := readColumnList(def, pos)
+			table.Indexes = append(table.Indexes, sqlIndex{Name: indexName, Columns: columns})
+		case sqlKeywordsAt(def, 0, "check") || sqlKeywordsAt(def, 0, "exclude") || sqlKeywordsAt(def, 0, "like"):
+			// Not relevant for code generation.
+		default:
+			parseColumnDefinition(table, def)
+		}
+	}
+	return table, nil
+}
+
+// parseColumnDefinition adds the column described by def, together with its
+// inline PRIMARY KEY, UNIQUE and REFERENCES constraints.
+func parseColumnDefinition(table *sqlTable, def []string) {
+	column := sqlColumn{Name: sqlIdent(def[0])}
+
+	var typeWords []string
+	pos := 1
+	for pos < len(def) {
+		tok := def[pos]
+		if columnStopWords[strings.ToLower(tok)] {
+			break
+		}
+		if tok == "(" {
+			// Length, precision or enum values: not part of the base type.
+			_, next, ok := readParenGroup(def, pos)
+			if !ok {
+				break
+			}
+			pos = next
+			continue
+		}
+		word := strings.ToLower(sqlIdent(tok))
+		if strings.HasSuffix(word, "[]") || word == "array" || word == "[]" {
+			column.Array = true
+			word = strings.TrimSuffix(word, "[]")
+			if word == "array" {
+				word = ""
+			}
+		}
+		if word == "." {
+			// Schema-qualified type such as public.citext: keep the type name.
+			typeWords = typeWords[:0]
+		} else if word != "" {
+			typeWords = append(typeWords, word)
+		}
+		pos++
+	}
+	column.Type = strings.Join(typeWords, " ")
+
+	for ; pos < len(def); pos++ {
+		switch {
+		case sqlKeywordsAt(def, pos, "not", "null"):
+			column.NotNull = true
+			pos++
+		case sqlKeywordsAt(def, pos, "primary", "key"):
+			table.PrimaryKey = []string{column.Name}
+			column.NotNull = true
+			pos++
+		case sqlKeywordsAt(def, pos, "unique"):
+			table.Indexes = append(table.Indexes, sqlIndex{Columns: []string{column.Name}, Unique: true})
+		case sqlKeywordsAt(def, pos, "references"):
+			refTable, refColumns := readReferences(def, pos)
+			table.ForeignKeys = append(table.ForeignKeys, sqlForeignKey{Columns: []string{column.Name}, RefTable: refTable, RefColumns: refColumns})
+		}
+	}
+
+	table.Columns = append(table.Columns, column)
+}
+
+// applyAlterTable applies ALTER TABLE ... ADD [CONSTRAINT name] PRIMARY KEY,
+// FOREIGN KEY or UNIQUE, the form pg_dump uses for constraints.
+func applyAlterTable(schema *sqlSchema, stmt []string) {
+	pos := skipKeywords(stmt, 2, "only", "if", "exists")
+	name, pos := readQualifiedName(stmt, pos)
+	table := schema.table(name)
+	if table == nil {
+		return
+	}
+
+	for _, action := range splitTopLevel(stmt[pos:]) {
+		if !sqlKeywordsAt(action, 0, "add") {
+			continue
+		}
+		def := action[1:]
+		constraintName := ""
+		if sqlKeywordsAt(def, 0, "constraint") && len(def) > 2 {
+			constraintName = sqlIdent(def[1])
+			def = def[2:]
+		}
+		switch {
+		case sqlKeywordsAt(def, 0, "primary", "key"):
+			table.PrimaryKey, _ = readColumnList(def, 2)
+		case sqlKeywordsAt(def, 0, "foreign", "key"):
+			columns, next := readColumnList(def, 2)
+			refTable, refColumns := readReferences(def, next)
+			table.ForeignKeys = append(table.ForeignKeys, sqlForeignKey{Columns: columns, RefTable: refTable, RefColumns: refColumns})
+		case sqlKeywordsAt(def, 0, "unique"):
+			columns, _ := readColumnList(def, 1)
+			table.Indexes = append(table.Indexes, sqlIndex{Name: constraintName, Columns: columns, Unique: true})
+		}
+	}
+}
+
+// applyCreateIndex applies CREATE [UNIQUE] INDEX [name] ON table [USING m] (...).
+func applyCreateIndex(schema *sqlSchema, stmt []string) {
+	unique := sqlKeywordsAt(stmt, 1, "unique")
+	pos := skipKeywords(stmt, sqlIndexOfKeyword(stmt, "index")+1, "concurrently", "if", "not", "exists")
+
+	indexName := ""
+	if !sqlKeywordsAt(stmt, pos, "on") {
+		indexName, pos = readQualifiedName(stmt, pos)
+	}
+	if !sqlKeywordsAt(stmt, pos, "on") {
+		return
+	}
+	pos = skipKeywords(stmt, pos+1, "only")
+	name, pos := readQualifiedName(stmt, pos)
+	table := schema.table(name)
+	if table == nil {
+		return
+	}
+	if sqlKeywordsAt(stmt, pos, "using") {
+		pos += 2
+	}
+	columns, _ := readColumnList(stmt, pos)
+	table.Indexes = append(table.Indexes, sqlIndex{Name: indexName, Columns: columns, Unique: unique})
+}
+
+// sqlTypeToFieldType maps a SQL column type to a field DSL type. ok is false
+// for types that have no supported Go representation.
+func sqlTypeToFieldType(column sqlColumn) (string, bool) {
+	if column.Array {
+		return "", false
+	}
+	switch column.Type {
+	case "smallint", "int2", "smallserial", "serial2", "tinyint", "mediumint",
+		"integer", "int", "int4", "serial", "serial4":
+		return FieldInt, true
+	case "bigint", "int8", "bigserial", "serial8":
+		return FieldInt64, true
+	case "real", "float4":
+		return FieldFloat32, true
+	case "double precision", "float8", "float", "double", "numeric", "decimal", "money":
+		return FieldFloat64, true
+	case "boolean", "bool", "bit":
+		return FieldBool, true
+	case "date", "datetime", "time", "timetz", "timestamp", "timestamptz",
+		"timestamp with time zone", "timestamp without time zone",
+		"time with time zone", "time without time zone":
+		return FieldTime, true
+	case "json", "jsonb":
+		return FieldJSON, true
+	case "bytea", "blob", "tinyblob", "mediumblob", "longblob", "binary", "varbinary":
+		return FieldBytes, true
+	case "text", "tinytext", "mediumtext", "longtext", "varchar", "character varying",
+		"char", "character", "nchar", "nvarchar", "citext", "uuid", "inet", "cidr",
+		"macaddr", "xml", "enum", "set", "interval":
+		return FieldString, true
+	}
+	if column.Type == "" {
+		return "", false
+	}
+	// User-defined types in a dump are almost always enums or domains over text.
+	return FieldString, true
+}
+
+// sqlManagedColumns are populated by generated code rather than by callers.
+var sqlManagedColumns = map[string]string{
+	"id":         "primary key (entity ID)",
+	"created_at": "timestamp managed by the database",
+	"updated_at": "timestamp managed by the database",
+	"deleted_at": "soft-delete column",
+}
+
+// sqlFieldMapping is the outcome of mapping one column to the field DSL.
+type sqlFieldMapping struct {
+	Column sqlColumn
+	Field  string // DSL definition, empty when skipped
+	Note   string
+}
+
+// mapTableFields maps the columns of table to field DSL definitions. Foreign
+// keys become uint fields so they match the ID type of generated entities.
+func mapTableFields(table *sqlTable) []sqlFieldMapping {
+	validator := NewFieldValidator()
+	singlePK := ""
+	if len(table.PrimaryKey) == 1 {
+		singlePK = table.PrimaryKey[0]
+	}
+
+	mappings := make([]sqlFieldMapping, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		m := sqlFieldMapping{Column: column}
+		name := strings.ToLower(column.Name)
+		if reason, ok := sqlManagedColumns[name]; ok {
+			m.Note = "skipped: " + reason
+		} else if strings.EqualFold(column.Name, singlePK) {
+			m.Note = "skipped: primary key (entity ID)"
+		} else if err := validator.ValidateReservedNames(name); err != nil || !isSQLFieldName(name) {
+			m.Note = "skipped: not usable as a Go field name"
+		} else if fk, ok := table.foreignKey(column.Name); ok {
+			m.Field = name + ":" + FieldUint
+			m.Note = fmt.Sprintf("references %s(%s)", fk.RefTable, strings.Join(fk.RefColumns, ", "))
+		} else if fieldType, ok := sqlTypeToFieldType(column); ok {
+			m.Field = name + ":" + fieldType
+		} else {
+			m.Note = "skipped: unsupported type"
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings
+}
+
+// isSQLFieldName reports whether a column name can be used as-is in the field
+// DSL: a letter followed by letters, digits or underscores.
+func isSQLFieldName(name string) bool {
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z':
+		case i > 0 && (r == '_' || (r >= '0' && r <= '9')):
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+// tableFieldsSpec joins the mapped fields into a --fields string.
+func tableFieldsSpec(mappings []sqlFieldMapping) string {
+	var fields []string
+	for _, m := range mappings {
+		if m.Field != "" {
+			fields = append(fields, m.Field)
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// sqlEntityName derives a singular PascalCase entity name from a table name,
+// e.g. order_items -> OrderItem and categories -> Category.
+func sqlEntityName(table string) string {
+	words := strings.FieldsFunc(strings.ToLower(table), func(r rune) bool {
+		return r == '_' || r == '-' || r == ' '
+	})
+	if len(words) == 0 {
+		return ""
+	}
+	words[len(words)-1] = singularizeWord(words[len(words)-1])
+
+	var b strings.Builder
+	for _, w := range words {
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return b.String()
+}
+
+// singularizeWord applies common English plural rules to a lowercase word.
+func singularizeWord(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"),
+		strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+		return word
+	case strings.HasSuffix(word, "s") && len(word) > 1:
+		return word[:len(word)-1]
+	}
+	return word
+}
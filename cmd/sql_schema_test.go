@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pgDumpSchema mirrors pg_dump --schema-only output: constraints and indexes
+// are separate statements and names are schema-qualified.
+const pgDumpSchema = `--
+-- PostgreSQL database dump
+--
+SET statement_timeout = 0;
+CREATE TYPE public.order_status AS ENUM ('pending', 'paid');
+CREATE FUNCTION public.touch() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN NEW.updated_at = now(); RETURN NEW; END; $$;
+
+CREATE TABLE public.customers (
+    id bigint NOT NULL,
+    full_name character varying(120) NOT NULL,
+    email text NOT NULL,
+    preferences jsonb,
+    tags text[],
+    "type" text,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+CREATE TABLE public.orders (
+    id bigint NOT NULL,
+    customer_id bigint NOT NULL,
+    status public.order_status DEFAULT 'pending'::public.order_status NOT NULL,
+    total numeric(10,2) NOT NULL,
+    placed_on date
+);
+
+ALTER TABLE ONLY public.customers ALTER COLUMN id SET DEFAULT nextval('public.customers_id_seq'::regclass);
+ALTER TABLE ONLY public.customers
+    ADD CONSTRAINT customers_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.customers
+    ADD CONSTRAINT customers_email_key UNIQUE (email);
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_customer_id_fkey FOREIGN KEY (customer_id) REFERENCES public.customers(id) ON DELETE CASCADE;
+CREATE INDEX idx_orders_status ON public.orders USING btree (status);
+`
+
+func TestParseSQLSchema_PgDump(t *testing.T) {
+	schema, err := parseSQLSchema(pgDumpSchema)
+	require.NoError(t, err)
+	require.Len(t, schema.Tables, 2)
+
+	customers := schema.table("customers")
+	require.NotNil(t, customers)
+	assert.Equal(t, []string{"id"}, customers.PrimaryKey)
+	assert.Equal(t, sqlColumn{Name: "full_name", Type: "character varying", NotNull: true}, customers.Columns[1])
+	assert.True(t, customers.Columns[4].Array)
+	assert.Equal(t, "type", customers.Columns[5].Name)
+	assert.Equal(t, "timestamp with time zone", customers.Columns[6].Type)
+	assert.Equal(t, []sqlIndex{{Name: "customers_email_key", Columns: []string{"email"}, Unique: true}}, customers.Indexes)
+
+	orders := schema.table("orders")
+	require.NotNil(t, orders)
+	assert.Equal(t, "order_status", orders.Columns[2].Type)
+	assert.Equal(t, []sqlForeignKey{{Columns: []string{"customer_id"}, RefTable: "customers", RefColumns: []string{"id"}}}, orders.ForeignKeys)
+	assert.Equal(t, []sqlIndex{{Name: "idx_orders_status", Columns: []string{"status"}}}, orders.Indexes)
+}
+
+func TestParseSQLSchema_InlineConstraints(t *testing.T) {
+	schema, err := parseSQLSchema("CREATE TABLE IF NOT EXISTS `order_items` (\n" +
+		"  id SERIAL,\n" +
+		"  order_id INTEGER NOT NULL REFERENCES orders,\n" +
+		"  sku VARCHAR(64) NOT NULL UNIQUE,\n" +
+		"  price DECIMAL(10, 2),\n" +
+		"  PRIMARY KEY (id),\n" +
+		"  CONSTRAINT uq_item UNIQUE (order_id, sku),\n" +
+		"  KEY idx_price (price)\n" +
+		") ENGINE=InnoDB;")
+	require.NoError(t, err)
+
+	items := schema.Tables[0]
+	assert.Equal(t, "order_items", items.Name)
+	assert.Equal(t, []string{"id"}, items.PrimaryKey)
+	require.Len(t, items.Columns, 4)
+	assert.Equal(t, "decimal", items.Columns[3].Type)
+	assert.Equal(t, []sqlForeignKey{{Columns: []string{"order_id"}, RefTable: "orders", RefColumns: []string{"id"}}}, items.ForeignKeys)
+	assert.Equal(t, []sqlIndex{
+		{Columns: []string{"sku"}, Unique: true},
+		{Columns: []string{"order_id", "sku"}, Unique: true},
+		{Name: "idx_price", Columns: []string{"price"}},
+	}, items.Indexes)
+
+	_, err = parseSQLSchema("CREATE INDEX idx ON t (a);")
+	assert.Error(t, err, "a schema without tables is rejected")
+	_, err = parseSQLSchema("CREATE TABLE t (a text DEFAULT 'x);")
+	assert.Error(t, err)
+}
+
+func TestMapTableFields(t *testing.T) {
+	schema, err := parseSQLSchema(pgDumpSchema)
+	require.NoError(t, err)
+
+	customers := mapTableFields(schema.table("customers"))
+	assert.Equal(t, "full_name:string,email:string,preferences:json", tableFieldsSpec(customers))
+	assert.Contains(t, customers[4].Note, "unsupported")
+	assert.Contains(t, customers[5].Note, "not usable")
+
+	orders := mapTableFields(schema.table("orders"))
+	assert.Equal(t, "customer_id:uint,status:string,total:float64,placed_on:time.Time", tableFieldsSpec(orders))
+	assert.Equal(t, "references customers(id)", orders[1].Note)
+	assert.NoError(t, NewFieldValidator().ValidateFields(tableFieldsSpec(orders)))
+}
+
+func TestSQLEntityName(t *testing.T) {
+	for table, entity := range map[string]string{
+		"customers":   "Customer",
+		"order_items": "OrderItem",
+		"categories":  "Category",
+		"addresses":   "Address",
+		"boxes":       "Box",
+		"status":      "Status",
+		"person":      "Person",
+	} {
+		assert.Equal(t, entity, sqlEntityName(table), table)
+	}
+}
+
+func TestGenerateTableNameMethod(t *testing.T) {
+	chdirTemp(t)
+	require.NoError(t, generateTableNameMethod("OrderItem", "order_items"))
+
+	content := readParsedGo(t, filepath.Join("internal", "domain", "orderitem_table.go"))
+	assert.Contains(t, content, "func (OrderItem) TableName() string {\n\treturn \"order_items\"")
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// errGeneratedChanged is returned by goca status --exit-code when a
+// generated file was modified or removed.
+var errGeneratedChanged = errors.New("generated files were modified or removed")
+
+// untrackedDirs are the directories goca generates into, searched for files
+// the lock does not track.
+var untrackedDirs = []string{DirInternal, "cmd", "pkg", "migrations"}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the generated files that were modified, removed or are untracked",
+	Long: `Compares the files recorded in .goca.lock with the ones on disk:
+
+  modified   The file changed since goca last wrote it
+  missing    The file was removed
+  untracked  A file under internal/, cmd/, pkg/ or migrations/ that no goca
+             command recorded, such as hand-written code or a file generated
+             before the project had a lock
+
+Every goca command that writes files records them in .goca.lock with the
+command and options that generated them and the SHA-256 of their content.
+Commit the lock with the project, and run goca status before regenerating a
+feature to see which changes goca regen would overwrite.
+
+Examples:
+  goca status
+  goca status --exit-code`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		exitCode, _ := cmd.Flags().GetBool("exit-code")
+
+		root, lock, err := loadProjectLock()
+		if err != nil {
+			return err
+		}
+		status := compareGenerationLock(root, lock)
+
+		ui.Header("Goca Status")
+		ui.Blank()
+		printLockStatus(lock, status)
+		if exitCode && len(status.modified)+len(status.missing) > 0 {
+			return errGeneratedChanged
+		}
+		return nil
+	},
+}
+
+func init() {
+	statusCmd.Flags().Bool("exit-code", false, "Exit with an error when a generated file was modified or removed, for CI")
+}
+
+// lockStatus is the state of the generated files of a project.
+type lockStatus struct {
+	modified  []string
+	missing   []string
+	untracked []string
+	unchanged int
+}
+
+// loadProjectLock reads the lock of the project the working directory
+// belongs to.
+func loadProjectLock() (string, *generationLock, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", nil, err
+	}
+	root, ok := findLockRoot(wd)
+	if !ok {
+		return "", nil, fmt.Errorf("not in a goca project: no .goca.yaml or go.mod found")
+	}
+	if _, err := os.Stat(filepath.Join(root, generationLockFile)); err != nil {
+		return "", nil, fmt.Errorf("%s has no %s; it is written by the next goca command that generates files", root, generationLockFile)
+	}
+	lock, err := readGenerationLock(root)
+	return root, lock, err
+}
+
+// compareGenerationLock compares the files of lock with those of the project
+// at root.
+func compareGenerationLock(root string, lock *generationLock) lockStatus {
+	var status lockStatus
+	for path, f := range lock.Files {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(path)))
+		if err != nil {
+			status.missing = append(status.missing, path)
+			continue
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != f.SHA256 {
+			status.modified = append(status.modified, path)
+			continue
+		}
+		status.unchanged++
+	}
+
+	for _, dir := range untrackedDirs {
+		_ = filepath.WalkDir(filepath.Join(root, dir), func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return nil
+			}
+			if _, tracked := lock.Files[filepath.ToSlash(rel)]; !tracked {
+				status.untracked = append(status.untracked, filepath.ToSlash(rel))
+			}
+			return nil
+		})
+	}
+
+	sort.Strings(status.modified)
+	sort.Strings(status.missing)
+	sort.Strings(status.untracked)
+	return status
+}
+
+// printLockStatus prints the modified, missing and untracked files.
+func printLockStatus(lock *generationLock, status lockStatus) {
+	describe := func(path string) string {
+		f := lock.Files[path]
+		origin := "goca " + f.Generator
+		if f.Feature != "" {
+			origin += " " + f.Feature
+		}
+		return fmt.Sprintf("  %s  (%s)", path, origin)
+	}
+	if len(status.modified) > 0 {
+		ui.Section("Modified")
+		for _, path := range status.modified {
+			ui.Println(describe(path))
+		}
+		ui.Blank()
+	}
+	if len(status.missing) > 0 {
+		ui.Section("Missing")
+		for _, path := range status.missing {
+			ui.Println(describe(path))
+		}
+		ui.Blank()
+	}
+	if len(status.untracked) > 0 {
+		ui.Section("Untracked")
+		for _, path := range status.untracked {
+			ui.Println("  " + path)
+		}
+		ui.Blank()
+	}
+
+	ui.KeyValue("Unchanged", fmt.Sprintf("%d", status.unchanged))
+	ui.KeyValue("Modified", fmt.Sprintf("%d", len(status.modified)))
+	ui.KeyValue("Missing", fmt.Sprintf("%d", len(status.missing)))
+	ui.KeyValue("Untracked", fmt.Sprintf("%d", len(status.untracked)))
+	ui.Blank()
+	if len(status.modified)+len(status.missing) == 0 {
+		ui.Success("Every generated file is as goca wrote it")
+		return
+	}
+	ui.Warning("Regenerating these features with goca regen would overwrite the changes")
+}
@@ -0,0 +1,838 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// storageConfigDecl declares the file storage settings of pkg/config.
+const storageConfigDecl = `// StorageConfig selects where uploaded files are kept.
+type StorageConfig struct {
+	// Backend is local, s3 or gcs.
+	Backend string
+	// Bucket is the S3 or GCS bucket.
+	Bucket string
+	// Region is the S3 region. The AWS configuration's region by default.
+	Region string
+	// Endpoint is the URL of an S3-compatible service such as MinIO.
+	Endpoint string
+	// LocalDir is the directory the local backend writes to.
+	LocalDir string
+	// BaseURL is the URL the local backend serves files under.
+	BaseURL string
+	// SigningKey signs the URLs of the local backend. Without it, URLs are
+	// signed with a random key and stop working when the process restarts.
+	SigningKey string
+	// URLExpiry is how long a signed URL stays valid.
+	URLExpiry time.Duration
+	// MaxUploadSize bounds the size of a request with uploads, in bytes.
+	MaxUploadSize int64
+}
+`
+
+// storageConfigLoad is the Storage element of the Config literal Load returns.
+const storageConfigLoad = `		Storage: StorageConfig{
+			Backend:       getEnv("STORAGE_BACKEND", "local"),
+			Bucket:        getEnv("STORAGE_BUCKET", ""),
+			Region:        getEnv("STORAGE_REGION", ""),
+			Endpoint:      getEnv("STORAGE_ENDPOINT", ""),
+			LocalDir:      getEnv("STORAGE_LOCAL_DIR", "uploads"),
+			BaseURL:       getEnv("STORAGE_BASE_URL", "/api/v1/files"),
+			SigningKey:    getEnv("STORAGE_SIGNING_KEY", ""),
+			URLExpiry:     getEnvAsDuration("STORAGE_URL_EXPIRY", "15m"),
+			MaxUploadSize: int64(getEnvAsInt("STORAGE_MAX_UPLOAD_SIZE", 32<<20)),
+		},
+`
+
+// entityFileFields returns the file fields of the entity in internal/domain.
+func entityFileFields(entity string) []Field {
+	path, err := findEntityFile(entity)
+	if err != nil {
+		return nil
+	}
+	s, err := parseGoSource(path)
+	if err != nil {
+		return nil
+	}
+	var files []Field
+	for _, field := range readEntityFields(s.file, entity) {
+		if isFileField(field) {
+			files = append(files, field)
+		}
+	}
+	return files
+}
+
+// generateDomainFileType writes internal/domain/file.go, the File type file
+// fields embed. Like repository_errors.go, it is only rewritten with --force.
+func generateDomainFileType(dir string, sm ...*SafetyManager) {
+	filename := filepath.Join(dir, "file.go")
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+	if _, err := os.Stat(filename); err == nil && !force {
+		return
+	}
+	if err := writeGoFile(filename, domainFileSource, sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing file type: %v", err))
+	}
+}
+
+const domainFileSource = `package domain
+
+// File is the metadata of an uploaded file kept in pkg/storage. Entities
+// embed it for their file fields, in "<field>_" prefixed columns.
+type File struct {
+	Key         string ` + "`json:\"key\"`" + `
+	URL         string ` + "`json:\"url\"`" + `
+	Size        int64  ` + "`json:\"size\"`" + `
+	ContentType string ` + "`json:\"content_type\"`" + `
+}
+`
+
+// generateStoragePackage writes pkg/storage, where HTTP handlers stream
+// uploads to, and adds its settings to pkg/config. Each file is only
+// rewritten with --force, so a backend can be edited or removed.
+func generateStoragePackage(sm ...*SafetyManager) {
+	dir := filepath.Join(DirPkg, "storage")
+	_ = os.MkdirAll(dir, 0o755)
+	moduleName := getImportPath(getModuleName())
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+
+	for _, file := range []struct{ name, template string }{
+		{"storage.go", storageTemplate},
+		{"local.go", storageLocalTemplate},
+		{"s3.go", storageS3Template},
+		{"gcs.go", storageGCSTemplate},
+	} {
+		filename := filepath.Join(dir, file.name)
+		if _, err := os.Stat(filename); err == nil && !force {
+			continue
+		}
+		if err := writeGoFile(filename, fmt.Sprintf(file.template, moduleName), sm...); err != nil {
+			ui.Error(fmt.Sprintf("Error writing %s: %v", filename, err))
+		}
+	}
+
+	if err := ensureConfigSection("Storage", "StorageConfig", storageConfigDecl, storageConfigLoad, sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Could not add the storage settings to pkg/config: %v", err))
+	}
+}
+
+// generateHTTPFilesFile writes internal/handler/http/files.go with the
+// helpers handlers of entities with file fields decode uploads and sign URLs
+// with. Like validation.go, it is only rewritten with --force.
+func generateHTTPFilesFile(dir string, sm ...*SafetyManager) {
+	filename := filepath.Join(dir, "files.go")
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+	if _, err := os.Stat(filename); err == nil && !force {
+		return
+	}
+	content := fmt.Sprintf(httpFilesTemplate, getImportPath(getModuleName()))
+	if strict, _ := projectStrictDecoding(); strict {
+		content = strictFilesDecoding.Replace(content)
+	}
+	if err := writeGoFile(filename, content, sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing files helpers: %v", err))
+	}
+}
+
+// strictFilesDecoding makes decodeRequest of files.go read the JSON input
+// with decodeStrict of decode.go.
+var strictFilesDecoding = strings.NewReplacer(
+	"\t\"encoding/json\"\n", "",
+	"json.NewDecoder(r.Body).Decode(input)", "decodeStrict(r.Body, input)",
+	"json.NewDecoder(part).Decode(input)", "decodeStrict(part, input)",
+)
+
+// writeRequestDecoding writes the statements of a Create or Update handler
+// that read the request into input. Entities with file fields also accept
+// multipart/form-data requests, whose files are stored before the use case
+// is called. With api.strict_decoding the JSON input is decoded by
+// decodeStrict of decode.go.
+func writeRequestDecoding(content *strings.Builder, entity, op, format string) {
+	files := entityFileFields(entity)
+	strict, _ := projectStrictDecoding()
+	if len(files) == 0 && strict {
+		writeStrictRequestDecoding(content)
+		return
+	}
+	if len(files) == 0 {
+		content.WriteString("\tif err := json.NewDecoder(r.Body).Decode(&input); err != nil {\n")
+		writeHandlerError(content, format, "http.StatusBadRequest", `"Invalid request body"`)
+		content.WriteString("\t\treturn\n")
+		content.WriteString("\t}\n\n")
+		return
+	}
+
+	names := make([]string, len(files))
+	for i, field := range files {
+		names[i] = fmt.Sprintf("%q", jsonTagName(field.Tag))
+	}
+	fmt.Fprintf(content, "\tfiles, err := decodeRequest(w, r, &input, %q, %s)\n", toPlural(strings.ToLower(entity)), strings.Join(names, ", "))
+	content.WriteString("\tif err != nil {\n")
+	content.WriteString("\t\tstatus, message := requestError(err)\n")
+	if strict {
+		// Rejected bodies are answered with their fields.
+		content.WriteString("\t\tif status == http.StatusBadRequest {\n")
+		content.WriteString("\t\t\trespondBodyError(w, r, err)\n")
+		content.WriteString("\t\t\treturn\n")
+		content.WriteString("\t\t}\n")
+	}
+	writeHandlerError(content, format, "status", "message")
+	content.WriteString("\t\treturn\n")
+	content.WriteString("\t}\n")
+	for _, field := range files {
+		fmt.Fprintf(content, "\tif file, ok := files[%q]; ok {\n", jsonTagName(field.Tag))
+		if op == OpUpdate {
+			fmt.Fprintf(content, "\t\tinput.%s = &file\n", field.Name)
+		} else {
+			fmt.Fprintf(content, "\t\tinput.%s = file\n", field.Name)
+		}
+		content.WriteString("\t}\n")
+	}
+	content.WriteString("\n")
+}
+
+// writeUploadCleanup writes the statement deleting the files of a request
+// whose use case call failed, for entities with file fields.
+func writeUploadCleanup(content *strings.Builder, entity string) {
+	if len(entityFileFields(entity)) > 0 {
+		content.WriteString("\t\tdiscardFiles(r.Context(), files)\n")
+	}
+}
+
+// generateFileHandlerMethod writes GET /<entities>/{id}/<field>, which
+// redirects to a signed URL of the file.
+func generateFileHandlerMethod(content *strings.Builder, entity, handlerName string, field Field, swagger, acting bool, format string) {
+	handlerVar := strings.ToLower(string(handlerName[0]))
+	entityLower := strings.ToLower(entity)
+	column := jsonTagName(field.Tag)
+
+	if swagger {
+		writeSwaggerAnnotations(content, entity, fmt.Sprintf("Download %s %s", entityLower, column), "get", "/"+toPlural(entityLower)+"/{id}/"+column, "307", "", "", format)
+	}
+
+	fmt.Fprintf(content, "func (%s *%s) Get%s%s(w http.ResponseWriter, r *http.Request) {\n", handlerVar, handlerName, entity, field.Name)
+	writeHandlerID(content, entity, format)
+
+	fmt.Fprintf(content, "\t%s, err := %s.Get%s(id)\n", entityLower, handlerUseCase(handlerVar, acting), entity)
+	content.WriteString("\tif err != nil {\n")
+	writeUseCaseError(content, format, "http.StatusNotFound")
+	content.WriteString("\t\treturn\n")
+	content.WriteString("\t}\n")
+	fmt.Fprintf(content, "\tif %s.%s.Key == \"\" {\n", entityLower, field.Name)
+	writeHandlerError(content, format, "http.StatusNotFound", fmt.Sprintf("\"%s has no %s\"", entityLower, column))
+	content.WriteString("\t\treturn\n")
+	content.WriteString("\t}\n\n")
+
+	fmt.Fprintf(content, "\tlocation, err := signedFileURL(r.Context(), %s.%s)\n", entityLower, field.Name)
+	content.WriteString("\tif err != nil {\n")
+	writeHandlerError(content, format, "http.StatusInternalServerError", fmt.Sprintf("\"Could not sign the %s URL\"", column))
+	content.WriteString("\t\treturn\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\thttp.Redirect(w, r, location, http.StatusTemporaryRedirect)\n")
+	content.WriteString("}\n\n")
+}
+
+// writeFileRoutes registers the download route of each file field, and the
+// route serving the files of the local storage backend.
+func writeFileRoutes(content *strings.Builder, entity, routerVar, prefix string) {
+	files := entityFileFields(entity)
+	if len(files) == 0 {
+		return
+	}
+	for _, field := range files {
+		fmt.Fprintf(content, "\t%s.HandleFunc(\"%s/{id}/%s\", handler.Get%s%s).Methods(\"GET\")\n", routerVar, prefix, jsonTagName(field.Tag), entity, field.Name)
+	}
+	content.WriteString("\tmountFiles(router)\n")
+}
+
+const httpFilesTemplate = `package http
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+
+	"%[1]s/internal/domain"
+	"%[1]s/pkg/config"
+	"%[1]s/pkg/storage"
+)
+
+// errStorage wraps the failures of the file storage, which are not the
+// client's fault.
+var errStorage = errors.New("file storage failed")
+
+// fileStore is the storage uploaded files are kept in, opened from pkg/config
+// on first use.
+var fileStore struct {
+	once    sync.Once
+	cfg     config.StorageConfig
+	storage storage.Storage
+	err     error
+}
+
+// UseStorage makes handlers keep uploaded files in s instead of the storage
+// configured in pkg/config, e.g. in tests. Call it before serving requests.
+func UseStorage(s storage.Storage) {
+	fileStore.once.Do(func() { fileStore.cfg = config.Load().Storage })
+	fileStore.storage, fileStore.err = s, nil
+}
+
+func openStorage() (storage.Storage, config.StorageConfig, error) {
+	fileStore.once.Do(func() {
+		fileStore.cfg = config.Load().Storage
+		fileStore.storage, fileStore.err = storage.New(context.Background(), fileStore.cfg)
+	})
+	if fileStore.err != nil {
+		return nil, fileStore.cfg, fmt.Errorf("%%w: %%v", errStorage, fileStore.err)
+	}
+	return fileStore.storage, fileStore.cfg, nil
+}
+
+// decodeRequest decodes a JSON request body into input. A multipart/form-data
+// request carries input as JSON in its "data" part, and the files of fields
+// in parts named after them, which are streamed to the storage under prefix.
+// It returns the stored files by field.
+func decodeRequest(w http.ResponseWriter, r *http.Request, input any, prefix string, fields ...string) (map[string]domain.File, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return nil, json.NewDecoder(r.Body).Decode(input)
+	}
+
+	store, cfg, err := openStorage()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MaxUploadSize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, cfg.MaxUploadSize)
+	}
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]domain.File)
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err == nil {
+			name := part.FormName()
+			switch {
+			case name == "data":
+				err = json.NewDecoder(part).Decode(input)
+			case slices.Contains(fields, name) && part.FileName() != "":
+				var file domain.File
+				if file, err = storeFile(r.Context(), store, prefix+"/"+name, part); err == nil {
+					files[name] = file
+				}
+			}
+			part.Close()
+		}
+		if err != nil {
+			discardFiles(r.Context(), files)
+			return nil, err
+		}
+	}
+}
+
+// requestError returns the status and message answering a decodeRequest
+// error.
+func requestError(err error) (int, string) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		return http.StatusRequestEntityTooLarge, "Request body is too large"
+	case errors.Is(err, errStorage):
+		return http.StatusInternalServerError, "Could not store the uploaded files"
+	default:
+		return http.StatusBadRequest, "Invalid request body"
+	}
+}
+
+// storeFile streams part to the storage under a random key in dir, keeping
+// the extension of its file name. The content type is sniffed when the
+// client did not send one.
+func storeFile(ctx context.Context, store storage.Storage, dir string, part *multipart.Part) (domain.File, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return domain.File{}, fmt.Errorf("%%w: %%v", errStorage, err)
+	}
+	key := dir + "/" + hex.EncodeToString(id) + fileExtension(part.FileName())
+
+	body := bufio.NewReader(part)
+	contentType := part.Header.Get("Content-Type")
+	if contentType == "" || contentType == "application/octet-stream" {
+		head, _ := body.Peek(512)
+		contentType = http.DetectContentType(head)
+	}
+
+	object, err := store.Put(ctx, key, body, contentType)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return domain.File{}, err
+		}
+		return domain.File{}, fmt.Errorf("%%w: %%v", errStorage, err)
+	}
+	return domain.File{Key: object.Key, URL: object.URL, Size: object.Size, ContentType: object.ContentType}, nil
+}
+
+var extensionPattern = regexp.MustCompile(` + "`^\\.[a-z0-9]{1,10}$`" + `)
+
+// fileExtension returns the extension of an uploaded file's name, or "" when
+// it is not a plain one.
+func fileExtension(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if !extensionPattern.MatchString(ext) {
+		return ""
+	}
+	return ext
+}
+
+// discardFiles deletes the files of a request that failed, even when the
+// client went away.
+func discardFiles(ctx context.Context, files map[string]domain.File) {
+	store, _, err := openStorage()
+	if err != nil {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	for _, file := range files {
+		_ = store.Delete(ctx, file.Key)
+	}
+}
+
+// signedFileURL returns a URL file can be downloaded from until it expires.
+func signedFileURL(ctx context.Context, file domain.File) (string, error) {
+	store, cfg, err := openStorage()
+	if err != nil {
+		return "", err
+	}
+	return store.SignedURL(ctx, file.Key, cfg.URLExpiry)
+}
+
+// mountFiles serves the files of the local storage backend under /files/,
+// where its signed URLs point. It is mounted once, whatever the number of
+// entities with files.
+func mountFiles(router *mux.Router) {
+	if router.Get("files") != nil {
+		return
+	}
+	router.PathPrefix("/files/").Name("files").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		store, _, err := openStorage()
+		local, ok := store.(http.Handler)
+		if err != nil || !ok {
+			http.NotFound(w, r)
+			return
+		}
+		local.ServeHTTP(w, r)
+	})
+}
+`
+
+const storageTemplate = `// Package storage keeps uploaded files on the local disk, in Amazon S3 or in
+// Google Cloud Storage, as STORAGE_BACKEND selects.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"%s/pkg/config"
+)
+
+// Object is a stored file.
+type Object struct {
+	Key         string
+	URL         string
+	Size        int64
+	ContentType string
+}
+
+// Storage keeps files by key.
+type Storage interface {
+	// Put streams r to the file at key.
+	Put(ctx context.Context, key string, r io.Reader, contentType string) (Object, error)
+	// SignedURL returns a URL the file at key can be downloaded from until
+	// expiry elapses.
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+	// Delete removes the file at key.
+	Delete(ctx context.Context, key string) error
+}
+
+// New opens the storage of cfg.Backend.
+func New(ctx context.Context, cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Backend {
+	case "", "local":
+		local, err := NewLocal(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return local, nil
+	case "s3":
+		s3, err := NewS3(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return s3, nil
+	case "gcs":
+		gcs, err := NewGCS(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return gcs, nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %%q; use local, s3 or gcs", cfg.Backend)
+	}
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+`
+
+const storageLocalTemplate = `package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"%s/pkg/config"
+)
+
+// Local keeps files in a directory and serves them, under BaseURL, to the
+// holders of a URL it signed.
+type Local struct {
+	dir     string
+	baseURL string
+	prefix  string
+	key     []byte
+}
+
+// NewLocal returns the local storage of cfg.
+func NewLocal(cfg config.StorageConfig) (*Local, error) {
+	if err := os.MkdirAll(cfg.LocalDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create storage directory: %%w", err)
+	}
+	base, err := url.Parse(cfg.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse storage base URL: %%w", err)
+	}
+	key := []byte(cfg.SigningKey)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+	}
+	return &Local{
+		dir:     cfg.LocalDir,
+		baseURL: strings.TrimSuffix(cfg.BaseURL, "/"),
+		prefix:  strings.TrimSuffix(base.Path, "/") + "/",
+		key:     key,
+	}, nil
+}
+
+// Put writes r to a temporary file, renamed to key once complete.
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, contentType string) (Object, error) {
+	if err := ctx.Err(); err != nil {
+		return Object{}, err
+	}
+	path, err := l.path(key)
+	if err != nil {
+		return Object{}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return Object{}, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return Object{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Object{}, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return Object{}, err
+	}
+	return Object{Key: key, URL: l.baseURL + "/" + key, Size: size, ContentType: contentType}, nil
+}
+
+// SignedURL returns the URL of the file with its expiry and signature.
+func (l *Local) SignedURL(_ context.Context, key string, expiry time.Duration) (string, error) {
+	if _, err := l.path(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+	query := url.Values{"expires": {expires}, "signature": {l.sign(key, expires)}}
+	return l.baseURL + "/" + key + "?" + query.Encode(), nil
+}
+
+// Delete removes the file at key. A missing file is not an error.
+func (l *Local) Delete(_ context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// ServeHTTP serves the file of a URL signed by SignedURL that has not
+// expired.
+func (l *Local) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, l.prefix)
+	expires := r.URL.Query().Get("expires")
+	signature := r.URL.Query().Get("signature")
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix || !hmac.Equal([]byte(signature), []byte(l.sign(key, expires))) {
+		http.Error(w, "invalid or expired file URL", http.StatusForbidden)
+		return
+	}
+
+	path, err := l.path(key)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
+
+func (l *Local) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, l.key)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path returns the file of key, which must stay inside the directory.
+func (l *Local) path(key string) (string, error) {
+	rel := filepath.FromSlash(key)
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("invalid storage key %%q", key)
+	}
+	return filepath.Join(l.dir, rel), nil
+}
+`
+
+const storageS3Template = `package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"%s/pkg/config"
+)
+
+// S3 keeps files in an Amazon S3 bucket, or in a bucket of a compatible
+// service such as MinIO when cfg.Endpoint is set. Credentials are read from
+// the environment, the shared AWS configuration or the instance role.
+type S3 struct {
+	client   *s3.Client
+	presign  *s3.PresignClient
+	uploader *manager.Uploader
+	bucket   string
+}
+
+// NewS3 returns the S3 storage of cfg.
+func NewS3(ctx context.Context, cfg config.StorageConfig) (*S3, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("STORAGE_BUCKET is required by the s3 storage backend")
+	}
+	var opts []func(*awsconfig.LoadOptions) error
+	if cfg.Region != "" {
+		opts = append(opts, awsconfig.WithRegion(cfg.Region))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("load AWS configuration: %%w", err)
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+			o.UsePathStyle = true
+		}
+	})
+	return &S3{
+		client:   client,
+		presign:  s3.NewPresignClient(client),
+		uploader: manager.NewUploader(client),
+		bucket:   cfg.Bucket,
+	}, nil
+}
+
+// Put uploads r in parts, so its size does not need to be known up front.
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, contentType string) (Object, error) {
+	body := &countingReader{r: r}
+	out, err := s.uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return Object{}, err
+	}
+	return Object{Key: key, URL: out.Location, Size: body.n, ContentType: contentType}, nil
+}
+
+// SignedURL returns a presigned GET URL of the object.
+func (s *S3) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	req, err := s.presign.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(expiry))
+	if err != nil {
+		return "", err
+	}
+	return req.URL, nil
+}
+
+// Delete removes the object at key.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+`
+
+const storageGCSTemplate = `package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	gcs "cloud.google.com/go/storage"
+
+	"%s/pkg/config"
+)
+
+// GCS keeps files in a Google Cloud Storage bucket. It authenticates with the
+// application default credentials, which must be a service account to sign
+// URLs.
+type GCS struct {
+	bucket *gcs.BucketHandle
+	name   string
+}
+
+// NewGCS returns the GCS storage of cfg.
+func NewGCS(ctx context.Context, cfg config.StorageConfig) (*GCS, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("STORAGE_BUCKET is required by the gcs storage backend")
+	}
+	client, err := gcs.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create GCS client: %%w", err)
+	}
+	return &GCS{bucket: client.Bucket(cfg.Bucket), name: cfg.Bucket}, nil
+}
+
+// Put streams r to the object. A failed upload is abandoned, not committed.
+func (g *GCS) Put(ctx context.Context, key string, r io.Reader, contentType string) (Object, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := g.bucket.Object(key).NewWriter(ctx)
+	w.ContentType = contentType
+	size, err := io.Copy(w, r)
+	if err != nil {
+		cancel()
+		_ = w.Close()
+		return Object{}, err
+	}
+	if err := w.Close(); err != nil {
+		return Object{}, err
+	}
+	url := fmt.Sprintf("https://storage.googleapis.com/%%s/%%s", g.name, key)
+	return Object{Key: key, URL: url, Size: size, ContentType: contentType}, nil
+}
+
+// SignedURL returns a V4 signed GET URL of the object.
+func (g *GCS) SignedURL(_ context.Context, key string, expiry time.Duration) (string, error) {
+	return g.bucket.SignedURL(key, &gcs.SignedURLOptions{
+		Scheme:  gcs.SigningSchemeV4,
+		Method:  http.MethodGet,
+		Expires: time.Now().Add(expiry),
+	})
+}
+
+// Delete removes the object at key.
+func (g *GCS) Delete(ctx context.Context, key string) error {
+	return g.bucket.Object(key).Delete(ctx)
+}
+`
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateHTTPHandler_FileFields(t *testing.T) {
+	chdirTemp(t)
+	defer ensureTestUI(t)()
+	writeTestFile(t, ".", filepath.Join("pkg", "config", "config.go"), legacyConfig)
+
+	fields := "Title:string,Avatar:file"
+	parsed := parseFields(fields)
+	require.Equal(t, FieldFileType, parsed[len(parsed)-1].Type)
+
+	sm := NewSafetyManager(false, true, false)
+	require.NoError(t, generateEntity("Profile", fields, true, false, false, false, false, false, false, true, "lowercase", sm))
+	generateUseCaseWithFields("ProfileUseCase", "Profile", "create,read,update,delete,list", false, false, fields, "standard", sm)
+	generateHTTPHandler("Profile", false, false, false, "lowercase", sm)
+
+	entity := readParsedGo(t, filepath.Join(DirInternal, DirDomain, "profile.go"))
+	assert.Contains(t, entity, "Avatar File   `json:\"avatar\" gorm:\"embedded;embeddedPrefix:avatar_\"`")
+	assert.FileExists(t, filepath.Join(DirInternal, DirDomain, "file.go"))
+
+	dto := readParsedGo(t, filepath.Join(DirInternal, DirUseCase, "dto.go"))
+	assert.Contains(t, dto, "Avatar domain.File `json:\"-\"`")
+
+	dir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	handler := readParsedGo(t, filepath.Join(dir, "profile_handler.go"))
+	assert.Contains(t, handler, `files, err := decodeRequest(w, r, &input, "profiles", "avatar")`)
+	assert.Contains(t, handler, "input.Avatar = &file")
+	assert.Contains(t, handler, "discardFiles(r.Context(), files)")
+	assert.Contains(t, handler, "location, err := signedFileURL(r.Context(), profile.Avatar)")
+
+	routes := readParsedGo(t, filepath.Join(dir, "routes.go"))
+	assert.Contains(t, routes, `router.HandleFunc("/profiles/{id}/avatar", handler.GetProfileAvatar).Methods("GET")`)
+	assert.Contains(t, routes, "mountFiles(router)")
+
+	files := readParsedGo(t, filepath.Join(dir, "files.go"))
+	assert.Contains(t, files, `"testproject/pkg/storage"`)
+	for _, name := range []string{"storage.go", "local.go", "s3.go", "gcs.go"} {
+		assert.FileExists(t, filepath.Join(DirPkg, "storage", name))
+	}
+
+	config := readParsedGo(t, filepath.Join("pkg", "config", "config.go"))
+	assert.Contains(t, config, "\tServer  ServerConfig\n\tStorage StorageConfig\n}")
+	assert.Contains(t, config, `Backend:       getEnv("STORAGE_BACKEND", "local"),`)
+
+	// Later handlers keep the edited storage package.
+	require.NoError(t, os.WriteFile(filepath.Join(DirPkg, "storage", "s3.go"), []byte("package storage\n"), 0o644))
+	generateHTTPHandler("Profile", false, false, false, "lowercase", NewSafetyManager(false, false, false))
+	assert.Equal(t, "package storage\n", readParsedGo(t, filepath.Join(DirPkg, "storage", "s3.go")))
+}
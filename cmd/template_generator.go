@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 	"text/template"
+
+	"github.com/jinzhu/inflection"
 )
 
 // TemplateData holds all data needed for template generation.
@@ -20,10 +22,11 @@ type TemplateData struct {
 
 // EntityData holds entity-specific information.
 type EntityData struct {
-	Name       string
-	NameLower  string
-	NamePlural string
-	Package    string
+	Name            string
+	NameLower       string
+	NamePlural      string
+	NamePluralLower string
+	Package         string
 }
 
 // FieldData holds field-specific information.
@@ -85,6 +88,7 @@ func NewTemplateGenerator() *TemplateGenerator {
 
 // GenerateFromTemplate generates code using template and data.
 func (g *TemplateGenerator) GenerateFromTemplate(templateName string, data *TemplateData) (string, error) {
+	defer profileStep("template rendering")()
 	tmpl, err := g.getTemplate(templateName)
 	if err != nil {
 		return "", err
@@ -107,12 +111,17 @@ func (g *TemplateGenerator) PrepareTemplateData(entityName, fields string, featu
 	}
 
 	// Convert to FieldData
+	convention := projectNaming().JSON
 	var fieldData []FieldData
 	for _, field := range fieldsList {
+		jsonName := field.JSONName
+		if jsonName == "" {
+			jsonName = fieldJSONName(field.Name, convention)
+		}
 		fieldData = append(fieldData, FieldData{
 			Name:         field.Name,
 			Type:         field.Type,
-			JSONTag:      fmt.Sprintf("json:\"%s\"", strings.ToLower(field.Name)),
+			JSONTag:      fmt.Sprintf("json:\"%s\"", jsonName),
 			GormTag:      getGormTag(field.Name, field.Type),
 			ValidateTag:  getValidationTag(field.Type),
 			IsRequired:   isRequiredField(field.Name),
@@ -123,10 +132,11 @@ func (g *TemplateGenerator) PrepareTemplateData(entityName, fields string, featu
 
 	// Prepare entity data
 	entityData := EntityData{
-		Name:       entityName,
-		NameLower:  strings.ToLower(entityName),
-		NamePlural: makePlural(entityName),
-		Package:    "domain",
+		Name:            entityName,
+		NameLower:       strings.ToLower(entityName),
+		NamePlural:      makePlural(entityName),
+		NamePluralLower: strings.ToLower(makePlural(entityName)),
+		Package:         "domain",
 	}
 
 	// Generate imports based on features
@@ -177,13 +187,7 @@ func isRequiredField(fieldName string) bool {
 }
 
 func makePlural(word string) string {
-	if strings.HasSuffix(word, "y") {
-		return strings.TrimSuffix(word, "y") + "ies"
-	}
-	if strings.HasSuffix(word, "s") || strings.HasSuffix(word, "x") || strings.HasSuffix(word, "ch") || strings.HasSuffix(word, "sh") {
-		return word + "es"
-	}
-	return word + "s"
+	return inflection.Plural(word)
 }
 
 func (g *TemplateGenerator) generateImports(features FeatureFlags, fields []FieldData) []string {
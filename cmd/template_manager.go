@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"text/template"
+
+	"github.com/jinzhu/inflection"
 )
 
 // TemplateManager manages custom templates for code generation.
@@ -480,6 +482,7 @@ func (tm *TemplateManager) HasTemplate(name string) bool {
 
 // ExecuteTemplate executes a template with given data.
 func (tm *TemplateManager) ExecuteTemplate(name string, data interface{}) (string, error) {
+	defer profileStep("template rendering")()
 	tmpl, exists := tm.templates[name]
 	if !exists {
 		return "", fmt.Errorf("template %s not found", name)
@@ -588,43 +591,19 @@ func toKebabCase(s string) string {
 	return strings.ReplaceAll(toSnakeCase(s), "_", "-")
 }
 
+// toPlural pluralizes a word the way GORM pluralizes table names:
+// category -> categories, person -> people.
 func toPlural(s string) string {
-	if s == "" {
-		return ""
-	}
-
-	// Simple pluralization rules
-	if strings.HasSuffix(s, "y") {
-		return s[:len(s)-1] + "ies"
-	}
-	if strings.HasSuffix(s, "s") || strings.HasSuffix(s, "x") || strings.HasSuffix(s, "ch") || strings.HasSuffix(s, "sh") {
-		return s + "es"
-	}
-
-	return s + "s"
+	return inflection.Plural(s)
 }
 
 func toSingular(s string) string {
-	if s == "" {
-		return ""
-	}
-
-	// Simple singularization rules
-	if strings.HasSuffix(s, "ies") {
-		return s[:len(s)-3] + "y"
-	}
-	if strings.HasSuffix(s, "es") {
-		return s[:len(s)-2]
-	}
-	if strings.HasSuffix(s, "s") {
-		return s[:len(s)-1]
-	}
-
-	return s
+	return inflection.Singular(s)
 }
 
 // ExecuteTemplateString executes a template from string content (useful for testing).
 func (tm *TemplateManager) ExecuteTemplateString(templateContent string, data interface{}) (string, error) {
+	defer profileStep("template rendering")()
 	tmpl, err := template.New("temp").Funcs(tm.functions).Parse(templateContent)
 	if err != nil {
 		return "", fmt.Errorf("error parsing template: %w", err)
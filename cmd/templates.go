@@ -42,7 +42,7 @@ type {{.Entity.Name}}UseCase interface {
 	Get{{.Entity.Name}}ByID(id int) (*{{.Entity.Name}}Output, error)
 	Update{{.Entity.Name}}(id int, input Update{{.Entity.Name}}Input) error
 	Delete{{.Entity.Name}}(id int) error
-	List{{.Entity.Name}}s() (*List{{.Entity.Name}}sOutput, error)
+	List{{.Entity.NamePlural}}() (*List{{.Entity.NamePlural}}Output, error)
 }
 
 type {{.Entity.NameLower}}Service struct {
@@ -70,8 +70,8 @@ type {{.Entity.Name}}Output struct {
 	{{.Entity.Name}} *domain.{{.Entity.Name}} ` + "`" + `json:"{{.Entity.NameLower}}"` + "`" + `
 }
 
-type List{{.Entity.Name}}sOutput struct {
-	{{.Entity.NamePlural}} []domain.{{.Entity.Name}} ` + "`" + `json:"{{.Entity.NameLower}}s"` + "`" + `
+type List{{.Entity.NamePlural}}Output struct {
+	{{.Entity.NamePlural}} []domain.{{.Entity.Name}} ` + "`" + `json:"{{.Entity.NamePluralLower}}"` + "`" + `
 	Total int ` + "`" + `json:"total"` + "`" + `
 }
 `
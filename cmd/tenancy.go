@@ -0,0 +1,519 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Tenant isolation modes of --multi-tenant.
+const (
+	// TenantIsolationColumn keeps every tenant in the same tables, filtered
+	// by their tenant_id column.
+	TenantIsolationColumn = "column"
+	// TenantIsolationSchema also moves each tenant's tables into its own
+	// PostgreSQL schema, tenant_<id>.
+	TenantIsolationSchema = "schema"
+)
+
+// tenantField is the column --multi-tenant adds to an entity. It is filled by
+// the tenancy GORM plugin from the request's tenant, never by request bodies.
+var tenantField = Field{Name: "TenantID", Type: "string", Tag: "`json:\"tenant_id\" gorm:\"size:64;not null;index\"`"}
+
+// isMultiTenantEntity reports whether the entity in internal/domain declares
+// the tenant column of --multi-tenant. Like isAuditedEntity, it lets the
+// outer layers follow the entity.
+func isMultiTenantEntity(entity string) bool {
+	return entityDeclaresField(entity, tenantField)
+}
+
+// validateMultiTenant reports why a feature cannot be generated with
+// --multi-tenant: tenants are scoped by a GORM plugin, and the decorators
+// that cache or audit rows do not know about tenants yet.
+func validateMultiTenant(database, isolation string, cache, audit bool) error {
+	switch {
+	case !isGormDatabase(database):
+		return fmt.Errorf("--multi-tenant scopes queries with GORM and does not support %s", database)
+	case isolation != TenantIsolationColumn && isolation != TenantIsolationSchema:
+		return fmt.Errorf("invalid --tenant-isolation %q (use %s or %s)", isolation, TenantIsolationColumn, TenantIsolationSchema)
+	case isolation == TenantIsolationSchema && database != DBPostgres && database != DBPostgresJSON:
+		return fmt.Errorf("--tenant-isolation %s needs PostgreSQL, not %s", TenantIsolationSchema, database)
+	case cache:
+		return fmt.Errorf("--multi-tenant cannot be combined with --cache: cached entries are not scoped by tenant")
+	case audit:
+		return fmt.Errorf("--multi-tenant cannot be combined with --audit: the audit log is not scoped by tenant")
+	}
+	return nil
+}
+
+// generateTenantFile writes internal/domain/tenant.go with the context helpers
+// carrying the tenant a request acts for.
+func generateTenantFile(dir string, sm ...*SafetyManager) {
+	writeOnce(filepath.Join(dir, "tenant.go"), tenantSource, "tenant helpers", sm...)
+}
+
+const tenantSource = `package domain
+
+import (
+	"context"
+	"errors"
+	"regexp"
+)
+
+// ErrTenantRequired is returned when tenant-owned data is read or written
+// without a tenant.
+var ErrTenantRequired = errors.New("tenant required")
+
+// tenantIDPattern keeps tenant IDs short and safe to use in schema names.
+var tenantIDPattern = regexp.MustCompile(` + "`^[A-Za-z0-9_-]{1,56}$`" + `)
+
+// ValidTenantID reports whether id can name a tenant: 1 to 56 letters,
+// digits, underscores or hyphens.
+func ValidTenantID(id string) bool {
+	return tenantIDPattern.MatchString(id)
+}
+
+type tenantKey struct{}
+
+// WithTenant returns a copy of ctx carrying the tenant a request acts for.
+// The HTTP tenant middleware calls it for every request; background jobs
+// call it before touching tenant-owned data.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant stored by WithTenant and whether one was.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
+`
+
+// generateTenancyPackage writes internal/tenancy/tenancy.go: the GORM plugin
+// that scopes every query on a tenant-owned model to the tenant of its
+// context, and the helpers repositories and migrations use with it.
+func generateTenancyPackage(sm ...*SafetyManager) {
+	importPath := getImportPath(getModuleName())
+	filename := filepath.Join(DirInternal, "tenancy", "tenancy.go")
+	writeOnce(filename, fmt.Sprintf(tenancySource, importPath), "tenancy package", sm...)
+}
+
+const tenancySource = `// Package tenancy scopes GORM queries on tenant-owned models, the entities
+// with a TenantID field, to the tenant of their context.
+package tenancy
+
+import (
+	"context"
+	"fmt"
+
+	"%s/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Isolation selects how the rows of different tenants are kept apart.
+type Isolation int
+
+const (
+	// ColumnIsolation keeps every tenant in the same tables and filters rows
+	// by their tenant_id column.
+	ColumnIsolation Isolation = iota
+	// SchemaIsolation also keeps the tables of each tenant in its own
+	// PostgreSQL schema, named by SchemaName. Create it with Migrate.
+	SchemaIsolation
+)
+
+const (
+	// tenantField is the field of tenant-owned models.
+	tenantField = "TenantID"
+	// tenantSetting and allTenantsSetting are the statement settings Scope
+	// and AllTenants store.
+	tenantSetting     = "tenancy:tenant"
+	allTenantsSetting = "tenancy:all"
+)
+
+// NewPlugin returns the GORM plugin enforcing isolation. Register it once
+// with db.Use before the database is used. Statements on tenant-owned models
+// without a tenant fail with domain.ErrTenantRequired; raw SQL is not
+// rewritten and must filter by tenant_id itself.
+func NewPlugin(isolation Isolation) gorm.Plugin {
+	return &plugin{isolation: isolation}
+}
+
+// Scope returns db bound to ctx and to its tenant. The tenant sticks to the
+// returned handle, so queries that later replace the context stay scoped.
+func Scope(ctx context.Context, db *gorm.DB) *gorm.DB {
+	tx := db.WithContext(ctx)
+	if tenantID, ok := domain.TenantFromContext(ctx); ok {
+		tx = tx.Set(tenantSetting, tenantID).Session(&gorm.Session{})
+	}
+	return tx
+}
+
+// AllTenants returns db reading and writing the rows of every tenant, for
+// administrative jobs. Use it sparingly.
+func AllTenants(db *gorm.DB) *gorm.DB {
+	return db.Set(allTenantsSetting, true).Session(&gorm.Session{})
+}
+
+// SchemaName returns the PostgreSQL schema holding the tables of a tenant
+// under SchemaIsolation.
+func SchemaName(tenantID string) string {
+	return "tenant_" + tenantID
+}
+
+// Migrate creates the schema of a tenant and migrates models into it. Run it
+// when a tenant signs up and after schema changes; it is only needed with
+// SchemaIsolation.
+func Migrate(ctx context.Context, db *gorm.DB, tenantID string, models ...interface{}) error {
+	if !domain.ValidTenantID(tenantID) {
+		return fmt.Errorf("invalid tenant ID %%q", tenantID)
+	}
+	name := SchemaName(tenantID)
+	tx := db.WithContext(ctx)
+	if err := tx.Exec(fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS \"%%s\"", name)).Error; err != nil {
+		return fmt.Errorf("creating schema %%s: %%w", name, err)
+	}
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		if err := tx.Table(name + "." + stmt.Schema.Table).AutoMigrate(model); err != nil {
+			return fmt.Errorf("migrating %%s for tenant %%s: %%w", stmt.Schema.Table, tenantID, err)
+		}
+	}
+	return nil
+}
+
+type plugin struct {
+	isolation Isolation
+}
+
+func (p *plugin) Name() string {
+	return "tenancy"
+}
+
+func (p *plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("tenancy:create", p.create); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("tenancy:query", p.scope); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("tenancy:update", p.scope); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("tenancy:delete", p.scope); err != nil {
+		return err
+	}
+	return cb.Row().Before("gorm:row").Register("tenancy:row", p.scope)
+}
+
+// create stamps new rows with the tenant.
+func (p *plugin) create(db *gorm.DB) {
+	if _, ok := p.tenantOwned(db); !ok {
+		return
+	}
+	tenantID, ok := p.tenant(db)
+	if !ok {
+		return
+	}
+	db.Statement.SetColumn(tenantField, tenantID, true)
+	p.route(db, tenantID)
+}
+
+// scope restricts a statement to the rows of the tenant.
+func (p *plugin) scope(db *gorm.DB) {
+	field, ok := p.tenantOwned(db)
+	if !ok {
+		return
+	}
+	tenantID, ok := p.tenant(db)
+	if !ok {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantID},
+	}})
+	p.route(db, tenantID)
+}
+
+// tenantOwned returns the tenant column of the statement's model when the
+// plugin has to scope the statement.
+func (p *plugin) tenantOwned(db *gorm.DB) (*schema.Field, bool) {
+	if db.Error != nil || db.Statement.Schema == nil || db.Statement.SQL.Len() > 0 {
+		return nil, false
+	}
+	if _, all := db.Get(allTenantsSetting); all {
+		return nil, false
+	}
+	field := db.Statement.Schema.LookUpField(tenantField)
+	if field == nil || field.DBName == "" {
+		return nil, false
+	}
+	return field, true
+}
+
+// tenant returns the tenant of the statement, failing it when there is none.
+func (p *plugin) tenant(db *gorm.DB) (string, bool) {
+	tenantID, ok := "", false
+	if v, found := db.Get(tenantSetting); found {
+		tenantID, ok = v.(string)
+	} else {
+		tenantID, ok = domain.TenantFromContext(db.Statement.Context)
+	}
+	if !ok || !domain.ValidTenantID(tenantID) {
+		_ = db.AddError(domain.ErrTenantRequired)
+		return "", false
+	}
+	return tenantID, true
+}
+
+// route points the statement at the tenant's schema under SchemaIsolation.
+// Tables named explicitly with db.Table are left alone.
+func (p *plugin) route(db *gorm.DB, tenantID string) {
+	if p.isolation != SchemaIsolation || db.Statement.Table != db.Statement.Schema.Table {
+		return
+	}
+	db.Statement.Table = SchemaName(tenantID) + "." + db.Statement.Schema.Table
+}
+`
+
+// writeRepositoryTenantScope appends WithContext to the GORM repository of a
+// multi-tenant entity. The returned copy runs every query for the tenant of
+// ctx through tenancy.Scope.
+func writeRepositoryTenantScope(dir, entity, database string, sm ...*SafetyManager) {
+	file, repoName, recv := "postgres_", "postgres"+entity+"Repository", "p"
+	switch database {
+	case DBPostgresJSON:
+		file, repoName = "postgres_json_", "postgresJSON"+entity+"Repository"
+	case DBSQLServer:
+		file, repoName, recv = "sqlserver_", "sqlserver"+entity+"Repository", "s"
+	}
+	path := filepath.Join(dir, file+strings.ToLower(entity)+"_repository.go")
+	if existing, err := os.ReadFile(path); err != nil || strings.Contains(string(existing), ") WithContext(ctx context.Context)") {
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// WithContext returns the repository scoped to ctx and its tenant.\n")
+	fmt.Fprintf(&b, "func (%s *%s) WithContext(ctx context.Context) %sRepository {\n", recv, repoName, entity)
+	fmt.Fprintf(&b, "\tscoped := *%s\n", recv)
+	fmt.Fprintf(&b, "\tscoped.db = tenancy.Scope(ctx, %s.db)\n", recv)
+	b.WriteString("\treturn &scoped\n")
+	b.WriteString("}\n")
+	appendToRepoFile(path, b.String(), []string{"context", getImportPath(getModuleName()) + "/internal/tenancy"}, sm...)
+}
+
+// writeTenantRepositoryMethod writes the WithContext signature of the
+// repository interface of a multi-tenant entity.
+func writeTenantRepositoryMethod(content *strings.Builder, entity string) {
+	content.WriteString("\t// WithContext returns the repository scoped to ctx and its tenant.\n")
+	fmt.Fprintf(content, "\tWithContext(ctx context.Context) %sRepository\n", entity)
+}
+
+// writeTenantScopedUseCase writes the TenantScoped<Entity>UseCase interface
+// the use cases of a multi-tenant entity implement. Handlers find it by type
+// assertion, so use cases built without a tenant scope keep compiling.
+func writeTenantScopedUseCase(content *strings.Builder, entity string) {
+	iface := entity + "UseCase"
+	fmt.Fprintf(content, "\n// TenantScoped%s is a %s whose queries can be scoped to\n", iface, iface)
+	content.WriteString("// the tenant of a request.\n")
+	fmt.Fprintf(content, "type TenantScoped%s interface {\n", iface)
+	content.WriteString("\t" + iface + "\n")
+	content.WriteString("\t// WithContext returns the use case scoped to ctx and its tenant.\n")
+	fmt.Fprintf(content, "\tWithContext(ctx context.Context) %s\n", iface)
+	content.WriteString("}\n")
+}
+
+// writeServiceTenantScope writes the service method scoping its repository
+// to the tenant of ctx.
+func writeServiceTenantScope(content *strings.Builder, serviceName, entity string) {
+	serviceVar := string(serviceName[0])
+	content.WriteString("// WithContext returns the service with its repository scoped to ctx and\n")
+	content.WriteString("// its tenant.\n")
+	fmt.Fprintf(content, "func (%s *%s) WithContext(ctx context.Context) %sUseCase {\n", serviceVar, serviceName, entity)
+	fmt.Fprintf(content, "\tscoped := *%s\n", serviceVar)
+	fmt.Fprintf(content, "\tscoped.repo = %s.repo.WithContext(ctx)\n", serviceVar)
+	content.WriteString("\treturn &scoped\n")
+	content.WriteString("}\n\n")
+}
+
+// writeTracedTenantScope writes the tracing decorator method scoping the
+// inner use case to the tenant of ctx. Its spans become children of the span
+// in ctx.
+func writeTracedTenantScope(b *strings.Builder, typeName, entity string) {
+	iface := entity + "UseCase"
+	b.WriteString("// WithContext returns the decorator tracing under ctx, around the inner use\n")
+	b.WriteString("// case scoped to the tenant of ctx.\n")
+	fmt.Fprintf(b, "func (t *%s) WithContext(ctx context.Context) %s {\n", typeName, iface)
+	b.WriteString("\tscoped := *t\n")
+	b.WriteString("\tscoped.ctx = ctx\n")
+	fmt.Fprintf(b, "\tif inner, ok := t.inner.(TenantScoped%s); ok {\n", iface)
+	b.WriteString("\t\tscoped.inner = inner.WithContext(ctx)\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\treturn &scoped\n")
+	b.WriteString("}\n\n")
+}
+
+// writeTenantActingMethod writes the handler method returning the use case
+// scoped to the tenant of a request, the multi-tenant counterpart of
+// writeActingUseCaseMethod.
+func writeTenantActingMethod(content *strings.Builder, entity, handlerName string) {
+	handlerVar := strings.ToLower(string(handlerName[0]))
+	content.WriteString("// acting returns the use case acting for the tenant of r, so every query it\n")
+	content.WriteString("// runs is scoped to that tenant.\n")
+	fmt.Fprintf(content, "func (%s *%s) acting(r *http.Request) usecase.%sUseCase {\n", handlerVar, handlerName, entity)
+	fmt.Fprintf(content, "\tif scoped, ok := %s.usecase.(usecase.TenantScoped%sUseCase); ok {\n", handlerVar, entity)
+	content.WriteString("\t\treturn scoped.WithContext(r.Context())\n")
+	content.WriteString("\t}\n")
+	fmt.Fprintf(content, "\treturn %s.usecase\n", handlerVar)
+	content.WriteString("}\n\n")
+}
+
+// generateHTTPTenantFile writes internal/handler/http/tenant.go with the
+// middleware binding requests to their tenant. The tenant_id claim of a JWT
+// is only read when the auth middleware exists; rerun with --force after
+// adding it.
+func generateHTTPTenantFile(dir string, sm ...*SafetyManager) {
+	importPath := getImportPath(getModuleName())
+	_, err := os.Stat(filepath.Join(DirInternal, dirMiddleware, "auth.go"))
+	claims := err == nil
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s\n\n", DirHTTP)
+	b.WriteString("import (\n\t\"net/http\"\n\n")
+	fmt.Fprintf(&b, "\t\"%s/internal/domain\"\n", importPath)
+	if claims {
+		fmt.Fprintf(&b, "\t\"%s/internal/middleware\"\n", importPath)
+	}
+	b.WriteString(")\n\n")
+
+	b.WriteString("// TenantHeader carries the tenant of requests")
+	if claims {
+		b.WriteString(" without a JWT")
+	}
+	b.WriteString(".\n")
+	b.WriteString("const TenantHeader = \"X-Tenant-ID\"\n\n")
+
+	b.WriteString("// tenantMiddleware binds every request to a tenant and refuses requests\n")
+	b.WriteString("// without one. The tenant is the one already stored with domain.WithTenant,\n")
+	if claims {
+		b.WriteString("// else the tenant_id claim of the JWT the auth middleware validated, else\n")
+		b.WriteString("// the X-Tenant-ID header.\n")
+	} else {
+		b.WriteString("// else the X-Tenant-ID header. Only trust the header behind a gateway that\n")
+		b.WriteString("// sets it.\n")
+	}
+	b.WriteString("func tenantMiddleware(next http.Handler) http.Handler {\n")
+	b.WriteString("\treturn http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {\n")
+	b.WriteString("\t\tif _, ok := domain.TenantFromContext(r.Context()); ok {\n")
+	b.WriteString("\t\t\tnext.ServeHTTP(w, r)\n")
+	b.WriteString("\t\t\treturn\n")
+	b.WriteString("\t\t}\n\n")
+	if claims {
+		b.WriteString("\t\t// A token decides the tenant; the header cannot override it.\n")
+		b.WriteString("\t\tif claims, ok := middleware.ClaimsFromContext(r.Context()); ok {\n")
+		b.WriteString("\t\t\ttenantID, _ := claims[\"tenant_id\"].(string)\n")
+		b.WriteString("\t\t\tif !domain.ValidTenantID(tenantID) {\n")
+		b.WriteString("\t\t\t\thttp.Error(w, \"token has no valid tenant_id claim\", http.StatusForbidden)\n")
+		b.WriteString("\t\t\t\treturn\n")
+		b.WriteString("\t\t\t}\n")
+		b.WriteString("\t\t\tnext.ServeHTTP(w, r.WithContext(domain.WithTenant(r.Context(), tenantID)))\n")
+		b.WriteString("\t\t\treturn\n")
+		b.WriteString("\t\t}\n\n")
+	}
+	b.WriteString("\t\ttenantID := r.Header.Get(TenantHeader)\n")
+	b.WriteString("\t\tif !domain.ValidTenantID(tenantID) {\n")
+	b.WriteString("\t\t\thttp.Error(w, \"missing or invalid \"+TenantHeader+\" header\", http.StatusBadRequest)\n")
+	b.WriteString("\t\t\treturn\n")
+	b.WriteString("\t\t}\n")
+	b.WriteString("\t\tnext.ServeHTTP(w, r.WithContext(domain.WithTenant(r.Context(), tenantID)))\n")
+	b.WriteString("\t})\n")
+	b.WriteString("}\n")
+
+	writeOnce(filepath.Join(dir, "tenant.go"), b.String(), "tenant middleware", sm...)
+}
+
+// tenancyPluginAnchor is the line of the generated connectToDatabase the
+// tenancy plugin is registered before, right after the database is opened.
+const tenancyPluginAnchor = "\t\t// Get underlying sql.DB for connection pool configuration"
+
+// registerTenancyPlugin registers the tenancy GORM plugin in main.go, printing
+// the manual step when main.go does not have the expected layout.
+func registerTenancyPlugin(isolation string, sm ...*SafetyManager) {
+	if len(sm) > 0 && sm[0] != nil && sm[0].DryRun {
+		return
+	}
+	mainPath, found := findMainGoPath()
+	if !found {
+		printTenancyInstructions(isolation)
+		return
+	}
+	content, err := os.ReadFile(mainPath)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Could not read main.go: %v", err))
+		printTenancyInstructions(isolation)
+		return
+	}
+	updated, ok := wireTenancyIntoMainGo(string(content), isolation, getImportPath(getModuleName()))
+	if !ok {
+		ui.Warning("main.go does not open a GORM database to register the tenancy plugin on")
+		printTenancyInstructions(isolation)
+		return
+	}
+	if updated == string(content) {
+		if !strings.Contains(updated, tenancyPluginCall(isolation)) {
+			ui.Warning(fmt.Sprintf("main.go already registers the tenancy plugin with another isolation; --tenant-isolation %s was not applied", isolation))
+		}
+		return
+	}
+	if err := writeMainGoInPlace(mainPath, updated); err != nil {
+		ui.Warning(fmt.Sprintf("Could not register the tenancy plugin in main.go: %v", err))
+		printTenancyInstructions(isolation)
+		return
+	}
+	ui.Dim(fmt.Sprintf("   Tenancy plugin (%s isolation) registered in %s", isolation, mainPath))
+}
+
+// tenancyPluginCall returns the plugin constructor call for an isolation mode.
+func tenancyPluginCall(isolation string) string {
+	if isolation == TenantIsolationSchema {
+		return "tenancy.NewPlugin(tenancy.SchemaIsolation)"
+	}
+	return "tenancy.NewPlugin(tenancy.ColumnIsolation)"
+}
+
+// wireTenancyIntoMainGo registers the tenancy plugin as soon as the database
+// is opened. A database the plugin cannot be registered on is not used, so
+// tenant-owned tables are never queried unscoped. It is idempotent and
+// reports false when main.go does not open a GORM database.
+func wireTenancyIntoMainGo(content, isolation, importPath string) (string, bool) {
+	if strings.Contains(content, "tenancy.NewPlugin(") {
+		return content, true
+	}
+	if !strings.Contains(content, tenancyPluginAnchor) {
+		return content, false
+	}
+	updated := ensureMainGoImport(content, importPath+"/internal/tenancy")
+	plugin := "\t\t// Scope tenant-owned tables to the tenant of each query\n" +
+		"\t\tif err := db.Use(" + tenancyPluginCall(isolation) + "); err != nil {\n" +
+		"\t\t\treturn nil, fmt.Errorf(\"registering tenancy plugin: %w\", err)\n" +
+		"\t\t}\n\n" + tenancyPluginAnchor
+	return strings.Replace(updated, tenancyPluginAnchor, plugin, 1), true
+}
+
+// printTenancyInstructions prints how to register the tenancy plugin by hand.
+func printTenancyInstructions(isolation string) {
+	ui.Blank()
+	ui.Section("Manual tenancy integration")
+	ui.Println("Register the plugin right after opening the database:")
+	ui.Dim(fmt.Sprintf("      if err := db.Use(%s); err != nil {", tenancyPluginCall(isolation)))
+	ui.Dim("          return nil, err")
+	ui.Dim("      }")
+}
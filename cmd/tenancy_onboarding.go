@@ -0,0 +1,351 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// tenantRegistryEntity is the domain type of the tenant registry.
+const tenantRegistryEntity = "Tenant"
+
+// generateTenantOnboarding writes the tooling of --tenant-isolation schema:
+// the domain.Tenant registry entity, internal/tenancy/registry.go creating
+// and migrating tenant schemas, the list of tenant-owned models they hold and
+// cmd/tenant, the command operators onboard tenants with. entity is the
+// tenant-owned entity being generated, which a dry run has not written yet.
+func generateTenantOnboarding(entity, database string, sm ...*SafetyManager) {
+	domainDir := filepath.Join(DirInternal, DirDomain)
+	registryFile := filepath.Join(domainDir, "tenant_registry.go")
+	if file := findTypeFile(domainDir, tenantRegistryEntity); file != "" && file != registryFile {
+		ui.Warning(fmt.Sprintf("%s already declares a %s type; skipping the tenant registry and cmd/tenant", file, tenantRegistryEntity))
+		return
+	}
+
+	importPath := getImportPath(getModuleName())
+	tenancyDir := filepath.Join(DirInternal, "tenancy")
+	writeOnce(registryFile, tenantRegistryEntitySource, "tenant registry entity", sm...)
+	writeOnce(filepath.Join(tenancyDir, "registry.go"), fmt.Sprintf(tenantRegistrySource, importPath), "tenant registry", sm...)
+	writeTenantModels(filepath.Join(tenancyDir, "models.go"), importPath, tenantOwnedEntities(entity), sm...)
+
+	content, ok := buildTenantCommandMain(importPath, database, projectSQLiteDriver())
+	if !ok {
+		ui.Warning(fmt.Sprintf("cmd/tenant does not support %s", database))
+		return
+	}
+	writeOnce(filepath.Join("cmd", "tenant", "main.go"), content, "tenant command", sm...)
+}
+
+// tenantOwnedEntities returns the entities of internal/domain that declare
+// the tenant column, and extra, sorted.
+func tenantOwnedEntities(extra ...string) []string {
+	seen := map[string]bool{}
+	for _, entity := range extra {
+		seen[entity] = true
+	}
+	files, _ := filepath.Glob(filepath.Join(DirInternal, DirDomain, "*.go"))
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		src, err := parseGoSource(file)
+		if err != nil {
+			continue
+		}
+		for _, decl := range src.file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				for _, f := range readEntityFields(src.file, ts.Name.Name) {
+					if f.Name == tenantField.Name && f.Type == tenantField.Type {
+						seen[ts.Name.Name] = true
+					}
+				}
+			}
+		}
+	}
+
+	entities := make([]string, 0, len(seen))
+	for entity := range seen {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+	return entities
+}
+
+// writeTenantModels writes internal/tenancy/models.go, listing the models
+// every tenant schema holds. It is rewritten when an entity is missing.
+func writeTenantModels(filename, importPath string, entities []string, sm ...*SafetyManager) {
+	if existing, err := os.ReadFile(filename); err == nil {
+		missing := false
+		for _, entity := range entities {
+			if !strings.Contains(string(existing), "&domain."+entity+"{}") {
+				missing = true
+			}
+		}
+		if !missing {
+			return
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("package tenancy\n\n")
+	fmt.Fprintf(&b, "import \"%s/internal/domain\"\n\n", importPath)
+	b.WriteString("// Models returns the tenant-owned models Migrate creates in every tenant\n")
+	b.WriteString("// schema. goca feature --multi-tenant --tenant-isolation schema adds its\n")
+	b.WriteString("// entity here; add models declared by hand yourself.\n")
+	b.WriteString("func Models() []interface{} {\n")
+	b.WriteString("\treturn []interface{}{\n")
+	for _, entity := range entities {
+		fmt.Fprintf(&b, "\t\t&domain.%s{},\n", entity)
+	}
+	b.WriteString("\t}\n")
+	b.WriteString("}\n")
+
+	if err := writeGoFileMerged(filename, b.String(), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing tenant models: %v", err))
+	}
+}
+
+const tenantRegistryEntitySource = `package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Tenant is a tenant registered with the application. Under schema isolation
+// its tables live in the PostgreSQL schema named Schema. Register tenants
+// with go run ./cmd/tenant create.
+type Tenant struct {
+	ID        string    ` + "`json:\"id\" gorm:\"primaryKey;size:64\"`" + `
+	Name      string    ` + "`json:\"name\" gorm:\"size:255;not null\"`" + `
+	Schema    string    ` + "`json:\"schema\" gorm:\"size:63;not null;uniqueIndex\"`" + `
+	CreatedAt time.Time ` + "`json:\"created_at\"`" + `
+	UpdatedAt time.Time ` + "`json:\"updated_at\"`" + `
+}
+
+// Validate checks a tenant before it is registered.
+func (t *Tenant) Validate() error {
+	if !ValidTenantID(t.ID) {
+		return fmt.Errorf("invalid tenant ID %q: use 1 to 56 letters, digits, underscores or hyphens", t.ID)
+	}
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.New("tenant name is required")
+	}
+	return nil
+}
+`
+
+const tenantRegistrySource = `package tenancy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"%s/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// ErrTenantExists is returned by Create for a tenant that is already
+// registered.
+var ErrTenantExists = errors.New("tenant already exists")
+
+// Create registers a tenant and creates its schema with the tables of
+// Models. The tenant is registered last, so a failed migration can be
+// retried.
+func Create(ctx context.Context, db *gorm.DB, id, name string) (*domain.Tenant, error) {
+	tenant := &domain.Tenant{ID: id, Name: name, Schema: SchemaName(id)}
+	if err := tenant.Validate(); err != nil {
+		return nil, err
+	}
+	if err := migrateRegistry(ctx, db); err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := db.WithContext(ctx).Model(&domain.Tenant{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("looking up tenant %%s: %%w", id, err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("%%w: %%s", ErrTenantExists, id)
+	}
+
+	if err := Migrate(ctx, db, id, Models()...); err != nil {
+		return nil, err
+	}
+	if err := db.WithContext(ctx).Create(tenant).Error; err != nil {
+		return nil, fmt.Errorf("registering tenant %%s: %%w", id, err)
+	}
+	return tenant, nil
+}
+
+// List returns the registered tenants ordered by ID.
+func List(ctx context.Context, db *gorm.DB) ([]domain.Tenant, error) {
+	if err := migrateRegistry(ctx, db); err != nil {
+		return nil, err
+	}
+	var tenants []domain.Tenant
+	if err := db.WithContext(ctx).Order("id").Find(&tenants).Error; err != nil {
+		return nil, fmt.Errorf("listing tenants: %%w", err)
+	}
+	return tenants, nil
+}
+
+// MigrateTenants migrates the schemas of the registered tenants, or only of
+// ids, so they pick up changes to the models of Models.
+func MigrateTenants(ctx context.Context, db *gorm.DB, ids ...string) error {
+	tenants, err := List(ctx, db)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		for _, tenant := range tenants {
+			ids = append(ids, tenant.ID)
+		}
+	}
+	registered := make(map[string]bool, len(tenants))
+	for _, tenant := range tenants {
+		registered[tenant.ID] = true
+	}
+	for _, id := range ids {
+		if !registered[id] {
+			return fmt.Errorf("tenant %%s is not registered", id)
+		}
+	}
+
+	for _, id := range ids {
+		if err := Migrate(ctx, db, id, Models()...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateRegistry creates the tenants table in the shared schema.
+func migrateRegistry(ctx context.Context, db *gorm.DB) error {
+	if err := db.WithContext(ctx).AutoMigrate(&domain.Tenant{}); err != nil {
+		return fmt.Errorf("migrating the tenant registry: %%w", err)
+	}
+	return nil
+}
+`
+
+// buildTenantCommandMain returns cmd/tenant/main.go, or false when the
+// database has no generated GORM opener.
+func buildTenantCommandMain(importPath, database, sqliteDriver string) (string, bool) {
+	if sqlDialect(database) < 0 {
+		return "", false
+	}
+	std, external, openDatabase, ok := databaseOpener(database, sqliteDriver)
+	if !ok {
+		return "", false
+	}
+	imports := importLines(append([]string{"context", "flag", "fmt", "os", "os/signal", "syscall", "text/tabwriter"}, std...), external)
+
+	return fmt.Sprintf(`package main
+
+import (
+%s
+	"%s/internal/tenancy"
+	"%s/pkg/config"
+)
+
+const usage = `+"`"+`Manage the tenants of the application.
+
+Usage:
+  go run ./cmd/tenant create <id> [-name <name>]  Register a tenant and create its schema
+  go run ./cmd/tenant list                        List the registered tenants
+  go run ./cmd/tenant migrate [id...]             Migrate the schemas of registered tenants
+`+"`"+`
+
+func main() {
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+	command, args := flag.Arg(0), flag.Args()
+	if len(args) > 0 {
+		args = args[1:]
+	}
+	if command != "create" && command != "list" && command != "migrate" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg := config.Load()
+	db, err := openDatabase(cfg)
+	if err != nil {
+		fail("connecting to database: %%v", err)
+	}
+
+	switch command {
+	case "create":
+		err = create(ctx, db, args)
+	case "list":
+		err = list(ctx, db)
+	case "migrate":
+		err = tenancy.MigrateTenants(ctx, db, args...)
+	}
+	if err != nil {
+		fail("%%v", err)
+	}
+}
+
+// create registers the tenant whose ID is the first argument.
+func create(ctx context.Context, db *gorm.DB, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: go run ./cmd/tenant create <id> [-name <name>]")
+	}
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	name := fs.String("name", "", "display name of the tenant (default: its ID)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *name == "" {
+		*name = args[0]
+	}
+
+	tenant, err := tenancy.Create(ctx, db, args[0], *name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Tenant %%s registered in schema %%s\n", tenant.ID, tenant.Schema)
+	return nil
+}
+
+// list prints the registered tenants.
+func list(ctx context.Context, db *gorm.DB) error {
+	tenants, err := tenancy.List(ctx, db)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tSCHEMA\tCREATED")
+	for _, tenant := range tenants {
+		fmt.Fprintf(w, "%%s\t%%s\t%%s\t%%s\n", tenant.ID, tenant.Name, tenant.Schema, tenant.CreatedAt.Format("2006-01-02 15:04"))
+	}
+	return w.Flush()
+}
+
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "Error: "+format+"\n", args...)
+	os.Exit(1)
+}
+
+%s`, imports, importPath, importPath, openDatabase), true
+}
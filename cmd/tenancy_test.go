@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiTenantEntity(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+
+	require.NoError(t, generateEntity("Product", "name:string,price:float64", true, false, false, false, false, false, true, false, "lowercase"))
+	entity := readParsedGo(t, filepath.Join(DirInternal, DirDomain, "product.go"))
+	assert.Regexp(t, "TenantID +string +`json:\"tenant_id\" gorm:\"size:64;not null;index\"`", entity)
+	assert.NotContains(t, entity, "p.TenantID", "the tenant column is not validated")
+
+	tenant := readParsedGo(t, filepath.Join(DirInternal, DirDomain, "tenant.go"))
+	assert.Contains(t, tenant, "func WithTenant(ctx context.Context, tenantID string) context.Context")
+	assert.Contains(t, tenant, "func TenantFromContext(ctx context.Context) (string, bool)")
+
+	assert.True(t, isMultiTenantEntity("Product"))
+	assert.Equal(t, "name:string,price:float64", readEntityFieldsString("Product"))
+
+	require.NoError(t, generateEntity("Order", "total:float64", true, false, false, false, false, false, false, false, "lowercase"))
+	assert.False(t, isMultiTenantEntity("Order"))
+}
+
+func TestMultiTenantLayers(t *testing.T) {
+	defer ensureTestUI(t)()
+	setupCLIProject(t, fullProductUseCase)
+	require.NoError(t, generateEntity("Product", "name:string,price:float64", true, false, false, false, false, false, true, false, "lowercase", NewSafetyManager(false, true, false)))
+
+	repoDir := filepath.Join(DirInternal, DirRepository)
+	generateRepository("Product", DBPostgres, false, false, false, false, "name:string,price:float64")
+	assert.Contains(t, readParsedGo(t, filepath.Join(repoDir, "interfaces.go")), "WithContext(ctx context.Context) ProductRepository")
+	repo := readParsedGo(t, filepath.Join(repoDir, "postgres_product_repository.go"))
+	assert.Contains(t, repo, "func (p *postgresProductRepository) WithContext(ctx context.Context) ProductRepository {")
+	assert.Contains(t, repo, "scoped.db = tenancy.Scope(ctx, p.db)")
+	plugin := readParsedGo(t, filepath.Join(DirInternal, "tenancy", "tenancy.go"))
+	assert.Contains(t, plugin, "func NewPlugin(isolation Isolation) gorm.Plugin")
+	assert.Contains(t, plugin, "func Migrate(ctx context.Context, db *gorm.DB, tenantID string, models ...interface{}) error")
+
+	usecaseDir := filepath.Join(DirInternal, DirUseCase)
+	ops := []string{OpCreate, OpRead, OpUpdate, OpDelete, OpList}
+	generateUseCaseInterface(usecaseDir, "ProductUseCase", "Product", ops)
+	assert.Contains(t, readParsedGo(t, filepath.Join(usecaseDir, "product_usecase.go")), "type TenantScopedProductUseCase interface {")
+	generateUseCaseServiceWithFields(usecaseDir, "ProductUseCase", "Product", []string{OpRead}, false, false, "name:string,price:float64", DTOStyleNested)
+	service := readParsedGo(t, filepath.Join(usecaseDir, "product_service.go"))
+	assert.Contains(t, service, "repo repository.ProductRepository", "only <Entity>Repository declares WithContext")
+	assert.Contains(t, service, "scoped.repo = p.repo.WithContext(ctx)")
+
+	generateUseCaseTracingDecorator("Product", ops)
+	assert.Contains(t, readParsedGo(t, filepath.Join(usecaseDir, "traced_product_usecase.go")), "if inner, ok := t.inner.(TenantScopedProductUseCase); ok {")
+
+	httpDir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	require.NoError(t, os.MkdirAll(httpDir, 0o755))
+	generateHTTPHandlerFile(httpDir, "Product", false, false, "lowercase")
+	handler := readParsedGo(t, filepath.Join(httpDir, "product_handler.go"))
+	assert.Contains(t, handler, "output, err := p.acting(r).ListProducts()")
+	assert.Contains(t, handler, "return scoped.WithContext(r.Context())")
+	middleware := readParsedGo(t, filepath.Join(httpDir, "tenant.go"))
+	assert.Contains(t, middleware, "func tenantMiddleware(next http.Handler) http.Handler")
+	assert.NotContains(t, middleware, "ClaimsFromContext", "claims are only read with the auth middleware")
+
+	var routes strings.Builder
+	writeRouteSetupFunc(&routes, "Product", defaultHTTPRoutes("Product"), false, false)
+	assert.Contains(t, routes.String(), "productRouter := router.PathPrefix(\"/products\").Subrouter()\n\tproductRouter.Use(tenantMiddleware)\n")
+	assert.Contains(t, routes.String(), `productRouter.HandleFunc("/{id}", handler.GetProduct).Methods("GET")`)
+
+	assert.Contains(t, generateRepositoryMock("Product", nil), "func (m *MockProductRepository) WithContext(ctx context.Context) repository.ProductRepository {")
+}
+
+func TestHTTPTenantFileReadsClaims(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	writeTestFile(t, ".", filepath.Join(DirInternal, dirMiddleware, "auth.go"), "package middleware\n")
+
+	httpDir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	require.NoError(t, os.MkdirAll(httpDir, 0o755))
+	generateHTTPTenantFile(httpDir)
+	middleware := readParsedGo(t, filepath.Join(httpDir, "tenant.go"))
+	assert.Contains(t, middleware, "if claims, ok := middleware.ClaimsFromContext(r.Context()); ok {")
+	assert.Less(t, strings.Index(middleware, "ClaimsFromContext"), strings.Index(middleware, "r.Header.Get(TenantHeader)"), "a token wins over the header")
+}
+
+func TestValidateMultiTenant(t *testing.T) {
+	assert.NoError(t, validateMultiTenant(DBPostgres, TenantIsolationSchema, false, false))
+	assert.NoError(t, validateMultiTenant(DBMySQL, TenantIsolationColumn, false, false))
+	assert.ErrorContains(t, validateMultiTenant(DBMongoDB, TenantIsolationColumn, false, false), "does not support mongodb")
+	assert.ErrorContains(t, validateMultiTenant(DBMySQL, TenantIsolationSchema, false, false), "needs PostgreSQL")
+	assert.ErrorContains(t, validateMultiTenant(DBPostgres, "database", false, false), "invalid --tenant-isolation")
+	assert.ErrorContains(t, validateMultiTenant(DBPostgres, TenantIsolationColumn, true, false), "--cache")
+	assert.ErrorContains(t, validateMultiTenant(DBPostgres, TenantIsolationColumn, false, true), "--audit")
+}
+
+func TestWireTenancyIntoMainGo(t *testing.T) {
+	main := "package main\n\nimport (\n\t\"fmt\"\n)\n\nfunc connect() {\n\t\tdb, err := open()\n" + tenancyPluginAnchor + "\n}\n"
+
+	wired, ok := wireTenancyIntoMainGo(main, TenantIsolationSchema, "example.com/shop")
+	require.True(t, ok)
+	assert.Contains(t, wired, "\"example.com/shop/internal/tenancy\"")
+	assert.Contains(t, wired, "if err := db.Use(tenancy.NewPlugin(tenancy.SchemaIsolation)); err != nil {")
+	assert.Less(t, strings.Index(wired, "db.Use("), strings.Index(wired, tenancyPluginAnchor))
+
+	again, ok := wireTenancyIntoMainGo(wired, TenantIsolationColumn, "example.com/shop")
+	assert.True(t, ok)
+	assert.Equal(t, wired, again)
+
+	_, ok = wireTenancyIntoMainGo("package main\n", TenantIsolationColumn, "example.com/shop")
+	assert.False(t, ok)
+}
+
+func TestTenantOnboarding(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+
+	sm := NewSafetyManager(false, false, false)
+	writeTestFile(t, ".", "go.mod", "module example.com/shop\n\ngo 1.21\n")
+	require.NoError(t, generateEntity("Project", "name:string", true, false, false, false, false, false, true, false, "lowercase", sm))
+
+	generateTenantOnboarding("Project", DBPostgres, sm)
+	registry := readParsedGo(t, filepath.Join(DirInternal, DirDomain, "tenant_registry.go"))
+	assert.Contains(t, registry, "type Tenant struct {")
+	tenancy := filepath.Join(DirInternal, "tenancy")
+	assert.Contains(t, readParsedGo(t, filepath.Join(tenancy, "registry.go")), "func Create(ctx context.Context, db *gorm.DB, id, name string) (*domain.Tenant, error)")
+	assert.Contains(t, readParsedGo(t, filepath.Join(tenancy, "models.go")), "&domain.Project{},")
+	command := readParsedGo(t, filepath.Join("cmd", "tenant", "main.go"))
+	assert.Contains(t, command, "err = tenancy.MigrateTenants(ctx, db, args...)")
+	assert.Contains(t, command, "\"example.com/shop/internal/tenancy\"")
+
+	// A second tenant-owned entity joins the models; the registry is not one.
+	require.NoError(t, generateEntity("Task", "title:string", true, false, false, false, false, false, true, false, "lowercase", sm))
+	generateTenantOnboarding("Task", DBPostgres, NewSafetyManager(false, true, false))
+	models := readParsedGo(t, filepath.Join(tenancy, "models.go"))
+	assert.Contains(t, models, "&domain.Project{},\n\t\t&domain.Task{},")
+	assert.NotContains(t, models, "domain.Tenant{}")
+
+	// A Tenant type declared by hand is left alone.
+	chdirTemp(t)
+	writeTestFile(t, ".", filepath.Join(DirInternal, DirDomain, "tenant.go"), "package domain\n\ntype Tenant struct{ ID uint }\n")
+	generateTenantOnboarding("Project", DBPostgres, sm)
+	assert.NoFileExists(t, filepath.Join(DirInternal, DirDomain, "tenant_registry.go"))
+	assert.NoFileExists(t, filepath.Join("cmd", "tenant", "main.go"))
+}
@@ -116,6 +116,7 @@ func init() {
 
 // generateIntegrationTests generates integration test files.
 func generateIntegrationTests(entityName, database string, withFixtures, withContainer bool, fields []Field, sm ...*SafetyManager) error {
+	defer profileStep("integration tests", entityName)()
 	// Create integration test directory
 	integrationDir := filepath.Join("internal", "testing", "integration")
 	if err := os.MkdirAll(integrationDir, 0o755); err != nil {
@@ -127,7 +128,7 @@ func generateIntegrationTests(entityName, database string, withFixtures, withCon
 	// Generate main integration test file
 	testFile := filepath.Join(integrationDir, strings.ToLower(entityName)+"_integration_test.go")
 	content := fixGeneratedModulePath(generateIntegrationTestContent(entityName, database, withContainer, fields), importPath)
-	if err := writeFile(testFile, content, sm...); err != nil {
+	if err := writeGoFile(testFile, content, sm...); err != nil {
 		return fmt.Errorf("failed to write integration test file: %w", err)
 	}
 
@@ -140,7 +141,7 @@ func generateIntegrationTests(entityName, database string, withFixtures, withCon
 
 		fixtureFile := filepath.Join(fixturesDir, strings.ToLower(entityName)+"_fixtures.go")
 		fixtureContent := fixGeneratedModulePath(generateFixtureContent(entityName, fields), importPath)
-		if err := writeFile(fixtureFile, fixtureContent, sm...); err != nil {
+		if err := writeGoFile(fixtureFile, fixtureContent, sm...); err != nil {
 			return fmt.Errorf("failed to write fixture file: %w", err)
 		}
 	}
@@ -149,7 +150,7 @@ func generateIntegrationTests(entityName, database string, withFixtures, withCon
 	helpersFile := filepath.Join(integrationDir, "helpers.go")
 	if _, err := os.Stat(helpersFile); os.IsNotExist(err) {
 		helpersContent := fixGeneratedModulePath(generateHelpersContent(database, withContainer, entityName), importPath)
-		if err := writeFile(helpersFile, helpersContent, sm...); err != nil {
+		if err := writeGoFile(helpersFile, helpersContent, sm...); err != nil {
 			return fmt.Errorf("failed to write helpers file: %w", err)
 		}
 	}
@@ -383,6 +384,7 @@ func generateHelpersContent(database string, withContainer bool, entityName stri
 	"os"
 	"testing"
 
+	"github.com/go-testfixtures/testfixtures/v3"
 	_ "github.com/go-sql-driver/mysql" // MySQL driver
 	_ "github.com/lib/pq"              // PostgreSQL driver
 	"gorm.io/driver/mysql"
@@ -404,6 +406,7 @@ func generateHelpersContent(database string, withContainer bool, entityName stri
 	"os"
 	"testing"
 
+	"github.com/go-testfixtures/testfixtures/v3"
 	_ "github.com/go-sql-driver/mysql" // MySQL driver
 	_ "github.com/lib/pq"              // PostgreSQL driver
 	"github.com/testcontainers/testcontainers-go"
@@ -598,21 +601,55 @@ func commitTransaction(t *testing.T, tx *gorm.DB) {
 	}
 }
 
+// fixturesDir holds the YAML fixtures goca writes for each entity, one file
+// per table.
+const fixturesDir = "../../../testdata/fixtures"
+
+// loadFixtures replaces the rows of the fixture tables with the fixtures of
+// testdata/fixtures, or only with the given files of it. The tables must
+// exist, so migrate the entities first.
+func loadFixtures(t *testing.T, db *gorm.DB, files ...string) {
+	t.Helper()
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get SQL DB: %%v", err)
+	}
+
+	options := []func(*testfixtures.Loader) error{
+		testfixtures.Database(sqlDB),
+		testfixtures.Dialect(db.Dialector.Name()),
+	}
+	if len(files) == 0 {
+		options = append(options, testfixtures.Directory(fixturesDir))
+	} else {
+		paths := make([]string, len(files))
+		for i, file := range files {
+			paths[i] = fixturesDir + "/" + file
+		}
+		options = append(options, testfixtures.Files(paths...))
+	}
+	if db.Dialector.Name() == "sqlite" {
+		// testfixtures refuses databases whose name lacks "test"; an
+		// in-memory SQLite database has no name.
+		options = append(options, testfixtures.DangerousSkipTestDatabaseCheck())
+	}
+
+	fixtures, err := testfixtures.New(options...)
+	if err != nil {
+		t.Fatalf("failed to prepare fixtures: %%v", err)
+	}
+	if err := fixtures.Load(); err != nil {
+		t.Fatalf("failed to load fixtures: %%v", err)
+	}
+}
+
 // seedTestData seeds the database with test data
 func seedTestData(t *testing.T, db *gorm.DB) {
 	t.Helper()
 
 	// TODO: Add seed data for tests
-	// Example:
-	// users := []*domain.User{
-	//     {Name: "Test User 1", Email: "user1@test.com"},
-	//     {Name: "Test User 2", Email: "user2@test.com"},
-	// }
-	// for _, user := range users {
-	//     if err := db.Create(user).Error; err != nil {
-	//         t.Fatalf("failed to seed user: %%v", err)
-	//     }
-	// }
+	loadFixtures(t, db)
 }
 
 // ptr returns a pointer to v. Handy for the optional pointer fields of Update DTOs.
@@ -715,6 +752,17 @@ func needsFmtImport(fields []Field) bool {
 	return false
 }
 
+// needsTimeImport checks whether any time.Time fields exist, which generated
+// tests set to time.Now().
+func needsTimeImport(fields []Field) bool {
+	for _, f := range fields {
+		if !skipTestField(f.Name) && f.Type == "time.Time" {
+			return true
+		}
+	}
+	return false
+}
+
 // buildTestFieldInit generates Go struct literal field initializers for test code.
 func buildTestFieldInit(fields []Field, entity, indent string) string {
 	var lines []string
@@ -827,6 +875,9 @@ func replaceIntegrationTestTODOs(content string, fields []Field, entityName stri
 	if useFmt {
 		content = strings.Replace(content, "\t\"testing\"\n", "\t\"fmt\"\n\t\"testing\"\n", 1)
 	}
+	if needsTimeImport(fields) {
+		content = strings.Replace(content, "\t\"testing\"\n", "\t\"testing\"\n\t\"time\"\n", 1)
+	}
 
 	createInit := buildTestFieldInit(fields, entityName, "\t\t\t")
 	updateInit := buildTestFieldInitUpdated(fields, entityName, "\t\t\t")
@@ -854,10 +905,17 @@ func replaceFixtureTODOs(content string, fields []Field, entityName string) stri
 	lowerEntity := strings.ToLower(entityName)
 	useFmt := needsFmtImport(fields)
 
+	var std []string
 	if useFmt {
+		std = append(std, "\t\"fmt\"\n")
+	}
+	if needsTimeImport(fields) {
+		std = append(std, "\t\"time\"\n")
+	}
+	if len(std) > 0 {
 		content = strings.Replace(content,
 			"\t\"github.com/sazardev/goca/internal/domain\"",
-			"\t\"fmt\"\n\n\t\"github.com/sazardev/goca/internal/domain\"", 1)
+			strings.Join(std, "")+"\n\t\"github.com/sazardev/goca/internal/domain\"", 1)
 	}
 
 	// Default field values
@@ -891,6 +949,6 @@ func replaceHelperTODOs(content, entityName string) string {
 		"// Drop entity table (requires domain import): db.Migrator().DropTable(&domain."+entityName+"{})", 1)
 	content = strings.Replace(content,
 		"// TODO: Add seed data for tests",
-		"// Seed test data — insert "+entityName+" records via db.Create(&domain."+entityName+"{...})", 1)
+		"// Load every fixture, or only the "+entityName+" ones: loadFixtures(t, db, \""+entityTableName(entityName)+".yml\")", 1)
 	return content
 }
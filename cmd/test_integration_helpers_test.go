@@ -257,6 +257,15 @@ func TestGenerateFixtureContent_WithFields(t *testing.T) {
 	assert.Contains(t, content, `fields["price"].(float64)`)
 }
 
+func TestGenerateFixtureContent_TimeFields(t *testing.T) {
+	t.Parallel()
+
+	content := generateFixtureContent("Order", []Field{{Name: "PlacedAt", Type: "time.Time"}})
+	assert.Contains(t, content, "import (\n\t\"time\"\n\n")
+	assert.Contains(t, content, "PlacedAt: time.Now(),")
+	assert.NoError(t, checkGeneratedGo("order_fixtures.go", content))
+}
+
 func TestGenerateFixtureContent_WithoutFields(t *testing.T) {
 	t.Parallel()
 
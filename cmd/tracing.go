@@ -0,0 +1,359 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// OpenTelemetry module versions pinned in generated go.mod files. They are kept
+// in lockstep (contrib v0.49.0 is built against otel v1.24.0).
+const (
+	otelVersion        = "v1.24.0"
+	otelContribVersion = "v0.49.0"
+	otelGormVersion    = "v0.1.4"
+)
+
+// tracingGoModDeps returns the go.mod require lines needed by the tracing
+// package and the database instrumentation for the given database.
+func tracingGoModDeps(database string) string {
+	deps := fmt.Sprintf(`
+	go.opentelemetry.io/otel %[1]s
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp %[1]s
+	go.opentelemetry.io/otel/sdk %[1]s
+	go.opentelemetry.io/otel/trace %[1]s`, otelVersion)
+
+	switch {
+	case database == DBMongoDB:
+		deps += fmt.Sprintf(`
+	go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo %s`, otelContribVersion)
+	case isGormDatabase(database):
+		deps += fmt.Sprintf(`
+	gorm.io/plugin/opentelemetry %s`, otelGormVersion)
+	}
+	return deps
+}
+
+// isGormDatabase reports whether the generated main.go connects through GORM
+// for the given database (see createMainGo).
+func isGormDatabase(database string) bool {
+	switch database {
+	case DBMongoDB, DBDynamoDB, DBElasticsearch:
+		return false
+	default:
+		return true
+	}
+}
+
+// createTracingPackage writes pkg/tracing/tracing.go: OpenTelemetry SDK setup
+// with an OTLP/HTTP exporter and an HTTP server middleware.
+func createTracingPackage(projectName string, sm ...*SafetyManager) {
+	if err := writeGoFile(filepath.Join(projectName, "pkg", "tracing", "tracing.go"), tracingPackageTemplate, sm...); err != nil {
+		ui.Warning(fmt.Sprintf("Error writing tracing package: %v", err))
+	}
+}
+
+// applyTracingToMainGo instruments a generated main.go: it initializes the
+// tracer provider after the logger, adds the HTTP tracing middleware to the
+// router and registers the GORM or MongoDB instrumentation. Anchors that are
+// missing (e.g. no database connection) are skipped.
+func applyTracingToMainGo(content, projectName, module, database string) string {
+	imports := fmt.Sprintf("\t\"%s/pkg/tracing\"\n", module)
+	switch {
+	case database == DBMongoDB:
+		imports += "\t\"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo\"\n"
+	case isGormDatabase(database):
+		imports += "\totelgorm \"gorm.io/plugin/opentelemetry/tracing\"\n"
+	}
+
+	loggerImport := fmt.Sprintf("\t\"%s/pkg/logger\"\n", module)
+	content = strings.Replace(content, loggerImport, loggerImport+imports, 1)
+	if !strings.Contains(content, "\t\"context\"\n") {
+		content = strings.Replace(content, "import (\n", "import (\n\t\"context\"\n", 1)
+	}
+
+	setup := fmt.Sprintf(`logger.Init()
+
+	// Initialize OpenTelemetry tracing (OTLP endpoint from OTEL_EXPORTER_OTLP_ENDPOINT)
+	shutdownTracing, tracingErr := tracing.Setup(context.Background(), tracing.ConfigFromEnv(%q, Version, cfg.Environment))
+	if tracingErr != nil {
+		log.Printf("Warning: tracing disabled: %%v", tracingErr)
+	} else {
+		defer func() {
+			if err := shutdownTracing(context.Background()); err != nil {
+				log.Printf("Error shutting down tracing: %%v", err)
+			}
+		}()
+	}
+`, projectName)
+	content = strings.Replace(content, "logger.Init()\n", setup, 1)
+
+	routerPattern := "router := mux.NewRouter()"
+	content = strings.Replace(content, routerPattern,
+		routerPattern+"\n\trouter.Use(tracing.Middleware)", 1)
+
+	switch {
+	case database == DBMongoDB:
+		anchor := "clientOptions := options.Client().ApplyURI(dsn)"
+		content = strings.Replace(content, anchor,
+			anchor+"\n\tclientOptions.Monitor = otelmongo.NewMonitor()", 1)
+	case isGormDatabase(database):
+		anchor := "\t\t// Get underlying sql.DB for connection pool configuration"
+		content = strings.Replace(content, anchor,
+			"\t\t// Trace every query issued through GORM\n"+
+				"\t\tif err := db.Use(otelgorm.NewPlugin()); err != nil {\n"+
+				"\t\t\tlog.Printf(\"Warning: GORM tracing disabled: %v\", err)\n"+
+				"\t\t}\n\n"+anchor, 1)
+	}
+
+	return content
+}
+
+// generateUseCaseTracingDecorator produces internal/usecase/traced_<entity>_usecase.go,
+// a decorator that records one span per use case call.
+func generateUseCaseTracingDecorator(entity string, operations []string, sm ...*SafetyManager) {
+	defer profileStep("tracing", entity)()
+	entityLower := strings.ToLower(entity)
+	filename := filepath.Join(DirInternal, DirUseCase, "traced_"+entityLower+"_usecase.go")
+	importPath := getImportPath(getModuleName())
+	typeName := "traced" + entity + "UseCase"
+	iface := entity + "UseCase"
+
+	needsDomain := false
+	for _, op := range operations {
+		if op == "read" || op == "get" {
+			needsDomain = true
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("package usecase\n\n")
+	b.WriteString("import (\n")
+	b.WriteString("\t\"context\"\n\n")
+	b.WriteString("\t\"go.opentelemetry.io/otel\"\n")
+	b.WriteString("\t\"go.opentelemetry.io/otel/codes\"\n")
+	b.WriteString("\t\"go.opentelemetry.io/otel/trace\"\n")
+	if needsDomain {
+		fmt.Fprintf(&b, "\n\t\"%s/internal/domain\"\n", importPath)
+	}
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(&b, "// %s is a tracing decorator around %s. Each call is\n", typeName, iface)
+	b.WriteString("// recorded as a span; errors mark the span as failed.\n")
+	fmt.Fprintf(&b, "type %s struct {\n", typeName)
+	fmt.Fprintf(&b, "\tinner  %s\n", iface)
+	b.WriteString("\ttracer trace.Tracer\n")
+	b.WriteString("\tctx    context.Context\n")
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// NewTraced%s wraps inner with OpenTelemetry spans.\n", iface)
+	fmt.Fprintf(&b, "func NewTraced%s(inner %s) %s {\n", iface, iface, iface)
+	fmt.Fprintf(&b, "\treturn &%s{\n", typeName)
+	b.WriteString("\t\tinner:  inner,\n")
+	fmt.Fprintf(&b, "\t\ttracer: otel.Tracer(\"usecase.%s\"),\n", entityLower)
+	b.WriteString("\t\tctx:    context.Background(),\n")
+	b.WriteString("\t}\n")
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "func (t *%s) start(name string) trace.Span {\n", typeName)
+	fmt.Fprintf(&b, "\t_, span := t.tracer.Start(t.ctx, \"%s.\"+name)\n", iface)
+	b.WriteString("\treturn span\n")
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "func (t *%s) end(span trace.Span, err error) {\n", typeName)
+	b.WriteString("\tif err != nil {\n")
+	b.WriteString("\t\tspan.RecordError(err)\n")
+	b.WriteString("\t\tspan.SetStatus(codes.Error, err.Error())\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\tspan.End()\n")
+	b.WriteString("}\n\n")
+
+	for _, op := range operations {
+		switch op {
+		case "create":
+			writeTracedMethod(&b, typeName, "Create"+entity, "input Create"+entity+"Input", "input", "Create"+entity+"Output")
+		case "read", "get":
+			writeTracedMethod(&b, typeName, "Get"+entity, "id int", "id", "*domain."+entity)
+		case "update":
+			writeTracedMethod(&b, typeName, "Update"+entity, "id int, input Update"+entity+"Input", "id, input", "")
+		case "delete":
+			writeTracedMethod(&b, typeName, "Delete"+entity, "id int", "id", "")
+		case "list":
+			writeTracedMethod(&b, typeName, "List"+toPlural(entity), "", "", "List"+entity+"Output")
+		case OpCount:
+			writeTracedMethod(&b, typeName, "Count"+toPlural(entity), "", "", "int64")
+		case OpSearch:
+			writeTracedMethod(&b, typeName, "Search"+toPlural(entity), "input Search"+entity+"Input", "input", "[]domain."+entity)
+		}
+	}
+	if isMultiTenantEntity(entity) {
+		writeTracedTenantScope(&b, typeName, entity)
+	}
+
+	if err := writeGoFile(filename, b.String(), sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error creating tracing decorator: %v", err))
+	}
+}
+
+// writeTracedMethod emits one decorator method. An empty result means the
+// method only returns an error.
+func writeTracedMethod(b *strings.Builder, typeName, method, params, args, result string) {
+	if result == "" {
+		fmt.Fprintf(b, "func (t *%s) %s(%s) error {\n", typeName, method, params)
+		fmt.Fprintf(b, "\tspan := t.start(%q)\n", method)
+		fmt.Fprintf(b, "\terr := t.inner.%s(%s)\n", method, args)
+		b.WriteString("\tt.end(span, err)\n")
+		b.WriteString("\treturn err\n")
+		b.WriteString("}\n\n")
+		return
+	}
+	fmt.Fprintf(b, "func (t *%s) %s(%s) (%s, error) {\n", typeName, method, params, result)
+	fmt.Fprintf(b, "\tspan := t.start(%q)\n", method)
+	fmt.Fprintf(b, "\tresult, err := t.inner.%s(%s)\n", method, args)
+	b.WriteString("\tt.end(span, err)\n")
+	b.WriteString("\treturn result, err\n")
+	b.WriteString("}\n\n")
+}
+
+// hasTracingDecorator reports whether a tracing decorator
+// (traced_<entity>_usecase.go) was generated for the entity.
+func hasTracingDecorator(feature string) bool {
+	path := filepath.Join(DirInternal, DirUseCase, "traced_"+strings.ToLower(feature)+"_usecase.go")
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return !info.IsDir()
+}
+
+// tracingPackageTemplate is the generated pkg/tracing/tracing.go.
+const tracingPackageTemplate = `package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Config holds the resource attributes and sampling settings for tracing.
+// The OTLP exporter itself is configured through the standard
+// OTEL_EXPORTER_OTLP_* environment variables.
+type Config struct {
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+	SampleRatio    float64
+}
+
+// ConfigFromEnv builds a Config from the given defaults, letting
+// OTEL_SERVICE_NAME and OTEL_TRACES_SAMPLER_ARG override them.
+func ConfigFromEnv(serviceName, version, environment string) Config {
+	cfg := Config{
+		ServiceName:    serviceName,
+		ServiceVersion: version,
+		Environment:    environment,
+		SampleRatio:    1.0,
+	}
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		cfg.ServiceName = name
+	}
+	if arg := os.Getenv("OTEL_TRACES_SAMPLER_ARG"); arg != "" {
+		if ratio, err := strconv.ParseFloat(arg, 64); err == nil {
+			cfg.SampleRatio = ratio
+		}
+	}
+	return cfg
+}
+
+// Setup installs a global tracer provider exporting spans over OTLP/HTTP and
+// returns a function that flushes and stops it.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(
+			attribute.String("service.name", cfg.ServiceName),
+			attribute.String("service.version", cfg.ServiceVersion),
+			attribute.String("deployment.environment", cfg.Environment),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return provider.Shutdown, nil
+}
+
+// Middleware starts a server span for every routed request, continuing any
+// trace propagated by the caller. Spans are named after the route template
+// (e.g. "GET /api/v1/users/{id}") to keep cardinality low.
+func Middleware(next http.Handler) http.Handler {
+	tracer := otel.Tracer("http.server")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if tmpl, err := current.GetPathTemplate(); err == nil {
+				route = tmpl
+			}
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("http.route", route),
+			),
+		)
+		defer span.End()
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		span.SetAttributes(attribute.Int("http.response.status_code", rec.status))
+		if rec.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rec.status))
+		}
+	})
+}
+
+// statusRecorder captures the status code written by the wrapped handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+`
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateUseCaseTracingDecorator(t *testing.T) {
+	cleanup := ensureTestUI(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(dir)
+
+	require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0o644))
+
+	sm := NewSafetyManager(false, true, false)
+	generateUseCaseTracingDecorator("Product", parseOperations("create,read,update,delete,list"), sm)
+
+	content, err := os.ReadFile(filepath.Join(DirInternal, DirUseCase, "traced_product_usecase.go"))
+	require.NoError(t, err)
+	src := string(content)
+
+	_, err = parser.ParseFile(token.NewFileSet(), "traced.go", src, 0)
+	require.NoError(t, err)
+
+	assert.Contains(t, src, "func NewTracedProductUseCase(inner ProductUseCase) ProductUseCase")
+	assert.Contains(t, src, "func (t *tracedProductUseCase) CreateProduct(input CreateProductInput) (CreateProductOutput, error)")
+	assert.Contains(t, src, "func (t *tracedProductUseCase) GetProduct(id int) (*domain.Product, error)")
+	assert.Contains(t, src, "func (t *tracedProductUseCase) UpdateProduct(id int, input UpdateProductInput) error")
+	assert.Contains(t, src, "func (t *tracedProductUseCase) DeleteProduct(id int) error")
+	assert.Contains(t, src, "func (t *tracedProductUseCase) ListProducts() (ListProductOutput, error)")
+	assert.Contains(t, src, `"testproject/internal/domain"`)
+
+	assert.True(t, hasTracingDecorator("Product"))
+	assert.Equal(t, "usecase.NewTracedProductUseCase(usecase.NewProductService(c.productRepo))", useCaseConstructor("Product"))
+	assert.Equal(t, "usecase.NewOrderService(c.orderRepo)", useCaseConstructor("Order"))
+}
+
+func TestGenerateUseCaseTracingDecorator_NoReadSkipsDomainImport(t *testing.T) {
+	cleanup := ensureTestUI(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(dir)
+
+	require.NoError(t, os.WriteFile("go.mod", []byte("module testproject\n\ngo 1.21\n"), 0o644))
+
+	generateUseCaseTracingDecorator("Order", []string{"create", "delete"}, NewSafetyManager(false, true, false))
+
+	content, err := os.ReadFile(filepath.Join(DirInternal, DirUseCase, "traced_order_usecase.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "internal/domain")
+}
+
+func TestApplyTracingToMainGo(t *testing.T) {
+	cleanup := setupDiscardUI(t)
+	defer cleanup()
+
+	for _, db := range []string{DBPostgres, DBMongoDB, DBDynamoDB} {
+		t.Run(db, func(t *testing.T) {
+			dir := t.TempDir()
+			sm := NewSafetyManager(false, true, false)
+			createMainGo(dir, "github.com/test/proj", db, "", true, sm)
+
+			content, err := os.ReadFile(filepath.Join(dir, "cmd", "server", "main.go"))
+			require.NoError(t, err)
+			src := string(content)
+
+			_, err = parser.ParseFile(token.NewFileSet(), "main.go", src, 0)
+			require.NoError(t, err)
+
+			assert.Contains(t, src, `"github.com/test/proj/pkg/tracing"`)
+			assert.Contains(t, src, `tracing.ConfigFromEnv(`)
+			assert.Contains(t, src, "router.Use(tracing.Middleware)")
+			assert.Equal(t, 1, strings.Count(src, "\"context\""))
+
+			switch db {
+			case DBPostgres:
+				assert.Contains(t, src, "db.Use(otelgorm.NewPlugin())")
+			case DBMongoDB:
+				assert.Contains(t, src, "clientOptions.Monitor = otelmongo.NewMonitor()")
+			default:
+				assert.NotContains(t, src, "otelgorm")
+			}
+		})
+	}
+}
+
+func TestCreateGoMod_TracingDeps(t *testing.T) {
+	cleanup := setupDiscardUI(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	createGoMod(dir, "github.com/test/proj", DBPostgres, "", "", true, NewSafetyManager(false, true, false))
+
+	content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "go.opentelemetry.io/otel/sdk "+otelVersion)
+	assert.Contains(t, string(content), "gorm.io/plugin/opentelemetry")
+	assert.NotContains(t, string(content), "otelmongo")
+}
+
+func TestPersistInitChoices_Tracing(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".goca.yaml")
+	yaml := "features:\n    logging:\n        tracing: false\n    monitoring:\n        tracing: false\n"
+	require.NoError(t, os.WriteFile(path, []byte(yaml), 0o600))
+
+	require.NoError(t, persistInitChoices(path, APITypeRest, "", "", true))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "logging:\n        tracing: false")
+	assert.Contains(t, string(content), "monitoring:\n        tracing: true")
+}
@@ -223,12 +223,12 @@ func (u *UIRenderer) Table(headers []string, rows [][]string) {
 	// Calculate column widths
 	widths := make([]int, len(headers))
 	for i, h := range headers {
-		widths[i] = len(h)
+		widths[i] = lipgloss.Width(h)
 	}
 	for _, row := range rows {
 		for i, cell := range row {
-			if i < len(widths) && len(cell) > widths[i] {
-				widths[i] = len(cell)
+			if i < len(widths) && lipgloss.Width(cell) > widths[i] {
+				widths[i] = lipgloss.Width(cell)
 			}
 		}
 	}
@@ -250,7 +250,7 @@ func (u *UIRenderer) Table(headers []string, rows [][]string) {
 	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(colorCyan)
 	headerCells := make([]string, len(headers))
 	for i, h := range headers {
-		padded := h + strings.Repeat(" ", widths[i]-len(h))
+		padded := h + strings.Repeat(" ", widths[i]-lipgloss.Width(h))
 		headerCells[i] = " " + headerStyle.Render(padded) + " "
 	}
 	fmt.Fprintln(u.writer, borderStyle.Render("│")+strings.Join(headerCells, borderStyle.Render("│"))+borderStyle.Render("│"))
@@ -266,7 +266,7 @@ func (u *UIRenderer) Table(headers []string, rows [][]string) {
 			if i < len(row) {
 				val = row[i]
 			}
-			padded := val + strings.Repeat(" ", widths[i]-len(val))
+			padded := val + strings.Repeat(" ", widths[i]-lipgloss.Width(val))
 			cells[i] = " " + padded + " "
 		}
 		fmt.Fprintln(u.writer, borderStyle.Render("│")+strings.Join(cells, borderStyle.Render("│"))+borderStyle.Render("│"))
@@ -315,6 +315,32 @@ func (u *UIRenderer) Dim(text string) {
 	fmt.Fprintln(u.writer, style.Render(text))
 }
 
+// Diff prints a unified diff with removed lines in red and added lines in
+// green.
+func (u *UIRenderer) Diff(diff string) {
+	if u == nil {
+		return
+	}
+	plain := lipgloss.NewStyle().TabWidth(lipgloss.NoTabConversion)
+	header := plain.Bold(true)
+	hunk := plain.Foreground(colorCyan)
+	removed := plain.Foreground(colorRed)
+	added := plain.Foreground(colorGreen)
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			line = header.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			line = hunk.Render(line)
+		case strings.HasPrefix(line, "-"):
+			line = removed.Render(line)
+		case strings.HasPrefix(line, "+"):
+			line = added.Render(line)
+		}
+		fmt.Fprintln(u.writer, line)
+	}
+}
+
 // Section prints a section with a title and indented content.
 func (u *UIRenderer) Section(title string) {
 	if u == nil {
@@ -28,6 +28,7 @@ clear interfaces and encapsulated business logic.`,
 		operations, _ := cmd.Flags().GetString("operations")
 		dtoValidation, _ := cmd.Flags().GetBool("dto-validation")
 		async, _ := cmd.Flags().GetBool("async")
+		dtoStyleFlag, _ := cmd.Flags().GetString("dto-style")
 
 		if entity == "" {
 			ui.Error("--entity flag is required")
@@ -67,6 +68,12 @@ clear interfaces and encapsulated business logic.`,
 			effectiveDtoValidation = configIntegration.config.Generation.Validation.Enabled
 		}
 
+		dtoStyle := configIntegration.GetDTOStyle(dtoStyleFlag)
+		if !isValidDTOStyle(dtoStyle) {
+			ui.Error(fmt.Sprintf("Invalid DTO style %q; use one of: %s", dtoStyle, strings.Join(ValidDTOStyles, ", ")))
+			os.Exit(1)
+		}
+
 		effectiveBusinessRules := false
 		if configIntegration.config != nil {
 			effectiveBusinessRules = configIntegration.config.Generation.BusinessRules.Enabled
@@ -106,13 +113,14 @@ clear interfaces and encapsulated business logic.`,
 		// service match the real struct instead of placeholder fields. Falls back
 		// to the generic templates when the entity cannot be read.
 		entityFields := readEntityFieldsString(entity)
-		generateUseCaseWithFields(usecaseName, entity, operations, effectiveDtoValidation, async, entityFields, sm)
+		generateUseCaseWithFields(usecaseName, entity, operations, effectiveDtoValidation, async, entityFields, dtoStyle, sm)
 
 		// The generated use case service imports and references the messages
 		// package (e.g. messages.<Entity>CreatedSuccessfully), so ensure that
 		// package exists. The feature command generates messages on its own and
 		// does not go through this command, so there is no double generation.
 		generateMessages(entity, true, true, true, sm)
+		runPluginHooks(entity, sm, pluginHookUseCase)
 
 		if dryRun {
 			sm.PrintSummary()
@@ -124,10 +132,14 @@ clear interfaces and encapsulated business logic.`,
 }
 
 func generateUseCase(usecaseName, entity, operations string, dtoValidation, async bool, sm ...*SafetyManager) {
-	generateUseCaseWithFields(usecaseName, entity, operations, dtoValidation, async, "", sm...)
+	generateUseCaseWithFields(usecaseName, entity, operations, dtoValidation, async, "", DTOStyleNested, sm...)
 }
 
-func generateUseCaseWithFields(usecaseName, entity, operations string, dtoValidation, async bool, fields string, sm ...*SafetyManager) {
+// generateUseCaseWithFields generates the DTOs, interface and service of a use
+// case from the entity fields. dtoStyle (nested or flat) controls how nested
+// struct fields appear in the DTOs.
+func generateUseCaseWithFields(usecaseName, entity, operations string, dtoValidation, async bool, fields, dtoStyle string, sm ...*SafetyManager) {
+	defer profileStep("usecase", entity)()
 	// Create usecase directory
 	usecaseDir := filepath.Join(DirInternal, DirUseCase)
 	_ = os.MkdirAll(usecaseDir, 0o755)
@@ -136,9 +148,12 @@ func generateUseCaseWithFields(usecaseName, entity, operations string, dtoValida
 	ops := parseOperations(operations)
 
 	// Generate files
-	generateDTOFileWithFields(usecaseDir, entity, ops, dtoValidation, fields, sm...)
+	generateDTOFileWithFields(usecaseDir, entity, ops, dtoValidation, fields, dtoStyle, sm...)
 	generateUseCaseInterface(usecaseDir, usecaseName, entity, ops, sm...)
-	generateUseCaseServiceWithFields(usecaseDir, usecaseName, entity, ops, dtoValidation, async, fields, sm...)
+	generateUseCaseServiceWithFields(usecaseDir, usecaseName, entity, ops, dtoValidation, async, fields, dtoStyle, sm...)
+	if usecaseName == entity+"UseCase" && isAuditedEntity(entity) {
+		generateAuditedUseCase(usecaseDir, entity, ops, sm...)
+	}
 
 	// The service depends on repository.<Entity>Repository (correct Clean
 	// Architecture layering). To make a standalone `goca usecase` compile, also
@@ -167,8 +182,9 @@ func parseOperations(operations string) []string {
 	return result
 }
 
-func generateDTOFileWithFields(dir, entity string, operations []string, validation bool, fields string, sm ...*SafetyManager) {
+func generateDTOFileWithFields(dir, entity string, operations []string, validation bool, fields, dtoStyle string, sm ...*SafetyManager) {
 	filename := filepath.Join(dir, "dto.go")
+	defer lockSharedFile(filename)()
 
 	// Get the module name from go.mod
 	moduleName := getModuleName()
@@ -182,13 +198,13 @@ func generateDTOFileWithFields(dir, entity string, operations []string, validati
 		switch op {
 		case OpCreate:
 			if fields != "" {
-				generateCreateDTOWithFields(&bodyB, entity, validation, fields)
+				generateCreateDTOWithFields(&bodyB, entity, validation, fields, dtoStyle)
 			} else {
 				generateCreateDTO(&bodyB, entity, validation)
 			}
 		case OpUpdate:
 			if fields != "" {
-				generateUpdateDTOWithFields(&bodyB, entity, validation, fields)
+				generateUpdateDTOWithFields(&bodyB, entity, validation, fields, dtoStyle)
 			} else {
 				generateUpdateDTO(&bodyB, entity, validation)
 			}
@@ -196,12 +212,16 @@ func generateDTOFileWithFields(dir, entity string, operations []string, validati
 			// Read operations typically don't need input DTOs, just output
 		case OpList:
 			generateListDTO(&bodyB, entity)
+		case OpSearch:
+			generateSearchDTO(&bodyB, entity)
 		}
 	}
 	body := bodyB.String()
 	usesErrors := strings.Contains(body, "errors.")
 	usesStrings := strings.Contains(body, "strings.")
 	usesTime := strings.Contains(body, "time.")
+	usesDatatypes := strings.Contains(body, "datatypes.")
+	usesRepository := strings.Contains(body, "repository.")
 
 	var content strings.Builder
 
@@ -214,8 +234,15 @@ func generateDTOFileWithFields(dir, entity string, operations []string, validati
 			// Check if DTOs for this entity already exist
 			createDTOName := fmt.Sprintf("type Create%sInput struct", entity)
 			if strings.Contains(existingStr, createDTOName) {
-				// DTOs already exist, don't regenerate
-				return
+				// DTOs already exist, don't regenerate; a search operation
+				// added later only appends its input.
+				if !contains(operations, OpSearch) || strings.Contains(existingStr, fmt.Sprintf("type Search%sInput ", entity)) {
+					return
+				}
+				var search strings.Builder
+				generateSearchDTO(&search, entity)
+				body = search.String()
+				usesErrors, usesStrings, usesTime, usesDatatypes = false, false, false, false
 			}
 
 			// Ensure errors/strings/time are imported only if the new DTOs use them.
@@ -228,6 +255,12 @@ func generateDTOFileWithFields(dir, entity string, operations []string, validati
 			if usesTime {
 				existingStr = ensureImportInDTOFile(existingStr, "time", moduleName)
 			}
+			if usesDatatypes {
+				existingStr = ensureImportInDTOFile(existingStr, "gorm.io/datatypes", moduleName)
+			}
+			if usesRepository {
+				existingStr = ensureImportInDTOFile(existingStr, getImportPath(moduleName)+"/internal/repository", moduleName)
+			}
 
 			// Add the existing content without the final newline
 			content.WriteString(strings.TrimSuffix(existingStr, "\n"))
@@ -249,7 +282,13 @@ func generateDTOFileWithFields(dir, entity string, operations []string, validati
 		if usesErrors || usesStrings || usesTime {
 			content.WriteString("\n")
 		}
+		if usesDatatypes {
+			content.WriteString("\t\"gorm.io/datatypes\"\n\n")
+		}
 		content.WriteString(fmt.Sprintf("\t\"%s/internal/domain\"\n", getImportPath(moduleName)))
+		if usesRepository {
+			content.WriteString(fmt.Sprintf("\t\"%s/internal/repository\"\n", getImportPath(moduleName)))
+		}
 		content.WriteString(")\n\n")
 	}
 
@@ -275,6 +314,9 @@ func generateCreateDTO(content *strings.Builder, entity string, validation bool)
 		content.WriteString(" validate:\"required,min=5\"")
 	}
 	content.WriteString("`\n")
+	if isAuditedEntity(entity) {
+		writeAuditInputField(content, auditFields[0].Name)
+	}
 	content.WriteString("}\n\n")
 
 	fmt.Fprintf(content, "type Create%sOutput struct {\n", entity)
@@ -297,18 +339,28 @@ func generateUpdateDTO(content *strings.Builder, entity string, validation bool)
 		content.WriteString(" validate:\"omitempty,min=5\"")
 	}
 	content.WriteString("`\n")
+	if isAuditedEntity(entity) {
+		writeAuditInputField(content, auditFields[1].Name)
+	}
 	content.WriteString("}\n\n")
 }
 
 func generateListDTO(content *strings.Builder, entity string) {
 	entityLower := strings.ToLower(entity)
 	fmt.Fprintf(content, "type List%sOutput struct {\n", entity)
-	fmt.Fprintf(content, "\t%ss   []domain.%s `json:\"%ss\"`\n", entity, entity, entityLower)
+	fmt.Fprintf(content, "\t%s   []domain.%s `json:\"%s\"`\n", toPlural(entity), entity, toPlural(entityLower))
 	content.WriteString("\tTotal   int           `json:\"total\"`\n")
 	content.WriteString("\tMessage string        `json:\"message\"`\n")
 	content.WriteString("}\n\n")
 }
 
+// generateSearchDTO emits Search<Entity>Input, the repository criteria the
+// handler binds from query parameters.
+func generateSearchDTO(content *strings.Builder, entity string) {
+	fmt.Fprintf(content, "// Search%sInput filters Search%s; see repository.%sCriteria.\n", entity, toPlural(entity), entity)
+	fmt.Fprintf(content, "type Search%sInput = repository.%sCriteria\n\n", entity, entity)
+}
+
 func generateUseCaseInterface(dir, usecaseName, entity string, operations []string, sm ...*SafetyManager) {
 	// Get the module name from go.mod
 	moduleName := getModuleName()
@@ -318,7 +370,18 @@ func generateUseCaseInterface(dir, usecaseName, entity string, operations []stri
 
 	var content strings.Builder
 	content.WriteString("package usecase\n\n")
-	content.WriteString(fmt.Sprintf("import \"%s/internal/domain\"\n\n", getImportPath(moduleName)))
+	// Only Get and Search return domain types; the other methods use DTOs.
+	// Multi-tenant use cases are scoped with a context.
+	tenant := isMultiTenantEntity(entity)
+	usesDomain := contains(operations, OpRead) || contains(operations, StringGet) || contains(operations, OpSearch)
+	switch {
+	case tenant && usesDomain:
+		content.WriteString(fmt.Sprintf("import (\n\t\"context\"\n\n\t\"%s/internal/domain\"\n)\n\n", getImportPath(moduleName)))
+	case tenant:
+		content.WriteString("import \"context\"\n\n")
+	case usesDomain:
+		content.WriteString(fmt.Sprintf("import \"%s/internal/domain\"\n\n", getImportPath(moduleName)))
+	}
 
 	// The use-case interface is always named <Entity>UseCase so that handlers,
 	// the DI container and the feature command all refer to the same type
@@ -331,39 +394,73 @@ func generateUseCaseInterface(dir, usecaseName, entity string, operations []stri
 			content.WriteString(fmt.Sprintf("\tCreate%s(input Create%sInput) (Create%sOutput, error)\n",
 				entity, entity, entity))
 		case "read", "get":
-			content.WriteString(fmt.Sprintf("\tGet%s(id int) (*domain.%s, error)\n", entity, entity))
+			content.WriteString(fmt.Sprintf("\tGet%s(id %s) (*domain.%s, error)\n", entity, idParamType(entity), entity))
 		case "update":
-			content.WriteString(fmt.Sprintf("\tUpdate%s(id int, input Update%sInput) error\n", entity, entity))
+			content.WriteString(fmt.Sprintf("\tUpdate%s(id %s, input Update%sInput) error\n", entity, idParamType(entity), entity))
 		case "delete":
-			content.WriteString(fmt.Sprintf("\tDelete%s(id int) error\n", entity))
+			content.WriteString(fmt.Sprintf("\tDelete%s(id %s) error\n", entity, idParamType(entity)))
 		case "list":
-			content.WriteString(fmt.Sprintf("\tList%ss() (List%sOutput, error)\n", entity, entity))
+			content.WriteString(fmt.Sprintf("\tList%s() (List%sOutput, error)\n", toPlural(entity), entity))
+		case OpCount:
+			content.WriteString(fmt.Sprintf("\tCount%s() (int64, error)\n", toPlural(entity)))
+		case OpSearch:
+			content.WriteString(fmt.Sprintf("\tSearch%s(input Search%sInput) ([]domain.%s, error)\n", toPlural(entity), entity, entity))
 		}
 	}
 
 	content.WriteString("}\n")
+	if tenant {
+		writeTenantScopedUseCase(&content, entity)
+	}
 
 	if err := writeGoFile(filename, content.String(), sm...); err != nil {
 		ui.Error(fmt.Sprintf("Error creating use case file: %v", err))
 	}
 }
 
-func generateUseCaseServiceWithFields(dir, usecaseName, entity string, operations []string, dtoValidation, async bool, fields string, sm ...*SafetyManager) {
+func generateUseCaseServiceWithFields(dir, usecaseName, entity string, operations []string, dtoValidation, async bool, fields, dtoStyle string, sm ...*SafetyManager) {
 	// Get the module name from go.mod
 	moduleName := getModuleName()
 
 	entityLower := strings.ToLower(entity)
 	filename := filepath.Join(dir, entityLower+"_service.go")
 
+	// Handlers answer 422 for validation errors wrapped in
+	// messages.ErrValidation.
+	wrapValidation := contains(operations, OpCreate)
+	if wrapValidation {
+		generateMessageErrorKinds(sm...)
+	}
+	// With generation.error_wrapping the errors of the repository are wrapped
+	// with the operation that failed.
+	wrapErrors := errorWrappingEnabled() && len(operations) > 0
+	if wrapErrors {
+		generateAppErrors(sm...)
+	}
+
 	var content strings.Builder
 	content.WriteString("package usecase\n\n")
 	content.WriteString("import (\n")
+	counts := contains(operations, OpCount) || contains(operations, OpSearch)
+	tenant := isMultiTenantEntity(entity)
+	if counts || tenant {
+		content.WriteString("\t\"context\"\n")
+	}
+	if wrapValidation {
+		content.WriteString("\t\"fmt\"\n")
+	}
 	if async {
-		content.WriteString("\t\"log\"\n\n")
+		content.WriteString("\t\"log\"\n")
+	}
+	if wrapValidation || async || counts || tenant {
+		content.WriteString("\n")
 	}
 	content.WriteString(fmt.Sprintf("\t\"%s/internal/domain\"\n", getImportPath(moduleName)))
 	content.WriteString(fmt.Sprintf("\t\"%s/internal/messages\"\n", getImportPath(moduleName)))
 	content.WriteString(fmt.Sprintf("\t\"%s/internal/repository\"\n", getImportPath(moduleName)))
+	if wrapErrors {
+		fmt.Fprintf(&content, "\t%q\n", appErrorsImport())
+	}
 	content.WriteString(")\n\n")
 
 	// Async support types, defined once per service file when --async is set.
@@ -375,7 +472,8 @@ func generateUseCaseServiceWithFields(dir, usecaseName, entity string, operation
 	// Service struct
 	serviceName := fmt.Sprintf("%sService", entityLower)
 	content.WriteString(fmt.Sprintf("type %s struct {\n", serviceName))
-	content.WriteString(fmt.Sprintf("\trepo repository.%sRepository\n", entity))
+	repoInterface := serviceRepositoryInterface(entity, operations)
+	content.WriteString(fmt.Sprintf("\trepo repository.%s\n", repoInterface))
 	if async {
 		content.WriteString("\t// asyncChannel buffers tasks for asynchronous processing.\n")
 		content.WriteString("\tasyncChannel chan AsyncTask\n")
@@ -388,8 +486,8 @@ func generateUseCaseServiceWithFields(dir, usecaseName, entity string, operation
 	// The exported constructor uses the PascalCase entity name (New<Entity>Service)
 	// so it matches the DI container and works for multi-word entities; the
 	// unexported struct keeps its lowercased name.
-	content.WriteString(fmt.Sprintf("func New%sService(repo repository.%sRepository) %s {\n",
-		entity, entity, interfaceName))
+	content.WriteString(fmt.Sprintf("func New%sService(repo repository.%s) %s {\n",
+		entity, repoInterface, interfaceName))
 	if async {
 		content.WriteString(fmt.Sprintf("\ts := &%s{\n", serviceName))
 		content.WriteString("\t\trepo:         repo,\n")
@@ -422,25 +520,33 @@ func generateUseCaseServiceWithFields(dir, usecaseName, entity string, operation
 		switch op {
 		case "create":
 			if fields != "" {
-				generateCreateMethodWithFields(&content, serviceName, entity, fields, callDTOValidate)
+				generateCreateMethodWithFields(&content, serviceName, entity, fields, dtoStyle, callDTOValidate, wrapValidation, wrapErrors)
 			} else {
-				generateCreateMethod(&content, serviceName, entity)
+				generateCreateMethod(&content, serviceName, entity, wrapValidation, wrapErrors)
 			}
 		case "read", "get":
-			generateGetMethod(&content, serviceName, entity)
+			generateGetMethod(&content, serviceName, entity, wrapErrors)
 		case "update":
 			if fields != "" {
-				generateUpdateMethodWithFields(&content, serviceName, entity, fields)
+				generateUpdateMethodWithFields(&content, serviceName, entity, fields, dtoStyle, wrapErrors)
 			} else {
-				generateUpdateMethod(&content, serviceName, entity)
+				generateUpdateMethod(&content, serviceName, entity, wrapErrors)
 			}
 		case "delete":
-			generateDeleteMethod(&content, serviceName, entity)
+			generateDeleteMethod(&content, serviceName, entity, wrapErrors)
 		case "list":
-			generateListMethod(&content, serviceName, entity)
+			generateListMethod(&content, serviceName, entity, wrapErrors)
+		case OpCount:
+			generateCountMethod(&content, serviceName, entity, wrapErrors)
+		case OpSearch:
+			generateSearchMethod(&content, serviceName, entity, wrapErrors)
 		}
 	}
 
+	if tenant {
+		writeServiceTenantScope(&content, serviceName, entity)
+	}
+
 	// When --async is enabled, emit a fire-and-forget wrapper for the create
 	// operation that queues the work on the service's async channel.
 	if async {
@@ -457,7 +563,48 @@ func generateUseCaseServiceWithFields(dir, usecaseName, entity string, operation
 	}
 }
 
-func generateCreateMethod(content *strings.Builder, serviceName, entity string) {
+// serviceRepositoryInterface returns the repository interface a service with
+// the given operations depends on. With segregated interfaces a read-only or
+// write-only service gets <Entity>Reader or <Entity>Writer; an existing
+// combined-only interfaces.go keeps <Entity>Repository so the service compiles.
+func serviceRepositoryInterface(entity string, operations []string) string {
+	// Search is declared by <Entity>Searcher, next to the field finders, and
+	// WithContext by <Entity>Repository.
+	if !repositoryInterfacesSegregated() || contains(operations, OpSearch) || isMultiTenantEntity(entity) {
+		return entity + "Repository"
+	}
+	interfaces := filepath.Join(DirInternal, DirRepository, "interfaces.go")
+	unlock := lockSharedFile(interfaces)
+	existing, err := os.ReadFile(interfaces)
+	unlock()
+	if err == nil {
+		src := string(existing)
+		if strings.Contains(src, "type "+entity+"Repository interface") && !strings.Contains(src, "type "+entity+"Reader interface") {
+			return entity + "Repository"
+		}
+	}
+
+	reads, writes := false, false
+	for _, op := range operations {
+		switch op {
+		case OpRead, StringGet, OpList, OpCount:
+			reads = true
+		case OpCreate, OpDelete:
+			writes = true
+		case OpUpdate:
+			reads, writes = true, true
+		}
+	}
+	switch {
+	case reads && !writes:
+		return entity + "Reader"
+	case writes && !reads:
+		return entity + "Writer"
+	}
+	return entity + "Repository"
+}
+
+func generateCreateMethod(content *strings.Builder, serviceName, entity string, wrapValidation, wrapErrors bool) {
 	entityLower := strings.ToLower(entity)
 	serviceVar := string(serviceName[0])
 
@@ -467,14 +614,17 @@ func generateCreateMethod(content *strings.Builder, serviceName, entity string)
 	content.WriteString("\t\t// Automatic field mapping - adjust according to your entity\n")
 	content.WriteString("\t\t// Name: input.Name,\n")
 	content.WriteString("\t\t// Description: input.Description,\n")
+	if isAuditedEntity(entity) {
+		writeAuditCreateMapping(content)
+	}
 	content.WriteString("\t}\n\n")
 
 	fmt.Fprintf(content, "\tif err := %s.Validate(); err != nil {\n", entityLower)
-	fmt.Fprintf(content, "\t\treturn Create%sOutput{}, err\n", entity)
+	fmt.Fprintf(content, "\t\treturn Create%sOutput{}, %s\n", entity, validationError(wrapValidation))
 	content.WriteString("\t}\n\n")
 
 	fmt.Fprintf(content, "\tif err := %s.repo.Save(&%s); err != nil {\n", serviceVar, entityLower)
-	fmt.Fprintf(content, "\t\treturn Create%sOutput{}, err\n", entity)
+	fmt.Fprintf(content, "\t\treturn Create%sOutput{}, %s\n", entity, useCaseError(wrapErrors, OpCreate, entity))
 	content.WriteString("\t}\n\n")
 
 	fmt.Fprintf(content, "\treturn Create%sOutput{\n", entity)
@@ -484,40 +634,51 @@ func generateCreateMethod(content *strings.Builder, serviceName, entity string)
 	content.WriteString("}\n\n")
 }
 
-func generateCreateMethodWithFields(content *strings.Builder, serviceName, entity, fields string, callDTOValidate bool) {
+func generateCreateMethodWithFields(content *strings.Builder, serviceName, entity, fields, dtoStyle string, callDTOValidate, wrapValidation, wrapErrors bool) {
 	entityLower := strings.ToLower(entity)
 	serviceVar := string(serviceName[0])
 	fieldsList := parseFields(fields)
 
 	fmt.Fprintf(content, "func (%s *%s) Create%s(input Create%sInput) (Create%sOutput, error) {\n",
 		serviceVar, serviceName, entity, entity, entity)
+	// Inputs not decoded by the HTTP handlers may still use deprecated fields.
+	if hasDeprecatedFields(fields) {
+		content.WriteString("\tinput.Upgrade()\n\n")
+	}
 
 	// Validate the input DTO first when DTO validation is enabled, so malformed
 	// requests are rejected before building the domain entity.
 	if callDTOValidate {
 		content.WriteString("\tif err := input.Validate(); err != nil {\n")
-		fmt.Fprintf(content, "\t\treturn Create%sOutput{}, err\n", entity)
+		fmt.Fprintf(content, "\t\treturn Create%sOutput{}, %s\n", entity, validationError(wrapValidation))
 		content.WriteString("\t}\n\n")
 	}
 
-	fmt.Fprintf(content, "\t%s := domain.%s{\n", entityLower, entity)
+	if hasFieldDefaults(fieldsList) {
+		writeCreateWithConstructor(content, entityLower, entity, fieldsList, dtoStyle)
+	} else {
+		fmt.Fprintf(content, "\t%s := domain.%s{\n", entityLower, entity)
 
-	// Map fields from input to entity
-	for _, field := range fieldsList {
-		if field.Name == "ID" {
-			continue // Skip ID, it's auto-generated
+		// Map fields from input to entity
+		for _, field := range withoutComputedFields(fieldsList) {
+			if field.Name == "ID" {
+				continue // Skip ID, it's auto-generated
+			}
+			writeCreateMapping(content, field, dtoStyle)
+		}
+		if isAuditedEntity(entity) {
+			writeAuditCreateMapping(content)
 		}
-		fmt.Fprintf(content, "\t\t%s: input.%s,\n", field.Name, field.Name)
-	}
 
-	content.WriteString("\t}\n\n")
+		content.WriteString("\t}\n\n")
+	}
 
 	fmt.Fprintf(content, "\tif err := %s.Validate(); err != nil {\n", entityLower)
-	fmt.Fprintf(content, "\t\treturn Create%sOutput{}, err\n", entity)
+	fmt.Fprintf(content, "\t\treturn Create%sOutput{}, %s\n", entity, validationError(wrapValidation))
 	content.WriteString("\t}\n\n")
 
 	fmt.Fprintf(content, "\tif err := %s.repo.Save(&%s); err != nil {\n", serviceVar, entityLower)
-	fmt.Fprintf(content, "\t\treturn Create%sOutput{}, err\n", entity)
+	fmt.Fprintf(content, "\t\treturn Create%sOutput{}, %s\n", entity, useCaseError(wrapErrors, OpCreate, entity))
 	content.WriteString("\t}\n\n")
 
 	fmt.Fprintf(content, "\treturn Create%sOutput{\n", entity)
@@ -528,7 +689,7 @@ func generateCreateMethodWithFields(content *strings.Builder, serviceName, entit
 		if field.Name == "ID" || field.Name == "CreatedAt" || field.Name == "UpdatedAt" || field.Name == "DeletedAt" {
 			continue
 		}
-		fmt.Fprintf(content, "\t\t%s: %s.%s,\n", field.Name, entityLower, field.Name)
+		writeCreateOutputMapping(content, entityLower, field, dtoStyle)
 	}
 
 	fmt.Fprintf(content, "\t\tMessage: messages.%sCreatedSuccessfully,\n", entity)
@@ -536,54 +697,152 @@ func generateCreateMethodWithFields(content *strings.Builder, serviceName, entit
 	content.WriteString("}\n\n")
 }
 
-func generateGetMethod(content *strings.Builder, serviceName, entity string) {
+// writeCreateMapping writes the Create<Entity>Input to entity mapping of one
+// field as a composite literal element.
+func writeCreateMapping(content *strings.Builder, field Field, dtoStyle string) {
+	fmt.Fprintf(content, "\t\t%s: %s,\n", field.Name, createInputValue(field, dtoStyle))
+}
+
+// createInputValue returns the Create<Entity>Input expression of one entity
+// field.
+func createInputValue(field Field, dtoStyle string) string {
+	if !flattenedField(field, dtoStyle) {
+		return "input." + field.Name
+	}
+	// Flat DTOs carry one <Field><Sub> value per nested field.
+	var value strings.Builder
+	fmt.Fprintf(&value, "domain.%s{\n", field.Type)
+	for _, sub := range field.Nested {
+		fmt.Fprintf(&value, "\t\t\t%s: input.%s%s,\n", sub.Name, field.Name, sub.Name)
+	}
+	value.WriteString("\t\t}")
+	return value.String()
+}
+
+// writeCreateWithConstructor builds the entity of Create<Entity> with
+// domain.New<Entity>, which sets the defaults, then applies the defaulted
+// fields the input sets. Bools default to true, so false takes the default.
+func writeCreateWithConstructor(content *strings.Builder, entityVar, entity string, fields []Field, dtoStyle string) {
+	params := constructorFields(fields)
+	args := make([]string, len(params))
+	for i, field := range params {
+		args[i] = createInputValue(field, dtoStyle)
+	}
+	fmt.Fprintf(content, "\t%s := *domain.New%s(%s)\n", entityVar, entity, strings.Join(args, ", "))
+	writeDefaultOverrides(content, entityVar, fields)
+	if isAuditedEntity(entity) {
+		writeAuditCreateAssignments(content, entityVar)
+	}
+	content.WriteString("\n")
+}
+
+// writeDefaultOverrides writes the assignments of the defaulted fields the
+// input sets to a non-zero value.
+func writeDefaultOverrides(content *strings.Builder, entityVar string, fields []Field) {
+	for _, field := range fields {
+		if field.Default == "" || field.Type == FieldBool {
+			continue
+		}
+		zero := "0"
+		if field.Type == FieldString {
+			zero = `""`
+		}
+		fmt.Fprintf(content, "\tif input.%s != %s {\n", field.Name, zero)
+		fmt.Fprintf(content, "\t\t%s.%s = input.%s\n", entityVar, field.Name, field.Name)
+		content.WriteString("\t}\n")
+	}
+}
+
+// writeCreateOutputMapping writes the entity to Create<Entity>Output mapping of
+// one field, reading from the entityVar variable.
+func writeCreateOutputMapping(content *strings.Builder, entityVar string, field Field, dtoStyle string) {
+	if flattenedField(field, dtoStyle) {
+		for _, sub := range field.Nested {
+			fmt.Fprintf(content, "\t\t%s%s: %s.%s.%s,\n", field.Name, sub.Name, entityVar, field.Name, sub.Name)
+		}
+		return
+	}
+	fmt.Fprintf(content, "\t\t%s: %s.%s,\n", field.Name, entityVar, field.Name)
+}
+
+func generateGetMethod(content *strings.Builder, serviceName, entity string, wrapErrors bool) {
 	serviceVar := string(serviceName[0])
 
-	fmt.Fprintf(content, "func (%s *%s) Get%s(id int) (*domain.%s, error) {\n",
-		serviceVar, serviceName, entity, entity)
-	fmt.Fprintf(content, "\treturn %s.repo.FindByID(id)\n", serviceVar)
+	fmt.Fprintf(content, "func (%s *%s) Get%s(id %s) (*domain.%s, error) {\n",
+		serviceVar, serviceName, entity, idParamType(entity), entity)
+	writeWrappedReturn(content, wrapErrors, StringGet, entity, serviceVar+".repo.FindByID(id)", strings.ToLower(entity), "nil")
 	content.WriteString("}\n\n")
 }
 
-func generateUpdateMethodWithFields(content *strings.Builder, serviceName, entity, fields string) {
+func generateUpdateMethodWithFields(content *strings.Builder, serviceName, entity, fields, dtoStyle string, wrapErrors bool) {
 	serviceVar := string(serviceName[0])
 	entityVar := strings.ToLower(entity)
 	fieldsList := parseFields(fields)
 
-	fmt.Fprintf(content, "func (%s *%s) Update%s(id int, input Update%sInput) error {\n",
-		serviceVar, serviceName, entity, entity)
+	fmt.Fprintf(content, "func (%s *%s) Update%s(id %s, input Update%sInput) error {\n",
+		serviceVar, serviceName, entity, idParamType(entity), entity)
+	if hasDeprecatedFields(fields) {
+		content.WriteString("\tinput.Upgrade()\n")
+	}
 	fmt.Fprintf(content, "\t%s, err := %s.repo.FindByID(id)\n", entityVar, serviceVar)
 	content.WriteString("\tif err != nil {\n")
-	content.WriteString("\t\treturn err\n")
+	fmt.Fprintf(content, "\t\treturn %s\n", useCaseError(wrapErrors, OpUpdate, entity))
 	content.WriteString("\t}\n\n")
+	if isVersionedEntity(entity) {
+		writeUpdateVersionCheck(content, entityVar)
+	}
 
 	// Update fields based on actual entity fields
 	// In UpdateInput DTOs, fields are always pointers (optional updates)
-	for _, field := range fieldsList {
+	for _, field := range withoutComputedFields(fieldsList) {
 		if field.Name == "ID" || field.Name == "CreatedAt" || field.Name == "UpdatedAt" || field.Name == "DeletedAt" {
 			continue
 		}
 
-		// Fields in UpdateInput are always pointers, check if not nil
-		fmt.Fprintf(content, "\tif input.%s != nil {\n", field.Name)
-		fmt.Fprintf(content, "\t\t%s.%s = *input.%s\n", entityVar, field.Name, field.Name)
-		content.WriteString("\t}\n")
+		writeUpdateMapping(content, entityVar, field, dtoStyle)
+	}
+	if isAuditedEntity(entity) {
+		writeAuditUpdateMapping(content, entityVar)
 	}
 
 	content.WriteString("\n")
-	fmt.Fprintf(content, "\treturn %s.repo.Update(%s)\n", serviceVar, entityVar)
+	writeWrappedErrorReturn(content, wrapErrors, OpUpdate, entity, serviceVar+".repo.Update("+entityVar+")")
 	content.WriteString("}\n\n")
 }
 
-func generateUpdateMethod(content *strings.Builder, serviceName, entity string) {
+// writeUpdateMapping writes the statements applying one Update<Entity>Input
+// field to the entityVar entity.
+func writeUpdateMapping(content *strings.Builder, entityVar string, field Field, dtoStyle string) {
+	if flattenedField(field, dtoStyle) {
+		// Each flat nested field updates its own part of the struct.
+		for _, sub := range field.Nested {
+			fmt.Fprintf(content, "\tif input.%s%s != nil {\n", field.Name, sub.Name)
+			fmt.Fprintf(content, "\t\t%s.%s.%s = *input.%s%s\n", entityVar, field.Name, sub.Name, field.Name, sub.Name)
+			content.WriteString("\t}\n")
+		}
+		return
+	}
+
+	// Fields in UpdateInput are always pointers, check if not nil.
+	// Pointer entity fields keep their own type in the DTO.
+	deref := "*"
+	if isPointerType(field.Type) {
+		deref = ""
+	}
+	fmt.Fprintf(content, "\tif input.%s != nil {\n", field.Name)
+	fmt.Fprintf(content, "\t\t%s.%s = %sinput.%s\n", entityVar, field.Name, deref, field.Name)
+	content.WriteString("\t}\n")
+}
+
+func generateUpdateMethod(content *strings.Builder, serviceName, entity string, wrapErrors bool) {
 	serviceVar := string(serviceName[0])
 	entityVar := strings.ToLower(entity)
 
-	fmt.Fprintf(content, "func (%s *%s) Update%s(id int, input Update%sInput) error {\n",
-		serviceVar, serviceName, entity, entity)
+	fmt.Fprintf(content, "func (%s *%s) Update%s(id %s, input Update%sInput) error {\n",
+		serviceVar, serviceName, entity, idParamType(entity), entity)
 	fmt.Fprintf(content, "\t%s, err := %s.repo.FindByID(id)\n", entityVar, serviceVar)
 	content.WriteString("\tif err != nil {\n")
-	content.WriteString("\t\treturn err\n")
+	fmt.Fprintf(content, "\t\treturn %s\n", useCaseError(wrapErrors, OpUpdate, entity))
 	content.WriteString("\t}\n\n")
 
 	// The generic Update<Entity>Input DTO exposes optional (pointer) Name and
@@ -595,40 +854,65 @@ func generateUpdateMethod(content *strings.Builder, serviceName, entity string)
 	content.WriteString("\tif input.Description != nil {\n")
 	fmt.Fprintf(content, "\t\t%s.Description = *input.Description\n", entityVar)
 	content.WriteString("\t}\n")
-	content.WriteString("\t// Add more fields as needed\n\n")
+	content.WriteString("\t// Add more fields as needed\n")
+	if isAuditedEntity(entity) {
+		writeAuditUpdateMapping(content, entityVar)
+	}
+	content.WriteString("\n")
 
-	fmt.Fprintf(content, "\treturn %s.repo.Update(%s)\n", serviceVar, entityVar)
+	writeWrappedErrorReturn(content, wrapErrors, OpUpdate, entity, serviceVar+".repo.Update("+entityVar+")")
 	content.WriteString("}\n\n")
 }
 
-func generateDeleteMethod(content *strings.Builder, serviceName, entity string) {
+func generateDeleteMethod(content *strings.Builder, serviceName, entity string, wrapErrors bool) {
 	serviceVar := string(serviceName[0])
 
-	fmt.Fprintf(content, "func (%s *%s) Delete%s(id int) error {\n",
-		serviceVar, serviceName, entity)
-	fmt.Fprintf(content, "\treturn %s.repo.Delete(id)\n", serviceVar)
+	fmt.Fprintf(content, "func (%s *%s) Delete%s(id %s) error {\n",
+		serviceVar, serviceName, entity, idParamType(entity))
+	writeWrappedErrorReturn(content, wrapErrors, OpDelete, entity, serviceVar+".repo.Delete(id)")
 	content.WriteString("}\n\n")
 }
 
-func generateListMethod(content *strings.Builder, serviceName, entity string) {
+func generateListMethod(content *strings.Builder, serviceName, entity string, wrapErrors bool) {
 	serviceVar := string(serviceName[0])
 	entityLower := strings.ToLower(entity)
 
-	fmt.Fprintf(content, "func (%s *%s) List%ss() (List%sOutput, error) {\n",
-		serviceVar, serviceName, entity, entity)
-	fmt.Fprintf(content, "\t%ss, err := %s.repo.FindAll()\n", entityLower, serviceVar)
+	fmt.Fprintf(content, "func (%s *%s) List%s() (List%sOutput, error) {\n",
+		serviceVar, serviceName, toPlural(entity), entity)
+	fmt.Fprintf(content, "\t%s, err := %s.repo.FindAll()\n", toPlural(entityLower), serviceVar)
 	content.WriteString("\tif err != nil {\n")
-	fmt.Fprintf(content, "\t\treturn List%sOutput{}, err\n", entity)
+	fmt.Fprintf(content, "\t\treturn List%sOutput{}, %s\n", entity, useCaseError(wrapErrors, OpList, entity))
 	content.WriteString("\t}\n\n")
 
 	fmt.Fprintf(content, "\treturn List%sOutput{\n", entity)
-	fmt.Fprintf(content, "\t\t%ss:   %ss,\n", entity, entityLower)
-	fmt.Fprintf(content, "\t\tTotal:   len(%ss),\n", entityLower)
-	fmt.Fprintf(content, "\t\tMessage: messages.%ssListedSuccessfully,\n", entity)
+	fmt.Fprintf(content, "\t\t%s:   %s,\n", toPlural(entity), toPlural(entityLower))
+	fmt.Fprintf(content, "\t\tTotal:   len(%s),\n", toPlural(entityLower))
+	fmt.Fprintf(content, "\t\tMessage: messages.%sListedSuccessfully,\n", toPlural(entity))
 	content.WriteString("\t}, nil\n")
 	content.WriteString("}\n\n")
 }
 
+// generateCountMethod emits Count<Entity>s, which counts in the repository
+// instead of loading every entity.
+func generateCountMethod(content *strings.Builder, serviceName, entity string, wrapErrors bool) {
+	serviceVar := string(serviceName[0])
+
+	fmt.Fprintf(content, "func (%s *%s) Count%s() (int64, error) {\n", serviceVar, serviceName, toPlural(entity))
+	call := fmt.Sprintf("%s.repo.Count(context.Background(), repository.%sSpec{})", serviceVar, entity)
+	writeWrappedReturn(content, wrapErrors, OpCount, entity, call, "count", "0")
+	content.WriteString("}\n\n")
+}
+
+// generateSearchMethod emits Search<Entity>s, which passes the criteria to the
+// repository's Search.
+func generateSearchMethod(content *strings.Builder, serviceName, entity string, wrapErrors bool) {
+	serviceVar := string(serviceName[0])
+
+	fmt.Fprintf(content, "func (%s *%s) Search%s(input Search%sInput) ([]domain.%s, error) {\n", serviceVar, serviceName, toPlural(entity), entity, entity)
+	writeWrappedReturn(content, wrapErrors, OpSearch, entity, serviceVar+".repo.Search(context.Background(), input)", "results", "nil")
+	content.WriteString("}\n\n")
+}
+
 // generateAsyncCreateMethod emits a fire-and-forget wrapper around Create that
 // queues the work on the service's async channel and logs any error.
 func generateAsyncCreateMethod(content *strings.Builder, serviceName, entity string) {
@@ -646,66 +930,43 @@ func generateAsyncCreateMethod(content *strings.Builder, serviceName, entity str
 	content.WriteString("}\n\n")
 }
 
-func generateCreateDTOWithFields(content *strings.Builder, entity string, validation bool, fields string) {
-	fieldsList := parseFields(fields)
+func generateCreateDTOWithFields(content *strings.Builder, entity string, validation bool, fields, dtoStyle string) {
+	fieldsList := dtoFields(parseFields(fields), dtoStyle)
+	aliases := deprecatedAliases(fields)
 
 	// Generate Create Input DTO
 	fmt.Fprintf(content, "// Create%sInput is the DTO for creating a new %s.\n", entity, strings.ToLower(entity))
 	fmt.Fprintf(content, "type Create%sInput struct {\n", entity)
 
-	for _, field := range fieldsList {
+	for _, field := range withoutComputedFields(fieldsList) {
 		// Skip auto-managed fields in create request
 		if field.Name == "ID" || field.Name == "CreatedAt" || field.Name == "UpdatedAt" || field.Name == "DeletedAt" {
 			continue
 		}
-
-		jsonTag := fmt.Sprintf("json:\"%s\"", strings.ToLower(field.Name))
-
-		if validation {
-			validateTag := dtoValidationTag(field)
-			fmt.Fprintf(content, "\t%s %s `%s validate:\"%s\"`\n",
-				field.Name, field.Type, jsonTag, validateTag)
-		} else {
-			fmt.Fprintf(content, "\t%s %s `%s`\n",
-				field.Name, field.Type, jsonTag)
-		}
+		writeCreateInputField(content, entity, field, validation)
+	}
+	for _, alias := range aliases {
+		writeDeprecatedAliasField(content, alias, fieldsList, false)
+	}
+	if isAuditedEntity(entity) {
+		writeAuditInputField(content, auditFields[0].Name)
 	}
 
 	content.WriteString("}\n\n")
+	if hasDeprecatedFields(fields) {
+		writeInputUpgrade(content, fmt.Sprintf("Create%sInput", entity), withoutComputedFields(fieldsList), aliases, false)
+	}
 
 	// Generate validation method for the DTO
 	if validation {
 		fmt.Fprintf(content, "// Validate checks the Create%sInput DTO fields.\n", entity)
 		fmt.Fprintf(content, "func (r *Create%sInput) Validate() error {\n", entity)
 
-		for _, field := range fieldsList {
+		for _, field := range withoutComputedFields(fieldsList) {
 			if field.Name == "ID" || field.Name == "CreatedAt" || field.Name == "UpdatedAt" || field.Name == "DeletedAt" {
 				continue
 			}
-
-			switch field.Type {
-			case "string":
-				if strings.Contains(strings.ToLower(field.Name), "email") {
-					fmt.Fprintf(content, "\tif r.%s == \"\" {\n", field.Name)
-					fmt.Fprintf(content, "\t\treturn errors.New(\"%s is required\")\n", getFieldDisplayName(strings.ToLower(field.Name)))
-					content.WriteString("\t}\n")
-					fmt.Fprintf(content, "\tif !strings.Contains(r.%s, \"@\") {\n", field.Name)
-					fmt.Fprintf(content, "\t\treturn errors.New(\"invalid %s format\")\n", getFieldDisplayName(strings.ToLower(field.Name)))
-					content.WriteString("\t}\n")
-				} else {
-					fmt.Fprintf(content, "\tif strings.TrimSpace(r.%s) == \"\" {\n", field.Name)
-					fmt.Fprintf(content, "\t\treturn errors.New(\"%s is required\")\n", getFieldDisplayName(strings.ToLower(field.Name)))
-					content.WriteString("\t}\n")
-				}
-			case "int", "int64", "uint", "uint64":
-				fmt.Fprintf(content, "\tif r.%s < 0 {\n", field.Name)
-				fmt.Fprintf(content, "\t\treturn errors.New(\"%s must be a positive number\")\n", getFieldDisplayName(strings.ToLower(field.Name)))
-				content.WriteString("\t}\n")
-			case "float64", "float32":
-				fmt.Fprintf(content, "\tif r.%s < 0 {\n", field.Name)
-				fmt.Fprintf(content, "\t\treturn errors.New(\"%s must be a positive number\")\n", getFieldDisplayName(strings.ToLower(field.Name)))
-				content.WriteString("\t}\n")
-			}
+			writeCreateInputValidation(content, "r", field)
 		}
 
 		content.WriteString("\treturn nil\n")
@@ -715,61 +976,212 @@ func generateCreateDTOWithFields(content *strings.Builder, entity string, valida
 	// Generate Create Output DTO
 	fmt.Fprintf(content, "// Create%sOutput DTO for the creation response\n", entity)
 	fmt.Fprintf(content, "type Create%sOutput struct {\n", entity)
-	content.WriteString("\tID      uint   `json:\"id\"`\n")
+	fmt.Fprintf(content, "\tID      %-6s `json:\"id\"`\n", entityIDType(entity))
 
 	// Add actual fields to response
 	for _, field := range fieldsList {
 		if field.Name == "ID" || field.Name == "CreatedAt" || field.Name == "UpdatedAt" || field.Name == "DeletedAt" {
 			continue
 		}
-		jsonTag := fmt.Sprintf("json:\"%s\"", strings.ToLower(field.Name))
-		fmt.Fprintf(content, "\t%s %s `%s`\n", field.Name, field.Type, jsonTag)
+		writeCreateOutputField(content, field)
 	}
 
 	content.WriteString("\tMessage string `json:\"message\"`\n")
 	content.WriteString("}\n\n")
 }
 
-func generateUpdateDTOWithFields(content *strings.Builder, entity string, validation bool, fields string) {
-	fieldsList := parseFields(fields)
+// writeCreateInputField writes one Create<Entity>Input field. Files are not
+// read from the request body: HTTP handlers fill them with the uploads.
+func writeCreateInputField(content *strings.Builder, entity string, field Field, validation bool) {
+	jsonTag := fmt.Sprintf("json:\"%s\"", dtoJSONName(field))
+	if isFileField(field) {
+		jsonTag = `json:"-"`
+	}
+
+	validateTag := dtoValidationTag(entity, field)
+	if field.Default != "" || field.Deprecated {
+		// An empty value takes the default; new clients leave deprecated
+		// fields out.
+		validateTag = dtoUpdateValidationTag(entity, field)
+	}
+	if field.Deprecated {
+		writeDeprecatedComment(content, field, nil)
+		jsonTag += deprecatedDTOTag(field, nil)
+	}
+	if validation && validateTag != "" {
+		fmt.Fprintf(content, "\t%s %s `%s validate:\"%s\"`\n",
+			field.Name, dtoFieldType(field), jsonTag, validateTag)
+	} else {
+		fmt.Fprintf(content, "\t%s %s `%s`\n",
+			field.Name, dtoFieldType(field), jsonTag)
+	}
+}
+
+// writeCreateInputValidation writes the Create<Entity>Input.Validate checks for
+// one field, with recv as the method receiver. Fields with a default may be
+// left empty.
+func writeCreateInputValidation(content *strings.Builder, recv string, field Field) {
+	if field.Deprecated {
+		return
+	}
+	switch field.Type {
+	case "string":
+		if field.Default != "" {
+			return
+		}
+		if strings.Contains(strings.ToLower(field.Name), "email") {
+			fmt.Fprintf(content, "\tif %s.%s == \"\" {\n", recv, field.Name)
+			fmt.Fprintf(content, "\t\treturn errors.New(\"%s is required\")\n", getFieldDisplayName(strings.ToLower(field.Name)))
+			content.WriteString("\t}\n")
+			fmt.Fprintf(content, "\tif !strings.Contains(%s.%s, \"@\") {\n", recv, field.Name)
+			fmt.Fprintf(content, "\t\treturn errors.New(\"invalid %s format\")\n", getFieldDisplayName(strings.ToLower(field.Name)))
+			content.WriteString("\t}\n")
+		} else {
+			fmt.Fprintf(content, "\tif strings.TrimSpace(%s.%s) == \"\" {\n", recv, field.Name)
+			fmt.Fprintf(content, "\t\treturn errors.New(\"%s is required\")\n", getFieldDisplayName(strings.ToLower(field.Name)))
+			content.WriteString("\t}\n")
+		}
+	case "int", "int64", "uint", "uint64":
+		fmt.Fprintf(content, "\tif %s.%s < 0 {\n", recv, field.Name)
+		fmt.Fprintf(content, "\t\treturn errors.New(\"%s must be a positive number\")\n", getFieldDisplayName(strings.ToLower(field.Name)))
+		content.WriteString("\t}\n")
+	case "float64", "float32":
+		fmt.Fprintf(content, "\tif %s.%s < 0 {\n", recv, field.Name)
+		fmt.Fprintf(content, "\t\treturn errors.New(\"%s must be a positive number\")\n", getFieldDisplayName(strings.ToLower(field.Name)))
+		content.WriteString("\t}\n")
+	}
+}
+
+// writeCreateOutputField writes one Create<Entity>Output field.
+func writeCreateOutputField(content *strings.Builder, field Field) {
+	jsonTag := fmt.Sprintf("json:\"%s\"", dtoJSONName(field))
+	fmt.Fprintf(content, "\t%s %s `%s`\n", field.Name, dtoFieldType(field), jsonTag)
+}
+
+func generateUpdateDTOWithFields(content *strings.Builder, entity string, validation bool, fields, dtoStyle string) {
+	fieldsList := dtoFields(parseFields(fields), dtoStyle)
+	aliases := deprecatedAliases(fields)
 
 	// Generate Update Input DTO (fields are optional)
 	fmt.Fprintf(content, "type Update%sInput struct {\n", entity)
 
-	for _, field := range fieldsList {
+	for _, field := range withoutComputedFields(fieldsList) {
 		// Skip ID field in update input (it's in the URL)
 		if field.Name == "ID" {
 			continue
 		}
+		writeUpdateInputField(content, entity, field, validation)
+	}
+	for _, alias := range aliases {
+		writeDeprecatedAliasField(content, alias, fieldsList, true)
+	}
+	if isVersionedEntity(entity) {
+		// The version the update is based on; HTTP handlers fill it from If-Match.
+		content.WriteString("\tVersion *int `json:\"version,omitempty\"`\n")
+	}
+	if isAuditedEntity(entity) {
+		writeAuditInputField(content, auditFields[1].Name)
+	}
 
-		// Make fields optional for update (pointers)
-		var fieldType string
-		switch field.Type {
-		case "string":
-			fieldType = "*string"
-		case "int":
-			fieldType = "*int"
-		case "bool":
-			fieldType = "*bool"
-		case "float64":
-			fieldType = "*float64"
-		default:
-			fieldType = "*" + field.Type
+	content.WriteString("}\n\n")
+	if hasDeprecatedFields(fields) {
+		writeInputUpgrade(content, fmt.Sprintf("Update%sInput", entity), withoutComputedFields(fieldsList), aliases, true)
+	}
+}
+
+// writeUpdateInputField writes one Update<Entity>Input field. Fields are
+// pointers so a nil value leaves the entity unchanged.
+func writeUpdateInputField(content *strings.Builder, entity string, field Field, validation bool) {
+	var fieldType string
+	switch field.Type {
+	case "string":
+		fieldType = "*string"
+	case "int":
+		fieldType = "*int"
+	case "bool":
+		fieldType = "*bool"
+	case "float64":
+		fieldType = "*float64"
+	default:
+		fieldType = dtoFieldType(field)
+		if !isPointerType(fieldType) {
+			fieldType = "*" + fieldType
 		}
+	}
 
-		jsonTag := fmt.Sprintf("json:\"%s,omitempty\"", strings.ToLower(field.Name))
+	jsonTag := fmt.Sprintf("json:\"%s,omitempty\"", dtoJSONName(field))
+	if isFileField(field) {
+		jsonTag = `json:"-"`
+	}
+	if field.Deprecated {
+		writeDeprecatedComment(content, field, nil)
+		jsonTag += deprecatedDTOTag(field, nil)
+	}
 
-		if validation {
-			validateTag := dtoUpdateValidationTag(field)
-			fmt.Fprintf(content, "\t%s %s `%s validate:\"%s\"`\n",
-				field.Name, fieldType, jsonTag, validateTag)
-		} else {
-			fmt.Fprintf(content, "\t%s %s `%s`\n",
-				field.Name, fieldType, jsonTag)
+	if validation {
+		validateTag := dtoUpdateValidationTag(entity, field)
+		fmt.Fprintf(content, "\t%s %s `%s validate:\"%s\"`\n",
+			field.Name, fieldType, jsonTag, validateTag)
+	} else {
+		fmt.Fprintf(content, "\t%s %s `%s`\n",
+			field.Name, fieldType, jsonTag)
+	}
+}
+
+// dtoFields returns the fields a DTO declares. With the flat style each nested
+// struct field is replaced by one <Field><Sub> field per nested field.
+func dtoFields(fields []Field, dtoStyle string) []Field {
+	if dtoStyle != DTOStyleFlat {
+		return fields
+	}
+
+	convention := projectNaming().JSON
+	var result []Field
+	for _, field := range fields {
+		if !flattenedField(field, dtoStyle) {
+			result = append(result, field)
+			continue
+		}
+		for _, sub := range field.Nested {
+			tag := rebuildFieldTag(sub.Tag, fieldJSONName(field.Name+sub.Name, convention))
+			result = append(result, Field{Name: field.Name + sub.Name, Type: sub.Type, Tag: tag})
 		}
 	}
+	return result
+}
 
-	content.WriteString("}\n\n")
+// dtoJSONName returns the json name of a DTO field: the one of the entity
+// field, so requests and responses use the names of the entity.
+func dtoJSONName(field Field) string {
+	if name := jsonTagName(field.Tag); name != "" && name != "-" {
+		return name
+	}
+	return fieldJSONName(field.Name, JSONNamingSnake)
+}
+
+// flattenedField reports whether a DTO of dtoStyle declares one <Field><Sub>
+// field per nested field of field. Files always travel as a domain.File.
+func flattenedField(field Field, dtoStyle string) bool {
+	return field.Nested != nil && dtoStyle == DTOStyleFlat && !isFileField(field)
+}
+
+// dtoFieldType returns the Go type of a DTO field. Nested struct types and
+// value objects live in the domain package.
+func dtoFieldType(field Field) string {
+	if field.Nested != nil || isValueObjectType(field.Type) {
+		return "domain." + field.Type
+	}
+	return field.Type
+}
+
+// isValidDTOStyle reports whether style is one of ValidDTOStyles.
+func isValidDTOStyle(style string) bool {
+	for _, s := range ValidDTOStyles {
+		if s == style {
+			return true
+		}
+	}
+	return false
 }
 
 func getValidationTag(fieldType string) string {
@@ -784,6 +1196,8 @@ func getValidationTag(fieldType string) string {
 		return ""
 	case "time.Time":
 		return "required"
+	case FieldJSONType:
+		return ""
 	default:
 		return "required"
 	}
@@ -792,8 +1206,15 @@ func getValidationTag(fieldType string) string {
 // dtoValidationTag returns the `validate` struct tag for a Create DTO field. It
 // is name-aware so email fields are validated for format at the HTTP layer
 // (returning 422), instead of only being caught by the domain Validate()
-// (which would surface as a 500).
-func dtoValidationTag(field Field) string {
+// (which would surface as a 500). Enum fields are checked by the validation
+// the HTTP layer registers for them.
+func dtoValidationTag(entity string, field Field) string {
+	if field.Nested != nil {
+		return "" // the nested struct carries its own validate tags
+	}
+	if len(field.Enum) > 0 {
+		return "required," + enumValidationTag(entity, field.Name)
+	}
 	if field.Type == "string" && strings.Contains(strings.ToLower(field.Name), "email") {
 		return "required,email"
 	}
@@ -802,8 +1223,8 @@ func dtoValidationTag(field Field) string {
 
 // dtoUpdateValidationTag returns the `validate` tag for an optional Update DTO
 // field (pointer), keeping format checks but not requiring presence.
-func dtoUpdateValidationTag(field Field) string {
-	base := dtoValidationTag(field)
+func dtoUpdateValidationTag(entity string, field Field) string {
+	base := dtoValidationTag(entity, field)
 	base = strings.TrimPrefix(base, "required,")
 	if base == "required" {
 		base = ""
@@ -814,6 +1235,12 @@ func dtoUpdateValidationTag(field Field) string {
 	return "omitempty," + base
 }
 
+// enumValidationTag returns the name of the custom validation that accepts the
+// values of an enum field, e.g. order_status.
+func enumValidationTag(entity, field string) string {
+	return toSnakeCase(entity) + "_" + gormColumnName(field)
+}
+
 // ensureImportInDTOFile ensures a specific import exists in the DTO file content.
 func ensureImportInDTOFile(content, importPkg, moduleName string) string {
 	// Check if import already exists
@@ -849,9 +1276,10 @@ func ensureImportInDTOFile(content, importPkg, moduleName string) string {
 
 func init() {
 	usecaseCmd.Flags().StringP("entity", "e", "", "Associated entity for the use case (required)")
-	usecaseCmd.Flags().StringP("operations", "o", "create,read,update,delete,list", "CRUD operations \"create,read,update,delete,list\"")
+	usecaseCmd.Flags().StringP("operations", "o", "create,read,update,delete,list", "CRUD operations \"create,read,update,delete,list\" (add count for Count<Entity>s, search for Search<Entity>s)")
 	usecaseCmd.Flags().BoolP("dto-validation", "d", false, "DTOs with specific validations")
 	usecaseCmd.Flags().BoolP("async", "a", false, "Include asynchronous operations")
+	usecaseCmd.Flags().String("dto-style", "", "How struct{...} fields appear in DTOs (nested, flat); defaults to generation.dto_style")
 	usecaseCmd.Flags().Bool("dry-run", false, "Preview changes without creating files")
 	usecaseCmd.Flags().Bool("force", false, "Overwrite existing files without asking")
 	usecaseCmd.Flags().Bool("backup", false, "Backup existing files before overwriting")
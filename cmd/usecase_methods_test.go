@@ -12,7 +12,7 @@ import (
 func TestGenerateCreateMethod_Pure(t *testing.T) {
 	t.Parallel()
 	var sb strings.Builder
-	generateCreateMethod(&sb, "productService", "Product")
+	generateCreateMethod(&sb, "productService", "Product", false, false)
 	result := sb.String()
 	assert.Contains(t, result, "func (p *productService) CreateProduct")
 	assert.Contains(t, result, "CreateProductInput")
@@ -25,7 +25,7 @@ func TestGenerateCreateMethod_Pure(t *testing.T) {
 func TestGenerateGetMethod_Pure(t *testing.T) {
 	t.Parallel()
 	var sb strings.Builder
-	generateGetMethod(&sb, "productService", "Product")
+	generateGetMethod(&sb, "productService", "Product", false)
 	result := sb.String()
 	assert.Contains(t, result, "func")
 	assert.Contains(t, result, "productService")
@@ -35,7 +35,7 @@ func TestGenerateGetMethod_Pure(t *testing.T) {
 func TestGenerateDeleteMethod_Pure(t *testing.T) {
 	t.Parallel()
 	var sb strings.Builder
-	generateDeleteMethod(&sb, "productService", "Product")
+	generateDeleteMethod(&sb, "productService", "Product", false)
 	result := sb.String()
 	assert.Contains(t, result, "func (p *productService) DeleteProduct")
 	assert.Contains(t, result, "repo.Delete")
@@ -44,7 +44,7 @@ func TestGenerateDeleteMethod_Pure(t *testing.T) {
 func TestGenerateListMethod_Pure(t *testing.T) {
 	t.Parallel()
 	var sb strings.Builder
-	generateListMethod(&sb, "productService", "Product")
+	generateListMethod(&sb, "productService", "Product", false)
 	result := sb.String()
 	assert.Contains(t, result, "func")
 	assert.Contains(t, result, "productService")
@@ -54,7 +54,7 @@ func TestGenerateListMethod_Pure(t *testing.T) {
 func TestGenerateUpdateMethodWithFields_Pure(t *testing.T) {
 	t.Parallel()
 	var sb strings.Builder
-	generateUpdateMethodWithFields(&sb, "productService", "Product", "Name:string,Price:float64")
+	generateUpdateMethodWithFields(&sb, "productService", "Product", "Name:string,Price:float64", DTOStyleNested, false)
 	result := sb.String()
 	assert.Contains(t, result, "func (p *productService) UpdateProduct")
 	assert.Contains(t, result, "Name")
@@ -64,7 +64,7 @@ func TestGenerateUpdateMethodWithFields_Pure(t *testing.T) {
 func TestGenerateCreateMethodWithFields_Pure(t *testing.T) {
 	t.Parallel()
 	var sb strings.Builder
-	generateCreateMethodWithFields(&sb, "productService", "Product", "Name:string,Price:float64", true)
+	generateCreateMethodWithFields(&sb, "productService", "Product", "Name:string,Price:float64", DTOStyleNested, true, false, false)
 	result := sb.String()
 	assert.Contains(t, result, "func (p *productService) CreateProduct")
 	assert.Contains(t, result, "Name")
@@ -75,7 +75,7 @@ func TestGenerateCreateMethodWithFields_Pure(t *testing.T) {
 func TestGenerateCreateMethodWithFields_NoDTOValidate(t *testing.T) {
 	t.Parallel()
 	var sb strings.Builder
-	generateCreateMethodWithFields(&sb, "productService", "Product", "Name:string,Price:float64", false)
+	generateCreateMethodWithFields(&sb, "productService", "Product", "Name:string,Price:float64", DTOStyleNested, false, false, false)
 	result := sb.String()
 	assert.Contains(t, result, "func (p *productService) CreateProduct")
 	assert.NotContains(t, result, "input.Validate()")
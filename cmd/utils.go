@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
 	"go/ast"
 	"go/format"
@@ -10,17 +9,28 @@ import (
 	"go/types"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 // readEntityFieldsString reconstructs the "name:type,..." field specification of
-// an already-generated entity by parsing internal/domain/<entity>.go. System
-// fields (ID, CreatedAt, ...) are skipped. It returns an empty string when the
-// file cannot be read or parsed, so callers can fall back to their defaults.
+// an already-generated entity by parsing internal/domain/<entity>.go (or its
+// snake_case/kebab-case name). System fields (ID, CreatedAt, ...) are skipped.
+// It returns an empty string when the file cannot be read or parsed, so callers
+// can fall back to their defaults.
 func readEntityFieldsString(entity string) string {
-	filename := filepath.Join("internal", "domain", strings.ToLower(entity)+".go")
-	src, err := os.ReadFile(filename)
-	if err != nil {
+	var filename string
+	var src []byte
+	for _, name := range []string{strings.ToLower(entity), toSnakeCase(entity), toKebabCase(entity)} {
+		filename = filepath.Join("internal", "domain", name+".go")
+		var err error
+		if src, err = os.ReadFile(filename); err == nil {
+			break
+		}
+	}
+	if src == nil {
 		return ""
 	}
 
@@ -30,6 +40,19 @@ func readEntityFieldsString(entity string) string {
 		return ""
 	}
 
+	// Struct types declared next to the entity are nested struct{...} fields.
+	nested := make(map[string]*ast.StructType)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if ts, ok := n.(*ast.TypeSpec); ok && ts.Name.Name != entity {
+			if st, ok := ts.Type.(*ast.StructType); ok {
+				nested[ts.Name.Name] = st
+			}
+		}
+		return true
+	})
+
+	enums := entityEnums(file, entity)
+	convention := projectNaming().JSON
 	var parts []string
 	ast.Inspect(file, func(n ast.Node) bool {
 		ts, ok := n.(*ast.TypeSpec)
@@ -45,8 +68,28 @@ func readEntityFieldsString(entity string) string {
 				if isSystemField(nm.Name) {
 					continue
 				}
-				name := strings.ToLower(nm.Name[:1]) + nm.Name[1:]
-				parts = append(parts, name+":"+types.ExprString(f.Type))
+				// snake_case names parse back to the same Go name (ZipCode, UserID).
+				specType := fieldSpecType(f.Type, nested)
+				part := gormColumnName(nm.Name) + ":" + specType
+				var computed string
+				if f.Tag != nil {
+					var defaultSuffix string
+					defaultSuffix, computed = fieldConstraintSpec(nm.Name, specType, f.Tag.Value)
+					part += defaultSuffix
+				}
+				if values := enums[nm.Name]; len(values) > 0 {
+					part += ":" + EnumModifier + strings.Join(values, "|")
+				}
+				if f.Tag != nil {
+					if hidden := fieldHiddenProtocols(Field{Tag: f.Tag.Value}); len(hidden) > 0 {
+						part += ":" + HideModifier + strings.Join(hidden, "|")
+					}
+					// Modifiers do not follow struct{...} types.
+					if !isStructFieldType(specType) {
+						part += jsonModifier(nm.Name, f.Tag.Value, convention)
+					}
+				}
+				parts = append(parts, part+computed)
 			}
 		}
 		return false
@@ -54,39 +97,106 @@ func readEntityFieldsString(entity string) string {
 	return strings.Join(parts, ",")
 }
 
-// getModuleName reads the module name from go.mod file.
-func getModuleName() string {
-	goMod, err := os.Open("go.mod")
-	if err != nil {
-		return "myproject" // fallback
-	}
-	defer func() {
-		if err := goMod.Close(); err != nil {
-			if ui != nil {
-				ui.Error(fmt.Sprintf("Error closing go.mod file: %v", err))
-			} else {
-				fmt.Printf("Error closing go.mod file: %v\n", err)
+// entityEnums returns the values of the entity's enum fields by field name,
+// read from the <Entity><Field>Values declarations of writeEnumValues.
+func entityEnums(file *ast.File, entity string) map[string][]string {
+	enums := make(map[string][]string)
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if len(vs.Names) != 1 || len(vs.Values) != 1 {
+				continue
+			}
+			name := vs.Names[0].Name
+			field := strings.TrimSuffix(strings.TrimPrefix(name, entity), "Values")
+			if !strings.HasPrefix(name, entity) || !strings.HasSuffix(name, "Values") || field == "" {
+				continue
 			}
+			lit, ok := vs.Values[0].(*ast.CompositeLit)
+			if !ok {
+				continue
+			}
+			var values []string
+			for _, elt := range lit.Elts {
+				if bl, ok := elt.(*ast.BasicLit); ok && bl.Kind == token.STRING {
+					if value, err := strconv.Unquote(bl.Value); err == nil {
+						values = append(values, value)
+					}
+				}
+			}
+			enums[field] = values
 		}
-	}()
+	}
+	return enums
+}
+
+// entityEnumValues returns the enum fields of the entity in
+// internal/domain/<entity>.go, or nil when it cannot be read.
+func entityEnumValues(entity string) map[string][]string {
+	file, err := parser.ParseFile(token.NewFileSet(), filepath.Join(DirInternal, DirDomain, strings.ToLower(entity)+".go"), nil, 0)
+	if err != nil {
+		return nil
+	}
+	return entityEnums(file, entity)
+}
 
-	scanner := bufio.NewScanner(goMod)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if strings.HasPrefix(line, "module ") {
-			return strings.TrimSpace(strings.TrimPrefix(line, "module"))
+// fieldSpecType returns the --fields type of a struct field expression, turning
+// datatypes.JSON back into json, File into file and nested struct types into
+// struct{...}.
+func fieldSpecType(expr ast.Expr, nested map[string]*ast.StructType) string {
+	typ := types.ExprString(expr)
+	if typ == FieldJSONType {
+		return FieldJSON
+	}
+	if typ == FieldFileType {
+		return FieldFile
+	}
+	st, ok := nested[typ]
+	if !ok {
+		return typ
+	}
+
+	var subs []string
+	for _, f := range st.Fields.List {
+		for _, nm := range f.Names {
+			subs = append(subs, gormColumnName(nm.Name)+":"+fieldSpecType(f.Type, nil))
 		}
 	}
+	return FieldStructOpen + strings.Join(subs, ",") + "}"
+}
+
+// getModuleName returns the import path of the current directory, resolved
+// from the nearest go.mod. When the project lives in a subdirectory of the
+// module, the subdirectory is part of the returned path.
+func getModuleName() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "myproject" // fallback
+	}
+	if importPath, ok := moduleImportPath(wd); ok {
+		return importPath
+	}
 	return "myproject" // fallback
 }
 
 // writeFile creates a file with the given content, creating directories if needed.
 // An optional SafetyManager can be passed to enable dry-run, force, and backup support.
 func writeFile(path, content string, sm ...*SafetyManager) error {
+	profileFile(len(content))
+	if err := checkGeneratedGo(path, content); err != nil {
+		return err
+	}
 	if len(sm) > 0 && sm[0] != nil {
 		return sm[0].WriteFile(path, content)
 	}
 
+	if err := checkProtectedPath(path); err != nil {
+		return err
+	}
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return fmt.Errorf("error creating directory %s: %w", dir, err)
@@ -111,25 +221,21 @@ func writeFile(path, content string, sm ...*SafetyManager) error {
 		return fmt.Errorf("error writing file %s: %w", path, err)
 	}
 
+	recordGenerated(path, content)
 	return nil
 }
 
-// writeGoFile creates a Go file with auto-formatting.
+// writeGoFile creates a Go file with auto-formatting. Go files that are not
+// valid (see checkGeneratedGo) are not written.
 // An optional SafetyManager can be passed to enable dry-run, force, and backup support.
 func writeGoFile(path, content string, sm ...*SafetyManager) error {
-	// Format Go code if it's a .go file
+	profileFile(len(content))
 	if strings.HasSuffix(path, ".go") {
-		formatted, err := format.Source([]byte(content))
+		emitted, err := emitGoSource(path, content)
 		if err != nil {
-			if ui != nil {
-				ui.Warning(fmt.Sprintf("Could not format Go code for %s: %v", path, err))
-			} else {
-				fmt.Printf("Warning: Could not format Go code for %s: %v\n", path, err)
-			}
-			// Continue with unformatted code
-		} else {
-			content = string(formatted)
+			return err
 		}
+		content = emitted
 	}
 
 	// Route through SafetyManager when available
@@ -137,6 +243,9 @@ func writeGoFile(path, content string, sm ...*SafetyManager) error {
 		return sm[0].WriteFile(path, content)
 	}
 
+	if err := checkProtectedPath(path); err != nil {
+		return err
+	}
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return fmt.Errorf("error creating directory %s: %w", dir, err)
@@ -161,6 +270,7 @@ func writeGoFile(path, content string, sm ...*SafetyManager) error {
 		return fmt.Errorf("error writing file %s: %w", path, err)
 	}
 
+	recordGenerated(path, content)
 	return nil
 }
 
@@ -170,18 +280,22 @@ func writeGoFile(path, content string, sm ...*SafetyManager) error {
 // second feature can extend these shared files. Falls back to a plain overwrite
 // when no SafetyManager is provided.
 func writeGoFileMerged(path, content string, sm ...*SafetyManager) error {
+	profileFile(len(content))
 	if strings.HasSuffix(path, ".go") {
-		if formatted, err := format.Source([]byte(content)); err == nil {
-			content = string(formatted)
-		} else if ui != nil {
-			ui.Warning(fmt.Sprintf("Could not format Go code for %s: %v", path, err))
+		emitted, err := emitGoSource(path, content)
+		if err != nil {
+			return err
 		}
+		content = emitted
 	}
 
 	if len(sm) > 0 && sm[0] != nil {
 		return sm[0].WriteMergedFile(path, content)
 	}
 
+	if err := checkProtectedPath(path); err != nil {
+		return err
+	}
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return fmt.Errorf("error creating directory %s: %w", dir, err)
@@ -190,9 +304,16 @@ func writeGoFileMerged(path, content string, sm ...*SafetyManager) error {
 	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
 		return fmt.Errorf("error writing file %s: %w", path, err)
 	}
+	recordGenerated(path, content)
 	return nil
 }
 
+// formatGoSource gofmts generated Go source.
+func formatGoSource(content string) ([]byte, error) {
+	defer profileStep("gofmt")()
+	return format.Source([]byte(content))
+}
+
 // fixGeneratedModulePath rewrites occurrences of Goca's own module path that
 // leaked into generator templates so generated code imports the user's project
 // packages instead of github.com/sazardev/goca/... (which are internal and
@@ -201,27 +322,31 @@ func fixGeneratedModulePath(content, importPath string) string {
 	return strings.ReplaceAll(content, "github.com/sazardev/goca/internal", importPath+"/internal")
 }
 
-// getImportPath determines whether to use the full module path or relative path for imports.
+// getImportPath returns the import prefix for the project's packages. The
+// module name from getModuleName is already the full package path (vanity
+// domains, major version suffixes and module subdirectories included), so it is
+// used as-is.
 func getImportPath(moduleName string) string {
-	// Check if we're in a test environment with a GitHub-style fake module
-	if strings.Contains(moduleName, "github.com/goca/testproject") {
-		// For test environments, use the module name as-is since it has valid structure
-		return moduleName
-	}
-
-	// For all projects (both local and remote), use the module name as defined in go.mod
-	// Go modules handle internal imports automatically based on the module declaration
 	return moduleName
 }
 
 // generateSearchMethods generates search methods based on entity fields.
+// Finders declared with --fields modifiers replace the ones derived from the
+// field names.
 func generateSearchMethods(fields []Field, entity string) []SearchMethod {
+	if hasDeclaredFinders(fields) {
+		return declaredSearchMethods(fields, fields, entity)
+	}
+
 	var methods []SearchMethod
 
 	for _, field := range fields {
 		if field.Name == "ID" {
 			continue // ID already has FindByID by default
 		}
+		if field.Nested != nil {
+			continue // embedded structs are matched column by column, not as a value
+		}
 
 		// Generate methods for fields commonly used for searches
 		if isSearchableField(field.Name, field.Type) {
@@ -239,30 +364,175 @@ func generateSearchMethods(fields []Field, entity string) []SearchMethod {
 	return methods
 }
 
-// isSearchableField determines if a field should have a search method.
-func isSearchableField(fieldName, fieldType string) bool {
-	// Types that are not suitable for searches
-	if fieldType == "[]byte" || fieldType == "interface{}" {
-		return false
+// generateRepositoryMethods returns the finders, the aggregates and, when the
+// entity has filterable fields, the criteria Search of the entity's repository.
+func generateRepositoryMethods(fields []Field, entity string) []SearchMethod {
+	methods := append(generateSearchMethods(fields, entity), generateAggregateMethods(fields, entity)...)
+	if len(criteriaFields(fields)) > 0 {
+		methods = append(methods, newCriteriaSearchMethod(entity))
 	}
+	return methods
+}
 
-	fieldLower := strings.ToLower(fieldName)
+// generateAggregateMethods returns SumBy<Field> and MaxBy<Field> for every
+// numeric field other than the ID and foreign keys.
+func generateAggregateMethods(fields []Field, entity string) []SearchMethod {
+	var methods []SearchMethod
+	for _, field := range fields {
+		if field.Name == "ID" || strings.HasSuffix(field.Name, "ID") || !isNumericType(field.Type) {
+			continue
+		}
+		for _, kind := range []string{AggregateSum, AggregateMax} {
+			methods = append(methods, newAggregateMethod(kind, field, entity))
+		}
+	}
+	return methods
+}
 
-	// Common fields typically used for searches
-	searchableFields := []string{
-		"email", "username", "name", "code",
-		"sku", "slug", "phone", "document", "dni",
-		"passport", "license", "title",
+// newAggregateMethod returns the aggregate of kind over field.
+func newAggregateMethod(kind string, field Field, entity string) SearchMethod {
+	prefix := "SumBy"
+	if kind == AggregateMax {
+		prefix = "MaxBy"
+	}
+	return SearchMethod{
+		MethodName: prefix + field.Name,
+		FieldName:  field.Name,
+		FieldType:  field.Type,
+		ReturnType: "(float64, error)",
+		Kind:       kind,
+		Spec:       entity + "Spec",
 	}
+}
 
-	for _, searchable := range searchableFields {
-		if strings.Contains(fieldLower, searchable) {
+// hasDeclaredFinders reports whether any field declares a finder.
+func hasDeclaredFinders(fields []Field) bool {
+	for _, field := range fields {
+		if len(field.Finders) > 0 {
 			return true
 		}
 	}
+	return false
+}
+
+// declaredSearchMethods returns the finders declared on fields. The other
+// columns of multi-column finders are looked up in known; finders naming an
+// unknown field are skipped with a warning.
+func declaredSearchMethods(fields, known []Field, entity string) []SearchMethod {
+	byName := make(map[string]Field)
+	for _, field := range known {
+		byName[field.Name] = field
+	}
+
+	var methods []SearchMethod
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		for _, finder := range field.Finders {
+			columns := []Field{{Name: field.Name, Type: field.Type}}
+			for _, name := range finder.With {
+				other, ok := byName[name]
+				if !ok || !isFinderFieldType(other.Type) {
+					ui.Warning(fmt.Sprintf("Skipping finder on %s: %s has no field %s usable in a finder", field.Name, entity, name))
+					columns = nil
+					break
+				}
+				columns = append(columns, Field{Name: other.Name, Type: other.Type})
+			}
+			if columns == nil {
+				continue
+			}
+			method := newSearchMethod(finder.Kind, columns, entity)
+			if !seen[method.MethodName] {
+				seen[method.MethodName] = true
+				methods = append(methods, method)
+			}
+		}
+	}
+	return methods
+}
+
+// newSearchMethod returns the finder of kind matching columns.
+func newSearchMethod(kind string, columns []Field, entity string) SearchMethod {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+	}
+	method := SearchMethod{
+		FieldName: columns[0].Name,
+		FieldType: columns[0].Type,
+		Kind:      kind,
+		Columns:   columns,
+	}
+	by := strings.Join(names, "And")
+	switch kind {
+	case FinderAll:
+		method.MethodName = "FindAllBy" + by
+		method.ReturnType = fmt.Sprintf("([]domain.%s, error)", entity)
+	case FinderExists:
+		method.MethodName = "ExistsBy" + by
+		method.ReturnType = "(bool, error)"
+	default:
+		method.MethodName = "FindBy" + by
+		method.ReturnType = fmt.Sprintf("(*domain.%s, error)", entity)
+		method.IsUnique = true
+	}
+	return method
+}
 
-	// Only string, int and uint fields are good for searches
-	return fieldType == "string" || fieldType == "int" || fieldType == "uint"
+// finderMethodPattern matches the names of generated finders and aggregates.
+var finderMethodPattern = regexp.MustCompile(`^(FindBy|FindAllBy|ExistsBy|SumBy|MaxBy)([A-Z]\w*)$`)
+
+// finderMethodColumns returns the fields a finder method name matches, or nil
+// when name is not a finder. FindByID and ExistsByID are not finders.
+func finderMethodColumns(name string) []string {
+	m := finderMethodPattern.FindStringSubmatch(name)
+	if m == nil || name == "FindByID" || name == "ExistsByID" {
+		return nil
+	}
+	return strings.Split(m[2], "And")
+}
+
+// isFinderFieldType reports whether a field of fieldType can be matched by a
+// finder: comparable scalar values, not JSON, pointers, slices or maps.
+func isFinderFieldType(fieldType string) bool {
+	if fieldType == "" || fieldType == FieldJSONType || fieldType == "interface{}" || fieldType == "any" ||
+		isStructFieldType(fieldType) || isJSONFieldType(fieldType) || isFileFieldType(fieldType) {
+		return false
+	}
+	return !strings.HasPrefix(fieldType, "*") && !strings.HasPrefix(fieldType, "[]") && !strings.HasPrefix(fieldType, "map[")
+}
+
+// isSearchableField reports whether a field without declared finders gets a
+// FindBy<Field>: only the string natural keys email, username, code and slug
+// do. Finders on other fields are declared with the findby modifier or
+// --finders.
+func isSearchableField(fieldName, fieldType string) bool {
+	if fieldType != "string" {
+		return false
+	}
+	switch strings.ToLower(fieldName) {
+	case "email", "username", "code", "slug":
+		return true
+	}
+	return false
+}
+
+// gormColumnName returns the column GORM's default naming strategy derives
+// from a Go field name, keeping initialisms together: UserID -> user_id.
+func gormColumnName(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
 }
 
 // isUniqueField determines if a field should likely be unique.
@@ -289,30 +559,103 @@ type SearchMethod struct {
 	FieldType  string // string, int, etc.
 	ReturnType string // (*domain.User, error)
 	IsUnique   bool   // true if it should return a single result
+	// Kind is FinderOne (also when empty), FinderAll, FinderExists or, for
+	// aggregates over FieldName, AggregateSum or AggregateMax.
+	Kind string
+	// Columns are the fields the finder matches; empty means FieldName.
+	Columns []Field
+	// Spec is the <Entity>Spec type aggregates filter by, or the
+	// <Entity>Criteria of Search.
+	Spec string
+}
+
+// isAggregate reports whether the method aggregates a field rather than
+// finding entities.
+func (sm SearchMethod) isAggregate() bool {
+	return sm.Kind == AggregateSum || sm.Kind == AggregateMax
+}
+
+// columns returns the fields the finder matches, in parameter order.
+func (sm SearchMethod) columns() []Field {
+	if len(sm.Columns) > 0 {
+		return sm.Columns
+	}
+	return []Field{{Name: sm.FieldName, Type: sm.FieldType}}
+}
+
+// params returns the parameter list of the finder, e.g. "email string".
+func (sm SearchMethod) params() string {
+	switch {
+	case sm.isAggregate():
+		return "ctx context.Context, spec " + sm.Spec
+	case sm.Kind == SearchCriteria:
+		return "ctx context.Context, criteria " + sm.Spec
+	}
+	var params []string
+	for _, column := range sm.columns() {
+		params = append(params, strings.ToLower(column.Name)+" "+column.Type)
+	}
+	return strings.Join(params, ", ")
+}
+
+// args returns the parameter names of the finder, comma separated.
+func (sm SearchMethod) args() string {
+	switch {
+	case sm.isAggregate():
+		return "ctx, spec"
+	case sm.Kind == SearchCriteria:
+		return "ctx, criteria"
+	}
+	var args []string
+	for _, column := range sm.columns() {
+		args = append(args, strings.ToLower(column.Name))
+	}
+	return strings.Join(args, ", ")
 }
 
 // generateSearchMethodSignature generates the search method signature.
 func (sm SearchMethod) generateSearchMethodSignature() string {
-	paramName := strings.ToLower(sm.FieldName)
-	return fmt.Sprintf("\t%s(%s %s) %s", sm.MethodName, paramName, sm.FieldType, sm.ReturnType)
+	return fmt.Sprintf("\t%s(%s) %s", sm.MethodName, sm.params(), sm.ReturnType)
 }
 
-// generateSearchMethodImplementation generates the search method implementation.
+// generateSearchMethodImplementation generates the GORM implementation of the
+// search method.
 func (sm SearchMethod) generateSearchMethodImplementation(receiverName, receiverType, entity string) string {
-	paramName := strings.ToLower(sm.FieldName)
+	switch {
+	case sm.isAggregate():
+		return sm.generateAggregateImplementation(receiverName, receiverType, entity)
+	case sm.Kind == SearchCriteria:
+		return generateGormCriteriaSearch(receiverName, receiverType, entity)
+	}
 	entityVar := strings.ToLower(entity)
 
+	var conditions []string
+	for _, column := range sm.columns() {
+		conditions = append(conditions, gormColumnName(column.Name)+" = ?")
+	}
+	where := fmt.Sprintf("%s.db.Where(%q, %s)", receiverName, strings.Join(conditions, " AND "), sm.args())
+
 	var implementation strings.Builder
-	implementation.WriteString(fmt.Sprintf("func (%s *%s) %s(%s %s) %s {\n",
-		receiverName, receiverType, sm.MethodName, paramName, sm.FieldType, sm.ReturnType))
-
-	implementation.WriteString(fmt.Sprintf("\t%s := &domain.%s{}\n", entityVar, entity))
-	implementation.WriteString(fmt.Sprintf("\tresult := %s.db.Where(\"%s = ?\", %s).First(%s)\n",
-		receiverName, strings.ToLower(sm.FieldName), paramName, entityVar))
-	implementation.WriteString("\tif result.Error != nil {\n")
-	implementation.WriteString("\t\treturn nil, result.Error\n")
-	implementation.WriteString("\t}\n")
-	implementation.WriteString(fmt.Sprintf("\treturn %s, nil\n", entityVar))
+	implementation.WriteString(fmt.Sprintf("func (%s *%s) %s(%s) %s {\n",
+		receiverName, receiverType, sm.MethodName, sm.params(), sm.ReturnType))
+
+	switch sm.Kind {
+	case FinderAll:
+		implementation.WriteString(fmt.Sprintf("\tvar %s []domain.%s\n", toPlural(entityVar), entity))
+		implementation.WriteString(fmt.Sprintf("\tresult := %s.Find(&%s)\n", where, toPlural(entityVar)))
+		implementation.WriteString(fmt.Sprintf("\treturn %s, result.Error\n", toPlural(entityVar)))
+	case FinderExists:
+		implementation.WriteString("\tvar count int64\n")
+		implementation.WriteString(fmt.Sprintf("\tresult := %s.Model(&domain.%s{}).Count(&count)\n", where, entity))
+		implementation.WriteString("\treturn count > 0, result.Error\n")
+	default:
+		implementation.WriteString(fmt.Sprintf("\t%s := &domain.%s{}\n", entityVar, entity))
+		implementation.WriteString(fmt.Sprintf("\tresult := %s.First(%s)\n", where, entityVar))
+		implementation.WriteString("\tif result.Error != nil {\n")
+		implementation.WriteString(fmt.Sprintf("\t\treturn nil, translateGormError(%s.db, result.Error)\n", receiverName))
+		implementation.WriteString("\t}\n")
+		implementation.WriteString(fmt.Sprintf("\treturn %s, nil\n", entityVar))
+	}
 	implementation.WriteString("}\n\n")
 
 	return implementation.String()
@@ -40,17 +40,15 @@ func TestIsSearchableField(t *testing.T) {
 	}{
 		{name: "email string", fieldName: "Email", fieldType: "string", expected: true},
 		{name: "username string", fieldName: "Username", fieldType: "string", expected: true},
-		{name: "name string", fieldName: "Name", fieldType: "string", expected: true},
 		{name: "code string", fieldName: "Code", fieldType: "string", expected: true},
-		{name: "sku string", fieldName: "SKU", fieldType: "string", expected: true},
 		{name: "slug string", fieldName: "Slug", fieldType: "string", expected: true},
-		{name: "phone string", fieldName: "Phone", fieldType: "string", expected: true},
-		{name: "title string", fieldName: "Title", fieldType: "string", expected: true},
-		{name: "age int", fieldName: "Age", fieldType: "int", expected: true},
-		{name: "count uint", fieldName: "Count", fieldType: "uint", expected: true},
+		{name: "name string", fieldName: "Name", fieldType: "string", expected: false},
+		{name: "sku string", fieldName: "SKU", fieldType: "string", expected: false},
+		{name: "title string", fieldName: "Title", fieldType: "string", expected: false},
+		{name: "stock int", fieldName: "Stock", fieldType: "int", expected: false},
+		{name: "code int", fieldName: "Code", fieldType: "int", expected: false},
 		{name: "data bytes", fieldName: "Data", fieldType: "[]byte", expected: false},
 		{name: "any interface", fieldName: "Meta", fieldType: "interface{}", expected: false},
-		{name: "price string", fieldName: "Price", fieldType: "string", expected: true},
 		{name: "active bool", fieldName: "Active", fieldType: "bool", expected: false},
 		{name: "random float", fieldName: "Random", fieldType: "float64", expected: false},
 	}
@@ -121,19 +119,11 @@ func TestGenerateSearchMethods(t *testing.T) {
 	assert.True(t, emailMethod.IsUnique)
 	assert.Equal(t, "string", emailMethod.FieldType)
 
-	// Name should be searchable but not unique
-	var nameMethod *SearchMethod
-	for i, m := range methods {
-		if m.MethodName == "FindByName" {
-			nameMethod = &methods[i]
-			break
-		}
-	}
-	require.NotNil(t, nameMethod, "FindByName should be generated")
-	assert.False(t, nameMethod.IsUnique)
+	// Fields that are not natural keys get no finder unless declared
+	require.Len(t, methods, 1)
 
-	// Data ([]byte) should NOT have a search method
-	for _, m := range methods {
-		assert.NotEqual(t, "FindByData", m.MethodName)
-	}
+	declared := parseFields("name:string:findby,age:int,email:string")
+	methods = generateSearchMethods(declared, "User")
+	require.Len(t, methods, 1)
+	assert.Equal(t, "FindByName", methods[0].MethodName)
 }
@@ -0,0 +1,419 @@
+package cmd
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// valueObjectFieldTypes are the field types of value objects: comparable
+// scalars, so value objects compare with == and serialize as flat JSON.
+var valueObjectFieldTypes = []string{
+	FieldString, FieldInt, FieldInt32, FieldInt64, FieldUint, FieldUint32, FieldUint64,
+	FieldFloat32, FieldFloat64, FieldBool, FieldTime,
+}
+
+var valueObjectCmd = &cobra.Command{
+	Use:     "valueobject <name>",
+	Aliases: []string{"vo"},
+	Short:   "Generate an immutable value object for the domain",
+	Long: `Generates internal/domain/<name>.go, an immutable value object: a type
+compared by value whose fields are only set, and validated, by its
+constructor.
+
+  - New<Name>(fields...)      Builds a valid value, or returns the error of
+                              the first invalid field
+  - <Field>(), With<Field>()  Read a field, or copy the value with another one
+  - Equal, IsZero, String     Compare and print values
+  - Validate                  The invariants of the value; add your own rules
+  - MarshalJSON/UnmarshalJSON A JSON object of the fields; decoding validates
+  - Value/Scan                One JSON column (jsonb on PostgreSQL)
+
+Entities reference value objects as field types, e.g. price:Money: the
+entity's Validate checks them, the DTOs carry them as domain.<Name>, and
+requests with invalid values are rejected when decoded.
+
+Examples:
+  goca valueobject Money --fields "amount:int64,currency:string"
+  goca feature Product --fields "name:string,price:Money"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		name := args[0]
+		fields, _ := cmd.Flags().GetString("fields")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if err := NewFieldValidator().ValidateEntityName(name); err != nil {
+			return err
+		}
+		voFields, err := parseValueObjectFields(name, fields)
+		if err != nil {
+			return err
+		}
+		if file := findTypeFile(filepath.Join(DirInternal, DirDomain), name); file != "" && !isValueObjectType(name) {
+			return fmt.Errorf("%s is already declared in %s and is not a value object", name, file)
+		}
+		ci := NewConfigIntegration()
+		_ = ci.LoadConfigForProject()
+
+		ui.Header(fmt.Sprintf("Generating value object '%s'", name))
+		ui.KeyValue("Fields", fields)
+		sm := NewSafetyManager(dryRun, force, false)
+		if err := generateValueObject(name, voFields, ci.GetNamingConvention("file"), sm); err != nil {
+			return err
+		}
+		if dryRun {
+			sm.PrintSummary()
+			return nil
+		}
+
+		ui.Blank()
+		ui.Success(fmt.Sprintf("Value object %s generated", name))
+		ui.NextSteps([]string{
+			fmt.Sprintf("Add the invariants of %s to its Validate method", name),
+			fmt.Sprintf("Use it as a field type: goca feature Product --fields \"name:string,%s:%s\"", fieldJSONName(name, JSONNamingSnake), name),
+		})
+		return nil
+	},
+}
+
+// valueObjectField is a field of a value object: Name is its Go accessor,
+// Var the unexported struct field and JSON its json name.
+type valueObjectField struct {
+	Name string
+	Var  string
+	JSON string
+	Type string
+}
+
+// parseValueObjectFields validates the --fields of a value object: scalar
+// types only, without modifiers other than json=.
+func parseValueObjectFields(name, fields string) ([]valueObjectField, error) {
+	if strings.TrimSpace(fields) == "" {
+		return nil, fmt.Errorf("value object %s needs --fields, e.g. \"amount:int64,currency:string\"", name)
+	}
+	validator := NewFieldValidator()
+	if err := validator.ValidateFields(fields); err != nil {
+		return nil, err
+	}
+	convention := projectNaming().JSON
+	var result []valueObjectField
+	for _, part := range validator.smartSplitFields(fields) {
+		field, err := validator.ValidateField(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		if !contains(valueObjectFieldTypes, field.Type) {
+			return nil, fmt.Errorf("field %s of value object %s has type %s; use one of %s", field.Name, name, field.Type, strings.Join(valueObjectFieldTypes, ", "))
+		}
+		if len(field.Finders) > 0 || len(field.Enum) > 0 || len(field.Hidden) > 0 || field.Default != "" || field.Computed || field.Sensitive || field.Deprecated {
+			return nil, fmt.Errorf("field %s of value object %s takes no modifier but json=", field.Name, name)
+		}
+		jsonName := field.JSONName
+		if jsonName == "" {
+			jsonName = fieldJSONName(field.Name, convention)
+		}
+		result = append(result, valueObjectField{
+			Name: toGoFieldName(field.Name),
+			Var:  fieldJSONName(field.Name, JSONNamingCamel),
+			JSON: jsonName,
+			Type: field.Type,
+		})
+	}
+	return result, nil
+}
+
+// valueObjectDoc starts the doc comment of a value object, which marks its
+// type as one for the generators of the entities referencing it.
+func valueObjectDoc(name string) string {
+	return "// " + name + " is a value object"
+}
+
+// isValueObjectType reports whether fieldType names a value object of
+// internal/domain generated by goca valueobject.
+func isValueObjectType(fieldType string) bool {
+	if fieldType == "" || strings.ContainsAny(fieldType, ".[]*{}() ") {
+		return false
+	}
+	file := findTypeFile(filepath.Join(DirInternal, DirDomain), fieldType)
+	if file == "" {
+		return false
+	}
+	content, err := os.ReadFile(file)
+	return err == nil && strings.Contains(string(content), valueObjectDoc(fieldType))
+}
+
+// valueObjectLiteral returns a literal of a valid value object of type
+// fieldType for the generated entity tests. They are in package domain, so
+// the literal sets the unexported fields directly.
+func valueObjectLiteral(fieldType string) string {
+	file := findTypeFile(filepath.Join(DirInternal, DirDomain), fieldType)
+	parsed, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return fieldType + "{}"
+	}
+	st := findStructType(parsed, fieldType)
+	if st == nil {
+		return fieldType + "{}"
+	}
+	var parts []string
+	for _, field := range structFields(parsed, st) {
+		parts = append(parts, field.Name+": "+getValidFieldValue(field))
+	}
+	return fmt.Sprintf("%s{%s}", fieldType, strings.Join(parts, ", "))
+}
+
+// generateValueObject writes the value object name to internal/domain.
+func generateValueObject(name string, fields []valueObjectField, fileNamingConvention string, sm ...*SafetyManager) error {
+	dir := filepath.Join(DirInternal, DirDomain)
+	var filename string
+	switch fileNamingConvention {
+	case "snake_case":
+		filename = filepath.Join(dir, toSnakeCase(name)+".go")
+	case "kebab-case":
+		filename = filepath.Join(dir, toKebabCase(name)+".go")
+	default:
+		filename = filepath.Join(dir, strings.ToLower(name)+".go")
+	}
+	return writeGoFile(filename, buildValueObject(name, fields, isGormDatabase(projectDatabase())), sm...)
+}
+
+// buildValueObject returns the source of the value object name. With gorm,
+// it also declares the column type of its JSON for each dialect.
+func buildValueObject(name string, fields []valueObjectField, gorm bool) string {
+	recv := strings.ToLower(name[:1])
+	if recv == "v" || recv == "d" {
+		// v and d name the locals of the JSON and Scan methods.
+		recv = "x"
+	}
+	jsonType := strings.ToLower(name[:1]) + name[1:] + "JSON"
+	errPrefix := strings.ToLower(getFieldDisplayName(toSnakeCase(name)))
+
+	var checks []valueObjectField
+	usesStrings, usesTime := false, false
+	for _, f := range fields {
+		switch f.Type {
+		case FieldString:
+			checks, usesStrings = append(checks, f), true
+		case FieldTime:
+			checks, usesTime = append(checks, f), true
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("package domain\n\n")
+	b.WriteString("import (\n")
+	b.WriteString("\t\"database/sql/driver\"\n")
+	b.WriteString("\t\"encoding/json\"\n")
+	if len(checks) > 0 {
+		b.WriteString("\t\"errors\"\n")
+	}
+	b.WriteString("\t\"fmt\"\n")
+	if usesStrings {
+		b.WriteString("\t\"strings\"\n")
+	}
+	if usesTime {
+		b.WriteString("\t\"time\"\n")
+	}
+	if gorm {
+		b.WriteString("\n\t\"gorm.io/gorm\"\n")
+		b.WriteString("\t\"gorm.io/gorm/schema\"\n")
+	}
+	b.WriteString(")\n\n")
+
+	if len(checks) > 0 {
+		fmt.Fprintf(&b, "// Errors of invalid %s values.\n", name)
+		b.WriteString("var (\n")
+		for _, f := range checks {
+			fmt.Fprintf(&b, "\tErrInvalid%s%s = errors.New(%q)\n", name, f.Name, "invalid "+strings.ToLower(getFieldDisplayName(toSnakeCase(name)))+" "+f.JSON)
+		}
+		b.WriteString(")\n\n")
+	}
+
+	fmt.Fprintf(&b, "%s: its fields are only set by New%s, which\n", valueObjectDoc(name), name)
+	fmt.Fprintf(&b, "// validates them, so a %s is immutable and always valid. Compare values\n", name)
+	b.WriteString("// with Equal. Entities store it as one JSON column.\n")
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	for _, f := range fields {
+		fmt.Fprintf(&b, "\t%s %s\n", f.Var, f.Type)
+	}
+	b.WriteString("}\n\n")
+
+	params := make([]string, len(fields))
+	args := make([]string, len(fields))
+	inits := make([]string, len(fields))
+	for i, f := range fields {
+		params[i] = f.Var + " " + f.Type
+		args[i] = f.Var
+		inits[i] = f.Var + ": " + f.Var
+	}
+	fmt.Fprintf(&b, "// New%s returns the %s of the given fields, or the error of the first\n", name, name)
+	b.WriteString("// invalid one.\n")
+	fmt.Fprintf(&b, "func New%s(%s) (%s, error) {\n", name, strings.Join(params, ", "), name)
+	fmt.Fprintf(&b, "\t%s := %s{%s}\n", recv, name, strings.Join(inits, ", "))
+	fmt.Fprintf(&b, "\tif err := %s.Validate(); err != nil {\n", recv)
+	fmt.Fprintf(&b, "\t\treturn %s{}, err\n", name)
+	b.WriteString("\t}\n")
+	fmt.Fprintf(&b, "\treturn %s, nil\n", recv)
+	b.WriteString("}\n\n")
+
+	for _, f := range fields {
+		fmt.Fprintf(&b, "// %s returns the %s of %s.\n", f.Name, strings.ToLower(getFieldDisplayName(toSnakeCase(f.Name))), recv)
+		fmt.Fprintf(&b, "func (%s %s) %s() %s {\n", recv, name, f.Name, f.Type)
+		fmt.Fprintf(&b, "\treturn %s.%s\n", recv, f.Var)
+		b.WriteString("}\n\n")
+	}
+	for _, f := range fields {
+		with := make([]string, len(fields))
+		for i, other := range fields {
+			if other.Var == f.Var {
+				with[i] = f.Var
+			} else {
+				with[i] = recv + "." + other.Var
+			}
+		}
+		fmt.Fprintf(&b, "// With%s returns a copy of %s with %s, validated like New%s.\n", f.Name, recv, f.Var, name)
+		fmt.Fprintf(&b, "func (%s %s) With%s(%s %s) (%s, error) {\n", recv, name, f.Name, f.Var, f.Type, name)
+		fmt.Fprintf(&b, "\treturn New%s(%s)\n", name, strings.Join(with, ", "))
+		b.WriteString("}\n\n")
+	}
+
+	fmt.Fprintf(&b, "// Equal reports whether %s and other hold the same values.\n", recv)
+	fmt.Fprintf(&b, "func (%s %s) Equal(other %s) bool {\n", recv, name, name)
+	fmt.Fprintf(&b, "\treturn %s == other\n", recv)
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// IsZero reports whether %s is the zero %s, e.g. of an entity without one.\n", recv, name)
+	fmt.Fprintf(&b, "func (%s %s) IsZero() bool {\n", recv, name)
+	fmt.Fprintf(&b, "\treturn %s == %s{}\n", recv, name)
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// Validate checks the invariants of %s. New%s, UnmarshalJSON and the\n", recv, name)
+	fmt.Fprintf(&b, "// entities holding a %s call it; add the rules of the domain here.\n", name)
+	fmt.Fprintf(&b, "func (%s %s) Validate() error {\n", recv, name)
+	for _, f := range checks {
+		if f.Type == FieldTime {
+			fmt.Fprintf(&b, "\tif %s.%s.IsZero() {\n", recv, f.Var)
+		} else {
+			fmt.Fprintf(&b, "\tif strings.TrimSpace(%s.%s) == \"\" {\n", recv, f.Var)
+		}
+		fmt.Fprintf(&b, "\t\treturn ErrInvalid%s%s\n", name, f.Name)
+		b.WriteString("\t}\n")
+	}
+	b.WriteString("\treturn nil\n")
+	b.WriteString("}\n\n")
+
+	verbs := make([]string, len(fields))
+	values := make([]string, len(fields))
+	for i, f := range fields {
+		verbs[i] = f.JSON + ": %v"
+		values[i] = recv + "." + f.Var
+	}
+	fmt.Fprintf(&b, "// String returns %s with its fields, for logs and errors.\n", recv)
+	fmt.Fprintf(&b, "func (%s %s) String() string {\n", recv, name)
+	fmt.Fprintf(&b, "\treturn fmt.Sprintf(\"%s{%s}\", %s)\n", name, strings.Join(verbs, ", "), strings.Join(values, ", "))
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// %s is the JSON of a %s, in requests, responses and its column.\n", jsonType, name)
+	fmt.Fprintf(&b, "type %s struct {\n", jsonType)
+	for _, f := range fields {
+		fmt.Fprintf(&b, "\t%s %s `json:\"%s\"`\n", f.Name, f.Type, f.JSON)
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// MarshalJSON encodes %s as an object of its fields.\n", recv)
+	fmt.Fprintf(&b, "func (%s %s) MarshalJSON() ([]byte, error) {\n", recv, name)
+	exported := make([]string, len(fields))
+	for i, f := range fields {
+		exported[i] = f.Name + ": " + recv + "." + f.Var
+	}
+	fmt.Fprintf(&b, "\treturn json.Marshal(%s{%s})\n", jsonType, strings.Join(exported, ", "))
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// UnmarshalJSON decodes an object of the fields of a %s and validates it,\n", name)
+	b.WriteString("// so requests carrying invalid values are rejected when decoded.\n")
+	fmt.Fprintf(&b, "func (%s *%s) UnmarshalJSON(data []byte) error {\n", recv, name)
+	b.WriteString("\tif string(data) == \"null\" {\n")
+	b.WriteString("\t\treturn nil\n")
+	b.WriteString("\t}\n")
+	fmt.Fprintf(&b, "\tvar v %s\n", jsonType)
+	b.WriteString("\tif err := json.Unmarshal(data, &v); err != nil {\n")
+	b.WriteString("\t\treturn err\n")
+	b.WriteString("\t}\n")
+	fromJSON := make([]string, len(fields))
+	scanned := make([]string, len(fields))
+	for i, f := range fields {
+		fromJSON[i] = "v." + f.Name
+		scanned[i] = f.Var + ": v." + f.Name
+	}
+	fmt.Fprintf(&b, "\tparsed, err := New%s(%s)\n", name, strings.Join(fromJSON, ", "))
+	b.WriteString("\tif err != nil {\n")
+	b.WriteString("\t\treturn err\n")
+	b.WriteString("\t}\n")
+	fmt.Fprintf(&b, "\t*%s = parsed\n", recv)
+	b.WriteString("\treturn nil\n")
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// Value stores %s as JSON, and the zero %s as NULL.\n", recv, name)
+	fmt.Fprintf(&b, "func (%s %s) Value() (driver.Value, error) {\n", recv, name)
+	fmt.Fprintf(&b, "\tif %s.IsZero() {\n", recv)
+	b.WriteString("\t\treturn nil, nil\n")
+	b.WriteString("\t}\n")
+	fmt.Fprintf(&b, "\tdata, err := %s.MarshalJSON()\n", recv)
+	b.WriteString("\treturn string(data), err\n")
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// Scan reads a %s stored by Value. Stored values are not validated\n", name)
+	b.WriteString("// again, so tightening Validate never makes existing rows unreadable.\n")
+	fmt.Fprintf(&b, "func (%s *%s) Scan(src any) error {\n", recv, name)
+	b.WriteString("\tvar data []byte\n")
+	b.WriteString("\tswitch d := src.(type) {\n")
+	b.WriteString("\tcase nil:\n")
+	fmt.Fprintf(&b, "\t\t*%s = %s{}\n", recv, name)
+	b.WriteString("\t\treturn nil\n")
+	b.WriteString("\tcase []byte:\n")
+	b.WriteString("\t\tdata = d\n")
+	b.WriteString("\tcase string:\n")
+	b.WriteString("\t\tdata = []byte(d)\n")
+	b.WriteString("\tdefault:\n")
+	fmt.Fprintf(&b, "\t\treturn fmt.Errorf(\"%s: cannot scan %%T\", src)\n", errPrefix)
+	b.WriteString("\t}\n")
+	fmt.Fprintf(&b, "\tvar v %s\n", jsonType)
+	b.WriteString("\tif err := json.Unmarshal(data, &v); err != nil {\n")
+	fmt.Fprintf(&b, "\t\treturn fmt.Errorf(\"%s: %%w\", err)\n", errPrefix)
+	b.WriteString("\t}\n")
+	fmt.Fprintf(&b, "\t*%s = %s{%s}\n", recv, name, strings.Join(scanned, ", "))
+	b.WriteString("\treturn nil\n")
+	b.WriteString("}\n")
+
+	if gorm {
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "// GormDBDataType stores %s in a jsonb column on PostgreSQL and a JSON\n", name)
+		b.WriteString("// one elsewhere.\n")
+		fmt.Fprintf(&b, "func (%s) GormDBDataType(db *gorm.DB, _ *schema.Field) string {\n", name)
+		b.WriteString("\tswitch db.Dialector.Name() {\n")
+		b.WriteString("\tcase \"postgres\":\n")
+		b.WriteString("\t\treturn \"jsonb\"\n")
+		b.WriteString("\tcase \"sqlserver\":\n")
+		b.WriteString("\t\treturn \"nvarchar(max)\"\n")
+		b.WriteString("\tcase \"oracle\":\n")
+		b.WriteString("\t\treturn \"clob\"\n")
+		b.WriteString("\tdefault:\n")
+		b.WriteString("\t\treturn \"json\"\n")
+		b.WriteString("\t}\n")
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func init() {
+	valueObjectCmd.Flags().String("fields", "", "Fields of the value object, e.g. \"amount:int64,currency:string\" (strings, numbers, bool and time.Time)")
+	valueObjectCmd.Flags().Bool("dry-run", false, "Preview changes without creating files")
+	valueObjectCmd.Flags().Bool("force", false, "Overwrite the existing value object without asking")
+}
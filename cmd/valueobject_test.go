@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseValueObjectFields(t *testing.T) {
+	fields, err := parseValueObjectFields("Money", "amount:int64,currency:string,IssuedAt:time.Time")
+	require.NoError(t, err)
+	assert.Equal(t, []valueObjectField{
+		{Name: "Amount", Var: "amount", JSON: "amount", Type: "int64"},
+		{Name: "Currency", Var: "currency", JSON: "currency", Type: "string"},
+		{Name: "IssuedAt", Var: "issuedAt", JSON: "issued_at", Type: "time.Time"},
+	}, fields)
+
+	_, err = parseValueObjectFields("Money", "")
+	assert.ErrorContains(t, err, "needs --fields")
+	_, err = parseValueObjectFields("Money", "tags:[]string")
+	assert.ErrorContains(t, err, "has type []string")
+	_, err = parseValueObjectFields("Money", "currency:string:enum=EUR|USD")
+	assert.ErrorContains(t, err, "takes no modifier")
+}
+
+func TestBuildValueObject(t *testing.T) {
+	fields, err := parseValueObjectFields("Money", "amount:int64,currency:string")
+	require.NoError(t, err)
+
+	src := buildValueObject("Money", fields, false)
+	_, err = parser.ParseFile(token.NewFileSet(), "money.go", src, parser.ParseComments)
+	require.NoError(t, err, src)
+	assert.Contains(t, src, "// Money is a value object: its fields are only set by NewMoney, which\n")
+	assert.Contains(t, src, "type Money struct {\n\tamount int64\n\tcurrency string\n}\n")
+	assert.Contains(t, src, `ErrInvalidMoneyCurrency = errors.New("invalid money currency")`)
+	assert.Contains(t, src, "func NewMoney(amount int64, currency string) (Money, error) {\n")
+	assert.Contains(t, src, "func (m Money) WithCurrency(currency string) (Money, error) {\n\treturn NewMoney(m.amount, currency)\n}\n")
+	assert.Contains(t, src, "\tparsed, err := NewMoney(v.Amount, v.Currency)\n")
+	assert.Contains(t, src, "\t*m = Money{amount: v.Amount, currency: v.Currency}\n")
+	assert.Contains(t, src, `return fmt.Errorf("money: cannot scan %T", src)`)
+	assert.NotContains(t, src, "gorm")
+
+	src = buildValueObject("Money", fields, true)
+	_, err = parser.ParseFile(token.NewFileSet(), "money.go", src, parser.ParseComments)
+	require.NoError(t, err, src)
+	assert.Contains(t, src, "func (Money) GormDBDataType(db *gorm.DB, _ *schema.Field) string {\n")
+
+	// Without string or time fields there are no errors to declare.
+	coords, err := parseValueObjectFields("Point", "x:float64,y:float64")
+	require.NoError(t, err)
+	src = buildValueObject("Point", coords, false)
+	_, err = parser.ParseFile(token.NewFileSet(), "point.go", src, parser.ParseComments)
+	require.NoError(t, err, src)
+	assert.NotContains(t, src, `"errors"`)
+	assert.Contains(t, src, "func (p Point) Validate() error {\n\treturn nil\n}\n")
+}
+
+func TestValueObjectFieldType(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+	sm := NewSafetyManager(false, true, false)
+
+	fields, err := parseValueObjectFields("Money", "amount:int64,currency:string")
+	require.NoError(t, err)
+	require.NoError(t, generateValueObject("Money", fields, "lowercase", sm))
+	assert.True(t, isValueObjectType("Money"))
+	assert.False(t, isValueObjectType("Price"))
+	assert.Equal(t, `Money{amount: 1, currency: "valid value"}`, valueObjectLiteral("Money"))
+
+	entityFields := parseFieldsWithValidation("name:string,price:Money", false)
+	assert.Equal(t, "`json:\"price\"`", entityFields[2].Tag)
+	assert.Equal(t, "domain.Money", dtoFieldType(entityFields[2]))
+
+	require.NoError(t, generateEntity("Product", "name:string,price:Money", true, false, false, false, false, false, false, false, "lowercase", sm))
+	entity := readParsedGo(t, filepath.Join(DirInternal, DirDomain, "product.go"))
+	assert.NotContains(t, entity, "type Money string")
+	assert.Contains(t, entity, "\tif err := p.Price.Validate(); err != nil {\n\t\treturn err\n\t}\n")
+	generateEntityTests(filepath.Join(DirInternal, DirDomain), "Product", entityFields, true, false, "lowercase", sm)
+	tests := readParsedGo(t, filepath.Join(DirInternal, DirDomain, "product_test.go"))
+	assert.Contains(t, tests, `Price: Money{amount: 1, currency: "valid value"}`)
+}
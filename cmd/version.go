@@ -23,9 +23,15 @@ var (
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Display Goca CLI version",
-	Long:  "Display the current version of Goca CLI along with build information.",
-	Run: func(cmd *cobra.Command, _ []string) {
+	Long: `Display the current version of Goca CLI along with build information.
+
+With --check, also compare it with the goca version recorded in the current
+project's .goca.yaml: whether the installed CLI is newer or older, which
+template changes were released in between, and whether goca upgrade is
+recommended.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
 		short, _ := cmd.Flags().GetBool("short")
+		check, _ := cmd.Flags().GetBool("check")
 
 		if short {
 			ui.Println(Version)
@@ -38,6 +44,12 @@ var versionCmd = &cobra.Command{
 			ui.Println(fmt.Sprintf("Go Version: %s", GoVersion))
 			ui.Println(fmt.Sprintf("Git Commit: %s", GitCommit))
 		}
+
+		if check {
+			ui.Println("")
+			return runVersionCheck()
+		}
+		return nil
 	},
 }
 
@@ -79,4 +91,5 @@ func init() {
 		}
 	}
 	versionCmd.Flags().BoolP("short", "s", false, "Display only the version number")
+	versionCmd.Flags().Bool("check", false, "Compare with the goca version that generated the current project")
 }
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// templateChange is a change to the code goca generates, introduced by a
+// release.
+type templateChange struct {
+	Version string
+	Area    string
+	Summary string
+	// Regenerate is set when existing projects only get the change by
+	// regenerating the affected files.
+	Regenerate bool
+}
+
+// templateChanges lists, oldest first, the releases that changed generated
+// code. Add an entry whenever a release changes what goca generates.
+var templateChanges = []templateChange{
+	{"1.17.1", "init", "go.mod and main.go use the driver of the selected database instead of PostgreSQL", true},
+	{"1.17.2", "init", "MongoDB projects connect with mongo-driver instead of GORM", true},
+	{"1.17.2", "init", "SQLite is the default database of new projects", false},
+	{"1.22.0", "handler", "HTTP handlers import the project's middleware package when it exists", true},
+	{"1.22.0", "repository", "--cache generates a Redis caching decorator for repositories", false},
+}
+
+// versionCompatibility compares the installed goca with the version that
+// generated a project.
+type versionCompatibility struct {
+	Installed string
+	Project   string
+	// Comparison is 1 when the installed goca is newer than the project's,
+	// -1 when it is older and 0 when they match or cannot be compared.
+	Comparison int
+	// Changes are the template changes between the two versions.
+	Changes []templateChange
+	// UpgradeRecommended is set when goca upgrade should be run.
+	UpgradeRecommended bool
+}
+
+// checkVersionCompatibility compares installed with the project's recorded
+// goca version. An installed development build is treated as newer than every
+// release; a project generated by one cannot be compared.
+func checkVersionCompatibility(installed, project string) versionCompatibility {
+	c := versionCompatibility{Installed: installed, Project: project}
+	switch {
+	case installed == project:
+		return c
+	case project == "" || isDevelopmentVersion(project):
+		c.UpgradeRecommended = true
+		return c
+	case isDevelopmentVersion(installed):
+		c.Comparison = 1
+	default:
+		c.Comparison = compareVersions(installed, project)
+	}
+	if c.Comparison <= 0 {
+		return c
+	}
+
+	c.UpgradeRecommended = true
+	for _, change := range templateChanges {
+		if compareVersions(change.Version, project) > 0 && (isDevelopmentVersion(installed) || compareVersions(change.Version, installed) <= 0) {
+			c.Changes = append(c.Changes, change)
+		}
+	}
+	return c
+}
+
+// isDevelopmentVersion reports whether version belongs to a build from
+// source: "dev", or a Go pseudo-version such as 0.0.0-20260101000000-abcdef.
+func isDevelopmentVersion(version string) bool {
+	return version == "dev" || strings.HasPrefix(version, "0.0.0-")
+}
+
+// recordedGocaVersion returns project.metadata.goca_version from the
+// .goca.yaml at path.
+func recordedGocaVersion(path string) (string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var cfg GocaConfig
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return "", fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return cfg.Project.Metadata["goca_version"], nil
+}
+
+// runVersionCheck reports how the installed goca relates to the version that
+// generated the project in the current directory.
+func runVersionCheck() error {
+	project, err := recordedGocaVersion(".goca.yaml")
+	if errors.Is(err, os.ErrNotExist) {
+		ui.Println("No .goca.yaml in the current directory; run this command from a goca project")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	c := checkVersionCompatibility(Version, project)
+	switch {
+	case c.Project == "":
+		ui.Println("Project: generated version not recorded in .goca.yaml")
+	case isDevelopmentVersion(c.Project) && c.Installed != c.Project:
+		ui.Println("Project: generated with a development build of goca")
+	case c.Comparison > 0:
+		ui.Println(fmt.Sprintf("Project: generated with goca %s; the installed goca is newer", c.Project))
+	case c.Comparison < 0:
+		ui.Println(fmt.Sprintf("Project: generated with goca %s; the installed goca is older", c.Project))
+	default:
+		ui.Println(fmt.Sprintf("Project: generated with goca %s; up to date", c.Project))
+	}
+
+	if len(c.Changes) > 0 {
+		ui.Println("")
+		ui.Println(fmt.Sprintf("Template changes since %s:", c.Project))
+		regenerate := false
+		for _, change := range c.Changes {
+			line := fmt.Sprintf("  %-8s %-11s %s", change.Version, change.Area, change.Summary)
+			if change.Regenerate {
+				line += " (regenerate to apply)"
+				regenerate = true
+			}
+			ui.Println(line)
+		}
+		if regenerate {
+			ui.Println("")
+			ui.Println("Changes marked 'regenerate to apply' only reach existing code through 'goca upgrade --regenerate <feature>'.")
+		}
+	}
+
+	ui.Println("")
+	switch {
+	case c.Project == "" || (isDevelopmentVersion(c.Project) && c.Installed != c.Project):
+		ui.Println("Recommendation: run 'goca upgrade --update' to record the installed version in .goca.yaml")
+	case c.Comparison < 0:
+		ui.Println(fmt.Sprintf("Recommendation: run 'goca self-update --version v%s' or newer before generating code in this project", c.Project))
+	case c.UpgradeRecommended:
+		ui.Println("Recommendation: run 'goca upgrade' to review the configuration, then 'goca upgrade --update' to record the installed version")
+	default:
+		ui.Println("No upgrade needed")
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckVersionCompatibility(t *testing.T) {
+	t.Run("installed newer", func(t *testing.T) {
+		c := checkVersionCompatibility("1.22.0", "1.17.1")
+		assert.Equal(t, 1, c.Comparison)
+		assert.True(t, c.UpgradeRecommended)
+		var versions []string
+		for _, change := range c.Changes {
+			versions = append(versions, change.Version)
+		}
+		assert.Equal(t, []string{"1.17.2", "1.17.2", "1.22.0", "1.22.0"}, versions)
+	})
+
+	t.Run("same version", func(t *testing.T) {
+		c := checkVersionCompatibility("1.22.0", "1.22.0")
+		assert.Equal(t, 0, c.Comparison)
+		assert.False(t, c.UpgradeRecommended)
+		assert.Empty(t, c.Changes)
+	})
+
+	t.Run("installed older", func(t *testing.T) {
+		c := checkVersionCompatibility("1.17.2", "1.22.0")
+		assert.Equal(t, -1, c.Comparison)
+		assert.False(t, c.UpgradeRecommended)
+		assert.Empty(t, c.Changes)
+	})
+
+	t.Run("development build", func(t *testing.T) {
+		c := checkVersionCompatibility("dev", "1.18.0")
+		assert.Equal(t, 1, c.Comparison)
+		require.Len(t, c.Changes, 2)
+		assert.Equal(t, "1.22.0", c.Changes[0].Version)
+
+		c = checkVersionCompatibility("0.0.0-20260101000000-abcdef123456", "1.22.0")
+		assert.Equal(t, 1, c.Comparison)
+	})
+
+	t.Run("not recorded", func(t *testing.T) {
+		c := checkVersionCompatibility("1.22.0", "")
+		assert.True(t, c.UpgradeRecommended)
+		assert.Empty(t, c.Changes)
+	})
+}
+
+func TestRecordedGocaVersion(t *testing.T) {
+	chdirTemp(t)
+	require.NoError(t, os.WriteFile(".goca.yaml", []byte("project:\n  name: shop\n  metadata:\n    goca_version: \"1.18.2\"\n"), 0o644))
+	version, err := recordedGocaVersion(".goca.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "1.18.2", version)
+
+	_, err = recordedGocaVersion("missing.yaml")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestGenerateDefaultConfigRecordsVersion(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, NewConfigManager().GenerateDefaultConfig(dir, "shop", "example.com/shop", "postgres"))
+	version, err := recordedGocaVersion(filepath.Join(dir, ".goca.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, Version, version)
+}
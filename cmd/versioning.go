@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// versionField is the column --versioned adds to an entity. Every update
+// increments it, and an update based on an older version is a conflict.
+var versionField = Field{Name: "Version", Type: "int", Tag: "`json:\"version\" gorm:\"not null;default:1\"`"}
+
+// isVersionedEntity reports whether the entity in internal/domain declares
+// the version column of --versioned. The repository, use case and handler
+// layers read it from there, so they only need the entity name.
+func isVersionedEntity(entity string) bool {
+	return entityDeclaresField(entity, versionField)
+}
+
+// entityDeclaresField reports whether the entity in internal/domain declares
+// a field with the name and type of f.
+func entityDeclaresField(entity string, f Field) bool {
+	path, err := findEntityFile(entity)
+	if err != nil {
+		return false
+	}
+	s, err := parseGoSource(path)
+	if err != nil {
+		return false
+	}
+	for _, field := range readEntityFields(s.file, entity) {
+		if field.Name == f.Name && field.Type == f.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// writeGormVersionedUpdate writes the body of a GORM update that only
+// succeeds while the stored version is the one entityVar was read at. The
+// version is incremented in the same statement; when no row matched, another
+// update got there first and the entity keeps its version.
+func writeGormVersionedUpdate(content *strings.Builder, db, entityVar string) {
+	fmt.Fprintf(content, "\tversion := %s.Version\n", entityVar)
+	fmt.Fprintf(content, "\t%s.Version++\n", entityVar)
+	fmt.Fprintf(content, "\tresult := %s.Model(%s).Where(\"version = ?\", version).Select(\"*\").Updates(%s)\n", db, entityVar, entityVar)
+	content.WriteString("\tif result.Error == nil && result.RowsAffected == 0 {\n")
+	content.WriteString("\t\tresult.Error = domain.ErrConflict\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tif result.Error != nil {\n")
+	fmt.Fprintf(content, "\t\t%s.Version = version\n", entityVar)
+	content.WriteString("\t}\n")
+	fmt.Fprintf(content, "\treturn translateGormError(%s, result.Error)\n", db)
+}
+
+// writeMongoVersionedUpdate writes the body of a MongoDB update that replaces
+// the document only while it still has the version entityVar was read at.
+// idKey and versionKey are the document keys of the ID and the version,
+// idValue the ID the document is matched by and replacement the document
+// written, entityVar or its copy without the ObjectID.
+func writeMongoVersionedUpdate(content *strings.Builder, entityVar, idKey, idValue, replacement, versionKey string) {
+	content.WriteString("\tctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)\n")
+	content.WriteString("\tdefer cancel()\n")
+	fmt.Fprintf(content, "\tversion := %s.Version\n", entityVar)
+	fmt.Fprintf(content, "\t%s.Version++\n", entityVar)
+	if replacement != entityVar {
+		writeMongoReplacement(content, entityVar)
+	}
+	fmt.Fprintf(content, "\tresult, err := m.collection.ReplaceOne(ctx, bson.M{%q: %s, %q: version}, %s)\n", idKey, idValue, versionKey, replacement)
+	content.WriteString("\tif err == nil && result.MatchedCount == 0 {\n")
+	content.WriteString("\t\terr = domain.ErrConflict\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tif err != nil {\n")
+	fmt.Fprintf(content, "\t\t%s.Version = version\n", entityVar)
+	content.WriteString("\t}\n")
+	content.WriteString("\treturn translateMongoError(err)\n")
+}
+
+// writeUpdateVersionCheck writes the statement rejecting an update whose
+// input names a version other than the stored one. It catches stale clients
+// on every database; GORM and MongoDB repositories also guard the write.
+func writeUpdateVersionCheck(content *strings.Builder, entityVar string) {
+	fmt.Fprintf(content, "\tif input.Version != nil && *input.Version != %s.Version {\n", entityVar)
+	content.WriteString("\t\treturn domain.ErrConflict\n")
+	content.WriteString("\t}\n\n")
+}
+
+// generateHTTPETagFile writes internal/handler/http/etag.go with the helpers
+// versioned handlers use for the ETag and If-Match headers. Like query.go, it
+// is shared by every handler and only rewritten with --force.
+func generateHTTPETagFile(dir string, sm ...*SafetyManager) {
+	filename := filepath.Join(dir, "etag.go")
+	force := len(sm) > 0 && sm[0] != nil && sm[0].Force
+	if _, err := os.Stat(filename); err == nil && !force {
+		return
+	}
+	if err := writeGoFile(filename, "package "+DirHTTP+"\n"+httpETagSource, sm...); err != nil {
+		ui.Error(fmt.Sprintf("Error writing ETag helpers: %v", err))
+	}
+}
+
+// httpETagSource is etag.go after its package clause.
+const httpETagSource = `
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// etag returns the entity tag of a version, e.g. "3".
+func etag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// ifMatchVersion returns the version in the If-Match header, or nil when the
+// request has none or accepts any version (*).
+func ifMatchVersion(r *http.Request) (*int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+	version, err := strconv.Atoi(strings.Trim(header, "\""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid If-Match header %s", header)
+	}
+	return &version, nil
+}
+`
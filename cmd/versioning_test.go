@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionedEntity(t *testing.T) {
+	defer ensureTestUI(t)()
+	chdirTemp(t)
+
+	require.NoError(t, generateEntity("Product", "name:string,price:float64", true, false, false, false, true, false, false, false, "lowercase"))
+	entity := readParsedGo(t, filepath.Join(DirInternal, DirDomain, "product.go"))
+	assert.Regexp(t, "Version +int +`json:\"version\" gorm:\"not null;default:1\"`", entity)
+	assert.NotContains(t, entity, "p.Version", "the version is not validated")
+	assert.Contains(t, readParsedGo(t, filepath.Join(DirInternal, DirDomain, "repository_errors.go")), `ErrConflict = errors.New("conflict")`)
+
+	assert.True(t, isVersionedEntity("Product"))
+	assert.Equal(t, "name:string,price:float64", readEntityFieldsString("Product"))
+
+	require.NoError(t, generateEntity("Order", "total:float64", true, false, false, false, false, false, false, false, "lowercase"))
+	assert.False(t, isVersionedEntity("Order"))
+	assert.False(t, isVersionedEntity("Missing"))
+}
+
+func TestVersionedLayers(t *testing.T) {
+	defer ensureTestUI(t)()
+	setupCLIProject(t, fullProductUseCase)
+	require.NoError(t, generateEntity("Product", "name:string,price:float64", true, false, false, false, true, false, false, false, "lowercase", NewSafetyManager(false, true, false)))
+	fields := parseFields("name:string,price:float64")
+
+	dir := filepath.Join(DirInternal, DirRepository)
+	generateGormRepositoryWithFields(dir, "Product", "Postgres", fields, false, true)
+	gorm := readParsedGo(t, filepath.Join(dir, "postgres_product_repository.go"))
+	assert.Contains(t, gorm, `result := p.db.Model(product).Where("version = ?", version).Select("*").Updates(product)`)
+	assert.Contains(t, gorm, `result := tx.Model(product).Where("version = ?", version).Select("*").Updates(product)`)
+	assert.Contains(t, gorm, "result.Error = domain.ErrConflict")
+
+	generateMongoRepositoryWithFields(dir, "Product", fields, false, false)
+	mongo := readParsedGo(t, filepath.Join(dir, "mongo_product_repository.go"))
+	assert.Contains(t, mongo, `m.collection.ReplaceOne(ctx, bson.M{"id": product.ID, "version": version}, product)`)
+
+	usecaseDir := filepath.Join(DirInternal, DirUseCase)
+	generateDTOFileWithFields(usecaseDir, "Product", []string{OpCreate, OpUpdate}, false, "name:string,price:float64", DTOStyleNested)
+	assert.Regexp(t, `Version +\*int +`+"`json:\"version,omitempty\"`", readParsedGo(t, filepath.Join(usecaseDir, "dto.go")))
+	generateUseCaseServiceWithFields(usecaseDir, "ProductUseCase", "Product", []string{OpUpdate}, false, false, "name:string,price:float64", DTOStyleNested)
+	assert.Contains(t, readParsedGo(t, filepath.Join(usecaseDir, "product_service.go")), "if input.Version != nil && *input.Version != product.Version {\n\t\treturn domain.ErrConflict")
+
+	httpDir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	require.NoError(t, os.MkdirAll(httpDir, 0o755))
+	generateHTTPHandlerFile(httpDir, "Product", false, false, "lowercase")
+	handler := readParsedGo(t, filepath.Join(httpDir, "product_handler.go"))
+	assert.Contains(t, handler, `w.Header().Set("ETag", etag(product.Version))`)
+	assert.Contains(t, handler, "version, err := ifMatchVersion(r)")
+	assert.Contains(t, handler, "if errors.Is(err, domain.ErrConflict) {\n\t\t\thttp.Error(w, err.Error(), http.StatusConflict)")
+	assert.Contains(t, readParsedGo(t, filepath.Join(httpDir, "etag.go")), "func ifMatchVersion(r *http.Request) (*int, error)")
+}
+
+func TestUnversionedLayers(t *testing.T) {
+	defer ensureTestUI(t)()
+	setupCLIProject(t, fullProductUseCase)
+
+	httpDir := filepath.Join(DirInternal, DirHandler, DirHTTP)
+	require.NoError(t, os.MkdirAll(httpDir, 0o755))
+	generateHTTPHandlerFile(httpDir, "Product", false, false, "lowercase")
+	assert.NotContains(t, readParsedGo(t, filepath.Join(httpDir, "product_handler.go")), "ErrConflict")
+	_, err := os.Stat(filepath.Join(httpDir, "etag.go"))
+	assert.True(t, os.IsNotExist(err))
+
+	var b strings.Builder
+	generateBasicCRUDMethods(&b, "Product", "postgresProductRepository", false)
+	assert.Contains(t, b.String(), "result := p.db.Save(product)")
+}
@@ -303,8 +303,12 @@ export default defineConfig({
                     items: [
                         { text: 'Overview', link: '/commands/' },
                         { text: 'goca init', link: '/commands/init' },
+                        { text: 'goca example', link: '/commands/example' },
                         { text: 'goca feature', link: '/commands/feature' },
+                        { text: 'goca generate', link: '/commands/generate' },
+                        { text: 'goca field', link: '/commands/field' },
                         { text: 'goca entity', link: '/commands/entity' },
+                        { text: 'goca valueobject', link: '/commands/valueobject' },
                         { text: 'goca usecase', link: '/commands/usecase' },
                         { text: 'goca repository', link: '/commands/repository' },
                         { text: 'goca handler', link: '/commands/handler' },
@@ -314,13 +318,39 @@ export default defineConfig({
                         { text: 'goca messages', link: '/commands/messages' },
                         { text: 'goca mocks', link: '/commands/mocks' },
                         { text: 'goca ci', link: '/commands/ci' },
+                        { text: 'goca deploy', link: '/commands/deploy' },
+                        { text: 'goca seed', link: '/commands/seed' },
+                        { text: 'goca migrate', link: '/commands/migrate' },
+                        { text: 'goca ingest', link: '/commands/ingest' },
+                        { text: 'goca cdc', link: '/commands/cdc' },
+                        { text: 'goca archive', link: '/commands/archive' },
+                        { text: 'goca admin', link: '/commands/admin' },
                         { text: 'goca middleware', link: '/commands/middleware' },
                         { text: 'goca test-integration', link: '/commands/test-integration' },
                         { text: 'goca mcp-server', link: '/commands/mcp-server' },
                         { text: 'goca config', link: '/commands/config' },
                         { text: 'goca template', link: '/commands/template' },
+                        { text: 'goca plugin', link: '/commands/plugin' },
                         { text: 'goca doctor', link: '/commands/doctor' },
                         { text: 'goca analyze', link: '/commands/analyze' },
+                        { text: 'goca diff', link: '/commands/diff' },
+                        { text: 'goca lint', link: '/commands/lint' },
+                        { text: 'goca explain', link: '/commands/explain' },
+                        { text: 'goca breaking', link: '/commands/breaking' },
+                        { text: 'goca self-test', link: '/commands/self-test' },
+                        { text: 'goca bench', link: '/commands/bench' },
+                        { text: 'goca audit-deps', link: '/commands/audit-deps' },
+                        { text: 'goca notification', link: '/commands/notification' },
+                        { text: 'goca rename', link: '/commands/rename' },
+                        { text: 'goca featureflags', link: '/commands/featureflags' },
+                        { text: 'goca presenter', link: '/commands/presenter' },
+                        { text: 'goca completion', link: '/commands/completion' },
+                        { text: 'goca docs', link: '/commands/docs' },
+                        { text: 'goca status', link: '/commands/status' },
+                        { text: 'goca regen', link: '/commands/regen' },
+                        { text: 'goca experiments', link: '/commands/experiments' },
+                        { text: 'goca serve-ui', link: '/commands/serve-ui' },
+                        { text: 'goca self-update', link: '/commands/self-update' },
                         { text: 'goca upgrade', link: '/commands/upgrade' },
                         { text: 'goca version', link: '/commands/version' },
                     ]
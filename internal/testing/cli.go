@@ -17,9 +17,14 @@ func NewCLIRunner(suite *TestSuite) *CLIRunner {
 
 // Run executes a Goca CLI command and returns the output.
 func (r *CLIRunner) Run(args ...string) (string, error) {
+	return r.RunIn(r.suite.tempDir, args...)
+}
+
+// RunIn executes a Goca CLI command in dir and returns the output.
+func (r *CLIRunner) RunIn(dir string, args ...string) (string, error) {
 	// Build the full command with goca binary
 	cmd := exec.Command("goca", args...)
-	cmd.Dir = r.suite.tempDir
+	cmd.Dir = dir
 
 	output, err := cmd.CombinedOutput()
 	return string(output), err
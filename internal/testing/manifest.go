@@ -0,0 +1,226 @@
+package testing
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// scenarioManifestDir holds the YAML scenarios, relative to this package.
+const scenarioManifestDir = "scenarios"
+
+// ScenarioManifest declares a test scenario in YAML: the goca commands to run
+// and what the generated tree must look like afterwards.
+type ScenarioManifest struct {
+	Name        string         `yaml:"name"`
+	Description string         `yaml:"description"`
+	Steps       []ManifestStep `yaml:"steps"`
+	Files       []ManifestFile `yaml:"files"`
+	source      string
+}
+
+// ManifestStep is one goca invocation of a scenario.
+type ManifestStep struct {
+	// Run holds the goca arguments, e.g. [feature, User, --fields, "name:string"].
+	Run []string `yaml:"run"`
+	// Dir is the working directory relative to the scenario root.
+	Dir string `yaml:"dir"`
+	// Fail expects the command to exit with an error.
+	Fail bool `yaml:"fail"`
+	// Output lists substrings the combined output must contain.
+	Output []string `yaml:"output"`
+}
+
+// ManifestFile asserts on one file relative to the scenario root.
+type ManifestFile struct {
+	Path        string   `yaml:"path"`
+	Absent      bool     `yaml:"absent"`
+	Contains    []string `yaml:"contains"`
+	NotContains []string `yaml:"not_contains"`
+	// Syntax parses the file as Go source.
+	Syntax bool `yaml:"syntax"`
+}
+
+// LoadScenarioManifests reads every *.yaml and *.yml manifest in dir, sorted
+// by file name. A missing directory yields no manifests.
+func LoadScenarioManifests(dir string) ([]ScenarioManifest, error) {
+	var paths []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	manifests := make([]ScenarioManifest, 0, len(paths))
+	for _, path := range paths {
+		manifest, err := loadScenarioManifest(path)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, manifest)
+	}
+	return manifests, nil
+}
+
+// loadScenarioManifest decodes and checks a single manifest file.
+func loadScenarioManifest(path string) (ScenarioManifest, error) {
+	var manifest ScenarioManifest
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return manifest, err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&manifest); err != nil {
+		return manifest, fmt.Errorf("%s: %w", path, err)
+	}
+	manifest.source = filepath.Base(path)
+
+	if err := manifest.validate(); err != nil {
+		return manifest, fmt.Errorf("%s: %w", path, err)
+	}
+	return manifest, nil
+}
+
+// validate rejects manifests that would run nothing or assert nothing useful.
+func (m ScenarioManifest) validate() error {
+	if strings.TrimSpace(m.Name) == "" {
+		return errors.New("name is required")
+	}
+	if len(m.Steps) == 0 {
+		return errors.New("at least one step is required")
+	}
+	for i, step := range m.Steps {
+		if len(step.Run) == 0 {
+			return fmt.Errorf("step %d: run is required", i+1)
+		}
+		if filepath.IsAbs(step.Dir) || strings.HasPrefix(filepath.Clean(step.Dir), "..") {
+			return fmt.Errorf("step %d: dir %q must stay inside the scenario root", i+1, step.Dir)
+		}
+	}
+	for i, file := range m.Files {
+		if file.Path == "" {
+			return fmt.Errorf("file %d: path is required", i+1)
+		}
+		if file.Absent && (len(file.Contains) > 0 || len(file.NotContains) > 0 || file.Syntax) {
+			return fmt.Errorf("file %s: absent files cannot carry content assertions", file.Path)
+		}
+	}
+	return nil
+}
+
+// Scenario converts the manifest into a TestScenario for the runner.
+func (m ScenarioManifest) Scenario() TestScenario {
+	description := m.Description
+	if description == "" {
+		description = "Declared in " + filepath.Join(scenarioManifestDir, m.source)
+	}
+
+	return TestScenario{
+		Name:        m.Name,
+		Description: description,
+		Execute: func(suite *TestSuite) error {
+			cli := NewCLIRunner(suite)
+			for i, step := range m.Steps {
+				if err := m.runStep(cli, suite, step); err != nil {
+					return fmt.Errorf("step %d (goca %s): %w", i+1, strings.Join(step.Run, " "), err)
+				}
+			}
+			return nil
+		},
+		Validate: func(suite *TestSuite) []*TestError {
+			var errors []*TestError
+			code := NewCodeValidator(suite)
+			for _, file := range m.Files {
+				errors = append(errors, m.checkFile(suite, code, file)...)
+			}
+			return errors
+		},
+	}
+}
+
+// runStep runs one goca command in the step directory and checks its result.
+func (m ScenarioManifest) runStep(cli *CLIRunner, suite *TestSuite, step ManifestStep) error {
+	output, err := cli.RunIn(filepath.Join(suite.tempDir, step.Dir), step.Run...)
+
+	switch {
+	case err != nil && !step.Fail:
+		return fmt.Errorf("%v\nOutput: %s", err, output)
+	case err == nil && step.Fail:
+		return fmt.Errorf("expected failure but succeeded\nOutput: %s", output)
+	}
+
+	for _, want := range step.Output {
+		if !strings.Contains(output, want) {
+			return fmt.Errorf("output does not contain %q\nOutput: %s", want, output)
+		}
+	}
+	return nil
+}
+
+// checkFile applies the assertions of one ManifestFile.
+func (m ScenarioManifest) checkFile(suite *TestSuite, code *CodeValidator, file ManifestFile) []*TestError {
+	fullPath := filepath.Join(suite.tempDir, file.Path)
+
+	if file.Absent {
+		if suite.FileExists(fullPath) {
+			return []*TestError{NewFileError(file.Path, "absence", "file should not exist")}
+		}
+		return nil
+	}
+
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		return []*TestError{NewFileError(file.Path, "existence", "file not found")}
+	}
+	content := string(data)
+
+	var errors []*TestError
+	for _, want := range file.Contains {
+		if !strings.Contains(content, want) {
+			errors = append(errors, NewComplianceError(file.Path, "content", fmt.Sprintf("missing %q", want)))
+		}
+	}
+	for _, unwanted := range file.NotContains {
+		if strings.Contains(content, unwanted) {
+			errors = append(errors, NewComplianceError(file.Path, "content", fmt.Sprintf("unexpected %q", unwanted)))
+		}
+	}
+	if file.Syntax {
+		if err := code.ValidateGoSyntax(fullPath); err != nil {
+			errors = append(errors, NewSyntaxError(file.Path, err.Error()))
+		}
+	}
+	return errors
+}
+
+// manifestScenarios loads the scenarios under scenarioManifestDir. A broken
+// manifest becomes a failing scenario so the runner reports it.
+func manifestScenarios() []TestScenario {
+	manifests, err := LoadScenarioManifests(scenarioManifestDir)
+	if err != nil {
+		return []TestScenario{{
+			Name:        "Scenario Manifests",
+			Description: "Loads the YAML scenarios in " + scenarioManifestDir,
+			Execute: func(*TestSuite) error {
+				return err
+			},
+		}}
+	}
+
+	scenarios := make([]TestScenario, 0, len(manifests))
+	for _, manifest := range manifests {
+		scenarios = append(scenarios, manifest.Scenario())
+	}
+	return scenarios
+}
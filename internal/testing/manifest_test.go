@@ -0,0 +1,96 @@
+package testing
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLoadScenarioManifests checks the shipped manifests and the loader checks.
+func TestLoadScenarioManifests(t *testing.T) {
+	manifests, err := LoadScenarioManifests(scenarioManifestDir)
+	if err != nil {
+		t.Fatalf("shipped manifests do not load: %v", err)
+	}
+	if len(manifests) == 0 {
+		t.Fatalf("expected manifests in %s", scenarioManifestDir)
+	}
+
+	cases := map[string]string{
+		"unknown.yaml":  "name: X\nsteps:\n  - run: [version]\nfiels: []\n",
+		"noname.yaml":   "steps:\n  - run: [version]\n",
+		"nosteps.yaml":  "name: X\n",
+		"escape.yaml":   "name: X\nsteps:\n  - run: [version]\n    dir: ../out\n",
+		"absent.yaml":   "name: X\nsteps:\n  - run: [version]\nfiles:\n  - path: a.go\n    absent: true\n    syntax: true\n",
+		"emptyrun.yaml": "name: X\nsteps:\n  - dir: app\n",
+	}
+	for name, content := range cases {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadScenarioManifests(dir); err == nil {
+			t.Errorf("%s: expected a load error", name)
+		}
+	}
+
+	if manifests, err := LoadScenarioManifests(filepath.Join(t.TempDir(), "missing")); err != nil || len(manifests) != 0 {
+		t.Errorf("missing directory should load nothing, got %d manifests and %v", len(manifests), err)
+	}
+}
+
+// TestScenarioManifestValidate checks the file assertions without running goca.
+func TestScenarioManifestValidate(t *testing.T) {
+	suite := NewTestSuite(t)
+	defer suite.Cleanup()
+
+	if err := os.WriteFile(filepath.Join(suite.tempDir, "user.go"), []byte("package domain\n\ntype User struct{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := ScenarioManifest{
+		Name:  "Validate",
+		Steps: []ManifestStep{{Run: []string{"version"}}},
+		Files: []ManifestFile{
+			{Path: "user.go", Syntax: true, Contains: []string{"type User struct"}, NotContains: []string{"DeletedAt"}},
+			{Path: "user_test.go", Absent: true},
+		},
+	}
+	if errs := manifest.Scenario().Validate(suite); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	manifest.Files = []ManifestFile{
+		{Path: "user.go", Contains: []string{"Email string"}, NotContains: []string{"package domain"}},
+		{Path: "user.go", Absent: true},
+		{Path: "order.go"},
+	}
+	errs := manifest.Scenario().Validate(suite)
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 errors, got %v", errs)
+	}
+	if !strings.Contains(errs[0].Error(), `missing "Email string"`) {
+		t.Errorf("unexpected first error: %v", errs[0])
+	}
+}
+
+// TestScenarioManifests runs the YAML scenarios against the goca binary in PATH.
+func TestScenarioManifests(t *testing.T) {
+	if _, err := exec.LookPath("goca"); err != nil {
+		t.Skip("goca binary not found in PATH")
+	}
+
+	runner := &ComprehensiveTestRunner{scenarios: manifestScenarios()}
+	for _, scenario := range runner.scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			suite := NewTestSuite(t)
+			defer suite.Cleanup()
+
+			for _, err := range runner.runScenario(scenario, suite) {
+				t.Error(err.DetailedError())
+			}
+		})
+	}
+}
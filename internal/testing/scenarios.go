@@ -120,6 +120,7 @@ func (r *ComprehensiveTestRunner) registerAllScenarios() {
 		r.createFlagValidationScenario(),
 		r.createVersionAndHelpScenario(),
 	}
+	r.scenarios = append(r.scenarios, manifestScenarios()...)
 }
 
 // Scenario implementations
@@ -460,13 +461,13 @@ func (r *ComprehensiveTestRunner) createInterfacesScenario() TestScenario {
 			return nil
 		},
 		Validate: func(suite *TestSuite) []*TestError {
-			interfaceFile := filepath.Join(suite.tempDir, "pkg/interfaces/category_repository.go")
+			interfaceFile := filepath.Join(suite.tempDir, "internal/repository/interfaces.go")
 			if !suite.FileExists(interfaceFile) {
 				return []*TestError{NewFileError(interfaceFile, "existence", "interface file not found")}
 			}
 
 			code := NewCodeValidator(suite)
-			expectedMethods := []string{"Create", "GetByID", "Update", "Delete", "List"}
+			expectedMethods := []string{"Save", "FindByID", "Update", "Delete", "FindAll"}
 			if err := code.ValidateInterfaceMethods(interfaceFile, "CategoryRepository", expectedMethods); err != nil {
 				return []*TestError{NewComplianceError(interfaceFile, "interface", err.Error())}
 			}
@@ -21,7 +21,7 @@ func TestConfigAdvancedValidation(t *testing.T) {
 			{"ValidMySQL", "mysql", false},
 			{"ValidMongoDB", "mongodb", false},
 			{"ValidSQLite", "sqlite", false},
-			{"InvalidOracle", "oracle", true},
+			{"InvalidCassandra", "cassandra", true},
 			{"InvalidMSSQL", "mssql", true},
 			// EmptyType now gets default "postgres" applied, so it passes
 			{"EmptyType", "", false},
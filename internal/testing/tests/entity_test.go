@@ -288,7 +288,7 @@ func testEntityWithAllOptions(tc *framework.TestContext, t *testing.T) {
 		"Number",
 		"Amount",
 		"Paid",
-		"Client_id",
+		"ClientID",
 		"CreatedAt",
 		"UpdatedAt",
 		"DeletedAt",
@@ -323,7 +323,7 @@ func testEntityWithDifferentFieldTypes(tc *framework.TestContext, t *testing.T)
 
 	// Verificar todos los tipos de campos
 	expectedContents := []string{
-		"Complexid",
+		"ComplexID",
 		"string",
 		"Count",
 		"int",
@@ -0,0 +1,12 @@
+#!/bin/sh
+# Generates the shell completions and man pages packaged with a release into
+# completions/ and manpages/. Run by GoReleaser before the build.
+set -e
+
+rm -rf completions manpages
+mkdir -p completions manpages
+for shell in bash zsh fish powershell; do
+	go run . completion "$shell" > "completions/goca.$shell"
+done
+go run . docs man --dir manpages --quiet
+gzip -9 -n manpages/*.1